@@ -3,14 +3,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -21,52 +26,44 @@ import (
 	// Import custom processors
 	"github.com/database-intelligence-mvp/processors/adaptivesampler"
 	"github.com/database-intelligence-mvp/processors/circuitbreaker"
-	"github.com/database-intelligence-mvp/processors/planattributeextractor"
-	"github.com/database-intelligence-mvp/processors/verification"
-	"github.com/database-intelligence-mvp/processors/nrerrormonitor"
 	"github.com/database-intelligence-mvp/processors/costcontrol"
+	"github.com/database-intelligence-mvp/processors/nrerrormonitor"
+	"github.com/database-intelligence-mvp/processors/planattributeextractor"
 	"github.com/database-intelligence-mvp/processors/querycorrelator"
+	"github.com/database-intelligence-mvp/processors/verification"
 )
 
-// testSink is a simple consumer that prints data
+// testSink collects what comes out of the end of a pipeline. Some
+// processors also emit from background goroutines, hence the mutex.
 type testSink struct {
 	name string
-	attributes map[string]string
+
+	mu      sync.Mutex
+	logs    []plog.Logs
+	metrics []pmetric.Metrics
+}
+
+func newTestSink(name string) *testSink {
+	return &testSink{name: name}
 }
 
 func (s *testSink) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
-	log.Printf("[%s] Received %d resource metrics", s.name, md.ResourceMetrics().Len())
-	
-	// Check for enriched attributes
-	for i := 0; i < md.ResourceMetrics().Len(); i++ {
-		rm := md.ResourceMetrics().At(i)
-		rm.Resource().Attributes().Range(func(k string, v pcommon.Value) bool {
-			s.attributes[k] = v.AsString()
-			return true
-		})
-		
-		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
-			sm := rm.ScopeMetrics().At(j)
-			for k := 0; k < sm.Metrics().Len(); k++ {
-				metric := sm.Metrics().At(k)
-				log.Printf("  Metric: %s", metric.Name())
-			}
-		}
-	}
+	copied := pmetric.NewMetrics()
+	md.CopyTo(copied)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = append(s.metrics, copied)
 	return nil
 }
 
 func (s *testSink) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
-	log.Printf("[%s] Received %d resource logs", s.name, ld.ResourceLogs().Len())
-	
-	// Check for enriched attributes
-	for i := 0; i < ld.ResourceLogs().Len(); i++ {
-		rl := ld.ResourceLogs().At(i)
-		rl.Resource().Attributes().Range(func(k string, v pcommon.Value) bool {
-			s.attributes[k] = v.AsString()
-			return true
-		})
-	}
+	copied := plog.NewLogs()
+	ld.CopyTo(copied)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, copied)
 	return nil
 }
 
@@ -74,40 +71,144 @@ func (s *testSink) Capabilities() consumer.Capabilities {
 	return consumer.Capabilities{MutatesData: false}
 }
 
+// logRecords returns the received log records whose resource has
+// service.name set to service, in arrival order
+func (s *testSink) logRecords(service string) []plog.LogRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []plog.LogRecord
+	for _, ld := range s.logs {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			if name, ok := rl.Resource().Attributes().Get("service.name"); !ok || name.Str() != service {
+				continue
+			}
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				lrs := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					records = append(records, lrs.At(k))
+				}
+			}
+		}
+	}
+	return records
+}
+
+// findMetrics returns every received metric called name whose resource has
+// service.name set to service
+func (s *testSink) findMetrics(service, name string) []pmetric.Metric {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []pmetric.Metric
+	for _, md := range s.metrics {
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			rm := md.ResourceMetrics().At(i)
+			if v, ok := rm.Resource().Attributes().Get("service.name"); !ok || v.Str() != service {
+				continue
+			}
+			for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+				ms := rm.ScopeMetrics().At(j).Metrics()
+				for k := 0; k < ms.Len(); k++ {
+					if ms.At(k).Name() == name {
+						found = append(found, ms.At(k))
+					}
+				}
+			}
+		}
+	}
+	return found
+}
+
+// seriesCount returns the number of distinct data point attribute sets of
+// a gauge or sum metric
+func seriesCount(metric pmetric.Metric) int {
+	var dps pmetric.NumberDataPointSlice
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pmetric.MetricTypeSum:
+		dps = metric.Sum().DataPoints()
+	default:
+		return 0
+	}
+
+	series := make(map[string]struct{})
+	for i := 0; i < dps.Len(); i++ {
+		var keys []string
+		dps.At(i).Attributes().Range(func(k string, v pcommon.Value) bool {
+			keys = append(keys, k+"="+v.AsString())
+			return true
+		})
+		sort.Strings(keys)
+		series[strings.Join(keys, "|")] = struct{}{}
+	}
+	return len(series)
+}
+
 func main() {
 	// Create logger
 	logger, _ := zap.NewDevelopment()
-	
+
 	// Test 1: Logs Pipeline (most processors)
 	log.Println("=== Testing Logs Pipeline ===")
-	testLogsPipeline(logger)
-	
-	// Test 2: Metrics Pipeline 
+	if err := testLogsPipeline(logger); err != nil {
+		log.Fatalf("Logs pipeline failed: %v", err)
+	}
+
+	// Test 2: Metrics Pipeline
 	log.Println("\n=== Testing Metrics Pipeline ===")
-	testMetricsPipeline(logger)
-	
+	if err := testMetricsPipeline(logger); err != nil {
+		log.Fatalf("Metrics pipeline failed: %v", err)
+	}
+
 	// Test 3: Cost Control (supports all signals)
 	log.Println("\n=== Testing Cost Control Processor ===")
-	testCostControl(logger)
-	
+	if err := testCostControl(logger); err != nil {
+		log.Fatalf("Cost control failed: %v", err)
+	}
+
 	log.Println("\n✅ All processor communication tests completed successfully!")
-	
+
 	// Wait for signal
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	<-sigCh
-	
+
 	log.Println("Shutting down...")
 }
 
-func testLogsPipeline(logger *zap.Logger) {
+// shutdownFunc stops the processors of a pipeline
+type shutdownFunc func()
+
+// startAll starts the processors, returning a func that shuts down the ones
+// started. On failure the ones already started are shut down.
+func startAll(names []string, procs []component.Component) (shutdownFunc, error) {
+	var started []component.Component
+	shutdown := func() {
+		for i := len(started) - 1; i >= 0; i-- {
+			_ = started[i].Shutdown(context.Background())
+		}
+	}
+	for i, proc := range procs {
+		if err := proc.Start(context.Background(), componenttest.NewNopHost()); err != nil {
+			shutdown()
+			return nil, fmt.Errorf("failed to start %s: %w", names[i], err)
+		}
+		started = append(started, proc)
+	}
+	return shutdown, nil
+}
+
+// newLogsPipeline builds adaptivesampler -> circuitbreaker ->
+// planattributeextractor -> verification -> next and returns its head. The
+// sampler keeps every record so the end of the chain sees all of them.
+func newLogsPipeline(logger *zap.Logger, next consumer.Logs) (consumer.Logs, shutdownFunc, error) {
 	baseSettings := component.TelemetrySettings{
 		Logger: logger,
 	}
-	
-	// Create final sink
-	sink := &testSink{name: "logs-sink", attributes: make(map[string]string)}
-	
+
 	// Create logs processors in reverse order
 	processors := []struct {
 		name   string
@@ -117,7 +218,7 @@ func testLogsPipeline(logger *zap.Logger) {
 			name: "verification",
 			create: func(next consumer.Logs) (processor.Logs, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("verification"),
+					ID:                component.NewID(verification.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
 				cfg := verification.NewFactory().CreateDefaultConfig()
@@ -128,7 +229,7 @@ func testLogsPipeline(logger *zap.Logger) {
 			name: "planattributeextractor",
 			create: func(next consumer.Logs) (processor.Logs, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("planattributeextractor"),
+					ID:                component.NewID(planattributeextractor.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
 				cfg := planattributeextractor.NewFactory().CreateDefaultConfig()
@@ -139,7 +240,7 @@ func testLogsPipeline(logger *zap.Logger) {
 			name: "circuitbreaker",
 			create: func(next consumer.Logs) (processor.Logs, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("circuitbreaker"),
+					ID:                component.NewID(circuitbreaker.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
 				cfg := circuitbreaker.NewFactory().CreateDefaultConfig()
@@ -150,187 +251,239 @@ func testLogsPipeline(logger *zap.Logger) {
 			name: "adaptivesampler",
 			create: func(next consumer.Logs) (processor.Logs, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("adaptivesampler"),
+					ID:                component.NewID(adaptivesampler.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
-				cfg := adaptivesampler.NewFactory().CreateDefaultConfig()
+				cfg := adaptivesampler.NewFactory().CreateDefaultConfig().(*adaptivesampler.Config)
+				cfg.SamplingRules = nil
+				cfg.DefaultSampleRate = 1.0
 				return adaptivesampler.NewFactory().CreateLogs(context.Background(), settings, cfg, next)
 			},
 		},
 	}
-	
+
 	// Build the pipeline
-	var pipeline consumer.Logs = sink
+	var pipeline consumer.Logs = next
+	var names []string
+	var procs []component.Component
 	for _, p := range processors {
 		proc, err := p.create(pipeline)
 		if err != nil {
-			log.Printf("Failed to create %s: %v", p.name, err)
-			continue
-		}
-		
-		// Start the processor
-		if err := proc.Start(context.Background(), nil); err != nil {
-			log.Printf("Failed to start %s: %v", p.name, err)
-			continue
+			return nil, nil, fmt.Errorf("failed to create %s: %w", p.name, err)
 		}
-		defer proc.Shutdown(context.Background())
-		
-		log.Printf("Started logs processor: %s", p.name)
+		names = append(names, p.name)
+		procs = append(procs, proc)
 		pipeline = proc
 	}
-	
-	// Create test logs
+
+	shutdown, err := startAll(names, procs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pipeline, shutdown, nil
+}
+
+// newTestLogs returns a query log with a PostgreSQL plan, as the
+// pg_stat_statements and auto_explain receivers send it
+func newTestLogs() plog.Logs {
 	logs := plog.NewLogs()
 	rl := logs.ResourceLogs().AppendEmpty()
 	rl.Resource().Attributes().PutStr("service.name", "test-db")
 	rl.Resource().Attributes().PutStr("db.system", "postgresql")
-	
+
 	sl := rl.ScopeLogs().AppendEmpty()
 	lr := sl.LogRecords().AppendEmpty()
 	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
 	lr.Body().SetStr("SELECT * FROM users WHERE email='test@example.com'")
 	lr.Attributes().PutStr("db.statement", "SELECT * FROM users WHERE email='test@example.com'")
-	lr.Attributes().PutStr("db.plan.json", `{"Plan": {"Node Type": "Seq Scan"}}`)
-	
+	lr.Attributes().PutStr("plan_json", `[{"Plan": {"Node Type": "Seq Scan", "Total Cost": 35.5, "Plan Rows": 1}}]`)
+	return logs
+}
+
+func testLogsPipeline(logger *zap.Logger) error {
+	sink := newTestSink("logs-sink")
+	pipeline, shutdown, err := newLogsPipeline(logger, sink)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
 	// Send logs through pipeline
-	if err := pipeline.ConsumeLogs(context.Background(), logs); err != nil {
-		log.Printf("Failed to consume logs: %v", err)
+	if err := pipeline.ConsumeLogs(context.Background(), newTestLogs()); err != nil {
+		return fmt.Errorf("failed to consume logs: %w", err)
 	}
-	
+
 	// Check if attributes were added
-	if val, ok := sink.attributes["db.query.plan.hash"]; ok {
-		log.Printf("✓ Plan hash extracted: %s", val)
+	for _, lr := range sink.logRecords("test-db") {
+		if val, ok := lr.Attributes().Get("db.query.plan.hash"); ok {
+			log.Printf("✓ Plan hash extracted: %s", val.AsString())
+		}
 	}
+	return nil
 }
 
-func testMetricsPipeline(logger *zap.Logger) {
+// newMetricsPipeline builds nrerrormonitor -> querycorrelator -> next and
+// returns its head
+func newMetricsPipeline(logger *zap.Logger, next consumer.Metrics) (consumer.Metrics, shutdownFunc, error) {
 	baseSettings := component.TelemetrySettings{
 		Logger: logger,
 	}
-	
-	// Create final sink
-	sink := &testSink{name: "metrics-sink", attributes: make(map[string]string)}
-	
-	// Create metrics processors
+
+	// Create metrics processors in reverse order
 	processors := []struct {
 		name   string
 		create func(consumer.Metrics) (processor.Metrics, error)
 	}{
 		{
-			name: "nrerrormonitor",
+			name: "querycorrelator",
 			create: func(next consumer.Metrics) (processor.Metrics, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("nrerrormonitor"),
+					ID:                component.NewID(querycorrelator.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
-				cfg := nrerrormonitor.NewFactory().CreateDefaultConfig()
-				return nrerrormonitor.NewFactory().CreateMetrics(context.Background(), settings, cfg, next)
+				cfg := querycorrelator.NewFactory().CreateDefaultConfig()
+				return querycorrelator.NewFactory().CreateMetrics(context.Background(), settings, cfg, next)
 			},
 		},
 		{
-			name: "querycorrelator",
+			name: "nrerrormonitor",
 			create: func(next consumer.Metrics) (processor.Metrics, error) {
 				settings := processor.Settings{
-					ID: component.MustNewID("querycorrelator"),
+					ID:                component.NewID(nrerrormonitor.NewFactory().Type()),
 					TelemetrySettings: baseSettings,
 				}
-				cfg := querycorrelator.NewFactory().CreateDefaultConfig()
-				return querycorrelator.NewFactory().CreateMetrics(context.Background(), settings, cfg, next)
+				cfg := nrerrormonitor.NewFactory().CreateDefaultConfig()
+				return nrerrormonitor.NewFactory().CreateMetrics(context.Background(), settings, cfg, next)
 			},
 		},
 	}
-	
+
 	// Build the pipeline
-	var pipeline consumer.Metrics = sink
+	var pipeline consumer.Metrics = next
+	var names []string
+	var procs []component.Component
 	for _, p := range processors {
 		proc, err := p.create(pipeline)
 		if err != nil {
-			log.Printf("Failed to create %s: %v", p.name, err)
-			continue
-		}
-		
-		// Start the processor
-		if err := proc.Start(context.Background(), nil); err != nil {
-			log.Printf("Failed to start %s: %v", p.name, err)
-			continue
+			return nil, nil, fmt.Errorf("failed to create %s: %w", p.name, err)
 		}
-		defer proc.Shutdown(context.Background())
-		
-		log.Printf("Started metrics processor: %s", p.name)
+		names = append(names, p.name)
+		procs = append(procs, proc)
 		pipeline = proc
 	}
-	
-	// Create test metrics
+
+	shutdown, err := startAll(names, procs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pipeline, shutdown, nil
+}
+
+// newTestMetrics returns a query duration histogram with one data point
+func newTestMetrics() pmetric.Metrics {
 	metrics := pmetric.NewMetrics()
 	rm := metrics.ResourceMetrics().AppendEmpty()
 	rm.Resource().Attributes().PutStr("service.name", "test-db")
 	rm.Resource().Attributes().PutStr("db.system", "postgresql")
-	
+
 	sm := rm.ScopeMetrics().AppendEmpty()
 	metric := sm.Metrics().AppendEmpty()
 	metric.SetName("db.query.duration")
-	
+
 	histogram := metric.SetEmptyHistogram()
 	dp := histogram.DataPoints().AppendEmpty()
 	dp.SetSum(123.45)
 	dp.SetCount(1)
 	dp.Attributes().PutStr("db.statement", "SELECT * FROM users")
+	dp.Attributes().PutStr("query.text", "SELECT * FROM users")
 	dp.Attributes().PutStr("db.user", "postgres")
-	
+	return metrics
+}
+
+func testMetricsPipeline(logger *zap.Logger) error {
+	sink := newTestSink("metrics-sink")
+	pipeline, shutdown, err := newMetricsPipeline(logger, sink)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
 	// Send metrics through pipeline
-	if err := pipeline.ConsumeMetrics(context.Background(), metrics); err != nil {
-		log.Printf("Failed to consume metrics: %v", err)
+	if err := pipeline.ConsumeMetrics(context.Background(), newTestMetrics()); err != nil {
+		return fmt.Errorf("failed to consume metrics: %w", err)
 	}
-}
 
-func testCostControl(logger *zap.Logger) {
-	baseSettings := component.TelemetrySettings{
-		Logger: logger,
+	for _, metric := range sink.findMetrics("test-db", "db.query.duration") {
+		attrs := metric.Histogram().DataPoints().At(0).Attributes()
+		if id, ok := attrs.Get("correlation.query_id"); ok {
+			log.Printf("✓ Query correlated: %s", id.AsString())
+		}
 	}
-	
-	// Create final sink
-	sink := &testSink{name: "cost-sink", attributes: make(map[string]string)}
-	
-	// Create cost control processor
+	return nil
+}
+
+// newCostControl creates and starts a costcontrol metrics processor
+func newCostControl(logger *zap.Logger, cfg *costcontrol.Config, next consumer.Metrics) (consumer.Metrics, shutdownFunc, error) {
 	settings := processor.Settings{
-		ID: component.MustNewID("costcontrol"),
-		TelemetrySettings: baseSettings,
+		ID: component.NewID(costcontrol.NewFactory().Type()),
+		TelemetrySettings: component.TelemetrySettings{
+			Logger: logger,
+		},
 	}
-	cfg := costcontrol.NewFactory().CreateDefaultConfig()
-	
-	// Test with metrics
-	metricsProc, err := costcontrol.NewFactory().CreateMetrics(context.Background(), settings, cfg, sink)
+
+	metricsProc, err := costcontrol.NewFactory().CreateMetrics(context.Background(), settings, cfg, next)
 	if err != nil {
-		log.Printf("Failed to create cost control for metrics: %v", err)
-		return
+		return nil, nil, fmt.Errorf("failed to create cost control for metrics: %w", err)
 	}
-	
-	if err := metricsProc.Start(context.Background(), nil); err != nil {
-		log.Printf("Failed to start cost control: %v", err)
-		return
+
+	shutdown, err := startAll([]string{"costcontrol"}, []component.Component{metricsProc})
+	if err != nil {
+		return nil, nil, err
 	}
-	defer metricsProc.Shutdown(context.Background())
-	
-	log.Printf("Started cost control processor for metrics")
-	
-	// Create test metrics
+	return metricsProc, shutdown, nil
+}
+
+// newHighCardinalityMetrics returns a gauge with one series per user, which
+// is the kind of attribute cost control sheds once a metric is over its
+// cardinality limit
+func newHighCardinalityMetrics(users int) pmetric.Metrics {
 	metrics := pmetric.NewMetrics()
 	rm := metrics.ResourceMetrics().AppendEmpty()
 	rm.Resource().Attributes().PutStr("service.name", "test-db")
-	
-	// Add many metrics to test cardinality control
+
 	sm := rm.ScopeMetrics().AppendEmpty()
-	for i := 0; i < 10; i++ {
-		metric := sm.Metrics().AppendEmpty()
-		metric.SetName("db.custom.metric")
-		gauge := metric.SetEmptyGauge()
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName("db.custom.metric")
+	gauge := metric.SetEmptyGauge()
+	for i := 0; i < users; i++ {
 		dp := gauge.DataPoints().AppendEmpty()
 		dp.SetDoubleValue(float64(i))
-		dp.Attributes().PutStr("unique_id", strconv.Itoa(i))
+		dp.Attributes().PutStr("db.name", "orders")
+		dp.Attributes().PutStr("user.id", strconv.Itoa(i))
+	}
+	return metrics
+}
+
+func testCostControl(logger *zap.Logger) error {
+	sink := newTestSink("cost-sink")
+	cfg := costcontrol.NewFactory().CreateDefaultConfig().(*costcontrol.Config)
+	cfg.MetricCardinalityLimit = 5
+
+	metricsProc, shutdown, err := newCostControl(logger, cfg, sink)
+	if err != nil {
+		return err
 	}
-	
+	defer shutdown()
+
+	log.Printf("Started cost control processor for metrics")
+
 	// Send metrics through cost control
-	if err := metricsProc.ConsumeMetrics(context.Background(), metrics); err != nil {
-		log.Printf("Failed to consume metrics in cost control: %v", err)
+	if err := metricsProc.ConsumeMetrics(context.Background(), newHighCardinalityMetrics(10)); err != nil {
+		return fmt.Errorf("failed to consume metrics in cost control: %w", err)
 	}
-}
\ No newline at end of file
+
+	for _, metric := range sink.findMetrics("test-db", "db.custom.metric") {
+		log.Printf("✓ %s reduced to %d series (limit %d)", metric.Name(), seriesCount(metric), cfg.MetricCardinalityLimit)
+	}
+	return nil
+}
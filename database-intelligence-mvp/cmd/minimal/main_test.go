@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence-mvp/processors/costcontrol"
+)
+
+func TestLogsPipelineEnrichment(t *testing.T) {
+	sink := newTestSink("logs-sink")
+	pipeline, shutdown, err := newLogsPipeline(zap.NewNop(), sink)
+	require.NoError(t, err)
+	t.Cleanup(shutdown)
+
+	require.NoError(t, pipeline.ConsumeLogs(context.Background(), newTestLogs()))
+
+	// verification may also send feedback logs, under its own resource
+	records := sink.logRecords("test-db")
+	require.Len(t, records, 1, "the sampler keeps every record")
+	attrs := records[0].Attributes()
+
+	hash, ok := attrs.Get("db.query.plan.hash")
+	require.True(t, ok, "planattributeextractor adds the plan hash")
+	assert.NotEmpty(t, hash.Str())
+
+	operation, ok := attrs.Get("db.query.plan.operation")
+	require.True(t, ok)
+	assert.Equal(t, "Seq Scan", operation.Str())
+
+	seqScan, ok := attrs.Get("db.query.plan.has_seq_scan")
+	require.True(t, ok)
+	assert.True(t, seqScan.Bool())
+
+	fingerprint, ok := attrs.Get("db.query.fingerprint")
+	require.True(t, ok)
+	assert.NotEmpty(t, fingerprint.Str())
+
+	statement, ok := attrs.Get("db.statement")
+	require.True(t, ok)
+	assert.NotContains(t, statement.Str(), "test@example.com", "literals are anonymized")
+}
+
+func TestMetricsPipeline(t *testing.T) {
+	sink := newTestSink("metrics-sink")
+	pipeline, shutdown, err := newMetricsPipeline(zap.NewNop(), sink)
+	require.NoError(t, err)
+	t.Cleanup(shutdown)
+
+	require.NoError(t, pipeline.ConsumeMetrics(context.Background(), newTestMetrics()))
+
+	metrics := sink.findMetrics("test-db", "db.query.duration")
+	require.Len(t, metrics, 1)
+	dps := metrics[0].Histogram().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	attrs := dps.At(0).Attributes()
+
+	avg, ok := attrs.Get("_avg_duration_ms")
+	require.True(t, ok, "querycorrelator adds the average duration")
+	assert.InDelta(t, 123.45, avg.Double(), 1e-9)
+
+	queryID, ok := attrs.Get("correlation.query_id")
+	require.True(t, ok)
+	assert.NotEmpty(t, queryID.Str())
+
+	tables, ok := attrs.Get("correlation.tables")
+	require.True(t, ok)
+	assert.Equal(t, "users", tables.Str())
+
+	category, ok := attrs.Get("query.performance_category")
+	require.True(t, ok)
+	assert.Equal(t, "slow", category.Str())
+
+	// nrerrormonitor passes data through unchanged
+	user, ok := attrs.Get("db.user")
+	require.True(t, ok)
+	assert.Equal(t, "postgres", user.Str())
+}
+
+func TestCostControlCardinality(t *testing.T) {
+	tests := []struct {
+		name       string
+		users      int
+		wantSeries int
+		wantUserID bool
+	}{
+		{
+			name:       "over the limit sheds user.id",
+			users:      10,
+			wantSeries: 1,
+		},
+		{
+			name:       "under the limit is left alone",
+			users:      3,
+			wantSeries: 3,
+			wantUserID: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := costcontrol.NewFactory().CreateDefaultConfig().(*costcontrol.Config)
+			cfg.MetricCardinalityLimit = 5
+
+			sink := newTestSink("cost-sink")
+			proc, shutdown, err := newCostControl(zap.NewNop(), cfg, sink)
+			require.NoError(t, err)
+			t.Cleanup(shutdown)
+
+			require.NoError(t, proc.ConsumeMetrics(context.Background(), newHighCardinalityMetrics(tt.users)))
+
+			metrics := sink.findMetrics("test-db", "db.custom.metric")
+			require.Len(t, metrics, 1)
+			assert.Equal(t, tt.wantSeries, seriesCount(metrics[0]))
+			assert.LessOrEqual(t, seriesCount(metrics[0]), cfg.MetricCardinalityLimit)
+
+			dps := metrics[0].Gauge().DataPoints()
+			require.Equal(t, tt.users, dps.Len(), "data points are kept, only attributes are removed")
+			for i := 0; i < dps.Len(); i++ {
+				_, hasUserID := dps.At(i).Attributes().Get("user.id")
+				assert.Equal(t, tt.wantUserID, hasUserID)
+				dbName, ok := dps.At(i).Attributes().Get("db.name")
+				require.True(t, ok, "low-cardinality attributes are kept")
+				assert.Equal(t, "orders", dbName.Str())
+			}
+		})
+	}
+}
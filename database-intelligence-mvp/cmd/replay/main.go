@@ -0,0 +1,192 @@
+// Replay feeds recorded OTLP JSON logs and metrics through a chain of the
+// custom processors and prints what comes out, to reproduce processor
+// behavior on captured data without a database.
+//
+//	replay -input recording.jsonl -processors planattributeextractor,verification
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// options are the replay command line flags
+type options struct {
+	input      string
+	output     string
+	configPath string
+	processors []string
+	pretty     bool
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Invalid flags: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Processor logs go to stderr, the replayed data to -output
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	if err := run(context.Background(), opts, logger); err != nil {
+		fmt.Fprintf(os.Stderr, "Replay failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string) (*options, error) {
+	opts := &options{}
+	var processors string
+
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.StringVar(&opts.input, "input", "", "Recorded OTLP JSON file, a single document or one payload per line as written by the fileexporter (- reads stdin)")
+	flags.StringVar(&opts.output, "output", "-", "Where to write the processed payloads, one JSON payload per line (- writes stdout)")
+	flags.StringVar(&opts.configPath, "config", "", "Collector configuration whose processors section configures the replayed processors (defaults are used otherwise)")
+	flags.StringVar(&processors, "processors", "planattributeextractor,verification,querycorrelator", "Comma-separated processors in pipeline order: "+processorTypes())
+	flags.BoolVar(&opts.pretty, "pretty", false, "Indent the output JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.input == "" {
+		return nil, errors.New("-input is required")
+	}
+	for _, p := range strings.Split(processors, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			opts.processors = append(opts.processors, p)
+		}
+	}
+	if len(opts.processors) == 0 {
+		return nil, errors.New("-processors must name at least one processor")
+	}
+	return opts, nil
+}
+
+// run reads the recording and the optional configuration, replays the
+// recording and writes the result
+func run(ctx context.Context, opts *options, logger *zap.Logger) error {
+	in := io.Reader(os.Stdin)
+	if opts.input != "-" {
+		f, err := os.Open(opts.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	rec, err := readRecording(in)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.input, err)
+	}
+
+	var conf *confmap.Conf
+	if opts.configPath != "" {
+		if conf, err = loadConfig(opts.configPath); err != nil {
+			return err
+		}
+	}
+
+	out := io.Writer(os.Stdout)
+	if opts.output != "-" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return replay(ctx, rec, opts.processors, conf, logger, out, opts.pretty)
+}
+
+// loadConfig reads a collector configuration file
+func loadConfig(path string) (*confmap.Conf, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	retrieved, err := confmap.NewRetrievedFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	conf, err := retrieved.AsConf()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return conf, nil
+}
+
+// replay sends every batch of rec through the processors and writes the
+// batches that reach the end of the pipeline to out
+func replay(ctx context.Context, rec *recording, processors []string, conf *confmap.Conf, logger *zap.Logger, out io.Writer, pretty bool) error {
+	sink := &outputSink{}
+	settings := component.TelemetrySettings{Logger: logger}
+
+	p, err := buildPipeline(ctx, processors, conf, settings, sink, rec)
+	if err != nil {
+		return err
+	}
+
+	for i, b := range rec.batches {
+		switch {
+		case b.logs != nil:
+			err = p.logs.ConsumeLogs(ctx, *b.logs)
+		case b.metrics != nil:
+			err = p.metrics.ConsumeMetrics(ctx, *b.metrics)
+		}
+		if err != nil {
+			logger.Warn("Pipeline rejected batch", zap.Int("batch", i+1), zap.Error(err))
+		}
+	}
+	p.shutdown(ctx)
+
+	return writeBatches(out, sink.collected(), pretty)
+}
+
+// writeBatches writes one OTLP JSON payload per line, the format replay
+// reads, so the output can be replayed again
+func writeBatches(out io.Writer, batches []batch, pretty bool) error {
+	for _, b := range batches {
+		var data []byte
+		var err error
+		switch {
+		case b.logs != nil:
+			data, err = (&plog.JSONMarshaler{}).MarshalLogs(*b.logs)
+		case b.metrics != nil:
+			data, err = (&pmetric.JSONMarshaler{}).MarshalMetrics(*b.metrics)
+		}
+		if err != nil {
+			return err
+		}
+
+		if pretty {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, data, "", "  "); err != nil {
+				return err
+			}
+			data = buf.Bytes()
+		}
+		if _, err := fmt.Fprintf(out, "%s\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
+
+	// Import custom processors
+	"github.com/database-intelligence-mvp/processors/adaptivesampler"
+	"github.com/database-intelligence-mvp/processors/circuitbreaker"
+	"github.com/database-intelligence-mvp/processors/costcontrol"
+	"github.com/database-intelligence-mvp/processors/nrerrormonitor"
+	"github.com/database-intelligence-mvp/processors/planattributeextractor"
+	"github.com/database-intelligence-mvp/processors/querycorrelator"
+	"github.com/database-intelligence-mvp/processors/verification"
+)
+
+// factories are the processors a replay can include, keyed by type
+var factories = map[string]processor.Factory{
+	"adaptivesampler":        adaptivesampler.NewFactory(),
+	"circuitbreaker":         circuitbreaker.NewFactory(),
+	"costcontrol":            costcontrol.NewFactory(),
+	"nrerrormonitor":         nrerrormonitor.NewFactory(),
+	"planattributeextractor": planattributeextractor.NewFactory(),
+	"querycorrelator":        querycorrelator.NewFactory(),
+	"verification":           verification.NewFactory(),
+}
+
+// processorTypes returns the names accepted by -processors
+func processorTypes() string {
+	types := make([]string, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+// pipeline is a chain of started processors ending in a sink. When no
+// selected processor handles a signal, its batches go straight to the sink.
+type pipeline struct {
+	logs       consumer.Logs
+	metrics    consumer.Metrics
+	components []component.Component
+}
+
+// buildPipeline creates and starts the processors named by ids, in order, so
+// the first id sees the data first. Each id is a processor type with an
+// optional /name, as in a collector configuration. Configuration comes from
+// the processors section of conf when it has one for the id, otherwise from
+// the factory defaults. Processors that do not support a signal are left
+// out of that signal's chain.
+func buildPipeline(ctx context.Context, ids []string, conf *confmap.Conf, settings component.TelemetrySettings, sink *outputSink, rec *recording) (*pipeline, error) {
+	p := &pipeline{}
+	var logs consumer.Logs = sink
+	var metrics consumer.Metrics = sink
+
+	// Build from the end of the chain so each processor gets its successor
+	for i := len(ids) - 1; i >= 0; i-- {
+		var id component.ID
+		if err := id.UnmarshalText([]byte(ids[i])); err != nil {
+			p.shutdown(ctx)
+			return nil, fmt.Errorf("invalid processor %q: %w", ids[i], err)
+		}
+		factory, ok := factories[id.Type().String()]
+		if !ok {
+			p.shutdown(ctx)
+			return nil, fmt.Errorf("unknown processor %q, expected one of: %s", ids[i], processorTypes())
+		}
+
+		cfg := factory.CreateDefaultConfig()
+		key := "processors::" + id.String()
+		if conf != nil && conf.IsSet(key) {
+			sub, err := conf.Sub(key)
+			if err == nil {
+				err = sub.Unmarshal(cfg)
+			}
+			if err != nil {
+				p.shutdown(ctx)
+				return nil, fmt.Errorf("processor %q config: %w", id, err)
+			}
+		}
+		set := processor.Settings{ID: id, TelemetrySettings: settings}
+
+		used := false
+		if rec.hasLogs() && factory.LogsStability() != component.StabilityLevelUndefined {
+			proc, err := factory.CreateLogs(ctx, set, cfg, logs)
+			if err != nil {
+				p.shutdown(ctx)
+				return nil, fmt.Errorf("failed to create %s for logs: %w", id, err)
+			}
+			if err := p.start(ctx, proc); err != nil {
+				return nil, fmt.Errorf("failed to start %s for logs: %w", id, err)
+			}
+			logs, used = proc, true
+		}
+		if rec.hasMetrics() && factory.MetricsStability() != component.StabilityLevelUndefined {
+			proc, err := factory.CreateMetrics(ctx, set, cfg, metrics)
+			if err != nil {
+				p.shutdown(ctx)
+				return nil, fmt.Errorf("failed to create %s for metrics: %w", id, err)
+			}
+			if err := p.start(ctx, proc); err != nil {
+				return nil, fmt.Errorf("failed to start %s for metrics: %w", id, err)
+			}
+			metrics, used = proc, true
+		}
+		if !used {
+			p.shutdown(ctx)
+			return nil, fmt.Errorf("processor %q handles none of the recorded signals", id)
+		}
+	}
+
+	p.logs, p.metrics = logs, metrics
+	return p, nil
+}
+
+// start starts a processor and adds it to the ones shut down with the
+// pipeline. On failure the pipeline is shut down.
+func (p *pipeline) start(ctx context.Context, c component.Component) error {
+	if err := c.Start(ctx, componenttest.NewNopHost()); err != nil {
+		p.shutdown(ctx)
+		return err
+	}
+	p.components = append(p.components, c)
+	return nil
+}
+
+// shutdown stops the processors, first in the chain first
+func (p *pipeline) shutdown(ctx context.Context) {
+	for i := len(p.components) - 1; i >= 0; i-- {
+		_ = p.components[i].Shutdown(ctx)
+	}
+	p.components = nil
+}
+
+// outputSink collects what comes out of the end of the pipeline. Some
+// processors also emit from background goroutines, hence the mutex.
+type outputSink struct {
+	mu      sync.Mutex
+	batches []batch
+}
+
+func (s *outputSink) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, batch{logs: &ld})
+	return nil
+}
+
+func (s *outputSink) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, batch{metrics: &md})
+	return nil
+}
+
+// collected returns the batches received so far
+func (s *outputSink) collected() []batch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]batch(nil), s.batches...)
+}
+
+func (s *outputSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// recording holds the batches read from a recorded file, in file order
+type recording struct {
+	batches []batch
+}
+
+// batch is one recorded OTLP payload; exactly one of logs or metrics is set
+type batch struct {
+	logs    *plog.Logs
+	metrics *pmetric.Metrics
+}
+
+// otlpKeys is used to tell logs from metrics before unmarshaling a payload
+type otlpKeys struct {
+	ResourceLogs    json.RawMessage `json:"resourceLogs"`
+	ResourceMetrics json.RawMessage `json:"resourceMetrics"`
+}
+
+// readRecording reads OTLP JSON payloads from r. It accepts a single JSON
+// document as well as the fileexporter format of one payload per line,
+// because both are a sequence of JSON values.
+func readRecording(r io.Reader) (*recording, error) {
+	rec := &recording{}
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("payload %d: %w", n, err)
+		}
+
+		var keys otlpKeys
+		if err := json.Unmarshal(raw, &keys); err != nil {
+			return nil, fmt.Errorf("payload %d: %w", n, err)
+		}
+
+		switch {
+		case keys.ResourceLogs != nil:
+			ld, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(raw)
+			if err != nil {
+				return nil, fmt.Errorf("payload %d: %w", n, err)
+			}
+			rec.batches = append(rec.batches, batch{logs: &ld})
+		case keys.ResourceMetrics != nil:
+			md, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(raw)
+			if err != nil {
+				return nil, fmt.Errorf("payload %d: %w", n, err)
+			}
+			rec.batches = append(rec.batches, batch{metrics: &md})
+		default:
+			return nil, fmt.Errorf("payload %d: expected resourceLogs or resourceMetrics", n)
+		}
+	}
+
+	if len(rec.batches) == 0 {
+		return nil, errors.New("no payloads in recording")
+	}
+	return rec, nil
+}
+
+// hasLogs reports whether the recording contains any logs payload
+func (rec *recording) hasLogs() bool {
+	for _, b := range rec.batches {
+		if b.logs != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMetrics reports whether the recording contains any metrics payload
+func (rec *recording) hasMetrics() bool {
+	for _, b := range rec.batches {
+		if b.metrics != nil {
+			return true
+		}
+	}
+	return false
+}
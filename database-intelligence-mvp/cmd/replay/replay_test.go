@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func readFixture(t *testing.T) *recording {
+	t.Helper()
+	f, err := os.Open("testdata/recording.jsonl")
+	require.NoError(t, err)
+	defer f.Close()
+
+	rec, err := readRecording(f)
+	require.NoError(t, err)
+	return rec
+}
+
+func TestReplay_Golden(t *testing.T) {
+	rec := readFixture(t)
+
+	var out bytes.Buffer
+	err := replay(context.Background(), rec, []string{"planattributeextractor", "querycorrelator"}, nil, zap.NewNop(), &out, false)
+	require.NoError(t, err)
+
+	const golden = "testdata/recording.golden.jsonl"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, out.Bytes(), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err, "run go test -update to create it")
+	assert.Equal(t, canonicalPayloads(t, want), canonicalPayloads(t, out.Bytes()))
+
+	// The output is itself a recording
+	replayed, err := readRecording(&out)
+	require.NoError(t, err)
+	assert.Len(t, replayed.batches, 2)
+}
+
+// canonicalPayloads decodes one JSON payload per line for comparison. It
+// ignores attribute order, since planattributeextractor adds attributes in
+// map order, and empty trace and span IDs, which pdata versions write
+// differently.
+func canonicalPayloads(t *testing.T, data []byte) []any {
+	t.Helper()
+	var payloads []any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var v any
+		require.NoError(t, json.Unmarshal([]byte(line), &v))
+		payloads = append(payloads, canonicalize(v))
+	}
+	return payloads
+}
+
+func canonicalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if (key == "traceId" || key == "spanId") && value == "" {
+				delete(v, key)
+				continue
+			}
+			v[key] = canonicalize(value)
+		}
+		if attrs, ok := v["attributes"].([]any); ok {
+			sort.SliceStable(attrs, func(i, j int) bool {
+				return fmt.Sprint(attrs[i].(map[string]any)["key"]) < fmt.Sprint(attrs[j].(map[string]any)["key"])
+			})
+		}
+	case []any:
+		for i := range v {
+			v[i] = canonicalize(v[i])
+		}
+	}
+	return v
+}
+
+func TestReplay_ConfigOverridesDefaults(t *testing.T) {
+	conf, err := loadConfig("testdata/collector.yaml")
+	require.NoError(t, err)
+
+	// queryText replays the fixture and returns the query_text of its log record
+	queryText := func(conf *confmap.Conf) string {
+		var out bytes.Buffer
+		err := replay(context.Background(), readFixture(t), []string{"planattributeextractor"}, conf, zap.NewNop(), &out, false)
+		require.NoError(t, err)
+
+		first, _, _ := strings.Cut(out.String(), "\n")
+		ld, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs([]byte(first))
+		require.NoError(t, err)
+		v, ok := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("query_text")
+		require.True(t, ok)
+		return v.Str()
+	}
+
+	assert.NotContains(t, queryText(nil), "jane@example.com", "anonymized by default")
+	assert.Contains(t, queryText(conf), "jane@example.com", "anonymization disabled in collector.yaml")
+}
+
+func TestReadRecording(t *testing.T) {
+	t.Run("single indented document", func(t *testing.T) {
+		rec, err := readRecording(strings.NewReader(`{
+			"resourceLogs": [{"scopeLogs": [{"logRecords": [{"body": {"stringValue": "x"}}]}]}]
+		}`))
+		require.NoError(t, err)
+		require.Len(t, rec.batches, 1)
+		assert.NotNil(t, rec.batches[0].logs)
+		assert.True(t, rec.hasLogs())
+		assert.False(t, rec.hasMetrics())
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: "", wantErr: "no payloads"},
+		{name: "not OTLP", input: `{"results": []}`, wantErr: "payload 1: expected resourceLogs or resourceMetrics"},
+		{name: "truncated", input: `{"resourceMetrics": []}` + "\n" + `{"resourceLogs": [`, wantErr: "payload 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readRecording(strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuildPipeline_Errors(t *testing.T) {
+	rec := readFixture(t)
+
+	tests := []struct {
+		name       string
+		processors []string
+		wantErr    string
+	}{
+		{name: "unknown processor", processors: []string{"planattributeextractor", "nope"}, wantErr: `unknown processor "nope"`},
+		{name: "invalid id", processors: []string{"planattributeextractor/"}, wantErr: "invalid processor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := replay(context.Background(), rec, tt.processors, nil, zap.NewNop(), &bytes.Buffer{}, false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"-input", "rec.jsonl", "-processors", " planattributeextractor, verification/strict ,"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"planattributeextractor", "verification/strict"}, opts.processors)
+	assert.Equal(t, "-", opts.output)
+
+	_, err = parseFlags(nil)
+	assert.ErrorContains(t, err, "-input is required")
+
+	_, err = parseFlags([]string{"-input", "rec.jsonl", "-processors", ","})
+	assert.ErrorContains(t, err, "at least one processor")
+}
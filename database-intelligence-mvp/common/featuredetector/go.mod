@@ -1,6 +1,6 @@
 module github.com/database-intelligence-mvp/common/featuredetector
 
-go 1.21
+go 1.23.0
 
 toolchain go1.24.3
 
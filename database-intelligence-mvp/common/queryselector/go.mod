@@ -1,6 +1,6 @@
 module github.com/database-intelligence-mvp/common/queryselector
 
-go 1.21
+go 1.23.0
 
 require (
 	github.com/database-intelligence-mvp/common/featuredetector v0.0.0-00010101000000-000000000000
@@ -1,6 +1,6 @@
 module github.com/database-intelligence-mvp
 
-go 1.21
+go 1.23.8
 
 require (
 	github.com/database-intelligence-mvp/processors/adaptivesampler v0.0.0-00010101000000-000000000000
@@ -31,6 +31,7 @@ require (
 	github.com/testcontainers/testcontainers-go/modules/postgres v0.37.0
 	go.opentelemetry.io/collector/component v1.35.0
 	go.opentelemetry.io/collector/component/componenttest v0.129.0
+	go.opentelemetry.io/collector/config/configretry v1.35.0
 	go.opentelemetry.io/collector/confmap v1.35.0
 	go.opentelemetry.io/collector/confmap/provider/envprovider v1.35.0
 	go.opentelemetry.io/collector/confmap/provider/fileprovider v1.35.0
@@ -314,7 +315,6 @@ require (
 	go.opentelemetry.io/collector/config/confignet v1.35.0 // indirect
 	go.opentelemetry.io/collector/config/configopaque v1.35.0 // indirect
 	go.opentelemetry.io/collector/config/configoptional v0.129.0 // indirect
-	go.opentelemetry.io/collector/config/configretry v1.35.0 // indirect
 	go.opentelemetry.io/collector/config/configtelemetry v0.129.0 // indirect
 	go.opentelemetry.io/collector/config/configtls v1.35.0 // indirect
 	go.opentelemetry.io/collector/confmap/xconfmap v0.129.0 // indirect
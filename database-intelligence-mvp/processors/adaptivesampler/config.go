@@ -77,6 +77,33 @@ type SamplingRule struct {
 
 	// MaxPerMinute limits records matched by this rule
 	MaxPerMinute int `mapstructure:"max_per_minute,omitempty"`
+
+	// Reservoir keeps a minimum number of records per query fingerprint
+	// that the sample rate would otherwise drop
+	Reservoir ReservoirConfig `mapstructure:"reservoir"`
+}
+
+// ReservoirConfig guarantees low-frequency queries are represented: records
+// dropped by the rule's sample rate are reservoir sampled per fingerprint,
+// and at the end of each window enough of them are forwarded to keep at
+// least Size records of every fingerprint seen
+type ReservoirConfig struct {
+	// Size is the minimum number of records kept per fingerprint and window.
+	// Zero disables the reservoir.
+	Size int `mapstructure:"size"`
+
+	// Window is how long records are collected before the reservoir is
+	// flushed. Defaults to one minute.
+	Window time.Duration `mapstructure:"window"`
+
+	// FingerprintAttribute identifies records of the same query shape.
+	// Defaults to db.query.fingerprint; records without it are only rate
+	// sampled.
+	FingerprintAttribute string `mapstructure:"fingerprint_attribute"`
+
+	// MaxFingerprints bounds the fingerprints tracked per window, and with
+	// Size the records held in memory. Defaults to 10000.
+	MaxFingerprints int `mapstructure:"max_fingerprints"`
 }
 
 // SamplingCondition defines a condition for sampling
@@ -104,6 +131,22 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("max_records_per_second must be positive, got: %d", cfg.MaxRecordsPerSecond)
 	}
 
+	if cfg.MinSampleRate < 0.0 || cfg.MinSampleRate > 1.0 {
+		return fmt.Errorf("min_sample_rate must be between 0.0 and 1.0, got: %f", cfg.MinSampleRate)
+	}
+
+	if cfg.MinSampleRate > cfg.DefaultSampleRate {
+		return fmt.Errorf("min_sample_rate (%f) cannot be greater than default_sample_rate (%f)", cfg.MinSampleRate, cfg.DefaultSampleRate)
+	}
+
+	if cfg.HighCostThreshold < 0 {
+		return fmt.Errorf("high_cost_threshold cannot be negative, got: %f", cfg.HighCostThreshold)
+	}
+
+	if cfg.SyncInterval < 0 {
+		return fmt.Errorf("sync_interval cannot be negative, got: %v", cfg.SyncInterval)
+	}
+
 	if cfg.Deduplication.Enabled {
 		if cfg.Deduplication.CacheSize <= 0 {
 			return fmt.Errorf("deduplication cache_size must be positive, got: %d", cfg.Deduplication.CacheSize)
@@ -114,6 +157,9 @@ func (cfg *Config) Validate() error {
 		if cfg.Deduplication.HashAttribute == "" {
 			return fmt.Errorf("deduplication hash_attribute must be specified")
 		}
+		if cfg.Deduplication.CleanupInterval < 0 {
+			return fmt.Errorf("deduplication cleanup_interval cannot be negative, got: %v", cfg.Deduplication.CleanupInterval)
+		}
 	}
 
 	// Validate sampling rules
@@ -140,6 +186,10 @@ func (rule *SamplingRule) Validate() error {
 		return fmt.Errorf("max_per_minute cannot be negative, got: %d", rule.MaxPerMinute)
 	}
 
+	if err := rule.Reservoir.Validate(); err != nil {
+		return fmt.Errorf("invalid reservoir: %w", err)
+	}
+
 	// Validate conditions
 	for i, condition := range rule.Conditions {
 		if err := condition.Validate(); err != nil {
@@ -150,6 +200,23 @@ func (rule *SamplingRule) Validate() error {
 	return nil
 }
 
+// Validate checks a reservoir configuration
+func (r *ReservoirConfig) Validate() error {
+	if r.Size < 0 {
+		return fmt.Errorf("size cannot be negative, got: %d", r.Size)
+	}
+
+	if r.Window < 0 {
+		return fmt.Errorf("window cannot be negative, got: %v", r.Window)
+	}
+
+	if r.MaxFingerprints < 0 {
+		return fmt.Errorf("max_fingerprints cannot be negative, got: %d", r.MaxFingerprints)
+	}
+
+	return nil
+}
+
 // Validate checks a sampling condition
 func (condition *SamplingCondition) Validate() error {
 	if condition.Attribute == "" {
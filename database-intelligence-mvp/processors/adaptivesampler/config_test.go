@@ -0,0 +1,162 @@
+package adaptivesampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero max records per second",
+			modify: func(cfg *Config) {
+				cfg.MaxRecordsPerSecond = 0
+			},
+			wantErr: "max_records_per_second must be positive",
+		},
+		{
+			name: "min sample rate above one",
+			modify: func(cfg *Config) {
+				cfg.MinSampleRate = 2
+			},
+			wantErr: "min_sample_rate must be between 0.0 and 1.0",
+		},
+		{
+			name: "min sample rate above default sample rate",
+			modify: func(cfg *Config) {
+				cfg.MinSampleRate = 0.5
+				cfg.DefaultSampleRate = 0.1
+			},
+			wantErr: "min_sample_rate (0.500000) cannot be greater than default_sample_rate (0.100000)",
+		},
+		{
+			name: "negative high cost threshold",
+			modify: func(cfg *Config) {
+				cfg.HighCostThreshold = -1
+			},
+			wantErr: "high_cost_threshold cannot be negative",
+		},
+		{
+			name: "negative sync interval",
+			modify: func(cfg *Config) {
+				cfg.SyncInterval = -time.Second
+			},
+			wantErr: "sync_interval cannot be negative",
+		},
+		{
+			name: "zero deduplication cache size",
+			modify: func(cfg *Config) {
+				cfg.Deduplication.CacheSize = 0
+			},
+			wantErr: "deduplication cache_size must be positive",
+		},
+		{
+			name: "zero deduplication window",
+			modify: func(cfg *Config) {
+				cfg.Deduplication.WindowSeconds = 0
+			},
+			wantErr: "deduplication window_seconds must be positive",
+		},
+		{
+			name: "missing deduplication hash attribute",
+			modify: func(cfg *Config) {
+				cfg.Deduplication.HashAttribute = ""
+			},
+			wantErr: "deduplication hash_attribute must be specified",
+		},
+		{
+			name: "negative deduplication cleanup interval",
+			modify: func(cfg *Config) {
+				cfg.Deduplication.CleanupInterval = -time.Second
+			},
+			wantErr: "deduplication cleanup_interval cannot be negative",
+		},
+		{
+			name: "invalid deduplication settings ignored when disabled",
+			modify: func(cfg *Config) {
+				cfg.Deduplication = DeduplicationConfig{Enabled: false}
+			},
+		},
+		{
+			name: "rule without name",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[0].Name = ""
+			},
+			wantErr: "rule name cannot be empty",
+		},
+		{
+			name: "negative rule rate limit",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[2].MaxPerMinute = -1
+			},
+			wantErr: "invalid sampling rule 2 (high_frequency): max_per_minute cannot be negative",
+		},
+		{
+			name: "reservoir on low-rate rule",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, Window: time.Minute}
+			},
+		},
+		{
+			name: "negative reservoir size",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir.Size = -1
+			},
+			wantErr: "invalid sampling rule 3 (default): invalid reservoir: size cannot be negative",
+		},
+		{
+			name: "negative reservoir window",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, Window: -time.Minute}
+			},
+			wantErr: "invalid reservoir: window cannot be negative",
+		},
+		{
+			name: "negative reservoir max fingerprints",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, MaxFingerprints: -1}
+			},
+			wantErr: "invalid reservoir: max_fingerprints cannot be negative",
+		},
+		{
+			name: "unknown condition operator",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[0].Conditions[0].Operator = "between"
+			},
+			wantErr: "invalid condition 0: invalid operator: between",
+		},
+		{
+			name: "condition without value",
+			modify: func(cfg *Config) {
+				cfg.SamplingRules[0].Conditions[0].Value = nil
+			},
+			wantErr: "value required for operator: gt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
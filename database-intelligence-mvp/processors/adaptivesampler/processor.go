@@ -15,8 +15,10 @@ import (
 	lru "github.com/hashicorp/golang-lru/v2"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
@@ -35,12 +37,17 @@ type adaptiveSampler struct {
 	deduplicationCache *lru.Cache[string, time.Time]
 	ruleLimiters       map[string]*rateLimiter
 	globalRateLimiter  *rateLimiter // Global rate limiter for MaxRecordsPerSecond
+	reservoirs         map[string]*ruleReservoir
 	stateMutex         sync.RWMutex
 
 	// Metrics
 	sampledCount   int64
 	droppedCount   int64
 	duplicateCount int64
+	reservoirCount int64
+
+	// Per-rule evaluated and kept counts and effective sample rates
+	telemetry *samplerTelemetry
 
 	// Shutdown signal
 	shutdownChan chan struct{}
@@ -74,13 +81,18 @@ type adaptiveSamplerMetrics struct {
 
 
 // newAdaptiveSampler creates a new adaptive sampler processor
-func newAdaptiveSampler(cfg *Config, logger *zap.Logger, consumer consumer.Logs) (*adaptiveSampler, error) {
+func newAdaptiveSampler(cfg *Config, logger *zap.Logger, meterProvider metric.MeterProvider, consumer consumer.Logs) (*adaptiveSampler, error) {
 	// Create deduplication cache
 	cache, err := lru.New[string, time.Time](cfg.Deduplication.CacheSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create deduplication cache: %w", err)
 	}
 
+	telemetry, err := newSamplerTelemetry(meterProvider, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampler telemetry: %w", err)
+	}
+
 	// Initialize rule limiters
 	limiters := make(map[string]*rateLimiter)
 	for _, rule := range cfg.SamplingRules {
@@ -109,9 +121,18 @@ func newAdaptiveSampler(cfg *Config, logger *zap.Logger, consumer consumer.Logs)
 		deduplicationCache: cache,
 		ruleLimiters:       limiters,
 		globalRateLimiter:  globalLimiter,
+		reservoirs:         make(map[string]*ruleReservoir),
+		telemetry:          telemetry,
 		shutdownChan:       make(chan struct{}),
 	}
 
+	// Initialize per-rule reservoirs
+	for _, rule := range cfg.SamplingRules {
+		if rule.Reservoir.Size > 0 {
+			processor.reservoirs[rule.Name] = newRuleReservoir(rule.Reservoir, processor.randomIndex)
+		}
+	}
+
 	return processor, nil
 }
 
@@ -128,6 +149,11 @@ func (p *adaptiveSampler) Start(ctx context.Context, host component.Host) error
 	p.wg.Add(1)
 	go p.periodicCleanup()
 
+	for name, reservoir := range p.reservoirs {
+		p.wg.Add(1)
+		go p.periodicReservoirFlush(name, reservoir)
+	}
+
 	// Sort rules by priority (highest first)
 	sort.Slice(p.config.SamplingRules, func(i, j int) bool {
 		return p.config.SamplingRules[i].Priority > p.config.SamplingRules[j].Priority
@@ -143,13 +169,19 @@ func (p *adaptiveSampler) Shutdown(ctx context.Context) error {
 	close(p.shutdownChan)
 	p.wg.Wait()
 
+	// Forward what the reservoirs hold for the unfinished window
+	for name, reservoir := range p.reservoirs {
+		p.flushReservoir(ctx, name, reservoir)
+	}
+
 	// No state persistence needed for in-memory mode
 	p.logger.Info("Adaptive sampler shutdown complete", 
 		zap.Int64("total_sampled", p.sampledCount),
 		zap.Int64("total_dropped", p.droppedCount),
-		zap.Int64("total_duplicates", p.duplicateCount))
+		zap.Int64("total_duplicates", p.duplicateCount),
+		zap.Int64("total_reservoir", p.reservoirCount))
 
-	return nil
+	return p.telemetry.shutdown()
 }
 
 // ConsumeLogs processes log records with adaptive sampling
@@ -180,7 +212,7 @@ func (p *adaptiveSampler) ConsumeLogs(ctx context.Context, logs plog.Logs) error
 				}
 
 				// Apply sampling decision
-				if p.shouldSample(logRecord) {
+				if p.shouldSample(resourceLogs.Resource(), scopeLogs.Scope(), logRecord) {
 					sampledLogRecord := sampledScopeLogs.LogRecords().AppendEmpty()
 					logRecord.CopyTo(sampledLogRecord)
 					p.sampledCount++
@@ -199,8 +231,9 @@ func (p *adaptiveSampler) ConsumeLogs(ctx context.Context, logs plog.Logs) error
 	return nil
 }
 
-// shouldSample determines if a log record should be sampled
-func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
+// shouldSample determines if a log record should be sampled. The resource
+// and scope are kept with records offered to the rule's reservoir.
+func (p *adaptiveSampler) shouldSample(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) bool {
 	// Check for deduplication if enabled
 	if p.config.Deduplication.Enabled {
 		if p.isDuplicate(record) {
@@ -213,7 +246,9 @@ func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
 	rule := p.findMatchingRule(record)
 	if rule == nil {
 		// Use default sample rate
-		return p.randomSample(p.config.DefaultSampleRate)
+		shouldSample := p.randomSample(p.config.DefaultSampleRate)
+		p.telemetry.record(defaultRuleName, shouldSample)
+		return shouldSample
 	}
 
 	// Check rate limiting for this rule
@@ -223,12 +258,19 @@ func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
 				p.logger.Debug("Record dropped due to rate limiting",
 					zap.String("rule", rule.Name))
 			}
+			p.telemetry.record(rule.Name, false)
 			return false
 		}
 	}
 
 	// Apply sampling rate
 	shouldSample := p.randomSample(rule.SampleRate)
+	p.telemetry.record(rule.Name, shouldSample)
+
+	// Records the rate drops may still be kept to cover their fingerprint
+	if reservoir, exists := p.reservoirs[rule.Name]; exists {
+		reservoir.observe(resource, scope, record, shouldSample)
+	}
 
 	if p.config.EnableDebugLogging {
 		p.logger.Debug("Sampling decision",
@@ -492,6 +534,56 @@ func (p *adaptiveSampler) randomSample(rate float64) bool {
 	return random < rate
 }
 
+// randomIndex returns a secure random index in [0, n). If no random number
+// can be generated it returns n, which callers treat as out of range.
+func (p *adaptiveSampler) randomIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		p.logger.Error("Failed to generate secure random number for reservoir sampling", zap.Error(err))
+		return n
+	}
+	return int(i.Int64())
+}
+
+// periodicReservoirFlush forwards a rule's reservoir records at the end of
+// every window
+func (p *adaptiveSampler) periodicReservoirFlush(name string, reservoir *ruleReservoir) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(reservoir.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.flushReservoir(context.Background(), name, reservoir)
+		case <-p.shutdownChan:
+			return
+		}
+	}
+}
+
+// flushReservoir ends the reservoir's window and forwards the records
+// needed to cover its fingerprints
+func (p *adaptiveSampler) flushReservoir(ctx context.Context, name string, reservoir *ruleReservoir) {
+	logs := reservoir.flush()
+	count := logs.LogRecordCount()
+	if count == 0 {
+		return
+	}
+
+	p.reservoirCount += int64(count)
+	p.telemetry.recordReservoir(name, count)
+	if p.config.EnableDebugLogging {
+		p.logger.Debug("Forwarding reservoir samples",
+			zap.String("rule", name),
+			zap.Int("records", count))
+	}
+
+	if err := p.consumer.ConsumeLogs(ctx, logs); err != nil {
+		p.logger.Error("Failed to forward reservoir samples", zap.String("rule", name), zap.Error(err))
+	}
+}
 
 // periodicCleanup cleans up expired cache entries
 func (p *adaptiveSampler) periodicCleanup() {
@@ -18,7 +18,7 @@ func TestNewAdaptiveSampler(t *testing.T) {
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
 	
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	require.NotNil(t, processor)
 }
@@ -44,7 +44,7 @@ func TestAdaptiveSampler_ProcessLogs(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	// Start the processor
@@ -97,7 +97,7 @@ func TestAdaptiveSampler_Deduplication(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -134,7 +134,7 @@ func TestAdaptiveSampler_RateLimiting(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -194,7 +194,7 @@ func TestAdaptiveSampler_MultipleRules(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -0,0 +1,192 @@
+package adaptivesampler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// newReservoirSampler returns a sampler with a single catch-all rule
+func newReservoirSampler(t *testing.T, sampleRate float64, reservoir ReservoirConfig) (*adaptiveSampler, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Deduplication.Enabled = false
+	cfg.SamplingRules = []SamplingRule{
+		{
+			Name:       "default",
+			SampleRate: sampleRate,
+			Reservoir:  reservoir,
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), nil, sink)
+	require.NoError(t, err)
+	return processor, sink
+}
+
+// queryLogs returns one record per fingerprint
+func queryLogs(fingerprints ...string) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "orders-db")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("sqlquery")
+	for _, fingerprint := range fingerprints {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Attributes().PutStr("db.query.fingerprint", fingerprint)
+	}
+	return logs
+}
+
+// fingerprintCounts counts the forwarded records per fingerprint
+func fingerprintCounts(sink *consumertest.LogsSink) map[string]int {
+	counts := make(map[string]int)
+	for _, logs := range sink.AllLogs() {
+		for i := 0; i < logs.ResourceLogs().Len(); i++ {
+			rl := logs.ResourceLogs().At(i)
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				records := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < records.Len(); k++ {
+					fingerprint, _ := records.At(k).Attributes().Get("db.query.fingerprint")
+					counts[fingerprint.AsString()]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestReservoirKeepsRareFingerprint(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0.001, ReservoirConfig{Size: 1, Window: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, processor.ConsumeLogs(ctx, queryLogs("select-orders-by-region")))
+	}
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+
+	// The rate almost never keeps one of three records; the reservoir
+	// supplies the sample when it does not
+	assert.GreaterOrEqual(t, fingerprintCounts(sink)["select-orders-by-region"], 1)
+
+	// The forwarded record keeps its resource and scope
+	forwarded := sink.AllLogs()[len(sink.AllLogs())-1].ResourceLogs().At(0)
+	serviceName, _ := forwarded.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "orders-db", serviceName.AsString())
+	assert.Equal(t, "sqlquery", forwarded.ScopeLogs().At(0).Scope().Name())
+}
+
+func TestReservoirSizePerFingerprint(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0, ReservoirConfig{Size: 2})
+	ctx := context.Background()
+
+	require.NoError(t, processor.ConsumeLogs(ctx, queryLogs("a", "b", "a", "c", "a", "b", "a")))
+	assert.Empty(t, sink.AllLogs(), "records are only forwarded when the window ends")
+
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+	assert.Equal(t, map[string]int{"a": 2, "b": 2, "c": 1}, fingerprintCounts(sink))
+	assert.Equal(t, int64(5), processor.reservoirCount)
+
+	// The next window starts empty
+	sink.Reset()
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+	assert.Empty(t, sink.AllLogs())
+}
+
+func TestReservoirCountsRateSampledRecords(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 1.0, ReservoirConfig{Size: 2})
+	ctx := context.Background()
+
+	require.NoError(t, processor.ConsumeLogs(ctx, queryLogs("a", "a", "a")))
+	sink.Reset()
+
+	// Every record was kept by the rate, so nothing is added
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+	assert.Empty(t, sink.AllLogs())
+}
+
+func TestReservoirIgnoresRecordsWithoutFingerprint(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0, ReservoirConfig{Size: 1})
+	ctx := context.Background()
+
+	logs := queryLogs("a")
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty().Attributes().PutStr("query", "SELECT 1")
+	require.NoError(t, processor.ConsumeLogs(ctx, logs))
+
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+	assert.Equal(t, map[string]int{"a": 1}, fingerprintCounts(sink))
+}
+
+func TestReservoirMaxFingerprints(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0, ReservoirConfig{Size: 1, MaxFingerprints: 2})
+	ctx := context.Background()
+
+	require.NoError(t, processor.ConsumeLogs(ctx, queryLogs("a", "b", "c", "a")))
+
+	processor.flushReservoir(ctx, "default", processor.reservoirs["default"])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1}, fingerprintCounts(sink))
+}
+
+func TestReservoirUniformSample(t *testing.T) {
+	// With a size of one, Algorithm R keeps each of the dropped records with
+	// equal probability
+	reservoir := newRuleReservoir(ReservoirConfig{Size: 1}, nil)
+	logs := queryLogs("a", "a", "a", "a")
+	rl := logs.ResourceLogs().At(0)
+	records := rl.ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		records.At(i).Attributes().PutInt("seq", int64(i))
+	}
+
+	kept := make(map[int64]int)
+	for round := 0; round < 4; round++ {
+		// Pick the replacement index deterministically: the record that
+		// arrives as number round+1 wins the reservoir
+		reservoir.randomIndex = func(n int) int {
+			if n == round+1 {
+				return 0
+			}
+			return n
+		}
+		for i := 0; i < records.Len(); i++ {
+			reservoir.observe(rl.Resource(), rl.ScopeLogs().At(0).Scope(), records.At(i), false)
+		}
+
+		out := reservoir.flush()
+		require.Equal(t, 1, out.LogRecordCount())
+		seq, _ := out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("seq")
+		kept[seq.Int()]++
+	}
+	assert.Equal(t, map[int64]int{0: 1, 1: 1, 2: 1, 3: 1}, kept)
+}
+
+func TestReservoirFlushedOnShutdown(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0, ReservoirConfig{Size: 1, Window: time.Hour})
+	ctx := context.Background()
+	require.NoError(t, processor.Start(ctx, nil))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, processor.ConsumeLogs(ctx, queryLogs(fmt.Sprintf("query-%d", i%2))))
+	}
+	require.NoError(t, processor.Shutdown(ctx))
+
+	assert.Equal(t, map[string]int{"query-0": 1, "query-1": 1}, fingerprintCounts(sink))
+}
+
+func TestReservoirWindowFlush(t *testing.T) {
+	processor, sink := newReservoirSampler(t, 0, ReservoirConfig{Size: 1, Window: 20 * time.Millisecond})
+	ctx := context.Background()
+	require.NoError(t, processor.Start(ctx, nil))
+	defer func() { require.NoError(t, processor.Shutdown(ctx)) }()
+
+	require.NoError(t, processor.ConsumeLogs(ctx, queryLogs("a", "a")))
+	assert.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,211 @@
+package adaptivesampler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	// scopeName is the instrumentation scope of the processor's own metrics
+	scopeName = "github.com/database-intelligence-mvp/processors/adaptivesampler"
+
+	// defaultRuleName labels the records no rule matched, which are sampled
+	// at DefaultSampleRate
+	defaultRuleName = "default_sample_rate"
+
+	// The effective sample rate covers the last effectiveRateWindow, kept in
+	// effectiveRateBuckets slots so old records age out smoothly
+	effectiveRateWindow  = 5 * time.Minute
+	effectiveRateBuckets = 10
+)
+
+// rateBucket counts records for one slot of the rolling window
+type rateBucket struct {
+	start     time.Time
+	evaluated int64
+	kept      int64
+}
+
+// ruleStats tracks one sampling rule
+type ruleStats struct {
+	configuredRate float64
+	attrs          metric.MeasurementOption
+	buckets        [effectiveRateBuckets]rateBucket
+}
+
+// samplerTelemetry reports, per rule, how many records were evaluated and
+// kept, and the kept/evaluated ratio over the rolling window next to the
+// configured rate. Records dropped as duplicates or by the global rate limit
+// never reach a rule and are not counted.
+type samplerTelemetry struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	rules map[string]*ruleStats
+
+	evaluated    metric.Int64Counter
+	kept         metric.Int64Counter
+	registration metric.Registration
+}
+
+// newSamplerTelemetry creates the instruments on meterProvider, which may be
+// nil when the processor is built without telemetry
+func newSamplerTelemetry(meterProvider metric.MeterProvider, cfg *Config) (*samplerTelemetry, error) {
+	if meterProvider == nil {
+		meterProvider = noop.NewMeterProvider()
+	}
+	meter := meterProvider.Meter(scopeName)
+
+	t := &samplerTelemetry{
+		now:   time.Now,
+		rules: make(map[string]*ruleStats),
+	}
+	for _, rule := range cfg.SamplingRules {
+		t.addRule(rule.Name, rule.SampleRate)
+	}
+	t.addRule(defaultRuleName, cfg.DefaultSampleRate)
+
+	var err error
+	if t.evaluated, err = meter.Int64Counter(
+		"processor_adaptivesampler_records_evaluated",
+		metric.WithDescription("Log records a sampling rule decided on"),
+		metric.WithUnit("{records}"),
+	); err != nil {
+		return nil, err
+	}
+	if t.kept, err = meter.Int64Counter(
+		"processor_adaptivesampler_records_kept",
+		metric.WithDescription("Log records a sampling rule kept, including those its reservoir forwarded"),
+		metric.WithUnit("{records}"),
+	); err != nil {
+		return nil, err
+	}
+
+	effective, err := meter.Float64ObservableGauge(
+		"processor_adaptivesampler_effective_sample_rate",
+		metric.WithDescription("Records kept per record evaluated by a sampling rule over the last 5 minutes"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := meter.Float64ObservableGauge(
+		"processor_adaptivesampler_configured_sample_rate",
+		metric.WithDescription("Sample rate configured for a sampling rule"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for _, stats := range t.rules {
+			attrs := stats.attrs.(metric.ObserveOption)
+			o.ObserveFloat64(configured, stats.configuredRate, attrs)
+			if rate, ok := t.effectiveRateLocked(stats); ok {
+				o.ObserveFloat64(effective, rate, attrs)
+			}
+		}
+		return nil
+	}, effective, configured)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *samplerTelemetry) addRule(name string, rate float64) {
+	if _, exists := t.rules[name]; exists {
+		return
+	}
+	t.rules[name] = &ruleStats{
+		configuredRate: rate,
+		attrs:          metric.WithAttributeSet(attribute.NewSet(attribute.String("rule", name))),
+	}
+}
+
+// record counts one sampling decision of the rule
+func (t *samplerTelemetry) record(rule string, kept bool) {
+	var keptCount int64
+	if kept {
+		keptCount = 1
+	}
+	t.add(rule, 1, keptCount)
+}
+
+// recordReservoir counts records the rule's reservoir forwarded. They were
+// evaluated, and dropped, when they arrived.
+func (t *samplerTelemetry) recordReservoir(rule string, count int) {
+	t.add(rule, 0, int64(count))
+}
+
+func (t *samplerTelemetry) add(rule string, evaluated, kept int64) {
+	t.mu.Lock()
+	stats, exists := t.rules[rule]
+	if !exists {
+		t.mu.Unlock()
+		return
+	}
+	bucket := t.bucketLocked(stats)
+	bucket.evaluated += evaluated
+	bucket.kept += kept
+	attrs := stats.attrs.(metric.AddOption)
+	t.mu.Unlock()
+
+	if evaluated > 0 {
+		t.evaluated.Add(context.Background(), evaluated, attrs)
+	}
+	if kept > 0 {
+		t.kept.Add(context.Background(), kept, attrs)
+	}
+}
+
+// bucketLocked returns the current slot of the rule's window, clearing it
+// if it last held an older slot
+func (t *samplerTelemetry) bucketLocked(stats *ruleStats) *rateBucket {
+	width := effectiveRateWindow / effectiveRateBuckets
+	start := t.now().Truncate(width)
+	bucket := &stats.buckets[(start.UnixNano()/int64(width))%effectiveRateBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = rateBucket{start: start}
+	}
+	return bucket
+}
+
+// effectiveRate returns the kept/evaluated ratio of the rule over the
+// rolling window. It reports false when the rule evaluated nothing.
+func (t *samplerTelemetry) effectiveRate(rule string) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats, exists := t.rules[rule]
+	if !exists {
+		return 0, false
+	}
+	return t.effectiveRateLocked(stats)
+}
+
+func (t *samplerTelemetry) effectiveRateLocked(stats *ruleStats) (float64, bool) {
+	cutoff := t.now().Add(-effectiveRateWindow)
+	var evaluated, kept int64
+	for _, bucket := range stats.buckets {
+		if bucket.start.After(cutoff) {
+			evaluated += bucket.evaluated
+			kept += bucket.kept
+		}
+	}
+	if evaluated == 0 {
+		return 0, false
+	}
+	return float64(kept) / float64(evaluated), true
+}
+
+// shutdown unregisters the gauge callback
+func (t *samplerTelemetry) shutdown() error {
+	return t.registration.Unregister()
+}
@@ -0,0 +1,180 @@
+package adaptivesampler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+func TestSamplerTelemetryPerRule(t *testing.T) {
+	tierRule := func(name, tier string, rate float64, priority int) SamplingRule {
+		return SamplingRule{
+			Name:       name,
+			Priority:   priority,
+			SampleRate: rate,
+			Conditions: []SamplingCondition{{Attribute: "tier", Operator: "eq", Value: tier}},
+		}
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Deduplication.Enabled = false
+	cfg.DefaultSampleRate = 1.0
+	limited := tierRule("limited", "silver", 1.0, 10)
+	limited.MaxPerMinute = 5
+	cfg.SamplingRules = []SamplingRule{
+		tierRule("keep_all", "gold", 1.0, 30),
+		tierRule("drop_all", "bronze", 0, 20),
+		limited,
+	}
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), provider, sink)
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for tier, n := range map[string]int{"gold": 10, "bronze": 8, "silver": 7, "": 4} {
+		for i := 0; i < n; i++ {
+			record := records.AppendEmpty()
+			if tier != "" {
+				record.Attributes().PutStr("tier", tier)
+			}
+		}
+	}
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+	assert.Equal(t, 19, sink.LogRecordCount())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	want := map[string]struct {
+		evaluated, kept int64
+		effective       float64
+		configured      float64
+	}{
+		"keep_all":      {10, 10, 1, 1},
+		"drop_all":      {8, 0, 0, 0},
+		"limited":       {7, 5, 5.0 / 7, 1},
+		defaultRuleName: {4, 4, 1, 1},
+	}
+
+	evaluated := sumByRule(t, rm, "processor_adaptivesampler_records_evaluated")
+	kept := sumByRule(t, rm, "processor_adaptivesampler_records_kept")
+	effective := gaugeByRule(t, rm, "processor_adaptivesampler_effective_sample_rate")
+	configured := gaugeByRule(t, rm, "processor_adaptivesampler_configured_sample_rate")
+
+	for rule, w := range want {
+		assert.Equal(t, w.evaluated, evaluated[rule], "evaluated for %s", rule)
+		assert.Equal(t, w.kept, kept[rule], "kept for %s", rule)
+		assert.InDelta(t, w.effective, effective[rule], 1e-9, "effective rate for %s", rule)
+		assert.InDelta(t, w.configured, configured[rule], 1e-9, "configured rate for %s", rule)
+	}
+	_, counted := kept["drop_all"]
+	assert.False(t, counted, "rules that keep nothing report no kept series")
+
+	require.NoError(t, processor.telemetry.shutdown())
+}
+
+func TestSamplerTelemetryReservoir(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SamplingRules = []SamplingRule{{Name: "all", SampleRate: 0}}
+
+	telemetry, err := newSamplerTelemetry(nil, cfg)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		telemetry.record("all", false)
+	}
+	telemetry.recordReservoir("all", 2)
+
+	rate, ok := telemetry.effectiveRate("all")
+	require.True(t, ok)
+	assert.InDelta(t, 0.2, rate, 1e-9, "records the reservoir forwards count as kept")
+
+	// Unknown rules are ignored
+	telemetry.record("missing", true)
+	_, ok = telemetry.effectiveRate("missing")
+	assert.False(t, ok)
+}
+
+func TestSamplerTelemetryWindow(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	telemetry, err := newSamplerTelemetry(nil, cfg)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	telemetry.now = func() time.Time { return now }
+
+	_, ok := telemetry.effectiveRate(defaultRuleName)
+	assert.False(t, ok, "no rate before any record is evaluated")
+
+	// Everything kept at first
+	for i := 0; i < 4; i++ {
+		telemetry.record(defaultRuleName, true)
+	}
+
+	// Then nothing, two minutes later
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 4; i++ {
+		telemetry.record(defaultRuleName, false)
+	}
+	rate, ok := telemetry.effectiveRate(defaultRuleName)
+	require.True(t, ok)
+	assert.InDelta(t, 0.5, rate, 1e-9)
+
+	// The first records age out of the window
+	now = now.Add(4 * time.Minute)
+	rate, ok = telemetry.effectiveRate(defaultRuleName)
+	require.True(t, ok)
+	assert.InDelta(t, 0.0, rate, 1e-9)
+
+	// And then the rest
+	now = now.Add(2 * time.Minute)
+	_, ok = telemetry.effectiveRate(defaultRuleName)
+	assert.False(t, ok)
+}
+
+func sumByRule(t *testing.T, rm metricdata.ResourceMetrics, name string) map[string]int64 {
+	t.Helper()
+	out := make(map[string]int64)
+	for _, sum := range findMetric(t, rm, name).Data.(metricdata.Sum[int64]).DataPoints {
+		rule, _ := sum.Attributes.Value("rule")
+		out[rule.AsString()] = sum.Value
+	}
+	return out
+}
+
+func gaugeByRule(t *testing.T, rm metricdata.ResourceMetrics, name string) map[string]float64 {
+	t.Helper()
+	out := make(map[string]float64)
+	for _, dp := range findMetric(t, rm, name).Data.(metricdata.Gauge[float64]).DataPoints {
+		rule, _ := dp.Attributes.Value("rule")
+		out[rule.AsString()] = dp.Value
+	}
+	return out
+}
+
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	require.Failf(t, "metric not found", "%s", name)
+	return metricdata.Metrics{}
+}
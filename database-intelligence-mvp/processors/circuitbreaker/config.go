@@ -2,6 +2,7 @@ package circuitbreaker
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -56,6 +57,26 @@ type Config struct {
 	
 	// QueryFallbacks define fallback queries for primary queries
 	QueryFallbacks map[string]string `mapstructure:"query_fallbacks"`
+
+	// OpenCircuit defines what happens to data while the circuit is open
+	OpenCircuit OpenCircuitConfig `mapstructure:"open_circuit"`
+}
+
+// OpenCircuitConfig defines the policy for data arriving while the global
+// circuit is open
+type OpenCircuitConfig struct {
+	// Action is "drop" (reject the data), "route_to_secondary" (send it to
+	// SecondaryExporter) or "buffer" (hold it and replay it once the circuit
+	// closes)
+	Action string `mapstructure:"action"`
+
+	// SecondaryExporter is the logs exporter used by route_to_secondary. It
+	// must be part of a logs pipeline.
+	SecondaryExporter component.ID `mapstructure:"secondary_exporter"`
+
+	// BufferSize is the maximum number of batches held by buffer. Batches
+	// arriving when the buffer is full are rejected.
+	BufferSize int `mapstructure:"buffer_size"`
 }
 
 // ErrorPatternConfig defines configuration for error pattern matching
@@ -67,8 +88,28 @@ type ErrorPatternConfig struct {
 	Description string        `mapstructure:"description"`
 }
 
+// applyAliases copies the alias settings into their canonical fields
+func (cfg *Config) applyAliases() {
+	if cfg.MaxConcurrentRequests == 0 && cfg.MaxConcurrent > 0 {
+		cfg.MaxConcurrentRequests = cfg.MaxConcurrent
+	}
+	if cfg.BaseTimeout == 0 && cfg.Timeout > 0 {
+		cfg.BaseTimeout = cfg.Timeout
+	}
+}
+
 // Validate checks the processor configuration
 func (cfg *Config) Validate() error {
+	if cfg.MaxConcurrent < 0 {
+		return fmt.Errorf("max_concurrent cannot be negative, got: %d", cfg.MaxConcurrent)
+	}
+
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative, got: %v", cfg.Timeout)
+	}
+
+	cfg.applyAliases()
+
 	if cfg.FailureThreshold <= 0 {
 		return fmt.Errorf("failure_threshold must be positive, got: %d", cfg.FailureThreshold)
 	}
@@ -109,6 +150,65 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("cpu_threshold_percent must be between 0 and 100, got: %f", cfg.CPUThresholdPercent)
 	}
 
+	for i, pattern := range cfg.ErrorPatterns {
+		if err := pattern.Validate(); err != nil {
+			return fmt.Errorf("invalid error pattern %d: %w", i, err)
+		}
+	}
+
+	for query, fallback := range cfg.QueryFallbacks {
+		if fallback == "" {
+			return fmt.Errorf("query_fallbacks entry for %q cannot be empty", query)
+		}
+	}
+
+	if err := cfg.OpenCircuit.Validate(); err != nil {
+		return fmt.Errorf("invalid open_circuit: %w", err)
+	}
+
+	return nil
+}
+
+// Validate checks the open circuit policy
+func (oc *OpenCircuitConfig) Validate() error {
+	switch oc.Action {
+	case "", OpenCircuitDrop:
+	case OpenCircuitRouteToSecondary:
+		if oc.SecondaryExporter == (component.ID{}) {
+			return fmt.Errorf("secondary_exporter must be specified for action %q", oc.Action)
+		}
+	case OpenCircuitBuffer:
+		if oc.BufferSize <= 0 {
+			return fmt.Errorf("buffer_size must be positive for action %q, got: %d", oc.Action, oc.BufferSize)
+		}
+	default:
+		return fmt.Errorf("action must be '%s', '%s', or '%s', got: %q",
+			OpenCircuitDrop, OpenCircuitRouteToSecondary, OpenCircuitBuffer, oc.Action)
+	}
+
+	return nil
+}
+
+// Validate checks an error pattern
+func (p *ErrorPatternConfig) Validate() error {
+	if p.Pattern == "" {
+		return fmt.Errorf("pattern cannot be empty")
+	}
+
+	if _, err := regexp.Compile(p.Pattern); err != nil {
+		return fmt.Errorf("pattern %q does not compile: %w", p.Pattern, err)
+	}
+
+	switch p.Action {
+	case "disable_query", "use_fallback", "circuit_break":
+	default:
+		return fmt.Errorf("action must be 'disable_query', 'use_fallback', or 'circuit_break', got: %q", p.Action)
+	}
+
+	if p.Backoff < 0 {
+		return fmt.Errorf("backoff cannot be negative, got: %v", p.Backoff)
+	}
+
 	return nil
 }
 
@@ -134,5 +234,9 @@ func createDefaultConfig() component.Config {
 			"quota exceeded",
 			"unique time series",
 		},
+		OpenCircuit: OpenCircuitConfig{
+			Action:     OpenCircuitDrop,
+			BufferSize: 1000,
+		},
 	}
 }
\ No newline at end of file
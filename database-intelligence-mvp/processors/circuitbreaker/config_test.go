@@ -0,0 +1,193 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero failure threshold",
+			modify: func(cfg *Config) {
+				cfg.FailureThreshold = 0
+			},
+			wantErr: "failure_threshold must be positive",
+		},
+		{
+			name: "zero success threshold",
+			modify: func(cfg *Config) {
+				cfg.SuccessThreshold = 0
+			},
+			wantErr: "success_threshold must be positive",
+		},
+		{
+			name: "zero open state timeout",
+			modify: func(cfg *Config) {
+				cfg.OpenStateTimeout = 0
+			},
+			wantErr: "open_state_timeout must be positive",
+		},
+		{
+			name: "max concurrent alias",
+			modify: func(cfg *Config) {
+				cfg.MaxConcurrentRequests = 0
+				cfg.MaxConcurrent = 20
+			},
+		},
+		{
+			name: "no concurrency limit",
+			modify: func(cfg *Config) {
+				cfg.MaxConcurrentRequests = 0
+			},
+			wantErr: "max_concurrent_requests must be positive",
+		},
+		{
+			name: "negative max concurrent alias",
+			modify: func(cfg *Config) {
+				cfg.MaxConcurrent = -1
+			},
+			wantErr: "max_concurrent cannot be negative",
+		},
+		{
+			name: "timeout alias",
+			modify: func(cfg *Config) {
+				cfg.BaseTimeout = 0
+				cfg.Timeout = 10 * time.Second
+			},
+		},
+		{
+			name: "negative timeout alias",
+			modify: func(cfg *Config) {
+				cfg.Timeout = -time.Second
+			},
+			wantErr: "timeout cannot be negative",
+		},
+		{
+			name: "base timeout above max timeout",
+			modify: func(cfg *Config) {
+				cfg.BaseTimeout = time.Minute
+			},
+			wantErr: "base_timeout (1m0s) cannot be greater than max_timeout (30s)",
+		},
+		{
+			name: "zero health check interval",
+			modify: func(cfg *Config) {
+				cfg.HealthCheckInterval = 0
+			},
+			wantErr: "health_check_interval must be positive",
+		},
+		{
+			name: "cpu threshold above 100",
+			modify: func(cfg *Config) {
+				cfg.CPUThresholdPercent = 120
+			},
+			wantErr: "cpu_threshold_percent must be between 0 and 100",
+		},
+		{
+			name: "valid error pattern",
+			modify: func(cfg *Config) {
+				cfg.ErrorPatterns = []ErrorPatternConfig{
+					{Pattern: `extension "pg_stat_statements" does not exist`, Action: "disable_query", Backoff: time.Hour},
+				}
+			},
+		},
+		{
+			name: "empty error pattern",
+			modify: func(cfg *Config) {
+				cfg.ErrorPatterns = []ErrorPatternConfig{{Action: "circuit_break"}}
+			},
+			wantErr: "invalid error pattern 0: pattern cannot be empty",
+		},
+		{
+			name: "error pattern that does not compile",
+			modify: func(cfg *Config) {
+				cfg.ErrorPatterns = []ErrorPatternConfig{{Pattern: "permission denied (", Action: "circuit_break"}}
+			},
+			wantErr: `invalid error pattern 0: pattern "permission denied (" does not compile`,
+		},
+		{
+			name: "unknown error pattern action",
+			modify: func(cfg *Config) {
+				cfg.ErrorPatterns = []ErrorPatternConfig{{Pattern: "timeout", Action: "retry"}}
+			},
+			wantErr: `action must be 'disable_query', 'use_fallback', or 'circuit_break', got: "retry"`,
+		},
+		{
+			name: "negative error pattern backoff",
+			modify: func(cfg *Config) {
+				cfg.ErrorPatterns = []ErrorPatternConfig{{Pattern: "timeout", Action: "circuit_break", Backoff: -time.Second}}
+			},
+			wantErr: "backoff cannot be negative",
+		},
+		{
+			name: "empty query fallback",
+			modify: func(cfg *Config) {
+				cfg.QueryFallbacks = map[string]string{"pg_stat_statements": ""}
+			},
+			wantErr: `query_fallbacks entry for "pg_stat_statements" cannot be empty`,
+		},
+		{
+			name: "open circuit action defaults to drop",
+			modify: func(cfg *Config) {
+				cfg.OpenCircuit = OpenCircuitConfig{}
+			},
+		},
+		{
+			name: "unknown open circuit action",
+			modify: func(cfg *Config) {
+				cfg.OpenCircuit.Action = "retry"
+			},
+			wantErr: `invalid open_circuit: action must be 'drop', 'route_to_secondary', or 'buffer', got: "retry"`,
+		},
+		{
+			name: "route to secondary",
+			modify: func(cfg *Config) {
+				cfg.OpenCircuit.Action = OpenCircuitRouteToSecondary
+				cfg.OpenCircuit.SecondaryExporter = component.MustNewIDWithName("otlp", "fallback")
+			},
+		},
+		{
+			name: "route to secondary without exporter",
+			modify: func(cfg *Config) {
+				cfg.OpenCircuit.Action = OpenCircuitRouteToSecondary
+			},
+			wantErr: `secondary_exporter must be specified for action "route_to_secondary"`,
+		},
+		{
+			name: "buffer without size",
+			modify: func(cfg *Config) {
+				cfg.OpenCircuit.Action = OpenCircuitBuffer
+				cfg.OpenCircuit.BufferSize = 0
+			},
+			wantErr: `buffer_size must be positive for action "buffer", got: 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.uber.org/zap"
+)
+
+// flakyConsumer fails the calls it is told to fail
+type flakyConsumer struct {
+	consumertest.LogsSink
+	calls     int
+	failCalls map[int]bool
+}
+
+func newFlakyConsumer() *flakyConsumer {
+	return &flakyConsumer{failCalls: make(map[int]bool)}
+}
+
+// failCall makes the call with the given 1-based number fail
+func (c *flakyConsumer) failCall(call int) {
+	c.failCalls[call] = true
+}
+
+func (c *flakyConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	c.calls++
+	if c.failCalls[c.calls] {
+		return assert.AnError
+	}
+	return c.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+// newOpenCircuitProcessor returns a processor whose circuit opens after one
+// failure and can be retried shortly after
+func newOpenCircuitProcessor(t *testing.T, openCircuit OpenCircuitConfig, next *flakyConsumer) *circuitBreakerProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FailureThreshold = 1
+	cfg.SuccessThreshold = 1
+	cfg.OpenStateTimeout = 50 * time.Millisecond
+	cfg.OpenCircuit = openCircuit
+	require.NoError(t, cfg.Validate())
+
+	return newCircuitBreakerProcessor(cfg, zap.NewNop(), next)
+}
+
+// openCircuit fails one request so the circuit opens
+func openCircuit(t *testing.T, p *circuitBreakerProcessor, next *flakyConsumer) {
+	next.failCall(next.calls + 1)
+	require.Error(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	require.Equal(t, Open, p.getState())
+}
+
+// waitForHalfOpen waits until the circuit lets the next request through
+func waitForHalfOpen(p *circuitBreakerProcessor) {
+	time.Sleep(p.config.OpenStateTimeout + 20*time.Millisecond)
+}
+
+func TestOpenCircuitDrop(t *testing.T) {
+	next := newFlakyConsumer()
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{Action: OpenCircuitDrop}, next)
+	openCircuit(t, p, next)
+
+	err := p.ConsumeLogs(context.Background(), createTestLogs("orders"))
+	assert.ErrorIs(t, err, errCircuitOpen)
+	assert.Equal(t, OpenCircuitStats{Dropped: 1}, p.openCircuit.snapshot())
+
+	// Once the circuit closes data flows again
+	waitForHalfOpen(p)
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	assert.Equal(t, Closed, p.getState())
+	assert.Equal(t, 1, next.LogRecordCount())
+}
+
+func TestOpenCircuitRouteToSecondary(t *testing.T) {
+	next := newFlakyConsumer()
+	secondary := &consumertest.LogsSink{}
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{
+		Action:            OpenCircuitRouteToSecondary,
+		SecondaryExporter: component.MustNewIDWithName("otlp", "fallback"),
+	}, next)
+	p.openCircuit.secondary = secondary
+	openCircuit(t, p, next)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	assert.Equal(t, 2, secondary.LogRecordCount())
+	assert.Equal(t, 0, next.LogRecordCount())
+	assert.Equal(t, OpenCircuitStats{Routed: 2}, p.openCircuit.snapshot())
+
+	// After recovery the primary consumer gets the data again
+	waitForHalfOpen(p)
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	assert.Equal(t, Closed, p.getState())
+	assert.Equal(t, 1, next.LogRecordCount())
+	assert.Equal(t, 2, secondary.LogRecordCount())
+}
+
+func TestOpenCircuitRouteToSecondaryPerDatabase(t *testing.T) {
+	next := newFlakyConsumer()
+	secondary := &consumertest.LogsSink{}
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{
+		Action:            OpenCircuitRouteToSecondary,
+		SecondaryExporter: component.MustNewIDWithName("otlp", "fallback"),
+	}, next)
+	p.openCircuit.secondary = secondary
+	p.onDatabaseFailure("orders", assert.AnError, time.Millisecond)
+	require.False(t, p.allowDatabaseRequest("orders"))
+	require.True(t, p.allowRequest(), "only the orders circuit is open")
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, db := range []string{"orders", "users", "orders"} {
+		records.AppendEmpty().Attributes().PutStr("database_name", db)
+	}
+	require.NoError(t, p.ConsumeLogs(context.Background(), logs))
+
+	assert.Equal(t, 2, secondary.LogRecordCount(), "the open database's records are routed")
+	assert.Equal(t, 1, next.LogRecordCount(), "the other database's records go to the primary")
+	routed := secondary.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < routed.Len(); i++ {
+		db, _ := routed.At(i).Attributes().Get("database_name")
+		assert.Equal(t, "orders", db.Str())
+	}
+	assert.Equal(t, OpenCircuitStats{Routed: 1}, p.openCircuit.snapshot())
+}
+
+func TestOpenCircuitRouteToSecondaryFailure(t *testing.T) {
+	next := newFlakyConsumer()
+	secondary := newFlakyConsumer()
+	secondary.failCall(1)
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{
+		Action:            OpenCircuitRouteToSecondary,
+		SecondaryExporter: component.MustNewID("otlp"),
+	}, next)
+	p.openCircuit.secondary = secondary
+	openCircuit(t, p, next)
+
+	err := p.ConsumeLogs(context.Background(), createTestLogs("orders"))
+	assert.ErrorIs(t, err, errCircuitOpen)
+	assert.ErrorContains(t, err, "secondary exporter failed")
+	assert.Equal(t, OpenCircuitStats{RouteFailures: 1}, p.openCircuit.snapshot())
+}
+
+func TestOpenCircuitBuffer(t *testing.T) {
+	next := newFlakyConsumer()
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{Action: OpenCircuitBuffer, BufferSize: 2}, next)
+	openCircuit(t, p, next)
+
+	buffered := createTestLogs("orders")
+	require.NoError(t, p.ConsumeLogs(context.Background(), buffered))
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("inventory")))
+
+	// The buffer holds a copy, so the caller may reuse its batch
+	buffered.ResourceLogs().At(0).Resource().Attributes().PutStr("db.name", "reused")
+
+	err := p.ConsumeLogs(context.Background(), createTestLogs("billing"))
+	assert.ErrorIs(t, err, errCircuitOpen)
+	assert.ErrorContains(t, err, "buffer full (2 batches)")
+	assert.Equal(t, 0, next.LogRecordCount())
+
+	// The request that closes the circuit is followed by the buffered ones
+	waitForHalfOpen(p)
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("customers")))
+	assert.Equal(t, Closed, p.getState())
+	assert.Equal(t, []string{"customers", "orders", "inventory"}, databaseNames(next.AllLogs()))
+	assert.Equal(t, OpenCircuitStats{Buffered: 2, BufferOverflows: 1, Replayed: 2}, p.openCircuit.snapshot())
+	assert.Equal(t, 0, p.openCircuit.buffered())
+}
+
+func TestOpenCircuitBufferReplayFailure(t *testing.T) {
+	next := newFlakyConsumer()
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{Action: OpenCircuitBuffer, BufferSize: 10}, next)
+	openCircuit(t, p, next)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("inventory")))
+
+	// The circuit closes, but the first replayed batch fails
+	waitForHalfOpen(p)
+	next.failCall(next.calls + 2)
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("customers")))
+
+	// Both batches are kept, in order, and the failure reopened the circuit
+	assert.Equal(t, 2, p.openCircuit.buffered())
+	assert.Equal(t, Open, p.getState())
+
+	waitForHalfOpen(p)
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("billing")))
+	assert.Equal(t, []string{"customers", "billing", "orders", "inventory"}, databaseNames(next.AllLogs()))
+}
+
+func TestOpenCircuitBufferPerDatabaseReplay(t *testing.T) {
+	next := newFlakyConsumer()
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{Action: OpenCircuitBuffer, BufferSize: 10}, next)
+	p.onDatabaseFailure("orders", assert.AnError, time.Millisecond)
+	require.False(t, p.databaseClosed("orders"))
+
+	databaseLogs := func(dbs ...string) plog.Logs {
+		logs := plog.NewLogs()
+		records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		for _, db := range dbs {
+			records.AppendEmpty().Attributes().PutStr("database_name", db)
+		}
+		return logs
+	}
+
+	// The global circuit is closed, but the orders records stay buffered
+	// while the orders circuit is open
+	require.NoError(t, p.ConsumeLogs(context.Background(), databaseLogs("orders", "users")))
+	require.NoError(t, p.ConsumeLogs(context.Background(), databaseLogs("users")))
+	assert.Equal(t, Closed, p.getState())
+	assert.Equal(t, []string{"users", "users"}, recordDatabases(next.AllLogs()))
+	assert.Equal(t, 1, p.openCircuit.buffered())
+
+	// Once the orders circuit has closed they are replayed
+	p.databaseStates["orders"].state = Closed
+	require.NoError(t, p.ConsumeLogs(context.Background(), databaseLogs("users")))
+	assert.Equal(t, []string{"users", "users", "users", "orders"}, recordDatabases(next.AllLogs()))
+	assert.Equal(t, OpenCircuitStats{Buffered: 1, Replayed: 1}, p.openCircuit.snapshot())
+	assert.Equal(t, 0, p.openCircuit.buffered())
+}
+
+func TestOpenCircuitBufferReplayedOnShutdown(t *testing.T) {
+	next := newFlakyConsumer()
+	p := newOpenCircuitProcessor(t, OpenCircuitConfig{Action: OpenCircuitBuffer, BufferSize: 10}, next)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	openCircuit(t, p, next)
+
+	require.NoError(t, p.ConsumeLogs(context.Background(), createTestLogs("orders")))
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"orders"}, databaseNames(next.AllLogs()))
+}
+
+// exportersHostStub is a host that provides exporters like the service host
+type exportersHostStub struct {
+	component.Host
+	exporters map[component.DataType]map[component.ID]component.Component
+}
+
+func (h *exportersHostStub) GetExporters() map[component.DataType]map[component.ID]component.Component {
+	return h.exporters
+}
+
+// logsExporterStub is a logs exporter
+type logsExporterStub struct {
+	component.StartFunc
+	component.ShutdownFunc
+	consumertest.LogsSink
+}
+
+func TestOpenCircuitSecondaryFromHost(t *testing.T) {
+	fallback := component.MustNewIDWithName("otlp", "fallback")
+	exporter := &logsExporterStub{}
+	host := &exportersHostStub{
+		Host: componenttest.NewNopHost(),
+		exporters: map[component.DataType]map[component.ID]component.Component{
+			component.DataTypeLogs: {fallback: exporter},
+		},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.OpenCircuit = OpenCircuitConfig{Action: OpenCircuitRouteToSecondary, SecondaryExporter: fallback}
+	p, err := createLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+
+	require.NoError(t, p.Start(context.Background(), host))
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	assert.Same(t, exporter, p.(*circuitBreakerProcessor).openCircuit.secondary)
+}
+
+func TestOpenCircuitSecondaryMissing(t *testing.T) {
+	resolve := secondaryExporterFromHost(component.MustNewID("otlp"))
+
+	// Wrapping the host hides any methods beyond component.Host
+	_, err := resolve(struct{ component.Host }{componenttest.NewNopHost()})
+	assert.EqualError(t, err, `host does not provide exporters, cannot route to secondary exporter "otlp"`)
+
+	_, err = resolve(&exportersHostStub{Host: componenttest.NewNopHost()})
+	assert.EqualError(t, err, `secondary exporter "otlp" is not part of a logs pipeline`)
+}
+
+// databaseNames lists the db.name of every batch, in order
+func databaseNames(batches []plog.Logs) []string {
+	var names []string
+	for _, logs := range batches {
+		name, _ := logs.ResourceLogs().At(0).Resource().Attributes().Get("db.name")
+		names = append(names, name.Str())
+	}
+	return names
+}
+
+// recordDatabases lists the database_name of every record, in order
+func recordDatabases(batches []plog.Logs) []string {
+	var names []string
+	for _, logs := range batches {
+		records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			name, _ := records.At(i).Attributes().Get("database_name")
+			names = append(names, name.Str())
+		}
+	}
+	return names
+}
@@ -65,12 +65,7 @@ type CircuitBreaker struct {
 // NewCircuitBreaker creates a new circuit breaker instance
 func NewCircuitBreaker(config *Config, logger *zap.Logger) *CircuitBreaker {
 	// Handle config aliases
-	if config.MaxConcurrentRequests == 0 && config.MaxConcurrent > 0 {
-		config.MaxConcurrentRequests = config.MaxConcurrent
-	}
-	if config.BaseTimeout == 0 && config.Timeout > 0 {
-		config.BaseTimeout = config.Timeout
-	}
+	config.applyAliases()
 
 	cb := &CircuitBreaker{
 		config:         config,
@@ -133,6 +128,10 @@ type circuitBreakerProcessor struct {
 	nrErrors           int64
 	cardinalityWarnings int64
 
+	// Open circuit policy
+	openCircuit       *openCircuitHandler
+	resolveSecondary  func(host component.Host) (consumer.Logs, error)
+
 	// Performance tracking
 	throughputMonitor  *ThroughputMonitor
 	latencyTracker    *LatencyTracker
@@ -162,6 +161,7 @@ func newCircuitBreakerProcessor(cfg *Config, logger *zap.Logger, consumer consum
 	return &circuitBreakerProcessor{
 		CircuitBreaker:    cb,
 		consumer:          consumer,
+		openCircuit:       newOpenCircuitHandler(cfg.OpenCircuit),
 		shutdownChan:      make(chan struct{}),
 		throughputMonitor: NewThroughputMonitor(time.Minute),
 		latencyTracker:    NewLatencyTracker(1000),
@@ -172,7 +172,8 @@ func newCircuitBreakerProcessor(cfg *Config, logger *zap.Logger, consumer consum
 
 // Capabilities returns the capabilities of the processor
 func (p *circuitBreakerProcessor) Capabilities() consumer.Capabilities {
-	return consumer.Capabilities{MutatesData: false}
+	// Records of a database whose circuit is open are taken out of the batch
+	return consumer.Capabilities{MutatesData: true}
 }
 
 // Start starts the processor
@@ -181,7 +182,19 @@ func (p *circuitBreakerProcessor) Start(ctx context.Context, host component.Host
 		zap.Int("failure_threshold", p.config.FailureThreshold),
 		zap.Int("success_threshold", p.config.SuccessThreshold),
 		zap.Duration("open_state_timeout", p.config.OpenStateTimeout),
-		zap.Int("max_concurrent_requests", p.config.MaxConcurrentRequests))
+		zap.Int("max_concurrent_requests", p.config.MaxConcurrentRequests),
+		zap.String("open_circuit_action", p.openCircuit.action))
+
+	if p.openCircuit.action == OpenCircuitRouteToSecondary && p.openCircuit.secondary == nil {
+		if p.resolveSecondary == nil {
+			return fmt.Errorf("open circuit action %q requires a secondary exporter", OpenCircuitRouteToSecondary)
+		}
+		secondary, err := p.resolveSecondary(host)
+		if err != nil {
+			return err
+		}
+		p.openCircuit.secondary = secondary
+	}
 
 	// Start health monitoring
 	p.wg.Add(1)
@@ -199,6 +212,15 @@ func (p *circuitBreakerProcessor) Shutdown(ctx context.Context) error {
 	p.logger.Info("Shutting down circuit breaker processor")
 	close(p.shutdownChan)
 	p.wg.Wait()
+
+	// Last attempt to deliver data buffered while the circuit was open
+	if p.openCircuit.buffered() > 0 {
+		if err := p.openCircuit.replay(ctx, p.consumer, nil); err != nil {
+			p.logger.Error("Discarding batches buffered while the circuit was open",
+				zap.Int("batches", p.openCircuit.buffered()),
+				zap.Error(err))
+		}
+	}
 	return nil
 }
 
@@ -231,28 +253,41 @@ func (p *circuitBreakerProcessor) ConsumeLogs(ctx context.Context, logs plog.Log
 	// Check global circuit state
 	if !p.allowRequest() {
 		p.rejectedRequests++
-		p.logger.Warn("Global circuit breaker open, rejecting request",
+		p.logger.Warn("Global circuit breaker open, applying open circuit action",
 			zap.String("state", p.getState().String()),
+			zap.String("action", p.openCircuit.action),
 			zap.Int64("rejected_requests", p.rejectedRequests))
-		return fmt.Errorf("circuit breaker open")
+		return p.openCircuit.handle(ctx, "", logs)
 	}
 
-	// Check per-database circuit states
+	// Check per-database circuit states; the records of a database whose
+	// circuit is open get the same open circuit action as a whole batch
+	var openErr error
+	forwarded := make([]string, 0, len(databases))
 	for _, dbName := range databases {
-		if !p.allowDatabaseRequest(dbName) {
-			p.rejectedRequests++
-			p.logger.Warn("Database circuit breaker open, rejecting request",
-				zap.String("database", dbName),
-				zap.Int64("rejected_requests", p.rejectedRequests))
-			
-			// Remove logs for this database
-			p.filterLogsForDatabase(logs, dbName)
+		if p.allowDatabaseRequest(dbName) {
+			forwarded = append(forwarded, dbName)
+			continue
+		}
+		p.rejectedRequests++
+		p.logger.Warn("Database circuit breaker open, applying open circuit action",
+			zap.String("database", dbName),
+			zap.String("action", p.openCircuit.action),
+			zap.Int64("rejected_requests", p.rejectedRequests))
+		
+		if err := p.openCircuit.handle(ctx, dbName, p.splitLogsForDatabase(logs, dbName)); err != nil {
+			openErr = err
 		}
 	}
+	// Only the databases still in the batch share its outcome
+	databases = forwarded
 
-	// If all logs were filtered out, return early
+	// If all logs were taken out, report what the open circuit action did
 	if logs.LogRecordCount() == 0 {
-		return nil
+		return openErr
+	}
+	if openErr != nil {
+		p.logger.Debug("Open circuit action failed for part of the batch", zap.Error(openErr))
 	}
 
 	// Acquire semaphore for concurrency control
@@ -318,6 +353,11 @@ func (p *circuitBreakerProcessor) ConsumeLogs(ctx context.Context, logs plog.Log
 	}
 
 	p.onSuccess()
+
+	// Replay data buffered while the circuit was open once it has closed
+	if p.getState() == Closed && p.openCircuit.buffered() > 0 {
+		p.replayBuffered(ctx)
+	}
 	
 	// Update per-database states
 	for _, dbName := range databases {
@@ -332,6 +372,21 @@ func (p *circuitBreakerProcessor) ConsumeLogs(ctx context.Context, logs plog.Log
 	return nil
 }
 
+// replayBuffered sends the batches buffered while the circuit was open,
+// keeping those of a database whose own circuit is still open. A failure
+// counts against the circuit like any other.
+func (p *circuitBreakerProcessor) replayBuffered(ctx context.Context) {
+	if err := p.openCircuit.replay(ctx, p.consumer, p.databaseClosed); err != nil {
+		p.onFailure(err)
+		p.logger.Warn("Replay of buffered batches failed, keeping the rest for later",
+			zap.Int("remaining_batches", p.openCircuit.buffered()),
+			zap.Error(err))
+		return
+	}
+	p.logger.Info("Replayed batches buffered while the circuit was open",
+		zap.Int64("replayed_total", p.openCircuit.snapshot().Replayed))
+}
+
 // allowRequest checks if the request should be allowed
 func (p *circuitBreakerProcessor) allowRequest() bool {
 	p.stateMutex.Lock()
@@ -498,6 +553,7 @@ func (p *circuitBreakerProcessor) checkSystemHealth() {
 
 	// Log comprehensive status
 	state := p.getState()
+	openCircuitStats := p.openCircuit.snapshot()
 	p.logger.Info("Circuit breaker health check",
 		zap.String("state", state.String()),
 		zap.Int("failure_count", p.failureCount),
@@ -509,7 +565,15 @@ func (p *circuitBreakerProcessor) checkSystemHealth() {
 		zap.Duration("latency_p50", p50),
 		zap.Duration("latency_p95", p95),
 		zap.Duration("latency_p99", p99),
-		zap.Any("error_stats", errorStats))
+		zap.Any("error_stats", errorStats),
+		zap.String("open_circuit_action", p.openCircuit.action),
+		zap.Int64("open_circuit_dropped", openCircuitStats.Dropped),
+		zap.Int64("open_circuit_routed", openCircuitStats.Routed),
+		zap.Int64("open_circuit_route_failures", openCircuitStats.RouteFailures),
+		zap.Int64("open_circuit_buffered", openCircuitStats.Buffered),
+		zap.Int64("open_circuit_buffer_overflows", openCircuitStats.BufferOverflows),
+		zap.Int64("open_circuit_replayed", openCircuitStats.Replayed),
+		zap.Int("open_circuit_buffer_len", p.openCircuit.buffered()))
 	
 	// Log per-database states if any are not closed
 	p.dbStatesMutex.RLock()
@@ -577,6 +641,22 @@ func (p *circuitBreakerProcessor) extractDatabaseInfo(logs plog.Logs) []string {
 	return result
 }
 
+// databaseClosed reports whether the circuit of a database is closed,
+// without moving an open circuit to half-open
+func (p *circuitBreakerProcessor) databaseClosed(dbName string) bool {
+	p.dbStatesMutex.RLock()
+	state, exists := p.databaseStates[dbName]
+	p.dbStatesMutex.RUnlock()
+
+	if !exists {
+		return true
+	}
+
+	state.mutex.RLock()
+	defer state.mutex.RUnlock()
+	return state.state == Closed
+}
+
 // allowDatabaseRequest checks if requests for a specific database should be allowed
 func (p *circuitBreakerProcessor) allowDatabaseRequest(dbName string) bool {
 	p.dbStatesMutex.RLock()
@@ -610,28 +690,46 @@ func (p *circuitBreakerProcessor) allowDatabaseRequest(dbName string) bool {
 	}
 }
 
-// filterLogsForDatabase removes logs for a specific database
-func (p *circuitBreakerProcessor) filterLogsForDatabase(logs plog.Logs, dbName string) {
+// splitLogsForDatabase moves the records of a specific database out of logs
+// and returns them, keeping their resource and scope
+func (p *circuitBreakerProcessor) splitLogsForDatabase(logs plog.Logs, dbName string) plog.Logs {
+	split := plog.NewLogs()
+	isDatabase := func(record plog.LogRecord) bool {
+		db, ok := record.Attributes().Get("database_name")
+		return ok && db.Str() == dbName
+	}
+	
 	for i := 0; i < logs.ResourceLogs().Len(); i++ {
 		rl := logs.ResourceLogs().At(i)
+		var splitRL plog.ResourceLogs
+		hasRL := false
 		for j := 0; j < rl.ScopeLogs().Len(); j++ {
 			sl := rl.ScopeLogs().At(j)
-			
-			// Filter log records
-			filtered := sl.LogRecords()
-			for k := filtered.Len() - 1; k >= 0; k-- {
-				lr := filtered.At(k)
-				if db, exists := lr.Attributes().Get("database_name"); exists && db.Str() == dbName {
-					filtered.RemoveIf(func(record plog.LogRecord) bool {
-						if recordDB, ok := record.Attributes().Get("database_name"); ok {
-							return recordDB.Str() == dbName
-						}
-						return false
-					})
+			var splitSL plog.ScopeLogs
+			hasSL := false
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+				if !isDatabase(lr) {
+					continue
+				}
+				if !hasRL {
+					splitRL = split.ResourceLogs().AppendEmpty()
+					rl.Resource().CopyTo(splitRL.Resource())
+					splitRL.SetSchemaUrl(rl.SchemaUrl())
+					hasRL = true
+				}
+				if !hasSL {
+					splitSL = splitRL.ScopeLogs().AppendEmpty()
+					sl.Scope().CopyTo(splitSL.Scope())
+					splitSL.SetSchemaUrl(sl.SchemaUrl())
+					hasSL = true
 				}
+				lr.CopyTo(splitSL.LogRecords().AppendEmpty())
 			}
+			sl.LogRecords().RemoveIf(isDatabase)
 		}
 	}
+	return split
 }
 
 // onDatabaseFailure handles failures for a specific database
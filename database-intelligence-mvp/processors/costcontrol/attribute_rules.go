@@ -0,0 +1,180 @@
+package costcontrol
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// attributeRule is a configured AttributeRule ready to apply
+type attributeRule struct {
+	AttributeRule
+	name    string
+	metrics map[string]bool
+}
+
+func newAttributeRules(rules []AttributeRule) []attributeRule {
+	compiled := make([]attributeRule, 0, len(rules))
+	for _, rule := range rules {
+		var metrics map[string]bool
+		if len(rule.Metrics) > 0 {
+			metrics = make(map[string]bool, len(rule.Metrics))
+			for _, name := range rule.Metrics {
+				metrics[name] = true
+			}
+		}
+		compiled = append(compiled, attributeRule{
+			AttributeRule: rule,
+			name:          rule.ruleName(),
+			metrics:       metrics,
+		})
+	}
+	return compiled
+}
+
+// appliesTo reports whether the rule rewrites the named metric
+func (r *attributeRule) appliesTo(metricName string) bool {
+	return r.metrics == nil || r.metrics[metricName]
+}
+
+// rewrite applies the rule to one data point's attributes
+func (r *attributeRule) rewrite(attrs pcommon.Map) {
+	value, exists := attrs.Get(r.Attribute)
+	if !exists {
+		return
+	}
+
+	switch r.Action {
+	case AttributeActionDrop:
+		attrs.Remove(r.Attribute)
+	case AttributeActionHash:
+		attrs.PutStr(r.Attribute, r.hash(value.AsString()))
+	case AttributeActionBucket:
+		if bucket, ok := r.bucket(value); ok {
+			attrs.PutStr(r.Attribute, bucket)
+		}
+	}
+}
+
+// hash returns the value's hash, or its hash bucket when hash_buckets is set
+func (r *attributeRule) hash(value string) string {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	sum := h.Sum64()
+
+	if r.HashBuckets > 0 {
+		return "bucket_" + strconv.FormatUint(sum%uint64(r.HashBuckets), 10)
+	}
+	return fmt.Sprintf("%016x", sum)
+}
+
+// bucket returns the label of the first bucket holding a numeric value, such
+// as "<=100", or ">1000" past the last bound. Non-numeric values are left
+// alone.
+func (r *attributeRule) bucket(value pcommon.Value) (string, bool) {
+	var number float64
+	switch value.Type() {
+	case pcommon.ValueTypeInt:
+		number = float64(value.Int())
+	case pcommon.ValueTypeDouble:
+		number = value.Double()
+	case pcommon.ValueTypeStr:
+		parsed, err := strconv.ParseFloat(value.Str(), 64)
+		if err != nil {
+			return "", false
+		}
+		number = parsed
+	default:
+		return "", false
+	}
+
+	for _, bound := range r.Buckets {
+		if number <= bound {
+			return "<=" + strconv.FormatFloat(bound, 'f', -1, 64), true
+		}
+	}
+	return ">" + strconv.FormatFloat(r.Buckets[len(r.Buckets)-1], 'f', -1, 64), true
+}
+
+// applyAttributeRules rewrites metric attributes with the configured rules
+// before cardinality is counted, and records how many series each rule
+// removed from the batch. A series is a metric name plus its attribute set.
+func (p *costControlProcessor) applyAttributeRules(md pmetric.Metrics) {
+	for i := range p.attributeRules {
+		rule := &p.attributeRules[i]
+		before := make(map[string]struct{})
+		after := make(map[string]struct{})
+
+		rms := md.ResourceMetrics()
+		for j := 0; j < rms.Len(); j++ {
+			sms := rms.At(j).ScopeMetrics()
+			for k := 0; k < sms.Len(); k++ {
+				metrics := sms.At(k).Metrics()
+				for l := 0; l < metrics.Len(); l++ {
+					metric := metrics.At(l)
+					if !rule.appliesTo(metric.Name()) {
+						continue
+					}
+					forEachDataPointAttributes(metric, func(attrs pcommon.Map) {
+						before[metric.Name()+"|"+getAttributeKey(attrs)] = struct{}{}
+						rule.rewrite(attrs)
+						after[metric.Name()+"|"+getAttributeKey(attrs)] = struct{}{}
+					})
+				}
+			}
+		}
+
+		if saved := len(before) - len(after); saved > 0 {
+			p.mutex.Lock()
+			p.attributeRuleSavings[rule.name] += int64(saved)
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// attributeRuleReport returns the series each attribute rule has removed
+func (p *costControlProcessor) attributeRuleReport() map[string]int64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	report := make(map[string]int64, len(p.attributeRules))
+	for _, rule := range p.attributeRules {
+		report[rule.name] = p.attributeRuleSavings[rule.name]
+	}
+	return report
+}
+
+// forEachDataPointAttributes calls fn with the attributes of every data point
+// of the metric
+func forEachDataPointAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
@@ -33,6 +33,83 @@ type Config struct {
 	
 	// DataPlusEnabled indicates if using New Relic Data Plus
 	DataPlusEnabled bool `mapstructure:"data_plus_enabled"`
+	
+	// AttributeRules rewrite metric attributes before cardinality is counted,
+	// so series collapse instead of being shed
+	AttributeRules []AttributeRule `mapstructure:"attribute_rules"`
+	
+	// LedgerPath is a file month-to-date spend is saved to every reporting
+	// interval and restored from on start, so the monthly budget holds
+	// across restarts. Empty keeps spend in memory only.
+	LedgerPath string `mapstructure:"ledger_path"`
+}
+
+// AttributeRule drops, hashes or buckets one metric attribute
+type AttributeRule struct {
+	// Name identifies the rule in the cost report
+	// Default: <action>_<attribute>
+	Name string `mapstructure:"name"`
+	
+	// Attribute is the data point attribute the rule rewrites
+	Attribute string `mapstructure:"attribute"`
+	
+	// Action is drop, hash or bucket
+	Action string `mapstructure:"action"`
+	
+	// Metrics limits the rule to these metric names; empty applies it to all
+	Metrics []string `mapstructure:"metrics"`
+	
+	// HashBuckets, for the hash action, maps values onto this many buckets.
+	// When 0 every value is replaced by its own hash, which shortens values
+	// but keeps cardinality.
+	HashBuckets int `mapstructure:"hash_buckets"`
+	
+	// Buckets, for the bucket action, are the ascending upper bounds numeric
+	// values are grouped into
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+// Attribute rule actions
+const (
+	AttributeActionDrop   = "drop"
+	AttributeActionHash   = "hash"
+	AttributeActionBucket = "bucket"
+)
+
+// ruleName returns the name the rule is reported under
+func (r AttributeRule) ruleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Action + "_" + r.Attribute
+}
+
+// Validate checks an attribute rule
+func (r AttributeRule) Validate() error {
+	if r.Attribute == "" {
+		return fmt.Errorf("attribute must be set")
+	}
+	
+	switch r.Action {
+	case AttributeActionDrop:
+	case AttributeActionHash:
+		if r.HashBuckets < 0 {
+			return fmt.Errorf("hash_buckets must not be negative")
+		}
+	case AttributeActionBucket:
+		if len(r.Buckets) == 0 {
+			return fmt.Errorf("buckets must be set for the bucket action")
+		}
+		for i := 1; i < len(r.Buckets); i++ {
+			if r.Buckets[i] <= r.Buckets[i-1] {
+				return fmt.Errorf("buckets must be in ascending order")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action %q, must be drop, hash or bucket", r.Action)
+	}
+	
+	return nil
 }
 
 // Validate checks the processor configuration
@@ -61,6 +138,17 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("reporting_interval must be positive")
 	}
 	
+	names := make(map[string]bool, len(cfg.AttributeRules))
+	for i, rule := range cfg.AttributeRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("attribute_rules[%d]: %w", i, err)
+		}
+		if names[rule.ruleName()] {
+			return fmt.Errorf("attribute_rules[%d]: duplicate rule name %q", i, rule.ruleName())
+		}
+		names[rule.ruleName()] = true
+	}
+	
 	return nil
 }
 
@@ -0,0 +1,141 @@
+package costcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero monthly budget",
+			modify: func(cfg *Config) {
+				cfg.MonthlyBudgetUSD = 0
+			},
+			wantErr: "monthly_budget_usd must be positive",
+		},
+		{
+			name: "negative price per GB",
+			modify: func(cfg *Config) {
+				cfg.PricePerGB = -0.35
+			},
+			wantErr: "price_per_gb must be positive",
+		},
+		{
+			name: "zero cardinality limit",
+			modify: func(cfg *Config) {
+				cfg.MetricCardinalityLimit = 0
+			},
+			wantErr: "metric_cardinality_limit must be positive",
+		},
+		{
+			name: "zero slow span threshold",
+			modify: func(cfg *Config) {
+				cfg.SlowSpanThresholdMs = 0
+			},
+			wantErr: "slow_span_threshold_ms must be positive",
+		},
+		{
+			name: "zero max log body size",
+			modify: func(cfg *Config) {
+				cfg.MaxLogBodySize = 0
+			},
+			wantErr: "max_log_body_size must be positive",
+		},
+		{
+			name: "zero reporting interval",
+			modify: func(cfg *Config) {
+				cfg.ReportingInterval = 0
+			},
+			wantErr: "reporting_interval must be positive",
+		},
+		{
+			name: "negative reporting interval",
+			modify: func(cfg *Config) {
+				cfg.ReportingInterval = -time.Minute
+			},
+			wantErr: "reporting_interval must be positive",
+		},
+		{
+			name: "valid attribute rules",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{
+					{Attribute: "unique_id", Action: AttributeActionDrop},
+					{Attribute: "user.id", Action: AttributeActionHash, HashBuckets: 64},
+					{Attribute: "rows", Action: AttributeActionBucket, Buckets: []float64{10, 100, 1000}},
+				}
+			},
+		},
+		{
+			name: "attribute rule without attribute",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{{Action: AttributeActionDrop}}
+			},
+			wantErr: "attribute_rules[0]: attribute must be set",
+		},
+		{
+			name: "unknown attribute rule action",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{{Attribute: "unique_id", Action: "rename"}}
+			},
+			wantErr: `unknown action "rename"`,
+		},
+		{
+			name: "negative hash buckets",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{{Attribute: "user.id", Action: AttributeActionHash, HashBuckets: -1}}
+			},
+			wantErr: "hash_buckets must not be negative",
+		},
+		{
+			name: "bucket action without buckets",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{{Attribute: "rows", Action: AttributeActionBucket}}
+			},
+			wantErr: "buckets must be set",
+		},
+		{
+			name: "unordered buckets",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{{Attribute: "rows", Action: AttributeActionBucket, Buckets: []float64{100, 10}}}
+			},
+			wantErr: "buckets must be in ascending order",
+		},
+		{
+			name: "duplicate rule names",
+			modify: func(cfg *Config) {
+				cfg.AttributeRules = []AttributeRule{
+					{Attribute: "unique_id", Action: AttributeActionDrop},
+					{Attribute: "unique_id", Action: AttributeActionDrop, Metrics: []string{"db.custom.metric"}},
+				}
+			},
+			wantErr: `duplicate rule name "drop_unique_id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -61,6 +61,7 @@ func createTracesProcessor(
 
 	processor := newCostControlProcessor(processorConfig, set.Logger)
 	processor.nextTraces = nextConsumer
+	processor.ledgerKey = set.ID.String() + "/traces"
 
 	return processor, nil
 }
@@ -83,6 +84,7 @@ func createMetricsProcessor(
 
 	processor := newCostControlProcessor(processorConfig, set.Logger)
 	processor.nextMetrics = nextConsumer
+	processor.ledgerKey = set.ID.String() + "/metrics"
 
 	return processor, nil
 }
@@ -105,16 +107,25 @@ func createLogsProcessor(
 
 	processor := newCostControlProcessor(processorConfig, set.Logger)
 	processor.nextLogs = nextConsumer
+	processor.ledgerKey = set.ID.String() + "/logs"
 
 	return processor, nil
 }
 
 // newCostControlProcessor creates a new cost control processor instance
 func newCostControlProcessor(config *Config, logger *zap.Logger) *costControlProcessor {
-	return &costControlProcessor{
-		config:            config,
-		logger:            logger,
-		costTracker:       &costTracker{currentMonth: time.Now()},
-		metricCardinality: make(map[string]*cardinalityTracker),
+	p := &costControlProcessor{
+		config:               config,
+		logger:               logger,
+		costTracker:          &costTracker{currentMonth: monthStart(time.Now())},
+		ledgerKey:            TypeStr,
+		now:                  time.Now,
+		metricCardinality:    make(map[string]*cardinalityTracker),
+		attributeRules:       newAttributeRules(config.AttributeRules),
+		attributeRuleSavings: make(map[string]int64),
 	}
+	if config.LedgerPath != "" {
+		p.ledger = newFileLedger(config.LedgerPath)
+	}
+	return p
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+package costcontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ledgerVersion is the format version written to ledger files
+const ledgerVersion = 1
+
+// monthKeyLayout formats the calendar month an entry belongs to, in UTC
+const monthKeyLayout = "2006-01"
+
+// errCorruptLedger is returned by Load when the stored ledger cannot be
+// trusted. The caller starts the month from zero.
+var errCorruptLedger = errors.New("corrupt spend ledger")
+
+// ledgerEntry is the month-to-date spend of one processor instance
+type ledgerEntry struct {
+	Month            string    `json:"month"`
+	BytesIngested    int64     `json:"bytes_ingested"`
+	EstimatedCostUSD float64   `json:"estimated_cost_usd"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// validate checks the values a restored entry feeds into budget decisions
+func (e ledgerEntry) validate() error {
+	if _, err := time.Parse(monthKeyLayout, e.Month); err != nil {
+		return fmt.Errorf("invalid month %q", e.Month)
+	}
+	if e.BytesIngested < 0 || e.EstimatedCostUSD < 0 {
+		return fmt.Errorf("negative spend for %s", e.Month)
+	}
+	if e.UpdatedAt.IsZero() {
+		return fmt.Errorf("missing updated_at for %s", e.Month)
+	}
+	return nil
+}
+
+// spendLedger persists month-to-date spend so the monthly budget holds
+// across collector restarts. Entries are keyed by processor instance, since
+// each pipeline's instance tracks its own data.
+type spendLedger interface {
+	// Load returns the entry for key, reporting false if there is none
+	Load(key string) (ledgerEntry, bool, error)
+
+	// Save replaces the entry for key
+	Save(key string, entry ledgerEntry) error
+}
+
+// ledgerFile is the on-disk format of fileLedger
+type ledgerFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]ledgerEntry `json:"entries"`
+}
+
+// fileLedgerLocks serializes access to each ledger file, which the traces,
+// metrics and logs instances of the processor share
+var fileLedgerLocks sync.Map
+
+// fileLedger keeps the ledger in a JSON file. Writes go to a temporary file
+// that is renamed over the ledger, so a crash leaves the old or the new
+// version, never a partial one.
+type fileLedger struct {
+	path string
+	mu   *sync.Mutex
+}
+
+func newFileLedger(path string) *fileLedger {
+	path = filepath.Clean(path)
+	mu, _ := fileLedgerLocks.LoadOrStore(path, &sync.Mutex{})
+	return &fileLedger{path: path, mu: mu.(*sync.Mutex)}
+}
+
+// Load reads the entry for key. A ledger that cannot be parsed, or holds
+// invalid values, is renamed aside so it is kept for inspection and not
+// overwritten, and errCorruptLedger is returned.
+func (l *fileLedger) Load(key string) (ledgerEntry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := l.read()
+	if err != nil {
+		return ledgerEntry{}, false, err
+	}
+	entry, found := file.Entries[key]
+	return entry, found, nil
+}
+
+// Save replaces the entry for key, keeping the entries of other instances
+func (l *fileLedger) Save(key string, entry ledgerEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := l.read()
+	if err != nil && !errors.Is(err, errCorruptLedger) {
+		return err
+	}
+	file.Entries[key] = entry
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode spend ledger: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	return nil
+}
+
+// read loads the ledger file, returning an empty ledger if it does not exist
+func (l *fileLedger) read() (ledgerFile, error) {
+	empty := ledgerFile{Version: ledgerVersion, Entries: make(map[string]ledgerEntry)}
+
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return empty, fmt.Errorf("failed to read spend ledger: %w", err)
+	}
+
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return empty, l.quarantine(err)
+	}
+	if file.Version != ledgerVersion {
+		return empty, l.quarantine(fmt.Errorf("unsupported version %d", file.Version))
+	}
+	if file.Entries == nil {
+		file.Entries = make(map[string]ledgerEntry)
+	}
+	for key, entry := range file.Entries {
+		if err := entry.validate(); err != nil {
+			return empty, l.quarantine(fmt.Errorf("entry %q: %w", key, err))
+		}
+	}
+	return file, nil
+}
+
+// quarantine renames a corrupt ledger to <path>.corrupt-<unix seconds>
+func (l *fileLedger) quarantine(cause error) error {
+	aside := fmt.Sprintf("%s.corrupt-%d", l.path, time.Now().Unix())
+	if err := os.Rename(l.path, aside); err != nil {
+		return fmt.Errorf("%w: %v (could not move it aside: %v)", errCorruptLedger, cause, err)
+	}
+	return fmt.Errorf("%w: %v (moved to %s)", errCorruptLedger, cause, aside)
+}
+
+// monthStart returns the first instant of t's calendar month in UTC
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,265 @@
+package costcontrol
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const gigabyte = 1024 * 1024 * 1024
+
+// newLedgerProcessor creates a processor with a file ledger and a clock the
+// test sets through *now
+func newLedgerProcessor(t *testing.T, path string, now *time.Time) *costControlProcessor {
+	t.Helper()
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.MonthlyBudgetUSD = 30
+	cfg.LedgerPath = path
+
+	p := newCostControlProcessor(cfg, zap.NewNop())
+	p.ledgerKey = "costcontrol/metrics"
+	p.now = func() time.Time { return *now }
+	p.costTracker.currentMonth = monthStart(*now)
+	return p
+}
+
+func TestFileLedgerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	ledger := newFileLedger(path)
+
+	_, found, err := ledger.Load("costcontrol/metrics")
+	require.NoError(t, err)
+	assert.False(t, found, "a missing file is an empty ledger")
+
+	updated := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	metrics := ledgerEntry{Month: "2024-05", BytesIngested: 2 * gigabyte, EstimatedCostUSD: 0.7, UpdatedAt: updated}
+	logs := ledgerEntry{Month: "2024-05", BytesIngested: gigabyte, EstimatedCostUSD: 0.35, UpdatedAt: updated}
+	require.NoError(t, ledger.Save("costcontrol/metrics", metrics))
+	require.NoError(t, ledger.Save("costcontrol/logs", logs))
+
+	// The traces, metrics and logs instances share the file
+	got, found, err := newFileLedger(path).Load("costcontrol/metrics")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, metrics, got)
+	got, _, err = newFileLedger(path).Load("costcontrol/logs")
+	require.NoError(t, err)
+	assert.Equal(t, logs, got)
+
+	var file ledgerFile
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &file))
+	assert.Equal(t, ledgerVersion, file.Version)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestFileLedgerCorrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"truncated", `{"version": 1, "entries": {"costcontrol/metrics": {"month": "2024-`},
+		{"unknown version", `{"version": 7, "entries": {}}`},
+		{"negative spend", `{"version": 1, "entries": {"costcontrol/metrics": {"month": "2024-05", "bytes_ingested": -1, "estimated_cost_usd": 1, "updated_at": "2024-05-10T12:00:00Z"}}}`},
+		{"bad month", `{"version": 1, "entries": {"costcontrol/metrics": {"month": "May", "bytes_ingested": 1, "estimated_cost_usd": 1, "updated_at": "2024-05-10T12:00:00Z"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "ledger.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			ledger := newFileLedger(path)
+			_, _, err := ledger.Load("costcontrol/metrics")
+			require.ErrorIs(t, err, errCorruptLedger)
+
+			// The corrupt file is kept aside and a fresh ledger starts
+			aside, err := filepath.Glob(path + ".corrupt-*")
+			require.NoError(t, err)
+			require.Len(t, aside, 1)
+			kept, err := os.ReadFile(aside[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(kept))
+
+			_, found, err := ledger.Load("costcontrol/metrics")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestSpendRestoredAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	first := newLedgerProcessor(t, path, &now)
+	require.NoError(t, first.Start(context.Background(), nil))
+	first.updateCostTracking(20*gigabyte, "metrics") // $7 by the 10th
+	assert.False(t, first.isOverBudget())
+	require.NoError(t, first.Shutdown(context.Background()))
+
+	// A restarted processor continues from the saved spend, and the same
+	// ingest now pushes the month over the $30 budget
+	now = now.Add(time.Hour)
+	second := newLedgerProcessor(t, path, &now)
+	require.NoError(t, second.Start(context.Background(), nil))
+	defer second.Shutdown(context.Background())
+
+	assert.Equal(t, int64(20*gigabyte), second.costTracker.bytesIngested)
+	assert.InDelta(t, 7.0, second.costTracker.estimatedCostUSD, 1e-9)
+	assert.Equal(t, monthStart(now), second.costTracker.currentMonth)
+
+	second.updateCostTracking(20*gigabyte, "metrics")
+	assert.InDelta(t, 14.0, second.costTracker.estimatedCostUSD, 1e-9)
+	assert.True(t, second.isOverBudget())
+
+	// Without a ledger the restart would have started from zero
+	now = now.Add(time.Hour)
+	memoryOnly := newLedgerProcessor(t, "", &now)
+	require.NoError(t, memoryOnly.Start(context.Background(), nil))
+	defer memoryOnly.Shutdown(context.Background())
+	assert.Zero(t, memoryOnly.costTracker.estimatedCostUSD)
+}
+
+func TestSpendRollsOverAtMonthEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	now := time.Date(2024, 5, 31, 23, 50, 0, 0, time.UTC)
+
+	p := newLedgerProcessor(t, path, &now)
+	require.NoError(t, p.Start(context.Background(), nil))
+	defer p.Shutdown(context.Background())
+
+	p.updateCostTracking(100*gigabyte, "metrics") // $35, over the $30 budget
+	assert.True(t, p.isOverBudget())
+	p.checkpointSpend()
+
+	// The first checkpoint of June closes May, even without new data
+	now = time.Date(2024, 6, 1, 0, 5, 0, 0, time.UTC)
+	p.checkpointSpend()
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), p.costTracker.currentMonth)
+	assert.Zero(t, p.costTracker.estimatedCostUSD)
+	assert.False(t, p.isOverBudget(), "the new month starts within budget")
+
+	entry, found, err := newFileLedger(path).Load(p.ledgerKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "2024-06", entry.Month)
+	assert.Zero(t, entry.BytesIngested)
+
+	// A new month is also started by data arriving
+	now = time.Date(2024, 7, 2, 8, 0, 0, 0, time.UTC)
+	p.updateCostTracking(gigabyte, "metrics")
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), p.costTracker.currentMonth)
+	assert.InDelta(t, 0.35, p.costTracker.estimatedCostUSD, 1e-9)
+}
+
+func TestSpendFromEarlierMonthIsNotRestored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	require.NoError(t, newFileLedger(path).Save("costcontrol/metrics", ledgerEntry{
+		Month:            "2024-04",
+		BytesIngested:    40 * gigabyte,
+		EstimatedCostUSD: 14,
+		UpdatedAt:        time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC),
+	}))
+
+	now := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	p := newLedgerProcessor(t, path, &now)
+	require.NoError(t, p.Start(context.Background(), nil))
+	defer p.Shutdown(context.Background())
+
+	assert.Equal(t, monthStart(now), p.costTracker.currentMonth)
+	assert.Zero(t, p.costTracker.estimatedCostUSD)
+}
+
+func TestSpendWithClockSkew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, newFileLedger(path).Save("costcontrol/metrics", ledgerEntry{
+		Month:            "2024-06",
+		BytesIngested:    gigabyte,
+		EstimatedCostUSD: 0.35,
+		UpdatedAt:        june.Add(5 * time.Minute),
+	}))
+
+	// The clock is a few minutes behind the one that wrote the ledger, on
+	// the other side of the month boundary: June is not reopened as May
+	now := june.Add(-2 * time.Minute)
+	p := newLedgerProcessor(t, path, &now)
+	require.NoError(t, p.Start(context.Background(), nil))
+	defer p.Shutdown(context.Background())
+
+	assert.Equal(t, june, p.costTracker.currentMonth)
+	assert.InDelta(t, 0.35, p.costTracker.estimatedCostUSD, 1e-9)
+
+	// Nor by data arriving before the clock catches up
+	now = now.Add(time.Minute)
+	p.updateCostTracking(gigabyte, "metrics")
+	assert.Equal(t, june, p.costTracker.currentMonth)
+	assert.InDelta(t, 0.7, p.costTracker.estimatedCostUSD, 1e-9)
+
+	// A ledger from months ahead was written by a wrong clock and is ignored
+	require.NoError(t, newFileLedger(path).Save("costcontrol/metrics", ledgerEntry{
+		Month:            "2024-09",
+		BytesIngested:    gigabyte,
+		EstimatedCostUSD: 0.35,
+		UpdatedAt:        time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC),
+	}))
+	restarted := newLedgerProcessor(t, path, &now)
+	require.NoError(t, restarted.Start(context.Background(), nil))
+	defer restarted.Shutdown(context.Background())
+	assert.Equal(t, monthStart(now), restarted.costTracker.currentMonth)
+	assert.Zero(t, restarted.costTracker.estimatedCostUSD)
+}
+
+func TestCorruptLedgerStartsMonthFromZero(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	p := newLedgerProcessor(t, path, &now)
+	require.NoError(t, p.Start(context.Background(), nil))
+	assert.Zero(t, p.costTracker.estimatedCostUSD)
+
+	p.updateCostTracking(gigabyte, "metrics")
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	entry, found, err := newFileLedger(path).Load(p.ledgerKey)
+	require.NoError(t, err)
+	require.True(t, found, "a fresh ledger replaces the corrupt one")
+	assert.InDelta(t, 0.35, entry.EstimatedCostUSD, 1e-9)
+}
+
+func TestUnreadableLedgerFailsStart(t *testing.T) {
+	// A directory where the ledger should be cannot be read or replaced
+	path := t.TempDir()
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	p := newLedgerProcessor(t, path, &now)
+
+	err := p.Start(context.Background(), nil)
+	assert.ErrorContains(t, err, "failed to read spend ledger")
+}
+
+func TestProjection(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	p := newLedgerProcessor(t, "", &now)
+
+	p.updateCostTracking(20*gigabyte, "metrics") // $7 in 9 of June's 30 days
+	assert.InDelta(t, 7.0*30/9, p.costTracker.projectedCostUSD, 1e-9)
+
+	// The first day is extrapolated as a full day
+	now = time.Date(2024, 7, 1, 0, 10, 0, 0, time.UTC)
+	p.updateCostTracking(gigabyte, "metrics")
+	assert.InDelta(t, 0.35*31, p.costTracker.projectedCostUSD, 1e-9)
+}
@@ -2,6 +2,7 @@ package costcontrol
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"strings"
 	"sync"
@@ -28,16 +29,35 @@ type costControlProcessor struct {
 	costTracker    *costTracker
 	mutex          sync.RWMutex
 	
+	// Month-to-date spend is persisted under ledgerKey when a ledger is
+	// configured
+	ledger    spendLedger
+	ledgerKey string
+	now       func() time.Time
+	
 	// Cardinality tracking for metrics
 	metricCardinality map[string]*cardinalityTracker
 	
+	// Attribute rules and the series each has removed
+	attributeRules       []attributeRule
+	attributeRuleSavings map[string]int64
+	
 	// Shutdown
 	shutdownCh     chan struct{}
 	wg             sync.WaitGroup
 }
 
+const (
+	// minProjectionWindow is the least elapsed time spend is extrapolated from
+	minProjectionWindow = 24 * time.Hour
+	
+	// maxClockSkew is how far ahead of the local clock a ledger written in
+	// a later month may be and still be restored
+	maxClockSkew = 24 * time.Hour
+)
+
 type costTracker struct {
-	currentMonth      time.Time
+	currentMonth      time.Time // Start of the calendar month, UTC
 	bytesIngested     int64
 	estimatedCostUSD  float64
 	projectedCostUSD  float64
@@ -56,6 +76,10 @@ func (p *costControlProcessor) Start(ctx context.Context, host component.Host) e
 	
 	p.shutdownCh = make(chan struct{})
 	
+	if err := p.restoreSpend(); err != nil {
+		return err
+	}
+	
 	// Start cost monitoring goroutine
 	p.wg.Add(1)
 	go p.costMonitoringLoop()
@@ -74,6 +98,8 @@ func (p *costControlProcessor) Shutdown(context.Context) error {
 	close(p.shutdownCh)
 	p.wg.Wait()
 	
+	p.checkpointSpend()
+	
 	// Log final cost report
 	p.logCostReport()
 	
@@ -108,6 +134,9 @@ func (p *costControlProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Me
 	dataSize := p.estimateMetricSize(md)
 	p.updateCostTracking(dataSize, "metrics")
 	
+	// Collapse series with the attribute rules before counting them
+	p.applyAttributeRules(md)
+	
 	// Apply cardinality reduction
 	md = p.reduceMetricCardinality(md)
 	
@@ -304,6 +333,9 @@ func (p *costControlProcessor) updateCostTracking(bytes int64, dataType string)
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	
+	now := p.now()
+	p.advanceMonthLocked(now)
+	
 	p.costTracker.bytesIngested += bytes
 	
 	// Calculate cost based on New Relic pricing
@@ -312,14 +344,8 @@ func (p *costControlProcessor) updateCostTracking(bytes int64, dataType string)
 	costIncrement := float64(bytes) / (1024 * 1024 * 1024) * pricePerGB
 	
 	p.costTracker.estimatedCostUSD += costIncrement
-	p.costTracker.lastUpdate = time.Now()
-	
-	// Update monthly projection
-	daysSoFar := time.Since(p.costTracker.currentMonth).Hours() / 24
-	if daysSoFar > 0 {
-		dailyRate := p.costTracker.estimatedCostUSD / daysSoFar
-		p.costTracker.projectedCostUSD = dailyRate * 30
-	}
+	p.costTracker.lastUpdate = now
+	p.projectLocked(now)
 	
 	// Log if exceeding budget
 	if p.costTracker.projectedCostUSD > p.config.MonthlyBudgetUSD {
@@ -339,6 +365,121 @@ func (p *costControlProcessor) isOverBudget() bool {
 	return p.costTracker.projectedCostUSD > p.config.MonthlyBudgetUSD
 }
 
+// projectLocked extrapolates month-to-date spend to the whole month. The
+// first day is extrapolated as a full day, so a burst in the first minutes
+// of a month does not project to many times the budget.
+func (p *costControlProcessor) projectLocked(now time.Time) {
+	month := p.costTracker.currentMonth
+	elapsed := now.Sub(month)
+	if elapsed < minProjectionWindow {
+		elapsed = minProjectionWindow
+	}
+	monthLength := month.AddDate(0, 1, 0).Sub(month)
+	p.costTracker.projectedCostUSD = p.costTracker.estimatedCostUSD * float64(monthLength) / float64(elapsed)
+}
+
+// advanceMonthLocked starts a new month when now is in a later calendar
+// month than the one being tracked. The month never moves backwards: when
+// the clock is stepped back, spend keeps counting in the current month.
+func (p *costControlProcessor) advanceMonthLocked(now time.Time) {
+	month := monthStart(now)
+	if !month.After(p.costTracker.currentMonth) {
+		return
+	}
+	
+	p.logger.Info("Starting a new budget month",
+		zap.String("closed_month", p.costTracker.currentMonth.Format(monthKeyLayout)),
+		zap.Float64("closed_month_cost_usd", p.costTracker.estimatedCostUSD),
+		zap.Int64("closed_month_bytes", p.costTracker.bytesIngested),
+		zap.String("month", month.Format(monthKeyLayout)))
+	
+	p.costTracker.currentMonth = month
+	p.costTracker.bytesIngested = 0
+	p.costTracker.estimatedCostUSD = 0
+	p.costTracker.projectedCostUSD = 0
+}
+
+// restoreSpend loads this instance's month-to-date spend from the ledger.
+// A corrupt ledger is logged and the month starts from zero; a ledger that
+// cannot be read fails Start, since the budget would silently reset.
+func (p *costControlProcessor) restoreSpend() error {
+	if p.ledger == nil {
+		return nil
+	}
+	
+	entry, found, err := p.ledger.Load(p.ledgerKey)
+	if errors.Is(err, errCorruptLedger) {
+		p.logger.Warn("Spend ledger is corrupt, starting the month from zero", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	
+	now := p.now()
+	month := monthStart(now)
+	ledgerMonth, _ := time.Parse(monthKeyLayout, entry.Month)
+	switch {
+	case ledgerMonth.Equal(month):
+	case ledgerMonth.Before(month):
+		p.logger.Info("Spend ledger is from an earlier month, starting the month from zero",
+			zap.String("ledger_month", entry.Month))
+		return nil
+	case entry.UpdatedAt.Sub(now) <= maxClockSkew:
+		// Written moments ago by a clock slightly ahead, just after a month
+		// boundary: keep counting in that month rather than reopen the last
+		month = ledgerMonth
+	default:
+		p.logger.Warn("Spend ledger is from a later month, ignoring it",
+			zap.String("ledger_month", entry.Month),
+			zap.Time("ledger_updated_at", entry.UpdatedAt))
+		return nil
+	}
+	
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	
+	p.costTracker.currentMonth = month
+	p.costTracker.bytesIngested = entry.BytesIngested
+	p.costTracker.estimatedCostUSD = entry.EstimatedCostUSD
+	p.costTracker.lastUpdate = entry.UpdatedAt
+	p.projectLocked(now)
+	
+	p.logger.Info("Restored month-to-date spend",
+		zap.String("month", entry.Month),
+		zap.Float64("estimated_cost_usd", entry.EstimatedCostUSD),
+		zap.Int64("bytes_ingested", entry.BytesIngested))
+	return nil
+}
+
+// checkpointSpend rolls over the month if it has ended, refreshes the
+// projection, which otherwise only changes when data arrives, and saves
+// the month-to-date spend to the ledger
+func (p *costControlProcessor) checkpointSpend() {
+	now := p.now()
+	
+	p.mutex.Lock()
+	p.advanceMonthLocked(now)
+	p.projectLocked(now)
+	entry := ledgerEntry{
+		Month:            p.costTracker.currentMonth.Format(monthKeyLayout),
+		BytesIngested:    p.costTracker.bytesIngested,
+		EstimatedCostUSD: p.costTracker.estimatedCostUSD,
+		UpdatedAt:        now,
+	}
+	p.mutex.Unlock()
+	
+	if p.ledger == nil {
+		return
+	}
+	if err := p.ledger.Save(p.ledgerKey, entry); err != nil {
+		p.logger.Warn("Failed to save spend ledger", zap.Error(err))
+	}
+}
+
 // costMonitoringLoop periodically reports cost metrics
 func (p *costControlProcessor) costMonitoringLoop() {
 	defer p.wg.Done()
@@ -349,6 +490,7 @@ func (p *costControlProcessor) costMonitoringLoop() {
 	for {
 		select {
 		case <-ticker.C:
+			p.checkpointSpend()
 			p.generateCostMetrics()
 		case <-p.shutdownCh:
 			return
@@ -358,10 +500,14 @@ func (p *costControlProcessor) costMonitoringLoop() {
 
 // logCostReport logs the current cost status
 func (p *costControlProcessor) logCostReport() {
+	ruleReport := p.attributeRuleReport()
+	
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 	
 	p.logger.Info("Cost control report",
+		zap.Any("attribute_rule_series_saved", ruleReport),
+		zap.String("month", p.costTracker.currentMonth.Format(monthKeyLayout)),
 		zap.Int64("bytes_ingested", p.costTracker.bytesIngested),
 		zap.Float64("estimated_cost_usd", p.costTracker.estimatedCostUSD),
 		zap.Float64("projected_monthly_cost_usd", p.costTracker.projectedCostUSD),
@@ -29,6 +29,20 @@ type Config struct {
 	
 	// EnableProactiveValidation performs additional checks
 	EnableProactiveValidation bool `mapstructure:"enable_proactive_validation"`
+	
+	// ErrorEventSampling bounds the error events the processor emits itself,
+	// so a mass failure does not turn into a flood of error telemetry
+	ErrorEventSampling ErrorEventSamplingConfig `mapstructure:"error_event_sampling"`
+}
+
+// ErrorEventSamplingConfig keeps the first Initial events of each error
+// category per second, then every Thereafter-th, with those sampled events
+// capped at MaxEventsPerSecond across categories. Alerts are never sampled.
+type ErrorEventSamplingConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	Initial            int  `mapstructure:"initial"`
+	Thereafter         int  `mapstructure:"thereafter"`
+	MaxEventsPerSecond int  `mapstructure:"max_events_per_second"`
 }
 
 // Validate checks the processor configuration
@@ -53,6 +67,22 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("reporting_interval must be positive")
 	}
 	
+	if cfg.ErrorSuppressionDuration < 0 {
+		return fmt.Errorf("error_suppression_duration cannot be negative")
+	}
+	
+	if cfg.ErrorEventSampling.Enabled {
+		if cfg.ErrorEventSampling.Initial < 0 {
+			return fmt.Errorf("error_event_sampling.initial cannot be negative")
+		}
+		if cfg.ErrorEventSampling.Thereafter <= 0 {
+			return fmt.Errorf("error_event_sampling.thereafter must be positive")
+		}
+		if cfg.ErrorEventSampling.MaxEventsPerSecond <= 0 {
+			return fmt.Errorf("error_event_sampling.max_events_per_second must be positive")
+		}
+	}
+	
 	return nil
 }
 
@@ -66,5 +96,11 @@ func createDefaultConfig() component.Config {
 		ReportingInterval:           60 * time.Second,
 		ErrorSuppressionDuration:    5 * time.Minute,
 		EnableProactiveValidation:   true,
+		ErrorEventSampling: ErrorEventSamplingConfig{
+			Enabled:            true,
+			Initial:            10,
+			Thereafter:         100,
+			MaxEventsPerSecond: 10,
+		},
 	}
 }
\ No newline at end of file
@@ -0,0 +1,105 @@
+package nrerrormonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero max attribute length",
+			modify: func(cfg *Config) {
+				cfg.MaxAttributeLength = 0
+			},
+			wantErr: "max_attribute_length must be positive",
+		},
+		{
+			name: "zero max metric name length",
+			modify: func(cfg *Config) {
+				cfg.MaxMetricNameLength = 0
+			},
+			wantErr: "max_metric_name_length must be positive",
+		},
+		{
+			name: "zero cardinality warning threshold",
+			modify: func(cfg *Config) {
+				cfg.CardinalityWarningThreshold = 0
+			},
+			wantErr: "cardinality_warning_threshold must be positive",
+		},
+		{
+			name: "zero alert threshold",
+			modify: func(cfg *Config) {
+				cfg.AlertThreshold = 0
+			},
+			wantErr: "alert_threshold must be positive",
+		},
+		{
+			name: "zero reporting interval",
+			modify: func(cfg *Config) {
+				cfg.ReportingInterval = 0
+			},
+			wantErr: "reporting_interval must be positive",
+		},
+		{
+			name: "no error suppression",
+			modify: func(cfg *Config) {
+				cfg.ErrorSuppressionDuration = 0
+			},
+		},
+		{
+			name: "negative error suppression",
+			modify: func(cfg *Config) {
+				cfg.ErrorSuppressionDuration = -time.Minute
+			},
+			wantErr: "error_suppression_duration cannot be negative",
+		},
+		{
+			name: "zero sampling thereafter",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling.Thereafter = 0
+			},
+			wantErr: "error_event_sampling.thereafter must be positive",
+		},
+		{
+			name: "zero sampling rate",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling.MaxEventsPerSecond = 0
+			},
+			wantErr: "error_event_sampling.max_events_per_second must be positive",
+		},
+		{
+			name: "sampling settings ignored when disabled",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling = ErrorEventSamplingConfig{Enabled: false}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
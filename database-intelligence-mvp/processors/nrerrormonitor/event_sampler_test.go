@@ -0,0 +1,34 @@
+package nrerrormonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorEventSampler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newErrorEventSampler(ErrorEventSamplingConfig{Initial: 2, Thereafter: 3, MaxEventsPerSecond: 2})
+	s.now = func() time.Time { return now }
+
+	allowed := func(category string, n int) []bool {
+		var got []bool
+		for i := 0; i < n; i++ {
+			got = append(got, s.allow(category))
+		}
+		return got
+	}
+
+	// First two always, then every third until the budget of two runs out
+	assert.Equal(t,
+		[]bool{true, true, true, false, false, true, false, false, false, false},
+		allowed("high_cardinality", 10))
+
+	// Another category still gets its initial events, but shares the budget
+	assert.Equal(t, []bool{true, true, false, false, false}, allowed("missing_attribute", 5))
+
+	// A new window starts over
+	now = now.Add(time.Second)
+	assert.Equal(t, []bool{true, true, true}, allowed("high_cardinality", 3))
+}
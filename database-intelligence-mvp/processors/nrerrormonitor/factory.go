@@ -36,6 +36,12 @@ func CreateDefaultConfig() component.Config {
 		ReportingInterval:           60 * time.Second,
 		ErrorSuppressionDuration:    5 * time.Minute,
 		EnableProactiveValidation:   true,
+		ErrorEventSampling: ErrorEventSamplingConfig{
+			Enabled:            true,
+			Initial:            10,
+			Thereafter:         100,
+			MaxEventsPerSecond: 10,
+		},
 	}
 }
 
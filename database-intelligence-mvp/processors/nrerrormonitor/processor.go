@@ -24,6 +24,10 @@ type nrErrorMonitor struct {
 	errorCounts  map[string]*errorTracker
 	mutex        sync.RWMutex
 	
+	// Sampling of the error events the processor emits, nil when disabled
+	sampler          *errorEventSampler
+	eventsSuppressed int64
+	
 	// Metrics generation
 	lastReport   time.Time
 	shutdownCh   chan struct{}
@@ -36,17 +40,22 @@ type errorTracker struct {
 	lastSeen      time.Time
 	lastMessage   string
 	alertFired    bool
+	suppressed    int64
 }
 
 // newNrErrorMonitor creates a new error monitor processor
 func newNrErrorMonitor(config *Config, logger *zap.Logger, nextConsumer consumer.Metrics) *nrErrorMonitor {
-	return &nrErrorMonitor{
+	p := &nrErrorMonitor{
 		config:       config,
 		logger:       logger,
 		nextConsumer: nextConsumer,
 		errorCounts:  make(map[string]*errorTracker),
 		lastReport:   time.Now(),
 	}
+	if config.ErrorEventSampling.Enabled {
+		p.sampler = newErrorEventSampler(config.ErrorEventSampling)
+	}
+	return p
 }
 
 // Start begins the error monitoring processor
@@ -64,7 +73,11 @@ func (p *nrErrorMonitor) Start(ctx context.Context, host component.Host) error {
 
 // Shutdown stops the processor
 func (p *nrErrorMonitor) Shutdown(context.Context) error {
-	p.logger.Info("Shutting down NrIntegrationError monitor processor")
+	p.mutex.RLock()
+	suppressed := p.eventsSuppressed
+	p.mutex.RUnlock()
+	p.logger.Info("Shutting down NrIntegrationError monitor processor",
+		zap.Int64("error_events_suppressed", suppressed))
 	
 	close(p.shutdownCh)
 	p.wg.Wait()
@@ -207,16 +220,24 @@ func (p *nrErrorMonitor) recordError(category, message string) {
 		p.errorCounts[category] = tracker
 	}
 	
+	now := time.Now()
+	recurring := exists && now.Sub(tracker.lastSeen) >= p.config.ErrorSuppressionDuration
+	
 	tracker.count++
-	tracker.lastSeen = time.Now()
+	tracker.lastSeen = now
 	tracker.lastMessage = message
 	
-	// Log if this is a new or recurring error
-	if tracker.count == 1 || time.Since(tracker.lastSeen) > p.config.ErrorSuppressionDuration {
-		p.logger.Warn("Potential NrIntegrationError detected",
-			zap.String("category", category),
-			zap.String("message", message),
-			zap.Int64("occurrences", tracker.count))
+	// Log if this is a new or recurring error, unless sampled out
+	if tracker.count == 1 || recurring {
+		if p.sampler == nil || p.sampler.allow(category) {
+			p.logger.Warn("Potential NrIntegrationError detected",
+				zap.String("category", category),
+				zap.String("message", message),
+				zap.Int64("occurrences", tracker.count))
+		} else {
+			tracker.suppressed++
+			p.eventsSuppressed++
+		}
 	}
 	
 	// Check if we need to fire an alert
@@ -292,6 +313,24 @@ func (p *nrErrorMonitor) generateSummaryMetrics() {
 		dp.Attributes().PutInt("error.minutes_since_last", int64(time.Since(tracker.lastSeen).Minutes()))
 	}
 	
+	// Report error events dropped by sampling
+	if p.sampler != nil {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName("otelcol.nrerror.events_suppressed")
+		metric.SetDescription("Error events not emitted because of error event sampling")
+		metric.SetUnit("1")
+		
+		sum := metric.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		for category, tracker := range p.errorCounts {
+			dp := sum.DataPoints().AppendEmpty()
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+			dp.SetIntValue(tracker.suppressed)
+			dp.Attributes().PutStr("error.category", category)
+		}
+	}
+	
 	// Send metrics through pipeline
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -3,12 +3,15 @@ package nrerrormonitor
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewNRErrorMonitor(t *testing.T) {
@@ -210,4 +213,76 @@ func TestNRErrorMonitor_MetricNameValidation(t *testing.T) {
 	errorCount := len(processor.errorCounts)
 	processor.mutex.RUnlock()
 	assert.Greater(t, errorCount, 0)
-}
\ No newline at end of file
+}
+
+func TestNRErrorMonitor_ErrorEventSamplingBoundsBurst(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.ErrorSuppressionDuration = 0 // every occurrence is an event
+	cfg.AlertThreshold = 1_000_000
+	
+	core, logs := observer.New(zapcore.WarnLevel)
+	processor := newNrErrorMonitor(cfg, zap.New(core), &consumertest.MetricsSink{})
+	
+	// Keep the whole burst in one sampling window
+	now := time.Now()
+	processor.sampler.now = func() time.Time { return now }
+	
+	const burst = 10_000
+	for i := 0; i < burst; i++ {
+		processor.recordError("high_cardinality", "Metric db.query has high cardinality")
+	}
+	
+	emitted := logs.FilterMessage("Potential NrIntegrationError detected").Len()
+	sampling := cfg.ErrorEventSampling
+	assert.Equal(t, sampling.Initial+sampling.MaxEventsPerSecond, emitted)
+	assert.Equal(t, int64(burst-emitted), processor.eventsSuppressed)
+	assert.Equal(t, int64(burst-emitted), processor.errorCounts["high_cardinality"].suppressed)
+	assert.Equal(t, int64(burst), processor.errorCounts["high_cardinality"].count)
+}
+
+func TestNRErrorMonitor_ErrorEventSamplingDisabled(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.ErrorSuppressionDuration = 0
+	cfg.AlertThreshold = 1_000_000
+	cfg.ErrorEventSampling.Enabled = false
+	
+	core, logs := observer.New(zapcore.WarnLevel)
+	processor := newNrErrorMonitor(cfg, zap.New(core), &consumertest.MetricsSink{})
+	
+	for i := 0; i < 100; i++ {
+		processor.recordError("high_cardinality", "Metric db.query has high cardinality")
+	}
+	
+	assert.Equal(t, 100, logs.FilterMessage("Potential NrIntegrationError detected").Len())
+	assert.Zero(t, processor.eventsSuppressed)
+}
+
+func TestNRErrorMonitor_SummaryReportsSuppressedEvents(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.ErrorSuppressionDuration = 0
+	
+	sink := &consumertest.MetricsSink{}
+	processor := newNrErrorMonitor(cfg, zap.NewNop(), sink)
+	now := time.Now()
+	processor.sampler.now = func() time.Time { return now }
+	
+	for i := 0; i < 50; i++ {
+		processor.recordError("missing_attribute", "Missing required service.name attribute")
+	}
+	processor.generateSummaryMetrics()
+	
+	require.Len(t, sink.AllMetrics(), 1)
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	var suppressed pmetric.Metric
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == "otelcol.nrerror.events_suppressed" {
+			suppressed = metrics.At(i)
+		}
+	}
+	require.Equal(t, pmetric.MetricTypeSum, suppressed.Type())
+	dp := suppressed.Sum().DataPoints().At(0)
+	assert.Equal(t, processor.eventsSuppressed, dp.IntValue())
+	assert.Greater(t, dp.IntValue(), int64(0))
+	category, _ := dp.Attributes().Get("error.category")
+	assert.Equal(t, "missing_attribute", category.Str())
+}
@@ -39,6 +39,9 @@ type Config struct {
 
 	// QueryLens configures pg_querylens integration
 	QueryLens QueryLensConfig `mapstructure:"querylens"`
+
+	// ExpensivePlan flags plans whose cost or row estimates exceed thresholds
+	ExpensivePlan ExpensivePlanConfig `mapstructure:"expensive_plan"`
 }
 
 // PostgreSQLExtractionRules defines how to extract attributes from PostgreSQL JSON plans
@@ -110,6 +113,56 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if len(cfg.HashConfig.Include) > 0 && cfg.HashConfig.Output == "" {
+		return fmt.Errorf("hash_config.output must be set when hash_config.include is configured")
+	}
+
+	if cfg.QueryAnonymization.Enabled && cfg.QueryAnonymization.GenerateFingerprint && cfg.QueryAnonymization.FingerprintAttribute == "" {
+		return fmt.Errorf("query_anonymization.fingerprint_attribute must be set when generate_fingerprint is enabled")
+	}
+
+	if cfg.QueryLens.Enabled {
+		if err := cfg.QueryLens.Validate(); err != nil {
+			return fmt.Errorf("invalid querylens config: %w", err)
+		}
+	}
+
+	if cfg.ExpensivePlan.Enabled {
+		if err := cfg.ExpensivePlan.Validate(); err != nil {
+			return fmt.Errorf("invalid expensive_plan config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the pg_querylens settings
+func (cfg *QueryLensConfig) Validate() error {
+	if cfg.PlanHistoryHours < 0 {
+		return fmt.Errorf("plan_history_hours cannot be negative, got %d", cfg.PlanHistoryHours)
+	}
+
+	if cfg.RegressionThreshold < 0 {
+		return fmt.Errorf("regression_threshold cannot be negative, got %f", cfg.RegressionThreshold)
+	}
+
+	if cfg.RegressionDetection.Enabled {
+		// Increases are ratios of the new plan's value to the old one's, so
+		// anything at or below 1.0 would flag every plan as a regression
+		increases := []struct {
+			name  string
+			value float64
+		}{
+			{"time_increase", cfg.RegressionDetection.TimeIncrease},
+			{"io_increase", cfg.RegressionDetection.IOIncrease},
+			{"cost_increase", cfg.RegressionDetection.CostIncrease},
+		}
+		for _, increase := range increases {
+			if increase.value <= 1.0 {
+				return fmt.Errorf("regression_detection.%s must be greater than 1.0, got %f", increase.name, increase.value)
+			}
+		}
+	}
 
 	return nil
 }
@@ -183,6 +236,15 @@ func createDefaultConfig() component.Config {
 			},
 			AlertOnRegression: false,
 		},
+		ExpensivePlan: ExpensivePlanConfig{
+			Enabled:   true,
+			TotalCost: 10000,
+			NodeThresholds: map[string]PlanNodeThreshold{
+				"Seq Scan": {PlanRows: 100000},
+			},
+			Attribute:       "db.query.plan.expensive",
+			ReasonAttribute: "db.query.plan.expensive_reason",
+		},
 	}
 }
 
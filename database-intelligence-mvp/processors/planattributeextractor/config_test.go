@@ -0,0 +1,128 @@
+package planattributeextractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero timeout",
+			modify: func(cfg *Config) {
+				cfg.TimeoutMS = 0
+			},
+			wantErr: "timeout_ms must be positive",
+		},
+		{
+			name: "timeout above safety limit",
+			modify: func(cfg *Config) {
+				cfg.TimeoutMS = 20000
+			},
+			wantErr: "timeout_ms must be <= 10000ms for safety",
+		},
+		{
+			name: "unknown error mode",
+			modify: func(cfg *Config) {
+				cfg.ErrorMode = "panic"
+			},
+			wantErr: "error_mode must be 'ignore' or 'propagate'",
+		},
+		{
+			name: "unsupported hash algorithm",
+			modify: func(cfg *Config) {
+				cfg.HashConfig.Algorithm = "md5"
+			},
+			wantErr: "unsupported hash algorithm: md5",
+		},
+		{
+			name: "hash without output attribute",
+			modify: func(cfg *Config) {
+				cfg.HashConfig.Output = ""
+			},
+			wantErr: "hash_config.output must be set",
+		},
+		{
+			name: "fingerprint without attribute",
+			modify: func(cfg *Config) {
+				cfg.QueryAnonymization.FingerprintAttribute = ""
+			},
+			wantErr: "query_anonymization.fingerprint_attribute must be set",
+		},
+		{
+			name: "fingerprint attribute not needed when anonymization is disabled",
+			modify: func(cfg *Config) {
+				cfg.QueryAnonymization.Enabled = false
+				cfg.QueryAnonymization.FingerprintAttribute = ""
+			},
+		},
+		{
+			name: "invalid querylens settings ignored when disabled",
+			modify: func(cfg *Config) {
+				cfg.QueryLens.RegressionThreshold = -1
+			},
+		},
+		{
+			name: "negative regression threshold",
+			modify: func(cfg *Config) {
+				cfg.QueryLens.Enabled = true
+				cfg.QueryLens.RegressionThreshold = -1
+			},
+			wantErr: "invalid querylens config: regression_threshold cannot be negative",
+		},
+		{
+			name: "regression cost increase of one",
+			modify: func(cfg *Config) {
+				cfg.QueryLens.Enabled = true
+				cfg.QueryLens.RegressionDetection.CostIncrease = 1.0
+			},
+			wantErr: "regression_detection.cost_increase must be greater than 1.0",
+		},
+		{
+			name: "expensive plan without attribute",
+			modify: func(cfg *Config) {
+				cfg.ExpensivePlan.Attribute = ""
+			},
+			wantErr: "invalid expensive_plan config: attribute and reason_attribute must be set",
+		},
+		{
+			name: "negative expensive plan node threshold",
+			modify: func(cfg *Config) {
+				cfg.ExpensivePlan.NodeThresholds["Seq Scan"] = PlanNodeThreshold{PlanRows: -1}
+			},
+			wantErr: "node_thresholds.Seq Scan cannot be negative",
+		},
+		{
+			name: "expensive plan settings ignored when disabled",
+			modify: func(cfg *Config) {
+				cfg.ExpensivePlan.Enabled = false
+				cfg.ExpensivePlan.TotalCost = -1
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
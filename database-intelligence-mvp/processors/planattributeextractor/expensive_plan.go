@@ -0,0 +1,133 @@
+package planattributeextractor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// ExpensivePlanConfig flags PostgreSQL plans whose estimates exceed the
+// configured thresholds, so that downstream sampling can keep them. A
+// threshold of zero is not checked.
+type ExpensivePlanConfig struct {
+	// Enabled turns on expensive plan detection
+	Enabled bool `mapstructure:"enabled"`
+
+	// TotalCost flags plans whose root node's total cost exceeds it
+	TotalCost float64 `mapstructure:"total_cost"`
+
+	// PlanRows flags plans whose root node estimates more rows than this
+	PlanRows float64 `mapstructure:"plan_rows"`
+
+	// NodeThresholds applies thresholds to every node of a type anywhere in
+	// the plan, keyed by node type, e.g. "Seq Scan"
+	NodeThresholds map[string]PlanNodeThreshold `mapstructure:"node_thresholds"`
+
+	// Attribute receives true or false for every evaluated plan
+	Attribute string `mapstructure:"attribute"`
+
+	// ReasonAttribute receives the thresholds a flagged plan exceeded
+	ReasonAttribute string `mapstructure:"reason_attribute"`
+}
+
+// PlanNodeThreshold holds the limits for a single plan node type
+type PlanNodeThreshold struct {
+	// TotalCost flags nodes whose total cost exceeds it
+	TotalCost float64 `mapstructure:"total_cost"`
+
+	// PlanRows flags nodes estimating more rows than this. For a Seq Scan
+	// this stands in for the size of the table being scanned.
+	PlanRows float64 `mapstructure:"plan_rows"`
+}
+
+// Validate checks the expensive plan settings
+func (cfg *ExpensivePlanConfig) Validate() error {
+	if cfg.Attribute == "" || cfg.ReasonAttribute == "" {
+		return fmt.Errorf("attribute and reason_attribute must be set")
+	}
+	if cfg.TotalCost < 0 {
+		return fmt.Errorf("total_cost cannot be negative, got %f", cfg.TotalCost)
+	}
+	if cfg.PlanRows < 0 {
+		return fmt.Errorf("plan_rows cannot be negative, got %f", cfg.PlanRows)
+	}
+	for nodeType, threshold := range cfg.NodeThresholds {
+		if nodeType == "" {
+			return fmt.Errorf("node_thresholds keys must be plan node types")
+		}
+		if threshold.TotalCost < 0 || threshold.PlanRows < 0 {
+			return fmt.Errorf("node_thresholds.%s cannot be negative", nodeType)
+		}
+	}
+	return nil
+}
+
+// expensivePlanReasons returns why the plan at root exceeds the expensive
+// plan thresholds, or nothing when it does not
+func (p *planAttributeExtractor) expensivePlanReasons(ctx context.Context, root gjson.Result) ([]string, error) {
+	cfg := p.config.ExpensivePlan
+	var reasons []string
+
+	if cost := root.Get("Total Cost").Float(); cfg.TotalCost > 0 && cost > cfg.TotalCost {
+		reasons = append(reasons, fmt.Sprintf("total cost %s exceeds %s", formatEstimate(cost), formatEstimate(cfg.TotalCost)))
+	}
+	if rows := root.Get("Plan Rows").Float(); cfg.PlanRows > 0 && rows > cfg.PlanRows {
+		reasons = append(reasons, fmt.Sprintf("estimated rows %s exceed %s", formatEstimate(rows), formatEstimate(cfg.PlanRows)))
+	}
+	if len(cfg.NodeThresholds) == 0 {
+		return reasons, nil
+	}
+
+	// Walk the whole plan tree; every offending node is reported, with its
+	// table when it has one
+	nodes := []gjson.Result{root}
+	for len(nodes) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout during expensive plan evaluation")
+		default:
+		}
+
+		node := nodes[0]
+		nodes = nodes[1:]
+		nodes = append(nodes, node.Get("Plans").Array()...)
+
+		nodeType := node.Get("Node Type").String()
+		threshold, ok := cfg.NodeThresholds[nodeType]
+		if !ok {
+			continue
+		}
+		name := nodeType
+		if relation := node.Get("Relation Name").String(); relation != "" {
+			name += " on " + relation
+		}
+		if cost := node.Get("Total Cost").Float(); threshold.TotalCost > 0 && cost > threshold.TotalCost {
+			reasons = append(reasons, fmt.Sprintf("%s cost %s exceeds %s", name, formatEstimate(cost), formatEstimate(threshold.TotalCost)))
+		}
+		if rows := node.Get("Plan Rows").Float(); threshold.PlanRows > 0 && rows > threshold.PlanRows {
+			reasons = append(reasons, fmt.Sprintf("%s estimates %s rows, above %s", name, formatEstimate(rows), formatEstimate(threshold.PlanRows)))
+		}
+	}
+	return reasons, nil
+}
+
+// applyExpensivePlan adds the expensive plan attributes for the plan at root
+func (p *planAttributeExtractor) applyExpensivePlan(ctx context.Context, root gjson.Result, attributes map[string]interface{}) error {
+	reasons, err := p.expensivePlanReasons(ctx, root)
+	if err != nil {
+		return err
+	}
+	attributes[p.config.ExpensivePlan.Attribute] = len(reasons) > 0
+	if len(reasons) > 0 {
+		attributes[p.config.ExpensivePlan.ReasonAttribute] = strings.Join(reasons, "; ")
+	}
+	return nil
+}
+
+// formatEstimate prints a planner estimate without an exponent
+func formatEstimate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,97 @@
+package planattributeextractor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func extractPlan(t *testing.T, cfg *Config, planJSON string) plog.LogRecord {
+	t.Helper()
+	processor := newPlanAttributeExtractor(cfg, zap.NewNop(), consumertest.NewNop())
+
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr("plan_json", planJSON)
+
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+	return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+}
+
+func TestExpensivePlan_HighCostSeqScanFlagged(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	planJSON := `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "orders", "Total Cost": 48250.5, "Plan Rows": 2500000}}]`
+
+	record := extractPlan(t, cfg, planJSON)
+
+	expensive, ok := record.Attributes().Get("db.query.plan.expensive")
+	require.True(t, ok)
+	assert.True(t, expensive.Bool())
+	reason, ok := record.Attributes().Get("db.query.plan.expensive_reason")
+	require.True(t, ok)
+	assert.Equal(t, "total cost 48250.5 exceeds 10000; Seq Scan on orders estimates 2500000 rows, above 100000", reason.Str())
+}
+
+func TestExpensivePlan_CheapIndexScanNotFlagged(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	planJSON := `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "orders", "Index Name": "orders_pkey", "Total Cost": 8.44, "Plan Rows": 1}}]`
+
+	record := extractPlan(t, cfg, planJSON)
+
+	expensive, ok := record.Attributes().Get("db.query.plan.expensive")
+	require.True(t, ok)
+	assert.False(t, expensive.Bool())
+	_, ok = record.Attributes().Get("db.query.plan.expensive_reason")
+	assert.False(t, ok, "a cheap plan has no reason")
+}
+
+func TestExpensivePlan_NodeThresholdsApplyToNestedNodes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExpensivePlan.TotalCost = 0
+	cfg.ExpensivePlan.NodeThresholds = map[string]PlanNodeThreshold{
+		"Seq Scan":    {PlanRows: 10000},
+		"Nested Loop": {TotalCost: 5000},
+	}
+	// Cheap at the root, but the join scans a large table
+	planJSON := `[{"Plan": {"Node Type": "Hash Join", "Total Cost": 2100, "Plan Rows": 50,
+		"Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "line_items", "Total Cost": 1800, "Plan Rows": 60000},
+			{"Node Type": "Hash", "Total Cost": 12, "Plan Rows": 50,
+				"Plans": [{"Node Type": "Seq Scan", "Relation Name": "customers", "Total Cost": 11, "Plan Rows": 50}]}
+		]}}]`
+
+	record := extractPlan(t, cfg, planJSON)
+
+	expensive, _ := record.Attributes().Get("db.query.plan.expensive")
+	assert.True(t, expensive.Bool())
+	reason, _ := record.Attributes().Get("db.query.plan.expensive_reason")
+	assert.Equal(t, "Seq Scan on line_items estimates 60000 rows, above 10000", reason.Str())
+}
+
+func TestExpensivePlan_RootRowThreshold(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExpensivePlan.PlanRows = 1000
+	planJSON := `[{"Plan": {"Node Type": "Index Scan", "Total Cost": 900, "Plan Rows": 5000}}]`
+
+	record := extractPlan(t, cfg, planJSON)
+
+	reason, ok := record.Attributes().Get("db.query.plan.expensive_reason")
+	require.True(t, ok)
+	assert.Equal(t, "estimated rows 5000 exceed 1000", reason.Str())
+}
+
+func TestExpensivePlan_Disabled(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExpensivePlan.Enabled = false
+	planJSON := `[{"Plan": {"Node Type": "Seq Scan", "Total Cost": 48250.5, "Plan Rows": 2500000}}]`
+
+	record := extractPlan(t, cfg, planJSON)
+
+	_, ok := record.Attributes().Get("db.query.plan.expensive")
+	assert.False(t, ok)
+}
@@ -252,6 +252,13 @@ func (p *planAttributeExtractor) extractPostgreSQLAttributes(ctx context.Context
 		attributes[attrName] = value
 	}
 	
+	if p.config.ExpensivePlan.Enabled {
+		root := parsedPlan.Get(p.config.PostgreSQLRules.DetectionJSONPath)
+		if err := p.applyExpensivePlan(ctx, root, attributes); err != nil {
+			return nil, err
+		}
+	}
+	
 	return attributes, nil
 }
 
@@ -370,6 +370,28 @@ func TestQueryLensConfigValidation(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "negative_plan_history",
+			config: QueryLensConfig{
+				Enabled:          true,
+				PlanHistoryHours: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "regression_ratio_not_above_one",
+			config: QueryLensConfig{
+				Enabled:          true,
+				PlanHistoryHours: 24,
+				RegressionDetection: RegressionDetectionConfig{
+					Enabled:      true,
+					TimeIncrease: 1.5,
+					IOIncrease:   0.5,
+					CostIncrease: 2.0,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
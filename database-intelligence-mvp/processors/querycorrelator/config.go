@@ -26,6 +26,9 @@ type Config struct {
 	
 	// CorrelationAttributes defines which attributes to add
 	CorrelationAttributes CorrelationAttributesConfig `mapstructure:"correlation_attributes"`
+	
+	// PlanRegression configures plan regression alerts
+	PlanRegression PlanRegressionConfig `mapstructure:"plan_regression"`
 }
 
 // CorrelationAttributesConfig defines which correlation attributes to add
@@ -43,6 +46,27 @@ type CorrelationAttributesConfig struct {
 	AddMaintenanceIndicators bool `mapstructure:"add_maintenance_indicators"`
 }
 
+// PlanRegressionConfig configures alerts for queries whose plan changed and
+// got slower
+type PlanRegressionConfig struct {
+	// Enabled turns on plan regression detection
+	Enabled bool `mapstructure:"enabled"`
+	
+	// FingerprintAttribute identifies the query across plans
+	FingerprintAttribute string `mapstructure:"fingerprint_attribute"`
+	
+	// PlanHashAttribute identifies the plan a query ran with
+	PlanHashAttribute string `mapstructure:"plan_hash_attribute"`
+	
+	// LatencyIncreaseThreshold is the relative increase in mean latency
+	// under the new plan that raises an alert, e.g. 0.5 for 50% slower
+	LatencyIncreaseThreshold float64 `mapstructure:"latency_increase_threshold"`
+	
+	// MinExecutions is how many executions each plan needs before their
+	// latencies are compared
+	MinExecutions int64 `mapstructure:"min_executions"`
+}
+
 var _ component.Config = (*Config)(nil)
 
 // Validate checks if the configuration is valid
@@ -64,5 +88,28 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("max_queries_tracked must be non-negative, got %d", cfg.MaxQueriesTracked)
 	}
 	
+	if cfg.PlanRegression.Enabled {
+		if err := cfg.PlanRegression.Validate(); err != nil {
+			return fmt.Errorf("plan_regression: %w", err)
+		}
+	}
+	
+	return nil
+}
+
+// Validate checks the plan regression settings
+func (cfg *PlanRegressionConfig) Validate() error {
+	if cfg.FingerprintAttribute == "" {
+		return fmt.Errorf("fingerprint_attribute must not be empty")
+	}
+	if cfg.PlanHashAttribute == "" {
+		return fmt.Errorf("plan_hash_attribute must not be empty")
+	}
+	if cfg.LatencyIncreaseThreshold <= 0 {
+		return fmt.Errorf("latency_increase_threshold must be positive, got %v", cfg.LatencyIncreaseThreshold)
+	}
+	if cfg.MinExecutions < 1 {
+		return fmt.Errorf("min_executions must be at least 1, got %d", cfg.MinExecutions)
+	}
 	return nil
 }
\ No newline at end of file
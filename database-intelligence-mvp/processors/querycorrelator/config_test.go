@@ -0,0 +1,99 @@
+package querycorrelator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero retention period",
+			modify: func(cfg *Config) {
+				cfg.RetentionPeriod = 0
+			},
+			wantErr: "retention_period must be positive",
+		},
+		{
+			name: "zero cleanup interval",
+			modify: func(cfg *Config) {
+				cfg.CleanupInterval = 0
+			},
+			wantErr: "cleanup_interval must be positive",
+		},
+		{
+			name: "cleanup interval above retention period",
+			modify: func(cfg *Config) {
+				cfg.CleanupInterval = 48 * time.Hour
+			},
+			wantErr: "should not be greater than retention_period",
+		},
+		{
+			name: "zero max queries tracked",
+			modify: func(cfg *Config) {
+				cfg.MaxQueriesTracked = 0
+			},
+		},
+		{
+			name: "negative max queries tracked",
+			modify: func(cfg *Config) {
+				cfg.MaxQueriesTracked = -1
+			},
+			wantErr: "max_queries_tracked must be non-negative",
+		},
+		{
+			name: "plan regression enabled",
+			modify: func(cfg *Config) {
+				cfg.PlanRegression.Enabled = true
+			},
+		},
+		{
+			name: "plan regression without threshold",
+			modify: func(cfg *Config) {
+				cfg.PlanRegression.Enabled = true
+				cfg.PlanRegression.LatencyIncreaseThreshold = 0
+			},
+			wantErr: "plan_regression: latency_increase_threshold must be positive",
+		},
+		{
+			name: "plan regression without plan hash attribute",
+			modify: func(cfg *Config) {
+				cfg.PlanRegression.Enabled = true
+				cfg.PlanRegression.PlanHashAttribute = ""
+			},
+			wantErr: "plan_hash_attribute must not be empty",
+		},
+		{
+			name: "plan regression disabled skips its validation",
+			modify: func(cfg *Config) {
+				cfg.PlanRegression.MinExecutions = 0
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
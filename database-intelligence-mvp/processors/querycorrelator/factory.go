@@ -40,6 +40,13 @@ func createDefaultConfig() component.Config {
 			AddLoadContribution:     true,
 			AddMaintenanceIndicators: true,
 		},
+		PlanRegression: PlanRegressionConfig{
+			Enabled:                  false,
+			FingerprintAttribute:     "db.query.fingerprint",
+			PlanHashAttribute:        "db.query.plan.hash",
+			LatencyIncreaseThreshold: 0.5,
+			MinExecutions:            1,
+		},
 	}
 }
 
@@ -66,6 +73,7 @@ func createMetricsProcessor(
 		queryIndex:    make(map[string]*queryInfo),
 		tableIndex:    make(map[string]*tableInfo),
 		databaseIndex: make(map[string]*databaseInfo),
+		planIndex:     make(map[string]*planHistory),
 		shutdownChan:  make(chan struct{}),
 	}
 
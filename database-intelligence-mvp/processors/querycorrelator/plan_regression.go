@@ -0,0 +1,227 @@
+package querycorrelator
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	// planRegressionMetric is the metric carrying plan regression events
+	planRegressionMetric = "db.query.plan_regression"
+	// planRegressionEvent is the event.name of a plan regression
+	planRegressionEvent = "plan_regression_with_performance_impact"
+)
+
+// planStats accumulates the latency of one plan of a query
+type planStats struct {
+	hash         string
+	executions   int64
+	totalLatency float64
+}
+
+func (s *planStats) meanLatency() float64 {
+	if s.executions == 0 {
+		return 0
+	}
+	return s.totalLatency / float64(s.executions)
+}
+
+// planHistory tracks the plan a query runs with now and the plan it ran
+// with before the last change
+type planHistory struct {
+	database string
+	baseline *planStats
+	current  *planStats
+	// alerted is set once the current plan has been reported against the
+	// baseline, so a regression is reported once per plan change
+	alerted  bool
+	lastSeen time.Time
+}
+
+// planRegression is a plan change that made a query slower
+type planRegression struct {
+	fingerprint        string
+	database           string
+	previousHash       string
+	currentHash        string
+	previousLatency    float64
+	currentLatency     float64
+	previousExecutions int64
+	currentExecutions  int64
+}
+
+// latencyIncrease returns the relative increase in mean latency
+func (r planRegression) latencyIncrease() float64 {
+	return r.currentLatency/r.previousLatency - 1
+}
+
+// detectPlanRegressions records the plan and latency of every query data
+// point in md and returns the plan changes whose latency increase crossed
+// the threshold
+func (p *queryCorrelator) detectPlanRegressions(md pmetric.Metrics) []planRegression {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var regressions []planRegression
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				switch {
+				case metric.Name() == "db.query.mean_time" && metric.Type() == pmetric.MetricTypeGauge:
+					dps := metric.Gauge().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						regressions = p.observePlan(regressions, dp.Attributes(), numberValue(dp), 1)
+					}
+				case metric.Name() == "db.query.duration" && metric.Type() == pmetric.MetricTypeHistogram:
+					dps := metric.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						if dp.Count() == 0 {
+							continue
+						}
+						mean := dp.Sum() / float64(dp.Count())
+						regressions = p.observePlan(regressions, dp.Attributes(), mean, int64(dp.Count()))
+					}
+				}
+			}
+		}
+	}
+	return regressions
+}
+
+// observePlan adds executions of a query under a plan and appends a
+// regression if the plan changed and the query got slower. The caller holds
+// the mutex.
+func (p *queryCorrelator) observePlan(regressions []planRegression, attrs pcommon.Map, latency float64, executions int64) []planRegression {
+	cfg := p.config.PlanRegression
+
+	fingerprint, ok := attrs.Get(cfg.FingerprintAttribute)
+	if !ok || fingerprint.AsString() == "" {
+		return regressions
+	}
+	planHash, ok := attrs.Get(cfg.PlanHashAttribute)
+	if !ok || planHash.AsString() == "" {
+		return regressions
+	}
+	hash := planHash.AsString()
+
+	history, exists := p.planIndex[fingerprint.AsString()]
+	if !exists {
+		if p.config.MaxQueriesTracked > 0 && len(p.planIndex) >= p.config.MaxQueriesTracked {
+			return regressions
+		}
+		history = &planHistory{current: &planStats{hash: hash}}
+		p.planIndex[fingerprint.AsString()] = history
+	}
+	if db, ok := attrs.Get("database_name"); ok {
+		history.database = db.Str()
+	}
+	history.lastSeen = time.Now()
+
+	if hash != history.current.hash {
+		switch {
+		case history.baseline != nil && history.baseline.hash == hash:
+			// Back on the previous plan, which is not a regression
+			history.current = history.baseline
+			history.baseline = nil
+		case history.current.executions >= cfg.MinExecutions:
+			history.baseline = history.current
+			history.current = &planStats{hash: hash}
+		default:
+			// The replaced plan ran too few times to compare against, so
+			// the baseline stays
+			history.current = &planStats{hash: hash}
+		}
+		history.alerted = false
+	}
+
+	history.current.executions += executions
+	history.current.totalLatency += latency * float64(executions)
+
+	if history.baseline == nil || history.alerted || history.current.executions < cfg.MinExecutions {
+		return regressions
+	}
+	previous := history.baseline.meanLatency()
+	if previous <= 0 {
+		return regressions
+	}
+	regression := planRegression{
+		fingerprint:        fingerprint.AsString(),
+		database:           history.database,
+		previousHash:       history.baseline.hash,
+		currentHash:        history.current.hash,
+		previousLatency:    previous,
+		currentLatency:     history.current.meanLatency(),
+		previousExecutions: history.baseline.executions,
+		currentExecutions:  history.current.executions,
+	}
+	if regression.latencyIncrease() < cfg.LatencyIncreaseThreshold {
+		return regressions
+	}
+
+	history.alerted = true
+	p.correlationsCreated++
+	return append(regressions, regression)
+}
+
+// appendPlanRegressions adds one db.query.plan_regression data point per
+// regression to md, under a resource of its own
+func (p *queryCorrelator) appendPlanRegressions(md pmetric.Metrics, regressions []planRegression) {
+	if len(regressions) == 0 {
+		return
+	}
+
+	rm := md.ResourceMetrics().AppendEmpty()
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("otelcol/querycorrelator")
+
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(planRegressionMetric)
+	metric.SetDescription("Query plan change with a mean latency increase above the threshold, as the ratio of new to previous latency")
+	metric.SetUnit("1")
+
+	now := pcommon.NewTimestampFromTime(time.Now())
+	dps := metric.SetEmptyGauge().DataPoints()
+	for _, r := range regressions {
+		dp := dps.AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetDoubleValue(r.currentLatency / r.previousLatency)
+
+		attrs := dp.Attributes()
+		attrs.PutStr("event.name", planRegressionEvent)
+		attrs.PutStr(p.config.PlanRegression.FingerprintAttribute, r.fingerprint)
+		if r.database != "" {
+			attrs.PutStr("database_name", r.database)
+		}
+		attrs.PutStr("db.plan.previous_hash", r.previousHash)
+		attrs.PutStr("db.plan.current_hash", r.currentHash)
+		attrs.PutDouble("db.query.previous_latency_ms", r.previousLatency)
+		attrs.PutDouble("db.query.current_latency_ms", r.currentLatency)
+		attrs.PutDouble("db.query.latency_increase_pct", r.latencyIncrease()*100)
+		attrs.PutInt("db.query.previous_executions", r.previousExecutions)
+		attrs.PutInt("db.query.current_executions", r.currentExecutions)
+
+		p.logger.Warn("Query plan regression detected",
+			zap.String("fingerprint", r.fingerprint),
+			zap.String("previous_plan", r.previousHash),
+			zap.String("current_plan", r.currentHash),
+			zap.Float64("previous_latency_ms", r.previousLatency),
+			zap.Float64("current_latency_ms", r.currentLatency))
+	}
+}
+
+// numberValue returns a data point's value as a float64
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
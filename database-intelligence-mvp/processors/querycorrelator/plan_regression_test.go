@@ -0,0 +1,167 @@
+package querycorrelator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func newPlanRegressionCorrelator(t *testing.T) (*queryCorrelator, *consumertest.MetricsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.PlanRegression.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.MetricsSink{}
+	return &queryCorrelator{
+		config:        cfg,
+		logger:        zap.NewNop(),
+		nextConsumer:  sink,
+		queryIndex:    make(map[string]*queryInfo),
+		tableIndex:    make(map[string]*tableInfo),
+		databaseIndex: make(map[string]*databaseInfo),
+		planIndex:     make(map[string]*planHistory),
+	}, sink
+}
+
+func createPlanMetrics(fingerprint, planHash string, meanTimeMs float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("db.query.mean_time")
+	dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetDoubleValue(meanTimeMs)
+	dp.Attributes().PutStr("database_name", "testdb")
+	dp.Attributes().PutStr("db.query.fingerprint", fingerprint)
+	dp.Attributes().PutStr("db.query.plan.hash", planHash)
+	return md
+}
+
+// planRegressionPoints returns the db.query.plan_regression data points sent
+// to the sink
+func planRegressionPoints(sink *consumertest.MetricsSink) []pmetric.NumberDataPoint {
+	var points []pmetric.NumberDataPoint
+	for _, md := range sink.AllMetrics() {
+		rms := md.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			sms := rms.At(i).ScopeMetrics()
+			for j := 0; j < sms.Len(); j++ {
+				metrics := sms.At(j).Metrics()
+				for k := 0; k < metrics.Len(); k++ {
+					if metrics.At(k).Name() != planRegressionMetric {
+						continue
+					}
+					dps := metrics.At(k).Gauge().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						points = append(points, dps.At(l))
+					}
+				}
+			}
+		}
+	}
+	return points
+}
+
+func TestPlanRegression_SlowerPlanAlerts(t *testing.T) {
+	p, sink := newPlanRegressionCorrelator(t)
+	ctx := context.Background()
+
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-a", 10)))
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-b", 25)))
+
+	points := planRegressionPoints(sink)
+	require.Len(t, points, 1)
+
+	dp := points[0]
+	assert.InDelta(t, 2.5, dp.DoubleValue(), 1e-9)
+	want := map[string]any{
+		"event.name":                    planRegressionEvent,
+		"db.query.fingerprint":          "fp1",
+		"database_name":                 "testdb",
+		"db.plan.previous_hash":         "plan-a",
+		"db.plan.current_hash":          "plan-b",
+		"db.query.previous_latency_ms":  10.0,
+		"db.query.current_latency_ms":   25.0,
+		"db.query.latency_increase_pct": 150.0,
+	}
+	for key, value := range want {
+		got, ok := dp.Attributes().Get(key)
+		if assert.True(t, ok, "missing %s", key) {
+			assert.Equal(t, value, got.AsRaw(), key)
+		}
+	}
+
+	// More executions under the same plan do not alert again
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-b", 30)))
+	assert.Len(t, planRegressionPoints(sink), 1)
+}
+
+func TestPlanRegression_StableLatencyDoesNotAlert(t *testing.T) {
+	p, sink := newPlanRegressionCorrelator(t)
+	ctx := context.Background()
+
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-a", 10)))
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-b", 11)))
+
+	assert.Empty(t, planRegressionPoints(sink))
+}
+
+func TestPlanRegression_Threshold(t *testing.T) {
+	p, sink := newPlanRegressionCorrelator(t)
+	p.config.PlanRegression.LatencyIncreaseThreshold = 2
+	ctx := context.Background()
+
+	// 150% slower is below a 200% threshold
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-a", 10)))
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-b", 25)))
+	assert.Empty(t, planRegressionPoints(sink))
+
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp2", "plan-a", 10)))
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp2", "plan-b", 40)))
+	assert.Len(t, planRegressionPoints(sink), 1)
+}
+
+func TestPlanRegression_MinExecutions(t *testing.T) {
+	p, sink := newPlanRegressionCorrelator(t)
+	p.config.PlanRegression.MinExecutions = 10
+	ctx := context.Background()
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	addHistogram := func(plan string, count uint64, sum float64) {
+		metric := metrics.AppendEmpty()
+		metric.SetName("db.query.duration")
+		dp := metric.SetEmptyHistogram().DataPoints().AppendEmpty()
+		dp.SetCount(count)
+		dp.SetSum(sum)
+		dp.Attributes().PutStr("db.query.fingerprint", "fp1")
+		dp.Attributes().PutStr("db.query.plan.hash", plan)
+	}
+	addHistogram("plan-a", 10, 100) // 10ms mean, enough executions
+	addHistogram("plan-b", 5, 150)  // 30ms mean, too few executions
+	require.NoError(t, p.ConsumeMetrics(ctx, md))
+	assert.Empty(t, planRegressionPoints(sink))
+
+	md = pmetric.NewMetrics()
+	metrics = md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	addHistogram("plan-b", 5, 150)
+	require.NoError(t, p.ConsumeMetrics(ctx, md))
+
+	points := planRegressionPoints(sink)
+	require.Len(t, points, 1)
+	executions, _ := points[0].Attributes().Get("db.query.current_executions")
+	assert.Equal(t, int64(10), executions.Int())
+}
+
+func TestPlanRegression_Disabled(t *testing.T) {
+	p, sink := newPlanRegressionCorrelator(t)
+	p.config.PlanRegression.Enabled = false
+	ctx := context.Background()
+
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-a", 10)))
+	require.NoError(t, p.ConsumeMetrics(ctx, createPlanMetrics("fp1", "plan-b", 100)))
+	assert.Empty(t, planRegressionPoints(sink))
+}
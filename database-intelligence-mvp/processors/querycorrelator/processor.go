@@ -26,6 +26,7 @@ type queryCorrelator struct {
 	queryIndex    map[string]*queryInfo
 	tableIndex    map[string]*tableInfo
 	databaseIndex map[string]*databaseInfo
+	planIndex     map[string]*planHistory
 	mutex         sync.RWMutex
 
 	// Metrics
@@ -95,6 +96,11 @@ func (p *queryCorrelator) ConsumeMetrics(ctx context.Context, md pmetric.Metrics
 	// Second pass: enrich metrics with correlations
 	p.enrichMetrics(md)
 	
+	// Alert on plan changes that made a query slower
+	if p.config.PlanRegression.Enabled {
+		p.appendPlanRegressions(md, p.detectPlanRegressions(md))
+	}
+	
 	// Pass to next consumer
 	return p.nextConsumer.ConsumeMetrics(ctx, md)
 }
@@ -498,9 +504,15 @@ func (p *queryCorrelator) cleanupOldData() {
 			delete(p.queryIndex, id)
 		}
 	}
+	for fingerprint, history := range p.planIndex {
+		if history.lastSeen.Before(cutoff) {
+			delete(p.planIndex, fingerprint)
+		}
+	}
 	
 	p.logger.Debug("Cleaned up correlation data",
 		zap.Int("remaining_queries", len(p.queryIndex)),
+		zap.Int("remaining_plan_histories", len(p.planIndex)),
 		zap.Int("remaining_tables", len(p.tableIndex)),
 		zap.Int("remaining_databases", len(p.databaseIndex)),
 	)
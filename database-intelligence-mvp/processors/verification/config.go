@@ -5,6 +5,9 @@ package verification
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -30,12 +33,27 @@ type Config struct {
 	// RequireEntitySynthesis enforces entity synthesis attributes
 	RequireEntitySynthesis bool `mapstructure:"require_entity_synthesis"`
 	
+	// EntitySynthesis fills in entity.guid on records that arrive without it
+	EntitySynthesis EntitySynthesisConfig `mapstructure:"entity_synthesis"`
+	
 	// ExportFeedbackAsLogs exports feedback events as telemetry
 	ExportFeedbackAsLogs bool `mapstructure:"export_feedback_as_logs"`
 	
 	// FeedbackEndpoint is the endpoint to send feedback (optional)
 	FeedbackEndpoint string `mapstructure:"feedback_endpoint"`
 	
+	// FeedbackChannelSize sets how many feedback events can be queued before dropping
+	FeedbackChannelSize int `mapstructure:"feedback_channel_size"`
+	
+	// FeedbackCoalescing merges repeated feedback events instead of dropping them
+	FeedbackCoalescing FeedbackCoalescingConfig `mapstructure:"feedback_coalescing"`
+	
+	// HealthEndpoint serves the current health report over HTTP (optional)
+	HealthEndpoint HealthEndpointConfig `mapstructure:"health_endpoint"`
+	
+	// HealthReportOutput writes periodic health reports to a file and/or webhook (optional)
+	HealthReportOutput HealthReportOutputConfig `mapstructure:"health_report_output"`
+	
 	// VerificationQueries are custom NRQL queries to run for verification
 	VerificationQueries []VerificationQuery `mapstructure:"verification_queries"`
 	
@@ -63,6 +81,13 @@ type Config struct {
 	// AutoTuningConfig configures auto-tuning behavior
 	AutoTuningConfig AutoTuningConfig `mapstructure:"auto_tuning_config"`
 	
+	// ConsumerTimeout bounds each call to the next consumer. 0, the default,
+	// disables it. Auto-tunable once set.
+	ConsumerTimeout time.Duration `mapstructure:"consumer_timeout"`
+	
+	// QualitySampleRate is the fraction of records that get quality validation (0.0-1.0). Auto-tunable.
+	QualitySampleRate float64 `mapstructure:"quality_sample_rate"`
+	
 	// EnableSelfHealing enables automatic issue remediation
 	EnableSelfHealing bool `mapstructure:"enable_self_healing"`
 	
@@ -73,6 +98,116 @@ type Config struct {
 	SelfHealingConfig SelfHealingConfig `mapstructure:"self_healing_config"`
 }
 
+// FeedbackCoalescingConfig configures merging of repeated feedback events
+type FeedbackCoalescingConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Window  time.Duration `mapstructure:"window"`
+}
+
+// EntitySynthesisConfig configures synthesizing entity.guid, entity.type and
+// entity.name for records that lack them. The entity type comes from the
+// database system and the entity name joins the identifier attributes, so
+// every record for the same database gets the same guid. Attributes are
+// read from the record first, then its resource.
+type EntitySynthesisConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccountID is the New Relic account encoded in the guid
+	AccountID string `mapstructure:"account_id"`
+	// Domain is the entity domain encoded in the guid
+	Domain string `mapstructure:"domain"`
+	// SystemAttribute holds the database system, e.g. postgresql
+	SystemAttribute string `mapstructure:"system_attribute"`
+	// EntityTypes maps database systems to entity types. Records for other
+	// systems are not synthesized.
+	EntityTypes map[string]string `mapstructure:"entity_types"`
+	// IdentifierAttributes identify the database; records missing any of
+	// them are not synthesized
+	IdentifierAttributes []string `mapstructure:"identifier_attributes"`
+	// Overwrite replaces an entity.guid set upstream
+	Overwrite bool `mapstructure:"overwrite"`
+}
+
+// HealthEndpointConfig configures the HTTP health-report endpoint. When enabled,
+// /health serves the report as JSON and /metrics serves key gauges in
+// Prometheus text format.
+type HealthEndpointConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// HealthReportOutputConfig configures destinations for the health report
+// outside the logs. Every Interval the report is appended as one JSON line
+// to File.Path and/or POSTed to Webhook.URL; either destination may be left
+// unset.
+type HealthReportOutputConfig struct {
+	Interval time.Duration             `mapstructure:"interval"`
+	File     HealthReportFileConfig    `mapstructure:"file"`
+	Webhook  HealthReportWebhookConfig `mapstructure:"webhook"`
+}
+
+// HealthReportFileConfig configures the rotating report file
+type HealthReportFileConfig struct {
+	Path string `mapstructure:"path"`
+	// MaxSizeMiB rotates the file once a report would take it past this size
+	MaxSizeMiB int `mapstructure:"max_size_mib"`
+	// MaxFiles is how many rotated files (path.1, path.2, ...) are kept
+	MaxFiles int `mapstructure:"max_files"`
+}
+
+// HealthReportWebhookConfig configures posting reports to a webhook.
+// Delivery never blocks the processor: reports that do not fit in the queue
+// or fail every retry are dropped and counted.
+type HealthReportWebhookConfig struct {
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+	// MaxRetries is how many times a failed post is retried
+	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBackoff is the wait before the first retry; it doubles each retry
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// QueueSize is how many reports can wait for delivery
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// enabled reports whether any destination is configured
+func (cfg *HealthReportOutputConfig) enabled() bool {
+	return cfg.File.Path != "" || cfg.Webhook.URL != ""
+}
+
+// validate checks an enabled health report output
+func (cfg *HealthReportOutputConfig) validate() error {
+	if cfg.Interval <= 0 {
+		return errors.New("health_report_output.interval must be positive")
+	}
+	if cfg.File.Path != "" {
+		if cfg.File.MaxSizeMiB <= 0 {
+			return errors.New("health_report_output.file.max_size_mib must be positive")
+		}
+		if cfg.File.MaxFiles < 0 {
+			return errors.New("health_report_output.file.max_files cannot be negative")
+		}
+	}
+	if cfg.Webhook.URL != "" {
+		u, err := url.Parse(cfg.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("health_report_output.webhook.url must be an http or https URL, got %q", cfg.Webhook.URL)
+		}
+		if cfg.Webhook.Timeout <= 0 {
+			return errors.New("health_report_output.webhook.timeout must be positive")
+		}
+		if cfg.Webhook.MaxRetries < 0 {
+			return errors.New("health_report_output.webhook.max_retries cannot be negative")
+		}
+		if cfg.Webhook.MaxRetries > 0 && cfg.Webhook.RetryBackoff <= 0 {
+			return errors.New("health_report_output.webhook.retry_backoff must be positive when retries are enabled")
+		}
+		if cfg.Webhook.QueueSize <= 0 {
+			return errors.New("health_report_output.webhook.queue_size must be positive")
+		}
+	}
+	return nil
+}
+
 // VerificationQuery defines a custom verification query
 type VerificationQuery struct {
 	Name        string        `mapstructure:"name"`
@@ -85,9 +220,14 @@ type VerificationQuery struct {
 // HealthThresholdsConfig defines system resource alert thresholds
 type HealthThresholdsConfig struct {
 	MemoryPercent  float64       `mapstructure:"memory_percent"`
+	// MemoryLimitMiB is the memory budget MemoryPercent is measured against.
+	// When zero the cgroup limit, or total system memory, is used.
+	MemoryLimitMiB int           `mapstructure:"memory_limit_mib"`
 	CPUPercent     float64       `mapstructure:"cpu_percent"`
 	DiskPercent    float64       `mapstructure:"disk_percent"`
 	NetworkLatency time.Duration `mapstructure:"network_latency"`
+	// DiskPath is the data/spool directory whose filesystem usage is monitored
+	DiskPath       string        `mapstructure:"disk_path"`
 }
 
 // QualityRulesConfig defines data quality validation rules
@@ -96,6 +236,10 @@ type QualityRulesConfig struct {
 	EnableSchemaValidation bool              `mapstructure:"enable_schema_validation"`
 	CardinalityLimits      map[string]int    `mapstructure:"cardinality_limits"`
 	DataTypeValidation     map[string]string `mapstructure:"data_type_validation"`
+	// DuplicateCacheSize caps the number of tracked attribute values (LRU eviction)
+	DuplicateCacheSize     int               `mapstructure:"duplicate_cache_size"`
+	// DuplicateCacheTTL expires tracked values that have not been seen recently
+	DuplicateCacheTTL      time.Duration     `mapstructure:"duplicate_cache_ttl"`
 }
 
 // PIIDetectionConfig configures PII detection and sanitization
@@ -143,6 +287,30 @@ func (cfg *Config) Validate() error {
 		return errors.New("min_normalization_rate must be between 0.0 and 1.0")
 	}
 	
+	if cfg.FeedbackChannelSize <= 0 {
+		return errors.New("feedback_channel_size must be positive")
+	}
+	
+	if cfg.FeedbackCoalescing.Enabled && cfg.FeedbackCoalescing.Window <= 0 {
+		return errors.New("feedback_coalescing.window must be positive when coalescing is enabled")
+	}
+	
+	if cfg.EntitySynthesis.Enabled {
+		if err := cfg.EntitySynthesis.validate(); err != nil {
+			return err
+		}
+	}
+	
+	if cfg.HealthEndpoint.Enabled && cfg.HealthEndpoint.Endpoint == "" {
+		return errors.New("health_endpoint.endpoint must be set when the health endpoint is enabled")
+	}
+	
+	if cfg.HealthReportOutput.enabled() {
+		if err := cfg.HealthReportOutput.validate(); err != nil {
+			return err
+		}
+	}
+	
 	// Validate health check configuration
 	if cfg.EnableContinuousHealthChecks {
 		if cfg.HealthCheckInterval <= 0 {
@@ -160,6 +328,14 @@ func (cfg *Config) Validate() error {
 		if cfg.HealthThresholds.DiskPercent < 0 || cfg.HealthThresholds.DiskPercent > 100 {
 			return errors.New("health_thresholds.disk_percent must be between 0 and 100")
 		}
+		
+		if cfg.HealthThresholds.NetworkLatency < 0 {
+			return errors.New("health_thresholds.network_latency cannot be negative")
+		}
+	}
+	
+	if cfg.HealthThresholds.MemoryLimitMiB < 0 {
+		return errors.New("health_thresholds.memory_limit_mib cannot be negative")
 	}
 	
 	// Validate auto-tuning configuration
@@ -175,6 +351,47 @@ func (cfg *Config) Validate() error {
 		if cfg.AutoTuningConfig.MaxParameterChange < 0 || cfg.AutoTuningConfig.MaxParameterChange > 1 {
 			return errors.New("auto_tuning_config.max_parameter_change must be between 0.0 and 1.0")
 		}
+		
+		if cfg.AutoTuningConfig.HistoryRetentionHours < 0 {
+			return errors.New("auto_tuning_config.history_retention_hours cannot be negative")
+		}
+	}
+	
+	// Validate quality rules
+	if cfg.QualityRules.DuplicateCacheSize <= 0 {
+		return errors.New("quality_rules.duplicate_cache_size must be positive")
+	}
+	
+	if cfg.QualityRules.DuplicateCacheTTL < 0 {
+		return errors.New("quality_rules.duplicate_cache_ttl cannot be negative")
+	}
+	
+	for _, field := range cfg.QualityRules.RequiredFields {
+		if field == "" {
+			return errors.New("quality_rules.required_fields cannot contain an empty field name")
+		}
+	}
+	
+	for field, limit := range cfg.QualityRules.CardinalityLimits {
+		if limit <= 0 {
+			return fmt.Errorf("quality_rules.cardinality_limits[%s] must be positive, got %d", field, limit)
+		}
+	}
+	
+	for field, dataType := range cfg.QualityRules.DataTypeValidation {
+		switch dataType {
+		case "string", "int", "double", "bool":
+		default:
+			return fmt.Errorf("quality_rules.data_type_validation[%s] must be 'string', 'int', 'double', or 'bool', got %q", field, dataType)
+		}
+	}
+	
+	if cfg.ConsumerTimeout < 0 {
+		return errors.New("consumer_timeout cannot be negative")
+	}
+	
+	if cfg.QualitySampleRate <= 0 || cfg.QualitySampleRate > 1 {
+		return errors.New("quality_sample_rate must be greater than 0.0 and at most 1.0")
 	}
 	
 	// Validate self-healing configuration
@@ -200,6 +417,12 @@ func (cfg *Config) Validate() error {
 		if cfg.PIIDetection.SensitivityLevel != "" && !validSensitivityLevels[cfg.PIIDetection.SensitivityLevel] {
 			return errors.New("pii_detection.sensitivity_level must be 'low', 'medium', or 'high'")
 		}
+		
+		for _, pattern := range cfg.PIIDetection.CustomPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("pii_detection.custom_patterns contains an invalid pattern %q: %w", pattern, err)
+			}
+		}
 	}
 	
 	// Validate custom queries
@@ -213,11 +436,44 @@ func (cfg *Config) Validate() error {
 		if q.Comparison != "gt" && q.Comparison != "lt" && q.Comparison != "eq" {
 			return errors.New("verification query comparison must be 'gt', 'lt', or 'eq'")
 		}
+		if q.Interval < 0 {
+			return fmt.Errorf("verification query %s interval cannot be negative", q.Name)
+		}
 	}
 	
 	return nil
 }
 
+// validate checks an enabled entity synthesis rule
+func (cfg *EntitySynthesisConfig) validate() error {
+	if cfg.AccountID == "" {
+		return errors.New("entity_synthesis.account_id must be set when entity synthesis is enabled")
+	}
+	if cfg.Domain == "" {
+		return errors.New("entity_synthesis.domain cannot be empty")
+	}
+	if cfg.SystemAttribute == "" {
+		return errors.New("entity_synthesis.system_attribute cannot be empty")
+	}
+	if len(cfg.EntityTypes) == 0 {
+		return errors.New("entity_synthesis.entity_types must map at least one database system")
+	}
+	for system, entityType := range cfg.EntityTypes {
+		if entityType == "" {
+			return fmt.Errorf("entity_synthesis.entity_types[%s] cannot be empty", system)
+		}
+	}
+	if len(cfg.IdentifierAttributes) == 0 {
+		return errors.New("entity_synthesis.identifier_attributes must name at least one attribute")
+	}
+	for _, attr := range cfg.IdentifierAttributes {
+		if attr == "" {
+			return errors.New("entity_synthesis.identifier_attributes cannot contain an empty attribute name")
+		}
+	}
+	return nil
+}
+
 // createDefaultConfig creates the default configuration for the verification processor
 func createDefaultConfig() component.Config {
 	return &Config{
@@ -227,7 +483,39 @@ func createDefaultConfig() component.Config {
 		MinEntityCorrelationRate:   0.8, // 80%
 		MinNormalizationRate:       0.9, // 90%
 		RequireEntitySynthesis:     true,
+		EntitySynthesis: EntitySynthesisConfig{
+			Enabled:         false,
+			Domain:          "INFRA",
+			SystemAttribute: "db.system",
+			EntityTypes: map[string]string{
+				"postgresql": "POSTGRESQLINSTANCE",
+				"mysql":      "MYSQLNODE",
+			},
+			IdentifierAttributes: []string{"server.address", "server.port", "db.name"},
+		},
 		ExportFeedbackAsLogs:       true,
+		FeedbackChannelSize:        1000,
+		FeedbackCoalescing: FeedbackCoalescingConfig{
+			Enabled: false,
+			Window:  30 * time.Second,
+		},
+		HealthEndpoint: HealthEndpointConfig{
+			Enabled:  false,
+			Endpoint: "localhost:13134",
+		},
+		HealthReportOutput: HealthReportOutputConfig{
+			Interval: 5 * time.Minute,
+			File: HealthReportFileConfig{
+				MaxSizeMiB: 10,
+				MaxFiles:   5,
+			},
+			Webhook: HealthReportWebhookConfig{
+				Timeout:      10 * time.Second,
+				MaxRetries:   3,
+				RetryBackoff: time.Second,
+				QueueSize:    10,
+			},
+		},
 		
 		// Continuous health checks
 		EnableContinuousHealthChecks: true,
@@ -257,6 +545,8 @@ func createDefaultConfig() component.Config {
 				"error_count":   "int",
 				"database_name": "string",
 			},
+			DuplicateCacheSize: 10000,
+			DuplicateCacheTTL:  time.Hour,
 		},
 		
 		// PII detection
@@ -280,6 +570,7 @@ func createDefaultConfig() component.Config {
 			MaxParameterChange:    0.2,   // 20%
 			HistoryRetentionHours: 24,    // 24 hours
 		},
+		QualitySampleRate: 1.0,
 		
 		// Self-healing
 		EnableSelfHealing:   true,
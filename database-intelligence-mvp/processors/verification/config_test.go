@@ -0,0 +1,238 @@
+package verification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "zero verification interval",
+			modify: func(cfg *Config) {
+				cfg.VerificationInterval = 0
+			},
+			wantErr: "verification_interval must be positive",
+		},
+		{
+			name: "zero verification interval with periodic verification disabled",
+			modify: func(cfg *Config) {
+				cfg.EnablePeriodicVerification = false
+				cfg.VerificationInterval = 0
+			},
+		},
+		{
+			name: "correlation rate above one",
+			modify: func(cfg *Config) {
+				cfg.MinEntityCorrelationRate = 1.5
+			},
+			wantErr: "min_entity_correlation_rate must be between 0.0 and 1.0",
+		},
+		{
+			name: "zero feedback channel size",
+			modify: func(cfg *Config) {
+				cfg.FeedbackChannelSize = 0
+			},
+			wantErr: "feedback_channel_size must be positive",
+		},
+		{
+			name: "coalescing without window",
+			modify: func(cfg *Config) {
+				cfg.FeedbackCoalescing = FeedbackCoalescingConfig{Enabled: true}
+			},
+			wantErr: "feedback_coalescing.window must be positive",
+		},
+		{
+			name: "entity synthesis without account",
+			modify: func(cfg *Config) {
+				cfg.EntitySynthesis.Enabled = true
+			},
+			wantErr: "entity_synthesis.account_id must be set",
+		},
+		{
+			name: "entity synthesis without identifier attributes",
+			modify: func(cfg *Config) {
+				cfg.EntitySynthesis.Enabled = true
+				cfg.EntitySynthesis.AccountID = "12345"
+				cfg.EntitySynthesis.IdentifierAttributes = nil
+			},
+			wantErr: "entity_synthesis.identifier_attributes must name at least one attribute",
+		},
+		{
+			name: "entity synthesis with empty entity type",
+			modify: func(cfg *Config) {
+				cfg.EntitySynthesis.Enabled = true
+				cfg.EntitySynthesis.AccountID = "12345"
+				cfg.EntitySynthesis.EntityTypes["mysql"] = ""
+			},
+			wantErr: "entity_synthesis.entity_types[mysql] cannot be empty",
+		},
+		{
+			name: "health report output disabled by default",
+			modify: func(cfg *Config) {
+				cfg.HealthReportOutput.Interval = 0
+			},
+		},
+		{
+			name: "health report output without interval",
+			modify: func(cfg *Config) {
+				cfg.HealthReportOutput.File.Path = "/var/lib/otelcol/health.jsonl"
+				cfg.HealthReportOutput.Interval = 0
+			},
+			wantErr: "health_report_output.interval must be positive",
+		},
+		{
+			name: "health report webhook with invalid url",
+			modify: func(cfg *Config) {
+				cfg.HealthReportOutput.Webhook.URL = "hooks.example.com/health"
+			},
+			wantErr: "health_report_output.webhook.url must be an http or https URL",
+		},
+		{
+			name: "health report webhook without queue",
+			modify: func(cfg *Config) {
+				cfg.HealthReportOutput.Webhook.URL = "https://hooks.example.com/health"
+				cfg.HealthReportOutput.Webhook.QueueSize = 0
+			},
+			wantErr: "health_report_output.webhook.queue_size must be positive",
+		},
+		{
+			name: "zero health check interval",
+			modify: func(cfg *Config) {
+				cfg.HealthCheckInterval = 0
+			},
+			wantErr: "health_check_interval must be positive",
+		},
+		{
+			name: "negative network latency threshold",
+			modify: func(cfg *Config) {
+				cfg.HealthThresholds.NetworkLatency = -time.Second
+			},
+			wantErr: "health_thresholds.network_latency cannot be negative",
+		},
+		{
+			name: "negative memory limit",
+			modify: func(cfg *Config) {
+				cfg.HealthThresholds.MemoryLimitMiB = -1
+			},
+			wantErr: "health_thresholds.memory_limit_mib cannot be negative",
+		},
+		{
+			name: "zero auto-tuning interval",
+			modify: func(cfg *Config) {
+				cfg.AutoTuningInterval = 0
+			},
+			wantErr: "auto_tuning_interval must be positive",
+		},
+		{
+			name: "negative history retention",
+			modify: func(cfg *Config) {
+				cfg.AutoTuningConfig.HistoryRetentionHours = -1
+			},
+			wantErr: "auto_tuning_config.history_retention_hours cannot be negative",
+		},
+		{
+			name: "empty required field",
+			modify: func(cfg *Config) {
+				cfg.QualityRules.RequiredFields = append(cfg.QualityRules.RequiredFields, "")
+			},
+			wantErr: "quality_rules.required_fields cannot contain an empty field name",
+		},
+		{
+			name: "zero cardinality limit",
+			modify: func(cfg *Config) {
+				cfg.QualityRules.CardinalityLimits["query_id"] = 0
+			},
+			wantErr: "quality_rules.cardinality_limits[query_id] must be positive",
+		},
+		{
+			name: "unknown data type",
+			modify: func(cfg *Config) {
+				cfg.QualityRules.DataTypeValidation["duration_ms"] = "float"
+			},
+			wantErr: `quality_rules.data_type_validation[duration_ms] must be 'string', 'int', 'double', or 'bool', got "float"`,
+		},
+		{
+			name: "zero duplicate cache size",
+			modify: func(cfg *Config) {
+				cfg.QualityRules.DuplicateCacheSize = 0
+			},
+			wantErr: "quality_rules.duplicate_cache_size must be positive",
+		},
+		{
+			name: "zero quality sample rate",
+			modify: func(cfg *Config) {
+				cfg.QualitySampleRate = 0
+			},
+			wantErr: "quality_sample_rate must be greater than 0.0 and at most 1.0",
+		},
+		{
+			name: "zero self-healing interval",
+			modify: func(cfg *Config) {
+				cfg.SelfHealingInterval = 0
+			},
+			wantErr: "self_healing_interval must be positive",
+		},
+		{
+			name: "backoff multiplier of one",
+			modify: func(cfg *Config) {
+				cfg.SelfHealingConfig.BackoffMultiplier = 1.0
+			},
+			wantErr: "self_healing_config.backoff_multiplier must be greater than 1.0",
+		},
+		{
+			name: "unknown sensitivity level",
+			modify: func(cfg *Config) {
+				cfg.PIIDetection.SensitivityLevel = "extreme"
+			},
+			wantErr: "pii_detection.sensitivity_level must be 'low', 'medium', or 'high'",
+		},
+		{
+			name: "invalid custom PII pattern",
+			modify: func(cfg *Config) {
+				cfg.PIIDetection.CustomPatterns = []string{`\d{3}-\d{2}`, `(unclosed`}
+			},
+			wantErr: `pii_detection.custom_patterns contains an invalid pattern "(unclosed"`,
+		},
+		{
+			name: "unknown query comparison",
+			modify: func(cfg *Config) {
+				cfg.VerificationQueries[0].Comparison = "gte"
+			},
+			wantErr: "verification query comparison must be 'gt', 'lt', or 'eq'",
+		},
+		{
+			name: "negative query interval",
+			modify: func(cfg *Config) {
+				cfg.VerificationQueries[0].Interval = -time.Minute
+			},
+			wantErr: "verification query integration_errors interval cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"encoding/base64"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Entity attributes New Relic uses to correlate records with an entity
+const (
+	attrEntityGUID = "entity.guid"
+	attrEntityType = "entity.type"
+	attrEntityName = "entity.name"
+)
+
+// entitySynthesizer fills in the entity attributes on records that arrive
+// without them, following the entity synthesis rule in EntitySynthesisConfig
+type entitySynthesizer struct {
+	accountID            string
+	domain               string
+	systemAttribute      string
+	entityTypes          map[string]string
+	identifierAttributes []string
+	overwrite            bool
+}
+
+func newEntitySynthesizer(cfg EntitySynthesisConfig) *entitySynthesizer {
+	entityTypes := make(map[string]string, len(cfg.EntityTypes))
+	for system, entityType := range cfg.EntityTypes {
+		entityTypes[strings.ToLower(system)] = entityType
+	}
+	return &entitySynthesizer{
+		accountID:            cfg.AccountID,
+		domain:               cfg.Domain,
+		systemAttribute:      cfg.SystemAttribute,
+		entityTypes:          entityTypes,
+		identifierAttributes: cfg.IdentifierAttributes,
+		overwrite:            cfg.Overwrite,
+	}
+}
+
+// synthesize sets entity.guid, entity.type and entity.name on attrs and
+// reports whether it did. Records that already carry a guid are left alone
+// unless overwrite is set, as are records whose database system has no
+// entity type or that lack one of the identifier attributes.
+func (s *entitySynthesizer) synthesize(resource, attrs pcommon.Map) bool {
+	if _, ok := attrs.Get(attrEntityGUID); ok && !s.overwrite {
+		return false
+	}
+
+	system, ok := lookupAttribute(resource, attrs, s.systemAttribute)
+	if !ok {
+		return false
+	}
+	entityType, ok := s.entityTypes[strings.ToLower(system)]
+	if !ok {
+		return false
+	}
+
+	parts := make([]string, 0, len(s.identifierAttributes))
+	for _, key := range s.identifierAttributes {
+		value, ok := lookupAttribute(resource, attrs, key)
+		if !ok {
+			return false
+		}
+		parts = append(parts, value)
+	}
+	name := strings.Join(parts, ":")
+
+	attrs.PutStr(attrEntityGUID, entityGUID(s.accountID, s.domain, entityType, name))
+	attrs.PutStr(attrEntityType, entityType)
+	attrs.PutStr(attrEntityName, name)
+	return true
+}
+
+// lookupAttribute returns a non-empty attribute from the record, falling
+// back to the resource
+func lookupAttribute(resource, attrs pcommon.Map, key string) (string, bool) {
+	for _, m := range []pcommon.Map{attrs, resource} {
+		if v, ok := m.Get(key); ok {
+			if s := v.AsString(); s != "" {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+// entityGUID builds a guid in New Relic's format, the unpadded base64 of
+// accountId|DOMAIN|TYPE|identifier, where the identifier is a hash of the
+// entity name. The same inputs always give the same guid.
+func entityGUID(accountID, domain, entityType, name string) string {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	identifier := strconv.FormatUint(h.Sum64(), 10)
+
+	raw := strings.Join([]string{accountID, domain, entityType, identifier}, "|")
+	return base64.RawStdEncoding.EncodeToString([]byte(raw))
+}
@@ -0,0 +1,181 @@
+package verification
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func entitySynthesisConfig() EntitySynthesisConfig {
+	cfg := createDefaultConfig().(*Config).EntitySynthesis
+	cfg.Enabled = true
+	cfg.AccountID = "12345"
+	return cfg
+}
+
+// databaseLogs builds one resource per entry of resources, each with a
+// single log record carrying recordAttrs
+func databaseLogs(t *testing.T, resources []map[string]any, recordAttrs map[string]any) plog.Logs {
+	t.Helper()
+	ld := plog.NewLogs()
+	for _, attrs := range resources {
+		rl := ld.ResourceLogs().AppendEmpty()
+		require.NoError(t, rl.Resource().Attributes().FromRaw(attrs))
+		lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		require.NoError(t, lr.Attributes().FromRaw(recordAttrs))
+	}
+	return ld
+}
+
+func TestVerificationProcessor_EntitySynthesis(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EntitySynthesis = entitySynthesisConfig()
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	orders := map[string]any{"db.system": "postgresql", "db.name": "orders", "server.address": "db1", "server.port": int64(5432)}
+	billing := map[string]any{"db.system": "postgresql", "db.name": "billing", "server.address": "db1", "server.port": int64(5432)}
+	ld := databaseLogs(t, []map[string]any{orders, orders, billing}, map[string]any{"database_name": "orders"})
+	require.NoError(t, processor.ConsumeLogs(context.Background(), ld))
+
+	guid := func(i int) string {
+		attrs := ld.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+		v, ok := attrs.Get(attrEntityGUID)
+		require.True(t, ok, "record %d has no entity.guid", i)
+		return v.Str()
+	}
+	assert.Equal(t, guid(0), guid(1), "records for the same database share a guid")
+	assert.NotEqual(t, guid(0), guid(2), "different databases get different guids")
+
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	assert.Equal(t, map[string]any{
+		"database_name": "orders",
+		"entity.guid":   guid(0),
+		"entity.type":   "POSTGRESQLINSTANCE",
+		"entity.name":   "db1:5432:orders",
+	}, attrs.AsRaw())
+
+	snap := processor.snapshotMetrics()
+	assert.Equal(t, int64(3), snap.entitiesSynthesized)
+	assert.Equal(t, int64(3), snap.entitiesCreated)
+	assert.Equal(t, 1.0, snap.databases["orders"].entityCorrelationRate)
+}
+
+func TestEntitySynthesizer_Synthesize(t *testing.T) {
+	resource := map[string]any{"db.system": "PostgreSQL", "db.name": "orders", "server.address": "db1", "server.port": int64(5432)}
+
+	tests := []struct {
+		name     string
+		modify   func(cfg *EntitySynthesisConfig)
+		resource map[string]any
+		record   map[string]any
+		want     bool
+		wantName string
+		wantType string
+	}{
+		{
+			name:     "resource attributes",
+			resource: resource,
+			want:     true,
+			wantName: "db1:5432:orders",
+			wantType: "POSTGRESQLINSTANCE",
+		},
+		{
+			name:     "record attributes take precedence",
+			resource: resource,
+			record:   map[string]any{"db.name": "billing"},
+			want:     true,
+			wantName: "db1:5432:billing",
+			wantType: "POSTGRESQLINSTANCE",
+		},
+		{
+			name:     "missing identifier attribute",
+			resource: map[string]any{"db.system": "postgresql", "db.name": "orders", "server.address": "db1"},
+		},
+		{
+			name:     "empty identifier attribute",
+			resource: map[string]any{"db.system": "postgresql", "db.name": "", "server.address": "db1", "server.port": int64(5432)},
+		},
+		{
+			name:     "unmapped database system",
+			resource: map[string]any{"db.system": "mongodb", "db.name": "orders", "server.address": "db1", "server.port": int64(27017)},
+		},
+		{
+			name:     "existing guid is kept",
+			resource: resource,
+			record:   map[string]any{"entity.guid": "upstream"},
+		},
+		{
+			name:     "existing guid is overwritten",
+			modify:   func(cfg *EntitySynthesisConfig) { cfg.Overwrite = true },
+			resource: resource,
+			record:   map[string]any{"entity.guid": "upstream"},
+			want:     true,
+			wantName: "db1:5432:orders",
+			wantType: "POSTGRESQLINSTANCE",
+		},
+		{
+			name: "overridden rule",
+			modify: func(cfg *EntitySynthesisConfig) {
+				cfg.SystemAttribute = "db.vendor"
+				cfg.EntityTypes = map[string]string{"aurora": "AURORAINSTANCE"}
+				cfg.IdentifierAttributes = []string{"cluster", "db.name"}
+			},
+			resource: map[string]any{"db.vendor": "aurora", "cluster": "prod", "db.name": "orders"},
+			want:     true,
+			wantName: "prod:orders",
+			wantType: "AURORAINSTANCE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := entitySynthesisConfig()
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			s := newEntitySynthesizer(cfg)
+
+			res := pcommon.NewMap()
+			require.NoError(t, res.FromRaw(tt.resource))
+			attrs := pcommon.NewMap()
+			require.NoError(t, attrs.FromRaw(tt.record))
+			before := attrs.AsRaw()
+
+			got := s.synthesize(res, attrs)
+			assert.Equal(t, tt.want, got)
+			if !tt.want {
+				assert.Equal(t, before, attrs.AsRaw(), "attributes unchanged")
+				return
+			}
+
+			name, _ := attrs.Get(attrEntityName)
+			assert.Equal(t, tt.wantName, name.Str())
+			entityType, _ := attrs.Get(attrEntityType)
+			assert.Equal(t, tt.wantType, entityType.Str())
+			guid, _ := attrs.Get(attrEntityGUID)
+			assert.Equal(t, entityGUID(cfg.AccountID, cfg.Domain, tt.wantType, tt.wantName), guid.Str())
+		})
+	}
+}
+
+func TestEntityGUID(t *testing.T) {
+	guid := entityGUID("12345", "INFRA", "POSTGRESQLINSTANCE", "db1:5432:orders")
+	assert.Equal(t, guid, entityGUID("12345", "INFRA", "POSTGRESQLINSTANCE", "db1:5432:orders"))
+	assert.NotEqual(t, guid, entityGUID("12345", "INFRA", "POSTGRESQLINSTANCE", "db1:5432:billing"))
+	assert.NotEqual(t, guid, entityGUID("67890", "INFRA", "POSTGRESQLINSTANCE", "db1:5432:orders"))
+
+	raw, err := base64.RawStdEncoding.DecodeString(guid)
+	require.NoError(t, err)
+	assert.Regexp(t, `^12345\|INFRA\|POSTGRESQLINSTANCE\|\d+$`, string(raw))
+}
@@ -5,12 +5,14 @@ go 1.21
 toolchain go1.24.3
 
 require (
+	github.com/hashicorp/golang-lru/v2 v2.0.7
 	github.com/stretchr/testify v1.10.0
 	go.opentelemetry.io/collector/component v0.109.0
 	go.opentelemetry.io/collector/consumer v0.109.0
 	go.opentelemetry.io/collector/consumer/consumertest v0.109.0
 	go.opentelemetry.io/collector/pdata v0.109.0
 	go.opentelemetry.io/collector/processor v0.109.0
+	go.uber.org/goleak v1.3.0
 	go.uber.org/zap v1.27.0
 )
 
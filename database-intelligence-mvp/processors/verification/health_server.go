@@ -0,0 +1,206 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// metricsSnapshot is a point-in-time copy of VerificationMetrics that can be
+// read without holding the metrics lock
+type metricsSnapshot struct {
+	timestamp              time.Time
+	recordsProcessed       int64
+	entitiesCreated        int64
+	entitiesSynthesized    int64
+	errorsDetected         int64
+	cardinalityWarnings    int64
+	entityCorrelationRate  float64
+	queryNormalizationRate float64
+	feedbackDropped        int64
+	healthReportsDropped   int64
+	databases              map[string]DatabaseMetrics
+}
+
+// snapshotMetrics copies the current metrics under the read lock
+func (vp *VerificationProcessor) snapshotMetrics() metricsSnapshot {
+	vp.metrics.mu.RLock()
+	defer vp.metrics.mu.RUnlock()
+
+	snap := metricsSnapshot{
+		timestamp:              time.Now(),
+		recordsProcessed:       vp.metrics.recordsProcessed,
+		entitiesCreated:        vp.metrics.entitiesCreated,
+		entitiesSynthesized:    vp.metrics.entitiesSynthesized,
+		errorsDetected:         vp.metrics.errorsDetected,
+		cardinalityWarnings:    vp.metrics.cardinalityWarnings,
+		entityCorrelationRate:  vp.metrics.entityCorrelationRate,
+		queryNormalizationRate: vp.metrics.queryNormalizationRate,
+		feedbackDropped:        vp.feedbackDropped.Load(),
+		healthReportsDropped:   vp.HealthReportsDropped(),
+		databases:              make(map[string]DatabaseMetrics, len(vp.metrics.databaseMetrics)),
+	}
+	for dbName, metrics := range vp.metrics.databaseMetrics {
+		snap.databases[dbName] = *metrics
+	}
+	return snap
+}
+
+// report renders the snapshot in the health report format
+func (s metricsSnapshot) report() map[string]interface{} {
+	databases := make(map[string]interface{}, len(s.databases))
+	for dbName, metrics := range s.databases {
+		databases[dbName] = map[string]interface{}{
+			"record_count":            metrics.recordCount,
+			"last_seen":               metrics.lastSeen,
+			"entity_correlation_rate": metrics.entityCorrelationRate,
+			"average_query_duration":  metrics.averageQueryDuration,
+			"circuit_breaker_state":   metrics.circuitBreakerState,
+		}
+	}
+
+	return map[string]interface{}{
+		"timestamp":                s.timestamp,
+		"records_processed":        s.recordsProcessed,
+		"entities_created":         s.entitiesCreated,
+		"entities_synthesized":     s.entitiesSynthesized,
+		"errors_detected":          s.errorsDetected,
+		"cardinality_warnings":     s.cardinalityWarnings,
+		"entity_correlation_rate":  s.entityCorrelationRate,
+		"query_normalization_rate": s.queryNormalizationRate,
+		"feedback_events_dropped":  s.feedbackDropped,
+		"health_reports_dropped":   s.healthReportsDropped,
+		"databases":                databases,
+	}
+}
+
+// writePrometheus writes the snapshot's key gauges in Prometheus text exposition format
+func (s metricsSnapshot) writePrometheus(w *strings.Builder) {
+	writeMetric := func(name, metricType, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, metricType, name, value)
+	}
+
+	writeMetric("verification_records_processed_total", "counter", "Log records processed by the verification processor.", float64(s.recordsProcessed))
+	writeMetric("verification_entities_created_total", "counter", "Records carrying entity synthesis attributes.", float64(s.entitiesCreated))
+	writeMetric("verification_entities_synthesized_total", "counter", "Records whose entity attributes were synthesized by this processor.", float64(s.entitiesSynthesized))
+	writeMetric("verification_errors_detected_total", "counter", "Integration errors detected in processed records.", float64(s.errorsDetected))
+	writeMetric("verification_cardinality_warnings_total", "counter", "High cardinality warnings raised.", float64(s.cardinalityWarnings))
+	writeMetric("verification_entity_correlation_rate", "gauge", "Fraction of records correlated to an entity.", s.entityCorrelationRate)
+	writeMetric("verification_query_normalization_rate", "gauge", "Fraction of records with a normalized query.", s.queryNormalizationRate)
+	writeMetric("verification_feedback_events_dropped_total", "counter", "Feedback events dropped because the channel was full.", float64(s.feedbackDropped))
+	writeMetric("verification_health_reports_dropped_total", "counter", "Health reports the webhook did not receive because its queue was full or delivery failed.", float64(s.healthReportsDropped))
+
+	if len(s.databases) == 0 {
+		return
+	}
+
+	dbNames := make([]string, 0, len(s.databases))
+	for dbName := range s.databases {
+		dbNames = append(dbNames, dbName)
+	}
+	sort.Strings(dbNames)
+
+	fmt.Fprint(w, "# HELP verification_database_records_total Log records processed per database.\n# TYPE verification_database_records_total counter\n")
+	for _, dbName := range dbNames {
+		fmt.Fprintf(w, "verification_database_records_total{database=\"%s\"} %d\n", escapeLabelValue(dbName), s.databases[dbName].recordCount)
+	}
+
+	fmt.Fprint(w, "# HELP verification_database_entity_correlation_rate Fraction of records correlated to an entity per database.\n# TYPE verification_database_entity_correlation_rate gauge\n")
+	for _, dbName := range dbNames {
+		fmt.Fprintf(w, "verification_database_entity_correlation_rate{database=\"%s\"} %g\n", escapeLabelValue(dbName), s.databases[dbName].entityCorrelationRate)
+	}
+
+	fmt.Fprint(w, "# HELP verification_database_circuit_breaker_open Whether the circuit breaker for the database is open (1) or not (0).\n# TYPE verification_database_circuit_breaker_open gauge\n")
+	for _, dbName := range dbNames {
+		open := 0
+		if s.databases[dbName].circuitBreakerState == "open" {
+			open = 1
+		}
+		fmt.Fprintf(w, "verification_database_circuit_breaker_open{database=\"%s\"} %d\n", escapeLabelValue(dbName), open)
+	}
+}
+
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// healthServer exposes the verification health report over HTTP
+type healthServer struct {
+	processor *VerificationProcessor
+	logger    *zap.Logger
+	server    *http.Server
+	listener  net.Listener
+}
+
+func newHealthServer(vp *VerificationProcessor, endpoint string) *healthServer {
+	hs := &healthServer{
+		processor: vp,
+		logger:    vp.logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", hs.handleHealth)
+	mux.HandleFunc("/metrics", hs.handleMetrics)
+
+	hs.server = &http.Server{
+		Addr:              endpoint,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return hs
+}
+
+// start binds the listener synchronously so address errors fail processor startup
+func (hs *healthServer) start() error {
+	listener, err := net.Listen("tcp", hs.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on health endpoint %s: %w", hs.server.Addr, err)
+	}
+	hs.listener = listener
+
+	hs.processor.wg.Add(1)
+	go func() {
+		defer hs.processor.wg.Done()
+		if err := hs.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			hs.logger.Error("Health endpoint server error", zap.Error(err))
+		}
+	}()
+
+	hs.logger.Info("Verification health endpoint started", zap.String("endpoint", listener.Addr().String()))
+	return nil
+}
+
+func (hs *healthServer) shutdown(ctx context.Context) error {
+	return hs.server.Shutdown(ctx)
+}
+
+func (hs *healthServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := hs.processor.snapshotMetrics().report()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		hs.logger.Debug("Failed to write health report", zap.Error(err))
+	}
+}
+
+func (hs *healthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	hs.processor.snapshotMetrics().writePrometheus(&b)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		hs.logger.Debug("Failed to write health metrics", zap.Error(err))
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/goleak"
+	"go.uber.org/zap"
+)
+
+// allWorkersConfig enables every background worker and the health endpoint
+func allWorkersConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnablePeriodicVerification = true
+	cfg.EnableContinuousHealthChecks = true
+	cfg.EnableAutoTuning = true
+	cfg.EnableSelfHealing = true
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "127.0.0.1:0"
+	return cfg
+}
+
+func TestVerificationProcessor_WorkersExitAfterShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+
+	processor.sendFeedback(FeedbackEvent{Level: "INFO", Category: "lifecycle", Message: "running"})
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_ShutdownIsIdempotent(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	require.NoError(t, processor.Shutdown(context.Background()))
+
+	// Feedback raised after shutdown is dropped rather than sent on a closed channel
+	assert.NotPanics(t, func() {
+		processor.sendFeedback(FeedbackEvent{Level: "INFO", Category: "lifecycle", Message: "late"})
+	})
+}
+
+func TestVerificationProcessor_ShutdownBeforeStart(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.Error(t, processor.Start(context.Background(), nil), "a shut down processor cannot be restarted")
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_StartTwice(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+
+	require.NoError(t, processor.Start(context.Background(), nil))
+	listener := processor.healthServer.listener
+	require.NoError(t, processor.Start(context.Background(), nil))
+	assert.Same(t, listener, processor.healthServer.listener, "the health endpoint is only started once")
+
+	processor.startWorkers()
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_ShutdownDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	sink := &blockingLogsSink{release: make(chan struct{})}
+	processor, err := newVerificationProcessor(zap.NewNop(), createDefaultConfig().(*Config), sink)
+	require.NoError(t, err)
+
+	// The feedback worker blocks exporting this event
+	processor.sendFeedback(FeedbackEvent{Level: "WARNING", Category: "lifecycle", Message: "stuck"})
+	require.Eventually(t, func() bool {
+		return len(processor.feedbackChannel) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, processor.Shutdown(ctx), context.DeadlineExceeded)
+
+	// Once the export completes the worker exits and a later Shutdown succeeds
+	close(sink.release)
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
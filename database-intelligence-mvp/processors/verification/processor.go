@@ -7,14 +7,18 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -31,6 +35,28 @@ type VerificationProcessor struct {
 	feedbackChannel  chan FeedbackEvent
 	shutdownChan     chan struct{}
 	wg              sync.WaitGroup
+	
+	// Guard against starting the workers or health endpoint twice and
+	// closing shutdownChan twice
+	workersOnce  sync.Once
+	startOnce    sync.Once
+	shutdownOnce sync.Once
+	
+	// Runtime parameters adjusted by auto-tuning
+	liveParams *liveParameters
+	
+	// Optional HTTP endpoint serving the health report
+	healthServer *healthServer
+	
+	// Optional file and webhook destinations for periodic health reports
+	reportOutput *healthReportOutput
+	
+	// Feedback delivery
+	feedbackCoalescer *feedbackCoalescer
+	feedbackDropped   atomic.Int64
+
+	// Fills in entity.guid when entity synthesis is enabled
+	entitySynthesizer *entitySynthesizer
 
 	// Quality validation components
 	qualityValidator *QualityValidator
@@ -49,6 +75,7 @@ type VerificationMetrics struct {
 	mu                     sync.RWMutex
 	recordsProcessed       int64
 	entitiesCreated        int64
+	entitiesSynthesized    int64
 	errorsDetected         int64
 	cardinalityWarnings    int64
 	lastDataTimestamp      time.Time
@@ -86,7 +113,26 @@ type QualityValidator struct {
 	dataTypeMismatches  int64
 	missingRequiredFields int64
 	schemaViolations    int64
-	duplicateDetection  map[string]time.Time
+	duplicateDetection  *lru.Cache[string, time.Time]
+	duplicateTTL        time.Duration
+}
+
+// duplicateKey hashes an attribute key/value pair for duplicate detection
+func duplicateKey(k string, v pcommon.Value) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(k+":"+v.AsString())))
+}
+
+// expireDuplicates removes entries older than the TTL. Callers must hold mu.
+func (qv *QualityValidator) expireDuplicates(now time.Time) {
+	if qv.duplicateTTL <= 0 {
+		return
+	}
+	cutoff := now.Add(-qv.duplicateTTL)
+	for _, hash := range qv.duplicateDetection.Keys() {
+		if timestamp, ok := qv.duplicateDetection.Peek(hash); ok && timestamp.Before(cutoff) {
+			qv.duplicateDetection.Remove(hash)
+		}
+	}
 }
 
 // PIIDetector detects and flags potential PII in logs
@@ -108,6 +154,7 @@ type HealthChecker struct {
 	networkLatency        time.Duration
 	databaseConnectivity  map[string]bool
 	alertThresholds       HealthThresholds
+	resourceSource        ResourceSource
 }
 
 // HealthThresholds defines alert thresholds for health monitoring
@@ -122,7 +169,7 @@ type HealthThresholds struct {
 type FeedbackEngine struct {
 	mu                   sync.RWMutex
 	performanceHistory   []PerformanceSnapshot
-	tunableParameters    map[string]interface{}
+	appliedTunings       []AppliedTuning
 	autoTuningEnabled    bool
 	lastTuning          time.Time
 	tuningRecommendations []TuningRecommendation
@@ -211,14 +258,37 @@ func newVerificationProcessor(
 		metrics:         &VerificationMetrics{
 			databaseMetrics: make(map[string]*DatabaseMetrics),
 		},
-		feedbackChannel: make(chan FeedbackEvent, 1000),
+		feedbackChannel: make(chan FeedbackEvent, config.FeedbackChannelSize),
 		shutdownChan:    make(chan struct{}),
 	}
 	
-	// Initialize quality validator
+	vp.liveParams = newLiveParameters(config)
+	
+	if config.HealthEndpoint.Enabled {
+		vp.healthServer = newHealthServer(vp, config.HealthEndpoint.Endpoint)
+	}
+	
+	if config.HealthReportOutput.enabled() {
+		vp.reportOutput = newHealthReportOutput(logger, config.HealthReportOutput)
+	}
+	
+	if config.FeedbackCoalescing.Enabled {
+		vp.feedbackCoalescer = newFeedbackCoalescer(config.FeedbackCoalescing.Window)
+	}
+	
+	if config.EntitySynthesis.Enabled {
+		vp.entitySynthesizer = newEntitySynthesizer(config.EntitySynthesis)
+	}
+	
+	// Initialize quality validator with a size-capped duplicate detection cache
+	duplicateCache, err := lru.New[string, time.Time](config.QualityRules.DuplicateCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duplicate detection cache: %w", err)
+	}
 	vp.qualityValidator = &QualityValidator{
 		cardinalityLimits:  make(map[string]int),
-		duplicateDetection: make(map[string]time.Time),
+		duplicateDetection: duplicateCache,
+		duplicateTTL:       config.QualityRules.DuplicateCacheTTL,
 	}
 	
 	// Initialize PII detector with common patterns
@@ -236,11 +306,14 @@ func newVerificationProcessor(
 			DiskPercent:    config.HealthThresholds.DiskPercent,
 			NetworkLatency: config.HealthThresholds.NetworkLatency,
 		},
+		resourceSource: newRuntimeResourceSource(
+			config.HealthThresholds.DiskPath,
+			uint64(config.HealthThresholds.MemoryLimitMiB)*1024*1024,
+		),
 	}
 	
 	// Initialize feedback engine
 	vp.feedbackEngine = &FeedbackEngine{
-		tunableParameters:  make(map[string]interface{}),
 		autoTuningEnabled:  config.EnableAutoTuning,
 		performanceHistory: make([]PerformanceSnapshot, 0, 1000),
 	}
@@ -265,50 +338,110 @@ func newVerificationProcessor(
 		lastUpdate: time.Now(),
 	}
 	
-	// Start background processes
-	vp.wg.Add(1)
-	go vp.processFeedback()
-	
-	if config.EnablePeriodicVerification {
-		vp.wg.Add(1)
-		go vp.periodicVerification()
-	}
-	
-	if config.EnableContinuousHealthChecks {
-		vp.wg.Add(1)
-		go vp.continuousHealthChecks()
-	}
+	vp.startWorkers()
 	
-	if config.EnableAutoTuning {
+	return vp, nil
+}
+
+// startWorkers starts the background goroutines. They run from construction
+// so feedback is delivered even if Start is never called, and exit when
+// shutdownChan is closed. Calling it again has no effect.
+func (vp *VerificationProcessor) startWorkers() {
+	vp.workersOnce.Do(func() {
 		vp.wg.Add(1)
-		go vp.autoTuningEngine()
-	}
-	
-	if config.EnableSelfHealing {
+		go vp.processFeedback()
+		
+		if vp.config.EnablePeriodicVerification {
+			vp.wg.Add(1)
+			go vp.periodicVerification()
+		}
+		
+		if vp.config.EnableContinuousHealthChecks {
+			vp.wg.Add(1)
+			go vp.continuousHealthChecks()
+		}
+		
+		if vp.config.EnableAutoTuning {
+			vp.wg.Add(1)
+			go vp.autoTuningEngine()
+		}
+		
+		if vp.config.EnableSelfHealing {
+			vp.wg.Add(1)
+			go vp.selfHealingEngine()
+		}
+		
+		if vp.reportOutput != nil {
+			vp.wg.Add(1)
+			go vp.healthReportOutputLoop()
+			
+			if vp.reportOutput.queue != nil {
+				vp.wg.Add(1)
+				go func() {
+					defer vp.wg.Done()
+					vp.reportOutput.deliverWebhook(vp.shutdownChan)
+				}()
+			}
+		}
+		
+		// Start resource monitoring
 		vp.wg.Add(1)
-		go vp.selfHealingEngine()
-	}
-	
-	// Start resource monitoring
-	vp.wg.Add(1)
-	go vp.resourceMonitoring()
-	
-	return vp, nil
+		go vp.resourceMonitoring()
+	})
 }
 
 // Start implements the component.Component interface
 func (vp *VerificationProcessor) Start(ctx context.Context, host component.Host) error {
-	vp.logger.Info("Starting verification processor")
-	return nil
+	select {
+	case <-vp.shutdownChan:
+		return errors.New("verification processor has been shut down")
+	default:
+	}
+	
+	var err error
+	vp.startOnce.Do(func() {
+		vp.logger.Info("Starting verification processor")
+		if vp.healthServer != nil {
+			err = vp.healthServer.start()
+		}
+	})
+	return err
 }
 
-// Shutdown implements the component.Component interface
+// Shutdown implements the component.Component interface. It is safe to call
+// more than once and before Start.
 func (vp *VerificationProcessor) Shutdown(ctx context.Context) error {
-	vp.logger.Info("Shutting down verification processor")
-	close(vp.shutdownChan)
-	vp.wg.Wait()
-	close(vp.feedbackChannel)
-	return nil
+	vp.shutdownOnce.Do(func() {
+		vp.logger.Info("Shutting down verification processor")
+		close(vp.shutdownChan)
+		
+		if vp.reportOutput != nil {
+			vp.reportOutput.cancel()
+		}
+		
+		if vp.healthServer != nil && vp.healthServer.listener != nil {
+			if err := vp.healthServer.shutdown(ctx); err != nil {
+				vp.logger.Error("Error shutting down health endpoint", zap.Error(err))
+			}
+		}
+	})
+	
+	// Workers exit at their next select, but one may be blocked exporting
+	// feedback; give up once the shutdown deadline passes. feedbackChannel is
+	// left open so late sendFeedback calls are dropped instead of panicking.
+	done := make(chan struct{})
+	go func() {
+		vp.wg.Wait()
+		close(done)
+	}()
+	
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		vp.logger.Warn("Verification workers did not exit before the shutdown deadline")
+		return ctx.Err()
+	}
 }
 
 // Capabilities implements the consumer.Consumer interface
@@ -326,6 +459,7 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 	vp.performanceTracker.mu.Unlock()
 	
 	// Process logs and collect verification metrics
+	qualitySampleRate := vp.liveParams.QualitySampleRate()
 	for i := 0; i < ld.ResourceLogs().Len(); i++ {
 		rl := ld.ResourceLogs().At(i)
 		resource := rl.Resource()
@@ -339,8 +473,10 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 				// Enhanced verification with new capabilities
 				vp.verifyLogRecord(resource, lr)
 				
-				// Quality validation
-				vp.validateQuality(lr)
+				// Quality validation, sampled when auto-tuning has reduced the rate
+				if qualitySampleRate >= 1.0 || rand.Float64() < qualitySampleRate {
+					vp.validateQuality(lr)
+				}
 				
 				// PII detection and sanitization
 				vp.detectAndSanitizePII(lr)
@@ -358,7 +494,13 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 	vp.performanceTracker.mu.Unlock()
 	
 	// Pass to next consumer
-	err := vp.nextConsumer.ConsumeLogs(ctx, ld)
+	nextCtx := ctx
+	if timeout := vp.liveParams.ConsumerTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		nextCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := vp.nextConsumer.ConsumeLogs(nextCtx, ld)
 	if err != nil {
 		vp.performanceTracker.mu.Lock()
 		vp.performanceTracker.errorCount++
@@ -406,6 +548,11 @@ func (vp *VerificationProcessor) verifyLogRecord(resource pcommon.Resource, lr p
 		}
 	}
 	
+	// Fill in missing entity attributes before verifying them
+	if vp.entitySynthesizer != nil && vp.entitySynthesizer.synthesize(resource.Attributes(), attrs) {
+		vp.metrics.entitiesSynthesized++
+	}
+	
 	// Verify entity synthesis attributes
 	hasEntityGuid := false
 	
@@ -436,7 +583,7 @@ func (vp *VerificationProcessor) verifyLogRecord(resource pcommon.Resource, lr p
 			Category:  "entity_synthesis",
 			Database:  dbName,
 			Message:   "Missing entity.guid attribute for proper New Relic entity correlation",
-			Remediation: "Ensure resource/entity_synthesis processor is configured correctly",
+			Remediation: "Ensure resource/entity_synthesis processor is configured correctly, or enable entity_synthesis",
 		})
 	}
 	
@@ -470,8 +617,9 @@ func (vp *VerificationProcessor) verifyLogRecord(resource pcommon.Resource, lr p
 
 // checkIntegrationHealth performs periodic health checks
 func (vp *VerificationProcessor) checkIntegrationHealth() {
-	vp.metrics.mu.RLock()
-	defer vp.metrics.mu.RUnlock()
+	// Write lock: the overall correlation rate and cardinality warnings are updated below
+	vp.metrics.mu.Lock()
+	defer vp.metrics.mu.Unlock()
 	
 	// Check data freshness
 	if time.Since(vp.metrics.lastDataTimestamp) > vp.config.DataFreshnessThreshold {
@@ -547,32 +695,37 @@ func (vp *VerificationProcessor) periodicVerification() {
 	}
 }
 
-// generateHealthReport creates a comprehensive health report
-func (vp *VerificationProcessor) generateHealthReport() {
-	vp.metrics.mu.RLock()
-	defer vp.metrics.mu.RUnlock()
+// healthReportOutputLoop publishes a health report to the configured file and
+// webhook every health_report_output.interval
+func (vp *VerificationProcessor) healthReportOutputLoop() {
+	defer vp.wg.Done()
+	defer vp.reportOutput.closeFile()
 	
-	report := map[string]interface{}{
-		"timestamp":               time.Now(),
-		"records_processed":       vp.metrics.recordsProcessed,
-		"entities_created":        vp.metrics.entitiesCreated,
-		"errors_detected":         vp.metrics.errorsDetected,
-		"cardinality_warnings":    vp.metrics.cardinalityWarnings,
-		"entity_correlation_rate": vp.metrics.entityCorrelationRate,
-		"query_normalization_rate": vp.metrics.queryNormalizationRate,
-		"databases":               make(map[string]interface{}),
-	}
+	ticker := time.NewTicker(vp.reportOutput.interval)
+	defer ticker.Stop()
 	
-	// Add per-database metrics
-	for dbName, metrics := range vp.metrics.databaseMetrics {
-		report["databases"].(map[string]interface{})[dbName] = map[string]interface{}{
-			"record_count":           metrics.recordCount,
-			"last_seen":              metrics.lastSeen,
-			"entity_correlation_rate": metrics.entityCorrelationRate,
-			"average_query_duration": metrics.averageQueryDuration,
-			"circuit_breaker_state":  metrics.circuitBreakerState,
+	for {
+		select {
+		case <-ticker.C:
+			vp.reportOutput.publish(vp.snapshotMetrics().report())
+		case <-vp.shutdownChan:
+			return
 		}
 	}
+}
+
+// HealthReportsDropped returns the number of health reports the webhook did
+// not receive, either because its queue was full or delivery failed
+func (vp *VerificationProcessor) HealthReportsDropped() int64 {
+	if vp.reportOutput == nil {
+		return 0
+	}
+	return vp.reportOutput.droppedTotal()
+}
+
+// generateHealthReport creates a comprehensive health report
+func (vp *VerificationProcessor) generateHealthReport() {
+	report := vp.snapshotMetrics().report()
 	
 	// Log the report
 	reportJSON, _ := json.MarshalIndent(report, "", "  ")
@@ -591,39 +744,46 @@ func (vp *VerificationProcessor) generateHealthReport() {
 
 // sendFeedback sends a feedback event
 func (vp *VerificationProcessor) sendFeedback(event FeedbackEvent) {
+	if vp.feedbackCoalescer != nil && !vp.feedbackCoalescer.admit(event, time.Now()) {
+		return
+	}
+	
 	select {
 	case vp.feedbackChannel <- event:
 	default:
-		vp.logger.Warn("Feedback channel full, dropping event")
+		dropped := vp.feedbackDropped.Add(1)
+		vp.logger.Warn("Feedback channel full, dropping event",
+			zap.String("category", event.Category),
+			zap.Int64("dropped_total", dropped))
 	}
 }
 
+// FeedbackDropped returns the number of feedback events dropped because the channel was full
+func (vp *VerificationProcessor) FeedbackDropped() int64 {
+	return vp.feedbackDropped.Load()
+}
+
 // processFeedback handles feedback events
 func (vp *VerificationProcessor) processFeedback() {
 	defer vp.wg.Done()
 	
+	// Coalesced summaries are flushed from this goroutine so they never compete
+	// with new events for channel capacity
+	var flushTicks <-chan time.Time
+	if vp.feedbackCoalescer != nil {
+		ticker := time.NewTicker(vp.feedbackCoalescer.window)
+		defer ticker.Stop()
+		flushTicks = ticker.C
+	}
+	
 	for {
 		select {
 		case event := <-vp.feedbackChannel:
-			// Log the feedback
-			vp.logger.Info("Verification feedback",
-				zap.String("level", event.Level),
-				zap.String("category", event.Category),
-				zap.String("message", event.Message),
-				zap.String("database", event.Database),
-				zap.String("remediation", event.Remediation),
-			)
-			
-			// Update error counter
-			if event.Level == "ERROR" {
-				vp.metrics.mu.Lock()
-				vp.metrics.errorsDetected++
-				vp.metrics.mu.Unlock()
-			}
+			vp.handleFeedback(event)
 			
-			// Export feedback as telemetry
-			if vp.config.ExportFeedbackAsLogs {
-				vp.exportFeedbackEvent(event)
+		case now := <-flushTicks:
+			for _, summary := range vp.feedbackCoalescer.flush(now) {
+				vp.handleFeedback(summary)
 			}
 			
 		case <-vp.shutdownChan:
@@ -632,6 +792,30 @@ func (vp *VerificationProcessor) processFeedback() {
 	}
 }
 
+// handleFeedback logs, counts and optionally exports a feedback event
+func (vp *VerificationProcessor) handleFeedback(event FeedbackEvent) {
+	// Log the feedback
+	vp.logger.Info("Verification feedback",
+		zap.String("level", event.Level),
+		zap.String("category", event.Category),
+		zap.String("message", event.Message),
+		zap.String("database", event.Database),
+		zap.String("remediation", event.Remediation),
+	)
+	
+	// Update error counter
+	if event.Level == "ERROR" {
+		vp.metrics.mu.Lock()
+		vp.metrics.errorsDetected++
+		vp.metrics.mu.Unlock()
+	}
+	
+	// Export feedback as telemetry
+	if vp.config.ExportFeedbackAsLogs {
+		vp.exportFeedbackEvent(event)
+	}
+}
+
 // exportFeedbackEvent exports feedback as telemetry
 func (vp *VerificationProcessor) exportFeedbackEvent(event FeedbackEvent) {
 	// Create a log record for the feedback event
@@ -724,18 +908,21 @@ func (vp *VerificationProcessor) validateQuality(lr plog.LogRecord) {
 	// Check cardinality limits
 	attrs.Range(func(k string, v pcommon.Value) bool {
 		if limit, exists := vp.qualityValidator.cardinalityLimits[k]; exists {
-			// Create a hash of the record for duplicate detection
-			hash := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s:%v", k, v))))
+			hash := duplicateKey(k, v)
 			
-			if _, seen := vp.qualityValidator.duplicateDetection[hash]; seen {
-				// Duplicate detected
-				return true
+			now := time.Now()
+			if firstSeen, seen := vp.qualityValidator.duplicateDetection.Get(hash); seen {
+				if vp.qualityValidator.duplicateTTL <= 0 || now.Sub(firstSeen) < vp.qualityValidator.duplicateTTL {
+					// Duplicate detected
+					return true
+				}
 			}
 			
-			vp.qualityValidator.duplicateDetection[hash] = time.Now()
+			// Add evicts the least recently seen value once the cache is full
+			vp.qualityValidator.duplicateDetection.Add(hash, now)
 			
 			// Check if we're approaching cardinality limits
-			if len(vp.qualityValidator.duplicateDetection) > limit {
+			if vp.qualityValidator.duplicateDetection.Len() > limit {
 				vp.sendFeedback(FeedbackEvent{
 					Timestamp:   time.Now(),
 					Level:       "WARNING",
@@ -945,6 +1132,21 @@ func (vp *VerificationProcessor) performHealthCheck() {
 		})
 	}
 	
+	if diskUsage > vp.healthChecker.alertThresholds.DiskPercent {
+		vp.sendFeedback(FeedbackEvent{
+			Timestamp:   time.Now(),
+			Level:       "WARNING",
+			Category:    "system_health",
+			Message:     fmt.Sprintf("High disk usage: %.2f%%", diskUsage),
+			Remediation: "Free space on the data directory or reduce retained telemetry",
+			Severity:    7,
+			Metrics: map[string]interface{}{
+				"disk_usage_percent": diskUsage,
+				"threshold": vp.healthChecker.alertThresholds.DiskPercent,
+			},
+		})
+	}
+	
 	// Test database connectivity
 	vp.testDatabaseConnectivity()
 }
@@ -1021,7 +1223,7 @@ func (vp *VerificationProcessor) performAutoTuning() {
 		
 		// Apply auto-tuning if confidence is high enough
 		for _, rec := range recommendations {
-			if rec.Confidence > 0.8 && vp.config.AutoTuningConfig.EnableAutoApply {
+			if rec.Confidence >= vp.config.AutoTuningConfig.MinConfidenceLevel && vp.config.AutoTuningConfig.EnableAutoApply {
 				vp.applyTuningRecommendation(rec)
 			} else {
 				vp.sendFeedback(FeedbackEvent{
@@ -1044,24 +1246,29 @@ func (vp *VerificationProcessor) performAutoTuning() {
 	vp.feedbackEngine.lastTuning = time.Now()
 }
 
-// Helper methods for system monitoring (simplified implementations)
+// Helper methods for system monitoring, backed by the health checker's resource source
 func (vp *VerificationProcessor) getMemoryUsage() float64 {
-	var m runtime.MemStats
-	runtime.GC()
-	runtime.ReadMemStats(&m)
-	return float64(m.Sys) / (1024 * 1024) // Convert to MB
+	return vp.readResource("memory", vp.healthChecker.resourceSource.MemoryPercent)
 }
 
 func (vp *VerificationProcessor) getCPUUsage() float64 {
-	// Simplified CPU usage calculation
-	// In production, use proper CPU monitoring
-	return 0.0
+	return vp.readResource("cpu", vp.healthChecker.resourceSource.CPUPercent)
 }
 
 func (vp *VerificationProcessor) getDiskUsage() float64 {
-	// Simplified disk usage calculation
-	// In production, use proper disk monitoring
-	return 0.0
+	return vp.readResource("disk", vp.healthChecker.resourceSource.DiskPercent)
+}
+
+// readResource reads a single measurement, treating unavailable readings as zero
+func (vp *VerificationProcessor) readResource(name string, read func() (float64, error)) float64 {
+	value, err := read()
+	if err != nil {
+		vp.logger.Debug("Resource measurement unavailable",
+			zap.String("resource", name),
+			zap.Error(err))
+		return 0.0
+	}
+	return value
 }
 
 func (vp *VerificationProcessor) simulateConnectivityCheck(dbName string) bool {
@@ -1074,17 +1281,9 @@ func (vp *VerificationProcessor) performMemoryCleanup() bool {
 	// Force garbage collection
 	runtime.GC()
 	
-	// Clear duplicate detection cache if it's too large
+	// Drop expired duplicate detection entries; size is already bounded by the LRU
 	vp.qualityValidator.mu.Lock()
-	if len(vp.qualityValidator.duplicateDetection) > 10000 {
-		// Clear old entries
-		cutoff := time.Now().Add(-time.Hour)
-		for hash, timestamp := range vp.qualityValidator.duplicateDetection {
-			if timestamp.Before(cutoff) {
-				delete(vp.qualityValidator.duplicateDetection, hash)
-			}
-		}
-	}
+	vp.qualityValidator.expireDuplicates(time.Now())
 	vp.qualityValidator.mu.Unlock()
 	
 	return true
@@ -1146,17 +1345,22 @@ func (vp *VerificationProcessor) analyzePerformanceTrends() []TuningRecommendati
 	
 	recent := vp.feedbackEngine.performanceHistory[len(vp.feedbackEngine.performanceHistory)-5:]
 	
+	maxChange := vp.config.AutoTuningConfig.MaxParameterChange
+	
 	// Analyze throughput trend
 	throughputTrend := recent[4].Throughput - recent[0].Throughput
 	if throughputTrend < -0.1 { // Decreasing throughput
-		recommendations = append(recommendations, TuningRecommendation{
-			Parameter:      "batch_size",
-			CurrentValue:   "current",
-			SuggestedValue: "increased",
-			Reason:         "Decreasing throughput detected",
-			Impact:         "Increase batch size to improve throughput",
-			Confidence:     0.7,
-		})
+		current := vp.liveParams.QualitySampleRate()
+		if current > minQualitySampleRate {
+			recommendations = append(recommendations, TuningRecommendation{
+				Parameter:      paramQualitySampleRate,
+				CurrentValue:   current,
+				SuggestedValue: math.Max(current*(1-maxChange), minQualitySampleRate),
+				Reason:         "Decreasing throughput detected",
+				Impact:         "Validate fewer records to reduce per-record processing cost",
+				Confidence:     0.7,
+			})
+		}
 	}
 	
 	// Analyze error rate trend
@@ -1167,29 +1371,48 @@ func (vp *VerificationProcessor) analyzePerformanceTrends() []TuningRecommendati
 	avgErrorRate /= float64(len(recent))
 	
 	if avgErrorRate > 0.05 { // High error rate
-		recommendations = append(recommendations, TuningRecommendation{
-			Parameter:      "timeout",
-			CurrentValue:   "current",
-			SuggestedValue: "increased",
-			Reason:         "High error rate detected",
-			Impact:         "Increase timeout to reduce timeout-related errors",
-			Confidence:     0.8,
-		})
+		current := vp.liveParams.ConsumerTimeout()
+		if current > 0 && current < maxConsumerTimeout {
+			recommendations = append(recommendations, TuningRecommendation{
+				Parameter:      paramConsumerTimeout,
+				CurrentValue:   current,
+				SuggestedValue: time.Duration(float64(current) * (1 + maxChange)),
+				Reason:         "High error rate detected",
+				Impact:         "Increase timeout to reduce timeout-related errors",
+				Confidence:     0.8,
+			})
+		}
 	}
 	
 	return recommendations
 }
 
+// applyTuningRecommendation updates the live parameter. Callers must hold feedbackEngine.mu.
 func (vp *VerificationProcessor) applyTuningRecommendation(rec TuningRecommendation) {
-	vp.logger.Info("Applying auto-tuning recommendation",
+	before, after, ok := vp.liveParams.apply(rec)
+	if !ok {
+		vp.logger.Warn("Ignoring unsupported tuning recommendation",
+			zap.String("parameter", rec.Parameter),
+			zap.Any("suggested_value", rec.SuggestedValue))
+		return
+	}
+	
+	vp.logger.Info("Applied auto-tuning recommendation",
 		zap.String("parameter", rec.Parameter),
-		zap.Any("current_value", rec.CurrentValue),
-		zap.Any("suggested_value", rec.SuggestedValue),
+		zap.Any("before", before),
+		zap.Any("after", after),
 		zap.Float64("confidence", rec.Confidence))
 	
-	// This is a simplified implementation
-	// In production, you would implement actual parameter adjustments
-	vp.feedbackEngine.tunableParameters[rec.Parameter] = rec.SuggestedValue
+	vp.feedbackEngine.appliedTunings = append(vp.feedbackEngine.appliedTunings, AppliedTuning{
+		Timestamp: time.Now(),
+		Parameter: rec.Parameter,
+		Before:    before,
+		After:     after,
+		Reason:    rec.Reason,
+	})
+	if len(vp.feedbackEngine.appliedTunings) > 100 {
+		vp.feedbackEngine.appliedTunings = vp.feedbackEngine.appliedTunings[1:]
+	}
 	
 	vp.sendFeedback(FeedbackEvent{
 		Timestamp: time.Now(),
@@ -1199,8 +1422,8 @@ func (vp *VerificationProcessor) applyTuningRecommendation(rec TuningRecommendat
 		AutoFixed: true,
 		Metrics: map[string]interface{}{
 			"parameter": rec.Parameter,
-			"old_value": rec.CurrentValue,
-			"new_value": rec.SuggestedValue,
+			"old_value": fmt.Sprint(before),
+			"new_value": fmt.Sprint(after),
 		},
 	})
 }
@@ -1363,7 +1586,7 @@ func (vp *VerificationProcessor) updateResourceMetrics() {
 	vp.resourceMonitor.lastUpdate = time.Now()
 	
 	// Check for resource alerts
-	if vp.resourceMonitor.memoryUsage > 90.0 {
+	if vp.resourceMonitor.memoryUsage > vp.healthChecker.alertThresholds.MemoryPercent {
 		vp.resourceMonitor.alertsTriggered++
 		if vp.selfHealer.healingEnabled {
 			vp.attemptSelfHealing("high_memory", fmt.Errorf("memory usage %.2f%%", vp.resourceMonitor.memoryUsage), nil)
@@ -2,7 +2,13 @@ package verification
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -170,4 +176,399 @@ func TestVerificationProcessor_CardinalityProtection(t *testing.T) {
 	// The processor should have logged warnings about high cardinality
 	// In a real implementation, you might check internal metrics or state
 	assert.True(t, true, "Cardinality protection should be active")
-}
\ No newline at end of file
+}
+// fakeResourceSource returns fixed resource readings
+type fakeResourceSource struct {
+	cpu, memory, disk float64
+}
+
+func (f *fakeResourceSource) CPUPercent() (float64, error)    { return f.cpu, nil }
+func (f *fakeResourceSource) MemoryPercent() (float64, error) { return f.memory, nil }
+func (f *fakeResourceSource) DiskPercent() (float64, error)   { return f.disk, nil }
+
+// feedbackMessages returns the bodies of exported feedback logs for a category
+func feedbackMessages(sink *consumertest.LogsSink, category string) []string {
+	var messages []string
+	for _, ld := range sink.AllLogs() {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				records := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < records.Len(); k++ {
+					lr := records.At(k)
+					if v, ok := lr.Attributes().Get("feedback.category"); ok && v.Str() == category {
+						messages = append(messages, lr.Body().Str())
+					}
+				}
+			}
+		}
+	}
+	return messages
+}
+
+func TestVerificationProcessor_ResourceThresholdAlerts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+	cfg.EnableSelfHealing = false
+	
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	processor.healthChecker.resourceSource = &fakeResourceSource{cpu: 95, memory: 10, disk: 97}
+	processor.performHealthCheck()
+	
+	require.Eventually(t, func() bool {
+		return len(feedbackMessages(sink, "system_health")) == 2
+	}, time.Second, 10*time.Millisecond)
+	
+	messages := feedbackMessages(sink, "system_health")
+	assert.Contains(t, messages, "High CPU usage: 95.00%")
+	assert.Contains(t, messages, "High disk usage: 97.00%")
+	assert.Equal(t, 95.0, processor.healthChecker.cpuUsage)
+	assert.Equal(t, 97.0, processor.healthChecker.diskUsage)
+}
+
+func TestVerificationProcessor_ResourcesBelowThresholds(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+	
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	processor.healthChecker.resourceSource = &fakeResourceSource{cpu: 20, memory: 30, disk: 40}
+	processor.performHealthCheck()
+	
+	// Give the feedback loop a chance to export anything that was queued
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, feedbackMessages(sink, "system_health"))
+}
+
+func TestRuntimeResourceSource(t *testing.T) {
+	rs := newRuntimeResourceSource(t.TempDir(), 0)
+	
+	for name, read := range map[string]func() (float64, error){
+		"cpu":    rs.CPUPercent,
+		"memory": rs.MemoryPercent,
+		"disk":   rs.DiskPercent,
+	} {
+		value, err := read()
+		if errors.Is(err, errResourceUnsupported) {
+			continue
+		}
+		require.NoError(t, err, name)
+		assert.GreaterOrEqual(t, value, 0.0, name)
+		assert.LessOrEqual(t, value, 100.0, name)
+	}
+}
+
+func TestRuntimeResourceSource_MemoryPercentOfLimit(t *testing.T) {
+	rs := newRuntimeResourceSource(t.TempDir(), 1024*1024*1024)
+	rs.memoryInUse = func() uint64 { return 50 * 1024 * 1024 }
+	
+	percent, err := rs.MemoryPercent()
+	require.NoError(t, err)
+	
+	// 50MB of a 1GB limit is ~5%, not 50
+	assert.InDelta(t, 4.88, percent, 0.01)
+}
+
+func TestVerificationProcessor_MemoryAlertUsesPercent(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+	cfg.EnableSelfHealing = false
+	cfg.HealthThresholds.MemoryLimitMiB = 1024
+	
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	rs := processor.healthChecker.resourceSource.(*runtimeResourceSource)
+	rs.memoryInUse = func() uint64 { return 100 * 1024 * 1024 }
+	processor.performHealthCheck()
+	
+	// 100MB under a 1GB limit must not trip the 85% threshold
+	time.Sleep(50 * time.Millisecond)
+	assert.InDelta(t, 9.77, processor.healthChecker.systemMemoryUsage, 0.01)
+	for _, msg := range feedbackMessages(sink, "system_health") {
+		assert.NotContains(t, msg, "memory")
+	}
+	
+	rs.memoryInUse = func() uint64 { return 900 * 1024 * 1024 }
+	processor.performHealthCheck()
+	
+	require.Eventually(t, func() bool {
+		for _, msg := range feedbackMessages(sink, "system_health") {
+			if msg == "High memory usage: 87.89%" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestQualityValidator_ExpireDuplicates(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QualityRules.DuplicateCacheTTL = time.Minute
+	
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	qv := processor.qualityValidator
+	now := time.Now()
+	qv.duplicateDetection.Add("stale", now.Add(-2*time.Minute))
+	qv.duplicateDetection.Add("fresh", now)
+	
+	qv.expireDuplicates(now)
+	
+	assert.False(t, qv.duplicateDetection.Contains("stale"))
+	assert.True(t, qv.duplicateDetection.Contains("fresh"))
+}
+
+// blockingLogsSink blocks every ConsumeLogs call until released
+type blockingLogsSink struct {
+	consumertest.LogsSink
+	release chan struct{}
+}
+
+func (b *blockingLogsSink) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	<-b.release
+	return b.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+func TestVerificationProcessor_FeedbackDroppedCounter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackChannelSize = 2
+	
+	sink := &blockingLogsSink{release: make(chan struct{})}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	
+	// The first event stalls the feedback loop on the blocked consumer
+	for i := 0; i < 100; i++ {
+		processor.sendFeedback(FeedbackEvent{Level: "WARNING", Category: "flood", Message: "storm"})
+	}
+	
+	// At most one in-flight event plus the channel capacity can be accepted
+	assert.GreaterOrEqual(t, processor.FeedbackDropped(), int64(97))
+	
+	close(sink.release)
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_FeedbackCoalescing(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackChannelSize = 2
+	cfg.FeedbackCoalescing.Enabled = true
+	cfg.FeedbackCoalescing.Window = 50 * time.Millisecond
+	
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	for i := 0; i < 100; i++ {
+		processor.sendFeedback(FeedbackEvent{Level: "WARNING", Category: "flood", Message: "storm"})
+	}
+	
+	require.Eventually(t, func() bool {
+		return len(feedbackMessages(sink, "flood")) == 2
+	}, time.Second, 10*time.Millisecond)
+	
+	assert.Equal(t, int64(0), processor.FeedbackDropped())
+	assert.Equal(t, []string{"storm", "storm (repeated 99 times in 50ms)"}, feedbackMessages(sink, "flood"))
+}
+
+func TestFeedbackCoalescer_SeparatesKeys(t *testing.T) {
+	c := newFeedbackCoalescer(time.Minute)
+	now := time.Now()
+	
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now))
+	assert.True(t, c.admit(FeedbackEvent{Category: "b", Level: "WARNING"}, now))
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "ERROR"}, now))
+	assert.False(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now))
+	
+	// Nothing is flushed while windows are open
+	assert.Empty(t, c.flush(now))
+	
+	summaries := c.flush(now.Add(time.Minute))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "a", summaries[0].Category)
+	assert.Equal(t, int64(1), summaries[0].Metrics["occurrences"])
+	
+	// A new window starts after the flush
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now.Add(time.Minute)))
+}
+
+func TestVerificationProcessor_AutoTuningAppliesParameters(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableAutoTuning = true
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.5
+	cfg.AutoTuningConfig.MaxParameterChange = 0.2
+	cfg.ConsumerTimeout = 30 * time.Second
+	
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	// Falling throughput with a high error rate; performAutoTuning appends the live snapshot
+	for _, throughput := range []float64{100, 90, 80, 70} {
+		processor.feedbackEngine.performanceHistory = append(processor.feedbackEngine.performanceHistory,
+			PerformanceSnapshot{Timestamp: time.Now(), Throughput: throughput, ErrorRate: 0.2})
+	}
+	
+	processor.performAutoTuning()
+	
+	assert.InDelta(t, 0.8, processor.liveParams.QualitySampleRate(), 1e-9)
+	assert.Equal(t, 36*time.Second, processor.liveParams.ConsumerTimeout())
+	
+	applied := processor.feedbackEngine.appliedTunings
+	require.Len(t, applied, 2)
+	assert.Equal(t, paramQualitySampleRate, applied[0].Parameter)
+	assert.Equal(t, 1.0, applied[0].Before)
+	assert.Equal(t, paramConsumerTimeout, applied[1].Parameter)
+	assert.Equal(t, 30*time.Second, applied[1].Before)
+}
+
+func TestVerificationProcessor_AutoTuningRespectsConfidence(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableAutoTuning = true
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.9
+	cfg.ConsumerTimeout = 30 * time.Second
+	
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	for _, throughput := range []float64{100, 90, 80, 70} {
+		processor.feedbackEngine.performanceHistory = append(processor.feedbackEngine.performanceHistory,
+			PerformanceSnapshot{Timestamp: time.Now(), Throughput: throughput, ErrorRate: 0.2})
+	}
+	
+	processor.performAutoTuning()
+	
+	// Recommendations are only reported below the confidence threshold
+	assert.Len(t, processor.feedbackEngine.tuningRecommendations, 2)
+	assert.Empty(t, processor.feedbackEngine.appliedTunings)
+	assert.Equal(t, 1.0, processor.liveParams.QualitySampleRate())
+	assert.Equal(t, 30*time.Second, processor.liveParams.ConsumerTimeout())
+}
+
+func TestVerificationProcessor_ConsumerTimeoutDisabledByDefault(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Zero(t, cfg.ConsumerTimeout)
+	
+	cfg.EnableAutoTuning = true
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.5
+	
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	for _, throughput := range []float64{100, 90, 80, 70} {
+		processor.feedbackEngine.performanceHistory = append(processor.feedbackEngine.performanceHistory,
+			PerformanceSnapshot{Timestamp: time.Now(), Throughput: throughput, ErrorRate: 0.2})
+	}
+	
+	processor.performAutoTuning()
+	
+	// Auto-tuning does not turn on a timeout that was never set
+	assert.Zero(t, processor.liveParams.ConsumerTimeout())
+	for _, applied := range processor.feedbackEngine.appliedTunings {
+		assert.NotEqual(t, paramConsumerTimeout, applied.Parameter)
+	}
+}
+
+func TestLiveParameters_ApplyBounds(t *testing.T) {
+	lp := newLiveParameters(createDefaultConfig().(*Config))
+	
+	_, after, ok := lp.apply(TuningRecommendation{Parameter: paramQualitySampleRate, SuggestedValue: 0.01})
+	require.True(t, ok)
+	assert.Equal(t, minQualitySampleRate, after)
+	
+	_, after, ok = lp.apply(TuningRecommendation{Parameter: paramConsumerTimeout, SuggestedValue: time.Hour})
+	require.True(t, ok)
+	assert.Equal(t, maxConsumerTimeout, after)
+	
+	_, _, ok = lp.apply(TuningRecommendation{Parameter: "batch_size", SuggestedValue: 10})
+	assert.False(t, ok)
+	_, _, ok = lp.apply(TuningRecommendation{Parameter: paramConsumerTimeout, SuggestedValue: "10s"})
+	assert.False(t, ok)
+}
+
+func TestVerificationProcessor_HealthEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "127.0.0.1:0"
+	
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+	
+	baseURL := "http://" + processor.healthServer.listener.Addr().String()
+	
+	// Scrape while records are being consumed to exercise the metrics lock
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			logs := plog.NewLogs()
+			lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			lr.Attributes().PutStr("database_name", "orders")
+			lr.Attributes().PutStr("cb.state", "open")
+			assert.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(baseURL + "/metrics")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	wg.Wait()
+	
+	resp, err := http.Get(baseURL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	
+	var report map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, float64(50), report["records_processed"])
+	databases := report["databases"].(map[string]interface{})
+	assert.Equal(t, float64(50), databases["orders"].(map[string]interface{})["record_count"])
+	
+	resp, err = http.Get(baseURL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "verification_records_processed_total 50\n")
+	assert.Contains(t, string(body), `verification_database_records_total{database="orders"} 50`)
+	assert.Contains(t, string(body), `verification_database_circuit_breaker_open{database="orders"} 1`)
+	
+	require.NoError(t, processor.Shutdown(context.Background()))
+	_, err = http.Get(baseURL + "/health")
+	assert.Error(t, err)
+}
+
+func TestVerificationProcessor_HealthEndpointBindError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "not-an-address"
+	
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+	
+	assert.Error(t, processor.Start(context.Background(), nil))
+}
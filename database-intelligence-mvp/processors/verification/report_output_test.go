@@ -0,0 +1,177 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// readReportLines returns the JSON lines of a report file
+func readReportLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var reports []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var report map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &report))
+		reports = append(reports, report)
+	}
+	require.NoError(t, scanner.Err())
+	return reports
+}
+
+func TestRotatingFile_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.jsonl")
+	f := newRotatingFile(path, 40, 2)
+
+	// Each line is 16 bytes, so every file holds two
+	for i := 1; i <= 7; i++ {
+		require.NoError(t, f.write([]byte(fmt.Sprintf("{\"report\":%04d}\n", i))))
+	}
+	require.NoError(t, f.close())
+
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "{\"report\":0007}\n", read(path))
+	assert.Equal(t, "{\"report\":0005}\n{\"report\":0006}\n", read(path+".1"))
+	assert.Equal(t, "{\"report\":0003}\n{\"report\":0004}\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	// Reopening appends instead of truncating
+	f = newRotatingFile(path, 40, 2)
+	require.NoError(t, f.write([]byte("{\"report\":0008}\n")))
+	require.NoError(t, f.close())
+	assert.Equal(t, "{\"report\":0007}\n{\"report\":0008}\n", read(path))
+}
+
+func TestRotatingFile_NoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.jsonl")
+	f := newRotatingFile(path, 20, 0)
+
+	require.NoError(t, f.write([]byte("{\"report\":0001}\n")))
+	require.NoError(t, f.write([]byte("{\"report\":0002}\n")))
+	require.NoError(t, f.close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"report\":0002}\n", string(data))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestHealthReportOutput_WebhookRetries(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		var report map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &report))
+		received <- report
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config).HealthReportOutput
+	cfg.Webhook.URL = server.URL
+	cfg.Webhook.Headers = map[string]string{"Authorization": "Bearer token"}
+	cfg.Webhook.RetryBackoff = time.Millisecond
+	out := newHealthReportOutput(zap.NewNop(), cfg)
+
+	done := make(chan struct{})
+	defer close(done)
+	go out.deliverWebhook(done)
+
+	out.publish(map[string]interface{}{"records_processed": 42})
+	select {
+	case report := <-received:
+		assert.Equal(t, float64(42), report["records_processed"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook did not receive the report")
+	}
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Equal(t, int64(0), out.droppedTotal())
+}
+
+func TestVerificationProcessor_HealthReportWebhookFailureDoesNotBlock(t *testing.T) {
+	// The webhook hangs until the test ends, so deliveries never complete
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	path := filepath.Join(t.TempDir(), "health.jsonl")
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExportFeedbackAsLogs = false
+	cfg.HealthReportOutput.Interval = 5 * time.Millisecond
+	cfg.HealthReportOutput.File.Path = path
+	cfg.HealthReportOutput.Webhook.URL = server.URL
+	cfg.HealthReportOutput.Webhook.QueueSize = 1
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	// Records keep flowing while reports pile up behind the webhook
+	for i := 0; i < 20; i++ {
+		logs := plog.NewLogs()
+		lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		lr.Attributes().PutStr("database_name", "orders")
+		require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 20, sink.LogRecordCount())
+
+	// With one report in flight and one queued, later reports are dropped
+	dropped := processor.HealthReportsDropped()
+	require.Eventually(t, func() bool {
+		return processor.HealthReportsDropped() >= dropped+2
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Shutdown aborts the hanging delivery
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, processor.Shutdown(ctx))
+
+	// The file destination is unaffected by the failing webhook
+	reports := readReportLines(t, path)
+	require.NotEmpty(t, reports)
+	last := reports[len(reports)-1]
+	assert.Equal(t, float64(20), last["records_processed"])
+	assert.Greater(t, last["health_reports_dropped"], float64(0))
+}
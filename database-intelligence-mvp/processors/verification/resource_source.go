@@ -0,0 +1,193 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errResourceUnsupported is returned when a measurement is not available on this platform
+var errResourceUnsupported = errors.New("resource measurement not supported on this platform")
+
+// ResourceSource provides system resource measurements to the health checker.
+// All values are percentages in the range 0-100. Tests inject fakes to drive
+// threshold alerting deterministically.
+type ResourceSource interface {
+	CPUPercent() (float64, error)
+	MemoryPercent() (float64, error)
+	DiskPercent() (float64, error)
+}
+
+// runtimeResourceSource measures the collector process and its data directory
+type runtimeResourceSource struct {
+	mu       sync.Mutex
+	diskPath string
+
+	// memoryLimit is the byte budget memory usage is reported against
+	memoryLimit uint64
+	// memoryInUse returns the bytes currently obtained from the OS
+	memoryInUse func() uint64
+
+	lastCPUTime time.Duration
+	lastSample  time.Time
+}
+
+// newRuntimeResourceSource creates a resource source reporting disk usage for diskPath.
+// Memory is reported as a percentage of memoryLimitBytes, or of the cgroup/system
+// memory limit when memoryLimitBytes is zero.
+func newRuntimeResourceSource(diskPath string, memoryLimitBytes uint64) *runtimeResourceSource {
+	if diskPath == "" {
+		diskPath = os.TempDir()
+	}
+	rs := &runtimeResourceSource{
+		diskPath:    diskPath,
+		memoryLimit: memoryLimitBytes,
+		memoryInUse: processMemoryBytes,
+	}
+	if rs.memoryLimit == 0 {
+		if limit, err := detectMemoryLimit(); err == nil {
+			rs.memoryLimit = limit
+		}
+	}
+
+	// Prime the CPU sample so the first reading covers a real interval
+	if cpuTime, err := processCPUTime(); err == nil {
+		rs.lastCPUTime = cpuTime
+		rs.lastSample = time.Now()
+	}
+	return rs
+}
+
+// CPUPercent returns process CPU usage since the previous call, normalized by core count
+func (rs *runtimeResourceSource) CPUPercent() (float64, error) {
+	cpuTime, err := processCPUTime()
+	if err != nil {
+		return 0, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	if rs.lastSample.IsZero() {
+		rs.lastCPUTime = cpuTime
+		rs.lastSample = now
+		return 0, nil
+	}
+
+	wall := now.Sub(rs.lastSample)
+	used := cpuTime - rs.lastCPUTime
+	rs.lastCPUTime = cpuTime
+	rs.lastSample = now
+
+	if wall <= 0 {
+		return 0, nil
+	}
+
+	percent := float64(used) / float64(wall) / float64(runtime.NumCPU()) * 100
+	return clampPercent(percent), nil
+}
+
+// MemoryPercent returns memory obtained from the OS as a percentage of the memory limit
+func (rs *runtimeResourceSource) MemoryPercent() (float64, error) {
+	if rs.memoryLimit == 0 {
+		return 0, fmt.Errorf("%w: memory limit unknown, set health_thresholds.memory_limit_mib", errResourceUnsupported)
+	}
+	return clampPercent(float64(rs.memoryInUse()) / float64(rs.memoryLimit) * 100), nil
+}
+
+// DiskPercent returns the used percentage of the filesystem holding the data directory
+func (rs *runtimeResourceSource) DiskPercent() (float64, error) {
+	total, free, err := diskSpace(rs.diskPath)
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("filesystem at %s reports zero size", rs.diskPath)
+	}
+	return clampPercent(float64(total-free) / float64(total) * 100), nil
+}
+
+// processMemoryBytes returns the memory obtained from the OS by the Go runtime
+func processMemoryBytes() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys
+}
+
+// detectMemoryLimit returns the container memory limit when one is set,
+// falling back to total system memory
+func detectMemoryLimit() (uint64, error) {
+	total, err := systemMemoryBytes()
+	if limit, ok := cgroupMemoryLimit(); ok && (err != nil || limit < total) {
+		return limit, nil
+	}
+	return total, err
+}
+
+// cgroupMemoryLimit reads the cgroup v2 or v1 memory limit
+func cgroupMemoryLimit() (uint64, bool) {
+	for _, path := range []string{
+		"/sys/fs/cgroup/memory.max",                   // cgroup v2
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes", // cgroup v1
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, false
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || limit == 0 {
+			continue
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// systemMemoryBytes reads MemTotal from /proc/meminfo
+func systemMemoryBytes() (uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, errResourceUnsupported
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid MemTotal value %q: %w", fields[1], err)
+			}
+			return kb * 1024, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("MemTotal not found in /proc/meminfo")
+}
+
+func clampPercent(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Tunable parameter names recognised by the auto-tuning engine
+const (
+	paramConsumerTimeout   = "consumer_timeout"
+	paramQualitySampleRate = "quality_sample_rate"
+)
+
+// Bounds keeping auto-tuned values within safe operating ranges
+const (
+	minQualitySampleRate = 0.1
+	maxConsumerTimeout   = 5 * time.Minute
+)
+
+// liveParameters holds the processor settings that auto-tuning may change at
+// runtime. Values are read on the hot path, so they are stored atomically.
+type liveParameters struct {
+	consumerTimeout   atomic.Int64  // nanoseconds; zero disables the deadline
+	qualitySampleRate atomic.Uint64 // math.Float64bits of a 0.0-1.0 fraction
+}
+
+// AppliedTuning records a parameter change made by the auto-tuning engine
+type AppliedTuning struct {
+	Timestamp time.Time
+	Parameter string
+	Before    interface{}
+	After     interface{}
+	Reason    string
+}
+
+func newLiveParameters(cfg *Config) *liveParameters {
+	lp := &liveParameters{}
+	lp.setConsumerTimeout(cfg.ConsumerTimeout)
+	lp.setQualitySampleRate(cfg.QualitySampleRate)
+	return lp
+}
+
+// ConsumerTimeout returns the deadline applied to the downstream consumer
+func (lp *liveParameters) ConsumerTimeout() time.Duration {
+	return time.Duration(lp.consumerTimeout.Load())
+}
+
+func (lp *liveParameters) setConsumerTimeout(d time.Duration) {
+	lp.consumerTimeout.Store(int64(d))
+}
+
+// QualitySampleRate returns the fraction of records that receive quality validation
+func (lp *liveParameters) QualitySampleRate() float64 {
+	return math.Float64frombits(lp.qualitySampleRate.Load())
+}
+
+func (lp *liveParameters) setQualitySampleRate(rate float64) {
+	lp.qualitySampleRate.Store(math.Float64bits(rate))
+}
+
+// apply sets a tunable parameter from a recommendation, returning the previous
+// and new values. Unknown parameters or mistyped values are rejected.
+func (lp *liveParameters) apply(rec TuningRecommendation) (before, after interface{}, ok bool) {
+	switch rec.Parameter {
+	case paramConsumerTimeout:
+		value, isDuration := rec.SuggestedValue.(time.Duration)
+		if !isDuration {
+			return nil, nil, false
+		}
+		if value > maxConsumerTimeout {
+			value = maxConsumerTimeout
+		}
+		before = lp.ConsumerTimeout()
+		lp.setConsumerTimeout(value)
+		return before, value, true
+
+	case paramQualitySampleRate:
+		value, isFloat := rec.SuggestedValue.(float64)
+		if !isFloat {
+			return nil, nil, false
+		}
+		value = math.Min(math.Max(value, minQualitySampleRate), 1.0)
+		before = lp.QualitySampleRate()
+		lp.setQualitySampleRate(value)
+		return before, value, true
+	}
+	return nil, nil, false
+}
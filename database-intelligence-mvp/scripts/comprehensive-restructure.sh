@@ -654,60 +654,105 @@ EOF
 print_status "Created minimal distribution"
 
 # Standard distribution
+# Mirrors distributions/standard in the restructured tree. The tracing,
+# export-health and cycle-metrics wrappers are added after migration, since
+# those packages do not exist in the MVP layout.
 cat > "${TARGET_DIR}/distributions/standard/main.go" << 'EOF'
+// Command standard runs the Database Intelligence Collector with the standard
+// subset of custom components compiled in.
 package main
 
 import (
     "log"
-    
+
     "go.opentelemetry.io/collector/component"
     "go.opentelemetry.io/collector/otelcol"
-    
-    // Import standard components
-    "github.com/database-intelligence/processors"
-    "github.com/database-intelligence/receivers"
-    "github.com/database-intelligence/exporters"
-    "github.com/database-intelligence/extensions"
 )
 
 func main() {
-    factories, err := components()
-    if err != nil {
-        log.Fatalf("failed to build components: %v", err)
-    }
-
     info := component.BuildInfo{
         Command:     "database-intelligence-standard",
         Description: "Database Intelligence Collector - Standard Edition",
         Version:     "2.0.0",
     }
 
-    if err := run(otelcol.CollectorSettings{
+    settings := otelcol.CollectorSettings{
         BuildInfo: info,
-        Factories: factories,
-    }); err != nil {
+        Factories: components,
+    }
+
+    if err := otelcol.NewCommand(settings).Execute(); err != nil {
         log.Fatal(err)
     }
 }
+EOF
+
+cat > "${TARGET_DIR}/distributions/standard/components.go" << 'EOF'
+package main
 
+import (
+    "go.opentelemetry.io/collector/connector"
+    "go.opentelemetry.io/collector/exporter"
+    "go.opentelemetry.io/collector/exporter/debugexporter"
+    "go.opentelemetry.io/collector/exporter/otlpexporter"
+    "go.opentelemetry.io/collector/extension"
+    "go.opentelemetry.io/collector/otelcol"
+    "go.opentelemetry.io/collector/processor"
+    "go.opentelemetry.io/collector/processor/batchprocessor"
+    "go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+    "go.opentelemetry.io/collector/receiver"
+    "go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+    "github.com/database-intelligence/exporters/nri"
+    "github.com/database-intelligence/extensions/healthcheck"
+    "github.com/database-intelligence/processors/adaptivesampler"
+    "github.com/database-intelligence/processors/circuitbreaker"
+    "github.com/database-intelligence/receivers/ash"
+    "github.com/database-intelligence/receivers/kernelmetrics"
+)
+
+// components returns the standard subset of components, keyed by component.Type
 func components() (otelcol.Factories, error) {
     var err error
     factories := otelcol.Factories{}
 
-    // Add custom processors (subset)
-    factories.Processors = map[string]processor.Factory{
-        "adaptivesampler": processors.AdaptiveSamplerFactory(),
-        "circuitbreaker": processors.CircuitBreakerFactory(),
+    factories.Extensions, err = extension.MakeFactoryMap(
+        healthcheck.NewFactory(),
+    )
+    if err != nil {
+        return factories, err
     }
 
-    // Standard receivers
-    factories.Receivers, err = receivers.Factories()
+    factories.Receivers, err = receiver.MakeFactoryMap(
+        otlpreceiver.NewFactory(),
+        ash.NewFactory(),
+        kernelmetrics.NewFactory(),
+    )
     if err != nil {
         return factories, err
     }
 
-    // Standard exporters
-    factories.Exporters, err = exporters.Factories()
+    // Custom processors (subset)
+    factories.Processors, err = processor.MakeFactoryMap(
+        batchprocessor.NewFactory(),
+        memorylimiterprocessor.NewFactory(),
+        adaptivesampler.NewFactory(),
+        circuitbreaker.NewFactory(),
+    )
+    if err != nil {
+        return factories, err
+    }
+
+    factories.Exporters, err = exporter.MakeFactoryMap(
+        debugexporter.NewFactory(),
+        otlpexporter.NewFactory(),
+        nri.NewFactory(),
+    )
+    if err != nil {
+        return factories, err
+    }
+
+    factories.Connectors, err = connector.MakeFactoryMap()
     if err != nil {
         return factories, err
     }
@@ -715,6 +760,40 @@ func components() (otelcol.Factories, error) {
     return factories, nil
 }
 EOF
+
+# Smoke test that the standard factories construct and provide default configs
+cat > "${TARGET_DIR}/distributions/standard/components_test.go" << 'EOF'
+package main
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.opentelemetry.io/collector/component"
+)
+
+func TestComponents(t *testing.T) {
+    factories, err := components()
+    require.NoError(t, err)
+
+    for _, typ := range []string{"adaptivesampler", "circuit_breaker"} {
+        factory, ok := factories.Processors[component.MustNewType(typ)]
+        require.True(t, ok, "standard distribution is missing processor %q", typ)
+        assert.NotNil(t, factory.CreateDefaultConfig(), "processor %s", typ)
+    }
+
+    for typ, f := range factories.Receivers {
+        assert.NotNil(t, f.CreateDefaultConfig(), "receiver %s", typ)
+    }
+    for typ, f := range factories.Exporters {
+        assert.NotNil(t, f.CreateDefaultConfig(), "exporter %s", typ)
+    }
+    for typ, f := range factories.Extensions {
+        assert.NotNil(t, f.CreateDefaultConfig(), "extension %s", typ)
+    }
+}
+EOF
 print_status "Created standard distribution"
 
 # Enterprise distribution
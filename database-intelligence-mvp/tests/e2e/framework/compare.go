@@ -0,0 +1,177 @@
+package framework
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults for ComparisonOptions
+const (
+	DefaultDurationRegressionPercent = 20.0
+	DefaultMinComparedDuration       = 10 * time.Second
+)
+
+// ComparisonOptions controls what counts as a regression between two runs
+type ComparisonOptions struct {
+	// DurationRegressionPercent is the suite duration increase, in percent,
+	// reported as a regression
+	DurationRegressionPercent float64
+
+	// MinComparedDuration skips duration checks for suites faster than this
+	// in both runs, where small absolute changes are large relative ones
+	MinComparedDuration time.Duration
+}
+
+// DefaultComparisonOptions returns the options used by the orchestrator
+func DefaultComparisonOptions() ComparisonOptions {
+	return ComparisonOptions{
+		DurationRegressionPercent: DefaultDurationRegressionPercent,
+		MinComparedDuration:       DefaultMinComparedDuration,
+	}
+}
+
+// DurationRegression is a suite that got slower than the threshold allows
+type DurationRegression struct {
+	Suite         string        `json:"suite"`
+	Baseline      time.Duration `json:"baseline"`
+	Current       time.Duration `json:"current"`
+	ChangePercent float64       `json:"change_percent"`
+}
+
+// ResultDiff describes how a run differs from a baseline run
+type ResultDiff struct {
+	BaselineID          string               `json:"baseline_id"`
+	CurrentID           string               `json:"current_id"`
+	NewlyFailing        []string             `json:"newly_failing"`
+	NewlyPassing        []string             `json:"newly_passing"`
+	StillFailing        []string             `json:"still_failing"`
+	AddedSuites         []string             `json:"added_suites"`
+	RemovedSuites       []string             `json:"removed_suites"`
+	DurationRegressions []DurationRegression `json:"duration_regressions"`
+}
+
+// HasRegressions reports whether the current run is worse than the baseline
+func (d *ResultDiff) HasRegressions() bool {
+	return len(d.NewlyFailing) > 0 || len(d.DurationRegressions) > 0
+}
+
+// CompareResults compares the suites of the current run with a baseline run.
+// Suites are matched by name; a suite only present in the current run that
+// fails counts as newly failing.
+func CompareResults(baseline, current *ExecutionResult, opts ComparisonOptions) *ResultDiff {
+	diff := &ResultDiff{
+		BaselineID: baseline.ExecutionID,
+		CurrentID:  current.ExecutionID,
+	}
+
+	baselineSuites := suitesByName(baseline)
+	currentSuites := suitesByName(current)
+
+	for _, name := range sortedSuiteNames(currentSuites) {
+		cur := currentSuites[name]
+		base, existed := baselineSuites[name]
+		if !existed {
+			diff.AddedSuites = append(diff.AddedSuites, name)
+		}
+
+		curFailed := cur.Status == StatusFailed
+		baseFailed := existed && base.Status == StatusFailed
+		switch {
+		case curFailed && baseFailed:
+			diff.StillFailing = append(diff.StillFailing, name)
+		case curFailed:
+			diff.NewlyFailing = append(diff.NewlyFailing, name)
+		case baseFailed && cur.Status == StatusPassed:
+			diff.NewlyPassing = append(diff.NewlyPassing, name)
+		}
+
+		if existed && !curFailed && !baseFailed {
+			if regression, ok := durationRegression(name, base.Duration(), cur.Duration(), opts); ok {
+				diff.DurationRegressions = append(diff.DurationRegressions, regression)
+			}
+		}
+	}
+
+	for _, name := range sortedSuiteNames(baselineSuites) {
+		if _, ok := currentSuites[name]; !ok {
+			diff.RemovedSuites = append(diff.RemovedSuites, name)
+		}
+	}
+
+	return diff
+}
+
+func durationRegression(suite string, baseline, current time.Duration, opts ComparisonOptions) (DurationRegression, bool) {
+	if baseline <= 0 || (baseline < opts.MinComparedDuration && current < opts.MinComparedDuration) {
+		return DurationRegression{}, false
+	}
+
+	change := float64(current-baseline) / float64(baseline) * 100
+	if change <= opts.DurationRegressionPercent {
+		return DurationRegression{}, false
+	}
+	return DurationRegression{
+		Suite:         suite,
+		Baseline:      baseline,
+		Current:       current,
+		ChangePercent: change,
+	}, true
+}
+
+func suitesByName(result *ExecutionResult) map[string]*TestResult {
+	suites := make(map[string]*TestResult, len(result.Results))
+	for _, suite := range result.Results {
+		if suite != nil {
+			suites[suite.SuiteName] = suite
+		}
+	}
+	return suites
+}
+
+func sortedSuiteNames(suites map[string]*TestResult) []string {
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteMarkdown writes a concise diff suitable for a CI pull request comment
+func (d *ResultDiff) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### E2E results: %s vs baseline %s\n\n", d.CurrentID, d.BaselineID)
+	if d.HasRegressions() {
+		b.WriteString(":x: Regressions detected\n")
+	} else {
+		b.WriteString(":white_check_mark: No regressions\n")
+	}
+
+	writeSuiteList(&b, "Newly failing", d.NewlyFailing)
+	writeSuiteList(&b, "Newly passing", d.NewlyPassing)
+	writeSuiteList(&b, "Still failing", d.StillFailing)
+	writeSuiteList(&b, "Added suites", d.AddedSuites)
+	writeSuiteList(&b, "Removed suites", d.RemovedSuites)
+
+	if len(d.DurationRegressions) > 0 {
+		b.WriteString("\n**Slower suites**\n\n| Suite | Baseline | Current | Change |\n|---|---|---|---|\n")
+		for _, r := range d.DurationRegressions {
+			fmt.Fprintf(&b, "| %s | %s | %s | +%.0f%% |\n",
+				r.Suite, r.Baseline.Round(time.Second), r.Current.Round(time.Second), r.ChangePercent)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSuiteList(b *strings.Builder, title string, suites []string) {
+	if len(suites) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n**%s** (%d): %s\n", title, len(suites), strings.Join(suites, ", "))
+}
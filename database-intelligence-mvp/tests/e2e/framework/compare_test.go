@@ -0,0 +1,96 @@
+package framework
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func suiteResult(name string, status TestStatus, duration time.Duration) *TestResult {
+	start := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	return &TestResult{SuiteName: name, Status: status, StartTime: start, EndTime: start.Add(duration)}
+}
+
+func TestCompareResults(t *testing.T) {
+	baseline := &ExecutionResult{
+		ExecutionID: "e2e_local_1",
+		Results: []*TestResult{
+			suiteResult("core_pipeline", StatusPassed, time.Minute),
+			suiteResult("database_integration", StatusFailed, time.Minute),
+			suiteResult("newrelic_integration", StatusPassed, 2*time.Minute),
+			suiteResult("security_compliance", StatusFailed, time.Minute),
+			suiteResult("quick_smoke", StatusPassed, time.Second),
+			suiteResult("legacy", StatusPassed, time.Minute),
+		},
+	}
+	current := &ExecutionResult{
+		ExecutionID: "e2e_local_2",
+		Results: []*TestResult{
+			suiteResult("core_pipeline", StatusFailed, time.Minute),
+			suiteResult("database_integration", StatusPassed, time.Minute),
+			suiteResult("newrelic_integration", StatusPassed, 3*time.Minute),
+			suiteResult("security_compliance", StatusFailed, time.Minute),
+			suiteResult("quick_smoke", StatusPassed, 3*time.Second),
+			suiteResult("performance_testing", StatusPassed, time.Minute),
+		},
+	}
+
+	diff := CompareResults(baseline, current, DefaultComparisonOptions())
+
+	assert.Equal(t, []string{"core_pipeline"}, diff.NewlyFailing)
+	assert.Equal(t, []string{"database_integration"}, diff.NewlyPassing)
+	assert.Equal(t, []string{"security_compliance"}, diff.StillFailing)
+	assert.Equal(t, []string{"performance_testing"}, diff.AddedSuites)
+	assert.Equal(t, []string{"legacy"}, diff.RemovedSuites)
+
+	// quick_smoke tripled but stays under the minimum compared duration
+	require.Len(t, diff.DurationRegressions, 1)
+	assert.Equal(t, "newrelic_integration", diff.DurationRegressions[0].Suite)
+	assert.InDelta(t, 50.0, diff.DurationRegressions[0].ChangePercent, 0.01)
+	assert.True(t, diff.HasRegressions())
+
+	var buf bytes.Buffer
+	require.NoError(t, diff.WriteMarkdown(&buf))
+	assert.Contains(t, buf.String(), "**Newly failing** (1): core_pipeline")
+	assert.Contains(t, buf.String(), "| newrelic_integration | 2m0s | 3m0s | +50% |")
+}
+
+func TestCompareResultsThreshold(t *testing.T) {
+	baseline := &ExecutionResult{Results: []*TestResult{suiteResult("core_pipeline", StatusPassed, time.Minute)}}
+	current := &ExecutionResult{Results: []*TestResult{suiteResult("core_pipeline", StatusPassed, 70*time.Second)}}
+
+	diff := CompareResults(baseline, current, DefaultComparisonOptions())
+	assert.False(t, diff.HasRegressions(), "a 17% slowdown is below the default threshold")
+
+	opts := DefaultComparisonOptions()
+	opts.DurationRegressionPercent = 10
+	diff = CompareResults(baseline, current, opts)
+	assert.True(t, diff.HasRegressions())
+}
+
+func TestLoadExecutionResultRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	result := failingExecutionResult()
+	result.Results[0].TestCases[1].Error = errors.New("redaction missed 3 emails")
+
+	reporter := NewReporter(dir, ReportingConfig{Formats: []string{ReportFormatJSON}})
+	require.NoError(t, reporter.GenerateReports(result))
+
+	loaded, err := LoadExecutionResult(filepath.Join(dir, technicalReportFile))
+	require.NoError(t, err)
+
+	assert.Equal(t, result.ExecutionID, loaded.ExecutionID)
+	require.Len(t, loaded.Results, 2)
+	assert.Equal(t, result.Results[0].Duration(), loaded.Results[0].Duration())
+	assert.EqualError(t, loaded.Results[0].TestCases[1].Error, "redaction missed 3 emails")
+	assert.EqualError(t, loaded.Results[1].Error, "suite setup failed: connection refused")
+	assert.NoError(t, loaded.Error)
+
+	diff := CompareResults(loaded, result, DefaultComparisonOptions())
+	assert.False(t, diff.HasRegressions())
+}
@@ -0,0 +1,665 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	// Database drivers used by the readiness checks
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+const (
+	defaultPostgresImage  = "postgres:15-alpine"
+	defaultMySQLImage     = "mysql:8.0"
+	defaultCollectorImage = "otel/opentelemetry-collector-contrib:0.91.0"
+
+	// Container ports of the collector
+	collectorOTLPPort       = 4317
+	collectorTelemetryPort  = 8888
+	collectorPrometheusPort = 8889
+	collectorHealthPort     = 13133
+
+	// maxProvisionAttempts bounds retries when a host port is taken between
+	// allocation and container start
+	maxProvisionAttempts = 3
+
+	composeFileName         = "docker-compose.yml"
+	collectorConfigFileName = "collector.yaml"
+	cleanupTimeout          = 2 * time.Minute
+	readinessPollInterval   = 2 * time.Second
+)
+
+// composePorts are the host ports published by the environment
+type composePorts struct {
+	PostgreSQL int
+	MySQL      int
+	Collector  int
+	Metrics    int
+	Prometheus int
+	Health     int
+}
+
+// commandRunner executes a command in dir and returns its combined output
+type commandRunner func(ctx context.Context, dir string, name string, args ...string) ([]byte, error)
+
+func execCommand(ctx context.Context, dir string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// ComposeEnvironmentManager provisions PostgreSQL, MySQL and the collector
+// with a generated Docker Compose project. Host ports are allocated per run
+// so several environments can coexist on one machine.
+type ComposeEnvironmentManager struct {
+	name    string
+	config  EnvironmentConfig
+	project string
+	run     commandRunner
+
+	mu          sync.Mutex
+	composeCmd  []string
+	workDir     string
+	composeFile string
+	ports       composePorts
+	env         *composeEnvironment
+}
+
+// NewComposeEnvironmentManager creates a Docker Compose environment manager
+func NewComposeEnvironmentManager(name string, config EnvironmentConfig) *ComposeEnvironmentManager {
+	return &ComposeEnvironmentManager{
+		name:    name,
+		config:  config,
+		project: fmt.Sprintf("e2e-%s-%d", sanitizeProjectName(name), time.Now().UnixNano()),
+		run:     execCommand,
+	}
+}
+
+// Name returns the environment manager name
+func (m *ComposeEnvironmentManager) Name() string {
+	return m.name
+}
+
+// Provision generates the compose project and starts it. Partially started
+// projects are torn down before an error is returned.
+func (m *ComposeEnvironmentManager) Provision(ctx context.Context) (TestEnvironment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.env != nil {
+		return m.env, nil
+	}
+
+	composeCmd, err := m.resolveComposeCommand(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.composeCmd = composeCmd
+
+	workDir, err := os.MkdirTemp("", m.project+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	m.workDir = workDir
+	m.composeFile = filepath.Join(workDir, composeFileName)
+
+	var lastErr error
+	for attempt := 1; attempt <= maxProvisionAttempts; attempt++ {
+		// Configured ports are only tried first; retries use ephemeral ports
+		m.ports, err = allocatePorts(m.config, attempt == 1)
+		if err != nil {
+			lastErr = err
+			break
+		}
+
+		if err := m.writeProjectFiles(); err != nil {
+			lastErr = err
+			break
+		}
+
+		output, err := m.compose(ctx, "up", "-d")
+		if err == nil {
+			lastErr = nil
+			break
+		}
+		lastErr = fmt.Errorf("docker compose up failed: %w: %s", err, strings.TrimSpace(string(output)))
+
+		m.down()
+		if !isPortConflict(output) {
+			break
+		}
+	}
+
+	if lastErr != nil {
+		os.RemoveAll(m.workDir)
+		m.workDir = ""
+		return nil, lastErr
+	}
+
+	if err := os.MkdirAll(filepath.Join(m.workDir, "artifacts"), 0755); err != nil {
+		m.down()
+		os.RemoveAll(m.workDir)
+		m.workDir = ""
+		return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	m.env = &composeEnvironment{
+		manager:   m,
+		ports:     m.ports,
+		createdAt: time.Now(),
+	}
+	return m.env, nil
+}
+
+// WaitForReady polls the databases and the collector health endpoint until
+// all of them respond or the timeout expires
+func (m *ComposeEnvironmentManager) WaitForReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := m.HealthCheck()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("environment %s not ready after %s: %w", m.name, timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup stops the compose project, removes its volumes and deletes the
+// generated files. It is safe to call more than once.
+func (m *ComposeEnvironmentManager) Cleanup() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.workDir == "" {
+		return nil
+	}
+
+	var errs []string
+	if output, err := m.down(); err != nil {
+		errs = append(errs, fmt.Sprintf("docker compose down failed: %v: %s", err, strings.TrimSpace(string(output))))
+	}
+	if err := os.RemoveAll(m.workDir); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to remove %s: %v", m.workDir, err))
+	}
+
+	m.workDir = ""
+	m.env = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("cleanup of environment %s: %s", m.name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// HealthCheck verifies that both databases accept connections and the
+// collector reports healthy
+func (m *ComposeEnvironmentManager) HealthCheck() error {
+	m.mu.Lock()
+	provisioned := m.workDir != ""
+	ports := m.ports
+	m.mu.Unlock()
+
+	if !provisioned {
+		return fmt.Errorf("environment %s is not provisioned", m.name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := pingDatabase(ctx, "postgres", m.postgresDSN(ports)); err != nil {
+		return fmt.Errorf("postgresql: %w", err)
+	}
+	if err := pingDatabase(ctx, "mysql", m.mysqlDSN(ports)); err != nil {
+		return fmt.Errorf("mysql: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d/", ports.Health), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("collector: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector: health check returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (m *ComposeEnvironmentManager) postgresDSN(ports composePorts) string {
+	db := m.config.Databases.PostgreSQL
+	return fmt.Sprintf("host=127.0.0.1 port=%d user=%s password=%s dbname=%s sslmode=disable",
+		ports.PostgreSQL, db.Username, db.Password, db.Database)
+}
+
+func (m *ComposeEnvironmentManager) mysqlDSN(ports composePorts) string {
+	db := m.config.Databases.MySQL
+	return fmt.Sprintf("%s:%s@tcp(127.0.0.1:%d)/%s", db.Username, db.Password, ports.MySQL, db.Database)
+}
+
+func pingDatabase(ctx context.Context, driver, dsn string) error {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.PingContext(ctx)
+}
+
+// resolveComposeCommand prefers the compose plugin and falls back to the
+// standalone docker-compose binary
+func (m *ComposeEnvironmentManager) resolveComposeCommand(ctx context.Context) ([]string, error) {
+	if _, err := m.run(ctx, "", "docker", "compose", "version"); err == nil {
+		return []string{"docker", "compose"}, nil
+	}
+	if _, err := m.run(ctx, "", "docker-compose", "version"); err == nil {
+		return []string{"docker-compose"}, nil
+	}
+	return nil, fmt.Errorf("neither 'docker compose' nor 'docker-compose' is available")
+}
+
+func (m *ComposeEnvironmentManager) compose(ctx context.Context, args ...string) ([]byte, error) {
+	full := append(append([]string{}, m.composeCmd[1:]...), "-p", m.project, "-f", m.composeFile)
+	full = append(full, args...)
+	return m.run(ctx, m.workDir, m.composeCmd[0], full...)
+}
+
+// down removes the project's containers, networks and volumes. It uses its
+// own context because it runs after the caller's context may be canceled.
+func (m *ComposeEnvironmentManager) down() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	return m.compose(ctx, "down", "-v", "--remove-orphans")
+}
+
+// writeProjectFiles renders the compose file and, unless a collector config
+// is configured, a collector config scraping both databases
+func (m *ComposeEnvironmentManager) writeProjectFiles() error {
+	collectorConfig := m.config.Collector.ConfigFile
+	if collectorConfig == "" {
+		collectorConfig = filepath.Join(m.workDir, collectorConfigFileName)
+		if err := renderToFile(collectorConfigTemplate, collectorConfig, m.templateData("")); err != nil {
+			return fmt.Errorf("failed to write collector config: %w", err)
+		}
+	} else {
+		abs, err := filepath.Abs(collectorConfig)
+		if err != nil {
+			return fmt.Errorf("invalid collector config path: %w", err)
+		}
+		collectorConfig = abs
+	}
+
+	if err := renderToFile(composeTemplate, m.composeFile, m.templateData(collectorConfig)); err != nil {
+		return fmt.Errorf("failed to write compose file: %w", err)
+	}
+	return nil
+}
+
+// composeTemplateData is the input of the compose and collector templates
+type composeTemplateData struct {
+	Project         string
+	Network         string
+	PostgresImage   string
+	MySQLImage      string
+	CollectorImage  string
+	CollectorConfig string
+	PostgreSQL      DatabaseConfig
+	MySQL           DatabaseConfig
+	Environment     map[string]string
+	Ports           composePorts
+	ContainerPorts  composePorts
+}
+
+func (m *ComposeEnvironmentManager) templateData(collectorConfig string) composeTemplateData {
+	network := m.config.NetworkConfig.NetworkName
+	if network == "" {
+		network = "e2e"
+	}
+	collectorImage := m.config.Collector.Image
+	if collectorImage == "" {
+		collectorImage = defaultCollectorImage
+	}
+
+	// Configured variables override the service addresses inside the network
+	environment := map[string]string{
+		"POSTGRES_HOST": "postgres",
+		"POSTGRES_PORT": "5432",
+		"MYSQL_HOST":    "mysql",
+		"MYSQL_PORT":    "3306",
+	}
+	for key, value := range m.config.Environment {
+		environment[key] = value
+	}
+
+	return composeTemplateData{
+		Project:         m.project,
+		Network:         network,
+		PostgresImage:   defaultPostgresImage,
+		MySQLImage:      defaultMySQLImage,
+		CollectorImage:  collectorImage,
+		CollectorConfig: collectorConfig,
+		PostgreSQL:      m.config.Databases.PostgreSQL,
+		MySQL:           m.config.Databases.MySQL,
+		Environment:     environment,
+		Ports:           m.ports,
+		ContainerPorts: composePorts{
+			PostgreSQL: 5432,
+			MySQL:      3306,
+			Collector:  collectorOTLPPort,
+			Metrics:    collectorTelemetryPort,
+			Prometheus: collectorPrometheusPort,
+			Health:     collectorHealthPort,
+		},
+	}
+}
+
+func renderToFile(tmpl *template.Template, path string, data composeTemplateData) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// allocatePorts picks a free host port for every published container port.
+// Configured ports are used when preferConfigured is set and they are free;
+// otherwise an ephemeral port is chosen.
+func allocatePorts(config EnvironmentConfig, preferConfigured bool) (composePorts, error) {
+	used := make(map[int]bool)
+	pick := func(configured int) (int, error) {
+		if preferConfigured && configured > 0 && !used[configured] && portAvailable(configured) {
+			used[configured] = true
+			return configured, nil
+		}
+		for i := 0; i < 10; i++ {
+			port, err := freePort()
+			if err != nil {
+				return 0, err
+			}
+			if !used[port] {
+				used[port] = true
+				return port, nil
+			}
+		}
+		return 0, fmt.Errorf("failed to allocate a unique host port")
+	}
+
+	var (
+		ports composePorts
+		err   error
+	)
+	targets := []struct {
+		port       *int
+		configured int
+	}{
+		{&ports.PostgreSQL, config.Databases.PostgreSQL.Port},
+		{&ports.MySQL, config.Databases.MySQL.Port},
+		{&ports.Collector, config.NetworkConfig.CollectorPort},
+		{&ports.Metrics, config.NetworkConfig.MetricsPort},
+		{&ports.Prometheus, 0},
+		{&ports.Health, 0},
+	}
+	for _, target := range targets {
+		if *target.port, err = pick(target.configured); err != nil {
+			return composePorts{}, err
+		}
+	}
+	return ports, nil
+}
+
+func portAvailable(port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// isPortConflict reports whether docker failed because a host port was taken
+func isPortConflict(output []byte) bool {
+	text := strings.ToLower(string(output))
+	return strings.Contains(text, "port is already allocated") ||
+		strings.Contains(text, "address already in use")
+}
+
+// sanitizeProjectName keeps the characters compose allows in project names
+func sanitizeProjectName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// composeEnvironment is the TestEnvironment of a running compose project
+type composeEnvironment struct {
+	manager   *ComposeEnvironmentManager
+	ports     composePorts
+	createdAt time.Time
+}
+
+// GetInfo returns information about the environment
+func (e *composeEnvironment) GetInfo() *EnvironmentInfo {
+	m := e.manager
+	data := m.templateData("")
+	health := "healthy"
+	if err := m.HealthCheck(); err != nil {
+		health = "unhealthy: " + err.Error()
+	}
+
+	return &EnvironmentInfo{
+		Name:    m.name,
+		Type:    EnvironmentTypeDockerCompose,
+		Version: data.CollectorImage,
+		Configuration: map[string]interface{}{
+			"project":             m.project,
+			"compose_file":        m.composeFile,
+			"collector_image":     data.CollectorImage,
+			"postgresql_port":     e.ports.PostgreSQL,
+			"mysql_port":          e.ports.MySQL,
+			"prometheus_endpoint": fmt.Sprintf("http://127.0.0.1:%d/metrics", e.ports.Prometheus),
+			"health_endpoint":     fmt.Sprintf("http://127.0.0.1:%d/", e.ports.Health),
+		},
+		Resources: &ResourcesInfo{
+			CPU:    m.config.Resources.CPU,
+			Memory: m.config.Resources.Memory,
+			Disk:   m.config.Resources.Disk,
+		},
+		Network: &NetworkInfo{
+			CollectorEndpoint: e.GetCollectorEndpoint(),
+			MetricsEndpoint:   e.GetMetricsEndpoint(),
+			NetworkName:       m.project + "_" + data.Network,
+		},
+		CreatedAt:    e.createdAt,
+		HealthStatus: health,
+	}
+}
+
+// GetConnectionInfo returns the host-side database connection information
+func (e *composeEnvironment) GetConnectionInfo() *ConnectionInfo {
+	pg := e.manager.config.Databases.PostgreSQL
+	my := e.manager.config.Databases.MySQL
+
+	return &ConnectionInfo{
+		PostgreSQL: &DatabaseConnectionInfo{
+			Host:     "127.0.0.1",
+			Port:     e.ports.PostgreSQL,
+			Database: pg.Database,
+			Username: pg.Username,
+		},
+		MySQL: &DatabaseConnectionInfo{
+			Host:     "127.0.0.1",
+			Port:     e.ports.MySQL,
+			Database: my.Database,
+			Username: my.Username,
+		},
+	}
+}
+
+// GetCollectorEndpoint returns the collector's OTLP gRPC endpoint
+func (e *composeEnvironment) GetCollectorEndpoint() string {
+	return fmt.Sprintf("127.0.0.1:%d", e.ports.Collector)
+}
+
+// GetMetricsEndpoint returns the collector's internal telemetry endpoint
+func (e *composeEnvironment) GetMetricsEndpoint() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/metrics", e.ports.Metrics)
+}
+
+// IsHealthy checks if the environment is healthy
+func (e *composeEnvironment) IsHealthy() bool {
+	return e.manager.HealthCheck() == nil
+}
+
+// GetTempDir returns the artifacts directory; it is removed by Cleanup
+func (e *composeEnvironment) GetTempDir() string {
+	e.manager.mu.Lock()
+	defer e.manager.mu.Unlock()
+	return filepath.Join(e.manager.workDir, "artifacts")
+}
+
+var templateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+}
+
+var composeTemplate = template.Must(template.New("compose").Funcs(templateFuncs).Parse(`# Generated by the e2e framework for project {{.Project}}
+services:
+  postgres:
+    image: {{.PostgresImage}}
+    environment:
+      POSTGRES_USER: {{quote .PostgreSQL.Username}}
+      POSTGRES_PASSWORD: {{quote .PostgreSQL.Password}}
+      POSTGRES_DB: {{quote .PostgreSQL.Database}}
+    ports:
+      - "127.0.0.1:{{.Ports.PostgreSQL}}:{{.ContainerPorts.PostgreSQL}}"
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U {{.PostgreSQL.Username}}"]
+      interval: 5s
+      timeout: 5s
+      retries: 20
+    networks:
+      - {{.Network}}
+
+  mysql:
+    image: {{.MySQLImage}}
+    environment:
+      MYSQL_ROOT_PASSWORD: {{quote .MySQL.Password}}
+      MYSQL_DATABASE: {{quote .MySQL.Database}}
+{{- if ne .MySQL.Username "root"}}
+      MYSQL_USER: {{quote .MySQL.Username}}
+      MYSQL_PASSWORD: {{quote .MySQL.Password}}
+{{- end}}
+    ports:
+      - "127.0.0.1:{{.Ports.MySQL}}:{{.ContainerPorts.MySQL}}"
+    healthcheck:
+      test: ["CMD", "mysqladmin", "ping", "-h", "localhost"]
+      interval: 5s
+      timeout: 5s
+      retries: 20
+    networks:
+      - {{.Network}}
+
+  otel-collector:
+    image: {{.CollectorImage}}
+    command: ["--config=/etc/otel/config.yaml"]
+    volumes:
+      - {{quote .CollectorConfig}}:/etc/otel/config.yaml:ro
+    environment:
+{{- range $key, $value := .Environment}}
+      {{$key}}: {{quote $value}}
+{{- end}}
+    ports:
+      - "127.0.0.1:{{.Ports.Collector}}:{{.ContainerPorts.Collector}}"
+      - "127.0.0.1:{{.Ports.Metrics}}:{{.ContainerPorts.Metrics}}"
+      - "127.0.0.1:{{.Ports.Prometheus}}:{{.ContainerPorts.Prometheus}}"
+      - "127.0.0.1:{{.Ports.Health}}:{{.ContainerPorts.Health}}"
+    depends_on:
+      postgres:
+        condition: service_healthy
+      mysql:
+        condition: service_healthy
+    networks:
+      - {{.Network}}
+
+networks:
+  {{.Network}}: {}
+`))
+
+var collectorConfigTemplate = template.Must(template.New("collector").Funcs(templateFuncs).Parse(`extensions:
+  health_check:
+    endpoint: 0.0.0.0:{{.ContainerPorts.Health}}
+
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:{{.ContainerPorts.Collector}}
+  postgresql:
+    endpoint: postgres:{{.ContainerPorts.PostgreSQL}}
+    username: {{quote .PostgreSQL.Username}}
+    password: {{quote .PostgreSQL.Password}}
+    databases: [{{quote .PostgreSQL.Database}}]
+    collection_interval: 10s
+    tls:
+      insecure: true
+  mysql:
+    endpoint: mysql:{{.ContainerPorts.MySQL}}
+    username: {{quote .MySQL.Username}}
+    password: {{quote .MySQL.Password}}
+    database: {{quote .MySQL.Database}}
+    collection_interval: 10s
+
+exporters:
+  prometheus:
+    endpoint: 0.0.0.0:{{.ContainerPorts.Prometheus}}
+
+service:
+  extensions: [health_check]
+  telemetry:
+    metrics:
+      address: 0.0.0.0:{{.ContainerPorts.Metrics}}
+  pipelines:
+    metrics:
+      receivers: [otlp, postgresql, mysql]
+      exporters: [prometheus]
+`))
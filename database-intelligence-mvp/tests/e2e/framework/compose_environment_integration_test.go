@@ -0,0 +1,45 @@
+//go:build e2e
+
+package framework
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComposeEnvironmentLifecycle provisions the real compose project and
+// requires a Docker daemon. Run with: go test -tags e2e ./framework/
+func TestComposeEnvironmentLifecycle(t *testing.T) {
+	manager := NewComposeEnvironmentManager("integration", testEnvironmentConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	env, err := manager.Provision(ctx)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, manager.Cleanup())
+	}()
+
+	require.NoError(t, manager.WaitForReady(ctx, 5*time.Minute))
+	assert.True(t, env.IsHealthy())
+
+	info := env.GetInfo()
+	assert.Equal(t, "healthy", info.HealthStatus)
+
+	conn := env.GetConnectionInfo()
+	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s password=postgres dbname=%s sslmode=disable",
+		conn.PostgreSQL.Host, conn.PostgreSQL.Port, conn.PostgreSQL.Username, conn.PostgreSQL.Database))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var one int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&one))
+	assert.Equal(t, 1, one)
+}
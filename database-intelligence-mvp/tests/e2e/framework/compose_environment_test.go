@@ -0,0 +1,162 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnvironmentConfig() EnvironmentConfig {
+	return EnvironmentConfig{
+		Type: EnvironmentTypeDockerCompose,
+		Databases: DatabasesConfig{
+			PostgreSQL: DatabaseConfig{Database: "testdb", Username: "postgres", Password: "postgres"},
+			MySQL:      DatabaseConfig{Database: "testdb", Username: "root", Password: "root"},
+		},
+		NetworkConfig: NetworkConfig{NetworkName: "e2e-test-network"},
+		Environment:   map[string]string{"POSTGRES_HOST": "postgres", "NEW_RELIC_LICENSE_KEY": "${TEST_NR_LICENSE_KEY}"},
+	}
+}
+
+// fakeCompose records compose invocations and fails "up" a configurable number of times
+type fakeCompose struct {
+	calls     []string
+	upFailure []byte
+	upFails   int
+}
+
+func (f *fakeCompose) run(ctx context.Context, dir string, name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+	if strings.HasSuffix(call, " up -d") && f.upFails > 0 {
+		f.upFails--
+		return f.upFailure, errors.New("exit status 1")
+	}
+	return nil, nil
+}
+
+func (f *fakeCompose) count(suffix string) int {
+	n := 0
+	for _, call := range f.calls {
+		if strings.HasSuffix(call, suffix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNewEnvironmentManager(t *testing.T) {
+	config := &TestConfig{Environments: map[string]EnvironmentConfig{
+		"local": testEnvironmentConfig(),
+		"k8s":   {Type: "kubernetes"},
+	}}
+
+	manager, err := NewEnvironmentManager("local", config)
+	require.NoError(t, err)
+	assert.Equal(t, "local", manager.Name())
+
+	_, err = NewEnvironmentManager("k8s", config)
+	assert.ErrorContains(t, err, "unsupported type")
+
+	_, err = NewEnvironmentManager("missing", config)
+	assert.ErrorContains(t, err, "not configured")
+}
+
+func TestComposeEnvironmentProvisionWritesProject(t *testing.T) {
+	fake := &fakeCompose{}
+	manager := NewComposeEnvironmentManager("local", testEnvironmentConfig())
+	manager.run = fake.run
+
+	env, err := manager.Provision(context.Background())
+	require.NoError(t, err)
+	defer manager.Cleanup()
+
+	compose, err := os.ReadFile(manager.composeFile)
+	require.NoError(t, err)
+	conn := env.GetConnectionInfo()
+	assert.Contains(t, string(compose), "127.0.0.1:"+strconv.Itoa(conn.PostgreSQL.Port)+":5432")
+	assert.Contains(t, string(compose), "127.0.0.1:"+strconv.Itoa(conn.MySQL.Port)+":3306")
+	assert.Contains(t, string(compose), `NEW_RELIC_LICENSE_KEY: "${TEST_NR_LICENSE_KEY}"`)
+	assert.Equal(t, 1, strings.Count(string(compose), "POSTGRES_HOST:"), "configured variables must not duplicate defaults")
+	assert.NotContains(t, string(compose), "MYSQL_USER", "root is created through MYSQL_ROOT_PASSWORD")
+
+	info := env.GetInfo()
+	assert.Equal(t, EnvironmentTypeDockerCompose, info.Type)
+	assert.Equal(t, manager.project+"_e2e-test-network", info.Network.NetworkName)
+	assert.Equal(t, env.GetCollectorEndpoint(), info.Network.CollectorEndpoint)
+	assert.DirExists(t, env.GetTempDir())
+
+	workDir := manager.workDir
+	require.NoError(t, manager.Cleanup())
+	assert.NoDirExists(t, workDir)
+	assert.Equal(t, 1, fake.count("down -v --remove-orphans"))
+
+	// A second cleanup, as done by the orchestrator on shutdown, is a no-op
+	require.NoError(t, manager.Cleanup())
+	assert.Equal(t, 1, fake.count("down -v --remove-orphans"))
+}
+
+func TestComposeEnvironmentRetriesPortConflicts(t *testing.T) {
+	fake := &fakeCompose{
+		upFailure: []byte("Bind for 127.0.0.1:5432 failed: port is already allocated"),
+		upFails:   1,
+	}
+	manager := NewComposeEnvironmentManager("local", testEnvironmentConfig())
+	manager.run = fake.run
+
+	_, err := manager.Provision(context.Background())
+	require.NoError(t, err)
+	defer manager.Cleanup()
+
+	assert.Equal(t, 2, fake.count("up -d"))
+	assert.Equal(t, 1, fake.count("down -v --remove-orphans"), "the failed attempt must be torn down")
+}
+
+func TestComposeEnvironmentTearsDownOnFailure(t *testing.T) {
+	fake := &fakeCompose{
+		upFailure: []byte("pull access denied"),
+		upFails:   maxProvisionAttempts,
+	}
+	manager := NewComposeEnvironmentManager("local", testEnvironmentConfig())
+	manager.run = fake.run
+
+	_, err := manager.Provision(context.Background())
+	require.ErrorContains(t, err, "pull access denied")
+
+	assert.Equal(t, 1, fake.count("up -d"), "only port conflicts are retried")
+	assert.GreaterOrEqual(t, fake.count("down -v --remove-orphans"), 1)
+	assert.Empty(t, manager.workDir)
+	assert.Error(t, manager.HealthCheck())
+}
+
+func TestAllocatePortsAvoidsBusyPorts(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	free, err := freePort()
+	require.NoError(t, err)
+
+	config := testEnvironmentConfig()
+	config.Databases.PostgreSQL.Port = busyPort
+	config.Databases.MySQL.Port = free
+
+	ports, err := allocatePorts(config, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, busyPort, ports.PostgreSQL)
+	assert.Equal(t, free, ports.MySQL)
+
+	seen := map[int]bool{}
+	for _, port := range []int{ports.PostgreSQL, ports.MySQL, ports.Collector, ports.Metrics, ports.Prometheus, ports.Health} {
+		assert.False(t, seen[port], "port %d allocated twice", port)
+		seen[port] = true
+	}
+}
@@ -0,0 +1,36 @@
+package framework
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Environment types supported by NewEnvironmentManager
+const (
+	EnvironmentTypeDockerCompose = "docker_compose"
+)
+
+// NewEnvironmentManager creates the environment manager for the named
+// environment in the test configuration
+func NewEnvironmentManager(name string, config *TestConfig) (EnvironmentManager, error) {
+	if config == nil {
+		return nil, fmt.Errorf("test configuration is required")
+	}
+
+	envConfig, ok := config.Environments[name]
+	if !ok {
+		available := make([]string, 0, len(config.Environments))
+		for envName := range config.Environments {
+			available = append(available, envName)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("environment %q is not configured (available: %v)", name, available)
+	}
+
+	switch envConfig.Type {
+	case EnvironmentTypeDockerCompose:
+		return NewComposeEnvironmentManager(name, envConfig), nil
+	default:
+		return nil, fmt.Errorf("environment %q has unsupported type %q", name, envConfig.Type)
+	}
+}
@@ -0,0 +1,223 @@
+package framework
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// JUnit XML schema as consumed by Jenkins, GitLab, GitHub Actions and most
+// other CI systems
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// suiteErrorCaseName names the synthetic test case reporting a suite-level
+// setup or execution error
+const suiteErrorCaseName = "suite"
+
+// WriteJUnit writes the execution result as JUnit XML
+func WriteJUnit(w io.Writer, result *ExecutionResult) error {
+	report := newJUnitReport(result)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newJUnitReport(result *ExecutionResult) *junitTestSuites {
+	report := &junitTestSuites{
+		Name: result.ExecutionID,
+		Time: junitSeconds(result.Duration()),
+	}
+
+	for _, suiteResult := range result.Results {
+		if suiteResult == nil {
+			continue
+		}
+		suite := newJUnitSuite(suiteResult)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return report
+}
+
+func newJUnitSuite(result *TestResult) junitTestSuite {
+	suite := junitTestSuite{
+		Name: result.SuiteName,
+		Time: junitSeconds(result.Duration()),
+	}
+	if !result.StartTime.IsZero() {
+		suite.Timestamp = result.StartTime.UTC().Format(time.RFC3339)
+	}
+	if result.Metadata != nil {
+		if len(result.Metadata.Tags) > 0 {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "tags", Value: strings.Join(result.Metadata.Tags, ",")})
+		}
+		if result.Metadata.Version != "" {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "version", Value: result.Metadata.Version})
+		}
+	}
+
+	for _, testCase := range result.TestCases {
+		if testCase == nil {
+			continue
+		}
+		suite.TestCases = append(suite.TestCases, newJUnitTestCase(result.SuiteName, testCase))
+	}
+
+	// Setup and execution errors have no test case of their own; report them
+	// as an errored case so CI systems surface the failure
+	if result.Error != nil {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      suiteErrorCaseName,
+			Classname: result.SuiteName,
+			Time:      junitSeconds(result.Duration()),
+			Error: &junitProblem{
+				Message:  result.Error.Error(),
+				Type:     "SuiteError",
+				Contents: result.Error.Error(),
+			},
+		})
+	}
+
+	for _, testCase := range suite.TestCases {
+		suite.Tests++
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+
+	return suite
+}
+
+func newJUnitTestCase(suiteName string, result *TestCaseResult) junitTestCase {
+	testCase := junitTestCase{
+		Name:      result.Name,
+		Classname: suiteName,
+		Time:      junitSeconds(result.Duration),
+		SystemOut: result.Description,
+	}
+
+	switch result.Status {
+	case StatusFailed:
+		message, details := failureDetails(result)
+		testCase.Failure = &junitProblem{
+			Message:  message,
+			Type:     "AssertionFailure",
+			Contents: details,
+		}
+	case StatusSkipped:
+		testCase.Skipped = &junitSkipped{}
+	case StatusCanceled:
+		testCase.Skipped = &junitSkipped{Message: "canceled"}
+	}
+
+	return testCase
+}
+
+// failureDetails summarizes why a test case failed: its error if set, then
+// every failed assertion with the expected and actual values
+func failureDetails(result *TestCaseResult) (string, string) {
+	var (
+		message string
+		details []string
+	)
+
+	if result.Error != nil {
+		message = result.Error.Error()
+		details = append(details, message)
+	}
+
+	for _, assertion := range result.Assertions {
+		if assertion == nil || assertion.Status != StatusFailed {
+			continue
+		}
+		line := fmt.Sprintf("%s: expected %v, got %v", assertion.Name, assertion.Expected, assertion.Actual)
+		if assertion.Message != "" {
+			line += " (" + assertion.Message + ")"
+		}
+		if assertion.Error != nil {
+			line += ": " + assertion.Error.Error()
+		}
+		if message == "" {
+			message = line
+		}
+		details = append(details, line)
+	}
+
+	if message == "" {
+		message = "test case failed"
+	}
+	return message, strings.Join(details, "\n")
+}
+
+func junitSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
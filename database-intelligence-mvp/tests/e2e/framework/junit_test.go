@@ -0,0 +1,121 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failingExecutionResult() *ExecutionResult {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return &ExecutionResult{
+		ExecutionID: "e2e_local_1",
+		Status:      StatusFailed,
+		StartTime:   start,
+		EndTime:     start.Add(90 * time.Second),
+		Results: []*TestResult{
+			{
+				SuiteName: "core_pipeline",
+				Status:    StatusFailed,
+				StartTime: start,
+				EndTime:   start.Add(30 * time.Second),
+				Metadata:  &SuiteMetadata{Tags: []string{"core", "pipeline"}},
+				TestCases: []*TestCaseResult{
+					{Name: "metrics_flow", Status: StatusPassed, Duration: 1500 * time.Millisecond},
+					{
+						Name:     "pii_redaction",
+						Status:   StatusFailed,
+						Duration: 2 * time.Second,
+						Assertions: []*AssertionResult{
+							{Name: "emails_redacted", Status: StatusFailed, Expected: 0, Actual: 3, Message: "found <email> in output"},
+						},
+					},
+					{Name: "mysql_only", Status: StatusSkipped},
+				},
+			},
+			{
+				SuiteName: "database_coverage",
+				Status:    StatusFailed,
+				StartTime: start.Add(30 * time.Second),
+				EndTime:   start.Add(31 * time.Second),
+				Error:     errors.New("suite setup failed: connection refused"),
+			},
+		},
+	}
+}
+
+func TestWriteJUnitRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, failingExecutionResult()))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, "e2e_local_1", report.Name)
+	assert.Equal(t, "90.000", report.Time)
+	assert.Equal(t, 4, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Suites, 2)
+
+	core := report.Suites[0]
+	assert.Equal(t, "core_pipeline", core.Name)
+	assert.Equal(t, "2024-01-02T03:04:05Z", core.Timestamp)
+	assert.Equal(t, []junitProperty{{Name: "tags", Value: "core,pipeline"}}, core.Properties)
+	require.Len(t, core.TestCases, 3)
+
+	assert.Equal(t, "1.500", core.TestCases[0].Time)
+	assert.Nil(t, core.TestCases[0].Failure)
+
+	failed := core.TestCases[1]
+	assert.Equal(t, "core_pipeline", failed.Classname)
+	require.NotNil(t, failed.Failure)
+	assert.Equal(t, "emails_redacted: expected 0, got 3 (found <email> in output)", failed.Failure.Message)
+
+	assert.NotNil(t, core.TestCases[2].Skipped)
+
+	setup := report.Suites[1]
+	assert.Equal(t, 1, setup.Errors)
+	require.Len(t, setup.TestCases, 1)
+	assert.Equal(t, suiteErrorCaseName, setup.TestCases[0].Name)
+	require.NotNil(t, setup.TestCases[0].Error)
+	assert.Equal(t, "suite setup failed: connection refused", setup.TestCases[0].Error.Message)
+}
+
+func TestGenerateReportsFormats(t *testing.T) {
+	dir := t.TempDir()
+	reporter := NewReporter(dir, ReportingConfig{Formats: []string{"json", "junit"}})
+
+	require.NoError(t, reporter.GenerateReports(failingExecutionResult()))
+	assert.FileExists(t, filepath.Join(dir, executiveSummaryFile))
+	assert.FileExists(t, filepath.Join(dir, technicalReportFile))
+	assert.FileExists(t, filepath.Join(dir, junitReportFile))
+	assert.NoFileExists(t, filepath.Join(dir, dashboardFile))
+
+	data, err := os.ReadFile(filepath.Join(dir, junitReportFile))
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	assert.Len(t, report.Suites, 2)
+
+	// JUnit output is opt-in through the formats list
+	dir = t.TempDir()
+	reporter = NewReporter(dir, ReportingConfig{Formats: []string{"html"}})
+	require.NoError(t, reporter.GenerateReports(failingExecutionResult()))
+	assert.NoFileExists(t, filepath.Join(dir, junitReportFile))
+	assert.FileExists(t, filepath.Join(dir, dashboardFile))
+
+	reporter = NewReporter(t.TempDir(), ReportingConfig{Formats: []string{"pdf"}})
+	assert.ErrorContains(t, reporter.GenerateReports(failingExecutionResult()), `unsupported report format "pdf"`)
+}
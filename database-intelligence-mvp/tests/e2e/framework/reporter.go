@@ -0,0 +1,200 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Report formats selectable through ReportingConfig.Formats
+const (
+	ReportFormatJSON  = "json"
+	ReportFormatHTML  = "html"
+	ReportFormatJUnit = "junit"
+)
+
+// Report file names written to the output directory
+const (
+	executiveSummaryFile = "summary.md"
+	technicalReportFile  = "report.json"
+	dashboardFile        = "dashboard.html"
+	junitReportFile      = "junit.xml"
+)
+
+// FileReporter writes reports for an execution into an output directory
+type FileReporter struct {
+	outputDir string
+	config    ReportingConfig
+}
+
+// NewReporter creates a reporter writing the configured formats to outputDir
+func NewReporter(outputDir string, config ReportingConfig) Reporter {
+	return &FileReporter{
+		outputDir: outputDir,
+		config:    config,
+	}
+}
+
+// GenerateReports creates the executive summary and every configured format
+func (r *FileReporter) GenerateReports(result *ExecutionResult) error {
+	if result == nil {
+		return fmt.Errorf("execution result is required")
+	}
+	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	errs := []error{r.GenerateExecutiveSummary(result)}
+
+	dashboard := r.config.DashboardGeneration
+	for _, format := range r.config.Formats {
+		switch strings.ToLower(format) {
+		case ReportFormatJSON:
+			errs = append(errs, r.GenerateTechnicalReport(result))
+		case ReportFormatJUnit:
+			errs = append(errs, r.GenerateJUnitReport(result))
+		case ReportFormatHTML:
+			dashboard = true
+		default:
+			errs = append(errs, fmt.Errorf("unsupported report format %q", format))
+		}
+	}
+	if dashboard {
+		errs = append(errs, r.GenerateDashboard(result))
+	}
+
+	return errors.Join(errs...)
+}
+
+// GenerateExecutiveSummary writes a short Markdown summary of the execution
+func (r *FileReporter) GenerateExecutiveSummary(result *ExecutionResult) error {
+	summary := summarize(result)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# E2E Test Execution %s\n\n", result.ExecutionID)
+	fmt.Fprintf(&b, "- Status: %s\n", result.Status)
+	fmt.Fprintf(&b, "- Duration: %s\n", result.Duration())
+	fmt.Fprintf(&b, "- Suites: %d passed, %d failed, %d skipped of %d\n",
+		summary.PassedSuites, summary.FailedSuites, summary.SkippedSuites, summary.TotalSuites)
+	fmt.Fprintf(&b, "- Pass rate: %.1f%%\n", summary.PassRate)
+	if result.Error != nil {
+		fmt.Fprintf(&b, "- Error: %v\n", result.Error)
+	}
+
+	for _, suite := range result.Results {
+		if suite != nil && suite.Status == StatusFailed {
+			fmt.Fprintf(&b, "\n## Failed: %s\n", suite.SuiteName)
+			if suite.Error != nil {
+				fmt.Fprintf(&b, "\n%v\n", suite.Error)
+			}
+		}
+	}
+
+	return r.writeFile(executiveSummaryFile, []byte(b.String()))
+}
+
+// GenerateTechnicalReport writes the full execution result as JSON
+func (r *FileReporter) GenerateTechnicalReport(result *ExecutionResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode technical report: %w", err)
+	}
+	return r.writeFile(technicalReportFile, data)
+}
+
+// GenerateJUnitReport writes the execution result as JUnit XML
+func (r *FileReporter) GenerateJUnitReport(result *ExecutionResult) error {
+	f, err := os.Create(filepath.Join(r.outputDir, junitReportFile))
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := WriteJUnit(f, result); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// GenerateDashboard writes a static HTML overview of the suites
+func (r *FileReporter) GenerateDashboard(result *ExecutionResult) error {
+	var b strings.Builder
+	data := struct {
+		Result  *ExecutionResult
+		Summary *ExecutionSummary
+	}{result, summarize(result)}
+
+	if err := dashboardTemplate.Execute(&b, data); err != nil {
+		return fmt.Errorf("failed to render dashboard: %w", err)
+	}
+	return r.writeFile(dashboardFile, []byte(b.String()))
+}
+
+func (r *FileReporter) writeFile(name string, data []byte) error {
+	if err := os.WriteFile(filepath.Join(r.outputDir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// summarize returns the result's summary, computing it from the suite
+// results when the orchestrator has not filled it in
+func summarize(result *ExecutionResult) *ExecutionSummary {
+	if result.Summary != nil {
+		return result.Summary
+	}
+
+	summary := &ExecutionSummary{TotalDuration: result.Duration()}
+	for _, suite := range result.Results {
+		if suite == nil {
+			continue
+		}
+		summary.TotalSuites++
+		switch suite.Status {
+		case StatusPassed:
+			summary.PassedSuites++
+		case StatusFailed:
+			summary.FailedSuites++
+		case StatusSkipped, StatusCanceled:
+			summary.SkippedSuites++
+		}
+	}
+	if summary.TotalSuites > 0 {
+		summary.PassRate = float64(summary.PassedSuites) / float64(summary.TotalSuites) * 100
+	}
+	return summary
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>E2E Test Execution {{.Result.ExecutionID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.passed { color: #2a7; } .failed { color: #c33; } .skipped, .canceled { color: #888; }
+</style>
+</head>
+<body>
+<h1>E2E Test Execution {{.Result.ExecutionID}}</h1>
+<p>Status: <span class="{{.Result.Status}}">{{.Result.Status}}</span>,
+{{.Summary.PassedSuites}}/{{.Summary.TotalSuites}} suites passed ({{printf "%.1f" .Summary.PassRate}}%)
+in {{.Result.Duration}}</p>
+<table>
+<tr><th>Suite</th><th>Status</th><th>Test cases</th><th>Duration</th><th>Error</th></tr>
+{{range .Result.Results}}{{if .}}<tr>
+<td>{{.SuiteName}}</td>
+<td class="{{.Status}}">{{.Status}}</td>
+<td>{{len .TestCases}}</td>
+<td>{{.Duration}}</td>
+<td>{{if .Error}}{{.Error}}{{end}}</td>
+</tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,156 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Result types carry errors, which encoding/json writes as empty objects and
+// cannot read back. The methods below store them as their message so stored
+// results can be loaded again, e.g. as a comparison baseline.
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func messageError(message string) error {
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
+
+// MarshalJSON encodes the execution result with its error as a string
+func (er *ExecutionResult) MarshalJSON() ([]byte, error) {
+	type alias ExecutionResult
+	return json.Marshal(&struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{(*alias)(er), errorMessage(er.Error)})
+}
+
+// UnmarshalJSON decodes an execution result written by MarshalJSON
+func (er *ExecutionResult) UnmarshalJSON(data []byte) error {
+	type alias ExecutionResult
+	aux := &struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{alias: (*alias)(er)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	er.Error = messageError(aux.Error)
+	return nil
+}
+
+// MarshalJSON encodes the suite result with its error as a string
+func (tr *TestResult) MarshalJSON() ([]byte, error) {
+	type alias TestResult
+	return json.Marshal(&struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{(*alias)(tr), errorMessage(tr.Error)})
+}
+
+// UnmarshalJSON decodes a suite result written by MarshalJSON
+func (tr *TestResult) UnmarshalJSON(data []byte) error {
+	type alias TestResult
+	aux := &struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{alias: (*alias)(tr)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	tr.Error = messageError(aux.Error)
+	return nil
+}
+
+// MarshalJSON encodes the attempt with its error as a string
+func (sa *SuiteAttempt) MarshalJSON() ([]byte, error) {
+	type alias SuiteAttempt
+	return json.Marshal(&struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{(*alias)(sa), errorMessage(sa.Error)})
+}
+
+// UnmarshalJSON decodes an attempt written by MarshalJSON
+func (sa *SuiteAttempt) UnmarshalJSON(data []byte) error {
+	type alias SuiteAttempt
+	aux := &struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{alias: (*alias)(sa)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	sa.Error = messageError(aux.Error)
+	return nil
+}
+
+// MarshalJSON encodes the test case result with its error as a string
+func (tc *TestCaseResult) MarshalJSON() ([]byte, error) {
+	type alias TestCaseResult
+	return json.Marshal(&struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{(*alias)(tc), errorMessage(tc.Error)})
+}
+
+// UnmarshalJSON decodes a test case result written by MarshalJSON
+func (tc *TestCaseResult) UnmarshalJSON(data []byte) error {
+	type alias TestCaseResult
+	aux := &struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{alias: (*alias)(tc)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	tc.Error = messageError(aux.Error)
+	return nil
+}
+
+// MarshalJSON encodes the assertion result with its error as a string
+func (ar *AssertionResult) MarshalJSON() ([]byte, error) {
+	type alias AssertionResult
+	return json.Marshal(&struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{(*alias)(ar), errorMessage(ar.Error)})
+}
+
+// UnmarshalJSON decodes an assertion result written by MarshalJSON
+func (ar *AssertionResult) UnmarshalJSON(data []byte) error {
+	type alias AssertionResult
+	aux := &struct {
+		*alias
+		Error string `json:"error,omitempty"`
+	}{alias: (*alias)(ar)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	ar.Error = messageError(aux.Error)
+	return nil
+}
+
+// LoadExecutionResult reads an execution result stored as JSON, such as the
+// report.json written by the reporter
+func LoadExecutionResult(path string) (*ExecutionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution result: %w", err)
+	}
+
+	var result ExecutionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse execution result %s: %w", path, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,342 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSoakInterval is the time between the starts of soak iterations
+// when none is configured
+const DefaultSoakInterval = 10 * time.Minute
+
+// SoakOptions schedules the iterations of a soak run
+type SoakOptions struct {
+	// Duration is how long new iterations are started for; the iteration
+	// running when it ends is finished. Zero leaves the run to Iterations.
+	Duration time.Duration
+
+	// Interval is the time between the starts of consecutive iterations.
+	// An iteration that takes longer delays the next one, which then starts
+	// as soon as it finishes.
+	Interval time.Duration
+
+	// Iterations ends the run after this many iterations. Zero leaves the
+	// run to Duration.
+	Iterations int
+
+	// Comparison decides when a suite got slower over the run
+	Comparison ComparisonOptions
+}
+
+// Validate checks that the options describe a run that ends
+func (o SoakOptions) Validate() error {
+	if o.Interval <= 0 {
+		return errors.New("soak interval must be positive")
+	}
+	if o.Duration < 0 || o.Iterations < 0 {
+		return errors.New("soak duration and iterations must not be negative")
+	}
+	if o.Duration == 0 && o.Iterations == 0 {
+		return errors.New("soak duration or iterations must be set")
+	}
+	return nil
+}
+
+// Trend compares the mean of a value over the first and the second half of
+// a soak run. With an odd number of samples the middle one is left out.
+type Trend struct {
+	First         float64 `json:"first"`
+	Last          float64 `json:"last"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// SuiteStability aggregates the runs of one suite over a soak run
+type SuiteStability struct {
+	Suite    string `json:"suite"`
+	Runs     int    `json:"runs"`
+	Passed   int    `json:"passed"`
+	Failed   int    `json:"failed"`
+	TimedOut int    `json:"timed_out"`
+
+	// Durations are the durations of the passed runs, in order
+	Durations    []time.Duration `json:"durations"`
+	MeanDuration time.Duration   `json:"mean_duration"`
+
+	// DurationTrend compares the passed runs' durations in seconds; nil
+	// with fewer than two passed runs
+	DurationTrend *Trend `json:"duration_trend,omitempty"`
+	// Degraded is set when the suite got slower than
+	// Comparison.DurationRegressionPercent allows
+	Degraded bool `json:"degraded"`
+
+	// MetricTrends follow the numeric metrics the suite reports, such as
+	// memory or CPU use, over its passed runs
+	MetricTrends map[string]Trend `json:"metric_trends,omitempty"`
+}
+
+// Flaky reports whether the suite both passed and failed during the run
+func (s *SuiteStability) Flaky() bool {
+	return s.Passed > 0 && s.Failed > 0
+}
+
+// FailureRate returns the share of failed runs, from 0 to 1
+func (s *SuiteStability) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Runs)
+}
+
+// SoakReport is the stability report of a soak run
+type SoakReport struct {
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Iterations int       `json:"iterations"`
+	// StopReason tells why no further iteration was started
+	StopReason string            `json:"stop_reason"`
+	Suites     []*SuiteStability `json:"suites"`
+
+	// DurationThreshold is the change in percent beyond which a suite's
+	// duration counts as degraded and a metric trend is listed
+	DurationThreshold float64 `json:"duration_threshold"`
+}
+
+// Unstable reports whether any suite failed or degraded during the run
+func (r *SoakReport) Unstable() bool {
+	for _, s := range r.Suites {
+		if s.Failed > 0 || s.Degraded {
+			return true
+		}
+	}
+	return false
+}
+
+// RunSoak calls run on the schedule of opts until the soak duration or the
+// number of iterations is reached, or ctx ends, and reports how stable each
+// suite was across the iterations. ctx is only checked between iterations:
+// run is expected to stop early on its own when ctx ends, such as when the
+// global timeout passes.
+func RunSoak(ctx context.Context, opts SoakOptions, run func(iteration int) []*TestResult) *SoakReport {
+	report := &SoakReport{StartTime: time.Now(), DurationThreshold: opts.Comparison.DurationRegressionPercent}
+
+	var deadline time.Time
+	if opts.Duration > 0 {
+		deadline = report.StartTime.Add(opts.Duration)
+	}
+
+	var iterations [][]*TestResult
+	for {
+		if err := ctx.Err(); err != nil {
+			report.StopReason = err.Error()
+			break
+		}
+
+		iterationStart := time.Now()
+		report.Iterations++
+		log.Printf("Starting soak iteration %d", report.Iterations)
+		iterations = append(iterations, run(report.Iterations))
+
+		if opts.Iterations > 0 && report.Iterations >= opts.Iterations {
+			report.StopReason = fmt.Sprintf("completed %d iterations", report.Iterations)
+			break
+		}
+		next := iterationStart.Add(opts.Interval)
+		if !deadline.IsZero() && !next.Before(deadline) {
+			report.StopReason = fmt.Sprintf("soak duration of %s ended", opts.Duration)
+			break
+		}
+		if !sleepContext(ctx, time.Until(next)) {
+			report.StopReason = ctx.Err().Error()
+			break
+		}
+	}
+
+	report.EndTime = time.Now()
+	report.Suites = suiteStability(iterations, opts.Comparison)
+	return report
+}
+
+// suiteStability aggregates the results of every iteration per suite, in
+// suite name order
+func suiteStability(iterations [][]*TestResult, opts ComparisonOptions) []*SuiteStability {
+	bySuite := make(map[string]*SuiteStability)
+	metrics := make(map[string]map[string][]float64)
+	for _, results := range iterations {
+		for _, result := range results {
+			if result == nil {
+				continue
+			}
+			s, ok := bySuite[result.SuiteName]
+			if !ok {
+				s = &SuiteStability{Suite: result.SuiteName}
+				bySuite[result.SuiteName] = s
+				metrics[result.SuiteName] = make(map[string][]float64)
+			}
+
+			s.Runs++
+			if result.TimedOut {
+				s.TimedOut++
+			}
+			switch result.Status {
+			case StatusFailed:
+				s.Failed++
+				continue
+			case StatusPassed:
+				s.Passed++
+			default:
+				continue
+			}
+
+			s.Durations = append(s.Durations, result.Duration())
+			for name, value := range result.Metrics {
+				if v, ok := numericMetric(value); ok {
+					metrics[result.SuiteName][name] = append(metrics[result.SuiteName][name], v)
+				}
+			}
+		}
+	}
+
+	suites := make([]*SuiteStability, 0, len(bySuite))
+	for name, s := range bySuite {
+		if len(s.Durations) > 0 {
+			seconds := make([]float64, len(s.Durations))
+			var total time.Duration
+			for i, d := range s.Durations {
+				seconds[i] = d.Seconds()
+				total += d
+			}
+			s.MeanDuration = total / time.Duration(len(s.Durations))
+
+			if trend, ok := newTrend(seconds); ok {
+				s.DurationTrend = &trend
+				first := time.Duration(trend.First * float64(time.Second))
+				last := time.Duration(trend.Last * float64(time.Second))
+				_, s.Degraded = durationRegression(name, first, last, opts)
+			}
+		}
+
+		for metric, values := range metrics[name] {
+			if trend, ok := newTrend(values); ok {
+				if s.MetricTrends == nil {
+					s.MetricTrends = make(map[string]Trend)
+				}
+				s.MetricTrends[metric] = trend
+			}
+		}
+		suites = append(suites, s)
+	}
+
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Suite < suites[j].Suite })
+	return suites
+}
+
+// newTrend compares the mean of the first and second half of values
+func newTrend(values []float64) (Trend, bool) {
+	if len(values) < 2 {
+		return Trend{}, false
+	}
+	half := len(values) / 2
+	trend := Trend{First: mean(values[:half]), Last: mean(values[len(values)-half:])}
+	if trend.First != 0 {
+		trend.ChangePercent = (trend.Last - trend.First) / math.Abs(trend.First) * 100
+	}
+	return trend, true
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// numericMetric converts a suite metric to a number, as decoded from JSON
+// or set by the suite
+func numericMetric(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case time.Duration:
+		return v.Seconds(), true
+	}
+	return 0, false
+}
+
+// WriteMarkdown writes the stability report. Metric trends are listed when
+// they changed by more than the duration regression threshold either way.
+func (r *SoakReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### Soak run: %d iterations over %s\n\n", r.Iterations, r.EndTime.Sub(r.StartTime).Round(time.Second))
+	if r.Unstable() {
+		b.WriteString(":x: Unstable\n")
+	} else {
+		b.WriteString(":white_check_mark: Stable\n")
+	}
+	fmt.Fprintf(&b, "\nStopped: %s\n", r.StopReason)
+
+	b.WriteString("\n| Suite | Runs | Passed | Failed | Timed out | Failure rate | Mean duration | Duration trend |\n|---|---|---|---|---|---|---|---|\n")
+	for _, s := range r.Suites {
+		trend := "-"
+		if s.DurationTrend != nil {
+			trend = fmt.Sprintf("%+.0f%%", s.DurationTrend.ChangePercent)
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %.0f%% | %s | %s |\n",
+			s.Suite, s.Runs, s.Passed, s.Failed, s.TimedOut, s.FailureRate()*100, s.MeanDuration.Round(time.Millisecond), trend)
+	}
+
+	var flaky, failing, degraded []string
+	for _, s := range r.Suites {
+		switch {
+		case s.Flaky():
+			flaky = append(flaky, s.Suite)
+		case s.Failed > 0:
+			failing = append(failing, s.Suite)
+		}
+		if s.Degraded {
+			degraded = append(degraded, fmt.Sprintf("%s (%+.0f%%)", s.Suite, s.DurationTrend.ChangePercent))
+		}
+	}
+	writeSuiteList(&b, "Flaky suites", flaky)
+	writeSuiteList(&b, "Failing suites", failing)
+	writeSuiteList(&b, "Degrading suites", degraded)
+
+	var rows []string
+	for _, s := range r.Suites {
+		names := make([]string, 0, len(s.MetricTrends))
+		for name := range s.MetricTrends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			trend := s.MetricTrends[name]
+			if math.Abs(trend.ChangePercent) > r.DurationThreshold {
+				rows = append(rows, fmt.Sprintf("| %s | %s | %.4g | %.4g | %+.0f%% |\n",
+					s.Suite, name, trend.First, trend.Last, trend.ChangePercent))
+			}
+		}
+	}
+	if len(rows) > 0 {
+		b.WriteString("\n**Metric trends**\n\n| Suite | Metric | First half | Second half | Change |\n|---|---|---|---|---|\n")
+		b.WriteString(strings.Join(rows, ""))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,187 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoakOptionsValidate(t *testing.T) {
+	assert.NoError(t, SoakOptions{Duration: time.Hour, Interval: time.Minute}.Validate())
+	assert.NoError(t, SoakOptions{Iterations: 3, Interval: time.Minute}.Validate())
+	assert.ErrorContains(t, SoakOptions{Duration: time.Hour}.Validate(), "interval must be positive")
+	assert.ErrorContains(t, SoakOptions{Interval: time.Minute}.Validate(), "duration or iterations must be set")
+	assert.ErrorContains(t, SoakOptions{Iterations: -1, Interval: time.Minute}.Validate(), "must not be negative")
+}
+
+func TestRunSoakIterations(t *testing.T) {
+	opts := SoakOptions{Interval: 20 * time.Millisecond, Iterations: 4, Comparison: DefaultComparisonOptions()}
+
+	var starts []time.Time
+	report := RunSoak(context.Background(), opts, func(iteration int) []*TestResult {
+		starts = append(starts, time.Now())
+		assert.Equal(t, len(starts), iteration)
+
+		// stable passes every time; flaky fails every other iteration
+		flaky := StatusPassed
+		if iteration%2 == 0 {
+			flaky = StatusFailed
+		}
+		return []*TestResult{
+			suiteResult("stable", StatusPassed, time.Minute),
+			suiteResult("flaky", flaky, time.Minute),
+		}
+	})
+
+	require.Len(t, starts, 4)
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), opts.Interval, "iteration %d started early", i+1)
+	}
+	assert.Equal(t, 4, report.Iterations)
+	assert.Equal(t, "completed 4 iterations", report.StopReason)
+
+	require.Len(t, report.Suites, 2)
+	flaky, stable := report.Suites[0], report.Suites[1]
+	assert.Equal(t, "flaky", flaky.Suite)
+	assert.True(t, flaky.Flaky())
+	assert.Equal(t, 2, flaky.Failed)
+	assert.InDelta(t, 0.5, flaky.FailureRate(), 0.001)
+	assert.False(t, stable.Flaky())
+	assert.Equal(t, 4, stable.Passed)
+	assert.True(t, report.Unstable())
+}
+
+func TestRunSoakDuration(t *testing.T) {
+	// Iterations start at 0, 50 and 100ms; the next would start after the
+	// soak duration
+	opts := SoakOptions{Duration: 125 * time.Millisecond, Interval: 50 * time.Millisecond}
+
+	report := RunSoak(context.Background(), opts, func(int) []*TestResult {
+		return []*TestResult{suiteResult("core_pipeline", StatusPassed, time.Minute)}
+	})
+
+	assert.Equal(t, 3, report.Iterations)
+	assert.Equal(t, "soak duration of 125ms ended", report.StopReason)
+	assert.False(t, report.Unstable())
+}
+
+func TestRunSoakOverrunningIteration(t *testing.T) {
+	// An iteration longer than the interval delays the next one instead of
+	// overlapping it
+	opts := SoakOptions{Interval: 10 * time.Millisecond, Iterations: 3}
+
+	var ends []time.Time
+	var overlapped bool
+	RunSoak(context.Background(), opts, func(int) []*TestResult {
+		if len(ends) > 0 && time.Now().Before(ends[len(ends)-1]) {
+			overlapped = true
+		}
+		time.Sleep(25 * time.Millisecond)
+		ends = append(ends, time.Now())
+		return nil
+	})
+
+	assert.Len(t, ends, 3)
+	assert.False(t, overlapped)
+}
+
+func TestRunSoakRespectsContext(t *testing.T) {
+	// The global timeout ends the wait for the next iteration
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	opts := SoakOptions{Duration: 2 * time.Hour, Interval: time.Hour}
+
+	start := time.Now()
+	report := RunSoak(ctx, opts, func(int) []*TestResult { return nil })
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, 1, report.Iterations)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.StopReason)
+
+	// An ended context starts no iteration
+	report = RunSoak(ctx, opts, func(int) []*TestResult {
+		t.Error("no iteration should run")
+		return nil
+	})
+	assert.Equal(t, 0, report.Iterations)
+}
+
+func TestSuiteStabilityTrends(t *testing.T) {
+	var iterations [][]*TestResult
+	for i, minutes := range []int{2, 2, 3, 3, 3} {
+		slowing := suiteResult("newrelic_integration", StatusPassed, time.Duration(minutes)*time.Minute)
+		slowing.Metrics = map[string]interface{}{
+			"collector_memory_mb": float64(100 + 50*i),
+			"rows_checked":        1000,
+			"status":              "ok",
+		}
+		steady := suiteResult("core_pipeline", StatusPassed, time.Minute)
+		iterations = append(iterations, []*TestResult{slowing, steady})
+	}
+	// A failed run is counted but not part of the trends
+	timedOut := suiteResult("core_pipeline", StatusFailed, time.Hour)
+	timedOut.TimedOut = true
+	iterations = append(iterations, []*TestResult{timedOut})
+
+	suites := suiteStability(iterations, DefaultComparisonOptions())
+	require.Len(t, suites, 2)
+
+	steady := suites[0]
+	assert.Equal(t, "core_pipeline", steady.Suite)
+	assert.Equal(t, 6, steady.Runs)
+	assert.Equal(t, 1, steady.TimedOut)
+	assert.Equal(t, time.Minute, steady.MeanDuration)
+	require.NotNil(t, steady.DurationTrend)
+	assert.Zero(t, steady.DurationTrend.ChangePercent)
+	assert.False(t, steady.Degraded)
+
+	// The first two runs average 2 minutes, the last two 3
+	slowing := suites[1]
+	require.NotNil(t, slowing.DurationTrend)
+	assert.Equal(t, Trend{First: 120, Last: 180, ChangePercent: 50}, *slowing.DurationTrend)
+	assert.True(t, slowing.Degraded)
+	assert.Equal(t, map[string]Trend{
+		"collector_memory_mb": {First: 125, Last: 275, ChangePercent: 120},
+		"rows_checked":        {First: 1000, Last: 1000},
+	}, slowing.MetricTrends)
+}
+
+func TestSoakReportMarkdown(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	report := &SoakReport{
+		StartTime:         start,
+		EndTime:           start.Add(2 * time.Hour),
+		Iterations:        12,
+		StopReason:        "soak duration of 2h0m0s ended",
+		DurationThreshold: DefaultDurationRegressionPercent,
+		Suites: []*SuiteStability{
+			{Suite: "broken", Runs: 12, Failed: 12},
+			{Suite: "database_integration", Runs: 12, Passed: 11, Failed: 1, TimedOut: 1, MeanDuration: time.Minute},
+			{
+				Suite: "newrelic_integration", Runs: 12, Passed: 12, MeanDuration: 150 * time.Second,
+				DurationTrend: &Trend{First: 120, Last: 180, ChangePercent: 50}, Degraded: true,
+				MetricTrends: map[string]Trend{
+					"collector_memory_mb": {First: 125, Last: 275, ChangePercent: 120},
+					"rows_checked":        {First: 1000, Last: 1010, ChangePercent: 1},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "### Soak run: 12 iterations over 2h0m0s")
+	assert.Contains(t, out, ":x: Unstable")
+	assert.Contains(t, out, "| database_integration | 12 | 11 | 1 | 1 | 8% | 1m0s | - |")
+	assert.Contains(t, out, "| newrelic_integration | 12 | 12 | 0 | 0 | 0% | 2m30s | +50% |")
+	assert.Contains(t, out, "**Flaky suites** (1): database_integration")
+	assert.Contains(t, out, "**Failing suites** (1): broken")
+	assert.Contains(t, out, "**Degrading suites** (1): newrelic_integration (+50%)")
+	assert.Contains(t, out, "| newrelic_integration | collector_memory_mb | 125 | 275 | +120% |")
+	assert.NotContains(t, out, "rows_checked")
+}
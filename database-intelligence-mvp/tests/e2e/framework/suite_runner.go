@@ -0,0 +1,204 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultSuiteTimeoutMultiplier scales a suite's estimated duration into its
+// timeout when the framework configuration sets no multiplier
+const DefaultSuiteTimeoutMultiplier = 2.0
+
+// ErrSuiteTimeout is wrapped by the error of a suite attempt that ran past
+// its per-suite timeout
+var ErrSuiteTimeout = errors.New("suite timed out")
+
+// RetryPolicy is the parsed form of a suite's RetryConfig
+type RetryPolicy struct {
+	MaxRetries int
+	Delay      time.Duration
+}
+
+// SuiteRetryPolicy returns the retry policy configured for the named suite.
+// Suites without configuration are not retried.
+func (tc *TestConfig) SuiteRetryPolicy(suiteName string) (RetryPolicy, error) {
+	config, exists := tc.TestSuites[suiteName]
+	if !exists {
+		return RetryPolicy{}, nil
+	}
+
+	policy := RetryPolicy{MaxRetries: config.Retry.MaxRetries}
+	if policy.MaxRetries < 0 {
+		return RetryPolicy{}, fmt.Errorf("suite %s: max_retries must not be negative", suiteName)
+	}
+	if config.Retry.Delay != "" {
+		delay, err := time.ParseDuration(config.Retry.Delay)
+		if err != nil {
+			return RetryPolicy{}, fmt.Errorf("suite %s: invalid retry delay: %w", suiteName, err)
+		}
+		policy.Delay = delay
+	}
+	return policy, nil
+}
+
+// SuiteTimeout returns how long one attempt of the named suite may run. A
+// timeout configured for the suite takes precedence; otherwise the estimated
+// duration from metadata is scaled by Framework.SuiteTimeoutMultiplier. Zero
+// means the suite is bounded only by the overall execution timeout.
+func (tc *TestConfig) SuiteTimeout(suiteName string, metadata *SuiteMetadata) (time.Duration, error) {
+	if config, exists := tc.TestSuites[suiteName]; exists && config.Timeout != "" {
+		timeout, err := time.ParseDuration(config.Timeout)
+		if err != nil {
+			return 0, fmt.Errorf("suite %s: invalid timeout: %w", suiteName, err)
+		}
+		return timeout, nil
+	}
+	if metadata == nil || metadata.EstimatedDuration <= 0 {
+		return 0, nil
+	}
+
+	multiplier := tc.Framework.SuiteTimeoutMultiplier
+	if multiplier <= 0 {
+		multiplier = DefaultSuiteTimeoutMultiplier
+	}
+	return time.Duration(float64(metadata.EstimatedDuration) * multiplier), nil
+}
+
+// RunSuite sets up, executes and cleans up a suite, re-running it while it
+// fails up to policy.MaxRetries times. The last attempt determines the
+// result; with retries enabled every attempt is recorded in Attempts. No
+// retry is started once ctx is done.
+//
+// A positive timeout bounds each attempt. An attempt that runs past it fails
+// with ErrSuiteTimeout and sets TimedOut, even if the suite ignores the
+// cancellation of its context.
+func RunSuite(ctx context.Context, suite TestSuite, env TestEnvironment, policy RetryPolicy, timeout time.Duration) *TestResult {
+	result := &TestResult{
+		SuiteName:   suite.Name(),
+		StartTime:   time.Now(),
+		Environment: env.GetInfo(),
+		Metadata:    suite.GetMetadata(),
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		suiteResult, err := runSuiteOnce(ctx, suite, env, timeout)
+
+		result.Error = err
+		result.TimedOut = errors.Is(err, ErrSuiteTimeout)
+		result.TestCases, result.Metrics, result.Artifacts = nil, nil, nil
+		if err != nil {
+			result.Status = StatusFailed
+		} else {
+			result.Status = suiteResult.Status
+			result.TestCases = suiteResult.TestCases
+			result.Metrics = suiteResult.Metrics
+			result.Artifacts = suiteResult.Artifacts
+		}
+
+		if policy.MaxRetries > 0 {
+			result.Attempts = append(result.Attempts, &SuiteAttempt{
+				Attempt:   attempt,
+				Status:    result.Status,
+				StartTime: attemptStart,
+				EndTime:   time.Now(),
+				Error:     err,
+			})
+		}
+
+		if result.Status != StatusFailed || attempt > policy.MaxRetries {
+			break
+		}
+
+		log.Printf("Test suite %s failed (attempt %d of %d), retrying in %s",
+			suite.Name(), attempt, policy.MaxRetries+1, policy.Delay)
+		if !sleepContext(ctx, policy.Delay) {
+			log.Printf("Not retrying test suite %s: %v", suite.Name(), ctx.Err())
+			break
+		}
+	}
+
+	result.EndTime = time.Now()
+	return result
+}
+
+// runSuiteOnce performs a single setup, execute and cleanup cycle
+func runSuiteOnce(ctx context.Context, suite TestSuite, env TestEnvironment, timeout time.Duration) (*TestResult, error) {
+	if err := suite.Setup(env); err != nil {
+		return nil, fmt.Errorf("suite setup failed: %w", err)
+	}
+
+	defer func() {
+		if err := suite.Cleanup(); err != nil {
+			log.Printf("Suite cleanup failed for %s: %v", suite.Name(), err)
+		}
+	}()
+
+	suiteResult, err := executeWithTimeout(ctx, suite, env, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if suiteResult == nil {
+		return nil, fmt.Errorf("suite returned no result")
+	}
+	return suiteResult, nil
+}
+
+// executeWithTimeout runs suite.Execute under a child context that ends after
+// timeout. When the deadline passes first the suite is abandoned: its
+// goroutine is left to finish on its own so that a suite stuck outside its
+// context cannot hold up cleanup or the suites after it.
+func executeWithTimeout(ctx context.Context, suite TestSuite, env TestEnvironment, timeout time.Duration) (*TestResult, error) {
+	if timeout <= 0 {
+		return suite.Execute(ctx, env)
+	}
+
+	suiteCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *TestResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := suite.Execute(suiteCtx, env)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil && ctx.Err() == nil && errors.Is(suiteCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %s: %v", ErrSuiteTimeout, timeout, o.err)
+		}
+		return o.result, o.err
+	case <-suiteCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w after %s", ErrSuiteTimeout, timeout)
+	}
+}
+
+// sleepContext waits for d and reports false if ctx ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
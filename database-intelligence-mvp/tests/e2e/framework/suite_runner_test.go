@@ -0,0 +1,200 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySuite fails its first failures executions and passes afterwards
+type flakySuite struct {
+	failures int
+	setups   int
+	runs     int
+	cleanups int
+}
+
+func (s *flakySuite) Name() string                    { return "newrelic_verification" }
+func (s *flakySuite) Setup(env TestEnvironment) error { s.setups++; return nil }
+func (s *flakySuite) Cleanup() error                  { s.cleanups++; return nil }
+func (s *flakySuite) GetMetadata() *SuiteMetadata     { return &SuiteMetadata{} }
+
+func (s *flakySuite) Execute(ctx context.Context, env TestEnvironment) (*TestResult, error) {
+	s.runs++
+	if s.runs <= s.failures {
+		return nil, errors.New("metrics not yet in NRDB")
+	}
+	return &TestResult{
+		Status:    StatusPassed,
+		TestCases: []*TestCaseResult{{Name: "metrics_arrive", Status: StatusPassed}},
+	}, nil
+}
+
+// hungSuite sleeps for delay without watching its context, like a suite
+// stuck on a blocking call
+type hungSuite struct {
+	delay    time.Duration
+	cleanups atomic.Int32
+}
+
+func (s *hungSuite) Name() string                    { return "performance_testing" }
+func (s *hungSuite) Setup(env TestEnvironment) error { return nil }
+func (s *hungSuite) Cleanup() error                  { s.cleanups.Add(1); return nil }
+func (s *hungSuite) GetMetadata() *SuiteMetadata {
+	return &SuiteMetadata{EstimatedDuration: 10 * time.Millisecond}
+}
+
+func (s *hungSuite) Execute(ctx context.Context, env TestEnvironment) (*TestResult, error) {
+	time.Sleep(s.delay)
+	return &TestResult{Status: StatusPassed}, nil
+}
+
+// staticEnvironment is a TestEnvironment that needs no infrastructure
+type staticEnvironment struct{}
+
+func (staticEnvironment) GetInfo() *EnvironmentInfo          { return &EnvironmentInfo{Name: "static"} }
+func (staticEnvironment) GetConnectionInfo() *ConnectionInfo { return &ConnectionInfo{} }
+func (staticEnvironment) GetCollectorEndpoint() string       { return "" }
+func (staticEnvironment) GetMetricsEndpoint() string         { return "" }
+func (staticEnvironment) IsHealthy() bool                    { return true }
+func (staticEnvironment) GetTempDir() string                 { return "" }
+
+func TestRunSuiteRetriesUntilPass(t *testing.T) {
+	suite := &flakySuite{failures: 1}
+
+	result := RunSuite(context.Background(), suite, staticEnvironment{}, RetryPolicy{MaxRetries: 2, Delay: time.Millisecond}, 0)
+
+	assert.Equal(t, StatusPassed, result.Status)
+	assert.NoError(t, result.Error)
+	assert.Len(t, result.TestCases, 1)
+	require.Len(t, result.Attempts, 2)
+	assert.Equal(t, StatusFailed, result.Attempts[0].Status)
+	assert.EqualError(t, result.Attempts[0].Error, "metrics not yet in NRDB")
+	assert.Equal(t, StatusPassed, result.Attempts[1].Status)
+	assert.Equal(t, 2, suite.setups)
+	assert.Equal(t, 2, suite.cleanups, "every attempt must be cleaned up")
+
+	execution := &ExecutionResult{Results: []*TestResult{result}}
+	summary := summarize(execution)
+	assert.Equal(t, 1, summary.PassedSuites)
+	assert.Zero(t, summary.FailedSuites)
+}
+
+func TestRunSuiteExhaustsRetries(t *testing.T) {
+	suite := &flakySuite{failures: 5}
+
+	result := RunSuite(context.Background(), suite, staticEnvironment{}, RetryPolicy{MaxRetries: 2}, 0)
+
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Len(t, result.Attempts, 3)
+	assert.Equal(t, 3, suite.runs)
+}
+
+func TestRunSuiteWithoutRetries(t *testing.T) {
+	suite := &flakySuite{failures: 1}
+
+	result := RunSuite(context.Background(), suite, staticEnvironment{}, RetryPolicy{}, 0)
+
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Nil(t, result.Attempts)
+	assert.Equal(t, 1, suite.runs)
+}
+
+func TestRunSuiteStopsRetryingWhenContextEnds(t *testing.T) {
+	suite := &flakySuite{failures: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := RunSuite(ctx, suite, staticEnvironment{}, RetryPolicy{MaxRetries: 3, Delay: time.Minute}, 0)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Len(t, result.Attempts, 1)
+}
+
+func TestRunSuiteTimesOutHungSuite(t *testing.T) {
+	config := &TestConfig{Framework: FrameworkConfig{SuiteTimeoutMultiplier: 3}}
+	hung := &hungSuite{delay: 10 * time.Second}
+	next := &flakySuite{}
+
+	var results []*TestResult
+	start := time.Now()
+	for _, suite := range []TestSuite{hung, next} {
+		timeout, err := config.SuiteTimeout(suite.Name(), suite.GetMetadata())
+		require.NoError(t, err)
+		results = append(results, RunSuite(context.Background(), suite, staticEnvironment{}, RetryPolicy{}, timeout))
+	}
+
+	assert.Less(t, time.Since(start), 5*time.Second, "the hung suite must not run to completion")
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.True(t, results[0].TimedOut)
+	assert.ErrorIs(t, results[0].Error, ErrSuiteTimeout)
+	assert.ErrorContains(t, results[0].Error, "after 30ms")
+	assert.Equal(t, int32(1), hung.cleanups.Load(), "cleanup must run after a timeout")
+
+	assert.Equal(t, StatusPassed, results[1].Status, "later suites still run")
+	assert.False(t, results[1].TimedOut)
+	assert.Equal(t, 1, next.runs)
+}
+
+func TestRunSuiteTimeoutLeavesFastSuitesAlone(t *testing.T) {
+	suite := &hungSuite{delay: time.Millisecond}
+
+	result := RunSuite(context.Background(), suite, staticEnvironment{}, RetryPolicy{}, time.Minute)
+
+	assert.Equal(t, StatusPassed, result.Status)
+	assert.False(t, result.TimedOut)
+	assert.NoError(t, result.Error)
+}
+
+func TestSuiteTimeout(t *testing.T) {
+	config := &TestConfig{TestSuites: map[string]SuiteConfig{
+		"core_pipeline": {Timeout: "10m"},
+		"bad_timeout":   {Timeout: "forever"},
+	}}
+	metadata := &SuiteMetadata{EstimatedDuration: 4 * time.Minute}
+
+	timeout, err := config.SuiteTimeout("core_pipeline", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, timeout, "configured timeout wins")
+
+	timeout, err = config.SuiteTimeout("database_integration", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, 8*time.Minute, timeout, "default multiplier")
+
+	config.Framework.SuiteTimeoutMultiplier = 1.5
+	timeout, err = config.SuiteTimeout("database_integration", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Minute, timeout)
+
+	timeout, err = config.SuiteTimeout("database_integration", &SuiteMetadata{})
+	require.NoError(t, err)
+	assert.Zero(t, timeout, "no estimate means no per-suite timeout")
+
+	_, err = config.SuiteTimeout("bad_timeout", metadata)
+	assert.ErrorContains(t, err, "invalid timeout")
+}
+
+func TestSuiteRetryPolicy(t *testing.T) {
+	config := &TestConfig{TestSuites: map[string]SuiteConfig{
+		"newrelic_integration": {Retry: RetryConfig{MaxRetries: 2, Delay: "30s"}},
+		"bad_delay":            {Retry: RetryConfig{MaxRetries: 1, Delay: "soon"}},
+	}}
+
+	policy, err := config.SuiteRetryPolicy("newrelic_integration")
+	require.NoError(t, err)
+	assert.Equal(t, RetryPolicy{MaxRetries: 2, Delay: 30 * time.Second}, policy)
+
+	policy, err = config.SuiteRetryPolicy("core_pipeline")
+	require.NoError(t, err)
+	assert.Zero(t, policy)
+
+	_, err = config.SuiteRetryPolicy("bad_delay")
+	assert.ErrorContains(t, err, "invalid retry delay")
+}
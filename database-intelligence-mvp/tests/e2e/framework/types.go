@@ -1,7 +1,6 @@
 package framework
 
 import (
-	"encoding/json"
 	"time"
 )
 
@@ -33,6 +32,7 @@ type FrameworkConfig struct {
 	MaxConcurrentSuites  int           `yaml:"max_concurrent_suites" json:"max_concurrent_suites"`
 	DefaultTimeout       string        `yaml:"default_timeout" json:"default_timeout"`
 	ContinueOnError      bool          `yaml:"continue_on_error" json:"continue_on_error"`
+	SuiteTimeoutMultiplier float64     `yaml:"suite_timeout_multiplier" json:"suite_timeout_multiplier"`
 	ArtifactRetention    string        `yaml:"artifact_retention" json:"artifact_retention"`
 }
 
@@ -45,6 +45,13 @@ type EnvironmentConfig struct {
 	Resources        ResourcesConfig        `yaml:"resources" json:"resources"`
 	NetworkConfig    NetworkConfig          `yaml:"network" json:"network"`
 	Environment      map[string]string      `yaml:"environment" json:"environment"`
+	Collector        CollectorEnvConfig     `yaml:"collector" json:"collector"`
+}
+
+// CollectorEnvConfig selects the collector started by the environment
+type CollectorEnvConfig struct {
+	Image      string `yaml:"image,omitempty" json:"image,omitempty"`
+	ConfigFile string `yaml:"config_file,omitempty" json:"config_file,omitempty"`
 }
 
 // DatabasesConfig contains database connection configuration
@@ -84,6 +91,14 @@ type SuiteConfig struct {
 	Parameters       map[string]interface{} `yaml:"parameters" json:"parameters"`
 	Dependencies     []string               `yaml:"dependencies" json:"dependencies"`
 	Tags             []string               `yaml:"tags" json:"tags"`
+	Retry            RetryConfig            `yaml:"retry" json:"retry"`
+}
+
+// RetryConfig controls how often a failed suite is re-run before it is
+// reported as failed
+type RetryConfig struct {
+	MaxRetries int    `yaml:"max_retries" json:"max_retries"`
+	Delay      string `yaml:"delay" json:"delay"`
 }
 
 // ReportingConfig contains reporting configuration
@@ -171,9 +186,20 @@ type TestResult struct {
 	Artifacts   []string            `json:"artifacts"`
 	Environment *EnvironmentInfo    `json:"environment"`
 	Metadata    *SuiteMetadata      `json:"metadata"`
+	Attempts    []*SuiteAttempt     `json:"attempts,omitempty"`
+	TimedOut    bool                `json:"timed_out,omitempty"`
 	Error       error               `json:"error,omitempty"`
 }
 
+// SuiteAttempt records one run of a suite that is retried on failure
+type SuiteAttempt struct {
+	Attempt   int        `json:"attempt"`
+	Status    TestStatus `json:"status"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   time.Time  `json:"end_time"`
+	Error     error      `json:"error,omitempty"`
+}
+
 // Duration returns the test duration
 func (tr *TestResult) Duration() time.Duration {
 	return tr.EndTime.Sub(tr.StartTime)
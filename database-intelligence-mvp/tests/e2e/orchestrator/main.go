@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
@@ -28,6 +29,7 @@ type TestOrchestrator struct {
 	cancel          context.CancelFunc
 	mutex           sync.RWMutex
 	executionID     string
+	outputDir       string
 }
 
 // OrchestratorConfig contains configuration for the test orchestrator
@@ -42,11 +44,33 @@ type OrchestratorConfig struct {
 	DryRun          bool
 	ContinueOnError bool
 	Timeout         time.Duration
+	SuiteTimeoutMultiplier float64
+
+	// Soak mode: re-run the suites on a schedule and report their stability
+	Soak           time.Duration
+	SoakInterval   time.Duration
+	SoakIterations int
+
+	// Comparison mode: diff two stored results instead of running tests
+	BaselineResult    string
+	CurrentResult     string
+	DurationThreshold float64
 }
 
 func main() {
 	config := parseFlags()
 	
+	if config.BaselineResult != "" || config.CurrentResult != "" {
+		regressed, err := compareResults(config)
+		if err != nil {
+			log.Fatalf("Result comparison failed: %v", err)
+		}
+		if regressed {
+			os.Exit(1)
+		}
+		return
+	}
+	
 	orchestrator, err := NewTestOrchestrator(config)
 	if err != nil {
 		log.Fatalf("Failed to create test orchestrator: %v", err)
@@ -59,6 +83,25 @@ func main() {
 		}
 		return
 	}
+	
+	if config.Soak > 0 || config.SoakIterations > 0 {
+		report, err := orchestrator.Soak(framework.SoakOptions{
+			Duration:   config.Soak,
+			Interval:   config.SoakInterval,
+			Iterations: config.SoakIterations,
+			Comparison: framework.ComparisonOptions{
+				DurationRegressionPercent: config.DurationThreshold,
+				MinComparedDuration:       framework.DefaultMinComparedDuration,
+			},
+		})
+		if err != nil {
+			log.Fatalf("Soak run failed: %v", err)
+		}
+		if report.Unstable() {
+			os.Exit(1)
+		}
+		return
+	}
 
 	result := orchestrator.Execute()
 	
@@ -86,12 +129,45 @@ func parseFlags() *OrchestratorConfig {
 	flag.BoolVar(&config.DryRun, "dry-run", false, "Show what would be executed without running tests")
 	flag.BoolVar(&config.ContinueOnError, "continue-on-error", false, "Continue executing tests after failures")
 	flag.DurationVar(&config.Timeout, "timeout", 30*time.Minute, "Global timeout for test execution")
+	flag.Float64Var(&config.SuiteTimeoutMultiplier, "suite-timeout-multiplier", 0, "Multiple of a suite's estimated duration after which it is failed as timed out, for suites without a configured timeout (default from config, else 2)")
+	flag.StringVar(&config.BaselineResult, "baseline", "", "Baseline execution result (report.json) to compare against")
+	flag.StringVar(&config.CurrentResult, "current", "", "Current execution result (report.json) to compare with the baseline")
+	flag.Float64Var(&config.DurationThreshold, "duration-threshold", framework.DefaultDurationRegressionPercent, "Suite duration increase in percent reported as a regression")
+	flag.DurationVar(&config.Soak, "soak", 0, "Re-run the suites for this long and report their stability (soak mode)")
+	flag.DurationVar(&config.SoakInterval, "interval", framework.DefaultSoakInterval, "Time between the starts of soak iterations")
+	flag.IntVar(&config.SoakIterations, "soak-iterations", 0, "Stop the soak run after this many iterations")
 	
 	flag.Parse()
 	
 	return config
 }
 
+// compareResults prints a Markdown diff of two stored execution results and
+// reports whether the current run regressed
+func compareResults(config *OrchestratorConfig) (bool, error) {
+	if config.BaselineResult == "" || config.CurrentResult == "" {
+		return false, fmt.Errorf("both -baseline and -current are required")
+	}
+	
+	baseline, err := framework.LoadExecutionResult(config.BaselineResult)
+	if err != nil {
+		return false, err
+	}
+	current, err := framework.LoadExecutionResult(config.CurrentResult)
+	if err != nil {
+		return false, err
+	}
+	
+	opts := framework.DefaultComparisonOptions()
+	opts.DurationRegressionPercent = config.DurationThreshold
+	
+	diff := framework.CompareResults(baseline, current, opts)
+	if err := diff.WriteMarkdown(os.Stdout); err != nil {
+		return false, err
+	}
+	return diff.HasRegressions(), nil
+}
+
 // StringSlice implements flag.Value for string slices
 type StringSlice []string
 
@@ -124,6 +200,12 @@ func NewTestOrchestrator(config *OrchestratorConfig) (*TestOrchestrator, error)
 	if config.MaxConcurrency > 0 {
 		testConfig.Framework.MaxConcurrentSuites = config.MaxConcurrency
 	}
+	if config.ContinueOnError {
+		testConfig.Framework.ContinueOnError = true
+	}
+	if config.SuiteTimeoutMultiplier > 0 {
+		testConfig.Framework.SuiteTimeoutMultiplier = config.SuiteTimeoutMultiplier
+	}
 	
 	// Create environment manager
 	envManager, err := framework.NewEnvironmentManager(config.Environment, testConfig)
@@ -153,6 +235,7 @@ func NewTestOrchestrator(config *OrchestratorConfig) (*TestOrchestrator, error)
 		ctx:             ctx,
 		cancel:          cancel,
 		executionID:     executionID,
+		outputDir:       outputDir,
 	}
 	
 	// Initialize test suites
@@ -224,6 +307,11 @@ func (o *TestOrchestrator) DryRun() error {
 		fmt.Printf("     Description: %s\n", metadata.Description)
 		fmt.Printf("     Priority: %d\n", metadata.Priority)
 		fmt.Printf("     Estimated Duration: %s\n", metadata.EstimatedDuration)
+		if timeout, err := o.config.SuiteTimeout(suite.Name(), metadata); err != nil {
+			fmt.Printf("     Timeout: invalid (%v)\n", err)
+		} else if timeout > 0 {
+			fmt.Printf("     Timeout: %s\n", timeout)
+		}
 		fmt.Printf("     Tags: %v\n", metadata.Tags)
 		fmt.Printf("\n")
 	}
@@ -237,43 +325,19 @@ func (o *TestOrchestrator) Execute() *framework.ExecutionResult {
 	
 	startTime := time.Now()
 	
-	// Provision test environment
-	log.Printf("Provisioning test environment...")
-	testEnv, err := o.environment.Provision(o.ctx)
+	testEnv, err := o.provision()
+	defer o.cleanupEnvironment()
 	if err != nil {
 		return &framework.ExecutionResult{
 			Status:    framework.StatusFailed,
-			Error:     fmt.Errorf("environment provisioning failed: %w", err),
-			StartTime: startTime,
-			EndTime:   time.Now(),
-		}
-	}
-	
-	defer func() {
-		log.Printf("Cleaning up test environment...")
-		if cleanupErr := o.environment.Cleanup(); cleanupErr != nil {
-			log.Printf("Environment cleanup failed: %v", cleanupErr)
-		}
-	}()
-	
-	// Wait for environment to be ready
-	log.Printf("Waiting for environment to be ready...")
-	if err := o.environment.WaitForReady(o.ctx, 5*time.Minute); err != nil {
-		return &framework.ExecutionResult{
-			Status:    framework.StatusFailed,
-			Error:     fmt.Errorf("environment not ready: %w", err),
+			Error:     err,
 			StartTime: startTime,
 			EndTime:   time.Now(),
 		}
 	}
 	
 	// Execute test suites
-	var results []*framework.TestResult
-	if o.config.Framework.ParallelExecution {
-		results = o.executeParallel(testEnv)
-	} else {
-		results = o.executeSequential(testEnv)
-	}
+	results := o.executeSuites(testEnv)
 	
 	// Collect overall execution result
 	executionResult := &framework.ExecutionResult{
@@ -303,6 +367,90 @@ func (o *TestOrchestrator) Execute() *framework.ExecutionResult {
 	return executionResult
 }
 
+// Soak provisions the environment once and re-runs the test suites on the
+// schedule of opts, then writes the stability report to soak_report.md and
+// soak_report.json and prints it. The global timeout ends the soak run
+// early.
+func (o *TestOrchestrator) Soak(opts framework.SoakOptions) (*framework.SoakReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if deadline, ok := o.ctx.Deadline(); ok && opts.Duration > 0 && time.Now().Add(opts.Duration).After(deadline) {
+		log.Printf("The global timeout ends the soak run before -soak %s, raise -timeout to soak longer", opts.Duration)
+	}
+	
+	log.Printf("Starting soak run (ID: %s, interval: %s)", o.executionID, opts.Interval)
+	testEnv, err := o.provision()
+	defer o.cleanupEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	
+	report := framework.RunSoak(o.ctx, opts, func(iteration int) []*framework.TestResult {
+		results := o.executeSuites(testEnv)
+		failed := 0
+		for _, result := range results {
+			if result.Status == framework.StatusFailed {
+				failed++
+			}
+		}
+		log.Printf("Soak iteration %d: %d of %d suites failed", iteration, failed, len(results))
+		return results
+	})
+	log.Printf("Soak run completed after %d iterations: %s", report.Iterations, report.StopReason)
+	
+	var markdown bytes.Buffer
+	if err := report.WriteMarkdown(&markdown); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(o.outputDir, "soak_report.md"), markdown.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write soak report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(o.outputDir, "soak_report.json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write soak report: %w", err)
+	}
+	if _, err := os.Stdout.Write(markdown.Bytes()); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// provision starts the test environment and waits for it to be ready
+func (o *TestOrchestrator) provision() (framework.TestEnvironment, error) {
+	log.Printf("Provisioning test environment...")
+	testEnv, err := o.environment.Provision(o.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("environment provisioning failed: %w", err)
+	}
+	
+	log.Printf("Waiting for environment to be ready...")
+	if err := o.environment.WaitForReady(o.ctx, 5*time.Minute); err != nil {
+		return nil, fmt.Errorf("environment not ready: %w", err)
+	}
+	return testEnv, nil
+}
+
+// cleanupEnvironment tears down the test environment
+func (o *TestOrchestrator) cleanupEnvironment() {
+	log.Printf("Cleaning up test environment...")
+	if err := o.environment.Cleanup(); err != nil {
+		log.Printf("Environment cleanup failed: %v", err)
+	}
+}
+
+// executeSuites runs the test suites in parallel or one by one, as
+// configured
+func (o *TestOrchestrator) executeSuites(env framework.TestEnvironment) []*framework.TestResult {
+	if o.config.Framework.ParallelExecution {
+		return o.executeParallel(env)
+	}
+	return o.executeSequential(env)
+}
+
 // executeSequential runs test suites one by one
 func (o *TestOrchestrator) executeSequential(env framework.TestEnvironment) []*framework.TestResult {
 	var results []*framework.TestResult
@@ -375,52 +523,32 @@ func (o *TestOrchestrator) executeParallel(env framework.TestEnvironment) []*fra
 	return results
 }
 
-// executeSuite runs a single test suite
+// executeSuite runs a single test suite, retrying it as configured
 func (o *TestOrchestrator) executeSuite(suite framework.TestSuite, env framework.TestEnvironment) *framework.TestResult {
-	startTime := time.Now()
-	
-	result := &framework.TestResult{
-		SuiteName:   suite.Name(),
-		StartTime:   startTime,
-		Environment: env.GetInfo(),
-		Metadata:    suite.GetMetadata(),
-	}
-	
-	// Setup suite
-	if err := suite.Setup(env); err != nil {
-		result.Status = framework.StatusFailed
-		result.Error = fmt.Errorf("suite setup failed: %w", err)
-		result.EndTime = time.Now()
-		return result
+	policy, err := o.config.SuiteRetryPolicy(suite.Name())
+	if err != nil {
+		log.Printf("Ignoring retry configuration: %v", err)
 	}
 	
-	// Ensure cleanup runs
-	defer func() {
-		if cleanupErr := suite.Cleanup(); cleanupErr != nil {
-			log.Printf("Suite cleanup failed for %s: %v", suite.Name(), cleanupErr)
-		}
-	}()
-	
-	// Execute suite
-	suiteResult, err := suite.Execute(o.ctx, env)
+	timeout, err := o.config.SuiteTimeout(suite.Name(), suite.GetMetadata())
 	if err != nil {
-		result.Status = framework.StatusFailed
-		result.Error = err
-	} else {
-		result.Status = suiteResult.Status
-		result.TestCases = suiteResult.TestCases
-		result.Metrics = suiteResult.Metrics
-		result.Artifacts = suiteResult.Artifacts
+		log.Printf("Ignoring suite timeout: %v", err)
 	}
 	
-	result.EndTime = time.Now()
+	result := framework.RunSuite(o.ctx, suite, env, policy, timeout)
 	
 	// Log result
 	status := "PASSED"
-	if result.Status == framework.StatusFailed {
+	if result.TimedOut {
+		status = fmt.Sprintf("TIMED OUT after %s", timeout)
+	} else if result.Status == framework.StatusFailed {
 		status = "FAILED"
 	}
-	log.Printf("Test suite %s: %s (Duration: %s)", suite.Name(), status, result.Duration())
+	if len(result.Attempts) > 1 {
+		log.Printf("Test suite %s: %s after %d attempts (Duration: %s)", suite.Name(), status, len(result.Attempts), result.Duration())
+	} else {
+		log.Printf("Test suite %s: %s (Duration: %s)", suite.Name(), status, result.Duration())
+	}
 	
 	return result
 }
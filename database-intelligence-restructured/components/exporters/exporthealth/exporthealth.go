@@ -0,0 +1,105 @@
+// Package exporthealth wraps exporter factories so the healthcheck extension
+// learns whether exports succeed, which exporters do not otherwise expose.
+package exporthealth
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// exportRecorder is implemented by extensions that track export health. It
+// is declared here so the exporters do not need to import the extension.
+type exportRecorder interface {
+	RecordExportSuccess(exporter string, at time.Time)
+	RecordExportFailure(exporter string, at time.Time, err error)
+}
+
+// reporter sends the outcome of one exporter's exports to the recorders
+type reporter struct {
+	id        string
+	recorders []exportRecorder
+}
+
+func newReporter(settings exporter.Settings) *reporter {
+	return &reporter{id: settings.ID.String()}
+}
+
+// start finds the host's recorders; exports only begin after Start
+func (r *reporter) start(host component.Host) {
+	r.recorders = nil
+	for _, ext := range host.GetExtensions() {
+		if recorder, ok := ext.(exportRecorder); ok {
+			r.recorders = append(r.recorders, recorder)
+		}
+	}
+}
+
+// report records the outcome of an export and returns its error
+func (r *reporter) report(err error) error {
+	at := time.Now()
+	for _, recorder := range r.recorders {
+		if err != nil {
+			recorder.RecordExportFailure(r.id, at, err)
+		} else {
+			recorder.RecordExportSuccess(r.id, at)
+		}
+	}
+	return err
+}
+
+// trackedTraces reports the exports of a traces exporter
+type trackedTraces struct {
+	exporter.Traces
+	reporter *reporter
+}
+
+// Start implements component.Component
+func (e *trackedTraces) Start(ctx context.Context, host component.Host) error {
+	e.reporter.start(host)
+	return e.Traces.Start(ctx, host)
+}
+
+// ConsumeTraces implements consumer.Traces
+func (e *trackedTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return e.reporter.report(e.Traces.ConsumeTraces(ctx, td))
+}
+
+// trackedMetrics reports the exports of a metrics exporter
+type trackedMetrics struct {
+	exporter.Metrics
+	reporter *reporter
+}
+
+// Start implements component.Component
+func (e *trackedMetrics) Start(ctx context.Context, host component.Host) error {
+	e.reporter.start(host)
+	return e.Metrics.Start(ctx, host)
+}
+
+// ConsumeMetrics implements consumer.Metrics
+func (e *trackedMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return e.reporter.report(e.Metrics.ConsumeMetrics(ctx, md))
+}
+
+// trackedLogs reports the exports of a logs exporter
+type trackedLogs struct {
+	exporter.Logs
+	reporter *reporter
+}
+
+// Start implements component.Component
+func (e *trackedLogs) Start(ctx context.Context, host component.Host) error {
+	e.reporter.start(host)
+	return e.Logs.Start(ctx, host)
+}
+
+// ConsumeLogs implements consumer.Logs
+func (e *trackedLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return e.reporter.report(e.Logs.ConsumeLogs(ctx, ld))
+}
@@ -0,0 +1,183 @@
+package exporthealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// stubExporter fails its exports while err is set
+type stubExporter struct {
+	component.ShutdownFunc
+	started bool
+	err     error
+}
+
+func (e *stubExporter) Start(context.Context, component.Host) error {
+	e.started = true
+	return nil
+}
+
+func (e *stubExporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (e *stubExporter) ConsumeMetrics(context.Context, pmetric.Metrics) error {
+	return e.err
+}
+
+func (e *stubExporter) ConsumeLogs(context.Context, plog.Logs) error {
+	return e.err
+}
+
+func newStubFactory(created *[]*stubExporter) exporter.Factory {
+	return exporter.NewFactory(
+		component.MustNewType("stubexp"),
+		func() component.Config { return &struct{}{} },
+		exporter.WithMetrics(func(context.Context, exporter.Settings, component.Config) (exporter.Metrics, error) {
+			e := &stubExporter{}
+			*created = append(*created, e)
+			return e, nil
+		}, component.StabilityLevelBeta),
+		exporter.WithLogs(func(context.Context, exporter.Settings, component.Config) (exporter.Logs, error) {
+			e := &stubExporter{}
+			*created = append(*created, e)
+			return e, nil
+		}, component.StabilityLevelBeta),
+	)
+}
+
+// recorder tracks exports the way the healthcheck extension does, failing
+// its probe after maxFailures failures in a row
+type recorder struct {
+	component.StartFunc
+	component.ShutdownFunc
+	maxFailures         int
+	lastSuccess         map[string]time.Time
+	lastError           map[string]error
+	consecutiveFailures map[string]int
+}
+
+func newRecorder(maxFailures int) *recorder {
+	return &recorder{
+		maxFailures:         maxFailures,
+		lastSuccess:         map[string]time.Time{},
+		lastError:           map[string]error{},
+		consecutiveFailures: map[string]int{},
+	}
+}
+
+func (r *recorder) RecordExportSuccess(exporter string, at time.Time) {
+	r.lastSuccess[exporter] = at
+	r.consecutiveFailures[exporter] = 0
+}
+
+func (r *recorder) RecordExportFailure(exporter string, _ time.Time, err error) {
+	r.lastError[exporter] = err
+	r.consecutiveFailures[exporter]++
+}
+
+func (r *recorder) probe(exporter string) bool {
+	return r.consecutiveFailures[exporter] < r.maxFailures
+}
+
+// nopExtension does not record exports
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// recorderHost exposes the recorder and an unrelated extension
+type recorderHost struct {
+	component.Host
+	recorder *recorder
+}
+
+func (h *recorderHost) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		component.MustNewID("healthcheck"): h.recorder,
+		component.MustNewID("zpages"):      &nopExtension{},
+	}
+}
+
+func newSettings(name string) exporter.Settings {
+	settings := exportertest.NewNopSettings()
+	settings.ID = component.MustNewIDWithName("stubexp", name)
+	return settings
+}
+
+func TestFailuresFlipProbe(t *testing.T) {
+	var created []*stubExporter
+	factory := Wrap(newStubFactory(&created))
+	rec := newRecorder(3)
+
+	exp, err := factory.CreateMetricsExporter(context.Background(), newSettings("newrelic"), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), &recorderHost{Host: componenttest.NewNopHost(), recorder: rec}))
+	assert.True(t, created[0].started, "the exporter must be started")
+
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	assert.WithinDuration(t, time.Now(), rec.lastSuccess["stubexp/newrelic"], time.Second)
+	assert.True(t, rec.probe("stubexp/newrelic"))
+
+	// The backend goes away
+	created[0].err = errors.New("rpc error: code = Unavailable")
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, created[0].err, exp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()),
+			"the exporter's error must be returned unchanged")
+	}
+	assert.Equal(t, 3, rec.consecutiveFailures["stubexp/newrelic"])
+	assert.Equal(t, created[0].err, rec.lastError["stubexp/newrelic"])
+	assert.False(t, rec.probe("stubexp/newrelic"))
+
+	// And comes back
+	created[0].err = nil
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	assert.True(t, rec.probe("stubexp/newrelic"))
+}
+
+func TestSignalsReportUnderOneID(t *testing.T) {
+	var created []*stubExporter
+	factory := Wrap(newStubFactory(&created))
+	assert.Equal(t, "stubexp", factory.Type().String())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.TracesExporterStability())
+	assert.Equal(t, component.StabilityLevelBeta, factory.LogsExporterStability())
+
+	rec := newRecorder(2)
+	host := &recorderHost{Host: componenttest.NewNopHost(), recorder: rec}
+	metrics, err := factory.CreateMetricsExporter(context.Background(), newSettings(""), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	logs, err := factory.CreateLogsExporter(context.Background(), newSettings(""), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, metrics.Start(context.Background(), host))
+	require.NoError(t, logs.Start(context.Background(), host))
+
+	for _, e := range created {
+		e.err = errors.New("queue is full")
+	}
+	assert.Error(t, metrics.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	assert.Error(t, logs.ConsumeLogs(context.Background(), plog.NewLogs()))
+	assert.False(t, rec.probe("stubexp"))
+}
+
+func TestWithoutRecorder(t *testing.T) {
+	var created []*stubExporter
+	factory := Wrap(newStubFactory(&created))
+
+	exp, err := factory.CreateLogsExporter(context.Background(), newSettings(""), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	created[0].err = errors.New("permanent error")
+	assert.EqualError(t, exp.ConsumeLogs(context.Background(), plog.NewLogs()), "permanent error")
+}
@@ -0,0 +1,45 @@
+package exporthealth
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+)
+
+// Wrap returns a factory of the same type and configuration as factory whose
+// exporters report the outcome of every export to the extensions that track
+// export health, such as healthcheck. The exporter's errors are returned
+// unchanged.
+func Wrap(factory exporter.Factory) exporter.Factory {
+	var options []exporter.FactoryOption
+	if stability := factory.TracesExporterStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, exporter.WithTraces(func(ctx context.Context, settings exporter.Settings, cfg component.Config) (exporter.Traces, error) {
+			exp, err := factory.CreateTracesExporter(ctx, settings, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return &trackedTraces{Traces: exp, reporter: newReporter(settings)}, nil
+		}, stability))
+	}
+	if stability := factory.MetricsExporterStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, exporter.WithMetrics(func(ctx context.Context, settings exporter.Settings, cfg component.Config) (exporter.Metrics, error) {
+			exp, err := factory.CreateMetricsExporter(ctx, settings, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return &trackedMetrics{Metrics: exp, reporter: newReporter(settings)}, nil
+		}, stability))
+	}
+	if stability := factory.LogsExporterStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, exporter.WithLogs(func(ctx context.Context, settings exporter.Settings, cfg component.Config) (exporter.Logs, error) {
+			exp, err := factory.CreateLogsExporter(ctx, settings, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return &trackedLogs{Logs: exp, reporter: newReporter(settings)}, nil
+		}, stability))
+	}
+
+	return exporter.NewFactory(factory.Type(), factory.CreateDefaultConfig, options...)
+}
@@ -5,12 +5,16 @@ go 1.23.0
 toolchain go1.24.3
 
 require (
+	github.com/go-sql-driver/mysql v1.9.3
+	github.com/lib/pq v1.10.9
+	github.com/stretchr/testify v1.10.0
 	go.opentelemetry.io/collector/component v0.105.0
 	go.opentelemetry.io/collector/extension v0.105.0
 	go.uber.org/zap v1.27.0
 )
 
 require (
+	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/go-viper/mapstructure/v2 v2.3.0 // indirect
 	github.com/gobwas/glob v0.2.3 // indirect
@@ -0,0 +1,227 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CircuitController is implemented by circuit breaker processors that allow
+// their circuits to be forced open or closed from /health/circuits. Like
+// StatsProvider it is an alias of an unnamed interface over standard library
+// types, so processors can register without importing this package.
+type CircuitController = interface {
+	// CircuitStates reports each circuit with at least "circuit" and "state" keys
+	CircuitStates() []map[string]any
+	// ForceCircuit forces a circuit open or closed until the returned expiry;
+	// a zero ttl uses the processor's default
+	ForceCircuit(circuit, state string, ttl time.Duration, reason string) (time.Time, error)
+	// ResetCircuit clears a manual override and reports whether there was one
+	ResetCircuit(circuit string) bool
+}
+
+// CircuitsResponse is the GET /health/circuits response
+type CircuitsResponse struct {
+	Timestamp  time.Time                   `json:"timestamp"`
+	Processors map[string][]map[string]any `json:"processors"`
+}
+
+// CircuitOverrideRequest is the POST /health/circuits body
+type CircuitOverrideRequest struct {
+	// Processor limits the override to one processor; all when empty
+	Processor string `json:"processor,omitempty"`
+	Circuit   string `json:"circuit"`
+	// State is open or closed
+	State string `json:"state"`
+	// Duration is a Go duration such as "30m"; the processor's default when empty
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// CircuitOverrideResponse reports which processors a change was applied to
+type CircuitOverrideResponse struct {
+	Circuit    string    `json:"circuit"`
+	State      string    `json:"state,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+	Processors []string  `json:"processors"`
+}
+
+// circuitRegistry holds the registered circuit controllers
+type circuitRegistry struct {
+	mu          sync.RWMutex
+	controllers map[string]CircuitController
+}
+
+func newCircuitRegistry() *circuitRegistry {
+	return &circuitRegistry{
+		controllers: make(map[string]CircuitController),
+	}
+}
+
+// RegisterCircuitController exposes a processor's circuits on /health/circuits
+func (hce *HealthCheckExtension) RegisterCircuitController(name string, controller CircuitController) {
+	hce.circuits.mu.Lock()
+	defer hce.circuits.mu.Unlock()
+
+	hce.circuits.controllers[name] = controller
+	hce.logger.Info("Registered circuit controller", zap.String("processor", name))
+}
+
+// selected returns the controllers to act on, sorted by name: the named one,
+// or all of them when name is empty
+func (cr *circuitRegistry) selected(name string) ([]string, []CircuitController, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	if name != "" {
+		controller, ok := cr.controllers[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("no circuit breaker processor named %q", name)
+		}
+		return []string{name}, []CircuitController{controller}, nil
+	}
+
+	if len(cr.controllers) == 0 {
+		return nil, nil, fmt.Errorf("no circuit breaker processor is registered")
+	}
+	names := make([]string, 0, len(cr.controllers))
+	for n := range cr.controllers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	controllers := make([]CircuitController, len(names))
+	for i, n := range names {
+		controllers[i] = cr.controllers[n]
+	}
+	return names, controllers, nil
+}
+
+func (hce *HealthCheckExtension) handleCircuits(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		hce.listCircuits(w)
+	case http.MethodPost, http.MethodDelete:
+		if !hce.config.CircuitControl.Enabled {
+			http.Error(w, "Circuit control is disabled, set circuit_control.enabled to allow changes", http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodPost {
+			hce.forceCircuit(w, r)
+		} else {
+			hce.resetCircuit(w, r)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (hce *HealthCheckExtension) listCircuits(w http.ResponseWriter) {
+	hce.circuits.mu.RLock()
+	response := CircuitsResponse{
+		Timestamp:  time.Now(),
+		Processors: make(map[string][]map[string]any, len(hce.circuits.controllers)),
+	}
+	for name, controller := range hce.circuits.controllers {
+		response.Processors[name] = controller.CircuitStates()
+	}
+	hce.circuits.mu.RUnlock()
+
+	writeCircuitsJSON(w, http.StatusOK, response)
+}
+
+func (hce *HealthCheckExtension) forceCircuit(w http.ResponseWriter, r *http.Request) {
+	var req CircuitOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if req.Duration != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.Duration); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid duration: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	names, controllers, err := hce.circuits.selected(req.Processor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := CircuitOverrideResponse{Circuit: req.Circuit, State: req.State}
+	for i, controller := range controllers {
+		expires, err := controller.ForceCircuit(req.Circuit, req.State, ttl, req.Reason)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Processor %s: %v", names[i], err), http.StatusBadRequest)
+			return
+		}
+		response.ExpiresAt = expires
+		response.Processors = append(response.Processors, names[i])
+	}
+
+	hce.logger.Warn("Circuit manually overridden",
+		zap.String("circuit", req.Circuit),
+		zap.String("state", req.State),
+		zap.Time("expires", response.ExpiresAt),
+		zap.Strings("processors", response.Processors),
+		zap.String("reason", req.Reason),
+		zap.String("remote_addr", r.RemoteAddr))
+
+	writeCircuitsJSON(w, http.StatusOK, response)
+}
+
+func (hce *HealthCheckExtension) resetCircuit(w http.ResponseWriter, r *http.Request) {
+	circuit := r.URL.Query().Get("circuit")
+	if circuit == "" {
+		http.Error(w, "The circuit query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	names, controllers, err := hce.circuits.selected(r.URL.Query().Get("processor"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := CircuitOverrideResponse{Circuit: circuit, Processors: []string{}}
+	for i, controller := range controllers {
+		if controller.ResetCircuit(circuit) {
+			response.Processors = append(response.Processors, names[i])
+		}
+	}
+	if len(response.Processors) == 0 {
+		http.Error(w, fmt.Sprintf("Circuit %q has no manual override", circuit), http.StatusNotFound)
+		return
+	}
+
+	hce.logger.Info("Circuit manual override cleared",
+		zap.String("circuit", circuit),
+		zap.Strings("processors", response.Processors),
+		zap.String("remote_addr", r.RemoteAddr))
+
+	writeCircuitsJSON(w, http.StatusOK, response)
+}
+
+func writeCircuitsJSON(w http.ResponseWriter, status int, v any) {
+	response, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to encode circuit states", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
@@ -0,0 +1,152 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeCircuitController keeps forced states the way a circuit breaker would
+type fakeCircuitController struct {
+	mu     sync.Mutex
+	forced map[string]string
+	ttls   map[string]time.Duration
+}
+
+func newFakeCircuitController() *fakeCircuitController {
+	return &fakeCircuitController{forced: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeCircuitController) CircuitStates() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	states := []map[string]any{}
+	for circuit, state := range f.forced {
+		states = append(states, map[string]any{"circuit": circuit, "state": state})
+	}
+	return states
+}
+
+func (f *fakeCircuitController) ForceCircuit(circuit, state string, ttl time.Duration, reason string) (time.Time, error) {
+	if state != "open" && state != "closed" {
+		return time.Time{}, errors.New("state must be open or closed")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.forced[circuit] = state
+	f.ttls[circuit] = ttl
+	return time.Now().Add(ttl), nil
+}
+
+func (f *fakeCircuitController) ResetCircuit(circuit string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.forced[circuit]
+	delete(f.forced, circuit)
+	return ok
+}
+
+func newCircuitsTestExtension(t *testing.T, enabled bool) *HealthCheckExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CircuitControl.Enabled = enabled
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	return hce
+}
+
+func doCircuitsRequest(hce *HealthCheckExtension, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	hce.handleCircuits(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestCircuitsEndpoint(t *testing.T) {
+	hce := newCircuitsTestExtension(t, true)
+	primary := newFakeCircuitController()
+	replica := newFakeCircuitController()
+	hce.RegisterCircuitController("circuit_breaker/primary", primary)
+	hce.RegisterCircuitController("circuit_breaker/replica", replica)
+
+	// Forcing without a processor applies to all of them
+	rec := doCircuitsRequest(hce, http.MethodPost, "/health/circuits",
+		`{"circuit": "orders", "state": "open", "duration": "30m", "reason": "failover"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var forced CircuitOverrideResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &forced))
+	assert.Equal(t, []string{"circuit_breaker/primary", "circuit_breaker/replica"}, forced.Processors)
+	assert.Equal(t, 30*time.Minute, primary.ttls["orders"])
+
+	// Or to the named one
+	rec = doCircuitsRequest(hce, http.MethodPost, "/health/circuits",
+		`{"processor": "circuit_breaker/replica", "circuit": "billing", "state": "closed"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, time.Duration(0), replica.ttls["billing"], "no duration uses the processor default")
+	assert.NotContains(t, primary.forced, "billing")
+
+	rec = doCircuitsRequest(hce, http.MethodGet, "/health/circuits", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var states CircuitsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &states))
+	assert.Len(t, states.Processors["circuit_breaker/primary"], 1)
+	assert.Len(t, states.Processors["circuit_breaker/replica"], 2)
+
+	rec = doCircuitsRequest(hce, http.MethodDelete, "/health/circuits?circuit=orders&processor=circuit_breaker/primary", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, primary.forced, "orders")
+	assert.Contains(t, replica.forced, "orders")
+
+	rec = doCircuitsRequest(hce, http.MethodDelete, "/health/circuits?circuit=orders&processor=circuit_breaker/primary", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCircuitsEndpoint_Errors(t *testing.T) {
+	hce := newCircuitsTestExtension(t, true)
+
+	rec := doCircuitsRequest(hce, http.MethodPost, "/health/circuits", `{"circuit": "orders", "state": "open"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "no processor registered")
+
+	hce.RegisterCircuitController("circuit_breaker", newFakeCircuitController())
+
+	for name, body := range map[string]string{
+		"malformed body":   `{"circuit":`,
+		"invalid duration": `{"circuit": "orders", "state": "open", "duration": "soon"}`,
+		"invalid state":    `{"circuit": "orders", "state": "half-open"}`,
+	} {
+		rec = doCircuitsRequest(hce, http.MethodPost, "/health/circuits", body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+	}
+
+	rec = doCircuitsRequest(hce, http.MethodPost, "/health/circuits", `{"processor": "other", "circuit": "orders", "state": "open"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doCircuitsRequest(hce, http.MethodDelete, "/health/circuits", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doCircuitsRequest(hce, http.MethodPut, "/health/circuits", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestCircuitsEndpoint_ControlDisabled(t *testing.T) {
+	hce := newCircuitsTestExtension(t, false)
+	controller := newFakeCircuitController()
+	hce.RegisterCircuitController("circuit_breaker", controller)
+
+	rec := doCircuitsRequest(hce, http.MethodPost, "/health/circuits", `{"circuit": "orders", "state": "open"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, controller.forced)
+
+	rec = doCircuitsRequest(hce, http.MethodGet, "/health/circuits", "")
+	assert.Equal(t, http.StatusOK, rec.Code, "states can always be read")
+}
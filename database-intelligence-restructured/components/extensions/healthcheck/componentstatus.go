@@ -0,0 +1,66 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+)
+
+var _ extension.StatusWatcher = (*HealthCheckExtension)(nil)
+
+// ComponentStatus is the error status a component last reported
+type ComponentStatus struct {
+	Kind   string    `json:"kind"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// ComponentStatusChanged implements extension.StatusWatcher. A component
+// reporting an error, such as a receiver backing off while its database is
+// down, degrades the health status until it reports a non-error status
+// again.
+func (hce *HealthCheckExtension) ComponentStatusChanged(source *component.InstanceID, event *component.StatusEvent) {
+	if source == nil || event == nil {
+		return
+	}
+	id := source.ID.String()
+
+	hce.healthStatus.mu.Lock()
+	defer hce.healthStatus.mu.Unlock()
+
+	if !component.StatusIsError(event.Status()) {
+		if _, degraded := hce.healthStatus.DegradedComponents[id]; degraded {
+			delete(hce.healthStatus.DegradedComponents, id)
+			hce.logger.Info("Component recovered", zap.String("component", id))
+		}
+		return
+	}
+
+	status := ComponentStatus{
+		Kind:   source.Kind.String(),
+		Status: event.Status().String(),
+		Since:  event.Timestamp(),
+	}
+	if err := event.Err(); err != nil {
+		status.Error = err.Error()
+	}
+	if hce.healthStatus.DegradedComponents == nil {
+		hce.healthStatus.DegradedComponents = make(map[string]ComponentStatus)
+	}
+	if previous, degraded := hce.healthStatus.DegradedComponents[id]; degraded {
+		// Since is when the component first reported an error
+		status.Since = previous.Since
+	} else {
+		hce.logger.Warn("Component degraded",
+			zap.String("component", id),
+			zap.String("status", status.Status),
+			zap.String("error", status.Error))
+	}
+	hce.healthStatus.DegradedComponents[id] = status
+}
@@ -0,0 +1,45 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+func TestComponentStatusChanged_DegradesHealth(t *testing.T) {
+	hce, err := newHealthCheckExtension(createDefaultConfig().(*Config), zap.NewNop())
+	require.NoError(t, err)
+	hce.healthStatus.NewRelicIntegration.ExportSuccessRate = 1
+
+	source := &component.InstanceID{
+		ID:   component.MustNewIDWithName("pgslowqueries", "primary"),
+		Kind: component.KindReceiver,
+	}
+	hce.ComponentStatusChanged(source, component.NewStatusEvent(component.StatusOK))
+	hce.performHealthCheck()
+	assert.Equal(t, "healthy", hce.healthStatus.Status)
+
+	first := component.NewRecoverableErrorEvent(errors.New("connection refused"))
+	hce.ComponentStatusChanged(source, first)
+	hce.ComponentStatusChanged(source, component.NewRecoverableErrorEvent(errors.New("connection reset")))
+	hce.performHealthCheck()
+	assert.Equal(t, "degraded - component errors", hce.healthStatus.Status)
+	require.Contains(t, hce.healthStatus.DegradedComponents, "pgslowqueries/primary")
+	status := hce.healthStatus.DegradedComponents["pgslowqueries/primary"]
+	assert.Equal(t, "Receiver", status.Kind)
+	assert.Equal(t, "StatusRecoverableError", status.Status)
+	assert.Equal(t, "connection reset", status.Error)
+	assert.Equal(t, first.Timestamp(), status.Since, "since is kept from the first error")
+
+	hce.ComponentStatusChanged(source, component.NewStatusEvent(component.StatusOK))
+	hce.performHealthCheck()
+	assert.Equal(t, "healthy", hce.healthStatus.Status)
+	assert.Empty(t, hce.healthStatus.DegradedComponents)
+}
@@ -5,6 +5,7 @@ package healthcheck
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -26,6 +27,81 @@ type Config struct {
 	
 	// NewRelicValidation enables New Relic specific health checks
 	NewRelicValidation NewRelicValidationConfig `mapstructure:"newrelic_validation"`
+	
+	// Dependencies are probed by /health/ready
+	Dependencies []DependencyConfig `mapstructure:"dependencies"`
+	
+	// Readiness holds /health/ready at 503 while the collector starts up
+	Readiness ReadinessConfig `mapstructure:"readiness"`
+	
+	// Pprof serves net/http/pprof runtime profiles
+	Pprof PprofConfig `mapstructure:"pprof"`
+	
+	// CircuitControl allows circuits to be tripped and reset through /health/circuits
+	CircuitControl CircuitControlConfig `mapstructure:"circuit_control"`
+}
+
+// CircuitControlConfig configures the manual circuit controls. Forcing a
+// circuit bypasses the circuit breaker's protection, so changes are disabled
+// by default; the current states can always be read.
+type CircuitControlConfig struct {
+	// Enabled accepts POST and DELETE requests on /health/circuits
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// PprofConfig configures the runtime profiling endpoints. Profiles expose
+// stack traces and memory contents, so they are disabled by default.
+type PprofConfig struct {
+	// Enabled serves the profiles under /debug/pprof/
+	Enabled bool `mapstructure:"enabled"`
+	
+	// Endpoint serves the profiles on a separate address, e.g. one bound to
+	// localhost only. When empty they share the health check server.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// ReadinessConfig configures the startup gate of /health/ready. Receivers
+// produce nothing until their first collection interval has passed, so a
+// collector that is ready at start would receive traffic it cannot serve yet.
+type ReadinessConfig struct {
+	// Warmup keeps the collector unready for this long after start,
+	// typically one collection interval
+	Warmup time.Duration `mapstructure:"warmup"`
+	
+	// RequireFirstData keeps the collector unready until the first data has
+	// been reported by a stats provider, a receiver or an exporter
+	RequireFirstData bool `mapstructure:"require_first_data"`
+}
+
+// enabled reports whether the startup gate is configured
+func (cfg ReadinessConfig) enabled() bool {
+	return cfg.Warmup > 0 || cfg.RequireFirstData
+}
+
+// DependencyConfig configures a readiness probe for an external dependency
+type DependencyConfig struct {
+	// Name identifies the dependency in readiness responses
+	Name string `mapstructure:"name"`
+	
+	// Type is one of postgresql, mysql, tcp or export
+	Type string `mapstructure:"type"`
+	
+	// Endpoint is the DSN for postgresql/mysql, host:port for tcp,
+	// or the exporter name for export
+	Endpoint string `mapstructure:"endpoint"`
+	
+	// MaxAge is how old the last successful export may be (export only)
+	MaxAge time.Duration `mapstructure:"max_age"`
+	
+	// MaxConsecutiveFailures fails the probe once this many exports in a row
+	// have failed, without waiting for MaxAge; 0 disables it (export only)
+	MaxConsecutiveFailures int `mapstructure:"max_consecutive_failures"`
+	
+	// Critical dependencies make the collector unready when they fail
+	Critical bool `mapstructure:"critical"`
+	
+	// Timeout bounds each probe; defaultDependencyTimeout when unset
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 // PipelineCheckConfig configures pipeline health checking
@@ -100,6 +176,50 @@ func (cfg *Config) Validate() error {
 		}
 	}
 	
+	names := make(map[string]bool, len(cfg.Dependencies))
+	for _, dep := range cfg.Dependencies {
+		if dep.Name == "" {
+			return errors.New("dependency name is required")
+		}
+		if names[dep.Name] {
+			return fmt.Errorf("duplicate dependency name %q", dep.Name)
+		}
+		if dep.Name == startupProbeName && cfg.Readiness.enabled() {
+			return fmt.Errorf("dependency name %q is reserved for the readiness startup gate", dep.Name)
+		}
+		names[dep.Name] = true
+		
+		switch dep.Type {
+		case DependencyTypePostgreSQL, DependencyTypeMySQL, DependencyTypeTCP, DependencyTypeExport:
+		default:
+			return fmt.Errorf("dependency %q has unknown type %q", dep.Name, dep.Type)
+		}
+		
+		if dep.Endpoint == "" {
+			return fmt.Errorf("dependency %q requires an endpoint", dep.Name)
+		}
+		
+		if dep.Type == DependencyTypeExport && dep.MaxAge <= 0 {
+			return fmt.Errorf("export dependency %q requires a positive max_age", dep.Name)
+		}
+		
+		if dep.MaxConsecutiveFailures < 0 {
+			return fmt.Errorf("dependency %q has a negative max_consecutive_failures", dep.Name)
+		}
+		
+		if dep.Timeout < 0 {
+			return fmt.Errorf("dependency %q has a negative timeout", dep.Name)
+		}
+	}
+	
+	if cfg.Readiness.Warmup < 0 {
+		return errors.New("readiness warmup must not be negative")
+	}
+	
+	if cfg.Pprof.Endpoint != "" && cfg.Pprof.Endpoint == cfg.Endpoint {
+		return fmt.Errorf("pprof endpoint %q is the health check endpoint; leave it empty to serve the profiles on the health check server", cfg.Pprof.Endpoint)
+	}
+	
 	if cfg.NewRelicValidation.Enabled {
 		if cfg.NewRelicValidation.APIKey == "" {
 			return errors.New("New Relic API key required when validation is enabled")
@@ -128,6 +248,12 @@ func createDefaultConfig() component.Config {
 			FeedbackEndpoint:   "localhost:13134",
 			MaxFeedbackHistory: 1000,
 		},
+		Pprof: PprofConfig{
+			Enabled: false, // Exposes runtime internals
+		},
+		CircuitControl: CircuitControlConfig{
+			Enabled: false, // Bypasses circuit breaker protection
+		},
 		NewRelicValidation: NewRelicValidationConfig{
 			Enabled: false, // Requires API key
 			ValidationQueries: []ValidationQuery{
@@ -0,0 +1,294 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	// Database drivers used by the SQL dependency probes
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// Dependency probe types
+const (
+	DependencyTypePostgreSQL = "postgresql"
+	DependencyTypeMySQL      = "mysql"
+	DependencyTypeTCP        = "tcp"
+	DependencyTypeExport     = "export"
+)
+
+// defaultDependencyTimeout bounds probes of dependencies without a timeout
+const defaultDependencyTimeout = 5 * time.Second
+
+// ProbeFunc checks a single dependency and returns an error when it is unavailable
+type ProbeFunc func(ctx context.Context) error
+
+// DependencyHealth reports the outcome of a dependency probe
+type DependencyHealth struct {
+	Healthy     bool      `json:"healthy"`
+	Critical    bool      `json:"critical"`
+	Message     string    `json:"message,omitempty"`
+	LastChecked time.Time `json:"last_checked"`
+	LatencyMs   float64   `json:"latency_ms"`
+}
+
+// dependencyProbe is a registered readiness probe
+type dependencyProbe struct {
+	name     string
+	critical bool
+	timeout  time.Duration
+	check    ProbeFunc
+	closer   func() error
+}
+
+// dependencyRegistry holds the probes evaluated by /health/ready
+type dependencyRegistry struct {
+	mu     sync.RWMutex
+	probes map[string]*dependencyProbe
+
+	// exports maps exporter name to its *exportState
+	exports sync.Map
+}
+
+// exportState tracks the outcome of one exporter's exports
+type exportState struct {
+	mu                  sync.Mutex
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	lastError           string
+	consecutiveFailures int
+	exports             int64
+	failures            int64
+}
+
+// exportState returns the state of the named exporter, creating it on first use
+func (dr *dependencyRegistry) exportState(exporter string) *exportState {
+	state, _ := dr.exports.LoadOrStore(exporter, &exportState{})
+	return state.(*exportState)
+}
+
+// errorRate returns the fraction of exports that failed. The caller holds mu.
+func (s *exportState) errorRate() float64 {
+	if s.exports == 0 {
+		return 0
+	}
+	return float64(s.failures) / float64(s.exports)
+}
+
+// check fails when the last maxFailures exports failed, or no export has
+// succeeded within maxAge. A maxFailures of 0 only checks the age.
+func (s *exportState) check(exporter string, maxAge time.Duration, maxFailures int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if maxFailures > 0 && s.consecutiveFailures >= maxFailures {
+		return fmt.Errorf("last %d exports for %s failed (%.1f%% of all exports): %s",
+			s.consecutiveFailures, exporter, s.errorRate()*100, s.lastError)
+	}
+	if s.lastSuccess.IsZero() {
+		return fmt.Errorf("no successful export recorded for %s", exporter)
+	}
+	if age := time.Since(s.lastSuccess); age > maxAge {
+		return fmt.Errorf("last successful export for %s was %s ago", exporter, age.Round(time.Second))
+	}
+	return nil
+}
+
+func newDependencyRegistry() *dependencyRegistry {
+	return &dependencyRegistry{
+		probes: make(map[string]*dependencyProbe),
+	}
+}
+
+// RegisterProbe adds or replaces a dependency probe. Critical probes make the
+// collector unready when they fail; non-critical failures are only reported.
+func (hce *HealthCheckExtension) RegisterProbe(name string, critical bool, timeout time.Duration, check ProbeFunc) {
+	hce.dependencies.register(&dependencyProbe{
+		name:     name,
+		critical: critical,
+		timeout:  timeout,
+		check:    check,
+	})
+}
+
+// RecordExportSuccess records a successful export for the named exporter.
+// Export dependency probes fail when no success has been recorded within their max age.
+// A successful export also counts as first data for the startup gate.
+func (hce *HealthCheckExtension) RecordExportSuccess(exporter string, at time.Time) {
+	state := hce.dependencies.exportState(exporter)
+	state.mu.Lock()
+	state.lastSuccess = at
+	state.consecutiveFailures = 0
+	state.exports++
+	state.mu.Unlock()
+	hce.startup.observe("exporter/"+exporter, at)
+
+	hce.healthStatus.mu.Lock()
+	hce.healthStatus.NewRelicIntegration.Connected = true
+	if at.After(hce.healthStatus.NewRelicIntegration.LastSuccessfulExport) {
+		hce.healthStatus.NewRelicIntegration.LastSuccessfulExport = at
+	}
+	hce.healthStatus.mu.Unlock()
+}
+
+// RecordExportFailure records a failed export for the named exporter. Export
+// dependency probes with max_consecutive_failures fail once that many exports
+// in a row have failed.
+func (hce *HealthCheckExtension) RecordExportFailure(exporter string, at time.Time, err error) {
+	state := hce.dependencies.exportState(exporter)
+	state.mu.Lock()
+	state.lastFailure = at
+	state.lastError = err.Error()
+	state.consecutiveFailures++
+	state.exports++
+	state.failures++
+	state.mu.Unlock()
+
+	hce.healthStatus.mu.Lock()
+	hce.healthStatus.NewRelicIntegration.IntegrationErrors++
+	hce.healthStatus.mu.Unlock()
+}
+
+func (dr *dependencyRegistry) register(probe *dependencyProbe) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	if existing, ok := dr.probes[probe.name]; ok && existing.closer != nil {
+		existing.closer()
+	}
+	dr.probes[probe.name] = probe
+}
+
+// registerConfigured creates probes for the dependencies in the configuration
+func (dr *dependencyRegistry) registerConfigured(deps []DependencyConfig) error {
+	for _, dep := range deps {
+		probe, err := dr.newConfiguredProbe(dep)
+		if err != nil {
+			return fmt.Errorf("dependency %q: %w", dep.Name, err)
+		}
+		dr.register(probe)
+	}
+	return nil
+}
+
+func (dr *dependencyRegistry) newConfiguredProbe(dep DependencyConfig) (*dependencyProbe, error) {
+	probe := &dependencyProbe{
+		name:     dep.Name,
+		critical: dep.Critical,
+		timeout:  dep.Timeout,
+	}
+	if probe.timeout == 0 {
+		probe.timeout = defaultDependencyTimeout
+	}
+
+	switch dep.Type {
+	case DependencyTypePostgreSQL, DependencyTypeMySQL:
+		driver := "postgres"
+		if dep.Type == DependencyTypeMySQL {
+			driver = "mysql"
+		}
+		// sql.Open does not connect; the pool is reused across probes
+		db, err := sql.Open(driver, dep.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
+		probe.check = db.PingContext
+		probe.closer = db.Close
+
+	case DependencyTypeTCP:
+		endpoint := dep.Endpoint
+		probe.check = func(ctx context.Context) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", endpoint)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}
+
+	case DependencyTypeExport:
+		exporter, maxAge, maxFailures := dep.Endpoint, dep.MaxAge, dep.MaxConsecutiveFailures
+		probe.check = func(ctx context.Context) error {
+			return dr.exportState(exporter).check(exporter, maxAge, maxFailures)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown dependency type %q", dep.Type)
+	}
+
+	return probe, nil
+}
+
+// check runs every probe concurrently and reports whether all critical
+// dependencies are healthy
+func (dr *dependencyRegistry) check(ctx context.Context) (bool, map[string]DependencyHealth) {
+	dr.mu.RLock()
+	probes := make([]*dependencyProbe, 0, len(dr.probes))
+	for _, probe := range dr.probes {
+		probes = append(probes, probe)
+	}
+	dr.mu.RUnlock()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		ready   = true
+		results = make(map[string]DependencyHealth, len(probes))
+	)
+
+	for _, probe := range probes {
+		wg.Add(1)
+		go func(probe *dependencyProbe) {
+			defer wg.Done()
+
+			probeCtx := ctx
+			if probe.timeout > 0 {
+				var cancel context.CancelFunc
+				probeCtx, cancel = context.WithTimeout(ctx, probe.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := probe.check(probeCtx)
+			result := DependencyHealth{
+				Healthy:     err == nil,
+				Critical:    probe.critical,
+				LastChecked: start,
+				LatencyMs:   float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Message = err.Error()
+			}
+
+			mu.Lock()
+			results[probe.name] = result
+			if err != nil && probe.critical {
+				ready = false
+			}
+			mu.Unlock()
+		}(probe)
+	}
+	wg.Wait()
+
+	return ready, results
+}
+
+// close releases resources held by configured probes
+func (dr *dependencyRegistry) close() {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	for _, probe := range dr.probes {
+		if probe.closer != nil {
+			probe.closer()
+		}
+	}
+}
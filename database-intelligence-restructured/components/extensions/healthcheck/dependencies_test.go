@@ -0,0 +1,179 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func readiness(t *testing.T, hce *HealthCheckExtension) (int, map[string]DependencyHealth) {
+	rec := httptest.NewRecorder()
+	hce.handleReady(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	var body struct {
+		Dependencies map[string]DependencyHealth `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body.Dependencies
+}
+
+func TestReadiness_FailingCriticalProbe(t *testing.T) {
+	hce, err := newHealthCheckExtension(createDefaultConfig().(*Config), zap.NewNop())
+	require.NoError(t, err)
+
+	var dbErr error
+	hce.RegisterProbe("postgres", true, time.Second, func(ctx context.Context) error { return dbErr })
+
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, deps["postgres"].Healthy)
+
+	dbErr = errors.New("connection refused")
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, deps["postgres"].Healthy)
+	assert.True(t, deps["postgres"].Critical)
+	assert.Equal(t, "connection refused", deps["postgres"].Message)
+}
+
+func TestReadiness_NonCriticalProbeOnlyReported(t *testing.T) {
+	hce, err := newHealthCheckExtension(createDefaultConfig().(*Config), zap.NewNop())
+	require.NoError(t, err)
+
+	hce.RegisterProbe("mysql", false, time.Second, func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, deps["mysql"].Healthy)
+}
+
+func TestReadiness_ProbeTimeout(t *testing.T) {
+	hce, err := newHealthCheckExtension(createDefaultConfig().(*Config), zap.NewNop())
+	require.NoError(t, err)
+
+	hce.RegisterProbe("slow", true, 20*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, deps["slow"].Message, "deadline exceeded")
+}
+
+func TestReadiness_ConfiguredProbes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Dependencies = []DependencyConfig{
+		{Name: "db-port", Type: DependencyTypeTCP, Endpoint: listener.Addr().String(), Critical: true},
+		{Name: "otlp-export", Type: DependencyTypeExport, Endpoint: "otlp", MaxAge: time.Minute, Critical: true},
+	}
+	require.NoError(t, cfg.Validate())
+
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, hce.dependencies.registerConfigured(cfg.Dependencies))
+	defer hce.dependencies.close()
+
+	// No export has succeeded yet
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, deps["db-port"].Healthy)
+	assert.False(t, deps["otlp-export"].Healthy)
+
+	hce.RecordExportSuccess("otlp", time.Now())
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+
+	// A stale export makes the collector unready again
+	hce.RecordExportSuccess("otlp", time.Now().Add(-time.Hour))
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, deps["otlp-export"].Message, "last successful export")
+
+	// Closing the listener makes the TCP dependency unreachable
+	hce.RecordExportSuccess("otlp", time.Now())
+	listener.Close()
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, deps["db-port"].Healthy)
+}
+
+func TestReadiness_ExportFailures(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Dependencies = []DependencyConfig{
+		{Name: "otlp-export", Type: DependencyTypeExport, Endpoint: "otlp", MaxAge: time.Hour, MaxConsecutiveFailures: 3, Critical: true},
+	}
+	require.NoError(t, cfg.Validate())
+
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, hce.dependencies.registerConfigured(cfg.Dependencies))
+	defer hce.dependencies.close()
+
+	hce.RecordExportSuccess("otlp", time.Now())
+	exportErr := errors.New("rpc error: code = Unavailable")
+	hce.RecordExportFailure("otlp", time.Now(), exportErr)
+	hce.RecordExportFailure("otlp", time.Now(), exportErr)
+	code, _ := readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code, "two failures are below the threshold")
+
+	// The third failure in a row flips the probe although the last success is recent
+	hce.RecordExportFailure("otlp", time.Now(), exportErr)
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "last 3 exports for otlp failed (75.0% of all exports): rpc error: code = Unavailable", deps["otlp-export"].Message)
+	assert.Equal(t, int64(3), hce.healthStatus.NewRelicIntegration.IntegrationErrors)
+
+	// A success resets the run of failures
+	hce.RecordExportSuccess("otlp", time.Now())
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestConfigValidate_Dependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		dep  DependencyConfig
+	}{
+		{"missing name", DependencyConfig{Type: DependencyTypeTCP, Endpoint: "localhost:5432"}},
+		{"unknown type", DependencyConfig{Name: "x", Type: "redis", Endpoint: "localhost:6379"}},
+		{"missing endpoint", DependencyConfig{Name: "x", Type: DependencyTypePostgreSQL}},
+		{"export without max age", DependencyConfig{Name: "x", Type: DependencyTypeExport, Endpoint: "otlp"}},
+		{"negative max consecutive failures", DependencyConfig{Name: "x", Type: DependencyTypeExport, Endpoint: "otlp", MaxAge: time.Minute, MaxConsecutiveFailures: -1}},
+		{"negative timeout", DependencyConfig{Name: "x", Type: DependencyTypeTCP, Endpoint: "localhost:5432", Timeout: -time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Dependencies = []DependencyConfig{tt.dep}
+			assert.Error(t, cfg.Validate())
+		})
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Dependencies = []DependencyConfig{{Name: "pg", Type: DependencyTypePostgreSQL, Endpoint: "postgres://localhost/db"}}
+	require.NoError(t, cfg.Validate())
+	assert.Zero(t, cfg.Dependencies[0].Timeout, "Validate leaves the configuration unchanged")
+
+	probe, err := newDependencyRegistry().newConfiguredProbe(cfg.Dependencies[0])
+	require.NoError(t, err)
+	assert.Equal(t, defaultDependencyTimeout, probe.timeout)
+}
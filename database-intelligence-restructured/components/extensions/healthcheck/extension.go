@@ -20,8 +20,13 @@ type HealthCheckExtension struct {
 	config           *Config
 	logger           *zap.Logger
 	server           *http.Server
+	pprofServer      *http.Server
 	healthStatus     *HealthStatus
 	verificationAPI  *VerificationAPI
+	dependencies     *dependencyRegistry
+	stats            *statsRegistry
+	circuits         *circuitRegistry
+	startup          *startupGate
 	shutdownChan     chan struct{}
 	wg              sync.WaitGroup
 }
@@ -35,6 +40,7 @@ type HealthStatus struct {
 	DataIngestion         DataIngestionHealth        `json:"data_ingestion"`
 	NewRelicIntegration   NewRelicIntegrationHealth  `json:"newrelic_integration"`
 	DatabaseConnections   map[string]DatabaseHealth  `json:"databases"`
+	DegradedComponents    map[string]ComponentStatus `json:"degraded_components,omitempty"`
 	VerificationMetrics   VerificationMetrics        `json:"verification"`
 }
 
@@ -105,11 +111,16 @@ func newHealthCheckExtension(cfg *Config, logger *zap.Logger) (*HealthCheckExten
 		logger:       logger,
 		healthStatus: &HealthStatus{
 			Status:              "initializing",
+			LastCheck:           time.Now(),
 			DatabaseConnections: make(map[string]DatabaseHealth),
 		},
 		verificationAPI: &VerificationAPI{
 			feedbackHistory: make([]FeedbackEvent, 0, 1000),
 		},
+		dependencies: newDependencyRegistry(),
+		stats:        newStatsRegistry(),
+		circuits:     newCircuitRegistry(),
+		startup:      newStartupGate(cfg.Readiness),
 		shutdownChan: make(chan struct{}),
 	}
 	
@@ -124,16 +135,38 @@ func newHealthCheckExtension(cfg *Config, logger *zap.Logger) (*HealthCheckExten
 func (hce *HealthCheckExtension) Start(ctx context.Context, host component.Host) error {
 	hce.logger.Info("Starting health check extension")
 	
+	if err := hce.dependencies.registerConfigured(hce.config.Dependencies); err != nil {
+		return err
+	}
+	
+	if hce.config.Readiness.enabled() {
+		hce.registerStartupGate(time.Now())
+	}
+	
 	// Create HTTP server
 	mux := http.NewServeMux()
 	
 	// Register endpoints
 	mux.HandleFunc("/health", hce.handleHealth)
+	mux.HandleFunc("/health/live", hce.handleLive)
+	mux.HandleFunc("/health/ready", hce.handleReady)
 	mux.HandleFunc("/health/detailed", hce.handleDetailedHealth)
+	mux.HandleFunc("/health/stats", hce.handleStats)
+	mux.HandleFunc("/health/circuits", hce.handleCircuits)
 	mux.HandleFunc("/health/verification", hce.handleVerification)
 	mux.HandleFunc("/health/feedback", hce.handleFeedbackHistory)
 	mux.HandleFunc("/health/remediation", hce.handleRemediation)
 	
+	if hce.config.Pprof.Enabled {
+		if hce.config.Pprof.Endpoint == "" {
+			hce.logger.Warn("Serving pprof runtime profiles on the health check endpoint; restrict access to it",
+				zap.String("endpoint", hce.config.Endpoint))
+			registerPprofHandlers(mux)
+		} else {
+			hce.startPprofServer()
+		}
+	}
+	
 	hce.server = &http.Server{
 		Addr:    hce.config.Endpoint,
 		Handler: mux,
@@ -153,6 +186,10 @@ func (hce *HealthCheckExtension) Start(ctx context.Context, host component.Host)
 	hce.wg.Add(1)
 	go hce.runPeriodicHealthChecks()
 	
+	// Sample record counts for the /health/stats rates
+	hce.wg.Add(1)
+	go hce.runStatsSampler()
+	
 	// Update status
 	hce.healthStatus.mu.Lock()
 	hce.healthStatus.Status = "healthy"
@@ -174,7 +211,14 @@ func (hce *HealthCheckExtension) Shutdown(ctx context.Context) error {
 		}
 	}
 	
+	if hce.pprofServer != nil {
+		if err := hce.pprofServer.Shutdown(ctx); err != nil {
+			hce.logger.Error("Error shutting down pprof server", zap.Error(err))
+		}
+	}
+	
 	hce.wg.Wait()
+	hce.dependencies.close()
 	return nil
 }
 
@@ -194,6 +238,38 @@ func (hce *HealthCheckExtension) handleHealth(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func (hce *HealthCheckExtension) handleLive(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// handleReady returns 503 when any critical dependency probe fails,
+// including the startup gate
+func (hce *HealthCheckExtension) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready, dependencies := hce.dependencies.check(r.Context())
+	
+	httpStatus := http.StatusOK
+	status := "ready"
+	if !ready {
+		httpStatus = http.StatusServiceUnavailable
+		status = "not ready"
+	}
+	
+	response, err := json.MarshalIndent(map[string]interface{}{
+		"status":       status,
+		"timestamp":    time.Now(),
+		"dependencies": dependencies,
+	}, "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to generate readiness status", http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(response)
+}
+
 func (hce *HealthCheckExtension) handleDetailedHealth(w http.ResponseWriter, r *http.Request) {
 	hce.healthStatus.mu.RLock()
 	
@@ -326,11 +402,28 @@ func (hce *HealthCheckExtension) runPeriodicHealthChecks() {
 }
 
 func (hce *HealthCheckExtension) performHealthCheck() {
+	stats := hce.stats.collect(time.Now())
+	
 	hce.healthStatus.mu.Lock()
 	defer hce.healthStatus.mu.Unlock()
 	
 	hce.healthStatus.LastCheck = time.Now()
 	
+	// Refresh data ingestion from registered stats providers
+	if len(stats.Components) > 0 {
+		var total int64
+		var rate float64
+		for _, signal := range stats.Signals {
+			total += signal.RecordsTotal
+			rate += signal.RecordsPerSecond
+		}
+		hce.healthStatus.DataIngestion.RecordsProcessed = total
+		hce.healthStatus.DataIngestion.ProcessingRate = rate
+		if stats.LastData.After(hce.healthStatus.DataIngestion.LastDataReceived) {
+			hce.healthStatus.DataIngestion.LastDataReceived = stats.LastData
+		}
+	}
+	
 	// Determine overall health status
 	overallHealthy := true
 	
@@ -355,6 +448,12 @@ func (hce *HealthCheckExtension) performHealthCheck() {
 		hce.healthStatus.Status = "degraded - low export success rate"
 	}
 	
+	// Check components reporting errors, such as receivers backing off
+	if len(hce.healthStatus.DegradedComponents) > 0 {
+		overallHealthy = false
+		hce.healthStatus.Status = "degraded - component errors"
+	}
+	
 	// Check database health
 	for _, dbHealth := range hce.healthStatus.DatabaseConnections {
 		if dbHealth.CircuitBreakerState == "open" {
@@ -5,7 +5,6 @@ package healthcheck
 
 import (
 	"context"
-	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
@@ -26,29 +25,13 @@ func NewFactory() extension.Factory {
 	)
 }
 
-// createExtension creates a new health check extension
+// createExtension creates a new health check extension. It goes through
+// newHealthCheckExtension so the collector and the tests build the
+// extension the same way.
 func createExtension(
-	ctx context.Context,
+	_ context.Context,
 	set extension.Settings,
 	cfg component.Config,
 ) (extension.Extension, error) {
-	config := cfg.(*Config)
-
-	ext := &HealthCheckExtension{
-		config:       config,
-		logger:       set.Logger,
-		shutdownChan: make(chan struct{}),
-		healthStatus: &HealthStatus{
-			Status:              "initializing",
-			LastCheck:           time.Now(),
-			DatabaseConnections: make(map[string]DatabaseHealth),
-			CollectorHealth: ComponentHealth{
-				Healthy:   false,
-				StartTime: time.Now(),
-				Version:   "2.0.0",
-			},
-		},
-	}
-
-	return ext, nil
-}
\ No newline at end of file
+	return newHealthCheckExtension(cfg.(*Config), set.Logger)
+}
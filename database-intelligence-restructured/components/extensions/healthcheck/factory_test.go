@@ -0,0 +1,31 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+func TestFactoryCreateExtension(t *testing.T) {
+	ext, err := NewFactory().CreateExtension(context.Background(), extensiontest.NewNopSettings(), createDefaultConfig())
+	require.NoError(t, err)
+	hce := ext.(*HealthCheckExtension)
+
+	// Components register with the extension the collector builds
+	hce.RegisterCircuitController("circuit_breaker", newFakeCircuitController())
+	rec := doCircuitsRequest(hce, http.MethodGet, "/health/circuits", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "circuit_breaker")
+
+	rec = httptest.NewRecorder()
+	hce.handleVerification(rec, httptest.NewRequest(http.MethodGet, "/health/verification", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,47 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"go.uber.org/zap"
+)
+
+// pprofPathPrefix is where the runtime profiles are served
+const pprofPathPrefix = "/debug/pprof/"
+
+// registerPprofHandlers adds the net/http/pprof endpoints to mux. The index
+// handler also serves the named profiles, e.g. /debug/pprof/goroutine and
+// /debug/pprof/heap.
+func registerPprofHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(pprofPathPrefix, pprof.Index)
+	mux.HandleFunc(pprofPathPrefix+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(pprofPathPrefix+"profile", pprof.Profile)
+	mux.HandleFunc(pprofPathPrefix+"symbol", pprof.Symbol)
+	mux.HandleFunc(pprofPathPrefix+"trace", pprof.Trace)
+}
+
+// startPprofServer serves the profiles on their own endpoint
+func (hce *HealthCheckExtension) startPprofServer() {
+	mux := http.NewServeMux()
+	registerPprofHandlers(mux)
+
+	hce.pprofServer = &http.Server{
+		Addr:    hce.config.Pprof.Endpoint,
+		Handler: mux,
+	}
+
+	hce.logger.Warn("Serving pprof runtime profiles; restrict access to this endpoint",
+		zap.String("endpoint", hce.config.Pprof.Endpoint))
+
+	hce.wg.Add(1)
+	go func() {
+		defer hce.wg.Done()
+		if err := hce.pprofServer.ListenAndServe(); err != http.ErrServerClosed {
+			hce.logger.Error("pprof server error", zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var pprofPaths = []string{
+	"/debug/pprof/",
+	"/debug/pprof/goroutine?debug=1",
+	"/debug/pprof/heap",
+	"/debug/pprof/cmdline",
+}
+
+// startForPprof starts the extension and waits for the health server
+func startForPprof(t *testing.T, cfg *Config) {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, hce.Start(context.Background(), nil))
+	t.Cleanup(func() { hce.Shutdown(context.Background()) })
+
+	require.Eventually(t, func() bool {
+		return statusCode(cfg.Endpoint, "/health/live") == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func statusCode(endpoint, path string) int {
+	resp, err := http.Get("http://" + endpoint + path)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestPprof_DisabledByDefault(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.False(t, cfg.Pprof.Enabled)
+
+	cfg.Endpoint = freeEndpoint(t)
+	startForPprof(t, cfg)
+
+	for _, path := range pprofPaths {
+		assert.Equal(t, http.StatusNotFound, statusCode(cfg.Endpoint, path), path)
+	}
+}
+
+func TestPprof_SharesHealthServer(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = freeEndpoint(t)
+	cfg.Pprof.Enabled = true
+	startForPprof(t, cfg)
+
+	for _, path := range pprofPaths {
+		assert.Equal(t, http.StatusOK, statusCode(cfg.Endpoint, path), path)
+	}
+}
+
+func TestPprof_SeparateEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = freeEndpoint(t)
+	cfg.Pprof.Enabled = true
+	cfg.Pprof.Endpoint = freeEndpoint(t)
+	startForPprof(t, cfg)
+
+	require.Eventually(t, func() bool {
+		return statusCode(cfg.Pprof.Endpoint, "/debug/pprof/") == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	for _, path := range pprofPaths {
+		assert.Equal(t, http.StatusOK, statusCode(cfg.Pprof.Endpoint, path), path)
+		assert.Equal(t, http.StatusNotFound, statusCode(cfg.Endpoint, path), path)
+	}
+}
+
+func TestPprof_SameEndpointRejected(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Pprof.Enabled = true
+	cfg.Pprof.Endpoint = cfg.Endpoint
+
+	assert.ErrorContains(t, cfg.Validate(), "is the health check endpoint")
+	assert.Equal(t, cfg.Endpoint, cfg.Pprof.Endpoint, "Validate leaves the configuration unchanged")
+}
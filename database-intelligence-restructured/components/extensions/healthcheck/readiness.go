@@ -0,0 +1,112 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startupProbeName is the dependency under which the startup gate is reported
+const startupProbeName = "startup"
+
+// startupGate keeps /health/ready failing until the warmup period has passed
+// and, if required, the first data has been seen. Once open it stays open:
+// data that stops flowing later is reported by the export dependency probes.
+type startupGate struct {
+	warmup      time.Duration
+	requireData bool
+	now         func() time.Time
+
+	mu         sync.Mutex
+	startedAt  time.Time
+	firstData  time.Time
+	dataSource string
+	open       bool
+}
+
+func newStartupGate(cfg ReadinessConfig) *startupGate {
+	return &startupGate{
+		warmup:      cfg.Warmup,
+		requireData: cfg.RequireFirstData,
+		now:         time.Now,
+	}
+}
+
+// start begins the warmup period
+func (g *startupGate) start(at time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.startedAt = at
+}
+
+// observe records data reported by source. Only the first report is kept.
+func (g *startupGate) observe(source string, at time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.firstData.IsZero() {
+		g.firstData = at
+		g.dataSource = source
+	}
+}
+
+// check returns nil once the gate is open. Stats providers are consulted for
+// data only while it is still closed.
+func (g *startupGate) check(stats *statsRegistry, logger *zap.Logger) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.open {
+		return nil
+	}
+
+	now := g.now()
+	if remaining := g.startedAt.Add(g.warmup).Sub(now); remaining > 0 {
+		return fmt.Errorf("warming up, %s remaining", remaining.Round(time.Second))
+	}
+
+	if g.requireData && g.firstData.IsZero() {
+		lastData := stats.collect(now).LastData
+		if lastData.IsZero() {
+			return errors.New("waiting for the first collection cycle to produce data")
+		}
+		g.firstData = lastData
+		g.dataSource = "stats"
+	}
+
+	g.open = true
+	fields := []zap.Field{zap.Duration("after", now.Sub(g.startedAt).Round(time.Millisecond))}
+	if g.requireData {
+		fields = append(fields, zap.String("first_data_source", g.dataSource), zap.Time("first_data", g.firstData))
+	}
+	logger.Info("Startup gate open, collector is ready", fields...)
+	return nil
+}
+
+// registerStartupGate starts the gate and adds it to the readiness probes as
+// a critical dependency, so its state is reported next to the others
+func (hce *HealthCheckExtension) registerStartupGate(startedAt time.Time) {
+	hce.startup.start(startedAt)
+	hce.RegisterProbe(startupProbeName, true, 0, func(ctx context.Context) error {
+		return hce.startup.check(hce.stats, hce.logger)
+	})
+}
+
+// RecordDataReceived records that source produced data, e.g. a receiver
+// completing a collection cycle with results. The first report opens a
+// startup gate that requires first data.
+func (hce *HealthCheckExtension) RecordDataReceived(source string, at time.Time) {
+	hce.startup.observe(source, at)
+
+	hce.healthStatus.mu.Lock()
+	if at.After(hce.healthStatus.DataIngestion.LastDataReceived) {
+		hce.healthStatus.DataIngestion.LastDataReceived = at
+	}
+	hce.healthStatus.mu.Unlock()
+}
@@ -0,0 +1,150 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newGatedExtension creates an extension whose startup gate started at
+// *now and reads the time from it
+func newGatedExtension(t *testing.T, readiness ReadinessConfig, now *time.Time) *HealthCheckExtension {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Readiness = readiness
+	require.NoError(t, cfg.Validate())
+
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	hce.startup.now = func() time.Time { return *now }
+	hce.registerStartupGate(*now)
+	return hce
+}
+
+func TestReadiness_FirstDataGate(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hce := newGatedExtension(t, ReadinessConfig{Warmup: time.Minute, RequireFirstData: true}, &now)
+
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, deps[startupProbeName].Healthy)
+	assert.True(t, deps[startupProbeName].Critical)
+	assert.Equal(t, "warming up, 1m0s remaining", deps[startupProbeName].Message)
+
+	// Data during warmup is remembered, but the warmup still applies
+	now = now.Add(30 * time.Second)
+	hce.RecordDataReceived("receiver/pgslowqueries", now)
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "warming up, 30s remaining", deps[startupProbeName].Message)
+
+	now = now.Add(30 * time.Second)
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, deps[startupProbeName].Healthy)
+}
+
+func TestReadiness_StaysUnreadyWithoutData(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hce := newGatedExtension(t, ReadinessConfig{Warmup: time.Minute, RequireFirstData: true}, &now)
+
+	// Long after the warmup, still no collection cycle has produced data
+	now = now.Add(10 * time.Minute)
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "waiting for the first collection cycle to produce data", deps[startupProbeName].Message)
+
+	hce.RecordDataReceived("receiver/ash", now)
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+
+	// The gate only covers startup and does not close again
+	now = now.Add(24 * time.Hour)
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestReadiness_FirstDataSources(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("stats provider", func(t *testing.T) {
+		hce := newGatedExtension(t, ReadinessConfig{RequireFirstData: true}, &now)
+		provider := &fakeStatsProvider{records: map[string]int64{}, lastSeen: map[string]time.Time{}}
+		hce.RegisterStatsProvider("verification", provider)
+
+		code, _ := readiness(t, hce)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+
+		provider.push("logs", 3, 0)
+		code, _ = readiness(t, hce)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		hce := newGatedExtension(t, ReadinessConfig{RequireFirstData: true}, &now)
+
+		code, _ := readiness(t, hce)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+
+		hce.RecordExportSuccess("otlp", now)
+		code, _ = readiness(t, hce)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestReadiness_GateWithDependencyProbes(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hce := newGatedExtension(t, ReadinessConfig{RequireFirstData: true}, &now)
+
+	var dbErr error
+	hce.RegisterProbe("postgres", true, time.Second, func(ctx context.Context) error { return dbErr })
+
+	// A healthy database does not make the collector ready before first data
+	code, deps := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, deps["postgres"].Healthy)
+
+	hce.RecordDataReceived("receiver/pgwaitevents", now)
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Nor does an open gate hide a failing critical dependency
+	dbErr = errors.New("connection refused")
+	code, deps = readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, deps[startupProbeName].Healthy)
+}
+
+func TestReadiness_WarmupOnly(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	hce := newGatedExtension(t, ReadinessConfig{Warmup: 90 * time.Second}, &now)
+
+	code, _ := readiness(t, hce)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	now = now.Add(90 * time.Second)
+	code, _ = readiness(t, hce)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestConfigValidate_Readiness(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.False(t, cfg.Readiness.enabled(), "the gate is opt-in")
+
+	cfg.Readiness.Warmup = -time.Second
+	assert.Error(t, cfg.Validate())
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.Readiness.RequireFirstData = true
+	cfg.Dependencies = []DependencyConfig{{Name: startupProbeName, Type: DependencyTypeTCP, Endpoint: "localhost:5432"}}
+	assert.ErrorContains(t, cfg.Validate(), "reserved")
+}
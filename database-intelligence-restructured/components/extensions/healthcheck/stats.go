@@ -0,0 +1,237 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StatsProvider is implemented by components that contribute pipeline
+// statistics to /health/stats. It is an alias of an unnamed interface using
+// only standard library types, so processors can implement it and register
+// through component.Host without importing this package.
+type StatsProvider = interface {
+	// RecordCounts returns cumulative records seen, keyed by signal (metrics, logs, traces)
+	RecordCounts() map[string]int64
+	// LastDataTimestamps returns when data was last seen, keyed by signal
+	LastDataTimestamps() map[string]time.Time
+	// DroppedRecords returns the cumulative number of records dropped
+	DroppedRecords() int64
+}
+
+// StageTimer is optionally implemented by stats providers that time the
+// stages of their per-record processing, such as the verification
+// processor's schema validation, PII scan and quality validation. Like
+// StatsProvider it is an alias of an unnamed interface over standard
+// library types.
+type StageTimer = interface {
+	// StageTimings returns the cumulative time spent in each stage, keyed
+	// by stage name
+	StageTimings() map[string]time.Duration
+}
+
+// StageStats reports the cost of one processing stage
+type StageStats struct {
+	TotalMs float64 `json:"total_ms"`
+	// PerRecordUs is the mean time per record the provider processed
+	PerRecordUs float64 `json:"per_record_us"`
+	// Share is the stage's fraction of the time across all stages
+	Share float64 `json:"share"`
+}
+
+// SignalStats aggregates throughput for one signal across all providers
+type SignalStats struct {
+	RecordsTotal     int64     `json:"records_total"`
+	RecordsPerSecond float64   `json:"records_per_second"`
+	LastSeen         time.Time `json:"last_seen,omitempty"`
+}
+
+// ComponentStats reports the statistics contributed by one provider
+type ComponentStats struct {
+	Records        map[string]int64     `json:"records"`
+	LastSeen       map[string]time.Time `json:"last_seen"`
+	RecordsDropped int64                `json:"records_dropped"`
+	// Stages is set for providers implementing StageTimer
+	Stages map[string]StageStats `json:"stages,omitempty"`
+}
+
+// PipelineStats is the /health/stats response
+type PipelineStats struct {
+	Timestamp  time.Time                 `json:"timestamp"`
+	LastData   time.Time                 `json:"last_data,omitempty"`
+	Signals    map[string]SignalStats    `json:"signals"`
+	Components map[string]ComponentStats `json:"components"`
+}
+
+// Record rates are averaged over statsRateWindow, from record counts the
+// extension samples every statsSampleInterval. Requests to /health/stats
+// only read the samples, so clients do not affect each other's rates.
+const (
+	statsRateWindow     = time.Minute
+	statsSampleInterval = 10 * time.Second
+)
+
+// countSample is a provider's record counts at one point in time
+type countSample struct {
+	at     time.Time
+	counts map[string]int64
+}
+
+// registeredProvider keeps the record counts sampled over the last
+// statsRateWindow, oldest first, starting with the counts at registration.
+// Rates cover only the window and fall to zero once data stops.
+type registeredProvider struct {
+	provider StatsProvider
+	samples  []countSample
+}
+
+// statsRegistry holds the registered stats providers
+type statsRegistry struct {
+	mu        sync.Mutex
+	providers map[string]*registeredProvider
+}
+
+func newStatsRegistry() *statsRegistry {
+	return &statsRegistry{
+		providers: make(map[string]*registeredProvider),
+	}
+}
+
+// RegisterStatsProvider adds a component's statistics to /health/stats
+func (hce *HealthCheckExtension) RegisterStatsProvider(name string, provider StatsProvider) {
+	rp := &registeredProvider{
+		provider: provider,
+		samples:  []countSample{{at: time.Now(), counts: provider.RecordCounts()}},
+	}
+
+	hce.stats.mu.Lock()
+	defer hce.stats.mu.Unlock()
+
+	hce.stats.providers[name] = rp
+	hce.logger.Info("Registered stats provider", zap.String("provider", name))
+}
+
+// sample records every provider's record counts and forgets the samples
+// that have left the rate window
+func (sr *statsRegistry) sample(now time.Time) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	cutoff := now.Add(-statsRateWindow)
+	for _, rp := range sr.providers {
+		rp.samples = append(rp.samples, countSample{at: now, counts: rp.provider.RecordCounts()})
+		for len(rp.samples) > 1 && rp.samples[0].at.Before(cutoff) {
+			rp.samples = rp.samples[1:]
+		}
+	}
+}
+
+// runStatsSampler samples the providers' record counts until shutdown
+func (hce *HealthCheckExtension) runStatsSampler() {
+	defer hce.wg.Done()
+
+	ticker := time.NewTicker(statsSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			hce.stats.sample(now)
+		case <-hce.shutdownChan:
+			return
+		}
+	}
+}
+
+// collect aggregates the statistics of all registered providers. Rates are
+// the change in record counts since the oldest sample in the rate window.
+func (sr *statsRegistry) collect(now time.Time) PipelineStats {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	stats := PipelineStats{
+		Timestamp:  now,
+		Signals:    make(map[string]SignalStats),
+		Components: make(map[string]ComponentStats, len(sr.providers)),
+	}
+
+	for name, rp := range sr.providers {
+		component := ComponentStats{
+			Records:        rp.provider.RecordCounts(),
+			LastSeen:       rp.provider.LastDataTimestamps(),
+			RecordsDropped: rp.provider.DroppedRecords(),
+		}
+		if timer, ok := rp.provider.(StageTimer); ok {
+			component.Stages = stageStats(timer.StageTimings(), component.Records)
+		}
+		stats.Components[name] = component
+
+		base := rp.samples[0]
+		elapsed := now.Sub(base.at).Seconds()
+		for signal, count := range component.Records {
+			s := stats.Signals[signal]
+			s.RecordsTotal += count
+			if delta := count - base.counts[signal]; elapsed > 0 && delta > 0 {
+				s.RecordsPerSecond += float64(delta) / elapsed
+			}
+			stats.Signals[signal] = s
+		}
+
+		for signal, seen := range component.LastSeen {
+			s := stats.Signals[signal]
+			if seen.After(s.LastSeen) {
+				s.LastSeen = seen
+			}
+			stats.Signals[signal] = s
+
+			if seen.After(stats.LastData) {
+				stats.LastData = seen
+			}
+		}
+	}
+
+	return stats
+}
+
+// stageStats converts cumulative stage timings to totals, per-record means
+// over all the provider's records, and shares of the total time
+func stageStats(timings map[string]time.Duration, records map[string]int64) map[string]StageStats {
+	var recordCount int64
+	for _, count := range records {
+		recordCount += count
+	}
+	var total time.Duration
+	for _, d := range timings {
+		total += d
+	}
+
+	stages := make(map[string]StageStats, len(timings))
+	for name, d := range timings {
+		s := StageStats{TotalMs: float64(d) / float64(time.Millisecond)}
+		if recordCount > 0 {
+			s.PerRecordUs = float64(d) / float64(time.Microsecond) / float64(recordCount)
+		}
+		if total > 0 {
+			s.Share = float64(d) / float64(total)
+		}
+		stages[name] = s
+	}
+	return stages
+}
+
+func (hce *HealthCheckExtension) handleStats(w http.ResponseWriter, r *http.Request) {
+	response, err := json.MarshalIndent(hce.stats.collect(time.Now()), "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to generate pipeline stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
@@ -0,0 +1,197 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStatsProvider counts records pushed through a pretend pipeline stage
+type fakeStatsProvider struct {
+	mu       sync.Mutex
+	records  map[string]int64
+	lastSeen map[string]time.Time
+	dropped  int64
+}
+
+func (f *fakeStatsProvider) push(signal string, count int64, dropped int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[signal] += count
+	f.lastSeen[signal] = time.Now()
+	f.dropped += dropped
+}
+
+func (f *fakeStatsProvider) RecordCounts() map[string]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]int64, len(f.records))
+	for k, v := range f.records {
+		out[k] = v
+	}
+	return out
+}
+
+func (f *fakeStatsProvider) LastDataTimestamps() map[string]time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]time.Time, len(f.lastSeen))
+	for k, v := range f.lastSeen {
+		out[k] = v
+	}
+	return out
+}
+
+func (f *fakeStatsProvider) DroppedRecords() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dropped
+}
+
+func newFakeStatsProvider() *fakeStatsProvider {
+	return &fakeStatsProvider{records: map[string]int64{}, lastSeen: map[string]time.Time{}}
+}
+
+func freeEndpoint(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = freeEndpoint(t)
+
+	hce, err := newHealthCheckExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, hce.Start(context.Background(), nil))
+	defer hce.Shutdown(context.Background())
+
+	verification := newFakeStatsProvider()
+	sampler := newFakeStatsProvider()
+	hce.RegisterStatsProvider("verification", verification)
+	hce.RegisterStatsProvider("adaptivesampler", sampler)
+
+	verification.push("logs", 120, 0)
+	sampler.push("metrics", 40, 0)
+	sampler.push("logs", 80, 30)
+
+	var stats PipelineStats
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + cfg.Endpoint + "/health/stats")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&stats) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, int64(200), stats.Signals["logs"].RecordsTotal)
+	assert.Equal(t, int64(40), stats.Signals["metrics"].RecordsTotal)
+	assert.Greater(t, stats.Signals["logs"].RecordsPerSecond, 0.0)
+	assert.False(t, stats.Signals["metrics"].LastSeen.IsZero())
+	assert.False(t, stats.LastData.IsZero())
+
+	require.Contains(t, stats.Components, "adaptivesampler")
+	assert.Equal(t, int64(30), stats.Components["adaptivesampler"].RecordsDropped)
+	assert.Equal(t, int64(120), stats.Components["verification"].Records["logs"])
+}
+
+func TestStatsCollect_RateOverWindow(t *testing.T) {
+	sr := newStatsRegistry()
+	provider := newFakeStatsProvider()
+	provider.push("logs", 1000, 0)
+	start := time.Now()
+	sr.providers["verification"] = &registeredProvider{
+		provider: provider,
+		samples:  []countSample{{at: start, counts: provider.RecordCounts()}},
+	}
+
+	provider.push("logs", 100, 0)
+	stats := sr.collect(start.Add(10 * time.Second))
+	assert.Equal(t, int64(1100), stats.Signals["logs"].RecordsTotal)
+	assert.InDelta(t, 10.0, stats.Signals["logs"].RecordsPerSecond, 1e-9, "records before registration are not counted")
+
+	// Another client asking at the same time sees the same rate
+	stats = sr.collect(start.Add(10 * time.Second))
+	assert.InDelta(t, 10.0, stats.Signals["logs"].RecordsPerSecond, 1e-9)
+
+	// The rate is averaged over the window, not since the previous request
+	stats = sr.collect(start.Add(20 * time.Second))
+	assert.InDelta(t, 5.0, stats.Signals["logs"].RecordsPerSecond, 1e-9)
+
+	// Once the samples before the data have left the window, the rate is zero
+	for at := statsSampleInterval; at <= 80*time.Second; at += statsSampleInterval {
+		sr.sample(start.Add(at))
+	}
+	stats = sr.collect(start.Add(80 * time.Second))
+	assert.Equal(t, int64(1100), stats.Signals["logs"].RecordsTotal)
+	assert.Zero(t, stats.Signals["logs"].RecordsPerSecond)
+	assert.Len(t, sr.providers["verification"].samples, 7, "samples older than the window are forgotten")
+
+	provider.push("logs", 60, 0)
+	stats = sr.collect(start.Add(80 * time.Second))
+	assert.InDelta(t, 1.0, stats.Signals["logs"].RecordsPerSecond, 1e-9)
+}
+
+func TestPerformHealthCheck_UsesStats(t *testing.T) {
+	hce, err := newHealthCheckExtension(createDefaultConfig().(*Config), zap.NewNop())
+	require.NoError(t, err)
+
+	provider := newFakeStatsProvider()
+	hce.RegisterStatsProvider("verification", provider)
+	provider.push("logs", 10, 0)
+
+	hce.performHealthCheck()
+
+	assert.Equal(t, int64(10), hce.healthStatus.DataIngestion.RecordsProcessed)
+	assert.False(t, hce.healthStatus.DataIngestion.LastDataReceived.IsZero())
+}
+
+// fakeStageTimer is a stats provider that also times its stages
+type fakeStageTimer struct {
+	*fakeStatsProvider
+	timings map[string]time.Duration
+}
+
+func (f *fakeStageTimer) StageTimings() map[string]time.Duration {
+	return f.timings
+}
+
+func TestStatsCollect_StageTimings(t *testing.T) {
+	sr := newStatsRegistry()
+	verification := &fakeStageTimer{
+		fakeStatsProvider: newFakeStatsProvider(),
+		timings: map[string]time.Duration{
+			"schema_validation":  10 * time.Millisecond,
+			"pii_scan":           30 * time.Millisecond,
+			"quality_validation": 0,
+		},
+	}
+	verification.push("logs", 1000, 0)
+	sampler := newFakeStatsProvider()
+	sampler.push("logs", 10, 0)
+	sr.providers["verification"] = &registeredProvider{provider: verification, samples: []countSample{{at: time.Now()}}}
+	sr.providers["adaptivesampler"] = &registeredProvider{provider: sampler, samples: []countSample{{at: time.Now()}}}
+
+	stats := sr.collect(time.Now())
+
+	assert.Equal(t, map[string]StageStats{
+		"schema_validation":  {TotalMs: 10, PerRecordUs: 10, Share: 0.25},
+		"pii_scan":           {TotalMs: 30, PerRecordUs: 30, Share: 0.75},
+		"quality_validation": {},
+	}, stats.Components["verification"].Stages)
+	assert.Nil(t, stats.Components["adaptivesampler"].Stages, "providers without stages report none")
+}
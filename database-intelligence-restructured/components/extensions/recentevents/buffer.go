@@ -0,0 +1,146 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package recentevents
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// minEventBytes is the smallest max_event_bytes, so a useful part of a
+	// record fits once its strings are shortened
+	minEventBytes = 1024
+
+	// shortenedStringBytes is how much of each string value is kept when a
+	// record is larger than max_event_bytes
+	shortenedStringBytes = 256
+)
+
+// Event is one record in the buffer
+type Event struct {
+	// Sequence numbers the records in the order they were recorded,
+	// starting at 1, so gaps between requests show what was evicted
+	Sequence uint64    `json:"sequence"`
+	Time     time.Time `json:"time"`
+	// Source is the component that recorded the event
+	Source string `json:"source"`
+	// Signal is logs or metrics
+	Signal string `json:"signal"`
+	// Record is the JSON encoding of the record, empty when it did not fit
+	// max_event_bytes
+	Record json.RawMessage `json:"record,omitempty"`
+	// Truncated is set when string values were shortened or the record was
+	// dropped to fit max_event_bytes
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ring is a fixed size buffer of the most recent events. Adding to a full
+// ring evicts the oldest event.
+type ring struct {
+	mu     sync.Mutex
+	events []Event
+	// start is the index of the oldest event
+	start int
+	count int
+	// recorded counts every event added, including evicted ones
+	recorded uint64
+}
+
+func newRing(capacity int) *ring {
+	return &ring{events: make([]Event, capacity)}
+}
+
+// add appends e, numbering it, and evicts the oldest event when full
+func (r *ring) add(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recorded++
+	e.Sequence = r.recorded
+	if r.count < len(r.events) {
+		r.events[(r.start+r.count)%len(r.events)] = e
+		r.count++
+		return
+	}
+	r.events[r.start] = e
+	r.start = (r.start + 1) % len(r.events)
+}
+
+// snapshot returns the newest limit events that match, oldest first, or all
+// matching events when limit is 0. It also returns how many events have been
+// recorded in total and how many of those the ring still holds.
+func (r *ring) snapshot(match func(Event) bool, limit int) ([]Event, uint64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var newest []Event
+	for i := r.count - 1; i >= 0; i-- {
+		if limit > 0 && len(newest) == limit {
+			break
+		}
+		e := r.events[(r.start+i)%len(r.events)]
+		if match(e) {
+			newest = append(newest, e)
+		}
+	}
+
+	events := make([]Event, len(newest))
+	for i, e := range newest {
+		events[len(newest)-1-i] = e
+	}
+	return events, r.recorded, r.count
+}
+
+// encodeRecord encodes record as JSON in at most maxBytes. A larger record
+// is encoded again with its string values shortened; when that is still too
+// large, it is dropped. The result reports whether anything was cut.
+func encodeRecord(record map[string]any, maxBytes int) (json.RawMessage, bool) {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		// NaN and infinite values cannot be encoded; keep why not
+		encoded, _ = json.Marshal(map[string]any{"encoding_error": err.Error()})
+		return encoded, true
+	}
+	if len(encoded) <= maxBytes {
+		return encoded, false
+	}
+
+	encoded, err = json.Marshal(shortenStrings(record, shortenedStringBytes))
+	if err != nil || len(encoded) > maxBytes {
+		return nil, true
+	}
+	return encoded, true
+}
+
+// shortenStrings returns a copy of v with every string cut to at most
+// maxBytes, on a rune boundary
+func shortenStrings(v any, maxBytes int) any {
+	switch value := v.(type) {
+	case string:
+		if len(value) <= maxBytes {
+			return value
+		}
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		return value[:cut]
+	case map[string]any:
+		shortened := make(map[string]any, len(value))
+		for k, item := range value {
+			shortened[k] = shortenStrings(item, maxBytes)
+		}
+		return shortened
+	case []any:
+		shortened := make([]any, len(value))
+		for i, item := range value {
+			shortened[i] = shortenStrings(item, maxBytes)
+		}
+		return shortened
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package recentevents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines the configuration for the recent events extension. The
+// buffer holds at most MaxEvents events of at most MaxEventBytes each, so its
+// memory is bounded by their product.
+type Config struct {
+	// Endpoint is the address the events are served on. Records can carry
+	// statements and user names even after PII redaction, so the default
+	// only listens on localhost.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Path is the HTTP path of the events
+	Path string `mapstructure:"path"`
+
+	// MaxEvents is how many of the most recent records are kept
+	MaxEvents int `mapstructure:"max_events"`
+
+	// MaxEventBytes bounds the JSON encoding of one record. Longer string
+	// values of a larger record are shortened; a record that is still too
+	// large is kept without its content.
+	MaxEventBytes int `mapstructure:"max_event_bytes"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return fmt.Errorf("path must start with /, got %q", cfg.Path)
+	}
+
+	if cfg.MaxEvents <= 0 {
+		return fmt.Errorf("max_events must be positive, got %d", cfg.MaxEvents)
+	}
+
+	if cfg.MaxEventBytes < minEventBytes {
+		return fmt.Errorf("max_event_bytes must be at least %d, got %d", minEventBytes, cfg.MaxEventBytes)
+	}
+
+	return nil
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return &Config{
+		Endpoint:      "localhost:13135",
+		Path:          "/recent-events",
+		MaxEvents:     1000,
+		MaxEventBytes: 16 * 1024,
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package recentevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// Signals a record can belong to
+const (
+	SignalLogs    = "logs"
+	SignalMetrics = "metrics"
+)
+
+// Recorder is implemented by the extension and used by the recentevents
+// processor to add records. Like the healthcheck extension's
+// CircuitController it is an alias of an unnamed interface over standard
+// library types, so processors can record without importing this package.
+type Recorder = interface {
+	// RecordEvent adds a record, such as the raw form of a log record's
+	// fields; source names the recording component
+	RecordEvent(source, signal string, record map[string]any)
+}
+
+// Response is the GET response of the events endpoint
+type Response struct {
+	// Capacity is max_events
+	Capacity int `json:"capacity"`
+	// Recorded counts every record since start
+	Recorded uint64 `json:"recorded"`
+	// Evicted counts records pushed out by newer ones
+	Evicted uint64 `json:"evicted"`
+	// Events are the matching records, oldest first
+	Events []Event `json:"events"`
+}
+
+// recentEventsExtension keeps the most recent records that flowed through
+// the recentevents processors and serves them over HTTP
+type recentEventsExtension struct {
+	config *Config
+	logger *zap.Logger
+	events *ring
+
+	server *http.Server
+	// addr is the address the server listens on, which differs from the
+	// endpoint when that has port 0
+	addr net.Addr
+	wg   sync.WaitGroup
+}
+
+var _ Recorder = (*recentEventsExtension)(nil)
+
+func newRecentEventsExtension(cfg *Config, logger *zap.Logger) *recentEventsExtension {
+	return &recentEventsExtension{
+		config: cfg,
+		logger: logger,
+		events: newRing(cfg.MaxEvents),
+	}
+}
+
+// Start implements the extension.Extension interface
+func (e *recentEventsExtension) Start(_ context.Context, _ component.Host) error {
+	listener, err := net.Listen("tcp", e.config.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", e.config.Endpoint, err)
+	}
+
+	e.addr = listener.Addr()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(e.config.Path, e.handleEvents)
+	e.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	e.logger.Info("Serving recent events",
+		zap.String("endpoint", e.addr.String()),
+		zap.String("path", e.config.Path),
+		zap.Int("max_events", e.config.MaxEvents))
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err := e.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			e.logger.Error("Recent events server error", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown implements the extension.Extension interface
+func (e *recentEventsExtension) Shutdown(ctx context.Context) error {
+	if e.server == nil {
+		return nil
+	}
+	err := e.server.Shutdown(ctx)
+	e.wg.Wait()
+	return err
+}
+
+// RecordEvent adds a record to the buffer, evicting the oldest when full.
+// The record is encoded before the buffer is locked, so concurrent pipelines
+// only contend for the append.
+func (e *recentEventsExtension) RecordEvent(source, signal string, record map[string]any) {
+	encoded, truncated := encodeRecord(record, e.config.MaxEventBytes)
+	e.events.add(Event{
+		Time:      time.Now(),
+		Source:    source,
+		Signal:    signal,
+		Record:    encoded,
+		Truncated: truncated,
+	})
+}
+
+// handleEvents serves the buffered events. The signal and source query
+// parameters filter them and limit returns only the newest ones.
+func (e *recentEventsExtension) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	signal := query.Get("signal")
+	if signal != "" && signal != SignalLogs && signal != SignalMetrics {
+		http.Error(w, fmt.Sprintf("signal must be %s or %s, got %q", SignalLogs, SignalMetrics, signal), http.StatusBadRequest)
+		return
+	}
+	source := query.Get("source")
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			http.Error(w, fmt.Sprintf("limit must be a positive integer, got %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, recorded, held := e.events.snapshot(func(event Event) bool {
+		return (signal == "" || event.Signal == signal) && (source == "" || event.Source == source)
+	}, limit)
+
+	response, err := json.MarshalIndent(Response{
+		Capacity: e.config.MaxEvents,
+		Recorded: recorded,
+		Evicted:  recorded - uint64(held),
+		Events:   events,
+	}, "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to encode recent events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
@@ -0,0 +1,207 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package recentevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.uber.org/zap"
+)
+
+func newTestExtension(t *testing.T, maxEvents int) *recentEventsExtension {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxEvents = maxEvents
+	require.NoError(t, cfg.Validate())
+	return newRecentEventsExtension(cfg, zap.NewNop())
+}
+
+func getEvents(t *testing.T, ext *recentEventsExtension, query string) Response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	ext.handleEvents(rec, httptest.NewRequest(http.MethodGet, "/recent-events"+query, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var response Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
+
+func sequences(events []Event) []uint64 {
+	var seqs []uint64
+	for _, e := range events {
+		seqs = append(seqs, e.Sequence)
+	}
+	return seqs
+}
+
+func TestRingEvictsOldest(t *testing.T) {
+	ext := newTestExtension(t, 3)
+	for i := 1; i <= 5; i++ {
+		ext.RecordEvent("recentevents/logs", SignalLogs, map[string]any{"body": fmt.Sprintf("record %d", i)})
+	}
+
+	response := getEvents(t, ext, "")
+	assert.Equal(t, 3, response.Capacity)
+	assert.Equal(t, uint64(5), response.Recorded)
+	assert.Equal(t, uint64(2), response.Evicted)
+	assert.Equal(t, []uint64{3, 4, 5}, sequences(response.Events))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(response.Events[0].Record, &record))
+	assert.Equal(t, map[string]any{"body": "record 3"}, record)
+	assert.Equal(t, "recentevents/logs", response.Events[0].Source)
+	assert.Equal(t, SignalLogs, response.Events[0].Signal)
+}
+
+func TestEventsEndpointFilters(t *testing.T) {
+	ext := newTestExtension(t, 10)
+	ext.RecordEvent("recentevents/a", SignalLogs, map[string]any{"n": 1})
+	ext.RecordEvent("recentevents/a", SignalMetrics, map[string]any{"n": 2})
+	ext.RecordEvent("recentevents/b", SignalLogs, map[string]any{"n": 3})
+	ext.RecordEvent("recentevents/a", SignalLogs, map[string]any{"n": 4})
+
+	assert.Equal(t, []uint64{1, 3, 4}, sequences(getEvents(t, ext, "?signal=logs").Events))
+	assert.Equal(t, []uint64{2}, sequences(getEvents(t, ext, "?signal=metrics").Events))
+	assert.Equal(t, []uint64{1, 2, 4}, sequences(getEvents(t, ext, "?source=recentevents/a").Events))
+	assert.Equal(t, []uint64{3, 4}, sequences(getEvents(t, ext, "?signal=logs&limit=2").Events))
+	assert.Empty(t, getEvents(t, ext, "?source=recentevents/c").Events)
+}
+
+func TestEventsEndpointRejectsBadRequests(t *testing.T) {
+	ext := newTestExtension(t, 10)
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/recent-events?signal=traces", http.StatusBadRequest},
+		{http.MethodGet, "/recent-events?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "/recent-events?limit=ten", http.StatusBadRequest},
+		{http.MethodDelete, "/recent-events", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		ext.handleEvents(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		assert.Equal(t, tt.want, rec.Code, "%s %s", tt.method, tt.target)
+	}
+}
+
+func TestRecordsAreBounded(t *testing.T) {
+	ext := newTestExtension(t, 10)
+	ext.config.MaxEventBytes = minEventBytes
+
+	// Long strings are shortened to fit
+	ext.RecordEvent("p", SignalLogs, map[string]any{"body": strings.Repeat("é", 2000), "severity": "ERROR"})
+	// Too many values to fit even when shortened
+	many := make(map[string]any)
+	for i := 0; i < 200; i++ {
+		many[fmt.Sprintf("attribute.%d", i)] = "value"
+	}
+	ext.RecordEvent("p", SignalLogs, many)
+
+	events := getEvents(t, ext, "").Events
+	require.Len(t, events, 2)
+
+	assert.True(t, events[0].Truncated)
+	assert.LessOrEqual(t, len(events[0].Record), minEventBytes)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(events[0].Record, &record))
+	assert.Equal(t, "ERROR", record["severity"])
+	assert.Equal(t, strings.Repeat("é", shortenedStringBytes/2), record["body"])
+
+	assert.True(t, events[1].Truncated)
+	assert.Empty(t, events[1].Record)
+}
+
+func TestConcurrentRecording(t *testing.T) {
+	ext := newTestExtension(t, 50)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ext.RecordEvent("p", SignalMetrics, map[string]any{"i": i})
+				if i%10 == 0 {
+					ext.events.snapshot(func(Event) bool { return true }, 5)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	response := getEvents(t, ext, "")
+	assert.Equal(t, uint64(800), response.Recorded)
+	assert.Equal(t, uint64(750), response.Evicted)
+	require.Len(t, response.Events, 50)
+	for i := 1; i < len(response.Events); i++ {
+		assert.Equal(t, response.Events[i-1].Sequence+1, response.Events[i].Sequence)
+	}
+}
+
+func TestExtensionServesEvents(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "127.0.0.1:0"
+
+	ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	recorder, ok := ext.(Recorder)
+	require.True(t, ok, "the extension must implement Recorder")
+	recorder.RecordEvent("recentevents", SignalLogs, map[string]any{"body": "hello"})
+
+	addr := ext.(*recentEventsExtension).addr.String()
+	resp, err := http.Get("http://" + addr + "/recent-events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Events, 1)
+	assert.JSONEq(t, `{"body":"hello"}`, string(response.Events[0].Record))
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(cfg *Config) {}},
+		{name: "no endpoint", modify: func(cfg *Config) { cfg.Endpoint = "" }, wantErr: "endpoint must be specified"},
+		{name: "relative path", modify: func(cfg *Config) { cfg.Path = "events" }, wantErr: `path must start with /, got "events"`},
+		{name: "no events", modify: func(cfg *Config) { cfg.MaxEvents = 0 }, wantErr: "max_events must be positive, got 0"},
+		{name: "tiny events", modify: func(cfg *Config) { cfg.MaxEventBytes = 100 }, wantErr: "max_event_bytes must be at least 1024, got 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
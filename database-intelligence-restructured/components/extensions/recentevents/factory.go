@@ -0,0 +1,36 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package recentevents
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+const (
+	// TypeStr is the type identifier for this extension
+	TypeStr   = "recentevents"
+	stability = component.StabilityLevelAlpha
+)
+
+// NewFactory creates a new factory for the recent events extension
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(TypeStr),
+		createDefaultConfig,
+		createExtension,
+		stability,
+	)
+}
+
+// createExtension creates a new recent events extension
+func createExtension(
+	_ context.Context,
+	set extension.Settings,
+	cfg component.Config,
+) (extension.Extension, error) {
+	return newRecentEventsExtension(cfg.(*Config), set.Logger), nil
+}
@@ -4,12 +4,16 @@ import (
     "go.opentelemetry.io/collector/component"
     "go.opentelemetry.io/collector/extension"
     
+    "github.com/database-intelligence/db-intel/components/extensions/healthcheck"
     "github.com/database-intelligence/db-intel/components/extensions/postgresqlquery"
+    "github.com/database-intelligence/db-intel/components/extensions/recentevents"
 )
 
 // All returns all extension factories
 func All() map[component.Type]extension.Factory {
     return map[component.Type]extension.Factory{
+        healthcheck.NewFactory().Type():     healthcheck.NewFactory(),
         postgresqlquery.NewFactory().Type(): postgresqlquery.NewFactory(),
+        recentevents.NewFactory().Type():    recentevents.NewFactory(),
     }
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+// Package dbrouting sends the queries of each metric group to the primary or
+// to a read replica, so heavy scrapes stay off the primary while statistics
+// that only the primary has, such as pg_stat_statements for the write
+// workload, are still read there.
+package dbrouting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Role is the endpoint a metric group is collected from
+type Role string
+
+const (
+	RolePrimary Role = "primary"
+	RoleReplica Role = "replica"
+)
+
+// Config routes metric groups between the primary, given by the receiver's
+// own datasource, and a read replica
+type Config struct {
+	// ReplicaDatasource is the connection string of the read replica. Without
+	// it every group is collected from the primary.
+	ReplicaDatasource string `mapstructure:"replica_datasource"`
+
+	// Default is the role of groups not listed in Groups. Empty means the
+	// replica when one is configured, the primary otherwise.
+	Default Role `mapstructure:"default"`
+
+	// Groups sets the role of single metric groups
+	Groups map[string]Role `mapstructure:"groups"`
+}
+
+// Validate checks the roles, and that nothing is routed to a replica that
+// is not configured
+func (cfg *Config) Validate() error {
+	if err := validateRole("default", cfg.Default, cfg.ReplicaDatasource); err != nil {
+		return err
+	}
+
+	groups := make([]string, 0, len(cfg.Groups))
+	for group := range cfg.Groups {
+		groups = append(groups, group)
+	}
+	// Report the same group first on every run
+	sort.Strings(groups)
+	for _, group := range groups {
+		if group == "" {
+			return errors.New("groups has an empty group name")
+		}
+		if err := validateRole("groups["+group+"]", cfg.Groups[group], cfg.ReplicaDatasource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRole checks a configured role; empty is allowed and means the
+// default
+func validateRole(field string, role Role, replicaDatasource string) error {
+	switch role {
+	case "", RolePrimary:
+		return nil
+	case RoleReplica:
+		if replicaDatasource == "" {
+			return fmt.Errorf("%s routes to the replica, but replica_datasource is not set", field)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s: unknown role %q, must be primary or replica", field, role)
+	}
+}
+
+// RoleFor returns the endpoint group is collected from
+func (cfg *Config) RoleFor(group string) Role {
+	if cfg.ReplicaDatasource == "" {
+		return RolePrimary
+	}
+	if role := cfg.Groups[group]; role != "" {
+		return role
+	}
+	if cfg.Default != "" {
+		return cfg.Default
+	}
+	return RoleReplica
+}
+
+// Router holds one connection per endpoint and hands out the connection of
+// each metric group. C is the connection type, e.g. *sql.DB.
+type Router[C io.Closer] struct {
+	config *Config
+	conns  map[Role]C
+}
+
+// Open connects to the primary and, when one is configured, the replica. The
+// primary is always connected, as receivers also use it for checks that are
+// not part of a metric group, such as feature detection.
+func Open[C io.Closer](ctx context.Context, primaryDatasource string, cfg *Config,
+	open func(ctx context.Context, datasource string) (C, error)) (*Router[C], error) {
+	r := &Router[C]{config: cfg, conns: make(map[Role]C, 2)}
+
+	primary, err := open(ctx, primaryDatasource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the primary: %w", err)
+	}
+	r.conns[RolePrimary] = primary
+
+	if cfg.ReplicaDatasource != "" {
+		replica, err := open(ctx, cfg.ReplicaDatasource)
+		if err != nil {
+			primary.Close()
+			return nil, fmt.Errorf("failed to connect to the replica: %w", err)
+		}
+		r.conns[RoleReplica] = replica
+	}
+	return r, nil
+}
+
+// Primary returns the primary's connection
+func (r *Router[C]) Primary() C {
+	return r.conns[RolePrimary]
+}
+
+// For returns the connection group is collected from
+func (r *Router[C]) For(group string) C {
+	return r.conns[r.config.RoleFor(group)]
+}
+
+// Close closes every connection
+func (r *Router[C]) Close() error {
+	var errs []error
+	for _, role := range []Role{RolePrimary, RoleReplica} {
+		if conn, ok := r.conns[role]; ok {
+			if err := conn.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", role, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
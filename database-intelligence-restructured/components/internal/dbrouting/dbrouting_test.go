@@ -0,0 +1,165 @@
+package dbrouting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubConn records the queries run on one endpoint
+type stubConn struct {
+	datasource string
+	queries    []string
+	closed     bool
+	closeErr   error
+}
+
+func (c *stubConn) Query(query string) {
+	c.queries = append(c.queries, query)
+}
+
+func (c *stubConn) Close() error {
+	c.closed = true
+	return c.closeErr
+}
+
+// stubOpener opens stub connections and keeps them by datasource
+type stubOpener struct {
+	conns map[string]*stubConn
+	fail  map[string]error
+}
+
+func (o *stubOpener) open(_ context.Context, datasource string) (*stubConn, error) {
+	if err := o.fail[datasource]; err != nil {
+		return nil, err
+	}
+	if o.conns == nil {
+		o.conns = make(map[string]*stubConn)
+	}
+	conn := &stubConn{datasource: datasource}
+	o.conns[datasource] = conn
+	return conn, nil
+}
+
+const (
+	primaryDSN = "postgres://monitor@primary:5432/postgres"
+	replicaDSN = "postgres://monitor@replica:5432/postgres"
+)
+
+func TestRouterDirectsGroupsToTheirEndpoint(t *testing.T) {
+	cfg := &Config{
+		ReplicaDatasource: replicaDSN,
+		Groups: map[string]Role{
+			"slow_queries": RolePrimary,
+			"replication":  RolePrimary,
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	opener := &stubOpener{}
+	router, err := Open(context.Background(), primaryDSN, cfg, opener.open)
+	require.NoError(t, err)
+
+	for _, group := range []string{"slow_queries", "table_stats", "replication", "active_sessions"} {
+		router.For(group).Query("SELECT " + group)
+	}
+
+	primary, replica := opener.conns[primaryDSN], opener.conns[replicaDSN]
+	assert.Equal(t, []string{"SELECT slow_queries", "SELECT replication"}, primary.queries)
+	assert.Equal(t, []string{"SELECT table_stats", "SELECT active_sessions"}, replica.queries,
+		"groups without a route go to the replica")
+	assert.Same(t, primary, router.Primary())
+
+	require.NoError(t, router.Close())
+	assert.True(t, primary.closed)
+	assert.True(t, replica.closed)
+}
+
+func TestRouterDefaultPrimary(t *testing.T) {
+	cfg := &Config{
+		ReplicaDatasource: replicaDSN,
+		Default:           RolePrimary,
+		Groups:            map[string]Role{"table_stats": RoleReplica},
+	}
+	require.NoError(t, cfg.Validate())
+
+	opener := &stubOpener{}
+	router, err := Open(context.Background(), primaryDSN, cfg, opener.open)
+	require.NoError(t, err)
+
+	assert.Equal(t, replicaDSN, router.For("table_stats").datasource)
+	assert.Equal(t, primaryDSN, router.For("slow_queries").datasource)
+	assert.Equal(t, primaryDSN, router.For("wait_events").datasource)
+}
+
+func TestRouterWithoutReplica(t *testing.T) {
+	cfg := &Config{Groups: map[string]Role{"slow_queries": RolePrimary}}
+	require.NoError(t, cfg.Validate())
+
+	opener := &stubOpener{}
+	router, err := Open(context.Background(), primaryDSN, cfg, opener.open)
+	require.NoError(t, err)
+
+	assert.Len(t, opener.conns, 1, "no replica connection is opened")
+	assert.Equal(t, primaryDSN, router.For("table_stats").datasource)
+	assert.Equal(t, RolePrimary, cfg.RoleFor("table_stats"))
+	require.NoError(t, router.Close())
+}
+
+func TestOpenFailures(t *testing.T) {
+	cfg := &Config{ReplicaDatasource: replicaDSN}
+
+	opener := &stubOpener{fail: map[string]error{primaryDSN: errors.New("connection refused")}}
+	_, err := Open(context.Background(), primaryDSN, cfg, opener.open)
+	assert.EqualError(t, err, "failed to connect to the primary: connection refused")
+
+	opener = &stubOpener{fail: map[string]error{replicaDSN: errors.New("connection refused")}}
+	_, err = Open(context.Background(), primaryDSN, cfg, opener.open)
+	assert.EqualError(t, err, "failed to connect to the replica: connection refused")
+	assert.True(t, opener.conns[primaryDSN].closed, "the primary is closed when the replica fails")
+}
+
+func TestCloseReportsEveryError(t *testing.T) {
+	opener := &stubOpener{}
+	router, err := Open(context.Background(), primaryDSN, &Config{ReplicaDatasource: replicaDSN}, opener.open)
+	require.NoError(t, err)
+	opener.conns[primaryDSN].closeErr = errors.New("bad connection")
+	opener.conns[replicaDSN].closeErr = errors.New("broken pipe")
+
+	assert.EqualError(t, router.Close(), "primary: bad connection\nreplica: broken pipe")
+	assert.True(t, opener.conns[replicaDSN].closed)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "empty", cfg: Config{}},
+		{name: "replica", cfg: Config{ReplicaDatasource: replicaDSN, Default: RoleReplica, Groups: map[string]Role{"slow_queries": RolePrimary}}},
+		{name: "unknown default", cfg: Config{Default: "standby"},
+			wantErr: `default: unknown role "standby", must be primary or replica`},
+		{name: "default replica without replica", cfg: Config{Default: RoleReplica},
+			wantErr: "default routes to the replica, but replica_datasource is not set"},
+		{name: "group replica without replica", cfg: Config{Groups: map[string]Role{"table_stats": RoleReplica}},
+			wantErr: "groups[table_stats] routes to the replica, but replica_datasource is not set"},
+		{name: "unknown group role", cfg: Config{ReplicaDatasource: replicaDSN, Groups: map[string]Role{"table_stats": "Replica"}},
+			wantErr: `groups[table_stats]: unknown role "Replica", must be primary or replica`},
+		{name: "empty group", cfg: Config{Groups: map[string]Role{"": RolePrimary}},
+			wantErr: "groups has an empty group name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
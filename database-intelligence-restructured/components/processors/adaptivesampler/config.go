@@ -27,6 +27,11 @@ type Config struct {
 	// DefaultSampleRate is used when no rules match
 	DefaultSampleRate float64 `mapstructure:"default_sample_rate"`
 
+	// Timezone is the IANA time zone, such as America/New_York, that rule
+	// schedules are read in. Empty or UTC means UTC; Local is the
+	// collector host's zone.
+	Timezone string `mapstructure:"timezone"`
+
 	// MaxRecordsPerSecond limits throughput for safety
 	MaxRecordsPerSecond int `mapstructure:"max_records_per_second"`
 
@@ -77,6 +82,59 @@ type SamplingRule struct {
 
 	// MaxPerMinute limits records matched by this rule
 	MaxPerMinute int `mapstructure:"max_per_minute,omitempty"`
+
+	// Schedule overrides SampleRate during time windows, such as a higher
+	// rate in business hours. The first window containing the current time
+	// applies; outside all of them SampleRate does.
+	Schedule []ScheduleWindow `mapstructure:"schedule"`
+
+	// Reservoir keeps a minimum number of records per query fingerprint
+	// that the sample rate would otherwise drop
+	Reservoir ReservoirConfig `mapstructure:"reservoir"`
+}
+
+// ReservoirConfig guarantees low-frequency queries are represented: records
+// dropped by the rule's sample rate are reservoir sampled per fingerprint,
+// and at the end of each window enough of them are forwarded to keep at
+// least Size records of every fingerprint seen
+type ReservoirConfig struct {
+	// Size is the minimum number of records kept per fingerprint and window.
+	// Zero disables the reservoir.
+	Size int `mapstructure:"size"`
+
+	// Window is how long records are collected before the reservoir is
+	// flushed. Defaults to one minute.
+	Window time.Duration `mapstructure:"window"`
+
+	// FingerprintAttribute identifies records of the same query shape.
+	// Defaults to db.query.fingerprint; records without it are only rate
+	// sampled.
+	FingerprintAttribute string `mapstructure:"fingerprint_attribute"`
+
+	// MaxFingerprints bounds the fingerprints tracked per window, and with
+	// Size the records held in memory. Defaults to 10000.
+	MaxFingerprints int `mapstructure:"max_fingerprints"`
+}
+
+// ScheduleWindow is a daily time window with its own sample rate
+type ScheduleWindow struct {
+	// Name of the window for debugging
+	Name string `mapstructure:"name"`
+
+	// Days the window starts on: mon, tue, wed, thu, fri, sat, sun. Every
+	// day when empty.
+	Days []string `mapstructure:"days"`
+
+	// Start is the HH:MM time the window opens, inclusive
+	Start string `mapstructure:"start"`
+
+	// End is the HH:MM time the window closes, exclusive. An end at or
+	// before start closes on the next day, so 22:00 to 06:00 is overnight
+	// and 18:00 to 00:00 runs until midnight.
+	End string `mapstructure:"end"`
+
+	// SampleRate replaces the rule's sample_rate while the window is open
+	SampleRate float64 `mapstructure:"sample_rate"`
 }
 
 // SamplingCondition defines a condition for sampling
@@ -101,6 +159,10 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("default_sample_rate must be between 0.0 and 1.0, got: %f", cfg.DefaultSampleRate)
 	}
 
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+
 	if cfg.MaxRecordsPerSecond <= 0 {
 		return fmt.Errorf("max_records_per_second must be positive, got: %d", cfg.MaxRecordsPerSecond)
 	}
@@ -141,6 +203,10 @@ func (rule *SamplingRule) Validate() error {
 		return fmt.Errorf("max_per_minute cannot be negative, got: %d", rule.MaxPerMinute)
 	}
 
+	if err := rule.Reservoir.Validate(); err != nil {
+		return fmt.Errorf("invalid reservoir: %w", err)
+	}
+
 	// Validate conditions
 	for i, condition := range rule.Conditions {
 		if err := condition.Validate(); err != nil {
@@ -148,6 +214,29 @@ func (rule *SamplingRule) Validate() error {
 		}
 	}
 
+	for i, window := range rule.Schedule {
+		if _, err := window.parse(); err != nil {
+			return fmt.Errorf("invalid schedule window %d (%s): %w", i, window.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks a reservoir configuration
+func (r *ReservoirConfig) Validate() error {
+	if r.Size < 0 {
+		return fmt.Errorf("size cannot be negative, got: %d", r.Size)
+	}
+
+	if r.Window < 0 {
+		return fmt.Errorf("window cannot be negative, got: %v", r.Window)
+	}
+
+	if r.MaxFingerprints < 0 {
+		return fmt.Errorf("max_fingerprints cannot be negative, got: %d", r.MaxFingerprints)
+	}
+
 	return nil
 }
 
@@ -240,6 +329,7 @@ func CreateDefaultConfig() component.Config {
 			},
 		},
 		DefaultSampleRate:   0.1,
+		Timezone:            "UTC",
 		MaxRecordsPerSecond: 1000,
 		EnableDebugLogging:  false,
 	}
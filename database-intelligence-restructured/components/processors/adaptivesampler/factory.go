@@ -56,7 +56,7 @@ func createLogsProcessor(
 		zap.Bool("debug_logging", processorConfig.EnableDebugLogging))
 	
 	// Create and return the processor
-	processor, err := newAdaptiveSampler(processorConfig, logger, nextConsumer)
+	processor, err := newAdaptiveSampler(processorConfig, logger, set.MeterProvider, nextConsumer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create adaptive sampler: %w", err)
 	}
@@ -12,10 +12,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
@@ -30,16 +33,30 @@ type adaptiveSampler struct {
 	logger   *zap.Logger
 	consumer consumer.Logs
 
+	// configMutex guards config, the rate limiters and the schedule, which
+	// Reconfigure replaces while the processor runs
+	configMutex sync.RWMutex
+
+	// schedule selects the sample rate of rules with a schedule
+	schedule *rateSchedule
+	// now is the wall clock schedules are evaluated against
+	now func() time.Time
+
 	// State management (in-memory only)
 	deduplicationCache *lru.Cache[string, time.Time]
 	ruleLimiters       map[string]*rateLimiter
 	globalRateLimiter  *rateLimiter // Global rate limiter for MaxRecordsPerSecond
+	reservoirs         map[string]*ruleReservoir
 	stateMutex         sync.RWMutex
 
 	// Metrics
 	sampledCount   int64
 	droppedCount   int64
 	duplicateCount int64
+	reservoirCount int64
+
+	// Per-rule evaluated and kept counts and effective sample rates
+	telemetry *samplerTelemetry
 
 	// Shutdown signal
 	shutdownChan chan struct{}
@@ -73,32 +90,16 @@ type adaptiveSamplerMetrics struct {
 
 
 // newAdaptiveSampler creates a new adaptive sampler processor
-func newAdaptiveSampler(cfg *Config, logger *zap.Logger, consumer consumer.Logs) (*adaptiveSampler, error) {
+func newAdaptiveSampler(cfg *Config, logger *zap.Logger, meterProvider metric.MeterProvider, consumer consumer.Logs) (*adaptiveSampler, error) {
 	// Create deduplication cache
 	cache, err := lru.New[string, time.Time](cfg.Deduplication.CacheSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create deduplication cache: %w", err)
 	}
 
-	// Initialize rule limiters
-	limiters := make(map[string]*rateLimiter)
-	for _, rule := range cfg.SamplingRules {
-		if rule.MaxPerMinute > 0 {
-			limiters[rule.Name] = &rateLimiter{
-				maxPerMinute: rule.MaxPerMinute,
-				windowStart:  time.Now(),
-			}
-		}
-	}
-
-	// Initialize global rate limiter
-	var globalLimiter *rateLimiter
-	if cfg.MaxRecordsPerSecond > 0 {
-		// Convert per-second to per-minute for consistency with existing rate limiter
-		globalLimiter = &rateLimiter{
-			maxPerMinute: cfg.MaxRecordsPerSecond * 60,
-			windowStart:  time.Now(),
-		}
+	schedule, err := newRateSchedule(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sampling schedule: %w", err)
 	}
 
 	processor := &adaptiveSampler{
@@ -106,14 +107,114 @@ func newAdaptiveSampler(cfg *Config, logger *zap.Logger, consumer consumer.Logs)
 		logger:             logger,
 		consumer:           consumer,
 		deduplicationCache: cache,
-		ruleLimiters:       limiters,
-		globalRateLimiter:  globalLimiter,
+		ruleLimiters:       newRuleLimiters(cfg, nil),
+		globalRateLimiter:  newGlobalRateLimiter(cfg, nil),
+		schedule:           schedule,
+		now:                time.Now,
+		reservoirs:         make(map[string]*ruleReservoir),
 		shutdownChan:       make(chan struct{}),
 	}
 
+	// Initialize per-rule reservoirs
+	for _, rule := range cfg.SamplingRules {
+		if rule.Reservoir.Size > 0 {
+			processor.reservoirs[rule.Name] = newRuleReservoir(rule.Reservoir, processor.randomIndex)
+		}
+	}
+
+	processor.telemetry, err = newSamplerTelemetry(meterProvider, cfg, processor.configuredRates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampler telemetry: %w", err)
+	}
+
 	return processor, nil
 }
 
+// configuredRates returns the sample rate each rule applies now, including
+// an open schedule window, and the default rate under defaultRuleName
+func (p *adaptiveSampler) configuredRates() map[string]float64 {
+	p.configMutex.RLock()
+	defer p.configMutex.RUnlock()
+
+	now := p.now()
+	rates := map[string]float64{defaultRuleName: p.config.DefaultSampleRate}
+	for i := range p.config.SamplingRules {
+		rule := &p.config.SamplingRules[i]
+		rates[rule.Name], _ = p.schedule.sampleRate(rule, now)
+	}
+	return rates
+}
+
+// reservoirsChanged reports whether the rules with a reservoir, or their
+// reservoir settings, differ between two configurations
+func reservoirsChanged(current, updated *Config) bool {
+	reservoirs := make(map[string]ReservoirConfig)
+	for _, rule := range current.SamplingRules {
+		if rule.Reservoir.Size > 0 {
+			reservoirs[rule.Name] = rule.Reservoir
+		}
+	}
+	for _, rule := range updated.SamplingRules {
+		if rule.Reservoir.Size <= 0 {
+			continue
+		}
+		if previous, exists := reservoirs[rule.Name]; !exists || previous != rule.Reservoir {
+			return true
+		}
+		delete(reservoirs, rule.Name)
+	}
+	return len(reservoirs) > 0
+}
+
+// newRuleLimiters creates a rate limiter for every rule with max_per_minute.
+// A rule that already had a limiter in previous keeps its current window.
+func newRuleLimiters(cfg *Config, previous map[string]*rateLimiter) map[string]*rateLimiter {
+	limiters := make(map[string]*rateLimiter)
+	for _, rule := range cfg.SamplingRules {
+		if rule.MaxPerMinute <= 0 {
+			continue
+		}
+		if limiter, exists := previous[rule.Name]; exists {
+			limiter.mutex.Lock()
+			limiter.maxPerMinute = rule.MaxPerMinute
+			limiter.mutex.Unlock()
+			limiters[rule.Name] = limiter
+			continue
+		}
+		limiters[rule.Name] = &rateLimiter{
+			maxPerMinute: rule.MaxPerMinute,
+			windowStart:  time.Now(),
+		}
+	}
+	return limiters
+}
+
+// newGlobalRateLimiter creates the limiter for max_records_per_second,
+// keeping the current window of previous when there is one
+func newGlobalRateLimiter(cfg *Config, previous *rateLimiter) *rateLimiter {
+	if cfg.MaxRecordsPerSecond <= 0 {
+		return nil
+	}
+	// Convert per-second to per-minute for consistency with existing rate limiter
+	if previous != nil {
+		previous.mutex.Lock()
+		previous.maxPerMinute = cfg.MaxRecordsPerSecond * 60
+		previous.mutex.Unlock()
+		return previous
+	}
+	return &rateLimiter{
+		maxPerMinute: cfg.MaxRecordsPerSecond * 60,
+		windowStart:  time.Now(),
+	}
+}
+
+// sortRules orders rules by priority, highest first
+func sortRules(rules []SamplingRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+}
+
 // Capabilities returns the capabilities of the processor
 func (p *adaptiveSampler) Capabilities() consumer.Capabilities {
 	return consumer.Capabilities{MutatesData: false}
@@ -127,10 +228,62 @@ func (p *adaptiveSampler) Start(ctx context.Context, host component.Host) error
 	p.wg.Add(1)
 	go p.periodicCleanup()
 
+	for name, reservoir := range p.reservoirs {
+		p.wg.Add(1)
+		go p.periodicReservoirFlush(name, reservoir)
+	}
+
 	// Sort rules by priority (highest first)
-	sort.Slice(p.config.SamplingRules, func(i, j int) bool {
-		return p.config.SamplingRules[i].Priority > p.config.SamplingRules[j].Priority
-	})
+	p.configMutex.Lock()
+	sortRules(p.config.SamplingRules)
+	p.configMutex.Unlock()
+
+	return nil
+}
+
+// Reconfigure implements base.Reconfigurable. The sampling rules, rates,
+// schedules and limits, deduplication settings and debug logging apply from
+// the next batch; rate limits that still exist keep their current window.
+// Changing the deduplication cleanup_interval, or adding, removing or
+// changing a rule's reservoir, requires a restart.
+func (p *adaptiveSampler) Reconfigure(cfg component.Config) error {
+	newConfig, ok := cfg.(*Config)
+	if !ok {
+		return fmt.Errorf("invalid configuration type: expected *Config, got %T", cfg)
+	}
+
+	p.configMutex.Lock()
+	defer p.configMutex.Unlock()
+
+	if newConfig.Deduplication.CleanupInterval != p.config.Deduplication.CleanupInterval {
+		return fmt.Errorf("%w: deduplication cleanup_interval changed from %v to %v", base.ErrRestartRequired,
+			p.config.Deduplication.CleanupInterval, newConfig.Deduplication.CleanupInterval)
+	}
+	if reservoirsChanged(p.config, newConfig) {
+		return fmt.Errorf("%w: sampling rule reservoirs changed", base.ErrRestartRequired)
+	}
+
+	schedule, err := newRateSchedule(newConfig)
+	if err != nil {
+		return fmt.Errorf("invalid sampling schedule: %w", err)
+	}
+
+	sortRules(newConfig.SamplingRules)
+	if size := newConfig.Deduplication.CacheSize; size > 0 && size != p.config.Deduplication.CacheSize {
+		p.stateMutex.Lock()
+		p.deduplicationCache.Resize(size)
+		p.stateMutex.Unlock()
+	}
+	p.ruleLimiters = newRuleLimiters(newConfig, p.ruleLimiters)
+	p.globalRateLimiter = newGlobalRateLimiter(newConfig, p.globalRateLimiter)
+	p.schedule = schedule
+	p.config = newConfig
+	p.telemetry.setRules(newConfig)
+
+	p.logger.Info("Reconfigured adaptive sampler processor",
+		zap.Int("num_sampling_rules", len(newConfig.SamplingRules)),
+		zap.Float64("default_sample_rate", newConfig.DefaultSampleRate),
+		zap.Int("max_records_per_second", newConfig.MaxRecordsPerSecond))
 
 	return nil
 }
@@ -142,17 +295,39 @@ func (p *adaptiveSampler) Shutdown(ctx context.Context) error {
 	close(p.shutdownChan)
 	p.wg.Wait()
 
+	// Forward what the reservoirs hold for the unfinished window
+	for name, reservoir := range p.reservoirs {
+		p.flushReservoir(ctx, name, reservoir)
+	}
+
 	// No state persistence needed for in-memory mode
 	p.logger.Info("Adaptive sampler shutdown complete", 
 		zap.Int64("total_sampled", p.sampledCount),
 		zap.Int64("total_dropped", p.droppedCount),
-		zap.Int64("total_duplicates", p.duplicateCount))
+		zap.Int64("total_duplicates", p.duplicateCount),
+		zap.Int64("total_reservoir", p.reservoirCount))
 
-	return nil
+	return p.telemetry.shutdown()
 }
 
 // ConsumeLogs processes log records with adaptive sampling
 func (p *adaptiveSampler) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
+	sampled := p.sampleLogs(logs)
+
+	// Only forward if we have sampled records
+	if sampled.LogRecordCount() > 0 {
+		return p.consumer.ConsumeLogs(ctx, sampled)
+	}
+
+	return nil
+}
+
+// sampleLogs returns the records kept by the sampling rules. The whole batch
+// is sampled with the same configuration.
+func (p *adaptiveSampler) sampleLogs(logs plog.Logs) plog.Logs {
+	p.configMutex.RLock()
+	defer p.configMutex.RUnlock()
+
 	sampled := plog.NewLogs()
 
 	for i := 0; i < logs.ResourceLogs().Len(); i++ {
@@ -179,7 +354,7 @@ func (p *adaptiveSampler) ConsumeLogs(ctx context.Context, logs plog.Logs) error
 				}
 
 				// Apply sampling decision
-				if p.shouldSample(logRecord) {
+				if p.shouldSample(resourceLogs.Resource(), scopeLogs.Scope(), logRecord) {
 					sampledLogRecord := sampledScopeLogs.LogRecords().AppendEmpty()
 					logRecord.CopyTo(sampledLogRecord)
 					p.sampledCount++
@@ -190,16 +365,12 @@ func (p *adaptiveSampler) ConsumeLogs(ctx context.Context, logs plog.Logs) error
 		}
 	}
 
-	// Only forward if we have sampled records
-	if sampled.LogRecordCount() > 0 {
-		return p.consumer.ConsumeLogs(ctx, sampled)
-	}
-
-	return nil
+	return sampled
 }
 
-// shouldSample determines if a log record should be sampled
-func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
+// shouldSample determines if a log record should be sampled. The resource
+// and scope are kept with records offered to the rule's reservoir.
+func (p *adaptiveSampler) shouldSample(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) bool {
 	// Check for deduplication if enabled
 	if p.config.Deduplication.Enabled {
 		if p.isDuplicate(record) {
@@ -212,7 +383,9 @@ func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
 	rule := p.findMatchingRule(record)
 	if rule == nil {
 		// Use default sample rate
-		return p.randomSample(p.config.DefaultSampleRate)
+		shouldSample := p.randomSample(p.config.DefaultSampleRate)
+		p.telemetry.record(defaultRuleName, shouldSample)
+		return shouldSample
 	}
 
 	// Check rate limiting for this rule
@@ -222,17 +395,26 @@ func (p *adaptiveSampler) shouldSample(record plog.LogRecord) bool {
 				p.logger.Debug("Record dropped due to rate limiting",
 					zap.String("rule", rule.Name))
 			}
+			p.telemetry.record(rule.Name, false)
 			return false
 		}
 	}
 
-	// Apply sampling rate
-	shouldSample := p.randomSample(rule.SampleRate)
+	// Apply the sampling rate of the rule's open schedule window, if any
+	sampleRate, window := p.schedule.sampleRate(rule, p.now())
+	shouldSample := p.randomSample(sampleRate)
+	p.telemetry.record(rule.Name, shouldSample)
+
+	// Records the rate drops may still be kept to cover their fingerprint
+	if reservoir, exists := p.reservoirs[rule.Name]; exists {
+		reservoir.observe(resource, scope, record, shouldSample)
+	}
 
 	if p.config.EnableDebugLogging {
 		p.logger.Debug("Sampling decision",
 			zap.String("rule", rule.Name),
-			zap.Float64("sample_rate", rule.SampleRate),
+			zap.String("schedule_window", window),
+			zap.Float64("sample_rate", sampleRate),
 			zap.Bool("sampled", shouldSample))
 	}
 
@@ -492,12 +674,68 @@ func (p *adaptiveSampler) randomSample(rate float64) bool {
 }
 
 
+// randomIndex returns a secure random index in [0, n). If no random number
+// can be generated it returns n, which callers treat as out of range.
+func (p *adaptiveSampler) randomIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		p.logger.Error("Failed to generate secure random number for reservoir sampling", zap.Error(err))
+		return n
+	}
+	return int(i.Int64())
+}
+
+// periodicReservoirFlush forwards a rule's reservoir records at the end of
+// every window
+func (p *adaptiveSampler) periodicReservoirFlush(name string, reservoir *ruleReservoir) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(reservoir.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.flushReservoir(context.Background(), name, reservoir)
+		case <-p.shutdownChan:
+			return
+		}
+	}
+}
+
+// flushReservoir ends the reservoir's window and forwards the records
+// needed to cover its fingerprints
+func (p *adaptiveSampler) flushReservoir(ctx context.Context, name string, reservoir *ruleReservoir) {
+	logs := reservoir.flush()
+	count := logs.LogRecordCount()
+	if count == 0 {
+		return
+	}
+
+	p.reservoirCount += int64(count)
+	p.telemetry.recordReservoir(name, count)
+	p.configMutex.RLock()
+	debug := p.config.EnableDebugLogging
+	p.configMutex.RUnlock()
+	if debug {
+		p.logger.Debug("Forwarding reservoir samples",
+			zap.String("rule", name),
+			zap.Int("records", count))
+	}
+
+	if err := p.consumer.ConsumeLogs(ctx, logs); err != nil {
+		p.logger.Error("Failed to forward reservoir samples", zap.String("rule", name), zap.Error(err))
+	}
+}
+
 // periodicCleanup cleans up expired cache entries
 func (p *adaptiveSampler) periodicCleanup() {
 	defer p.wg.Done()
 
 	// Default to 60 seconds if cleanup interval is not set
+	p.configMutex.RLock()
 	cleanupInterval := p.config.Deduplication.CleanupInterval
+	p.configMutex.RUnlock()
 	if cleanupInterval <= 0 {
 		cleanupInterval = 60 * time.Second
 	}
@@ -517,11 +755,15 @@ func (p *adaptiveSampler) periodicCleanup() {
 
 // cleanupExpiredHashes removes expired entries from the deduplication cache
 func (p *adaptiveSampler) cleanupExpiredHashes() {
-	if !p.config.Deduplication.Enabled {
+	p.configMutex.RLock()
+	dedup := p.config.Deduplication
+	p.configMutex.RUnlock()
+
+	if !dedup.Enabled {
 		return
 	}
 
-	windowDuration := time.Duration(p.config.Deduplication.WindowSeconds) * time.Second
+	windowDuration := time.Duration(dedup.WindowSeconds) * time.Second
 	cutoff := time.Now().Add(-windowDuration)
 
 	p.stateMutex.Lock()
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumertest"
@@ -14,17 +15,17 @@ import (
 )
 
 func TestNewAdaptiveSampler(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
 	
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	require.NotNil(t, processor)
 }
 
 func TestAdaptiveSampler_ProcessLogs(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.InMemoryOnly = true
 	cfg.SamplingRules = []SamplingRule{
 		{
@@ -44,7 +45,7 @@ func TestAdaptiveSampler_ProcessLogs(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	// Start the processor
@@ -84,7 +85,7 @@ func TestAdaptiveSampler_ProcessLogs(t *testing.T) {
 }
 
 func TestAdaptiveSampler_Deduplication(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.InMemoryOnly = true
 	cfg.DefaultSampleRate = 1.0 // Ensure we sample all logs
 	cfg.SamplingRules = []SamplingRule{} // Clear all sampling rules
@@ -97,7 +98,7 @@ func TestAdaptiveSampler_Deduplication(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -128,13 +129,13 @@ func TestAdaptiveSampler_Deduplication(t *testing.T) {
 }
 
 func TestAdaptiveSampler_RateLimiting(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.InMemoryOnly = true
 	cfg.MaxRecordsPerSecond = 10
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -163,7 +164,7 @@ func TestAdaptiveSampler_RateLimiting(t *testing.T) {
 }
 
 func TestAdaptiveSampler_MultipleRules(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.InMemoryOnly = true
 	cfg.SamplingRules = []SamplingRule{
 		{
@@ -194,7 +195,7 @@ func TestAdaptiveSampler_MultipleRules(t *testing.T) {
 	
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
-	processor, err := newAdaptiveSampler(cfg, logger, consumer)
+	processor, err := newAdaptiveSampler(cfg, logger, nil, consumer)
 	require.NoError(t, err)
 	
 	ctx := context.Background()
@@ -221,6 +222,56 @@ func TestAdaptiveSampler_MultipleRules(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAdaptiveSampler_Reconfigure(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.SamplingRules = []SamplingRule{
+		{
+			Name:       "slow-queries",
+			Priority:   1,
+			SampleRate: 0,
+			Conditions: []SamplingCondition{
+				{Attribute: "duration_ms", Operator: "gt", Value: 1000.0},
+			},
+		},
+	}
+
+	consumer := &consumertest.LogsSink{}
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), nil, consumer)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, processor.Start(ctx, nil))
+	defer processor.Shutdown(ctx)
+
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutDouble("duration_ms", 2500)
+
+	require.NoError(t, processor.ConsumeLogs(ctx, logs))
+	assert.Equal(t, 0, consumer.LogRecordCount())
+
+	// Raising the rule's sample rate applies to the next batch
+	updated := CreateDefaultConfig().(*Config)
+	updated.SamplingRules = []SamplingRule{cfg.SamplingRules[0]}
+	updated.SamplingRules[0].SampleRate = 1.0
+	require.NoError(t, updated.Validate())
+
+	var reconfigurable base.Reconfigurable = processor
+	require.NoError(t, reconfigurable.Reconfigure(updated))
+
+	require.NoError(t, processor.ConsumeLogs(ctx, logs))
+	assert.Equal(t, 1, consumer.LogRecordCount())
+
+	// The cleanup loop is started once, so its interval needs a restart
+	restart := CreateDefaultConfig().(*Config)
+	restart.Deduplication.CleanupInterval = time.Hour
+	err = reconfigurable.Reconfigure(restart)
+	assert.ErrorIs(t, err, base.ErrRestartRequired)
+
+	require.NoError(t, processor.ConsumeLogs(ctx, logs))
+	assert.Equal(t, 2, consumer.LogRecordCount(), "a rejected configuration leaves the running one in place")
+}
+
 func TestAdaptiveSampler_InvalidConfiguration(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -241,6 +292,81 @@ func TestAdaptiveSampler_InvalidConfiguration(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown timezone",
+			configure: func(cfg *Config) {
+				cfg.Timezone = "Mars/Olympus_Mons"
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule window without end",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules = []SamplingRule{
+					{
+						Name:       "scheduled",
+						SampleRate: 0.1,
+						Schedule:   []ScheduleWindow{{Start: "09:00", SampleRate: 0.5}},
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule window on an unknown day",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules = []SamplingRule{
+					{
+						Name:       "scheduled",
+						SampleRate: 0.1,
+						Schedule:   []ScheduleWindow{{Days: []string{"monday"}, Start: "09:00", End: "17:00", SampleRate: 0.5}},
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule window",
+			configure: func(cfg *Config) {
+				cfg.Timezone = "America/New_York"
+				cfg.SamplingRules = []SamplingRule{
+					{
+						Name:       "scheduled",
+						SampleRate: 0.1,
+						Schedule:   []ScheduleWindow{{Days: []string{"Mon", "fri"}, Start: "22:00", End: "06:00", SampleRate: 0.5}},
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "reservoir on low-rate rule",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, Window: time.Minute}
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative reservoir size",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir.Size = -1
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative reservoir window",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, Window: -time.Minute}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative reservoir max fingerprints",
+			configure: func(cfg *Config) {
+				cfg.SamplingRules[3].Reservoir = ReservoirConfig{Size: 1, MaxFingerprints: -1}
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid rule percentage",
 			configure: func(cfg *Config) {
@@ -257,7 +383,7 @@ func TestAdaptiveSampler_InvalidConfiguration(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			cfg := createDefaultConfig().(*Config)
+			cfg := CreateDefaultConfig().(*Config)
 			tc.configure(cfg)
 			
 			err := cfg.Validate()
@@ -268,4 +394,101 @@ func TestAdaptiveSampler_InvalidConfiguration(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAdaptiveSampler_ScheduleSwitchesAtWindowBoundary(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Deduplication.Enabled = false
+	cfg.Timezone = "America/New_York"
+	cfg.SamplingRules = []SamplingRule{
+		{
+			Name:       "slow-queries",
+			Priority:   1,
+			SampleRate: 0,
+			Schedule: []ScheduleWindow{
+				{Name: "business_hours", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00", SampleRate: 1.0},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	consumer := &consumertest.LogsSink{}
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), nil, consumer)
+	require.NoError(t, err)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// Monday
+	clock := time.Date(2024, 5, 6, 8, 59, 59, 0, newYork)
+	processor.now = func() time.Time { return clock }
+
+	ctx := context.Background()
+	require.NoError(t, processor.Start(ctx, nil))
+	defer processor.Shutdown(ctx)
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+
+	steps := []struct {
+		at   time.Time
+		kept int
+	}{
+		{time.Date(2024, 5, 6, 8, 59, 59, 0, newYork), 0},
+		{time.Date(2024, 5, 6, 9, 0, 0, 0, newYork), 1},
+		// The clock is read in the configured timezone, so 20:59 UTC is
+		// still in business hours
+		{time.Date(2024, 5, 6, 20, 59, 0, 0, time.UTC), 1},
+		{time.Date(2024, 5, 6, 17, 0, 0, 0, newYork), 0},
+		// Saturday is not in the window
+		{time.Date(2024, 5, 11, 10, 0, 0, 0, newYork), 0},
+	}
+	total := 0
+	for _, step := range steps {
+		clock = step.at
+		require.NoError(t, processor.ConsumeLogs(ctx, logs))
+		total += step.kept
+		assert.Equal(t, total, consumer.LogRecordCount(), "at %v", step.at)
+	}
+}
+
+func TestRateSchedule_OvernightWindow(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	rule := SamplingRule{
+		Name:       "nightly",
+		SampleRate: 0.5,
+		Schedule: []ScheduleWindow{
+			{Name: "weeknights", Days: []string{"fri"}, Start: "22:00", End: "06:00", SampleRate: 0.01},
+			{Name: "evenings", Start: "18:00", End: "00:00", SampleRate: 0.2},
+		},
+	}
+	cfg.SamplingRules = []SamplingRule{rule}
+	schedule, err := newRateSchedule(cfg)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		at     time.Time
+		rate   float64
+		window string
+	}{
+		// Friday 2024-05-10
+		{time.Date(2024, 5, 10, 21, 59, 0, 0, time.UTC), 0.2, "evenings"},
+		{time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC), 0.01, "weeknights"},
+		// The part after midnight belongs to Friday's window
+		{time.Date(2024, 5, 11, 5, 59, 0, 0, time.UTC), 0.01, "weeknights"},
+		{time.Date(2024, 5, 11, 6, 0, 0, 0, time.UTC), 0.5, ""},
+		// Thursday night is not a Friday window; evenings end at midnight
+		{time.Date(2024, 5, 9, 23, 0, 0, 0, time.UTC), 0.2, "evenings"},
+		{time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), 0.5, ""},
+	}
+	for _, tc := range testCases {
+		rate, window := schedule.sampleRate(&rule, tc.at)
+		assert.Equal(t, tc.rate, rate, "at %v", tc.at)
+		assert.Equal(t, tc.window, window, "at %v", tc.at)
+	}
+
+	// Rules without a schedule keep their static rate
+	static := SamplingRule{Name: "static", SampleRate: 0.3}
+	rate, window := schedule.sampleRate(&static, time.Now())
+	assert.Equal(t, 0.3, rate)
+	assert.Empty(t, window)
+}
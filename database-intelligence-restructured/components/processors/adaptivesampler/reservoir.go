@@ -0,0 +1,132 @@
+package adaptivesampler
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Defaults for ReservoirConfig
+const (
+	defaultReservoirWindow      = time.Minute
+	defaultFingerprintAttribute = "db.query.fingerprint"
+	defaultMaxFingerprints      = 10000
+)
+
+// ruleReservoir holds one rule's per-fingerprint reservoirs for the current
+// window
+type ruleReservoir struct {
+	config       ReservoirConfig
+	fingerprints map[string]*fingerprintReservoir
+	mutex        sync.Mutex
+
+	// randomIndex returns a random index in [0, n)
+	randomIndex func(n int) int
+}
+
+// fingerprintReservoir tracks one fingerprint within a window
+type fingerprintReservoir struct {
+	// kept is the number of records the sample rate kept
+	kept int
+	// dropped is the number of records the sample rate dropped
+	dropped int
+	// records is a uniform sample of the dropped records, each with its
+	// resource and scope
+	records []plog.Logs
+}
+
+func newRuleReservoir(cfg ReservoirConfig, randomIndex func(n int) int) *ruleReservoir {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultReservoirWindow
+	}
+	if cfg.FingerprintAttribute == "" {
+		cfg.FingerprintAttribute = defaultFingerprintAttribute
+	}
+	if cfg.MaxFingerprints <= 0 {
+		cfg.MaxFingerprints = defaultMaxFingerprints
+	}
+
+	return &ruleReservoir{
+		config:       cfg,
+		fingerprints: make(map[string]*fingerprintReservoir),
+		randomIndex:  randomIndex,
+	}
+}
+
+// observe records the sampling decision for a record matched by the rule.
+// Dropped records are offered to the fingerprint's reservoir using
+// Algorithm R, so every dropped record has the same chance to be kept.
+func (r *ruleReservoir) observe(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord, sampled bool) {
+	attr, exists := record.Attributes().Get(r.config.FingerprintAttribute)
+	if !exists {
+		return
+	}
+	fingerprint := attr.AsString()
+	if fingerprint == "" {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fr, exists := r.fingerprints[fingerprint]
+	if !exists {
+		if len(r.fingerprints) >= r.config.MaxFingerprints {
+			return
+		}
+		fr = &fingerprintReservoir{}
+		r.fingerprints[fingerprint] = fr
+	}
+
+	if sampled {
+		fr.kept++
+		if fr.kept >= r.config.Size {
+			// The sample rate alone covers this fingerprint
+			fr.records = nil
+		}
+		return
+	}
+	if fr.kept >= r.config.Size {
+		return
+	}
+
+	fr.dropped++
+	if len(fr.records) < r.config.Size {
+		fr.records = append(fr.records, copyLogRecord(resource, scope, record))
+		return
+	}
+	if i := r.randomIndex(fr.dropped); i < r.config.Size {
+		fr.records[i] = copyLogRecord(resource, scope, record)
+	}
+}
+
+// flush ends the window and returns the reservoir records needed to bring
+// every fingerprint up to the configured size
+func (r *ruleReservoir) flush() plog.Logs {
+	r.mutex.Lock()
+	fingerprints := r.fingerprints
+	r.fingerprints = make(map[string]*fingerprintReservoir)
+	r.mutex.Unlock()
+
+	logs := plog.NewLogs()
+	for _, fr := range fingerprints {
+		needed := r.config.Size - fr.kept
+		for i := 0; i < needed && i < len(fr.records); i++ {
+			fr.records[i].ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+		}
+	}
+	return logs
+}
+
+// copyLogRecord copies a record with its resource and scope into new logs
+func copyLogRecord(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	resource.CopyTo(rl.Resource())
+	sl := rl.ScopeLogs().AppendEmpty()
+	scope.CopyTo(sl.Scope())
+	record.CopyTo(sl.LogRecords().AppendEmpty())
+	return logs
+}
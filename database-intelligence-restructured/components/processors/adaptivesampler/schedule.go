@@ -0,0 +1,139 @@
+package adaptivesampler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdays maps the day names accepted in a schedule window
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// window is a parsed ScheduleWindow
+type window struct {
+	name       string
+	sampleRate float64
+	// start and end are minutes since midnight; end <= start wraps past
+	// midnight
+	start int
+	end   int
+	// days the window starts on, every day when empty
+	days map[time.Weekday]bool
+}
+
+// rateSchedule selects the sample rate of rules with a schedule by the wall
+// clock in the configured timezone
+type rateSchedule struct {
+	location *time.Location
+	// windows are keyed by rule name, in configuration order
+	windows map[string][]window
+}
+
+// newRateSchedule parses the timezone and the schedules of cfg's rules
+func newRateSchedule(cfg *Config) (*rateSchedule, error) {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+
+	s := &rateSchedule{location: location, windows: make(map[string][]window)}
+	for _, rule := range cfg.SamplingRules {
+		for i, w := range rule.Schedule {
+			parsed, err := w.parse()
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: schedule window %d: %w", rule.Name, i, err)
+			}
+			s.windows[rule.Name] = append(s.windows[rule.Name], parsed)
+		}
+	}
+	return s, nil
+}
+
+// sampleRate returns the rate of the first of the rule's windows that
+// contains now, with the window's name, or the rule's own rate and an empty
+// name when none does
+func (s *rateSchedule) sampleRate(rule *SamplingRule, now time.Time) (float64, string) {
+	windows := s.windows[rule.Name]
+	if len(windows) == 0 {
+		return rule.SampleRate, ""
+	}
+
+	local := now.In(s.location)
+	for _, w := range windows {
+		if w.contains(local) {
+			return w.sampleRate, w.name
+		}
+	}
+	return rule.SampleRate, ""
+}
+
+// contains reports whether t, in the schedule's timezone, is in the window.
+// The part of a window after midnight belongs to the day it started on.
+func (w window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end && w.onDay(day)
+	}
+	if minute >= w.start {
+		return w.onDay(day)
+	}
+	if minute < w.end {
+		return w.onDay((day + 6) % 7)
+	}
+	return false
+}
+
+func (w window) onDay(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+// parse checks the window and converts its times and days
+func (w ScheduleWindow) parse() (window, error) {
+	if w.SampleRate < 0.0 || w.SampleRate > 1.0 {
+		return window{}, fmt.Errorf("sample_rate must be between 0.0 and 1.0, got: %f", w.SampleRate)
+	}
+
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid end: %w", err)
+	}
+	if start == end {
+		return window{}, fmt.Errorf("start and end cannot both be %s", w.Start)
+	}
+
+	parsed := window{name: w.Name, sampleRate: w.SampleRate, start: start, end: end}
+	if len(w.Days) > 0 {
+		parsed.days = make(map[time.Weekday]bool, len(w.Days))
+		for _, name := range w.Days {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return window{}, fmt.Errorf("invalid day %q, must be one of mon, tue, wed, thu, fri, sat, sun", name)
+			}
+			parsed.days[day] = true
+		}
+	}
+	return parsed, nil
+}
+
+// parseTimeOfDay converts HH:MM to minutes since midnight
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,229 @@
+package adaptivesampler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+func TestSamplerTelemetryPerRule(t *testing.T) {
+	tierRule := func(name, tier string, rate float64, priority int) SamplingRule {
+		return SamplingRule{
+			Name:       name,
+			Priority:   priority,
+			SampleRate: rate,
+			Conditions: []SamplingCondition{{Attribute: "tier", Operator: "eq", Value: tier}},
+		}
+	}
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Deduplication.Enabled = false
+	cfg.DefaultSampleRate = 1.0
+	limited := tierRule("limited", "silver", 1.0, 10)
+	limited.MaxPerMinute = 5
+	cfg.SamplingRules = []SamplingRule{
+		tierRule("keep_all", "gold", 1.0, 30),
+		tierRule("drop_all", "bronze", 0, 20),
+		limited,
+	}
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), provider, sink)
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for tier, n := range map[string]int{"gold": 10, "bronze": 8, "silver": 7, "": 4} {
+		for i := 0; i < n; i++ {
+			record := records.AppendEmpty()
+			if tier != "" {
+				record.Attributes().PutStr("tier", tier)
+			}
+		}
+	}
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+	assert.Equal(t, 19, sink.LogRecordCount())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	want := map[string]struct {
+		evaluated, kept int64
+		effective       float64
+		configured      float64
+	}{
+		"keep_all":      {10, 10, 1, 1},
+		"drop_all":      {8, 0, 0, 0},
+		"limited":       {7, 5, 5.0 / 7, 1},
+		defaultRuleName: {4, 4, 1, 1},
+	}
+
+	evaluated := sumByRule(t, rm, "processor_adaptivesampler_records_evaluated")
+	kept := sumByRule(t, rm, "processor_adaptivesampler_records_kept")
+	effective := gaugeByRule(t, rm, "processor_adaptivesampler_effective_sample_rate")
+	configured := gaugeByRule(t, rm, "processor_adaptivesampler_configured_sample_rate")
+
+	for rule, w := range want {
+		assert.Equal(t, w.evaluated, evaluated[rule], "evaluated for %s", rule)
+		assert.Equal(t, w.kept, kept[rule], "kept for %s", rule)
+		assert.InDelta(t, w.effective, effective[rule], 1e-9, "effective rate for %s", rule)
+		assert.InDelta(t, w.configured, configured[rule], 1e-9, "configured rate for %s", rule)
+	}
+	_, counted := kept["drop_all"]
+	assert.False(t, counted, "rules that keep nothing report no kept series")
+
+	require.NoError(t, processor.telemetry.shutdown())
+}
+
+func TestSamplerTelemetryReservoir(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.SamplingRules = []SamplingRule{{Name: "all", SampleRate: 0}}
+
+	telemetry, err := newSamplerTelemetry(nil, cfg, func() map[string]float64 { return nil })
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		telemetry.record("all", false)
+	}
+	telemetry.recordReservoir("all", 2)
+
+	rate, ok := telemetry.effectiveRate("all")
+	require.True(t, ok)
+	assert.InDelta(t, 0.2, rate, 1e-9, "records the reservoir forwards count as kept")
+
+	// Unknown rules are ignored
+	telemetry.record("missing", true)
+	_, ok = telemetry.effectiveRate("missing")
+	assert.False(t, ok)
+}
+
+func TestSamplerTelemetryWindow(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	telemetry, err := newSamplerTelemetry(nil, cfg, func() map[string]float64 { return nil })
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	telemetry.now = func() time.Time { return now }
+
+	_, ok := telemetry.effectiveRate(defaultRuleName)
+	assert.False(t, ok, "no rate before any record is evaluated")
+
+	// Everything kept at first
+	for i := 0; i < 4; i++ {
+		telemetry.record(defaultRuleName, true)
+	}
+
+	// Then nothing, two minutes later
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 4; i++ {
+		telemetry.record(defaultRuleName, false)
+	}
+	rate, ok := telemetry.effectiveRate(defaultRuleName)
+	require.True(t, ok)
+	assert.InDelta(t, 0.5, rate, 1e-9)
+
+	// The first records age out of the window
+	now = now.Add(4 * time.Minute)
+	rate, ok = telemetry.effectiveRate(defaultRuleName)
+	require.True(t, ok)
+	assert.InDelta(t, 0.0, rate, 1e-9)
+
+	// And then the rest
+	now = now.Add(2 * time.Minute)
+	_, ok = telemetry.effectiveRate(defaultRuleName)
+	assert.False(t, ok)
+}
+
+func TestSamplerTelemetryFollowsScheduleAndReconfigure(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Deduplication.Enabled = false
+	cfg.SamplingRules = []SamplingRule{
+		{
+			Name:       "scheduled",
+			SampleRate: 0.1,
+			Schedule:   []ScheduleWindow{{Start: "09:00", End: "17:00", SampleRate: 0.5}},
+		},
+		{Name: "retired", SampleRate: 0.2},
+	}
+	require.NoError(t, cfg.Validate())
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	processor, err := newAdaptiveSampler(cfg, zap.NewNop(), provider, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	clock := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	processor.now = func() time.Time { return clock }
+
+	configured := func() map[string]float64 {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		return gaugeByRule(t, rm, "processor_adaptivesampler_configured_sample_rate")
+	}
+
+	assert.Equal(t, map[string]float64{"scheduled": 0.1, "retired": 0.2, defaultRuleName: 0.1}, configured())
+
+	// An open schedule window is the rate in effect
+	clock = time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0.5, configured()["scheduled"])
+
+	// A reload adds and removes rules and changes their rates
+	updated := CreateDefaultConfig().(*Config)
+	updated.Deduplication.Enabled = false
+	updated.DefaultSampleRate = 0.3
+	updated.SamplingRules = []SamplingRule{
+		{Name: "scheduled", SampleRate: 0.1},
+		{Name: "added", SampleRate: 0.4},
+	}
+	require.NoError(t, updated.Validate())
+	require.NoError(t, processor.Reconfigure(updated))
+
+	assert.Equal(t, map[string]float64{"scheduled": 0.1, "added": 0.4, defaultRuleName: 0.3}, configured())
+	require.NoError(t, processor.telemetry.shutdown())
+}
+
+func sumByRule(t *testing.T, rm metricdata.ResourceMetrics, name string) map[string]int64 {
+	t.Helper()
+	out := make(map[string]int64)
+	for _, sum := range findMetric(t, rm, name).Data.(metricdata.Sum[int64]).DataPoints {
+		rule, _ := sum.Attributes.Value("rule")
+		out[rule.AsString()] = sum.Value
+	}
+	return out
+}
+
+func gaugeByRule(t *testing.T, rm metricdata.ResourceMetrics, name string) map[string]float64 {
+	t.Helper()
+	out := make(map[string]float64)
+	for _, dp := range findMetric(t, rm, name).Data.(metricdata.Gauge[float64]).DataPoints {
+		rule, _ := dp.Attributes.Value("rule")
+		out[rule.AsString()] = dp.Value
+	}
+	return out
+}
+
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	require.Failf(t, "metric not found", "%s", name)
+	return metricdata.Metrics{}
+}
@@ -0,0 +1,63 @@
+package attributefilter
+
+import (
+	"fmt"
+	"path"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the attribute filter processor.
+//
+// Patterns are attribute keys or globs in path.Match syntax, e.g.
+// "internal.*" or "k8s.*.uid". For each level the include patterns of the
+// top level and of the level act as an allowlist when any are set; the
+// exclude patterns then remove keys, so exclude wins over include.
+type Config struct {
+	// Include keeps only matching attributes on resources and records
+	Include []string `mapstructure:"include"`
+
+	// Exclude removes matching attributes from resources and records
+	Exclude []string `mapstructure:"exclude"`
+
+	// Resource adds patterns for resource attributes only
+	Resource LevelConfig `mapstructure:"resource"`
+
+	// Record adds patterns for log record and metric data point attributes
+	// only
+	Record LevelConfig `mapstructure:"record"`
+}
+
+// LevelConfig holds the patterns for one attribute level
+type LevelConfig struct {
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	lists := []struct {
+		name     string
+		patterns []string
+	}{
+		{"include", cfg.Include},
+		{"exclude", cfg.Exclude},
+		{"resource.include", cfg.Resource.Include},
+		{"resource.exclude", cfg.Resource.Exclude},
+		{"record.include", cfg.Record.Include},
+		{"record.exclude", cfg.Record.Exclude},
+	}
+	for _, list := range lists {
+		for i, pattern := range list.patterns {
+			if pattern == "" {
+				return fmt.Errorf("%s[%d] must not be empty", list.name, i)
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s[%d] %q is not a valid glob: %w", list.name, i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
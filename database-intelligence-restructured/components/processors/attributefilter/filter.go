@@ -0,0 +1,72 @@
+package attributefilter
+
+import (
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// keyMatcher matches attribute keys against exact keys and globs
+type keyMatcher struct {
+	exact map[string]struct{}
+	globs []string
+}
+
+// newKeyMatcher returns nil for no patterns. Patterns must have been
+// validated with path.Match.
+func newKeyMatcher(patterns []string) *keyMatcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	m := &keyMatcher{exact: make(map[string]struct{})}
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, `*?[\`) {
+			m.globs = append(m.globs, pattern)
+		} else {
+			m.exact[pattern] = struct{}{}
+		}
+	}
+	return m
+}
+
+func (m *keyMatcher) match(key string) bool {
+	if _, ok := m.exact[key]; ok {
+		return true
+	}
+	for _, glob := range m.globs {
+		if ok, _ := path.Match(glob, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFilter decides which attributes of one level are kept
+type keyFilter struct {
+	// include is nil when every key is allowed
+	include *keyMatcher
+	exclude *keyMatcher
+}
+
+func newKeyFilter(include, exclude []string) keyFilter {
+	return keyFilter{include: newKeyMatcher(include), exclude: newKeyMatcher(exclude)}
+}
+
+// keep reports whether an attribute with key passes the filter
+func (f keyFilter) keep(key string) bool {
+	if f.include != nil && !f.include.match(key) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.match(key)
+}
+
+// apply removes the attributes that do not pass the filter
+func (f keyFilter) apply(attrs pcommon.Map) {
+	if f.include == nil && f.exclude == nil {
+		return
+	}
+	attrs.RemoveIf(func(key string, _ pcommon.Value) bool {
+		return !f.keep(key)
+	})
+}
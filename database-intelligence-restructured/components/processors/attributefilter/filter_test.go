@@ -0,0 +1,77 @@
+package attributefilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMatcher(t *testing.T) {
+	m := newKeyMatcher([]string{"service.name", "internal.*", "k8s.*.uid", "db.query.plan.?", "host.[ai]*"})
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"service.name", true},
+		{"service.namespace", false},
+		{"internal.collector_id", true},
+		{"internal", false},
+		{"k8s.pod.uid", true},
+		{"k8s.node.uid", true},
+		{"k8s.pod.name", false},
+		{"db.query.plan.x", true},
+		{"db.query.plan.hash", false},
+		{"host.arch", true},
+		{"host.id", true},
+		{"host.name", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.match(tt.key), tt.key)
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		keep    []string
+		drop    []string
+	}{
+		{
+			name: "no patterns keeps everything",
+			keep: []string{"anything", "internal.id"},
+		},
+		{
+			name:    "denylist",
+			exclude: []string{"internal.*", "net.peer.ip"},
+			keep:    []string{"db.name", "net.peer.port"},
+			drop:    []string{"internal.id", "net.peer.ip"},
+		},
+		{
+			name:    "allowlist",
+			include: []string{"db.*", "service.name"},
+			keep:    []string{"db.name", "db.query.fingerprint", "service.name"},
+			drop:    []string{"host.name", "service.version"},
+		},
+		{
+			name:    "exclude wins over include",
+			include: []string{"db.*"},
+			exclude: []string{"db.statement"},
+			keep:    []string{"db.name"},
+			drop:    []string{"db.statement", "host.name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newKeyFilter(tt.include, tt.exclude)
+			for _, key := range tt.keep {
+				assert.True(t, f.keep(key), "should keep %s", key)
+			}
+			for _, key := range tt.drop {
+				assert.False(t, f.keep(key), "should drop %s", key)
+			}
+		})
+	}
+}
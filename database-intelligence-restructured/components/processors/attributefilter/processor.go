@@ -0,0 +1,83 @@
+package attributefilter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// attributeFilterProcessor removes attributes from resources and from log
+// records and data points in a single pass
+type attributeFilterProcessor struct {
+	logger   *zap.Logger
+	resource keyFilter
+	record   keyFilter
+}
+
+// processLogs filters resource and log record attributes
+func (afp *attributeFilterProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		afp.resource.apply(rl.Resource().Attributes())
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				afp.record.apply(records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processMetrics filters resource and data point attributes
+func (afp *attributeFilterProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		afp.resource.apply(rm.Resource().Attributes())
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				afp.filterMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (afp *attributeFilterProcessor) filterMetric(metric pmetric.Metric) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			afp.record.apply(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			afp.record.apply(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			afp.record.apply(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			afp.record.apply(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			afp.record.apply(dps.At(i).Attributes())
+		}
+	}
+}
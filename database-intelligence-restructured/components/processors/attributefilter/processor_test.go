@@ -0,0 +1,166 @@
+package attributefilter
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "globs on every level",
+			modify: func(cfg *Config) {
+				cfg.Exclude = []string{"internal.*"}
+				cfg.Resource.Include = []string{"service.*", "host.name"}
+				cfg.Record.Exclude = []string{"db.query.plan.?"}
+			},
+		},
+		{
+			name:    "empty pattern",
+			modify:  func(cfg *Config) { cfg.Exclude = []string{"internal.*", ""} },
+			wantErr: "exclude[1] must not be empty",
+		},
+		{
+			name:    "invalid glob",
+			modify:  func(cfg *Config) { cfg.Record.Include = []string{"db.[name"} },
+			wantErr: `record.include[0] "db.[name" is not a valid glob`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// keys returns the sorted attribute keys of attrs
+func keys(attrs pcommon.Map) []string {
+	var out []string
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		out = append(out, k)
+		return true
+	})
+	sort.Strings(out)
+	return out
+}
+
+func putAll(attrs pcommon.Map, keys ...string) {
+	for _, k := range keys {
+		attrs.PutStr(k, "v")
+	}
+}
+
+func TestProcessLogs_Scoping(t *testing.T) {
+	cfg := &Config{
+		// Both levels
+		Exclude: []string{"internal.*"},
+		Resource: LevelConfig{
+			Exclude: []string{"k8s.*.uid"},
+		},
+		Record: LevelConfig{
+			Include: []string{"db.*", "internal.*", "k8s.*"},
+		},
+	}
+	factory := NewFactory()
+	sink := &consumertest.LogsSink{}
+	proc, err := factory.CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	putAll(rl.Resource().Attributes(), "service.name", "internal.collector_id", "k8s.pod.uid", "k8s.pod.name")
+	putAll(rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes(),
+		"db.statement", "internal.trace", "k8s.pod.uid", "net.peer.ip")
+
+	require.NoError(t, proc.ConsumeLogs(context.Background(), logs))
+	got := sink.AllLogs()[0].ResourceLogs().At(0)
+
+	// The record allowlist does not apply to the resource, and the resource
+	// exclude does not apply to records
+	assert.Equal(t, []string{"k8s.pod.name", "service.name"}, keys(got.Resource().Attributes()))
+	assert.Equal(t, []string{"db.statement", "k8s.pod.uid"}, keys(got.ScopeLogs().At(0).LogRecords().At(0).Attributes()))
+}
+
+func TestProcessMetrics_Scoping(t *testing.T) {
+	cfg := &Config{
+		Resource: LevelConfig{
+			Include: []string{"service.*"},
+		},
+		Record: LevelConfig{
+			Exclude: []string{"db.query.text", "net.*"},
+		},
+	}
+	factory := NewFactory()
+	sink := &consumertest.MetricsSink{}
+	proc, err := factory.CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	putAll(rm.Resource().Attributes(), "service.name", "service.version", "host.name", "db.query.text")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	putAll(ms.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().Attributes(), "db.name", "db.query.text", "net.peer.ip")
+	putAll(ms.AppendEmpty().SetEmptySum().DataPoints().AppendEmpty().Attributes(), "db.name", "net.peer.port")
+	putAll(ms.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty().Attributes(), "db.name", "db.query.text")
+	putAll(ms.AppendEmpty().SetEmptyExponentialHistogram().DataPoints().AppendEmpty().Attributes(), "db.name", "net.peer.ip")
+	putAll(ms.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty().Attributes(), "db.name", "db.query.text")
+
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), metrics))
+	got := sink.AllMetrics()[0].ResourceMetrics().At(0)
+
+	// db.query.text is only excluded from data points, and the resource
+	// allowlist drops it from the resource
+	assert.Equal(t, []string{"service.name", "service.version"}, keys(got.Resource().Attributes()))
+
+	gotMetrics := got.ScopeMetrics().At(0).Metrics()
+	dataPointAttrs := []pcommon.Map{
+		gotMetrics.At(0).Gauge().DataPoints().At(0).Attributes(),
+		gotMetrics.At(1).Sum().DataPoints().At(0).Attributes(),
+		gotMetrics.At(2).Histogram().DataPoints().At(0).Attributes(),
+		gotMetrics.At(3).ExponentialHistogram().DataPoints().At(0).Attributes(),
+		gotMetrics.At(4).Summary().DataPoints().At(0).Attributes(),
+	}
+	for i, attrs := range dataPointAttrs {
+		assert.Equal(t, []string{"db.name"}, keys(attrs), "metric %d", i)
+	}
+}
+
+func TestProcessMetrics_DefaultKeepsEverything(t *testing.T) {
+	afp, err := newProcessor(createDefaultConfig(), processortest.NewNopSettings())
+	require.NoError(t, err)
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	putAll(rm.Resource().Attributes(), "service.name", "internal.id")
+	putAll(rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().Attributes(), "db.name")
+
+	got, err := afp.processMetrics(context.Background(), metrics)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"internal.id", "service.name"}, keys(got.ResourceMetrics().At(0).Resource().Attributes()))
+}
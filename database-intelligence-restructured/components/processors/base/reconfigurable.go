@@ -0,0 +1,23 @@
+package base
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// ErrRestartRequired is returned by Reconfigure when the new configuration
+// changes a setting that only takes effect when the processor is recreated
+var ErrRestartRequired = errors.New("restart required")
+
+// Reconfigurable is implemented by processors that can apply a new
+// configuration while running, so a config reload does not need to rebuild
+// the pipelines and drop the data in flight.
+//
+// Reconfigure receives a validated configuration of the processor's own type.
+// It either applies all of it or none of it; when a changed setting cannot be
+// applied at runtime it returns an error wrapping ErrRestartRequired and the
+// caller falls back to a full reload.
+type Reconfigurable interface {
+	Reconfigure(cfg component.Config) error
+}
@@ -0,0 +1,119 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// hashes creates the hash of each algorithm
+var hashes = map[string]func() hash.Hash{
+	AlgorithmSHA256:  sha256.New,
+	AlgorithmSHA512:  sha512.New,
+	AlgorithmMD5:     md5.New,
+	AlgorithmFNV1a64: func() hash.Hash { return fnv.New64a() },
+}
+
+// record is the view of a log record or data point the fields are read from
+type record struct {
+	resource   pcommon.Map
+	attributes pcommon.Map
+	timestamp  pcommon.Timestamp
+
+	// Log records only
+	body         *pcommon.Value
+	severityText string
+
+	// Data points only
+	name  string
+	value string
+}
+
+// canonicalForm writes the fields of r as lines of <field>=<value>, with
+// each value as a JSON string, so the same record always hashes the same
+// and any consumer can rebuild the input. Whole attribute maps are written
+// as one line per key, <field>.<key>=<value>, in key order, without the
+// checksum and batch ID attributes. Fields r does not have are left out.
+func canonicalForm(fields []field, r record, skipAttribute, skipResource string) []byte {
+	var buf bytes.Buffer
+	for _, f := range fields {
+		switch f.kind {
+		case kindName:
+			if r.name != "" {
+				writeLine(&buf, f.label, r.name)
+			}
+		case kindValue:
+			if r.value != "" {
+				writeLine(&buf, f.label, r.value)
+			}
+		case kindTimestamp:
+			if r.timestamp != 0 {
+				writeLine(&buf, f.label, strconv.FormatUint(uint64(r.timestamp), 10))
+			}
+		case kindBody:
+			if r.body != nil && r.body.Type() != pcommon.ValueTypeEmpty {
+				writeLine(&buf, f.label, r.body.AsString())
+			}
+		case kindSeverityText:
+			if r.severityText != "" {
+				writeLine(&buf, f.label, r.severityText)
+			}
+		case kindAttributes:
+			writeMap(&buf, f.label, r.attributes, skipAttribute)
+		case kindAttribute:
+			if v, ok := r.attributes.Get(f.key); ok {
+				writeLine(&buf, f.label, v.AsString())
+			}
+		case kindResource:
+			writeMap(&buf, f.label, r.resource, skipResource)
+		case kindResourceAttribute:
+			if v, ok := r.resource.Get(f.key); ok {
+				writeLine(&buf, f.label, v.AsString())
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeMap writes a line per key of attrs in key order, except skip
+func writeMap(buf *bytes.Buffer, label string, attrs pcommon.Map, skip string) {
+	keys := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		if k != skip {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, _ := attrs.Get(k)
+		writeLine(buf, label+"."+k, v.AsString())
+	}
+}
+
+// writeLine writes <label>=<value as a JSON string>
+func writeLine(buf *bytes.Buffer, label, value string) {
+	buf.WriteString(label)
+	buf.WriteByte('=')
+	// Escaping HTML would make the form harder to rebuild outside Go;
+	// Encode adds the newline ending the line
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(value)
+}
+
+// sum returns the hex encoded hash of data
+func sum(newHash func() hash.Hash, data []byte) string {
+	h := newHash()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,148 @@
+package checksum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Fields a checksum can cover. A field a record does not have, such as body
+// on a data point or a missing attribute, is left out of its checksum.
+const (
+	// FieldName is the metric name of a data point
+	FieldName = "name"
+	// FieldValue is a data point's value; count/sum for histograms and
+	// summaries
+	FieldValue = "value"
+	// FieldTimestamp is the record's timestamp in Unix nanoseconds
+	FieldTimestamp = "timestamp"
+	// FieldBody is a log record's body
+	FieldBody = "body"
+	// FieldSeverityText is a log record's severity text
+	FieldSeverityText = "severity_text"
+	// FieldAttributes is every record attribute except the checksum
+	// attribute itself; attributes.<key> is a single one
+	FieldAttributes = "attributes"
+	// FieldResource is every resource attribute; resource.<key> is a
+	// single one
+	FieldResource = "resource"
+)
+
+// Hash algorithms
+const (
+	AlgorithmSHA256  = "sha256"
+	AlgorithmSHA512  = "sha512"
+	AlgorithmMD5     = "md5"
+	AlgorithmFNV1a64 = "fnv1a_64"
+)
+
+// Config defines configuration for the checksum processor
+type Config struct {
+	// Fields are the parts of each record the checksum covers, in the
+	// order they are hashed
+	Fields []string `mapstructure:"fields"`
+
+	// Algorithm is the hash: sha256, sha512, md5 or fnv1a_64
+	Algorithm string `mapstructure:"algorithm"`
+
+	// Attribute is the record attribute the hex encoded checksum is
+	// stamped into
+	Attribute string `mapstructure:"attribute"`
+
+	// BatchIDAttribute is the resource attribute each log batch is stamped
+	// with a random ID in, so a replayed batch can be told apart from a new
+	// one downstream. A resource that already has the attribute keeps its
+	// ID, so a batch sent again keeps the ID of its first attempt. Empty
+	// disables it.
+	BatchIDAttribute string `mapstructure:"batch_id_attribute"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Fields) == 0 {
+		return errors.New("fields must not be empty")
+	}
+
+	seen := make(map[string]bool, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		if _, err := parseField(field); err != nil {
+			return err
+		}
+		if seen[field] {
+			return fmt.Errorf("fields: %q is listed twice", field)
+		}
+		seen[field] = true
+	}
+
+	if _, ok := hashes[cfg.Algorithm]; !ok {
+		return fmt.Errorf("algorithm must be one of %s, %s, %s or %s, got %q",
+			AlgorithmSHA256, AlgorithmSHA512, AlgorithmMD5, AlgorithmFNV1a64, cfg.Algorithm)
+	}
+
+	if cfg.Attribute == "" {
+		return errors.New("attribute must be specified")
+	}
+	if seen[FieldAttributes+"."+cfg.Attribute] {
+		return fmt.Errorf("fields: the checksum cannot cover its own attribute %q", cfg.Attribute)
+	}
+	if cfg.BatchIDAttribute != "" && seen[FieldResource+"."+cfg.BatchIDAttribute] {
+		return fmt.Errorf("fields: the checksum cannot cover the batch ID attribute %q", cfg.BatchIDAttribute)
+	}
+	return nil
+}
+
+// fieldKind is what a field refers to
+type fieldKind int
+
+const (
+	kindName fieldKind = iota
+	kindValue
+	kindTimestamp
+	kindBody
+	kindSeverityText
+	kindAttributes
+	kindAttribute
+	kindResource
+	kindResourceAttribute
+)
+
+// field is a parsed entry of Fields
+type field struct {
+	// label is the configured field, written before its value
+	label string
+	kind  fieldKind
+	// key is the attribute key of attributes.<key> and resource.<key>
+	key string
+}
+
+// parseField converts a configured field
+func parseField(label string) (field, error) {
+	switch label {
+	case FieldName:
+		return field{label: label, kind: kindName}, nil
+	case FieldValue:
+		return field{label: label, kind: kindValue}, nil
+	case FieldTimestamp:
+		return field{label: label, kind: kindTimestamp}, nil
+	case FieldBody:
+		return field{label: label, kind: kindBody}, nil
+	case FieldSeverityText:
+		return field{label: label, kind: kindSeverityText}, nil
+	case FieldAttributes:
+		return field{label: label, kind: kindAttributes}, nil
+	case FieldResource:
+		return field{label: label, kind: kindResource}, nil
+	}
+
+	if key, ok := strings.CutPrefix(label, FieldAttributes+"."); ok && key != "" {
+		return field{label: label, kind: kindAttribute, key: key}, nil
+	}
+	if key, ok := strings.CutPrefix(label, FieldResource+"."); ok && key != "" {
+		return field{label: label, kind: kindResourceAttribute, key: key}, nil
+	}
+	return field{}, fmt.Errorf("fields: unknown field %q, must be name, value, timestamp, body, severity_text, attributes, attributes.<key>, resource or resource.<key>", label)
+}
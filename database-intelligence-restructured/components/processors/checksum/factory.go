@@ -0,0 +1,101 @@
+package checksum
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// DefaultAttribute is the attribute the checksum is stamped into
+	DefaultAttribute = "telemetry.checksum"
+)
+
+var (
+	// componentType is the type of this processor
+	componentType = component.MustNewType("checksum")
+	// stability is the stability level of this processor
+	stability = component.StabilityLevelAlpha
+)
+
+// NewFactory creates a new processor factory
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		createDefaultConfig,
+		processor.WithMetrics(createMetricsProcessor, stability),
+		processor.WithLogs(createLogsProcessor, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration, which covers a
+// data point's metric name, value and attributes, and a log record's body
+// and attributes
+func createDefaultConfig() component.Config {
+	return &Config{
+		Fields:    []string{FieldName, FieldValue, FieldBody, FieldAttributes},
+		Algorithm: AlgorithmSHA256,
+		Attribute: DefaultAttribute,
+	}
+}
+
+// createMetricsProcessor creates a metrics processor
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	cp, err := newProcessor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		cp.processMetrics,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// createLogsProcessor creates a logs processor
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	cp, err := newProcessor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		cp.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+func newProcessor(cfg component.Config) (*checksumProcessor, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return newChecksumProcessor(processorConfig), nil
+}
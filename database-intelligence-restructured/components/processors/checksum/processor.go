@@ -0,0 +1,176 @@
+package checksum
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"hash"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// checksumProcessor stamps every log record and data point with a checksum
+// of its configured fields, and each log batch with an ID when configured
+type checksumProcessor struct {
+	fields           []field
+	newHash          func() hash.Hash
+	attribute        string
+	batchIDAttribute string
+}
+
+func newChecksumProcessor(cfg *Config) *checksumProcessor {
+	fields := make([]field, 0, len(cfg.Fields))
+	for _, label := range cfg.Fields {
+		// Validate has checked every field
+		f, _ := parseField(label)
+		fields = append(fields, f)
+	}
+	return &checksumProcessor{
+		fields:           fields,
+		newHash:          hashes[cfg.Algorithm],
+		attribute:        cfg.Attribute,
+		batchIDAttribute: cfg.BatchIDAttribute,
+	}
+}
+
+// checksum returns the checksum of r
+func (p *checksumProcessor) checksum(r record) string {
+	return sum(p.newHash, canonicalForm(p.fields, r, p.attribute, p.batchIDAttribute))
+}
+
+// stampBatchID stamps resource with the batch's ID, unless it kept one from
+// an earlier attempt. id holds the batch's ID and is created on first use.
+func (p *checksumProcessor) stampBatchID(resource pcommon.Map, id *string) {
+	if p.batchIDAttribute == "" {
+		return
+	}
+	if _, ok := resource.Get(p.batchIDAttribute); ok {
+		return
+	}
+	if *id == "" {
+		*id = newBatchID()
+	}
+	resource.PutStr(p.batchIDAttribute, *id)
+}
+
+// newBatchID returns a random 128-bit ID, hex encoded
+func newBatchID() string {
+	var b [16]byte
+	// The system's random source does not fail on supported platforms
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// processLogs stamps each log record
+func (p *checksumProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	var batchID string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		p.stampBatchID(rl.Resource().Attributes(), &batchID)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				lr := records.At(k)
+				body := lr.Body()
+				timestamp := lr.Timestamp()
+				if timestamp == 0 {
+					timestamp = lr.ObservedTimestamp()
+				}
+				lr.Attributes().PutStr(p.attribute, p.checksum(record{
+					resource:     rl.Resource().Attributes(),
+					attributes:   lr.Attributes(),
+					timestamp:    timestamp,
+					body:         &body,
+					severityText: lr.SeverityText(),
+				}))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processMetrics stamps each data point. Metric batches get no ID: as a
+// resource attribute a new value per batch would start new time series.
+func (p *checksumProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := rm.Resource().Attributes()
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				p.stampDataPoints(resource, metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+// stampDataPoints stamps each data point of metric
+func (p *checksumProcessor) stampDataPoints(resource pcommon.Map, metric pmetric.Metric) {
+	stamp := func(attributes pcommon.Map, timestamp pcommon.Timestamp, value string) {
+		attributes.PutStr(p.attribute, p.checksum(record{
+			resource:   resource,
+			attributes: attributes,
+			timestamp:  timestamp,
+			name:       metric.Name(),
+			value:      value,
+		}))
+	}
+
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			stamp(dp.Attributes(), dp.Timestamp(), numberValue(dp))
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			stamp(dp.Attributes(), dp.Timestamp(), numberValue(dp))
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			stamp(dp.Attributes(), dp.Timestamp(), countSumValue(dp.Count(), dp.Sum()))
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			stamp(dp.Attributes(), dp.Timestamp(), countSumValue(dp.Count(), dp.Sum()))
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			stamp(dp.Attributes(), dp.Timestamp(), countSumValue(dp.Count(), dp.Sum()))
+		}
+	}
+}
+
+// numberValue formats a number data point's value, in the shortest form
+// that reads back as the same number
+func numberValue(dp pmetric.NumberDataPoint) string {
+	switch dp.ValueType() {
+	case pmetric.NumberDataPointValueTypeInt:
+		return strconv.FormatInt(dp.IntValue(), 10)
+	case pmetric.NumberDataPointValueTypeDouble:
+		return strconv.FormatFloat(dp.DoubleValue(), 'g', -1, 64)
+	}
+	return ""
+}
+
+// countSumValue formats a histogram or summary as <count>/<sum>
+func countSumValue(count uint64, sum float64) string {
+	return strconv.FormatUint(count, 10) + "/" + strconv.FormatFloat(sum, 'g', -1, 64)
+}
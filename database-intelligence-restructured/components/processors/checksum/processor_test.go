@@ -0,0 +1,286 @@
+package checksum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+// newLogs returns a batch with one query log record
+func newLogs(statement string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "postgres")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.Timestamp(1714979289000000000))
+	lr.Body().SetStr("duration: 12.5 ms")
+	lr.SetSeverityText("LOG")
+	lr.Attributes().PutStr("db.statement", statement)
+	lr.Attributes().PutInt("db.query.calls", 3)
+	return ld
+}
+
+// logChecksum runs ld through a logs processor and returns the stamped
+// checksum of its first record
+func logChecksum(t *testing.T, cfg *Config, ld plog.Logs) string {
+	t.Helper()
+	sink := &consumertest.LogsSink{}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+
+	v, ok := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get(cfg.Attribute)
+	require.True(t, ok, "the record must be stamped")
+	return v.Str()
+}
+
+func TestIdenticalRecordsHaveIdenticalChecksums(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	first := logChecksum(t, cfg, newLogs("SELECT * FROM orders WHERE id = $1"))
+	second := logChecksum(t, cfg, newLogs("SELECT * FROM orders WHERE id = $1"))
+	assert.Equal(t, first, second)
+	assert.Len(t, first, sha256.Size*2)
+
+	// Attributes set in another order hash the same
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "postgres")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.Timestamp(1714979289000000000))
+	lr.Body().SetStr("duration: 12.5 ms")
+	lr.SetSeverityText("LOG")
+	lr.Attributes().PutInt("db.query.calls", 3)
+	lr.Attributes().PutStr("db.statement", "SELECT * FROM orders WHERE id = $1")
+	assert.Equal(t, first, logChecksum(t, cfg, ld))
+
+	// Stamping again gives the same checksum, as the checksum attribute is
+	// not covered
+	stamped := newLogs("SELECT * FROM orders WHERE id = $1")
+	stamped.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutStr(DefaultAttribute, first)
+	assert.Equal(t, first, logChecksum(t, cfg, stamped))
+}
+
+func TestChangedFieldChangesChecksum(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	original := logChecksum(t, cfg, newLogs("SELECT * FROM orders WHERE id = $1"))
+
+	changed := logChecksum(t, cfg, newLogs("SELECT * FROM orders WHERE id = $2"))
+	assert.NotEqual(t, original, changed, "a changed attribute must change the checksum")
+
+	ld := newLogs("SELECT * FROM orders WHERE id = $1")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().SetStr("duration: 12.6 ms")
+	assert.NotEqual(t, original, logChecksum(t, cfg, ld), "a changed body must change the checksum")
+
+	ld = newLogs("SELECT * FROM orders WHERE id = $1")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutInt("db.query.calls", 4)
+	assert.NotEqual(t, original, logChecksum(t, cfg, ld), "a changed value type must change the checksum")
+
+	// Fields not covered do not
+	ld = newLogs("SELECT * FROM orders WHERE id = $1")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SetSeverityText("ERROR")
+	ld.ResourceLogs().At(0).Resource().Attributes().PutStr("host.name", "db-1")
+	assert.Equal(t, original, logChecksum(t, cfg, ld))
+}
+
+func TestSelectedFields(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Fields = []string{"attributes.db.statement", "resource.service.name", "severity_text", "timestamp"}
+
+	// The canonical form can be rebuilt outside the collector
+	want := sha256.Sum256([]byte(
+		`attributes.db.statement="SELECT 1 WHERE a < b"` + "\n" +
+			`resource.service.name="postgres"` + "\n" +
+			`severity_text="LOG"` + "\n" +
+			`timestamp="1714979289000000000"` + "\n"))
+	assert.Equal(t, hex.EncodeToString(want[:]), logChecksum(t, cfg, newLogs("SELECT 1 WHERE a < b")))
+
+	original := logChecksum(t, cfg, newLogs("SELECT 1"))
+	ld := newLogs("SELECT 1")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().SetStr("changed")
+	assert.Equal(t, original, logChecksum(t, cfg, ld), "the body is not covered")
+
+	ld = newLogs("SELECT 1")
+	ld.ResourceLogs().At(0).Resource().Attributes().PutStr("service.name", "replica")
+	assert.NotEqual(t, original, logChecksum(t, cfg, ld))
+
+	// A missing attribute is left out rather than hashed as empty
+	ld = newLogs("SELECT 1")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Remove("db.statement")
+	missing := logChecksum(t, cfg, ld)
+	ld = newLogs("")
+	assert.NotEqual(t, missing, logChecksum(t, cfg, ld))
+}
+
+func TestAlgorithms(t *testing.T) {
+	lengths := map[string]int{
+		AlgorithmSHA256:  64,
+		AlgorithmSHA512:  128,
+		AlgorithmMD5:     32,
+		AlgorithmFNV1a64: 16,
+	}
+	for algorithm, length := range lengths {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Algorithm = algorithm
+		assert.Len(t, logChecksum(t, cfg, newLogs("SELECT 1")), length, algorithm)
+	}
+}
+
+func TestMetricDataPointChecksums(t *testing.T) {
+	newMetrics := func(value float64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+		gauge := metrics.AppendEmpty()
+		gauge.SetName("db.query.duration.avg")
+		dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetDoubleValue(value)
+		dp.Attributes().PutStr("db.name", "shop")
+		histogram := metrics.AppendEmpty()
+		histogram.SetName("db.query.duration")
+		hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+		hdp.SetCount(4)
+		hdp.SetSum(value * 4)
+		return md
+	}
+	checksums := func(md pmetric.Metrics) []string {
+		sink := &consumertest.MetricsSink{}
+		mp, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), createDefaultConfig(), sink)
+		require.NoError(t, err)
+		require.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+
+		metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		gauge, _ := metrics.At(0).Gauge().DataPoints().At(0).Attributes().Get(DefaultAttribute)
+		histogram, _ := metrics.At(1).Histogram().DataPoints().At(0).Attributes().Get(DefaultAttribute)
+		return []string{gauge.Str(), histogram.Str()}
+	}
+
+	original := checksums(newMetrics(12.5))
+	assert.Equal(t, original, checksums(newMetrics(12.5)))
+	assert.NotEqual(t, original[0], original[1])
+
+	changed := checksums(newMetrics(12.75))
+	assert.NotEqual(t, original[0], changed[0])
+	assert.NotEqual(t, original[1], changed[1])
+}
+
+func TestRetriedBatchKeepsItsID(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Fields = append(cfg.Fields, FieldResource)
+	cfg.BatchIDAttribute = "telemetry.batch_id"
+
+	// The exporter fails twice before the batch goes through
+	var attempts []plog.Logs
+	next, err := consumer.NewLogs(func(_ context.Context, ld plog.Logs) error {
+		copied := plog.NewLogs()
+		ld.CopyTo(copied)
+		attempts = append(attempts, copied)
+		if len(attempts) < 3 {
+			return errors.New("export partially failed")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, next)
+	require.NoError(t, err)
+
+	// The sender retries the same batch until it is accepted
+	ld := newLogs("SELECT * FROM orders WHERE id = $1")
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().PutStr("service.name", "mysql")
+	for attempt := 1; lp.ConsumeLogs(context.Background(), ld) != nil; attempt++ {
+		require.Less(t, attempt, 3)
+	}
+	require.Len(t, attempts, 3)
+
+	batchIDs := func(ld plog.Logs) []string {
+		var ids []string
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			v, ok := ld.ResourceLogs().At(i).Resource().Attributes().Get(cfg.BatchIDAttribute)
+			require.True(t, ok, "every resource must be stamped")
+			ids = append(ids, v.Str())
+		}
+		return ids
+	}
+	first := batchIDs(attempts[0])
+	assert.Len(t, first[0], 32)
+	assert.Equal(t, first[0], first[1], "the resources of a batch share its ID")
+	for _, attempt := range attempts[1:] {
+		assert.Equal(t, first, batchIDs(attempt), "a retried batch keeps its original ID")
+	}
+
+	// The batch ID does not change the record checksums, so replayed
+	// records still match
+	checksum := func(ld plog.Logs) pcommon.Value {
+		v, _ := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get(cfg.Attribute)
+		return v
+	}
+	assert.Equal(t, checksum(attempts[0]), checksum(attempts[2]))
+	cfg.BatchIDAttribute = ""
+	assert.Equal(t, checksum(attempts[0]).Str(), logChecksum(t, cfg, newLogs("SELECT * FROM orders WHERE id = $1")))
+
+	// A new batch gets a new ID
+	cfg.BatchIDAttribute = "telemetry.batch_id"
+	require.NoError(t, lp.ConsumeLogs(context.Background(), newLogs("SELECT 1")))
+	require.Len(t, attempts, 4)
+	assert.NotEqual(t, first[0], batchIDs(attempts[3])[0])
+}
+
+func TestMetricsGetNoBatchID(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.BatchIDAttribute = "telemetry.batch_id"
+	sink := &consumertest.MetricsSink{}
+	mp, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+
+	// A new value per batch would make every batch a new time series
+	_, ok := sink.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes().Get(cfg.BatchIDAttribute)
+	assert.False(t, ok)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(cfg *Config) {}},
+		{name: "no fields", modify: func(cfg *Config) { cfg.Fields = nil }, wantErr: "fields must not be empty"},
+		{name: "unknown field", modify: func(cfg *Config) { cfg.Fields = []string{"attributes."} },
+			wantErr: `fields: unknown field "attributes.", must be name, value, timestamp, body, severity_text, attributes, attributes.<key>, resource or resource.<key>`},
+		{name: "duplicate field", modify: func(cfg *Config) { cfg.Fields = []string{"body", "body"} }, wantErr: `fields: "body" is listed twice`},
+		{name: "unknown algorithm", modify: func(cfg *Config) { cfg.Algorithm = "crc32" },
+			wantErr: `algorithm must be one of sha256, sha512, md5 or fnv1a_64, got "crc32"`},
+		{name: "no attribute", modify: func(cfg *Config) { cfg.Attribute = "" }, wantErr: "attribute must be specified"},
+		{name: "covers itself", modify: func(cfg *Config) { cfg.Fields = []string{"attributes.telemetry.checksum"} },
+			wantErr: `fields: the checksum cannot cover its own attribute "telemetry.checksum"`},
+		{name: "covers the batch ID", modify: func(cfg *Config) {
+			cfg.Fields = []string{"resource.telemetry.batch_id"}
+			cfg.BatchIDAttribute = "telemetry.batch_id"
+		}, wantErr: `fields: the checksum cannot cover the batch ID attribute "telemetry.batch_id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -56,6 +56,34 @@ type Config struct {
 	
 	// QueryFallbacks define fallback queries for primary queries
 	QueryFallbacks map[string]string `mapstructure:"query_fallbacks"`
+
+	// ManualOverrideTTL is how long a circuit forced open or closed through
+	// the control API stays forced when no duration is given
+	ManualOverrideTTL time.Duration `mapstructure:"manual_override_ttl"`
+
+	// MaxManualOverrideTTL caps the duration of a manual override, so a
+	// forgotten override cannot disable a circuit indefinitely
+	MaxManualOverrideTTL time.Duration `mapstructure:"max_manual_override_ttl"`
+
+	// OpenCircuit defines what happens to data while the circuit is open
+	OpenCircuit OpenCircuitConfig `mapstructure:"open_circuit"`
+}
+
+// OpenCircuitConfig defines the policy for data arriving while the global
+// circuit is open
+type OpenCircuitConfig struct {
+	// Action is "drop" (reject the data), "route_to_secondary" (send it to
+	// SecondaryExporter) or "buffer" (hold it and replay it once the circuit
+	// closes)
+	Action string `mapstructure:"action"`
+
+	// SecondaryExporter is the logs exporter used by route_to_secondary. It
+	// must be part of a logs pipeline.
+	SecondaryExporter component.ID `mapstructure:"secondary_exporter"`
+
+	// BufferSize is the maximum number of batches held by buffer. Batches
+	// arriving when the buffer is full are rejected.
+	BufferSize int `mapstructure:"buffer_size"`
 }
 
 // ErrorPatternConfig defines configuration for error pattern matching
@@ -109,6 +137,38 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("cpu_threshold_percent must be between 0 and 100, got: %f", cfg.CPUThresholdPercent)
 	}
 
+	if cfg.ManualOverrideTTL <= 0 {
+		return fmt.Errorf("manual_override_ttl must be positive, got: %v", cfg.ManualOverrideTTL)
+	}
+
+	if cfg.MaxManualOverrideTTL < cfg.ManualOverrideTTL {
+		return fmt.Errorf("max_manual_override_ttl (%v) cannot be less than manual_override_ttl (%v)", cfg.MaxManualOverrideTTL, cfg.ManualOverrideTTL)
+	}
+
+	if err := cfg.OpenCircuit.Validate(); err != nil {
+		return fmt.Errorf("invalid open_circuit: %w", err)
+	}
+
+	return nil
+}
+
+// Validate checks the open circuit policy
+func (oc *OpenCircuitConfig) Validate() error {
+	switch oc.Action {
+	case "", OpenCircuitDrop:
+	case OpenCircuitRouteToSecondary:
+		if oc.SecondaryExporter == (component.ID{}) {
+			return fmt.Errorf("secondary_exporter must be specified for action %q", oc.Action)
+		}
+	case OpenCircuitBuffer:
+		if oc.BufferSize <= 0 {
+			return fmt.Errorf("buffer_size must be positive for action %q, got: %d", oc.Action, oc.BufferSize)
+		}
+	default:
+		return fmt.Errorf("action must be '%s', '%s', or '%s', got: %q",
+			OpenCircuitDrop, OpenCircuitRouteToSecondary, OpenCircuitBuffer, oc.Action)
+	}
+
 	return nil
 }
 
@@ -126,6 +186,8 @@ func CreateDefaultConfig() component.Config {
 		MemoryThresholdMB:     512, // 512MB
 		CPUThresholdPercent:   80.0, // 80%
 		EnableDebugLogging:    false,
+		ManualOverrideTTL:     time.Hour,
+		MaxManualOverrideTTL:  24 * time.Hour,
 		NewRelicErrorPatterns: []string{
 			"cardinality",
 			"NrIntegrationError",
@@ -134,5 +196,9 @@ func CreateDefaultConfig() component.Config {
 			"quota exceeded",
 			"unique time series",
 		},
+		OpenCircuit: OpenCircuitConfig{
+			Action:     OpenCircuitDrop,
+			BufferSize: 1000,
+		},
 	}
 }
\ No newline at end of file
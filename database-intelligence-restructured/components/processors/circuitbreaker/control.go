@@ -0,0 +1,187 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// circuitController matches the healthcheck extension's CircuitController.
+// Both are unnamed interfaces over standard library types, so they are
+// identical and the processor does not need to import the extension.
+type circuitController = interface {
+	CircuitStates() []map[string]any
+	ForceCircuit(circuit, state string, ttl time.Duration, reason string) (time.Time, error)
+	ResetCircuit(circuit string) bool
+}
+
+// circuitRegistrar is implemented by extensions that expose circuit controls
+type circuitRegistrar interface {
+	RegisterCircuitController(name string, controller circuitController)
+}
+
+// manualOverride is a circuit state forced through the control API. It
+// expires so the automatic logic always takes over again.
+type manualOverride struct {
+	state   State
+	reason  string
+	setAt   time.Time
+	expires time.Time
+}
+
+// ForceCircuit forces the circuit of a database open or closed for ttl.
+// A zero ttl uses manual_override_ttl. The automatic state keeps being
+// tracked while the override is in place and applies again once it expires
+// or is reset.
+func (p *circuitBreakerProcessor) ForceCircuit(circuit, state string, ttl time.Duration, reason string) (time.Time, error) {
+	if circuit == "" {
+		return time.Time{}, fmt.Errorf("circuit name is required")
+	}
+
+	var forced State
+	switch state {
+	case "open":
+		forced = Open
+	case "closed":
+		forced = Closed
+	default:
+		return time.Time{}, fmt.Errorf("state must be open or closed, got: %q", state)
+	}
+
+	switch {
+	case ttl < 0:
+		return time.Time{}, fmt.Errorf("duration cannot be negative, got: %v", ttl)
+	case ttl == 0:
+		ttl = p.config.ManualOverrideTTL
+	case ttl > p.config.MaxManualOverrideTTL:
+		return time.Time{}, fmt.Errorf("duration %v exceeds max_manual_override_ttl (%v)", ttl, p.config.MaxManualOverrideTTL)
+	}
+
+	now := p.now()
+	override := &manualOverride{
+		state:   forced,
+		reason:  reason,
+		setAt:   now,
+		expires: now.Add(ttl),
+	}
+
+	p.overridesMutex.Lock()
+	p.overrides[circuit] = override
+	p.overridesMutex.Unlock()
+
+	p.logger.Warn("Database circuit breaker manually overridden",
+		zap.String("database", circuit),
+		zap.String("state", forced.String()),
+		zap.Time("expires", override.expires),
+		zap.String("reason", reason))
+
+	return override.expires, nil
+}
+
+// ResetCircuit removes the manual override of a database, returning control
+// to the automatic logic. It reports whether there was an override.
+func (p *circuitBreakerProcessor) ResetCircuit(circuit string) bool {
+	p.overridesMutex.Lock()
+	_, exists := p.overrides[circuit]
+	delete(p.overrides, circuit)
+	p.overridesMutex.Unlock()
+
+	if exists {
+		p.logger.Info("Database circuit breaker manual override cleared",
+			zap.String("database", circuit))
+	}
+	return exists
+}
+
+// CircuitStates reports every per-database circuit the processor knows of,
+// automatic or manually overridden, sorted by name
+func (p *circuitBreakerProcessor) CircuitStates() []map[string]any {
+	circuits := make(map[string]map[string]any)
+
+	p.dbStatesMutex.RLock()
+	for dbName, state := range p.databaseStates {
+		state.mutex.RLock()
+		circuits[dbName] = map[string]any{
+			"circuit":         dbName,
+			"state":           state.state.String(),
+			"automatic_state": state.state.String(),
+			"failure_count":   state.failureCount,
+			"error_rate":      state.errorRate,
+		}
+		state.mutex.RUnlock()
+	}
+	p.dbStatesMutex.RUnlock()
+
+	now := p.now()
+	p.overridesMutex.Lock()
+	for dbName, override := range p.overrides {
+		if !now.Before(override.expires) {
+			continue
+		}
+		circuit, exists := circuits[dbName]
+		if !exists {
+			circuit = map[string]any{
+				"circuit":         dbName,
+				"automatic_state": Closed.String(),
+			}
+			circuits[dbName] = circuit
+		}
+		circuit["state"] = override.state.String()
+		circuit["override"] = map[string]any{
+			"state":      override.state.String(),
+			"reason":     override.reason,
+			"set_at":     override.setAt,
+			"expires_at": override.expires,
+		}
+	}
+	p.overridesMutex.Unlock()
+
+	names := make([]string, 0, len(circuits))
+	for name := range circuits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	states := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		states = append(states, circuits[name])
+	}
+	return states
+}
+
+// manualState returns the state a database's circuit is forced to, if any.
+// An expired override is removed.
+func (p *circuitBreakerProcessor) manualState(dbName string) (State, bool) {
+	p.overridesMutex.Lock()
+	defer p.overridesMutex.Unlock()
+
+	override, exists := p.overrides[dbName]
+	if !exists {
+		return Closed, false
+	}
+	if !p.now().Before(override.expires) {
+		delete(p.overrides, dbName)
+		p.logger.Info("Database circuit breaker manual override expired",
+			zap.String("database", dbName),
+			zap.String("state", override.state.String()))
+		return Closed, false
+	}
+	return override.state, true
+}
+
+// registerCircuitController registers the processor with every host extension that exposes circuit controls
+func (p *circuitBreakerProcessor) registerCircuitController(host component.Host) {
+	if host == nil {
+		return
+	}
+
+	for id, ext := range host.GetExtensions() {
+		if registrar, ok := ext.(circuitRegistrar); ok {
+			registrar.RegisterCircuitController(p.name, p)
+			p.logger.Debug("Registered circuit controls", zap.String("extension", id.String()))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// fakeClock lets tests move past an override's expiry
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newControlTestProcessor(t *testing.T) (*circuitBreakerProcessor, *consumertest.LogsSink, *fakeClock) {
+	cfg := CreateDefaultConfig().(*Config)
+	sink := &consumertest.LogsSink{}
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	processor := newCircuitBreakerProcessor(cfg, zap.NewNop(), sink)
+	processor.now = clock.Now
+	require.NoError(t, processor.Start(context.Background(), nil))
+	t.Cleanup(func() { processor.Shutdown(context.Background()) })
+
+	return processor, sink, clock
+}
+
+// createDatabaseLogs creates one record per database, tagged the way the
+// processor keys its per-database circuits
+func createDatabaseLogs(dbNames ...string) plog.Logs {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, dbName := range dbNames {
+		lr := records.AppendEmpty()
+		lr.Attributes().PutStr("database_name", dbName)
+		lr.Body().SetStr("query stats")
+	}
+	return logs
+}
+
+func TestCircuitBreaker_ManualOpenDropsUntilExpiry(t *testing.T) {
+	processor, sink, clock := newControlTestProcessor(t)
+
+	expires, err := processor.ForceCircuit("orders", "open", 10*time.Minute, "maintenance")
+	require.NoError(t, err)
+	assert.Equal(t, clock.now.Add(10*time.Minute), expires)
+
+	// Records for the forced database are dropped, others pass
+	require.NoError(t, processor.ConsumeLogs(context.Background(), createDatabaseLogs("orders", "billing")))
+	require.Equal(t, 1, sink.LogRecordCount())
+	db, _ := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("database_name")
+	assert.Equal(t, "billing", db.Str())
+
+	states := processor.CircuitStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "orders", states[0]["circuit"])
+	assert.Equal(t, "open", states[0]["state"])
+	assert.Equal(t, "closed", states[0]["automatic_state"])
+
+	// Once the override expires the automatic logic, still closed, applies
+	clock.now = expires
+	sink.Reset()
+	require.NoError(t, processor.ConsumeLogs(context.Background(), createDatabaseLogs("orders", "billing")))
+	assert.Equal(t, 2, sink.LogRecordCount())
+	assert.Empty(t, processor.CircuitStates())
+}
+
+func TestCircuitBreaker_ManualCloseOverridesAutomaticOpen(t *testing.T) {
+	processor, sink, clock := newControlTestProcessor(t)
+
+	for i := 0; i < processor.config.FailureThreshold; i++ {
+		processor.onDatabaseFailure("orders", assert.AnError, time.Millisecond)
+	}
+	require.False(t, processor.allowDatabaseRequest("orders"))
+
+	_, err := processor.ForceCircuit("orders", "closed", 0, "")
+	require.NoError(t, err)
+	require.NoError(t, processor.ConsumeLogs(context.Background(), createDatabaseLogs("orders")))
+	assert.Equal(t, 1, sink.LogRecordCount())
+
+	states := processor.CircuitStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "closed", states[0]["state"])
+	assert.Equal(t, "open", states[0]["automatic_state"])
+	override := states[0]["override"].(map[string]any)
+	assert.Equal(t, clock.now.Add(processor.config.ManualOverrideTTL), override["expires_at"])
+
+	// Resetting returns control to the automatic logic
+	assert.True(t, processor.ResetCircuit("orders"))
+	assert.False(t, processor.ResetCircuit("orders"))
+	assert.False(t, processor.allowDatabaseRequest("orders"))
+}
+
+func TestCircuitBreaker_ForceCircuitValidation(t *testing.T) {
+	processor, _, _ := newControlTestProcessor(t)
+
+	_, err := processor.ForceCircuit("", "open", time.Minute, "")
+	assert.Error(t, err)
+
+	_, err = processor.ForceCircuit("orders", "half-open", time.Minute, "")
+	assert.Error(t, err)
+
+	_, err = processor.ForceCircuit("orders", "open", processor.config.MaxManualOverrideTTL+time.Second, "")
+	assert.Error(t, err)
+
+	assert.Empty(t, processor.CircuitStates())
+}
@@ -60,10 +60,19 @@ func createLogsProcessor(
 		zap.Float64("cpu_threshold_percent", processorConfig.CPUThresholdPercent),
 		zap.Bool("debug_logging", processorConfig.EnableDebugLogging),
 		zap.Int("error_patterns", len(processorConfig.ErrorPatterns)),
-		zap.Int("query_fallbacks", len(processorConfig.QueryFallbacks)))
+		zap.Int("query_fallbacks", len(processorConfig.QueryFallbacks)),
+		zap.Duration("manual_override_ttl", processorConfig.ManualOverrideTTL),
+		zap.String("open_circuit_action", processorConfig.OpenCircuit.Action))
 	
 	// Create and return the processor
 	processor := newCircuitBreakerProcessor(processorConfig, logger, nextConsumer)
+	processor.name = set.ID.String()
+	
+	// The secondary exporter only exists once the pipelines are built, so
+	// it is looked up when the processor starts
+	if processorConfig.OpenCircuit.Action == OpenCircuitRouteToSecondary {
+		processor.resolveSecondary = secondaryExporterFromHost(processorConfig.OpenCircuit.SecondaryExporter)
+	}
 	
 	return processor, nil
 }
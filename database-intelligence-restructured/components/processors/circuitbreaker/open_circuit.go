@@ -0,0 +1,195 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Open circuit actions
+const (
+	OpenCircuitDrop             = "drop"
+	OpenCircuitRouteToSecondary = "route_to_secondary"
+	OpenCircuitBuffer           = "buffer"
+)
+
+// errCircuitOpen is returned for data rejected while the circuit is open
+var errCircuitOpen = errors.New("circuit breaker open")
+
+// OpenCircuitStats counts what happened to batches arriving while the
+// circuit was open
+type OpenCircuitStats struct {
+	Dropped         int64
+	Routed          int64
+	RouteFailures   int64
+	Buffered        int64
+	BufferOverflows int64
+	Replayed        int64
+}
+
+// openCircuitHandler applies the open circuit policy
+type openCircuitHandler struct {
+	action     string
+	bufferSize int
+
+	// secondary receives data with route_to_secondary; it is resolved from
+	// the host on start
+	secondary consumer.Logs
+
+	mutex  sync.Mutex
+	buffer []bufferedBatch
+	stats  OpenCircuitStats
+}
+
+// bufferedBatch is a batch held while a circuit was open
+type bufferedBatch struct {
+	// database is the database whose circuit rejected the batch, or empty
+	// when the global circuit did
+	database string
+	logs     plog.Logs
+}
+
+func newOpenCircuitHandler(cfg OpenCircuitConfig) *openCircuitHandler {
+	action := cfg.Action
+	if action == "" {
+		action = OpenCircuitDrop
+	}
+	return &openCircuitHandler{
+		action:     action,
+		bufferSize: cfg.BufferSize,
+	}
+}
+
+// handle applies the policy to a batch rejected by the open circuit of
+// database, or by the global circuit when database is empty
+func (h *openCircuitHandler) handle(ctx context.Context, database string, logs plog.Logs) error {
+	switch h.action {
+	case OpenCircuitRouteToSecondary:
+		err := h.secondary.ConsumeLogs(ctx, logs)
+		h.mutex.Lock()
+		if err != nil {
+			h.stats.RouteFailures++
+		} else {
+			h.stats.Routed++
+		}
+		h.mutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("%w: secondary exporter failed: %v", errCircuitOpen, err)
+		}
+		return nil
+
+	case OpenCircuitBuffer:
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		if len(h.buffer) >= h.bufferSize {
+			h.stats.BufferOverflows++
+			return fmt.Errorf("%w: buffer full (%d batches)", errCircuitOpen, h.bufferSize)
+		}
+		// The caller may reuse the batch once we return
+		buffered := plog.NewLogs()
+		logs.CopyTo(buffered)
+		h.buffer = append(h.buffer, bufferedBatch{database: database, logs: buffered})
+		h.stats.Buffered++
+		return nil
+
+	default:
+		h.mutex.Lock()
+		h.stats.Dropped++
+		h.mutex.Unlock()
+		return errCircuitOpen
+	}
+}
+
+// buffered returns the number of batches waiting for replay
+func (h *openCircuitHandler) buffered() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.buffer)
+}
+
+// replay sends the buffered batches to next in arrival order. A batch
+// rejected by a database circuit is only sent once ready reports that
+// database's circuit closed; a nil ready sends every batch. It stops at the
+// first failure and keeps that batch, the ones after it and the ones not
+// ready for the next replay.
+func (h *openCircuitHandler) replay(ctx context.Context, next consumer.Logs, ready func(database string) bool) error {
+	h.mutex.Lock()
+	pending := h.buffer
+	h.buffer = nil
+	h.mutex.Unlock()
+
+	var held []bufferedBatch
+	for i, batch := range pending {
+		if batch.database != "" && ready != nil && !ready(batch.database) {
+			held = append(held, batch)
+			continue
+		}
+		if err := next.ConsumeLogs(ctx, batch.logs); err != nil {
+			h.requeue(append(held, pending[i:]...))
+			return err
+		}
+		h.mutex.Lock()
+		h.stats.Replayed++
+		h.mutex.Unlock()
+	}
+	if len(held) > 0 {
+		h.requeue(held)
+	}
+	return nil
+}
+
+// requeue puts batches that were not replayed back in front of the batches
+// buffered meanwhile, dropping the newest ones beyond the buffer size
+func (h *openCircuitHandler) requeue(batches []bufferedBatch) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	buffer := make([]bufferedBatch, 0, len(batches)+len(h.buffer))
+	buffer = append(buffer, batches...)
+	buffer = append(buffer, h.buffer...)
+	if len(buffer) > h.bufferSize {
+		h.stats.BufferOverflows += int64(len(buffer) - h.bufferSize)
+		buffer = buffer[:h.bufferSize]
+	}
+	h.buffer = buffer
+}
+
+// snapshot returns the current counts
+func (h *openCircuitHandler) snapshot() OpenCircuitStats {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.stats
+}
+
+// exportersHost is implemented by the collector's service host. It is not
+// part of component.Host, so it is checked for at runtime.
+type exportersHost interface {
+	GetExporters() map[component.DataType]map[component.ID]component.Component
+}
+
+// secondaryExporterFromHost returns a resolver for the logs exporter that
+// receives data while the circuit is open
+func secondaryExporterFromHost(id component.ID) func(host component.Host) (consumer.Logs, error) {
+	return func(host component.Host) (consumer.Logs, error) {
+		eh, ok := host.(exportersHost)
+		if !ok {
+			return nil, fmt.Errorf("host does not provide exporters, cannot route to secondary exporter %q", id)
+		}
+
+		exporter, ok := eh.GetExporters()[component.DataTypeLogs][id]
+		if !ok {
+			return nil, fmt.Errorf("secondary exporter %q is not part of a logs pipeline", id)
+		}
+
+		logsExporter, ok := exporter.(consumer.Logs)
+		if !ok {
+			return nil, fmt.Errorf("secondary exporter %q does not accept logs", id)
+		}
+		return logsExporter, nil
+	}
+}
@@ -14,7 +14,7 @@ import (
 )
 
 func TestNewCircuitBreaker(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	logger := zap.NewNop()
 	consumer := &consumertest.LogsSink{}
 	
@@ -23,7 +23,7 @@ func TestNewCircuitBreaker(t *testing.T) {
 }
 
 func TestCircuitBreaker_StateTransitions(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.FailureThreshold = 2
 	cfg.SuccessThreshold = 2
 	cfg.Timeout = time.Second
@@ -74,7 +74,7 @@ func TestCircuitBreaker_StateTransitions(t *testing.T) {
 }
 
 func TestCircuitBreaker_PerDatabaseIsolation(t *testing.T) {
-	cfg := createDefaultConfig().(*Config)
+	cfg := CreateDefaultConfig().(*Config)
 	cfg.FailureThreshold = 1
 	
 	logger := zap.NewNop()
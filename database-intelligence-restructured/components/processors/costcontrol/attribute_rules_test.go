@@ -0,0 +1,255 @@
+package costcontrol
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// createUniqueIDMetrics builds the metrics cmd/minimal sends to test
+// cardinality control: one series per unique_id
+func createUniqueIDMetrics(n int) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "test-db")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	for i := 0; i < n; i++ {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName("db.custom.metric")
+		dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetDoubleValue(float64(i))
+		dp.Attributes().PutStr("unique_id", strconv.Itoa(i))
+		dp.Attributes().PutStr("db.name", "testdb")
+	}
+	return metrics
+}
+
+// seriesCount counts distinct metric name and attribute set pairs
+func seriesCount(md pmetric.Metrics) int {
+	series := make(map[string]struct{})
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				forEachDataPointAttributes(metric, func(attrs pcommon.Map) {
+					series[metric.Name()+"|"+getAttributeKey(attrs)] = struct{}{}
+				})
+			}
+		}
+	}
+	return len(series)
+}
+
+func TestAttributeRules_DropCollapsesSeries(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.AttributeRules = []AttributeRule{
+		{Attribute: "unique_id", Action: AttributeActionDrop},
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.MetricsSink{}
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+	processor.nextMetrics = sink
+
+	metrics := createUniqueIDMetrics(10)
+	require.Equal(t, 10, seriesCount(metrics))
+
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), metrics))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	got := sink.AllMetrics()[0]
+	assert.Equal(t, 10, got.DataPointCount(), "data points are kept")
+	assert.Equal(t, 1, seriesCount(got))
+
+	attrs := got.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()
+	_, exists := attrs.Get("unique_id")
+	assert.False(t, exists)
+	_, exists = attrs.Get("db.name")
+	assert.True(t, exists)
+
+	assert.Equal(t, map[string]int64{"drop_unique_id": 9}, processor.attributeRuleReport())
+}
+
+func TestAttributeRules_Hash(t *testing.T) {
+	rules := newAttributeRules([]AttributeRule{
+		{Name: "hashed", Attribute: "unique_id", Action: AttributeActionHash},
+		{Name: "hash_buckets", Attribute: "db.name", Action: AttributeActionHash, HashBuckets: 4},
+	})
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr("unique_id", "42")
+	attrs.PutStr("db.name", "testdb")
+	rules[0].rewrite(attrs)
+	rules[1].rewrite(attrs)
+
+	hashed, _ := attrs.Get("unique_id")
+	assert.Len(t, hashed.Str(), 16)
+	assert.NotEqual(t, "42", hashed.Str())
+	assert.Equal(t, rules[0].hash("42"), hashed.Str(), "hash is stable")
+
+	bucket, _ := attrs.Get("db.name")
+	assert.Regexp(t, `^bucket_[0-3]$`, bucket.Str())
+}
+
+func TestAttributeRules_HashBucketsCollapseSeries(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.AttributeRules = []AttributeRule{
+		{Attribute: "unique_id", Action: AttributeActionHash, HashBuckets: 2},
+	}
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+
+	metrics := createUniqueIDMetrics(10)
+	processor.applyAttributeRules(processor.currentAttributeRules(), metrics)
+
+	assert.LessOrEqual(t, seriesCount(metrics), 2)
+	assert.GreaterOrEqual(t, processor.attributeRuleReport()["hash_unique_id"], int64(8))
+}
+
+func TestAttributeRules_Bucket(t *testing.T) {
+	rule := newAttributeRules([]AttributeRule{
+		{Attribute: "rows", Action: AttributeActionBucket, Buckets: []float64{10, 100, 1000}},
+	})[0]
+
+	tests := []struct {
+		name  string
+		value func(pcommon.Map)
+		want  string
+	}{
+		{name: "int", value: func(m pcommon.Map) { m.PutInt("rows", 5) }, want: "<=10"},
+		{name: "double on bound", value: func(m pcommon.Map) { m.PutDouble("rows", 100) }, want: "<=100"},
+		{name: "numeric string", value: func(m pcommon.Map) { m.PutStr("rows", "250.5") }, want: "<=1000"},
+		{name: "past last bound", value: func(m pcommon.Map) { m.PutInt("rows", 5000) }, want: ">1000"},
+		{name: "non-numeric string", value: func(m pcommon.Map) { m.PutStr("rows", "many") }, want: "many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			tt.value(attrs)
+			rule.rewrite(attrs)
+			got, _ := attrs.Get("rows")
+			assert.Equal(t, tt.want, got.AsString())
+		})
+	}
+}
+
+func TestAttributeRules_MetricFilter(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.AttributeRules = []AttributeRule{
+		{Attribute: "unique_id", Action: AttributeActionDrop, Metrics: []string{"db.other.metric"}},
+	}
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+
+	metrics := createUniqueIDMetrics(10)
+	processor.applyAttributeRules(processor.currentAttributeRules(), metrics)
+
+	assert.Equal(t, 10, seriesCount(metrics))
+	assert.Equal(t, int64(0), processor.attributeRuleReport()["drop_unique_id"])
+}
+
+func TestAttributeRules_ConcurrentProcessor(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.AttributeRules = []AttributeRule{
+		{Attribute: "unique_id", Action: AttributeActionDrop},
+	}
+
+	// The factory builds the concurrent processor
+	sink := &consumertest.MetricsSink{}
+	processor := NewConcurrentCostControlProcessor(zap.NewNop(), cfg, nil, sink, nil)
+	require.NoError(t, processor.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, processor.Shutdown(context.Background())) }()
+
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), createUniqueIDMetrics(10)))
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Equal(t, 1, seriesCount(sink.AllMetrics()[0]))
+}
+
+func TestAttributeRules_Reconfigure(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+
+	updated := CreateDefaultConfig().(*Config)
+	updated.AttributeRules = []AttributeRule{
+		{Attribute: "unique_id", Action: AttributeActionHash, HashBuckets: 2},
+	}
+	require.NoError(t, processor.Reconfigure(updated))
+
+	// The new rules apply to the next batch
+	metrics := createUniqueIDMetrics(10)
+	processor.applyAttributeRules(processor.currentAttributeRules(), metrics)
+	assert.LessOrEqual(t, seriesCount(metrics), 2)
+}
+
+func TestAttributeRulesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []AttributeRule
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rules: []AttributeRule{
+				{Attribute: "unique_id", Action: AttributeActionDrop},
+				{Attribute: "user.id", Action: AttributeActionHash, HashBuckets: 64},
+				{Attribute: "rows", Action: AttributeActionBucket, Buckets: []float64{10, 100, 1000}},
+			},
+		},
+		{
+			name:    "without attribute",
+			rules:   []AttributeRule{{Action: AttributeActionDrop}},
+			wantErr: "attribute_rules[0]: attribute must be set",
+		},
+		{
+			name:    "unknown action",
+			rules:   []AttributeRule{{Attribute: "unique_id", Action: "rename"}},
+			wantErr: `unknown action "rename"`,
+		},
+		{
+			name:    "negative hash buckets",
+			rules:   []AttributeRule{{Attribute: "user.id", Action: AttributeActionHash, HashBuckets: -1}},
+			wantErr: "hash_buckets must not be negative",
+		},
+		{
+			name:    "bucket action without buckets",
+			rules:   []AttributeRule{{Attribute: "rows", Action: AttributeActionBucket}},
+			wantErr: "buckets must be set",
+		},
+		{
+			name:    "unordered buckets",
+			rules:   []AttributeRule{{Attribute: "rows", Action: AttributeActionBucket, Buckets: []float64{100, 10}}},
+			wantErr: "buckets must be in ascending order",
+		},
+		{
+			name: "duplicate rule names",
+			rules: []AttributeRule{
+				{Attribute: "unique_id", Action: AttributeActionDrop},
+				{Attribute: "unique_id", Action: AttributeActionDrop, Metrics: []string{"db.custom.metric"}},
+			},
+			wantErr: `duplicate rule name "drop_unique_id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.AttributeRules = tt.rules
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -48,8 +48,105 @@ type Config struct {
 	
 	// EnableLogReduction enables log size reduction
 	EnableLogReduction bool `mapstructure:"enable_log_reduction"`
+	
+	// CardinalityMode is "enforce" to remove high cardinality dimensions
+	// from metrics over metric_cardinality_limit, or "observe" to only
+	// estimate the distinct series of each metric and report them, so the
+	// limit can be sized before it is enforced. Default: enforce
+	CardinalityMode string `mapstructure:"cardinality_mode"`
+	
+	// CardinalityWindow is the period each estimate covers in observe mode
+	CardinalityWindow time.Duration `mapstructure:"cardinality_window"`
+	
+	// CardinalityPrecision sets the estimator's sketch size to
+	// 2^precision bytes per metric; the standard error is about
+	// 1.04/sqrt(2^precision), 1.6% for the default of 12. Range 4-16
+	CardinalityPrecision int `mapstructure:"cardinality_precision"`
+	
+	// AttributeRules rewrite metric attributes before cardinality is counted,
+	// so series collapse instead of being shed
+	AttributeRules []AttributeRule `mapstructure:"attribute_rules"`
+	
+	// LedgerPath is a file month-to-date spend is saved to every reporting
+	// interval and restored from on start, so the monthly budget holds
+	// across restarts. Empty keeps spend in memory only.
+	LedgerPath string `mapstructure:"ledger_path"`
+}
+
+// AttributeRule drops, hashes or buckets one metric attribute
+type AttributeRule struct {
+	// Name identifies the rule in the cost report
+	// Default: <action>_<attribute>
+	Name string `mapstructure:"name"`
+	
+	// Attribute is the data point attribute the rule rewrites
+	Attribute string `mapstructure:"attribute"`
+	
+	// Action is drop, hash or bucket
+	Action string `mapstructure:"action"`
+	
+	// Metrics limits the rule to these metric names; empty applies it to all
+	Metrics []string `mapstructure:"metrics"`
+	
+	// HashBuckets, for the hash action, maps values onto this many buckets.
+	// When 0 every value is replaced by its own hash, which shortens values
+	// but keeps cardinality.
+	HashBuckets int `mapstructure:"hash_buckets"`
+	
+	// Buckets, for the bucket action, are the ascending upper bounds numeric
+	// values are grouped into
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+// Attribute rule actions
+const (
+	AttributeActionDrop   = "drop"
+	AttributeActionHash   = "hash"
+	AttributeActionBucket = "bucket"
+)
+
+// ruleName returns the name the rule is reported under
+func (r AttributeRule) ruleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Action + "_" + r.Attribute
 }
 
+// Validate checks an attribute rule
+func (r AttributeRule) Validate() error {
+	if r.Attribute == "" {
+		return fmt.Errorf("attribute must be set")
+	}
+	
+	switch r.Action {
+	case AttributeActionDrop:
+	case AttributeActionHash:
+		if r.HashBuckets < 0 {
+			return fmt.Errorf("hash_buckets must not be negative")
+		}
+	case AttributeActionBucket:
+		if len(r.Buckets) == 0 {
+			return fmt.Errorf("buckets must be set for the bucket action")
+		}
+		for i := 1; i < len(r.Buckets); i++ {
+			if r.Buckets[i] <= r.Buckets[i-1] {
+				return fmt.Errorf("buckets must be in ascending order")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action %q, must be drop, hash or bucket", r.Action)
+	}
+	
+	return nil
+}
+
+// Cardinality modes
+const (
+	CardinalityModeEnforce = "enforce"
+	CardinalityModeObserve = "observe"
+)
+
 // Validate checks the processor configuration
 func (cfg *Config) Validate() error {
 	if cfg.MonthlyBudgetUSD <= 0 {
@@ -76,6 +173,31 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("reporting_interval must be positive")
 	}
 	
+	switch cfg.CardinalityMode {
+	case "", CardinalityModeEnforce:
+	case CardinalityModeObserve:
+		if cfg.CardinalityWindow <= 0 {
+			return fmt.Errorf("cardinality_window must be positive in observe mode")
+		}
+		if cfg.CardinalityPrecision < minPrecision || cfg.CardinalityPrecision > maxPrecision {
+			return fmt.Errorf("cardinality_precision must be between %d and %d", minPrecision, maxPrecision)
+		}
+	default:
+		return fmt.Errorf("cardinality_mode must be %q or %q, got %q",
+			CardinalityModeEnforce, CardinalityModeObserve, cfg.CardinalityMode)
+	}
+	
+	names := make(map[string]bool, len(cfg.AttributeRules))
+	for i, rule := range cfg.AttributeRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("attribute_rules[%d]: %w", i, err)
+		}
+		if names[rule.ruleName()] {
+			return fmt.Errorf("attribute_rules[%d]: duplicate rule name %q", i, rule.ruleName())
+		}
+		names[rule.ruleName()] = true
+	}
+	
 	return nil
 }
 
@@ -90,5 +212,8 @@ func createDefaultConfig() component.Config {
 		ReportingInterval:     60 * time.Second,
 		AggressiveMode:        false,
 		DataPlusEnabled:       false,
+		CardinalityMode:       CardinalityModeEnforce,
+		CardinalityWindow:     10 * time.Minute,
+		CardinalityPrecision:  defaultPrecision,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,248 @@
+package costcontrol
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Sketch precision bounds; 2^precision registers of one byte each
+const (
+	minPrecision     = 4
+	maxPrecision     = 16
+	defaultPrecision = 12
+)
+
+// hllSketch is a HyperLogLog sketch estimating the number of distinct
+// hashes added to it in fixed memory
+type hllSketch struct {
+	precision uint8
+	registers []uint8
+}
+
+func newHLLSketch(precision uint8) *hllSketch {
+	return &hllSketch{precision: precision, registers: make([]uint8, 1<<precision)}
+}
+
+// add records a 64-bit hash. The top precision bits pick the register,
+// which keeps the longest run of leading zeros seen in the rest.
+func (s *hllSketch) add(hash uint64) {
+	index := hash >> (64 - s.precision)
+	// The guard bit caps the rank for a hash whose remaining bits are zero
+	rank := uint8(bits.LeadingZeros64(hash<<s.precision|1<<(s.precision-1))) + 1
+	if rank > s.registers[index] {
+		s.registers[index] = rank
+	}
+}
+
+// estimate returns the estimated number of distinct hashes, using linear
+// counting while registers are still empty, where it is more accurate
+func (s *hllSketch) estimate() uint64 {
+	m := float64(len(s.registers))
+	var alpha float64
+	switch len(s.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+
+	sum, zeros := 0.0, 0
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// cardinalityEstimator counts the distinct series of each metric over a
+// window for observe mode. Each metric has its own sketch, so memory stays
+// at 2^precision bytes per metric however many series it has, and the
+// sketches are dropped when the window is reported, so metrics that stop
+// reporting are forgotten after one window.
+type cardinalityEstimator struct {
+	mu          sync.Mutex
+	precision   uint8
+	sketches    map[string]*hllSketch
+	windowStart time.Time
+}
+
+func newCardinalityEstimator(precision int) *cardinalityEstimator {
+	if precision < minPrecision || precision > maxPrecision {
+		precision = defaultPrecision
+	}
+	return &cardinalityEstimator{
+		precision:   uint8(precision),
+		sketches:    make(map[string]*hllSketch),
+		windowStart: time.Now(),
+	}
+}
+
+// observe adds the series of every data point in md. A series is a
+// metric's resource attributes together with its data point attributes.
+func (e *cardinalityEstimator) observe(md pmetric.Metrics) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := getAttributeKey(rm.Resource().Attributes())
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				sketch, ok := e.sketches[metric.Name()]
+				if !ok {
+					sketch = newHLLSketch(e.precision)
+					e.sketches[metric.Name()] = sketch
+				}
+				forEachDataPointAttributes(metric, func(attrs pcommon.Map) {
+					sketch.add(seriesHash(resourceKey + "\x00" + getAttributeKey(attrs)))
+				})
+			}
+		}
+	}
+}
+
+// seriesHash hashes a series key with FNV-1a. FNV leaves similar keys,
+// such as ones differing in a trailing digit, close together in the high
+// bits the sketch uses, so the result is mixed with the murmur3 finalizer.
+func seriesHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// cardinalityEstimate is the estimated distinct series of one metric
+type cardinalityEstimate struct {
+	metric string
+	series uint64
+}
+
+// cardinalityReport holds the estimates of one window
+type cardinalityReport struct {
+	start, end time.Time
+	// estimates are sorted by descending series, then by metric name
+	estimates []cardinalityEstimate
+	total     uint64
+}
+
+// report returns the estimates of the window ending now and starts a new
+// window
+func (e *cardinalityEstimator) report(now time.Time) cardinalityReport {
+	e.mu.Lock()
+	sketches, start := e.sketches, e.windowStart
+	e.sketches, e.windowStart = make(map[string]*hllSketch), now
+	e.mu.Unlock()
+
+	report := cardinalityReport{start: start, end: now}
+	for name, sketch := range sketches {
+		series := sketch.estimate()
+		report.estimates = append(report.estimates, cardinalityEstimate{metric: name, series: series})
+		report.total += series
+	}
+	sort.Slice(report.estimates, func(i, j int) bool {
+		a, b := report.estimates[i], report.estimates[j]
+		if a.series != b.series {
+			return a.series > b.series
+		}
+		return a.metric < b.metric
+	})
+	return report
+}
+
+// metrics returns the report as telemetry: one gauge data point per
+// metric and the total over all metrics
+func (r cardinalityReport) metrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "otel-collector")
+	rm.Resource().Attributes().PutStr("collector.type", "cost-control")
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("otelcol/costcontrol")
+	start := pcommon.NewTimestampFromTime(r.start)
+	now := pcommon.NewTimestampFromTime(r.end)
+
+	perMetric := sm.Metrics().AppendEmpty()
+	perMetric.SetName("otelcol.costcontrol.cardinality.estimate")
+	perMetric.SetDescription("Estimated distinct series of a metric during the cardinality window")
+	perMetric.SetUnit("{series}")
+	gauge := perMetric.SetEmptyGauge()
+	for _, estimate := range r.estimates {
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(now)
+		dp.SetIntValue(int64(estimate.series))
+		dp.Attributes().PutStr("metric.name", estimate.metric)
+	}
+
+	total := sm.Metrics().AppendEmpty()
+	total.SetName("otelcol.costcontrol.cardinality.estimate.total")
+	total.SetDescription("Estimated distinct series of all metrics during the cardinality window")
+	total.SetUnit("{series}")
+	dp := total.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(now)
+	dp.SetIntValue(int64(r.total))
+
+	return md
+}
+
+// forEachDataPointAttributes calls fn with the attributes of each data
+// point of metric
+func forEachDataPointAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
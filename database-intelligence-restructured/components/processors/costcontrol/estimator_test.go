@@ -0,0 +1,154 @@
+package costcontrol
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestHLLSketchAccuracy(t *testing.T) {
+	for _, distinct := range []int{1, 10, 500, 10000, 200000} {
+		t.Run(fmt.Sprint(distinct), func(t *testing.T) {
+			sketch := newHLLSketch(defaultPrecision)
+			// Every series is seen twice; repeats must not count
+			for round := 0; round < 2; round++ {
+				for i := 0; i < distinct; i++ {
+					sketch.add(seriesHash(fmt.Sprintf("db.name=shop|query.id=%d", i)))
+				}
+			}
+
+			// Three standard errors of 1.04/sqrt(4096)
+			tolerance := math.Max(1, 0.05*float64(distinct))
+			assert.InDelta(t, distinct, sketch.estimate(), tolerance)
+		})
+	}
+}
+
+// seriesMetrics adds a gauge named name with one data point per series in
+// [from, to)
+func seriesMetrics(md pmetric.Metrics, resource, name string, from, to int) {
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", resource)
+	gauge := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	gauge.SetName(name)
+	dps := gauge.SetEmptyGauge().DataPoints()
+	for i := from; i < to; i++ {
+		dp := dps.AppendEmpty()
+		dp.SetIntValue(1)
+		dp.Attributes().PutStr("query.id", fmt.Sprint(i))
+	}
+}
+
+func TestCardinalityEstimatorReport(t *testing.T) {
+	estimator := newCardinalityEstimator(defaultPrecision)
+
+	// 8000 distinct query series over two overlapping batches, the same
+	// 20 from two resources, and one series without attributes
+	first, second := pmetric.NewMetrics(), pmetric.NewMetrics()
+	seriesMetrics(first, "collector", "db.query.duration", 0, 5000)
+	seriesMetrics(second, "collector", "db.query.duration", 3000, 8000)
+	seriesMetrics(first, "primary", "db.connections", 0, 20)
+	seriesMetrics(second, "replica", "db.connections", 0, 20)
+	seriesMetrics(second, "collector", "db.up", 0, 0)
+	second.ResourceMetrics().At(2).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().AppendEmpty()
+	estimator.observe(first)
+	estimator.observe(second)
+
+	end := time.Now()
+	report := estimator.report(end)
+	assert.Equal(t, end, report.end)
+	require.Len(t, report.estimates, 3)
+
+	assert.Equal(t, "db.query.duration", report.estimates[0].metric)
+	assert.InDelta(t, 8000, report.estimates[0].series, 400)
+	assert.Equal(t, "db.connections", report.estimates[1].metric)
+	assert.InDelta(t, 40, report.estimates[1].series, 1)
+	assert.Equal(t, cardinalityEstimate{metric: "db.up", series: 1}, report.estimates[2])
+	assert.Equal(t, report.estimates[0].series+report.estimates[1].series+1, report.total)
+
+	// The next window starts empty
+	next := estimator.report(end.Add(time.Minute))
+	assert.Empty(t, next.estimates)
+	assert.Equal(t, end, next.start)
+}
+
+func TestObserveModeKeepsDimensions(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.CardinalityMode = CardinalityModeObserve
+	cfg.MetricCardinalityLimit = 10
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.MetricsSink{}
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+	processor.nextMetrics = sink
+
+	md := pmetric.NewMetrics()
+	seriesMetrics(md, "collector", "db.query.duration", 0, 100)
+	for i := 0; i < 100; i++ {
+		md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(i).
+			Attributes().PutStr("user.id", fmt.Sprint(i))
+	}
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), md))
+
+	// Nothing is removed although the metric is over the limit
+	require.Len(t, sink.AllMetrics(), 1)
+	dp := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(99)
+	assert.Equal(t, map[string]any{"query.id": "99", "user.id": "99"}, dp.Attributes().AsRaw())
+
+	// The estimate is sent on as telemetry
+	require.NoError(t, processor.reportCardinality(context.Background()))
+	require.Len(t, sink.AllMetrics(), 2)
+	metrics := sink.AllMetrics()[1].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	perMetric := metrics.At(0)
+	assert.Equal(t, "otelcol.costcontrol.cardinality.estimate", perMetric.Name())
+	assert.Equal(t, "{series}", perMetric.Unit())
+	require.Equal(t, 1, perMetric.Gauge().DataPoints().Len())
+	estimate := perMetric.Gauge().DataPoints().At(0)
+	assert.Equal(t, map[string]any{"metric.name": "db.query.duration"}, estimate.Attributes().AsRaw())
+	assert.InDelta(t, 100, estimate.IntValue(), 2)
+
+	total := metrics.At(1)
+	assert.Equal(t, "otelcol.costcontrol.cardinality.estimate.total", total.Name())
+	assert.Equal(t, estimate.IntValue(), total.Gauge().DataPoints().At(0).IntValue())
+
+	// An empty window sends nothing
+	require.NoError(t, processor.reportCardinality(context.Background()))
+	assert.Len(t, sink.AllMetrics(), 2)
+}
+
+func TestEnforceModeDoesNotEstimate(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+	processor.nextMetrics = &consumertest.MetricsSink{}
+
+	md := pmetric.NewMetrics()
+	seriesMetrics(md, "collector", "db.query.duration", 0, 10)
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), md))
+	assert.Empty(t, processor.estimator.report(time.Now()).estimates)
+}
+
+func TestCardinalityModeValidate(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.CardinalityMode = CardinalityModeObserve
+	require.NoError(t, cfg.Validate())
+
+	cfg.CardinalityPrecision = 20
+	assert.ErrorContains(t, cfg.Validate(), "cardinality_precision")
+
+	cfg.CardinalityPrecision = defaultPrecision
+	cfg.CardinalityWindow = 0
+	assert.ErrorContains(t, cfg.Validate(), "cardinality_window")
+
+	cfg.CardinalityMode = "dry-run"
+	assert.ErrorContains(t, cfg.Validate(), "cardinality_mode")
+}
@@ -44,6 +44,9 @@ func CreateDefaultConfig() component.Config {
 		CardinalityCleanupInterval: 1 * time.Hour,
 		EnableIntelligentAggregation: true,
 		EnableLogReduction:    true,
+		CardinalityMode:       CardinalityModeEnforce,
+		CardinalityWindow:     10 * time.Minute,
+		CardinalityPrecision:  defaultPrecision,
 		HighCardinalityDimensions: []string{
 			"user.id", "session.id", "request.id", "trace.id", "span.id",
 			"http.request.id", "transaction.id", "correlation.id",
@@ -71,6 +74,7 @@ func createTracesProcessor(
 
 	// Create concurrent version for better performance
 	processor := NewConcurrentCostControlProcessor(set.Logger, processorConfig, nextConsumer, nil, nil)
+	processor.ledgerKey = set.ID.String() + "/traces"
 
 	return processor, nil
 }
@@ -93,6 +97,7 @@ func createMetricsProcessor(
 
 	// Create concurrent version for better performance
 	processor := NewConcurrentCostControlProcessor(set.Logger, processorConfig, nil, nextConsumer, nil)
+	processor.ledgerKey = set.ID.String() + "/metrics"
 
 	return processor, nil
 }
@@ -115,16 +120,26 @@ func createLogsProcessor(
 
 	// Create concurrent version for better performance
 	processor := NewConcurrentCostControlProcessor(set.Logger, processorConfig, nil, nil, nextConsumer)
+	processor.ledgerKey = set.ID.String() + "/logs"
 
 	return processor, nil
 }
 
 // newCostControlProcessor creates a new cost control processor instance
 func newCostControlProcessor(config *Config, logger *zap.Logger) *costControlProcessor {
-	return &costControlProcessor{
-		config:            config,
-		logger:            logger,
-		costTracker:       &costTracker{currentMonth: time.Now()},
-		metricCardinality: make(map[string]*cardinalityTracker),
+	p := &costControlProcessor{
+		config:               config,
+		logger:               logger,
+		costTracker:          &costTracker{currentMonth: monthStart(time.Now())},
+		ledgerKey:            TypeStr,
+		now:                  time.Now,
+		metricCardinality:    make(map[string]*cardinalityTracker),
+		estimator:            newCardinalityEstimator(config.CardinalityPrecision),
+		attributeRules:       newAttributeRules(config.AttributeRules),
+		attributeRuleSavings: make(map[string]int64),
 	}
+	if config.LedgerPath != "" {
+		p.ledger = newFileLedger(config.LedgerPath)
+	}
+	return p
 }
\ No newline at end of file
@@ -2,11 +2,14 @@ package costcontrol
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -19,6 +22,7 @@ import (
 // costControlProcessor implements intelligent data reduction for cost optimization
 type costControlProcessor struct {
 	config         *Config
+	configMu       sync.RWMutex // guards config, replaced by Reconfigure
 	logger         *zap.Logger
 	nextTraces     consumer.Traces
 	nextMetrics    consumer.Metrics
@@ -28,16 +32,39 @@ type costControlProcessor struct {
 	costTracker    *costTracker
 	mutex          sync.RWMutex
 	
+	// Month-to-date spend is persisted under ledgerKey when a ledger is
+	// configured
+	ledger    spendLedger
+	ledgerKey string
+	now       func() time.Time
+	
 	// Cardinality tracking for metrics
 	metricCardinality map[string]*cardinalityTracker
 	
+	// Distinct series estimates for cardinality_mode observe
+	estimator *cardinalityEstimator
+	
+	// Attribute rules, compiled from config and guarded by configMu, and
+	// the series each has removed
+	attributeRules       []attributeRule
+	attributeRuleSavings map[string]int64
+	
 	// Shutdown
 	shutdownCh     chan struct{}
 	wg             sync.WaitGroup
 }
 
+const (
+	// minProjectionWindow is the least elapsed time spend is extrapolated from
+	minProjectionWindow = 24 * time.Hour
+	
+	// maxClockSkew is how far ahead of the local clock a ledger written in
+	// a later month may be and still be restored
+	maxClockSkew = 24 * time.Hour
+)
+
 type costTracker struct {
-	currentMonth      time.Time
+	currentMonth      time.Time // Start of the calendar month, UTC
 	bytesIngested     int64
 	estimatedCostUSD  float64
 	projectedCostUSD  float64
@@ -50,12 +77,79 @@ type cardinalityTracker struct {
 	lastCleanup       time.Time
 }
 
+// currentConfig returns the configuration in effect
+func (p *costControlProcessor) currentConfig() *Config {
+	p.configMu.RLock()
+	defer p.configMu.RUnlock()
+	return p.config
+}
+
+// currentAttributeRules returns the attribute rules in effect
+func (p *costControlProcessor) currentAttributeRules() []attributeRule {
+	p.configMu.RLock()
+	defer p.configMu.RUnlock()
+	return p.attributeRules
+}
+
+// Reconfigure implements base.Reconfigurable. The budget, pricing,
+// cardinality limits, attribute rules and reduction settings apply to the
+// next batch; the
+// month's tracked spend is kept, so raising the budget takes a processor
+// that is over budget out of aggressive mode right away. Switching
+// cardinality_mode takes effect for the next batch. Changing
+// reporting_interval, cardinality_cleanup_interval, cardinality_window or
+// cardinality_precision or ledger_path requires a restart.
+func (p *costControlProcessor) Reconfigure(cfg component.Config) error {
+	newConfig, ok := cfg.(*Config)
+	if !ok {
+		return fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	p.configMu.Lock()
+	defer p.configMu.Unlock()
+
+	if newConfig.ReportingInterval != p.config.ReportingInterval {
+		return fmt.Errorf("%w: reporting_interval changed from %v to %v", base.ErrRestartRequired,
+			p.config.ReportingInterval, newConfig.ReportingInterval)
+	}
+	if newConfig.CardinalityCleanupInterval != p.config.CardinalityCleanupInterval {
+		return fmt.Errorf("%w: cardinality_cleanup_interval changed from %v to %v", base.ErrRestartRequired,
+			p.config.CardinalityCleanupInterval, newConfig.CardinalityCleanupInterval)
+	}
+	if newConfig.CardinalityWindow != p.config.CardinalityWindow {
+		return fmt.Errorf("%w: cardinality_window changed from %v to %v", base.ErrRestartRequired,
+			p.config.CardinalityWindow, newConfig.CardinalityWindow)
+	}
+	if newConfig.CardinalityPrecision != p.config.CardinalityPrecision {
+		return fmt.Errorf("%w: cardinality_precision changed from %d to %d", base.ErrRestartRequired,
+			p.config.CardinalityPrecision, newConfig.CardinalityPrecision)
+	}
+	if newConfig.LedgerPath != p.config.LedgerPath {
+		return fmt.Errorf("%w: ledger_path changed from %q to %q", base.ErrRestartRequired,
+			p.config.LedgerPath, newConfig.LedgerPath)
+	}
+	p.config = newConfig
+	p.attributeRules = newAttributeRules(newConfig.AttributeRules)
+
+	p.logger.Info("Reconfigured cost control processor",
+		zap.Float64("monthly_budget_usd", newConfig.MonthlyBudgetUSD),
+		zap.Float64("price_per_gb", newConfig.PricePerGB),
+		zap.Int("metric_cardinality_limit", newConfig.MetricCardinalityLimit),
+		zap.String("cardinality_mode", newConfig.CardinalityMode))
+
+	return nil
+}
+
 // Start begins the cost control processor
 func (p *costControlProcessor) Start(ctx context.Context, host component.Host) error {
 	p.logger.Info("Starting cost control processor")
 	
 	p.shutdownCh = make(chan struct{})
 	
+	if err := p.restoreSpend(); err != nil {
+		return err
+	}
+	
 	// Start cost monitoring goroutine
 	p.wg.Add(1)
 	go p.costMonitoringLoop()
@@ -64,6 +158,12 @@ func (p *costControlProcessor) Start(ctx context.Context, host component.Host) e
 	p.wg.Add(1)
 	go p.cardinalityCleanupLoop()
 	
+	// Start cardinality estimate reporting goroutine
+	if p.currentConfig().CardinalityWindow > 0 {
+		p.wg.Add(1)
+		go p.cardinalityReportLoop()
+	}
+	
 	return nil
 }
 
@@ -74,6 +174,8 @@ func (p *costControlProcessor) Shutdown(context.Context) error {
 	close(p.shutdownCh)
 	p.wg.Wait()
 	
+	p.checkpointSpend()
+	
 	// Log final cost report
 	p.logCostReport()
 	
@@ -87,13 +189,15 @@ func (p *costControlProcessor) Capabilities() consumer.Capabilities {
 
 // ConsumeTraces applies cost control to traces
 func (p *costControlProcessor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	cfg := p.currentConfig()
+	
 	// Track data volume
 	dataSize := p.estimateTraceSize(td)
-	p.updateCostTracking(dataSize, "traces")
+	p.updateCostTracking(cfg, dataSize, "traces")
 	
 	// Apply intelligent sampling if over budget
-	if p.isOverBudget() {
-		td = p.applyAggressiveTraceSampling(td)
+	if p.isOverBudget(cfg) {
+		td = p.applyAggressiveTraceSampling(cfg, td)
 	}
 	
 	// Remove high-cost attributes
@@ -104,15 +208,24 @@ func (p *costControlProcessor) ConsumeTraces(ctx context.Context, td ptrace.Trac
 
 // ConsumeMetrics applies cost control to metrics
 func (p *costControlProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	cfg := p.currentConfig()
+	
 	// Track data volume
 	dataSize := p.estimateMetricSize(md)
-	p.updateCostTracking(dataSize, "metrics")
+	p.updateCostTracking(cfg, dataSize, "metrics")
+	
+	// Collapse series with the attribute rules before counting them
+	p.applyAttributeRules(p.currentAttributeRules(), md)
 	
-	// Apply cardinality reduction
-	md = p.reduceMetricCardinality(md)
+	// Apply cardinality reduction, or only estimate it in observe mode
+	if observingCardinality(cfg) {
+		p.estimator.observe(md)
+	} else {
+		md = p.reduceMetricCardinality(cfg, md)
+	}
 	
 	// Drop low-value metrics if over budget
-	if p.isOverBudget() {
+	if p.isOverBudget(cfg) {
 		md = p.dropLowValueMetrics(md)
 	}
 	
@@ -121,23 +234,25 @@ func (p *costControlProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Me
 
 // ConsumeLogs applies cost control to logs
 func (p *costControlProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	cfg := p.currentConfig()
+	
 	// Track data volume
 	dataSize := p.estimateLogSize(ld)
-	p.updateCostTracking(dataSize, "logs")
+	p.updateCostTracking(cfg, dataSize, "logs")
 	
 	// Apply aggressive filtering if over budget
-	if p.isOverBudget() {
+	if p.isOverBudget(cfg) {
 		ld = p.applyAggressiveLogFiltering(ld)
 	}
 	
 	// Truncate large log bodies
-	p.truncateLargeLogs(ld)
+	p.truncateLargeLogs(cfg, ld)
 	
 	return p.nextLogs.ConsumeLogs(ctx, ld)
 }
 
 // reduceMetricCardinality removes high-cardinality attributes
-func (p *costControlProcessor) reduceMetricCardinality(md pmetric.Metrics) pmetric.Metrics {
+func (p *costControlProcessor) reduceMetricCardinality(cfg *Config, md pmetric.Metrics) pmetric.Metrics {
 	rms := md.ResourceMetrics()
 	for i := 0; i < rms.Len(); i++ {
 		rm := rms.At(i)
@@ -149,7 +264,7 @@ func (p *costControlProcessor) reduceMetricCardinality(md pmetric.Metrics) pmetr
 			
 			for k := 0; k < metrics.Len(); k++ {
 				metric := metrics.At(k)
-				p.processMetricCardinality(metric)
+				p.processMetricCardinality(cfg, metric)
 			}
 		}
 	}
@@ -158,7 +273,7 @@ func (p *costControlProcessor) reduceMetricCardinality(md pmetric.Metrics) pmetr
 }
 
 // processMetricCardinality tracks and reduces cardinality for a metric
-func (p *costControlProcessor) processMetricCardinality(metric pmetric.Metric) {
+func (p *costControlProcessor) processMetricCardinality(cfg *Config, metric pmetric.Metric) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	
@@ -176,20 +291,20 @@ func (p *costControlProcessor) processMetricCardinality(metric pmetric.Metric) {
 	currentCardinality := p.countMetricCardinality(metric)
 	
 	// If exceeding threshold, remove high-cardinality attributes
-	if currentCardinality > p.config.MetricCardinalityLimit {
+	if currentCardinality > cfg.MetricCardinalityLimit {
 		p.logger.Warn("Metric exceeds cardinality limit - removing attributes",
 			zap.String("metric", metric.Name()),
 			zap.Int("cardinality", currentCardinality),
-			zap.Int("limit", p.config.MetricCardinalityLimit))
+			zap.Int("limit", cfg.MetricCardinalityLimit))
 		
-		p.removeHighCardinalityAttributes(metric)
+		p.removeHighCardinalityAttributes(cfg, metric)
 	}
 }
 
 // removeHighCardinalityAttributes removes attributes that contribute to high cardinality
-func (p *costControlProcessor) removeHighCardinalityAttributes(metric pmetric.Metric) {
+func (p *costControlProcessor) removeHighCardinalityAttributes(cfg *Config, metric pmetric.Metric) {
 	// Use configured high cardinality attributes
-	highCardAttrs := p.config.HighCardinalityDimensions
+	highCardAttrs := cfg.HighCardinalityDimensions
 	
 	switch metric.Type() {
 	case pmetric.MetricTypeGauge:
@@ -218,7 +333,7 @@ func (p *costControlProcessor) removeAttributesFromDataPoint(attrs pcommon.Map,
 }
 
 // applyAggressiveTraceSampling reduces trace volume when over budget
-func (p *costControlProcessor) applyAggressiveTraceSampling(td ptrace.Traces) ptrace.Traces {
+func (p *costControlProcessor) applyAggressiveTraceSampling(cfg *Config, td ptrace.Traces) ptrace.Traces {
 	// This is a simplified version - in production would use more sophisticated sampling
 	newTd := ptrace.NewTraces()
 	
@@ -227,7 +342,7 @@ func (p *costControlProcessor) applyAggressiveTraceSampling(td ptrace.Traces) pt
 		rs := rss.At(i)
 		
 		// Only keep traces with errors or high latency
-		if p.shouldKeepResourceSpans(rs) {
+		if p.shouldKeepResourceSpans(cfg, rs) {
 			newRs := newTd.ResourceSpans().AppendEmpty()
 			rs.CopyTo(newRs)
 		}
@@ -237,7 +352,7 @@ func (p *costControlProcessor) applyAggressiveTraceSampling(td ptrace.Traces) pt
 }
 
 // shouldKeepResourceSpans determines if spans should be kept
-func (p *costControlProcessor) shouldKeepResourceSpans(rs ptrace.ResourceSpans) bool {
+func (p *costControlProcessor) shouldKeepResourceSpans(cfg *Config, rs ptrace.ResourceSpans) bool {
 	sss := rs.ScopeSpans()
 	for i := 0; i < sss.Len(); i++ {
 		ss := sss.At(i)
@@ -253,7 +368,7 @@ func (p *costControlProcessor) shouldKeepResourceSpans(rs ptrace.ResourceSpans)
 			
 			// Keep slow spans
 			duration := span.EndTimestamp() - span.StartTimestamp()
-			if duration > pcommon.Timestamp(p.config.SlowSpanThresholdMs*1_000_000) {
+			if duration > pcommon.Timestamp(cfg.SlowSpanThresholdMs*1_000_000) {
 				return true
 			}
 		}
@@ -295,55 +410,168 @@ func (p *costControlProcessor) applyAggressiveLogFiltering(ld plog.Logs) plog.Lo
 }
 
 // updateCostTracking updates the cost tracking metrics
-func (p *costControlProcessor) updateCostTracking(bytes int64, dataType string) {
+func (p *costControlProcessor) updateCostTracking(cfg *Config, bytes int64, dataType string) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 	
+	now := p.now()
+	p.advanceMonthLocked(now)
+	
 	p.costTracker.bytesIngested += bytes
 	
 	// Calculate cost based on New Relic pricing
 	// $0.35/GB for standard, $0.55/GB for Data Plus
-	pricePerGB := p.config.PricePerGB
+	pricePerGB := cfg.PricePerGB
 	costIncrement := float64(bytes) / (1024 * 1024 * 1024) * pricePerGB
 	
 	p.costTracker.estimatedCostUSD += costIncrement
-	p.costTracker.lastUpdate = time.Now()
-	
-	// Update monthly projection
-	daysSoFar := time.Since(p.costTracker.currentMonth).Hours() / 24
-	if daysSoFar > 0 {
-		dailyRate := p.costTracker.estimatedCostUSD / daysSoFar
-		p.costTracker.projectedCostUSD = dailyRate * 30
-	}
+	p.costTracker.lastUpdate = now
+	p.projectLocked(now)
 	
 	// Log if exceeding budget
-	if p.costTracker.projectedCostUSD > p.config.MonthlyBudgetUSD {
+	if p.costTracker.projectedCostUSD > cfg.MonthlyBudgetUSD {
 		p.logger.Warn("Projected to exceed monthly budget",
 			zap.Float64("current_cost", p.costTracker.estimatedCostUSD),
 			zap.Float64("projected_cost", p.costTracker.projectedCostUSD),
-			zap.Float64("budget", p.config.MonthlyBudgetUSD),
+			zap.Float64("budget", cfg.MonthlyBudgetUSD),
 			zap.String("data_type", dataType))
 	}
 }
 
-// isOverBudget checks if current usage exceeds budget
-func (p *costControlProcessor) isOverBudget() bool {
+// isOverBudget checks if current usage exceeds the budget in cfg
+func (p *costControlProcessor) isOverBudget(cfg *Config) bool {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 	
-	return p.costTracker.projectedCostUSD > p.config.MonthlyBudgetUSD
+	return p.costTracker.projectedCostUSD > cfg.MonthlyBudgetUSD
+}
+
+// projectLocked extrapolates month-to-date spend to the whole month. The
+// first day is extrapolated as a full day, so a burst in the first minutes
+// of a month does not project to many times the budget.
+func (p *costControlProcessor) projectLocked(now time.Time) {
+	month := p.costTracker.currentMonth
+	elapsed := now.Sub(month)
+	if elapsed < minProjectionWindow {
+		elapsed = minProjectionWindow
+	}
+	monthLength := month.AddDate(0, 1, 0).Sub(month)
+	p.costTracker.projectedCostUSD = p.costTracker.estimatedCostUSD * float64(monthLength) / float64(elapsed)
+}
+
+// advanceMonthLocked starts a new month when now is in a later calendar
+// month than the one being tracked. The month never moves backwards: when
+// the clock is stepped back, spend keeps counting in the current month.
+func (p *costControlProcessor) advanceMonthLocked(now time.Time) {
+	month := monthStart(now)
+	if !month.After(p.costTracker.currentMonth) {
+		return
+	}
+	
+	p.logger.Info("Starting a new budget month",
+		zap.String("closed_month", p.costTracker.currentMonth.Format(monthKeyLayout)),
+		zap.Float64("closed_month_cost_usd", p.costTracker.estimatedCostUSD),
+		zap.Int64("closed_month_bytes", p.costTracker.bytesIngested),
+		zap.String("month", month.Format(monthKeyLayout)))
+	
+	p.costTracker.currentMonth = month
+	p.costTracker.bytesIngested = 0
+	p.costTracker.estimatedCostUSD = 0
+	p.costTracker.projectedCostUSD = 0
+}
+
+// restoreSpend loads this instance's month-to-date spend from the ledger.
+// A corrupt ledger is logged and the month starts from zero; a ledger that
+// cannot be read fails Start, since the budget would silently reset.
+func (p *costControlProcessor) restoreSpend() error {
+	if p.ledger == nil {
+		return nil
+	}
+	
+	entry, found, err := p.ledger.Load(p.ledgerKey)
+	if errors.Is(err, errCorruptLedger) {
+		p.logger.Warn("Spend ledger is corrupt, starting the month from zero", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	
+	now := p.now()
+	month := monthStart(now)
+	ledgerMonth, _ := time.Parse(monthKeyLayout, entry.Month)
+	switch {
+	case ledgerMonth.Equal(month):
+	case ledgerMonth.Before(month):
+		p.logger.Info("Spend ledger is from an earlier month, starting the month from zero",
+			zap.String("ledger_month", entry.Month))
+		return nil
+	case entry.UpdatedAt.Sub(now) <= maxClockSkew:
+		// Written moments ago by a clock slightly ahead, just after a month
+		// boundary: keep counting in that month rather than reopen the last
+		month = ledgerMonth
+	default:
+		p.logger.Warn("Spend ledger is from a later month, ignoring it",
+			zap.String("ledger_month", entry.Month),
+			zap.Time("ledger_updated_at", entry.UpdatedAt))
+		return nil
+	}
+	
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	
+	p.costTracker.currentMonth = month
+	p.costTracker.bytesIngested = entry.BytesIngested
+	p.costTracker.estimatedCostUSD = entry.EstimatedCostUSD
+	p.costTracker.lastUpdate = entry.UpdatedAt
+	p.projectLocked(now)
+	
+	p.logger.Info("Restored month-to-date spend",
+		zap.String("month", entry.Month),
+		zap.Float64("estimated_cost_usd", entry.EstimatedCostUSD),
+		zap.Int64("bytes_ingested", entry.BytesIngested))
+	return nil
+}
+
+// checkpointSpend rolls over the month if it has ended, refreshes the
+// projection, which otherwise only changes when data arrives, and saves
+// the month-to-date spend to the ledger
+func (p *costControlProcessor) checkpointSpend() {
+	now := p.now()
+	
+	p.mutex.Lock()
+	p.advanceMonthLocked(now)
+	p.projectLocked(now)
+	entry := ledgerEntry{
+		Month:            p.costTracker.currentMonth.Format(monthKeyLayout),
+		BytesIngested:    p.costTracker.bytesIngested,
+		EstimatedCostUSD: p.costTracker.estimatedCostUSD,
+		UpdatedAt:        now,
+	}
+	p.mutex.Unlock()
+	
+	if p.ledger == nil {
+		return
+	}
+	if err := p.ledger.Save(p.ledgerKey, entry); err != nil {
+		p.logger.Warn("Failed to save spend ledger", zap.Error(err))
+	}
 }
 
 // costMonitoringLoop periodically reports cost metrics
 func (p *costControlProcessor) costMonitoringLoop() {
 	defer p.wg.Done()
 	
-	ticker := time.NewTicker(p.config.ReportingInterval)
+	ticker := time.NewTicker(p.currentConfig().ReportingInterval)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
+			p.checkpointSpend()
 			p.generateCostMetrics()
 		case <-p.shutdownCh:
 			return
@@ -353,16 +581,21 @@ func (p *costControlProcessor) costMonitoringLoop() {
 
 // logCostReport logs the current cost status
 func (p *costControlProcessor) logCostReport() {
+	cfg := p.currentConfig()
+	ruleReport := p.attributeRuleReport()
+	
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 	
 	p.logger.Info("Cost control report",
+		zap.Any("attribute_rule_series_saved", ruleReport),
+		zap.String("month", p.costTracker.currentMonth.Format(monthKeyLayout)),
 		zap.Int64("bytes_ingested", p.costTracker.bytesIngested),
 		zap.Float64("estimated_cost_usd", p.costTracker.estimatedCostUSD),
 		zap.Float64("projected_monthly_cost_usd", p.costTracker.projectedCostUSD),
-		zap.Float64("monthly_budget_usd", p.config.MonthlyBudgetUSD),
+		zap.Float64("monthly_budget_usd", cfg.MonthlyBudgetUSD),
 		zap.Float64("budget_utilization_percent", 
-			(p.costTracker.projectedCostUSD/p.config.MonthlyBudgetUSD)*100))
+			(p.costTracker.projectedCostUSD/cfg.MonthlyBudgetUSD)*100))
 }
 
 // Helper functions for size estimation
@@ -434,7 +667,10 @@ func getAttributeKey(attrs pcommon.Map) string {
 	return strings.Join(keys, "|")
 }
 
-func (p *costControlProcessor) truncateLargeLogs(ld plog.Logs) {
+// truncateLargeLogs truncates log bodies over max_log_body_size. cfg is read
+// once per batch by the caller, so a reload cannot change the limit between
+// the length check and the slice.
+func (p *costControlProcessor) truncateLargeLogs(cfg *Config, ld plog.Logs) {
 	rls := ld.ResourceLogs()
 	for i := 0; i < rls.Len(); i++ {
 		rl := rls.At(i)
@@ -449,8 +685,8 @@ func (p *costControlProcessor) truncateLargeLogs(ld plog.Logs) {
 				
 				// Truncate large log bodies
 				if body := log.Body(); body.Type() == pcommon.ValueTypeStr {
-					if len(body.Str()) > p.config.MaxLogBodySize {
-						truncated := body.Str()[:p.config.MaxLogBodySize] + "... [truncated]"
+					if len(body.Str()) > cfg.MaxLogBodySize {
+						truncated := body.Str()[:cfg.MaxLogBodySize] + "... [truncated]"
 						body.SetStr(truncated)
 					}
 				}
@@ -534,6 +770,71 @@ func (p *costControlProcessor) cleanupCardinalityTrackers() {
 	}
 }
 
+// observingCardinality reports whether cardinality is estimated rather
+// than enforced
+func observingCardinality(cfg *Config) bool {
+	return cfg.CardinalityMode == CardinalityModeObserve
+}
+
+func (p *costControlProcessor) cardinalityReportLoop() {
+	defer p.wg.Done()
+	
+	ticker := time.NewTicker(p.currentConfig().CardinalityWindow)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := p.reportCardinality(ctx); err != nil {
+				p.logger.Error("Failed to send cardinality estimates", zap.Error(err))
+			}
+			cancel()
+		case <-p.shutdownCh:
+			return
+		}
+	}
+}
+
+// reportCardinality logs the cardinality estimates of the window that just
+// ended and sends them on as metrics. Metrics whose estimate is over
+// metric_cardinality_limit are logged as warnings, since enforcing the
+// limit would strip their high cardinality dimensions.
+func (p *costControlProcessor) reportCardinality(ctx context.Context) error {
+	report := p.estimator.report(time.Now())
+	if len(report.estimates) == 0 {
+		return nil
+	}
+	
+	limit := p.currentConfig().MetricCardinalityLimit
+	over := 0
+	for _, estimate := range report.estimates {
+		if estimate.series > uint64(limit) {
+			over++
+			p.logger.Warn("Metric cardinality estimate exceeds metric_cardinality_limit",
+				zap.String("metric", estimate.metric),
+				zap.Uint64("estimated_series", estimate.series),
+				zap.Int("limit", limit))
+		} else {
+			p.logger.Debug("Metric cardinality estimate",
+				zap.String("metric", estimate.metric),
+				zap.Uint64("estimated_series", estimate.series))
+		}
+	}
+	p.logger.Info("Metric cardinality estimate",
+		zap.Duration("window", report.end.Sub(report.start)),
+		zap.Int("metrics", len(report.estimates)),
+		zap.Uint64("estimated_series", report.total),
+		zap.String("largest_metric", report.estimates[0].metric),
+		zap.Uint64("largest_metric_series", report.estimates[0].series),
+		zap.Int("metrics_over_limit", over))
+	
+	if p.nextMetrics == nil {
+		return nil
+	}
+	return p.nextMetrics.ConsumeMetrics(ctx, report.metrics())
+}
+
 func (p *costControlProcessor) removeExpensiveTraceAttributes(td ptrace.Traces) {
 	// Remove large/expensive attributes from traces
 	expensiveAttrs := []string{
@@ -41,17 +41,25 @@ func NewConcurrentCostControlProcessor(
 ) *ConcurrentCostControlProcessor {
 	// Create the original processor
 	p := &costControlProcessor{
-		config:           config,
-		logger:           logger,
-		nextTraces:       nextTraces,
-		nextMetrics:      nextMetrics,
-		nextLogs:         nextLogs,
-		metricCardinality: make(map[string]*cardinalityTracker),
+		config:               config,
+		logger:               logger,
+		nextTraces:           nextTraces,
+		nextMetrics:          nextMetrics,
+		nextLogs:             nextLogs,
+		metricCardinality:    make(map[string]*cardinalityTracker),
+		estimator:            newCardinalityEstimator(config.CardinalityPrecision),
+		attributeRules:       newAttributeRules(config.AttributeRules),
+		attributeRuleSavings: make(map[string]int64),
+		ledgerKey:            TypeStr,
+		now:                  time.Now,
 		costTracker: &costTracker{
-			currentMonth: time.Now(),
+			currentMonth: monthStart(time.Now()),
 			lastUpdate:   time.Now(),
 		},
 	}
+	if config.LedgerPath != "" {
+		p.ledger = newFileLedger(config.LedgerPath)
+	}
 
 	return &ConcurrentCostControlProcessor{
 		costControlProcessor: p,
@@ -61,6 +69,11 @@ func NewConcurrentCostControlProcessor(
 
 // Start starts the concurrent processor
 func (ccp *ConcurrentCostControlProcessor) Start(ctx context.Context, host component.Host) error {
+	// Restore the month's spend before any data is tracked
+	if err := ccp.restoreSpend(); err != nil {
+		return err
+	}
+
 	// Initialize base concurrent processor
 	if err := ccp.ConcurrentProcessor.Start(ctx, host); err != nil {
 		return err
@@ -74,10 +87,15 @@ func (ccp *ConcurrentCostControlProcessor) Start(ctx context.Context, host compo
 	ccp.StartBackgroundTask("cost-monitoring", 1*time.Minute, ccp.costMonitoringWithContext)
 
 	// Start cardinality cleanup with proper context
-	ccp.StartBackgroundTask("cardinality-cleanup", ccp.config.CardinalityCleanupInterval, ccp.cardinalityCleanupWithContext)
+	ccp.StartBackgroundTask("cardinality-cleanup", ccp.currentConfig().CardinalityCleanupInterval, ccp.cardinalityCleanupWithContext)
+
+	// Report cardinality estimates at the end of each window
+	if ccp.currentConfig().CardinalityWindow > 0 {
+		ccp.StartBackgroundTask("cardinality-estimate", ccp.currentConfig().CardinalityWindow, ccp.reportCardinality)
+	}
 
 	ccp.logger.Info("Started concurrent cost control processor",
-		zap.Float64("monthly_budget_usd", ccp.config.MonthlyBudgetUSD),
+		zap.Float64("monthly_budget_usd", ccp.currentConfig().MonthlyBudgetUSD),
 		zap.Int("processing_workers", runtime.NumCPU()))
 
 	return nil
@@ -87,16 +105,19 @@ func (ccp *ConcurrentCostControlProcessor) Start(ctx context.Context, host compo
 func (ccp *ConcurrentCostControlProcessor) Shutdown(ctx context.Context) error {
 	ccp.logger.Info("Shutting down concurrent cost control processor")
 
-	// Log final cost report
-	ccp.logCostReport()
-
 	// Stop worker pool
 	if ccp.processingWorkerPool != nil {
 		ccp.processingWorkerPool.Stop()
 	}
 
-	// Shutdown base concurrent processor
-	return ccp.ConcurrentProcessor.Shutdown(ctx)
+	// Shutdown base concurrent processor, stopping the cost monitoring task
+	err := ccp.ConcurrentProcessor.Shutdown(ctx)
+
+	// Save the month's spend and log the final cost report
+	ccp.checkpointSpend()
+	ccp.logCostReport()
+
+	return err
 }
 
 // ConsumeTraces applies cost control to traces concurrently
@@ -106,15 +127,16 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeTraces(ctx context.Context, td
 		return nil
 	}
 
+	cfg := ccp.currentConfig()
 	ccp.concurrentMetrics.tracesProcessed.Add(int64(td.SpanCount()))
 
 	// Track data volume
 	dataSize := ccp.estimateTraceSize(td)
-	ccp.updateCostTracking(dataSize, "traces")
+	ccp.updateCostTracking(cfg, dataSize, "traces")
 
 	// Apply intelligent sampling if over budget
-	if ccp.isOverBudget() {
-		td = ccp.applyAggressiveTraceSampling(td)
+	if ccp.isOverBudget(cfg) {
+		td = ccp.applyAggressiveTraceSampling(cfg, td)
 		ccp.concurrentMetrics.itemsDropped.Add(int64(td.SpanCount()))
 	}
 
@@ -143,21 +165,30 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeMetrics(ctx context.Context, m
 		return nil
 	}
 
+	cfg := ccp.currentConfig()
 	ccp.concurrentMetrics.metricsProcessed.Add(int64(md.DataPointCount()))
 
 	// Track data volume
 	dataSize := ccp.estimateMetricSize(md)
-	ccp.updateCostTracking(dataSize, "metrics")
+	ccp.updateCostTracking(cfg, dataSize, "metrics")
+
+	// Collapse series with the attribute rules before counting them
+	ccp.applyAttributeRules(ccp.currentAttributeRules(), md)
+
+	// Only estimate cardinality in observe mode; nothing is dropped
+	if observingCardinality(cfg) {
+		ccp.estimator.observe(md)
+	}
 
 	// Process metric optimization concurrently
 	err := ccp.processingWorkerPool.Submit(func() {
 		// Check cardinality limits
-		if ccp.config.CardinalityLimit > 0 {
+		if cfg.CardinalityLimit > 0 {
 			// Cardinality limits are enforced in the base processor
 		}
 
 		// Apply aggregation if needed
-		if ccp.isOverBudget() && ccp.config.EnableIntelligentAggregation {
+		if ccp.isOverBudget(cfg) && cfg.EnableIntelligentAggregation {
 			// Intelligent aggregation is applied in the base processor
 		}
 
@@ -167,10 +198,10 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeMetrics(ctx context.Context, m
 	
 	if err != nil {
 		// Fall back to synchronous processing
-		if ccp.config.CardinalityLimit > 0 {
+		if cfg.CardinalityLimit > 0 {
 			// Cardinality limits are enforced in the base processor
 		}
-		if ccp.isOverBudget() && ccp.config.EnableIntelligentAggregation {
+		if ccp.isOverBudget(cfg) && cfg.EnableIntelligentAggregation {
 			// Intelligent aggregation is applied in the base processor
 		}
 		// Remove expensive labels using embedded processor
@@ -189,22 +220,23 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeLogs(ctx context.Context, ld p
 		return nil
 	}
 
+	cfg := ccp.currentConfig()
 	ccp.concurrentMetrics.logsProcessed.Add(int64(ld.LogRecordCount()))
 
 	// Track data volume
 	dataSize := ccp.estimateLogSize(ld)
-	ccp.updateCostTracking(dataSize, "logs")
+	ccp.updateCostTracking(cfg, dataSize, "logs")
 
 	// Process log optimization concurrently
 	err := ccp.processingWorkerPool.Submit(func() {
 		// Filter by severity if over budget
-		if ccp.isOverBudget() {
+		if ccp.isOverBudget(cfg) {
 			// Log filtering is done in the base processor
 		}
 
 		// Reduce log verbosity
-		if ccp.config.EnableLogReduction {
-			ccp.truncateLargeLogs(ld)
+		if cfg.EnableLogReduction {
+			ccp.truncateLargeLogs(cfg, ld)
 		}
 
 		// Remove expensive fields
@@ -213,11 +245,11 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeLogs(ctx context.Context, ld p
 	
 	if err != nil {
 		// Fall back to synchronous processing
-		if ccp.isOverBudget() {
+		if ccp.isOverBudget(cfg) {
 			// Log filtering is done in the base processor
 		}
-		if ccp.config.EnableLogReduction {
-			ccp.truncateLargeLogs(ld)
+		if cfg.EnableLogReduction {
+			ccp.truncateLargeLogs(cfg, ld)
 		}
 		// Expensive fields are removed in the base processor
 	}
@@ -230,24 +262,19 @@ func (ccp *ConcurrentCostControlProcessor) ConsumeLogs(ctx context.Context, ld p
 
 // costMonitoringWithContext performs cost monitoring with proper context
 func (ccp *ConcurrentCostControlProcessor) costMonitoringWithContext(ctx context.Context) error {
-	ccp.mutex.Lock()
-	defer ccp.mutex.Unlock()
+	cfg := ccp.currentConfig()
 
-	// Update cost projections
-	now := time.Now()
-	daysSinceMonthStart := float64(now.Day())
-	daysInMonth := float64(time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day())
-	
-	if daysSinceMonthStart > 0 {
-		dailyRate := ccp.costTracker.estimatedCostUSD / daysSinceMonthStart
-		ccp.costTracker.projectedCostUSD = dailyRate * daysInMonth
-	}
+	// Roll over the month, update the cost projection and save the spend
+	ccp.checkpointSpend()
+
+	ccp.mutex.RLock()
+	defer ccp.mutex.RUnlock()
 
 	// Log cost status
 	ccp.logger.Info("Cost control status",
 		zap.Float64("current_cost_usd", ccp.costTracker.estimatedCostUSD),
 		zap.Float64("projected_cost_usd", ccp.costTracker.projectedCostUSD),
-		zap.Float64("monthly_budget_usd", ccp.config.MonthlyBudgetUSD),
+		zap.Float64("monthly_budget_usd", cfg.MonthlyBudgetUSD),
 		zap.Int64("bytes_ingested", ccp.costTracker.bytesIngested),
 		zap.Int64("traces_processed", ccp.concurrentMetrics.tracesProcessed.Load()),
 		zap.Int64("metrics_processed", ccp.concurrentMetrics.metricsProcessed.Load()),
@@ -255,9 +282,9 @@ func (ccp *ConcurrentCostControlProcessor) costMonitoringWithContext(ctx context
 		zap.Int64("items_dropped", ccp.concurrentMetrics.itemsDropped.Load()))
 
 	// Alert if over budget
-	if ccp.costTracker.projectedCostUSD > ccp.config.MonthlyBudgetUSD {
+	if ccp.costTracker.projectedCostUSD > cfg.MonthlyBudgetUSD {
 		ccp.logger.Warn("Projected to exceed monthly budget",
-			zap.Float64("projected_overage_usd", ccp.costTracker.projectedCostUSD-ccp.config.MonthlyBudgetUSD))
+			zap.Float64("projected_overage_usd", ccp.costTracker.projectedCostUSD-cfg.MonthlyBudgetUSD))
 	}
 
 	return nil
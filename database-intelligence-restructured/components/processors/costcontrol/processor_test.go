@@ -5,10 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
 )
@@ -172,4 +174,44 @@ func createTestMetrics(numMetrics, numAttributes int) pmetric.Metrics {
 	}
 	
 	return metrics
-}
\ No newline at end of file
+}
+func TestCostControlProcessor_Reconfigure(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.MonthlyBudgetUSD = 10.0
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+	processor.costTracker.projectedCostUSD = 50.0
+	assert.True(t, processor.isOverBudget(processor.currentConfig()))
+
+	// Raising the budget keeps the tracked spend
+	updated := CreateDefaultConfig().(*Config)
+	updated.MonthlyBudgetUSD = 100.0
+	require.NoError(t, updated.Validate())
+	require.NoError(t, processor.Reconfigure(updated))
+	assert.False(t, processor.isOverBudget(processor.currentConfig()))
+	assert.Equal(t, 50.0, processor.costTracker.projectedCostUSD)
+
+	restart := CreateDefaultConfig().(*Config)
+	restart.ReportingInterval = 2 * updated.ReportingInterval
+	assert.ErrorIs(t, processor.Reconfigure(restart), base.ErrRestartRequired)
+	assert.Same(t, updated, processor.currentConfig())
+}
+
+func TestCostControlProcessor_TruncateUsesBatchConfig(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.MaxLogBodySize = 10
+	processor := newCostControlProcessor(cfg, zap.NewNop())
+
+	// A reload that raises the limit while a batch is in flight does not
+	// change the limit that batch is truncated to
+	batchConfig := processor.currentConfig()
+	updated := CreateDefaultConfig().(*Config)
+	updated.MaxLogBodySize = 1000
+	require.NoError(t, processor.Reconfigure(updated))
+
+	ld := plog.NewLogs()
+	record := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.Body().SetStr("0123456789abcdefghij")
+	processor.truncateLargeLogs(batchConfig, ld)
+
+	assert.Equal(t, "0123456789... [truncated]", record.Body().Str())
+}
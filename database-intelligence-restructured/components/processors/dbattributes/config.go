@@ -0,0 +1,95 @@
+package dbattributes
+
+import (
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the database attribute processor.
+//
+// Legacy attribute keys are renamed to the canonical db.* keys. A built-in
+// mapping covers the keys used across this repository; Mappings adds to it
+// and Exclude removes from it.
+type Config struct {
+	// Mappings maps further legacy keys to canonical keys. An entry for a
+	// key the built-in mapping has replaces the built-in canonical key.
+	Mappings map[string]string `mapstructure:"mappings"`
+
+	// Exclude lists built-in legacy keys to leave unchanged
+	Exclude []string `mapstructure:"exclude"`
+
+	// KeepOriginal keeps the legacy key next to the canonical one, for
+	// dashboards that still query it
+	KeepOriginal bool `mapstructure:"keep_original"`
+
+	// StripPrefix renames attributes.<canonical key>, left by tools that
+	// flatten nested attributes, to the canonical key
+	StripPrefix bool `mapstructure:"strip_prefix"`
+
+	// NormalizeSystem lowercases db.system and replaces aliases such as
+	// postgres with their semantic convention value
+	NormalizeSystem bool `mapstructure:"normalize_system"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	for legacy, canonical := range cfg.Mappings {
+		if legacy == "" || canonical == "" {
+			return fmt.Errorf("mappings: legacy and canonical keys must not be empty")
+		}
+		if legacy == canonical {
+			return fmt.Errorf("mappings: %q is mapped to itself", legacy)
+		}
+	}
+
+	mappings := cfg.mappings()
+	legacy := make(map[string]bool, len(mappings))
+	for _, m := range mappings {
+		legacy[m.legacy] = true
+	}
+	for _, m := range mappings {
+		if legacy[m.canonical] {
+			return fmt.Errorf("mappings: %q is both a legacy key and the canonical key for %q; exclude one of them", m.canonical, m.legacy)
+		}
+	}
+	return nil
+}
+
+// mappings merges the built-in mapping with the configured one. Built-in
+// keys keep their order, with overrides in place; added keys follow in
+// sorted order, so the result is the same on every start.
+func (cfg *Config) mappings() []defaultMapping {
+	excluded := make(map[string]bool, len(cfg.Exclude))
+	for _, key := range cfg.Exclude {
+		excluded[key] = true
+	}
+
+	merged := make([]defaultMapping, 0, len(defaultMappings)+len(cfg.Mappings))
+	seen := make(map[string]bool, len(defaultMappings))
+	for _, m := range defaultMappings {
+		seen[m.legacy] = true
+		if excluded[m.legacy] {
+			continue
+		}
+		if canonical, ok := cfg.Mappings[m.legacy]; ok {
+			m.canonical = canonical
+		}
+		merged = append(merged, m)
+	}
+
+	added := make([]string, 0, len(cfg.Mappings))
+	for legacy := range cfg.Mappings {
+		if !seen[legacy] {
+			added = append(added, legacy)
+		}
+	}
+	sort.Strings(added)
+	for _, legacy := range added {
+		merged = append(merged, defaultMapping{legacy: legacy, canonical: cfg.Mappings[legacy]})
+	}
+	return merged
+}
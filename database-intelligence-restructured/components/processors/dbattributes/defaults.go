@@ -0,0 +1,71 @@
+package dbattributes
+
+// Canonical database attribute keys. These are the OpenTelemetry semantic
+// convention names the collector configurations, dashboards and validation
+// queries in this repository use.
+const (
+	AttrDBName      = "db.name"
+	AttrDBStatement = "db.statement"
+	AttrDBSystem    = "db.system"
+	AttrDBOperation = "db.operation"
+	AttrDBUser      = "db.user"
+	AttrDBSQLTable  = "db.sql.table"
+	AttrServerAddr  = "server.address"
+	AttrServerPort  = "server.port"
+)
+
+// legacyPrefix is left on attribute keys by exporters and tools that
+// flatten nested maps, e.g. attributes.db.name
+const legacyPrefix = "attributes."
+
+// defaultMapping maps one legacy key to its canonical key
+type defaultMapping struct {
+	legacy    string
+	canonical string
+}
+
+// defaultMappings are the legacy keys found in receivers, generators and
+// queries, most specific first. When several are present the first one
+// wins. Later semantic convention names (db.namespace, db.query.text) are
+// mapped back, since nothing here queries them yet.
+var defaultMappings = []defaultMapping{
+	{"postgresql.database.name", AttrDBName},
+	{"mysql.database.name", AttrDBName},
+	{"db.namespace", AttrDBName},
+	{"database_name", AttrDBName},
+	{"db_name", AttrDBName},
+	{"datname", AttrDBName},
+	{"database", AttrDBName},
+
+	{"db.query.text", AttrDBStatement},
+	{"query.text", AttrDBStatement},
+	{"query_text", AttrDBStatement},
+	{"statement", AttrDBStatement},
+
+	{"db.system.name", AttrDBSystem},
+	{"db_system", AttrDBSystem},
+
+	{"db.operation.name", AttrDBOperation},
+
+	{"db.collection.name", AttrDBSQLTable},
+	{"postgresql.table.name", AttrDBSQLTable},
+	{"mysql.table.name", AttrDBSQLTable},
+	{"table_name", AttrDBSQLTable},
+
+	{"usename", AttrDBUser},
+	{"user_name", AttrDBUser},
+	{"db_user", AttrDBUser},
+
+	{"net.peer.name", AttrServerAddr},
+	{"net.peer.port", AttrServerPort},
+}
+
+// defaultSystemValues normalizes db.system values that name a database
+// differently from the semantic conventions
+var defaultSystemValues = map[string]string{
+	"postgres":   "postgresql",
+	"pg":         "postgresql",
+	"pgsql":      "postgresql",
+	"sqlserver":  "mssql",
+	"sql server": "mssql",
+}
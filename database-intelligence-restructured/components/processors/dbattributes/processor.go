@@ -0,0 +1,155 @@
+package dbattributes
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// canonicalKeys are stripped of the attributes. prefix even when no
+// mapping names them
+var canonicalKeys = []string{
+	AttrDBName, AttrDBStatement, AttrDBSystem, AttrDBOperation,
+	AttrDBUser, AttrDBSQLTable, AttrServerAddr, AttrServerPort,
+}
+
+// dbAttributesProcessor renames legacy database attribute keys to the
+// canonical db.* keys on resources, data points and log records
+type dbAttributesProcessor struct {
+	// rules are applied in order, so the first legacy key present sets a
+	// canonical key that is missing
+	rules           []defaultMapping
+	keepOriginal    bool
+	normalizeSystem bool
+}
+
+// newDBAttributesProcessor expands the configured mapping into rules. With
+// StripPrefix, attributes.<key> follows <key> for every legacy and
+// canonical key, so unprefixed keys win.
+func newDBAttributesProcessor(cfg *Config) *dbAttributesProcessor {
+	mappings := cfg.mappings()
+	rules := append([]defaultMapping(nil), mappings...)
+
+	if cfg.StripPrefix {
+		canonical := append([]string(nil), canonicalKeys...)
+		for _, m := range mappings {
+			canonical = append(canonical, m.canonical)
+		}
+		seen := make(map[string]bool, len(canonical))
+		for _, key := range canonical {
+			if !seen[key] {
+				seen[key] = true
+				rules = append(rules, defaultMapping{legacy: legacyPrefix + key, canonical: key})
+			}
+		}
+		for _, m := range mappings {
+			rules = append(rules, defaultMapping{legacy: legacyPrefix + m.legacy, canonical: m.canonical})
+		}
+	}
+
+	return &dbAttributesProcessor{
+		rules:           rules,
+		keepOriginal:    cfg.KeepOriginal,
+		normalizeSystem: cfg.NormalizeSystem,
+	}
+}
+
+// processMetrics normalizes resource and data point attributes
+func (dap *dbAttributesProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		dap.normalize(rm.Resource().Attributes())
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				forEachAttributes(metrics.At(k), dap.normalize)
+			}
+		}
+	}
+	return md, nil
+}
+
+// processLogs normalizes resource and log record attributes
+func (dap *dbAttributesProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		dap.normalize(rl.Resource().Attributes())
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				dap.normalize(records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// normalize renames legacy keys in attrs. A canonical key that is already
+// set keeps its value; the legacy key is still removed unless keepOriginal.
+func (dap *dbAttributesProcessor) normalize(attrs pcommon.Map) {
+	for _, rule := range dap.rules {
+		v, ok := attrs.Get(rule.legacy)
+		if !ok {
+			continue
+		}
+		if _, exists := attrs.Get(rule.canonical); !exists {
+			// Copy out first: PutEmpty and Remove invalidate v
+			value := pcommon.NewValueEmpty()
+			v.CopyTo(value)
+			value.CopyTo(attrs.PutEmpty(rule.canonical))
+		}
+		if !dap.keepOriginal {
+			attrs.Remove(rule.legacy)
+		}
+	}
+
+	if dap.normalizeSystem {
+		if v, ok := attrs.Get(AttrDBSystem); ok && v.Type() == pcommon.ValueTypeStr {
+			system := strings.ToLower(strings.TrimSpace(v.Str()))
+			if alias, ok := defaultSystemValues[system]; ok {
+				system = alias
+			}
+			v.SetStr(system)
+		}
+	}
+}
+
+// forEachAttributes calls fn with the attributes of every data point
+func forEachAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
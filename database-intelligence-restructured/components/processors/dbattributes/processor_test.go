@@ -0,0 +1,285 @@
+package dbattributes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "added and overridden mappings",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"dbname": AttrDBName, "database": "db.instance.id"}
+				cfg.Exclude = []string{"statement"}
+			},
+		},
+		{
+			name: "empty key",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"dbname": ""}
+			},
+			wantErr: "legacy and canonical keys must not be empty",
+		},
+		{
+			name: "mapped to itself",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{AttrDBName: AttrDBName}
+			},
+			wantErr: `"db.name" is mapped to itself`,
+		},
+		{
+			name: "canonical key is a built-in legacy key",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"dbname": "db.namespace"}
+			},
+			wantErr: `"db.namespace" is both a legacy key and the canonical key for "dbname"`,
+		},
+		{
+			name: "excluding the built-in legacy key allows it",
+			modify: func(cfg *Config) {
+				cfg.Mappings = map[string]string{"dbname": "db.namespace"}
+				cfg.Exclude = []string{"db.namespace"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNormalizeLegacyKeys(t *testing.T) {
+	tests := map[string]string{
+		"postgresql.database.name": AttrDBName,
+		"mysql.database.name":      AttrDBName,
+		"db.namespace":             AttrDBName,
+		"database_name":            AttrDBName,
+		"db_name":                  AttrDBName,
+		"datname":                  AttrDBName,
+		"database":                 AttrDBName,
+		"attributes.db.name":       AttrDBName,
+		"attributes.database_name": AttrDBName,
+		"db.query.text":            AttrDBStatement,
+		"query.text":               AttrDBStatement,
+		"query_text":               AttrDBStatement,
+		"statement":                AttrDBStatement,
+		"attributes.db.statement":  AttrDBStatement,
+		"db.system.name":           AttrDBSystem,
+		"db_system":                AttrDBSystem,
+		"attributes.db.system":     AttrDBSystem,
+		"db.operation.name":        AttrDBOperation,
+		"db.collection.name":       AttrDBSQLTable,
+		"postgresql.table.name":    AttrDBSQLTable,
+		"mysql.table.name":         AttrDBSQLTable,
+		"table_name":               AttrDBSQLTable,
+		"usename":                  AttrDBUser,
+		"user_name":                AttrDBUser,
+		"db_user":                  AttrDBUser,
+		"net.peer.name":            AttrServerAddr,
+		"net.peer.port":            AttrServerPort,
+	}
+
+	// Every built-in legacy key is covered
+	for _, m := range defaultMappings {
+		require.Contains(t, tests, m.legacy)
+		assert.Equal(t, m.canonical, tests[m.legacy], m.legacy)
+	}
+
+	dap := newDBAttributesProcessor(createDefaultConfig().(*Config))
+	for legacy, canonical := range tests {
+		t.Run(legacy, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.PutStr(legacy, "orders")
+			attrs.PutStr("db.query.plan.operation", "Seq Scan")
+
+			dap.normalize(attrs)
+
+			assert.Equal(t, map[string]any{
+				canonical:                 "orders",
+				"db.query.plan.operation": "Seq Scan",
+			}, attrs.AsRaw())
+		})
+	}
+}
+
+func TestNormalizePrecedence(t *testing.T) {
+	dap := newDBAttributesProcessor(createDefaultConfig().(*Config))
+
+	t.Run("canonical key wins", func(t *testing.T) {
+		attrs := pcommon.NewMap()
+		attrs.PutStr(AttrDBName, "orders")
+		attrs.PutStr("database_name", "stale")
+		dap.normalize(attrs)
+		assert.Equal(t, map[string]any{AttrDBName: "orders"}, attrs.AsRaw())
+	})
+
+	t.Run("first legacy key wins", func(t *testing.T) {
+		attrs := pcommon.NewMap()
+		attrs.PutStr("datname", "from_datname")
+		attrs.PutStr("postgresql.database.name", "from_receiver")
+		attrs.PutStr("attributes.db.name", "from_prefix")
+		dap.normalize(attrs)
+		assert.Equal(t, map[string]any{AttrDBName: "from_receiver"}, attrs.AsRaw())
+	})
+
+	t.Run("value types are kept", func(t *testing.T) {
+		attrs := pcommon.NewMap()
+		attrs.PutInt("net.peer.port", 5432)
+		dap.normalize(attrs)
+		assert.Equal(t, map[string]any{AttrServerPort: int64(5432)}, attrs.AsRaw())
+	})
+}
+
+func TestNormalizeOptions(t *testing.T) {
+	t.Run("keep original", func(t *testing.T) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.KeepOriginal = true
+		attrs := pcommon.NewMap()
+		attrs.PutStr("query_text", "SELECT 1")
+		newDBAttributesProcessor(cfg).normalize(attrs)
+		assert.Equal(t, map[string]any{"query_text": "SELECT 1", AttrDBStatement: "SELECT 1"}, attrs.AsRaw())
+	})
+
+	t.Run("mappings and exclude", func(t *testing.T) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Mappings = map[string]string{"dbname": AttrDBName, "table_name": "db.collection"}
+		cfg.Exclude = []string{"statement"}
+		require.NoError(t, cfg.Validate())
+
+		attrs := pcommon.NewMap()
+		attrs.PutStr("dbname", "orders")
+		attrs.PutStr("table_name", "line_items")
+		attrs.PutStr("statement", "kept as is")
+		newDBAttributesProcessor(cfg).normalize(attrs)
+		assert.Equal(t, map[string]any{
+			AttrDBName:      "orders",
+			"db.collection": "line_items",
+			"statement":     "kept as is",
+		}, attrs.AsRaw())
+	})
+
+	t.Run("without strip prefix", func(t *testing.T) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.StripPrefix = false
+		attrs := pcommon.NewMap()
+		attrs.PutStr("attributes.db.name", "orders")
+		newDBAttributesProcessor(cfg).normalize(attrs)
+		assert.Equal(t, map[string]any{"attributes.db.name": "orders"}, attrs.AsRaw())
+	})
+}
+
+func TestNormalizeSystem(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"postgresql", "postgresql"},
+		{"PostgreSQL", "postgresql"},
+		{"postgres", "postgresql"},
+		{" pg ", "postgresql"},
+		{"MySQL", "mysql"},
+		{"SQL Server", "mssql"},
+		{"mongodb", "mongodb"},
+	}
+
+	dap := newDBAttributesProcessor(createDefaultConfig().(*Config))
+	for _, tt := range tests {
+		attrs := pcommon.NewMap()
+		attrs.PutStr("db_system", tt.in)
+		dap.normalize(attrs)
+		system, ok := attrs.Get(AttrDBSystem)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, system.Str(), tt.in)
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.NormalizeSystem = false
+	attrs := pcommon.NewMap()
+	attrs.PutStr(AttrDBSystem, "Postgres")
+	newDBAttributesProcessor(cfg).normalize(attrs)
+	system, _ := attrs.Get(AttrDBSystem)
+	assert.Equal(t, "Postgres", system.Str())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	proc, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("postgresql.database.name", "orders")
+	rm.Resource().Attributes().PutStr("db_system", "postgres")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("postgres.slow_queries.count")
+	gdp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gdp.Attributes().PutStr("query_text", "SELECT * FROM orders")
+	gdp.Attributes().PutStr("attributes.db.name", "orders")
+
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("db.query.duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.Attributes().PutStr("usename", "app")
+
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), md))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	out := sink.AllMetrics()[0].ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{AttrDBName: "orders", AttrDBSystem: "postgresql"}, out.Resource().Attributes().AsRaw())
+	outMetrics := out.ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, map[string]any{AttrDBStatement: "SELECT * FROM orders", AttrDBName: "orders"},
+		outMetrics.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{AttrDBUser: "app"},
+		outMetrics.At(1).Histogram().DataPoints().At(0).Attributes().AsRaw())
+}
+
+func TestProcessLogs(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	proc, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("database_name", "orders")
+	record := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.Attributes().PutStr("db.query.text", "UPDATE orders SET status = $1")
+	record.Attributes().PutStr("db.collection.name", "orders")
+
+	require.NoError(t, proc.ConsumeLogs(context.Background(), ld))
+	require.Len(t, sink.AllLogs(), 1)
+
+	out := sink.AllLogs()[0].ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{AttrDBName: "orders"}, out.Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		AttrDBStatement: "UPDATE orders SET status = $1",
+		AttrDBSQLTable:  "orders",
+	}, out.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
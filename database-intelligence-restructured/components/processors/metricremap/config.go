@@ -0,0 +1,137 @@
+package metricremap
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Actions for metric and attribute rules
+const (
+	// ActionRename replaces the OTEL name with the OHI name
+	ActionRename = "rename"
+	// ActionCopy keeps the OTEL name and adds the OHI name alongside it
+	ActionCopy = "copy"
+)
+
+// Attribute value transforms
+const (
+	TransformUppercase = "uppercase"
+	TransformLowercase = "lowercase"
+)
+
+// Config defines configuration for the metric remap processor.
+//
+// Rules come from MappingFile, a metric_mappings.yaml in the schema used by
+// the OHI parity validation, and from the inline Metrics and Attributes
+// lists, which are applied after the file's rules.
+type Config struct {
+	// MappingFile is the path of a metric_mappings.yaml file
+	MappingFile string `mapstructure:"mapping_file"`
+
+	// Events limits the file's rules to these OHI event types, e.g.
+	// PostgreSQLSample; every event type is used when empty
+	Events []string `mapstructure:"events"`
+
+	// Action is how the file's rules are applied: rename or copy
+	Action string `mapstructure:"action"`
+
+	// Metrics renames or copies metrics
+	Metrics []MetricRule `mapstructure:"metrics"`
+
+	// Attributes renames or copies resource and data point attributes
+	Attributes []AttributeRule `mapstructure:"attributes"`
+}
+
+// MetricRule maps an OTEL metric name to an OHI one
+type MetricRule struct {
+	// From is the OTEL metric name, e.g. postgresql.commits
+	From string `mapstructure:"from"`
+
+	// To is the name the OHI dashboards query, e.g. db.commitsPerSecond
+	To string `mapstructure:"to"`
+
+	// Action is rename or copy, defaulting to rename
+	Action string `mapstructure:"action"`
+
+	// Scale multiplies every value, e.g. 0.001 for milliseconds to seconds.
+	// Zero leaves values unchanged.
+	Scale float64 `mapstructure:"scale"`
+}
+
+// AttributeRule maps an OTEL attribute key to an OHI one
+type AttributeRule struct {
+	// From is the OTEL attribute key, e.g. postgresql.database.name
+	From string `mapstructure:"from"`
+
+	// To is the OHI attribute key, e.g. database
+	To string `mapstructure:"to"`
+
+	// Action is rename or copy, defaulting to rename
+	Action string `mapstructure:"action"`
+
+	// Transform changes string values: uppercase or lowercase
+	Transform string `mapstructure:"transform"`
+
+	// Values replaces whole values, e.g. "<insufficient privilege>" with
+	// "[REDACTED]"
+	Values map[string]string `mapstructure:"values"`
+
+	// DropValues lists values for which the OHI attribute is not set
+	DropValues []string `mapstructure:"drop_values"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.MappingFile == "" && len(cfg.Metrics) == 0 && len(cfg.Attributes) == 0 {
+		return fmt.Errorf("mapping_file, metrics or attributes must be set")
+	}
+	if cfg.MappingFile == "" && len(cfg.Events) > 0 {
+		return fmt.Errorf("events requires mapping_file")
+	}
+	if err := validateAction(cfg.Action); err != nil {
+		return err
+	}
+	for i, rule := range cfg.Metrics {
+		if rule.From == "" || rule.To == "" {
+			return fmt.Errorf("metrics[%d]: from and to must be set", i)
+		}
+		if err := validateAction(rule.Action); err != nil {
+			return fmt.Errorf("metrics[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range cfg.Attributes {
+		if rule.From == "" || rule.To == "" {
+			return fmt.Errorf("attributes[%d]: from and to must be set", i)
+		}
+		if err := validateAction(rule.Action); err != nil {
+			return fmt.Errorf("attributes[%d]: %w", i, err)
+		}
+		switch rule.Transform {
+		case "", TransformUppercase, TransformLowercase:
+		default:
+			return fmt.Errorf("attributes[%d]: transform must be %s or %s, got %q", i, TransformUppercase, TransformLowercase, rule.Transform)
+		}
+	}
+
+	err := checkRenames("metric", len(cfg.Metrics), func(i int) (string, string, string) {
+		return cfg.Metrics[i].From, cfg.Metrics[i].To, cfg.Metrics[i].Action
+	})
+	if err != nil {
+		return err
+	}
+	return checkRenames("attribute", len(cfg.Attributes), func(i int) (string, string, string) {
+		return cfg.Attributes[i].From, cfg.Attributes[i].To, cfg.Attributes[i].Action
+	})
+}
+
+func validateAction(action string) error {
+	switch action {
+	case "", ActionRename, ActionCopy:
+		return nil
+	default:
+		return fmt.Errorf("action must be %s or %s, got %q", ActionRename, ActionCopy, action)
+	}
+}
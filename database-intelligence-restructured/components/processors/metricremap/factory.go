@@ -0,0 +1,118 @@
+package metricremap
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+var (
+	// componentType is the type of this processor
+	componentType = component.MustNewType("metricremap")
+	// stability is the stability level of this processor
+	stability = component.StabilityLevelAlpha
+)
+
+// NewFactory creates a new processor factory
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		createDefaultConfig,
+		processor.WithMetrics(createMetricsProcessor, stability),
+		processor.WithLogs(createLogsProcessor, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration, which has no rules
+// and must be given a mapping file or inline rules
+func createDefaultConfig() component.Config {
+	return &Config{
+		Action: ActionRename,
+	}
+}
+
+// createMetricsProcessor creates a metrics processor
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	mrp, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		mrp.processMetrics,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// createLogsProcessor creates a logs processor, which applies the attribute
+// rules only
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	mrp, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		mrp.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+func newProcessor(cfg component.Config, set processor.Settings) (*metricRemapProcessor, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	var (
+		metrics    []MetricRule
+		attributes []AttributeRule
+	)
+	if processorConfig.MappingFile != "" {
+		action := processorConfig.Action
+		if action == "" {
+			action = ActionRename
+		}
+		fileMetrics, fileAttributes, skipped, err := loadMappingFile(processorConfig.MappingFile, processorConfig.Events, action)
+		if err != nil {
+			return nil, err
+		}
+		metrics, attributes = fileMetrics, fileAttributes
+		if len(skipped) > 0 {
+			set.Logger.Info("Some OHI fields cannot be produced by renaming and are not mapped",
+				zap.Strings("fields", skipped))
+		}
+	}
+
+	return newMetricRemapProcessor(set.Logger,
+		append(metrics, processorConfig.Metrics...),
+		append(attributes, processorConfig.Attributes...),
+	), nil
+}
@@ -0,0 +1,209 @@
+package metricremap
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mappingFile is the part of metric_mappings.yaml the processor reads. The
+// same file drives the OHI parity validation in tests/e2e.
+type mappingFile struct {
+	Mappings map[string]eventMapping `yaml:"ohi_to_otel_mappings"`
+}
+
+// eventMapping lists the fields of one OHI event type, keyed by OHI name
+type eventMapping struct {
+	Metrics    map[string]fieldMapping `yaml:"metrics"`
+	Attributes map[string]fieldMapping `yaml:"attributes"`
+}
+
+// fieldMapping describes where an OHI field comes from in OTEL
+type fieldMapping struct {
+	OTELName       string             `yaml:"otel_name"`
+	Type           string             `yaml:"type"`
+	Transformation string             `yaml:"transformation"`
+	SpecialValues  map[string]*string `yaml:"special_values"`
+}
+
+// loadMappingFile turns the events of a metric_mappings.yaml into rules
+// applied with action. Fields the processor cannot produce by renaming, such
+// as calculated metrics or per-second rates, are returned as skipped.
+func loadMappingFile(path string, events []string, action string) ([]MetricRule, []AttributeRule, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+	var file mappingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse mapping file %s: %w", path, err)
+	}
+	if len(file.Mappings) == 0 {
+		return nil, nil, nil, fmt.Errorf("mapping file %s has no ohi_to_otel_mappings", path)
+	}
+
+	names := events
+	if len(names) == 0 {
+		for name := range file.Mappings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var (
+		metrics    []MetricRule
+		attributes []AttributeRule
+		skipped    []string
+	)
+	for _, event := range names {
+		mapping, ok := file.Mappings[event]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("mapping file %s has no event %q", path, event)
+		}
+
+		fields := make(map[string]fieldMapping, len(mapping.Metrics)+len(mapping.Attributes))
+		for ohiName, field := range mapping.Attributes {
+			fields[ohiName] = field
+		}
+		for ohiName, field := range mapping.Metrics {
+			// The metrics section holds metrics only, whatever their type
+			if field.Type == "attribute" {
+				field.Type = "gauge"
+			}
+			fields[ohiName] = field
+		}
+
+		ohiNames := make([]string, 0, len(fields))
+		for ohiName := range fields {
+			ohiNames = append(ohiNames, ohiName)
+		}
+		sort.Strings(ohiNames)
+
+		for _, ohiName := range ohiNames {
+			field := fields[ohiName]
+			transform, ok := fileTransform(field)
+			if !ok {
+				skipped = append(skipped, fmt.Sprintf("%s.%s (%s)", event, ohiName, describeField(field)))
+				continue
+			}
+			if field.Type != "attribute" {
+				metrics = append(metrics, MetricRule{From: field.OTELName, To: ohiName, Action: action})
+				continue
+			}
+
+			rule := AttributeRule{From: field.OTELName, To: ohiName, Action: action, Transform: transform}
+			for value, replacement := range field.SpecialValues {
+				if replacement == nil {
+					rule.DropValues = append(rule.DropValues, value)
+					continue
+				}
+				if rule.Values == nil {
+					rule.Values = make(map[string]string)
+				}
+				rule.Values[value] = *replacement
+			}
+			sort.Strings(rule.DropValues)
+			attributes = append(attributes, rule)
+		}
+	}
+	metrics, attributes = dedupeMetricRules(metrics), dedupeAttributeRules(attributes)
+
+	err = checkRenames("metric", len(metrics), func(i int) (string, string, string) {
+		return metrics[i].From, metrics[i].To, metrics[i].Action
+	})
+	if err == nil {
+		err = checkRenames("attribute", len(attributes), func(i int) (string, string, string) {
+			return attributes[i].From, attributes[i].To, attributes[i].Action
+		})
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("mapping file %s: %w; restrict events or use action copy", path, err)
+	}
+	return metrics, attributes, skipped, nil
+}
+
+// fileTransform returns the attribute transform for a field's
+// transformation, or false when renaming cannot produce the field
+func fileTransform(field fieldMapping) (string, bool) {
+	if field.OTELName == "" || field.OTELName == "calculated" {
+		return "", false
+	}
+	switch field.Transformation {
+	case "", "direct", "nested_to_flat":
+		return "", true
+	case "anonymize":
+		// Query text is anonymized before it reaches this processor
+		return "", true
+	case "uppercase":
+		if field.Type != "attribute" {
+			return "", false
+		}
+		return TransformUppercase, true
+	default:
+		// rate_per_second, sum_aggregation and timestamp need state or a
+		// value conversion this processor does not do
+		return "", false
+	}
+}
+
+func describeField(field fieldMapping) string {
+	if field.OTELName == "" || field.OTELName == "calculated" {
+		return "calculated"
+	}
+	return field.Transformation
+}
+
+// The same field often appears in several events; keep one rule for each
+func dedupeMetricRules(rules []MetricRule) []MetricRule {
+	seen := make(map[MetricRule]bool, len(rules))
+	var out []MetricRule
+	for _, rule := range rules {
+		if !seen[rule] {
+			seen[rule] = true
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func dedupeAttributeRules(rules []AttributeRule) []AttributeRule {
+	seen := make(map[string]bool, len(rules))
+	var out []AttributeRule
+	for _, rule := range rules {
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%v\x00%v", rule.From, rule.To, rule.Action, rule.Transform, rule.DropValues, sortedValues(rule.Values))
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func sortedValues(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// checkRenames fails when one name is renamed to two different names, which
+// would make the result depend on rule order. rule returns the i-th rule.
+func checkRenames(kind string, n int, rule func(i int) (from, to, action string)) error {
+	renamed := make(map[string]string)
+	for i := 0; i < n; i++ {
+		from, to, action := rule(i)
+		if action == ActionCopy {
+			continue
+		}
+		if prev, ok := renamed[from]; ok && prev != to {
+			return fmt.Errorf("%s %q is renamed to both %q and %q", kind, from, prev, to)
+		}
+		renamed[from] = to
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package metricremap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMappingFile(t *testing.T) {
+	metrics, attributes, skipped, err := loadMappingFile("testdata/metric_mappings.yaml", nil, ActionRename)
+	require.NoError(t, err)
+
+	assert.Equal(t, []MetricRule{
+		{From: "postgresql.connections.active", To: "db.connections.active", Action: ActionRename},
+		{From: "postgresql.database.size", To: "db.database.sizeInBytes", Action: ActionRename},
+		{From: "db.query.calls", To: "execution_count", Action: ActionRename},
+	}, metrics)
+
+	// database_name appears in two events but is mapped once
+	assert.Equal(t, []AttributeRule{
+		{From: "db.name", To: "database_name", Action: ActionRename},
+		{From: "db.operation", To: "statement_type", Action: ActionRename, Transform: TransformUppercase},
+		{
+			From:       "wait.event_name",
+			To:         "wait_event_name",
+			Action:     ActionRename,
+			Values:     map[string]string{"<insufficient privilege>": "[REDACTED]"},
+			DropValues: []string{"<nil>"},
+		},
+	}, attributes)
+
+	assert.Equal(t, []string{
+		"PostgreSQLSample.db.bufferHitRatio (calculated)",
+		"PostgreSQLSample.db.commitsPerSecond (rate_per_second)",
+	}, skipped)
+}
+
+func TestLoadMappingFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	conflicting := filepath.Join(dir, "conflicting.yaml")
+	require.NoError(t, os.WriteFile(conflicting, []byte(`
+ohi_to_otel_mappings:
+  A:
+    attributes:
+      database_name: {otel_name: db.name, type: attribute}
+  B:
+    attributes:
+      database: {otel_name: db.name, type: attribute}
+`), 0o600))
+
+	_, _, _, err := loadMappingFile("testdata/metric_mappings.yaml", []string{"MySQLSample"}, ActionRename)
+	assert.ErrorContains(t, err, `has no event "MySQLSample"`)
+
+	_, _, _, err = loadMappingFile(filepath.Join(dir, "missing.yaml"), nil, ActionRename)
+	assert.ErrorContains(t, err, "failed to read mapping file")
+
+	_, _, _, err = loadMappingFile(conflicting, nil, ActionRename)
+	assert.ErrorContains(t, err, `attribute "db.name" is renamed to both "database_name" and "database"`)
+
+	// Either event alone, or copying, is fine
+	_, _, _, err = loadMappingFile(conflicting, []string{"B"}, ActionRename)
+	assert.NoError(t, err)
+	_, _, _, err = loadMappingFile(conflicting, nil, ActionCopy)
+	assert.NoError(t, err)
+}
+
+// The processor must accept the mappings the parity validation uses
+func TestLoadValidationMappings(t *testing.T) {
+	path := "../../../tests/e2e/configs/validation/metric_mappings.yaml"
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("validation mappings not found: %v", err)
+	}
+
+	metrics, attributes, _, err := loadMappingFile(path, nil, ActionRename)
+	require.NoError(t, err)
+	assert.Contains(t, metrics, MetricRule{From: "postgresql.connections.active", To: "db.connections.active", Action: ActionRename})
+	assert.Contains(t, attributes, AttributeRule{From: "db.name", To: "database_name", Action: ActionRename})
+}
@@ -0,0 +1,207 @@
+package metricremap
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// metricRemapProcessor renames or copies metrics and attributes so that
+// dashboards written against OHI names keep working
+type metricRemapProcessor struct {
+	logger *zap.Logger
+	// renames and copies are keyed by the OTEL metric name
+	renames map[string]MetricRule
+	copies  map[string][]MetricRule
+	// attributes are applied in order
+	attributes []AttributeRule
+}
+
+// newMetricRemapProcessor indexes the rules. A later rename of the same
+// name replaces an earlier one, so inline rules override the mapping file.
+func newMetricRemapProcessor(logger *zap.Logger, metrics []MetricRule, attributes []AttributeRule) *metricRemapProcessor {
+	mrp := &metricRemapProcessor{
+		logger:  logger,
+		renames: make(map[string]MetricRule),
+		copies:  make(map[string][]MetricRule),
+	}
+	for _, rule := range metrics {
+		if rule.Action == ActionCopy {
+			mrp.copies[rule.From] = append(mrp.copies[rule.From], rule)
+		} else {
+			mrp.renames[rule.From] = rule
+		}
+	}
+
+	renamed := make(map[string]int)
+	for _, rule := range attributes {
+		if rule.Action == ActionCopy {
+			mrp.attributes = append(mrp.attributes, rule)
+			continue
+		}
+		if i, ok := renamed[rule.From]; ok {
+			mrp.attributes[i] = rule
+			continue
+		}
+		renamed[rule.From] = len(mrp.attributes)
+		mrp.attributes = append(mrp.attributes, rule)
+	}
+	return mrp
+}
+
+// processMetrics remaps metric names, then resource and data point
+// attributes, including those of copied metrics
+func (mrp *metricRemapProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		mrp.remapAttributes(rm.Resource().Attributes())
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k, n := 0, metrics.Len(); k < n; k++ {
+				mrp.remapMetric(metrics, metrics.At(k))
+			}
+			for k := 0; k < metrics.Len(); k++ {
+				forEachAttributes(metrics.At(k), mrp.remapAttributes)
+			}
+		}
+	}
+	return md, nil
+}
+
+// processLogs remaps resource and log record attributes
+func (mrp *metricRemapProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		mrp.remapAttributes(rl.Resource().Attributes())
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				mrp.remapAttributes(records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// remapMetric appends the copies of metric to metrics, then renames it
+func (mrp *metricRemapProcessor) remapMetric(metrics pmetric.MetricSlice, metric pmetric.Metric) {
+	name := metric.Name()
+	for _, rule := range mrp.copies[name] {
+		copied := metrics.AppendEmpty()
+		metric.CopyTo(copied)
+		copied.SetName(rule.To)
+		scaleMetric(copied, rule.Scale)
+	}
+	if rule, ok := mrp.renames[name]; ok {
+		metric.SetName(rule.To)
+		scaleMetric(metric, rule.Scale)
+	}
+}
+
+// remapAttributes applies the attribute rules to attrs
+func (mrp *metricRemapProcessor) remapAttributes(attrs pcommon.Map) {
+	for i := range mrp.attributes {
+		mrp.attributes[i].apply(attrs)
+	}
+}
+
+// apply sets the OHI attribute from the OTEL one, removing the OTEL one
+// unless the rule copies
+func (rule *AttributeRule) apply(attrs pcommon.Map) {
+	v, ok := attrs.Get(rule.From)
+	if !ok {
+		return
+	}
+	// Copy out first: Remove and PutEmpty invalidate v
+	value := pcommon.NewValueEmpty()
+	v.CopyTo(value)
+	if rule.Action != ActionCopy {
+		attrs.Remove(rule.From)
+	}
+
+	if value.Type() == pcommon.ValueTypeStr {
+		s := value.Str()
+		if slices.Contains(rule.DropValues, s) {
+			return
+		}
+		if replacement, ok := rule.Values[s]; ok {
+			s = replacement
+		}
+		switch rule.Transform {
+		case TransformUppercase:
+			s = strings.ToUpper(s)
+		case TransformLowercase:
+			s = strings.ToLower(s)
+		}
+		value.SetStr(s)
+	}
+	value.CopyTo(attrs.PutEmpty(rule.To))
+}
+
+// scaleMetric multiplies gauge and sum values by scale. Integer values
+// become doubles unless scale is 1.
+func scaleMetric(metric pmetric.Metric, scale float64) {
+	if scale == 0 || scale == 1 {
+		return
+	}
+	var dps pmetric.NumberDataPointSlice
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps = metric.Gauge().DataPoints()
+	case pmetric.MetricTypeSum:
+		dps = metric.Sum().DataPoints()
+	default:
+		return
+	}
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			dp.SetDoubleValue(float64(dp.IntValue()) * scale)
+		case pmetric.NumberDataPointValueTypeDouble:
+			dp.SetDoubleValue(dp.DoubleValue() * scale)
+		}
+	}
+}
+
+// forEachAttributes calls fn with the attributes of every data point
+func forEachAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package metricremap
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			name:    "default has no rules",
+			modify:  func(*Config) {},
+			wantErr: "mapping_file, metrics or attributes must be set",
+		},
+		{
+			name: "inline rules",
+			modify: func(cfg *Config) {
+				cfg.Metrics = []MetricRule{{From: "postgresql.commits", To: "db.commits", Action: ActionCopy}}
+				cfg.Attributes = []AttributeRule{{From: "postgresql.database.name", To: "database", Transform: TransformLowercase}}
+			},
+		},
+		{
+			name: "events without a file",
+			modify: func(cfg *Config) {
+				cfg.Events = []string{"PostgreSQLSample"}
+				cfg.Attributes = []AttributeRule{{From: "db.name", To: "database_name"}}
+			},
+			wantErr: "events requires mapping_file",
+		},
+		{
+			name: "unknown action",
+			modify: func(cfg *Config) {
+				cfg.Metrics = []MetricRule{{From: "a", To: "b", Action: "move"}}
+			},
+			wantErr: `metrics[0]: action must be rename or copy, got "move"`,
+		},
+		{
+			name: "missing target",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeRule{{From: "db.name"}}
+			},
+			wantErr: "attributes[0]: from and to must be set",
+		},
+		{
+			name: "unknown transform",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeRule{{From: "db.name", To: "database", Transform: "titlecase"}}
+			},
+			wantErr: `attributes[0]: transform must be uppercase or lowercase, got "titlecase"`,
+		},
+		{
+			name: "conflicting renames",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeRule{
+					{From: "db.name", To: "database"},
+					{From: "db.name", To: "database_name"},
+				}
+			},
+			wantErr: `attribute "db.name" is renamed to both "database" and "database_name"`,
+		},
+		{
+			name: "copying to two names",
+			modify: func(cfg *Config) {
+				cfg.Attributes = []AttributeRule{
+					{From: "db.name", To: "database", Action: ActionCopy},
+					{From: "db.name", To: "database_name", Action: ActionCopy},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// names returns the sorted metric names of the first scope
+func names(md pmetric.Metrics) []string {
+	var out []string
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		out = append(out, metrics.At(i).Name())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func findMetric(t *testing.T, md pmetric.Metrics, name string) pmetric.Metric {
+	t.Helper()
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i)
+		}
+	}
+	t.Fatalf("metric %s not found in %v", name, names(md))
+	return pmetric.Metric{}
+}
+
+// otelMetrics builds what the postgresql receiver and the query receivers
+// send: OTEL metric names and attribute keys
+func otelMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("postgresql.database.name", "orders")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	active := ms.AppendEmpty()
+	active.SetName("postgresql.connections.active")
+	active.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(12)
+
+	size := ms.AppendEmpty()
+	size.SetName("postgresql.database.size")
+	size.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(4096)
+
+	calls := ms.AppendEmpty()
+	calls.SetName("db.query.calls")
+	dp := calls.SetEmptySum().DataPoints().AppendEmpty()
+	dp.SetIntValue(40)
+	dp.Attributes().PutStr("db.name", "orders")
+	dp.Attributes().PutStr("db.operation", "select")
+
+	waits := ms.AppendEmpty()
+	waits.SetName("wait.time")
+	wdps := waits.SetEmptySum().DataPoints()
+	wdps.AppendEmpty().Attributes().PutStr("wait.event_name", "<nil>")
+	wdps.AppendEmpty().Attributes().PutStr("wait.event_name", "<insufficient privilege>")
+	wdps.AppendEmpty().Attributes().PutStr("wait.event_name", "ClientRead")
+
+	// Not in the mapping, so left alone
+	commits := ms.AppendEmpty()
+	commits.SetName("postgresql.commits")
+	commits.SetEmptySum().DataPoints().AppendEmpty().SetIntValue(7)
+	return md
+}
+
+func TestProcessMetrics_MappingFileRename(t *testing.T) {
+	cfg := &Config{
+		MappingFile: "testdata/metric_mappings.yaml",
+		Action:      ActionRename,
+		Attributes:  []AttributeRule{{From: "postgresql.database.name", To: "database"}},
+	}
+	sink := &consumertest.MetricsSink{}
+	proc, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), otelMetrics()))
+	got := sink.AllMetrics()[0]
+
+	assert.Equal(t, []string{
+		"db.connections.active",
+		"db.database.sizeInBytes",
+		"execution_count",
+		"postgresql.commits",
+		"wait.time",
+	}, names(got))
+
+	resource := got.ResourceMetrics().At(0).Resource().Attributes()
+	assert.Equal(t, map[string]any{"database": "orders"}, resource.AsRaw())
+
+	calls := findMetric(t, got, "execution_count").Sum().DataPoints().At(0)
+	assert.Equal(t, int64(40), calls.IntValue())
+	assert.Equal(t, map[string]any{"database_name": "orders", "statement_type": "SELECT"}, calls.Attributes().AsRaw())
+
+	waits := findMetric(t, got, "wait.time").Sum().DataPoints()
+	assert.Equal(t, map[string]any{}, waits.At(0).Attributes().AsRaw(), "<nil> is dropped")
+	assert.Equal(t, map[string]any{"wait_event_name": "[REDACTED]"}, waits.At(1).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"wait_event_name": "ClientRead"}, waits.At(2).Attributes().AsRaw())
+}
+
+func TestProcessMetrics_CopyKeepsOTELNames(t *testing.T) {
+	cfg := &Config{
+		MappingFile: "testdata/metric_mappings.yaml",
+		Events:      []string{"PostgresSlowQueries"},
+		Action:      ActionCopy,
+		Metrics: []MetricRule{
+			// Overrides nothing in the file and scales bytes to kilobytes
+			{From: "postgresql.database.size", To: "db.database.sizeInKB", Action: ActionCopy, Scale: 1.0 / 1024},
+		},
+	}
+	sink := &consumertest.MetricsSink{}
+	proc, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), otelMetrics()))
+	got := sink.AllMetrics()[0]
+
+	assert.Equal(t, []string{
+		"db.database.sizeInKB",
+		"db.query.calls",
+		"execution_count",
+		"postgresql.commits",
+		"postgresql.connections.active",
+		"postgresql.database.size",
+		"wait.time",
+	}, names(got))
+
+	assert.Equal(t, int64(4096), findMetric(t, got, "postgresql.database.size").Gauge().DataPoints().At(0).IntValue())
+	assert.Equal(t, 4.0, findMetric(t, got, "db.database.sizeInKB").Gauge().DataPoints().At(0).DoubleValue())
+
+	// Both the original and the copy carry both attribute names
+	for _, name := range []string{"db.query.calls", "execution_count"} {
+		attrs := findMetric(t, got, name).Sum().DataPoints().At(0).Attributes()
+		assert.Equal(t, map[string]any{
+			"db.name":        "orders",
+			"database_name":  "orders",
+			"db.operation":   "select",
+			"statement_type": "SELECT",
+		}, attrs.AsRaw(), name)
+	}
+
+	// Only PostgresSlowQueries was selected
+	waits := findMetric(t, got, "wait.time").Sum().DataPoints()
+	assert.Equal(t, map[string]any{"wait.event_name": "<nil>"}, waits.At(0).Attributes().AsRaw())
+}
+
+func TestProcessMetrics_InlineRenameOverridesFile(t *testing.T) {
+	cfg := &Config{
+		MappingFile: "testdata/metric_mappings.yaml",
+		Events:      []string{"PostgreSQLSample"},
+		Metrics:     []MetricRule{{From: "postgresql.connections.active", To: "db.activeConnections"}},
+	}
+	mrp, err := newProcessor(cfg, processortest.NewNopSettings())
+	require.NoError(t, err)
+
+	got, err := mrp.processMetrics(context.Background(), otelMetrics())
+	require.NoError(t, err)
+	findMetric(t, got, "db.activeConnections")
+	findMetric(t, got, "db.database.sizeInBytes")
+}
+
+func TestProcessLogs_Attributes(t *testing.T) {
+	cfg := &Config{
+		Attributes: []AttributeRule{
+			{From: "db.name", To: "database_name"},
+			{From: "session.blocked.pid", To: "blocked_pid", Action: ActionCopy},
+		},
+	}
+	sink := &consumertest.LogsSink{}
+	proc, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("db.name", "orders")
+	attrs := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes()
+	attrs.PutInt("session.blocked.pid", 4242)
+	attrs.PutStr("db.name", "orders")
+
+	require.NoError(t, proc.ConsumeLogs(context.Background(), logs))
+	got := sink.AllLogs()[0].ResourceLogs().At(0)
+
+	assert.Equal(t, map[string]any{"database_name": "orders"}, got.Resource().Attributes().AsRaw())
+	record := got.ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	assert.Equal(t, map[string]any{
+		"database_name":       "orders",
+		"session.blocked.pid": int64(4242),
+		"blocked_pid":         int64(4242),
+	}, record.AsRaw())
+
+	pid, _ := record.Get("blocked_pid")
+	assert.Equal(t, pcommon.ValueTypeInt, pid.Type(), "non-string values keep their type")
+}
@@ -29,6 +29,25 @@ type Config struct {
 	
 	// EnableProactiveValidation performs additional checks
 	EnableProactiveValidation bool `mapstructure:"enable_proactive_validation"`
+
+	// SQLStateAttributes are the attributes checked, in order, for a
+	// SQLSTATE to classify into error.code, error.class and error.severity.
+	// An empty list disables classification.
+	SQLStateAttributes []string `mapstructure:"sqlstate_attributes"`
+
+	// ErrorEventSampling bounds the error events the processor emits itself,
+	// so a mass failure does not turn into a flood of error telemetry
+	ErrorEventSampling ErrorEventSamplingConfig `mapstructure:"error_event_sampling"`
+}
+
+// ErrorEventSamplingConfig keeps the first Initial events of each error
+// category per second, then every Thereafter-th, with those sampled events
+// capped at MaxEventsPerSecond across categories. Alerts are never sampled.
+type ErrorEventSamplingConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	Initial            int  `mapstructure:"initial"`
+	Thereafter         int  `mapstructure:"thereafter"`
+	MaxEventsPerSecond int  `mapstructure:"max_events_per_second"`
 }
 
 // Validate checks the processor configuration
@@ -53,9 +72,27 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("reporting_interval must be positive")
 	}
 	
+	if cfg.ErrorEventSampling.Enabled {
+		if cfg.ErrorEventSampling.Initial < 0 {
+			return fmt.Errorf("error_event_sampling.initial cannot be negative")
+		}
+		if cfg.ErrorEventSampling.Thereafter <= 0 {
+			return fmt.Errorf("error_event_sampling.thereafter must be positive")
+		}
+		if cfg.ErrorEventSampling.MaxEventsPerSecond <= 0 {
+			return fmt.Errorf("error_event_sampling.max_events_per_second must be positive")
+		}
+	}
+	
 	return nil
 }
 
+// defaultSQLStateAttributes are where the receivers and sqlquery
+// configurations put the SQLSTATE
+func defaultSQLStateAttributes() []string {
+	return []string{"db.postgresql.sqlstate", "sqlstate", "error.code"}
+}
+
 // createDefaultConfig creates the default configuration
 func createDefaultConfig() component.Config {
 	return &Config{
@@ -66,5 +103,12 @@ func createDefaultConfig() component.Config {
 		ReportingInterval:           60 * time.Second,
 		ErrorSuppressionDuration:    5 * time.Minute,
 		EnableProactiveValidation:   true,
+		SQLStateAttributes:          defaultSQLStateAttributes(),
+		ErrorEventSampling: ErrorEventSamplingConfig{
+			Enabled:            true,
+			Initial:            10,
+			Thereafter:         100,
+			MaxEventsPerSecond: 10,
+		},
 	}
 }
\ No newline at end of file
@@ -0,0 +1,53 @@
+package nrerrormonitor
+
+import (
+	"time"
+)
+
+// errorEventSampler decides which error events the processor emits. Within
+// each one-second window the first initial events of a category are always
+// kept, so new problems are never hidden. After that every thereafter-th
+// event of the category is a candidate, and candidates from all categories
+// share a budget of maxPerSecond. Callers serialize access.
+type errorEventSampler struct {
+	initial      int64
+	thereafter   int64
+	maxPerSecond int
+
+	windowStart time.Time
+	counts      map[string]int64 // events per category in the window
+	sampled     int              // candidates kept in the window
+
+	now func() time.Time
+}
+
+func newErrorEventSampler(cfg ErrorEventSamplingConfig) *errorEventSampler {
+	return &errorEventSampler{
+		initial:      int64(cfg.Initial),
+		thereafter:   int64(cfg.Thereafter),
+		maxPerSecond: cfg.MaxEventsPerSecond,
+		counts:       make(map[string]int64),
+		now:          time.Now,
+	}
+}
+
+// allow reports whether an event of the category should be emitted
+func (s *errorEventSampler) allow(category string) bool {
+	now := s.now()
+	if now.Sub(s.windowStart) >= time.Second {
+		s.windowStart = now
+		s.sampled = 0
+		clear(s.counts)
+	}
+
+	s.counts[category]++
+	n := s.counts[category]
+	if n <= s.initial {
+		return true
+	}
+	if (n-s.initial-1)%s.thereafter != 0 || s.sampled >= s.maxPerSecond {
+		return false
+	}
+	s.sampled++
+	return true
+}
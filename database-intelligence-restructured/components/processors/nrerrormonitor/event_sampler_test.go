@@ -0,0 +1,76 @@
+package nrerrormonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorEventSampler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newErrorEventSampler(ErrorEventSamplingConfig{Initial: 2, Thereafter: 3, MaxEventsPerSecond: 2})
+	s.now = func() time.Time { return now }
+
+	allowed := func(category string, n int) []bool {
+		var got []bool
+		for i := 0; i < n; i++ {
+			got = append(got, s.allow(category))
+		}
+		return got
+	}
+
+	// First two always, then every third until the budget of two runs out
+	assert.Equal(t,
+		[]bool{true, true, true, false, false, true, false, false, false, false},
+		allowed("high_cardinality", 10))
+
+	// Another category still gets its initial events, but shares the budget
+	assert.Equal(t, []bool{true, true, false, false, false}, allowed("missing_attribute", 5))
+
+	// A new window starts over
+	now = now.Add(time.Second)
+	assert.Equal(t, []bool{true, true, true}, allowed("high_cardinality", 3))
+}
+
+func TestErrorEventSamplingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "zero sampling thereafter",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling.Thereafter = 0
+			},
+			wantErr: "error_event_sampling.thereafter must be positive",
+		},
+		{
+			name: "zero sampling rate",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling.MaxEventsPerSecond = 0
+			},
+			wantErr: "error_event_sampling.max_events_per_second must be positive",
+		},
+		{
+			name: "sampling settings ignored when disabled",
+			modify: func(cfg *Config) {
+				cfg.ErrorEventSampling = ErrorEventSamplingConfig{Enabled: false}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CreateDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
 )
 
 const (
@@ -23,6 +24,7 @@ func NewFactory() processor.Factory {
 		component.MustNewType(TypeStr),
 		CreateDefaultConfig,
 		processor.WithMetrics(createMetricsProcessor, stability),
+		processor.WithLogs(createLogsProcessor, stability),
 	)
 }
 
@@ -36,6 +38,13 @@ func CreateDefaultConfig() component.Config {
 		ReportingInterval:           60 * time.Second,
 		ErrorSuppressionDuration:    5 * time.Minute,
 		EnableProactiveValidation:   true,
+		SQLStateAttributes:          defaultSQLStateAttributes(),
+		ErrorEventSampling: ErrorEventSamplingConfig{
+			Enabled:            true,
+			Initial:            10,
+			Thereafter:         100,
+			MaxEventsPerSecond: 10,
+		},
 	}
 }
 
@@ -58,4 +67,32 @@ func createMetricsProcessor(
 	processor := newNrErrorMonitor(processorConfig, set.Logger, nextConsumer)
 
 	return processor, nil
-}
\ No newline at end of file
+}
+
+// createLogsProcessor creates a logs processor that classifies the SQLSTATE
+// of log records
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	classifier := &sqlStateClassifier{attributes: processorConfig.SQLStateAttributes}
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		classifier.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: len(processorConfig.SQLStateAttributes) > 0}),
+	)
+}
@@ -0,0 +1,261 @@
+package nrerrormonitor
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Attributes set on records that carry a SQLSTATE
+const (
+	attrErrorCode     = "error.code"
+	attrErrorClass    = "error.class"
+	attrErrorSeverity = "error.severity"
+)
+
+// Severities of SQLSTATE classes, from least to most severe
+const (
+	severityInfo     = "info"
+	severityWarning  = "warning"
+	severityError    = "error"
+	severityCritical = "critical"
+)
+
+// sqlStateClass is a PostgreSQL error class, the first two characters of a
+// SQLSTATE
+type sqlStateClass struct {
+	name     string
+	severity string
+}
+
+// sqlStateClasses are the standard PostgreSQL error classes (Appendix A of
+// the PostgreSQL manual). Errors the client can fix by changing the statement
+// are error; transaction rollbacks, which succeed when retried, are warning;
+// errors of the server or its environment are critical.
+var sqlStateClasses = map[string]sqlStateClass{
+	"00": {"successful_completion", severityInfo},
+	"01": {"warning", severityWarning},
+	"02": {"no_data", severityInfo},
+	"03": {"sql_statement_not_yet_complete", severityError},
+	"08": {"connection_exception", severityCritical},
+	"09": {"triggered_action_exception", severityError},
+	"0A": {"feature_not_supported", severityError},
+	"0B": {"invalid_transaction_initiation", severityError},
+	"0F": {"locator_exception", severityError},
+	"0L": {"invalid_grantor", severityError},
+	"0P": {"invalid_role_specification", severityError},
+	"0Z": {"diagnostics_exception", severityError},
+	"20": {"case_not_found", severityError},
+	"21": {"cardinality_violation", severityError},
+	"22": {"data_exception", severityError},
+	"23": {"integrity_constraint_violation", severityError},
+	"24": {"invalid_cursor_state", severityError},
+	"25": {"invalid_transaction_state", severityError},
+	"26": {"invalid_sql_statement_name", severityError},
+	"27": {"triggered_data_change_violation", severityError},
+	"28": {"invalid_authorization_specification", severityError},
+	"2B": {"dependent_privilege_descriptors_still_exist", severityError},
+	"2D": {"invalid_transaction_termination", severityError},
+	"2F": {"sql_routine_exception", severityError},
+	"34": {"invalid_cursor_name", severityError},
+	"38": {"external_routine_exception", severityError},
+	"39": {"external_routine_invocation_exception", severityError},
+	"3B": {"savepoint_exception", severityError},
+	"3D": {"invalid_catalog_name", severityError},
+	"3F": {"invalid_schema_name", severityError},
+	"40": {"transaction_rollback", severityWarning},
+	"42": {"syntax_error_or_access_rule_violation", severityError},
+	"44": {"with_check_option_violation", severityError},
+	"53": {"insufficient_resources", severityCritical},
+	"54": {"program_limit_exceeded", severityError},
+	"55": {"object_not_in_prerequisite_state", severityError},
+	"57": {"operator_intervention", severityCritical},
+	"58": {"system_error", severityCritical},
+	"72": {"snapshot_too_old", severityError},
+	"F0": {"config_file_error", severityCritical},
+	"HV": {"fdw_error", severityError},
+	"P0": {"plpgsql_error", severityError},
+	"XX": {"internal_error", severityCritical},
+}
+
+// sqlStateConditions names the conditions seen most often in database
+// monitoring. A code that is not listed is classified by its class.
+var sqlStateConditions = map[string]string{
+	"08000": "connection_exception",
+	"08003": "connection_does_not_exist",
+	"08006": "connection_failure",
+	"08P01": "protocol_violation",
+	"0A000": "feature_not_supported",
+	"21000": "cardinality_violation",
+	"22001": "string_data_right_truncation",
+	"22003": "numeric_value_out_of_range",
+	"22007": "invalid_datetime_format",
+	"22008": "datetime_field_overflow",
+	"22012": "division_by_zero",
+	"22P02": "invalid_text_representation",
+	"23502": "not_null_violation",
+	"23503": "foreign_key_violation",
+	"23505": "unique_violation",
+	"23514": "check_violation",
+	"23P01": "exclusion_violation",
+	"25001": "active_sql_transaction",
+	"25P02": "in_failed_sql_transaction",
+	"28000": "invalid_authorization_specification",
+	"28P01": "invalid_password",
+	"3D000": "invalid_catalog_name",
+	"3F000": "invalid_schema_name",
+	"40001": "serialization_failure",
+	"40P01": "deadlock_detected",
+	"42501": "insufficient_privilege",
+	"42601": "syntax_error",
+	"42703": "undefined_column",
+	"42704": "undefined_object",
+	"42710": "duplicate_object",
+	"42883": "undefined_function",
+	"42P01": "undefined_table",
+	"42P07": "duplicate_table",
+	"53100": "disk_full",
+	"53200": "out_of_memory",
+	"53300": "too_many_connections",
+	"54000": "program_limit_exceeded",
+	"55P03": "lock_not_available",
+	"57014": "query_canceled",
+	"57P01": "admin_shutdown",
+	"57P02": "crash_shutdown",
+	"57P03": "cannot_connect_now",
+	"58030": "io_error",
+	"XX000": "internal_error",
+	"XX001": "data_corrupted",
+	"XX002": "index_corrupted",
+}
+
+// SQLStateClassification is the meaning of a SQLSTATE
+type SQLStateClassification struct {
+	// Code is the normalized five character SQLSTATE
+	Code string
+	// Class is the condition name, such as deadlock_detected, or the class
+	// name when the condition is not known
+	Class string
+	// Severity is info, warning, error or critical
+	Severity string
+}
+
+// ClassifySQLState classifies a SQLSTATE. It reports false when code is not
+// five letters or digits or its class is not a PostgreSQL error class.
+func ClassifySQLState(code string) (SQLStateClassification, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !isSQLState(code) {
+		return SQLStateClassification{}, false
+	}
+
+	class, ok := sqlStateClasses[code[:2]]
+	if !ok {
+		return SQLStateClassification{}, false
+	}
+
+	name, ok := sqlStateConditions[code]
+	if !ok {
+		name = class.name
+	}
+	return SQLStateClassification{Code: code, Class: name, Severity: class.severity}, true
+}
+
+func isSQLState(code string) bool {
+	if len(code) != 5 {
+		return false
+	}
+	for _, c := range code {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlStateClassifier attaches error.code, error.class and error.severity to
+// records that carry a SQLSTATE in one of the configured attributes
+type sqlStateClassifier struct {
+	attributes []string
+}
+
+// classifyAttributes classifies the first SQLSTATE found in attrs. Codes that
+// cannot be classified are left alone.
+func (c *sqlStateClassifier) classifyAttributes(attrs pcommon.Map) {
+	for _, name := range c.attributes {
+		value, ok := attrs.Get(name)
+		if !ok || value.Type() != pcommon.ValueTypeStr || value.Str() == "" {
+			continue
+		}
+		classification, ok := ClassifySQLState(value.Str())
+		if !ok {
+			return
+		}
+		attrs.PutStr(attrErrorCode, classification.Code)
+		attrs.PutStr(attrErrorClass, classification.Class)
+		attrs.PutStr(attrErrorSeverity, classification.Severity)
+		return
+	}
+}
+
+// processLogs classifies every log record that carries a SQLSTATE, such as
+// the records of the pgserverlog receiver
+func (c *sqlStateClassifier) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				c.classifyAttributes(records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// classifyMetrics classifies every data point that carries a SQLSTATE
+func (c *sqlStateClassifier) classifyMetrics(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				c.classifyMetric(metrics.At(k))
+			}
+		}
+	}
+}
+
+func (c *sqlStateClassifier) classifyMetric(metric pmetric.Metric) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			c.classifyAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			c.classifyAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			c.classifyAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			c.classifyAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			c.classifyAttributes(dps.At(i).Attributes())
+		}
+	}
+}
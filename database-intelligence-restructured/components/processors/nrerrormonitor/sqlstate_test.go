@@ -0,0 +1,124 @@
+package nrerrormonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.uber.org/zap"
+)
+
+func TestClassifySQLState(t *testing.T) {
+	tests := []struct {
+		code string
+		want SQLStateClassification
+	}{
+		// The codes the e2e error tests provoke
+		{"42601", SQLStateClassification{Code: "42601", Class: "syntax_error", Severity: "error"}},
+		{"42P01", SQLStateClassification{Code: "42P01", Class: "undefined_table", Severity: "error"}},
+		{"22012", SQLStateClassification{Code: "22012", Class: "division_by_zero", Severity: "error"}},
+		{"23514", SQLStateClassification{Code: "23514", Class: "check_violation", Severity: "error"}},
+		{"40P01", SQLStateClassification{Code: "40P01", Class: "deadlock_detected", Severity: "warning"}},
+
+		// Unlisted conditions fall back to their class
+		{"22P05", SQLStateClassification{Code: "22P05", Class: "data_exception", Severity: "error"}},
+		{"53400", SQLStateClassification{Code: "53400", Class: "insufficient_resources", Severity: "critical"}},
+		{" 42p01 ", SQLStateClassification{Code: "42P01", Class: "undefined_table", Severity: "error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := ClassifySQLState(tt.code)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClassifySQLStateRejectsInvalidCodes(t *testing.T) {
+	for _, code := range []string{"", "4260", "426011", "42-01", "ZZ000"} {
+		_, ok := ClassifySQLState(code)
+		assert.False(t, ok, code)
+	}
+}
+
+func TestSQLStateClassesAreTwoCharacters(t *testing.T) {
+	for prefix, class := range sqlStateClasses {
+		assert.Len(t, prefix, 2)
+		assert.Contains(t, []string{severityInfo, severityWarning, severityError, severityCritical}, class.severity, prefix)
+	}
+	for code := range sqlStateConditions {
+		_, ok := sqlStateClasses[code[:2]]
+		assert.True(t, ok, "condition %s has no class", code)
+	}
+}
+
+func TestLogsProcessorClassifiesSQLState(t *testing.T) {
+	factory := NewFactory()
+	sink := &consumertest.LogsSink{}
+	lp, err := factory.CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), factory.CreateDefaultConfig(), sink)
+	require.NoError(t, err)
+	assert.True(t, lp.Capabilities().MutatesData)
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Attributes().PutStr("db.postgresql.sqlstate", "40P01")
+	records.AppendEmpty().Attributes().PutStr("db.statement", "SELECT 1")
+	records.AppendEmpty().Attributes().PutStr("sqlstate", "not a code")
+
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, map[string]any{
+		"db.postgresql.sqlstate": "40P01",
+		"error.code":             "40P01",
+		"error.class":            "deadlock_detected",
+		"error.severity":         "warning",
+	}, got.At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"db.statement": "SELECT 1"}, got.At(1).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"sqlstate": "not a code"}, got.At(2).Attributes().AsRaw())
+}
+
+func TestMetricsProcessorClassifiesSQLState(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	sink := &consumertest.MetricsSink{}
+	processor := newNrErrorMonitor(cfg, zap.NewNop(), sink)
+	assert.True(t, processor.Capabilities().MutatesData)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "test-service")
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("postgres.errors")
+	dp := metric.SetEmptySum().DataPoints().AppendEmpty()
+	dp.SetIntValue(3)
+	dp.Attributes().PutStr("error.code", "23514")
+
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), md))
+	attrs := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).Attributes()
+	assert.Equal(t, map[string]any{
+		"error.code":     "23514",
+		"error.class":    "check_violation",
+		"error.severity": "error",
+	}, attrs.AsRaw())
+}
+
+func TestSQLStateClassificationDisabled(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.SQLStateAttributes = nil
+	sink := &consumertest.MetricsSink{}
+	processor := newNrErrorMonitor(cfg, zap.NewNop(), sink)
+	assert.False(t, processor.Capabilities().MutatesData)
+
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("postgres.errors")
+	metric.SetEmptyGauge().DataPoints().AppendEmpty().Attributes().PutStr("sqlstate", "42601")
+
+	require.NoError(t, processor.ConsumeMetrics(context.Background(), md))
+	attrs := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()
+	assert.Equal(t, map[string]any{"sqlstate": "42601"}, attrs.AsRaw())
+}
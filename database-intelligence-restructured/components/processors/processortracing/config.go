@@ -0,0 +1,55 @@
+package processortracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// configKey is the section a wrapped processor's configuration gains
+const configKey = "tracing"
+
+// Config controls the tracing of a wrapped processor
+type Config struct {
+	// Enabled starts a span for every batch the processor consumes
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// wrappedConfig is the wrapped processor's own configuration with the
+// tracing section next to its settings
+type wrappedConfig struct {
+	// processor is unexported so that it is unmarshaled and validated by
+	// this type rather than by reflection on its fields
+	processor component.Config
+
+	Tracing Config `mapstructure:"tracing"`
+}
+
+// Unmarshal implements confmap.Unmarshaler. The tracing section is taken out
+// and everything else is passed to the wrapped processor's configuration,
+// which still rejects keys it does not know.
+func (cfg *wrappedConfig) Unmarshal(conf *confmap.Conf) error {
+	if conf == nil {
+		return nil
+	}
+
+	if conf.IsSet(configKey) {
+		section, err := conf.Sub(configKey)
+		if err != nil {
+			return fmt.Errorf("invalid %s section: %w", configKey, err)
+		}
+		if err := section.Unmarshal(&cfg.Tracing); err != nil {
+			return fmt.Errorf("invalid %s section: %w", configKey, err)
+		}
+	}
+
+	settings := conf.ToStringMap()
+	delete(settings, configKey)
+	return confmap.NewFromStringMap(settings).Unmarshal(cfg.processor)
+}
+
+// Validate validates the wrapped processor's configuration
+func (cfg *wrappedConfig) Validate() error {
+	return component.ValidateConfig(cfg.processor)
+}
@@ -0,0 +1,69 @@
+package processortracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+)
+
+var errConfigNotWrapped = errors.New("config is not a tracing wrapped processor config")
+
+// Wrap returns a factory of the same type as factory whose processors can
+// trace the batches they consume. The configuration is the wrapped
+// processor's own with an optional tracing section; with it enabled, every
+// ConsumeTraces, ConsumeMetrics and ConsumeLogs call runs in a span from the
+// collector's tracer provider. Without it batches are passed straight to the
+// processor, so tracing costs nothing when it is off.
+func Wrap(factory processor.Factory) processor.Factory {
+	var options []processor.FactoryOption
+	if stability := factory.TracesProcessorStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, processor.WithTraces(func(ctx context.Context, settings processor.Settings, cfg component.Config, next consumer.Traces) (processor.Traces, error) {
+			wrapped, ok := cfg.(*wrappedConfig)
+			if !ok {
+				return nil, errConfigNotWrapped
+			}
+			proc, err := factory.CreateTracesProcessor(ctx, settings, wrapped.processor, next)
+			if err != nil {
+				return nil, err
+			}
+			return &tracedTraces{Traces: proc, reconfigurer: newReconfigurer(proc, wrapped.Tracing), tracer: newTracer(settings, wrapped.Tracing)}, nil
+		}, stability))
+	}
+	if stability := factory.MetricsProcessorStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, processor.WithMetrics(func(ctx context.Context, settings processor.Settings, cfg component.Config, next consumer.Metrics) (processor.Metrics, error) {
+			wrapped, ok := cfg.(*wrappedConfig)
+			if !ok {
+				return nil, errConfigNotWrapped
+			}
+			proc, err := factory.CreateMetricsProcessor(ctx, settings, wrapped.processor, next)
+			if err != nil {
+				return nil, err
+			}
+			return &tracedMetrics{Metrics: proc, reconfigurer: newReconfigurer(proc, wrapped.Tracing), tracer: newTracer(settings, wrapped.Tracing)}, nil
+		}, stability))
+	}
+	if stability := factory.LogsProcessorStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, processor.WithLogs(func(ctx context.Context, settings processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+			wrapped, ok := cfg.(*wrappedConfig)
+			if !ok {
+				return nil, errConfigNotWrapped
+			}
+			proc, err := factory.CreateLogsProcessor(ctx, settings, wrapped.processor, next)
+			if err != nil {
+				return nil, err
+			}
+			return &tracedLogs{Logs: proc, reconfigurer: newReconfigurer(proc, wrapped.Tracing), tracer: newTracer(settings, wrapped.Tracing)}, nil
+		}, stability))
+	}
+
+	return processor.NewFactory(
+		factory.Type(),
+		func() component.Config {
+			return &wrappedConfig{processor: factory.CreateDefaultConfig()}
+		},
+		options...,
+	)
+}
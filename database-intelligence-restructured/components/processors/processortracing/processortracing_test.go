@@ -0,0 +1,257 @@
+package processortracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/database-intelligence/db-intel/components/processors/base"
+)
+
+// stubConfig is the wrapped processor's configuration
+type stubConfig struct {
+	Limit int `mapstructure:"limit"`
+}
+
+func (cfg *stubConfig) Validate() error {
+	if cfg.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// stubProcessor passes batches on, failing when it is told to
+type stubProcessor struct {
+	component.StartFunc
+	component.ShutdownFunc
+	nextMetrics consumer.Metrics
+	nextLogs    consumer.Logs
+	err         error
+	config      *stubConfig
+}
+
+func (p *stubProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (p *stubProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.nextMetrics.ConsumeMetrics(ctx, md)
+}
+
+func (p *stubProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.nextLogs.ConsumeLogs(ctx, ld)
+}
+
+func (p *stubProcessor) Reconfigure(cfg component.Config) error {
+	p.config = cfg.(*stubConfig)
+	return nil
+}
+
+func newStubFactory(created *[]*stubProcessor) processor.Factory {
+	return processor.NewFactory(
+		component.MustNewType("stubproc"),
+		func() component.Config { return &stubConfig{} },
+		processor.WithMetrics(func(_ context.Context, _ processor.Settings, cfg component.Config, next consumer.Metrics) (processor.Metrics, error) {
+			p := &stubProcessor{nextMetrics: next, config: cfg.(*stubConfig)}
+			*created = append(*created, p)
+			return p, nil
+		}, component.StabilityLevelAlpha),
+		processor.WithLogs(func(_ context.Context, _ processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+			p := &stubProcessor{nextLogs: next, config: cfg.(*stubConfig)}
+			*created = append(*created, p)
+			return p, nil
+		}, component.StabilityLevelAlpha),
+	)
+}
+
+// newConfig returns the wrapped factory's configuration from settings
+func newConfig(t *testing.T, factory processor.Factory, settings map[string]any) component.Config {
+	t.Helper()
+	cfg := factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(settings).Unmarshal(cfg))
+	require.NoError(t, component.ValidateConfig(cfg))
+	return cfg
+}
+
+// newSettings returns settings for the named processor whose tracer
+// provider records spans in exporter
+func newSettings(name string, exporter *tracetest.InMemoryExporter) processor.Settings {
+	set := processortest.NewNopSettings()
+	set.ID = component.MustNewIDWithName("stubproc", name)
+	set.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return set
+}
+
+func metricsBatch(points int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	dps := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints()
+	for i := 0; i < points; i++ {
+		dps.AppendEmpty().SetIntValue(int64(i))
+	}
+	return md
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestMetricsSpansFormPipelineTrace(t *testing.T) {
+	var created []*stubProcessor
+	factory := Wrap(newStubFactory(&created))
+	cfg := newConfig(t, factory, map[string]any{"tracing": map[string]any{"enabled": true}})
+	exporter := tracetest.NewInMemoryExporter()
+
+	// Two processors in a pipeline, the first sending to the second
+	sink := &consumertest.MetricsSink{}
+	second, err := factory.CreateMetricsProcessor(context.Background(), newSettings("second", exporter), cfg, sink)
+	require.NoError(t, err)
+	first, err := factory.CreateMetricsProcessor(context.Background(), newSettings("first", exporter), cfg, second)
+	require.NoError(t, err)
+	assert.Equal(t, consumer.Capabilities{MutatesData: true}, first.Capabilities())
+
+	require.NoError(t, first.ConsumeMetrics(context.Background(), metricsBatch(3)))
+	assert.Equal(t, 3, sink.DataPointCount(), "the batch must be passed on")
+
+	// The inner span ends first
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	inner, outer := spans[0], spans[1]
+
+	assert.Equal(t, "stubproc/first ConsumeMetrics", outer.Name)
+	assert.Equal(t, "stubproc/second ConsumeMetrics", inner.Name)
+	assert.Equal(t, scopeName, outer.InstrumentationLibrary.Name)
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		attributeProcessor: attribute.StringValue("stubproc/first"),
+		attributeRecords:   attribute.IntValue(3),
+	}, spanAttributes(outer))
+	assert.Equal(t, "stubproc/second", spanAttributes(inner)[attributeProcessor].AsString())
+
+	assert.Equal(t, outer.SpanContext.TraceID(), inner.SpanContext.TraceID(), "the batch forms one trace")
+	assert.Equal(t, outer.SpanContext.SpanID(), inner.Parent.SpanID())
+	assert.False(t, inner.StartTime.Before(outer.StartTime))
+	assert.False(t, inner.EndTime.After(outer.EndTime))
+}
+
+func TestLogsSpanRecordsError(t *testing.T) {
+	var created []*stubProcessor
+	factory := Wrap(newStubFactory(&created))
+	cfg := newConfig(t, factory, map[string]any{"tracing": map[string]any{"enabled": true}})
+	exporter := tracetest.NewInMemoryExporter()
+
+	proc, err := factory.CreateLogsProcessor(context.Background(), newSettings("", exporter), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	created[0].err = errors.New("budget exceeded")
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty()
+	records.AppendEmpty()
+	assert.EqualError(t, proc.ConsumeLogs(context.Background(), ld), "budget exceeded")
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "stubproc ConsumeLogs", spans[0].Name)
+	assert.Equal(t, attribute.IntValue(2), spanAttributes(spans[0])[attributeRecords])
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "budget exceeded", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
+
+func TestDisabledByDefault(t *testing.T) {
+	var created []*stubProcessor
+	factory := Wrap(newStubFactory(&created))
+	cfg := newConfig(t, factory, map[string]any{"limit": 5})
+	assert.Equal(t, 5, cfg.(*wrappedConfig).processor.(*stubConfig).Limit)
+	exporter := tracetest.NewInMemoryExporter()
+
+	sink := &consumertest.MetricsSink{}
+	proc, err := factory.CreateMetricsProcessor(context.Background(), newSettings("", exporter), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), metricsBatch(2)))
+
+	assert.Equal(t, 2, sink.DataPointCount())
+	assert.Empty(t, exporter.GetSpans())
+	assert.Nil(t, proc.(*tracedMetrics).tracer)
+}
+
+func TestReconfigure(t *testing.T) {
+	var created []*stubProcessor
+	factory := Wrap(newStubFactory(&created))
+	cfg := newConfig(t, factory, map[string]any{"limit": 5})
+
+	proc, err := factory.CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	reconfigurable, ok := proc.(base.Reconfigurable)
+	require.True(t, ok, "a wrapped processor must stay reconfigurable")
+
+	require.NoError(t, reconfigurable.Reconfigure(newConfig(t, factory, map[string]any{"limit": 7})))
+	assert.Equal(t, 7, created[0].config.Limit, "the processor must get its own configuration")
+
+	err = reconfigurable.Reconfigure(newConfig(t, factory, map[string]any{"limit": 7, "tracing": map[string]any{"enabled": true}}))
+	assert.ErrorIs(t, err, base.ErrRestartRequired)
+	assert.Equal(t, 7, created[0].config.Limit)
+
+	assert.Equal(t, errConfigNotWrapped, reconfigurable.Reconfigure(&stubConfig{}))
+}
+
+func TestReconfigureUnsupported(t *testing.T) {
+	factory := Wrap(processor.NewFactory(
+		component.MustNewType("stubproc"),
+		func() component.Config { return &stubConfig{} },
+		processor.WithMetrics(func(_ context.Context, _ processor.Settings, _ component.Config, next consumer.Metrics) (processor.Metrics, error) {
+			return processortest.NewNopFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), nil, next)
+		}, component.StabilityLevelAlpha),
+	))
+	cfg := newConfig(t, factory, map[string]any{})
+
+	proc, err := factory.CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	err = proc.(base.Reconfigurable).Reconfigure(cfg)
+	assert.ErrorIs(t, err, base.ErrRestartRequired)
+}
+
+func TestWrappedConfig(t *testing.T) {
+	var created []*stubProcessor
+	factory := Wrap(newStubFactory(&created))
+	assert.Equal(t, "stubproc", factory.Type().String())
+	assert.Equal(t, component.StabilityLevelAlpha, factory.MetricsProcessorStability())
+	assert.Equal(t, component.StabilityLevelAlpha, factory.LogsProcessorStability())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.TracesProcessorStability())
+
+	cfg := factory.CreateDefaultConfig()
+	err := confmap.NewFromStringMap(map[string]any{"limit": 1, "threshold": 2}).Unmarshal(cfg)
+	assert.ErrorContains(t, err, "threshold", "the wrapped processor must reject unknown keys")
+
+	cfg = factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{"limit": -1}).Unmarshal(cfg))
+	assert.EqualError(t, component.ValidateConfig(cfg), "limit must not be negative")
+
+	err = confmap.NewFromStringMap(map[string]any{"tracing": map[string]any{"sample": 0.5}}).Unmarshal(factory.CreateDefaultConfig())
+	assert.ErrorContains(t, err, "invalid tracing section")
+}
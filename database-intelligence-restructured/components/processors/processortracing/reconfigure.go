@@ -0,0 +1,37 @@
+package processortracing
+
+import (
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/processors/base"
+	"go.opentelemetry.io/collector/component"
+)
+
+// reconfigurer implements base.Reconfigurable for a wrapped processor, so a
+// config reload can still reconfigure it in place
+type reconfigurer struct {
+	processor component.Component
+	tracing   Config
+}
+
+func newReconfigurer(processor component.Component, tracing Config) reconfigurer {
+	return reconfigurer{processor: processor, tracing: tracing}
+}
+
+// Reconfigure implements base.Reconfigurable. The wrapped processor gets its
+// own section of cfg. Turning tracing on or off, or reconfiguring a processor
+// that cannot be reconfigured, needs a restart.
+func (r reconfigurer) Reconfigure(cfg component.Config) error {
+	wrapped, ok := cfg.(*wrappedConfig)
+	if !ok {
+		return errConfigNotWrapped
+	}
+	if wrapped.Tracing != r.tracing {
+		return fmt.Errorf("%w: %s changed", base.ErrRestartRequired, configKey)
+	}
+	reconfigurable, ok := r.processor.(base.Reconfigurable)
+	if !ok {
+		return fmt.Errorf("%w: the processor cannot be reconfigured at runtime", base.ErrRestartRequired)
+	}
+	return reconfigurable.Reconfigure(wrapped.processor)
+}
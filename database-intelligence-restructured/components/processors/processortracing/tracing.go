@@ -0,0 +1,107 @@
+package processortracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scopeName is the instrumentation scope of the spans
+const scopeName = "github.com/database-intelligence/db-intel/components/processors/processortracing"
+
+const (
+	// attributeProcessor is the wrapped processor's component ID
+	attributeProcessor = "processor"
+	// attributeRecords is the number of spans, data points or log records
+	// in the batch
+	attributeRecords = "processor.records"
+)
+
+// tracer starts the spans of one processor
+type tracer struct {
+	tracer trace.Tracer
+	id     string
+}
+
+// newTracer returns the processor's tracer, or nil when tracing is off
+func newTracer(settings processor.Settings, cfg Config) *tracer {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &tracer{
+		tracer: settings.TracerProvider.Tracer(scopeName),
+		id:     settings.ID.String(),
+	}
+}
+
+// run calls consume in a span named after the processor and the method. The
+// span is a child of the span in ctx, which is the previous processor's, so
+// each batch forms one trace through the pipeline. A processor's span
+// includes the processors after it, which it calls before returning. A nil
+// tracer calls consume directly.
+func (t *tracer) run(ctx context.Context, method string, records int, consume func(context.Context) error) error {
+	if t == nil {
+		return consume(ctx)
+	}
+
+	ctx, span := t.tracer.Start(ctx, t.id+" "+method, trace.WithAttributes(
+		attribute.String(attributeProcessor, t.id),
+		attribute.Int(attributeRecords, records),
+	))
+	defer span.End()
+
+	err := consume(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// tracedTraces traces a traces processor
+type tracedTraces struct {
+	processor.Traces
+	reconfigurer
+	tracer *tracer
+}
+
+// ConsumeTraces implements consumer.Traces
+func (p *tracedTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return p.tracer.run(ctx, "ConsumeTraces", td.SpanCount(), func(ctx context.Context) error {
+		return p.Traces.ConsumeTraces(ctx, td)
+	})
+}
+
+// tracedMetrics traces a metrics processor
+type tracedMetrics struct {
+	processor.Metrics
+	reconfigurer
+	tracer *tracer
+}
+
+// ConsumeMetrics implements consumer.Metrics
+func (p *tracedMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return p.tracer.run(ctx, "ConsumeMetrics", md.DataPointCount(), func(ctx context.Context) error {
+		return p.Metrics.ConsumeMetrics(ctx, md)
+	})
+}
+
+// tracedLogs traces a logs processor
+type tracedLogs struct {
+	processor.Logs
+	reconfigurer
+	tracer *tracer
+}
+
+// ConsumeLogs implements consumer.Logs
+func (p *tracedLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return p.tracer.run(ctx, "ConsumeLogs", ld.LogRecordCount(), func(ctx context.Context) error {
+		return p.Logs.ConsumeLogs(ctx, ld)
+	})
+}
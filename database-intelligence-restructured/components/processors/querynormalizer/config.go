@@ -0,0 +1,50 @@
+package querynormalizer
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the query normalizer processor
+type Config struct {
+	// SourceAttributes lists the attributes holding the query text, in order
+	// of preference. The first one present on a record or data point is used.
+	SourceAttributes []string `mapstructure:"source_attributes"`
+
+	// NormalizedAttribute receives the query with its literals replaced by ?
+	NormalizedAttribute string `mapstructure:"normalized_attribute"`
+
+	// FingerprintAttribute receives the hash of the normalized query
+	FingerprintAttribute string `mapstructure:"fingerprint_attribute"`
+
+	// OverwriteExisting replaces normalized and fingerprint attributes that
+	// are already set, for example by an upstream receiver
+	OverwriteExisting bool `mapstructure:"overwrite_existing"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.SourceAttributes) == 0 {
+		return errors.New("source_attributes must not be empty")
+	}
+	for i, attr := range cfg.SourceAttributes {
+		if attr == "" {
+			return fmt.Errorf("source_attributes[%d] must not be empty", i)
+		}
+	}
+
+	if cfg.NormalizedAttribute == "" {
+		return errors.New("normalized_attribute must not be empty")
+	}
+	if cfg.FingerprintAttribute == "" {
+		return errors.New("fingerprint_attribute must not be empty")
+	}
+	if cfg.NormalizedAttribute == cfg.FingerprintAttribute {
+		return fmt.Errorf("normalized_attribute and fingerprint_attribute must differ, both are %q", cfg.NormalizedAttribute)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package querynormalizer
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+var (
+	// componentType is the type of this processor
+	componentType = component.MustNewType("querynormalizer")
+	// stability is the stability level of this processor
+	stability = component.StabilityLevelBeta
+)
+
+// NewFactory creates a new processor factory
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		createDefaultConfig,
+		processor.WithLogs(createLogsProcessor, stability),
+		processor.WithMetrics(createMetricsProcessor, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return &Config{
+		SourceAttributes:     []string{"db.statement", "db.query.text", "query_text"},
+		NormalizedAttribute:  "db.query.normalized",
+		FingerprintAttribute: "db.query.fingerprint",
+	}
+}
+
+// createLogsProcessor creates a logs processor
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	qnp, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		qnp.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// createMetricsProcessor creates a metrics processor
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	qnp, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		qnp.processMetrics,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+func newProcessor(cfg component.Config, set processor.Settings) (*queryNormalizerProcessor, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &queryNormalizerProcessor{
+		config: processorConfig,
+		logger: set.Logger,
+	}, nil
+}
@@ -0,0 +1,350 @@
+package querynormalizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Normalize replaces the literals in a SQL statement with ?, so statements
+// that differ only in their parameters normalize to the same text.
+//
+// String literals (including E'...' and dollar-quoted strings), numbers and
+// positional parameters become ?, IN lists made up only of literals collapse
+// to IN (?), comments are removed and whitespace is collapsed to single
+// spaces. Identifiers, keywords and operators are kept as written.
+func Normalize(query string) string {
+	tokens := collapseInLists(tokenize(query))
+
+	// A trailing semicolon does not change the statement
+	for len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenPunct && tokens[len(tokens)-1].text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	var b strings.Builder
+	b.Grow(len(query))
+	for i, t := range tokens {
+		if i > 0 && t.spaced {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+// Literals returns the string and numeric literals of a statement as
+// written, in order: the text Normalize replaces with ?. Positional
+// parameters such as $1 and ? placeholders are not literals, so a statement
+// that is already normalized has none.
+func Literals(query string) []string {
+	var literals []string
+	for _, t := range tokenize(query) {
+		if t.kind == tokenLiteral && !isPositionalParameter(t.raw) {
+			literals = append(literals, t.raw)
+		}
+	}
+	return literals
+}
+
+// isPositionalParameter reports whether a literal is a parameter like $1
+func isPositionalParameter(raw string) bool {
+	if len(raw) < 2 || raw[0] != '$' {
+		return false
+	}
+	for i := 1; i < len(raw); i++ {
+		if !isDigit(raw[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint returns a stable hash of a normalized statement. Case is
+// ignored so statements that differ only in keyword case share a fingerprint.
+func Fingerprint(normalized string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(normalized)))
+	return hex.EncodeToString(sum[:8])
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenLiteral
+	tokenPunct
+)
+
+// token is a lexical element of a statement. raw is the text as written,
+// which differs from text for literals. spaced records whether whitespace or
+// a comment separated it from the previous token.
+type token struct {
+	kind   tokenKind
+	text   string
+	raw    string
+	spaced bool
+}
+
+// tokenize splits a statement into words, literals and punctuation,
+// replacing each literal with ?
+func tokenize(query string) []token {
+	var tokens []token
+	spaced := false
+	// start and i bound the token being emitted
+	var start, i int
+	emit := func(kind tokenKind, text string) {
+		tokens = append(tokens, token{kind: kind, text: text, raw: query[start:i], spaced: spaced})
+		spaced = false
+	}
+
+	for i < len(query) {
+		start = i
+		c := query[i]
+		switch {
+		case isSpace(c):
+			spaced = true
+			i++
+
+		case c == '-' && peek(query, i+1) == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			spaced = true
+			i += end
+
+		case c == '/' && peek(query, i+1) == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+			spaced = true
+
+		case c == '\'':
+			i = skipString(query, i, false)
+			emit(tokenLiteral, "?")
+
+		case (c == 'E' || c == 'e') && peek(query, i+1) == '\'':
+			i = skipString(query, i+1, true)
+			emit(tokenLiteral, "?")
+
+		case c == '$' && isDigit(peek(query, i+1)):
+			i++
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			emit(tokenLiteral, "?")
+
+		case c == '$' && dollarTag(query, i) != "":
+			tag := dollarTag(query, i)
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+			emit(tokenLiteral, "?")
+
+		case c == '"':
+			i = skipQuotedIdentifier(query, i)
+			emit(tokenWord, query[start:i])
+
+		case isDigit(c) || (c == '.' && isDigit(peek(query, i+1))):
+			i = skipNumber(query, i)
+			emit(tokenLiteral, "?")
+
+		case c == '-' && (isDigit(peek(query, i+1)) || peek(query, i+1) == '.') && unaryPosition(tokens):
+			// A negative number is one literal, so -1 and 1 normalize the same
+			i = skipNumber(query, i+1)
+			emit(tokenLiteral, "?")
+
+		case isIdentifierStart(c):
+			for i < len(query) && isIdentifierPart(query[i]) {
+				i++
+			}
+			emit(tokenWord, query[start:i])
+
+		default:
+			i++
+			emit(tokenPunct, query[start:i])
+		}
+	}
+	return tokens
+}
+
+// collapseInLists replaces IN lists made up only of literals with IN (?), so
+// the number of values in the list does not change the normalized text
+func collapseInLists(tokens []token) []token {
+	out := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		out = append(out, tokens[i])
+		if tokens[i].kind != tokenWord || !strings.EqualFold(tokens[i].text, "in") {
+			continue
+		}
+
+		end, ok := literalList(tokens, i+1)
+		if !ok {
+			continue
+		}
+		out = append(out,
+			tokens[i+1],
+			token{kind: tokenLiteral, text: "?"},
+			token{kind: tokenPunct, text: ")"},
+		)
+		i = end
+	}
+	return out
+}
+
+// literalList reports whether tokens[start:] begins with a parenthesized,
+// comma separated list of literals and returns the index of its closing
+// parenthesis
+func literalList(tokens []token, start int) (int, bool) {
+	if start >= len(tokens) || tokens[start].kind != tokenPunct || tokens[start].text != "(" {
+		return 0, false
+	}
+
+	wantLiteral := true
+	for j := start + 1; j < len(tokens); j++ {
+		t := tokens[j]
+		if wantLiteral {
+			if t.kind != tokenLiteral {
+				return 0, false
+			}
+		} else if t.kind != tokenPunct {
+			return 0, false
+		} else if t.text == ")" {
+			return j, true
+		} else if t.text != "," {
+			return 0, false
+		}
+		wantLiteral = !wantLiteral
+	}
+	return 0, false
+}
+
+// unaryPosition reports whether a minus sign after tokens starts a negative
+// number rather than a subtraction
+func unaryPosition(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	prev := tokens[len(tokens)-1]
+	return prev.kind == tokenPunct && prev.text != ")" && prev.text != "]"
+}
+
+// skipString returns the index after the string literal whose opening quote
+// is at start. Doubled quotes are always escapes; backslash escapes only
+// apply to E'...' strings.
+func skipString(query string, start int, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case '\'':
+			if peek(query, i+1) != '\'' {
+				return i + 1
+			}
+			i++
+		}
+	}
+	return len(query)
+}
+
+// skipQuotedIdentifier returns the index after the quoted identifier whose
+// opening quote is at start
+func skipQuotedIdentifier(query string, start int) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] == '"' {
+			if peek(query, i+1) != '"' {
+				return i + 1
+			}
+			i++
+		}
+	}
+	return len(query)
+}
+
+// skipNumber returns the index after the numeric literal starting at start,
+// covering hex, decimal and exponent forms
+func skipNumber(query string, start int) int {
+	i := start
+	if query[i] == '0' && (peek(query, i+1) == 'x' || peek(query, i+1) == 'X') {
+		i += 2
+		for i < len(query) && isHexDigit(query[i]) {
+			i++
+		}
+		return i
+	}
+
+	for i < len(query) && isDigit(query[i]) {
+		i++
+	}
+	if peek(query, i) == '.' {
+		i++
+		for i < len(query) && isDigit(query[i]) {
+			i++
+		}
+	}
+	if c := peek(query, i); c == 'e' || c == 'E' {
+		j := i + 1
+		if c := peek(query, j); c == '+' || c == '-' {
+			j++
+		}
+		if isDigit(peek(query, j)) {
+			i = j
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string at start, such
+// as $$ or $body$, or "" if there is none
+func dollarTag(query string, start int) string {
+	for i := start + 1; i < len(query); i++ {
+		c := query[i]
+		if c == '$' {
+			return query[start : i+1]
+		}
+		if !isIdentifierStart(c) && !(i > start+1 && isDigit(c)) {
+			return ""
+		}
+	}
+	return ""
+}
+
+// peek returns the byte at i, or 0 past the end of the query
+func peek(query string, i int) byte {
+	if i < len(query) {
+		return query[i]
+	}
+	return 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isIdentifierStart treats bytes of multi-byte UTF-8 characters as letters,
+// matching how PostgreSQL accepts them in identifiers
+func isIdentifierStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c >= 0x80
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || isDigit(c) || c == '$'
+}
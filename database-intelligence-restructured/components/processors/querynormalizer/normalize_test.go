@@ -0,0 +1,190 @@
+package querynormalizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "integer",
+			query: "SELECT * FROM users WHERE id = 42",
+			want:  "SELECT * FROM users WHERE id = ?",
+		},
+		{
+			name:  "decimal, exponent and hex",
+			query: "SELECT price * 1.5, 2e10, .5, 0xFF FROM items",
+			want:  "SELECT price * ?, ?, ?, ? FROM items",
+		},
+		{
+			name:  "negative number",
+			query: "UPDATE accounts SET balance = -10.25 WHERE id = 7",
+			want:  "UPDATE accounts SET balance = ? WHERE id = ?",
+		},
+		{
+			name:  "subtraction is kept",
+			query: "SELECT total-1, (a)-2 FROM t",
+			want:  "SELECT total-?, (a)-? FROM t",
+		},
+		{
+			name:  "string with doubled quote",
+			query: "SELECT * FROM users WHERE name = 'O''Brien' AND city='Paris'",
+			want:  "SELECT * FROM users WHERE name = ? AND city=?",
+		},
+		{
+			name:  "escape string",
+			query: `SELECT * FROM logs WHERE msg = E'it\'s \\ done'`,
+			want:  "SELECT * FROM logs WHERE msg = ?",
+		},
+		{
+			name:  "dollar quoted string",
+			query: "SELECT $fn$ body; with 'quotes' $fn$, $$x$$",
+			want:  "SELECT ?, ?",
+		},
+		{
+			name:  "positional parameters",
+			query: "SELECT * FROM orders WHERE customer_id = $1 AND status = $2",
+			want:  "SELECT * FROM orders WHERE customer_id = ? AND status = ?",
+		},
+		{
+			name:  "identifiers with digits are kept",
+			query: `SELECT t1.col2, "Table 3".x FROM t1 JOIN "Table 3" ON t1.id = "Table 3".id`,
+			want:  `SELECT t1.col2, "Table 3".x FROM t1 JOIN "Table 3" ON t1.id = "Table 3".id`,
+		},
+		{
+			name:  "casts",
+			query: "SELECT '2024-01-01'::date, 5::bigint",
+			want:  "SELECT ?::date, ?::bigint",
+		},
+		{
+			name:  "IN list of numbers",
+			query: "SELECT * FROM users WHERE id IN (1, 2, 3)",
+			want:  "SELECT * FROM users WHERE id IN (?)",
+		},
+		{
+			name:  "IN list of strings",
+			query: "SELECT * FROM users WHERE status in ('active','pending')",
+			want:  "SELECT * FROM users WHERE status in (?)",
+		},
+		{
+			name:  "NOT IN list of parameters",
+			query: "DELETE FROM jobs WHERE id NOT IN ($1, $2, $3, $4)",
+			want:  "DELETE FROM jobs WHERE id NOT IN (?)",
+		},
+		{
+			name:  "IN subquery is kept",
+			query: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 100)",
+			want:  "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > ?)",
+		},
+		{
+			name:  "IN list with expression is kept",
+			query: "SELECT * FROM t WHERE x IN (1, y)",
+			want:  "SELECT * FROM t WHERE x IN (?, y)",
+		},
+		{
+			name:  "comments and whitespace",
+			query: "SELECT  id,\n\tname -- the name\nFROM /* main */ users\r\n WHERE id = 1;",
+			want:  "SELECT id, name FROM users WHERE id = ?",
+		},
+		{
+			name:  "literals inside comments are dropped",
+			query: "SELECT 1 /* id = 'secret' */",
+			want:  "SELECT ?",
+		},
+		{
+			name:  "empty",
+			query: "   ",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.query))
+		})
+	}
+}
+
+func TestNormalize_UnterminatedInput(t *testing.T) {
+	for _, query := range []string{
+		"SELECT 'unterminated",
+		"SELECT E'unterminated\\",
+		`SELECT "unterminated`,
+		"SELECT $tag$ unterminated",
+		"SELECT 1 /* unterminated",
+		"SELECT 1 -- trailing",
+		"SELECT * FROM t WHERE id IN (1, 2",
+	} {
+		assert.NotPanics(t, func() { Normalize(query) }, query)
+	}
+}
+
+func TestFingerprint_StableAcrossParameters(t *testing.T) {
+	variants := []string{
+		"SELECT * FROM orders WHERE customer_id = 17 AND status IN ('new', 'paid')",
+		"SELECT * FROM orders WHERE customer_id = 9001 AND status IN ('shipped')",
+		"select *  from orders\nwhere customer_id = -3 and status in ('a','b','c','d')",
+		"SELECT * FROM orders WHERE customer_id = $1 AND status IN ($2, $3); -- prepared",
+	}
+
+	want := Fingerprint(Normalize(variants[0]))
+	assert.Len(t, want, 16)
+	for _, query := range variants[1:] {
+		assert.Equal(t, want, Fingerprint(Normalize(query)), query)
+	}
+}
+
+func TestFingerprint_DistinguishesStatements(t *testing.T) {
+	fingerprints := map[string]string{}
+	for _, query := range []string{
+		"SELECT * FROM orders WHERE customer_id = 1",
+		"SELECT * FROM orders WHERE order_id = 1",
+		"SELECT * FROM invoices WHERE customer_id = 1",
+		"DELETE FROM orders WHERE customer_id = 1",
+	} {
+		fp := Fingerprint(Normalize(query))
+		if other, exists := fingerprints[fp]; exists {
+			t.Fatalf("%q and %q share fingerprint %s", other, query, fp)
+		}
+		fingerprints[fp] = query
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "strings and numbers as written",
+			query: "SELECT * FROM orders WHERE status = 'A' AND amount > -5000.5 AND note = E'it\\'s'",
+			want:  []string{"'A'", "-5000.5", "E'it\\'s'"},
+		},
+		{
+			name:  "dollar-quoted string",
+			query: "SELECT $tag$secret$tag$",
+			want:  []string{"$tag$secret$tag$"},
+		},
+		{
+			name:  "placeholders are not literals",
+			query: "SELECT * FROM t WHERE a = $1 AND b IN (?, ?) AND c = $12",
+		},
+		{
+			name:  "identifiers with digits are not literals",
+			query: `SELECT col1, "2024" FROM t2 -- 42`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Literals(tt.query))
+		})
+	}
+}
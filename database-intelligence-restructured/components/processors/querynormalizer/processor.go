@@ -0,0 +1,107 @@
+package querynormalizer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// queryNormalizerProcessor adds the normalized query text and its
+// fingerprint next to the raw query text
+type queryNormalizerProcessor struct {
+	config *Config
+	logger *zap.Logger
+}
+
+// processLogs normalizes the query on every log record that carries one
+func (qnp *queryNormalizerProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				qnp.normalizeAttributes(records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processMetrics normalizes the query on every data point that carries one
+func (qnp *queryNormalizerProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				qnp.normalizeMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (qnp *queryNormalizerProcessor) normalizeMetric(metric pmetric.Metric) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			qnp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			qnp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			qnp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			qnp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			qnp.normalizeAttributes(dps.At(i).Attributes())
+		}
+	}
+}
+
+// normalizeAttributes sets the normalized and fingerprint attributes from the
+// first non-empty source attribute
+func (qnp *queryNormalizerProcessor) normalizeAttributes(attrs pcommon.Map) {
+	if !qnp.config.OverwriteExisting {
+		_, hasNormalized := attrs.Get(qnp.config.NormalizedAttribute)
+		_, hasFingerprint := attrs.Get(qnp.config.FingerprintAttribute)
+		if hasNormalized && hasFingerprint {
+			return
+		}
+	}
+
+	query := qnp.sourceQuery(attrs)
+	if query == "" {
+		return
+	}
+
+	normalized := Normalize(query)
+	attrs.PutStr(qnp.config.NormalizedAttribute, normalized)
+	attrs.PutStr(qnp.config.FingerprintAttribute, Fingerprint(normalized))
+}
+
+func (qnp *queryNormalizerProcessor) sourceQuery(attrs pcommon.Map) string {
+	for _, name := range qnp.config.SourceAttributes {
+		if value, ok := attrs.Get(name); ok && value.Type() == pcommon.ValueTypeStr && value.Str() != "" {
+			return value.Str()
+		}
+	}
+	return ""
+}
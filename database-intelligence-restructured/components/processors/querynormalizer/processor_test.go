@@ -0,0 +1,148 @@
+package querynormalizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name:    "no source attributes",
+			modify:  func(cfg *Config) { cfg.SourceAttributes = nil },
+			wantErr: true,
+		},
+		{
+			name:    "empty source attribute",
+			modify:  func(cfg *Config) { cfg.SourceAttributes = []string{"db.statement", ""} },
+			wantErr: true,
+		},
+		{
+			name:    "empty normalized attribute",
+			modify:  func(cfg *Config) { cfg.NormalizedAttribute = "" },
+			wantErr: true,
+		},
+		{
+			name:    "empty fingerprint attribute",
+			modify:  func(cfg *Config) { cfg.FingerprintAttribute = "" },
+			wantErr: true,
+		},
+		{
+			name:    "same target attribute",
+			modify:  func(cfg *Config) { cfg.FingerprintAttribute = cfg.NormalizedAttribute },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	sink := &consumertest.LogsSink{}
+	proc, err := factory.CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Attributes().PutStr("db.statement", "SELECT * FROM t WHERE id IN (1, 2, 3)")
+	records.AppendEmpty().Attributes().PutStr("query_text", "SELECT * FROM t WHERE id IN (4)")
+	records.AppendEmpty().Attributes().PutStr("db.system", "postgresql")
+
+	require.NoError(t, proc.ConsumeLogs(context.Background(), logs))
+	require.Len(t, sink.AllLogs(), 1)
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	first := got.At(0).Attributes()
+	normalized, ok := first.Get("db.query.normalized")
+	require.True(t, ok)
+	assert.Equal(t, "SELECT * FROM t WHERE id IN (?)", normalized.Str())
+	fingerprint, ok := first.Get("db.query.fingerprint")
+	require.True(t, ok)
+	assert.Equal(t, Fingerprint("SELECT * FROM t WHERE id IN (?)"), fingerprint.Str())
+
+	second, ok := got.At(1).Attributes().Get("db.query.fingerprint")
+	require.True(t, ok)
+	assert.Equal(t, fingerprint.Str(), second.Str())
+
+	_, ok = got.At(2).Attributes().Get("db.query.fingerprint")
+	assert.False(t, ok)
+}
+
+func TestProcessMetrics(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	sink := &consumertest.MetricsSink{}
+	proc, err := factory.CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("db.query.duration")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().Attributes().PutStr("db.query.text", "SELECT 'a'")
+	sum := ms.AppendEmpty()
+	sum.SetName("db.query.calls")
+	sum.SetEmptySum().DataPoints().AppendEmpty().Attributes().PutStr("db.query.text", "SELECT 'b'")
+
+	require.NoError(t, proc.ConsumeMetrics(context.Background(), metrics))
+	require.Len(t, sink.AllMetrics(), 1)
+	got := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+
+	gaugeFP, ok := got.At(0).Gauge().DataPoints().At(0).Attributes().Get("db.query.fingerprint")
+	require.True(t, ok)
+	sumFP, ok := got.At(1).Sum().DataPoints().At(0).Attributes().Get("db.query.fingerprint")
+	require.True(t, ok)
+	assert.Equal(t, gaugeFP.Str(), sumFP.Str())
+}
+
+func TestProcessLogs_ExistingAttributes(t *testing.T) {
+	for _, overwrite := range []bool{false, true} {
+		cfg := createDefaultConfig().(*Config)
+		cfg.OverwriteExisting = overwrite
+		qnp := &queryNormalizerProcessor{config: cfg}
+
+		logs := plog.NewLogs()
+		attrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes()
+		attrs.PutStr("db.statement", "SELECT 1")
+		attrs.PutStr("db.query.normalized", "upstream")
+		attrs.PutStr("db.query.fingerprint", "upstream")
+
+		_, err := qnp.processLogs(context.Background(), logs)
+		require.NoError(t, err)
+
+		fingerprint, _ := attrs.Get("db.query.fingerprint")
+		if overwrite {
+			assert.Equal(t, Fingerprint("SELECT ?"), fingerprint.Str())
+		} else {
+			assert.Equal(t, "upstream", fingerprint.Str())
+		}
+	}
+}
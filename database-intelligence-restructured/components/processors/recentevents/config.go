@@ -0,0 +1,29 @@
+package recentevents
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the recent events processor
+type Config struct {
+	// Extension is the ID of the recentevents extension to record into, such
+	// as recentevents/debug. When empty, records go to every recentevents
+	// extension of the collector.
+	Extension string `mapstructure:"extension"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Extension == "" {
+		return nil
+	}
+	var id component.ID
+	if err := id.UnmarshalText([]byte(cfg.Extension)); err != nil {
+		return fmt.Errorf("extension %q is not a valid component ID: %w", cfg.Extension, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package recentevents
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+var (
+	// componentType is the type of this processor
+	componentType = component.MustNewType("recentevents")
+	// stability is the stability level of this processor
+	stability = component.StabilityLevelAlpha
+)
+
+// NewFactory creates a new processor factory
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		createDefaultConfig,
+		processor.WithLogs(createLogsProcessor, stability),
+		processor.WithMetrics(createMetricsProcessor, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return &Config{}
+}
+
+// createLogsProcessor creates a logs processor
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	rep, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		rep.processLogs,
+		processorhelper.WithStart(rep.start),
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+	)
+}
+
+// createMetricsProcessor creates a metrics processor
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	rep, err := newProcessor(cfg, set)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		rep.processMetrics,
+		processorhelper.WithStart(rep.start),
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+	)
+}
+
+func newProcessor(cfg component.Config, set processor.Settings) (*recentEventsProcessor, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &recentEventsProcessor{
+		config: processorConfig,
+		logger: set.Logger,
+		source: set.ID.String(),
+	}, nil
+}
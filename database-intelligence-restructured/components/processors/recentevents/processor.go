@@ -0,0 +1,186 @@
+package recentevents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// Signals as named by the recentevents extension
+const (
+	signalLogs    = "logs"
+	signalMetrics = "metrics"
+)
+
+// eventRecorder matches the recentevents extension's Recorder. Both are
+// unnamed interfaces over standard library types, so they are identical and
+// the processor does not need to import the extension.
+type eventRecorder = interface {
+	RecordEvent(source, signal string, record map[string]any)
+}
+
+// recentEventsProcessor copies every log record and metric data point into
+// the recentevents extensions and passes the data on unchanged. Placed after
+// the processors that redact PII, it keeps only redacted records.
+type recentEventsProcessor struct {
+	config *Config
+	logger *zap.Logger
+	// source is the processor's component ID, recorded with each event
+	source string
+
+	recorders []eventRecorder
+}
+
+// start finds the extensions to record into
+func (p *recentEventsProcessor) start(_ context.Context, host component.Host) error {
+	for id, ext := range host.GetExtensions() {
+		recorder, ok := ext.(eventRecorder)
+		if !ok || (p.config.Extension != "" && id.String() != p.config.Extension) {
+			continue
+		}
+		p.recorders = append(p.recorders, recorder)
+		p.logger.Debug("Recording recent events", zap.String("extension", id.String()))
+	}
+
+	if len(p.recorders) == 0 {
+		if p.config.Extension != "" {
+			return fmt.Errorf("extension %q is not a recentevents extension in service.extensions", p.config.Extension)
+		}
+		p.logger.Warn("No recentevents extension is in service.extensions; records are passed on without being kept")
+	}
+	return nil
+}
+
+// processLogs records every log record
+func (p *recentEventsProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	if len(p.recorders) == 0 {
+		return ld, nil
+	}
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		resource := rls.At(i).Resource().Attributes().AsRaw()
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				p.record(signalLogs, logRecord(records.At(k), resource))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processMetrics records every data point
+func (p *recentEventsProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	if len(p.recorders) == 0 {
+		return md, nil
+	}
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		resource := rms.At(i).Resource().Attributes().AsRaw()
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				p.recordMetric(metrics.At(k), resource)
+			}
+		}
+	}
+	return md, nil
+}
+
+func (p *recentEventsProcessor) recordMetric(metric pmetric.Metric, resource map[string]any) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			p.recordPoint(metric, resource, dps.At(i).Timestamp(), dps.At(i).Attributes(), numberValue(dps.At(i)))
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			p.recordPoint(metric, resource, dps.At(i).Timestamp(), dps.At(i).Attributes(), numberValue(dps.At(i)))
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			p.recordPoint(metric, resource, dp.Timestamp(), dp.Attributes(), distributionValue(dp.Count(), dp.Sum()))
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			p.recordPoint(metric, resource, dp.Timestamp(), dp.Attributes(), distributionValue(dp.Count(), dp.Sum()))
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			p.recordPoint(metric, resource, dp.Timestamp(), dp.Attributes(), distributionValue(dp.Count(), dp.Sum()))
+		}
+	}
+}
+
+func (p *recentEventsProcessor) recordPoint(metric pmetric.Metric, resource map[string]any, ts pcommon.Timestamp, attrs pcommon.Map, value any) {
+	p.record(signalMetrics, map[string]any{
+		"timestamp":  formatTimestamp(ts),
+		"metric":     metric.Name(),
+		"type":       metric.Type().String(),
+		"unit":       metric.Unit(),
+		"value":      value,
+		"attributes": attrs.AsRaw(),
+		"resource":   resource,
+	})
+}
+
+func (p *recentEventsProcessor) record(signal string, record map[string]any) {
+	for _, recorder := range p.recorders {
+		recorder.RecordEvent(p.source, signal, record)
+	}
+}
+
+// logRecord returns the fields of a log record worth inspecting
+func logRecord(log plog.LogRecord, resource map[string]any) map[string]any {
+	ts := log.Timestamp()
+	if ts == 0 {
+		ts = log.ObservedTimestamp()
+	}
+	severity := log.SeverityText()
+	if severity == "" {
+		severity = log.SeverityNumber().String()
+	}
+	return map[string]any{
+		"timestamp":  formatTimestamp(ts),
+		"severity":   severity,
+		"body":       log.Body().AsRaw(),
+		"attributes": log.Attributes().AsRaw(),
+		"resource":   resource,
+	}
+}
+
+func numberValue(dp pmetric.NumberDataPoint) any {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return dp.IntValue()
+	}
+	return dp.DoubleValue()
+}
+
+func distributionValue(count uint64, sum float64) map[string]any {
+	return map[string]any{"count": count, "sum": sum}
+}
+
+func formatTimestamp(ts pcommon.Timestamp) string {
+	if ts == 0 {
+		return ""
+	}
+	return ts.AsTime().UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,182 @@
+package recentevents
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+// recordingExtension has the recentevents extension's RecordEvent signature
+type recordingExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	source string
+	signal string
+	record map[string]any
+}
+
+func (e *recordingExtension) RecordEvent(source, signal string, record map[string]any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, recordedEvent{source, signal, record})
+}
+
+// nopExtension does not record events
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// extensionsHost exposes a fixed set of extensions
+type extensionsHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *extensionsHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func newHost(extensions map[component.ID]component.Component) component.Host {
+	return &extensionsHost{Host: componenttest.NewNopHost(), extensions: extensions}
+}
+
+func newSettings() processor.Settings {
+	set := processortest.NewNopSettings()
+	set.ID = component.MustNewIDWithName("recentevents", "after_redaction")
+	return set
+}
+
+func TestProcessorRecordsLogs(t *testing.T) {
+	ext := &recordingExtension{}
+	sink := &consumertest.LogsSink{}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), newSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	assert.False(t, lp.Capabilities().MutatesData)
+	require.NoError(t, lp.Start(context.Background(), newHost(map[component.ID]component.Component{
+		component.MustNewID("recentevents"): ext,
+	})))
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "postgres")
+	record := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)))
+	record.SetSeverityText("ERROR")
+	record.Body().SetStr("deadlock detected")
+	record.Attributes().PutStr("db.statement", "UPDATE accounts SET balance = [REDACTED]")
+
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+	assert.Equal(t, 1, sink.LogRecordCount(), "records must be passed on")
+
+	require.Len(t, ext.events, 1)
+	assert.Equal(t, recordedEvent{
+		source: "recentevents/after_redaction",
+		signal: "logs",
+		record: map[string]any{
+			"timestamp":  "2024-05-06T07:08:09Z",
+			"severity":   "ERROR",
+			"body":       "deadlock detected",
+			"attributes": map[string]any{"db.statement": "UPDATE accounts SET balance = [REDACTED]"},
+			"resource":   map[string]any{"service.name": "postgres"},
+		},
+	}, ext.events[0])
+}
+
+func TestProcessorRecordsMetricDataPoints(t *testing.T) {
+	ext := &recordingExtension{}
+	sink := &consumertest.MetricsSink{}
+	mp, err := NewFactory().CreateMetricsProcessor(context.Background(), newSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, mp.Start(context.Background(), newHost(map[component.ID]component.Component{
+		component.MustNewID("recentevents"): ext,
+	})))
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("postgresql.backends")
+	gauge.SetUnit("1")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(12)
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("db.query.duration")
+	dp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	dp.SetCount(4)
+	dp.SetSum(2.5)
+	dp.Attributes().PutStr("db.name", "shop")
+
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+	assert.Equal(t, 2, sink.DataPointCount())
+
+	require.Len(t, ext.events, 2)
+	assert.Equal(t, "metrics", ext.events[0].signal)
+	assert.Equal(t, "postgresql.backends", ext.events[0].record["metric"])
+	assert.Equal(t, "Gauge", ext.events[0].record["type"])
+	assert.Equal(t, int64(12), ext.events[0].record["value"])
+	assert.Equal(t, map[string]any{"count": uint64(4), "sum": 2.5}, ext.events[1].record["value"])
+	assert.Equal(t, map[string]any{"db.name": "shop"}, ext.events[1].record["attributes"])
+}
+
+func TestProcessorRecordsIntoNamedExtension(t *testing.T) {
+	debug := &recordingExtension{}
+	other := &recordingExtension{}
+	cfg := &Config{Extension: "recentevents/debug"}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), newSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), newHost(map[component.ID]component.Component{
+		component.MustNewIDWithName("recentevents", "debug"): debug,
+		component.MustNewID("recentevents"):                  other,
+		component.MustNewID("healthcheck"):                   &nopExtension{},
+	})))
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+
+	assert.Len(t, debug.events, 1)
+	assert.Empty(t, other.events)
+}
+
+func TestProcessorStartFailsWithoutNamedExtension(t *testing.T) {
+	cfg := &Config{Extension: "recentevents/debug"}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), newSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	err = lp.Start(context.Background(), newHost(nil))
+	assert.EqualError(t, err, `extension "recentevents/debug" is not a recentevents extension in service.extensions`)
+}
+
+func TestProcessorPassesThroughWithoutExtension(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), newSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), newHost(nil)))
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+	assert.Equal(t, 1, sink.LogRecordCount())
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{Extension: "recentevents/debug"}).Validate())
+	assert.Error(t, (&Config{Extension: "recent events"}).Validate())
+}
@@ -5,25 +5,41 @@ import (
     "go.opentelemetry.io/collector/processor"
     
     "github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+    "github.com/database-intelligence/db-intel/components/processors/attributefilter"
+    "github.com/database-intelligence/db-intel/components/processors/checksum"
     "github.com/database-intelligence/db-intel/components/processors/circuitbreaker"
     "github.com/database-intelligence/db-intel/components/processors/costcontrol"
+    "github.com/database-intelligence/db-intel/components/processors/dbattributes"
+    "github.com/database-intelligence/db-intel/components/processors/metricremap"
     "github.com/database-intelligence/db-intel/components/processors/nrerrormonitor"
     "github.com/database-intelligence/db-intel/components/processors/planattributeextractor"
+    "github.com/database-intelligence/db-intel/components/processors/processortracing"
     "github.com/database-intelligence/db-intel/components/processors/querycorrelator"
+    "github.com/database-intelligence/db-intel/components/processors/querynormalizer"
+    "github.com/database-intelligence/db-intel/components/processors/recentevents"
+    "github.com/database-intelligence/db-intel/components/processors/tenant"
     "github.com/database-intelligence/db-intel/components/processors/verification"
     "github.com/database-intelligence/db-intel/components/processors/ohitransform"
 )
 
-// All returns all processor factories
+// All returns all processor factories, each wrapped so it can trace the
+// batches it consumes
 func All() map[component.Type]processor.Factory {
     return map[component.Type]processor.Factory{
-        adaptivesampler.NewFactory().Type():        adaptivesampler.NewFactory(),
-        circuitbreaker.NewFactory().Type():         circuitbreaker.NewFactory(),
-        costcontrol.NewFactory().Type():            costcontrol.NewFactory(),
-        nrerrormonitor.NewFactory().Type():         nrerrormonitor.NewFactory(),
-        planattributeextractor.NewFactory().Type(): planattributeextractor.NewFactory(),
-        querycorrelator.NewFactory().Type():        querycorrelator.NewFactory(),
-        verification.NewFactory().Type():           verification.NewFactory(),
-        ohitransform.NewFactory().Type():           ohitransform.NewFactory(),
+        adaptivesampler.NewFactory().Type():        processortracing.Wrap(adaptivesampler.NewFactory()),
+        attributefilter.NewFactory().Type():        processortracing.Wrap(attributefilter.NewFactory()),
+        checksum.NewFactory().Type():               processortracing.Wrap(checksum.NewFactory()),
+        circuitbreaker.NewFactory().Type():         processortracing.Wrap(circuitbreaker.NewFactory()),
+        costcontrol.NewFactory().Type():            processortracing.Wrap(costcontrol.NewFactory()),
+        dbattributes.NewFactory().Type():           processortracing.Wrap(dbattributes.NewFactory()),
+        metricremap.NewFactory().Type():            processortracing.Wrap(metricremap.NewFactory()),
+        nrerrormonitor.NewFactory().Type():         processortracing.Wrap(nrerrormonitor.NewFactory()),
+        planattributeextractor.NewFactory().Type(): processortracing.Wrap(planattributeextractor.NewFactory()),
+        querycorrelator.NewFactory().Type():        processortracing.Wrap(querycorrelator.NewFactory()),
+        querynormalizer.NewFactory().Type():        processortracing.Wrap(querynormalizer.NewFactory()),
+        recentevents.NewFactory().Type():           processortracing.Wrap(recentevents.NewFactory()),
+        tenant.NewFactory().Type():                 processortracing.Wrap(tenant.NewFactory()),
+        verification.NewFactory().Type():           processortracing.Wrap(verification.NewFactory()),
+        ohitransform.NewFactory().Type():           processortracing.Wrap(ohitransform.NewFactory()),
     }
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines configuration for the tenant processor.
+//
+// Each source attribute is read from the record, or from its resource when
+// the record does not have it, and its value is looked up in Table first;
+// when the table has no entry, Rules are tried in order. The first source
+// attribute that maps to a tenant decides. A record none of them maps gets
+// DefaultTenant.
+type Config struct {
+	// SourceAttributes are the attributes the tenant is derived from, in
+	// order of preference, e.g. db.name, then server.address
+	SourceAttributes []string `mapstructure:"source_attributes"`
+
+	// Table maps source values to tenant IDs exactly
+	Table map[string]string `mapstructure:"table"`
+
+	// Rules map source values matching a pattern to a tenant ID
+	Rules []RuleConfig `mapstructure:"rules"`
+
+	// DefaultTenant is stamped on records no entry or rule matches. Empty
+	// leaves them without a tenant.
+	DefaultTenant string `mapstructure:"default_tenant"`
+
+	// Attribute is the record attribute the tenant ID is stamped into
+	Attribute string `mapstructure:"attribute"`
+
+	// Override replaces a tenant ID a record already has, e.g. one set by
+	// an upstream collector. By default it is kept.
+	Override bool `mapstructure:"override"`
+}
+
+// RuleConfig maps source values matching Pattern to Tenant
+type RuleConfig struct {
+	// Pattern is a regular expression in RE2 syntax, matched anywhere in the
+	// value unless anchored
+	Pattern string `mapstructure:"pattern"`
+
+	// Tenant is the tenant ID. It can refer to the pattern's capture
+	// groups as $1 or ${name}.
+	Tenant string `mapstructure:"tenant"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.SourceAttributes) == 0 {
+		return errors.New("source_attributes must not be empty")
+	}
+	for i, key := range cfg.SourceAttributes {
+		if key == "" {
+			return fmt.Errorf("source_attributes[%d] must not be empty", i)
+		}
+	}
+
+	for value, tenant := range cfg.Table {
+		if tenant == "" {
+			return fmt.Errorf("table: tenant for %q must not be empty", value)
+		}
+	}
+
+	for i, rule := range cfg.Rules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("rules[%d].pattern must not be empty", i)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("rules[%d].pattern %q is not a valid regular expression: %w", i, rule.Pattern, err)
+		}
+		if rule.Tenant == "" {
+			return fmt.Errorf("rules[%d].tenant must not be empty", i)
+		}
+	}
+
+	if cfg.Attribute == "" {
+		return errors.New("attribute must be specified")
+	}
+	return nil
+}
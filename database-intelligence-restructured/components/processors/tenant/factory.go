@@ -0,0 +1,124 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	// DefaultAttribute is the attribute the tenant ID is stamped into
+	DefaultAttribute = "tenant.id"
+)
+
+var (
+	// componentType is the type of this processor
+	componentType = component.MustNewType("tenant")
+	// stability is the stability level of this processor
+	stability = component.StabilityLevelAlpha
+)
+
+// NewFactory creates a new processor factory
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		createDefaultConfig,
+		processor.WithTraces(createTracesProcessor, stability),
+		processor.WithMetrics(createMetricsProcessor, stability),
+		processor.WithLogs(createLogsProcessor, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration, which derives the
+// tenant from db.name. Without a table or rules every record gets the
+// default tenant.
+func createDefaultConfig() component.Config {
+	return &Config{
+		SourceAttributes: []string{"db.name"},
+		DefaultTenant:    "default",
+		Attribute:        DefaultAttribute,
+	}
+}
+
+// createTracesProcessor creates a traces processor
+func createTracesProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (processor.Traces, error) {
+	tp, err := newProcessor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewTracesProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		tp.processTraces,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// createMetricsProcessor creates a metrics processor
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	tp, err := newProcessor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewMetricsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		tp.processMetrics,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+// createLogsProcessor creates a logs processor
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	tp, err := newProcessor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return processorhelper.NewLogsProcessor(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		tp.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+	)
+}
+
+func newProcessor(cfg component.Config) (*tenantProcessor, error) {
+	processorConfig, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("invalid config type: %T", cfg)
+	}
+
+	if err := processorConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return newTenantProcessor(processorConfig), nil
+}
@@ -0,0 +1,178 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// rule is a compiled RuleConfig
+type rule struct {
+	pattern *regexp.Regexp
+	tenant  string
+}
+
+// tenantProcessor stamps every span, data point and log record with the
+// tenant derived from its source attributes
+type tenantProcessor struct {
+	sourceAttributes []string
+	table            map[string]string
+	rules            []rule
+	defaultTenant    string
+	attribute        string
+	override         bool
+}
+
+func newTenantProcessor(cfg *Config) *tenantProcessor {
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		// Validate has compiled every pattern
+		rules = append(rules, rule{pattern: regexp.MustCompile(r.Pattern), tenant: r.Tenant})
+	}
+	return &tenantProcessor{
+		sourceAttributes: cfg.SourceAttributes,
+		table:            cfg.Table,
+		rules:            rules,
+		defaultTenant:    cfg.DefaultTenant,
+		attribute:        cfg.Attribute,
+		override:         cfg.Override,
+	}
+}
+
+// lookup returns the tenant of a source value, or false when no table entry
+// or rule matches it. A rule whose tenant expands to nothing does not
+// match.
+func (p *tenantProcessor) lookup(value string) (string, bool) {
+	if tenant, ok := p.table[value]; ok {
+		return tenant, true
+	}
+	for _, r := range p.rules {
+		match := r.pattern.FindStringSubmatchIndex(value)
+		if match == nil {
+			continue
+		}
+		if tenant := string(r.pattern.ExpandString(nil, r.tenant, value, match)); tenant != "" {
+			return tenant, true
+		}
+	}
+	return "", false
+}
+
+// tenant returns the tenant of a record from the first source attribute
+// whose value maps to one. Each source is looked up on the record and, when
+// the record does not have it, on its resource.
+func (p *tenantProcessor) tenant(resource, attrs pcommon.Map) string {
+	for _, key := range p.sourceAttributes {
+		v, ok := attrs.Get(key)
+		if !ok {
+			v, ok = resource.Get(key)
+		}
+		if !ok {
+			continue
+		}
+		if tenant, ok := p.lookup(v.AsString()); ok {
+			return tenant
+		}
+	}
+	return p.defaultTenant
+}
+
+// stamp sets the tenant on a record's attributes
+func (p *tenantProcessor) stamp(resource, attrs pcommon.Map) {
+	if !p.override {
+		if _, ok := attrs.Get(p.attribute); ok {
+			return
+		}
+	}
+	if tenant := p.tenant(resource, attrs); tenant != "" {
+		attrs.PutStr(p.attribute, tenant)
+	}
+}
+
+// processTraces stamps each span
+func (p *tenantProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		resource := rs.Resource().Attributes()
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				p.stamp(resource, spans.At(k).Attributes())
+			}
+		}
+	}
+	return td, nil
+}
+
+// processMetrics stamps each data point
+func (p *tenantProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := rm.Resource().Attributes()
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				forEachAttributes(metrics.At(k), func(attrs pcommon.Map) {
+					p.stamp(resource, attrs)
+				})
+			}
+		}
+	}
+	return md, nil
+}
+
+// processLogs stamps each log record
+func (p *tenantProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resource := rl.Resource().Attributes()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				p.stamp(resource, records.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// forEachAttributes calls fn with the attributes of every data point
+func forEachAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "table and rules",
+			modify: func(cfg *Config) {
+				cfg.Table = map[string]string{"orders": "commerce"}
+				cfg.Rules = []RuleConfig{{Pattern: `^(\w+)-prod$`, Tenant: "$1"}}
+			},
+		},
+		{
+			name: "no source attributes",
+			modify: func(cfg *Config) {
+				cfg.SourceAttributes = nil
+			},
+			wantErr: "source_attributes must not be empty",
+		},
+		{
+			name: "empty source attribute",
+			modify: func(cfg *Config) {
+				cfg.SourceAttributes = []string{"db.name", ""}
+			},
+			wantErr: "source_attributes[1] must not be empty",
+		},
+		{
+			name: "empty table tenant",
+			modify: func(cfg *Config) {
+				cfg.Table = map[string]string{"orders": ""}
+			},
+			wantErr: `table: tenant for "orders" must not be empty`,
+		},
+		{
+			name: "invalid pattern",
+			modify: func(cfg *Config) {
+				cfg.Rules = []RuleConfig{{Pattern: `^(orders`, Tenant: "commerce"}}
+			},
+			wantErr: "rules[0].pattern \"^(orders\" is not a valid regular expression",
+		},
+		{
+			name: "empty rule tenant",
+			modify: func(cfg *Config) {
+				cfg.Rules = []RuleConfig{{Pattern: `^orders`}}
+			},
+			wantErr: "rules[0].tenant must not be empty",
+		},
+		{
+			name: "no attribute",
+			modify: func(cfg *Config) {
+				cfg.Attribute = ""
+			},
+			wantErr: "attribute must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// newLogs returns a batch with one log record per database name, each on
+// its own record, from a resource on host
+func newLogs(host string, databases ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", host)
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, database := range databases {
+		lr := records.AppendEmpty()
+		if database != "" {
+			lr.Attributes().PutStr("db.name", database)
+		}
+	}
+	return ld
+}
+
+// logTenants runs ld through a logs processor and returns the tenant of
+// each record, or "" for a record without one
+func logTenants(t *testing.T, cfg *Config, ld plog.Logs) []string {
+	t.Helper()
+	sink := &consumertest.LogsSink{}
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+
+	var tenants []string
+	records := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		tenant := ""
+		if v, ok := records.At(i).Attributes().Get(cfg.Attribute); ok {
+			tenant = v.Str()
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
+func TestTableLookup(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Table = map[string]string{
+		"orders":    "commerce",
+		"inventory": "commerce",
+		"billing":   "finance",
+	}
+
+	// Table values match exactly
+	tenants := logTenants(t, cfg, newLogs("db-1", "orders", "billing", "inventory", "Orders", "orders_archive"))
+	assert.Equal(t, []string{"commerce", "finance", "commerce", "default", "default"}, tenants)
+}
+
+func TestRegexLookup(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = []RuleConfig{
+		{Pattern: `^(?P<team>[a-z]+)_(prod|staging)$`, Tenant: "${team}"},
+		{Pattern: `^tmp_`, Tenant: "scratch"},
+		{Pattern: `reporting`, Tenant: "analytics"},
+	}
+
+	tenants := logTenants(t, cfg, newLogs("db-1", "payments_prod", "search_staging", "tmp_load_test", "legacy_reporting_v2", "payments_dev"))
+	assert.Equal(t, []string{"payments", "search", "scratch", "analytics", "default"}, tenants)
+}
+
+func TestTableBeforeRules(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Table = map[string]string{"payments_prod": "finance"}
+	cfg.Rules = []RuleConfig{
+		// An empty expansion does not match, so the next rule is tried
+		{Pattern: `^(?P<team>[a-z]*)_prod$`, Tenant: "${team}"},
+		{Pattern: `_prod$`, Tenant: "production"},
+		{Pattern: `_staging$`, Tenant: "staging"},
+	}
+
+	tenants := logTenants(t, cfg, newLogs("db-1", "payments_prod", "search_prod", "_prod", "search_staging"))
+	assert.Equal(t, []string{"finance", "search", "production", "staging"}, tenants)
+}
+
+func TestNoMatchDefault(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Table = map[string]string{"orders": "commerce"}
+
+	// Records without the source attribute or with an unmapped value get
+	// the default tenant
+	assert.Equal(t, []string{"commerce", "default", "default"}, logTenants(t, cfg, newLogs("db-1", "orders", "billing", "")))
+
+	cfg.DefaultTenant = "unassigned"
+	assert.Equal(t, []string{"unassigned"}, logTenants(t, cfg, newLogs("db-1", "billing")))
+
+	// Without a default they are left untagged
+	cfg.DefaultTenant = ""
+	assert.Equal(t, []string{"commerce", ""}, logTenants(t, cfg, newLogs("db-1", "orders", "billing")))
+}
+
+func TestSourceAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SourceAttributes = []string{"db.name", "host.name"}
+	cfg.Table = map[string]string{"orders": "commerce"}
+	cfg.Rules = []RuleConfig{{Pattern: `^(\w+)-db-\d+$`, Tenant: "$1"}}
+
+	// db.name decides when it maps; otherwise host.name from the resource
+	// is tried
+	tenants := logTenants(t, cfg, newLogs("search-db-3", "orders", "billing", ""))
+	assert.Equal(t, []string{"commerce", "search", "search"}, tenants)
+
+	// No source maps
+	assert.Equal(t, []string{"default"}, logTenants(t, cfg, newLogs("localhost", "billing")))
+
+	// A record attribute takes precedence over the resource attribute of
+	// the same key
+	cfg.SourceAttributes = []string{"host.name"}
+	ld := newLogs("search-db-3", "")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutStr("host.name", "orders-db-1")
+	assert.Equal(t, []string{"orders"}, logTenants(t, cfg, ld))
+}
+
+func TestOverride(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Table = map[string]string{"orders": "commerce"}
+	ld := func() plog.Logs {
+		ld := newLogs("db-1", "orders")
+		ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutStr(DefaultAttribute, "upstream")
+		return ld
+	}
+
+	// A tenant set upstream is kept by default
+	assert.Equal(t, []string{"upstream"}, logTenants(t, cfg, ld()))
+
+	cfg.Override = true
+	assert.Equal(t, []string{"commerce"}, logTenants(t, cfg, ld()))
+}
+
+func TestProcessMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Table = map[string]string{"orders": "commerce"}
+	cfg.Attribute = "tenant"
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.name", "orders")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("postgresql.backends")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(12)
+	sum := metrics.AppendEmpty()
+	sum.SetName("postgresql.commits")
+	dps := sum.SetEmptySum().DataPoints()
+	dps.AppendEmpty().SetIntValue(100)
+	other := dps.AppendEmpty()
+	other.SetIntValue(7)
+	other.Attributes().PutStr("db.name", "billing")
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("db.query.duration")
+	histogram.SetEmptyHistogram().DataPoints().AppendEmpty().SetCount(3)
+
+	sink := &consumertest.MetricsSink{}
+	mp, err := NewFactory().CreateMetricsProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+
+	out := sink.AllMetrics()[0].ResourceMetrics().At(0)
+	_, ok := out.Resource().Attributes().Get("tenant")
+	assert.False(t, ok, "the resource is not stamped")
+
+	tenantOf := func(attrs pcommon.Map) string {
+		v, ok := attrs.Get("tenant")
+		require.True(t, ok, "every data point is stamped")
+		return v.Str()
+	}
+	outMetrics := out.ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, "commerce", tenantOf(outMetrics.At(0).Gauge().DataPoints().At(0).Attributes()))
+	assert.Equal(t, "commerce", tenantOf(outMetrics.At(1).Sum().DataPoints().At(0).Attributes()))
+	assert.Equal(t, "default", tenantOf(outMetrics.At(1).Sum().DataPoints().At(1).Attributes()))
+	assert.Equal(t, "commerce", tenantOf(outMetrics.At(2).Histogram().DataPoints().At(0).Attributes()))
+}
+
+func TestProcessTraces(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SourceAttributes = []string{"server.address"}
+	cfg.Rules = []RuleConfig{{Pattern: `^pg-(\w+)\.`, Tenant: "$1"}}
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().Attributes().PutStr("server.address", "pg-payments.internal")
+	spans.AppendEmpty().Attributes().PutStr("server.address", "10.0.0.12")
+
+	sink := &consumertest.TracesSink{}
+	tp, err := NewFactory().CreateTracesProcessor(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, tp.ConsumeTraces(context.Background(), td))
+
+	out := sink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	first, _ := out.At(0).Attributes().Get(DefaultAttribute)
+	second, _ := out.At(1).Attributes().Get(DefaultAttribute)
+	assert.Equal(t, "payments", first.Str())
+	assert.Equal(t, "default", second.Str())
+}
+
+func TestCreateProcessorInvalidConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = []RuleConfig{{Pattern: `(`, Tenant: "broken"}}
+
+	_, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, &consumertest.LogsSink{})
+	assert.ErrorContains(t, err, "config validation failed")
+}
@@ -5,6 +5,9 @@ package verification
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -30,12 +33,30 @@ type Config struct {
 	// RequireEntitySynthesis enforces entity synthesis attributes
 	RequireEntitySynthesis bool `mapstructure:"require_entity_synthesis"`
 	
+	// EntitySynthesis fills in entity.guid on records that arrive without it
+	EntitySynthesis EntitySynthesisConfig `mapstructure:"entity_synthesis"`
+	
 	// ExportFeedbackAsLogs exports feedback events as telemetry
 	ExportFeedbackAsLogs bool `mapstructure:"export_feedback_as_logs"`
 	
+	// FeedbackExport batches and bounds the export of feedback events
+	FeedbackExport FeedbackExportConfig `mapstructure:"feedback_export"`
+	
 	// FeedbackEndpoint is the endpoint to send feedback (optional)
 	FeedbackEndpoint string `mapstructure:"feedback_endpoint"`
 	
+	// FeedbackChannelSize sets how many feedback events can be queued before dropping
+	FeedbackChannelSize int `mapstructure:"feedback_channel_size"`
+	
+	// HealthEndpoint serves the current health report over HTTP (optional)
+	HealthEndpoint HealthEndpointConfig `mapstructure:"health_endpoint"`
+	
+	// HealthReportOutput writes periodic health reports to a file and/or webhook (optional)
+	HealthReportOutput HealthReportOutputConfig `mapstructure:"health_report_output"`
+	
+	// FeedbackCoalescing merges repeated feedback events instead of dropping them
+	FeedbackCoalescing FeedbackCoalescingConfig `mapstructure:"feedback_coalescing"`
+	
 	// VerificationQueries are custom NRQL queries to run for verification
 	VerificationQueries []VerificationQuery `mapstructure:"verification_queries"`
 	
@@ -53,6 +74,165 @@ type Config struct {
 	
 	// PIIDetection configures PII detection and sanitization
 	PIIDetection PIIDetectionConfig `mapstructure:"pii_detection"`
+
+	// MaxBodyBytes truncates string log bodies longer than this many bytes
+	// before they are checked; 0, the default, disables truncation
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+
+	// MaxStatementBytes truncates db.statement values the same way. New
+	// Relic drops attributes over 4096 bytes, a sensible limit to opt into.
+	MaxStatementBytes int `mapstructure:"max_statement_bytes"`
+
+	// EnableAutoTuning periodically analyzes performance and recommends,
+	// or with auto_tuning_config.enable_auto_apply applies, changes to
+	// consumer_timeout and quality_sample_rate
+	EnableAutoTuning bool `mapstructure:"enable_auto_tuning"`
+
+	// AutoTuningInterval sets how often to run auto-tuning analysis
+	AutoTuningInterval time.Duration `mapstructure:"auto_tuning_interval"`
+
+	// AutoTuningConfig configures auto-tuning behavior
+	AutoTuningConfig AutoTuningConfig `mapstructure:"auto_tuning_config"`
+
+	// ConsumerTimeout bounds each call to the next consumer. 0, the default,
+	// disables it. Auto-tunable once set.
+	ConsumerTimeout time.Duration `mapstructure:"consumer_timeout"`
+
+	// QualitySampleRate is the fraction of records that get quality validation (0.0-1.0). Auto-tunable.
+	QualitySampleRate float64 `mapstructure:"quality_sample_rate"`
+}
+
+// EntitySynthesisConfig configures synthesizing entity.guid, entity.type and
+// entity.name for records that lack them. The entity type comes from the
+// database system and the entity name joins the identifier attributes, so
+// every record for the same database gets the same guid. Attributes are
+// read from the record first, then its resource.
+type EntitySynthesisConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccountID is the New Relic account encoded in the guid
+	AccountID string `mapstructure:"account_id"`
+	// Domain is the entity domain encoded in the guid
+	Domain string `mapstructure:"domain"`
+	// SystemAttribute holds the database system, e.g. postgresql
+	SystemAttribute string `mapstructure:"system_attribute"`
+	// EntityTypes maps database systems to entity types. Records for other
+	// systems are not synthesized.
+	EntityTypes map[string]string `mapstructure:"entity_types"`
+	// IdentifierAttributes identify the database; records missing any of
+	// them are not synthesized
+	IdentifierAttributes []string `mapstructure:"identifier_attributes"`
+	// Overwrite replaces an entity.guid set upstream
+	Overwrite bool `mapstructure:"overwrite"`
+}
+
+// HealthEndpointConfig configures the HTTP health-report endpoint. When enabled,
+// /health serves the report as JSON and /metrics serves key gauges in
+// Prometheus text format.
+type HealthEndpointConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// HealthReportOutputConfig configures destinations for the health report
+// outside the logs. Every Interval the report is appended as one JSON line
+// to File.Path and/or POSTed to Webhook.URL; either destination may be left
+// unset.
+type HealthReportOutputConfig struct {
+	Interval time.Duration             `mapstructure:"interval"`
+	File     HealthReportFileConfig    `mapstructure:"file"`
+	Webhook  HealthReportWebhookConfig `mapstructure:"webhook"`
+}
+
+// HealthReportFileConfig configures the rotating report file
+type HealthReportFileConfig struct {
+	Path string `mapstructure:"path"`
+	// MaxSizeMiB rotates the file once a report would take it past this size
+	MaxSizeMiB int `mapstructure:"max_size_mib"`
+	// MaxFiles is how many rotated files (path.1, path.2, ...) are kept
+	MaxFiles int `mapstructure:"max_files"`
+}
+
+// HealthReportWebhookConfig configures posting reports to a webhook.
+// Delivery never blocks the processor: reports that do not fit in the queue
+// or fail every retry are dropped and counted.
+type HealthReportWebhookConfig struct {
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+	// MaxRetries is how many times a failed post is retried
+	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBackoff is the wait before the first retry; it doubles each retry
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// QueueSize is how many reports can wait for delivery
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// enabled reports whether any destination is configured
+func (cfg *HealthReportOutputConfig) enabled() bool {
+	return cfg.File.Path != "" || cfg.Webhook.URL != ""
+}
+
+// validate checks an enabled health report output
+func (cfg *HealthReportOutputConfig) validate() error {
+	if cfg.Interval <= 0 {
+		return errors.New("health_report_output.interval must be positive")
+	}
+	if cfg.File.Path != "" {
+		if cfg.File.MaxSizeMiB <= 0 {
+			return errors.New("health_report_output.file.max_size_mib must be positive")
+		}
+		if cfg.File.MaxFiles < 0 {
+			return errors.New("health_report_output.file.max_files cannot be negative")
+		}
+	}
+	if cfg.Webhook.URL != "" {
+		u, err := url.Parse(cfg.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("health_report_output.webhook.url must be an http or https URL, got %q", cfg.Webhook.URL)
+		}
+		if cfg.Webhook.Timeout <= 0 {
+			return errors.New("health_report_output.webhook.timeout must be positive")
+		}
+		if cfg.Webhook.MaxRetries < 0 {
+			return errors.New("health_report_output.webhook.max_retries cannot be negative")
+		}
+		if cfg.Webhook.MaxRetries > 0 && cfg.Webhook.RetryBackoff <= 0 {
+			return errors.New("health_report_output.webhook.retry_backoff must be positive when retries are enabled")
+		}
+		if cfg.Webhook.QueueSize <= 0 {
+			return errors.New("health_report_output.webhook.queue_size must be positive")
+		}
+	}
+	return nil
+}
+
+// AutoTuningConfig configures auto-tuning behavior
+type AutoTuningConfig struct {
+	EnableAutoApply    bool    `mapstructure:"enable_auto_apply"`
+	MinConfidenceLevel float64 `mapstructure:"min_confidence_level"`
+	MaxParameterChange float64 `mapstructure:"max_parameter_change"`
+}
+
+// FeedbackExportConfig configures how feedback events are exported as logs.
+// Events are sent from a goroutine of their own, once MaxBatchSize are
+// queued or every FlushInterval; events arriving while QueueSize are
+// waiting are dropped and counted.
+type FeedbackExportConfig struct {
+	MaxBatchSize  int           `mapstructure:"max_batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	QueueSize     int           `mapstructure:"queue_size"`
+	// Timeout bounds each export, so a stuck consumer only delays the next
+	// batch
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// FeedbackCoalescingConfig configures merging of repeated feedback events.
+// Coalescing happens before events reach the feedback loop, so a storm of
+// identical events costs one queued event and one summary rather than a
+// full feedback_export batch.
+type FeedbackCoalescingConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Window  time.Duration `mapstructure:"window"`
 }
 
 // VerificationQuery defines a custom verification query
@@ -67,9 +247,14 @@ type VerificationQuery struct {
 // HealthThresholdsConfig defines system resource alert thresholds
 type HealthThresholdsConfig struct {
 	MemoryPercent  float64       `mapstructure:"memory_percent"`
+	// MemoryLimitMiB is the memory budget MemoryPercent is measured against.
+	// When zero the cgroup limit, or total system memory, is used.
+	MemoryLimitMiB int           `mapstructure:"memory_limit_mib"`
 	CPUPercent     float64       `mapstructure:"cpu_percent"`
 	DiskPercent    float64       `mapstructure:"disk_percent"`
 	NetworkLatency time.Duration `mapstructure:"network_latency"`
+	// DiskPath is the data/spool directory whose filesystem usage is monitored
+	DiskPath       string        `mapstructure:"disk_path"`
 }
 
 // QualityRulesConfig defines data quality validation rules
@@ -78,6 +263,10 @@ type QualityRulesConfig struct {
 	EnableSchemaValidation bool              `mapstructure:"enable_schema_validation"`
 	CardinalityLimits      map[string]int    `mapstructure:"cardinality_limits"`
 	DataTypeValidation     map[string]string `mapstructure:"data_type_validation"`
+	// DuplicateCacheSize caps the number of tracked attribute values (LRU eviction)
+	DuplicateCacheSize     int               `mapstructure:"duplicate_cache_size"`
+	// DuplicateCacheTTL expires tracked values that have not been seen recently
+	DuplicateCacheTTL      time.Duration     `mapstructure:"duplicate_cache_ttl"`
 }
 
 // PIIDetectionConfig configures PII detection and sanitization
@@ -87,6 +276,28 @@ type PIIDetectionConfig struct {
 	CustomPatterns  []string `mapstructure:"custom_patterns"`
 	ExcludeFields   []string `mapstructure:"exclude_fields"`
 	SensitivityLevel string  `mapstructure:"sensitivity_level"` // low, medium, high
+
+	// ScanFields restricts scanning to these attribute keys, such as
+	// db.statement, and "body" adds the log record body. When empty every
+	// attribute is scanned and the body is not. ExcludeFields are skipped
+	// either way.
+	ScanFields []string `mapstructure:"scan_fields"`
+}
+
+// piiFieldBody names the log record body in ScanFields
+const piiFieldBody = "body"
+
+// scansField reports whether the attribute key is scanned for PII
+func (cfg *PIIDetectionConfig) scansField(key string) bool {
+	if slices.Contains(cfg.ExcludeFields, key) {
+		return false
+	}
+	return len(cfg.ScanFields) == 0 || slices.Contains(cfg.ScanFields, key)
+}
+
+// scansBody reports whether the log record body is scanned for PII
+func (cfg *PIIDetectionConfig) scansBody() bool {
+	return slices.Contains(cfg.ScanFields, piiFieldBody) && !slices.Contains(cfg.ExcludeFields, piiFieldBody)
 }
 
 
@@ -110,6 +321,14 @@ func (cfg *Config) Validate() error {
 		return errors.New("min_normalization_rate must be between 0.0 and 1.0")
 	}
 	
+	if cfg.FeedbackChannelSize <= 0 {
+		return errors.New("feedback_channel_size must be positive")
+	}
+	
+	if cfg.FeedbackCoalescing.Enabled && cfg.FeedbackCoalescing.Window <= 0 {
+		return errors.New("feedback_coalescing.window must be positive when coalescing is enabled")
+	}
+	
 	// Validate health check configuration
 	if cfg.EnableContinuousHealthChecks {
 		if cfg.HealthCheckInterval <= 0 {
@@ -129,6 +348,58 @@ func (cfg *Config) Validate() error {
 		}
 	}
 	
+	if cfg.HealthThresholds.MemoryLimitMiB < 0 {
+		return errors.New("health_thresholds.memory_limit_mib cannot be negative")
+	}
+	
+	if cfg.EntitySynthesis.Enabled {
+		if err := cfg.EntitySynthesis.validate(); err != nil {
+			return err
+		}
+	}
+	
+	if cfg.HealthEndpoint.Enabled && cfg.HealthEndpoint.Endpoint == "" {
+		return errors.New("health_endpoint.endpoint must be set when the health endpoint is enabled")
+	}
+	
+	if cfg.HealthReportOutput.enabled() {
+		if err := cfg.HealthReportOutput.validate(); err != nil {
+			return err
+		}
+	}
+	
+	// Validate auto-tuning configuration
+	if cfg.EnableAutoTuning {
+		if cfg.AutoTuningInterval <= 0 {
+			return errors.New("auto_tuning_interval must be positive when auto-tuning is enabled")
+		}
+		
+		if cfg.AutoTuningConfig.MinConfidenceLevel < 0 || cfg.AutoTuningConfig.MinConfidenceLevel > 1 {
+			return errors.New("auto_tuning_config.min_confidence_level must be between 0.0 and 1.0")
+		}
+		
+		if cfg.AutoTuningConfig.MaxParameterChange < 0 || cfg.AutoTuningConfig.MaxParameterChange > 1 {
+			return errors.New("auto_tuning_config.max_parameter_change must be between 0.0 and 1.0")
+		}
+	}
+	
+	if cfg.ConsumerTimeout < 0 {
+		return errors.New("consumer_timeout cannot be negative")
+	}
+	
+	if cfg.QualitySampleRate <= 0 || cfg.QualitySampleRate > 1 {
+		return errors.New("quality_sample_rate must be greater than 0.0 and at most 1.0")
+	}
+	
+	// Validate quality rules
+	if cfg.QualityRules.DuplicateCacheSize <= 0 {
+		return errors.New("quality_rules.duplicate_cache_size must be positive")
+	}
+	
+	if cfg.QualityRules.DuplicateCacheTTL < 0 {
+		return errors.New("quality_rules.duplicate_cache_ttl cannot be negative")
+	}
+	
 	
 	// Validate PII detection configuration
 	if cfg.PIIDetection.Enabled {
@@ -138,6 +409,29 @@ func (cfg *Config) Validate() error {
 		if cfg.PIIDetection.SensitivityLevel != "" && !validSensitivityLevels[cfg.PIIDetection.SensitivityLevel] {
 			return errors.New("pii_detection.sensitivity_level must be 'low', 'medium', or 'high'")
 		}
+		if slices.Contains(cfg.PIIDetection.ScanFields, "") {
+			return errors.New("pii_detection.scan_fields cannot contain an empty field")
+		}
+	}
+	
+	if cfg.FeedbackExport.MaxBatchSize <= 0 {
+		return errors.New("feedback_export.max_batch_size must be positive")
+	}
+	if cfg.FeedbackExport.FlushInterval <= 0 {
+		return errors.New("feedback_export.flush_interval must be positive")
+	}
+	if cfg.FeedbackExport.QueueSize <= 0 {
+		return errors.New("feedback_export.queue_size must be positive")
+	}
+	if cfg.FeedbackExport.Timeout <= 0 {
+		return errors.New("feedback_export.timeout must be positive")
+	}
+	
+	if cfg.MaxBodyBytes < 0 {
+		return errors.New("max_body_bytes cannot be negative")
+	}
+	if cfg.MaxStatementBytes < 0 {
+		return errors.New("max_statement_bytes cannot be negative")
 	}
 	
 	// Validate custom queries
@@ -156,6 +450,36 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+// validate checks an enabled entity synthesis rule
+func (cfg *EntitySynthesisConfig) validate() error {
+	if cfg.AccountID == "" {
+		return errors.New("entity_synthesis.account_id must be set when entity synthesis is enabled")
+	}
+	if cfg.Domain == "" {
+		return errors.New("entity_synthesis.domain cannot be empty")
+	}
+	if cfg.SystemAttribute == "" {
+		return errors.New("entity_synthesis.system_attribute cannot be empty")
+	}
+	if len(cfg.EntityTypes) == 0 {
+		return errors.New("entity_synthesis.entity_types must map at least one database system")
+	}
+	for system, entityType := range cfg.EntityTypes {
+		if entityType == "" {
+			return fmt.Errorf("entity_synthesis.entity_types[%s] cannot be empty", system)
+		}
+	}
+	if len(cfg.IdentifierAttributes) == 0 {
+		return errors.New("entity_synthesis.identifier_attributes must name at least one attribute")
+	}
+	for _, attr := range cfg.IdentifierAttributes {
+		if attr == "" {
+			return errors.New("entity_synthesis.identifier_attributes cannot contain an empty attribute name")
+		}
+	}
+	return nil
+}
+
 // createDefaultConfig creates the default configuration for the verification processor
 func createDefaultConfig() component.Config {
 	return &Config{
@@ -165,7 +489,28 @@ func createDefaultConfig() component.Config {
 		MinEntityCorrelationRate:   0.8, // 80%
 		MinNormalizationRate:       0.9, // 90%
 		RequireEntitySynthesis:     true,
+		EntitySynthesis: EntitySynthesisConfig{
+			Enabled:         false,
+			Domain:          "INFRA",
+			SystemAttribute: "db.system",
+			EntityTypes: map[string]string{
+				"postgresql": "POSTGRESQLINSTANCE",
+				"mysql":      "MYSQLNODE",
+			},
+			IdentifierAttributes: []string{"server.address", "server.port", "db.name"},
+		},
 		ExportFeedbackAsLogs:       true,
+		FeedbackChannelSize:        1000,
+		FeedbackCoalescing: FeedbackCoalescingConfig{
+			Enabled: false,
+			Window:  30 * time.Second,
+		},
+		FeedbackExport: FeedbackExportConfig{
+			MaxBatchSize:  100,
+			FlushInterval: 10 * time.Second,
+			QueueSize:     1000,
+			Timeout:       5 * time.Second,
+		},
 		
 		// Continuous health checks
 		EnableContinuousHealthChecks: true,
@@ -195,7 +540,37 @@ func createDefaultConfig() component.Config {
 				"error_count":   "int",
 				"database_name": "string",
 			},
+			DuplicateCacheSize: 10000,
+			DuplicateCacheTTL:  24 * time.Hour,
+		},
+		
+		HealthEndpoint: HealthEndpointConfig{
+			Enabled:  false,
+			Endpoint: "localhost:13134",
+		},
+		HealthReportOutput: HealthReportOutputConfig{
+			Interval: 5 * time.Minute,
+			File: HealthReportFileConfig{
+				MaxSizeMiB: 10,
+				MaxFiles:   5,
+			},
+			Webhook: HealthReportWebhookConfig{
+				Timeout:      10 * time.Second,
+				MaxRetries:   3,
+				RetryBackoff: time.Second,
+				QueueSize:    10,
+			},
+		},
+		
+		// Auto-tuning
+		EnableAutoTuning:   false,
+		AutoTuningInterval: 10 * time.Minute,
+		AutoTuningConfig: AutoTuningConfig{
+			EnableAutoApply:    false, // Conservative default
+			MinConfidenceLevel: 0.8,   // 80%
+			MaxParameterChange: 0.2,   // 20%
 		},
+		QualitySampleRate: 1.0,
 		
 		// PII detection
 		PIIDetection: PIIDetectionConfig{
@@ -57,6 +57,7 @@ func createLogsProcessor(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create verification processor: %w", err)
 	}
+	vp.id = set.ID
 	
 	return vp, nil
 }
\ No newline at end of file
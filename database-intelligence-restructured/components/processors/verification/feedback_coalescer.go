@@ -0,0 +1,87 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// feedbackCoalescer merges repeated feedback events of the same kind within a
+// window. The first event of a window is delivered immediately; repeats are
+// counted and summarized in a single event once the window closes.
+type feedbackCoalescer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*coalescedFeedback
+}
+
+// coalescedFeedback tracks one open coalescing window
+type coalescedFeedback struct {
+	first       FeedbackEvent
+	windowStart time.Time
+	repeats     int64
+}
+
+func newFeedbackCoalescer(window time.Duration) *feedbackCoalescer {
+	return &feedbackCoalescer{
+		window:  window,
+		pending: make(map[string]*coalescedFeedback),
+	}
+}
+
+// coalesceKey groups events that describe the same condition
+func coalesceKey(event FeedbackEvent) string {
+	return event.Category + "|" + event.Level + "|" + event.Database
+}
+
+// admit reports whether the event should be delivered now. Events that fall
+// into an open window are absorbed into that window's summary.
+func (c *feedbackCoalescer) admit(event FeedbackEvent, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := coalesceKey(event)
+	if entry, ok := c.pending[key]; ok && now.Sub(entry.windowStart) < c.window {
+		entry.repeats++
+		return false
+	}
+
+	c.pending[key] = &coalescedFeedback{
+		first:       event,
+		windowStart: now,
+	}
+	return true
+}
+
+// flush closes expired windows and returns a summary event for each window
+// that absorbed repeats
+func (c *feedbackCoalescer) flush(now time.Time) []FeedbackEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var summaries []FeedbackEvent
+	for key, entry := range c.pending {
+		if now.Sub(entry.windowStart) < c.window {
+			continue
+		}
+		delete(c.pending, key)
+
+		if entry.repeats == 0 {
+			continue
+		}
+
+		summary := entry.first
+		summary.Timestamp = now
+		summary.Message = fmt.Sprintf("%s (repeated %d times in %s)", entry.first.Message, entry.repeats, c.window)
+		summary.Metrics = make(map[string]interface{}, len(entry.first.Metrics)+1)
+		for k, v := range entry.first.Metrics {
+			summary.Metrics[k] = v
+		}
+		summary.Metrics["occurrences"] = entry.repeats
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
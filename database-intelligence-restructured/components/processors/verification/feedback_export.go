@@ -0,0 +1,152 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// feedbackExporter sends feedback events to the next consumer in batches,
+// from its own goroutine, so a slow pipeline cannot stall the feedback loop
+// or the processing of data. Events that do not fit in the queue are
+// dropped and counted.
+type feedbackExporter struct {
+	logger *zap.Logger
+	next   consumer.Logs
+	config FeedbackExportConfig
+	queue  chan FeedbackEvent
+
+	exported atomic.Int64
+	batches  atomic.Int64
+	dropped  atomic.Int64
+	// reportedDrops is the drop count last logged
+	reportedDrops int64
+}
+
+func newFeedbackExporter(logger *zap.Logger, next consumer.Logs, cfg FeedbackExportConfig) *feedbackExporter {
+	return &feedbackExporter{
+		logger: logger,
+		next:   next,
+		config: cfg,
+		queue:  make(chan FeedbackEvent, cfg.QueueSize),
+	}
+}
+
+// enqueue queues an event for export without blocking
+func (fe *feedbackExporter) enqueue(event FeedbackEvent) {
+	select {
+	case fe.queue <- event:
+	default:
+		fe.dropped.Add(1)
+	}
+}
+
+// run exports queued events whenever max_batch_size of them are waiting and
+// every flush_interval, until done is closed. What is still queued then is
+// exported before it returns.
+func (fe *feedbackExporter) run(done <-chan struct{}) {
+	ticker := time.NewTicker(fe.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]FeedbackEvent, 0, fe.config.MaxBatchSize)
+	add := func(event FeedbackEvent) {
+		batch = append(batch, event)
+		if len(batch) >= fe.config.MaxBatchSize {
+			batch = fe.export(batch)
+		}
+	}
+
+	for {
+		select {
+		case event := <-fe.queue:
+			add(event)
+		case <-ticker.C:
+			batch = fe.export(batch)
+			fe.reportDrops()
+		case <-done:
+			for {
+				select {
+				case event := <-fe.queue:
+					add(event)
+				default:
+					fe.export(batch)
+					fe.reportDrops()
+					fe.logger.Debug("Stopped feedback export",
+						zap.Int64("exported", fe.exported.Load()),
+						zap.Int64("batches", fe.batches.Load()),
+						zap.Int64("dropped", fe.dropped.Load()))
+					return
+				}
+			}
+		}
+	}
+}
+
+// export sends the batch as one set of log records and returns it emptied
+func (fe *feedbackExporter) export(batch []FeedbackEvent) []FeedbackEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fe.config.Timeout)
+	defer cancel()
+	if err := fe.next.ConsumeLogs(ctx, feedbackLogs(batch)); err != nil {
+		fe.logger.Error("Failed to export feedback as logs", zap.Int("events", len(batch)), zap.Error(err))
+	} else {
+		fe.exported.Add(int64(len(batch)))
+	}
+	fe.batches.Add(1)
+	return batch[:0]
+}
+
+// reportDrops logs the events dropped since the last report
+func (fe *feedbackExporter) reportDrops() {
+	dropped := fe.dropped.Load()
+	if dropped == fe.reportedDrops {
+		return
+	}
+	fe.logger.Warn("Feedback export queue full, dropped events",
+		zap.Int64("dropped", dropped-fe.reportedDrops),
+		zap.Int64("dropped_total", dropped),
+		zap.Int("queue_size", fe.config.QueueSize))
+	fe.reportedDrops = dropped
+}
+
+// feedbackLogs converts feedback events to log records under one resource
+func feedbackLogs(events []FeedbackEvent) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+
+	resource := rl.Resource()
+	resource.Attributes().PutStr("service.name", "database-intelligence-verification")
+	resource.Attributes().PutStr("service.version", "2.0.0")
+
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	records.EnsureCapacity(len(events))
+	for _, event := range events {
+		logRecord := records.AppendEmpty()
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(event.Timestamp))
+		logRecord.SetSeverityNumber(plog.SeverityNumber(event.Severity))
+		logRecord.SetSeverityText(event.Level)
+
+		body, _ := json.Marshal(event)
+		logRecord.Body().SetStr(string(body))
+
+		attrs := logRecord.Attributes()
+		attrs.PutStr("feedback.category", event.Category)
+		attrs.PutStr("feedback.level", event.Level)
+		if event.Database != "" {
+			attrs.PutStr("database.name", event.Database)
+		}
+	}
+	return logs
+}
@@ -0,0 +1,119 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.uber.org/goleak"
+	"go.uber.org/zap"
+)
+
+// allWorkersConfig enables every background worker and the health endpoint
+func allWorkersConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnablePeriodicVerification = true
+	cfg.EnableContinuousHealthChecks = true
+	cfg.EnableAutoTuning = true
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "127.0.0.1:0"
+	return cfg
+}
+
+func TestVerificationProcessor_WorkersExitAfterShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+
+	processor.sendFeedback(FeedbackEvent{Level: "INFO", Category: "lifecycle", Message: "running"})
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_ShutdownIsIdempotent(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	require.NoError(t, processor.Shutdown(context.Background()))
+
+	// Feedback raised after shutdown is dropped rather than sent on a closed channel
+	assert.NotPanics(t, func() {
+		processor.sendFeedback(FeedbackEvent{Level: "INFO", Category: "lifecycle", Message: "late"})
+	})
+}
+
+func TestVerificationProcessor_ShutdownBeforeStart(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.ErrorIs(t, processor.Start(context.Background(), nil), errShutDown, "a shut down processor cannot be restarted")
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_StartTwice(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processor, err := newVerificationProcessor(zap.NewNop(), allWorkersConfig(), &consumertest.LogsSink{})
+	require.NoError(t, err)
+
+	require.NoError(t, processor.Start(context.Background(), nil))
+	listener := processor.healthServer.listener
+	require.NoError(t, processor.Start(context.Background(), nil))
+	assert.Same(t, listener, processor.healthServer.listener, "the health endpoint is only started once")
+
+	processor.startWorkers()
+	require.NoError(t, processor.Shutdown(context.Background()))
+}
+
+func TestVerificationProcessor_ShutdownDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackExport.FlushInterval = time.Hour
+	next := &blockingLogsConsumer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+
+	// The feedback export blocks flushing this event on shutdown
+	processor.sendFeedback(FeedbackEvent{Level: "WARNING", Category: "lifecycle", Message: "stuck"})
+	require.Eventually(t, func() bool {
+		return len(processor.feedbackChannel) == 0 && len(processor.feedbackExport.queue) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, processor.Shutdown(ctx), context.DeadlineExceeded)
+
+	// Once the export completes the workers exit and a later Shutdown succeeds
+	close(next.release)
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.Equal(t, 1, next.LogRecordCount())
+}
+
+func TestConcurrentVerificationProcessor_Lifecycle(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), allWorkersConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, lp.Start(context.Background(), nil))
+	require.NoError(t, lp.Start(context.Background(), nil))
+	require.NoError(t, lp.Shutdown(context.Background()))
+	require.NoError(t, lp.Shutdown(context.Background()))
+	assert.ErrorIs(t, lp.Start(context.Background(), nil), errShutDown)
+}
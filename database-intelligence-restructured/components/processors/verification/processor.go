@@ -5,15 +5,19 @@ package verification
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"regexp"
-	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -23,19 +27,46 @@ import (
 
 // VerificationProcessor provides real-time feedback on data quality and integration health
 type VerificationProcessor struct {
+	// id names the processor to the stats extension; the factory sets it
+	id               component.ID
 	logger           *zap.Logger
 	nextConsumer     consumer.Logs
 	config           *Config
+	configMu         sync.RWMutex // guards config, replaced by Reconfigure
 	metrics          *VerificationMetrics
 	feedbackChannel  chan FeedbackEvent
 	shutdownChan     chan struct{}
 	wg              sync.WaitGroup
+	
+	// Guard against starting the workers or health endpoint twice and
+	// closing shutdownChan twice
+	workersOnce  sync.Once
+	startOnce    sync.Once
+	shutdownOnce sync.Once
+	
+	// Runtime parameters adjusted by auto-tuning
+	liveParams *liveParameters
+	
+	// Fills in entity.guid when entity synthesis is enabled; guarded by
+	// configMu, replaced by Reconfigure
+	entitySynthesizer *entitySynthesizer
+	
+	// Optional HTTP endpoint serving the health report
+	healthServer *healthServer
+	
+	// Optional file and webhook destinations for periodic health reports
+	reportOutput *healthReportOutput
+	
+	// Feedback delivery
+	feedbackCoalescer *feedbackCoalescer
+	feedbackDropped   atomic.Int64
 
 	// Quality validation components
 	qualityValidator *QualityValidator
 	piiDetector      *PIIDetector
 	healthChecker    *HealthChecker
 	feedbackEngine   *FeedbackEngine
+	feedbackExport   *feedbackExporter
 
 	// Performance tracking
 	performanceTracker *PerformanceTracker
@@ -47,6 +78,7 @@ type VerificationMetrics struct {
 	mu                     sync.RWMutex
 	recordsProcessed       int64
 	entitiesCreated        int64
+	entitiesSynthesized    int64
 	errorsDetected         int64
 	cardinalityWarnings    int64
 	lastDataTimestamp      time.Time
@@ -83,7 +115,21 @@ type QualityValidator struct {
 	dataTypeMismatches  int64
 	missingRequiredFields int64
 	schemaViolations    int64
-	duplicateDetection  map[string]time.Time
+	duplicateDetection  *lru.Cache[string, time.Time]
+	duplicateTTL        time.Duration
+}
+
+// expireDuplicates removes entries older than the TTL. Callers must hold mu.
+func (qv *QualityValidator) expireDuplicates(now time.Time) {
+	if qv.duplicateTTL <= 0 {
+		return
+	}
+	cutoff := now.Add(-qv.duplicateTTL)
+	for _, key := range qv.duplicateDetection.Keys() {
+		if timestamp, ok := qv.duplicateDetection.Peek(key); ok && timestamp.Before(cutoff) {
+			qv.duplicateDetection.Remove(key)
+		}
+	}
 }
 
 // PIIDetector detects and flags potential PII in logs
@@ -105,6 +151,7 @@ type HealthChecker struct {
 	networkLatency        time.Duration
 	databaseConnectivity  map[string]bool
 	alertThresholds       HealthThresholds
+	resourceSource        ResourceSource
 }
 
 // HealthThresholds defines alert thresholds for health monitoring
@@ -115,17 +162,20 @@ type HealthThresholds struct {
 	NetworkLatency time.Duration
 }
 
-// FeedbackEngine provides performance feedback capabilities
+// FeedbackEngine provides auto-tuning capabilities
 type FeedbackEngine struct {
-	mu                   sync.RWMutex
-	performanceHistory   []PerformanceSnapshot
-	tunableParameters    map[string]interface{}
+	mu                    sync.RWMutex
+	performanceHistory    []PerformanceSnapshot
+	appliedTunings        []AppliedTuning
+	lastTuning            time.Time
+	tuningRecommendations []TuningRecommendation
 }
 
 // PerformanceSnapshot captures performance at a point in time
 type PerformanceSnapshot struct {
 	Timestamp          time.Time
 	Throughput         float64
+	// Latency is the mean time ConsumeLogs takes per batch
 	Latency           time.Duration
 	ErrorRate         float64
 	ResourceUtilization float64
@@ -137,10 +187,18 @@ type PerformanceTracker struct {
 	mu              sync.RWMutex
 	startTime       time.Time
 	recordsProcessed int64
+	lastRecordTime  time.Time
+	// totalLatency is the time spent in ConsumeLogs over batches calls,
+	// including the next consumer
 	totalLatency    time.Duration
+	batches         int64
 	errorCount      int64
 	throughputHistory []float64
 	maxHistorySize  int
+
+	// stageTime is the cumulative time per verification stage, in
+	// nanoseconds, updated per record without taking mu
+	stageTime [stageCount]atomic.Int64
 }
 
 // ResourceMonitor monitors system resources
@@ -162,20 +220,41 @@ func newVerificationProcessor(
 ) (*VerificationProcessor, error) {
 	
 	vp := &VerificationProcessor{
+		id:              component.NewID(componentType),
 		logger:          logger,
 		nextConsumer:    nextConsumer,
 		config:          config,
 		metrics:         &VerificationMetrics{
 			databaseMetrics: make(map[string]*DatabaseMetrics),
 		},
-		feedbackChannel: make(chan FeedbackEvent, 1000),
+		feedbackChannel: make(chan FeedbackEvent, config.FeedbackChannelSize),
 		shutdownChan:    make(chan struct{}),
 	}
 	
-	// Initialize quality validator
+	vp.liveParams = newLiveParameters(config)
+	vp.entitySynthesizer = newEntitySynthesizer(config.EntitySynthesis)
+	
+	if config.HealthReportOutput.enabled() {
+		vp.reportOutput = newHealthReportOutput(logger, config.HealthReportOutput)
+	}
+	
+	if config.HealthEndpoint.Enabled {
+		vp.healthServer = newHealthServer(vp, config.HealthEndpoint.Endpoint)
+	}
+	
+	if config.FeedbackCoalescing.Enabled {
+		vp.feedbackCoalescer = newFeedbackCoalescer(config.FeedbackCoalescing.Window)
+	}
+	
+	// Initialize quality validator with a size-capped duplicate detection cache
+	duplicateCache, err := lru.New[string, time.Time](config.QualityRules.DuplicateCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duplicate detection cache: %w", err)
+	}
 	vp.qualityValidator = &QualityValidator{
 		cardinalityLimits:  make(map[string]int),
-		duplicateDetection: make(map[string]time.Time),
+		duplicateDetection: duplicateCache,
+		duplicateTTL:       config.QualityRules.DuplicateCacheTTL,
 	}
 	
 	// Initialize PII detector with common patterns
@@ -193,12 +272,15 @@ func newVerificationProcessor(
 			DiskPercent:    config.HealthThresholds.DiskPercent,
 			NetworkLatency: config.HealthThresholds.NetworkLatency,
 		},
+		resourceSource: newRuntimeResourceSource(
+			config.HealthThresholds.DiskPath,
+			uint64(config.HealthThresholds.MemoryLimitMiB)*1024*1024,
+		),
 	}
 	
 	// Initialize feedback engine
 	vp.feedbackEngine = &FeedbackEngine{
-		tunableParameters:  make(map[string]interface{}),
-		performanceHistory: make([]PerformanceSnapshot, 0, 1000),
+		performanceHistory: make([]PerformanceSnapshot, 0, 100),
 	}
 	
 	// Initialize performance tracker
@@ -213,40 +295,214 @@ func newVerificationProcessor(
 		lastUpdate: time.Now(),
 	}
 	
-	// Start background processes
-	vp.wg.Add(1)
-	go vp.processFeedback()
+	vp.feedbackExport = newFeedbackExporter(logger, nextConsumer, config.FeedbackExport)
 	
-	if config.EnablePeriodicVerification {
-		vp.wg.Add(1)
-		go vp.periodicVerification()
-	}
+	vp.startWorkers()
 	
-	if config.EnableContinuousHealthChecks {
+	return vp, nil
+}
+
+// startWorkers starts the background goroutines. They run from construction
+// so feedback is delivered even if Start is never called, and exit when
+// shutdownChan is closed. Calling it again has no effect.
+func (vp *VerificationProcessor) startWorkers() {
+	vp.workersOnce.Do(func() {
+		cfg := vp.currentConfig()
+		
+		vp.wg.Add(2)
+		go vp.processFeedback()
+		go func() {
+			defer vp.wg.Done()
+			vp.feedbackExport.run(vp.shutdownChan)
+		}()
+		
+		if cfg.EnablePeriodicVerification {
+			vp.wg.Add(1)
+			go vp.periodicVerification()
+		}
+		
+		if cfg.EnableContinuousHealthChecks {
+			vp.wg.Add(1)
+			go vp.continuousHealthChecks()
+		}
+		
+		if cfg.EnableAutoTuning {
+			vp.wg.Add(1)
+			go vp.autoTuningEngine()
+		}
+		
+		if vp.reportOutput != nil {
+			vp.wg.Add(1)
+			go vp.healthReportOutputLoop()
+			
+			if vp.reportOutput.queue != nil {
+				vp.wg.Add(1)
+				go func() {
+					defer vp.wg.Done()
+					vp.reportOutput.deliverWebhook(vp.shutdownChan)
+				}()
+			}
+		}
+		
+		// Start resource monitoring
 		vp.wg.Add(1)
-		go vp.continuousHealthChecks()
+		go vp.resourceMonitoring()
+	})
+}
+
+// currentConfig returns the configuration in effect
+func (vp *VerificationProcessor) currentConfig() *Config {
+	vp.configMu.RLock()
+	defer vp.configMu.RUnlock()
+	return vp.config
+}
+
+// Reconfigure implements base.Reconfigurable. Thresholds, quality rules, size
+// limits and the PII, entity synthesis and feedback settings apply to the next records and
+// checks. A changed consumer_timeout or quality_sample_rate replaces the
+// value auto-tuning arrived at.
+// Enabling or disabling the periodic checks or auto-tuning, changing their
+// intervals or turning PII detection on or off requires a restart, as those
+// start background workers.
+func (vp *VerificationProcessor) Reconfigure(cfg component.Config) error {
+	newConfig, ok := cfg.(*Config)
+	if !ok {
+		return fmt.Errorf("invalid config type: %T", cfg)
 	}
-	
-	// Start resource monitoring
-	vp.wg.Add(1)
-	go vp.resourceMonitoring()
-	
-	return vp, nil
+
+	vp.configMu.Lock()
+	defer vp.configMu.Unlock()
+
+	old := vp.config
+	switch {
+	case newConfig.EnablePeriodicVerification != old.EnablePeriodicVerification,
+		newConfig.VerificationInterval != old.VerificationInterval:
+		return fmt.Errorf("%w: periodic verification settings changed", base.ErrRestartRequired)
+	case newConfig.EnableContinuousHealthChecks != old.EnableContinuousHealthChecks,
+		newConfig.HealthCheckInterval != old.HealthCheckInterval:
+		return fmt.Errorf("%w: continuous health check settings changed", base.ErrRestartRequired)
+	case newConfig.PIIDetection.Enabled != old.PIIDetection.Enabled:
+		return fmt.Errorf("%w: pii_detection.enabled changed", base.ErrRestartRequired)
+	case newConfig.FeedbackExport != old.FeedbackExport:
+		return fmt.Errorf("%w: feedback_export settings changed", base.ErrRestartRequired)
+	case newConfig.HealthEndpoint != old.HealthEndpoint:
+		return fmt.Errorf("%w: health_endpoint settings changed", base.ErrRestartRequired)
+	case !reflect.DeepEqual(newConfig.HealthReportOutput, old.HealthReportOutput):
+		return fmt.Errorf("%w: health_report_output settings changed", base.ErrRestartRequired)
+	case newConfig.EnableAutoTuning != old.EnableAutoTuning,
+		newConfig.AutoTuningInterval != old.AutoTuningInterval:
+		return fmt.Errorf("%w: auto-tuning settings changed", base.ErrRestartRequired)
+	case newConfig.FeedbackChannelSize != old.FeedbackChannelSize,
+		newConfig.FeedbackCoalescing != old.FeedbackCoalescing:
+		return fmt.Errorf("%w: feedback channel or coalescing settings changed", base.ErrRestartRequired)
+	}
+
+	vp.healthChecker.mu.Lock()
+	vp.healthChecker.alertThresholds = HealthThresholds{
+		MemoryPercent:  newConfig.HealthThresholds.MemoryPercent,
+		CPUPercent:     newConfig.HealthThresholds.CPUPercent,
+		DiskPercent:    newConfig.HealthThresholds.DiskPercent,
+		NetworkLatency: newConfig.HealthThresholds.NetworkLatency,
+	}
+	if newConfig.HealthThresholds.DiskPath != old.HealthThresholds.DiskPath ||
+		newConfig.HealthThresholds.MemoryLimitMiB != old.HealthThresholds.MemoryLimitMiB {
+		vp.healthChecker.resourceSource = newRuntimeResourceSource(
+			newConfig.HealthThresholds.DiskPath,
+			uint64(newConfig.HealthThresholds.MemoryLimitMiB)*1024*1024,
+		)
+	}
+	vp.healthChecker.mu.Unlock()
+
+	vp.qualityValidator.mu.Lock()
+	vp.qualityValidator.duplicateDetection.Resize(newConfig.QualityRules.DuplicateCacheSize)
+	vp.qualityValidator.duplicateTTL = newConfig.QualityRules.DuplicateCacheTTL
+	vp.qualityValidator.mu.Unlock()
+
+	if newConfig.ConsumerTimeout != old.ConsumerTimeout {
+		vp.liveParams.setConsumerTimeout(newConfig.ConsumerTimeout)
+	}
+	if newConfig.QualitySampleRate != old.QualitySampleRate {
+		vp.liveParams.setQualitySampleRate(newConfig.QualitySampleRate)
+	}
+	vp.entitySynthesizer = newEntitySynthesizer(newConfig.EntitySynthesis)
+	vp.config = newConfig
+
+	vp.logger.Info("Reconfigured verification processor",
+		zap.Duration("data_freshness_threshold", newConfig.DataFreshnessThreshold),
+		zap.Float64("min_entity_correlation_rate", newConfig.MinEntityCorrelationRate),
+		zap.Float64("min_normalization_rate", newConfig.MinNormalizationRate))
+
+	return nil
 }
 
-// Start implements the component.Component interface
+// errShutDown is returned by Start once the processor has been shut down
+var errShutDown = errors.New("verification processor has been shut down")
+
+// Start implements the component.Component interface. Calling it again has no
+// effect.
 func (vp *VerificationProcessor) Start(ctx context.Context, host component.Host) error {
-	vp.logger.Info("Starting verification processor")
-	return nil
+	if vp.isShutDown() {
+		return errShutDown
+	}
+	
+	var err error
+	vp.startOnce.Do(func() {
+		vp.logger.Info("Starting verification processor")
+		vp.registerStatsProvider(host)
+		err = vp.startHealthServer()
+	})
+	return err
 }
 
-// Shutdown implements the component.Component interface
+// Shutdown implements the component.Component interface. It is safe to call
+// more than once and before Start.
 func (vp *VerificationProcessor) Shutdown(ctx context.Context) error {
-	vp.logger.Info("Shutting down verification processor")
+	vp.shutdownOnce.Do(func() {
+		vp.logger.Info("Shutting down verification processor")
+		vp.signalShutdown(ctx)
+	})
+	return vp.waitForWorkers(ctx)
+}
+
+// isShutDown reports whether Shutdown has been called
+func (vp *VerificationProcessor) isShutDown() bool {
+	select {
+	case <-vp.shutdownChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// signalShutdown tells the background workers to exit, aborts an in-flight
+// health report delivery and stops the health endpoint. Callers must run it
+// once, under shutdownOnce.
+func (vp *VerificationProcessor) signalShutdown(ctx context.Context) {
 	close(vp.shutdownChan)
-	vp.wg.Wait()
-	close(vp.feedbackChannel)
-	return nil
+	if vp.reportOutput != nil {
+		vp.reportOutput.cancel()
+	}
+	vp.shutdownHealthServer(ctx)
+}
+
+// waitForWorkers waits for the background workers to exit. They exit at their
+// next select, but one may be blocked exporting feedback; give up once the
+// shutdown deadline passes. feedbackChannel is left open so late sendFeedback
+// calls are dropped instead of panicking.
+func (vp *VerificationProcessor) waitForWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		vp.wg.Wait()
+		close(done)
+	}()
+	
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		vp.logger.Warn("Verification workers did not exit before the shutdown deadline")
+		return ctx.Err()
+	}
 }
 
 // Capabilities implements the consumer.Consumer interface
@@ -261,6 +517,7 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 	// Track performance
 	vp.performanceTracker.mu.Lock()
 	vp.performanceTracker.recordsProcessed += int64(ld.LogRecordCount())
+	vp.performanceTracker.lastRecordTime = startTime
 	vp.performanceTracker.mu.Unlock()
 	
 	// Process each resource
@@ -281,6 +538,9 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 			for k := 0; k < logs.Len(); k++ {
 				log := logs.At(k)
 				
+				// Fill in missing entity attributes before verifying the record
+				vp.synthesizeEntity(resource.Attributes(), log.Attributes())
+				
 				// Verify log attributes
 				if err := vp.verifyLogRecord(log); err != nil {
 					vp.logger.Debug("Log verification failed", zap.Error(err))
@@ -292,17 +552,24 @@ func (vp *VerificationProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs)
 		}
 	}
 	
-	// Forward to next consumer
-	err := vp.nextConsumer.ConsumeLogs(ctx, ld)
+	// Forward to next consumer, bounded by the live consumer timeout
+	nextCtx := ctx
+	if timeout := vp.liveParams.ConsumerTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		nextCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := vp.nextConsumer.ConsumeLogs(nextCtx, ld)
 	
 	// Track latency
 	vp.performanceTracker.mu.Lock()
 	vp.performanceTracker.totalLatency += time.Since(startTime)
+	vp.performanceTracker.batches++
 	if err != nil {
 		vp.performanceTracker.errorCount++
-		vp.performanceTracker.mu.Unlock()
 		// Log error - self-healing removed
 	}
+	vp.performanceTracker.mu.Unlock()
 	
 	return err
 }
@@ -328,8 +595,10 @@ func (vp *VerificationProcessor) verifyResourceAttributes(attrs pcommon.Map) {
 // verifyLogRecord verifies individual log record
 func (vp *VerificationProcessor) verifyLogRecord(log plog.LogRecord) error {
 	attrs := log.Attributes()
+	vp.truncateOversized(log)
 	
 	// Check required fields
+	start := time.Now()
 	missing := vp.checkRequiredFields(attrs)
 	if len(missing) > 0 {
 		vp.sendFeedback(FeedbackEvent{
@@ -340,26 +609,84 @@ func (vp *VerificationProcessor) verifyLogRecord(log plog.LogRecord) error {
 			Severity:  6,
 		})
 	}
+	vp.performanceTracker.observeStage(stageSchemaValidation, start)
 	
 	// Check for PII
-	if vp.config.PIIDetection.Enabled {
+	if vp.currentConfig().PIIDetection.Enabled {
+		start = time.Now()
 		vp.detectPII(attrs)
+		vp.detectPIIInBody(log.Body())
+		vp.performanceTracker.observeStage(stagePIIScan, start)
 	}
 	
-	// Validate data quality
-	vp.validateDataQuality(attrs)
-	
-	// Check cardinality
-	vp.checkCardinality(attrs)
+	// Validate data quality, sampled when auto-tuning has reduced the rate
+	if vp.sampleQuality() {
+		start = time.Now()
+		vp.validateDataQuality(attrs)
+		
+		// Check cardinality
+		vp.checkCardinality(attrs)
+		vp.performanceTracker.observeStage(stageQualityValidation, start)
+	}
 	
 	return nil
 }
 
+// truncationMarker ends a value cut down to its size limit
+const truncationMarker = "...[truncated]"
+
+const (
+	attrStatement               = "db.statement"
+	attrBodyOriginalLength      = "log.body.original_length"
+	attrStatementOriginalLength = "db.statement.original_length"
+)
+
+// truncateOversized cuts a string body longer than max_body_bytes and a
+// db.statement longer than max_statement_bytes down to the limit, recording
+// the original length in an attribute
+func (vp *VerificationProcessor) truncateOversized(log plog.LogRecord) {
+	cfg := vp.currentConfig()
+	attrs := log.Attributes()
+
+	if body := log.Body(); body.Type() == pcommon.ValueTypeStr {
+		if truncated, length, ok := truncate(body.Str(), cfg.MaxBodyBytes); ok {
+			body.SetStr(truncated)
+			attrs.PutInt(attrBodyOriginalLength, int64(length))
+		}
+	}
+
+	if statement, exists := attrs.Get(attrStatement); exists && statement.Type() == pcommon.ValueTypeStr {
+		if truncated, length, ok := truncate(statement.Str(), cfg.MaxStatementBytes); ok {
+			statement.SetStr(truncated)
+			attrs.PutInt(attrStatementOriginalLength, int64(length))
+		}
+	}
+}
+
+// truncate returns s cut to at most limit bytes, ending in the truncation
+// marker when it fits, without splitting a UTF-8 sequence. It reports
+// false when s is within the limit or the limit is 0.
+func truncate(s string, limit int) (string, int, bool) {
+	if limit <= 0 || len(s) <= limit {
+		return s, len(s), false
+	}
+
+	marker := truncationMarker
+	if limit <= len(marker) {
+		marker = ""
+	}
+	cut := limit - len(marker)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker, len(s), true
+}
+
 // checkRequiredFields checks for required fields in attributes
 func (vp *VerificationProcessor) checkRequiredFields(attrs pcommon.Map) []string {
 	var missing []string
 	
-	for _, field := range vp.config.QualityRules.RequiredFields {
+	for _, field := range vp.currentConfig().QualityRules.RequiredFields {
 		if _, exists := attrs.Get(field); !exists {
 			missing = append(missing, field)
 		}
@@ -368,14 +695,14 @@ func (vp *VerificationProcessor) checkRequiredFields(attrs pcommon.Map) []string
 	return missing
 }
 
-// detectPII detects potential PII in attributes
+// detectPII detects potential PII in the attributes selected by
+// scan_fields and exclude_fields
 func (vp *VerificationProcessor) detectPII(attrs pcommon.Map) {
+	piiConfig := &vp.currentConfig().PIIDetection
 	attrs.Range(func(key string, value pcommon.Value) bool {
-		// Skip excluded fields
-		for _, exclude := range vp.config.PIIDetection.ExcludeFields {
-			if key == exclude {
-				return true
-			}
+		// Skip fields that are excluded or not selected
+		if !piiConfig.scansField(key) {
+			return true
 		}
 		
 		// Check common PII field names
@@ -389,7 +716,7 @@ func (vp *VerificationProcessor) detectPII(attrs pcommon.Map) {
 					Severity:  8,
 				})
 				
-				if vp.config.PIIDetection.AutoSanitize {
+				if vp.currentConfig().PIIDetection.AutoSanitize {
 					value.SetStr("[REDACTED]")
 				}
 			}
@@ -407,7 +734,7 @@ func (vp *VerificationProcessor) detectPII(attrs pcommon.Map) {
 						Severity:  8,
 					})
 					
-					if vp.config.PIIDetection.AutoSanitize {
+					if vp.currentConfig().PIIDetection.AutoSanitize {
 						value.SetStr(pattern.ReplaceAllString(value.Str(), "[REDACTED]"))
 					}
 				}
@@ -418,10 +745,35 @@ func (vp *VerificationProcessor) detectPII(attrs pcommon.Map) {
 	})
 }
 
+// detectPIIInBody checks a string body against the PII patterns when
+// scan_fields selects the body
+func (vp *VerificationProcessor) detectPIIInBody(body pcommon.Value) {
+	piiConfig := &vp.currentConfig().PIIDetection
+	if !piiConfig.scansBody() || body.Type() != pcommon.ValueTypeStr {
+		return
+	}
+
+	for _, pattern := range vp.piiDetector.patterns {
+		if pattern.MatchString(body.Str()) {
+			vp.sendFeedback(FeedbackEvent{
+				Timestamp: time.Now(),
+				Level:     "WARNING",
+				Category:  "pii_pattern_detected",
+				Message:   "PII pattern detected in body",
+				Severity:  8,
+			})
+
+			if piiConfig.AutoSanitize {
+				body.SetStr(pattern.ReplaceAllString(body.Str(), "[REDACTED]"))
+			}
+		}
+	}
+}
+
 // validateDataQuality validates data types and quality
 func (vp *VerificationProcessor) validateDataQuality(attrs pcommon.Map) {
 	// Check data type validation
-	for field, expectedType := range vp.config.QualityRules.DataTypeValidation {
+	for field, expectedType := range vp.currentConfig().QualityRules.DataTypeValidation {
 		value, exists := attrs.Get(field)
 		if !exists {
 			continue
@@ -464,7 +816,7 @@ func (vp *VerificationProcessor) calculateQualityScore(attrs pcommon.Map) float6
 	score := 1.0
 	
 	// Deduct for missing required fields
-	requiredCount := float64(len(vp.config.QualityRules.RequiredFields))
+	requiredCount := float64(len(vp.currentConfig().QualityRules.RequiredFields))
 	missingCount := float64(len(vp.checkRequiredFields(attrs)))
 	if requiredCount > 0 {
 		score -= (missingCount / requiredCount) * 0.3
@@ -493,7 +845,7 @@ func (vp *VerificationProcessor) checkCardinality(attrs pcommon.Map) {
 	vp.qualityValidator.mu.Lock()
 	defer vp.qualityValidator.mu.Unlock()
 	
-	for field, limit := range vp.config.QualityRules.CardinalityLimits {
+	for field, limit := range vp.currentConfig().QualityRules.CardinalityLimits {
 		value, exists := attrs.Get(field)
 		if !exists {
 			continue
@@ -502,40 +854,46 @@ func (vp *VerificationProcessor) checkCardinality(attrs pcommon.Map) {
 		// Create a unique key for this field-value combination
 		key := fmt.Sprintf("%s:%s", field, value.AsString())
 		
-		// Track unique values
-		if _, seen := vp.qualityValidator.duplicateDetection[key]; !seen {
-			vp.qualityValidator.duplicateDetection[key] = time.Now()
-			
-			// Count unique values for this field
-			count := 0
-			prefix := field + ":"
-			for k := range vp.qualityValidator.duplicateDetection {
-				if strings.HasPrefix(k, prefix) {
-					count++
-				}
+		// Track unique values; a value not seen within the TTL counts as new
+		now := time.Now()
+		if firstSeen, seen := vp.qualityValidator.duplicateDetection.Get(key); seen {
+			if vp.qualityValidator.duplicateTTL <= 0 || now.Sub(firstSeen) < vp.qualityValidator.duplicateTTL {
+				continue
 			}
-			
-			if count > limit {
-				vp.sendFeedback(FeedbackEvent{
-					Timestamp: time.Now(),
-					Level:     "WARNING",
-					Category:  "high_cardinality",
-					Message:   fmt.Sprintf("Field %s exceeds cardinality limit: %d > %d", field, count, limit),
-					Severity:  7,
-				})
+		}
+		
+		// Add evicts the least recently seen value once the cache is full
+		vp.qualityValidator.duplicateDetection.Add(key, now)
+		
+		// Count unique values for this field
+		count := 0
+		prefix := field + ":"
+		for _, k := range vp.qualityValidator.duplicateDetection.Keys() {
+			if strings.HasPrefix(k, prefix) {
+				count++
 			}
 		}
-	}
-	
-	// Clean up old entries periodically
-	now := time.Now()
-	for key, timestamp := range vp.qualityValidator.duplicateDetection {
-		if now.Sub(timestamp) > 24*time.Hour {
-			delete(vp.qualityValidator.duplicateDetection, key)
+		
+		if count > limit {
+			vp.sendFeedback(FeedbackEvent{
+				Timestamp: time.Now(),
+				Level:     "WARNING",
+				Category:  "high_cardinality",
+				Message:   fmt.Sprintf("Field %s exceeds cardinality limit: %d > %d", field, count, limit),
+				Severity:  7,
+			})
 		}
 	}
 }
 
+// expireDuplicates drops duplicate detection entries older than the TTL;
+// the cache size is already bounded by the LRU
+func (vp *VerificationProcessor) expireDuplicates() {
+	vp.qualityValidator.mu.Lock()
+	defer vp.qualityValidator.mu.Unlock()
+	vp.qualityValidator.expireDuplicates(time.Now())
+}
+
 // updateDatabaseMetrics updates metrics for a specific database
 func (vp *VerificationProcessor) updateDatabaseMetrics(database string) {
 	vp.metrics.mu.Lock()
@@ -552,39 +910,46 @@ func (vp *VerificationProcessor) updateDatabaseMetrics(database string) {
 
 // sendFeedback sends a feedback event
 func (vp *VerificationProcessor) sendFeedback(event FeedbackEvent) {
+	if vp.feedbackCoalescer != nil && !vp.feedbackCoalescer.admit(event, time.Now()) {
+		return
+	}
+	
 	select {
 	case vp.feedbackChannel <- event:
 	default:
-		vp.logger.Debug("Feedback channel full, dropping event")
+		dropped := vp.feedbackDropped.Add(1)
+		vp.logger.Debug("Feedback channel full, dropping event",
+			zap.String("category", event.Category),
+			zap.Int64("dropped_total", dropped))
 	}
 }
 
+// FeedbackDropped returns the number of feedback events dropped because the channel was full
+func (vp *VerificationProcessor) FeedbackDropped() int64 {
+	return vp.feedbackDropped.Load()
+}
+
 // processFeedback processes feedback events
 func (vp *VerificationProcessor) processFeedback() {
 	defer vp.wg.Done()
 	
+	// Coalesced summaries are flushed from this goroutine so they never compete
+	// with new events for channel capacity
+	var flushTicks <-chan time.Time
+	if vp.feedbackCoalescer != nil {
+		ticker := time.NewTicker(vp.feedbackCoalescer.window)
+		defer ticker.Stop()
+		flushTicks = ticker.C
+	}
+	
 	for {
 		select {
 		case event := <-vp.feedbackChannel:
-			// Export as log if configured
-			if vp.config.ExportFeedbackAsLogs {
-				vp.exportFeedbackAsLog(event)
-			}
+			vp.handleFeedback(event)
 			
-			// Log locally
-			switch event.Level {
-			case "ERROR":
-				vp.logger.Error(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
-			case "WARNING":
-				vp.logger.Warn(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
-			default:
-				vp.logger.Info(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
+		case now := <-flushTicks:
+			for _, summary := range vp.feedbackCoalescer.flush(now) {
+				vp.handleFeedback(summary)
 			}
 			
 		case <-vp.shutdownChan:
@@ -593,46 +958,27 @@ func (vp *VerificationProcessor) processFeedback() {
 	}
 }
 
-// exportFeedbackAsLog exports feedback event as telemetry
-func (vp *VerificationProcessor) exportFeedbackAsLog(event FeedbackEvent) {
-	// Create a new log record
-	logs := plog.NewLogs()
-	rl := logs.ResourceLogs().AppendEmpty()
-	
-	// Set resource attributes
-	resource := rl.Resource()
-	resource.Attributes().PutStr("service.name", "database-intelligence-verification")
-	resource.Attributes().PutStr("service.version", "2.0.0")
-	
-	// Create log record
-	sl := rl.ScopeLogs().AppendEmpty()
-	logRecord := sl.LogRecords().AppendEmpty()
-	
-	// Set log attributes
-	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(event.Timestamp))
-	logRecord.SetSeverityNumber(plog.SeverityNumber(event.Severity))
-	logRecord.SetSeverityText(event.Level)
-	
-	// Set body
-	body, _ := json.Marshal(event)
-	logRecord.Body().SetStr(string(body))
-	
-	// Set attributes
-	attrs := logRecord.Attributes()
-	attrs.PutStr("feedback.category", event.Category)
-	attrs.PutStr("feedback.level", event.Level)
-	if event.Database != "" {
-		attrs.PutStr("database.name", event.Database)
+// handleFeedback logs a feedback event and queues it for export
+func (vp *VerificationProcessor) handleFeedback(event FeedbackEvent) {
+	// Export as log if configured
+	if vp.currentConfig().ExportFeedbackAsLogs {
+		vp.feedbackExport.enqueue(event)
 	}
 	
-	// Send to export
-	// TODO: Consider storing a context in the processor for proper cancellation propagation
-	// Using Background context is acceptable here as this is an async feedback mechanism
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	if err := vp.nextConsumer.ConsumeLogs(ctx, logs); err != nil {
-		vp.logger.Error("Failed to export feedback as log", zap.Error(err))
+	// Log locally
+	switch event.Level {
+	case "ERROR":
+		vp.logger.Error(event.Message,
+			zap.String("category", event.Category),
+			zap.Int("severity", event.Severity))
+	case "WARNING":
+		vp.logger.Warn(event.Message,
+			zap.String("category", event.Category),
+			zap.Int("severity", event.Severity))
+	default:
+		vp.logger.Info(event.Message,
+			zap.String("category", event.Category),
+			zap.Int("severity", event.Severity))
 	}
 }
 
@@ -640,7 +986,7 @@ func (vp *VerificationProcessor) exportFeedbackAsLog(event FeedbackEvent) {
 func (vp *VerificationProcessor) periodicVerification() {
 	defer vp.wg.Done()
 	
-	ticker := time.NewTicker(vp.config.VerificationInterval)
+	ticker := time.NewTicker(vp.currentConfig().VerificationInterval)
 	defer ticker.Stop()
 	
 	for {
@@ -659,7 +1005,7 @@ func (vp *VerificationProcessor) performVerification() {
 	defer vp.metrics.mu.RUnlock()
 	
 	// Check data freshness
-	if time.Since(vp.metrics.lastDataTimestamp) > vp.config.DataFreshnessThreshold {
+	if time.Since(vp.metrics.lastDataTimestamp) > vp.currentConfig().DataFreshnessThreshold {
 		vp.sendFeedback(FeedbackEvent{
 			Timestamp: time.Now(),
 			Level:     "WARNING",
@@ -670,25 +1016,25 @@ func (vp *VerificationProcessor) performVerification() {
 	}
 	
 	// Check entity correlation rate
-	if vp.metrics.entityCorrelationRate < vp.config.MinEntityCorrelationRate {
+	if vp.metrics.entityCorrelationRate < vp.currentConfig().MinEntityCorrelationRate {
 		vp.sendFeedback(FeedbackEvent{
 			Timestamp: time.Now(),
 			Level:     "WARNING",
 			Category:  "entity_correlation",
 			Message:   fmt.Sprintf("Entity correlation rate below threshold: %.2f%% < %.2f%%",
-				vp.metrics.entityCorrelationRate*100, vp.config.MinEntityCorrelationRate*100),
+				vp.metrics.entityCorrelationRate*100, vp.currentConfig().MinEntityCorrelationRate*100),
 			Severity:  6,
 		})
 	}
 	
 	// Check normalization rate
-	if vp.metrics.queryNormalizationRate < vp.config.MinNormalizationRate {
+	if vp.metrics.queryNormalizationRate < vp.currentConfig().MinNormalizationRate {
 		vp.sendFeedback(FeedbackEvent{
 			Timestamp: time.Now(),
 			Level:     "WARNING",
 			Category:  "query_normalization",
 			Message:   fmt.Sprintf("Query normalization rate below threshold: %.2f%% < %.2f%%",
-				vp.metrics.queryNormalizationRate*100, vp.config.MinNormalizationRate*100),
+				vp.metrics.queryNormalizationRate*100, vp.currentConfig().MinNormalizationRate*100),
 			Severity:  6,
 		})
 	}
@@ -698,7 +1044,7 @@ func (vp *VerificationProcessor) performVerification() {
 func (vp *VerificationProcessor) continuousHealthChecks() {
 	defer vp.wg.Done()
 	
-	ticker := time.NewTicker(vp.config.HealthCheckInterval)
+	ticker := time.NewTicker(vp.currentConfig().HealthCheckInterval)
 	defer ticker.Stop()
 	
 	for {
@@ -716,9 +1062,13 @@ func (vp *VerificationProcessor) performHealthCheck() {
 	// Update system metrics
 	vp.updateSystemMetrics()
 	
+	vp.healthChecker.mu.RLock()
+	thresholds := vp.healthChecker.alertThresholds
+	vp.healthChecker.mu.RUnlock()
+
 	// Check memory usage
 	memUsage := vp.resourceMonitor.memoryUsage
-	if memUsage > vp.healthChecker.alertThresholds.MemoryPercent {
+	if memUsage > thresholds.MemoryPercent {
 		vp.sendFeedback(FeedbackEvent{
 			Timestamp: time.Now(),
 			Level:     "WARNING",
@@ -729,7 +1079,7 @@ func (vp *VerificationProcessor) performHealthCheck() {
 		// Log high memory usage - self-healing removed
 	}
 	
-	if cpuUsage := vp.resourceMonitor.cpuUsage; cpuUsage > vp.healthChecker.alertThresholds.CPUPercent {
+	if cpuUsage := vp.resourceMonitor.cpuUsage; cpuUsage > thresholds.CPUPercent {
 		vp.sendFeedback(FeedbackEvent{
 			Timestamp: time.Now(),
 			Level:     "WARNING",
@@ -738,27 +1088,50 @@ func (vp *VerificationProcessor) performHealthCheck() {
 			Severity:  7,
 		})
 	}
+
+	if diskUsage := vp.resourceMonitor.diskUsage; diskUsage > thresholds.DiskPercent {
+		vp.sendFeedback(FeedbackEvent{
+			Timestamp:   time.Now(),
+			Level:       "WARNING",
+			Category:    "high_disk_usage",
+			Message:     fmt.Sprintf("Disk usage above threshold: %.2f%%", diskUsage),
+			Remediation: "Free space on the data directory or reduce retained telemetry",
+			Severity:    7,
+		})
+	}
 }
 
 // updateSystemMetrics updates system resource metrics
 func (vp *VerificationProcessor) updateSystemMetrics() {
+	vp.healthChecker.mu.RLock()
+	source := vp.healthChecker.resourceSource
+	vp.healthChecker.mu.RUnlock()
+
+	memoryUsage := vp.readResource("memory", source.MemoryPercent)
+	cpuUsage := vp.readResource("cpu", source.CPUPercent)
+	diskUsage := vp.readResource("disk", source.DiskPercent)
+
 	vp.resourceMonitor.mu.Lock()
 	defer vp.resourceMonitor.mu.Unlock()
 	
-	// Get memory stats
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	
-	// Update memory usage percentage (simplified)
-	vp.resourceMonitor.memoryUsage = float64(m.Alloc) / float64(m.Sys) * 100
-	
-	// CPU usage would require OS-specific implementation
-	// For now, using a placeholder
-	vp.resourceMonitor.cpuUsage = 0
-	
+	vp.resourceMonitor.memoryUsage = memoryUsage
+	vp.resourceMonitor.cpuUsage = cpuUsage
+	vp.resourceMonitor.diskUsage = diskUsage
 	vp.resourceMonitor.lastUpdate = time.Now()
 }
 
+// readResource reads a single measurement, treating unavailable readings as zero
+func (vp *VerificationProcessor) readResource(name string, read func() (float64, error)) float64 {
+	value, err := read()
+	if err != nil {
+		vp.logger.Debug("Resource measurement unavailable",
+			zap.String("resource", name),
+			zap.Error(err))
+		return 0.0
+	}
+	return value
+}
+
 // resourceMonitoring monitors resource usage
 func (vp *VerificationProcessor) resourceMonitoring() {
 	defer vp.wg.Done()
@@ -773,6 +1146,7 @@ func (vp *VerificationProcessor) resourceMonitoring() {
 			
 			// Calculate and update performance metrics
 			vp.updatePerformanceMetrics()
+			vp.expireDuplicates()
 			
 		case <-vp.shutdownChan:
 			return
@@ -804,12 +1178,18 @@ func (vp *VerificationProcessor) collectPerformanceSnapshot() PerformanceSnapsho
 	
 	elapsed := time.Since(vp.performanceTracker.startTime).Seconds()
 	throughput := float64(vp.performanceTracker.recordsProcessed) / elapsed
+	// The latency is measured per ConsumeLogs call, so it is averaged per
+	// batch; dividing by records would understate it for large batches.
+	// StageTimings has the per-record cost of each stage.
 	avgLatency := time.Duration(0)
-	if vp.performanceTracker.recordsProcessed > 0 {
-		avgLatency = vp.performanceTracker.totalLatency / time.Duration(vp.performanceTracker.recordsProcessed)
+	if vp.performanceTracker.batches > 0 {
+		avgLatency = vp.performanceTracker.totalLatency / time.Duration(vp.performanceTracker.batches)
 	}
 	
-	errorRate := float64(vp.performanceTracker.errorCount) / float64(vp.performanceTracker.recordsProcessed)
+	errorRate := 0.0
+	if vp.performanceTracker.recordsProcessed > 0 {
+		errorRate = float64(vp.performanceTracker.errorCount) / float64(vp.performanceTracker.recordsProcessed)
+	}
 	
 	return PerformanceSnapshot{
 		Timestamp:          time.Now(),
@@ -5,7 +5,6 @@ package verification
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"runtime"
 	"strings"
@@ -54,8 +53,22 @@ func NewConcurrentVerificationProcessor(
 	}, nil
 }
 
-// Start starts the concurrent processor
+// Start starts the concurrent processor. Calling it again has no effect.
 func (cvp *ConcurrentVerificationProcessor) Start(ctx context.Context, host component.Host) error {
+	if cvp.isShutDown() {
+		return errShutDown
+	}
+
+	var err error
+	cvp.startOnce.Do(func() {
+		err = cvp.start(ctx, host)
+	})
+	return err
+}
+
+// start initializes the base concurrent processor, the worker pools and the
+// background tasks
+func (cvp *ConcurrentVerificationProcessor) start(ctx context.Context, host component.Host) error {
 	// Initialize base concurrent processor
 	if err := cvp.ConcurrentProcessor.Start(ctx, host); err != nil {
 		return err
@@ -65,7 +78,7 @@ func (cvp *ConcurrentVerificationProcessor) Start(ctx context.Context, host comp
 	cvp.verificationWorkerPool = cvp.NewWorkerPool(runtime.NumCPU())
 	cvp.verificationWorkerPool.Start()
 
-	if cvp.config.PIIDetection.Enabled {
+	if cvp.currentConfig().PIIDetection.Enabled {
 		cvp.piiDetectionWorkerPool = cvp.NewWorkerPool(4) // 4 workers for PII detection
 		cvp.piiDetectionWorkerPool.Start()
 	}
@@ -74,42 +87,55 @@ func (cvp *ConcurrentVerificationProcessor) Start(ctx context.Context, host comp
 	cvp.StartBackgroundTask("feedback-processor", 100*time.Millisecond, cvp.processFeedbackWithContext)
 
 	// Start periodic verification if enabled
-	if cvp.config.EnablePeriodicVerification {
-		cvp.StartBackgroundTask("periodic-verification", cvp.config.VerificationInterval, cvp.performVerificationWithContext)
+	if cvp.currentConfig().EnablePeriodicVerification {
+		cvp.StartBackgroundTask("periodic-verification", cvp.currentConfig().VerificationInterval, cvp.performVerificationWithContext)
 	}
 
 	// Start continuous health checks if enabled
-	if cvp.config.EnableContinuousHealthChecks {
-		cvp.StartBackgroundTask("health-checks", cvp.config.HealthCheckInterval, cvp.performHealthCheckWithContext)
+	if cvp.currentConfig().EnableContinuousHealthChecks {
+		cvp.StartBackgroundTask("health-checks", cvp.currentConfig().HealthCheckInterval, cvp.performHealthCheckWithContext)
 	}
 
 	// Start resource monitoring
 	cvp.StartBackgroundTask("resource-monitoring", 30*time.Second, cvp.updateSystemMetricsWithContext)
 
+	cvp.registerStatsProvider(host)
+
+	if err := cvp.startHealthServer(); err != nil {
+		return err
+	}
+
 	cvp.logger.Info("Started concurrent verification processor",
 		zap.Int("verification_workers", runtime.NumCPU()),
-		zap.Bool("pii_detection", cvp.config.PIIDetection.Enabled))
+		zap.Bool("pii_detection", cvp.currentConfig().PIIDetection.Enabled))
 
 	return nil
 }
 
-// Shutdown stops the concurrent processor
+// Shutdown stops the concurrent processor. It is safe to call more than once
+// and before Start.
 func (cvp *ConcurrentVerificationProcessor) Shutdown(ctx context.Context) error {
-	cvp.logger.Info("Shutting down concurrent verification processor")
+	cvp.shutdownOnce.Do(func() {
+		cvp.logger.Info("Shutting down concurrent verification processor")
 
-	// Stop worker pools
-	if cvp.verificationWorkerPool != nil {
-		cvp.verificationWorkerPool.Stop()
-	}
-	if cvp.piiDetectionWorkerPool != nil {
-		cvp.piiDetectionWorkerPool.Stop()
-	}
+		// Stop worker pools
+		if cvp.verificationWorkerPool != nil {
+			cvp.verificationWorkerPool.Stop()
+		}
+		if cvp.piiDetectionWorkerPool != nil {
+			cvp.piiDetectionWorkerPool.Stop()
+		}
 
-	// Close feedback channel
-	close(cvp.feedbackChannel)
+		// Stop the feedback loop, flushing the feedback export
+		cvp.signalShutdown(ctx)
+	})
+	err := cvp.waitForWorkers(ctx)
 
 	// Shutdown base concurrent processor
-	return cvp.ConcurrentProcessor.Shutdown(ctx)
+	if cerr := cvp.ConcurrentProcessor.Shutdown(ctx); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 // ConsumeLogs processes logs concurrently
@@ -139,14 +165,22 @@ func (cvp *ConcurrentVerificationProcessor) ConsumeLogs(ctx context.Context, ld
 		cvp.logger.Warn("Error processing resource logs", zap.Error(err))
 	}
 
-	// Forward to next consumer with timeout
-	err := cvp.ExecuteWithContext(5*time.Second, func(ctx context.Context) error {
+	// Forward to next consumer with timeout; consumer_timeout overrides the
+	// default when it is set
+	timeout := 5 * time.Second
+	if configured := cvp.liveParams.ConsumerTimeout(); configured > 0 {
+		timeout = configured
+	}
+	err := cvp.ExecuteWithContext(timeout, func(ctx context.Context) error {
 		return cvp.nextConsumer.ConsumeLogs(ctx, ld)
 	})
 
 	// Track performance
 	cvp.performanceTracker.mu.Lock()
+	cvp.performanceTracker.recordsProcessed += int64(ld.LogRecordCount())
+	cvp.performanceTracker.lastRecordTime = startTime
 	cvp.performanceTracker.totalLatency += time.Since(startTime)
+	cvp.performanceTracker.batches++
 	if err != nil {
 		cvp.performanceTracker.errorCount++
 	}
@@ -171,6 +205,11 @@ func (cvp *ConcurrentVerificationProcessor) processResourceLogs(ctx context.Cont
 		// Submit log verification tasks to worker pool
 		for k := 0; k < logs.Len(); k++ {
 			logIndex := k
+			
+			// Entity attributes are filled in here rather than on the pool
+			// so they are set before the records are forwarded
+			cvp.synthesizeEntity(resource.Attributes(), logs.At(k).Attributes())
+			
 			cvp.concurrentMetrics.verificationTasks.Add(1)
 			
 			err := cvp.verificationWorkerPool.Submit(func() {
@@ -195,8 +234,10 @@ func (cvp *ConcurrentVerificationProcessor) processResourceLogs(ctx context.Cont
 // verifyLogRecordConcurrent verifies a log record with concurrent PII detection
 func (cvp *ConcurrentVerificationProcessor) verifyLogRecordConcurrent(log plog.LogRecord) error {
 	attrs := log.Attributes()
+	cvp.truncateOversized(log)
 	
 	// Check required fields
+	start := time.Now()
 	missing := cvp.checkRequiredFields(attrs)
 	if len(missing) > 0 {
 		cvp.sendFeedback(FeedbackEvent{
@@ -207,9 +248,12 @@ func (cvp *ConcurrentVerificationProcessor) verifyLogRecordConcurrent(log plog.L
 			Severity:  6,
 		})
 	}
+	cvp.performanceTracker.observeStage(stageSchemaValidation, start)
 	
-	// Submit PII detection to separate worker pool if enabled
-	if cvp.config.PIIDetection.Enabled && cvp.piiDetectionWorkerPool != nil {
+	// Submit PII detection to separate worker pool if enabled. The time
+	// spent in the pool counts toward the PII stage as well.
+	if cvp.currentConfig().PIIDetection.Enabled && cvp.piiDetectionWorkerPool != nil {
+		start = time.Now()
 		cvp.concurrentMetrics.piiChecksQueued.Add(1)
 		
 		// Create a copy of attributes for async PII detection
@@ -217,7 +261,9 @@ func (cvp *ConcurrentVerificationProcessor) verifyLogRecordConcurrent(log plog.L
 		attrs.CopyTo(attrsCopy)
 		
 		err := cvp.piiDetectionWorkerPool.Submit(func() {
+			asyncStart := time.Now()
 			cvp.detectPIIAsync(attrsCopy, attrs)
+			cvp.performanceTracker.observeStage(stagePIIScan, asyncStart)
 			cvp.concurrentMetrics.piiChecksComplete.Add(1)
 		})
 		
@@ -225,25 +271,32 @@ func (cvp *ConcurrentVerificationProcessor) verifyLogRecordConcurrent(log plog.L
 			// Fall back to synchronous PII detection if worker pool is full
 			cvp.detectPII(attrs)
 		}
+		// Only the attributes are copied for the pool; the body is
+		// checked here
+		cvp.detectPIIInBody(log.Body())
+		cvp.performanceTracker.observeStage(stagePIIScan, start)
 	}
 	
-	// Validate data quality
-	cvp.validateDataQuality(attrs)
-	
-	// Check cardinality
-	cvp.checkCardinality(attrs)
+	// Validate data quality, sampled when auto-tuning has reduced the rate
+	if cvp.sampleQuality() {
+		start = time.Now()
+		cvp.validateDataQuality(attrs)
+		
+		// Check cardinality
+		cvp.checkCardinality(attrs)
+		cvp.performanceTracker.observeStage(stageQualityValidation, start)
+	}
 	
 	return nil
 }
 
 // detectPIIAsync performs PII detection asynchronously
 func (cvp *ConcurrentVerificationProcessor) detectPIIAsync(attrsCopy pcommon.Map, originalAttrs pcommon.Map) {
+	piiConfig := &cvp.currentConfig().PIIDetection
 	attrsCopy.Range(func(key string, value pcommon.Value) bool {
-		// Skip excluded fields
-		for _, exclude := range cvp.config.PIIDetection.ExcludeFields {
-			if key == exclude {
-				return true
-			}
+		// Skip fields that are excluded or not selected
+		if !piiConfig.scansField(key) {
+			return true
 		}
 		
 		// Check common PII field names
@@ -257,7 +310,7 @@ func (cvp *ConcurrentVerificationProcessor) detectPIIAsync(attrsCopy pcommon.Map
 					Severity:  8,
 				})
 				
-				if cvp.config.PIIDetection.AutoSanitize {
+				if cvp.currentConfig().PIIDetection.AutoSanitize {
 					// Sanitize in the original attributes
 					originalAttrs.PutStr(key, "[REDACTED]")
 				}
@@ -276,7 +329,7 @@ func (cvp *ConcurrentVerificationProcessor) detectPIIAsync(attrsCopy pcommon.Map
 						Severity:  8,
 					})
 					
-					if cvp.config.PIIDetection.AutoSanitize {
+					if cvp.currentConfig().PIIDetection.AutoSanitize {
 						// Sanitize in the original attributes
 						originalAttrs.PutStr(key, pattern.ReplaceAllString(value.Str(), "[REDACTED]"))
 					}
@@ -294,27 +347,7 @@ func (cvp *ConcurrentVerificationProcessor) processFeedbackWithContext(ctx conte
 	for {
 		select {
 		case event := <-cvp.feedbackChannel:
-			// Export as log if configured
-			if cvp.config.ExportFeedbackAsLogs {
-				cvp.exportFeedbackAsLogWithContext(ctx, event)
-			}
-			
-			// Log locally
-			switch event.Level {
-			case "ERROR":
-				cvp.logger.Error(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
-			case "WARNING":
-				cvp.logger.Warn(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
-			default:
-				cvp.logger.Info(event.Message,
-					zap.String("category", event.Category),
-					zap.Int("severity", event.Severity))
-			}
-			
+			cvp.handleFeedback(event)
 			processed++
 			
 		case <-ctx.Done():
@@ -333,44 +366,6 @@ func (cvp *ConcurrentVerificationProcessor) processFeedbackWithContext(ctx conte
 	}
 }
 
-// exportFeedbackAsLogWithContext exports feedback with proper context
-func (cvp *ConcurrentVerificationProcessor) exportFeedbackAsLogWithContext(ctx context.Context, event FeedbackEvent) {
-	// Create a new log record
-	logs := plog.NewLogs()
-	rl := logs.ResourceLogs().AppendEmpty()
-	
-	// Set resource attributes
-	resource := rl.Resource()
-	resource.Attributes().PutStr("service.name", "database-intelligence-verification")
-	resource.Attributes().PutStr("service.version", "2.0.0")
-	
-	// Create log record
-	sl := rl.ScopeLogs().AppendEmpty()
-	logRecord := sl.LogRecords().AppendEmpty()
-	
-	// Set log attributes
-	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(event.Timestamp))
-	logRecord.SetSeverityNumber(plog.SeverityNumber(event.Severity))
-	logRecord.SetSeverityText(event.Level)
-	
-	// Set body
-	body, _ := json.Marshal(event)
-	logRecord.Body().SetStr(string(body))
-	
-	// Set attributes
-	attrs := logRecord.Attributes()
-	attrs.PutStr("feedback.category", event.Category)
-	attrs.PutStr("feedback.level", event.Level)
-	if event.Database != "" {
-		attrs.PutStr("database.name", event.Database)
-	}
-	
-	// Send to export with the provided context
-	if err := cvp.nextConsumer.ConsumeLogs(ctx, logs); err != nil {
-		cvp.logger.Error("Failed to export feedback as log", zap.Error(err))
-	}
-}
-
 // performVerificationWithContext performs verification with proper context
 func (cvp *ConcurrentVerificationProcessor) performVerificationWithContext(ctx context.Context) error {
 	cvp.performVerification()
@@ -387,6 +382,7 @@ func (cvp *ConcurrentVerificationProcessor) performHealthCheckWithContext(ctx co
 func (cvp *ConcurrentVerificationProcessor) updateSystemMetricsWithContext(ctx context.Context) error {
 	cvp.updateSystemMetrics()
 	cvp.updatePerformanceMetrics()
+	cvp.expireDuplicates()
 	
 	// Log concurrent processing metrics
 	cvp.logger.Debug("Concurrent processing metrics",
@@ -2,12 +2,25 @@ package verification
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/database-intelligence/db-intel/components/processors/base"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
 	"go.uber.org/zap"
 )
 
@@ -170,4 +183,961 @@ func TestVerificationProcessor_CardinalityProtection(t *testing.T) {
 	// The processor should have logged warnings about high cardinality
 	// In a real implementation, you might check internal metrics or state
 	assert.True(t, true, "Cardinality protection should be active")
-}
\ No newline at end of file
+}
+
+// statsHost exposes a single extension that collects stats providers
+type statsHost struct {
+	component.Host
+	ext *statsCollectingExtension
+}
+
+func (h *statsHost) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		component.MustNewID("healthcheck"): h.ext,
+	}
+}
+
+// statsCollectingExtension has the healthcheck extension's registration signature
+type statsCollectingExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	providers map[string]interface {
+		RecordCounts() map[string]int64
+		LastDataTimestamps() map[string]time.Time
+		DroppedRecords() int64
+	}
+}
+
+func (e *statsCollectingExtension) RegisterStatsProvider(name string, provider interface {
+	RecordCounts() map[string]int64
+	LastDataTimestamps() map[string]time.Time
+	DroppedRecords() int64
+}) {
+	e.providers[name] = provider
+}
+
+func TestVerificationProcessor_RegistersStatsProvider(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	
+	ext := &statsCollectingExtension{providers: map[string]interface {
+		RecordCounts() map[string]int64
+		LastDataTimestamps() map[string]time.Time
+		DroppedRecords() int64
+	}{}}
+	require.NoError(t, processor.Start(context.Background(), &statsHost{ext: ext}))
+	defer processor.Shutdown(context.Background())
+	
+	provider, ok := ext.providers["verification"]
+	require.True(t, ok)
+	assert.Empty(t, provider.LastDataTimestamps())
+	
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+	
+	assert.Equal(t, map[string]int64{"logs": 1}, provider.RecordCounts())
+	assert.Contains(t, provider.LastDataTimestamps(), "logs")
+	assert.Equal(t, int64(0), provider.DroppedRecords())
+}
+
+func TestVerificationProcessor_RegistersStatsUnderComponentID(t *testing.T) {
+	ext := &statsCollectingExtension{providers: map[string]interface {
+		RecordCounts() map[string]int64
+		LastDataTimestamps() map[string]time.Time
+		DroppedRecords() int64
+	}{}}
+	
+	for _, name := range []string{"primary", "replica"} {
+		set := processortest.NewNopSettings()
+		set.ID = component.NewIDWithName(componentType, name)
+		lp, err := NewFactory().CreateLogsProcessor(context.Background(), set, createDefaultConfig(), consumertest.NewNop())
+		require.NoError(t, err)
+		require.NoError(t, lp.Start(context.Background(), &statsHost{ext: ext}))
+		defer lp.Shutdown(context.Background())
+	}
+	
+	assert.Contains(t, ext.providers, "verification/primary")
+	assert.Contains(t, ext.providers, "verification/replica")
+	assert.NotContains(t, ext.providers, "verification")
+}
+
+func TestVerificationProcessor_Reconfigure(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	attrs := plog.NewLogRecord().Attributes()
+	attrs.PutStr("database_name", "orders")
+	assert.Equal(t, []string{"query_id", "duration_ms"}, processor.checkRequiredFields(attrs))
+
+	updated := createDefaultConfig().(*Config)
+	updated.QualityRules.RequiredFields = []string{"database_name"}
+	updated.HealthThresholds.MemoryPercent = 95
+	require.NoError(t, updated.Validate())
+	require.NoError(t, processor.Reconfigure(updated))
+
+	assert.Empty(t, processor.checkRequiredFields(attrs))
+	assert.Equal(t, 95.0, processor.healthChecker.alertThresholds.MemoryPercent)
+
+	// The periodic verification loop is already running at its interval
+	restart := createDefaultConfig().(*Config)
+	restart.VerificationInterval = time.Minute
+	assert.ErrorIs(t, processor.Reconfigure(restart), base.ErrRestartRequired)
+	assert.Same(t, updated, processor.currentConfig())
+}
+
+// piiRecord is a log record with PII in free text, id and numeric fields
+func piiRecord() plog.LogRecord {
+	lr := plog.NewLogRecord()
+	lr.Body().SetStr("slow query from john@example.com")
+	lr.Attributes().PutStr("db.statement", "SELECT * FROM users WHERE email='john@example.com'")
+	lr.Attributes().PutStr("session_id", "555-123-4567")
+	lr.Attributes().PutStr("user_email", "jane@example.com")
+	lr.Attributes().PutInt("duration_ms", 1234)
+	return lr
+}
+
+// drainPIIFeedback empties the feedback queue and returns the messages of
+// the PII events in it
+func drainPIIFeedback(vp *VerificationProcessor) []string {
+	var messages []string
+	for {
+		select {
+		case event := <-vp.feedbackChannel:
+			if strings.HasPrefix(event.Category, "pii_") {
+				messages = append(messages, event.Message)
+			}
+		default:
+			return messages
+		}
+	}
+}
+
+func TestVerificationProcessor_PIIScanFields(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.PIIDetection.AutoSanitize = true
+	cfg.PIIDetection.ScanFields = []string{"db.statement", "body"}
+	require.NoError(t, cfg.Validate())
+
+	vp, err := newVerificationProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	lr := piiRecord()
+	require.NoError(t, vp.verifyLogRecord(lr))
+
+	// Scanned fields are still redacted
+	statement, _ := lr.Attributes().Get("db.statement")
+	assert.Equal(t, "SELECT * FROM users WHERE email='[REDACTED]'", statement.Str())
+	assert.Equal(t, "slow query from [REDACTED]", lr.Body().Str())
+
+	// Other fields are not scanned, by value or by name
+	sessionID, _ := lr.Attributes().Get("session_id")
+	assert.Equal(t, "555-123-4567", sessionID.Str())
+	email, _ := lr.Attributes().Get("user_email")
+	assert.Equal(t, "jane@example.com", email.Str())
+
+	assert.ElementsMatch(t, []string{
+		"PII pattern detected in field: db.statement",
+		"PII pattern detected in body",
+	}, drainPIIFeedback(vp))
+}
+
+func TestVerificationProcessor_PIIScansAllAttributesByDefault(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.PIIDetection.AutoSanitize = true
+	cfg.PIIDetection.ExcludeFields = []string{"session_id"}
+
+	vp, err := newVerificationProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	lr := piiRecord()
+	require.NoError(t, vp.verifyLogRecord(lr))
+
+	statement, _ := lr.Attributes().Get("db.statement")
+	assert.Equal(t, "SELECT * FROM users WHERE email='[REDACTED]'", statement.Str())
+	email, _ := lr.Attributes().Get("user_email")
+	assert.Equal(t, "[REDACTED]", email.Str())
+
+	// Excluded fields and the body are not scanned
+	sessionID, _ := lr.Attributes().Get("session_id")
+	assert.Equal(t, "555-123-4567", sessionID.Str())
+	assert.Equal(t, "slow query from john@example.com", lr.Body().Str())
+	for _, message := range drainPIIFeedback(vp) {
+		assert.NotContains(t, message, "session_id")
+		assert.NotContains(t, message, "body")
+	}
+}
+
+func TestVerificationProcessor_StageTimings(t *testing.T) {
+	for _, piiEnabled := range []bool{true, false} {
+		cfg := createDefaultConfig().(*Config)
+		cfg.PIIDetection.Enabled = piiEnabled
+
+		vp, err := newVerificationProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+		require.NoError(t, err)
+
+		logs := plog.NewLogs()
+		piiRecord().CopyTo(logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+		require.NoError(t, vp.ConsumeLogs(context.Background(), logs))
+
+		timings := vp.StageTimings()
+		assert.Len(t, timings, int(stageCount))
+		assert.Positive(t, timings["schema_validation"])
+		assert.Positive(t, timings["quality_validation"])
+		if piiEnabled {
+			assert.Positive(t, timings["pii_scan"])
+		} else {
+			// A disabled stage costs nothing
+			assert.Zero(t, timings["pii_scan"])
+		}
+	}
+}
+
+func TestPIIDetectionConfig_ScanFields(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.PIIDetection.ScanFields = []string{"db.statement", ""}
+	assert.EqualError(t, cfg.Validate(), "pii_detection.scan_fields cannot contain an empty field")
+
+	pii := PIIDetectionConfig{ScanFields: []string{"db.statement", "body"}, ExcludeFields: []string{"body"}}
+	assert.True(t, pii.scansField("db.statement"))
+	assert.False(t, pii.scansField("duration_ms"))
+	assert.False(t, pii.scansBody(), "exclude_fields wins over scan_fields")
+}
+
+func TestVerificationProcessor_TruncatesOversizedBody(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxBodyBytes = 4096
+	cfg.MaxStatementBytes = 1024
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	oversized := records.AppendEmpty()
+	body := strings.Repeat("x", 1<<20)
+	oversized.Body().SetStr(body)
+	query := "SELECT * FROM orders WHERE id IN (" + strings.Repeat("1, ", 100000) + "1)"
+	oversized.Attributes().PutStr("db.statement", query)
+	small := records.AppendEmpty()
+	small.Body().SetStr("checkpoint complete")
+
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	truncated := got.At(0)
+	assert.Len(t, truncated.Body().Str(), 4096)
+	assert.True(t, strings.HasSuffix(truncated.Body().Str(), truncationMarker))
+	length, ok := truncated.Attributes().Get("log.body.original_length")
+	require.True(t, ok)
+	assert.Equal(t, int64(len(body)), length.Int())
+
+	statement, _ := truncated.Attributes().Get("db.statement")
+	assert.Len(t, statement.Str(), 1024)
+	assert.True(t, strings.HasPrefix(statement.Str(), "SELECT * FROM orders WHERE id IN (1, 1, "))
+	length, ok = truncated.Attributes().Get("db.statement.original_length")
+	require.True(t, ok)
+	assert.Equal(t, int64(len(query)), length.Int())
+
+	assert.Equal(t, "checkpoint complete", got.At(1).Body().Str())
+	_, ok = got.At(1).Attributes().Get("log.body.original_length")
+	assert.False(t, ok, "records within the limit are left alone")
+}
+
+func TestVerificationProcessor_DefaultConfigDoesNotTruncate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Zero(t, cfg.MaxBodyBytes)
+	assert.Zero(t, cfg.MaxStatementBytes)
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	logs := plog.NewLogs()
+	record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	body := strings.Repeat("x", 1<<20)
+	record.Body().SetStr(body)
+	query := "SELECT * FROM orders WHERE id IN (" + strings.Repeat("1, ", 100000) + "1)"
+	record.Attributes().PutStr("db.statement", query)
+
+	require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, body, got.Body().Str())
+	statement, _ := got.Attributes().Get("db.statement")
+	assert.Equal(t, query, statement.Str())
+	_, ok := got.Attributes().Get("log.body.original_length")
+	assert.False(t, ok)
+	_, ok = got.Attributes().Get("db.statement.original_length")
+	assert.False(t, ok)
+}
+
+func TestTruncate(t *testing.T) {
+	// A multi-byte rune straddling the cut is dropped whole
+	s, length, ok := truncate("héllo wörld, "+strings.Repeat("ü", 20), 16)
+	require.True(t, ok)
+	assert.Equal(t, "h"+truncationMarker, s)
+	assert.True(t, utf8.ValidString(s))
+	assert.Equal(t, 55, length)
+
+	s, _, ok = truncate("abcdefgh", 4)
+	require.True(t, ok)
+	assert.Equal(t, "abcd", s, "a limit shorter than the marker cuts without it")
+
+	_, _, ok = truncate(strings.Repeat("x", 100), 0)
+	assert.False(t, ok, "0 disables truncation")
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxBodyBytes = -1
+	assert.EqualError(t, cfg.Validate(), "max_body_bytes cannot be negative")
+}
+
+func BenchmarkVerificationProcessor_DetectPII(b *testing.B) {
+	benchmarks := []struct {
+		name       string
+		scanFields []string
+	}{
+		{name: "all_attributes"},
+		{name: "scan_fields", scanFields: []string{"db.statement", "body"}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.PIIDetection.ScanFields = bm.scanFields
+			vp, err := newVerificationProcessor(zap.NewNop(), cfg, consumertest.NewNop())
+			require.NoError(b, err)
+
+			lr := plog.NewLogRecord()
+			lr.Body().SetStr("duration: 12.5 ms statement: SELECT * FROM orders WHERE customer_id = $1")
+			lr.Attributes().PutStr("db.statement", "SELECT * FROM orders WHERE customer_id = $1 AND status = $2")
+			for _, key := range []string{"db.name", "db.system", "query_id", "plan_hash", "server.address", "db.operation", "db.sql.table"} {
+				lr.Attributes().PutStr(key, "orders_primary_7f3a9c2e")
+			}
+			for _, key := range []string{"duration_ms", "rows", "calls", "shared_blks_hit", "shared_blks_read"} {
+				lr.Attributes().PutDouble(key, 1234.5)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vp.detectPII(lr.Attributes())
+				vp.detectPIIInBody(lr.Body())
+				drainPIIFeedback(vp)
+			}
+		})
+	}
+}
+
+// blockingLogsConsumer blocks every ConsumeLogs call until released
+type blockingLogsConsumer struct {
+	consumertest.LogsSink
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingLogsConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	select {
+	case c.entered <- struct{}{}:
+	default:
+	}
+	<-c.release
+	return c.LogsSink.ConsumeLogs(ctx, ld)
+}
+
+func TestVerificationProcessor_SlowFeedbackExportDoesNotBlockFeedback(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackExport = FeedbackExportConfig{MaxBatchSize: 10, FlushInterval: time.Hour, QueueSize: 20, Timeout: time.Hour}
+	require.NoError(t, cfg.Validate())
+
+	next := &blockingLogsConsumer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	vp, err := newVerificationProcessor(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			vp.sendFeedback(FeedbackEvent{Timestamp: time.Now(), Level: "INFO", Category: "test", Message: "event"})
+		}
+	}
+
+	// The first batch gets stuck in the consumer
+	send(10)
+	select {
+	case <-next.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first batch was not exported")
+	}
+
+	// The feedback loop keeps up; the queue fills and the rest is dropped
+	send(90)
+	assert.Eventually(t, func() bool { return vp.feedbackExport.dropped.Load() == 70 }, 5*time.Second, time.Millisecond)
+	assert.Empty(t, vp.feedbackChannel)
+	assert.Len(t, vp.feedbackExport.queue, 20)
+
+	close(next.release)
+	require.NoError(t, vp.Shutdown(context.Background()))
+	assert.Equal(t, 30, next.LogRecordCount(), "the queued events are exported on shutdown")
+	assert.Equal(t, int64(30), vp.feedbackExport.exported.Load())
+}
+
+func TestVerificationProcessor_FeedbackExportBatches(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackExport.FlushInterval = time.Hour
+	require.NoError(t, cfg.Validate())
+
+	next := &consumertest.LogsSink{}
+	vp, err := newVerificationProcessor(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+
+	for i := 0; i < 250; i++ {
+		vp.sendFeedback(FeedbackEvent{Timestamp: time.Now(), Level: "WARNING", Category: "test", Message: "event", Database: "orders"})
+	}
+	assert.Eventually(t, func() bool { return next.LogRecordCount() == 200 }, 5*time.Second, time.Millisecond,
+		"full batches are exported without waiting for the flush interval")
+
+	require.NoError(t, vp.Shutdown(context.Background()))
+	assert.Equal(t, 250, next.LogRecordCount())
+	require.Len(t, next.AllLogs(), 3, "one ConsumeLogs call per batch instead of per event")
+
+	record := next.AllLogs()[2].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "WARNING", record.SeverityText())
+	category, _ := record.Attributes().Get("feedback.category")
+	assert.Equal(t, "test", category.Str())
+	database, _ := record.Attributes().Get("database.name")
+	assert.Equal(t, "orders", database.Str())
+}
+
+func TestVerificationProcessor_FeedbackExportFlushesOnInterval(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackExport.FlushInterval = 10 * time.Millisecond
+	next := &consumertest.LogsSink{}
+	vp, err := newVerificationProcessor(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+	defer vp.Shutdown(context.Background())
+
+	vp.sendFeedback(FeedbackEvent{Timestamp: time.Now(), Level: "INFO", Category: "test"})
+	assert.Eventually(t, func() bool { return next.LogRecordCount() == 1 }, 5*time.Second, time.Millisecond)
+
+	// The export goroutine is already running with its settings
+	updated := createDefaultConfig().(*Config)
+	updated.FeedbackExport.QueueSize = 10
+	assert.ErrorIs(t, vp.Reconfigure(updated), base.ErrRestartRequired)
+
+	updated.FeedbackExport.QueueSize = 0
+	assert.EqualError(t, updated.Validate(), "feedback_export.queue_size must be positive")
+}
+
+// fakeResourceSource returns fixed resource readings
+type fakeResourceSource struct {
+	cpu, memory, disk float64
+}
+
+func (f *fakeResourceSource) CPUPercent() (float64, error)    { return f.cpu, nil }
+func (f *fakeResourceSource) MemoryPercent() (float64, error) { return f.memory, nil }
+func (f *fakeResourceSource) DiskPercent() (float64, error)   { return f.disk, nil }
+
+// feedbackMessages returns the messages of the exported feedback events of
+// the given categories
+func feedbackMessages(sink *consumertest.LogsSink, categories ...string) []string {
+	var messages []string
+	for _, ld := range sink.AllLogs() {
+		records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			var event FeedbackEvent
+			if err := json.Unmarshal([]byte(records.At(i).Body().Str()), &event); err == nil && slices.Contains(categories, event.Category) {
+				messages = append(messages, event.Message)
+			}
+		}
+	}
+	return messages
+}
+
+func TestVerificationProcessor_ResourceThresholdAlerts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+	cfg.FeedbackExport.FlushInterval = 10 * time.Millisecond
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	processor.healthChecker.resourceSource = &fakeResourceSource{cpu: 95, memory: 10, disk: 97}
+	processor.performHealthCheck()
+
+	categories := []string{"high_memory_usage", "high_cpu_usage", "high_disk_usage"}
+	require.Eventually(t, func() bool {
+		return len(feedbackMessages(sink, categories...)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.ElementsMatch(t, []string{
+		"CPU usage above threshold: 95.00%",
+		"Disk usage above threshold: 97.00%",
+	}, feedbackMessages(sink, categories...))
+	assert.Equal(t, 95.0, processor.resourceMonitor.cpuUsage)
+	assert.Equal(t, 97.0, processor.resourceMonitor.diskUsage)
+}
+
+func TestVerificationProcessor_ResourcesBelowThresholds(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	processor.healthChecker.resourceSource = &fakeResourceSource{cpu: 20, memory: 30, disk: 40}
+	processor.performHealthCheck()
+
+	// Shutting down exports whatever was queued
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.Empty(t, feedbackMessages(sink, "high_memory_usage", "high_cpu_usage", "high_disk_usage"))
+}
+
+func TestVerificationProcessor_ReconfigureResourceSource(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	updated := createDefaultConfig().(*Config)
+	updated.HealthThresholds.DiskPath = dir
+	updated.HealthThresholds.MemoryLimitMiB = 512
+	require.NoError(t, processor.Reconfigure(updated))
+
+	rs := processor.healthChecker.resourceSource.(*runtimeResourceSource)
+	assert.Equal(t, dir, rs.diskPath)
+	assert.Equal(t, uint64(512*1024*1024), rs.memoryLimit)
+}
+
+func TestRuntimeResourceSource(t *testing.T) {
+	rs := newRuntimeResourceSource(t.TempDir(), 0)
+
+	for name, read := range map[string]func() (float64, error){
+		"cpu":    rs.CPUPercent,
+		"memory": rs.MemoryPercent,
+		"disk":   rs.DiskPercent,
+	} {
+		value, err := read()
+		if errors.Is(err, errResourceUnsupported) {
+			continue
+		}
+		require.NoError(t, err, name)
+		assert.GreaterOrEqual(t, value, 0.0, name)
+		assert.LessOrEqual(t, value, 100.0, name)
+	}
+}
+
+func TestRuntimeResourceSource_MemoryPercentOfLimit(t *testing.T) {
+	rs := newRuntimeResourceSource(t.TempDir(), 1024*1024*1024)
+	rs.memoryInUse = func() uint64 { return 50 * 1024 * 1024 }
+
+	percent, err := rs.MemoryPercent()
+	require.NoError(t, err)
+
+	// 50MB of a 1GB limit is ~5%, not 50
+	assert.InDelta(t, 4.88, percent, 0.01)
+}
+
+func TestVerificationProcessor_MemoryAlertUsesPercent(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableContinuousHealthChecks = false
+	cfg.FeedbackExport.FlushInterval = 10 * time.Millisecond
+	cfg.HealthThresholds.MemoryLimitMiB = 1024
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	rs := processor.healthChecker.resourceSource.(*runtimeResourceSource)
+	rs.memoryInUse = func() uint64 { return 100 * 1024 * 1024 }
+	processor.performHealthCheck()
+
+	// 100MB under a 1GB limit must not trip the 85% threshold
+	assert.InDelta(t, 9.77, processor.resourceMonitor.memoryUsage, 0.01)
+
+	rs.memoryInUse = func() uint64 { return 900 * 1024 * 1024 }
+	processor.performHealthCheck()
+
+	require.Eventually(t, func() bool {
+		return len(feedbackMessages(sink, "high_memory_usage")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"Memory usage above threshold: 87.89%"}, feedbackMessages(sink, "high_memory_usage"))
+}
+
+func TestVerificationProcessor_MemoryLimitValidation(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthThresholds.MemoryLimitMiB = -1
+	assert.EqualError(t, cfg.Validate(), "health_thresholds.memory_limit_mib cannot be negative")
+}
+
+func TestVerificationProcessor_DuplicateDetectionBounded(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QualityRules.DuplicateCacheSize = 5
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	for i := 0; i < 20; i++ {
+		logs := plog.NewLogs()
+		lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		lr.Attributes().PutStr("query_id", fmt.Sprintf("q%d", i))
+		require.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+	}
+
+	// Only the most recent values are remembered
+	cache := processor.qualityValidator.duplicateDetection
+	assert.Equal(t, 5, cache.Len())
+	for i := 0; i < 15; i++ {
+		assert.False(t, cache.Contains(fmt.Sprintf("query_id:q%d", i)))
+	}
+	for i := 15; i < 20; i++ {
+		assert.True(t, cache.Contains(fmt.Sprintf("query_id:q%d", i)))
+	}
+
+	// A reload resizes the cache in place
+	updated := createDefaultConfig().(*Config)
+	updated.QualityRules.DuplicateCacheSize = 2
+	require.NoError(t, processor.Reconfigure(updated))
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestQualityValidator_ExpireDuplicates(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QualityRules.DuplicateCacheTTL = time.Minute
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	qv := processor.qualityValidator
+	now := time.Now()
+	qv.duplicateDetection.Add("stale", now.Add(-2*time.Minute))
+	qv.duplicateDetection.Add("fresh", now)
+
+	qv.expireDuplicates(now)
+
+	assert.False(t, qv.duplicateDetection.Contains("stale"))
+	assert.True(t, qv.duplicateDetection.Contains("fresh"))
+}
+
+func TestQualityRulesConfig_DuplicateCacheValidation(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QualityRules.DuplicateCacheSize = 0
+	assert.EqualError(t, cfg.Validate(), "quality_rules.duplicate_cache_size must be positive")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.QualityRules.DuplicateCacheTTL = -time.Second
+	assert.EqualError(t, cfg.Validate(), "quality_rules.duplicate_cache_ttl cannot be negative")
+}
+
+func TestVerificationProcessor_FeedbackDroppedCounter(t *testing.T) {
+	// No feedback loop drains the channel
+	vp := &VerificationProcessor{logger: zap.NewNop(), feedbackChannel: make(chan FeedbackEvent, 2)}
+
+	for i := 0; i < 100; i++ {
+		vp.sendFeedback(FeedbackEvent{Level: "WARNING", Category: "flood", Message: "storm"})
+	}
+	assert.Equal(t, int64(98), vp.FeedbackDropped())
+}
+
+func TestVerificationProcessor_FeedbackCoalescing(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackChannelSize = 2
+	cfg.FeedbackCoalescing.Enabled = true
+	cfg.FeedbackCoalescing.Window = 50 * time.Millisecond
+	cfg.FeedbackExport.FlushInterval = 10 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	sink := &consumertest.LogsSink{}
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	for i := 0; i < 100; i++ {
+		processor.sendFeedback(FeedbackEvent{Timestamp: time.Now(), Level: "WARNING", Category: "flood", Message: "storm"})
+	}
+
+	// The first event and the summary go through the batched export
+	require.Eventually(t, func() bool {
+		return len(feedbackMessages(sink, "flood")) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int64(0), processor.FeedbackDropped())
+	assert.Equal(t, []string{"storm", "storm (repeated 99 times in 50ms)"}, feedbackMessages(sink, "flood"))
+
+	// The coalescer and its flush ticker are set up at start
+	updated := *cfg
+	updated.FeedbackCoalescing.Window = time.Minute
+	assert.ErrorIs(t, processor.Reconfigure(&updated), base.ErrRestartRequired)
+}
+
+func TestFeedbackCoalescer_SeparatesKeys(t *testing.T) {
+	c := newFeedbackCoalescer(time.Minute)
+	now := time.Now()
+
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now))
+	assert.True(t, c.admit(FeedbackEvent{Category: "b", Level: "WARNING"}, now))
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "ERROR"}, now))
+	assert.False(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now))
+
+	// Nothing is flushed while windows are open
+	assert.Empty(t, c.flush(now))
+
+	summaries := c.flush(now.Add(time.Minute))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "a", summaries[0].Category)
+	assert.Equal(t, int64(1), summaries[0].Metrics["occurrences"])
+
+	// A new window starts after the flush
+	assert.True(t, c.admit(FeedbackEvent{Category: "a", Level: "WARNING"}, now.Add(time.Minute)))
+}
+
+func TestFeedbackCoalescingConfig_Validate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.FeedbackChannelSize = 0
+	assert.EqualError(t, cfg.Validate(), "feedback_channel_size must be positive")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.FeedbackCoalescing = FeedbackCoalescingConfig{Enabled: true}
+	assert.EqualError(t, cfg.Validate(), "feedback_coalescing.window must be positive when coalescing is enabled")
+}
+
+// seedFallingThroughput leaves four snapshots of falling throughput with a
+// high error rate; performAutoTuning appends the live snapshot as the fifth.
+func seedFallingThroughput(vp *VerificationProcessor) {
+	for _, throughput := range []float64{100, 90, 80, 70} {
+		vp.feedbackEngine.performanceHistory = append(vp.feedbackEngine.performanceHistory,
+			PerformanceSnapshot{Timestamp: time.Now(), Throughput: throughput, ErrorRate: 0.2})
+	}
+}
+
+func TestVerificationProcessor_AutoTuningAppliesParameters(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.5
+	cfg.AutoTuningConfig.MaxParameterChange = 0.2
+	cfg.ConsumerTimeout = 30 * time.Second
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	seedFallingThroughput(processor)
+	processor.performAutoTuning()
+
+	assert.InDelta(t, 0.8, processor.liveParams.QualitySampleRate(), 1e-9)
+	assert.Equal(t, 36*time.Second, processor.liveParams.ConsumerTimeout())
+
+	applied := processor.feedbackEngine.appliedTunings
+	require.Len(t, applied, 2)
+	assert.Equal(t, paramQualitySampleRate, applied[0].Parameter)
+	assert.Equal(t, 1.0, applied[0].Before)
+	assert.Equal(t, paramConsumerTimeout, applied[1].Parameter)
+	assert.Equal(t, 30*time.Second, applied[1].Before)
+}
+
+func TestVerificationProcessor_AutoTuningRespectsConfidence(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.9
+	cfg.ConsumerTimeout = 30 * time.Second
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	seedFallingThroughput(processor)
+	processor.performAutoTuning()
+
+	// Recommendations are only reported below the confidence threshold
+	assert.Len(t, processor.feedbackEngine.tuningRecommendations, 2)
+	assert.Empty(t, processor.feedbackEngine.appliedTunings)
+	assert.Equal(t, 1.0, processor.liveParams.QualitySampleRate())
+	assert.Equal(t, 30*time.Second, processor.liveParams.ConsumerTimeout())
+}
+
+func TestVerificationProcessor_ConsumerTimeoutDisabledByDefault(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Zero(t, cfg.ConsumerTimeout)
+	cfg.AutoTuningConfig.EnableAutoApply = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 0.5
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	seedFallingThroughput(processor)
+	processor.performAutoTuning()
+
+	// Auto-tuning does not turn on a timeout that was never set
+	assert.Zero(t, processor.liveParams.ConsumerTimeout())
+	for _, applied := range processor.feedbackEngine.appliedTunings {
+		assert.NotEqual(t, paramConsumerTimeout, applied.Parameter)
+	}
+}
+
+func TestVerificationProcessor_ReconfigureAutoTuning(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ConsumerTimeout = 30 * time.Second
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	processor.liveParams.setQualitySampleRate(0.5)
+
+	// A changed timeout replaces the tuned value; the unchanged rate keeps it
+	updated := *cfg
+	updated.ConsumerTimeout = 10 * time.Second
+	require.NoError(t, processor.Reconfigure(&updated))
+	assert.Equal(t, 10*time.Second, processor.liveParams.ConsumerTimeout())
+	assert.Equal(t, 0.5, processor.liveParams.QualitySampleRate())
+
+	restart := updated
+	restart.EnableAutoTuning = true
+	assert.ErrorIs(t, processor.Reconfigure(&restart), base.ErrRestartRequired)
+}
+
+func TestLiveParameters_ApplyBounds(t *testing.T) {
+	lp := newLiveParameters(createDefaultConfig().(*Config))
+
+	_, after, ok := lp.apply(TuningRecommendation{Parameter: paramQualitySampleRate, SuggestedValue: 0.01})
+	require.True(t, ok)
+	assert.Equal(t, minQualitySampleRate, after)
+
+	_, after, ok = lp.apply(TuningRecommendation{Parameter: paramConsumerTimeout, SuggestedValue: time.Hour})
+	require.True(t, ok)
+	assert.Equal(t, maxConsumerTimeout, after)
+
+	_, _, ok = lp.apply(TuningRecommendation{Parameter: "batch_size", SuggestedValue: 10})
+	assert.False(t, ok)
+	_, _, ok = lp.apply(TuningRecommendation{Parameter: paramConsumerTimeout, SuggestedValue: "10s"})
+	assert.False(t, ok)
+}
+
+func TestAutoTuningConfig_Validate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EnableAutoTuning = true
+	cfg.AutoTuningInterval = 0
+	assert.EqualError(t, cfg.Validate(), "auto_tuning_interval must be positive when auto-tuning is enabled")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.EnableAutoTuning = true
+	cfg.AutoTuningConfig.MinConfidenceLevel = 1.5
+	assert.EqualError(t, cfg.Validate(), "auto_tuning_config.min_confidence_level must be between 0.0 and 1.0")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.ConsumerTimeout = -time.Second
+	assert.EqualError(t, cfg.Validate(), "consumer_timeout cannot be negative")
+
+	cfg = createDefaultConfig().(*Config)
+	cfg.QualitySampleRate = 0
+	assert.EqualError(t, cfg.Validate(), "quality_sample_rate must be greater than 0.0 and at most 1.0")
+}
+
+func TestVerificationProcessor_HealthEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "127.0.0.1:0"
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	require.NoError(t, processor.Start(context.Background(), nil))
+
+	baseURL := "http://" + processor.healthServer.listener.Addr().String()
+
+	// Scrape while records are being consumed to exercise the metrics lock
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			logs := plog.NewLogs()
+			rl := logs.ResourceLogs().AppendEmpty()
+			rl.Resource().Attributes().PutStr("database_name", "orders")
+			rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			assert.NoError(t, processor.ConsumeLogs(context.Background(), logs))
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(baseURL + "/metrics")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	wg.Wait()
+
+	resp, err := http.Get(baseURL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var report map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, float64(50), report["records_processed"])
+	databases := report["databases"].(map[string]interface{})
+	assert.Equal(t, float64(50), databases["orders"].(map[string]interface{})["record_count"])
+
+	resp, err = http.Get(baseURL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "verification_records_processed_total 50\n")
+	assert.Contains(t, string(body), `verification_database_records_total{database="orders"} 50`)
+	assert.Contains(t, string(body), `verification_database_circuit_breaker_open{database="orders"} 0`)
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	_, err = http.Get(baseURL + "/health")
+	assert.Error(t, err)
+}
+
+func TestVerificationProcessor_HealthEndpointBindError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "not-an-address"
+
+	processor, err := newVerificationProcessor(zap.NewNop(), cfg, &consumertest.LogsSink{})
+	require.NoError(t, err)
+	defer processor.Shutdown(context.Background())
+
+	assert.Error(t, processor.Start(context.Background(), nil))
+
+	updated := *cfg
+	updated.HealthEndpoint.Endpoint = "127.0.0.1:0"
+	assert.ErrorIs(t, processor.Reconfigure(&updated), base.ErrRestartRequired)
+}
+
+func TestHealthEndpointConfig_Validate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint = HealthEndpointConfig{Enabled: true}
+	assert.EqualError(t, cfg.Validate(), "health_endpoint.endpoint must be set when the health endpoint is enabled")
+}
+
+func TestConcurrentVerificationProcessor_HealthEndpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HealthEndpoint.Enabled = true
+	cfg.HealthEndpoint.Endpoint = "127.0.0.1:0"
+
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), processortest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), nil))
+
+	cvp := lp.(*ConcurrentVerificationProcessor)
+	baseURL := "http://" + cvp.healthServer.listener.Addr().String()
+	resp, err := http.Get(baseURL + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, lp.Shutdown(context.Background()))
+	_, err = http.Get(baseURL + "/health")
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// healthReportOutput persists periodic health reports to a rotating JSON
+// lines file and/or posts them to a webhook, so external systems can consume
+// health without scraping logs. Webhook delivery runs on its own goroutine
+// behind a bounded queue: a slow or failing webhook never blocks the
+// processor, and reports that cannot be queued or delivered are dropped and
+// counted.
+type healthReportOutput struct {
+	logger   *zap.Logger
+	interval time.Duration
+
+	file *rotatingFile
+
+	webhook HealthReportWebhookConfig
+	client  *http.Client
+	queue   chan []byte
+	dropped atomic.Int64
+
+	// ctx aborts in-flight webhook requests and retry waits on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newHealthReportOutput(logger *zap.Logger, cfg HealthReportOutputConfig) *healthReportOutput {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &healthReportOutput{
+		logger:   logger,
+		interval: cfg.Interval,
+		webhook:  cfg.Webhook,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if cfg.File.Path != "" {
+		out.file = newRotatingFile(cfg.File.Path, int64(cfg.File.MaxSizeMiB)*1024*1024, cfg.File.MaxFiles)
+	}
+	if cfg.Webhook.URL != "" {
+		out.client = &http.Client{Timeout: cfg.Webhook.Timeout}
+		out.queue = make(chan []byte, cfg.Webhook.QueueSize)
+	}
+	return out
+}
+
+// healthReportOutputLoop publishes a health report to the configured file and
+// webhook every health_report_output.interval
+func (vp *VerificationProcessor) healthReportOutputLoop() {
+	defer vp.wg.Done()
+	defer vp.reportOutput.closeFile()
+
+	ticker := time.NewTicker(vp.reportOutput.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			vp.reportOutput.publish(vp.snapshotMetrics().report())
+		case <-vp.shutdownChan:
+			return
+		}
+	}
+}
+
+// HealthReportsDropped returns the number of health reports the webhook did
+// not receive, either because its queue was full or delivery failed
+func (vp *VerificationProcessor) HealthReportsDropped() int64 {
+	if vp.reportOutput == nil {
+		return 0
+	}
+	return vp.reportOutput.droppedTotal()
+}
+
+// publish writes the report to the file and queues it for the webhook. It
+// never waits on the webhook.
+func (o *healthReportOutput) publish(report map[string]interface{}) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		o.logger.Warn("Failed to encode health report", zap.Error(err))
+		return
+	}
+
+	if o.file != nil {
+		if err := o.file.write(append(body, '\n')); err != nil {
+			o.logger.Warn("Failed to write health report file",
+				zap.String("path", o.file.path), zap.Error(err))
+		}
+	}
+
+	if o.queue != nil {
+		select {
+		case o.queue <- body:
+		default:
+			dropped := o.dropped.Add(1)
+			o.logger.Warn("Health report webhook queue full, dropping report",
+				zap.Int64("dropped_total", dropped))
+		}
+	}
+}
+
+// deliverWebhook posts queued reports until done is closed
+func (o *healthReportOutput) deliverWebhook(done <-chan struct{}) {
+	for {
+		select {
+		case body := <-o.queue:
+			if err := o.post(body); err != nil {
+				dropped := o.dropped.Add(1)
+				o.logger.Warn("Failed to deliver health report to webhook, dropping report",
+					zap.Error(err), zap.Int64("dropped_total", dropped))
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// post sends one report, retrying with exponential backoff
+func (o *healthReportOutput) post(body []byte) error {
+	backoff := o.webhook.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = o.postOnce(body); err == nil {
+			return nil
+		}
+		if attempt >= o.webhook.MaxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-o.ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (o *healthReportOutput) postOnce(body []byte) error {
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodPost, o.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range o.webhook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// droppedTotal returns the number of reports the webhook did not receive
+func (o *healthReportOutput) droppedTotal() int64 {
+	return o.dropped.Load()
+}
+
+// closeFile closes the report file. The output loop calls it on exit, so no
+// write can reopen the file afterwards.
+func (o *healthReportOutput) closeFile() {
+	if o.file == nil {
+		return
+	}
+	if err := o.file.close(); err != nil {
+		o.logger.Warn("Failed to close health report file", zap.Error(err))
+	}
+}
+
+// rotatingFile appends to path and, once a write would take it past
+// maxBytes, shifts path to path.1, path.1 to path.2 and so on, keeping at
+// most maxFiles rotated files
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newRotatingFile(path string, maxBytes int64, maxFiles int) *rotatingFile {
+	return &rotatingFile{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+}
+
+func (f *rotatingFile) write(p []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return err
+}
+
+// open appends to an existing file so restarts continue where they left off
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	if f.maxFiles > 0 {
+		for i := f.maxFiles - 1; i > 0; i-- {
+			if err := os.Rename(rotatedName(f.path, i), rotatedName(f.path, i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(f.path, rotatedName(f.path, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func rotatedName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
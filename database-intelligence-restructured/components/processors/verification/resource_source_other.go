@@ -0,0 +1,16 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux && !darwin
+
+package verification
+
+import "time"
+
+func processCPUTime() (time.Duration, error) {
+	return 0, errResourceUnsupported
+}
+
+func diskSpace(path string) (total, free uint64, err error) {
+	return 0, 0, errResourceUnsupported
+}
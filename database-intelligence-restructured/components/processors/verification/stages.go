@@ -0,0 +1,57 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"time"
+)
+
+// stage is a step of the per-record verification whose cost is tracked
+// separately, so operators can see what each check costs before deciding
+// to disable it
+type stage int
+
+const (
+	// stageSchemaValidation checks the required fields
+	stageSchemaValidation stage = iota
+	// stagePIIScan matches attribute names, values and the body against
+	// the PII patterns
+	stagePIIScan
+	// stageQualityValidation scores data quality and tracks cardinality
+	stageQualityValidation
+
+	stageCount
+)
+
+var stageNames = [stageCount]string{
+	stageSchemaValidation:  "schema_validation",
+	stagePIIScan:           "pii_scan",
+	stageQualityValidation: "quality_validation",
+}
+
+func (s stage) String() string {
+	return stageNames[s]
+}
+
+// observeStage adds the time since start to a stage. It is safe to call
+// from the concurrent processor's worker pools.
+func (pt *PerformanceTracker) observeStage(s stage, start time.Time) {
+	pt.stageTime[s].Add(int64(time.Since(start)))
+}
+
+// stageTimings returns the cumulative time spent in each stage. A disabled
+// stage reports zero.
+func (pt *PerformanceTracker) stageTimings() map[string]time.Duration {
+	timings := make(map[string]time.Duration, stageCount)
+	for s := stage(0); s < stageCount; s++ {
+		timings[s.String()] = time.Duration(pt.stageTime[s].Load())
+	}
+	return timings
+}
+
+// StageTimings returns the cumulative time spent in each verification
+// stage, for the healthcheck extension's /health/stats
+func (vp *VerificationProcessor) StageTimings() map[string]time.Duration {
+	return vp.performanceTracker.stageTimings()
+}
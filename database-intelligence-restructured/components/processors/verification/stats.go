@@ -0,0 +1,64 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// statsProvider matches the healthcheck extension's StatsProvider. Both are
+// unnamed interfaces over standard library types, so they are identical and
+// the processor does not need to import the extension.
+type statsProvider = interface {
+	RecordCounts() map[string]int64
+	LastDataTimestamps() map[string]time.Time
+	DroppedRecords() int64
+}
+
+// statsRegistrar is implemented by extensions that aggregate pipeline stats
+type statsRegistrar interface {
+	RegisterStatsProvider(name string, provider statsProvider)
+}
+
+// RecordCounts returns the number of log records processed
+func (vp *VerificationProcessor) RecordCounts() map[string]int64 {
+	vp.performanceTracker.mu.RLock()
+	defer vp.performanceTracker.mu.RUnlock()
+
+	return map[string]int64{"logs": vp.performanceTracker.recordsProcessed}
+}
+
+// LastDataTimestamps returns when log records were last processed
+func (vp *VerificationProcessor) LastDataTimestamps() map[string]time.Time {
+	vp.performanceTracker.mu.RLock()
+	defer vp.performanceTracker.mu.RUnlock()
+
+	if vp.performanceTracker.lastRecordTime.IsZero() {
+		return map[string]time.Time{}
+	}
+	return map[string]time.Time{"logs": vp.performanceTracker.lastRecordTime}
+}
+
+// DroppedRecords returns zero; the verification processor forwards every record
+func (vp *VerificationProcessor) DroppedRecords() int64 {
+	return 0
+}
+
+// registerStatsProvider registers the processor under its component ID with
+// every host extension that aggregates stats, so two instances stay apart
+func (vp *VerificationProcessor) registerStatsProvider(host component.Host) {
+	if host == nil {
+		return
+	}
+
+	for id, ext := range host.GetExtensions() {
+		if registrar, ok := ext.(statsRegistrar); ok {
+			registrar.RegisterStatsProvider(vp.id.String(), vp)
+			vp.logger.Debug("Registered verification stats", zap.String("extension", id.String()))
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright Database Intelligence MVP
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Tunable parameter names recognised by the auto-tuning engine
+const (
+	paramConsumerTimeout   = "consumer_timeout"
+	paramQualitySampleRate = "quality_sample_rate"
+)
+
+// Bounds keeping auto-tuned values within safe operating ranges
+const (
+	minQualitySampleRate = 0.1
+	maxConsumerTimeout   = 5 * time.Minute
+)
+
+// liveParameters holds the processor settings that auto-tuning may change at
+// runtime. Values are read on the hot path, so they are stored atomically.
+type liveParameters struct {
+	consumerTimeout   atomic.Int64  // nanoseconds; zero disables the deadline
+	qualitySampleRate atomic.Uint64 // math.Float64bits of a 0.0-1.0 fraction
+}
+
+// TuningRecommendation suggests a change to a live parameter
+type TuningRecommendation struct {
+	Parameter      string
+	CurrentValue   interface{}
+	SuggestedValue interface{}
+	Reason         string
+	Impact         string
+	Confidence     float64
+}
+
+// AppliedTuning records a parameter change made by the auto-tuning engine
+type AppliedTuning struct {
+	Timestamp time.Time
+	Parameter string
+	Before    interface{}
+	After     interface{}
+	Reason    string
+}
+
+func newLiveParameters(cfg *Config) *liveParameters {
+	lp := &liveParameters{}
+	lp.setConsumerTimeout(cfg.ConsumerTimeout)
+	lp.setQualitySampleRate(cfg.QualitySampleRate)
+	return lp
+}
+
+// ConsumerTimeout returns the deadline applied to the downstream consumer
+func (lp *liveParameters) ConsumerTimeout() time.Duration {
+	return time.Duration(lp.consumerTimeout.Load())
+}
+
+func (lp *liveParameters) setConsumerTimeout(d time.Duration) {
+	lp.consumerTimeout.Store(int64(d))
+}
+
+// QualitySampleRate returns the fraction of records that receive quality validation
+func (lp *liveParameters) QualitySampleRate() float64 {
+	return math.Float64frombits(lp.qualitySampleRate.Load())
+}
+
+func (lp *liveParameters) setQualitySampleRate(rate float64) {
+	lp.qualitySampleRate.Store(math.Float64bits(rate))
+}
+
+// apply sets a tunable parameter from a recommendation, returning the previous
+// and new values. Unknown parameters or mistyped values are rejected.
+func (lp *liveParameters) apply(rec TuningRecommendation) (before, after interface{}, ok bool) {
+	switch rec.Parameter {
+	case paramConsumerTimeout:
+		value, isDuration := rec.SuggestedValue.(time.Duration)
+		if !isDuration {
+			return nil, nil, false
+		}
+		if value > maxConsumerTimeout {
+			value = maxConsumerTimeout
+		}
+		before = lp.ConsumerTimeout()
+		lp.setConsumerTimeout(value)
+		return before, value, true
+
+	case paramQualitySampleRate:
+		value, isFloat := rec.SuggestedValue.(float64)
+		if !isFloat {
+			return nil, nil, false
+		}
+		value = math.Min(math.Max(value, minQualitySampleRate), 1.0)
+		before = lp.QualitySampleRate()
+		lp.setQualitySampleRate(value)
+		return before, value, true
+	}
+	return nil, nil, false
+}
+
+// autoTuningEngine runs performAutoTuning every auto_tuning_interval
+func (vp *VerificationProcessor) autoTuningEngine() {
+	defer vp.wg.Done()
+
+	ticker := time.NewTicker(vp.currentConfig().AutoTuningInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			vp.performAutoTuning()
+		case <-vp.shutdownChan:
+			return
+		}
+	}
+}
+
+// performAutoTuning records a performance snapshot and, once there is enough
+// history, applies the recommendations that clear min_confidence_level when
+// enable_auto_apply is set. Other recommendations are reported as feedback.
+func (vp *VerificationProcessor) performAutoTuning() {
+	cfg := vp.currentConfig().AutoTuningConfig
+
+	vp.feedbackEngine.mu.Lock()
+	defer vp.feedbackEngine.mu.Unlock()
+
+	vp.feedbackEngine.performanceHistory = append(vp.feedbackEngine.performanceHistory, vp.collectPerformanceSnapshot())
+	if len(vp.feedbackEngine.performanceHistory) > 100 {
+		vp.feedbackEngine.performanceHistory = vp.feedbackEngine.performanceHistory[1:]
+	}
+
+	recommendations := vp.analyzePerformanceTrends(cfg.MaxParameterChange)
+	vp.feedbackEngine.tuningRecommendations = recommendations
+	for _, rec := range recommendations {
+		if cfg.EnableAutoApply && rec.Confidence >= cfg.MinConfidenceLevel {
+			vp.applyTuningRecommendation(rec)
+			continue
+		}
+		vp.sendFeedback(FeedbackEvent{
+			Timestamp:   time.Now(),
+			Level:       "INFO",
+			Category:    "auto_tuning",
+			Message:     fmt.Sprintf("Tuning recommendation: %s", rec.Reason),
+			Remediation: rec.Impact,
+			Metrics: map[string]interface{}{
+				"parameter":       rec.Parameter,
+				"current_value":   fmt.Sprint(rec.CurrentValue),
+				"suggested_value": fmt.Sprint(rec.SuggestedValue),
+				"confidence":      rec.Confidence,
+			},
+		})
+	}
+	vp.feedbackEngine.lastTuning = time.Now()
+}
+
+// analyzePerformanceTrends looks at the last five snapshots. Falling
+// throughput lowers the quality sample rate, and a high error rate raises a
+// configured consumer timeout, each by at most maxChange. Callers must hold
+// feedbackEngine.mu.
+func (vp *VerificationProcessor) analyzePerformanceTrends(maxChange float64) []TuningRecommendation {
+	history := vp.feedbackEngine.performanceHistory
+	if len(history) < 5 {
+		return nil
+	}
+	recent := history[len(history)-5:]
+
+	var recommendations []TuningRecommendation
+	if recent[4].Throughput-recent[0].Throughput < -0.1 {
+		current := vp.liveParams.QualitySampleRate()
+		if current > minQualitySampleRate {
+			recommendations = append(recommendations, TuningRecommendation{
+				Parameter:      paramQualitySampleRate,
+				CurrentValue:   current,
+				SuggestedValue: math.Max(current*(1-maxChange), minQualitySampleRate),
+				Reason:         "Decreasing throughput detected",
+				Impact:         "Validate fewer records to reduce per-record processing cost",
+				Confidence:     0.7,
+			})
+		}
+	}
+
+	avgErrorRate := 0.0
+	for _, snapshot := range recent {
+		avgErrorRate += snapshot.ErrorRate
+	}
+	avgErrorRate /= float64(len(recent))
+
+	// A timeout that was never configured is not turned on
+	if avgErrorRate > 0.05 {
+		current := vp.liveParams.ConsumerTimeout()
+		if current > 0 && current < maxConsumerTimeout {
+			recommendations = append(recommendations, TuningRecommendation{
+				Parameter:      paramConsumerTimeout,
+				CurrentValue:   current,
+				SuggestedValue: time.Duration(float64(current) * (1 + maxChange)),
+				Reason:         "High error rate detected",
+				Impact:         "Increase timeout to reduce timeout-related errors",
+				Confidence:     0.8,
+			})
+		}
+	}
+
+	return recommendations
+}
+
+// applyTuningRecommendation updates the live parameter. Callers must hold
+// feedbackEngine.mu.
+func (vp *VerificationProcessor) applyTuningRecommendation(rec TuningRecommendation) {
+	before, after, ok := vp.liveParams.apply(rec)
+	if !ok {
+		vp.logger.Warn("Ignoring unsupported tuning recommendation",
+			zap.String("parameter", rec.Parameter),
+			zap.Any("suggested_value", rec.SuggestedValue))
+		return
+	}
+
+	vp.logger.Info("Applied auto-tuning recommendation",
+		zap.String("parameter", rec.Parameter),
+		zap.Any("before", before),
+		zap.Any("after", after),
+		zap.Float64("confidence", rec.Confidence))
+
+	vp.feedbackEngine.appliedTunings = append(vp.feedbackEngine.appliedTunings, AppliedTuning{
+		Timestamp: time.Now(),
+		Parameter: rec.Parameter,
+		Before:    before,
+		After:     after,
+		Reason:    rec.Reason,
+	})
+	if len(vp.feedbackEngine.appliedTunings) > 100 {
+		vp.feedbackEngine.appliedTunings = vp.feedbackEngine.appliedTunings[1:]
+	}
+
+	vp.sendFeedback(FeedbackEvent{
+		Timestamp: time.Now(),
+		Level:     "INFO",
+		Category:  "auto_tuning",
+		Message:   fmt.Sprintf("Auto-applied tuning: %s", rec.Parameter),
+		Metrics: map[string]interface{}{
+			"parameter": rec.Parameter,
+			"old_value": fmt.Sprint(before),
+			"new_value": fmt.Sprint(after),
+		},
+	})
+}
+
+// sampleQuality reports whether the next record gets quality validation,
+// given the live quality sample rate
+func (vp *VerificationProcessor) sampleQuality() bool {
+	rate := vp.liveParams.QualitySampleRate()
+	return rate >= 1.0 || rand.Float64() < rate
+}
@@ -0,0 +1,134 @@
+package cyclemetrics
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// configKey is the section a wrapped receiver's configuration gains
+const configKey = "collection_cycle"
+
+// Config controls the collection cycle metrics of a wrapped receiver
+type Config struct {
+	// Enabled adds the collection cycle metrics to every batch the
+	// receiver sends
+	Enabled bool `mapstructure:"enabled"`
+
+	// StartupJitter delays the receiver's start by a random time of up to
+	// this percentage of its collection_interval, so receivers with the
+	// same interval do not scrape in lockstep
+	StartupJitter float64 `mapstructure:"startup_jitter"`
+
+	// IntervalJitter lengthens the receiver's collection_interval by a
+	// random time of up to this percentage, picked once per receiver, so
+	// receivers that start together drift apart
+	IntervalJitter float64 `mapstructure:"interval_jitter"`
+}
+
+// validate checks that the jitter percentages are between 0 and 100. It is
+// unexported so that component.ValidateConfig does not also report its
+// errors without the section name.
+func (cfg *Config) validate() error {
+	if cfg.StartupJitter < 0 || cfg.StartupJitter > 100 {
+		return fmt.Errorf("startup_jitter must be between 0 and 100, got %v", cfg.StartupJitter)
+	}
+	if cfg.IntervalJitter < 0 || cfg.IntervalJitter > 100 {
+		return fmt.Errorf("interval_jitter must be between 0 and 100, got %v", cfg.IntervalJitter)
+	}
+	return nil
+}
+
+// jittered reports whether either jitter is set
+func (cfg *Config) jittered() bool {
+	return cfg.StartupJitter > 0 || cfg.IntervalJitter > 0
+}
+
+// wrappedConfig is the wrapped receiver's own configuration with the
+// collection_cycle section next to its settings
+type wrappedConfig struct {
+	// receiver is unexported so that it is unmarshaled and validated by
+	// this type rather than by reflection on its fields
+	receiver component.Config
+
+	CollectionCycle Config `mapstructure:"collection_cycle"`
+}
+
+// Unmarshal implements confmap.Unmarshaler. The collection_cycle section is
+// taken out and everything else is passed to the wrapped receiver's
+// configuration, which still rejects keys it does not know.
+func (cfg *wrappedConfig) Unmarshal(conf *confmap.Conf) error {
+	if conf == nil {
+		return nil
+	}
+
+	if conf.IsSet(configKey) {
+		section, err := conf.Sub(configKey)
+		if err != nil {
+			return fmt.Errorf("invalid %s section: %w", configKey, err)
+		}
+		if err := section.Unmarshal(&cfg.CollectionCycle); err != nil {
+			return fmt.Errorf("invalid %s section: %w", configKey, err)
+		}
+	}
+
+	settings := conf.ToStringMap()
+	delete(settings, configKey)
+	return confmap.NewFromStringMap(settings).Unmarshal(cfg.receiver)
+}
+
+// Validate validates the wrapped receiver's configuration and the
+// collection_cycle section. Jitter needs the receiver to have a
+// collection_interval.
+func (cfg *wrappedConfig) Validate() error {
+	if err := component.ValidateConfig(cfg.receiver); err != nil {
+		return err
+	}
+	if err := cfg.CollectionCycle.validate(); err != nil {
+		return fmt.Errorf("%s: %w", configKey, err)
+	}
+	if !cfg.CollectionCycle.jittered() {
+		return nil
+	}
+	if _, ok := collectionInterval(cfg.receiver); !ok {
+		return fmt.Errorf("%s: jitter needs a collection_interval, which %T does not have", configKey, cfg.receiver)
+	}
+	return nil
+}
+
+// collectionInterval returns the CollectionInterval field of a receiver
+// configuration, which may be promoted from an embedded struct such as
+// scraperhelper.ControllerConfig
+func collectionInterval(cfg component.Config) (time.Duration, bool) {
+	field, ok := collectionIntervalField(reflect.ValueOf(cfg))
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(field.Int()), true
+}
+
+// withCollectionInterval returns a copy of cfg with its collection interval
+// set to interval. The copy is shallow; only the interval differs.
+func withCollectionInterval(cfg component.Config, interval time.Duration) component.Config {
+	original := reflect.ValueOf(cfg)
+	copied := reflect.New(original.Elem().Type())
+	copied.Elem().Set(original.Elem())
+	if field, ok := collectionIntervalField(copied); ok {
+		field.SetInt(int64(interval))
+	}
+	return copied.Interface()
+}
+
+func collectionIntervalField(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	field := v.Elem().FieldByName("CollectionInterval")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(time.Duration(0)) || !field.CanSet() {
+		return reflect.Value{}, false
+	}
+	return field, true
+}
@@ -0,0 +1,194 @@
+package cyclemetrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	metricCycle    = "collector.collection.cycle"
+	metricDuration = "collector.collection.duration"
+
+	// attributeReceiver is the wrapped receiver's component ID
+	attributeReceiver = "receiver"
+)
+
+// durationBounds are the collector.collection.duration bucket bounds in
+// seconds, from quick single queries to scrapes near a 60s interval
+var durationBounds = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// cycleConsumer counts the batches a receiver sends, each being one
+// completed collection cycle, and adds the running totals to the batch
+type cycleConsumer struct {
+	receiver string
+	next     consumer.Metrics
+	now      func() time.Time
+
+	mu        sync.Mutex
+	startTime pcommon.Timestamp
+	cycles    int64
+	durations durationHistogram
+}
+
+// durationHistogram is a cumulative histogram over durationBounds
+type durationHistogram struct {
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+	counts []uint64
+}
+
+func newCycleConsumer(id component.ID, next consumer.Metrics) *cycleConsumer {
+	c := &cycleConsumer{
+		receiver:  id.String(),
+		next:      next,
+		now:       time.Now,
+		durations: durationHistogram{counts: make([]uint64, len(durationBounds)+1)},
+	}
+	c.startTime = pcommon.NewTimestampFromTime(c.now())
+	return c
+}
+
+// Capabilities implements the consumer.Metrics interface. The cycle's
+// metrics are appended to the batches passed in.
+func (c *cycleConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+// ConsumeMetrics implements the consumer.Metrics interface. The cycle's
+// duration runs from the earliest data point timestamp, which scrapers set
+// when the scrape starts, to now. A batch without timestamps is counted
+// but has no duration.
+func (c *cycleConsumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	now := c.now()
+	start, ok := earliestTimestamp(md)
+
+	c.mu.Lock()
+	c.cycles++
+	if ok {
+		c.durations.observe(now.Sub(start.AsTime()).Seconds())
+	}
+	c.appendMetrics(md, pcommon.NewTimestampFromTime(now))
+	c.mu.Unlock()
+
+	return c.next.ConsumeMetrics(ctx, md)
+}
+
+// appendMetrics adds the cycle totals to md under their own resource, so
+// the wrapped receiver's resource attributes are not repeated on them
+func (c *cycleConsumer) appendMetrics(md pmetric.Metrics, now pcommon.Timestamp) {
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("cyclemetrics")
+	sm.Scope().SetVersion("1.0.0")
+
+	cycle := sm.Metrics().AppendEmpty()
+	cycle.SetName(metricCycle)
+	cycle.SetDescription("Collection cycles the receiver has completed")
+	cycle.SetUnit("{cycle}")
+	sum := cycle.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(c.startTime)
+	dp.SetTimestamp(now)
+	dp.SetIntValue(c.cycles)
+	dp.Attributes().PutStr(attributeReceiver, c.receiver)
+
+	if c.durations.count == 0 {
+		return
+	}
+
+	duration := sm.Metrics().AppendEmpty()
+	duration.SetName(metricDuration)
+	duration.SetDescription("Time from the start of a collection cycle until the receiver sent its batch")
+	duration.SetUnit("s")
+	histogram := duration.SetEmptyHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	hdp := histogram.DataPoints().AppendEmpty()
+	hdp.SetStartTimestamp(c.startTime)
+	hdp.SetTimestamp(now)
+	hdp.SetCount(c.durations.count)
+	hdp.SetSum(c.durations.sum)
+	hdp.SetMin(c.durations.min)
+	hdp.SetMax(c.durations.max)
+	hdp.ExplicitBounds().FromRaw(durationBounds)
+	hdp.BucketCounts().FromRaw(c.durations.counts)
+	hdp.Attributes().PutStr(attributeReceiver, c.receiver)
+}
+
+// observe adds one duration in seconds, counting negative ones, from clock
+// skew between a remote timestamp and this host, as zero
+func (h *durationHistogram) observe(seconds float64) {
+	if seconds < 0 {
+		seconds = 0
+	}
+	if h.count == 0 || seconds < h.min {
+		h.min = seconds
+	}
+	if h.count == 0 || seconds > h.max {
+		h.max = seconds
+	}
+	h.count++
+	h.sum += seconds
+	// Buckets are upper-bound inclusive
+	h.counts[sort.SearchFloat64s(durationBounds, seconds)]++
+}
+
+// earliestTimestamp returns the earliest data point timestamp in md
+func earliestTimestamp(md pmetric.Metrics) (pcommon.Timestamp, bool) {
+	var earliest pcommon.Timestamp
+	found := false
+	visit := func(ts pcommon.Timestamp) {
+		if ts != 0 && (!found || ts < earliest) {
+			earliest = ts
+			found = true
+		}
+	}
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					dps := metric.Gauge().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						visit(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeSum:
+					dps := metric.Sum().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						visit(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeHistogram:
+					dps := metric.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						visit(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := metric.ExponentialHistogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						visit(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeSummary:
+					dps := metric.Summary().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						visit(dps.At(l).Timestamp())
+					}
+				}
+			}
+		}
+	}
+	return earliest, found
+}
@@ -0,0 +1,356 @@
+package cyclemetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+// stubConfig is the wrapped receiver's configuration
+type stubConfig struct {
+	Endpoint           string        `mapstructure:"endpoint"`
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+}
+
+func (cfg *stubConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must be specified")
+	}
+	return nil
+}
+
+// stubReceiver keeps the consumer and configuration it was created with,
+// so tests can send batches as a scrape loop would
+type stubReceiver struct {
+	component.StartFunc
+	component.ShutdownFunc
+	next   consumer.Metrics
+	config *stubConfig
+}
+
+func newStubFactory(created **stubReceiver) receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType("stubdb"),
+		func() component.Config { return &stubConfig{} },
+		receiver.WithMetrics(func(_ context.Context, _ receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+			*created = &stubReceiver{next: next, config: cfg.(*stubConfig)}
+			return *created, nil
+		}, component.StabilityLevelAlpha),
+	)
+}
+
+// newWrapped creates a wrapped stub receiver with the given configuration
+func newWrapped(t *testing.T, settings map[string]any, sink consumer.Metrics) *stubReceiver {
+	t.Helper()
+	var created *stubReceiver
+	factory := Wrap(newStubFactory(&created))
+
+	cfg := factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(settings).Unmarshal(cfg))
+	require.NoError(t, component.ValidateConfig(cfg))
+
+	set := receivertest.NewNopSettings()
+	set.ID = component.MustNewIDWithName("stubdb", "primary")
+	rcv, err := factory.CreateMetricsReceiver(context.Background(), set, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcv.Start(context.Background(), componenttest.NewNopHost()))
+	return created
+}
+
+// scrapeBatch is a batch whose data points are stamped at start
+func scrapeBatch(start time.Time) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("postgresql.backends")
+	dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetIntValue(3)
+	return md
+}
+
+// findMetric returns the named metric in md
+func findMetric(t *testing.T, md pmetric.Metrics, name string) (pmetric.Metric, bool) {
+	t.Helper()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if metrics.At(k).Name() == name {
+					return metrics.At(k), true
+				}
+			}
+		}
+	}
+	return pmetric.Metric{}, false
+}
+
+func TestCycleCounterIncrementsPerCycle(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	rcv := newWrapped(t, map[string]any{
+		"endpoint":         "primary:5432",
+		"collection_cycle": map[string]any{"enabled": true},
+	}, sink)
+
+	clock := time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC)
+	cycles := rcv.next.(*cycleConsumer)
+	cycles.now = func() time.Time { return clock }
+	assert.True(t, cycles.Capabilities().MutatesData, "the cycle's metrics are appended to the receiver's batches")
+
+	scrapeDurations := []time.Duration{200 * time.Millisecond, 2 * time.Second, 40 * time.Millisecond}
+	for _, d := range scrapeDurations {
+		clock = clock.Add(time.Minute)
+		require.NoError(t, rcv.next.ConsumeMetrics(context.Background(), scrapeBatch(clock.Add(-d))))
+	}
+
+	batches := sink.AllMetrics()
+	require.Len(t, batches, len(scrapeDurations))
+	for i, md := range batches {
+		_, ok := findMetric(t, md, "postgresql.backends")
+		assert.True(t, ok, "the receiver's metrics must be passed on")
+
+		cycle, ok := findMetric(t, md, metricCycle)
+		require.True(t, ok)
+		assert.True(t, cycle.Sum().IsMonotonic())
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, cycle.Sum().AggregationTemporality())
+		dp := cycle.Sum().DataPoints().At(0)
+		assert.Equal(t, int64(i+1), dp.IntValue())
+		receiverID, _ := dp.Attributes().Get(attributeReceiver)
+		assert.Equal(t, "stubdb/primary", receiverID.Str())
+	}
+
+	duration, ok := findMetric(t, batches[2], metricDuration)
+	require.True(t, ok)
+	assert.Equal(t, "s", duration.Unit())
+	hdp := duration.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(3), hdp.Count())
+	assert.InDelta(t, 2.24, hdp.Sum(), 1e-9)
+	assert.InDelta(t, 0.04, hdp.Min(), 1e-9)
+	assert.InDelta(t, 2.0, hdp.Max(), 1e-9)
+	// 0.04s in (0.01, 0.05], 0.2s in (0.1, 0.25], 2s in (1, 2.5]
+	assert.Equal(t, []uint64{0, 1, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0}, hdp.BucketCounts().AsRaw())
+}
+
+func TestCycleWithoutTimestampsHasNoDuration(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	rcv := newWrapped(t, map[string]any{
+		"endpoint":         "primary:5432",
+		"collection_cycle": map[string]any{"enabled": true},
+	}, sink)
+
+	require.NoError(t, rcv.next.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+
+	md := sink.AllMetrics()[0]
+	cycle, ok := findMetric(t, md, metricCycle)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), cycle.Sum().DataPoints().At(0).IntValue())
+	_, ok = findMetric(t, md, metricDuration)
+	assert.False(t, ok)
+}
+
+func TestDisabledByDefault(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	rcv := newWrapped(t, map[string]any{"endpoint": "primary:5432"}, sink)
+	assert.Same(t, sink, rcv.next, "the receiver must send to the pipeline directly")
+
+	require.NoError(t, rcv.next.ConsumeMetrics(context.Background(), scrapeBatch(time.Now())))
+	_, ok := findMetric(t, sink.AllMetrics()[0], metricCycle)
+	assert.False(t, ok)
+}
+
+func TestWrappedConfig(t *testing.T) {
+	var created *stubReceiver
+	factory := Wrap(newStubFactory(&created))
+	assert.Equal(t, "stubdb", factory.Type().String())
+	assert.Equal(t, component.StabilityLevelAlpha, factory.MetricsReceiverStability())
+	assert.Equal(t, component.StabilityLevelUndefined, factory.LogsReceiverStability())
+
+	cfg := factory.CreateDefaultConfig()
+	err := confmap.NewFromStringMap(map[string]any{"endpoint": "primary:5432", "datasource": "x"}).Unmarshal(cfg)
+	assert.ErrorContains(t, err, "datasource", "the wrapped receiver must reject unknown keys")
+
+	cfg = factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{"collection_cycle": map[string]any{"enabled": true}}).Unmarshal(cfg))
+	assert.True(t, cfg.(*wrappedConfig).CollectionCycle.Enabled)
+	assert.EqualError(t, component.ValidateConfig(cfg), "endpoint must be specified")
+
+	err = confmap.NewFromStringMap(map[string]any{"collection_cycle": map[string]any{"interval": "60s"}}).Unmarshal(factory.CreateDefaultConfig())
+	assert.ErrorContains(t, err, "invalid collection_cycle section")
+}
+
+// setRandom makes jitter draw values from draws in order
+func setRandom(t *testing.T, draws ...float64) {
+	t.Helper()
+	original := randFloat64
+	t.Cleanup(func() { randFloat64 = original })
+	randFloat64 = func() float64 {
+		require.NotEmpty(t, draws, "more jitter drawn than expected")
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}
+}
+
+func TestStartupJitterStaggersReceivers(t *testing.T) {
+	const interval = 200 * time.Millisecond
+	// Jitter of up to 50% of the interval, the two receivers drawing 10%
+	// and 80% of that
+	setRandom(t, 0.1, 0.8)
+	bound := interval / 2
+
+	started := make(chan string, 2)
+	factory := Wrap(receiver.NewFactory(
+		component.MustNewType("stubdb"),
+		func() component.Config { return &stubConfig{} },
+		receiver.WithMetrics(func(_ context.Context, set receiver.Settings, _ component.Config, _ consumer.Metrics) (receiver.Metrics, error) {
+			return &stubReceiver{StartFunc: func(context.Context, component.Host) error {
+				started <- set.ID.Name()
+				return nil
+			}}, nil
+		}, component.StabilityLevelAlpha),
+	))
+
+	receivers := make(map[string]*delayedReceiver)
+	for _, name := range []string{"a", "b"} {
+		cfg := factory.CreateDefaultConfig()
+		require.NoError(t, confmap.NewFromStringMap(map[string]any{
+			"endpoint":            name + ":5432",
+			"collection_interval": interval,
+			"collection_cycle":    map[string]any{"startup_jitter": 50},
+		}).Unmarshal(cfg))
+		require.NoError(t, component.ValidateConfig(cfg))
+
+		set := receivertest.NewNopSettings()
+		set.ID = component.MustNewIDWithName("stubdb", name)
+		rcv, err := factory.CreateMetricsReceiver(context.Background(), set, cfg, consumertest.NewNop())
+		require.NoError(t, err)
+		require.IsType(t, &delayedReceiver{}, rcv)
+		receivers[name] = rcv.(*delayedReceiver)
+	}
+
+	assert.Equal(t, 10*time.Millisecond, receivers["a"].delay)
+	assert.Equal(t, 80*time.Millisecond, receivers["b"].delay)
+	for _, rcv := range receivers {
+		assert.Less(t, rcv.delay, bound, "the delay must be within the jitter bound")
+	}
+
+	begin := time.Now()
+	for _, rcv := range receivers {
+		require.NoError(t, rcv.Start(context.Background(), componenttest.NewNopHost()))
+	}
+	assert.Less(t, time.Since(begin), 20*time.Millisecond, "Start must not wait for the delay")
+
+	offsets := make(map[string]time.Duration)
+	for range receivers {
+		select {
+		case name := <-started:
+			offsets[name] = time.Since(begin)
+		case <-time.After(5 * time.Second):
+			t.Fatal("a delayed receiver was not started")
+		}
+	}
+	assert.GreaterOrEqual(t, offsets["a"], receivers["a"].delay)
+	assert.GreaterOrEqual(t, offsets["b"], receivers["b"].delay)
+	assert.Less(t, offsets["a"], offsets["b"], "the receivers must start at different offsets")
+
+	for _, rcv := range receivers {
+		require.NoError(t, rcv.Shutdown(context.Background()))
+	}
+}
+
+func TestShutdownBeforeDelayedStart(t *testing.T) {
+	setRandom(t, 0.5)
+	var created *stubReceiver
+	factory := Wrap(newStubFactory(&created))
+
+	cfg := factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"endpoint":            "primary:5432",
+		"collection_interval": time.Hour,
+		"collection_cycle":    map[string]any{"startup_jitter": 100},
+	}).Unmarshal(cfg))
+
+	startCalled := false
+	rcv, err := factory.CreateMetricsReceiver(context.Background(), receivertest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	created.StartFunc = func(context.Context, component.Host) error {
+		startCalled = true
+		return nil
+	}
+	created.ShutdownFunc = func(context.Context) error {
+		return errors.New("a receiver that was never started must not be shut down")
+	}
+
+	require.NoError(t, rcv.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, rcv.Shutdown(context.Background()))
+	assert.False(t, startCalled)
+	assert.False(t, rcv.(*delayedReceiver).timer.Stop(), "the pending start must be cancelled")
+}
+
+func TestIntervalJitterLengthensInterval(t *testing.T) {
+	setRandom(t, 0.5)
+	var created *stubReceiver
+	factory := Wrap(newStubFactory(&created))
+
+	cfg := factory.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"endpoint":            "primary:5432",
+		"collection_interval": time.Minute,
+		"collection_cycle":    map[string]any{"interval_jitter": 20},
+	}).Unmarshal(cfg))
+
+	rcv, err := factory.CreateMetricsReceiver(context.Background(), receivertest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Same(t, created, rcv, "without startup jitter the receiver starts directly")
+	assert.Equal(t, 66*time.Second, created.config.CollectionInterval)
+	assert.Equal(t, "primary:5432", created.config.Endpoint)
+	assert.Equal(t, time.Minute, cfg.(*wrappedConfig).receiver.(*stubConfig).CollectionInterval,
+		"the configuration itself must not change")
+}
+
+func TestJitterValidation(t *testing.T) {
+	var created *stubReceiver
+	factory := Wrap(newStubFactory(&created))
+	validate := func(cycle map[string]any) error {
+		cfg := factory.CreateDefaultConfig()
+		require.NoError(t, confmap.NewFromStringMap(map[string]any{
+			"endpoint":         "primary:5432",
+			"collection_cycle": cycle,
+		}).Unmarshal(cfg))
+		return component.ValidateConfig(cfg)
+	}
+
+	assert.NoError(t, validate(map[string]any{"startup_jitter": 100, "interval_jitter": 0}))
+	assert.EqualError(t, validate(map[string]any{"startup_jitter": 150}),
+		"collection_cycle: startup_jitter must be between 0 and 100, got 150")
+	assert.EqualError(t, validate(map[string]any{"interval_jitter": -5}),
+		"collection_cycle: interval_jitter must be between 0 and 100, got -5")
+
+	noInterval := Wrap(receiver.NewFactory(
+		component.MustNewType("stubdb"),
+		func() component.Config { return &struct{}{} },
+		receiver.WithMetrics(func(context.Context, receiver.Settings, component.Config, consumer.Metrics) (receiver.Metrics, error) {
+			return &stubReceiver{}, nil
+		}, component.StabilityLevelAlpha),
+	))
+	cfg := noInterval.CreateDefaultConfig()
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{
+		"collection_cycle": map[string]any{"startup_jitter": 10},
+	}).Unmarshal(cfg))
+	assert.EqualError(t, component.ValidateConfig(cfg),
+		"collection_cycle: jitter needs a collection_interval, which *struct {} does not have")
+}
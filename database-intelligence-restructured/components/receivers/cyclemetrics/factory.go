@@ -0,0 +1,88 @@
+package cyclemetrics
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+var errConfigNotWrapped = errors.New("config is not a collection cycle wrapped receiver config")
+
+// Wrap returns a factory of the same type as factory whose receivers can
+// report their collection cycles. The configuration is the wrapped
+// receiver's own with an optional collection_cycle section; with it
+// enabled, every metrics batch the receiver sends is followed by the
+// collector.collection.cycle and collector.collection.duration metrics.
+// The section can also stagger the receiver's collection with jitter.
+// Logs and traces receivers are created by factory unchanged.
+func Wrap(factory receiver.Factory) receiver.Factory {
+	options := []receiver.FactoryOption{
+		receiver.WithMetrics(func(ctx context.Context, settings receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+			return createMetricsReceiver(ctx, settings, cfg, next, factory)
+		}, factory.MetricsReceiverStability()),
+	}
+	if stability := factory.LogsReceiverStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, receiver.WithLogs(func(ctx context.Context, settings receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+			wrapped, ok := cfg.(*wrappedConfig)
+			if !ok {
+				return nil, errConfigNotWrapped
+			}
+			return factory.CreateLogsReceiver(ctx, settings, wrapped.receiver, next)
+		}, stability))
+	}
+	if stability := factory.TracesReceiverStability(); stability != component.StabilityLevelUndefined {
+		options = append(options, receiver.WithTraces(func(ctx context.Context, settings receiver.Settings, cfg component.Config, next consumer.Traces) (receiver.Traces, error) {
+			wrapped, ok := cfg.(*wrappedConfig)
+			if !ok {
+				return nil, errConfigNotWrapped
+			}
+			return factory.CreateTracesReceiver(ctx, settings, wrapped.receiver, next)
+		}, stability))
+	}
+
+	return receiver.NewFactory(
+		factory.Type(),
+		func() component.Config {
+			return &wrappedConfig{receiver: factory.CreateDefaultConfig()}
+		},
+		options...,
+	)
+}
+
+// createMetricsReceiver creates the wrapped receiver, sending through a
+// cycleConsumer when collection cycle metrics are enabled and staggering
+// its collection when jitter is set
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	next consumer.Metrics,
+	factory receiver.Factory,
+) (receiver.Metrics, error) {
+	wrapped, ok := cfg.(*wrappedConfig)
+	if !ok {
+		return nil, errConfigNotWrapped
+	}
+
+	cycle := wrapped.CollectionCycle
+	receiverCfg := wrapped.receiver
+	interval, _ := collectionInterval(receiverCfg)
+	if cycle.IntervalJitter > 0 {
+		interval += jitter(interval, cycle.IntervalJitter)
+		receiverCfg = withCollectionInterval(receiverCfg, interval)
+		settings.Logger.Info("Lengthened collection interval to stagger collection", zap.Duration("collection_interval", interval))
+	}
+
+	if cycle.Enabled {
+		next = newCycleConsumer(settings.ID, next)
+	}
+	rcv, err := factory.CreateMetricsReceiver(ctx, settings, receiverCfg, next)
+	if err != nil || cycle.StartupJitter <= 0 {
+		return rcv, err
+	}
+	return newDelayedReceiver(rcv, jitter(interval, cycle.StartupJitter), settings.TelemetrySettings), nil
+}
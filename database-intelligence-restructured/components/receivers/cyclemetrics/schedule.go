@@ -0,0 +1,80 @@
+package cyclemetrics
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// randFloat64 returns a number in [0, 1) for picking jitter
+var randFloat64 = rand.Float64
+
+// jitter returns a random duration of up to percent of interval
+func jitter(interval time.Duration, percent float64) time.Duration {
+	return time.Duration(randFloat64() * percent / 100 * float64(interval))
+}
+
+// delayedReceiver starts the wrapped receiver after a delay. Start returns
+// at once so a long delay does not hold up the collector's startup; an
+// error from the delayed start is reported as a fatal status, as the
+// collector would treat it at startup.
+type delayedReceiver struct {
+	receiver.Metrics
+	delay     time.Duration
+	telemetry component.TelemetrySettings
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	started bool
+	stopped bool
+}
+
+func newDelayedReceiver(rcv receiver.Metrics, delay time.Duration, telemetry component.TelemetrySettings) *delayedReceiver {
+	return &delayedReceiver{Metrics: rcv, delay: delay, telemetry: telemetry}
+}
+
+// Start implements component.Component, scheduling the wrapped receiver's
+// start
+func (r *delayedReceiver) Start(_ context.Context, host component.Host) error {
+	r.telemetry.Logger.Info("Delaying receiver start to stagger collection", zap.Duration("delay", r.delay))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timer = time.AfterFunc(r.delay, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.stopped {
+			return
+		}
+		// The startup context has ended by now
+		if err := r.Metrics.Start(context.Background(), host); err != nil {
+			r.telemetry.Logger.Error("Delayed receiver start failed", zap.Error(err))
+			if r.telemetry.ReportStatus != nil {
+				r.telemetry.ReportStatus(component.NewFatalErrorEvent(err))
+			}
+			return
+		}
+		r.started = true
+	})
+	return nil
+}
+
+// Shutdown implements component.Component. A receiver whose delay has not
+// passed yet is never started.
+func (r *delayedReceiver) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if !r.started {
+		return nil
+	}
+	return r.Metrics.Shutdown(ctx)
+}
@@ -84,8 +84,8 @@ func (r *Receiver) executeQuery(ctx context.Context, queryDef *featuredetector.Q
 	// Prepare query arguments
 	args := r.prepareQueryArgs(config)
 	
-	// Execute query
-	rows, err := r.db.QueryContext(queryCtx, queryDef.SQL, args...)
+	// Execute query on the endpoint the category is routed to
+	rows, err := r.router.For(config.Category).QueryContext(queryCtx, queryDef.SQL, args...)
 	if err != nil {
 		return fmt.Errorf("query execution failed: %w", err)
 	}
@@ -107,6 +107,15 @@ func (r *Receiver) executeQuery(ctx context.Context, queryDef *featuredetector.Q
 	return fmt.Errorf("no output configuration (metrics or logs) specified")
 }
 
+// metricUnit returns the configured UCUM unit, "1" for dimensionless
+// values when none is set
+func metricUnit(unit string) string {
+	if unit == "" {
+		return "1"
+	}
+	return unit
+}
+
 // processMetrics processes query results as metrics
 func (r *Receiver) processMetrics(ctx context.Context, rows *sql.Rows, columns []string, config *QueryConfig) error {
 	timestamp := time.Now()
@@ -134,6 +143,7 @@ func (r *Receiver) processMetrics(ctx context.Context, rows *sql.Rows, columns [
 		if metricConfig.Description != "" {
 			metric.SetDescription(metricConfig.Description)
 		}
+		metric.SetUnit(metricUnit(metricConfig.Unit))
 		
 		// Set metric type
 		switch metricConfig.ValueType {
@@ -142,6 +152,7 @@ func (r *Receiver) processMetrics(ctx context.Context, rows *sql.Rows, columns [
 		case "sum":
 			sum := metric.SetEmptySum()
 			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			sum.SetIsMonotonic(true)
 		case "histogram":
 			metric.SetEmptyHistogram()
 		default:
@@ -221,6 +232,7 @@ func (r *Receiver) processMetrics(ctx context.Context, rows *sql.Rows, columns [
 				
 			case pmetric.MetricTypeSum:
 				dp := metric.Sum().DataPoints().AppendEmpty()
+				dp.SetStartTimestamp(pcommon.NewTimestampFromTime(r.startTime))
 				dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
 				dp.SetDoubleValue(numericValue)
 				
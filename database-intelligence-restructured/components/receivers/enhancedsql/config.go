@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 	
+	"github.com/database-intelligence/db-intel/components/internal/dbrouting"
 	"github.com/database-intelligence/db-intel/internal/featuredetector"
 	"go.opentelemetry.io/collector/component"
 )
@@ -25,6 +26,10 @@ type Config struct {
 	MaxOpenConnections int `mapstructure:"max_open_connections"`
 	MaxIdleConnections int `mapstructure:"max_idle_connections"`
 	
+	// Routing sends query categories to a read replica instead of the
+	// primary given by Datasource
+	Routing dbrouting.Config `mapstructure:"routing"`
+	
 	// Feature detection configuration
 	FeatureDetection FeatureDetectionConfig `mapstructure:"feature_detection"`
 	
@@ -115,9 +120,14 @@ type MetricConfig struct {
 	// ValueColumn is the column containing the metric value
 	ValueColumn string `mapstructure:"value_column"`
 	
-	// ValueType is the type of metric (gauge, sum, histogram)
+	// ValueType is the type of metric (gauge, sum, histogram). A sum is
+	// reported as a cumulative, monotonic counter.
 	ValueType string `mapstructure:"value_type"`
 	
+	// Unit is the UCUM unit of the metric, such as ms, By or {connection}.
+	// Defaults to "1".
+	Unit string `mapstructure:"unit"`
+	
 	// AttributeColumns are columns to use as metric attributes
 	AttributeColumns []string `mapstructure:"attribute_columns"`
 }
@@ -178,6 +188,11 @@ func (cfg *Config) Validate() error {
 		return errors.New("collection_interval must be positive")
 	}
 	
+	// Validate routing
+	if err := cfg.Routing.Validate(); err != nil {
+		return fmt.Errorf("routing: %w", err)
+	}
+	
 	// Validate feature detection
 	if cfg.FeatureDetection.Enabled {
 		if cfg.FeatureDetection.CacheDuration <= 0 {
@@ -266,6 +281,14 @@ func createDefaultConfig() component.Config {
 		CollectionInterval:        60 * time.Second,
 		MaxOpenConnections:        10,
 		MaxIdleConnections:        5,
+		Routing: dbrouting.Config{
+			// Only the primary sees the write workload and its replication
+			// state, so these stay there once a replica is configured
+			Groups: map[string]dbrouting.Role{
+				"slow_queries": dbrouting.RolePrimary,
+				"replication":  dbrouting.RolePrimary,
+			},
+		},
 		FeatureDetection: FeatureDetectionConfig{
 			Enabled:            true,
 			CacheDuration:      5 * time.Minute,
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 	
+	"github.com/database-intelligence/db-intel/components/internal/dbrouting"
 	"github.com/database-intelligence/db-intel/internal/featuredetector"
 	"github.com/database-intelligence/db-intel/internal/queryselector"
 	"github.com/database-intelligence/db-intel/internal/database"
@@ -29,12 +30,14 @@ type Receiver struct {
 	config          *Config
 	logger          *zap.Logger
 	db              *sql.DB
+	router          *dbrouting.Router[*sql.DB]
 	detector        featuredetector.Detector
 	selector        *queryselector.QuerySelector
 	metricsConsumer consumer.Metrics
 	logsConsumer    consumer.Logs
 	
 	// Collection state
+	startTime      time.Time
 	wg             sync.WaitGroup
 	cancel         context.CancelFunc
 	collectionTick *time.Ticker
@@ -70,6 +73,7 @@ func (r *Receiver) Start(ctx context.Context, host component.Host) error {
 	r.logger.Info("Starting enhanced SQL receiver",
 		zap.String("driver", r.config.Driver),
 		zap.String("datasource", r.config.getDatasourceMasked()))
+	r.startTime = time.Now()
 	
 	// Create connection pool configuration
 	poolConfig := database.DefaultConnectionPoolConfig()
@@ -87,13 +91,18 @@ func (r *Receiver) Start(ctx context.Context, host component.Host) error {
 		return fmt.Errorf("invalid connection pool configuration: %w", err)
 	}
 	
-	// Connect to database with secure connection pooling
-	db, err := database.OpenWithSecurePool(r.config.Driver, r.config.Datasource, poolConfig, r.logger)
+	// Connect to the primary, and the replica if one is configured, with
+	// secure connection pooling
+	router, err := dbrouting.Open(ctx, r.config.Datasource, &r.config.Routing,
+		func(ctx context.Context, datasource string) (*sql.DB, error) {
+			return database.OpenWithSecurePool(r.config.Driver, datasource, poolConfig, r.logger)
+		})
 	if err != nil {
 		return fmt.Errorf("failed to establish secure database connection: %w", err)
 	}
 	
-	r.db = db
+	r.router = router
+	r.db = router.Primary()
 	
 	// Create feature detector
 	detectorConfig := featuredetector.DetectionConfig{
@@ -170,9 +179,9 @@ func (r *Receiver) Shutdown(ctx context.Context) error {
 		return ctx.Err()
 	}
 	
-	// Close database
-	if r.db != nil {
-		if err := r.db.Close(); err != nil {
+	// Close database connections
+	if r.router != nil {
+		if err := r.router.Close(); err != nil {
 			r.logger.Warn("Error closing database", zap.Error(err))
 		}
 	}
@@ -310,6 +319,7 @@ func (r *Receiver) emitFeatureMetrics(ctx context.Context, features *featuredete
 	extensionMetric := sm.Metrics().AppendEmpty()
 	extensionMetric.SetName("db.feature.extension.available")
 	extensionMetric.SetDescription("Database extension availability")
+	extensionMetric.SetUnit("1")
 	gauge := extensionMetric.SetEmptyGauge()
 	
 	for name, ext := range features.Extensions {
@@ -327,6 +337,7 @@ func (r *Receiver) emitFeatureMetrics(ctx context.Context, features *featuredete
 	capabilityMetric := sm.Metrics().AppendEmpty()
 	capabilityMetric.SetName("db.feature.capability.available")
 	capabilityMetric.SetDescription("Database capability availability")
+	capabilityMetric.SetUnit("1")
 	capGauge := capabilityMetric.SetEmptyGauge()
 	
 	for name, cap := range features.Capabilities {
@@ -480,6 +491,7 @@ func (r *Receiver) executeMetricQuery(ctx context.Context, queryDef featuredetec
 	metric := sm.Metrics().AppendEmpty()
 	metric.SetName(queryDef.Name)
 	metric.SetDescription(queryDef.Description)
+	metric.SetUnit(metricUnit(queryDef.Unit))
 	
 	gauge := metric.SetEmptyGauge()
 	
@@ -644,6 +656,7 @@ func (r *Receiver) getFallbackMetricQueries() []*featuredetector.QueryDefinition
 			Name:        "pg_database_size",
 			SQL:         "SELECT pg_database_size(current_database()) as database_size_bytes",
 			Description: "Current database size in bytes",
+			Unit:        "By",
 			Priority:    100,
 		})
 		
@@ -651,6 +664,7 @@ func (r *Receiver) getFallbackMetricQueries() []*featuredetector.QueryDefinition
 			Name:        "pg_connection_count",
 			SQL:         "SELECT count(*) as active_connections FROM pg_stat_activity WHERE state = 'active'",
 			Description: "Number of active database connections",
+			Unit:        "{connection}",
 			Priority:    90,
 		})
 		
@@ -659,6 +673,7 @@ func (r *Receiver) getFallbackMetricQueries() []*featuredetector.QueryDefinition
 			Name:        "mysql_connection_count",
 			SQL:         "SELECT VARIABLE_VALUE as active_connections FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Threads_connected'",
 			Description: "Number of active MySQL connections",
+			Unit:        "{connection}",
 			Priority:    90,
 		})
 	}
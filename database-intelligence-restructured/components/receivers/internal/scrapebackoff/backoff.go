@@ -0,0 +1,107 @@
+// Package scrapebackoff widens a receiver's collection interval while its
+// collections fail. A receiver whose database is down otherwise queries it,
+// and logs the failure, every interval until it recovers, and adds to the
+// load of a database that is just coming back up.
+package scrapebackoff
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// MaxInterval caps the widened interval, so a recovered database is picked
+// up again within a few minutes. Receivers collecting less often than this
+// do not back off.
+const MaxInterval = 5 * time.Minute
+
+// Backoff skips collection ticks after consecutive failures: the effective
+// interval doubles with each failure, up to MaxInterval, and returns to the
+// configured interval after a success. While failing, the receiver reports
+// a recoverable error status, which health checks show as degraded.
+//
+// A Backoff is used by the receiver's collection loop only and is not safe
+// for concurrent use.
+type Backoff struct {
+	interval time.Duration
+	logger   *zap.Logger
+
+	// ReportStatus reports the receiver's status; nil disables it
+	ReportStatus func(*component.StatusEvent)
+
+	failures int
+	// skip is the number of ticks left to skip before the next collection
+	skip int
+}
+
+// New returns a Backoff for a receiver collecting every interval
+func New(interval time.Duration, logger *zap.Logger) *Backoff {
+	return &Backoff{interval: interval, logger: logger}
+}
+
+// Ready reports whether the collection due at this tick should run. It is
+// false for the ticks skipped while backing off.
+func (b *Backoff) Ready() bool {
+	if b.skip > 0 {
+		b.skip--
+		return false
+	}
+	return true
+}
+
+// Failure records a failed collection and returns how long until the next
+// one. The receiver logs the error itself, with the returned interval.
+func (b *Backoff) Failure(err error) time.Duration {
+	b.failures++
+	if b.failures == 1 {
+		b.report(component.NewRecoverableErrorEvent(err))
+	}
+
+	b.skip = b.multiple() - 1
+	return b.Interval()
+}
+
+// Success records a successful collection, restoring the configured
+// interval
+func (b *Backoff) Success() {
+	if b.failures == 0 {
+		return
+	}
+	b.logger.Info("Collection recovered; restoring the collection interval",
+		zap.Int("failed_collections", b.failures),
+		zap.Duration("collection_interval", b.interval))
+	b.failures, b.skip = 0, 0
+	b.report(component.NewStatusEvent(component.StatusOK))
+}
+
+// Failures returns the number of consecutive failed collections
+func (b *Backoff) Failures() int {
+	return b.failures
+}
+
+// Interval returns the current effective collection interval
+func (b *Backoff) Interval() time.Duration {
+	return time.Duration(b.multiple()) * b.interval
+}
+
+// multiple returns the effective interval in collection intervals:
+// 2^failures, capped so it does not exceed MaxInterval
+func (b *Backoff) multiple() int {
+	limit := 1
+	if b.interval > 0 && b.interval < MaxInterval {
+		limit = int(MaxInterval / b.interval)
+	}
+
+	multiple := 1
+	for i := 0; i < b.failures && multiple*2 <= limit; i++ {
+		multiple *= 2
+	}
+	return multiple
+}
+
+func (b *Backoff) report(event *component.StatusEvent) {
+	if b.ReportStatus != nil {
+		b.ReportStatus(event)
+	}
+}
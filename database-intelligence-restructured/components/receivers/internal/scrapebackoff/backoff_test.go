@@ -0,0 +1,95 @@
+package scrapebackoff
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// collectAt runs a collection loop over ticks and returns the ticks at which
+// collections ran. fail reports whether the collection at a tick fails.
+func collectAt(b *Backoff, ticks int, fail func(tick int) bool) []int {
+	var collected []int
+	for tick := 0; tick < ticks; tick++ {
+		if !b.Ready() {
+			continue
+		}
+		collected = append(collected, tick)
+		if fail(tick) {
+			b.Failure(errors.New("connection refused"))
+		} else {
+			b.Success()
+		}
+	}
+	return collected
+}
+
+func TestBackoffWidensWhileFailing(t *testing.T) {
+	b := New(10*time.Second, zap.NewNop())
+
+	// The gaps double, 20s, 40s, 80s, 160s, until doubling again would pass
+	// the 5m cap
+	collected := collectAt(b, 60, func(int) bool { return true })
+	assert.Equal(t, []int{0, 2, 6, 14, 30, 46}, collected)
+	assert.Equal(t, 6, b.Failures())
+	assert.Equal(t, 160*time.Second, b.Interval())
+}
+
+func TestBackoffResetsOnSuccess(t *testing.T) {
+	b := New(time.Minute, zap.NewNop())
+
+	// The database is down for the first ten minutes
+	collected := collectAt(b, 16, func(tick int) bool { return tick < 10 })
+	assert.Equal(t, []int{0, 2, 6, 10, 11, 12, 13, 14, 15}, collected)
+	assert.Zero(t, b.Failures())
+	assert.Equal(t, time.Minute, b.Interval())
+}
+
+func TestBackoffReturnsNextInterval(t *testing.T) {
+	b := New(time.Minute, zap.NewNop())
+	err := errors.New("connection refused")
+
+	assert.Equal(t, 2*time.Minute, b.Failure(err))
+	assert.Equal(t, 4*time.Minute, b.Failure(err))
+	// Doubling again would pass MaxInterval
+	assert.Equal(t, 4*time.Minute, b.Failure(err))
+}
+
+func TestBackoffLongInterval(t *testing.T) {
+	b := New(10*time.Minute, zap.NewNop())
+
+	// Collections already further apart than the cap are never skipped
+	collected := collectAt(b, 4, func(int) bool { return true })
+	assert.Equal(t, []int{0, 1, 2, 3}, collected)
+	assert.Equal(t, 10*time.Minute, b.Interval())
+}
+
+func TestBackoffReportsStatus(t *testing.T) {
+	b := New(time.Minute, zap.NewNop())
+	var events []*component.StatusEvent
+	b.ReportStatus = func(event *component.StatusEvent) {
+		events = append(events, event)
+	}
+
+	b.Success()
+	assert.Empty(t, events, "a healthy receiver reports nothing")
+
+	err := errors.New("connection refused")
+	b.Failure(err)
+	b.Failure(err)
+	require.Len(t, events, 1, "the degraded status is reported once")
+	assert.Equal(t, component.StatusRecoverableError, events[0].Status())
+	assert.Equal(t, err, events[0].Err())
+
+	b.Success()
+	require.Len(t, events, 2)
+	assert.Equal(t, component.StatusOK, events[1].Status())
+
+	b.Success()
+	assert.Len(t, events, 2)
+}
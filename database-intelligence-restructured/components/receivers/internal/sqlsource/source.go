@@ -0,0 +1,165 @@
+// Package sqlsource is how the database receivers query the database they
+// monitor: a small interface over database/sql that tests can stub, and the
+// settings every such receiver shares.
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Rows is the subset of *sql.Rows the receivers read, so tests can stub it
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// Source runs queries against the monitored database
+type Source interface {
+	Query(ctx context.Context, query string, args ...any) (Rows, error)
+	Close() error
+}
+
+// OpenFunc connects to a datasource. Receivers keep the one they use in a
+// field, so tests can replace the connection.
+type OpenFunc func(ctx context.Context, datasource string) (Source, error)
+
+// OpenPostgres connects to PostgreSQL
+func OpenPostgres(ctx context.Context, datasource string) (Source, error) {
+	return Open(ctx, "postgres", datasource)
+}
+
+// OpenMySQL connects to MySQL
+func OpenMySQL(ctx context.Context, datasource string) (Source, error) {
+	return Open(ctx, "mysql", datasource)
+}
+
+// Open connects with a database/sql driver, "postgres" or "mysql", and
+// checks the connection. With setup statements, every query runs in its own
+// read-only transaction after them, so settings applied with SET LOCAL hold
+// even if the pool reconnects.
+func Open(ctx context.Context, driverName, datasource string, setup ...string) (Source, error) {
+	db, err := sql.Open(driverName, datasource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Collections run one at a time, so a single connection is enough
+	db.SetMaxOpenConns(1)
+	return &dbSource{db: db, setup: setup}, nil
+}
+
+// dbSource is the Source backed by a database connection pool
+type dbSource struct {
+	db    *sql.DB
+	setup []string
+}
+
+func (s *dbSource) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	if len(s.setup) == 0 {
+		return s.db.QueryContext(ctx, query, args...)
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range s.setup {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	rs, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &txRows{Rows: rs, tx: tx}, nil
+}
+
+func (s *dbSource) Close() error {
+	return s.db.Close()
+}
+
+// txRows ends the query's transaction when the rows are closed
+type txRows struct {
+	*sql.Rows
+	tx *sql.Tx
+}
+
+func (r *txRows) Close() error {
+	err := r.Rows.Close()
+	// Nothing was written, so rolling back just releases the transaction
+	r.tx.Rollback()
+	return err
+}
+
+// QueryValue runs a query returning a single value and scans it into dest
+func QueryValue(ctx context.Context, source Source, query string, dest any) error {
+	rs, err := source.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	if !rs.Next() {
+		if err := rs.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rs.Scan(dest); err != nil {
+		return err
+	}
+	return rs.Err()
+}
+
+// insufficientPrivilege is the SQLSTATE of a permission denied error
+const insufficientPrivilege pq.ErrorCode = "42501"
+
+// IsInsufficientPrivilege reports whether err is PostgreSQL refusing access,
+// such as to a view in a schema the user cannot use
+func IsInsufficientPrivilege(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == insufficientPrivilege
+}
+
+// ValidateCollection checks the settings the receivers that poll with a
+// query share: a datasource, a positive collection_interval, and a
+// query_timeout that fits in it
+func ValidateCollection(datasource string, collectionInterval, queryTimeout time.Duration) error {
+	if datasource == "" {
+		return errors.New("datasource must be specified")
+	}
+
+	if collectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive, got %v", collectionInterval)
+	}
+
+	if queryTimeout <= 0 {
+		return fmt.Errorf("query_timeout must be positive, got %v", queryTimeout)
+	}
+
+	if queryTimeout > collectionInterval {
+		return fmt.Errorf("query_timeout (%v) cannot be greater than collection_interval (%v)",
+			queryTimeout, collectionInterval)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package sqlsource_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// valueSource answers every query with the same rows
+type valueSource struct {
+	rows [][]any
+	err  error
+}
+
+func (s *valueSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return sqlsourcetest.NewRows(s.rows), nil
+}
+
+func (s *valueSource) Close() error { return nil }
+
+func TestQueryValue(t *testing.T) {
+	var version int
+	require.NoError(t, sqlsource.QueryValue(context.Background(), &valueSource{rows: [][]any{{160000}}}, "SELECT 1", &version))
+	assert.Equal(t, 160000, version)
+
+	err := sqlsource.QueryValue(context.Background(), &valueSource{}, "SELECT 1", &version)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	queryErr := errors.New("connection reset by peer")
+	err = sqlsource.QueryValue(context.Background(), &valueSource{err: queryErr}, "SELECT 1", &version)
+	assert.ErrorIs(t, err, queryErr)
+}
+
+func TestIsInsufficientPrivilege(t *testing.T) {
+	assert.True(t, sqlsource.IsInsufficientPrivilege(&pq.Error{Code: "42501"}))
+	assert.False(t, sqlsource.IsInsufficientPrivilege(&pq.Error{Code: "42P01"}))
+	assert.False(t, sqlsource.IsInsufficientPrivilege(errors.New("permission denied")))
+}
+
+func TestValidateCollection(t *testing.T) {
+	tests := []struct {
+		name               string
+		datasource         string
+		collectionInterval time.Duration
+		queryTimeout       time.Duration
+		wantErr            string
+	}{
+		{
+			name:               "valid",
+			datasource:         "postgres://localhost:5432/postgres",
+			collectionInterval: time.Minute,
+			queryTimeout:       time.Minute,
+		},
+		{
+			name:               "missing datasource",
+			collectionInterval: time.Minute,
+			queryTimeout:       time.Second,
+			wantErr:            "datasource must be specified",
+		},
+		{
+			name:         "zero collection interval",
+			datasource:   "postgres://localhost:5432/postgres",
+			queryTimeout: time.Second,
+			wantErr:      "collection_interval must be positive",
+		},
+		{
+			name:               "zero query timeout",
+			datasource:         "postgres://localhost:5432/postgres",
+			collectionInterval: time.Minute,
+			wantErr:            "query_timeout must be positive",
+		},
+		{
+			name:               "query timeout above collection interval",
+			datasource:         "postgres://localhost:5432/postgres",
+			collectionInterval: 10 * time.Second,
+			queryTimeout:       time.Minute,
+			wantErr:            "query_timeout (1m0s) cannot be greater than collection_interval (10s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sqlsource.ValidateCollection(tt.datasource, tt.collectionInterval, tt.queryTimeout)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package sqlsourcetest has the stubs the database receivers' tests answer
+// queries with
+package sqlsourcetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Rows returns fixed values, converting them the way database/sql would for
+// destinations that implement sql.Scanner
+type Rows struct {
+	values [][]any
+	next   int
+}
+
+// NewRows returns Rows with one row per element of values
+func NewRows(values [][]any) *Rows {
+	return &Rows{values: values}
+}
+
+func (r *Rows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	row := r.values[r.next-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %d destination arguments, got %d", len(row), len(dest))
+	}
+	for i, v := range row {
+		if scanner, ok := dest[i].(sql.Scanner); ok {
+			if err := scanner.Scan(v); err != nil {
+				return err
+			}
+			continue
+		}
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *Rows) Err() error   { return nil }
+func (r *Rows) Close() error { return nil }
+
+// Lifecycle checks that a receiver connects to datasource on Start and shuts
+// down cleanly, and that Start fails when the database cannot be reached.
+// newReceiver returns a receiver that connects with open; source answers the
+// queries the receiver runs while it is up.
+func Lifecycle(t *testing.T, datasource string, source sqlsource.Source, newReceiver func(open sqlsource.OpenFunc) component.Component) {
+	t.Helper()
+
+	t.Run("start and shutdown", func(t *testing.T) {
+		var opened string
+		r := newReceiver(func(ctx context.Context, ds string) (sqlsource.Source, error) {
+			opened = ds
+			return source, nil
+		})
+
+		require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+		require.NoError(t, r.Shutdown(context.Background()))
+		assert.Equal(t, datasource, opened)
+	})
+
+	t.Run("database unreachable", func(t *testing.T) {
+		r := newReceiver(func(ctx context.Context, ds string) (sqlsource.Source, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		assert.EqualError(t, r.Start(context.Background(), componenttest.NewNopHost()), "connection refused")
+		require.NoError(t, r.Shutdown(context.Background()))
+	})
+}
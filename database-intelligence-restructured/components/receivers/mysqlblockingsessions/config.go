@@ -0,0 +1,48 @@
+package mysqlblockingsessions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the MySQL DSN, e.g. user:password@tcp(localhost:3306)/
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often lock waits are looked up
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each collection query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// MaxQueryLength truncates the query text attributes to this many bytes
+	MaxQueryLength int `mapstructure:"max_query_length"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	if cfg.MaxQueryLength <= 0 {
+		return fmt.Errorf("max_query_length must be positive, got %d", cfg.MaxQueryLength)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 30 * time.Second,
+		QueryTimeout:       2 * time.Second,
+		MaxQueryLength:     1024,
+	}
+}
@@ -0,0 +1,54 @@
+package mysqlblockingsessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "mysqlblockingsessions"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotBlockingSessions = errors.New("config is not for mysqlblockingsessions receiver")
+
+// NewFactory creates a new MySQL blocking sessions receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	bsCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotBlockingSessions
+	}
+
+	// Validate the configuration
+	if err := bsCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	rcv := newBlockingSessionsReceiver(bsCfg, settings.Logger, consumer)
+	rcv.backoff.ReportStatus = settings.ReportStatus
+	return rcv, nil
+}
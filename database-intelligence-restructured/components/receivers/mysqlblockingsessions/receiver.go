@@ -0,0 +1,222 @@
+package mysqlblockingsessions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// metricBlockingSessions follows postgres.blocking_sessions of the
+// pgblockingsessions receiver, with the same attributes, so dashboards can
+// query both with one pattern
+const metricBlockingSessions = "mysql.blocking_sessions"
+
+// blockingSessionsReceiver implements the receiver.Metrics interface
+type blockingSessionsReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Metrics
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	// lockWaitsUnavailable is set while performance_schema or its
+	// data_lock_waits table is missing so the warning is logged once rather
+	// than on every collection
+	lockWaitsUnavailable bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newBlockingSessionsReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *blockingSessionsReceiver {
+	return &blockingSessionsReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		openSource: sqlsource.OpenMySQL,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+// Start implements the receiver.Metrics interface
+func (r *blockingSessionsReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting MySQL blocking sessions receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Duration("query_timeout", r.config.QueryTimeout))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *blockingSessionsReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down MySQL blocking sessions receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect periodically looks up blocked sessions
+func (r *blockingSessionsReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.backoff.Ready() {
+				continue
+			}
+			md, err := r.scrape(ctx)
+			if err != nil {
+				r.logger.Error("Failed to collect blocking sessions", zap.Error(err),
+					zap.Duration("next_collection_in", r.backoff.Failure(err)))
+				continue
+			}
+			r.backoff.Success()
+			if md.MetricCount() == 0 {
+				continue
+			}
+			if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+				r.logger.Error("Failed to send blocking session metrics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// scrape reads the blocked/blocking pairs and converts them to metrics.
+// Without blocking it returns no metrics. When performance_schema is off, or
+// the server predates data_lock_waits, it returns no metrics and no error,
+// so the collector keeps running and picks the lock waits up once they are
+// available.
+func (r *blockingSessionsReceiver) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	var available bool
+	if err := sqlsource.QueryValue(ctx, r.source, lockWaitsAvailableQuery, &available); err != nil {
+		return pmetric.NewMetrics(), fmt.Errorf("failed to check for performance_schema.data_lock_waits: %w", err)
+	}
+	if !available {
+		if !r.lockWaitsUnavailable {
+			r.logger.Warn("performance_schema.data_lock_waits is unavailable; blocking session metrics are disabled until it is available",
+				zap.String("remediation", "use MySQL 8.0 or later with performance_schema=ON in my.cnf"))
+			r.lockWaitsUnavailable = true
+		}
+		return pmetric.NewMetrics(), nil
+	}
+	if r.lockWaitsUnavailable {
+		r.logger.Info("performance_schema.data_lock_waits is now available; collecting blocking session metrics")
+		r.lockWaitsUnavailable = false
+	}
+
+	pairs, err := queryBlockingPairs(ctx, r.source)
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+	return r.buildMetrics(pairs, pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// buildMetrics emits one data point per blocked/blocking pair
+func (r *blockingSessionsReceiver) buildMetrics(pairs []blockingPair, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	if len(pairs) == 0 {
+		return md
+	}
+
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "mysql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("mysqlblockingsessions_receiver")
+	sm.Scope().SetVersion("1.0.0")
+
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(metricBlockingSessions)
+	metric.SetDescription("Sessions waiting on a lock held by another session")
+	metric.SetUnit("{session}")
+	gauge := metric.SetEmptyGauge()
+
+	for _, p := range pairs {
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetIntValue(1)
+		dp.Attributes().PutInt("db.blocking.blocked_pid", p.BlockedPID)
+		dp.Attributes().PutInt("db.blocking.blocking_pid", p.BlockingPID)
+		dp.Attributes().PutStr("db.blocking.lock_type", p.LockType)
+		dp.Attributes().PutStr("db.blocking.blocked_query", cleanQuery(p.BlockedQuery, r.config.MaxQueryLength))
+		dp.Attributes().PutStr("db.blocking.blocking_query", cleanQuery(p.BlockingQuery, r.config.MaxQueryLength))
+		dp.Attributes().PutStr("db.name", p.Database)
+	}
+
+	return md
+}
+
+// cleanQuery collapses whitespace in query text and truncates it to maxLen
+// bytes without splitting a multi-byte character
+func cleanQuery(query string, maxLen int) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) <= maxLen {
+		return query
+	}
+
+	end := maxLen
+	for end > 0 && !utf8.RuneStart(query[end]) {
+		end--
+	}
+	return query[:end]
+}
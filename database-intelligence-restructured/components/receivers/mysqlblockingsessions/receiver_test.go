@@ -0,0 +1,217 @@
+package mysqlblockingsessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the availability and lock wait queries with fixed rows
+type stubSource struct {
+	lockWaitsAvailable bool
+	pairs              [][]any
+	err                error
+	queries            []string
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	s.queries = append(s.queries, query)
+	switch query {
+	case lockWaitsAvailableQuery:
+		return sqlsourcetest.NewRows([][]any{{s.lockWaitsAvailable}}), nil
+	case lockWaitsQuery:
+		if s.err != nil {
+			return nil, s.err
+		}
+		return sqlsourcetest.NewRows(s.pairs), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+// fixturePairs is a result set captured while one transaction held a row
+// lock that two others were waiting on, one of which was also queued behind
+// the other's table lock request. The blocker had finished its statement, so
+// INNODB_TRX has no query for it.
+var fixturePairs = [][]any{
+	{int64(31), int64(27), "shop", "record",
+		"UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		""},
+	{int64(34), int64(27), "shop", "record",
+		"DELETE FROM inventory WHERE sku = 'A-1'",
+		""},
+	{int64(34), int64(31), "shop", "table",
+		"DELETE FROM inventory\n WHERE sku = 'A-1'",
+		"UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'"},
+}
+
+func newTestReceiver(source *stubSource) *blockingSessionsReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newBlockingSessionsReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+func TestQueryBlockingPairs(t *testing.T) {
+	pairs, err := queryBlockingPairs(context.Background(), &stubSource{pairs: fixturePairs})
+	require.NoError(t, err)
+
+	assert.Equal(t, []blockingPair{
+		{
+			BlockedPID:   31,
+			BlockingPID:  27,
+			Database:     "shop",
+			LockType:     "record",
+			BlockedQuery: "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		},
+		{
+			BlockedPID:   34,
+			BlockingPID:  27,
+			Database:     "shop",
+			LockType:     "record",
+			BlockedQuery: "DELETE FROM inventory WHERE sku = 'A-1'",
+		},
+		{
+			BlockedPID:    34,
+			BlockingPID:   31,
+			Database:      "shop",
+			LockType:      "table",
+			BlockedQuery:  "DELETE FROM inventory\n WHERE sku = 'A-1'",
+			BlockingQuery: "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		},
+	}, pairs)
+}
+
+func TestQueryBlockingPairsScanError(t *testing.T) {
+	_, err := queryBlockingPairs(context.Background(), &stubSource{pairs: [][]any{{int64(1), int64(2)}}})
+	assert.ErrorContains(t, err, "failed to scan data_lock_waits row")
+}
+
+// TestScrapeEmitsBlockingSessions also keeps the attributes the same as the
+// pgblockingsessions receiver's, so one dashboard covers both databases
+func TestScrapeEmitsBlockingSessions(t *testing.T) {
+	r := newTestReceiver(&stubSource{lockWaitsAvailable: true, pairs: fixturePairs})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, md.MetricCount())
+
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"db.system":              "mysql",
+		"deployment.environment": "test",
+	}, rm.Resource().Attributes().AsRaw())
+
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "mysql.blocking_sessions", metric.Name())
+	assert.Equal(t, "{session}", metric.Unit())
+
+	points := metric.Gauge().DataPoints()
+	require.Equal(t, 3, points.Len())
+	for i := 0; i < points.Len(); i++ {
+		assert.Equal(t, int64(1), points.At(i).IntValue())
+	}
+
+	assert.Equal(t, map[string]any{
+		"db.blocking.blocked_pid":    int64(34),
+		"db.blocking.blocking_pid":   int64(31),
+		"db.blocking.lock_type":      "table",
+		"db.blocking.blocked_query":  "DELETE FROM inventory WHERE sku = 'A-1'",
+		"db.blocking.blocking_query": "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		"db.name":                    "shop",
+	}, points.At(2).Attributes().AsRaw())
+}
+
+func TestScrapeWithoutBlocking(t *testing.T) {
+	r := newTestReceiver(&stubSource{lockWaitsAvailable: true})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+}
+
+func TestScrapeWithLockWaitsUnavailable(t *testing.T) {
+	source := &stubSource{lockWaitsAvailable: false, pairs: fixturePairs}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.True(t, r.lockWaitsUnavailable)
+	assert.Equal(t, []string{lockWaitsAvailableQuery}, source.queries, "data_lock_waits must not be queried")
+
+	// Collection resumes once performance_schema is on
+	source.lockWaitsAvailable = true
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.False(t, r.lockWaitsUnavailable)
+	assert.Equal(t, 1, md.MetricCount())
+}
+
+func TestScrapeQueryError(t *testing.T) {
+	r := newTestReceiver(&stubSource{lockWaitsAvailable: true, err: errors.New("SELECT command denied to user 'monitor'")})
+
+	_, err := r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to query data_lock_waits: SELECT command denied to user 'monitor'")
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{}
+	sqlsourcetest.Lifecycle(t, "monitor:secret@tcp(localhost:3306)/", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestCleanQuery(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM orders", cleanQuery("SELECT *\n\tFROM  orders ", 100))
+	assert.Equal(t, "SELECT 'h", cleanQuery("SELECT 'héllo'", 10), "a multi-byte character is kept whole")
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "zero max query length",
+			modify:  func(cfg *Config) { cfg.MaxQueryLength = 0 },
+			wantErr: "max_query_length must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package mysqlblockingsessions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+const (
+	// lockWaitsAvailableQuery reports whether performance_schema is on and
+	// has the data_lock_waits table, which replaced
+	// information_schema.innodb_lock_waits in MySQL 8.0
+	lockWaitsAvailableQuery = `SELECT @@global.performance_schema = 1 AND EXISTS (
+  SELECT 1 FROM information_schema.TABLES
+  WHERE TABLE_SCHEMA = 'performance_schema' AND TABLE_NAME = 'data_lock_waits')`
+
+	// lockWaitsQuery joins every InnoDB lock wait with the waiting lock and
+	// both transactions, the join sys.innodb_lock_waits makes on MySQL 8.0,
+	// so the process IDs can be looked up there and in SHOW PROCESSLIST. A
+	// blocker can hold several locks the request conflicts with, so the
+	// result is reduced to one row per blocked/blocking pair.
+	lockWaitsQuery = `SELECT DISTINCT
+  waiting_trx.trx_mysql_thread_id,
+  blocking_trx.trx_mysql_thread_id,
+  COALESCE(waiting_lock.OBJECT_SCHEMA, ''),
+  LOWER(waiting_lock.LOCK_TYPE),
+  COALESCE(waiting_trx.trx_query, ''),
+  COALESCE(blocking_trx.trx_query, '')
+FROM performance_schema.data_lock_waits w
+JOIN performance_schema.data_locks waiting_lock
+  ON waiting_lock.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID
+JOIN information_schema.INNODB_TRX waiting_trx
+  ON waiting_trx.trx_id = w.REQUESTING_ENGINE_TRANSACTION_ID
+JOIN information_schema.INNODB_TRX blocking_trx
+  ON blocking_trx.trx_id = w.BLOCKING_ENGINE_TRANSACTION_ID
+ORDER BY 1, 2`
+)
+
+// blockingPair is one session waiting on a lock held by another
+type blockingPair struct {
+	BlockedPID    int64
+	BlockingPID   int64
+	Database      string
+	LockType      string
+	BlockedQuery  string
+	BlockingQuery string
+}
+
+// queryBlockingPairs reads the blocked/blocking session pairs
+func queryBlockingPairs(ctx context.Context, source sqlsource.Source) ([]blockingPair, error) {
+	rs, err := source.Query(ctx, lockWaitsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data_lock_waits: %w", err)
+	}
+	defer rs.Close()
+
+	var pairs []blockingPair
+	for rs.Next() {
+		var p blockingPair
+		if err := rs.Scan(&p.BlockedPID, &p.BlockingPID, &p.Database, &p.LockType, &p.BlockedQuery, &p.BlockingQuery); err != nil {
+			return nil, fmt.Errorf("failed to scan data_lock_waits row: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read data_lock_waits rows: %w", err)
+	}
+	return pairs, nil
+}
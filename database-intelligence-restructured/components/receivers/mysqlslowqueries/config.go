@@ -0,0 +1,66 @@
+package mysqlslowqueries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the MySQL DSN, e.g. user:password@tcp(localhost:3306)/
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often the statement digest table is queried
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each performance_schema query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// MinMeanExecTime only reports digests whose mean execution time is at
+	// least this long
+	MinMeanExecTime time.Duration `mapstructure:"min_mean_exec_time"`
+
+	// MaxStatements caps how many of the slowest digests are reported per
+	// collection, which bounds metric cardinality
+	MaxStatements int `mapstructure:"max_statements"`
+
+	// MaxStatementLength truncates db.statement to this many bytes
+	MaxStatementLength int `mapstructure:"max_statement_length"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	if cfg.MinMeanExecTime < 0 {
+		return fmt.Errorf("min_mean_exec_time cannot be negative, got %v", cfg.MinMeanExecTime)
+	}
+
+	if cfg.MaxStatements <= 0 {
+		return fmt.Errorf("max_statements must be positive, got %d", cfg.MaxStatements)
+	}
+
+	if cfg.MaxStatementLength <= 0 {
+		return fmt.Errorf("max_statement_length must be positive, got %d", cfg.MaxStatementLength)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 60 * time.Second,
+		QueryTimeout:       10 * time.Second,
+		MinMeanExecTime:    100 * time.Millisecond,
+		MaxStatements:      100,
+		MaxStatementLength: 4096,
+	}
+}
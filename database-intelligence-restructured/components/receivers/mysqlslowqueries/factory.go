@@ -0,0 +1,54 @@
+package mysqlslowqueries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "mysqlslowqueries"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotSlowQueries = errors.New("config is not for mysqlslowqueries receiver")
+
+// NewFactory creates a new MySQL statement digest slow query receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	sqCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotSlowQueries
+	}
+
+	// Validate the configuration
+	if err := sqCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	rcv := newSlowQueriesReceiver(sqCfg, settings.Logger, consumer)
+	rcv.backoff.ReportStatus = settings.ReportStatus
+	return rcv, nil
+}
@@ -0,0 +1,77 @@
+package mysqlslowqueries
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// normalizeStatement prepares a DIGEST_TEXT for use as the db.statement
+// attribute. MySQL has already replaced literals with ? and removed
+// comments; this collapses any remaining whitespace and truncates the result
+// to maxLen bytes.
+func normalizeStatement(digestText string, maxLen int) string {
+	normalized := strings.Join(strings.Fields(digestText), " ")
+	return truncate(strings.TrimRight(normalized, "; "), maxLen)
+}
+
+// truncate shortens s to at most maxLen bytes without splitting a rune
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// dmlOperations are the operations reported as db.operation; everything else
+// is reported as OTHER
+var dmlOperations = map[string]bool{
+	"SELECT":  true,
+	"INSERT":  true,
+	"UPDATE":  true,
+	"DELETE":  true,
+	"REPLACE": true,
+}
+
+// statementOperation returns the operation of a normalized statement. For
+// statements starting with a WITH clause it is the operation of the main
+// statement after the common table expressions.
+func statementOperation(statement string) string {
+	// Digest text separates parentheses from the words they enclose
+	words := strings.Fields(statement)
+	for len(words) > 0 && strings.Trim(words[0], "(") == "" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return "OTHER"
+	}
+
+	first := strings.ToUpper(strings.TrimLeft(words[0], "("))
+	if dmlOperations[first] {
+		return first
+	}
+	if first != "WITH" {
+		return "OTHER"
+	}
+
+	// Skip the parenthesized CTE bodies and take the first operation
+	// keyword at the top level
+	depth := 0
+	for _, word := range words[1:] {
+		opening := strings.Count(word, "(")
+		closing := strings.Count(word, ")")
+		if depth == 0 && opening == 0 {
+			if op := strings.ToUpper(word); dmlOperations[op] {
+				return op
+			}
+		}
+		depth += opening - closing
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return "OTHER"
+}
@@ -0,0 +1,258 @@
+package mysqlslowqueries
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Metric names, following the postgres.slow_queries.* metrics of the
+// pgslowqueries receiver for the measurements both databases provide
+const (
+	metricCount        = "mysql.slow_queries.count"
+	metricElapsedTime  = "mysql.slow_queries.elapsed_time"
+	metricTotalTime    = "mysql.slow_queries.total_time"
+	metricRowsExamined = "mysql.slow_queries.rows_examined"
+	metricRowsSent     = "mysql.slow_queries.rows_sent"
+)
+
+// MetricNames returns the names of the metrics the receiver emits
+func MetricNames() []string {
+	return []string{metricCount, metricElapsedTime, metricTotalTime, metricRowsExamined, metricRowsSent}
+}
+
+// slowQueriesReceiver implements the receiver.Metrics interface
+type slowQueriesReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Metrics
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	startTime pcommon.Timestamp
+	// digestsUnavailable is set while performance_schema or its digest
+	// consumer is disabled so the warning is logged once rather than on
+	// every collection
+	digestsUnavailable bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newSlowQueriesReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *slowQueriesReceiver {
+	return &slowQueriesReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		openSource: sqlsource.OpenMySQL,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+// Start implements the receiver.Metrics interface
+func (r *slowQueriesReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting MySQL statement digest slow query receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Duration("min_mean_exec_time", r.config.MinMeanExecTime),
+		zap.Int("max_statements", r.config.MaxStatements))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+	r.startTime = pcommon.NewTimestampFromTime(time.Now())
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *slowQueriesReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down MySQL statement digest slow query receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect periodically queries the statement digest table
+func (r *slowQueriesReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.backoff.Ready() {
+				continue
+			}
+			md, err := r.scrape(ctx)
+			if err != nil {
+				r.logger.Error("Failed to collect slow queries", zap.Error(err),
+					zap.Duration("next_collection_in", r.backoff.Failure(err)))
+				continue
+			}
+			r.backoff.Success()
+			if md.MetricCount() == 0 {
+				continue
+			}
+			if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+				r.logger.Error("Failed to send slow query metrics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// scrape reads the slowest statement digests and converts them to metrics.
+// When performance_schema or the statements_digest consumer is disabled it
+// returns no metrics and no error, so the collector keeps running and picks
+// the digests up once they are enabled.
+func (r *slowQueriesReceiver) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	var available bool
+	if err := sqlsource.QueryValue(ctx, r.source, digestsAvailableQuery, &available); err != nil {
+		return pmetric.NewMetrics(), fmt.Errorf("failed to check for performance_schema statement digests: %w", err)
+	}
+	if !available {
+		if !r.digestsUnavailable {
+			r.logger.Warn("performance_schema statement digests are disabled; slow query metrics are disabled until they are enabled",
+				zap.String("remediation", "set performance_schema=ON in my.cnf and run UPDATE performance_schema.setup_consumers SET ENABLED = 'YES' WHERE NAME = 'statements_digest'"))
+			r.digestsUnavailable = true
+		}
+		return pmetric.NewMetrics(), nil
+	}
+	if r.digestsUnavailable {
+		r.logger.Info("performance_schema statement digests are now enabled; collecting slow query metrics")
+		r.digestsUnavailable = false
+	}
+
+	minMeanPs := r.config.MinMeanExecTime.Nanoseconds() * 1000
+	stats, err := querySlowDigests(ctx, r.source, minMeanPs, r.config.MaxStatements)
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+
+	return r.buildMetrics(stats, pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// buildMetrics converts digest statistics to metrics
+func (r *slowQueriesReceiver) buildMetrics(stats []digestStats, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	if len(stats) == 0 {
+		return md
+	}
+
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "mysql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("mysqlslowqueries_receiver")
+	sm.Scope().SetVersion("1.0.0")
+
+	count := newCumulativeSum(sm, metricCount, "Number of times the statement was executed", "{call}")
+	elapsed := newGauge(sm, metricElapsedTime, "Mean execution time of the statement", "ms")
+	total := newCumulativeSum(sm, metricTotalTime, "Total execution time of the statement", "ms")
+	examined := newGauge(sm, metricRowsExamined, "Mean rows examined per execution", "{row}")
+	sent := newGauge(sm, metricRowsSent, "Mean rows sent to the client per execution", "{row}")
+
+	for _, s := range stats {
+		statement := normalizeStatement(s.DigestText, r.config.MaxStatementLength)
+		attrs := pcommon.NewMap()
+		attrs.PutStr("db.mysql.digest", s.Digest)
+		attrs.PutStr("db.statement", statement)
+		attrs.PutStr("db.name", s.Schema)
+		attrs.PutStr("db.operation", statementOperation(statement))
+
+		dp := count.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(r.startTime)
+		dp.SetTimestamp(now)
+		dp.SetIntValue(s.Calls)
+		attrs.CopyTo(dp.Attributes())
+
+		dp = total.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(r.startTime)
+		dp.SetTimestamp(now)
+		dp.SetDoubleValue(s.TotalExecTimeMs)
+		attrs.CopyTo(dp.Attributes())
+
+		addGaugePoint(elapsed, s.MeanExecTimeMs, now, attrs)
+		addGaugePoint(examined, s.MeanRowsExamined, now, attrs)
+		addGaugePoint(sent, s.MeanRowsSent, now, attrs)
+	}
+
+	return md
+}
+
+func newCumulativeSum(sm pmetric.ScopeMetrics, name, description, unit string) pmetric.Sum {
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	sum := metric.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.SetIsMonotonic(true)
+	return sum
+}
+
+func newGauge(sm pmetric.ScopeMetrics, name, description, unit string) pmetric.Gauge {
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	return metric.SetEmptyGauge()
+}
+
+func addGaugePoint(gauge pmetric.Gauge, value float64, now pcommon.Timestamp, attrs pcommon.Map) {
+	dp := gauge.DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetDoubleValue(value)
+	attrs.CopyTo(dp.Attributes())
+}
@@ -0,0 +1,323 @@
+package mysqlslowqueries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/pgslowqueries"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers queries by their first distinguishing fragment
+type stubSource struct {
+	digestsAvailable bool
+	digests          [][]any
+	queries          []string
+	args             [][]any
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+	switch {
+	case query == digestsAvailableQuery:
+		return sqlsourcetest.NewRows([][]any{{s.digestsAvailable}}), nil
+	case strings.Contains(query, "FROM performance_schema.events_statements_summary_by_digest"):
+		return sqlsourcetest.NewRows(s.digests), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+func newTestReceiver(source *stubSource) *slowQueriesReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newSlowQueriesReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	r.startTime = pcommon.NewTimestampFromTime(time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC))
+	return r
+}
+
+func findMetric(t *testing.T, md pmetric.Metrics, name string) pmetric.Metric {
+	t.Helper()
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i)
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return pmetric.NewMetric()
+}
+
+// digestFixture is a digest table as performance_schema returns it
+var digestFixture = [][]any{
+	{"3f1b1f2c9d5e", "SELECT * FROM `orders` WHERE `customer_id` = ? ", "shop", int64(1200), 300600.0, 250.5, 4200.0, 12.0},
+	{"a7c04e11", "UPDATE `inventory` SET `qty` = `qty` - ? WHERE `sku` = ?", nil, int64(40), 4800.0, 120.0, 1.0, 0.0},
+}
+
+func TestScrapeEmitsSlowQueryMetrics(t *testing.T) {
+	source := &stubSource{digestsAvailable: true, digests: digestFixture}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	resource := md.ResourceMetrics().At(0).Resource().Attributes()
+	system, _ := resource.Get("db.system")
+	assert.Equal(t, "mysql", system.Str())
+	env, _ := resource.Get("deployment.environment")
+	assert.Equal(t, "test", env.Str())
+
+	count := findMetric(t, md, metricCount)
+	assert.Equal(t, "{call}", count.Unit())
+	require.Equal(t, pmetric.MetricTypeSum, count.Type())
+	assert.True(t, count.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, count.Sum().AggregationTemporality())
+	require.Equal(t, 2, count.Sum().DataPoints().Len())
+
+	dp := count.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(1200), dp.IntValue())
+	assert.Equal(t, r.startTime, dp.StartTimestamp())
+	assert.Equal(t, map[string]any{
+		"db.mysql.digest": "3f1b1f2c9d5e",
+		"db.statement":    "SELECT * FROM `orders` WHERE `customer_id` = ?",
+		"db.name":         "shop",
+		"db.operation":    "SELECT",
+	}, dp.Attributes().AsRaw())
+
+	total := findMetric(t, md, metricTotalTime)
+	assert.Equal(t, "ms", total.Unit())
+	require.Equal(t, pmetric.MetricTypeSum, total.Type())
+	assert.True(t, total.Sum().IsMonotonic())
+	assert.Equal(t, 300600.0, total.Sum().DataPoints().At(0).DoubleValue())
+
+	elapsed := findMetric(t, md, metricElapsedTime)
+	assert.Equal(t, "ms", elapsed.Unit())
+	assert.Equal(t, 250.5, elapsed.Gauge().DataPoints().At(0).DoubleValue())
+
+	examined := findMetric(t, md, metricRowsExamined)
+	assert.Equal(t, "{row}", examined.Unit())
+	assert.Equal(t, 4200.0, examined.Gauge().DataPoints().At(0).DoubleValue())
+
+	sent := findMetric(t, md, metricRowsSent)
+	second := sent.Gauge().DataPoints().At(1)
+	assert.Equal(t, 0.0, second.DoubleValue())
+	assert.Equal(t, map[string]any{
+		"db.mysql.digest": "a7c04e11",
+		"db.statement":    "UPDATE `inventory` SET `qty` = `qty` - ? WHERE `sku` = ?",
+		"db.name":         "",
+		"db.operation":    "UPDATE",
+	}, second.Attributes().AsRaw())
+
+	// The mean execution time threshold is passed in picoseconds
+	last := len(source.args) - 1
+	assert.Equal(t, []any{int64(100_000_000_000), 100}, source.args[last])
+}
+
+// metricSemantics is the unit and aggregation a metric is declared with
+type metricSemantics struct {
+	unit        string
+	metricType  pmetric.MetricType
+	temporality pmetric.AggregationTemporality
+}
+
+func TestMetricUnits(t *testing.T) {
+	r := newTestReceiver(&stubSource{digestsAvailable: true, digests: digestFixture})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	// Counters are cumulative since the receiver started; per-digest
+	// averages are gauges
+	want := map[string]metricSemantics{
+		metricCount:        {"{call}", pmetric.MetricTypeSum, pmetric.AggregationTemporalityCumulative},
+		metricTotalTime:    {"ms", pmetric.MetricTypeSum, pmetric.AggregationTemporalityCumulative},
+		metricElapsedTime:  {"ms", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+		metricRowsExamined: {"{row}", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+		metricRowsSent:     {"{row}", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+	}
+
+	got := make(map[string]metricSemantics)
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		m := metrics.At(i)
+		s := metricSemantics{unit: m.Unit(), metricType: m.Type()}
+		if m.Type() == pmetric.MetricTypeSum {
+			assert.True(t, m.Sum().IsMonotonic(), m.Name())
+			s.temporality = m.Sum().AggregationTemporality()
+		}
+		got[m.Name()] = s
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestScrapeWithDigestsDisabled(t *testing.T) {
+	source := &stubSource{digestsAvailable: false}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.True(t, r.digestsUnavailable)
+	assert.Equal(t, []string{digestsAvailableQuery}, source.queries, "the digest table must not be queried")
+
+	// Collection resumes once the digests are enabled
+	source.digestsAvailable = true
+	source.digests = digestFixture[:1]
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.False(t, r.digestsUnavailable)
+	assert.Equal(t, 5, md.MetricCount())
+}
+
+// TestMetricNamesMatchPostgreSQL keeps the two slow query receivers
+// interchangeable in dashboards: measurements both databases provide must
+// have the same name after the system prefix, and share the attributes
+// that are not database specific.
+func TestMetricNamesMatchPostgreSQL(t *testing.T) {
+	// pg_stat_statements block counters have no digest table equivalent
+	noMySQLEquivalent := map[string]bool{
+		"slow_queries.disk_reads":  true,
+		"slow_queries.disk_writes": true,
+	}
+
+	mysqlNames := make(map[string]bool)
+	for _, name := range MetricNames() {
+		require.True(t, strings.HasPrefix(name, "mysql.slow_queries."), name)
+		mysqlNames[strings.TrimPrefix(name, "mysql.")] = true
+	}
+
+	shared := 0
+	for _, name := range pgslowqueries.MetricNames() {
+		require.True(t, strings.HasPrefix(name, "postgres.slow_queries."), name)
+		suffix := strings.TrimPrefix(name, "postgres.")
+		if noMySQLEquivalent[suffix] {
+			assert.False(t, mysqlNames[suffix], "%s is listed as PostgreSQL only", suffix)
+			continue
+		}
+		assert.True(t, mysqlNames[suffix], "no MySQL metric matches %s", name)
+		shared++
+	}
+	assert.Equal(t, 2, shared)
+
+	// Every emitted metric is listed, and shares the semantic convention attributes
+	md, err := newTestReceiver(&stubSource{digestsAvailable: true, digests: digestFixture}).scrape(context.Background())
+	require.NoError(t, err)
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, len(MetricNames()), metrics.Len())
+	for i := 0; i < metrics.Len(); i++ {
+		assert.Contains(t, MetricNames(), metrics.At(i).Name())
+	}
+	attrs := findMetric(t, md, metricElapsedTime).Gauge().DataPoints().At(0).Attributes()
+	for _, key := range []string{"db.statement", "db.name", "db.operation"} {
+		_, ok := attrs.Get(key)
+		assert.True(t, ok, "missing %s", key)
+	}
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{digestsAvailable: true}
+	sqlsourcetest.Lifecycle(t, "monitor:secret@tcp(localhost:3306)/", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestNormalizeStatement(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "collapses whitespace",
+			text:   "SELECT  *\nFROM `users` WHERE `id` = ? ;",
+			maxLen: 100,
+			want:   "SELECT * FROM `users` WHERE `id` = ?",
+		},
+		{
+			name:   "truncates on a rune boundary",
+			text:   "SELECT 'héllo'",
+			maxLen: 10,
+			want:   "SELECT 'h",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeStatement(tt.text, tt.maxLen))
+		})
+	}
+}
+
+func TestStatementOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT ?":                                         "SELECT",
+		"REPLACE INTO `t` VALUES (?)":                      "REPLACE",
+		"( SELECT ? ) UNION ( SELECT ? )":                  "SELECT",
+		"WITH `recent` AS ( SELECT `id` FROM `t` ) DELETE": "DELETE",
+		"SHOW GLOBAL STATUS":                               "OTHER",
+		"":                                                 "OTHER",
+	}
+
+	for statement, want := range tests {
+		assert.Equal(t, want, statementOperation(statement), statement)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "negative minimum mean execution time",
+			modify:  func(cfg *Config) { cfg.MinMeanExecTime = -time.Millisecond },
+			wantErr: "min_mean_exec_time cannot be negative",
+		},
+		{
+			name:    "zero max statement length",
+			modify:  func(cfg *Config) { cfg.MaxStatementLength = 0 },
+			wantErr: "max_statement_length must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package mysqlslowqueries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+const (
+	// digestsAvailableQuery reports whether performance_schema is on and the
+	// statements_digest consumer is filling the digest table. With
+	// performance_schema off the setup tables exist but are empty.
+	digestsAvailableQuery = `SELECT @@global.performance_schema = 1 AND EXISTS (
+  SELECT 1 FROM performance_schema.setup_consumers
+  WHERE NAME = 'statements_digest' AND ENABLED = 'YES')`
+
+	// slowDigestsQuery reads one row per schema and statement digest. Timer
+	// columns are in picoseconds. The row with a NULL digest counts
+	// statements that did not fit in the table and is skipped.
+	slowDigestsQuery = `SELECT
+  DIGEST,
+  DIGEST_TEXT,
+  SCHEMA_NAME,
+  COUNT_STAR,
+  SUM_TIMER_WAIT / 1000000000,
+  AVG_TIMER_WAIT / 1000000000,
+  SUM_ROWS_EXAMINED / COUNT_STAR,
+  SUM_ROWS_SENT / COUNT_STAR
+FROM performance_schema.events_statements_summary_by_digest
+WHERE DIGEST IS NOT NULL AND COUNT_STAR > 0 AND AVG_TIMER_WAIT >= ?
+ORDER BY AVG_TIMER_WAIT DESC
+LIMIT ?`
+)
+
+// digestStats is one statement digest's performance_schema counters
+type digestStats struct {
+	Digest     string
+	DigestText string
+	// Schema is the default database the statements ran in
+	Schema string
+	Calls  int64
+	// TotalExecTimeMs and MeanExecTimeMs are execution times in milliseconds
+	TotalExecTimeMs float64
+	MeanExecTimeMs  float64
+	// MeanRowsExamined and MeanRowsSent are rows per call
+	MeanRowsExamined float64
+	MeanRowsSent     float64
+}
+
+// querySlowDigests returns the slowest digests by mean execution time
+func querySlowDigests(ctx context.Context, source sqlsource.Source, minMeanExecTimePs int64, limit int) ([]digestStats, error) {
+	rs, err := source.Query(ctx, slowDigestsQuery, minMeanExecTimePs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events_statements_summary_by_digest: %w", err)
+	}
+	defer rs.Close()
+
+	var stats []digestStats
+	for rs.Next() {
+		var s digestStats
+		var digestText, schema sql.NullString
+		if err := rs.Scan(&s.Digest, &digestText, &schema, &s.Calls, &s.TotalExecTimeMs,
+			&s.MeanExecTimeMs, &s.MeanRowsExamined, &s.MeanRowsSent); err != nil {
+			return nil, fmt.Errorf("failed to scan events_statements_summary_by_digest row: %w", err)
+		}
+		s.DigestText = digestText.String
+		s.Schema = schema.String
+		stats = append(stats, s)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events_statements_summary_by_digest rows: %w", err)
+	}
+	return stats, nil
+}
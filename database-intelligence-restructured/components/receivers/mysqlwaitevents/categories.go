@@ -0,0 +1,79 @@
+package mysqlwaitevents
+
+import "strings"
+
+// otherCategory is used for instrument classes no rule matches, e.g. classes
+// added in a newer MySQL release
+const otherCategory = "Other"
+
+// CategoryRule maps wait events to a category. WaitEventType is the
+// instrument class, e.g. io/file or synch/mutex. An empty WaitEvent matches
+// every event of the class; otherwise it is the full instrument name.
+type CategoryRule struct {
+	WaitEventType string `mapstructure:"wait_event_type"`
+	WaitEvent     string `mapstructure:"wait_event"`
+	Category      string `mapstructure:"category"`
+}
+
+// defaultCategoryRules map MySQL's instrument classes to the categories the
+// pgwaitevents receiver reports, so one dashboard can break down wait time
+// for both databases. Rules for specific events come first so they take
+// precedence over the class's rule.
+var defaultCategoryRules = []CategoryRule{
+	// The listener threads wait on these sockets for new connections, like
+	// PostgreSQL's background processes wait for work
+	{WaitEventType: "io/socket", WaitEvent: "wait/io/socket/sql/server_tcpip_socket", Category: "Activity"},
+	{WaitEventType: "io/socket", WaitEvent: "wait/io/socket/sql/server_unix_socket", Category: "Activity"},
+
+	{WaitEventType: "io/file", Category: "IO"},
+	{WaitEventType: "io/table", Category: "IO"},
+	{WaitEventType: "io/socket", Category: "Client"},
+	{WaitEventType: "lock/table", Category: "Lock"},
+	{WaitEventType: "lock/metadata", Category: "Lock"},
+	// Mutexes and read/write locks guard in-memory structures, like
+	// PostgreSQL's lightweight locks
+	{WaitEventType: "synch/mutex", Category: "LWLock"},
+	{WaitEventType: "synch/rwlock", Category: "LWLock"},
+	{WaitEventType: "synch/sxlock", Category: "LWLock"},
+	{WaitEventType: "synch/prlock", Category: "LWLock"},
+	// Condition waits are threads signalling each other
+	{WaitEventType: "synch/cond", Category: "IPC"},
+}
+
+// categoryMapper assigns categories to wait events using an ordered rule
+// table; the first matching rule wins
+type categoryMapper struct {
+	rules []CategoryRule
+}
+
+// newCategoryMapper returns a mapper that checks overrides before the
+// default rules
+func newCategoryMapper(overrides []CategoryRule) *categoryMapper {
+	rules := make([]CategoryRule, 0, len(overrides)+len(defaultCategoryRules))
+	rules = append(rules, overrides...)
+	rules = append(rules, defaultCategoryRules...)
+	return &categoryMapper{rules: rules}
+}
+
+// category returns the category of a wait event. Matching ignores case.
+func (m *categoryMapper) category(waitEventType, waitEvent string) string {
+	for _, rule := range m.rules {
+		if !strings.EqualFold(rule.WaitEventType, waitEventType) {
+			continue
+		}
+		if rule.WaitEvent == "" || strings.EqualFold(rule.WaitEvent, waitEvent) {
+			return rule.Category
+		}
+	}
+	return otherCategory
+}
+
+// eventType returns the instrument class of a wait event name:
+// wait/io/file/innodb/innodb_data_file is of class io/file
+func eventType(waitEvent string) string {
+	parts := strings.SplitN(strings.TrimPrefix(waitEvent, "wait/"), "/", 3)
+	if len(parts) < 2 {
+		return parts[0]
+	}
+	return parts[0] + "/" + parts[1]
+}
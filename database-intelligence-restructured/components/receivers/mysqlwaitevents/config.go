@@ -0,0 +1,53 @@
+package mysqlwaitevents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the MySQL DSN, e.g. user:password@tcp(localhost:3306)/
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often the wait event summary is read
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each performance_schema query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// CategoryOverrides are checked before the default category rules, so
+	// they can recategorize single events or whole instrument classes
+	CategoryOverrides []CategoryRule `mapstructure:"category_overrides"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	for i, rule := range cfg.CategoryOverrides {
+		if rule.WaitEventType == "" {
+			return fmt.Errorf("category_overrides[%d]: wait_event_type is required", i)
+		}
+		if rule.Category == "" {
+			return fmt.Errorf("category_overrides[%d]: category is required", i)
+		}
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 10 * time.Second,
+		QueryTimeout:       5 * time.Second,
+	}
+}
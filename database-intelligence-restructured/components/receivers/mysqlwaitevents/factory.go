@@ -0,0 +1,54 @@
+package mysqlwaitevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "mysqlwaitevents"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotWaitEvents = errors.New("config is not for mysqlwaitevents receiver")
+
+// NewFactory creates a new MySQL wait events receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	weCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotWaitEvents
+	}
+
+	// Validate the configuration
+	if err := weCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	rcv := newWaitEventsReceiver(weCfg, settings.Logger, consumer)
+	rcv.backoff.ReportStatus = settings.ReportStatus
+	return rcv, nil
+}
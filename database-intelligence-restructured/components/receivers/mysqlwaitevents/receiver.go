@@ -0,0 +1,235 @@
+package mysqlwaitevents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// metricWaitEvents follows postgres.wait_events of the pgwaitevents receiver,
+// with the same attributes, so dashboards can query both with one pattern
+const metricWaitEvents = "mysql.wait_events"
+
+// waitEventsReceiver implements the receiver.Metrics interface
+type waitEventsReceiver struct {
+	config     *Config
+	logger     *zap.Logger
+	consumer   consumer.Metrics
+	categories *categoryMapper
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	// previous holds the cumulative wait time of each event at the last
+	// collection; nil until the first collection has set the baseline
+	previous map[string]uint64
+	// waitsUnavailable is set while performance_schema or its wait
+	// instruments are disabled so the warning is logged once rather than on
+	// every collection
+	waitsUnavailable bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newWaitEventsReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *waitEventsReceiver {
+	return &waitEventsReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		categories: newCategoryMapper(cfg.CategoryOverrides),
+		openSource: sqlsource.OpenMySQL,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+// Start implements the receiver.Metrics interface
+func (r *waitEventsReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting MySQL wait events receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Int("category_overrides", len(r.config.CategoryOverrides)))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *waitEventsReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down MySQL wait events receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect periodically reads the wait event summary
+func (r *waitEventsReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.backoff.Ready() {
+				continue
+			}
+			md, err := r.scrape(ctx)
+			if err != nil {
+				r.logger.Error("Failed to collect wait events", zap.Error(err),
+					zap.Duration("next_collection_in", r.backoff.Failure(err)))
+				continue
+			}
+			r.backoff.Success()
+			if md.MetricCount() == 0 {
+				continue
+			}
+			if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+				r.logger.Error("Failed to send wait event metrics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// scrape reads the wait event summary and converts the time waited since the
+// previous collection to metrics. When performance_schema or its wait
+// instruments are disabled it returns no metrics and no error, so the
+// collector keeps running and picks the waits up once they are enabled.
+func (r *waitEventsReceiver) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	var available bool
+	if err := sqlsource.QueryValue(ctx, r.source, waitsAvailableQuery, &available); err != nil {
+		return pmetric.NewMetrics(), fmt.Errorf("failed to check for performance_schema wait instruments: %w", err)
+	}
+	if !available {
+		if !r.waitsUnavailable {
+			r.logger.Warn("performance_schema wait instruments are disabled; wait event metrics are disabled until they are enabled",
+				zap.String("remediation", "set performance_schema=ON in my.cnf and run UPDATE performance_schema.setup_instruments SET ENABLED = 'YES', TIMED = 'YES' WHERE NAME LIKE 'wait/%'"))
+			r.waitsUnavailable = true
+		}
+		// Time counted while the instruments were off would be missing, so
+		// the next collection starts a new baseline
+		r.previous = nil
+		return pmetric.NewMetrics(), nil
+	}
+	if r.waitsUnavailable {
+		r.logger.Info("performance_schema wait instruments are now enabled; collecting wait event metrics")
+		r.waitsUnavailable = false
+	}
+
+	waits, err := queryWaitSummary(ctx, r.source)
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+	return r.buildMetrics(waits, pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// buildMetrics emits the time spent in each wait event since the previous
+// collection. The first collection only sets the baseline. An event whose
+// total went down, because the summary was truncated or the server
+// restarted, starts again from its new total.
+func (r *waitEventsReceiver) buildMetrics(waits []waitEventTime, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+
+	previous := r.previous
+	r.previous = make(map[string]uint64, len(waits))
+	for _, w := range waits {
+		r.previous[w.WaitEvent] = w.TimerWaitPs
+	}
+	if previous == nil {
+		return md
+	}
+
+	var waited []waitEventTime
+	for _, w := range waits {
+		// An event first seen now has no previous total and started from zero
+		last := previous[w.WaitEvent]
+		if w.TimerWaitPs > last {
+			waited = append(waited, waitEventTime{WaitEvent: w.WaitEvent, TimerWaitPs: w.TimerWaitPs - last})
+		}
+	}
+	if len(waited) == 0 {
+		return md
+	}
+
+	gauge := r.newWaitEventsGauge(md)
+	for _, w := range waited {
+		waitEventType := eventType(w.WaitEvent)
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		// Picoseconds to milliseconds
+		dp.SetDoubleValue(float64(w.TimerWaitPs) / 1e9)
+		dp.Attributes().PutStr("db.wait_event.name", w.WaitEvent)
+		dp.Attributes().PutStr("db.wait_event.category", r.categories.category(waitEventType, w.WaitEvent))
+		dp.Attributes().PutStr("db.wait_event.type", waitEventType)
+	}
+
+	return md
+}
+
+// newWaitEventsGauge adds the resource, scope and metric to md
+func (r *waitEventsReceiver) newWaitEventsGauge(md pmetric.Metrics) pmetric.Gauge {
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "mysql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("mysqlwaitevents_receiver")
+	sm.Scope().SetVersion("1.0.0")
+
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(metricWaitEvents)
+	metric.SetDescription("Time spent in each wait event since the previous collection")
+	metric.SetUnit("ms")
+	return metric.SetEmptyGauge()
+}
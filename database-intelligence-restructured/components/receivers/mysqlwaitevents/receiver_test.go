@@ -0,0 +1,259 @@
+package mysqlwaitevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the availability and summary queries with fixed rows
+type stubSource struct {
+	waitsAvailable bool
+	waits          [][]any
+	err            error
+	queries        []string
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	s.queries = append(s.queries, query)
+	switch query {
+	case waitsAvailableQuery:
+		return sqlsourcetest.NewRows([][]any{{s.waitsAvailable}}), nil
+	case waitSummaryQuery:
+		if s.err != nil {
+			return nil, s.err
+		}
+		return sqlsourcetest.NewRows(s.waits), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+func newTestReceiver(cfg *Config, source *stubSource) *waitEventsReceiver {
+	cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+	r := newWaitEventsReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+// summaryFixture is the wait summary as performance_schema returns it, with
+// timer columns in picoseconds
+func summaryFixture(dataFilePs, rowLockPs, mutexPs uint64) [][]any {
+	return [][]any{
+		{"wait/io/file/innodb/innodb_data_file", dataFilePs},
+		{"wait/lock/table/sql/handler", rowLockPs},
+		{"wait/synch/mutex/innodb/trx_sys_mutex", mutexPs},
+	}
+}
+
+func TestEventType(t *testing.T) {
+	assert.Equal(t, "io/file", eventType("wait/io/file/innodb/innodb_data_file"))
+	assert.Equal(t, "synch/mutex", eventType("wait/synch/mutex/innodb/trx_sys_mutex"))
+	assert.Equal(t, "lock/metadata", eventType("wait/lock/metadata/sql/mdl"))
+	assert.Equal(t, "idle", eventType("idle"))
+}
+
+func TestCategoryMapping(t *testing.T) {
+	mapper := newCategoryMapper(nil)
+
+	tests := []struct {
+		waitEvent string
+		want      string
+	}{
+		{"wait/io/file/innodb/innodb_data_file", "IO"},
+		{"wait/io/file/sql/binlog", "IO"},
+		{"wait/io/table/sql/handler", "IO"},
+		{"wait/io/socket/sql/client_connection", "Client"},
+		{"wait/io/socket/sql/server_tcpip_socket", "Activity"},
+		{"wait/lock/table/sql/handler", "Lock"},
+		{"wait/lock/metadata/sql/mdl", "Lock"},
+		{"wait/synch/mutex/innodb/trx_sys_mutex", "LWLock"},
+		{"wait/synch/rwlock/innodb/dict_operation_lock", "LWLock"},
+		{"wait/synch/sxlock/innodb/btr_search_latch", "LWLock"},
+		{"wait/synch/cond/sql/MYSQL_BIN_LOG::COND_done", "IPC"},
+		{"wait/future/instrument", "Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.waitEvent, func(t *testing.T) {
+			assert.Equal(t, tt.want, mapper.category(eventType(tt.waitEvent), tt.waitEvent))
+		})
+	}
+
+	assert.Equal(t, "Activity", mapper.category("IO/Socket", "WAIT/IO/SOCKET/SQL/SERVER_UNIX_SOCKET"), "matching ignores case")
+}
+
+func TestCategoryOverrides(t *testing.T) {
+	mapper := newCategoryMapper([]CategoryRule{
+		{WaitEventType: "lock/metadata", WaitEvent: "wait/lock/metadata/sql/mdl", Category: "DDL"},
+		{WaitEventType: "synch/cond", Category: "Replication"},
+	})
+
+	assert.Equal(t, "DDL", mapper.category("lock/metadata", "wait/lock/metadata/sql/mdl"))
+	assert.Equal(t, "Lock", mapper.category("lock/table", "wait/lock/table/sql/handler"), "other events keep the default category")
+	assert.Equal(t, "Replication", mapper.category("synch/cond", "wait/synch/cond/sql/MYSQL_BIN_LOG::COND_done"))
+}
+
+func TestScrapeEmitsWaitTimeSincePreviousCollection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	source := &stubSource{waitsAvailable: true, waits: summaryFixture(5_000_000_000, 0, 100_000_000)}
+	r := newTestReceiver(cfg, source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount(), "the first collection only sets the baseline")
+
+	// 40ms more data file reads and a 1.5s row lock wait; the mutex is idle
+	source.waits = summaryFixture(45_000_000_000, 1_500_000_000_000, 100_000_000)
+	source.waits = append(source.waits, []any{"wait/lock/metadata/sql/mdl", uint64(2_000_000_000)})
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, md.MetricCount())
+
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"db.system":              "mysql",
+		"deployment.environment": "test",
+	}, rm.Resource().Attributes().AsRaw())
+
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, metricWaitEvents, metric.Name())
+	assert.Equal(t, "ms", metric.Unit())
+	require.Equal(t, pmetric.MetricTypeGauge, metric.Type())
+
+	points := metric.Gauge().DataPoints()
+	require.Equal(t, 3, points.Len(), "events without new wait time are not reported")
+
+	assert.Equal(t, 40.0, points.At(0).DoubleValue())
+	assert.Equal(t, map[string]any{
+		"db.wait_event.name":     "wait/io/file/innodb/innodb_data_file",
+		"db.wait_event.category": "IO",
+		"db.wait_event.type":     "io/file",
+	}, points.At(0).Attributes().AsRaw())
+
+	assert.Equal(t, 1500.0, points.At(1).DoubleValue())
+	category, _ := points.At(1).Attributes().Get("db.wait_event.category")
+	assert.Equal(t, "Lock", category.Str())
+
+	// An event first seen after the baseline reports its whole total
+	assert.Equal(t, 2.0, points.At(2).DoubleValue())
+}
+
+func TestScrapeAfterSummaryTruncated(t *testing.T) {
+	source := &stubSource{waitsAvailable: true, waits: summaryFixture(5_000_000_000, 9_000_000_000, 0)}
+	r := newTestReceiver(DefaultConfig(), source)
+	_, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	// TRUNCATE TABLE events_waits_summary_global_by_event_name
+	source.waits = summaryFixture(1_000_000_000, 9_000_000_000, 0)
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount(), "a total that went down is not reported as negative or as a whole")
+
+	source.waits = summaryFixture(3_000_000_000, 9_000_000_000, 0)
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, md.MetricCount())
+	assert.Equal(t, 2.0, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).DoubleValue())
+}
+
+func TestScrapeWithWaitInstrumentsDisabled(t *testing.T) {
+	source := &stubSource{waitsAvailable: true, waits: summaryFixture(5_000_000_000, 0, 0)}
+	r := newTestReceiver(DefaultConfig(), source)
+	_, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	source.waitsAvailable = false
+	source.queries = nil
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.True(t, r.waitsUnavailable)
+	assert.Equal(t, []string{waitsAvailableQuery}, source.queries, "the summary must not be queried")
+
+	// Once enabled again, the first collection sets a new baseline
+	source.waitsAvailable = true
+	source.waits = summaryFixture(90_000_000_000, 0, 0)
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.False(t, r.waitsUnavailable)
+	assert.Equal(t, 0, md.MetricCount())
+
+	source.waits = summaryFixture(95_000_000_000, 0, 0)
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, md.MetricCount())
+}
+
+func TestScrapeQueryError(t *testing.T) {
+	r := newTestReceiver(DefaultConfig(), &stubSource{waitsAvailable: true, err: errors.New("SELECT command denied to user 'monitor'")})
+
+	_, err := r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to query events_waits_summary_global_by_event_name: SELECT command denied to user 'monitor'")
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{}
+	sqlsourcetest.Lifecycle(t, "monitor:secret@tcp(localhost:3306)/", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(DefaultConfig(), source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "override without wait event type",
+			modify: func(cfg *Config) {
+				cfg.CategoryOverrides = []CategoryRule{{WaitEvent: "wait/lock/metadata/sql/mdl", Category: "DDL"}}
+			},
+			wantErr: "category_overrides[0]: wait_event_type is required",
+		},
+		{
+			name: "override without category",
+			modify: func(cfg *Config) {
+				cfg.CategoryOverrides = []CategoryRule{{WaitEventType: "synch/cond"}}
+			},
+			wantErr: "category_overrides[0]: category is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "monitor:secret@tcp(localhost:3306)/"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package mysqlwaitevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+const (
+	// waitsAvailableQuery reports whether the wait summary is being filled:
+	// performance_schema must be on, with the global_instrumentation
+	// consumer and at least one timed wait instrument enabled. With
+	// performance_schema off the setup tables exist but are empty.
+	waitsAvailableQuery = `SELECT @@global.performance_schema = 1 AND EXISTS (
+  SELECT 1 FROM performance_schema.setup_consumers
+  WHERE NAME = 'global_instrumentation' AND ENABLED = 'YES') AND EXISTS (
+  SELECT 1 FROM performance_schema.setup_instruments
+  WHERE NAME LIKE 'wait/%' AND ENABLED = 'YES' AND TIMED = 'YES')`
+
+	// waitSummaryQuery reads the server-wide wait time of every wait event
+	// that has occurred, in picoseconds since server start or the last
+	// TRUNCATE. The idle event is not a wait/ instrument: it is time
+	// connections spent waiting for a statement, and is skipped like idle
+	// PostgreSQL sessions.
+	waitSummaryQuery = `SELECT EVENT_NAME, SUM_TIMER_WAIT
+FROM performance_schema.events_waits_summary_global_by_event_name
+WHERE EVENT_NAME LIKE 'wait/%' AND COUNT_STAR > 0`
+)
+
+// waitEventTime is the total time spent in one wait event
+type waitEventTime struct {
+	WaitEvent string
+	// TimerWaitPs is cumulative, in picoseconds
+	TimerWaitPs uint64
+}
+
+// queryWaitSummary reads the wait event summary
+func queryWaitSummary(ctx context.Context, source sqlsource.Source) ([]waitEventTime, error) {
+	rs, err := source.Query(ctx, waitSummaryQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events_waits_summary_global_by_event_name: %w", err)
+	}
+	defer rs.Close()
+
+	var waits []waitEventTime
+	for rs.Next() {
+		var w waitEventTime
+		if err := rs.Scan(&w.WaitEvent, &w.TimerWaitPs); err != nil {
+			return nil, fmt.Errorf("failed to scan events_waits_summary_global_by_event_name row: %w", err)
+		}
+		waits = append(waits, w)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events_waits_summary_global_by_event_name rows: %w", err)
+	}
+	return waits, nil
+}
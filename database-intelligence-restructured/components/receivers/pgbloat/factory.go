@@ -0,0 +1,54 @@
+package pgbloat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "pgbloat"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotBloat = errors.New("config is not for pgbloat receiver")
+
+// NewFactory creates a new PostgreSQL bloat receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	bCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotBloat
+	}
+
+	// Validate the configuration
+	if err := bCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	rcv := newBloatReceiver(bCfg, settings.Logger, consumer)
+	rcv.backoff.ReportStatus = settings.ReportStatus
+	return rcv, nil
+}
@@ -0,0 +1,296 @@
+package pgbloat
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Metric names the dashboards query
+const (
+	metricTableBloat = "postgresql.table.bloat_bytes"
+	metricIndexBloat = "postgresql.index.bloat_bytes"
+)
+
+// bloatReceiver implements the receiver.Metrics interface
+type bloatReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Metrics
+	matcher  tableMatcher
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	// database is the name of the connected database, read once
+	database string
+	// extensionMissing and accessDenied record that pgstattuple could not
+	// be used, so the fallback to the estimate is logged once
+	extensionMissing bool
+	accessDenied     bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newBloatReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *bloatReceiver {
+	return &bloatReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		matcher:    newTableMatcher(cfg.Tables),
+		openSource: sqlsource.OpenPostgres,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+// Start implements the receiver.Metrics interface
+func (r *bloatReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting PostgreSQL bloat receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Strings("tables", r.config.Tables),
+		zap.Bool("use_pgstattuple", r.config.UsePgstattuple))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *bloatReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down PostgreSQL bloat receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect measures bloat right away, rather than an interval after start,
+// then every interval
+func (r *bloatReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.backoff.Ready() {
+			r.collectOnce(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectOnce runs one collection and sends its metrics
+func (r *bloatReceiver) collectOnce(ctx context.Context) {
+	md, err := r.scrape(ctx)
+	if err != nil {
+		r.logger.Error("Failed to collect bloat", zap.Error(err),
+			zap.Duration("next_collection_in", r.backoff.Failure(err)))
+		return
+	}
+	r.backoff.Success()
+	if md.MetricCount() == 0 {
+		return
+	}
+	if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+		r.logger.Error("Failed to send bloat metrics", zap.Error(err))
+	}
+}
+
+// scrape estimates the bloat of the monitored tables and their indexes and,
+// with use_pgstattuple, replaces the estimates with measurements
+func (r *bloatReceiver) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	if r.database == "" {
+		queryCtx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+		err := sqlsource.QueryValue(queryCtx, r.source, databaseQuery, &r.database)
+		cancel()
+		if err != nil {
+			return pmetric.NewMetrics(), fmt.Errorf("failed to read database name: %w", err)
+		}
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	tables, skippedTables, err := queryTableBloat(queryCtx, r.source, r.matcher)
+	cancel()
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+
+	queryCtx, cancel = context.WithTimeout(ctx, r.config.QueryTimeout)
+	indexes, skippedIndexes, err := queryIndexBloat(queryCtx, r.source, r.matcher)
+	cancel()
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+
+	if skippedTables+skippedIndexes > 0 {
+		r.logger.Debug("Skipped relations without statistics for the bloat estimate; run ANALYZE on them",
+			zap.Int("tables", skippedTables), zap.Int("indexes", skippedIndexes))
+	}
+
+	if r.config.UsePgstattuple {
+		if err := r.measure(ctx, tables, indexes); err != nil {
+			return pmetric.NewMetrics(), err
+		}
+	}
+
+	return r.buildMetrics(tables, indexes, pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// measure replaces the estimates with pgstattuple measurements. Without the
+// extension or the privilege to use it, the estimates are kept and the
+// fallback is logged once. A relation that cannot be measured, for example
+// because it was dropped since the estimate, keeps its estimate.
+func (r *bloatReceiver) measure(ctx context.Context, tables, indexes []relationBloat) error {
+	queryCtx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	var installed bool
+	err := sqlsource.QueryValue(queryCtx, r.source, extensionInstalledQuery, &installed)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to check for pgstattuple: %w", err)
+	}
+	if !installed {
+		if !r.extensionMissing {
+			r.logger.Warn("pgstattuple extension is not installed; reporting estimated bloat until it is",
+				zap.String("remediation", "run CREATE EXTENSION pgstattuple"))
+			r.extensionMissing = true
+		}
+		return nil
+	}
+	if r.extensionMissing {
+		r.logger.Info("pgstattuple extension is now installed; measuring bloat")
+		r.extensionMissing = false
+	}
+
+	for _, relations := range [][]relationBloat{tables, indexes} {
+		for i := range relations {
+			b := &relations[i]
+			queryCtx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+			err := measureRelation(queryCtx, r.source, b)
+			cancel()
+
+			switch {
+			case err == nil:
+			case sqlsource.IsInsufficientPrivilege(err):
+				if !r.accessDenied {
+					r.logger.Warn("Permission denied using pgstattuple; reporting estimated bloat until access is granted",
+						zap.Error(err),
+						zap.String("remediation", "GRANT pg_stat_scan_tables TO <monitoring user>"))
+					r.accessDenied = true
+				}
+				return nil
+			case ctx.Err() != nil:
+				return ctx.Err()
+			default:
+				r.logger.Warn("Failed to measure bloat with pgstattuple; reporting the estimate",
+					zap.String("schema", b.Schema), zap.String("table", b.Table), zap.String("index", b.Index),
+					zap.Error(err))
+			}
+		}
+	}
+
+	if r.accessDenied {
+		r.logger.Info("pgstattuple is now usable; measuring bloat")
+		r.accessDenied = false
+	}
+	return nil
+}
+
+// buildMetrics emits one data point per table and per index
+func (r *bloatReceiver) buildMetrics(tables, indexes []relationBloat, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	if len(tables) == 0 && len(indexes) == 0 {
+		return md
+	}
+
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "postgresql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("pgbloat_receiver")
+	sm.Scope().SetVersion("1.0.0")
+
+	if len(tables) > 0 {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName(metricTableBloat)
+		metric.SetDescription("Space in a table not taken by live rows")
+		metric.SetUnit("By")
+		r.appendDataPoints(metric.SetEmptyGauge(), tables, now)
+	}
+
+	if len(indexes) > 0 {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName(metricIndexBloat)
+		metric.SetDescription("Space in a B-tree index not taken by its entries")
+		metric.SetUnit("By")
+		r.appendDataPoints(metric.SetEmptyGauge(), indexes, now)
+	}
+
+	return md
+}
+
+func (r *bloatReceiver) appendDataPoints(gauge pmetric.Gauge, relations []relationBloat, now pcommon.Timestamp) {
+	for _, b := range relations {
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetIntValue(b.BloatBytes)
+		dp.Attributes().PutStr("postgresql.database.name", r.database)
+		dp.Attributes().PutStr("postgresql.schema.name", b.Schema)
+		dp.Attributes().PutStr("postgresql.table.name", b.Table)
+		if b.Index != "" {
+			dp.Attributes().PutStr("postgresql.index.name", b.Index)
+		}
+		dp.Attributes().PutStr("postgresql.bloat.method", b.Method)
+	}
+}
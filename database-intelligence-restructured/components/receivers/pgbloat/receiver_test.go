@@ -0,0 +1,375 @@
+package pgbloat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the bloat queries with fixed rows, and pgstattuple
+// queries with the measurement of each relation by name
+type stubSource struct {
+	extensionInstalled bool
+	measured           map[string]int64
+	// measureErr is returned by the pgstattuple queries
+	measureErr error
+	err        error
+	measures   int
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	switch query {
+	case databaseQuery:
+		return sqlsourcetest.NewRows([][]any{{"shop"}}), nil
+	case tableBloatQuery:
+		return sqlsourcetest.NewRows(tableBloatRows), nil
+	case indexBloatQuery:
+		return sqlsourcetest.NewRows(indexBloatRows), nil
+	case extensionInstalledQuery:
+		return sqlsourcetest.NewRows([][]any{{s.extensionInstalled}}), nil
+	case tableStatQuery, indexStatQuery:
+		s.measures++
+		if s.measureErr != nil {
+			return nil, s.measureErr
+		}
+		name := fmt.Sprintf("%s.%s", args...)
+		bloat, ok := s.measured[name]
+		if !ok {
+			return nil, fmt.Errorf(`relation "%s" does not exist`, name)
+		}
+		return sqlsourcetest.NewRows([][]any{{bloat}}), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+// Estimate rows for the e-commerce schema the generators create. The
+// events table has not been analyzed yet, so its estimate is unusable.
+var (
+	tableBloatRows = [][]any{
+		{"public", "events", int64(0), true},
+		{"public", "orders", int64(41_943_040), false},
+		{"public", "products", int64(0), false},
+		{"sales", "quotas", int64(8_192), false},
+	}
+	indexBloatRows = [][]any{
+		{"public", "orders", "orders_created_at_idx", int64(12_582_912), false},
+		{"public", "orders", "orders_pkey", int64(1_048_576), false},
+		{"public", "products", "products_name_idx", int64(0), true},
+		{"sales", "quotas", "quotas_pkey", int64(0), false},
+	}
+)
+
+func newTestReceiver(source *stubSource, tables ...string) *bloatReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "postgres://localhost:5432/shop"
+	cfg.Tables = tables
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newBloatReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+// dataPoints returns the values of a bloat metric by relation name
+func dataPoints(t *testing.T, md pmetric.Metrics, name string) map[string]int64 {
+	t.Helper()
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() != name {
+			continue
+		}
+		values := make(map[string]int64)
+		dps := metrics.At(i).Gauge().DataPoints()
+		for j := 0; j < dps.Len(); j++ {
+			attrs := dps.At(j).Attributes()
+			schema, _ := attrs.Get("postgresql.schema.name")
+			relation, _ := attrs.Get("postgresql.table.name")
+			if index, ok := attrs.Get("postgresql.index.name"); ok {
+				relation = index
+			}
+			values[schema.Str()+"."+relation.Str()] = dps.At(j).IntValue()
+		}
+		return values
+	}
+	t.Fatalf("metric %s not found", name)
+	return nil
+}
+
+func TestQueryTableBloat(t *testing.T) {
+	tables, skipped, err := queryTableBloat(context.Background(), &stubSource{}, newTableMatcher(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []relationBloat{
+		{Schema: "public", Table: "orders", BloatBytes: 41_943_040, Method: methodEstimate},
+		{Schema: "public", Table: "products", BloatBytes: 0, Method: methodEstimate},
+		{Schema: "sales", Table: "quotas", BloatBytes: 8_192, Method: methodEstimate},
+	}, tables)
+	assert.Equal(t, 1, skipped, "the unanalyzed table is skipped")
+
+	tables, skipped, err = queryTableBloat(context.Background(), &stubSource{}, newTableMatcher([]string{"sales.*"}))
+	require.NoError(t, err)
+	assert.Equal(t, []relationBloat{{Schema: "sales", Table: "quotas", BloatBytes: 8_192, Method: methodEstimate}}, tables)
+	assert.Zero(t, skipped, "unmonitored tables are not counted as skipped")
+}
+
+func TestQueryIndexBloat(t *testing.T) {
+	indexes, skipped, err := queryIndexBloat(context.Background(), &stubSource{}, newTableMatcher([]string{"orders", "products"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []relationBloat{
+		{Schema: "public", Table: "orders", Index: "orders_created_at_idx", BloatBytes: 12_582_912, Method: methodEstimate},
+		{Schema: "public", Table: "orders", Index: "orders_pkey", BloatBytes: 1_048_576, Method: methodEstimate},
+	}, indexes)
+	assert.Equal(t, 1, skipped)
+}
+
+func TestQueryBloatErrors(t *testing.T) {
+	source := &stubSource{err: errors.New("connection refused")}
+
+	_, _, err := queryTableBloat(context.Background(), source, nil)
+	assert.EqualError(t, err, "failed to query table bloat: connection refused")
+	_, _, err = queryIndexBloat(context.Background(), source, nil)
+	assert.EqualError(t, err, "failed to query index bloat: connection refused")
+}
+
+func TestScrapeEmitsEstimates(t *testing.T) {
+	r := newTestReceiver(&stubSource{})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, md.MetricCount())
+
+	rm := md.ResourceMetrics().At(0)
+	system, _ := rm.Resource().Attributes().Get("db.system")
+	assert.Equal(t, "postgresql", system.Str())
+	env, _ := rm.Resource().Attributes().Get("deployment.environment")
+	assert.Equal(t, "test", env.Str())
+
+	assert.Equal(t, map[string]int64{
+		"public.orders":   41_943_040,
+		"public.products": 0,
+		"sales.quotas":    8_192,
+	}, dataPoints(t, md, metricTableBloat))
+	assert.Equal(t, map[string]int64{
+		"public.orders_created_at_idx": 12_582_912,
+		"public.orders_pkey":           1_048_576,
+		"sales.quotas_pkey":            0,
+	}, dataPoints(t, md, metricIndexBloat))
+
+	metric := rm.ScopeMetrics().At(0).Metrics().At(1)
+	assert.Equal(t, "By", metric.Unit())
+	attrs := metric.Gauge().DataPoints().At(0).Attributes().AsRaw()
+	assert.Equal(t, map[string]any{
+		"postgresql.database.name": "shop",
+		"postgresql.schema.name":   "public",
+		"postgresql.table.name":    "orders",
+		"postgresql.index.name":    "orders_created_at_idx",
+		"postgresql.bloat.method":  "estimate",
+	}, attrs)
+}
+
+func TestScrapeWithoutMonitoredTables(t *testing.T) {
+	r := newTestReceiver(&stubSource{}, "archive.*")
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, md.MetricCount())
+}
+
+func TestScrapeMeasuresWithPgstattuple(t *testing.T) {
+	source := &stubSource{
+		extensionInstalled: true,
+		measured: map[string]int64{
+			"public.orders":                38_000_000,
+			"public.orders_created_at_idx": 9_000_000,
+		},
+	}
+	r := newTestReceiver(source, "orders")
+	r.config.UsePgstattuple = true
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{"public.orders": 38_000_000}, dataPoints(t, md, metricTableBloat))
+	// orders_pkey cannot be measured, so it keeps its estimate
+	assert.Equal(t, map[string]int64{
+		"public.orders_created_at_idx": 9_000_000,
+		"public.orders_pkey":           1_048_576,
+	}, dataPoints(t, md, metricIndexBloat))
+
+	indexes := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(1).Gauge().DataPoints()
+	method, _ := indexes.At(0).Attributes().Get("postgresql.bloat.method")
+	assert.Equal(t, "pgstattuple", method.Str())
+	method, _ = indexes.At(1).Attributes().Get("postgresql.bloat.method")
+	assert.Equal(t, "estimate", method.Str())
+}
+
+func TestScrapeWithoutPgstattuple(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	source := &stubSource{measured: map[string]int64{"public.orders": 38_000_000}}
+	r := newTestReceiver(source, "orders")
+	r.config.UsePgstattuple = true
+	r.logger = zap.New(core)
+
+	// The estimates are reported instead
+	for i := 0; i < 2; i++ {
+		md, err := r.scrape(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"public.orders": 41_943_040}, dataPoints(t, md, metricTableBloat))
+	}
+	assert.Zero(t, source.measures)
+	warnings := logs.FilterMessageSnippet("pgstattuple extension is not installed").All()
+	require.Len(t, warnings, 1, "the diagnostic is logged once")
+	assert.Equal(t, zapcore.WarnLevel, warnings[0].Level)
+
+	source.extensionInstalled = true
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"public.orders": 38_000_000}, dataPoints(t, md, metricTableBloat))
+	assert.Equal(t, 1, logs.FilterMessageSnippet("now installed").Len())
+}
+
+func TestScrapeWithPgstattuplePermissionDenied(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	source := &stubSource{
+		extensionInstalled: true,
+		measured:           map[string]int64{"public.orders": 38_000_000},
+		measureErr:         &pq.Error{Code: "42501", Message: "permission denied for function pgstattuple"},
+	}
+	r := newTestReceiver(source, "orders")
+	r.config.UsePgstattuple = true
+	r.logger = zap.New(core)
+
+	for i := 0; i < 2; i++ {
+		md, err := r.scrape(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"public.orders": 41_943_040}, dataPoints(t, md, metricTableBloat))
+	}
+	assert.Equal(t, 2, source.measures, "measuring stops at the first denial")
+	warnings := logs.FilterMessageSnippet("Permission denied using pgstattuple").All()
+	require.Len(t, warnings, 1, "the diagnostic is logged once")
+	assert.Contains(t, warnings[0].ContextMap()["error"], "permission denied for function pgstattuple")
+
+	source.measureErr = nil
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"public.orders": 38_000_000}, dataPoints(t, md, metricTableBloat))
+	assert.False(t, r.accessDenied)
+}
+
+func TestScrapeErrors(t *testing.T) {
+	r := newTestReceiver(&stubSource{err: errors.New("connection refused")})
+
+	_, err := r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to read database name: connection refused")
+}
+
+func TestTableMatcher(t *testing.T) {
+	m := newTableMatcher([]string{"orders", "sales.*"})
+
+	assert.True(t, m.matches("public", "orders"))
+	assert.False(t, m.matches("archive", "orders"), "a pattern without schema means public")
+	assert.True(t, m.matches("sales", "quotas"))
+	assert.False(t, m.matches("public", "customers"))
+
+	assert.True(t, newTableMatcher(nil).matches("public", "customers"), "no patterns monitor every table")
+}
+
+func TestReceiverCollectsAtStart(t *testing.T) {
+	source := &stubSource{}
+	r := newTestReceiver(source)
+	sink := new(consumertest.MetricsSink)
+	r.consumer = sink
+	r.openSource = func(ctx context.Context, datasource string) (sqlsource.Source, error) {
+		return source, nil
+	}
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	// The first collection runs right away
+	require.Eventually(t, func() bool { return sink.DataPointCount() > 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{}
+	sqlsourcetest.Lifecycle(t, "postgres://localhost:5432/shop", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "every table",
+			modify: func(cfg *Config) { cfg.Tables = nil },
+		},
+		{
+			name:   "pgstattuple",
+			modify: func(cfg *Config) { cfg.UsePgstattuple = true },
+		},
+		{
+			name: "pgstattuple on every table",
+			modify: func(cfg *Config) {
+				cfg.UsePgstattuple = true
+				cfg.Tables = nil
+			},
+			wantErr: "tables must be specified when use_pgstattuple is enabled",
+		},
+		{
+			name:    "too many dots",
+			modify:  func(cfg *Config) { cfg.Tables = []string{"shop.public.orders"} },
+			wantErr: `invalid table pattern "shop.public.orders"`,
+		},
+		{
+			name:    "bad wildcard",
+			modify:  func(cfg *Config) { cfg.Tables = []string{"public.order_[a"} },
+			wantErr: "syntax error in pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "postgres://localhost:5432/shop"
+			cfg.Tables = []string{"orders"}
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
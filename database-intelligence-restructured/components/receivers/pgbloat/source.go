@@ -0,0 +1,253 @@
+package pgbloat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+const (
+	databaseQuery = `SELECT current_database()`
+
+	extensionInstalledQuery = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pgstattuple')`
+
+	// tableBloatQuery is the standard catalog-based estimate of table
+	// bloat: the size the table would have if its live rows, at their
+	// average width from pg_stats, were packed at its fillfactor, compared
+	// with its actual size. It returns the schema, table and bloat in bytes,
+	// and whether the estimate is unusable because columns lack
+	// statistics or have a type of unknown width.
+	tableBloatQuery = `SELECT schemaname, tblname,
+  (CASE WHEN tblpages > 0 AND tblpages - est_tblpages_ff > 0
+    THEN (tblpages - est_tblpages_ff) * bs ELSE 0 END)::bigint,
+  is_na
+FROM (
+  SELECT ceil(reltuples / ((bs - page_hdr) * fillfactor / (tpl_size * 100))) + ceil(toasttuples / 4) AS est_tblpages_ff,
+    tblpages, bs, schemaname, tblname, is_na
+  FROM (
+    SELECT
+      (4 + tpl_hdr_size + tpl_data_size + (2 * ma)
+        - CASE WHEN tpl_hdr_size % ma = 0 THEN ma ELSE tpl_hdr_size % ma END
+        - CASE WHEN ceil(tpl_data_size)::int % ma = 0 THEN ma ELSE ceil(tpl_data_size)::int % ma END
+      ) AS tpl_size,
+      (heappages + toastpages) AS tblpages, reltuples, toasttuples, bs, page_hdr,
+      schemaname, tblname, fillfactor, is_na
+    FROM (
+      SELECT
+        ns.nspname AS schemaname, tbl.relname AS tblname, tbl.reltuples,
+        tbl.relpages AS heappages, coalesce(toast.relpages, 0) AS toastpages,
+        coalesce(toast.reltuples, 0) AS toasttuples,
+        coalesce(substring(array_to_string(tbl.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 100) AS fillfactor,
+        current_setting('block_size')::numeric AS bs,
+        CASE WHEN version() ~ 'mingw32' OR version() ~ '64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS ma,
+        24 AS page_hdr,
+        23 + CASE WHEN max(coalesce(s.null_frac, 0)) > 0 THEN (7 + count(s.attname)) / 8 ELSE 0::int END AS tpl_hdr_size,
+        sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 0)) AS tpl_data_size,
+        bool_or(att.atttypid = 'pg_catalog.name'::regtype)
+          OR sum(CASE WHEN att.attnum > 0 THEN 1 ELSE 0 END) <> count(s.attname) AS is_na
+      FROM pg_attribute att
+      JOIN pg_class tbl ON att.attrelid = tbl.oid
+      JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+      LEFT JOIN pg_stats s ON s.schemaname = ns.nspname
+        AND s.tablename = tbl.relname AND s.inherited = false AND s.attname = att.attname
+      LEFT JOIN pg_class toast ON tbl.reltoastrelid = toast.oid
+      WHERE NOT att.attisdropped
+        AND att.attnum > 0
+        AND tbl.relkind IN ('r', 'm')
+        AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+        AND ns.nspname NOT LIKE 'pg_toast%'
+      GROUP BY 1, 2, 3, 4, 5, 6, 7
+    ) AS s
+  ) AS s2
+) AS s3
+ORDER BY schemaname, tblname`
+
+	// indexBloatQuery is the standard catalog-based estimate of B-tree
+	// index bloat, comparing each index with the size of its entries at
+	// their average width from pg_stats, packed at its fillfactor. It
+	// returns the schema, table, index and bloat in bytes, and whether
+	// the estimate is unusable. Other index types are not
+	// estimated.
+	indexBloatQuery = `SELECT nspname, tblname, idxname,
+  (CASE WHEN relpages > est_pages_ff THEN bs * (relpages - est_pages_ff) ELSE 0 END)::bigint,
+  is_na
+FROM (
+  SELECT coalesce(1 + ceil(reltuples / floor((bs - pageopqdata - pagehdr) * fillfactor / (100 * (4 + nulldatahdrwidth)::float))), 0) AS est_pages_ff,
+    bs, nspname, tblname, idxname, relpages, is_na
+  FROM (
+    SELECT bs, nspname, tblname, idxname, reltuples, relpages, fillfactor,
+      (index_tuple_hdr_bm + maxalign
+        - CASE WHEN index_tuple_hdr_bm % maxalign = 0 THEN maxalign ELSE index_tuple_hdr_bm % maxalign END
+        + nulldatawidth + maxalign
+        - CASE WHEN nulldatawidth = 0 THEN 0
+            WHEN nulldatawidth::integer % maxalign = 0 THEN maxalign
+            ELSE nulldatawidth::integer % maxalign END
+      )::numeric AS nulldatahdrwidth,
+      pagehdr, pageopqdata, is_na
+    FROM (
+      SELECT n.nspname, i.tblname, i.idxname, i.reltuples, i.relpages, i.fillfactor,
+        current_setting('block_size')::numeric AS bs,
+        CASE WHEN version() ~ 'mingw32' OR version() ~ '64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS maxalign,
+        24 AS pagehdr,
+        16 AS pageopqdata,
+        CASE WHEN max(coalesce(s.null_frac, 0)) = 0 THEN 8 ELSE 8 + ((32 + 8 - 1) / 8) END AS index_tuple_hdr_bm,
+        sum((1 - coalesce(s.null_frac, 0)) * coalesce(s.avg_width, 1024)) AS nulldatawidth,
+        max(CASE WHEN i.atttypid = 'pg_catalog.name'::regtype THEN 1 ELSE 0 END) > 0 AS is_na
+      FROM (
+        SELECT ct.relname AS tblname, ct.relnamespace, ic.idxname, ic.reltuples, ic.relpages, ic.fillfactor,
+          coalesce(a1.attname, a2.attname) AS attname,
+          coalesce(a1.atttypid, a2.atttypid) AS atttypid,
+          CASE WHEN a1.attnum IS NULL THEN ic.idxname ELSE ct.relname END AS attrelname
+        FROM (
+          SELECT idxname, reltuples, relpages, tbloid, idxoid, fillfactor, indkey,
+            generate_series(1, indnatts) AS attpos
+          FROM (
+            SELECT ci.relname AS idxname, ci.reltuples, ci.relpages, i.indrelid AS tbloid, i.indexrelid AS idxoid,
+              coalesce(substring(array_to_string(ci.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 90) AS fillfactor,
+              i.indnatts,
+              string_to_array(textin(int2vectorout(i.indkey)), ' ')::int[] AS indkey
+            FROM pg_index i
+            JOIN pg_class ci ON ci.oid = i.indexrelid
+            WHERE ci.relam = (SELECT oid FROM pg_am WHERE amname = 'btree')
+              AND ci.relpages > 0
+          ) AS idx_data
+        ) AS ic
+        JOIN pg_class ct ON ct.oid = ic.tbloid
+        LEFT JOIN pg_attribute a1 ON ic.indkey[ic.attpos] <> 0
+          AND a1.attrelid = ic.tbloid AND a1.attnum = ic.indkey[ic.attpos]
+        LEFT JOIN pg_attribute a2 ON ic.indkey[ic.attpos] = 0
+          AND a2.attrelid = ic.idxoid AND a2.attnum = ic.attpos
+      ) i
+      JOIN pg_namespace n ON n.oid = i.relnamespace
+      JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = i.attrelname AND s.attname = i.attname
+      WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+        AND n.nspname NOT LIKE 'pg_toast%'
+      GROUP BY 1, 2, 3, 4, 5, 6
+    ) AS rows_data_stats
+  ) AS rows_hdr_pdg_stats
+) AS relation_stats
+ORDER BY nspname, tblname, idxname`
+
+	// tableStatQuery measures one table with pgstattuple: its bloat is the
+	// space taken by dead rows plus the free space in its pages
+	tableStatQuery = `SELECT dead_tuple_len + free_space
+FROM pgstattuple(format('%I.%I', $1::text, $2::text)::regclass)`
+
+	// indexStatQuery measures one B-tree index with pgstatindex: its bloat
+	// is the unused share of its leaf pages. An empty index has no leaf
+	// density.
+	indexStatQuery = `SELECT CASE WHEN avg_leaf_density = 'NaN' THEN 0
+    ELSE (index_size * (100 - avg_leaf_density) / 100)::bigint END
+FROM pgstatindex(format('%I.%I', $1::text, $2::text)::regclass)`
+)
+
+// Methods the bloat of a relation was determined with
+const (
+	methodEstimate    = "estimate"
+	methodPgstattuple = "pgstattuple"
+)
+
+// relationBloat is the bloat of one table or index
+type relationBloat struct {
+	Schema string
+	Table  string
+	// Index is empty for a table
+	Index      string
+	BloatBytes int64
+	Method     string
+}
+
+// queryTableBloat estimates the bloat of the tables the matcher selects.
+// Tables whose estimate is unusable, usually because they have not been
+// analyzed yet, are left out; skipped returns how many.
+func queryTableBloat(ctx context.Context, source sqlsource.Source, matcher tableMatcher) (tables []relationBloat, skipped int, err error) {
+	rs, err := source.Query(ctx, tableBloatQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query table bloat: %w", err)
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+		b := relationBloat{Method: methodEstimate}
+		var unusable bool
+		if err := rs.Scan(&b.Schema, &b.Table, &b.BloatBytes, &unusable); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan table bloat row: %w", err)
+		}
+		if !matcher.matches(b.Schema, b.Table) {
+			continue
+		}
+		if unusable {
+			skipped++
+			continue
+		}
+		tables = append(tables, b)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read table bloat rows: %w", err)
+	}
+	return tables, skipped, nil
+}
+
+// queryIndexBloat estimates the bloat of the B-tree indexes of the tables
+// the matcher selects, leaving out those whose estimate is unusable
+func queryIndexBloat(ctx context.Context, source sqlsource.Source, matcher tableMatcher) (indexes []relationBloat, skipped int, err error) {
+	rs, err := source.Query(ctx, indexBloatQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query index bloat: %w", err)
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+		b := relationBloat{Method: methodEstimate}
+		var unusable bool
+		if err := rs.Scan(&b.Schema, &b.Table, &b.Index, &b.BloatBytes, &unusable); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan index bloat row: %w", err)
+		}
+		if !matcher.matches(b.Schema, b.Table) {
+			continue
+		}
+		if unusable {
+			skipped++
+			continue
+		}
+		indexes = append(indexes, b)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read index bloat rows: %w", err)
+	}
+	return indexes, skipped, nil
+}
+
+// measureRelation replaces the estimate for b with its pgstattuple
+// measurement
+func measureRelation(ctx context.Context, source sqlsource.Source, b *relationBloat) error {
+	query, name := tableStatQuery, b.Table
+	if b.Index != "" {
+		query, name = indexStatQuery, b.Index
+	}
+
+	rs, err := source.Query(ctx, query, b.Schema, name)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	if !rs.Next() {
+		if err := rs.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	var bloat int64
+	if err := rs.Scan(&bloat); err != nil {
+		return err
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+
+	b.BloatBytes, b.Method = max(bloat, 0), methodPgstattuple
+	return nil
+}
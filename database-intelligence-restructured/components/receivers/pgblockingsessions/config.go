@@ -0,0 +1,62 @@
+package pgblockingsessions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the PostgreSQL connection string
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often blocked sessions are looked up
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// StatementTimeout is set as statement_timeout and lock_timeout on the
+	// collection query, so a busy lock manager cannot stall the receiver
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"`
+
+	// MaxQueryLength truncates the query text attributes to this many bytes
+	MaxQueryLength int `mapstructure:"max_query_length"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Datasource == "" {
+		return errors.New("datasource must be specified")
+	}
+
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive, got %v", cfg.CollectionInterval)
+	}
+
+	// PostgreSQL timeouts have millisecond resolution and 0 disables them
+	if cfg.StatementTimeout < time.Millisecond {
+		return fmt.Errorf("statement_timeout must be at least 1ms, got %v", cfg.StatementTimeout)
+	}
+
+	if cfg.StatementTimeout > cfg.CollectionInterval {
+		return fmt.Errorf("statement_timeout (%v) cannot be greater than collection_interval (%v)",
+			cfg.StatementTimeout, cfg.CollectionInterval)
+	}
+
+	if cfg.MaxQueryLength <= 0 {
+		return fmt.Errorf("max_query_length must be positive, got %d", cfg.MaxQueryLength)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 30 * time.Second,
+		StatementTimeout:   2 * time.Second,
+		MaxQueryLength:     1024,
+	}
+}
@@ -0,0 +1,243 @@
+package pgblockingsessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the blocking sessions query with fixed rows
+type stubSource struct {
+	pairs [][]any
+	err   error
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	if query != blockingSessionsQuery {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return sqlsourcetest.NewRows(s.pairs), nil
+}
+
+func (s *stubSource) Close() error { return nil }
+
+// fixturePairs is a result set captured while one session held a row lock
+// that two others were waiting on, one of which was also queued behind the
+// other for the same tuple
+var fixturePairs = [][]any{
+	{int64(4211), int64(4187), "shop", "transactionid",
+		"UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		"UPDATE inventory\n   SET qty = 0\n WHERE sku = 'A-1'"},
+	{int64(4230), int64(4187), "shop", "transactionid",
+		"DELETE FROM inventory WHERE sku = 'A-1'",
+		"UPDATE inventory\n   SET qty = 0\n WHERE sku = 'A-1'"},
+	{int64(4230), int64(4211), "shop", "tuple",
+		"DELETE FROM inventory WHERE sku = 'A-1'",
+		"UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'"},
+}
+
+func newTestReceiver(source *stubSource) *blockingSessionsReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "postgres://localhost:5432/postgres"
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newBlockingSessionsReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+func TestQueryBlockingPairs(t *testing.T) {
+	pairs, err := queryBlockingPairs(context.Background(), &stubSource{pairs: fixturePairs})
+	require.NoError(t, err)
+
+	assert.Equal(t, []blockingPair{
+		{
+			BlockedPID:    4211,
+			BlockingPID:   4187,
+			Database:      "shop",
+			LockType:      "transactionid",
+			BlockedQuery:  "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+			BlockingQuery: "UPDATE inventory\n   SET qty = 0\n WHERE sku = 'A-1'",
+		},
+		{
+			BlockedPID:    4230,
+			BlockingPID:   4187,
+			Database:      "shop",
+			LockType:      "transactionid",
+			BlockedQuery:  "DELETE FROM inventory WHERE sku = 'A-1'",
+			BlockingQuery: "UPDATE inventory\n   SET qty = 0\n WHERE sku = 'A-1'",
+		},
+		{
+			BlockedPID:    4230,
+			BlockingPID:   4211,
+			Database:      "shop",
+			LockType:      "tuple",
+			BlockedQuery:  "DELETE FROM inventory WHERE sku = 'A-1'",
+			BlockingQuery: "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		},
+	}, pairs)
+}
+
+func TestQueryBlockingPairsScanError(t *testing.T) {
+	_, err := queryBlockingPairs(context.Background(), &stubSource{pairs: [][]any{{int64(1), int64(2)}}})
+	assert.ErrorContains(t, err, "failed to scan blocking session row")
+}
+
+func TestScrapeEmitsBlockingSessions(t *testing.T) {
+	r := newTestReceiver(&stubSource{pairs: fixturePairs})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, md.MetricCount())
+
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"db.system":              "postgresql",
+		"deployment.environment": "test",
+	}, rm.Resource().Attributes().AsRaw())
+
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, metricBlockingSessions, metric.Name())
+	assert.Equal(t, "{session}", metric.Unit())
+
+	points := metric.Gauge().DataPoints()
+	require.Equal(t, 3, points.Len())
+	for i := 0; i < points.Len(); i++ {
+		assert.Equal(t, int64(1), points.At(i).IntValue())
+	}
+
+	assert.Equal(t, map[string]any{
+		"db.blocking.blocked_pid":    int64(4211),
+		"db.blocking.blocking_pid":   int64(4187),
+		"db.blocking.lock_type":      "transactionid",
+		"db.blocking.blocked_query":  "UPDATE inventory SET qty = qty - 1 WHERE sku = 'A-1'",
+		"db.blocking.blocking_query": "UPDATE inventory SET qty = 0 WHERE sku = 'A-1'",
+		"db.name":                    "shop",
+	}, points.At(0).Attributes().AsRaw())
+}
+
+func TestScrapeWithoutBlocking(t *testing.T) {
+	r := newTestReceiver(&stubSource{})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+}
+
+func TestScrapeQueryError(t *testing.T) {
+	r := newTestReceiver(&stubSource{err: errors.New("canceling statement due to statement timeout")})
+
+	_, err := r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to query blocking sessions: canceling statement due to statement timeout")
+}
+
+func TestTimeoutStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		"SET LOCAL statement_timeout = 2000",
+		"SET LOCAL lock_timeout = 2000",
+	}, timeoutStatements(2*time.Second))
+	assert.Equal(t, []string{
+		"SET LOCAL statement_timeout = 250",
+		"SET LOCAL lock_timeout = 250",
+	}, timeoutStatements(250*time.Millisecond))
+}
+
+func TestCleanQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		maxLen int
+		want   string
+	}{
+		{"unchanged", "SELECT 1", 100, "SELECT 1"},
+		{"whitespace collapsed", "SELECT *\n\tFROM  orders ", 100, "SELECT * FROM orders"},
+		{"truncated", "SELECT * FROM orders", 8, "SELECT *"},
+		{"multi-byte character kept whole", "SELECT 'héllo'", 10, "SELECT 'h"},
+		{"empty", "", 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cleanQuery(tt.query, tt.maxLen))
+		})
+	}
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{}
+	sqlsourcetest.Lifecycle(t, "postgres://localhost:5432/postgres", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = func(ctx context.Context, datasource string, statementTimeout time.Duration) (sqlsource.Source, error) {
+			assert.Equal(t, 2*time.Second, statementTimeout)
+			return open(ctx, datasource)
+		}
+		return r
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "missing datasource",
+			modify:  func(cfg *Config) { cfg.Datasource = "" },
+			wantErr: "datasource must be specified",
+		},
+		{
+			name:    "zero collection interval",
+			modify:  func(cfg *Config) { cfg.CollectionInterval = 0 },
+			wantErr: "collection_interval must be positive",
+		},
+		{
+			name:    "statement timeout below resolution",
+			modify:  func(cfg *Config) { cfg.StatementTimeout = time.Microsecond },
+			wantErr: "statement_timeout must be at least 1ms",
+		},
+		{
+			name:    "statement timeout above collection interval",
+			modify:  func(cfg *Config) { cfg.StatementTimeout = time.Minute },
+			wantErr: "statement_timeout (1m0s) cannot be greater than collection_interval (30s)",
+		},
+		{
+			name:    "zero max query length",
+			modify:  func(cfg *Config) { cfg.MaxQueryLength = 0 },
+			wantErr: "max_query_length must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "postgres://localhost:5432/postgres"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
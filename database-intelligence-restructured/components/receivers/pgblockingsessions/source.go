@@ -0,0 +1,89 @@
+package pgblockingsessions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// timeoutStatements returns the statements that bound the collection query.
+// lock_timeout matters as much as statement_timeout: reading pg_locks must
+// never queue behind the locks being reported.
+func timeoutStatements(timeout time.Duration) []string {
+	ms := timeout.Milliseconds()
+	return []string{
+		fmt.Sprintf("SET LOCAL statement_timeout = %d", ms),
+		fmt.Sprintf("SET LOCAL lock_timeout = %d", ms),
+	}
+}
+
+// openSource connects to PostgreSQL. Every query runs in its own read-only
+// transaction with the timeouts applied via SET LOCAL, so they hold even if
+// the pool reconnects.
+func openSource(ctx context.Context, datasource string, statementTimeout time.Duration) (sqlsource.Source, error) {
+	return sqlsource.Open(ctx, "postgres", datasource, timeoutStatements(statementTimeout)...)
+}
+
+// blockingSessionsQuery is the standard pg_locks/pg_stat_activity join: every
+// ungranted lock is matched with the granted locks on the same object. A
+// blocked session can conflict with several locks held by the same blocker,
+// so the result is reduced to one row per blocked/blocking pair.
+const blockingSessionsQuery = `SELECT DISTINCT ON (blocked_locks.pid, blocking_locks.pid)
+  blocked_locks.pid,
+  blocking_locks.pid,
+  COALESCE(blocked_activity.datname, ''),
+  blocked_locks.locktype,
+  COALESCE(blocked_activity.query, ''),
+  COALESCE(blocking_activity.query, '')
+FROM pg_locks blocked_locks
+JOIN pg_stat_activity blocked_activity ON blocked_activity.pid = blocked_locks.pid
+JOIN pg_locks blocking_locks
+  ON blocking_locks.locktype = blocked_locks.locktype
+  AND blocking_locks.database IS NOT DISTINCT FROM blocked_locks.database
+  AND blocking_locks.relation IS NOT DISTINCT FROM blocked_locks.relation
+  AND blocking_locks.page IS NOT DISTINCT FROM blocked_locks.page
+  AND blocking_locks.tuple IS NOT DISTINCT FROM blocked_locks.tuple
+  AND blocking_locks.virtualxid IS NOT DISTINCT FROM blocked_locks.virtualxid
+  AND blocking_locks.transactionid IS NOT DISTINCT FROM blocked_locks.transactionid
+  AND blocking_locks.classid IS NOT DISTINCT FROM blocked_locks.classid
+  AND blocking_locks.objid IS NOT DISTINCT FROM blocked_locks.objid
+  AND blocking_locks.objsubid IS NOT DISTINCT FROM blocked_locks.objsubid
+  AND blocking_locks.pid <> blocked_locks.pid
+  AND blocking_locks.granted
+JOIN pg_stat_activity blocking_activity ON blocking_activity.pid = blocking_locks.pid
+WHERE NOT blocked_locks.granted
+ORDER BY blocked_locks.pid, blocking_locks.pid`
+
+// blockingPair is one session waiting on a lock held by another
+type blockingPair struct {
+	BlockedPID    int64
+	BlockingPID   int64
+	Database      string
+	LockType      string
+	BlockedQuery  string
+	BlockingQuery string
+}
+
+// queryBlockingPairs reads the blocked/blocking session pairs
+func queryBlockingPairs(ctx context.Context, source sqlsource.Source) ([]blockingPair, error) {
+	rs, err := source.Query(ctx, blockingSessionsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blocking sessions: %w", err)
+	}
+	defer rs.Close()
+
+	var pairs []blockingPair
+	for rs.Next() {
+		var p blockingPair
+		if err := rs.Scan(&p.BlockedPID, &p.BlockingPID, &p.Database, &p.LockType, &p.BlockedQuery, &p.BlockingQuery); err != nil {
+			return nil, fmt.Errorf("failed to scan blocking session row: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocking session rows: %w", err)
+	}
+	return pairs, nil
+}
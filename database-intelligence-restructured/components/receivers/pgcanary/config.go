@@ -0,0 +1,153 @@
+package pgcanary
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MarkerPlaceholder is replaced with the marker ID in the lookup NRQL
+const MarkerPlaceholder = "{marker_id}"
+
+// tableName matches an optionally schema-qualified, unquoted identifier, so
+// the table can be interpolated into the marker statements
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// markerPrefix matches the characters a marker ID may contain, so it can be
+// interpolated into NRQL without escaping
+var markerPrefix = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the PostgreSQL connection string of the database the
+	// markers are written to
+	Datasource string `mapstructure:"datasource"`
+
+	// Table holds the marker rows. The pipeline under test must read it,
+	// typically with a sqlquery receiver, and export the marker IDs.
+	Table string `mapstructure:"table"`
+
+	// CreateTable creates the table at startup if it does not exist
+	CreateTable bool `mapstructure:"create_table"`
+
+	// MarkerPrefix starts every marker ID, so several canaries can share a
+	// table and an account
+	MarkerPrefix string `mapstructure:"marker_prefix"`
+
+	// MarkerInterval is how often a marker row is inserted
+	MarkerInterval time.Duration `mapstructure:"marker_interval"`
+
+	// PollInterval is how often the backend is queried for the pending
+	// markers; it is the resolution of the measured latency
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// MarkerTimeout is how long a marker is looked for before it is counted
+	// as lost. Rows older than twice this are deleted.
+	MarkerTimeout time.Duration `mapstructure:"marker_timeout"`
+
+	// QueryTimeout bounds each database statement and backend query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// Lookup is where the markers are looked for once exported
+	Lookup LookupConfig `mapstructure:"lookup"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// LookupConfig configures the NRDB query that finds an exported marker
+type LookupConfig struct {
+	// Endpoint is the NerdGraph URL; https://api.eu.newrelic.com/graphql
+	// for EU accounts
+	Endpoint string `mapstructure:"endpoint"`
+
+	// AccountID is the account the markers are exported to
+	AccountID int64 `mapstructure:"account_id"`
+
+	// APIKey is a User API key that can query the account
+	APIKey string `mapstructure:"api_key"`
+
+	// NRQL finds one marker; {marker_id} is replaced with its ID. The marker
+	// counts as arrived once the query returns a non-zero value.
+	NRQL string `mapstructure:"nrql"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Datasource == "" {
+		return errors.New("datasource must be specified")
+	}
+
+	if !tableName.MatchString(cfg.Table) {
+		return fmt.Errorf("table must be an unquoted identifier, optionally schema-qualified, got %q", cfg.Table)
+	}
+
+	if !markerPrefix.MatchString(cfg.MarkerPrefix) {
+		return fmt.Errorf("marker_prefix may only contain letters, digits, '_' and '-', got %q", cfg.MarkerPrefix)
+	}
+
+	if cfg.MarkerInterval <= 0 {
+		return fmt.Errorf("marker_interval must be positive, got %v", cfg.MarkerInterval)
+	}
+
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %v", cfg.PollInterval)
+	}
+
+	if cfg.MarkerTimeout <= cfg.PollInterval {
+		return fmt.Errorf("marker_timeout (%v) must be greater than poll_interval (%v)",
+			cfg.MarkerTimeout, cfg.PollInterval)
+	}
+
+	if cfg.QueryTimeout <= 0 {
+		return fmt.Errorf("query_timeout must be positive, got %v", cfg.QueryTimeout)
+	}
+
+	if cfg.QueryTimeout > cfg.PollInterval {
+		return fmt.Errorf("query_timeout (%v) cannot be greater than poll_interval (%v)",
+			cfg.QueryTimeout, cfg.PollInterval)
+	}
+
+	return cfg.Lookup.Validate()
+}
+
+// Validate checks if the lookup configuration is valid
+func (cfg *LookupConfig) Validate() error {
+	if u, err := url.Parse(cfg.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("lookup.endpoint must be an absolute URL, got %q", cfg.Endpoint)
+	}
+
+	if cfg.AccountID <= 0 {
+		return errors.New("lookup.account_id must be specified")
+	}
+
+	if cfg.APIKey == "" {
+		return errors.New("lookup.api_key must be specified")
+	}
+
+	if !strings.Contains(cfg.NRQL, MarkerPlaceholder) {
+		return fmt.Errorf("lookup.nrql must contain %s", MarkerPlaceholder)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Table:          "otel_canary_markers",
+		CreateTable:    true,
+		MarkerPrefix:   "canary",
+		MarkerInterval: time.Minute,
+		PollInterval:   15 * time.Second,
+		MarkerTimeout:  10 * time.Minute,
+		QueryTimeout:   10 * time.Second,
+		Lookup: LookupConfig{
+			Endpoint: "https://api.newrelic.com/graphql",
+			NRQL:     "SELECT count(*) FROM Metric WHERE metricName = 'db.canary.marker' AND marker_id = '" + MarkerPlaceholder + "' SINCE 1 hour ago",
+		},
+	}
+}
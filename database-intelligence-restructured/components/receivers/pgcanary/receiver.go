@@ -0,0 +1,288 @@
+package pgcanary
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+)
+
+// Metric names
+const (
+	metricLatency    = "db.canary.freshness.latency"
+	metricPendingAge = "db.canary.freshness.pending_age"
+	metricPending    = "db.canary.markers.pending"
+	metricInserted   = "db.canary.markers.inserted"
+	metricObserved   = "db.canary.markers.observed"
+	metricLost       = "db.canary.markers.lost"
+)
+
+// pendingMarker is a marker inserted but not yet seen at the backend
+type pendingMarker struct {
+	ID         string
+	InsertedAt time.Time
+}
+
+// canaryReceiver implements the receiver.Metrics interface
+type canaryReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Metrics
+
+	// openStore connects to the database; replaced in tests
+	openStore func(ctx context.Context, datasource string) (markerStore, error)
+	store     markerStore
+	lookup    markerLookup
+	now       func() time.Time
+	// backoff skips marker inserts while they fail
+	backoff *scrapebackoff.Backoff
+
+	startTime pcommon.Timestamp
+	pending   []pendingMarker
+	inserted  int64
+	observed  int64
+	lost      int64
+	// latency is the highest end-to-end latency of the markers found by
+	// the last check, zero when it found none
+	latency time.Duration
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newCanaryReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *canaryReceiver {
+	return &canaryReceiver{
+		config:    cfg,
+		logger:    logger,
+		consumer:  consumer,
+		openStore: openDBMarkerStore,
+		lookup:    newNRDBLookup(cfg.Lookup, cfg.QueryTimeout),
+		now:       time.Now,
+		backoff:   scrapebackoff.New(cfg.MarkerInterval, logger),
+	}
+}
+
+// Start implements the receiver.Metrics interface
+func (r *canaryReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting freshness canary receiver",
+		zap.String("table", r.config.Table),
+		zap.Duration("marker_interval", r.config.MarkerInterval),
+		zap.Duration("poll_interval", r.config.PollInterval),
+		zap.Duration("marker_timeout", r.config.MarkerTimeout))
+
+	store, err := r.openStore(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.store = store
+
+	if r.config.CreateTable {
+		if err := r.store.Exec(ctx, fmt.Sprintf(createTableQuery, r.config.Table)); err != nil {
+			r.store.Close()
+			return fmt.Errorf("failed to create marker table %s: %w", r.config.Table, err)
+		}
+	}
+	r.startTime = pcommon.NewTimestampFromTime(r.now())
+
+	// The marker loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *canaryReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down freshness canary receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.store != nil {
+		return r.store.Close()
+	}
+	return nil
+}
+
+// run inserts the first marker right away, then inserts a marker every
+// marker interval and checks the pending markers every poll interval
+func (r *canaryReceiver) run(ctx context.Context) {
+	markerTicker := time.NewTicker(r.config.MarkerInterval)
+	defer markerTicker.Stop()
+	pollTicker := time.NewTicker(r.config.PollInterval)
+	defer pollTicker.Stop()
+
+	r.insertMarker(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-markerTicker.C:
+			if r.backoff.Ready() {
+				r.insertMarker(ctx)
+			}
+		case <-pollTicker.C:
+			r.check(ctx)
+			if err := r.consumer.ConsumeMetrics(ctx, r.buildMetrics()); err != nil {
+				r.logger.Error("Failed to send canary metrics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// insertMarker writes a new marker row and deletes the rows no longer
+// looked for. A failed insert is logged and retried once the backoff
+// allows; the missing marker shows up as a growing pending age, not as a
+// loss.
+func (r *canaryReceiver) insertMarker(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	now := r.now()
+	marker := pendingMarker{
+		ID:         fmt.Sprintf("%s-%d", r.config.MarkerPrefix, now.UnixNano()),
+		InsertedAt: now,
+	}
+	if err := r.store.Exec(ctx, fmt.Sprintf(insertMarkerQuery, r.config.Table), marker.ID, marker.InsertedAt); err != nil {
+		r.logger.Error("Failed to insert canary marker", zap.String("marker_id", marker.ID), zap.Error(err),
+			zap.Duration("next_insert_in", r.backoff.Failure(err)))
+		return
+	}
+	r.backoff.Success()
+	r.pending = append(r.pending, marker)
+	r.inserted++
+
+	cutoff := now.Add(-2 * r.config.MarkerTimeout)
+	if err := r.store.Exec(ctx, fmt.Sprintf(deleteMarkersQuery, r.config.Table), cutoff); err != nil {
+		r.logger.Warn("Failed to delete old canary markers", zap.Error(err))
+	}
+}
+
+// check looks up every pending marker. A marker that has arrived is
+// removed with its latency, the time from insertion until the check that
+// found it; one older than marker_timeout is counted as lost. Markers whose
+// lookup fails stay pending.
+func (r *canaryReceiver) check(ctx context.Context) {
+	r.latency = 0
+	remaining := r.pending[:0]
+	for _, marker := range r.pending {
+		lookupCtx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+		seen, err := r.lookup.Seen(lookupCtx, marker.ID)
+		cancel()
+
+		now := r.now()
+		switch {
+		case err == nil && seen:
+			if latency := now.Sub(marker.InsertedAt); latency > r.latency {
+				r.latency = latency
+			}
+			r.observed++
+		case now.Sub(marker.InsertedAt) > r.config.MarkerTimeout:
+			r.logger.Warn("Canary marker did not arrive within marker_timeout",
+				zap.String("marker_id", marker.ID),
+				zap.Duration("marker_timeout", r.config.MarkerTimeout))
+			r.lost++
+		default:
+			if err != nil {
+				r.logger.Warn("Failed to look up canary marker", zap.String("marker_id", marker.ID), zap.Error(err))
+			}
+			remaining = append(remaining, marker)
+		}
+	}
+	r.pending = remaining
+}
+
+// buildMetrics reports the highest latency of the markers found by the last
+// check, when it found any, the age of the oldest pending marker and the
+// marker counters. The pending age keeps rising while nothing arrives, so it
+// alerts on a stalled pipeline that produces no latency points at all.
+func (r *canaryReceiver) buildMetrics() pmetric.Metrics {
+	now := pcommon.NewTimestampFromTime(r.now())
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "postgresql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("pgcanary_receiver")
+	sm.Scope().SetVersion("1.0.0")
+
+	if r.latency > 0 {
+		addGaugePoint(newGauge(sm, metricLatency, "Time from inserting a canary marker until it was found at the backend", "ms"), durationMs(r.latency), now)
+	}
+
+	var pendingAge time.Duration
+	if len(r.pending) > 0 {
+		pendingAge = r.now().Sub(r.pending[0].InsertedAt)
+	}
+	addGaugePoint(newGauge(sm, metricPendingAge, "Age of the oldest canary marker not yet found at the backend", "ms"), durationMs(pendingAge), now)
+	addGaugePoint(newGauge(sm, metricPending, "Canary markers inserted and not yet found", "{marker}"), float64(len(r.pending)), now)
+
+	r.addCounter(sm, metricInserted, "Canary markers inserted", r.inserted, now)
+	r.addCounter(sm, metricObserved, "Canary markers found at the backend", r.observed, now)
+	r.addCounter(sm, metricLost, "Canary markers not found within marker_timeout", r.lost, now)
+
+	return md
+}
+
+func (r *canaryReceiver) addCounter(sm pmetric.ScopeMetrics, name, description string, value int64, now pcommon.Timestamp) {
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit("{marker}")
+	sum := metric.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.SetIsMonotonic(true)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(r.startTime)
+	dp.SetTimestamp(now)
+	dp.SetIntValue(value)
+}
+
+func newGauge(sm pmetric.ScopeMetrics, name, description, unit string) pmetric.Gauge {
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	return metric.SetEmptyGauge()
+}
+
+func addGaugePoint(gauge pmetric.Gauge, value float64, now pcommon.Timestamp) {
+	dp := gauge.DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetDoubleValue(value)
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,314 @@
+package pgcanary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// execCall is one statement run against the stub store
+type execCall struct {
+	Query string
+	Args  []any
+}
+
+// stubStore records the statements it is asked to run
+type stubStore struct {
+	calls []execCall
+	err   error
+}
+
+func (s *stubStore) Exec(ctx context.Context, query string, args ...any) error {
+	s.calls = append(s.calls, execCall{Query: query, Args: args})
+	return s.err
+}
+
+func (s *stubStore) Close() error { return nil }
+
+// stubLookup reports the markers in arrived as seen
+type stubLookup struct {
+	arrived map[string]bool
+	err     error
+	asked   []string
+}
+
+func (l *stubLookup) Seen(ctx context.Context, markerID string) (bool, error) {
+	l.asked = append(l.asked, markerID)
+	return l.arrived[markerID], l.err
+}
+
+// fakeClock is advanced by the tests
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func (c *fakeClock) markerID(prefix string) string {
+	return prefix + "-" + strconv.FormatInt(c.t.UnixNano(), 10)
+}
+
+func newTestConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Datasource = "postgres://localhost:5432/shop"
+	cfg.Lookup.AccountID = 1234567
+	cfg.Lookup.APIKey = "NRAK-test"
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	return cfg
+}
+
+func newTestReceiver(store *stubStore, lookup *stubLookup) (*canaryReceiver, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	r := newCanaryReceiver(newTestConfig(), zap.NewNop(), consumertest.NewNop())
+	r.store = store
+	r.lookup = lookup
+	r.now = clock.now
+	return r, clock
+}
+
+// metricValues maps each metric name to the value of its data point
+func metricValues(md pmetric.Metrics) map[string]float64 {
+	values := make(map[string]float64)
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		m := metrics.At(i)
+		switch m.Type() {
+		case pmetric.MetricTypeGauge:
+			values[m.Name()] = m.Gauge().DataPoints().At(0).DoubleValue()
+		case pmetric.MetricTypeSum:
+			values[m.Name()] = float64(m.Sum().DataPoints().At(0).IntValue())
+		}
+	}
+	return values
+}
+
+func TestInsertMarker(t *testing.T) {
+	store := &stubStore{}
+	r, clock := newTestReceiver(store, &stubLookup{})
+
+	r.insertMarker(context.Background())
+
+	id := clock.markerID("canary")
+	require.Len(t, store.calls, 2)
+	assert.Equal(t, execCall{
+		Query: "INSERT INTO otel_canary_markers (marker_id, created_at) VALUES ($1, $2)",
+		Args:  []any{id, clock.t},
+	}, store.calls[0])
+	assert.Equal(t, execCall{
+		Query: "DELETE FROM otel_canary_markers WHERE created_at < $1",
+		Args:  []any{clock.t.Add(-20 * time.Minute)},
+	}, store.calls[1], "rows older than twice marker_timeout are deleted")
+	assert.Equal(t, []pendingMarker{{ID: id, InsertedAt: clock.t}}, r.pending)
+	assert.Equal(t, int64(1), r.inserted)
+
+	// A failed insert leaves nothing pending
+	store.err = errors.New("connection reset")
+	clock.advance(time.Minute)
+	r.insertMarker(context.Background())
+	assert.Len(t, r.pending, 1)
+	assert.Equal(t, int64(1), r.inserted)
+}
+
+func TestInsertMarkerBacksOffWhileFailing(t *testing.T) {
+	store := &stubStore{err: errors.New("connection refused")}
+	r, clock := newTestReceiver(store, &stubLookup{})
+	var statuses []component.Status
+	r.backoff.ReportStatus = func(event *component.StatusEvent) {
+		statuses = append(statuses, event.Status())
+	}
+
+	r.insertMarker(context.Background())
+	clock.advance(time.Minute)
+	r.insertMarker(context.Background())
+	assert.Equal(t, 2, r.backoff.Failures())
+	assert.Equal(t, 4*time.Minute, r.backoff.Interval())
+	assert.False(t, r.backoff.Ready(), "the next marker ticks are skipped")
+
+	store.err = nil
+	clock.advance(time.Minute)
+	r.insertMarker(context.Background())
+	assert.Equal(t, 0, r.backoff.Failures())
+	assert.Equal(t, time.Minute, r.backoff.Interval())
+	assert.Equal(t, int64(1), r.inserted)
+	assert.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, statuses)
+}
+
+func TestCheckMeasuresLatency(t *testing.T) {
+	lookup := &stubLookup{arrived: map[string]bool{}}
+	r, clock := newTestReceiver(&stubStore{}, lookup)
+
+	first := clock.markerID("canary")
+	r.insertMarker(context.Background())
+	clock.advance(time.Minute)
+	second := clock.markerID("canary")
+	r.insertMarker(context.Background())
+
+	// Nothing has arrived: both stay pending and the oldest ages
+	clock.advance(15 * time.Second)
+	r.check(context.Background())
+	assert.Equal(t, []string{first, second}, lookup.asked)
+	values := metricValues(r.buildMetrics())
+	assert.NotContains(t, values, metricLatency)
+	assert.Equal(t, 75000.0, values[metricPendingAge])
+	assert.Equal(t, 2.0, values[metricPending])
+
+	// The first marker arrives 90s after it was inserted
+	lookup.arrived[first] = true
+	clock.advance(15 * time.Second)
+	r.check(context.Background())
+	values = metricValues(r.buildMetrics())
+	assert.Equal(t, 90000.0, values[metricLatency])
+	assert.Equal(t, 30000.0, values[metricPendingAge], "the second marker is now the oldest")
+	assert.Equal(t, 1.0, values[metricPending])
+	assert.Equal(t, 2.0, values[metricInserted])
+	assert.Equal(t, 1.0, values[metricObserved])
+	assert.Equal(t, 0.0, values[metricLost])
+
+	// The latency is reported by the check that found the marker only
+	clock.advance(15 * time.Second)
+	r.check(context.Background())
+	assert.NotContains(t, metricValues(r.buildMetrics()), metricLatency)
+}
+
+func TestCheckCountsLostMarkers(t *testing.T) {
+	lookup := &stubLookup{err: errors.New("NRDB query failed with status 503")}
+	r, clock := newTestReceiver(&stubStore{}, lookup)
+
+	r.insertMarker(context.Background())
+
+	// Lookup errors keep the marker pending until marker_timeout
+	clock.advance(5 * time.Minute)
+	r.check(context.Background())
+	assert.Len(t, r.pending, 1)
+	assert.Equal(t, int64(0), r.lost)
+
+	clock.advance(5*time.Minute + time.Second)
+	r.check(context.Background())
+	assert.Empty(t, r.pending)
+
+	values := metricValues(r.buildMetrics())
+	assert.Equal(t, 1.0, values[metricLost])
+	assert.Equal(t, 0.0, values[metricObserved])
+	assert.Equal(t, 0.0, values[metricPendingAge])
+}
+
+func TestStartCreatesTable(t *testing.T) {
+	store := &stubStore{}
+	r, _ := newTestReceiver(store, &stubLookup{})
+	r.openStore = func(ctx context.Context, datasource string) (markerStore, error) { return store, nil }
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	require.NotEmpty(t, store.calls)
+	assert.Contains(t, store.calls[0].Query, "CREATE TABLE IF NOT EXISTS otel_canary_markers")
+
+	store = &stubStore{err: errors.New("permission denied for schema public")}
+	r, _ = newTestReceiver(store, &stubLookup{})
+	r.openStore = func(ctx context.Context, datasource string) (markerStore, error) { return store, nil }
+	assert.EqualError(t, r.Start(context.Background(), componenttest.NewNopHost()),
+		"failed to create marker table otel_canary_markers: permission denied for schema public")
+}
+
+func TestNRDBLookup(t *testing.T) {
+	var request struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	count := 0.0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "NRAK-test", req.Header.Get("API-Key"))
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"actor": map[string]any{"account": map[string]any{
+				"nrql": map[string]any{"results": []map[string]any{{"count": count}}},
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig().Lookup
+	cfg.Endpoint = server.URL
+	lookup := newNRDBLookup(cfg, time.Second)
+
+	seen, err := lookup.Seen(context.Background(), "canary-42")
+	require.NoError(t, err)
+	assert.False(t, seen)
+	assert.Equal(t, 1234567.0, request.Variables["accountId"])
+	assert.Equal(t, "SELECT count(*) FROM Metric WHERE metricName = 'db.canary.marker' AND marker_id = 'canary-42' SINCE 1 hour ago",
+		request.Variables["nrql"])
+
+	count = 1
+	seen, err = lookup.Seen(context.Background(), "canary-42")
+	require.NoError(t, err)
+	assert.True(t, seen)
+}
+
+func TestNRDBLookupErrors(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"errors":[{"message":"NRQL Syntax Error"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig().Lookup
+	cfg.Endpoint = server.URL
+	lookup := newNRDBLookup(cfg, time.Second)
+
+	_, err := lookup.Seen(context.Background(), "canary-42")
+	assert.EqualError(t, err, "NRDB query error: NRQL Syntax Error")
+
+	status = http.StatusForbidden
+	_, err = lookup.Seen(context.Background(), "canary-42")
+	assert.ErrorContains(t, err, "NRDB query failed with status 403")
+}
+
+func TestResultsShowMarker(t *testing.T) {
+	assert.False(t, resultsShowMarker(nil))
+	assert.False(t, resultsShowMarker([]map[string]any{{"count": 0.0}}))
+	assert.True(t, resultsShowMarker([]map[string]any{{"count": 2.0}}))
+	assert.True(t, resultsShowMarker([]map[string]any{{"marker_id": "canary-42"}}), "a SELECT * row")
+	assert.False(t, resultsShowMarker([]map[string]any{{"latest.timestamp": nil}}))
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, newTestConfig().Validate())
+
+	cfg := newTestConfig()
+	cfg.Table = "markers; DROP TABLE orders"
+	assert.ErrorContains(t, cfg.Validate(), "table must be an unquoted identifier")
+
+	cfg = newTestConfig()
+	cfg.Table = "monitoring.canary_markers"
+	assert.NoError(t, cfg.Validate())
+
+	cfg = newTestConfig()
+	cfg.MarkerPrefix = "it's"
+	assert.ErrorContains(t, cfg.Validate(), "marker_prefix may only contain")
+
+	cfg = newTestConfig()
+	cfg.MarkerTimeout = cfg.PollInterval
+	assert.EqualError(t, cfg.Validate(), "marker_timeout (15s) must be greater than poll_interval (15s)")
+
+	cfg = newTestConfig()
+	cfg.Lookup.NRQL = "SELECT count(*) FROM Metric"
+	assert.EqualError(t, cfg.Validate(), "lookup.nrql must contain {marker_id}")
+
+	cfg = newTestConfig()
+	cfg.Lookup.APIKey = ""
+	assert.EqualError(t, cfg.Validate(), "lookup.api_key must be specified")
+}
@@ -0,0 +1,162 @@
+package pgcanary
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// markerStore runs the marker statements against PostgreSQL
+type markerStore interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	Close() error
+}
+
+// dbMarkerStore is the markerStore backed by a database connection pool
+type dbMarkerStore struct {
+	db *sql.DB
+}
+
+// openDBMarkerStore connects to PostgreSQL and checks the connection
+func openDBMarkerStore(ctx context.Context, datasource string) (markerStore, error) {
+	db, err := sql.Open("postgres", datasource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Statements run one at a time, so a single connection is enough
+	db.SetMaxOpenConns(1)
+	return &dbMarkerStore{db: db}, nil
+}
+
+func (s *dbMarkerStore) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (s *dbMarkerStore) Close() error {
+	return s.db.Close()
+}
+
+// The %s placeholder is the validated table name
+const (
+	createTableQuery = `CREATE TABLE IF NOT EXISTS %s (
+  marker_id text PRIMARY KEY,
+  created_at timestamptz NOT NULL DEFAULT now()
+)`
+
+	insertMarkerQuery = `INSERT INTO %s (marker_id, created_at) VALUES ($1, $2)`
+
+	deleteMarkersQuery = `DELETE FROM %s WHERE created_at < $1`
+)
+
+// markerLookup reports whether a marker has arrived at the backend
+type markerLookup interface {
+	Seen(ctx context.Context, markerID string) (bool, error)
+}
+
+// nrdbLookup queries NRDB through NerdGraph
+type nrdbLookup struct {
+	config LookupConfig
+	client *http.Client
+}
+
+func newNRDBLookup(cfg LookupConfig, timeout time.Duration) *nrdbLookup {
+	return &nrdbLookup{
+		config: cfg,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// nrqlRequest passes the account and query as variables, so the NRQL needs
+// no GraphQL escaping
+const nrqlRequest = `query($accountId: Int!, $nrql: Nrql!) {
+  actor { account(id: $accountId) { nrql(query: $nrql) { results } } }
+}`
+
+// Seen runs the lookup NRQL for a marker
+func (l *nrdbLookup) Seen(ctx context.Context, markerID string) (bool, error) {
+	body, err := json.Marshal(map[string]any{
+		"query": nrqlRequest,
+		"variables": map[string]any{
+			"accountId": l.config.AccountID,
+			"nrql":      strings.ReplaceAll(l.config.NRQL, MarkerPlaceholder, markerID),
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-Key", l.config.APIKey)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query NRDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("NRDB query failed with status %d: %s", resp.StatusCode, msg)
+	}
+
+	var response struct {
+		Data struct {
+			Actor struct {
+				Account struct {
+					NRQL struct {
+						Results []map[string]any `json:"results"`
+					} `json:"nrql"`
+				} `json:"account"`
+			} `json:"actor"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return false, fmt.Errorf("failed to decode NRDB response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return false, fmt.Errorf("NRDB query error: %s", response.Errors[0].Message)
+	}
+
+	return resultsShowMarker(response.Data.Actor.Account.NRQL.Results), nil
+}
+
+// resultsShowMarker reports whether NRQL results contain the marker: an
+// aggregate such as count(*) is non-zero, or a SELECT * returned a row
+func resultsShowMarker(results []map[string]any) bool {
+	for _, row := range results {
+		for _, v := range row {
+			switch v := v.(type) {
+			case nil:
+			case float64:
+				if v > 0 {
+					return true
+				}
+			default:
+				return true
+			}
+		}
+	}
+	return false
+}
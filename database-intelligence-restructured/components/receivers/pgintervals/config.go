@@ -0,0 +1,84 @@
+package pgintervals
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// reservedKeys are the postgresql receiver settings the wrapper sets for
+// each collection group, so they are configured on the wrapper instead
+var reservedKeys = []string{"collection_interval", "databases", "exclude_databases"}
+
+// Config represents the receiver configuration
+type Config struct {
+	// CollectionInterval is the interval for databases without an override
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// DatabaseIntervals overrides the collection interval of the named
+	// databases, e.g. 10s for a hot primary and 10m for an archive
+	DatabaseIntervals map[string]time.Duration `mapstructure:"database_intervals"`
+
+	// Databases limits collection to these databases; empty means every
+	// database on the server
+	Databases []string `mapstructure:"databases"`
+
+	// ExcludeDatabases are never collected
+	ExcludeDatabases []string `mapstructure:"exclude_databases"`
+
+	// PostgreSQL holds the other postgresql receiver settings, such as
+	// endpoint, username, password, tls and metrics, shared by every
+	// collection group
+	PostgreSQL map[string]any `mapstructure:"postgresql"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive, got %v", cfg.CollectionInterval)
+	}
+
+	if len(cfg.DatabaseIntervals) == 0 {
+		return errors.New("database_intervals must override at least one database; use the postgresql receiver directly otherwise")
+	}
+
+	listed := make(map[string]bool, len(cfg.Databases))
+	for _, db := range cfg.Databases {
+		listed[db] = true
+	}
+	excluded := make(map[string]bool, len(cfg.ExcludeDatabases))
+	for _, db := range cfg.ExcludeDatabases {
+		excluded[db] = true
+	}
+
+	for db, interval := range cfg.DatabaseIntervals {
+		if db == "" {
+			return errors.New("database_intervals has an empty database name")
+		}
+		if interval <= 0 {
+			return fmt.Errorf("database_intervals[%s] must be positive, got %v", db, interval)
+		}
+		if excluded[db] {
+			return fmt.Errorf("database_intervals[%s] overrides an excluded database", db)
+		}
+		if len(cfg.Databases) > 0 && !listed[db] {
+			return fmt.Errorf("database_intervals[%s] overrides a database that is not in databases", db)
+		}
+	}
+
+	for _, key := range reservedKeys {
+		if _, ok := cfg.PostgreSQL[key]; ok {
+			return fmt.Errorf("set %s on the receiver, not under postgresql", key)
+		}
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: time.Minute,
+		PostgreSQL:         map[string]any{},
+	}
+}
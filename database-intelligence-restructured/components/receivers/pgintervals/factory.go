@@ -0,0 +1,91 @@
+package pgintervals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	typeStr   = "pgintervals"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotIntervals = errors.New("config is not for pgintervals receiver")
+
+// NewFactory creates a new per-database interval receiver factory. It
+// wraps the given postgresql receiver factory, which the distribution
+// passes in so this module does not depend on collector-contrib.
+func NewFactory(postgresql receiver.Factory) receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(func(ctx context.Context, settings receiver.Settings, cfg component.Config, consumer consumer.Metrics) (receiver.Metrics, error) {
+			return createMetricsReceiver(ctx, settings, cfg, consumer, postgresql)
+		}, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createMetricsReceiver creates one postgresql receiver per collection
+// group, all sending to the same consumer
+func createMetricsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Metrics,
+	postgresql receiver.Factory,
+) (receiver.Metrics, error) {
+	piCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotIntervals
+	}
+
+	// Validate the configuration
+	if err := piCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	r := &intervalsReceiver{logger: settings.Logger}
+	for _, group := range planGroups(piCfg) {
+		groupCfg := postgresql.CreateDefaultConfig()
+		if err := confmap.NewFromStringMap(groupSettings(piCfg.PostgreSQL, group)).Unmarshal(groupCfg); err != nil {
+			return nil, fmt.Errorf("invalid postgresql settings: %w", err)
+		}
+		if err := component.ValidateConfig(groupCfg); err != nil {
+			return nil, fmt.Errorf("invalid postgresql settings for the %v group: %w", group.Interval, err)
+		}
+
+		groupSettings := settings
+		groupSettings.ID = component.NewIDWithName(settings.ID.Type(), groupName(settings.ID, group))
+		groupSettings.Logger = settings.Logger.With(zap.Duration("collection_interval", group.Interval))
+
+		sub, err := postgresql.CreateMetricsReceiver(ctx, groupSettings, groupCfg, consumer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create postgresql receiver for the %v group: %w", group.Interval, err)
+		}
+		r.groups = append(r.groups, group)
+		r.receivers = append(r.receivers, sub)
+	}
+
+	return r, nil
+}
+
+// groupName names a group's receiver after the wrapper and its interval,
+// so the groups are told apart in the collector's own telemetry
+func groupName(id component.ID, group collectionGroup) string {
+	if id.Name() == "" {
+		return group.Interval.String()
+	}
+	return id.Name() + "/" + group.Interval.String()
+}
@@ -0,0 +1,51 @@
+package pgintervals
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// intervalsReceiver implements the receiver.Metrics interface by starting
+// and stopping one postgresql receiver per collection group
+type intervalsReceiver struct {
+	logger    *zap.Logger
+	groups    []collectionGroup
+	receivers []receiver.Metrics
+}
+
+// Start implements the receiver.Metrics interface. If a group fails to
+// start, the groups already started are shut down.
+func (r *intervalsReceiver) Start(ctx context.Context, host component.Host) error {
+	for i, group := range r.groups {
+		r.logger.Info("Starting PostgreSQL collection group",
+			zap.Duration("collection_interval", group.Interval),
+			zap.Strings("databases", group.Databases),
+			zap.Strings("exclude_databases", group.ExcludeDatabases),
+			zap.Bool("cluster_metrics", group.ClusterMetrics))
+
+		if err := r.receivers[i].Start(ctx, host); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				_ = r.receivers[j].Shutdown(ctx)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *intervalsReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down PostgreSQL collection groups")
+
+	var errs []error
+	for _, sub := range r.receivers {
+		if err := sub.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,272 @@
+package pgintervals
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+// stubConfig has the postgresql receiver settings the wrapper sets
+type stubConfig struct {
+	CollectionInterval time.Duration              `mapstructure:"collection_interval"`
+	Databases          []string                   `mapstructure:"databases"`
+	ExcludeDatabases   []string                   `mapstructure:"exclude_databases"`
+	Endpoint           string                     `mapstructure:"endpoint"`
+	Metrics            map[string]map[string]bool `mapstructure:"metrics"`
+}
+
+// stubReceiver emits one data point per database at its collection
+// interval, like the postgresql receiver's scrape loop
+type stubReceiver struct {
+	config   *stubConfig
+	consumer consumer.Metrics
+	startErr error
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func (r *stubReceiver) Start(ctx context.Context, host component.Host) error {
+	if r.startErr != nil {
+		return r.startErr
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.config.CollectionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				md := pmetric.NewMetrics()
+				gauge := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge()
+				for _, db := range r.config.Databases {
+					gauge.DataPoints().AppendEmpty().Attributes().PutStr("postgresql.database.name", db)
+				}
+				_ = r.consumer.ConsumeMetrics(ctx, md)
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *stubReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+// stubFactory stands in for the postgresql receiver factory and keeps the
+// receivers it creates
+type stubFactory struct {
+	receiver.Factory
+
+	mu        sync.Mutex
+	created   []*stubReceiver
+	ids       []component.ID
+	failStart int
+}
+
+func newStubFactory() *stubFactory {
+	f := &stubFactory{failStart: -1}
+	f.Factory = receiver.NewFactory(
+		component.MustNewType("postgresql"),
+		func() component.Config { return &stubConfig{CollectionInterval: 10 * time.Second} },
+		receiver.WithMetrics(func(ctx context.Context, settings receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			r := &stubReceiver{config: cfg.(*stubConfig), consumer: next}
+			if len(f.created) == f.failStart {
+				r.startErr = errors.New("connection refused")
+			}
+			f.created = append(f.created, r)
+			f.ids = append(f.ids, settings.ID)
+			return r, nil
+		}, component.StabilityLevelBeta),
+	)
+	return f
+}
+
+func newTestConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.CollectionInterval = time.Minute
+	cfg.DatabaseIntervals = map[string]time.Duration{
+		"orders":    10 * time.Second,
+		"payments":  10 * time.Second,
+		"archive":   10 * time.Minute,
+		"inventory": time.Minute,
+	}
+	cfg.ExcludeDatabases = []string{"template0"}
+	cfg.PostgreSQL = map[string]any{
+		"endpoint": "primary:5432",
+		"metrics": map[string]any{
+			"postgresql.deadlocks": map[string]any{"enabled": true},
+		},
+	}
+	return cfg
+}
+
+func TestPlanGroups(t *testing.T) {
+	groups := planGroups(newTestConfig())
+	assert.Equal(t, []collectionGroup{
+		{Interval: time.Minute, ExcludeDatabases: []string{"archive", "orders", "payments", "template0"}, ClusterMetrics: true},
+		{Interval: 10 * time.Second, Databases: []string{"orders", "payments"}},
+		{Interval: 10 * time.Minute, Databases: []string{"archive"}},
+	}, groups, "inventory's override equals collection_interval, so it stays in the default group")
+
+	cfg := newTestConfig()
+	cfg.ExcludeDatabases = nil
+	cfg.Databases = []string{"orders", "inventory", "archive"}
+	cfg.DatabaseIntervals = map[string]time.Duration{"orders": 10 * time.Second, "archive": 10 * time.Minute}
+	assert.Equal(t, []collectionGroup{
+		{Interval: time.Minute, Databases: []string{"inventory"}, ClusterMetrics: true},
+		{Interval: 10 * time.Second, Databases: []string{"orders"}},
+		{Interval: 10 * time.Minute, Databases: []string{"archive"}},
+	}, planGroups(cfg))
+
+	// When every listed database is overridden there is no default group,
+	// and the first override group reports the cluster metrics
+	cfg.Databases = []string{"orders", "archive"}
+	assert.Equal(t, []collectionGroup{
+		{Interval: 10 * time.Second, Databases: []string{"orders"}, ClusterMetrics: true},
+		{Interval: 10 * time.Minute, Databases: []string{"archive"}},
+	}, planGroups(cfg))
+}
+
+func TestGroupSettings(t *testing.T) {
+	cfg := newTestConfig()
+	groups := planGroups(cfg)
+
+	settings := groupSettings(cfg.PostgreSQL, groups[0])
+	assert.Equal(t, "1m0s", settings["collection_interval"])
+	assert.Equal(t, []any{}, settings["databases"])
+	assert.Equal(t, []any{"archive", "orders", "payments", "template0"}, settings["exclude_databases"])
+	assert.Equal(t, cfg.PostgreSQL["metrics"], settings["metrics"], "the cluster metrics group keeps the configured metrics")
+
+	settings = groupSettings(cfg.PostgreSQL, groups[1])
+	assert.Equal(t, "primary:5432", settings["endpoint"])
+	metrics := settings["metrics"].(map[string]any)
+	assert.Equal(t, map[string]any{"enabled": true}, metrics["postgresql.deadlocks"])
+	assert.Equal(t, map[string]any{"enabled": false}, metrics["postgresql.bgwriter.duration"])
+	assert.Len(t, metrics, len(clusterMetrics)+1)
+	assert.Len(t, cfg.PostgreSQL["metrics"], 1, "the shared settings are not modified")
+}
+
+func TestCreateReceiverConfiguresGroups(t *testing.T) {
+	stub := newStubFactory()
+	factory := NewFactory(stub)
+	settings := receivertest.NewNopSettings()
+	settings.ID = component.NewIDWithName(factory.Type(), "fleet")
+
+	_, err := factory.CreateMetricsReceiver(context.Background(), settings, newTestConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	require.Len(t, stub.created, 3)
+
+	assert.Equal(t, []component.ID{
+		component.NewIDWithName(factory.Type(), "fleet/1m0s"),
+		component.NewIDWithName(factory.Type(), "fleet/10s"),
+		component.NewIDWithName(factory.Type(), "fleet/10m0s"),
+	}, stub.ids)
+
+	def := stub.created[0].config
+	assert.Equal(t, time.Minute, def.CollectionInterval)
+	assert.Empty(t, def.Databases)
+	assert.Equal(t, []string{"archive", "orders", "payments", "template0"}, def.ExcludeDatabases)
+	assert.Equal(t, "primary:5432", def.Endpoint)
+	assert.NotContains(t, def.Metrics, "postgresql.bgwriter.duration")
+
+	hot := stub.created[1].config
+	assert.Equal(t, 10*time.Second, hot.CollectionInterval)
+	assert.Equal(t, []string{"orders", "payments"}, hot.Databases)
+	assert.Equal(t, map[string]bool{"enabled": false}, hot.Metrics["postgresql.database.count"])
+
+	cold := stub.created[2].config
+	assert.Equal(t, 10*time.Minute, cold.CollectionInterval)
+	assert.Equal(t, []string{"archive"}, cold.Databases)
+}
+
+func TestSchedulerFiresEachDatabaseAtItsInterval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CollectionInterval = 100 * time.Millisecond
+	cfg.Databases = []string{"orders", "archive"}
+	cfg.DatabaseIntervals = map[string]time.Duration{"orders": 20 * time.Millisecond}
+
+	sink := new(consumertest.MetricsSink)
+	r, err := NewFactory(newStubFactory()).CreateMetricsReceiver(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	time.Sleep(450 * time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	fires := make(map[string]int)
+	for _, md := range sink.AllMetrics() {
+		dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			db, _ := dps.At(i).Attributes().Get("postgresql.database.name")
+			fires[db.Str()]++
+		}
+	}
+
+	// 450ms is about 22 ticks at 20ms and 4 at 100ms; the bounds leave room
+	// for a slow scheduler
+	assert.GreaterOrEqual(t, fires["orders"], 10)
+	assert.GreaterOrEqual(t, fires["archive"], 2)
+	assert.LessOrEqual(t, fires["archive"], 5)
+	assert.Greater(t, fires["orders"], 2*fires["archive"])
+}
+
+func TestStartFailureStopsStartedGroups(t *testing.T) {
+	stub := newStubFactory()
+	stub.failStart = 1
+
+	r, err := NewFactory(stub).CreateMetricsReceiver(context.Background(), receivertest.NewNopSettings(), newTestConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+
+	assert.EqualError(t, r.Start(context.Background(), componenttest.NewNopHost()), "connection refused")
+	assert.NotNil(t, stub.created[0].cancel, "the first group was started")
+	assert.Nil(t, stub.created[2].cancel, "groups after the failure are not started")
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, newTestConfig().Validate())
+
+	cfg := newTestConfig()
+	cfg.DatabaseIntervals = nil
+	assert.ErrorContains(t, cfg.Validate(), "database_intervals must override at least one database")
+
+	cfg = newTestConfig()
+	cfg.DatabaseIntervals["orders"] = 0
+	assert.EqualError(t, cfg.Validate(), "database_intervals[orders] must be positive, got 0s")
+
+	cfg = newTestConfig()
+	cfg.ExcludeDatabases = []string{"archive"}
+	assert.EqualError(t, cfg.Validate(), "database_intervals[archive] overrides an excluded database")
+
+	cfg = newTestConfig()
+	cfg.Databases = []string{"orders", "payments", "inventory"}
+	assert.EqualError(t, cfg.Validate(), "database_intervals[archive] overrides a database that is not in databases")
+
+	cfg = newTestConfig()
+	cfg.PostgreSQL["collection_interval"] = "5s"
+	assert.EqualError(t, cfg.Validate(), "set collection_interval on the receiver, not under postgresql")
+}
@@ -0,0 +1,123 @@
+package pgintervals
+
+import (
+	"sort"
+	"time"
+)
+
+// clusterMetrics are the postgresql receiver metrics that describe the
+// server rather than a database. Every group would report them, so only
+// the first group keeps them enabled.
+var clusterMetrics = []string{
+	"postgresql.bgwriter.buffers.allocated",
+	"postgresql.bgwriter.buffers.writes",
+	"postgresql.bgwriter.checkpoint.count",
+	"postgresql.bgwriter.duration",
+	"postgresql.bgwriter.maxwritten",
+	"postgresql.connection.max",
+	"postgresql.database.count",
+	"postgresql.database.locks",
+	"postgresql.replication.data_delay",
+	"postgresql.wal.age",
+	"postgresql.wal.delay",
+	"postgresql.wal.lag",
+}
+
+// collectionGroup is the set of databases collected by one postgresql
+// receiver instance at one interval
+type collectionGroup struct {
+	Interval time.Duration
+	// Databases lists the databases to collect; empty means every database
+	// that is not excluded
+	Databases        []string
+	ExcludeDatabases []string
+	// ClusterMetrics keeps the server-wide metrics enabled
+	ClusterMetrics bool
+}
+
+// planGroups splits the databases into one group per distinct interval. The
+// group at collection_interval comes first and, when databases is empty,
+// collects every database without an override, including ones created
+// later. Overrides equal to collection_interval stay in that group.
+func planGroups(cfg *Config) []collectionGroup {
+	byInterval := make(map[time.Duration][]string)
+	overridden := make(map[string]bool)
+	for db, interval := range cfg.DatabaseIntervals {
+		if interval == cfg.CollectionInterval {
+			continue
+		}
+		byInterval[interval] = append(byInterval[interval], db)
+		overridden[db] = true
+	}
+
+	var groups []collectionGroup
+	if len(cfg.Databases) > 0 {
+		var rest []string
+		for _, db := range cfg.Databases {
+			if !overridden[db] {
+				rest = append(rest, db)
+			}
+		}
+		if len(rest) > 0 {
+			groups = append(groups, collectionGroup{Interval: cfg.CollectionInterval, Databases: rest})
+		}
+	} else {
+		exclude := append([]string(nil), cfg.ExcludeDatabases...)
+		for db := range overridden {
+			exclude = append(exclude, db)
+		}
+		sort.Strings(exclude)
+		groups = append(groups, collectionGroup{Interval: cfg.CollectionInterval, ExcludeDatabases: exclude})
+	}
+
+	intervals := make([]time.Duration, 0, len(byInterval))
+	for interval := range byInterval {
+		intervals = append(intervals, interval)
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	for _, interval := range intervals {
+		dbs := byInterval[interval]
+		sort.Strings(dbs)
+		groups = append(groups, collectionGroup{Interval: interval, Databases: dbs})
+	}
+
+	if len(groups) > 0 {
+		groups[0].ClusterMetrics = true
+	}
+	return groups
+}
+
+// groupSettings returns the postgresql receiver settings of a group: the
+// shared settings with the group's interval and databases, and the cluster
+// metrics disabled unless the group reports them
+func groupSettings(shared map[string]any, group collectionGroup) map[string]any {
+	settings := make(map[string]any, len(shared)+3)
+	for k, v := range shared {
+		settings[k] = v
+	}
+	settings["collection_interval"] = group.Interval.String()
+	settings["databases"] = toAnySlice(group.Databases)
+	settings["exclude_databases"] = toAnySlice(group.ExcludeDatabases)
+
+	if !group.ClusterMetrics {
+		metrics := make(map[string]any)
+		if configured, ok := shared["metrics"].(map[string]any); ok {
+			for k, v := range configured {
+				metrics[k] = v
+			}
+		}
+		for _, name := range clusterMetrics {
+			metrics[name] = map[string]any{"enabled": false}
+		}
+		settings["metrics"] = metrics
+	}
+	return settings
+}
+
+func toAnySlice(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
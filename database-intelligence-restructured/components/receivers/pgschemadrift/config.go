@@ -0,0 +1,108 @@
+package pgschemadrift
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the PostgreSQL connection string
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often table and index definitions are
+	// snapshotted and compared with the previous snapshot
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each catalog query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// Tables are the monitored tables, as schema.table patterns. Either part
+	// may use shell wildcards ("public.order_*", "sales.*"); a pattern
+	// without a schema refers to the public schema.
+	Tables []string `mapstructure:"tables"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	if len(cfg.Tables) == 0 {
+		return errors.New("at least one table must be specified")
+	}
+
+	for _, pattern := range cfg.Tables {
+		if _, err := parseTablePattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 5 * time.Minute,
+		QueryTimeout:       10 * time.Second,
+	}
+}
+
+// tablePattern matches tables by schema and name
+type tablePattern struct {
+	schema string
+	table  string
+}
+
+// parseTablePattern splits a schema.table pattern and checks both parts
+func parseTablePattern(pattern string) (tablePattern, error) {
+	p := tablePattern{schema: "public", table: pattern}
+	if schema, table, found := strings.Cut(pattern, "."); found {
+		p = tablePattern{schema: schema, table: table}
+	}
+
+	if p.schema == "" || p.table == "" || strings.Contains(p.table, ".") {
+		return p, fmt.Errorf("invalid table pattern %q, expected schema.table", pattern)
+	}
+	for _, part := range []string{p.schema, p.table} {
+		if _, err := path.Match(part, ""); err != nil {
+			return p, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	return p, nil
+}
+
+// tableMatcher reports whether a table is monitored
+type tableMatcher []tablePattern
+
+// newTableMatcher parses validated table patterns
+func newTableMatcher(patterns []string) tableMatcher {
+	m := make(tableMatcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		if p, err := parseTablePattern(pattern); err == nil {
+			m = append(m, p)
+		}
+	}
+	return m
+}
+
+func (m tableMatcher) matches(schema, table string) bool {
+	for _, p := range m {
+		schemaOK, _ := path.Match(p.schema, schema)
+		tableOK, _ := path.Match(p.table, table)
+		if schemaOK && tableOK {
+			return true
+		}
+	}
+	return false
+}
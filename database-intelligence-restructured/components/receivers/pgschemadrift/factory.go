@@ -0,0 +1,54 @@
+package pgschemadrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "pgschemadrift"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotSchemaDrift = errors.New("config is not for pgschemadrift receiver")
+
+// NewFactory creates a new PostgreSQL schema drift receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createLogsReceiver creates a logs receiver based on provided config.
+func createLogsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Logs,
+) (receiver.Logs, error) {
+	sdCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotSchemaDrift
+	}
+
+	// Validate the configuration
+	if err := sdCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	rcv := newSchemaDriftReceiver(sdCfg, settings.Logger, consumer)
+	rcv.backoff.ReportStatus = settings.ReportStatus
+	return rcv, nil
+}
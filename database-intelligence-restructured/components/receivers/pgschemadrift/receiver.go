@@ -0,0 +1,235 @@
+package pgschemadrift
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// eventName identifies schema drift log records
+const eventName = "db.schema.drift"
+
+// schemaDriftReceiver implements the receiver.Logs interface
+type schemaDriftReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Logs
+	matcher  tableMatcher
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	// previous is the last snapshot, nil until the baseline is taken. It is
+	// only kept in memory, so a restart takes a new baseline.
+	previous snapshot
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newSchemaDriftReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Logs) *schemaDriftReceiver {
+	return &schemaDriftReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		matcher:    newTableMatcher(cfg.Tables),
+		openSource: sqlsource.OpenPostgres,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+// Start implements the receiver.Logs interface
+func (r *schemaDriftReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting PostgreSQL schema drift receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Strings("tables", r.config.Tables))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Logs interface
+func (r *schemaDriftReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down PostgreSQL schema drift receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect takes the baseline snapshot right away, so changes made during
+// the first interval are not missed, then compares a new snapshot with the
+// previous one every interval
+func (r *schemaDriftReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.backoff.Ready() {
+			r.collectOnce(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectOnce takes one snapshot and sends the drift it found
+func (r *schemaDriftReceiver) collectOnce(ctx context.Context) {
+	ld, err := r.scrape(ctx)
+	if err != nil {
+		r.logger.Error("Failed to snapshot table definitions", zap.Error(err),
+			zap.Duration("next_collection_in", r.backoff.Failure(err)))
+		return
+	}
+	r.backoff.Success()
+	if ld.LogRecordCount() == 0 {
+		return
+	}
+	if err := r.consumer.ConsumeLogs(ctx, ld); err != nil {
+		r.logger.Error("Failed to send schema drift logs", zap.Error(err))
+	}
+}
+
+// scrape snapshots the monitored tables and returns one log record per
+// table that changed since the previous snapshot. The first snapshot is the
+// baseline and returns no records; after an error the previous snapshot is
+// kept, so the changes are reported by the next successful one.
+func (r *schemaDriftReceiver) scrape(ctx context.Context) (plog.Logs, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	current, err := querySnapshot(ctx, r.source, r.matcher)
+	if err != nil {
+		return plog.NewLogs(), err
+	}
+
+	previous := r.previous
+	r.previous = current
+	if previous == nil {
+		if len(current) == 0 {
+			r.logger.Warn("No tables match the monitored table patterns", zap.Strings("tables", r.config.Tables))
+		} else {
+			r.logger.Info("Recorded baseline schema snapshot", zap.Int("tables", len(current)))
+		}
+		return plog.NewLogs(), nil
+	}
+
+	return r.buildLogs(diffSnapshots(previous, current), pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// buildLogs emits one log record per changed table. The body summarizes the
+// changes, one per line; the attributes hold them structured.
+func (r *schemaDriftReceiver) buildLogs(diffs []tableDiff, now pcommon.Timestamp) plog.Logs {
+	ld := plog.NewLogs()
+	if len(diffs) == 0 {
+		return ld
+	}
+
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("db.system", "postgresql")
+	for k, v := range r.config.ResourceAttributes {
+		rl.Resource().Attributes().PutStr(k, v)
+	}
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("pgschemadrift_receiver")
+	sl.Scope().SetVersion("1.0.0")
+
+	for _, diff := range diffs {
+		record := sl.LogRecords().AppendEmpty()
+		record.SetTimestamp(now)
+		record.SetObservedTimestamp(now)
+		record.SetSeverityNumber(plog.SeverityNumberInfo)
+		record.SetSeverityText("INFO")
+
+		lines := make([]string, 0, len(diff.Changes)+1)
+		lines = append(lines, "Schema change on "+diff.Table.String())
+		kinds := make(map[string]bool)
+		for _, c := range diff.Changes {
+			lines = append(lines, c.String())
+			kinds[c.Kind] = true
+		}
+		record.Body().SetStr(strings.Join(lines, "\n"))
+
+		attrs := record.Attributes()
+		attrs.PutStr("event.name", eventName)
+		attrs.PutStr("db.schema_drift.schema", diff.Table.Schema)
+		attrs.PutStr("db.schema_drift.table", diff.Table.Table)
+		attrs.PutInt("db.schema_drift.change_count", int64(len(diff.Changes)))
+
+		kindList := make([]string, 0, len(kinds))
+		for kind := range kinds {
+			kindList = append(kindList, kind)
+		}
+		sort.Strings(kindList)
+		kindSlice := attrs.PutEmptySlice("db.schema_drift.change_kinds")
+		for _, kind := range kindList {
+			kindSlice.AppendEmpty().SetStr(kind)
+		}
+
+		changes := attrs.PutEmptySlice("db.schema_drift.changes")
+		for _, c := range diff.Changes {
+			m := changes.AppendEmpty().SetEmptyMap()
+			m.PutStr("kind", c.Kind)
+			m.PutStr("object", c.Object)
+			if c.Before != "" {
+				m.PutStr("before", c.Before)
+			}
+			if c.After != "" {
+				m.PutStr("after", c.After)
+			}
+		}
+	}
+
+	return ld
+}
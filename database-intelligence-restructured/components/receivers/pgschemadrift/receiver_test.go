@@ -0,0 +1,341 @@
+package pgschemadrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the catalog queries with fixed rows
+type stubSource struct {
+	columns [][]any
+	indexes [][]any
+	err     error
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	switch query {
+	case columnsQuery:
+		return sqlsourcetest.NewRows(s.columns), nil
+	case indexesQuery:
+		return sqlsourcetest.NewRows(s.indexes), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+// Catalog rows for the e-commerce schema the generators create, before and
+// after a migration that widened a column, added one, dropped another,
+// replaced an index and dropped the audit table
+var (
+	baselineColumns = [][]any{
+		{"public", "audit_log", "id", "bigint", false, ""},
+		{"public", "audit_log", "entry", "text", true, ""},
+		{"public", "orders", "id", "integer", false, "nextval('orders_id_seq'::regclass)"},
+		{"public", "orders", "customer_email", "character varying(100)", true, ""},
+		{"public", "orders", "legacy_flag", "boolean", true, "false"},
+		{"public", "orders", "created_at", "timestamp without time zone", false, "now()"},
+		{"public", "products", "sku", "text", false, ""},
+		{"sales", "quotas", "region", "text", false, ""},
+	}
+	baselineIndexes = [][]any{
+		{"public", "audit_log", "audit_log_pkey", "CREATE UNIQUE INDEX audit_log_pkey ON public.audit_log USING btree (id)"},
+		{"public", "orders", "orders_created_at_idx", "CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at)"},
+		{"public", "orders", "orders_email_idx", "CREATE INDEX orders_email_idx ON public.orders USING btree (customer_email)"},
+		{"public", "orders", "orders_pkey", "CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)"},
+		{"public", "products", "products_pkey", "CREATE UNIQUE INDEX products_pkey ON public.products USING btree (sku)"},
+	}
+
+	migratedColumns = [][]any{
+		{"public", "order_items", "order_id", "integer", false, ""},
+		{"public", "orders", "id", "integer", false, "nextval('orders_id_seq'::regclass)"},
+		{"public", "orders", "customer_email", "character varying(255)", false, ""},
+		{"public", "orders", "created_at", "timestamp without time zone", false, "now()"},
+		{"public", "orders", "discount", "numeric(10,2)", true, "0"},
+		{"public", "products", "sku", "text", false, ""},
+		{"sales", "quotas", "region", "text", false, ""},
+		{"sales", "quotas", "target", "numeric", true, ""},
+	}
+	migratedIndexes = [][]any{
+		{"public", "orders", "orders_email_idx", "CREATE INDEX orders_email_idx ON public.orders USING hash (customer_email)"},
+		{"public", "orders", "orders_pkey", "CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)"},
+		{"public", "orders", "orders_created_at_brin", "CREATE INDEX orders_created_at_brin ON public.orders USING brin (created_at)"},
+		{"public", "products", "products_pkey", "CREATE UNIQUE INDEX products_pkey ON public.products USING btree (sku)"},
+	}
+)
+
+// monitoredTables leaves out sales, so its new column is not reported
+var monitoredTables = []string{"orders", "order_*", "audit_log", "public.products"}
+
+func querySnapshotFrom(t *testing.T, columns, indexes [][]any) snapshot {
+	t.Helper()
+	snap, err := querySnapshot(context.Background(), &stubSource{columns: columns, indexes: indexes}, newTableMatcher(monitoredTables))
+	require.NoError(t, err)
+	return snap
+}
+
+func newTestReceiver(source *stubSource) *schemaDriftReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "postgres://localhost:5432/shop"
+	cfg.Tables = monitoredTables
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newSchemaDriftReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+func TestQuerySnapshot(t *testing.T) {
+	snap := querySnapshotFrom(t, baselineColumns, baselineIndexes)
+
+	assert.Len(t, snap, 3, "sales.quotas is not monitored")
+	orders := snap[tableKey{"public", "orders"}]
+	require.NotNil(t, orders)
+	assert.Equal(t, []column{
+		{Name: "id", Type: "integer", Default: "nextval('orders_id_seq'::regclass)"},
+		{Name: "customer_email", Type: "character varying(100)", Nullable: true},
+		{Name: "legacy_flag", Type: "boolean", Nullable: true, Default: "false"},
+		{Name: "created_at", Type: "timestamp without time zone", Default: "now()"},
+	}, orders.Columns)
+	assert.Len(t, orders.Indexes, 3)
+	assert.Equal(t, "CREATE UNIQUE INDEX orders_pkey ON public.orders USING btree (id)", orders.Indexes["orders_pkey"])
+}
+
+func TestQuerySnapshotErrors(t *testing.T) {
+	_, err := querySnapshot(context.Background(), &stubSource{err: errors.New("permission denied")}, newTableMatcher(monitoredTables))
+	assert.EqualError(t, err, "failed to query columns: permission denied")
+
+	_, err = querySnapshot(context.Background(), &stubSource{columns: [][]any{{"public", "orders"}}}, newTableMatcher(monitoredTables))
+	assert.ErrorContains(t, err, "failed to scan column row")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	before := querySnapshotFrom(t, baselineColumns, baselineIndexes)
+	after := querySnapshotFrom(t, migratedColumns, migratedIndexes)
+
+	diffs := diffSnapshots(before, after)
+	assert.Equal(t, []tableDiff{
+		{
+			Table: tableKey{"public", "audit_log"},
+			Changes: []change{
+				{Kind: changeTableDropped, Object: "public.audit_log", Before: "id bigint NOT NULL, entry text"},
+			},
+		},
+		{
+			Table: tableKey{"public", "order_items"},
+			Changes: []change{
+				{Kind: changeTableCreated, Object: "public.order_items", After: "order_id integer NOT NULL"},
+			},
+		},
+		{
+			Table: tableKey{"public", "orders"},
+			Changes: []change{
+				{Kind: changeColumnAltered, Object: "customer_email", Before: "character varying(100)", After: "character varying(255) NOT NULL"},
+				{Kind: changeColumnAdded, Object: "discount", After: "numeric(10,2) DEFAULT 0"},
+				{Kind: changeColumnDropped, Object: "legacy_flag", Before: "boolean DEFAULT false"},
+				{Kind: changeIndexCreated, Object: "orders_created_at_brin", After: "CREATE INDEX orders_created_at_brin ON public.orders USING brin (created_at)"},
+				{Kind: changeIndexDropped, Object: "orders_created_at_idx", Before: "CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at)"},
+				{Kind: changeIndexAltered, Object: "orders_email_idx",
+					Before: "CREATE INDEX orders_email_idx ON public.orders USING btree (customer_email)",
+					After:  "CREATE INDEX orders_email_idx ON public.orders USING hash (customer_email)"},
+			},
+		},
+	}, diffs)
+
+	assert.Empty(t, diffSnapshots(after, after), "an unchanged schema has no diff")
+}
+
+func TestChangeString(t *testing.T) {
+	assert.Equal(t, "column altered customer_email: character varying(100) -> character varying(255) NOT NULL",
+		change{Kind: changeColumnAltered, Object: "customer_email", Before: "character varying(100)", After: "character varying(255) NOT NULL"}.String())
+	assert.Equal(t, "column added discount: numeric(10,2)",
+		change{Kind: changeColumnAdded, Object: "discount", After: "numeric(10,2)"}.String())
+	assert.Equal(t, "table dropped public.audit_log: id bigint NOT NULL",
+		change{Kind: changeTableDropped, Object: "public.audit_log", Before: "id bigint NOT NULL"}.String())
+}
+
+func TestScrapeEmitsDrift(t *testing.T) {
+	source := &stubSource{columns: baselineColumns, indexes: baselineIndexes}
+	r := newTestReceiver(source)
+
+	ld, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, ld.LogRecordCount(), "the first snapshot is the baseline")
+
+	source.columns, source.indexes = migratedColumns, migratedIndexes
+	ld, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, ld.LogRecordCount())
+
+	rl := ld.ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{
+		"db.system":              "postgresql",
+		"deployment.environment": "test",
+	}, rl.Resource().Attributes().AsRaw())
+
+	record := rl.ScopeLogs().At(0).LogRecords().At(2)
+	assert.Equal(t, "Schema change on public.orders\n"+
+		"column altered customer_email: character varying(100) -> character varying(255) NOT NULL\n"+
+		"column added discount: numeric(10,2) DEFAULT 0\n"+
+		"column dropped legacy_flag: boolean DEFAULT false\n"+
+		"index created orders_created_at_brin: CREATE INDEX orders_created_at_brin ON public.orders USING brin (created_at)\n"+
+		"index dropped orders_created_at_idx: CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at)\n"+
+		"index altered orders_email_idx: CREATE INDEX orders_email_idx ON public.orders USING btree (customer_email) -> CREATE INDEX orders_email_idx ON public.orders USING hash (customer_email)",
+		record.Body().Str())
+
+	attrs := record.Attributes().AsRaw()
+	assert.Equal(t, eventName, attrs["event.name"])
+	assert.Equal(t, "public", attrs["db.schema_drift.schema"])
+	assert.Equal(t, "orders", attrs["db.schema_drift.table"])
+	assert.Equal(t, int64(6), attrs["db.schema_drift.change_count"])
+	assert.Equal(t, []any{
+		changeColumnAdded, changeColumnAltered, changeColumnDropped,
+		changeIndexAltered, changeIndexCreated, changeIndexDropped,
+	}, attrs["db.schema_drift.change_kinds"])
+	changes := attrs["db.schema_drift.changes"].([]any)
+	require.Len(t, changes, 6)
+	assert.Equal(t, map[string]any{
+		"kind":   changeColumnAdded,
+		"object": "discount",
+		"after":  "numeric(10,2) DEFAULT 0",
+	}, changes[1])
+
+	// The migrated schema is now the one compared against
+	ld, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, ld.LogRecordCount())
+}
+
+func TestScrapeErrorKeepsPreviousSnapshot(t *testing.T) {
+	source := &stubSource{columns: baselineColumns, indexes: baselineIndexes}
+	r := newTestReceiver(source)
+
+	_, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	source.err = errors.New("canceling statement due to statement timeout")
+	_, err = r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to query columns: canceling statement due to statement timeout")
+
+	source.err = nil
+	source.columns, source.indexes = migratedColumns, migratedIndexes
+	ld, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, ld.LogRecordCount(), "changes are reported by the next successful snapshot")
+}
+
+func TestCollectBacksOffWhileFailing(t *testing.T) {
+	source := &stubSource{columns: baselineColumns, indexes: baselineIndexes, err: errors.New("connection refused")}
+	sink := &consumertest.LogsSink{}
+	r := newTestReceiver(source)
+	r.config.CollectionInterval = time.Minute
+	r.backoff = scrapebackoff.New(r.config.CollectionInterval, zap.NewNop())
+	r.consumer = sink
+	var statuses []component.Status
+	r.backoff.ReportStatus = func(event *component.StatusEvent) {
+		statuses = append(statuses, event.Status())
+	}
+
+	r.collectOnce(context.Background())
+	r.collectOnce(context.Background())
+	assert.Equal(t, 2, r.backoff.Failures())
+	assert.Equal(t, 4*time.Minute, r.backoff.Interval())
+	assert.False(t, r.backoff.Ready(), "the next ticks are skipped")
+
+	// The baseline is taken once the database is back, then drift is sent
+	source.err = nil
+	r.collectOnce(context.Background())
+	assert.Equal(t, 0, r.backoff.Failures())
+	assert.Equal(t, time.Minute, r.backoff.Interval())
+
+	source.columns, source.indexes = migratedColumns, migratedIndexes
+	r.collectOnce(context.Background())
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, 3, sink.AllLogs()[0].LogRecordCount())
+	assert.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, statuses)
+}
+
+func TestTableMatcher(t *testing.T) {
+	m := newTableMatcher([]string{"orders", "sales.*", "app_?.user_*"})
+
+	assert.True(t, m.matches("public", "orders"))
+	assert.False(t, m.matches("archive", "orders"), "a pattern without schema means public")
+	assert.True(t, m.matches("sales", "quotas"))
+	assert.True(t, m.matches("app_1", "user_roles"))
+	assert.False(t, m.matches("app_10", "user_roles"))
+	assert.False(t, m.matches("public", "customers"))
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{columns: baselineColumns, indexes: baselineIndexes}
+	sqlsourcetest.Lifecycle(t, "postgres://localhost:5432/shop", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "no tables",
+			modify:  func(cfg *Config) { cfg.Tables = nil },
+			wantErr: "at least one table must be specified",
+		},
+		{
+			name:    "too many dots",
+			modify:  func(cfg *Config) { cfg.Tables = []string{"shop.public.orders"} },
+			wantErr: `invalid table pattern "shop.public.orders"`,
+		},
+		{
+			name:    "empty schema",
+			modify:  func(cfg *Config) { cfg.Tables = []string{".orders"} },
+			wantErr: `invalid table pattern ".orders"`,
+		},
+		{
+			name:    "bad wildcard",
+			modify:  func(cfg *Config) { cfg.Tables = []string{"public.order_[a"} },
+			wantErr: "syntax error in pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "postgres://localhost:5432/shop"
+			cfg.Tables = []string{"orders"}
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
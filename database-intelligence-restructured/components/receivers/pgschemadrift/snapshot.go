@@ -0,0 +1,205 @@
+package pgschemadrift
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kinds of schema change
+const (
+	changeTableCreated  = "table_created"
+	changeTableDropped  = "table_dropped"
+	changeColumnAdded   = "column_added"
+	changeColumnDropped = "column_dropped"
+	changeColumnAltered = "column_altered"
+	changeIndexCreated  = "index_created"
+	changeIndexDropped  = "index_dropped"
+	changeIndexAltered  = "index_altered"
+)
+
+// tableKey identifies a table
+type tableKey struct {
+	Schema string
+	Table  string
+}
+
+func (k tableKey) String() string {
+	return k.Schema + "." + k.Table
+}
+
+// column is the definition of one table column
+type column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+}
+
+// definition renders the column as in CREATE TABLE, without its name
+func (c column) definition() string {
+	def := c.Type
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
+
+// tableDef is the definition of one table: its columns in order and its
+// indexes by name
+type tableDef struct {
+	Columns []column
+	Indexes map[string]string
+}
+
+// snapshot holds the definitions of the monitored tables
+type snapshot map[tableKey]*tableDef
+
+// table returns the definition for key, adding an empty one if needed
+func (s snapshot) table(key tableKey) *tableDef {
+	def, exists := s[key]
+	if !exists {
+		def = &tableDef{Indexes: make(map[string]string)}
+		s[key] = def
+	}
+	return def
+}
+
+// change is one difference between two definitions of a table. Object is
+// the column, index or table changed; Before and After are its definitions,
+// empty for an object that did not exist.
+type change struct {
+	Kind   string
+	Object string
+	Before string
+	After  string
+}
+
+// String describes the change on one line
+func (c change) String() string {
+	s := strings.ReplaceAll(c.Kind, "_", " ") + " " + c.Object
+	switch {
+	case c.Before != "" && c.After != "":
+		return s + ": " + c.Before + " -> " + c.After
+	case c.After != "":
+		return s + ": " + c.After
+	case c.Before != "":
+		return s + ": " + c.Before
+	}
+	return s
+}
+
+// tableDiff lists the changes to one table
+type tableDiff struct {
+	Table   tableKey
+	Changes []change
+}
+
+// diffSnapshots compares two snapshots and returns the changed tables,
+// sorted by schema and name. A renamed column or index shows up as dropped
+// and added, since the catalog does not record renames.
+func diffSnapshots(before, after snapshot) []tableDiff {
+	keys := make(map[tableKey]bool, len(after))
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var diffs []tableDiff
+	for key := range keys {
+		oldDef, existed := before[key]
+		newDef, exists := after[key]
+
+		var changes []change
+		switch {
+		case !existed:
+			changes = []change{{Kind: changeTableCreated, Object: key.String(), After: columnList(newDef.Columns)}}
+		case !exists:
+			changes = []change{{Kind: changeTableDropped, Object: key.String(), Before: columnList(oldDef.Columns)}}
+		default:
+			changes = append(diffColumns(oldDef.Columns, newDef.Columns), diffIndexes(oldDef.Indexes, newDef.Indexes)...)
+		}
+
+		if len(changes) > 0 {
+			diffs = append(diffs, tableDiff{Table: key, Changes: changes})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Table.Schema != diffs[j].Table.Schema {
+			return diffs[i].Table.Schema < diffs[j].Table.Schema
+		}
+		return diffs[i].Table.Table < diffs[j].Table.Table
+	})
+	return diffs
+}
+
+// diffColumns reports added and altered columns in their new order, then
+// dropped columns in their old order
+func diffColumns(before, after []column) []change {
+	old := make(map[string]column, len(before))
+	for _, c := range before {
+		old[c.Name] = c
+	}
+	current := make(map[string]bool, len(after))
+
+	var changes []change
+	for _, c := range after {
+		current[c.Name] = true
+		prev, existed := old[c.Name]
+		switch {
+		case !existed:
+			changes = append(changes, change{Kind: changeColumnAdded, Object: c.Name, After: c.definition()})
+		case prev.definition() != c.definition():
+			changes = append(changes, change{Kind: changeColumnAltered, Object: c.Name, Before: prev.definition(), After: c.definition()})
+		}
+	}
+	for _, c := range before {
+		if !current[c.Name] {
+			changes = append(changes, change{Kind: changeColumnDropped, Object: c.Name, Before: c.definition()})
+		}
+	}
+	return changes
+}
+
+// diffIndexes reports index changes sorted by index name
+func diffIndexes(before, after map[string]string) []change {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, existed := before[name]; !existed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []change
+	for _, name := range names {
+		oldDef, existed := before[name]
+		newDef, exists := after[name]
+		switch {
+		case !existed:
+			changes = append(changes, change{Kind: changeIndexCreated, Object: name, After: newDef})
+		case !exists:
+			changes = append(changes, change{Kind: changeIndexDropped, Object: name, Before: oldDef})
+		case oldDef != newDef:
+			changes = append(changes, change{Kind: changeIndexAltered, Object: name, Before: oldDef, After: newDef})
+		}
+	}
+	return changes
+}
+
+// columnList renders columns as in CREATE TABLE
+func columnList(columns []column) string {
+	defs := make([]string, len(columns))
+	for i, c := range columns {
+		defs[i] = c.Name + " " + c.definition()
+	}
+	return strings.Join(defs, ", ")
+}
@@ -0,0 +1,86 @@
+package pgschemadrift
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// columnsQuery lists the columns of every ordinary and partitioned table
+// outside the system schemas. It reads pg_catalog rather than
+// information_schema.columns, whose data_type drops type modifiers, so a
+// varchar(50) widened to varchar(100) would go unnoticed.
+const columnsQuery = `SELECT
+  n.nspname,
+  c.relname,
+  a.attname,
+  format_type(a.atttypid, a.atttypmod),
+  NOT a.attnotnull,
+  COALESCE(pg_get_expr(d.adbin, d.adrelid), '')
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE c.relkind IN ('r', 'p')
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname NOT LIKE 'pg_toast%'
+ORDER BY n.nspname, c.relname, a.attnum`
+
+// indexesQuery lists the index definitions of the same tables
+const indexesQuery = `SELECT schemaname, tablename, indexname, indexdef
+FROM pg_indexes
+WHERE schemaname NOT IN ('pg_catalog', 'information_schema')
+  AND schemaname NOT LIKE 'pg_toast%'
+ORDER BY schemaname, tablename, indexname`
+
+// querySnapshot reads the definitions of the tables the matcher selects
+func querySnapshot(ctx context.Context, source sqlsource.Source, matcher tableMatcher) (snapshot, error) {
+	snap := make(snapshot)
+
+	rs, err := source.Query(ctx, columnsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	for rs.Next() {
+		var key tableKey
+		var col column
+		if err := rs.Scan(&key.Schema, &key.Table, &col.Name, &col.Type, &col.Nullable, &col.Default); err != nil {
+			rs.Close()
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+		if matcher.matches(key.Schema, key.Table) {
+			snap.table(key).Columns = append(snap.table(key).Columns, col)
+		}
+	}
+	err = rs.Err()
+	rs.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column rows: %w", err)
+	}
+
+	rs, err = source.Query(ctx, indexesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var key tableKey
+		var name, definition string
+		if err := rs.Scan(&key.Schema, &key.Table, &name, &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		// Only tables the columns query found are monitored; that leaves
+		// out tables with no columns at all, which cannot have indexes
+		if def, exists := snap[key]; exists {
+			def.Indexes[name] = definition
+		}
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index rows: %w", err)
+	}
+
+	return snap, nil
+}
@@ -0,0 +1,86 @@
+package pgserverlog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Log formats, matching the log_destination values that produce them
+const (
+	FormatCSV  = "csvlog"
+	FormatJSON = "jsonlog"
+)
+
+// Where to start reading files that exist when the receiver starts
+const (
+	StartAtBeginning = "beginning"
+	StartAtEnd       = "end"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Include are glob patterns of the server log files to tail, e.g.
+	// /var/lib/postgresql/data/log/postgresql-*.csv
+	Include []string `mapstructure:"include"`
+
+	// Format is the log_destination the files were written with: csvlog
+	// or jsonlog (PostgreSQL 15 and later)
+	Format string `mapstructure:"format"`
+
+	// StartAt is where files that exist at startup are read from: end
+	// skips their history, beginning reads it. Files that appear later,
+	// such as after a rotation, are always read from the beginning.
+	StartAt string `mapstructure:"start_at"`
+
+	// PollInterval is how often the files are checked for new lines
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// MaxStatementLength truncates db.statement to this many bytes
+	MaxStatementLength int `mapstructure:"max_statement_length"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Include) == 0 {
+		return errors.New("at least one include pattern must be specified")
+	}
+
+	for _, pattern := range cfg.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+
+	if cfg.Format != FormatCSV && cfg.Format != FormatJSON {
+		return fmt.Errorf("format must be %q or %q, got %q", FormatCSV, FormatJSON, cfg.Format)
+	}
+
+	if cfg.StartAt != StartAtBeginning && cfg.StartAt != StartAtEnd {
+		return fmt.Errorf("start_at must be %q or %q, got %q", StartAtBeginning, StartAtEnd, cfg.StartAt)
+	}
+
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive, got %v", cfg.PollInterval)
+	}
+
+	if cfg.MaxStatementLength <= 0 {
+		return fmt.Errorf("max_statement_length must be positive, got %d", cfg.MaxStatementLength)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Format:             FormatCSV,
+		StartAt:            StartAtEnd,
+		PollInterval:       time.Second,
+		MaxStatementLength: 4096,
+	}
+}
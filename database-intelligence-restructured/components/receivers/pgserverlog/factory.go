@@ -0,0 +1,52 @@
+package pgserverlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "pgserverlog"
+	stability = component.StabilityLevelAlpha
+)
+
+var errConfigNotServerLog = errors.New("config is not for pgserverlog receiver")
+
+// NewFactory creates a new PostgreSQL server log receiver factory
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration
+func createDefaultConfig() component.Config {
+	return DefaultConfig()
+}
+
+// createLogsReceiver creates a logs receiver based on provided config.
+func createLogsReceiver(
+	ctx context.Context,
+	settings receiver.Settings,
+	cfg component.Config,
+	consumer consumer.Logs,
+) (receiver.Logs, error) {
+	slCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, errConfigNotServerLog
+	}
+
+	// Validate the configuration
+	if err := slCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return newServerLogReceiver(slCfg, settings.Logger, consumer), nil
+}
@@ -0,0 +1,235 @@
+package pgserverlog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logTimeLayout is the format of log_time in csvlog and timestamp in jsonlog
+const logTimeLayout = "2006-01-02 15:04:05.999 MST"
+
+// minCSVFields is the csvlog column count of PostgreSQL 12; later versions
+// append backend_type, leader_pid and query_id
+const minCSVFields = 23
+
+// entry is one server log message
+type entry struct {
+	Time            time.Time
+	User            string
+	Database        string
+	PID             int64
+	RemoteHost      string
+	SessionID       string
+	Severity        string
+	SQLState        string
+	Message         string
+	Detail          string
+	Hint            string
+	Context         string
+	Query           string
+	ApplicationName string
+	BackendType     string
+	QueryID         int64
+}
+
+// splitCSVRecords returns the complete records at the start of data and
+// the rest. A quoted field may span lines, so a record only ends at a
+// newline outside quotes; data must start at a record boundary.
+func splitCSVRecords(data []byte) (records [][]byte, rest []byte) {
+	inQuotes := false
+	start := 0
+	for i, b := range data {
+		switch b {
+		case '"':
+			inQuotes = !inQuotes
+		case '\n':
+			if !inQuotes {
+				records = append(records, data[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return records, data[start:]
+}
+
+// splitLines returns the complete lines at the start of data and the rest.
+// jsonlog escapes newlines, so every record is one line.
+func splitLines(data []byte) (lines [][]byte, rest []byte) {
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return lines, data
+		}
+		lines = append(lines, data[:i])
+		data = data[i+1:]
+	}
+}
+
+// parseCSVRecord parses one csvlog record
+func parseCSVRecord(record []byte) (entry, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimSuffix(record, []byte("\r"))))
+	r.FieldsPerRecord = -1
+	fields, err := r.Read()
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid csvlog record: %w", err)
+	}
+	if len(fields) < minCSVFields {
+		return entry{}, fmt.Errorf("invalid csvlog record: expected at least %d fields, got %d", minCSVFields, len(fields))
+	}
+
+	e := entry{
+		User:            fields[1],
+		Database:        fields[2],
+		RemoteHost:      remoteHost(fields[4]),
+		SessionID:       fields[5],
+		Severity:        fields[11],
+		SQLState:        fields[12],
+		Message:         fields[13],
+		Detail:          fields[14],
+		Hint:            fields[15],
+		Context:         fields[18],
+		Query:           fields[19],
+		ApplicationName: fields[22],
+	}
+	e.Time, _ = time.Parse(logTimeLayout, fields[0])
+	e.PID, _ = strconv.ParseInt(fields[3], 10, 64)
+	if len(fields) > 23 {
+		e.BackendType = fields[23]
+	}
+	if len(fields) > 25 {
+		e.QueryID, _ = strconv.ParseInt(fields[25], 10, 64)
+	}
+	return e, nil
+}
+
+// remoteHost strips the port from a csvlog connection_from value
+func remoteHost(connectionFrom string) string {
+	if i := strings.LastIndexByte(connectionFrom, ':'); i > 0 && !strings.HasSuffix(connectionFrom, "]") {
+		if _, err := strconv.Atoi(connectionFrom[i+1:]); err == nil {
+			return connectionFrom[:i]
+		}
+	}
+	return connectionFrom
+}
+
+// jsonRecord holds the jsonlog keys the receiver reads
+type jsonRecord struct {
+	Timestamp       string `json:"timestamp"`
+	User            string `json:"user"`
+	Database        string `json:"dbname"`
+	PID             int64  `json:"pid"`
+	RemoteHost      string `json:"remote_host"`
+	SessionID       string `json:"session_id"`
+	Severity        string `json:"error_severity"`
+	SQLState        string `json:"state_code"`
+	Message         string `json:"message"`
+	Detail          string `json:"detail"`
+	Hint            string `json:"hint"`
+	Context         string `json:"context"`
+	Statement       string `json:"statement"`
+	ApplicationName string `json:"application_name"`
+	BackendType     string `json:"backend_type"`
+	QueryID         int64  `json:"query_id"`
+}
+
+// parseJSONRecord parses one jsonlog record
+func parseJSONRecord(record []byte) (entry, error) {
+	var j jsonRecord
+	if err := json.Unmarshal(record, &j); err != nil {
+		return entry{}, fmt.Errorf("invalid jsonlog record: %w", err)
+	}
+
+	e := entry{
+		User:            j.User,
+		Database:        j.Database,
+		PID:             j.PID,
+		RemoteHost:      j.RemoteHost,
+		SessionID:       j.SessionID,
+		Severity:        j.Severity,
+		SQLState:        j.SQLState,
+		Message:         j.Message,
+		Detail:          j.Detail,
+		Hint:            j.Hint,
+		Context:         j.Context,
+		Query:           j.Statement,
+		ApplicationName: j.ApplicationName,
+		BackendType:     j.BackendType,
+		QueryID:         j.QueryID,
+	}
+	e.Time, _ = time.Parse(logTimeLayout, j.Timestamp)
+	return e, nil
+}
+
+// durationMessage matches the messages written by log_min_duration_statement,
+// log_duration and auto_explain: "duration: 12.345 ms", optionally followed
+// by "statement: ...", "execute <name>: ...", "parse ...", "bind ..." or
+// auto_explain's "plan:" and the text, which may span lines
+var durationMessage = regexp.MustCompile(`(?s)^duration: ([0-9]+(?:\.[0-9]+)?) ms(?:\s+(statement|plan|(?:execute|parse|bind) [^:\n]*):\s?(.*))?$`)
+
+// statementMessage matches the messages written by log_statement
+var statementMessage = regexp.MustCompile(`(?s)^(statement|(?:execute|parse|bind) [^:\n]*): (.*)$`)
+
+// extracted holds the fields derived from a message
+type extracted struct {
+	// DurationMs is negative when the message has no duration
+	DurationMs float64
+	Statement  string
+	// PlanJSON is an auto_explain JSON plan, wrapped in an array like the
+	// output of EXPLAIN (FORMAT JSON)
+	PlanJSON string
+	// PlanText is an auto_explain plan in another format
+	PlanText string
+}
+
+// extract derives the duration, statement and plan of an entry. The
+// statement comes from the message when it carries one, otherwise from the
+// query column that accompanies errors.
+func extract(e entry) extracted {
+	x := extracted{DurationMs: -1}
+
+	if m := durationMessage.FindStringSubmatch(e.Message); m != nil {
+		x.DurationMs, _ = strconv.ParseFloat(m[1], 64)
+		switch {
+		case m[2] == "plan":
+			x.Statement, x.PlanJSON, x.PlanText = parsePlan(m[3])
+		case m[2] != "":
+			x.Statement = m[3]
+		}
+	} else if m := statementMessage.FindStringSubmatch(e.Message); m != nil {
+		x.Statement = m[2]
+	}
+
+	if x.Statement == "" {
+		x.Statement = e.Query
+	}
+	x.Statement = strings.TrimSpace(x.Statement)
+	return x
+}
+
+// parsePlan splits an auto_explain plan. With auto_explain.log_format=json
+// the plan is an object holding "Query Text" and "Plan"; other formats are
+// returned as text, with the statement from their "Query Text: " line.
+func parsePlan(plan string) (statement, planJSON, planText string) {
+	plan = strings.TrimSpace(plan)
+
+	var explained struct {
+		QueryText string          `json:"Query Text"`
+		Plan      json.RawMessage `json:"Plan"`
+	}
+	if strings.HasPrefix(plan, "{") && json.Unmarshal([]byte(plan), &explained) == nil && explained.Plan != nil {
+		return explained.QueryText, "[" + plan + "]", ""
+	}
+
+	const queryTextPrefix = "Query Text: "
+	if strings.HasPrefix(plan, queryTextPrefix) {
+		first, _, _ := strings.Cut(plan, "\n")
+		statement = strings.TrimPrefix(first, queryTextPrefix)
+	}
+	return statement, "", plan
+}
@@ -0,0 +1,248 @@
+package pgserverlog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// serverLogReceiver implements the receiver.Logs interface
+type serverLogReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Logs
+
+	tailer *tailer
+	parse  func(record []byte) (entry, error)
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newServerLogReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Logs) *serverLogReceiver {
+	split, parse := splitCSVRecords, parseCSVRecord
+	if cfg.Format == FormatJSON {
+		split, parse = splitLines, parseJSONRecord
+	}
+
+	return &serverLogReceiver{
+		config:   cfg,
+		logger:   logger,
+		consumer: consumer,
+		tailer:   newTailer(cfg.Include, split, cfg.StartAt == StartAtEnd),
+		parse:    parse,
+	}
+}
+
+// Start implements the receiver.Logs interface
+func (r *serverLogReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting PostgreSQL server log receiver",
+		zap.Strings("include", r.config.Include),
+		zap.String("format", r.config.Format),
+		zap.String("start_at", r.config.StartAt))
+
+	// The polling loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Logs interface
+func (r *serverLogReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down PostgreSQL server log receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// collect polls the files right away, so start_at: end skips only what was
+// written before the receiver started, then every poll interval
+func (r *serverLogReceiver) collect(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		ld := r.poll()
+		if ld.LogRecordCount() > 0 {
+			if err := r.consumer.ConsumeLogs(ctx, ld); err != nil {
+				r.logger.Error("Failed to send server log records", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the new records of every file and converts them to log
+// records. Records that do not parse are counted and skipped.
+func (r *serverLogReceiver) poll() plog.Logs {
+	files, errs := r.tailer.poll()
+	for _, err := range errs {
+		r.logger.Warn("Failed to read server log file", zap.Error(err))
+	}
+
+	ld := plog.NewLogs()
+	if len(files) == 0 {
+		return ld
+	}
+
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("db.system", "postgresql")
+	for k, v := range r.config.ResourceAttributes {
+		rl.Resource().Attributes().PutStr(k, v)
+	}
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("pgserverlog_receiver")
+	sl.Scope().SetVersion("1.0.0")
+
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for _, file := range files {
+		invalid := 0
+		for _, record := range file.Records {
+			if len(strings.TrimSpace(string(record))) == 0 {
+				continue
+			}
+			e, err := r.parse(record)
+			if err != nil {
+				if invalid == 0 {
+					r.logger.Debug("Skipping unparsable server log record",
+						zap.String("path", file.Path), zap.Error(err))
+				}
+				invalid++
+				continue
+			}
+			r.appendRecord(sl.LogRecords(), e, file.Path, now)
+		}
+		if invalid > 0 {
+			r.logger.Warn("Skipped unparsable server log records; check that format matches log_destination",
+				zap.String("path", file.Path),
+				zap.String("format", r.config.Format),
+				zap.Int("records", invalid))
+		}
+	}
+
+	return ld
+}
+
+// appendRecord converts one server log entry. The attribute names match
+// what the downstream processors read: plan_json for planattributeextractor
+// and database_name, query_id and duration_ms for verification.
+func (r *serverLogReceiver) appendRecord(records plog.LogRecordSlice, e entry, path string, now pcommon.Timestamp) {
+	record := records.AppendEmpty()
+	if e.Time.IsZero() {
+		record.SetTimestamp(now)
+	} else {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(e.Time))
+	}
+	record.SetObservedTimestamp(now)
+	record.SetSeverityNumber(severityNumber(e.Severity))
+	record.SetSeverityText(e.Severity)
+	record.Body().SetStr(e.Message)
+
+	attrs := record.Attributes()
+	attrs.PutStr("log.file.path", path)
+	putNonEmpty(attrs, "db.name", e.Database)
+	putNonEmpty(attrs, "database_name", e.Database)
+	putNonEmpty(attrs, "db.user", e.User)
+	putNonEmpty(attrs, "client.address", e.RemoteHost)
+	if e.PID != 0 {
+		attrs.PutInt("process.pid", e.PID)
+	}
+	putNonEmpty(attrs, "db.postgresql.session_id", e.SessionID)
+	putNonEmpty(attrs, "db.postgresql.application_name", e.ApplicationName)
+	putNonEmpty(attrs, "db.postgresql.backend_type", e.BackendType)
+	putNonEmpty(attrs, "db.postgresql.error_severity", e.Severity)
+	putNonEmpty(attrs, "db.postgresql.sqlstate", e.SQLState)
+	putNonEmpty(attrs, "db.postgresql.detail", e.Detail)
+	putNonEmpty(attrs, "db.postgresql.hint", e.Hint)
+	putNonEmpty(attrs, "db.postgresql.context", e.Context)
+	if e.QueryID != 0 {
+		attrs.PutStr("query_id", strconv.FormatInt(e.QueryID, 10))
+	}
+
+	x := extract(e)
+	if x.DurationMs >= 0 {
+		attrs.PutDouble("duration_ms", x.DurationMs)
+	}
+	putNonEmpty(attrs, "db.statement", truncate(x.Statement, r.config.MaxStatementLength))
+	putNonEmpty(attrs, "plan_json", x.PlanJSON)
+	putNonEmpty(attrs, "db.postgresql.plan_text", x.PlanText)
+}
+
+func putNonEmpty(attrs pcommon.Map, key, value string) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
+
+// severityNumber maps a PostgreSQL error severity to an OpenTelemetry one.
+// LOG is what log_min_duration_statement and log_statement write, so it is
+// informational even though the server ranks it above NOTICE.
+func severityNumber(severity string) plog.SeverityNumber {
+	switch severity {
+	case "DEBUG5", "DEBUG4", "DEBUG3":
+		return plog.SeverityNumberTrace
+	case "DEBUG2", "DEBUG1":
+		return plog.SeverityNumberDebug
+	case "INFO", "NOTICE":
+		return plog.SeverityNumberInfo
+	case "LOG":
+		return plog.SeverityNumberInfo2
+	case "WARNING":
+		return plog.SeverityNumberWarn
+	case "ERROR":
+		return plog.SeverityNumberError
+	case "FATAL":
+		return plog.SeverityNumberFatal
+	case "PANIC":
+		return plog.SeverityNumberFatal4
+	}
+	return plog.SeverityNumberUnspecified
+}
+
+// truncate shortens s to at most maxLen bytes without splitting a rune
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,298 @@
+package pgserverlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// Sample csvlog records written by PostgreSQL 16: a slow statement that
+// spans lines, an error with its statement in the query column, and an
+// auto_explain JSON plan
+const (
+	csvSlowStatement = `2024-05-01 12:34:56.789 UTC,"shop","orders",4242,"10.0.0.7:51234",66323a10.1092,3,"SELECT",2024-05-01 12:30:00 UTC,3/17,0,LOG,00000,"duration: 1523.456 ms  statement: SELECT o.id, o.total
+FROM orders o
+WHERE o.customer_email = 'a@example.com'",,,,,,,,,"","client backend",,-2876139428190113451` + "\n"
+
+	csvError = `2024-05-01 12:35:01.002 UTC,"shop","orders",4243,"[::1]:51240",66323a11.1093,1,"INSERT",2024-05-01 12:34:00 UTC,4/9,0,ERROR,23505,"duplicate key value violates unique constraint ""orders_pkey""","Key (id)=(7) already exists.",,,,,"INSERT INTO orders (id) VALUES (7)",,,"loadgen","client backend",,0` + "\n"
+
+	csvPlan = `2024-05-01 12:36:00.000 UTC,"shop","orders",4244,"10.0.0.7:51236",66323a12.1094,2,"SELECT",2024-05-01 12:35:00 UTC,5/3,0,LOG,00000,"duration: 250.100 ms  plan:
+{
+  ""Query Text"": ""SELECT * FROM orders WHERE id = 1"",
+  ""Plan"": {""Node Type"": ""Seq Scan"", ""Total Cost"": 35.5}
+}",,,,,,,,,"psql","client backend",,0` + "\n"
+
+	// A PostgreSQL 12 record, before backend_type, leader_pid and query_id
+	csvPG12 = `2024-05-01 12:37:00.000 UTC,"postgres","postgres",4245,"[local]",66323a13.1095,1,"idle",2024-05-01 12:36:00 UTC,6/1,0,LOG,00000,"statement: VACUUM orders",,,,,,,,,"psql"` + "\n"
+
+	jsonSlowStatement = `{"timestamp":"2024-05-01 12:34:56.789 UTC","user":"shop","dbname":"orders","pid":4242,"remote_host":"10.0.0.7","remote_port":51234,"session_id":"66323a10.1092","error_severity":"LOG","state_code":"00000","message":"duration: 12.5 ms  execute S_1: SELECT *\nFROM products WHERE sku = $1","application_name":"api","backend_type":"client backend","query_id":42}` + "\n"
+)
+
+func TestSplitCSVRecords(t *testing.T) {
+	data := []byte(csvSlowStatement + csvError + csvPlan[:40])
+
+	records, rest := splitCSVRecords(data)
+	require.Len(t, records, 2, "newlines inside quoted fields do not end a record")
+	assert.Equal(t, csvSlowStatement[:len(csvSlowStatement)-1], string(records[0]))
+	assert.Equal(t, csvPlan[:40], string(rest), "the incomplete record is kept for the next read")
+
+	// A record cut inside a multiline field is not returned
+	records, rest = splitCSVRecords([]byte(csvSlowStatement[:200]))
+	assert.Empty(t, records)
+	assert.Len(t, rest, 200)
+}
+
+func TestParseCSVRecord(t *testing.T) {
+	records, _ := splitCSVRecords([]byte(csvSlowStatement + csvError + csvPG12))
+	require.Len(t, records, 3)
+
+	e, err := parseCSVRecord(records[0])
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 34, 56, 789000000, time.UTC), e.Time.UTC())
+	assert.Equal(t, "shop", e.User)
+	assert.Equal(t, "orders", e.Database)
+	assert.Equal(t, int64(4242), e.PID)
+	assert.Equal(t, "10.0.0.7", e.RemoteHost)
+	assert.Equal(t, "66323a10.1092", e.SessionID)
+	assert.Equal(t, "LOG", e.Severity)
+	assert.Equal(t, "00000", e.SQLState)
+	assert.Equal(t, "client backend", e.BackendType)
+	assert.Equal(t, int64(-2876139428190113451), e.QueryID)
+	assert.Contains(t, e.Message, "FROM orders o\nWHERE")
+
+	e, err = parseCSVRecord(records[1])
+	require.NoError(t, err)
+	assert.Equal(t, "[::1]", e.RemoteHost, "IPv6 hosts keep their brackets")
+	assert.Equal(t, "ERROR", e.Severity)
+	assert.Equal(t, "23505", e.SQLState)
+	assert.Equal(t, `duplicate key value violates unique constraint "orders_pkey"`, e.Message)
+	assert.Equal(t, "Key (id)=(7) already exists.", e.Detail)
+	assert.Equal(t, "INSERT INTO orders (id) VALUES (7)", e.Query)
+	assert.Equal(t, "loadgen", e.ApplicationName)
+
+	e, err = parseCSVRecord(records[2])
+	require.NoError(t, err)
+	assert.Equal(t, "[local]", e.RemoteHost)
+	assert.Equal(t, "psql", e.ApplicationName)
+	assert.Empty(t, e.BackendType)
+	assert.Zero(t, e.QueryID)
+
+	_, err = parseCSVRecord([]byte(`2024-05-01 12:34:56.789 UTC,"shop","orders",4242`))
+	assert.EqualError(t, err, "invalid csvlog record: expected at least 23 fields, got 4")
+
+	_, err = parseCSVRecord([]byte(`2024-05-01,"unterminated`))
+	assert.ErrorContains(t, err, "invalid csvlog record")
+}
+
+func TestParseJSONRecord(t *testing.T) {
+	lines, rest := splitLines([]byte(jsonSlowStatement + `{"timestamp":`))
+	require.Len(t, lines, 1)
+	assert.Equal(t, `{"timestamp":`, string(rest))
+
+	e, err := parseJSONRecord(lines[0])
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 34, 56, 789000000, time.UTC), e.Time.UTC())
+	assert.Equal(t, "orders", e.Database)
+	assert.Equal(t, "10.0.0.7", e.RemoteHost)
+	assert.Equal(t, int64(42), e.QueryID)
+	assert.Equal(t, "api", e.ApplicationName)
+
+	x := extract(e)
+	assert.Equal(t, 12.5, x.DurationMs)
+	assert.Equal(t, "SELECT *\nFROM products WHERE sku = $1", x.Statement)
+
+	_, err = parseJSONRecord([]byte(`not json`))
+	assert.ErrorContains(t, err, "invalid jsonlog record")
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     entry
+		duration  float64
+		statement string
+		planJSON  string
+		planText  string
+	}{
+		{
+			name:      "log_min_duration_statement",
+			entry:     entry{Message: "duration: 1523.456 ms  statement: SELECT 1\nFROM t"},
+			duration:  1523.456,
+			statement: "SELECT 1\nFROM t",
+		},
+		{
+			name:      "extended protocol",
+			entry:     entry{Message: "duration: 3 ms  bind <unnamed>: SELECT $1"},
+			duration:  3,
+			statement: "SELECT $1",
+		},
+		{
+			name:     "log_duration",
+			entry:    entry{Message: "duration: 0.045 ms"},
+			duration: 0.045,
+		},
+		{
+			name:      "log_statement",
+			entry:     entry{Message: "statement: CREATE TABLE t (id int)"},
+			duration:  -1,
+			statement: "CREATE TABLE t (id int)",
+		},
+		{
+			name:      "error with query column",
+			entry:     entry{Message: "relation \"missing\" does not exist", Query: "SELECT * FROM missing"},
+			duration:  -1,
+			statement: "SELECT * FROM missing",
+		},
+		{
+			name:      "auto_explain json",
+			entry:     entry{Message: "duration: 250.100 ms  plan:\n{\"Query Text\": \"SELECT 1\", \"Plan\": {\"Node Type\": \"Result\"}}"},
+			duration:  250.1,
+			statement: "SELECT 1",
+			planJSON:  "[{\"Query Text\": \"SELECT 1\", \"Plan\": {\"Node Type\": \"Result\"}}]",
+		},
+		{
+			name:      "auto_explain text",
+			entry:     entry{Message: "duration: 9 ms  plan:\nQuery Text: SELECT 1\nResult  (cost=0.00..0.01 rows=1 width=4)"},
+			duration:  9,
+			statement: "SELECT 1",
+			planText:  "Query Text: SELECT 1\nResult  (cost=0.00..0.01 rows=1 width=4)",
+		},
+		{
+			name:     "other message",
+			entry:    entry{Message: "checkpoint starting: time"},
+			duration: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := extract(tt.entry)
+			assert.Equal(t, tt.duration, x.DurationMs)
+			assert.Equal(t, tt.statement, x.Statement)
+			assert.Equal(t, tt.planJSON, x.PlanJSON)
+			assert.Equal(t, tt.planText, x.PlanText)
+		})
+	}
+}
+
+func newTestReceiver(t *testing.T, format, startAt string) (*serverLogReceiver, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Include = []string{filepath.Join(dir, "postgresql-*.log")}
+	cfg.Format = format
+	cfg.StartAt = startAt
+	cfg.MaxStatementLength = 40
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	require.NoError(t, cfg.Validate())
+	return newServerLogReceiver(cfg, zap.NewNop(), consumertest.NewNop()), dir
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestPollBuildsLogRecords(t *testing.T) {
+	r, dir := newTestReceiver(t, FormatCSV, StartAtBeginning)
+	path := filepath.Join(dir, "postgresql-2024-05-01.log")
+	appendFile(t, path, csvSlowStatement+csvError+csvPlan)
+
+	ld := r.poll()
+	require.Equal(t, 3, ld.LogRecordCount())
+
+	rl := ld.ResourceLogs().At(0)
+	dbSystem, _ := rl.Resource().Attributes().Get("db.system")
+	assert.Equal(t, "postgresql", dbSystem.Str())
+	env, _ := rl.Resource().Attributes().Get("deployment.environment")
+	assert.Equal(t, "test", env.Str())
+
+	records := rl.ScopeLogs().At(0).LogRecords()
+
+	slow := records.At(0)
+	assert.Equal(t, plog.SeverityNumberInfo2, slow.SeverityNumber())
+	assert.Equal(t, "LOG", slow.SeverityText())
+	attrs := slow.Attributes().AsRaw()
+	assert.Equal(t, 1523.456, attrs["duration_ms"])
+	assert.Equal(t, "orders", attrs["database_name"])
+	assert.Equal(t, "-2876139428190113451", attrs["query_id"])
+	assert.Equal(t, "SELECT o.id, o.total\nFROM orders o\nWHERE", attrs["db.statement"], "truncated to max_statement_length")
+	assert.Equal(t, path, attrs["log.file.path"])
+	assert.Equal(t, int64(4242), attrs["process.pid"])
+
+	failed := records.At(1)
+	assert.Equal(t, plog.SeverityNumberError, failed.SeverityNumber())
+	attrs = failed.Attributes().AsRaw()
+	assert.Equal(t, "23505", attrs["db.postgresql.sqlstate"])
+	assert.Equal(t, "ERROR", attrs["db.postgresql.error_severity"])
+	assert.Equal(t, "INSERT INTO orders (id) VALUES (7)", attrs["db.statement"])
+	assert.NotContains(t, attrs, "duration_ms")
+	assert.NotContains(t, attrs, "query_id")
+
+	planned := records.At(2)
+	planJSON, ok := planned.Attributes().Get("plan_json")
+	require.True(t, ok)
+	assert.Contains(t, planJSON.Str(), `"Node Type": "Seq Scan"`)
+}
+
+func TestPollFollowsAppendsAndRotation(t *testing.T) {
+	r, dir := newTestReceiver(t, FormatCSV, StartAtEnd)
+	path := filepath.Join(dir, "postgresql-2024-05-01.log")
+	appendFile(t, path, csvError)
+
+	assert.Equal(t, 0, r.poll().LogRecordCount(), "start_at: end skips existing content")
+
+	// A record written in two parts is emitted once it is complete
+	appendFile(t, path, csvSlowStatement[:150])
+	assert.Equal(t, 0, r.poll().LogRecordCount())
+	appendFile(t, path, csvSlowStatement[150:])
+	ld := r.poll()
+	require.Equal(t, 1, ld.LogRecordCount())
+	duration, _ := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("duration_ms")
+	assert.Equal(t, 1523.456, duration.Double())
+
+	// A file created after startup is read from the beginning
+	appendFile(t, filepath.Join(dir, "postgresql-2024-05-02.log"), csvError+csvPG12)
+	assert.Equal(t, 2, r.poll().LogRecordCount())
+
+	// log_truncate_on_rotation rewrites the file in place
+	require.NoError(t, os.WriteFile(path, []byte(csvPG12), 0o600))
+	assert.Equal(t, 1, r.poll().LogRecordCount())
+
+	assert.Equal(t, 0, r.poll().LogRecordCount(), "nothing new")
+}
+
+func TestPollSkipsInvalidRecords(t *testing.T) {
+	r, dir := newTestReceiver(t, FormatJSON, StartAtBeginning)
+	appendFile(t, filepath.Join(dir, "postgresql-2024-05-01.log"), "not json\n\n"+jsonSlowStatement)
+
+	ld := r.poll()
+	require.Equal(t, 1, ld.LogRecordCount())
+	statement, _ := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("db.statement")
+	assert.Equal(t, "SELECT *\nFROM products WHERE sku = $1", statement.Str())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.EqualError(t, cfg.Validate(), "at least one include pattern must be specified")
+
+	cfg.Include = []string{"/var/log/postgresql/[.csv"}
+	assert.ErrorContains(t, cfg.Validate(), "invalid include pattern")
+
+	cfg.Include = []string{"/var/log/postgresql/*.csv"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Format = "stderr"
+	assert.EqualError(t, cfg.Validate(), `format must be "csvlog" or "jsonlog", got "stderr"`)
+}
@@ -0,0 +1,155 @@
+package pgserverlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// maxPendingBytes bounds the unterminated record kept between polls. A
+// record this long is not one PostgreSQL wrote, so the buffer is dropped
+// rather than grown without limit.
+const maxPendingBytes = 16 << 20
+
+// trackedFile is the read position in one log file
+type trackedFile struct {
+	info   os.FileInfo
+	offset int64
+	// pending is the start of a record whose end has not been written yet
+	pending []byte
+}
+
+// tailer reads the records appended to the files matching a set of globs
+type tailer struct {
+	include []string
+	split   func(data []byte) (records [][]byte, rest []byte)
+	// startAtEnd skips the content of the files found by the first poll
+	startAtEnd bool
+
+	files  map[string]*trackedFile
+	polled bool
+}
+
+func newTailer(include []string, split func([]byte) ([][]byte, []byte), startAtEnd bool) *tailer {
+	return &tailer{
+		include:    include,
+		split:      split,
+		startAtEnd: startAtEnd,
+		files:      make(map[string]*trackedFile),
+	}
+}
+
+// fileRecords are the complete records read from one file
+type fileRecords struct {
+	Path    string
+	Records [][]byte
+}
+
+// poll reads the complete records appended to every matching file since
+// the previous poll. A file replaced at the same path, or truncated as with
+// log_truncate_on_rotation, is read again from the beginning. Errors for
+// individual files are collected so the other files are still read.
+func (t *tailer) poll() ([]fileRecords, []error) {
+	first := !t.polled
+	t.polled = true
+
+	var (
+		results []fileRecords
+		errs    []error
+	)
+	seen := make(map[string]bool)
+	for _, path := range t.match() {
+		seen[path] = true
+
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		tf := t.files[path]
+		if tf == nil || !os.SameFile(tf.info, info) {
+			tf = &trackedFile{}
+			if first && t.startAtEnd {
+				tf.offset = info.Size()
+			}
+			t.files[path] = tf
+		}
+		tf.info = info
+		if info.Size() < tf.offset {
+			tf.offset = 0
+			tf.pending = nil
+		}
+		if info.Size() == tf.offset {
+			continue
+		}
+
+		records, err := t.read(path, tf)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(records) > 0 {
+			results = append(results, fileRecords{Path: path, Records: records})
+		}
+	}
+
+	// Forget files that were removed, so a new file with the same name is
+	// read from the beginning
+	for path := range t.files {
+		if !seen[path] {
+			delete(t.files, path)
+		}
+	}
+
+	return results, errs
+}
+
+// match returns the paths matching the include patterns, sorted so files
+// rotated by name are read oldest first
+func (t *tailer) match() []string {
+	unique := make(map[string]bool)
+	for _, pattern := range t.include {
+		// The patterns were validated, so Glob cannot fail
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			unique[m] = true
+		}
+	}
+
+	paths := make([]string, 0, len(unique))
+	for path := range unique {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// read reads a file from its offset to the end and splits the pending and
+// new bytes into records
+func (t *tailer) read(path string, tf *trackedFile) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(tf.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek %s: %w", path, err)
+	}
+	data, err := io.ReadAll(f)
+	tf.offset += int64(len(data))
+	if err != nil {
+		err = fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	records, rest := t.split(append(tf.pending, data...))
+	if len(rest) > maxPendingBytes {
+		if err == nil {
+			err = fmt.Errorf("dropped %d bytes of %s without a record end", len(rest), path)
+		}
+		rest = nil
+	}
+	tf.pending = append([]byte(nil), rest...)
+	return records, err
+}
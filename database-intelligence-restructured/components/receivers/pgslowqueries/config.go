@@ -0,0 +1,89 @@
+package pgslowqueries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the PostgreSQL connection string
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often pg_stat_statements is queried
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each pg_stat_statements query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// MinMeanExecTime only reports statements whose mean execution time is at
+	// least this long
+	MinMeanExecTime time.Duration `mapstructure:"min_mean_exec_time"`
+
+	// MaxStatements caps how many of the slowest statements are reported per
+	// collection, which bounds metric cardinality
+	MaxStatements int `mapstructure:"max_statements"`
+
+	// MaxStatementLength truncates db.statement to this many bytes
+	MaxStatementLength int `mapstructure:"max_statement_length"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+
+	// Reset schedules pg_stat_statements_reset()
+	Reset ResetConfig `mapstructure:"reset"`
+}
+
+// ResetConfig controls the periodic pg_stat_statements reset. Resetting
+// clears the statistics for every consumer of the view, so it is off by
+// default.
+type ResetConfig struct {
+	// Enabled turns the scheduled reset on
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often the statistics are reset
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	if cfg.MinMeanExecTime < 0 {
+		return fmt.Errorf("min_mean_exec_time cannot be negative, got %v", cfg.MinMeanExecTime)
+	}
+
+	if cfg.MaxStatements <= 0 {
+		return fmt.Errorf("max_statements must be positive, got %d", cfg.MaxStatements)
+	}
+
+	if cfg.MaxStatementLength <= 0 {
+		return fmt.Errorf("max_statement_length must be positive, got %d", cfg.MaxStatementLength)
+	}
+
+	if cfg.Reset.Enabled && cfg.Reset.Interval < cfg.CollectionInterval {
+		// Shorter intervals would reset statistics that were never collected
+		return fmt.Errorf("reset.interval (%v) cannot be less than collection_interval (%v)",
+			cfg.Reset.Interval, cfg.CollectionInterval)
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 60 * time.Second,
+		QueryTimeout:       10 * time.Second,
+		MinMeanExecTime:    100 * time.Millisecond,
+		MaxStatements:      100,
+		MaxStatementLength: 4096,
+		Reset: ResetConfig{
+			Interval: 24 * time.Hour,
+		},
+	}
+}
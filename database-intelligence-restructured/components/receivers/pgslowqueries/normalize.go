@@ -0,0 +1,144 @@
+package pgslowqueries
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizeStatement prepares pg_stat_statements query text for use as the
+// db.statement attribute. pg_stat_statements already replaces constants with
+// $n parameters; this removes comments, which often carry per-request tags
+// that would split one statement into many series, collapses whitespace, and
+// truncates the result to maxLen bytes.
+func normalizeStatement(query string, maxLen int) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	space := false
+	writeSpace := func() {
+		if b.Len() > 0 {
+			space = true
+		}
+	}
+
+	for i := 0; i < len(query); {
+		switch {
+		case query[i] == '\'' || query[i] == '"':
+			// Copy quoted literals and identifiers verbatim
+			end := closingQuote(query, i)
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteString(query[i:end])
+			i = end
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end
+			}
+			writeSpace()
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+			writeSpace()
+		default:
+			r, size := utf8.DecodeRuneInString(query[i:])
+			if unicode.IsSpace(r) {
+				writeSpace()
+			} else {
+				if space {
+					b.WriteByte(' ')
+					space = false
+				}
+				b.WriteString(query[i : i+size])
+			}
+			i += size
+		}
+	}
+
+	normalized := strings.TrimRight(b.String(), "; ")
+	return truncate(normalized, maxLen)
+}
+
+// closingQuote returns the index just past the quoted section starting at
+// start, treating a doubled quote as an escaped one
+func closingQuote(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// truncate shortens s to at most maxLen bytes without splitting a rune
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// dmlOperations are the operations reported as db.operation; everything else
+// is reported as OTHER
+var dmlOperations = map[string]bool{
+	"SELECT": true,
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+}
+
+// statementOperation returns the operation of a normalized statement. For
+// statements starting with a WITH clause it is the operation of the main
+// statement after the common table expressions.
+func statementOperation(statement string) string {
+	words := strings.Fields(statement)
+	if len(words) == 0 {
+		return "OTHER"
+	}
+
+	first := strings.ToUpper(strings.TrimLeft(words[0], "("))
+	if dmlOperations[first] {
+		return first
+	}
+	if first != "WITH" {
+		return "OTHER"
+	}
+
+	// Skip the parenthesized CTE bodies and take the first operation
+	// keyword at the top level
+	depth := 0
+	for _, word := range words[1:] {
+		opening := strings.Count(word, "(")
+		closing := strings.Count(word, ")")
+		if depth == 0 && opening == 0 {
+			if op := strings.ToUpper(strings.TrimRight(word, ";")); dmlOperations[op] {
+				return op
+			}
+		}
+		depth += opening - closing
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return "OTHER"
+}
@@ -0,0 +1,380 @@
+package pgslowqueries
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/scrapebackoff"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Metric names, matching those produced by the sqlquery-based OHI parity
+// configuration so dashboards and validation queries work with either
+const (
+	metricCount       = "postgres.slow_queries.count"
+	metricElapsedTime = "postgres.slow_queries.elapsed_time"
+	metricDiskReads   = "postgres.slow_queries.disk_reads"
+	metricDiskWrites  = "postgres.slow_queries.disk_writes"
+
+	// metricReset marks a pg_stat_statements reset, so dashboards can
+	// annotate where the statistics start again from zero
+	metricReset = "postgres.slow_queries.reset"
+)
+
+// MetricNames returns the names of the metrics the receiver emits, so the
+// slow query receivers for other databases can keep their naming consistent
+func MetricNames() []string {
+	return []string{metricCount, metricElapsedTime, metricDiskReads, metricDiskWrites}
+}
+
+// slowQueriesReceiver implements the receiver.Metrics interface
+type slowQueriesReceiver struct {
+	config   *Config
+	logger   *zap.Logger
+	consumer consumer.Metrics
+
+	// openSource connects to the database; replaced in tests
+	openSource sqlsource.OpenFunc
+	source     sqlsource.Source
+	// newTicker returns a channel ticking every d and a function stopping
+	// it; replaced in tests
+	newTicker func(d time.Duration) (<-chan time.Time, func())
+	// backoff skips collections while they fail
+	backoff *scrapebackoff.Backoff
+
+	startTime pcommon.Timestamp
+	// timeColumn is the total execution time column, detected on first use
+	timeColumn string
+	// extensionMissing is set while pg_stat_statements is not installed so
+	// the warning is logged once rather than on every collection
+	extensionMissing bool
+	// accessDenied is set while reading pg_stat_statements is refused, and
+	// statementsHidden while the statements of other users are hidden;
+	// both are logged once as they change
+	accessDenied     bool
+	statementsHidden bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func newSlowQueriesReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Metrics) *slowQueriesReceiver {
+	return &slowQueriesReceiver{
+		config:     cfg,
+		logger:     logger,
+		consumer:   consumer,
+		openSource: sqlsource.OpenPostgres,
+		newTicker:  newTimeTicker,
+		backoff:    scrapebackoff.New(cfg.CollectionInterval, logger),
+	}
+}
+
+func newTimeTicker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+// Start implements the receiver.Metrics interface
+func (r *slowQueriesReceiver) Start(ctx context.Context, host component.Host) error {
+	r.logger.Info("Starting pg_stat_statements slow query receiver",
+		zap.Duration("collection_interval", r.config.CollectionInterval),
+		zap.Duration("min_mean_exec_time", r.config.MinMeanExecTime),
+		zap.Int("max_statements", r.config.MaxStatements),
+		zap.Bool("reset_enabled", r.config.Reset.Enabled))
+
+	source, err := r.openSource(ctx, r.config.Datasource)
+	if err != nil {
+		return err
+	}
+	r.source = source
+	r.startTime = pcommon.NewTimestampFromTime(time.Now())
+
+	// The collection loop must outlive the start context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.collect(ctx)
+	}()
+
+	return nil
+}
+
+// Shutdown implements the receiver.Metrics interface
+func (r *slowQueriesReceiver) Shutdown(ctx context.Context) error {
+	r.logger.Info("Shutting down pg_stat_statements slow query receiver")
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if r.source != nil {
+		return r.source.Close()
+	}
+	return nil
+}
+
+// collect periodically queries pg_stat_statements and, when enabled, resets it
+func (r *slowQueriesReceiver) collect(ctx context.Context) {
+	collectTicks, stopCollect := r.newTicker(r.config.CollectionInterval)
+	defer stopCollect()
+
+	// A nil channel never fires, so the reset case is off unless enabled
+	var resetTicks <-chan time.Time
+	if r.config.Reset.Enabled {
+		ticks, stopReset := r.newTicker(r.config.Reset.Interval)
+		defer stopReset()
+		resetTicks = ticks
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-collectTicks:
+			if r.backoff.Ready() {
+				r.collectOnce(ctx)
+			}
+		case <-resetTicks:
+			// Report the statistics gathered since the last collection before
+			// they are cleared
+			r.collectOnce(ctx)
+			md, err := r.reset(ctx)
+			if err != nil {
+				r.logger.Error("Failed to reset pg_stat_statements", zap.Error(err))
+				continue
+			}
+			r.send(ctx, md)
+		}
+	}
+}
+
+// collectOnce scrapes pg_stat_statements and sends the metrics
+func (r *slowQueriesReceiver) collectOnce(ctx context.Context) {
+	md, err := r.scrape(ctx)
+	if err != nil {
+		r.logger.Error("Failed to collect slow queries", zap.Error(err),
+			zap.Duration("next_collection_in", r.backoff.Failure(err)))
+		return
+	}
+	r.backoff.Success()
+	r.send(ctx, md)
+}
+
+func (r *slowQueriesReceiver) send(ctx context.Context, md pmetric.Metrics) {
+	if md.MetricCount() == 0 {
+		return
+	}
+	if err := r.consumer.ConsumeMetrics(ctx, md); err != nil {
+		r.logger.Error("Failed to send slow query metrics", zap.Error(err))
+	}
+}
+
+// reset clears pg_stat_statements and returns the reset marker. The
+// cumulative call counts start again from zero, so the reset time becomes
+// their start time. Nothing is reset while the extension is not installed.
+func (r *slowQueriesReceiver) reset(ctx context.Context) (pmetric.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	var installed bool
+	if err := sqlsource.QueryValue(ctx, r.source, extensionInstalledQuery, &installed); err != nil {
+		return pmetric.NewMetrics(), fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+	}
+	if !installed {
+		return pmetric.NewMetrics(), nil
+	}
+
+	if err := resetStatements(ctx, r.source); err != nil {
+		return pmetric.NewMetrics(), err
+	}
+	now := pcommon.NewTimestampFromTime(time.Now())
+	r.startTime = now
+	r.logger.Info("Reset pg_stat_statements", zap.Duration("interval", r.config.Reset.Interval))
+
+	return r.buildResetMetric(now), nil
+}
+
+// scrape reads the slowest statements and converts them to metrics. When
+// pg_stat_statements is not installed or the user may not read it, it
+// returns no metrics and no error, so the collector keeps running and picks
+// the extension up once it is created or access is granted. Without
+// pg_read_all_stats only the user's own statements are reported.
+func (r *slowQueriesReceiver) scrape(ctx context.Context) (pmetric.Metrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QueryTimeout)
+	defer cancel()
+
+	var installed bool
+	if err := sqlsource.QueryValue(ctx, r.source, extensionInstalledQuery, &installed); err != nil {
+		return pmetric.NewMetrics(), fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+	}
+	if !installed {
+		if !r.extensionMissing {
+			r.logger.Warn("pg_stat_statements extension is not installed; slow query metrics are disabled until it is",
+				zap.String("remediation", "add pg_stat_statements to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements"))
+			r.extensionMissing = true
+		}
+		return pmetric.NewMetrics(), nil
+	}
+	if r.extensionMissing {
+		r.logger.Info("pg_stat_statements extension is now installed; collecting slow query metrics")
+		r.extensionMissing = false
+	}
+
+	if r.timeColumn == "" {
+		var version int
+		if err := sqlsource.QueryValue(ctx, r.source, serverVersionQuery, &version); err != nil {
+			return pmetric.NewMetrics(), fmt.Errorf("failed to read server version: %w", err)
+		}
+		r.timeColumn = totalTimeColumn(version)
+		r.logger.Debug("Detected PostgreSQL version",
+			zap.Int("server_version_num", version),
+			zap.String("total_time_column", r.timeColumn))
+	}
+	r.checkReadAllStats(ctx)
+
+	minMeanMs := float64(r.config.MinMeanExecTime) / float64(time.Millisecond)
+	stats, err := querySlowStatements(ctx, r.source, r.timeColumn, minMeanMs, r.config.MaxStatements)
+	if sqlsource.IsInsufficientPrivilege(err) {
+		if !r.accessDenied {
+			r.logger.Warn("Permission denied reading pg_stat_statements; slow query metrics are disabled until access is granted",
+				zap.Error(err),
+				zap.String("remediation", "grant the monitoring user USAGE on the extension's schema and pg_read_all_stats"))
+			r.accessDenied = true
+		}
+		return pmetric.NewMetrics(), nil
+	}
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+	if r.accessDenied {
+		r.logger.Info("pg_stat_statements is now readable; collecting slow query metrics")
+		r.accessDenied = false
+	}
+
+	return r.buildMetrics(stats, pcommon.NewTimestampFromTime(time.Now())), nil
+}
+
+// checkReadAllStats logs when the user starts or stops seeing the
+// statements of other users. A failed check is not fatal; the statements
+// are still collected.
+func (r *slowQueriesReceiver) checkReadAllStats(ctx context.Context) {
+	var readAll bool
+	if err := sqlsource.QueryValue(ctx, r.source, readAllStatsQuery, &readAll); err != nil {
+		r.logger.Debug("Failed to check for pg_read_all_stats", zap.Error(err))
+		return
+	}
+	switch {
+	case !readAll && !r.statementsHidden:
+		r.logger.Warn("Monitoring user lacks pg_read_all_stats; only its own statements are reported",
+			zap.String("remediation", "GRANT pg_read_all_stats TO <monitoring user>"))
+		r.statementsHidden = true
+	case readAll && r.statementsHidden:
+		r.logger.Info("Monitoring user can read all statements; reporting statements of every user")
+		r.statementsHidden = false
+	}
+}
+
+// buildMetrics converts statement statistics to metrics
+func (r *slowQueriesReceiver) buildMetrics(stats []statementStats, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	if len(stats) == 0 {
+		return md
+	}
+	sm := r.newScopeMetrics(md)
+
+	count := sm.Metrics().AppendEmpty()
+	count.SetName(metricCount)
+	count.SetDescription("Number of times the statement was executed")
+	count.SetUnit("{call}")
+	countSum := count.SetEmptySum()
+	countSum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	countSum.SetIsMonotonic(true)
+
+	elapsed := newGauge(sm, metricElapsedTime, "Mean execution time of the statement", "ms")
+	reads := newGauge(sm, metricDiskReads, "Mean shared blocks read from disk per execution", "{block}")
+	writes := newGauge(sm, metricDiskWrites, "Mean shared blocks written per execution", "{block}")
+
+	for _, s := range stats {
+		statement := normalizeStatement(s.Query, r.config.MaxStatementLength)
+		attrs := pcommon.NewMap()
+		attrs.PutStr("db.postgresql.query_id", s.QueryID)
+		attrs.PutStr("db.statement", statement)
+		attrs.PutStr("db.name", s.Database)
+		attrs.PutStr("db.operation", statementOperation(statement))
+
+		dp := countSum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(r.startTime)
+		dp.SetTimestamp(now)
+		dp.SetIntValue(s.Calls)
+		attrs.CopyTo(dp.Attributes())
+
+		addGaugePoint(elapsed, s.MeanExecTimeMs, now, attrs)
+		addGaugePoint(reads, s.MeanBlocksRead, now, attrs)
+		addGaugePoint(writes, s.MeanBlocksWritten, now, attrs)
+	}
+
+	return md
+}
+
+// buildResetMetric returns the reset marker, a gauge of 1 at the reset time
+func (r *slowQueriesReceiver) buildResetMetric(now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	sm := r.newScopeMetrics(md)
+	reset := newGauge(sm, metricReset, "Set when pg_stat_statements is reset; statement statistics start again from zero", "{reset}")
+	dp := reset.DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetIntValue(1)
+	return md
+}
+
+// newScopeMetrics adds the receiver's resource and scope to md
+func (r *slowQueriesReceiver) newScopeMetrics(md pmetric.Metrics) pmetric.ScopeMetrics {
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("db.system", "postgresql")
+	for k, v := range r.config.ResourceAttributes {
+		rm.Resource().Attributes().PutStr(k, v)
+	}
+
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("pgslowqueries_receiver")
+	sm.Scope().SetVersion("1.0.0")
+	return sm
+}
+
+func newGauge(sm pmetric.ScopeMetrics, name, description, unit string) pmetric.Gauge {
+	metric := sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDescription(description)
+	metric.SetUnit(unit)
+	return metric.SetEmptyGauge()
+}
+
+func addGaugePoint(gauge pmetric.Gauge, value float64, now pcommon.Timestamp, attrs pcommon.Map) {
+	dp := gauge.DataPoints().AppendEmpty()
+	dp.SetTimestamp(now)
+	dp.SetDoubleValue(value)
+	attrs.CopyTo(dp.Attributes())
+}
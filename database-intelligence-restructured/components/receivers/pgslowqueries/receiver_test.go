@@ -0,0 +1,626 @@
+package pgslowqueries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers queries by their first distinguishing fragment
+type stubSource struct {
+	extensionInstalled bool
+	serverVersion      int
+	readAllStats       bool
+	statements         [][]any
+	// statementsErr is returned when pg_stat_statements is queried
+	statementsErr error
+	queries       []string
+	args          [][]any
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+	switch {
+	case query == extensionInstalledQuery:
+		return sqlsourcetest.NewRows([][]any{{s.extensionInstalled}}), nil
+	case query == serverVersionQuery:
+		return sqlsourcetest.NewRows([][]any{{s.serverVersion}}), nil
+	case query == readAllStatsQuery:
+		return sqlsourcetest.NewRows([][]any{{s.readAllStats}}), nil
+	case query == resetStatementsQuery:
+		return sqlsourcetest.NewRows([][]any{{nil}}), nil
+	case strings.Contains(query, "FROM pg_stat_statements"):
+		if !s.extensionInstalled {
+			return nil, errors.New(`relation "pg_stat_statements" does not exist`)
+		}
+		if s.statementsErr != nil {
+			return nil, s.statementsErr
+		}
+		return sqlsourcetest.NewRows(s.statements), nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (s *stubSource) Close() error { return nil }
+
+func newTestReceiver(source *stubSource) *slowQueriesReceiver {
+	cfg := DefaultConfig()
+	cfg.Datasource = "postgres://localhost:5432/postgres"
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newSlowQueriesReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	r.startTime = pcommon.NewTimestampFromTime(time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC))
+	return r
+}
+
+func findMetric(t *testing.T, md pmetric.Metrics, name string) pmetric.Metric {
+	t.Helper()
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i)
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return pmetric.NewMetric()
+}
+
+func TestScrapeEmitsSlowQueryMetrics(t *testing.T) {
+	source := &stubSource{
+		extensionInstalled: true,
+		serverVersion:      150004,
+		statements: [][]any{
+			{"-8317469238212445520", "SELECT * FROM orders /* request_id=42 */\n  WHERE customer_id = $1;", "shop", int64(1200), 250.5, 12.0, 0.5},
+			{"512", "UPDATE  inventory SET qty = qty - $1 WHERE sku = $2", nil, int64(40), 120.0, 3.0, 4.0},
+		},
+	}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+
+	resource := md.ResourceMetrics().At(0).Resource().Attributes()
+	system, _ := resource.Get("db.system")
+	assert.Equal(t, "postgresql", system.Str())
+	env, _ := resource.Get("deployment.environment")
+	assert.Equal(t, "test", env.Str())
+
+	count := findMetric(t, md, metricCount)
+	assert.Equal(t, "{call}", count.Unit())
+	require.Equal(t, pmetric.MetricTypeSum, count.Type())
+	assert.True(t, count.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, count.Sum().AggregationTemporality())
+	require.Equal(t, 2, count.Sum().DataPoints().Len())
+
+	dp := count.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(1200), dp.IntValue())
+	assert.Equal(t, r.startTime, dp.StartTimestamp())
+	assert.Equal(t, map[string]any{
+		"db.postgresql.query_id": "-8317469238212445520",
+		"db.statement":           "SELECT * FROM orders WHERE customer_id = $1",
+		"db.name":                "shop",
+		"db.operation":           "SELECT",
+	}, dp.Attributes().AsRaw())
+
+	elapsed := findMetric(t, md, metricElapsedTime)
+	assert.Equal(t, "ms", elapsed.Unit())
+	assert.Equal(t, 250.5, elapsed.Gauge().DataPoints().At(0).DoubleValue())
+
+	reads := findMetric(t, md, metricDiskReads)
+	assert.Equal(t, "{block}", reads.Unit())
+	assert.Equal(t, 12.0, reads.Gauge().DataPoints().At(0).DoubleValue())
+
+	writes := findMetric(t, md, metricDiskWrites)
+	second := writes.Gauge().DataPoints().At(1)
+	assert.Equal(t, 4.0, second.DoubleValue())
+	assert.Equal(t, map[string]any{
+		"db.postgresql.query_id": "512",
+		"db.statement":           "UPDATE inventory SET qty = qty - $1 WHERE sku = $2",
+		"db.name":                "",
+		"db.operation":           "UPDATE",
+	}, second.Attributes().AsRaw())
+
+	// PostgreSQL 13 and later name the column total_exec_time
+	last := len(source.queries) - 1
+	assert.Contains(t, source.queries[last], "sum(s.total_exec_time)")
+	assert.Equal(t, []any{100.0, 100}, source.args[last])
+}
+
+// metricSemantics is the unit and aggregation a metric is declared with
+type metricSemantics struct {
+	unit        string
+	metricType  pmetric.MetricType
+	temporality pmetric.AggregationTemporality
+}
+
+func TestMetricUnits(t *testing.T) {
+	source := &stubSource{
+		extensionInstalled: true,
+		serverVersion:      150004,
+		statements: [][]any{
+			{"512", "SELECT 1", "shop", int64(40), 120.0, 3.0, 4.0},
+		},
+	}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	reset := r.buildResetMetric(pcommon.NewTimestampFromTime(time.Now()))
+
+	// Counters are cumulative since the receiver started; per-statement
+	// averages and the reset marker are gauges
+	want := map[string]metricSemantics{
+		metricCount:       {"{call}", pmetric.MetricTypeSum, pmetric.AggregationTemporalityCumulative},
+		metricElapsedTime: {"ms", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+		metricDiskReads:   {"{block}", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+		metricDiskWrites:  {"{block}", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+		metricReset:       {"{reset}", pmetric.MetricTypeGauge, pmetric.AggregationTemporalityUnspecified},
+	}
+	got := make(map[string]metricSemantics)
+	for _, md := range []pmetric.Metrics{md, reset} {
+		metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		for i := 0; i < metrics.Len(); i++ {
+			m := metrics.At(i)
+			s := metricSemantics{unit: m.Unit(), metricType: m.Type()}
+			if m.Type() == pmetric.MetricTypeSum {
+				assert.True(t, m.Sum().IsMonotonic(), m.Name())
+				s.temporality = m.Sum().AggregationTemporality()
+			}
+			got[m.Name()] = s
+		}
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestScrapeSelectsColumnsByServerVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverVersion int
+		column        string
+		otherColumn   string
+	}{
+		{"PostgreSQL 10", 100023, "total_time", "total_exec_time"},
+		{"PostgreSQL 12", 120015, "total_time", "total_exec_time"},
+		{"PostgreSQL 13", 130000, "total_exec_time", "total_time"},
+		{"PostgreSQL 17", 170002, "total_exec_time", "total_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &stubSource{
+				extensionInstalled: true,
+				serverVersion:      tt.serverVersion,
+				readAllStats:       true,
+				statements:         [][]any{{"7", "SELECT 1", "app", int64(2), 150.0, 0.0, 0.0}},
+			}
+			r := newTestReceiver(source)
+
+			md, err := r.scrape(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 4, md.MetricCount())
+			assert.Equal(t, tt.column, r.timeColumn)
+
+			last := source.queries[len(source.queries)-1]
+			assert.Contains(t, last, "sum(s."+tt.column+")")
+			assert.NotContains(t, last, "s."+tt.otherColumn+")")
+
+			// The version is detected once
+			_, err = r.scrape(context.Background())
+			require.NoError(t, err)
+			versionChecks := 0
+			for _, query := range source.queries {
+				if query == serverVersionQuery {
+					versionChecks++
+				}
+			}
+			assert.Equal(t, 1, versionChecks)
+		})
+	}
+}
+
+func TestScrapeWithoutReadAllStats(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	source := &stubSource{
+		extensionInstalled: true,
+		serverVersion:      160000,
+		statements:         [][]any{{"7", "SELECT 1", "app", int64(2), 150.0, 0.0, 0.0}},
+	}
+	r := newTestReceiver(source)
+	r.logger = zap.New(core)
+
+	// The user's own statements are still reported
+	for i := 0; i < 2; i++ {
+		md, err := r.scrape(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 4, md.MetricCount())
+	}
+	warnings := logs.FilterMessageSnippet("lacks pg_read_all_stats").All()
+	require.Len(t, warnings, 1, "the diagnostic is logged once")
+	assert.Equal(t, zapcore.WarnLevel, warnings[0].Level)
+	assert.True(t, r.statementsHidden)
+
+	source.readAllStats = true
+	_, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.False(t, r.statementsHidden)
+	assert.Equal(t, 1, logs.FilterMessageSnippet("can read all statements").Len())
+}
+
+func TestScrapeWithPermissionDenied(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	source := &stubSource{
+		extensionInstalled: true,
+		serverVersion:      120015,
+		readAllStats:       true,
+		statementsErr:      &pq.Error{Code: "42501", Message: "permission denied for view pg_stat_statements"},
+	}
+	r := newTestReceiver(source)
+	r.logger = zap.New(core)
+
+	// The scrape degrades to no metrics instead of failing
+	for i := 0; i < 2; i++ {
+		md, err := r.scrape(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, md.MetricCount())
+	}
+	warnings := logs.FilterMessageSnippet("Permission denied reading pg_stat_statements").All()
+	require.Len(t, warnings, 1, "the diagnostic is logged once")
+	assert.Contains(t, warnings[0].ContextMap()["error"], "permission denied for view pg_stat_statements")
+
+	// Other errors still fail the scrape
+	source.statementsErr = errors.New("connection reset by peer")
+	_, err := r.scrape(context.Background())
+	assert.ErrorContains(t, err, "connection reset by peer")
+
+	// Collection resumes once access is granted
+	source.statementsErr = nil
+	source.statements = [][]any{{"7", "SELECT 1", "app", int64(2), 150.0, 0.0, 0.0}}
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, md.MetricCount())
+	assert.False(t, r.accessDenied)
+	assert.Equal(t, 1, logs.FilterMessageSnippet("now readable").Len())
+}
+
+func TestScrapeWithoutExtension(t *testing.T) {
+	source := &stubSource{extensionInstalled: false, serverVersion: 160000}
+	r := newTestReceiver(source)
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.True(t, r.extensionMissing)
+	assert.Equal(t, []string{extensionInstalledQuery}, source.queries, "pg_stat_statements must not be queried")
+
+	// Collection resumes once the extension is created
+	source.extensionInstalled = true
+	source.statements = [][]any{{"1", "DELETE FROM sessions WHERE expires < $1", "app", int64(3), 900.0, 1.0, 1.0}}
+	md, err = r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.False(t, r.extensionMissing)
+	assert.Equal(t, 4, md.MetricCount())
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{extensionInstalled: true, serverVersion: 160000}
+	sqlsourcetest.Lifecycle(t, "postgres://localhost:5432/postgres", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(source)
+		r.openSource = open
+		return r
+	})
+}
+
+// fakeTickers hands out tick channels that the test fires by interval
+type fakeTickers struct {
+	mu    sync.Mutex
+	ticks map[time.Duration]chan time.Time
+}
+
+func (f *fakeTickers) newTicker(d time.Duration) (<-chan time.Time, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ticks == nil {
+		f.ticks = make(map[time.Duration]chan time.Time)
+	}
+	f.ticks[d] = make(chan time.Time)
+	return f.ticks[d], func() {}
+}
+
+func (f *fakeTickers) intervals() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var intervals []time.Duration
+	for d := range f.ticks {
+		intervals = append(intervals, d)
+	}
+	return intervals
+}
+
+func (f *fakeTickers) tick(d time.Duration) {
+	f.mu.Lock()
+	ch := f.ticks[d]
+	f.mu.Unlock()
+	ch <- time.Now()
+}
+
+func countResetMarkers(t *testing.T, sink *consumertest.MetricsSink) int {
+	t.Helper()
+	markers := 0
+	for _, md := range sink.AllMetrics() {
+		metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		for i := 0; i < metrics.Len(); i++ {
+			if metrics.At(i).Name() != metricReset {
+				continue
+			}
+			dp := metrics.At(i).Gauge().DataPoints().At(0)
+			assert.Equal(t, int64(1), dp.IntValue())
+			env, _ := md.ResourceMetrics().At(0).Resource().Attributes().Get("deployment.environment")
+			assert.Equal(t, "test", env.Str())
+			markers++
+		}
+	}
+	return markers
+}
+
+func TestResetSchedulerFiresAtInterval(t *testing.T) {
+	source := &stubSource{extensionInstalled: true, serverVersion: 170000}
+	sink := &consumertest.MetricsSink{}
+	tickers := &fakeTickers{}
+
+	r := newTestReceiver(source)
+	r.consumer = sink
+	r.config.Reset = ResetConfig{Enabled: true, Interval: 6 * time.Hour}
+	r.openSource = func(ctx context.Context, datasource string) (sqlsource.Source, error) {
+		return source, nil
+	}
+	r.newTicker = tickers.newTicker
+	startTime := r.startTime
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool { return len(tickers.intervals()) == 2 }, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []time.Duration{time.Minute, 6 * time.Hour}, tickers.intervals())
+
+	// Every tick of the reset interval resets once and emits one marker
+	tickers.tick(6 * time.Hour)
+	require.Eventually(t, func() bool { return countResetMarkers(t, sink) == 1 }, time.Second, time.Millisecond)
+	tickers.tick(time.Minute)
+	tickers.tick(6 * time.Hour)
+	require.Eventually(t, func() bool { return countResetMarkers(t, sink) == 2 }, time.Second, time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	resets := 0
+	for i, query := range source.queries {
+		if query != resetStatementsQuery {
+			continue
+		}
+		resets++
+		// The statistics are collected right before they are cleared, after
+		// the reset checks for the extension
+		assert.Equal(t, extensionInstalledQuery, source.queries[i-1])
+		assert.Contains(t, source.queries[i-2], "FROM pg_stat_statements")
+	}
+	assert.Equal(t, 2, resets)
+	assert.Greater(t, r.startTime, startTime, "cumulative counts start again at the reset")
+}
+
+func TestResetSchedulerOffByDefault(t *testing.T) {
+	assert.False(t, DefaultConfig().Reset.Enabled)
+
+	source := &stubSource{extensionInstalled: true, serverVersion: 160000}
+	tickers := &fakeTickers{}
+	r := newTestReceiver(source)
+	r.openSource = func(ctx context.Context, datasource string) (sqlsource.Source, error) {
+		return source, nil
+	}
+	r.newTicker = tickers.newTicker
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool { return len(tickers.intervals()) == 1 }, time.Second, time.Millisecond)
+	tickers.tick(time.Minute)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	assert.Equal(t, []time.Duration{time.Minute}, tickers.intervals())
+	assert.NotContains(t, source.queries, resetStatementsQuery)
+}
+
+func TestResetWithoutExtension(t *testing.T) {
+	source := &stubSource{extensionInstalled: false}
+	r := newTestReceiver(source)
+	startTime := r.startTime
+
+	md, err := r.reset(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.Equal(t, []string{extensionInstalledQuery}, source.queries)
+	assert.Equal(t, startTime, r.startTime)
+}
+
+func TestCollectBacksOffWhileFailing(t *testing.T) {
+	source := &stubSource{
+		extensionInstalled: true,
+		serverVersion:      160000,
+		readAllStats:       true,
+		statements:         [][]any{{"512", "SELECT pg_sleep($1)", "shop", int64(3), 1000.0, 0.0, 0.0}},
+		statementsErr:      errors.New("connection refused"),
+	}
+	sink := &consumertest.MetricsSink{}
+	tickers := &fakeTickers{}
+
+	r := newTestReceiver(source)
+	r.consumer = sink
+	r.openSource = func(ctx context.Context, datasource string) (sqlsource.Source, error) {
+		return source, nil
+	}
+	r.newTicker = tickers.newTicker
+	var mu sync.Mutex
+	var statuses []component.Status
+	r.backoff.ReportStatus = func(event *component.StatusEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, event.Status())
+	}
+
+	statementQueries := func() int {
+		count := 0
+		for _, query := range source.queries {
+			if strings.Contains(query, "FROM pg_stat_statements") {
+				count++
+			}
+		}
+		return count
+	}
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool { return len(tickers.intervals()) == 1 }, time.Second, time.Millisecond)
+
+	// Failures at ticks 0, 2 and 6 widen the interval to 2, 4 and 4 minutes,
+	// the most that stays within the 5 minute cap. Tick 7 is skipped, so
+	// once it is received tick 6 has been collected and the source is idle.
+	for tick := 0; tick <= 7; tick++ {
+		tickers.tick(time.Minute)
+	}
+	assert.Equal(t, 3, statementQueries())
+
+	// The database recovers: tick 10 collects, and tick 11 follows at the
+	// configured interval
+	source.statementsErr = nil
+	for tick := 8; tick <= 11; tick++ {
+		tickers.tick(time.Minute)
+	}
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	assert.Equal(t, 5, statementQueries())
+	assert.Len(t, sink.AllMetrics(), 2)
+	assert.Equal(t, []component.Status{component.StatusRecoverableError, component.StatusOK}, statuses)
+}
+
+func TestNormalizeStatement(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "collapses whitespace",
+			query:  "SELECT  *\n\tFROM users\r\n WHERE id = $1",
+			maxLen: 100,
+			want:   "SELECT * FROM users WHERE id = $1",
+		},
+		{
+			name:   "removes comments",
+			query:  "/* app=api */ SELECT name -- trailing\nFROM users",
+			maxLen: 100,
+			want:   "SELECT name FROM users",
+		},
+		{
+			name:   "keeps comment markers inside literals",
+			query:  "SELECT '--not a comment', \"a  /* b */\" FROM t",
+			maxLen: 100,
+			want:   "SELECT '--not a comment', \"a  /* b */\" FROM t",
+		},
+		{
+			name:   "handles escaped quotes",
+			query:  "SELECT 'it''s  --fine' FROM t;",
+			maxLen: 100,
+			want:   "SELECT 'it''s  --fine' FROM t",
+		},
+		{
+			name:   "truncates on a rune boundary",
+			query:  "SELECT 'héllo'",
+			maxLen: 10,
+			want:   "SELECT 'h",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeStatement(tt.query, tt.maxLen))
+		})
+	}
+}
+
+func TestStatementOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1":                                         "SELECT",
+		"insert into t values ($1)":                        "INSERT",
+		"(SELECT 1) UNION (SELECT 2)":                      "SELECT",
+		"WITH recent AS (SELECT id FROM t) DELETE FROM t":  "DELETE",
+		"WITH a AS ( SELECT 1 ), b AS (SELECT 2) SELECT 3": "SELECT",
+		"VACUUM ANALYZE t":                                 "OTHER",
+		"":                                                 "OTHER",
+	}
+
+	for statement, want := range tests {
+		assert.Equal(t, want, statementOperation(statement), statement)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:    "negative minimum mean execution time",
+			modify:  func(cfg *Config) { cfg.MinMeanExecTime = -time.Millisecond },
+			wantErr: "min_mean_exec_time cannot be negative",
+		},
+		{
+			name:    "zero max statements",
+			modify:  func(cfg *Config) { cfg.MaxStatements = 0 },
+			wantErr: "max_statements must be positive",
+		},
+		{
+			name: "reset interval below collection interval",
+			modify: func(cfg *Config) {
+				cfg.Reset = ResetConfig{Enabled: true, Interval: 30 * time.Second}
+			},
+			wantErr: "reset.interval (30s) cannot be less than collection_interval (1m0s)",
+		},
+		{
+			name:   "reset interval ignored while disabled",
+			modify: func(cfg *Config) { cfg.Reset.Interval = 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "postgres://localhost:5432/postgres"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
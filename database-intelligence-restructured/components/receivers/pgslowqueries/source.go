@@ -0,0 +1,110 @@
+package pgslowqueries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+const (
+	extensionInstalledQuery = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`
+
+	serverVersionQuery = `SELECT current_setting('server_version_num')::int`
+
+	// readAllStatsQuery checks whether the user may see the statements of
+	// other users. Without pg_read_all_stats their rows have a NULL queryid
+	// and "<insufficient privilege>" as the text; superusers pass the check.
+	readAllStatsQuery = `SELECT pg_has_role(current_user, 'pg_read_all_stats', 'USAGE')`
+
+	// resetStatementsQuery clears the statistics of every database and user
+	resetStatementsQuery = `SELECT pg_stat_statements_reset()`
+
+	// slowStatementsQuery aggregates pg_stat_statements, which keeps one row
+	// per user and top-level flag, into one row per statement and database.
+	// The %[1]s placeholder is the total execution time column, which was
+	// renamed in PostgreSQL 13.
+	slowStatementsQuery = `SELECT
+  s.queryid::text,
+  min(s.query),
+  d.datname,
+  sum(s.calls)::bigint,
+  (sum(s.%[1]s) / sum(s.calls))::float8,
+  (sum(s.shared_blks_read)::float8 / sum(s.calls))::float8,
+  (sum(s.shared_blks_written)::float8 / sum(s.calls))::float8
+FROM pg_stat_statements s
+JOIN pg_database d ON d.oid = s.dbid
+WHERE s.queryid IS NOT NULL
+GROUP BY s.queryid, d.datname
+HAVING sum(s.calls) > 0 AND sum(s.%[1]s) / sum(s.calls) >= $1
+ORDER BY 5 DESC
+LIMIT $2`
+)
+
+// statementStats is one statement's pg_stat_statements counters
+type statementStats struct {
+	QueryID string
+	Query   string
+	// Database is the name of the database the statement ran in
+	Database string
+	Calls    int64
+	// MeanExecTimeMs is the mean execution time in milliseconds
+	MeanExecTimeMs float64
+	// MeanBlocksRead and MeanBlocksWritten are shared blocks per call
+	MeanBlocksRead    float64
+	MeanBlocksWritten float64
+}
+
+// totalTimeColumn returns the pg_stat_statements total execution time column
+// for a server_version_num: total_time before PostgreSQL 13, which split it
+// into total_plan_time and total_exec_time
+func totalTimeColumn(serverVersion int) string {
+	if serverVersion >= 130000 {
+		return "total_exec_time"
+	}
+	return "total_time"
+}
+
+// querySlowStatements returns the slowest statements by mean execution time
+func querySlowStatements(ctx context.Context, source sqlsource.Source, timeColumn string, minMeanExecTimeMs float64, limit int) ([]statementStats, error) {
+	rs, err := source.Query(ctx, fmt.Sprintf(slowStatementsQuery, timeColumn), minMeanExecTimeMs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_statements: %w", err)
+	}
+	defer rs.Close()
+
+	var stats []statementStats
+	for rs.Next() {
+		var s statementStats
+		var database sql.NullString
+		if err := rs.Scan(&s.QueryID, &s.Query, &database, &s.Calls,
+			&s.MeanExecTimeMs, &s.MeanBlocksRead, &s.MeanBlocksWritten); err != nil {
+			return nil, fmt.Errorf("failed to scan pg_stat_statements row: %w", err)
+		}
+		s.Database = database.String
+		stats = append(stats, s)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements rows: %w", err)
+	}
+	return stats, nil
+}
+
+// resetStatements runs pg_stat_statements_reset(). The function returns void
+// before PostgreSQL 17 and the reset time from 17 on, so the result is not
+// read.
+func resetStatements(ctx context.Context, source sqlsource.Source) error {
+	rs, err := source.Query(ctx, resetStatementsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+	}
+	if err := rs.Err(); err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	return nil
+}
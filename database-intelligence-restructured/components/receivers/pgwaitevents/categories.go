@@ -0,0 +1,72 @@
+package pgwaitevents
+
+import "strings"
+
+// Categories for sessions that are running rather than waiting
+const (
+	cpuCategory  = "CPU"
+	cpuEventName = "CPU"
+
+	// otherCategory is used for wait event types no rule matches, e.g. types
+	// added in a newer PostgreSQL release
+	otherCategory = "Other"
+)
+
+// CategoryRule maps wait events to a category. An empty WaitEvent matches
+// every event of the type.
+type CategoryRule struct {
+	WaitEventType string `mapstructure:"wait_event_type"`
+	WaitEvent     string `mapstructure:"wait_event"`
+	Category      string `mapstructure:"category"`
+}
+
+// defaultCategoryRules follow PostgreSQL's wait_event_type values. Rules for
+// specific events come first so they take precedence over the type's rule.
+var defaultCategoryRules = []CategoryRule{
+	// WAL and data file LWLocks are held around I/O
+	{WaitEventType: "LWLock", WaitEvent: "WALWrite", Category: "IO"},
+	{WaitEventType: "LWLock", WaitEvent: "BufferIO", Category: "IO"},
+
+	{WaitEventType: "IO", Category: "IO"},
+	{WaitEventType: "Lock", Category: "Lock"},
+	{WaitEventType: "LWLock", Category: "LWLock"},
+	{WaitEventType: "BufferPin", Category: "BufferPin"},
+	{WaitEventType: "Client", Category: "Client"},
+	{WaitEventType: "IPC", Category: "IPC"},
+	{WaitEventType: "Timeout", Category: "Timeout"},
+	{WaitEventType: "Activity", Category: "Activity"},
+	{WaitEventType: "Extension", Category: "Extension"},
+}
+
+// categoryMapper assigns categories to wait events using an ordered rule
+// table; the first matching rule wins
+type categoryMapper struct {
+	rules []CategoryRule
+}
+
+// newCategoryMapper returns a mapper that checks overrides before the
+// default rules
+func newCategoryMapper(overrides []CategoryRule) *categoryMapper {
+	rules := make([]CategoryRule, 0, len(overrides)+len(defaultCategoryRules))
+	rules = append(rules, overrides...)
+	rules = append(rules, defaultCategoryRules...)
+	return &categoryMapper{rules: rules}
+}
+
+// category returns the category of a wait event. Matching ignores case, as
+// the capitalization of some event names changed between releases.
+func (m *categoryMapper) category(waitEventType, waitEvent string) string {
+	if waitEventType == "" {
+		return cpuCategory
+	}
+
+	for _, rule := range m.rules {
+		if !strings.EqualFold(rule.WaitEventType, waitEventType) {
+			continue
+		}
+		if rule.WaitEvent == "" || strings.EqualFold(rule.WaitEvent, waitEvent) {
+			return rule.Category
+		}
+	}
+	return otherCategory
+}
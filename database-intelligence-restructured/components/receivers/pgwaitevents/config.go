@@ -0,0 +1,58 @@
+package pgwaitevents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// Config represents the receiver configuration
+type Config struct {
+	// Datasource is the PostgreSQL connection string
+	Datasource string `mapstructure:"datasource"`
+
+	// CollectionInterval is how often pg_stat_activity is sampled
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+
+	// QueryTimeout bounds each pg_stat_activity query
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+
+	// IncludeCPU reports active sessions that are not waiting under the CPU
+	// event and category
+	IncludeCPU bool `mapstructure:"include_cpu"`
+
+	// CategoryOverrides are checked before the default category rules, so
+	// they can recategorize single events or whole wait event types
+	CategoryOverrides []CategoryRule `mapstructure:"category_overrides"`
+
+	// ResourceAttributes are added to the resource of every batch
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+// Validate checks if the configuration is valid
+func (cfg *Config) Validate() error {
+	if err := sqlsource.ValidateCollection(cfg.Datasource, cfg.CollectionInterval, cfg.QueryTimeout); err != nil {
+		return err
+	}
+
+	for i, rule := range cfg.CategoryOverrides {
+		if rule.WaitEventType == "" {
+			return fmt.Errorf("category_overrides[%d]: wait_event_type is required", i)
+		}
+		if rule.Category == "" {
+			return fmt.Errorf("category_overrides[%d]: category is required", i)
+		}
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		CollectionInterval: 10 * time.Second,
+		QueryTimeout:       5 * time.Second,
+		IncludeCPU:         true,
+	}
+}
@@ -0,0 +1,216 @@
+package pgwaitevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource/sqlsourcetest"
+)
+
+// stubSource answers the active sessions query with fixed rows
+type stubSource struct {
+	sessions [][]any
+	err      error
+}
+
+func (s *stubSource) Query(ctx context.Context, query string, args ...any) (sqlsource.Rows, error) {
+	if query != activeSessionsQuery {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return sqlsourcetest.NewRows(s.sessions), nil
+}
+
+func (s *stubSource) Close() error { return nil }
+
+func newTestReceiver(cfg *Config, source *stubSource) *waitEventsReceiver {
+	cfg.Datasource = "postgres://localhost:5432/postgres"
+	r := newWaitEventsReceiver(cfg, zap.NewNop(), consumertest.NewNop())
+	r.source = source
+	return r
+}
+
+func TestCategoryMapping(t *testing.T) {
+	mapper := newCategoryMapper(nil)
+
+	tests := []struct {
+		waitEventType string
+		waitEvent     string
+		want          string
+	}{
+		{"IO", "DataFileRead", "IO"},
+		{"Lock", "transactionid", "Lock"},
+		{"Lock", "relation", "Lock"},
+		{"LWLock", "LockManager", "LWLock"},
+		{"LWLock", "WALWrite", "IO"},
+		{"LWLock", "WALWriteLock", "LWLock"},
+		{"LWLock", "BufferIO", "IO"},
+		{"LWLock", "buffer_io", "LWLock"},
+		{"BufferPin", "BufferPin", "BufferPin"},
+		{"Client", "ClientRead", "Client"},
+		{"IPC", "ParallelFinish", "IPC"},
+		{"Timeout", "PgSleep", "Timeout"},
+		{"Activity", "WalWriterMain", "Activity"},
+		{"Extension", "Extension", "Extension"},
+		{"io", "datafileread", "IO"},
+		{"", "", "CPU"},
+		{"InjectionPoint", "test", "Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.waitEventType+"/"+tt.waitEvent, func(t *testing.T) {
+			assert.Equal(t, tt.want, mapper.category(tt.waitEventType, tt.waitEvent))
+		})
+	}
+}
+
+func TestCategoryOverrides(t *testing.T) {
+	mapper := newCategoryMapper([]CategoryRule{
+		{WaitEventType: "Lock", WaitEvent: "advisory", Category: "Application"},
+		{WaitEventType: "IPC", Category: "Replication"},
+	})
+
+	assert.Equal(t, "Application", mapper.category("Lock", "advisory"))
+	assert.Equal(t, "Lock", mapper.category("Lock", "tuple"), "other events keep the default category")
+	assert.Equal(t, "Replication", mapper.category("IPC", "SyncRep"))
+	assert.Equal(t, "IO", mapper.category("LWLock", "WALWrite"))
+}
+
+func TestScrapeEmitsWaitEvents(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CollectionInterval = 15 * time.Second
+	cfg.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	r := newTestReceiver(cfg, &stubSource{sessions: [][]any{
+		{"shop", "Lock", "transactionid", int64(3)},
+		{"shop", "", "", int64(2)},
+		{"", "LWLock", "WALWrite", int64(1)},
+	}})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, md.MetricCount())
+
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"db.system":              "postgresql",
+		"deployment.environment": "test",
+	}, rm.Resource().Attributes().AsRaw())
+
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, metricWaitEvents, metric.Name())
+	assert.Equal(t, "ms", metric.Unit())
+
+	points := metric.Gauge().DataPoints()
+	require.Equal(t, 3, points.Len())
+
+	assert.Equal(t, 45000.0, points.At(0).DoubleValue())
+	assert.Equal(t, map[string]any{
+		"db.wait_event.name":     "transactionid",
+		"db.wait_event.category": "Lock",
+		"db.wait_event.type":     "Lock",
+		"db.name":                "shop",
+	}, points.At(0).Attributes().AsRaw())
+
+	assert.Equal(t, 30000.0, points.At(1).DoubleValue())
+	assert.Equal(t, map[string]any{
+		"db.wait_event.name":     "CPU",
+		"db.wait_event.category": "CPU",
+		"db.wait_event.type":     "",
+		"db.name":                "shop",
+	}, points.At(1).Attributes().AsRaw())
+
+	category, _ := points.At(2).Attributes().Get("db.wait_event.category")
+	assert.Equal(t, "IO", category.Str())
+}
+
+func TestScrapeExcludesCPU(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IncludeCPU = false
+	r := newTestReceiver(cfg, &stubSource{sessions: [][]any{
+		{"shop", "", "", int64(4)},
+	}})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount(), "sessions that are only running are not reported")
+}
+
+func TestScrapeWithoutActivity(t *testing.T) {
+	r := newTestReceiver(DefaultConfig(), &stubSource{})
+
+	md, err := r.scrape(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, md.MetricCount())
+	assert.Equal(t, 0, md.ResourceMetrics().Len())
+}
+
+func TestScrapeQueryError(t *testing.T) {
+	r := newTestReceiver(DefaultConfig(), &stubSource{err: errors.New("permission denied for view pg_stat_activity")})
+
+	_, err := r.scrape(context.Background())
+	assert.EqualError(t, err, "failed to query pg_stat_activity: permission denied for view pg_stat_activity")
+}
+
+func TestReceiverLifecycle(t *testing.T) {
+	source := &stubSource{}
+	sqlsourcetest.Lifecycle(t, "postgres://localhost:5432/postgres", source, func(open sqlsource.OpenFunc) component.Component {
+		r := newTestReceiver(DefaultConfig(), source)
+		r.openSource = open
+		return r
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "override without wait event type",
+			modify: func(cfg *Config) {
+				cfg.CategoryOverrides = []CategoryRule{{WaitEvent: "advisory", Category: "Application"}}
+			},
+			wantErr: "category_overrides[0]: wait_event_type is required",
+		},
+		{
+			name: "override without category",
+			modify: func(cfg *Config) {
+				cfg.CategoryOverrides = []CategoryRule{{WaitEventType: "Lock"}}
+			},
+			wantErr: "category_overrides[0]: category is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Datasource = "postgres://localhost:5432/postgres"
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
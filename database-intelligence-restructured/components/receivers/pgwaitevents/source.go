@@ -0,0 +1,50 @@
+package pgwaitevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/database-intelligence/db-intel/components/receivers/internal/sqlsource"
+)
+
+// activeSessionsQuery counts active sessions by database and wait event. An
+// empty wait event type means the session is running, not waiting. Idle
+// sessions are excluded, as they always wait on ClientRead.
+const activeSessionsQuery = `SELECT
+  COALESCE(datname, ''),
+  COALESCE(wait_event_type, ''),
+  COALESCE(wait_event, ''),
+  count(*)
+FROM pg_stat_activity
+WHERE state = 'active' AND pid <> pg_backend_pid()
+GROUP BY 1, 2, 3`
+
+// waitEventSessions is the number of active sessions in one wait event
+type waitEventSessions struct {
+	Database      string
+	WaitEventType string
+	WaitEvent     string
+	Sessions      int64
+}
+
+// queryActiveSessions samples pg_stat_activity
+func queryActiveSessions(ctx context.Context, source sqlsource.Source) ([]waitEventSessions, error) {
+	rs, err := source.Query(ctx, activeSessionsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_activity: %w", err)
+	}
+	defer rs.Close()
+
+	var sessions []waitEventSessions
+	for rs.Next() {
+		var s waitEventSessions
+		if err := rs.Scan(&s.Database, &s.WaitEventType, &s.WaitEvent, &s.Sessions); err != nil {
+			return nil, fmt.Errorf("failed to scan pg_stat_activity row: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_activity rows: %w", err)
+	}
+	return sessions, nil
+}
@@ -8,16 +8,36 @@ import (
     "github.com/database-intelligence/db-intel/components/receivers/enhancedsql"
     "github.com/database-intelligence/db-intel/components/receivers/kernelmetrics"
     "github.com/database-intelligence/db-intel/components/receivers/mongodb"
+    "github.com/database-intelligence/db-intel/components/receivers/mysqlblockingsessions"
+    "github.com/database-intelligence/db-intel/components/receivers/mysqlslowqueries"
+    "github.com/database-intelligence/db-intel/components/receivers/mysqlwaitevents"
+    "github.com/database-intelligence/db-intel/components/receivers/pgbloat"
+    "github.com/database-intelligence/db-intel/components/receivers/pgblockingsessions"
+    "github.com/database-intelligence/db-intel/components/receivers/pgcanary"
+    "github.com/database-intelligence/db-intel/components/receivers/pgschemadrift"
+    "github.com/database-intelligence/db-intel/components/receivers/pgserverlog"
+    "github.com/database-intelligence/db-intel/components/receivers/pgslowqueries"
+    "github.com/database-intelligence/db-intel/components/receivers/pgwaitevents"
     "github.com/database-intelligence/db-intel/components/receivers/redis"
 )
 
 // All returns all receiver factories
 func All() map[component.Type]receiver.Factory {
     return map[component.Type]receiver.Factory{
-        ash.NewFactory().Type():           ash.NewFactory(),
-        enhancedsql.NewFactory().Type():   enhancedsql.NewFactory(),
-        kernelmetrics.NewFactory().Type(): kernelmetrics.NewFactory(),
-        mongodb.NewFactory().Type():       mongodb.NewFactory(),
-        redis.NewFactory().Type():         redis.NewFactory(),
+        ash.NewFactory().Type():                   ash.NewFactory(),
+        enhancedsql.NewFactory().Type():           enhancedsql.NewFactory(),
+        kernelmetrics.NewFactory().Type():         kernelmetrics.NewFactory(),
+        mongodb.NewFactory().Type():               mongodb.NewFactory(),
+        mysqlblockingsessions.NewFactory().Type(): mysqlblockingsessions.NewFactory(),
+        mysqlslowqueries.NewFactory().Type():      mysqlslowqueries.NewFactory(),
+        mysqlwaitevents.NewFactory().Type():       mysqlwaitevents.NewFactory(),
+        pgbloat.NewFactory().Type():               pgbloat.NewFactory(),
+        pgblockingsessions.NewFactory().Type():    pgblockingsessions.NewFactory(),
+        pgcanary.NewFactory().Type():              pgcanary.NewFactory(),
+        pgschemadrift.NewFactory().Type():         pgschemadrift.NewFactory(),
+        pgserverlog.NewFactory().Type():           pgserverlog.NewFactory(),
+        pgslowqueries.NewFactory().Type():         pgslowqueries.NewFactory(),
+        pgwaitevents.NewFactory().Type():          pgwaitevents.NewFactory(),
+        redis.NewFactory().Type():                 redis.NewFactory(),
     }
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/debugexporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+	"github.com/database-intelligence/db-intel/components/exporters/exporthealth"
+	"github.com/database-intelligence/db-intel/components/exporters/nri"
+	"github.com/database-intelligence/db-intel/components/extensions/healthcheck"
+	"github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+	"github.com/database-intelligence/db-intel/components/processors/circuitbreaker"
+	"github.com/database-intelligence/db-intel/components/processors/processortracing"
+	"github.com/database-intelligence/db-intel/components/receivers/ash"
+	"github.com/database-intelligence/db-intel/components/receivers/cyclemetrics"
+	"github.com/database-intelligence/db-intel/components/receivers/kernelmetrics"
+)
+
+// components returns the standard subset of components, keyed by component.Type
+func components() (otelcol.Factories, error) {
+	var err error
+	factories := otelcol.Factories{}
+
+	factories.Extensions, err = extension.MakeFactoryMap(
+		healthcheck.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Receivers, err = receiver.MakeFactoryMap(
+		otlpreceiver.NewFactory(),
+		cyclemetrics.Wrap(ash.NewFactory()),
+		kernelmetrics.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	// Custom processors (subset)
+	factories.Processors, err = processor.MakeFactoryMap(
+		batchprocessor.NewFactory(),
+		memorylimiterprocessor.NewFactory(),
+		processortracing.Wrap(adaptivesampler.NewFactory()),
+		processortracing.Wrap(circuitbreaker.NewFactory()),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	// The OTLP exporter reports each export to healthcheck, so export
+	// dependencies can tell whether data is leaving the collector
+	factories.Exporters, err = exporter.MakeFactoryMap(
+		debugexporter.NewFactory(),
+		exporthealth.Wrap(otlpexporter.NewFactory()),
+		nri.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Connectors, err = connector.MakeFactoryMap()
+	if err != nil {
+		return factories, err
+	}
+
+	return factories, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+)
+
+func TestComponents(t *testing.T) {
+	factories, err := components()
+	require.NoError(t, err)
+
+	for _, typ := range []string{"adaptivesampler", "circuit_breaker"} {
+		factory, ok := factories.Processors[component.MustNewType(typ)]
+		require.True(t, ok, "standard distribution is missing processor %q", typ)
+		assert.NotNil(t, factory.CreateDefaultConfig(), "processor %s", typ)
+	}
+
+	// Custom processors are wrapped for tracing, as in the unified build
+	sampler := factories.Processors[component.MustNewType("adaptivesampler")]
+	_, plain := sampler.CreateDefaultConfig().(*adaptivesampler.Config)
+	assert.False(t, plain, "adaptivesampler is not wrapped by processortracing")
+
+	for typ, f := range factories.Receivers {
+		assert.NotNil(t, f.CreateDefaultConfig(), "receiver %s", typ)
+	}
+	for typ, f := range factories.Exporters {
+		assert.NotNil(t, f.CreateDefaultConfig(), "exporter %s", typ)
+	}
+	for typ, f := range factories.Extensions {
+		assert.NotNil(t, f.CreateDefaultConfig(), "extension %s", typ)
+	}
+}
@@ -0,0 +1,37 @@
+module github.com/database-intelligence/db-intel/distributions/standard
+
+go 1.23.0
+
+require (
+	go.opentelemetry.io/collector/component v0.105.0
+	go.opentelemetry.io/collector/connector v0.105.0
+	go.opentelemetry.io/collector/exporter v0.105.0
+	go.opentelemetry.io/collector/exporter/debugexporter v0.105.0
+	go.opentelemetry.io/collector/exporter/otlpexporter v0.105.0
+	go.opentelemetry.io/collector/extension v0.105.0
+	go.opentelemetry.io/collector/otelcol v0.105.0
+	go.opentelemetry.io/collector/processor v0.105.0
+	go.opentelemetry.io/collector/processor/batchprocessor v0.105.0
+	go.opentelemetry.io/collector/processor/memorylimiterprocessor v0.105.0
+	go.opentelemetry.io/collector/receiver v0.105.0
+	go.opentelemetry.io/collector/receiver/otlpreceiver v0.105.0
+
+	// Custom components
+	github.com/database-intelligence/db-intel/components/exporters v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/components/extensions v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/components/processors v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/components/receivers v0.0.0-00010101000000-000000000000
+
+	github.com/stretchr/testify v1.10.0
+)
+
+replace (
+	github.com/database-intelligence/db-intel/components/exporters => ../../components/exporters
+	github.com/database-intelligence/db-intel/components/extensions => ../../components/extensions
+	github.com/database-intelligence/db-intel/components/processors => ../../components/processors
+	github.com/database-intelligence/db-intel/components/receivers => ../../components/receivers
+	github.com/database-intelligence/db-intel/internal => ../../internal
+	github.com/database-intelligence/db-intel/internal/featuredetector => ../../internal/featuredetector
+	github.com/database-intelligence/db-intel/internal/queryselector => ../../internal/queryselector
+	github.com/database-intelligence/db-intel/internal/database => ../../internal/database
+)
@@ -0,0 +1,27 @@
+// Command standard runs the Database Intelligence Collector with the standard
+// subset of custom components compiled in.
+package main
+
+import (
+	"log"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/otelcol"
+)
+
+func main() {
+	info := component.BuildInfo{
+		Command:     "database-intelligence-standard",
+		Description: "Database Intelligence Collector - Standard Edition",
+		Version:     "2.0.0",
+	}
+
+	settings := otelcol.CollectorSettings{
+		BuildInfo: info,
+		Factories: components,
+	}
+
+	if err := otelcol.NewCommand(settings).Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
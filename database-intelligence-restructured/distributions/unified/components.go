@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+
 	"go.opentelemetry.io/collector/connector"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/extension"
@@ -8,6 +10,8 @@ import (
 	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/receiver"
 
+	"github.com/database-intelligence/db-intel/distributions/unified/registry"
+
 	// Core exporters
 	"go.opentelemetry.io/collector/exporter/debugexporter"
 	"go.opentelemetry.io/collector/exporter/otlpexporter"
@@ -38,15 +42,38 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/sqlqueryreceiver"
 
 	// Custom components - conditionally included based on profile
+	"github.com/database-intelligence/db-intel/components/exporters/exporthealth"
 	"github.com/database-intelligence/db-intel/components/exporters/nri"
+	"github.com/database-intelligence/db-intel/components/extensions/healthcheck"
+	recenteventsextension "github.com/database-intelligence/db-intel/components/extensions/recentevents"
 	"github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+	"github.com/database-intelligence/db-intel/components/processors/attributefilter"
+	"github.com/database-intelligence/db-intel/components/processors/checksum"
 	"github.com/database-intelligence/db-intel/components/processors/circuitbreaker"
 	"github.com/database-intelligence/db-intel/components/processors/costcontrol"
+	"github.com/database-intelligence/db-intel/components/processors/dbattributes"
+	"github.com/database-intelligence/db-intel/components/processors/metricremap"
 	"github.com/database-intelligence/db-intel/components/processors/planattributeextractor"
+	"github.com/database-intelligence/db-intel/components/processors/processortracing"
 	"github.com/database-intelligence/db-intel/components/processors/querycorrelator"
+	"github.com/database-intelligence/db-intel/components/processors/querynormalizer"
+	recenteventsprocessor "github.com/database-intelligence/db-intel/components/processors/recentevents"
+	"github.com/database-intelligence/db-intel/components/processors/tenant"
 	"github.com/database-intelligence/db-intel/components/receivers/ash"
+	"github.com/database-intelligence/db-intel/components/receivers/cyclemetrics"
 	"github.com/database-intelligence/db-intel/components/receivers/enhancedsql"
 	"github.com/database-intelligence/db-intel/components/receivers/kernelmetrics"
+	"github.com/database-intelligence/db-intel/components/receivers/mysqlblockingsessions"
+	"github.com/database-intelligence/db-intel/components/receivers/mysqlslowqueries"
+	"github.com/database-intelligence/db-intel/components/receivers/mysqlwaitevents"
+	"github.com/database-intelligence/db-intel/components/receivers/pgbloat"
+	"github.com/database-intelligence/db-intel/components/receivers/pgblockingsessions"
+	"github.com/database-intelligence/db-intel/components/receivers/pgcanary"
+	"github.com/database-intelligence/db-intel/components/receivers/pgintervals"
+	"github.com/database-intelligence/db-intel/components/receivers/pgschemadrift"
+	"github.com/database-intelligence/db-intel/components/receivers/pgserverlog"
+	"github.com/database-intelligence/db-intel/components/receivers/pgslowqueries"
+	"github.com/database-intelligence/db-intel/components/receivers/pgwaitevents"
 )
 
 // MinimalComponents returns factories for minimal distribution
@@ -54,8 +81,11 @@ func MinimalComponents() (otelcol.Factories, error) {
 	var err error
 	factories := otelcol.Factories{}
 
+	// healthcheck serves the verification endpoints and, when enabled, pprof
+	// profiles on the same server
 	factories.Extensions, err = extension.MakeFactoryMap(
 		healthcheckextension.NewFactory(),
+		healthcheck.NewFactory(),
 		zpagesextension.NewFactory(),
 	)
 	if err != nil {
@@ -64,18 +94,20 @@ func MinimalComponents() (otelcol.Factories, error) {
 
 	factories.Receivers, err = receiver.MakeFactoryMap(
 		otlpreceiver.NewFactory(),
-		postgresqlreceiver.NewFactory(),
-		mysqlreceiver.NewFactory(),
-		sqlqueryreceiver.NewFactory(),
+		cyclemetrics.Wrap(postgresqlreceiver.NewFactory()),
+		cyclemetrics.Wrap(mysqlreceiver.NewFactory()),
+		cyclemetrics.Wrap(sqlqueryreceiver.NewFactory()),
 	)
 	if err != nil {
 		return factories, err
 	}
 
+	// The OTLP exporters report each export to healthcheck, so export
+	// dependencies can tell whether data is leaving the collector
 	factories.Exporters, err = exporter.MakeFactoryMap(
 		debugexporter.NewFactory(),
-		otlpexporter.NewFactory(),
-		otlphttpexporter.NewFactory(),
+		exporthealth.Wrap(otlpexporter.NewFactory()),
+		exporthealth.Wrap(otlphttpexporter.NewFactory()),
 		fileexporter.NewFactory(),
 	)
 	if err != nil {
@@ -111,22 +143,41 @@ func StandardComponents() (otelcol.Factories, error) {
 	// Add standard profile components
 	standardExtensions := []extension.Factory{
 		pprofextension.NewFactory(),
+		recenteventsextension.NewFactory(),
 	}
 
 	standardReceivers := []receiver.Factory{
 		prometheusreceiver.NewFactory(),
-		ash.NewFactory(),
-		enhancedsql.NewFactory(),
+		cyclemetrics.Wrap(ash.NewFactory()),
+		cyclemetrics.Wrap(enhancedsql.NewFactory()),
 		kernelmetrics.NewFactory(),
+		cyclemetrics.Wrap(mysqlblockingsessions.NewFactory()),
+		cyclemetrics.Wrap(mysqlslowqueries.NewFactory()),
+		cyclemetrics.Wrap(mysqlwaitevents.NewFactory()),
+		cyclemetrics.Wrap(pgbloat.NewFactory()),
+		cyclemetrics.Wrap(pgblockingsessions.NewFactory()),
+		pgcanary.NewFactory(),
+		cyclemetrics.Wrap(pgintervals.NewFactory(postgresqlreceiver.NewFactory())),
+		pgschemadrift.NewFactory(),
+		pgserverlog.NewFactory(),
+		cyclemetrics.Wrap(pgslowqueries.NewFactory()),
+		cyclemetrics.Wrap(pgwaitevents.NewFactory()),
 	}
 
 	standardProcessors := []processor.Factory{
 		transformprocessor.NewFactory(),
-		adaptivesampler.NewFactory(),
-		circuitbreaker.NewFactory(),
-		planattributeextractor.NewFactory(),
-		querycorrelator.NewFactory(),
-		costcontrol.NewFactory(),
+		processortracing.Wrap(adaptivesampler.NewFactory()),
+		processortracing.Wrap(circuitbreaker.NewFactory()),
+		processortracing.Wrap(planattributeextractor.NewFactory()),
+		processortracing.Wrap(querycorrelator.NewFactory()),
+		processortracing.Wrap(querynormalizer.NewFactory()),
+		processortracing.Wrap(costcontrol.NewFactory()),
+		processortracing.Wrap(attributefilter.NewFactory()),
+		processortracing.Wrap(metricremap.NewFactory()),
+		processortracing.Wrap(dbattributes.NewFactory()),
+		processortracing.Wrap(recenteventsprocessor.NewFactory()),
+		processortracing.Wrap(checksum.NewFactory()),
+		processortracing.Wrap(tenant.NewFactory()),
 	}
 
 	standardExporters := []exporter.Factory{
@@ -155,4 +206,33 @@ func StandardComponents() (otelcol.Factories, error) {
 func EnterpriseComponents() (otelcol.Factories, error) {
 	// Enterprise includes everything from standard
 	return StandardComponents()
-}
\ No newline at end of file
+}
+
+// newRegistry returns a component registry whose catalog contains every
+// factory compiled into this binary, with the built-in profiles as presets
+func newRegistry() (*registry.Registry, error) {
+	profiles := []struct {
+		name  string
+		build func() (otelcol.Factories, error)
+	}{
+		{ProfileMinimal, MinimalComponents},
+		{ProfileStandard, StandardComponents},
+		{ProfileEnterprise, EnterpriseComponents},
+	}
+
+	var builtin []registry.Preset
+	for _, p := range profiles {
+		factories, err := p.build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s profile: %w", p.name, err)
+		}
+		builtin = append(builtin, registry.PresetFromFactories(p.name, factories))
+	}
+
+	// The enterprise profile is a superset of the others
+	catalog, err := EnterpriseComponents()
+	if err != nil {
+		return nil, err
+	}
+	return registry.New(catalog, builtin...), nil
+}
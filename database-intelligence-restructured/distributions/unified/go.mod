@@ -4,6 +4,10 @@ go 1.23.0
 
 require (
 	go.opentelemetry.io/collector/component v0.105.0
+	go.opentelemetry.io/collector/confmap v0.105.0
+	go.opentelemetry.io/collector/confmap/provider/envprovider v0.105.0
+	go.opentelemetry.io/collector/confmap/provider/fileprovider v0.105.0
+	go.opentelemetry.io/collector/confmap/provider/yamlprovider v0.105.0
 	go.opentelemetry.io/collector/connector v0.105.0
 	go.opentelemetry.io/collector/exporter v0.105.0
 	go.opentelemetry.io/collector/exporter/debugexporter v0.105.0
@@ -34,14 +38,21 @@ require (
 	
 	// Custom components
 	github.com/database-intelligence/db-intel/components/exporters v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/components/extensions v0.0.0-00010101000000-000000000000
 	github.com/database-intelligence/db-intel/components/processors v0.0.0-00010101000000-000000000000
 	github.com/database-intelligence/db-intel/components/receivers v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/internal v0.0.0-00010101000000-000000000000
+	
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 replace (
 	github.com/database-intelligence/db-intel/components/exporters => ../../components/exporters
+	github.com/database-intelligence/db-intel/components/extensions => ../../components/extensions
 	github.com/database-intelligence/db-intel/components/processors => ../../components/processors
 	github.com/database-intelligence/db-intel/components/receivers => ../../components/receivers
+	github.com/database-intelligence/db-intel/internal => ../../internal
 	github.com/database-intelligence/db-intel/internal/featuredetector => ../../internal/featuredetector
 	github.com/database-intelligence/db-intel/internal/queryselector => ../../internal/queryselector
 	github.com/database-intelligence/db-intel/internal/database => ../../internal/database
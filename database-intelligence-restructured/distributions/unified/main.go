@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
 	"go.opentelemetry.io/collector/otelcol"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/distributions/unified/registry"
+	"github.com/database-intelligence/db-intel/distributions/unified/reload"
+	"github.com/database-intelligence/db-intel/internal/secrets"
 )
 
 const (
@@ -17,10 +29,22 @@ const (
 )
 
 var (
-	profile     = flag.String("profile", ProfileStandard, "Distribution profile: minimal, standard, or enterprise")
+	profile     = flag.String("profile", ProfileStandard, "Distribution profile: minimal, standard, enterprise, or a preset from -preset-file")
+	presetFile  = flag.String("preset-file", "", "YAML or JSON file defining custom component presets")
+	orderRules  = flag.String("order-rules", "", "YAML or JSON file adding, replacing or disabling processor order rules")
 	showVersion = flag.Bool("version", false, "Show version information")
+	listComps   = flag.Bool("list-components", false, "List the components in each profile and exit")
+	listFormat  = flag.String("list-format", "table", "Output format for -list-components: table or json")
+	validate    = flag.Bool("validate", false, "Validate the configuration against the profile without starting the collector, then exit")
+	watchConfig = flag.Bool("watch-config", false, "Apply changes to the -config files while running; processors that support it are reconfigured in place, anything else triggers a full reload")
+	watchEvery  = flag.Duration("watch-interval", reload.DefaultInterval, "How often -watch-config checks the configuration files")
+	configURIs  stringList
 )
 
+func init() {
+	flag.Var(&configURIs, "config", "Configuration file or URI (can be repeated); read by -validate and -watch-config")
+}
+
 func main() {
 	flag.Parse()
 
@@ -38,29 +62,60 @@ func main() {
 		Version:     Version,
 	}
 
-	var factories otelcol.Factories
-	var err error
+	reg, err := newRegistry()
+	if err != nil {
+		log.Fatalf("Failed to build component registry: %v", err)
+	}
+
+	if *presetFile != "" {
+		if err := reg.LoadFile(*presetFile); err != nil {
+			log.Fatalf("Failed to load presets: %v", err)
+		}
+	}
 
-	switch *profile {
-	case ProfileMinimal:
-		factories, err = MinimalComponents()
-	case ProfileStandard:
-		factories, err = StandardComponents()
-	case ProfileEnterprise:
-		factories, err = EnterpriseComponents()
-	default:
-		log.Fatalf("Unknown profile: %s. Valid profiles are: minimal, standard, enterprise", *profile)
+	if *orderRules != "" {
+		if err := reg.LoadOrderRules(*orderRules); err != nil {
+			log.Fatalf("Failed to load order rules: %v", err)
+		}
 	}
 
+	if *listComps {
+		if err := listComponents(reg, *listFormat); err != nil {
+			log.Fatalf("Failed to list components: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	if *validate {
+		os.Exit(validateConfig(reg, *profile, configURIs))
+	}
+
+	factories, err := reg.BuildFromPreset(*profile)
 	if err != nil {
 		log.Fatalf("Failed to build components for %s profile: %v", *profile, err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	warnPipelineOrder(ctx, reg, configURIs)
+
+	if *watchConfig {
+		reloader, err := newReloader(&factories, configURIs, *watchEvery)
+		if err != nil {
+			log.Fatalf("Failed to set up config hot reload: %v", err)
+		}
+		go reloader.Run(ctx)
+	}
+
 	params := otelcol.CollectorSettings{
 		BuildInfo: info,
 		Factories: func() (otelcol.Factories, error) {
 			return factories, nil
 		},
+		ConfigProviderSettings: otelcol.ConfigProviderSettings{
+			ResolverSettings: resolverSettings(configURIs),
+		},
 	}
 
 	if err := runInteractive(params); err != nil {
@@ -73,6 +128,111 @@ var (
 	BuildDate = "unknown"
 )
 
+// listComponents prints the components registered for every profile
+func listComponents(reg *registry.Registry, format string) error {
+	lists, err := reg.ListComponents()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "table":
+		return registry.WriteTable(os.Stdout, lists)
+	case "json":
+		return registry.WriteJSON(os.Stdout, lists)
+	default:
+		return fmt.Errorf("unknown list format %q, expected table or json", format)
+	}
+}
+
+// validateConfig checks the configuration against the profile, prints the
+// report and returns the exit code: 0 when valid, 1 otherwise
+func validateConfig(reg *registry.Registry, profile string, uris []string) int {
+	if len(uris) == 0 {
+		fmt.Fprintln(os.Stderr, "-validate needs at least one -config")
+		return 2
+	}
+
+	report, err := reg.ValidateConfig(context.Background(), profile, resolverSettings(uris))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to validate: %v\n", err)
+		return 1
+	}
+	if err := report.Write(os.Stdout); err != nil {
+		log.Printf("Failed to write validation report: %v", err)
+	}
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// warnPipelineOrder logs every pipeline that breaks a processor order rule.
+// The collector still starts; it reports configuration errors itself.
+func warnPipelineOrder(ctx context.Context, reg *registry.Registry, uris []string) {
+	if len(uris) == 0 {
+		return
+	}
+	warnings, err := reg.CheckPipelineOrder(ctx, resolverSettings(uris))
+	if err != nil {
+		log.Printf("Skipping the processor order check: %v", err)
+		return
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning: %s", warning)
+	}
+}
+
+// resolverSettings returns the config sources for the given URIs
+func resolverSettings(uris []string) confmap.ResolverSettings {
+	return confmap.ResolverSettings{
+		URIs: uris,
+		ProviderFactories: []confmap.ProviderFactory{
+			fileprovider.NewFactory(),
+			envprovider.NewFactory(),
+			yamlprovider.NewFactory(),
+			secrets.NewFactory(),
+		},
+		DefaultScheme: "env",
+	}
+}
+
+// newReloader wraps the processor factories so the running processors can be
+// reconfigured, and returns the reloader watching the configuration files
+func newReloader(factories *otelcol.Factories, uris []string, interval time.Duration) (*reload.Reloader, error) {
+	if len(uris) == 0 {
+		return nil, fmt.Errorf("-watch-config needs at least one -config")
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := reload.NewTracker()
+	factories.Processors = tracker.WrapFactories(factories.Processors)
+
+	return reload.New(reload.Settings{
+		ResolverSettings: resolverSettings(uris),
+		Factories:        *factories,
+		Tracker:          tracker,
+		Interval:         interval,
+		Logger:           logger,
+	})
+}
+
+// stringList implements flag.Value for repeatable string flags
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func runInteractive(params otelcol.CollectorSettings) error {
 	cmd := otelcol.NewCommand(params)
 	if err := cmd.Execute(); err != nil {
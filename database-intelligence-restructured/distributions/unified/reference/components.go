@@ -0,0 +1,89 @@
+package main
+
+import (
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/debugexporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+	"github.com/database-intelligence/db-intel/components/exporters/exporthealth"
+	"github.com/database-intelligence/db-intel/components/extensions/healthcheck"
+	"github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+	"github.com/database-intelligence/db-intel/components/processors/circuitbreaker"
+	"github.com/database-intelligence/db-intel/components/processors/costcontrol"
+	"github.com/database-intelligence/db-intel/components/processors/planattributeextractor"
+	"github.com/database-intelligence/db-intel/components/processors/querycorrelator"
+	"github.com/database-intelligence/db-intel/components/processors/verification"
+	"github.com/database-intelligence/db-intel/distributions/unified/registry"
+)
+
+// PresetReference is the only preset of the reference distribution
+const PresetReference = "reference"
+
+// Components returns the canonical component set: OTLP in and out, the
+// standard memory limiter and batcher, and the custom processors. A
+// downstream build adds its own factories here.
+func Components() (otelcol.Factories, error) {
+	var err error
+	factories := otelcol.Factories{}
+
+	factories.Extensions, err = extension.MakeFactoryMap(
+		healthcheck.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Receivers, err = receiver.MakeFactoryMap(
+		otlpreceiver.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Processors, err = processor.MakeFactoryMap(
+		memorylimiterprocessor.NewFactory(),
+		batchprocessor.NewFactory(),
+		adaptivesampler.NewFactory(),
+		circuitbreaker.NewFactory(),
+		costcontrol.NewFactory(),
+		planattributeextractor.NewFactory(),
+		querycorrelator.NewFactory(),
+		verification.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Exporters, err = exporter.MakeFactoryMap(
+		exporthealth.Wrap(otlpexporter.NewFactory()),
+		debugexporter.NewFactory(),
+	)
+	if err != nil {
+		return factories, err
+	}
+
+	factories.Connectors, err = connector.MakeFactoryMap()
+	if err != nil {
+		return factories, err
+	}
+
+	return factories, nil
+}
+
+// newRegistry returns a registry whose catalog and single preset are the
+// canonical component set
+func newRegistry() (*registry.Registry, error) {
+	catalog, err := Components()
+	if err != nil {
+		return nil, err
+	}
+	return registry.New(catalog, registry.PresetFromFactories(PresetReference, catalog)), nil
+}
@@ -0,0 +1,63 @@
+// Command reference is the reference distribution of the Database
+// Intelligence Collector: the smallest runnable collector built from the
+// component registry, for downstream builders to copy. It registers one
+// preset holding the canonical component set and runs it with the standard
+// collector command line, so --config and the validate subcommand work as
+// in any collector:
+//
+//	reference --config=config.yaml
+//	reference validate --config=config.yaml
+package main
+
+import (
+	"log"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.opentelemetry.io/collector/otelcol"
+
+	"github.com/database-intelligence/db-intel/internal/secrets"
+)
+
+var Version = "dev"
+
+func main() {
+	reg, err := newRegistry()
+	if err != nil {
+		log.Fatalf("Failed to build component registry: %v", err)
+	}
+	factories, err := reg.BuildFromPreset(PresetReference)
+	if err != nil {
+		log.Fatalf("Failed to build components: %v", err)
+	}
+
+	params := otelcol.CollectorSettings{
+		BuildInfo: component.BuildInfo{
+			Command:     "database-intelligence-reference",
+			Description: "Database Intelligence Collector - reference distribution",
+			Version:     Version,
+		},
+		Factories: func() (otelcol.Factories, error) {
+			return factories, nil
+		},
+		// The command fills in the URIs from --config
+		ConfigProviderSettings: otelcol.ConfigProviderSettings{
+			ResolverSettings: confmap.ResolverSettings{
+				ProviderFactories: []confmap.ProviderFactory{
+					fileprovider.NewFactory(),
+					envprovider.NewFactory(),
+					yamlprovider.NewFactory(),
+					secrets.NewFactory(),
+				},
+				DefaultScheme: "env",
+			},
+		},
+	}
+
+	if err := otelcol.NewCommand(params).Execute(); err != nil {
+		log.Fatalf("collector server run finished with error: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+)
+
+func TestComponentsConstruct(t *testing.T) {
+	factories, err := Components()
+	require.NoError(t, err)
+
+	for _, typ := range []string{"memory_limiter", "batch", "adaptivesampler", "circuit_breaker",
+		"costcontrol", "planattributeextractor", "querycorrelator", "verification"} {
+		require.Contains(t, factories.Processors, component.MustNewType(typ))
+		assert.NotNil(t, factories.Processors[component.MustNewType(typ)].CreateDefaultConfig(), "processor %s", typ)
+	}
+	assert.Contains(t, factories.Receivers, component.MustNewType("otlp"))
+	assert.Contains(t, factories.Exporters, component.MustNewType("otlp"))
+	assert.Contains(t, factories.Exporters, component.MustNewType("debug"))
+	assert.Contains(t, factories.Extensions, component.MustNewType("healthcheck"))
+}
+
+func TestReferenceConfigValidates(t *testing.T) {
+	reg, err := newRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, []string{PresetReference}, reg.Presets())
+
+	report, err := reg.ValidateConfig(context.Background(), PresetReference, confmap.ResolverSettings{
+		URIs:              []string{"file:config.yaml"},
+		ProviderFactories: []confmap.ProviderFactory{fileprovider.NewFactory(), envprovider.NewFactory()},
+	})
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "problems: %v", report.Problems)
+	assert.Empty(t, report.Warnings)
+}
@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+// Component kinds reported by Describe, in listing order
+const (
+	KindExtension = "extension"
+	KindReceiver  = "receiver"
+	KindProcessor = "processor"
+	KindExporter  = "exporter"
+	KindConnector = "connector"
+)
+
+// ComponentInfo describes a registered component factory
+type ComponentInfo struct {
+	Type string `json:"type"`
+	Kind string `json:"kind"`
+	// Stability maps each supported signal to its stability level.
+	// Extensions report a single "extension" entry; connectors are keyed
+	// by "<from>_to_<to>".
+	Stability map[string]string `json:"stability"`
+}
+
+// PresetComponents lists the components included in a preset
+type PresetComponents struct {
+	Preset     string          `json:"preset"`
+	Components []ComponentInfo `json:"components"`
+}
+
+// Describe lists the factories in a component set, ordered by kind then type
+func Describe(factories otelcol.Factories) []ComponentInfo {
+	var infos []ComponentInfo
+
+	for _, typ := range sortedKeys(factories.Extensions) {
+		f := factories.Extensions[typ]
+		infos = append(infos, newComponentInfo(typ, KindExtension, map[string]component.StabilityLevel{
+			"extension": f.ExtensionStability(),
+		}))
+	}
+	for _, typ := range sortedKeys(factories.Receivers) {
+		infos = append(infos, newComponentInfo(typ, KindReceiver, receiverStability(factories.Receivers[typ])))
+	}
+	for _, typ := range sortedKeys(factories.Processors) {
+		infos = append(infos, newComponentInfo(typ, KindProcessor, processorStability(factories.Processors[typ])))
+	}
+	for _, typ := range sortedKeys(factories.Exporters) {
+		infos = append(infos, newComponentInfo(typ, KindExporter, exporterStability(factories.Exporters[typ])))
+	}
+	for _, typ := range sortedKeys(factories.Connectors) {
+		infos = append(infos, newComponentInfo(typ, KindConnector, connectorStability(factories.Connectors[typ])))
+	}
+
+	return infos
+}
+
+// ListComponents describes the components of every known preset
+func (r *Registry) ListComponents() ([]PresetComponents, error) {
+	var lists []PresetComponents
+	for _, name := range r.Presets() {
+		factories, err := r.BuildFromPreset(name)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, PresetComponents{
+			Preset:     name,
+			Components: Describe(factories),
+		})
+	}
+	return lists, nil
+}
+
+// WriteJSON writes component listings as indented JSON
+func WriteJSON(w io.Writer, lists []PresetComponents) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(lists)
+}
+
+// WriteTable writes component listings as an aligned text table
+func WriteTable(w io.Writer, lists []PresetComponents) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRESET\tKIND\tTYPE\tSTABILITY")
+	for _, list := range lists {
+		for _, c := range list.Components {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", list.Preset, c.Kind, c.Type, formatStability(c.Stability))
+		}
+	}
+	return tw.Flush()
+}
+
+func newComponentInfo(typ component.Type, kind string, levels map[string]component.StabilityLevel) ComponentInfo {
+	stability := make(map[string]string, len(levels))
+	for signal, level := range levels {
+		if level != component.StabilityLevelUndefined {
+			stability[signal] = level.String()
+		}
+	}
+	return ComponentInfo{
+		Type:      typ.String(),
+		Kind:      kind,
+		Stability: stability,
+	}
+}
+
+func receiverStability(f receiver.Factory) map[string]component.StabilityLevel {
+	return map[string]component.StabilityLevel{
+		"traces":  f.TracesReceiverStability(),
+		"metrics": f.MetricsReceiverStability(),
+		"logs":    f.LogsReceiverStability(),
+	}
+}
+
+func processorStability(f processor.Factory) map[string]component.StabilityLevel {
+	return map[string]component.StabilityLevel{
+		"traces":  f.TracesProcessorStability(),
+		"metrics": f.MetricsProcessorStability(),
+		"logs":    f.LogsProcessorStability(),
+	}
+}
+
+func exporterStability(f exporter.Factory) map[string]component.StabilityLevel {
+	return map[string]component.StabilityLevel{
+		"traces":  f.TracesExporterStability(),
+		"metrics": f.MetricsExporterStability(),
+		"logs":    f.LogsExporterStability(),
+	}
+}
+
+func connectorStability(f connector.Factory) map[string]component.StabilityLevel {
+	return map[string]component.StabilityLevel{
+		"traces_to_traces":   f.TracesToTracesStability(),
+		"traces_to_metrics":  f.TracesToMetricsStability(),
+		"traces_to_logs":     f.TracesToLogsStability(),
+		"metrics_to_traces":  f.MetricsToTracesStability(),
+		"metrics_to_metrics": f.MetricsToMetricsStability(),
+		"metrics_to_logs":    f.MetricsToLogsStability(),
+		"logs_to_traces":     f.LogsToTracesStability(),
+		"logs_to_metrics":    f.LogsToMetricsStability(),
+		"logs_to_logs":       f.LogsToLogsStability(),
+	}
+}
+
+// formatStability renders a stability map as "signal=level" pairs sorted by signal
+func formatStability(stability map[string]string) string {
+	signals := make([]string, 0, len(stability))
+	for signal := range stability {
+		signals = append(signals, signal)
+	}
+	sort.Strings(signals)
+
+	parts := make([]string, 0, len(signals))
+	for _, signal := range signals {
+		parts = append(parts, signal+"="+stability[signal])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedKeys[F any](factories map[component.Type]F) []component.Type {
+	types := make([]component.Type, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+	return types
+}
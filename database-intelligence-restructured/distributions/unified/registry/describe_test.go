@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	infos := Describe(testCatalog(t))
+
+	var kinds, types []string
+	for _, info := range infos {
+		kinds = append(kinds, info.Kind)
+		types = append(types, info.Type)
+	}
+	assert.Equal(t, []string{KindExtension, KindReceiver, KindProcessor, KindProcessor, KindExporter}, kinds)
+	assert.Equal(t, []string{"zpages", "otlp", "batch", "memory_limiter", "debug"}, types)
+
+	batch := infos[2]
+	assert.Equal(t, "Beta", batch.Stability["traces"])
+	assert.Equal(t, "Beta", batch.Stability["logs"])
+}
+
+func TestListComponents(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal(), PresetFromFactories("full", testCatalog(t)))
+
+	lists, err := r.ListComponents()
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+	assert.Equal(t, "full", lists[0].Preset)
+	assert.Len(t, lists[0].Components, 5)
+	assert.Equal(t, "minimal", lists[1].Preset)
+	assert.Len(t, lists[1].Components, 3)
+
+	var table bytes.Buffer
+	require.NoError(t, WriteTable(&table, lists))
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	assert.Len(t, lines, 9)
+	assert.Regexp(t, `^PRESET\s+KIND\s+TYPE\s+STABILITY$`, lines[0])
+	assert.Regexp(t, `^minimal\s+processor\s+batch\s+logs=Beta, metrics=Beta, traces=Beta$`, lines[7])
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJSON(&out, lists))
+	var decoded []PresetComponents
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, lists, decoded)
+}
@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderRule flags pipelines that run processors in an unsafe order. When a
+// pipeline has a processor of a Before type and one of an After type, the
+// Before processor must come first.
+type OrderRule struct {
+	Name   string   `yaml:"name" json:"name"`
+	Before []string `yaml:"before" json:"before"`
+	After  []string `yaml:"after" json:"after"`
+	// Reason explains what goes wrong when the order is reversed
+	Reason string `yaml:"reason" json:"reason"`
+}
+
+// OrderRuleFile is the on-disk format for processor order rules
+type OrderRuleFile struct {
+	// Disable names default rules that should not be checked
+	Disable []string `yaml:"disable" json:"disable"`
+	// Rules are checked in addition to the defaults; a rule replaces the
+	// default of the same name
+	Rules []OrderRule `yaml:"rules" json:"rules"`
+}
+
+// DefaultOrderRules returns the processor orderings known to be unsafe
+func DefaultOrderRules() []OrderRule {
+	return []OrderRule{
+		{
+			Name:   "pii-redaction-before-batch",
+			Before: []string{"verification"},
+			After:  []string{"batch"},
+			Reason: "PII must be redacted before records are batched for export",
+		},
+		{
+			Name:   "sampler-before-costcontrol",
+			Before: []string{"adaptivesampler"},
+			After:  []string{"costcontrol"},
+			Reason: "cost control would budget and cap data the sampler drops afterwards",
+		},
+		{
+			Name:   "pii-redaction-before-recent-events",
+			Before: []string{"verification"},
+			After:  []string{"recentevents"},
+			Reason: "the recent events buffer must only keep records with PII redacted",
+		},
+		{
+			Name:   "transforms-before-checksum",
+			Before: []string{"attributes", "resource", "transform", "attributefilter", "dbattributes", "metricremap", "querynormalizer", "tenant", "verification"},
+			After:  []string{"checksum"},
+			Reason: "records changed after the checksum is stamped no longer match it downstream",
+		},
+	}
+}
+
+// OrderRules returns the rules the registry checks pipelines against
+func (r *Registry) OrderRules() []OrderRule {
+	return slices.Clone(r.orderRules)
+}
+
+// LoadOrderRules reads processor order rules from a YAML or JSON file, chosen
+// by extension like LoadFile. Rules in the file are added to the current ones,
+// replacing rules of the same name, and disabled rules are removed. Every
+// rule must only name processor types in the catalog.
+func (r *Registry) LoadOrderRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read order rule file: %w", err)
+	}
+
+	var file OrderRuleFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse order rule file %s: %w", path, err)
+	}
+
+	rules := slices.Clone(r.orderRules)
+	for _, name := range file.Disable {
+		i := slices.IndexFunc(rules, func(rule OrderRule) bool { return rule.Name == name })
+		if i < 0 {
+			return fmt.Errorf("order rule file %s disables unknown rule %q", path, name)
+		}
+		rules = slices.Delete(rules, i, i+1)
+	}
+
+	seen := make(map[string]bool, len(file.Rules))
+	for _, rule := range file.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("order rule file %s contains a rule without a name", path)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("order rule file %s defines rule %q more than once", path, rule.Name)
+		}
+		seen[rule.Name] = true
+
+		if len(rule.Before) == 0 || len(rule.After) == 0 {
+			return fmt.Errorf("order rule %q needs both before and after processors", rule.Name)
+		}
+		if unknown := unknownTypes(r.catalog.Processors, append(slices.Clone(rule.Before), rule.After...)); len(unknown) > 0 {
+			return fmt.Errorf("order rule %q references unknown processors: %s", rule.Name, strings.Join(unknown, ", "))
+		}
+
+		if i := slices.IndexFunc(rules, func(r OrderRule) bool { return r.Name == rule.Name }); i >= 0 {
+			rules[i] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+
+	r.orderRules = rules
+	return nil
+}
+
+// CheckPipelineOrder resolves the configuration described by settings and
+// returns a warning for every pipeline that breaks an order rule
+func (r *Registry) CheckPipelineOrder(ctx context.Context, settings confmap.ResolverSettings) ([]string, error) {
+	resolver, err := confmap.NewResolver(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config sources: %w", err)
+	}
+	conf, err := resolver.Resolve(ctx)
+	_ = resolver.Shutdown(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve the configuration: %w", err)
+	}
+	return orderWarnings(conf, r.orderRules), nil
+}
+
+// orderWarnings checks the processors of every configured pipeline against
+// rules. Pipelines are checked in name order.
+func orderWarnings(conf *confmap.Conf, rules []OrderRule) []string {
+	pipelines, ok := conf.Get("service::pipelines").(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		pipeline, _ := pipelines[name].(map[string]any)
+		processors := pipelineProcessors(pipeline["processors"])
+		for _, rule := range rules {
+			warnings = append(warnings, ruleWarnings(name, processors, rule)...)
+		}
+	}
+	return warnings
+}
+
+// ruleWarnings returns a warning for every processor of a Before type that
+// runs after one of an After type
+func ruleWarnings(pipeline string, processors []component.ID, rule OrderRule) []string {
+	var warnings []string
+	for i, later := range processors {
+		if !slices.Contains(rule.Before, later.Type().String()) {
+			continue
+		}
+		for _, earlier := range processors[:i] {
+			if slices.Contains(rule.After, earlier.Type().String()) {
+				warnings = append(warnings, fmt.Sprintf("pipeline %q: processor %q runs after %q: %s (rule %s)",
+					pipeline, later, earlier, rule.Reason, rule.Name))
+			}
+		}
+	}
+	return warnings
+}
+
+// pipelineProcessors parses the processor list of a pipeline. Malformed IDs
+// are skipped; validation reports them.
+func pipelineProcessors(value any) []component.ID {
+	list, _ := value.([]any)
+	ids := make([]component.ID, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			continue
+		}
+		var id component.ID
+		if err := id.UnmarshalText([]byte(s)); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
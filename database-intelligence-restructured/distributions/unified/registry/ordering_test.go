@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func pipelinesConf(pipelines map[string][]any) *confmap.Conf {
+	configured := make(map[string]any, len(pipelines))
+	for name, processors := range pipelines {
+		configured[name] = map[string]any{"processors": processors}
+	}
+	return confmap.NewFromStringMap(map[string]any{
+		"service": map[string]any{"pipelines": configured},
+	})
+}
+
+func TestOrderWarnings(t *testing.T) {
+	tests := []struct {
+		name      string
+		pipelines map[string][]any
+		warnings  []string
+	}{
+		{
+			name: "safe order",
+			pipelines: map[string][]any{
+				"metrics": {"memory_limiter", "adaptivesampler", "verification", "costcontrol", "batch"},
+			},
+		},
+		{
+			name: "pii redaction after batching",
+			pipelines: map[string][]any{
+				"logs": {"memory_limiter", "batch", "verification/pii"},
+			},
+			warnings: []string{
+				`pipeline "logs": processor "verification/pii" runs after "batch": PII must be redacted before records are batched for export (rule pii-redaction-before-batch)`,
+			},
+		},
+		{
+			name: "sampler after cost control",
+			pipelines: map[string][]any{
+				"metrics": {"costcontrol", "adaptivesampler", "batch"},
+			},
+			warnings: []string{
+				`pipeline "metrics": processor "adaptivesampler" runs after "costcontrol": cost control would budget and cap data the sampler drops afterwards (rule sampler-before-costcontrol)`,
+			},
+		},
+		{
+			name: "recent events kept before pii redaction",
+			pipelines: map[string][]any{
+				"logs": {"memory_limiter", "recentevents", "verification", "recentevents/redacted", "batch"},
+			},
+			warnings: []string{
+				`pipeline "logs": processor "verification" runs after "recentevents": the recent events buffer must only keep records with PII redacted (rule pii-redaction-before-recent-events)`,
+			},
+		},
+		{
+			name: "records changed after the checksum",
+			pipelines: map[string][]any{
+				"metrics": {"memory_limiter", "dbattributes", "checksum", "metricremap", "batch"},
+			},
+			warnings: []string{
+				`pipeline "metrics": processor "metricremap" runs after "checksum": records changed after the checksum is stamped no longer match it downstream (rule transforms-before-checksum)`,
+			},
+		},
+		{
+			name: "both, across pipelines in name order",
+			pipelines: map[string][]any{
+				"metrics":       {"costcontrol", "adaptivesampler/slow", "batch"},
+				"logs/database": {"batch/small", "costcontrol", "verification", "batch/large"},
+			},
+			warnings: []string{
+				`pipeline "logs/database": processor "verification" runs after "batch/small": PII must be redacted before records are batched for export (rule pii-redaction-before-batch)`,
+				`pipeline "metrics": processor "adaptivesampler/slow" runs after "costcontrol": cost control would budget and cap data the sampler drops afterwards (rule sampler-before-costcontrol)`,
+			},
+		},
+		{
+			name: "processors only one rule side",
+			pipelines: map[string][]any{
+				"metrics": {"batch", "costcontrol"},
+				"traces":  {"adaptivesampler"},
+			},
+		},
+		{
+			name: "malformed ids are left to validation",
+			pipelines: map[string][]any{
+				"metrics": {"batch", "", 42, "verification"},
+			},
+			warnings: []string{
+				`pipeline "metrics": processor "verification" runs after "batch": PII must be redacted before records are batched for export (rule pii-redaction-before-batch)`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.warnings, orderWarnings(pipelinesConf(tt.pipelines), DefaultOrderRules()))
+		})
+	}
+
+	assert.Empty(t, orderWarnings(confmap.New(), DefaultOrderRules()))
+}
+
+func TestLoadOrderRules(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+	require.NoError(t, r.LoadOrderRules("testdata/order-rules.yaml"))
+
+	rules := r.OrderRules()
+	require.Len(t, rules, 4)
+	assert.Equal(t, "pii-redaction-before-batch", rules[0].Name)
+	assert.Equal(t, "replaced", rules[0].Reason)
+	assert.Equal(t, "pii-redaction-before-recent-events", rules[1].Name)
+	assert.Equal(t, "transforms-before-checksum", rules[2].Name)
+	assert.Equal(t, "memory-limiter-first", rules[3].Name)
+
+	warnings := orderWarnings(pipelinesConf(map[string][]any{
+		"metrics": {"costcontrol", "adaptivesampler", "batch", "memory_limiter"},
+	}), rules)
+	assert.Equal(t, []string{
+		`pipeline "metrics": processor "memory_limiter" runs after "batch": replaced (rule pii-redaction-before-batch)`,
+		`pipeline "metrics": processor "memory_limiter" runs after "batch": the memory limiter must refuse data before it is buffered (rule memory-limiter-first)`,
+	}, warnings)
+}
+
+func TestLoadOrderRulesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"unknown disabled rule", "disable: [nope]\n", `disables unknown rule "nope"`},
+		{"unnamed rule", "rules:\n  - before: [batch]\n    after: [memory_limiter]\n", "contains a rule without a name"},
+		{"one sided rule", "rules:\n  - name: r\n    before: [batch]\n", `order rule "r" needs both before and after processors`},
+		{"unknown processor", "rules:\n  - name: r\n    before: [batch]\n    after: [costcontrol]\n", `order rule "r" references unknown processors: costcontrol`},
+		{"duplicate rule", "rules:\n  - {name: r, before: [batch], after: [memory_limiter]}\n  - {name: r, before: [batch], after: [memory_limiter]}\n", `defines rule "r" more than once`},
+		{"malformed", "rules: {", "failed to parse order rule file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			r := New(testCatalog(t), builtinMinimal())
+			assert.ErrorContains(t, r.LoadOrderRules(path), tt.err)
+			assert.Equal(t, DefaultOrderRules(), r.OrderRules())
+		})
+	}
+
+	r := New(testCatalog(t), builtinMinimal())
+	assert.ErrorContains(t, r.LoadOrderRules("testdata/nope.yaml"), "failed to read order rule file")
+}
+
+func TestValidateConfigOrderWarnings(t *testing.T) {
+	r := New(testCatalog(t), Preset{
+		Name:       "standard",
+		Receivers:  []string{"otlp"},
+		Processors: []string{"batch", "memory_limiter"},
+		Exporters:  []string{"debug"},
+	})
+	require.NoError(t, r.LoadOrderRules("testdata/order-rules.yaml"))
+
+	report, err := r.ValidateConfig(context.Background(), "standard", fileSettings("file:testdata/config-misordered.yaml"))
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "problems: %v", report.Problems)
+	require.Len(t, report.Warnings, 2)
+	assert.Contains(t, report.Warnings[1], "(rule memory-limiter-first)")
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	assert.Contains(t, out.String(), "Configuration is valid for the standard profile\nWarnings:\n  - pipeline \"metrics\"")
+
+	warnings, err := r.CheckPipelineOrder(context.Background(), fileSettings("file:testdata/config-misordered.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, report.Warnings, warnings)
+
+	_, err = r.CheckPipelineOrder(context.Background(), fileSettings("file:testdata/nope.yaml"))
+	assert.ErrorContains(t, err, "cannot resolve the configuration")
+}
@@ -0,0 +1,209 @@
+// Package registry composes collector component sets from named presets.
+//
+// A Registry holds a catalog of every component factory compiled into the
+// binary together with a set of presets. Each preset lists the component
+// types to include, so operators can define custom distributions in a
+// YAML or JSON file without recompiling.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/otelcol"
+	"gopkg.in/yaml.v3"
+)
+
+// Preset lists the component types included in a distribution profile
+type Preset struct {
+	Name       string   `yaml:"name" json:"name"`
+	Extensions []string `yaml:"extensions" json:"extensions"`
+	Receivers  []string `yaml:"receivers" json:"receivers"`
+	Processors []string `yaml:"processors" json:"processors"`
+	Exporters  []string `yaml:"exporters" json:"exporters"`
+	Connectors []string `yaml:"connectors" json:"connectors"`
+}
+
+// PresetFile is the on-disk format for custom presets
+type PresetFile struct {
+	Presets []Preset `yaml:"presets" json:"presets"`
+}
+
+// Registry builds component factories from presets
+type Registry struct {
+	catalog    otelcol.Factories
+	presets    map[string]Preset
+	orderRules []OrderRule
+}
+
+// New creates a registry over the given catalog of available factories.
+// The builtin presets are available until overridden by a preset file, and
+// pipelines are checked against DefaultOrderRules.
+func New(catalog otelcol.Factories, builtin ...Preset) *Registry {
+	r := &Registry{
+		catalog:    catalog,
+		presets:    make(map[string]Preset, len(builtin)),
+		orderRules: DefaultOrderRules(),
+	}
+	for _, p := range builtin {
+		r.presets[p.Name] = p
+	}
+	return r
+}
+
+// PresetFromFactories describes an existing factory set as a preset
+func PresetFromFactories(name string, factories otelcol.Factories) Preset {
+	return Preset{
+		Name:       name,
+		Extensions: sortedTypes(factories.Extensions),
+		Receivers:  sortedTypes(factories.Receivers),
+		Processors: sortedTypes(factories.Processors),
+		Exporters:  sortedTypes(factories.Exporters),
+		Connectors: sortedTypes(factories.Connectors),
+	}
+}
+
+// LoadFile reads presets from a YAML or JSON file. The format is chosen by
+// file extension; .json is parsed as JSON and anything else as YAML. Presets
+// in the file replace builtin presets of the same name. Every preset is
+// validated against the catalog before any is added.
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read preset file: %w", err)
+	}
+
+	var file PresetFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse preset file %s: %w", path, err)
+	}
+
+	if len(file.Presets) == 0 {
+		return fmt.Errorf("preset file %s defines no presets", path)
+	}
+
+	seen := make(map[string]bool, len(file.Presets))
+	for _, p := range file.Presets {
+		if p.Name == "" {
+			return fmt.Errorf("preset file %s contains a preset without a name", path)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("preset file %s defines preset %q more than once", path, p.Name)
+		}
+		seen[p.Name] = true
+
+		if err := r.validate(p); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range file.Presets {
+		r.presets[p.Name] = p
+	}
+	return nil
+}
+
+// Presets returns the names of all known presets in sorted order
+func (r *Registry) Presets() []string {
+	names := make([]string, 0, len(r.presets))
+	for name := range r.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuildFromPreset returns the factories selected by the named preset
+func (r *Registry) BuildFromPreset(name string) (otelcol.Factories, error) {
+	p, ok := r.presets[name]
+	if !ok {
+		return otelcol.Factories{}, fmt.Errorf("unknown preset %q, available presets: %s",
+			name, strings.Join(r.Presets(), ", "))
+	}
+
+	if err := r.validate(p); err != nil {
+		return otelcol.Factories{}, err
+	}
+
+	return otelcol.Factories{
+		Extensions: selectFactories(r.catalog.Extensions, p.Extensions),
+		Receivers:  selectFactories(r.catalog.Receivers, p.Receivers),
+		Processors: selectFactories(r.catalog.Processors, p.Processors),
+		Exporters:  selectFactories(r.catalog.Exporters, p.Exporters),
+		Connectors: selectFactories(r.catalog.Connectors, p.Connectors),
+	}, nil
+}
+
+// validate checks that every component named by the preset is in the catalog
+func (r *Registry) validate(p Preset) error {
+	var problems []string
+	for _, kind := range []struct {
+		name    string
+		unknown []string
+	}{
+		{"extensions", unknownTypes(r.catalog.Extensions, p.Extensions)},
+		{"receivers", unknownTypes(r.catalog.Receivers, p.Receivers)},
+		{"processors", unknownTypes(r.catalog.Processors, p.Processors)},
+		{"exporters", unknownTypes(r.catalog.Exporters, p.Exporters)},
+		{"connectors", unknownTypes(r.catalog.Connectors, p.Connectors)},
+	} {
+		if len(kind.unknown) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", kind.name, strings.Join(kind.unknown, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("preset %q references unknown components (%s)", p.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func unknownTypes[F any](catalog map[component.Type]F, names []string) []string {
+	var unknown []string
+	for _, name := range names {
+		if !hasType(catalog, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func selectFactories[F any](catalog map[component.Type]F, names []string) map[component.Type]F {
+	selected := make(map[component.Type]F, len(names))
+	for typ, factory := range catalog {
+		for _, name := range names {
+			if typ.String() == name {
+				selected[typ] = factory
+			}
+		}
+	}
+	return selected
+}
+
+func hasType[F any](catalog map[component.Type]F, name string) bool {
+	for typ := range catalog {
+		if typ.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedTypes[F any](factories map[component.Type]F) []string {
+	types := sortedKeys(factories)
+	names := make([]string, len(types))
+	for i, typ := range types {
+		names[i] = typ.String()
+	}
+	return names
+}
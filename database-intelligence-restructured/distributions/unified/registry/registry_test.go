@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/debugexporter"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/zpagesextension"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+)
+
+func testCatalog(t *testing.T) otelcol.Factories {
+	var err error
+	factories := otelcol.Factories{}
+
+	factories.Extensions, err = extension.MakeFactoryMap(zpagesextension.NewFactory())
+	require.NoError(t, err)
+	factories.Receivers, err = receiver.MakeFactoryMap(otlpreceiver.NewFactory())
+	require.NoError(t, err)
+	factories.Processors, err = processor.MakeFactoryMap(batchprocessor.NewFactory(), memorylimiterprocessor.NewFactory())
+	require.NoError(t, err)
+	factories.Exporters, err = exporter.MakeFactoryMap(debugexporter.NewFactory())
+	require.NoError(t, err)
+	factories.Connectors, err = connector.MakeFactoryMap()
+	require.NoError(t, err)
+
+	return factories
+}
+
+func builtinMinimal() Preset {
+	return Preset{
+		Name:       "minimal",
+		Receivers:  []string{"otlp"},
+		Processors: []string{"batch"},
+		Exporters:  []string{"debug"},
+	}
+}
+
+func TestBuildFromBuiltinPreset(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+
+	factories, err := r.BuildFromPreset("minimal")
+	require.NoError(t, err)
+
+	assert.Contains(t, factories.Processors, component.MustNewType("batch"))
+	assert.NotContains(t, factories.Processors, component.MustNewType("memory_limiter"))
+	assert.Len(t, factories.Receivers, 1)
+	assert.Len(t, factories.Exporters, 1)
+	assert.Empty(t, factories.Extensions)
+}
+
+func TestBuildFromUnknownPreset(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+
+	_, err := r.BuildFromPreset("gold")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown preset "gold"`)
+	assert.Contains(t, err.Error(), "minimal")
+}
+
+func TestLoadFile(t *testing.T) {
+	for _, path := range []string{"testdata/presets.yaml", "testdata/presets.json"} {
+		t.Run(path, func(t *testing.T) {
+			r := New(testCatalog(t), builtinMinimal())
+			require.NoError(t, r.LoadFile(path))
+
+			factories, err := r.BuildFromPreset("edge")
+			require.NoError(t, err)
+			assert.Len(t, factories.Receivers, 1)
+			assert.Len(t, factories.Processors, 1)
+			assert.Contains(t, factories.Exporters, component.MustNewType("debug"))
+		})
+	}
+}
+
+func TestLoadFileOverridesBuiltin(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+	require.NoError(t, r.LoadFile("testdata/presets.yaml"))
+
+	factories, err := r.BuildFromPreset("minimal")
+	require.NoError(t, err)
+	assert.Contains(t, factories.Processors, component.MustNewType("memory_limiter"))
+	assert.Contains(t, factories.Extensions, component.MustNewType("zpages"))
+	assert.Equal(t, []string{"edge", "minimal"}, r.Presets())
+}
+
+func TestLoadFileUnknownComponents(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+
+	err := r.LoadFile("testdata/unknown.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `preset "broken"`)
+	assert.Contains(t, err.Error(), "receivers: oracle")
+	assert.Contains(t, err.Error(), "processors: nosuchprocessor")
+
+	// A rejected file leaves the registry unchanged
+	assert.Equal(t, []string{"minimal"}, r.Presets())
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	r := New(testCatalog(t))
+	assert.Error(t, r.LoadFile("testdata/does-not-exist.yaml"))
+}
+
+func TestPresetFromFactories(t *testing.T) {
+	p := PresetFromFactories("all", testCatalog(t))
+
+	assert.Equal(t, "all", p.Name)
+	assert.Equal(t, []string{"batch", "memory_limiter"}, p.Processors)
+	assert.Equal(t, []string{"zpages"}, p.Extensions)
+	assert.Empty(t, p.Connectors)
+}
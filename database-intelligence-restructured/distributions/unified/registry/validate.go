@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/otelcol"
+)
+
+// ValidationReport lists the problems found in a collector configuration
+type ValidationReport struct {
+	Preset   string   `json:"preset"`
+	Problems []string `json:"problems"`
+	// Warnings do not make the configuration invalid, such as pipelines that
+	// break an order rule
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// OK reports whether the configuration has no problems
+func (r *ValidationReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Write prints the report in a human readable form
+func (r *ValidationReport) Write(w io.Writer) error {
+	if r.OK() {
+		if _, err := fmt.Fprintf(w, "Configuration is valid for the %s profile\n", r.Preset); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(w, "Configuration is invalid for the %s profile:\n", r.Preset); err != nil {
+			return err
+		}
+		for _, p := range r.Problems {
+			if _, err := fmt.Fprintf(w, "  - %s\n", p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(r.Warnings) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "Warnings:"); err != nil {
+		return err
+	}
+	for _, warning := range r.Warnings {
+		if _, err := fmt.Fprintf(w, "  - %s\n", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateConfig resolves the configuration described by settings and checks
+// it against the named preset, the way the collector would before starting.
+// Every component section is checked for types the preset does not include,
+// then the configuration is unmarshaled and validated. No component is
+// created, so no database connection is made and no port is opened. Pipelines
+// that break an order rule are reported as warnings. The error is only for an
+// unknown or invalid preset.
+func (r *Registry) ValidateConfig(ctx context.Context, preset string, settings confmap.ResolverSettings) (*ValidationReport, error) {
+	factories, err := r.BuildFromPreset(preset)
+	if err != nil {
+		return nil, err
+	}
+	report := &ValidationReport{Preset: preset}
+
+	resolver, err := confmap.NewResolver(settings)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("invalid config sources: %v", err))
+		return report, nil
+	}
+	conf, err := resolver.Resolve(ctx)
+	_ = resolver.Shutdown(ctx)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("cannot resolve the configuration: %v", err))
+		return report, nil
+	}
+	report.Warnings = orderWarnings(conf, r.orderRules)
+
+	report.Problems = append(report.Problems, r.missingComponents(conf, KindReceiver, "receivers", preset, sortedTypes(factories.Receivers))...)
+	report.Problems = append(report.Problems, r.missingComponents(conf, KindProcessor, "processors", preset, sortedTypes(factories.Processors))...)
+	report.Problems = append(report.Problems, r.missingComponents(conf, KindExporter, "exporters", preset, sortedTypes(factories.Exporters))...)
+	report.Problems = append(report.Problems, r.missingComponents(conf, KindExtension, "extensions", preset, sortedTypes(factories.Extensions))...)
+	report.Problems = append(report.Problems, r.missingComponents(conf, KindConnector, "connectors", preset, sortedTypes(factories.Connectors))...)
+	if !report.OK() {
+		// Unmarshaling would only repeat the first missing type
+		return report, nil
+	}
+
+	provider, err := otelcol.NewConfigProvider(otelcol.ConfigProviderSettings{ResolverSettings: settings})
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("invalid config sources: %v", err))
+		return report, nil
+	}
+	defer provider.Shutdown(ctx)
+
+	cfg, err := provider.Get(ctx, factories)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	}
+	return report, nil
+}
+
+// missingComponents returns a problem for every component configured under
+// key whose type is not in available, naming the presets that include it
+func (r *Registry) missingComponents(conf *confmap.Conf, kind, key, preset string, available []string) []string {
+	configured, ok := conf.Get(key).(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	ids := make([]string, 0, len(configured))
+	for id := range configured {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var problems []string
+	for _, id := range ids {
+		var cid component.ID
+		if err := cid.UnmarshalText([]byte(id)); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q: %v", kind, id, err))
+			continue
+		}
+		if slices.Contains(available, cid.Type().String()) {
+			continue
+		}
+
+		problem := fmt.Sprintf("%s %q is not in the %s profile", kind, id, preset)
+		if others := r.presetsWith(kind, cid.Type().String()); len(others) > 0 {
+			problem += fmt.Sprintf(" (available in: %s)", strings.Join(others, ", "))
+		} else {
+			problem += " (not compiled into this collector)"
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// presetsWith returns the presets that include a component type of kind
+func (r *Registry) presetsWith(kind, typ string) []string {
+	var names []string
+	for _, name := range r.Presets() {
+		p := r.presets[name]
+		var types []string
+		switch kind {
+		case KindReceiver:
+			types = p.Receivers
+		case KindProcessor:
+			types = p.Processors
+		case KindExporter:
+			types = p.Exporters
+		case KindExtension:
+			types = p.Extensions
+		case KindConnector:
+			types = p.Connectors
+		}
+		if slices.Contains(types, typ) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
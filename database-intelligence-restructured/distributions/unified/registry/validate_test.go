@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+)
+
+func fileSettings(uris ...string) confmap.ResolverSettings {
+	return confmap.ResolverSettings{
+		URIs:              uris,
+		ProviderFactories: []confmap.ProviderFactory{fileprovider.NewFactory()},
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	full := Preset{
+		Name:       "standard",
+		Extensions: []string{"zpages"},
+		Receivers:  []string{"otlp"},
+		Processors: []string{"batch", "memory_limiter"},
+		Exporters:  []string{"debug"},
+	}
+	r := New(testCatalog(t), builtinMinimal(), full)
+
+	tests := []struct {
+		name     string
+		preset   string
+		config   string
+		problems []string
+	}{
+		{
+			name:   "valid",
+			preset: "minimal",
+			config: "testdata/config.yaml",
+		},
+		{
+			name:   "processor absent from minimal",
+			preset: "minimal",
+			config: "testdata/config-memory-limiter.yaml",
+			problems: []string{
+				`processor "memory_limiter" is not in the minimal profile (available in: standard)`,
+				`extension "zpages" is not in the minimal profile (available in: standard)`,
+			},
+		},
+		{
+			name:   "same config in a profile that has it",
+			preset: "standard",
+			config: "testdata/config-memory-limiter.yaml",
+		},
+		{
+			name:     "pipeline references unconfigured exporter",
+			preset:   "minimal",
+			config:   "testdata/config-unconfigured-exporter.yaml",
+			problems: []string{`references exporter "debug/verbose" which is not configured`},
+		},
+		{
+			name:     "missing file",
+			preset:   "minimal",
+			config:   "testdata/nope.yaml",
+			problems: []string{"cannot resolve the configuration"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := r.ValidateConfig(context.Background(), tt.preset, fileSettings("file:"+tt.config))
+			require.NoError(t, err)
+
+			require.Len(t, report.Problems, len(tt.problems), "problems: %v", report.Problems)
+			assert.Equal(t, len(tt.problems) == 0, report.OK())
+			for i, want := range tt.problems {
+				assert.Contains(t, report.Problems[i], want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigUnknownPreset(t *testing.T) {
+	r := New(testCatalog(t), builtinMinimal())
+
+	_, err := r.ValidateConfig(context.Background(), "edge", fileSettings("file:testdata/config.yaml"))
+	assert.ErrorContains(t, err, `unknown preset "edge"`)
+}
+
+func TestValidationReportWrite(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, (&ValidationReport{Preset: "minimal"}).Write(&out))
+	assert.Equal(t, "Configuration is valid for the minimal profile\n", out.String())
+
+	out.Reset()
+	report := &ValidationReport{Preset: "minimal", Problems: []string{`processor "costcontrol" is not in the minimal profile`}}
+	require.NoError(t, report.Write(&out))
+	assert.Equal(t, "Configuration is invalid for the minimal profile:\n  - processor \"costcontrol\" is not in the minimal profile\n", out.String())
+}
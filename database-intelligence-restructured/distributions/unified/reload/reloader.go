@@ -0,0 +1,322 @@
+// Package reload applies configuration changes to a running collector.
+// Changes confined to processors that implement base.Reconfigurable are
+// applied in place, so the pipelines keep running and no data is dropped;
+// any other change falls back to the collector's full in-process reload.
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.uber.org/zap"
+
+	"github.com/database-intelligence/db-intel/components/processors/base"
+)
+
+// DefaultInterval is how often the configuration files are checked
+const DefaultInterval = 5 * time.Second
+
+// uriScheme matches the scheme of a config URI; like confmap, a scheme has
+// at least two characters, so a Windows drive letter is not one
+var uriScheme = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]+):`)
+
+// Settings configures a Reloader
+type Settings struct {
+	// ResolverSettings are the config sources the collector was started with
+	ResolverSettings confmap.ResolverSettings
+
+	// Factories are the components the collector was built with. The
+	// processor factories must be wrapped by Tracker.
+	Factories otelcol.Factories
+
+	// Tracker records the running processors
+	Tracker *Tracker
+
+	// Interval is how often the configuration files are checked for changes
+	Interval time.Duration
+
+	// Restart triggers a full reload of the collector. It defaults to
+	// sending SIGHUP to this process, which the collector handles by
+	// rebuilding every pipeline from the configuration.
+	Restart func() error
+
+	Logger *zap.Logger
+}
+
+// Reloader watches the configuration files and applies their changes
+type Reloader struct {
+	settings Settings
+	files    []string
+
+	// fingerprint is the hash of the files' contents last acted on
+	fingerprint [sha256.Size]byte
+	// running is the resolved configuration the collector is running
+	running map[string]any
+}
+
+// New creates a Reloader for the file sources in the resolver settings.
+// Changes to other sources, such as environment variables, are not noticed.
+func New(set Settings) (*Reloader, error) {
+	if set.Interval <= 0 {
+		set.Interval = DefaultInterval
+	}
+	if set.Restart == nil {
+		set.Restart = signalRestart
+	}
+	if set.Logger == nil {
+		set.Logger = zap.NewNop()
+	}
+
+	files := configFiles(set.ResolverSettings.URIs)
+	if len(files) == 0 {
+		return nil, errors.New("no configuration file to watch, config hot reload needs a file: source")
+	}
+	return &Reloader{settings: set, files: files}, nil
+}
+
+// configFiles returns the paths of the file sources among the config URIs.
+// A URI without a scheme is a file path.
+func configFiles(uris []string) []string {
+	var files []string
+	for _, uri := range uris {
+		m := uriScheme.FindStringSubmatch(uri)
+		switch {
+		case m == nil:
+			files = append(files, uri)
+		case m[1] == "file":
+			files = append(files, strings.TrimPrefix(uri, "file:"))
+		}
+	}
+	return files
+}
+
+// Run records the running configuration, then checks the files every
+// interval until ctx is done
+func (r *Reloader) Run(ctx context.Context) {
+	if err := r.init(ctx); err != nil {
+		r.settings.Logger.Error("Config hot reload disabled, failed to load the configuration", zap.Error(err))
+		return
+	}
+
+	r.settings.Logger.Info("Watching configuration for changes",
+		zap.Strings("files", r.files),
+		zap.Duration("interval", r.settings.Interval))
+
+	ticker := time.NewTicker(r.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.poll(ctx)
+		}
+	}
+}
+
+// init records the configuration the collector started with
+func (r *Reloader) init(ctx context.Context) error {
+	fingerprint, err := r.readFingerprint()
+	if err != nil {
+		return err
+	}
+	running, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	r.fingerprint = fingerprint
+	r.running = running
+	return nil
+}
+
+// poll reloads the configuration when a file's contents changed
+func (r *Reloader) poll(ctx context.Context) {
+	fingerprint, err := r.readFingerprint()
+	if err != nil {
+		// An editor replacing the file can leave it missing for a moment
+		r.settings.Logger.Debug("Failed to read configuration files", zap.Error(err))
+		return
+	}
+	if fingerprint == r.fingerprint {
+		return
+	}
+	r.fingerprint = fingerprint
+	r.reload(ctx)
+}
+
+// readFingerprint hashes the contents of the watched files
+func (r *Reloader) readFingerprint() ([sha256.Size]byte, error) {
+	h := sha256.New()
+	for _, file := range r.files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		h.Write(data)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// reload validates the new configuration and applies it. An invalid
+// configuration is logged and ignored, so the collector keeps running with
+// the last good one.
+func (r *Reloader) reload(ctx context.Context) {
+	logger := r.settings.Logger
+
+	resolved, err := r.resolve(ctx)
+	if err != nil {
+		logger.Error("Ignoring configuration change, failed to resolve it", zap.Error(err))
+		return
+	}
+	cfg, err := r.validate(ctx)
+	if err != nil {
+		logger.Error("Ignoring invalid configuration change", zap.Error(err))
+		return
+	}
+
+	processors, other := diff(r.running, resolved)
+	switch {
+	case len(processors) == 0 && len(other) == 0:
+		return
+	case len(other) > 0:
+		r.restart(fmt.Sprintf("%s changed", strings.Join(other, ", ")))
+	default:
+		if err := r.reconfigure(processors, cfg); err != nil {
+			r.restart(err.Error())
+		}
+	}
+	r.running = resolved
+}
+
+// reconfigure hands the new configuration of each changed processor to its
+// running instances. Processors not in any pipeline are not running and are
+// skipped.
+func (r *Reloader) reconfigure(ids []component.ID, cfg *otelcol.Config) error {
+	for _, id := range ids {
+		for _, instance := range r.settings.Tracker.Instances(id) {
+			reconfigurable, ok := instance.(base.Reconfigurable)
+			if !ok {
+				return fmt.Errorf("processor %s does not support runtime reconfiguration", id)
+			}
+			if err := reconfigurable.Reconfigure(cfg.Processors[id]); err != nil {
+				return fmt.Errorf("processor %s: %w", id, err)
+			}
+		}
+		r.settings.Logger.Info("Applied configuration change", zap.Stringer("processor", id))
+	}
+	return nil
+}
+
+// restart falls back to a full reload of the collector
+func (r *Reloader) restart(reason string) {
+	r.settings.Logger.Info("Configuration change needs a full reload", zap.String("reason", reason))
+	if err := r.settings.Restart(); err != nil {
+		r.settings.Logger.Error("Failed to trigger a full reload", zap.Error(err))
+	}
+}
+
+// resolve returns the configuration with every source merged and expanded
+func (r *Reloader) resolve(ctx context.Context) (map[string]any, error) {
+	resolver, err := confmap.NewResolver(r.settings.ResolverSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer resolver.Shutdown(ctx)
+
+	conf, err := resolver.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conf.ToStringMap(), nil
+}
+
+// validate unmarshals and validates the configuration the way the collector
+// does before starting
+func (r *Reloader) validate(ctx context.Context) (*otelcol.Config, error) {
+	provider, err := otelcol.NewConfigProvider(otelcol.ConfigProviderSettings{ResolverSettings: r.settings.ResolverSettings})
+	if err != nil {
+		return nil, err
+	}
+	defer provider.Shutdown(ctx)
+
+	cfg, err := provider.Get(ctx, r.settings.Factories)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// diff returns the processors whose configuration changed and the other
+// top-level sections that changed. A processor that was added or removed is
+// reported with the other sections, as the pipelines using it are rebuilt.
+func diff(running, updated map[string]any) ([]component.ID, []string) {
+	var other []string
+	for _, key := range unionKeys(running, updated) {
+		if key != "processors" && !reflect.DeepEqual(running[key], updated[key]) {
+			other = append(other, key)
+		}
+	}
+
+	oldProcessors, _ := running["processors"].(map[string]any)
+	newProcessors, _ := updated["processors"].(map[string]any)
+	var processors []component.ID
+	for _, key := range unionKeys(oldProcessors, newProcessors) {
+		oldCfg, inOld := oldProcessors[key]
+		newCfg, inNew := newProcessors[key]
+		if inOld && inNew && reflect.DeepEqual(oldCfg, newCfg) {
+			continue
+		}
+		if !inOld || !inNew {
+			other = append(other, "processors."+key)
+			continue
+		}
+		var id component.ID
+		if err := id.UnmarshalText([]byte(key)); err != nil {
+			other = append(other, "processors."+key)
+			continue
+		}
+		processors = append(processors, id)
+	}
+	return processors, other
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// signalRestart sends SIGHUP to this process
+func signalRestart() error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(syscall.SIGHUP)
+}
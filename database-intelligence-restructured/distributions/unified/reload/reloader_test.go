@@ -0,0 +1,267 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/debugexporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+	"github.com/database-intelligence/db-intel/components/processors/adaptivesampler"
+)
+
+const configTemplate = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: localhost:4317
+processors:
+  adaptivesampler:
+    default_sample_rate: 0
+    max_records_per_second: 1000
+    rules:
+      - name: slow_queries
+        priority: 100
+        sample_rate: SAMPLE_RATE
+        conditions:
+          - attribute: duration_ms
+            operator: gt
+            value: 1000
+  batch:
+    timeout: BATCH_TIMEOUT
+exporters:
+  debug:
+    verbosity: VERBOSITY
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      processors: [adaptivesampler, batch]
+      exporters: [debug]
+`
+
+// testConfig fills in the template values that the tests edit
+type testConfig struct {
+	SampleRate   string
+	BatchTimeout string
+	Verbosity    string
+}
+
+func (c testConfig) write(t *testing.T, path string) {
+	yaml := strings.NewReplacer(
+		"SAMPLE_RATE", c.SampleRate,
+		"BATCH_TIMEOUT", c.BatchTimeout,
+		"VERBOSITY", c.Verbosity,
+	).Replace(configTemplate)
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+}
+
+// testCollector is a reloader with the sampler it would reconfigure running
+type testCollector struct {
+	reloader *Reloader
+	sampler  processor.Logs
+	sink     *consumertest.LogsSink
+	restarts int
+}
+
+func newTestCollector(t *testing.T, path string) *testCollector {
+	var err error
+	factories := otelcol.Factories{}
+	factories.Receivers, err = receiver.MakeFactoryMap(otlpreceiver.NewFactory())
+	require.NoError(t, err)
+	factories.Exporters, err = exporter.MakeFactoryMap(debugexporter.NewFactory())
+	require.NoError(t, err)
+	factories.Processors, err = processor.MakeFactoryMap(adaptivesampler.NewFactory(), batchprocessor.NewFactory())
+	require.NoError(t, err)
+
+	tracker := NewTracker()
+	factories.Processors = tracker.WrapFactories(factories.Processors)
+
+	c := &testCollector{sink: &consumertest.LogsSink{}}
+	c.reloader, err = New(Settings{
+		ResolverSettings: confmap.ResolverSettings{
+			URIs:              []string{"file:" + path},
+			ProviderFactories: []confmap.ProviderFactory{fileprovider.NewFactory(), envprovider.NewFactory()},
+			DefaultScheme:     "env",
+		},
+		Factories: factories,
+		Tracker:   tracker,
+		Restart: func() error {
+			c.restarts++
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.reloader.init(ctx))
+
+	// Create the sampler the way the collector would
+	cfg, err := c.reloader.validate(ctx)
+	require.NoError(t, err)
+	id := component.MustNewID("adaptivesampler")
+	set := processortest.NewNopSettings()
+	set.ID = id
+	c.sampler, err = factories.Processors[id.Type()].CreateLogsProcessor(ctx, set, cfg.Processors[id], c.sink)
+	require.NoError(t, err)
+	require.NoError(t, c.sampler.Start(ctx, componenttest.NewNopHost()))
+	t.Cleanup(func() { c.sampler.Shutdown(context.Background()) })
+
+	return c
+}
+
+// sendSlowQuery passes a record matching the slow_queries rule through the
+// sampler and reports whether it was kept
+func (c *testCollector) sendSlowQuery(t *testing.T) bool {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutDouble("duration_ms", 2500)
+
+	before := c.sink.LogRecordCount()
+	require.NoError(t, c.sampler.ConsumeLogs(context.Background(), logs))
+	return c.sink.LogRecordCount() > before
+}
+
+func TestReloadAppliesSamplingRatesLive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := testConfig{SampleRate: "0", BatchTimeout: "200ms", Verbosity: "basic"}
+	cfg.write(t, path)
+	c := newTestCollector(t, path)
+
+	assert.False(t, c.sendSlowQuery(t))
+
+	cfg.SampleRate = "1.0"
+	cfg.write(t, path)
+	c.reloader.poll(context.Background())
+
+	assert.True(t, c.sendSlowQuery(t), "the new sample rate applies without a restart")
+	assert.Equal(t, 0, c.restarts)
+}
+
+func TestReloadIgnoresInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := testConfig{SampleRate: "0", BatchTimeout: "200ms", Verbosity: "basic"}
+	cfg.write(t, path)
+	c := newTestCollector(t, path)
+
+	cfg.SampleRate = "2.0"
+	cfg.write(t, path)
+	c.reloader.poll(context.Background())
+
+	assert.False(t, c.sendSlowQuery(t), "the running configuration is kept")
+	assert.Equal(t, 0, c.restarts)
+
+	// Fixing the file applies it
+	cfg.SampleRate = "1.0"
+	cfg.write(t, path)
+	c.reloader.poll(context.Background())
+	assert.True(t, c.sendSlowQuery(t))
+}
+
+func TestReloadFallsBackToRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := testConfig{SampleRate: "0", BatchTimeout: "200ms", Verbosity: "basic"}
+	cfg.write(t, path)
+	c := newTestCollector(t, path)
+
+	// Exporters cannot be reconfigured
+	cfg.Verbosity = "detailed"
+	cfg.write(t, path)
+	c.reloader.poll(context.Background())
+	assert.Equal(t, 1, c.restarts)
+
+	// Polling again without a change does nothing
+	c.reloader.poll(context.Background())
+	assert.Equal(t, 1, c.restarts)
+}
+
+func TestReloadRestartsForProcessorsWithoutSupport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := testConfig{SampleRate: "0", BatchTimeout: "200ms", Verbosity: "basic"}
+	cfg.write(t, path)
+	c := newTestCollector(t, path)
+
+	// The batch processor is not running in this test, so its change is
+	// skipped; the running sampler is reconfigured
+	cfg.BatchTimeout = "1s"
+	cfg.SampleRate = "1.0"
+	cfg.write(t, path)
+	c.reloader.poll(context.Background())
+	assert.Equal(t, 0, c.restarts)
+	assert.True(t, c.sendSlowQuery(t))
+
+	// Once it runs, a change to it needs a restart
+	ctx := context.Background()
+	running, err := c.reloader.validate(ctx)
+	require.NoError(t, err)
+	id := component.MustNewID("batch")
+	set := processortest.NewNopSettings()
+	set.ID = id
+	batch, err := c.reloader.settings.Factories.Processors[id.Type()].CreateLogsProcessor(ctx, set, running.Processors[id], consumertest.NewNop())
+	require.NoError(t, err)
+
+	cfg.BatchTimeout = "2s"
+	cfg.write(t, path)
+	c.reloader.poll(ctx)
+	assert.Equal(t, 1, c.restarts)
+
+	// A processor that was shut down is forgotten
+	require.NoError(t, batch.Shutdown(ctx))
+	assert.Empty(t, c.reloader.settings.Tracker.Instances(id))
+}
+
+func TestDiff(t *testing.T) {
+	running := map[string]any{
+		"processors": map[string]any{
+			"adaptivesampler":       map[string]any{"default_sample_rate": 0.1},
+			"verification/critical": map[string]any{"min_normalization_rate": 0.9},
+		},
+		"exporters": map[string]any{"debug": nil},
+	}
+	updated := map[string]any{
+		"processors": map[string]any{
+			"adaptivesampler":       map[string]any{"default_sample_rate": 0.5},
+			"verification/critical": map[string]any{"min_normalization_rate": 0.9},
+			"batch":                 nil,
+		},
+		"exporters": map[string]any{"debug": nil},
+		"service":   map[string]any{"telemetry": nil},
+	}
+
+	processors, other := diff(running, updated)
+	assert.Equal(t, []component.ID{component.MustNewID("adaptivesampler")}, processors)
+	assert.Equal(t, []string{"service", "processors.batch"}, other)
+}
+
+func TestConfigFiles(t *testing.T) {
+	assert.Equal(t, []string{"/etc/otel/config.yaml", "overrides.yaml", `C:\otel\config.yaml`},
+		configFiles([]string{
+			"file:/etc/otel/config.yaml",
+			"env:OTEL_CONFIG",
+			"overrides.yaml",
+			"yaml:processors::batch::timeout: 1s",
+			`C:\otel\config.yaml`,
+		}))
+
+	_, err := New(Settings{ResolverSettings: confmap.ResolverSettings{URIs: []string{"env:OTEL_CONFIG"}}})
+	assert.EqualError(t, err, "no configuration file to watch, config hot reload needs a file: source")
+}
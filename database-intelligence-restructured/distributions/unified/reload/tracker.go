@@ -0,0 +1,121 @@
+package reload
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+)
+
+// Tracker records the processors the collector is running, by component ID,
+// so a changed processor configuration can be handed to the running
+// instances. A processor is recorded when its factory creates it and
+// forgotten when it is shut down, so a full reload replaces the instances.
+type Tracker struct {
+	mu        sync.Mutex
+	instances map[component.ID][]component.Component
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{instances: make(map[component.ID][]component.Component)}
+}
+
+// WrapFactories returns processor factories that record every processor they
+// create. The collector must be built with the wrapped factories.
+func (t *Tracker) WrapFactories(factories map[component.Type]processor.Factory) map[component.Type]processor.Factory {
+	wrapped := make(map[component.Type]processor.Factory, len(factories))
+	for typ, f := range factories {
+		wrapped[typ] = &trackedFactory{Factory: f, tracker: t}
+	}
+	return wrapped
+}
+
+// Instances returns the running processors with the given ID, one per
+// pipeline data type
+func (t *Tracker) Instances(id component.ID) []component.Component {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.instances[id])
+}
+
+// add records a created processor and returns the function that forgets it
+func (t *Tracker) add(id component.ID, c component.Component) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.instances[id] = append(t.instances[id], c)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		remaining := slices.DeleteFunc(t.instances[id], func(other component.Component) bool { return other == c })
+		if len(remaining) == 0 {
+			delete(t.instances, id)
+			return
+		}
+		t.instances[id] = remaining
+	}
+}
+
+// trackedFactory records the processors created by the wrapped factory
+type trackedFactory struct {
+	processor.Factory
+	tracker *Tracker
+}
+
+func (f *trackedFactory) CreateTracesProcessor(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Traces) (processor.Traces, error) {
+	p, err := f.Factory.CreateTracesProcessor(ctx, set, cfg, next)
+	if err != nil {
+		return nil, err
+	}
+	return &trackedTraces{Traces: p, forget: f.tracker.add(set.ID, p)}, nil
+}
+
+func (f *trackedFactory) CreateMetricsProcessor(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Metrics) (processor.Metrics, error) {
+	p, err := f.Factory.CreateMetricsProcessor(ctx, set, cfg, next)
+	if err != nil {
+		return nil, err
+	}
+	return &trackedMetrics{Metrics: p, forget: f.tracker.add(set.ID, p)}, nil
+}
+
+func (f *trackedFactory) CreateLogsProcessor(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+	p, err := f.Factory.CreateLogsProcessor(ctx, set, cfg, next)
+	if err != nil {
+		return nil, err
+	}
+	return &trackedLogs{Logs: p, forget: f.tracker.add(set.ID, p)}, nil
+}
+
+type trackedTraces struct {
+	processor.Traces
+	forget func()
+}
+
+func (p *trackedTraces) Shutdown(ctx context.Context) error {
+	p.forget()
+	return p.Traces.Shutdown(ctx)
+}
+
+type trackedMetrics struct {
+	processor.Metrics
+	forget func()
+}
+
+func (p *trackedMetrics) Shutdown(ctx context.Context) error {
+	p.forget()
+	return p.Metrics.Shutdown(ctx)
+}
+
+type trackedLogs struct {
+	processor.Logs
+	forget func()
+}
+
+func (p *trackedLogs) Shutdown(ctx context.Context) error {
+	p.forget()
+	return p.Logs.Shutdown(ctx)
+}
@@ -49,6 +49,7 @@ type QueryDefinition struct {
 	Priority     int               `mapstructure:"priority"`
 	FallbackName string            `mapstructure:"fallback"`
 	Description  string            `mapstructure:"description"`
+	Unit         string            `mapstructure:"unit"` // UCUM unit of the metric, "1" when empty
 }
 
 // Detector interface for feature detection
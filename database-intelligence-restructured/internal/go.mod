@@ -7,6 +7,7 @@ toolchain go1.24.3
 require (
 	github.com/hashicorp/golang-lru/v2 v2.0.7
 	github.com/redis/go-redis/v9 v9.7.0
+	github.com/stretchr/testify v1.10.0
 	go.opentelemetry.io/collector/config/configopaque v1.35.0
 	go.opentelemetry.io/collector/config/configtelemetry v0.129.0
 	go.opentelemetry.io/collector/confmap v1.35.0
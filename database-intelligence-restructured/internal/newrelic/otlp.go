@@ -0,0 +1,167 @@
+// Package newrelic builds the OTLP exporter settings for sending data to New
+// Relic, so distributions and tests agree on endpoints, headers and
+// compression instead of each repeating them in YAML.
+package newrelic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/database-intelligence/db-intel/internal/secrets"
+)
+
+// Environment variables read by ExporterConfigFromEnv
+const (
+	LicenseKeyEnv = "NEW_RELIC_LICENSE_KEY"
+	RegionEnv     = "NEW_RELIC_REGION"
+	EndpointEnv   = "NEW_RELIC_OTLP_ENDPOINT"
+)
+
+// LicenseKeyHeader is the header New Relic reads the license key from
+const LicenseKeyHeader = "api-key"
+
+// Region is the New Relic data center an account belongs to
+type Region string
+
+const (
+	RegionUS Region = "US"
+	RegionEU Region = "EU"
+)
+
+// Protocol is the OTLP transport used by the exporter
+type Protocol string
+
+const (
+	ProtocolGRPC Protocol = "grpc"
+	ProtocolHTTP Protocol = "http"
+)
+
+// otlpHosts are the OTLP ingest hosts for each region
+var otlpHosts = map[Region]string{
+	RegionUS: "otlp.nr-data.net",
+	RegionEU: "otlp.eu01.nr-data.net",
+}
+
+// ParseRegion parses a region name case-insensitively. An empty name is the
+// US region, which is where accounts are created unless EU is chosen.
+func ParseRegion(name string) (Region, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "", string(RegionUS):
+		return RegionUS, nil
+	case string(RegionEU):
+		return RegionEU, nil
+	default:
+		return "", fmt.Errorf("unknown New Relic region %q, expected US or EU", name)
+	}
+}
+
+// OTLPEndpoint returns the OTLP ingest endpoint for a region. gRPC endpoints
+// are host:port as the otlp exporter expects; HTTP endpoints are base URLs
+// for the otlphttp exporter.
+func OTLPEndpoint(region Region, protocol Protocol) (string, error) {
+	host, ok := otlpHosts[region]
+	if !ok {
+		return "", fmt.Errorf("unknown New Relic region %q", region)
+	}
+
+	switch protocol {
+	case ProtocolGRPC:
+		return host + ":4317", nil
+	case ProtocolHTTP:
+		return "https://" + host + ":4318", nil
+	default:
+		return "", fmt.Errorf("unknown OTLP protocol %q", protocol)
+	}
+}
+
+// ExporterConfig holds the OTLP exporter settings for New Relic
+type ExporterConfig struct {
+	Endpoint    string
+	Headers     map[string]string
+	Compression string
+}
+
+// NewExporterConfig returns gzip-compressed exporter settings for the
+// region's endpoint with the license key header
+func NewExporterConfig(licenseKey string, region Region, protocol Protocol) (ExporterConfig, error) {
+	if licenseKey == "" {
+		return ExporterConfig{}, errors.New("New Relic license key is required")
+	}
+
+	endpoint, err := OTLPEndpoint(region, protocol)
+	if err != nil {
+		return ExporterConfig{}, err
+	}
+
+	return ExporterConfig{
+		Endpoint:    endpoint,
+		Headers:     map[string]string{LicenseKeyHeader: licenseKey},
+		Compression: "gzip",
+	}, nil
+}
+
+// EndpointFromEnv returns NEW_RELIC_OTLP_ENDPOINT when set, e.g. to point
+// tests at a local mock, and otherwise the endpoint for NEW_RELIC_REGION
+func EndpointFromEnv(protocol Protocol) (string, error) {
+	if endpoint := os.Getenv(EndpointEnv); endpoint != "" {
+		return endpoint, nil
+	}
+
+	region, err := ParseRegion(os.Getenv(RegionEnv))
+	if err != nil {
+		return "", err
+	}
+	return OTLPEndpoint(region, protocol)
+}
+
+// ExporterConfigFromEnv builds the exporter settings from
+// NEW_RELIC_LICENSE_KEY, which may be a secretref:// reference, and the
+// endpoint chosen by EndpointFromEnv
+func ExporterConfigFromEnv(protocol Protocol) (ExporterConfig, error) {
+	endpoint, err := EndpointFromEnv(protocol)
+	if err != nil {
+		return ExporterConfig{}, err
+	}
+
+	licenseKey, err := secrets.Getenv(context.Background(), LicenseKeyEnv)
+	if err != nil {
+		return ExporterConfig{}, err
+	}
+
+	// The region only selects the endpoint, which is already resolved
+	cfg, err := NewExporterConfig(licenseKey, RegionUS, protocol)
+	if err != nil {
+		return ExporterConfig{}, err
+	}
+	cfg.Endpoint = endpoint
+	return cfg, nil
+}
+
+// AsMap returns the settings in collector configuration form, ready to be
+// placed under an otlp or otlphttp exporter
+func (c ExporterConfig) AsMap() map[string]interface{} {
+	headers := make(map[string]interface{}, len(c.Headers))
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+
+	return map[string]interface{}{
+		"endpoint":    c.Endpoint,
+		"headers":     headers,
+		"compression": c.Compression,
+	}
+}
+
+// IsOTLPEndpoint reports whether endpoint points at a New Relic OTLP ingest
+// host in any region
+func IsOTLPEndpoint(endpoint string) bool {
+	for _, host := range otlpHosts {
+		if strings.Contains(endpoint, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package newrelic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPEndpoint(t *testing.T) {
+	tests := []struct {
+		region   Region
+		protocol Protocol
+		want     string
+	}{
+		{RegionUS, ProtocolGRPC, "otlp.nr-data.net:4317"},
+		{RegionUS, ProtocolHTTP, "https://otlp.nr-data.net:4318"},
+		{RegionEU, ProtocolGRPC, "otlp.eu01.nr-data.net:4317"},
+		{RegionEU, ProtocolHTTP, "https://otlp.eu01.nr-data.net:4318"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.region)+"/"+string(tt.protocol), func(t *testing.T) {
+			endpoint, err := OTLPEndpoint(tt.region, tt.protocol)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, endpoint)
+			assert.True(t, IsOTLPEndpoint(endpoint))
+		})
+	}
+
+	_, err := OTLPEndpoint("AP", ProtocolGRPC)
+	assert.EqualError(t, err, `unknown New Relic region "AP"`)
+
+	_, err = OTLPEndpoint(RegionUS, "websocket")
+	assert.EqualError(t, err, `unknown OTLP protocol "websocket"`)
+}
+
+func TestParseRegion(t *testing.T) {
+	for name, want := range map[string]Region{
+		"":     RegionUS,
+		"US":   RegionUS,
+		"us":   RegionUS,
+		"EU":   RegionEU,
+		" eu ": RegionEU,
+	} {
+		region, err := ParseRegion(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, region, name)
+	}
+
+	_, err := ParseRegion("eu02")
+	assert.EqualError(t, err, `unknown New Relic region "eu02", expected US or EU`)
+}
+
+func TestNewExporterConfig(t *testing.T) {
+	cfg, err := NewExporterConfig("license-123", RegionEU, ProtocolHTTP)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"endpoint":    "https://otlp.eu01.nr-data.net:4318",
+		"headers":     map[string]interface{}{"api-key": "license-123"},
+		"compression": "gzip",
+	}, cfg.AsMap())
+
+	_, err = NewExporterConfig("", RegionUS, ProtocolGRPC)
+	assert.EqualError(t, err, "New Relic license key is required")
+}
+
+func TestExporterConfigFromEnv(t *testing.T) {
+	t.Setenv(LicenseKeyEnv, "license-123")
+	t.Setenv(RegionEnv, "eu")
+	t.Setenv(EndpointEnv, "")
+
+	cfg, err := ExporterConfigFromEnv(ProtocolGRPC)
+	require.NoError(t, err)
+	assert.Equal(t, "otlp.eu01.nr-data.net:4317", cfg.Endpoint)
+	assert.Equal(t, "license-123", cfg.Headers[LicenseKeyHeader])
+	assert.Equal(t, "gzip", cfg.Compression)
+
+	t.Setenv(EndpointEnv, "localhost:4317")
+	cfg, err = ExporterConfigFromEnv(ProtocolGRPC)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:4317", cfg.Endpoint, "an explicit endpoint overrides the region")
+
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(RegionEnv, "moon")
+	_, err = ExporterConfigFromEnv(ProtocolGRPC)
+	assert.Error(t, err)
+
+	t.Setenv(RegionEnv, "")
+	t.Setenv(LicenseKeyEnv, "")
+	_, err = ExporterConfigFromEnv(ProtocolGRPC)
+	assert.EqualError(t, err, "New Relic license key is required")
+
+	t.Setenv("NEW_RELIC_TEST_LICENSE", "license-456")
+	t.Setenv(LicenseKeyEnv, "secretref://env/NEW_RELIC_TEST_LICENSE")
+	cfg, err = ExporterConfigFromEnv(ProtocolGRPC)
+	require.NoError(t, err)
+	assert.Equal(t, "license-456", cfg.Headers[LicenseKeyHeader], "the license key may be a secret reference")
+
+	t.Setenv(LicenseKeyEnv, "secretref://env/NEW_RELIC_TEST_UNSET")
+	_, err = ExporterConfigFromEnv(ProtocolGRPC)
+	assert.ErrorContains(t, err, LicenseKeyEnv)
+}
+
+func TestIsOTLPEndpoint(t *testing.T) {
+	assert.True(t, IsOTLPEndpoint("https://otlp.nr-data.net:4318"))
+	assert.True(t, IsOTLPEndpoint("otlp.eu01.nr-data.net:4317"))
+	assert.False(t, IsOTLPEndpoint("localhost:4317"))
+}
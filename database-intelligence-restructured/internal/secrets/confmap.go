@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// NewFactory returns the confmap provider for the secretref scheme, which
+// resolves ${secretref://<provider>/<key>} in collector configuration with
+// the default resolver when the configuration is loaded
+func NewFactory() confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+		return &confmapProvider{resolver: defaultResolver}
+	})
+}
+
+type confmapProvider struct {
+	resolver *Resolver
+}
+
+func (p *confmapProvider) Retrieve(ctx context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if !IsReference(uri) {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, Scheme)
+	}
+
+	secret, err := p.resolver.Resolve(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	// The secret is kept a string rather than parsed as YAML, so a numeric
+	// password stays a password
+	return confmap.NewRetrieved(secret)
+}
+
+func (p *confmapProvider) Scheme() string {
+	return Scheme
+}
+
+func (p *confmapProvider) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,220 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Scheme is the URI scheme of secret references
+const Scheme = "secretref"
+
+// referencePrefix starts every secret reference
+const referencePrefix = Scheme + "://"
+
+// DefaultSecretsDir is where FileProvider looks up relative keys. Docker
+// and Kubernetes mount secrets there by convention.
+const DefaultSecretsDir = "/run/secrets"
+
+// ErrSecretNotFound is returned when no provider has the requested secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Reference is a parsed secret reference of the form
+// secretref://<provider>/<key>, or secretref://<key> to try the fallback
+// providers in order
+type Reference struct {
+	// Provider is the provider type, empty for the fallback providers
+	Provider string
+	Key      string
+}
+
+// String returns the reference in secretref:// form
+func (r Reference) String() string {
+	if r.Provider == "" {
+		return referencePrefix + r.Key
+	}
+	return referencePrefix + r.Provider + "/" + r.Key
+}
+
+// IsReference reports whether value is a secret reference
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, referencePrefix)
+}
+
+// ParseReference parses a secretref:// reference. The key is everything
+// after the provider, so file paths and store paths may contain slashes.
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, fmt.Errorf("%q is not a %s reference", value, referencePrefix)
+	}
+
+	rest := strings.TrimPrefix(value, referencePrefix)
+	provider, key, found := strings.Cut(rest, "/")
+	if !found {
+		provider, key = "", rest
+	}
+	if key == "" {
+		return Reference{}, fmt.Errorf("secret reference %q has no key", value)
+	}
+	return Reference{Provider: provider, Key: key}, nil
+}
+
+// FileProvider reads each secret from a file holding only the secret, such
+// as a mounted Docker or Kubernetes secret. Relative keys are looked up in
+// Dir; absolute keys, written secretref://file//path, are read as is.
+type FileProvider struct {
+	// Dir defaults to DefaultSecretsDir
+	Dir string
+}
+
+func (p *FileProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	path := key
+	if !filepath.IsAbs(path) {
+		dir := p.Dir
+		if dir == "" {
+			dir = DefaultSecretsDir
+		}
+		path = filepath.Join(dir, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: file %s does not exist", ErrSecretNotFound, path)
+		}
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	// Editors and echo leave a trailing newline
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func (p *FileProvider) Type() string {
+	return "file"
+}
+
+func (p *FileProvider) IsAvailable() bool {
+	return true
+}
+
+// Resolver resolves secret references through registered providers. The
+// env and file providers are always registered; external stores such as
+// Vault or a cloud secret manager plug in with Register.
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	// fallback lists the provider types tried, in order, for references
+	// that name no provider
+	fallback []string
+}
+
+// NewResolver returns a resolver with the env and file providers. References
+// without a provider are looked up in the environment, then in
+// DefaultSecretsDir.
+func NewResolver() *Resolver {
+	r := &Resolver{providers: make(map[string]Provider)}
+	r.Register(&EnvProvider{})
+	r.Register(&FileProvider{})
+	r.fallback = []string{"env", "file"}
+	return r
+}
+
+// Register adds a provider, replacing any registered with the same type
+func (r *Resolver) Register(provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Type()] = provider
+}
+
+// SetFallback sets the provider types tried, in order, for references that
+// name no provider
+func (r *Resolver) SetFallback(types ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = append([]string(nil), types...)
+}
+
+// Resolve returns the secret a reference points to
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if ref.Provider != "" {
+		provider, ok := r.providers[ref.Provider]
+		if !ok {
+			return "", fmt.Errorf("secret reference %s: unknown provider %q", ref, ref.Provider)
+		}
+		if !provider.IsAvailable() {
+			return "", fmt.Errorf("secret reference %s: provider %q is not available", ref, ref.Provider)
+		}
+		secret, err := provider.GetSecret(ctx, ref.Key)
+		if err != nil {
+			return "", fmt.Errorf("secret reference %s: %w", ref, err)
+		}
+		return secret, nil
+	}
+
+	var errs []error
+	for _, providerType := range r.fallback {
+		provider, ok := r.providers[providerType]
+		if !ok || !provider.IsAvailable() {
+			continue
+		}
+		secret, err := provider.GetSecret(ctx, ref.Key)
+		if err == nil {
+			return secret, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", providerType, err))
+	}
+	return "", fmt.Errorf("secret reference %s: %w in %s: %w",
+		ref, ErrSecretNotFound, strings.Join(r.fallback, ", "), errors.Join(errs...))
+}
+
+// ResolveValue resolves value if it is a secret reference and otherwise
+// returns it unchanged, so settings accept either a secret or a reference
+func (r *Resolver) ResolveValue(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	return r.Resolve(ctx, value)
+}
+
+// defaultResolver backs the package level functions and the confmap
+// provider
+var defaultResolver = NewResolver()
+
+// Register adds a provider to the default resolver. Call it before the
+// collector starts, typically from an init function of the distribution.
+func Register(provider Provider) {
+	defaultResolver.Register(provider)
+}
+
+// Resolve resolves a secret reference with the default resolver
+func Resolve(ctx context.Context, value string) (string, error) {
+	return defaultResolver.Resolve(ctx, value)
+}
+
+// ResolveValue resolves value with the default resolver if it is a secret
+// reference and otherwise returns it unchanged
+func ResolveValue(ctx context.Context, value string) (string, error) {
+	return defaultResolver.ResolveValue(ctx, value)
+}
+
+// Getenv returns the environment variable name, resolved with the default
+// resolver when it holds a secret reference, e.g.
+// NEW_RELIC_LICENSE_KEY=secretref://file/newrelic-license-key
+func Getenv(ctx context.Context, name string) (string, error) {
+	value, err := ResolveValue(ctx, os.Getenv(name))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Reference
+	}{
+		{"secretref://env/POSTGRES_PASSWORD", Reference{Provider: "env", Key: "POSTGRES_PASSWORD"}},
+		{"secretref://file/postgres-password", Reference{Provider: "file", Key: "postgres-password"}},
+		{"secretref://file//etc/db/password", Reference{Provider: "file", Key: "/etc/db/password"}},
+		{"secretref://vault/database/creds#password", Reference{Provider: "vault", Key: "database/creds#password"}},
+		{"secretref://NEW_RELIC_LICENSE_KEY", Reference{Key: "NEW_RELIC_LICENSE_KEY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, err := ParseReference(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ref)
+			assert.Equal(t, tt.value, ref.String())
+		})
+	}
+
+	for _, value := range []string{"hunter2", "env:POSTGRES_PASSWORD", "secretref://", "secretref://env/"} {
+		_, err := ParseReference(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("SECRETREF_TEST_PASSWORD", "s3cr3t")
+	resolver := NewResolver()
+
+	secret, err := resolver.Resolve(context.Background(), "secretref://env/SECRETREF_TEST_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	_, err = resolver.Resolve(context.Background(), "secretref://env/SECRETREF_TEST_UNSET")
+	assert.ErrorContains(t, err, "SECRETREF_TEST_UNSET not found")
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "postgres-password"), []byte("p@ss word\n"), 0o600))
+
+	resolver := NewResolver()
+	resolver.Register(&FileProvider{Dir: dir})
+
+	// Relative keys are read from the directory; the trailing newline is
+	// dropped but other whitespace is part of the secret
+	secret, err := resolver.Resolve(context.Background(), "secretref://file/postgres-password")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss word", secret)
+
+	absolute := filepath.Join(dir, "postgres-password")
+	secret, err = resolver.Resolve(context.Background(), "secretref://file/"+absolute)
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss word", secret)
+
+	_, err = resolver.Resolve(context.Background(), "secretref://file/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+// storeProvider is an external store holding fixed secrets
+type storeProvider struct {
+	secrets   map[string]string
+	available bool
+}
+
+func (p *storeProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	if secret, ok := p.secrets[key]; ok {
+		return secret, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (p *storeProvider) Type() string {
+	return "store"
+}
+
+func (p *storeProvider) IsAvailable() bool {
+	return p.available
+}
+
+func TestResolverRegister(t *testing.T) {
+	store := &storeProvider{secrets: map[string]string{"newrelic/license": "eu01xx"}, available: true}
+	resolver := NewResolver()
+
+	_, err := resolver.Resolve(context.Background(), "secretref://store/newrelic/license")
+	assert.ErrorContains(t, err, `unknown provider "store"`)
+
+	resolver.Register(store)
+	secret, err := resolver.Resolve(context.Background(), "secretref://store/newrelic/license")
+	require.NoError(t, err)
+	assert.Equal(t, "eu01xx", secret)
+
+	store.available = false
+	_, err = resolver.Resolve(context.Background(), "secretref://store/newrelic/license")
+	assert.ErrorContains(t, err, `provider "store" is not available`)
+}
+
+func TestResolveFallback(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SECRETREF_TEST_FILE_ONLY"), []byte("from-file"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SECRETREF_TEST_BOTH"), []byte("from-file"), 0o600))
+	t.Setenv("SECRETREF_TEST_BOTH", "from-env")
+
+	resolver := NewResolver()
+	resolver.Register(&FileProvider{Dir: dir})
+	ctx := context.Background()
+
+	// The environment comes first
+	secret, err := resolver.Resolve(ctx, "secretref://SECRETREF_TEST_BOTH")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", secret)
+
+	// Then the secrets directory
+	secret, err = resolver.Resolve(ctx, "secretref://SECRETREF_TEST_FILE_ONLY")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", secret)
+
+	_, err = resolver.Resolve(ctx, "secretref://SECRETREF_TEST_NOWHERE")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	assert.ErrorContains(t, err, "env, file")
+
+	// The fallback order is configurable and skips unavailable providers
+	resolver.Register(&storeProvider{secrets: map[string]string{"SECRETREF_TEST_BOTH": "from-store"}, available: true})
+	resolver.SetFallback("store", "env")
+	secret, err = resolver.Resolve(ctx, "secretref://SECRETREF_TEST_BOTH")
+	require.NoError(t, err)
+	assert.Equal(t, "from-store", secret)
+
+	resolver.Register(&storeProvider{available: false})
+	secret, err = resolver.Resolve(ctx, "secretref://SECRETREF_TEST_BOTH")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", secret)
+
+	// Plain values pass through ResolveValue untouched
+	value, err := resolver.ResolveValue(ctx, "postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", value)
+}
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("SECRETREF_TEST_PLAIN", "plain")
+	t.Setenv("SECRETREF_TEST_TARGET", "resolved")
+	t.Setenv("SECRETREF_TEST_REF", "secretref://env/SECRETREF_TEST_TARGET")
+	t.Setenv("SECRETREF_TEST_BROKEN", "secretref://env/SECRETREF_TEST_UNSET")
+	ctx := context.Background()
+
+	value, err := Getenv(ctx, "SECRETREF_TEST_PLAIN")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	value, err = Getenv(ctx, "SECRETREF_TEST_REF")
+	require.NoError(t, err)
+	assert.Equal(t, "resolved", value)
+
+	_, err = Getenv(ctx, "SECRETREF_TEST_BROKEN")
+	assert.ErrorContains(t, err, "SECRETREF_TEST_BROKEN")
+}
+
+func TestConfmapProvider(t *testing.T) {
+	t.Setenv("SECRETREF_TEST_PASSWORD", "12345")
+
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs:              []string{"yaml:password: ${secretref://env/SECRETREF_TEST_PASSWORD}"},
+		ProviderFactories: []confmap.ProviderFactory{NewFactory(), yamlprovider.NewFactory()},
+	})
+	require.NoError(t, err)
+
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	// A numeric secret stays a string
+	assert.Equal(t, "12345", conf.Get("password"))
+
+	resolver, err = confmap.NewResolver(confmap.ResolverSettings{
+		URIs:              []string{"yaml:password: ${secretref://env/SECRETREF_TEST_UNSET}"},
+		ProviderFactories: []confmap.ProviderFactory{NewFactory(), yamlprovider.NewFactory()},
+	})
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+	assert.ErrorContains(t, err, "SECRETREF_TEST_UNSET not found")
+}
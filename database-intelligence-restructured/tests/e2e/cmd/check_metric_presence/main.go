@@ -0,0 +1,73 @@
+// Command check_metric_presence checks that every OTEL metric named in
+// metric_mappings.yaml has data in NRDB, so a mapping to a metric the
+// collector never sends, or one that stopped arriving, is caught before the
+// dashboards built on it go blank.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
+)
+
+const defaultMappingsFile = "./configs/validation/metric_mappings.yaml"
+
+func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	mappingsFile := flag.String("mappings", defaultMappingsFile, "Metric mappings file to generate the checks from")
+	where := flag.String("where", "", "NRQL condition limiting the metrics, e.g. \"host.name = 'db-1'\"")
+	batchSize := flag.Int("batch-size", 25, "Number of queries sent per request")
+	flag.Parse()
+
+	accountID := os.Getenv("NEW_RELIC_ACCOUNT_ID")
+	apiKey := os.Getenv("NEW_RELIC_USER_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("NEW_RELIC_API_KEY")
+	}
+	if accountID == "" || apiKey == "" {
+		log.Fatal("NEW_RELIC_ACCOUNT_ID and NEW_RELIC_USER_KEY must be set")
+	}
+
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
+	mappings, err := validation.LoadMetricMappings(*mappingsFile)
+	if err != nil {
+		log.Fatalf("Failed to load metric mappings: %v", err)
+	}
+	checks := validation.PresenceChecks(mappings, *where)
+
+	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := validation.CheckPresence(ctx, checks, *batchSize, func(ctx context.Context, nrqls []string) ([][]map[string]interface{}, error) {
+		results, err := nrdb.QueryBatch(ctx, nrqls)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([][]map[string]interface{}, len(results))
+		for i, result := range results {
+			rows[i] = result.Results
+		}
+		return rows, nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to query metric presence: %v", err)
+	}
+
+	if err := report.Print(os.Stdout); err != nil {
+		log.Fatalf("Failed to print report: %v", err)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
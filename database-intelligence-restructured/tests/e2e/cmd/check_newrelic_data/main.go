@@ -3,15 +3,19 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
-	"github.com/database-intelligence/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
 	fmt.Println("=== Checking New Relic Data ===")
 	fmt.Println()
 
@@ -28,7 +32,14 @@ func main() {
 		log.Fatal("Missing required environment variables")
 	}
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+	fmt.Printf("Time window: %s\n", window)
+
 	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
 	ctx := context.Background()
 
 	// Check various metric queries
@@ -72,7 +83,7 @@ func main() {
 
 	for _, q := range queries {
 		fmt.Printf("\nQuery: %s\n", q.name)
-		fmt.Printf("NRQL: %s\n", q.query)
+		fmt.Printf("NRQL: %s\n", window.Apply(q.query))
 		
 		result, err := nrdb.Query(ctx, q.query)
 		if err != nil {
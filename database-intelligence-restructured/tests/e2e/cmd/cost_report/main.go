@@ -0,0 +1,114 @@
+// Command cost_report ranks the metrics and attribute keys driving New Relic
+// ingest cost, so normalization and the costcontrol processor's
+// high_cardinality_dimensions can target what actually costs the most.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/costreport"
+)
+
+// attributeBatchSize is the number of attribute queries sent per request
+const attributeBatchSize = 25
+
+func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	pricing := costreport.DefaultPricing()
+	top := flag.Int("top", 20, "Number of metrics and attribute keys to list")
+	where := flag.String("where", "", "NRQL condition limiting the metrics, e.g. \"db.system IS NOT NULL\"")
+	maxAttributes := flag.Int("max-attributes", 100, "Maximum number of attribute keys to measure")
+	flag.Float64Var(&pricing.PricePerGB, "price-per-gb", pricing.PricePerGB, "Ingest price per GB, as costcontrol's price_per_gb")
+	flag.IntVar(&pricing.BytesPerDataPoint, "bytes-per-datapoint", pricing.BytesPerDataPoint, "Estimated size of one data point")
+	flag.Parse()
+
+	accountID := os.Getenv("NEW_RELIC_ACCOUNT_ID")
+	apiKey := os.Getenv("NEW_RELIC_USER_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("NEW_RELIC_API_KEY")
+	}
+	if accountID == "" || apiKey == "" {
+		log.Fatal("NEW_RELIC_ACCOUNT_ID and NEW_RELIC_USER_KEY must be set")
+	}
+
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
+	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	usage, err := nrdb.QueryBatch(ctx, []string{
+		costreport.MetricVolumeQuery(*where),
+		costreport.MetricSeriesQuery(*where),
+		costreport.KeysetQuery(*where),
+	})
+	if err != nil {
+		log.Fatalf("Failed to query metric usage: %v", err)
+	}
+
+	metrics, err := costreport.ParseMetricUsage(usage[0].Results, usage[1].Results)
+	if err != nil {
+		log.Fatalf("Failed to read metric usage: %v", err)
+	}
+
+	keys := costreport.ParseKeyset(usage[2].Results)
+	if len(keys) > *maxAttributes {
+		log.Printf("Measuring %d of %d attribute keys, raise -max-attributes to measure all", *maxAttributes, len(keys))
+		keys = keys[:*maxAttributes]
+	}
+
+	attributes, err := queryAttributes(ctx, nrdb, keys, *where)
+	if err != nil {
+		log.Fatalf("Failed to query attribute usage: %v", err)
+	}
+
+	report := costreport.Build(queriedWindow(usage[0]), pricing, metrics, attributes, *top)
+	if err := report.Print(os.Stdout); err != nil {
+		log.Fatalf("Failed to print report: %v", err)
+	}
+}
+
+// queryAttributes measures every key, batching the queries
+func queryAttributes(ctx context.Context, nrdb *framework.NRDBClient, keys []string, where string) ([]costreport.AttributeUsage, error) {
+	attributes := make([]costreport.AttributeUsage, 0, len(keys))
+	for start := 0; start < len(keys); start += attributeBatchSize {
+		batch := keys[start:min(start+attributeBatchSize, len(keys))]
+
+		queries := make([]string, len(batch))
+		for i, key := range batch {
+			queries[i] = costreport.AttributeQuery(key, where)
+		}
+		results, err := nrdb.QueryBatch(ctx, queries)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, key := range batch {
+			usage, err := costreport.ParseAttributeUsage(key, results[i].Results)
+			if err != nil {
+				return nil, err
+			}
+			attributes = append(attributes, usage)
+		}
+	}
+	return attributes, nil
+}
+
+// queriedWindow returns the time range NRDB ran a query over, zero if it did
+// not say
+func queriedWindow(result *framework.NRQLResult) time.Duration {
+	if result.Metadata == nil || result.Metadata.TimeWindow == nil {
+		return 0
+	}
+	w := result.Metadata.TimeWindow
+	return time.Duration(w.End-w.Begin) * time.Millisecond
+}
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,30 +12,6 @@ import (
 	"github.com/joho/godotenv"
 )
 
-const nerdGraphEndpoint = "https://api.newrelic.com/graphql"
-
-type GraphQLRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
-}
-
-type DashboardResponse struct {
-	Data struct {
-		DashboardCreate struct {
-			EntityResult struct {
-				GUID string `json:"guid"`
-			} `json:"entityResult"`
-			Errors []struct {
-				Description string `json:"description"`
-				Type        string `json:"type"`
-			} `json:"errors"`
-		} `json:"dashboardCreate"`
-	} `json:"data"`
-	Errors []struct {
-		Message string `json:"message"`
-	} `json:"errors"`
-}
-
 func loadEnv() error {
 	// Try to load .env from current directory
 	err := godotenv.Load()
@@ -53,71 +27,29 @@ func loadEnv() error {
 	return nil
 }
 
-func readGraphQLQuery() (string, error) {
-	queryPath := filepath.Join("..", "..", "nerdgraph", "create_otel_dashboard.graphql")
-	content, err := os.ReadFile(queryPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read GraphQL query: %w", err)
-	}
-	return string(content), nil
-}
-
-func createDashboard(apiKey string, accountID int, query string) (*DashboardResponse, error) {
-	variables := map[string]interface{}{
-		"accountId": accountID,
-	}
-
-	reqBody := GraphQLRequest{
-		Query:     query,
-		Variables: variables,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", nerdGraphEndpoint, bytes.NewReader(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("API-Key", apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	var dashboardResp DashboardResponse
-	if err := json.Unmarshal(body, &dashboardResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &dashboardResp, nil
-}
-
 func main() {
+	templatePath := flag.String("template", filepath.Join("..", "..", "nerdgraph", "otel_dashboard.json.tmpl"), "Dashboard JSON template")
+	perDatabase := flag.Bool("per-database", false, "Create one dashboard per database in the databases variable")
+	dryRun := flag.Bool("dry-run", false, "Print the rendered dashboards without creating them")
+	upsert := flag.Bool("upsert", false, "Update the dashboard with the same name instead of creating a duplicate")
+	vars := templateVars{}
+	flag.Var(vars, "vars", "Template variable as key=value (repeatable): name, databases (comma separated), since")
+	flag.Parse()
+
 	// Load environment variables
 	if err := loadEnv(); err != nil {
 		log.Printf("Warning: %v", err)
 	}
 
+	// Flags override DASHBOARD_VAR_* environment variables
+	resolved := varsFromEnv(os.Environ())
+	for key, value := range vars {
+		resolved[key] = value
+	}
+
 	// Get API key and account ID
 	apiKey := os.Getenv("NEW_RELIC_API_KEY")
-	if apiKey == "" {
+	if apiKey == "" && !*dryRun {
 		log.Fatal("NEW_RELIC_API_KEY environment variable is required")
 	}
 
@@ -131,49 +63,77 @@ func main() {
 		log.Fatalf("Invalid account ID: %v", err)
 	}
 
-	// Read GraphQL query
-	query, err := readGraphQLQuery()
+	templateText, err := readTemplate(*templatePath)
 	if err != nil {
-		log.Fatalf("Failed to read query: %v", err)
+		log.Fatalf("Failed to read template: %v", err)
 	}
 
-	fmt.Println("Creating OpenTelemetry PostgreSQL Dashboard...")
-	fmt.Printf("Account ID: %d\n", accountID)
+	dashboardVars := resolved.dashboardVars(accountID)
+	targets := []DashboardVars{dashboardVars}
+	if *perDatabase {
+		if len(dashboardVars.Databases) == 0 {
+			log.Fatal("-per-database requires the databases variable")
+		}
+		targets = dashboardVars.perDatabase()
+	}
 
-	// Create dashboard
-	resp, err := createDashboard(apiKey, accountID, query)
-	if err != nil {
-		log.Fatalf("Failed to create dashboard: %v", err)
+	// Render everything first so a template error creates no dashboards
+	dashboards := make([]map[string]interface{}, 0, len(targets))
+	for _, target := range targets {
+		dashboard, err := renderDashboard(templateText, target)
+		if err != nil {
+			log.Fatalf("Failed to render dashboard %q: %v", target.Name, err)
+		}
+		dashboards = append(dashboards, dashboard)
 	}
 
-	// Check for GraphQL errors
-	if len(resp.Errors) > 0 {
-		log.Println("GraphQL errors:")
-		for _, err := range resp.Errors {
-			log.Printf("  - %s", err.Message)
+	if *dryRun {
+		output, err := json.MarshalIndent(dashboards, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode dashboards: %v", err)
 		}
-		os.Exit(1)
+		fmt.Println(string(output))
+		return
 	}
 
-	// Check for mutation errors
-	if len(resp.Data.DashboardCreate.Errors) > 0 {
-		log.Println("Dashboard creation errors:")
-		for _, err := range resp.Data.DashboardCreate.Errors {
-			log.Printf("  - %s: %s", err.Type, err.Description)
+	fmt.Printf("Account ID: %d\n", accountID)
+
+	client := newNerdGraphClient(apiKey)
+	failed := false
+	for _, dashboard := range dashboards {
+		if err := publish(client, accountID, dashboard, *upsert); err != nil {
+			log.Printf("Failed to publish dashboard %q: %v", dashboard["name"], err)
+			failed = true
 		}
+	}
+	if failed {
 		os.Exit(1)
 	}
+}
 
-	// Success!
-	guid := resp.Data.DashboardCreate.EntityResult.GUID
-	if guid != "" {
-		fmt.Println("\n✅ Dashboard created successfully!")
-		fmt.Printf("Dashboard GUID: %s\n", guid)
-		
-		// Construct dashboard URL
-		dashboardURL := fmt.Sprintf("https://one.newrelic.com/dashboards?account=%d&state=%s", accountID, guid)
-		fmt.Printf("Dashboard URL: %s\n", dashboardURL)
+// publish creates or, in upsert mode, creates or updates one dashboard and
+// prints its URL
+func publish(client *nerdGraphClient, accountID int, dashboard map[string]interface{}, upsert bool) error {
+	var (
+		guid    string
+		created = true
+		err     error
+	)
+	if upsert {
+		guid, created, err = client.upsertDashboard(accountID, dashboard)
 	} else {
-		log.Fatal("Dashboard was created but no GUID was returned")
+		guid, err = client.createDashboard(accountID, dashboard)
 	}
-}
\ No newline at end of file
+	if err != nil {
+		return err
+	}
+
+	action := "created"
+	if !created {
+		action = "updated"
+	}
+	fmt.Printf("✅ Dashboard %q %s\n", dashboard["name"], action)
+	fmt.Printf("Dashboard GUID: %s\n", guid)
+	fmt.Printf("Dashboard URL: https://one.newrelic.com/dashboards?account=%d&state=%s\n", accountID, guid)
+	return nil
+}
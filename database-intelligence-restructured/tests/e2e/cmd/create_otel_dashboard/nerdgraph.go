@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const nerdGraphEndpoint = "https://api.newrelic.com/graphql"
+
+type GraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLResponse is the envelope of every NerdGraph response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// dashboardMutationResult is the payload of dashboardCreate and dashboardUpdate
+type dashboardMutationResult struct {
+	EntityResult struct {
+		GUID string `json:"guid"`
+	} `json:"entityResult"`
+	Errors []struct {
+		Description string `json:"description"`
+		Type        string `json:"type"`
+	} `json:"errors"`
+}
+
+// createDashboardMutation takes the whole dashboard as a DashboardInput
+// variable, so dashboards are rendered from a template instead of being
+// written into the query text
+const createDashboardMutation = `mutation CreateDashboard($accountId: Int!, $dashboard: DashboardInput!) {
+  dashboardCreate(accountId: $accountId, dashboard: $dashboard) {
+    entityResult {
+      guid
+    }
+    errors {
+      description
+      type
+    }
+  }
+}`
+
+const updateDashboardMutation = `mutation UpdateDashboard($guid: EntityGuid!, $dashboard: DashboardInput!) {
+  dashboardUpdate(guid: $guid, dashboard: $dashboard) {
+    entityResult {
+      guid
+    }
+    errors {
+      description
+      type
+    }
+  }
+}`
+
+const findDashboardsQuery = `query FindDashboards($query: String!) {
+  actor {
+    entitySearch(query: $query) {
+      results {
+        entities {
+          guid
+          name
+          accountId
+        }
+      }
+    }
+  }
+}`
+
+// nerdGraphClient sends queries to NerdGraph
+type nerdGraphClient struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func newNerdGraphClient(apiKey string) *nerdGraphClient {
+	return &nerdGraphClient{
+		endpoint: nerdGraphEndpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{},
+	}
+}
+
+// execute runs a GraphQL operation and decodes its data into out
+func (c *nerdGraphClient) execute(query string, variables map[string]interface{}, out interface{}) error {
+	jsonBody, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var envelope graphQLResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		messages := make([]string, 0, len(envelope.Errors))
+		for _, e := range envelope.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response data: %w", err)
+	}
+	return nil
+}
+
+// guid returns the mutation's entity GUID or its errors
+func (r dashboardMutationResult) guid() (string, error) {
+	if len(r.Errors) > 0 {
+		messages := make([]string, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", e.Type, e.Description))
+		}
+		return "", fmt.Errorf("dashboard errors: %s", strings.Join(messages, "; "))
+	}
+	if r.EntityResult.GUID == "" {
+		return "", fmt.Errorf("no GUID was returned")
+	}
+	return r.EntityResult.GUID, nil
+}
+
+func (c *nerdGraphClient) createDashboard(accountID int, dashboard map[string]interface{}) (string, error) {
+	var data struct {
+		DashboardCreate dashboardMutationResult `json:"dashboardCreate"`
+	}
+	variables := map[string]interface{}{
+		"accountId": accountID,
+		"dashboard": dashboard,
+	}
+	if err := c.execute(createDashboardMutation, variables, &data); err != nil {
+		return "", err
+	}
+	return data.DashboardCreate.guid()
+}
+
+func (c *nerdGraphClient) updateDashboard(guid string, dashboard map[string]interface{}) (string, error) {
+	var data struct {
+		DashboardUpdate dashboardMutationResult `json:"dashboardUpdate"`
+	}
+	variables := map[string]interface{}{
+		"guid":      guid,
+		"dashboard": dashboard,
+	}
+	if err := c.execute(updateDashboardMutation, variables, &data); err != nil {
+		return "", err
+	}
+	return data.DashboardUpdate.guid()
+}
+
+// dashboardEntity is a dashboard found by entity search
+type dashboardEntity struct {
+	GUID      string `json:"guid"`
+	Name      string `json:"name"`
+	AccountID int    `json:"accountId"`
+}
+
+// findDashboards returns the dashboards in the account with exactly this name
+func (c *nerdGraphClient) findDashboards(accountID int, name string) ([]dashboardEntity, error) {
+	var data struct {
+		Actor struct {
+			EntitySearch struct {
+				Results struct {
+					Entities []dashboardEntity `json:"entities"`
+				} `json:"results"`
+			} `json:"entitySearch"`
+		} `json:"actor"`
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name)
+	variables := map[string]interface{}{
+		"query": fmt.Sprintf("type = 'DASHBOARD' AND accountId = %d AND name = '%s'", accountID, escaped),
+	}
+	if err := c.execute(findDashboardsQuery, variables, &data); err != nil {
+		return nil, err
+	}
+
+	// Entity search also matches dashboard pages and is not case sensitive,
+	// so keep exact matches only
+	var matches []dashboardEntity
+	for _, entity := range data.Actor.EntitySearch.Results.Entities {
+		if entity.Name == name && entity.AccountID == accountID {
+			matches = append(matches, entity)
+		}
+	}
+	return matches, nil
+}
+
+// upsertDashboard updates the dashboard with the same name if there is one
+// and creates it otherwise. It reports whether a new dashboard was created.
+func (c *nerdGraphClient) upsertDashboard(accountID int, dashboard map[string]interface{}) (string, bool, error) {
+	name, _ := dashboard["name"].(string)
+	if strings.TrimSpace(name) == "" {
+		return "", false, fmt.Errorf("dashboard has no name to match existing dashboards by")
+	}
+
+	matches, err := c.findDashboards(accountID, name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up existing dashboard: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		guid, err := c.createDashboard(accountID, dashboard)
+		return guid, true, err
+	case 1:
+		guid, err := c.updateDashboard(matches[0].GUID, dashboard)
+		return guid, false, err
+	default:
+		guids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			guids = append(guids, match.GUID)
+		}
+		return "", false, fmt.Errorf("%d dashboards are named %q (%s); rename or delete the duplicates before upserting",
+			len(matches), name, strings.Join(guids, ", "))
+	}
+}
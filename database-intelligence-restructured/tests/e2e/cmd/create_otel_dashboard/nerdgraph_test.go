@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeNerdGraph answers the dashboard queries and mutations and records the
+// operations it received
+type fakeNerdGraph struct {
+	t        *testing.T
+	entities []dashboardEntity
+	requests []GraphQLRequest
+}
+
+func (f *fakeNerdGraph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("API-Key"); got != "test-key" {
+		f.t.Errorf("API-Key = %q, want test-key", got)
+	}
+
+	var req GraphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Fatalf("invalid request: %v", err)
+	}
+	f.requests = append(f.requests, req)
+
+	var data interface{}
+	switch {
+	case strings.Contains(req.Query, "entitySearch"):
+		data = map[string]interface{}{"actor": map[string]interface{}{"entitySearch": map[string]interface{}{
+			"results": map[string]interface{}{"entities": f.entities},
+		}}}
+	case strings.Contains(req.Query, "dashboardCreate"):
+		data = map[string]interface{}{"dashboardCreate": map[string]interface{}{
+			"entityResult": map[string]string{"guid": "created-guid"},
+		}}
+	case strings.Contains(req.Query, "dashboardUpdate"):
+		data = map[string]interface{}{"dashboardUpdate": map[string]interface{}{
+			"entityResult": map[string]interface{}{"guid": req.Variables["guid"]},
+		}}
+	default:
+		f.t.Fatalf("unexpected query %q", req.Query)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// operations returns the GraphQL operation names in the order received
+func (f *fakeNerdGraph) operations() []string {
+	var ops []string
+	for _, req := range f.requests {
+		for _, op := range []string{"FindDashboards", "CreateDashboard", "UpdateDashboard"} {
+			if strings.Contains(req.Query, op) {
+				ops = append(ops, op)
+			}
+		}
+	}
+	return ops
+}
+
+func newFakeNerdGraphClient(t *testing.T, entities []dashboardEntity) (*nerdGraphClient, *fakeNerdGraph) {
+	fake := &fakeNerdGraph{t: t, entities: entities}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := newNerdGraphClient("test-key")
+	client.endpoint = server.URL
+	return client, fake
+}
+
+func TestUpsertDashboard_Creates(t *testing.T) {
+	// Entity search is not exact: pages and other accounts' dashboards match too
+	client, fake := newFakeNerdGraphClient(t, []dashboardEntity{
+		{GUID: "page-guid", Name: "Orders / Overview", AccountID: 1},
+		{GUID: "other-account", Name: "Orders", AccountID: 2},
+		{GUID: "other-case", Name: "orders", AccountID: 1},
+	})
+
+	guid, created, err := client.upsertDashboard(1, map[string]interface{}{"name": "Orders"})
+	if err != nil {
+		t.Fatalf("upsertDashboard: %v", err)
+	}
+	if guid != "created-guid" || !created {
+		t.Errorf("got %q, created=%v; want created-guid, created=true", guid, created)
+	}
+	if ops := strings.Join(fake.operations(), ","); ops != "FindDashboards,CreateDashboard" {
+		t.Errorf("operations = %s", ops)
+	}
+	if query := fake.requests[0].Variables["query"]; query != "type = 'DASHBOARD' AND accountId = 1 AND name = 'Orders'" {
+		t.Errorf("search query = %v", query)
+	}
+}
+
+func TestUpsertDashboard_Updates(t *testing.T) {
+	client, fake := newFakeNerdGraphClient(t, []dashboardEntity{
+		{GUID: "existing-guid", Name: "Orders", AccountID: 1},
+	})
+
+	guid, created, err := client.upsertDashboard(1, map[string]interface{}{"name": "Orders"})
+	if err != nil {
+		t.Fatalf("upsertDashboard: %v", err)
+	}
+	if guid != "existing-guid" || created {
+		t.Errorf("got %q, created=%v; want existing-guid, created=false", guid, created)
+	}
+	if ops := strings.Join(fake.operations(), ","); ops != "FindDashboards,UpdateDashboard" {
+		t.Errorf("operations = %s", ops)
+	}
+}
+
+func TestUpsertDashboard_MultipleMatches(t *testing.T) {
+	client, fake := newFakeNerdGraphClient(t, []dashboardEntity{
+		{GUID: "first-guid", Name: "Orders", AccountID: 1},
+		{GUID: "second-guid", Name: "Orders", AccountID: 1},
+	})
+
+	_, _, err := client.upsertDashboard(1, map[string]interface{}{"name": "Orders"})
+	if err == nil || !strings.Contains(err.Error(), "2 dashboards are named \"Orders\" (first-guid, second-guid)") {
+		t.Errorf("error = %v, want one naming both duplicates", err)
+	}
+	if ops := strings.Join(fake.operations(), ","); ops != "FindDashboards" {
+		t.Errorf("operations = %s, want no mutation", ops)
+	}
+}
+
+func TestUpsertDashboard_EmptyName(t *testing.T) {
+	client, fake := newFakeNerdGraphClient(t, nil)
+
+	for _, dashboard := range []map[string]interface{}{{}, {"name": "  "}} {
+		if _, _, err := client.upsertDashboard(1, dashboard); err == nil {
+			t.Errorf("upsertDashboard(%v) succeeded, want an error", dashboard)
+		}
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("sent %d requests for a dashboard without a name", len(fake.requests))
+	}
+}
+
+func TestFindDashboards_EscapesName(t *testing.T) {
+	client, fake := newFakeNerdGraphClient(t, nil)
+
+	if _, err := client.findDashboards(1, `Bob's \ dashboard`); err != nil {
+		t.Fatalf("findDashboards: %v", err)
+	}
+	want := `type = 'DASHBOARD' AND accountId = 1 AND name = 'Bob\'s \\ dashboard'`
+	if query := fake.requests[0].Variables["query"]; query != want {
+		t.Errorf("search query = %v, want %s", query, want)
+	}
+}
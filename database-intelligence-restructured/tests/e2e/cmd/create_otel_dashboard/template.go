@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultDashboardName = "PostgreSQL OpenTelemetry Monitoring"
+	defaultSince         = "1 hour"
+
+	// varEnvPrefix marks environment variables that set template variables,
+	// e.g. DASHBOARD_VAR_DATABASES=orders,inventory
+	varEnvPrefix = "DASHBOARD_VAR_"
+)
+
+// DashboardVars are the values available to the dashboard template
+type DashboardVars struct {
+	AccountID int
+	Name      string
+	// Databases limits every widget to these database names; empty means all
+	Databases []string
+	// Since is the default NRQL time window, e.g. "1 hour" or "30 minutes"
+	Since string
+	// Extra holds variables without a dedicated field for custom templates
+	Extra map[string]string
+}
+
+// templateVars collects key=value substitutions from the environment and
+// the -vars flag; flags override the environment
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+v[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Set parses a key=value pair
+func (v templateVars) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	v[strings.ToLower(strings.TrimSpace(key))] = val
+	return nil
+}
+
+// varsFromEnv reads DASHBOARD_VAR_* environment variables
+func varsFromEnv(environ []string) templateVars {
+	vars := templateVars{}
+	for _, entry := range environ {
+		key, value, _ := strings.Cut(entry, "=")
+		if name, ok := strings.CutPrefix(key, varEnvPrefix); ok && name != "" {
+			vars[strings.ToLower(name)] = value
+		}
+	}
+	return vars
+}
+
+// dashboardVars resolves the well-known variables and keeps the rest as extras
+func (v templateVars) dashboardVars(accountID int) DashboardVars {
+	vars := DashboardVars{
+		AccountID: accountID,
+		Name:      defaultDashboardName,
+		Since:     defaultSince,
+		Extra:     map[string]string{},
+	}
+
+	for key, value := range v {
+		switch key {
+		case "name":
+			vars.Name = value
+		case "since":
+			vars.Since = value
+		case "databases":
+			for _, db := range strings.Split(value, ",") {
+				if db = strings.TrimSpace(db); db != "" {
+					vars.Databases = append(vars.Databases, db)
+				}
+			}
+		default:
+			vars.Extra[key] = value
+		}
+	}
+	return vars
+}
+
+// perDatabase returns one set of variables per database, naming each
+// dashboard after its database
+func (d DashboardVars) perDatabase() []DashboardVars {
+	if len(d.Databases) == 0 {
+		return []DashboardVars{d}
+	}
+
+	all := make([]DashboardVars, 0, len(d.Databases))
+	for _, db := range d.Databases {
+		vars := d
+		vars.Databases = []string{db}
+		vars.Name = fmt.Sprintf("%s - %s", d.Name, db)
+		all = append(all, vars)
+	}
+	return all
+}
+
+// jsonStringBody escapes s for use inside a JSON string literal
+func jsonStringBody(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// nrqlList renders names as a list of NRQL string literals
+func nrqlList(names []string) string {
+	literals := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name)
+		literals = append(literals, "'"+escaped+"'")
+	}
+	return strings.Join(literals, ", ")
+}
+
+// templateFuncs are available in dashboard templates. Their output is safe
+// to place inside JSON string literals.
+func templateFuncs(vars DashboardVars) template.FuncMap {
+	databaseFilter := func(attribute string) string {
+		return jsonStringBody(fmt.Sprintf("%s IN (%s)", attribute, nrqlList(vars.Databases)))
+	}
+
+	return template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"esc":  jsonStringBody,
+		"join": strings.Join,
+		// dbWhere starts a WHERE clause limiting attribute to the selected databases
+		"dbWhere": func(attribute string) string {
+			if len(vars.Databases) == 0 {
+				return ""
+			}
+			return " WHERE " + databaseFilter(attribute)
+		},
+		// dbAnd extends an existing WHERE clause with the database filter
+		"dbAnd": func(attribute string) string {
+			if len(vars.Databases) == 0 {
+				return ""
+			}
+			return " AND " + databaseFilter(attribute)
+		},
+	}
+}
+
+// renderDashboard executes the template and returns the DashboardInput it
+// describes, ready to be sent as the dashboardCreate mutation's variable
+func renderDashboard(templateText string, vars DashboardVars) (map[string]interface{}, error) {
+	tmpl, err := template.New("dashboard").Funcs(templateFuncs(vars)).Option("missingkey=error").Parse(templateText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dashboard template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("failed to render dashboard template: %w", err)
+	}
+
+	var dashboard map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &dashboard); err != nil {
+		return nil, fmt.Errorf("dashboard template did not render valid JSON: %w", err)
+	}
+	if name, _ := dashboard["name"].(string); name == "" {
+		return nil, fmt.Errorf("rendered dashboard has no name")
+	}
+	if pages, _ := dashboard["pages"].([]interface{}); len(pages) == 0 {
+		return nil, fmt.Errorf("rendered dashboard has no pages")
+	}
+	return dashboard, nil
+}
+
+func readTemplate(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read dashboard template: %w", err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateVarsSet(t *testing.T) {
+	vars := templateVars{}
+	for _, value := range []string{"Name=Orders", " since =30 minutes", "filter=a=b", "owner="} {
+		if err := vars.Set(value); err != nil {
+			t.Errorf("Set(%q): %v", value, err)
+		}
+	}
+	want := templateVars{"name": "Orders", "since": "30 minutes", "filter": "a=b", "owner": ""}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("vars = %v, want %v", vars, want)
+	}
+
+	for _, value := range []string{"databases", "=orders", "  =orders", ""} {
+		if err := vars.Set(value); err == nil {
+			t.Errorf("Set(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestVarsFlag(t *testing.T) {
+	parse := func(args ...string) (templateVars, error) {
+		vars := templateVars{}
+		flags := flag.NewFlagSet("create_otel_dashboard", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		flags.Var(vars, "vars", "")
+		return vars, flags.Parse(args)
+	}
+
+	vars, err := parse("-vars", "name=Orders", "-vars", "databases=orders,inventory")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := vars.String(); got != "databases=orders,inventory name=Orders" {
+		t.Errorf("vars = %q", got)
+	}
+
+	if _, err := parse("-vars", "name=Orders", "-vars", "databases"); err == nil || !strings.Contains(err.Error(), `expected key=value, got "databases"`) {
+		t.Errorf("error = %v, want one naming the malformed pair", err)
+	}
+}
+
+func TestVarsFromEnv(t *testing.T) {
+	vars := varsFromEnv([]string{
+		"DASHBOARD_VAR_DATABASES=orders,inventory",
+		"DASHBOARD_VAR_Since=6 hours",
+		"DASHBOARD_VAR_=ignored",
+		"NEW_RELIC_ACCOUNT_ID=1",
+	})
+	want := templateVars{"databases": "orders,inventory", "since": "6 hours"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("vars = %v, want %v", vars, want)
+	}
+}
+
+func TestDashboardVars(t *testing.T) {
+	defaults := templateVars{}.dashboardVars(1)
+	if defaults.Name != defaultDashboardName || defaults.Since != defaultSince || len(defaults.Databases) != 0 {
+		t.Errorf("defaults = %+v", defaults)
+	}
+
+	vars := templateVars{"databases": " orders, ,inventory ", "owner": "dba"}.dashboardVars(1)
+	if !reflect.DeepEqual(vars.Databases, []string{"orders", "inventory"}) {
+		t.Errorf("databases = %q", vars.Databases)
+	}
+	if vars.Extra["owner"] != "dba" {
+		t.Errorf("extra = %v", vars.Extra)
+	}
+
+	perDatabase := vars.perDatabase()
+	if len(perDatabase) != 2 || perDatabase[1].Name != defaultDashboardName+" - inventory" ||
+		!reflect.DeepEqual(perDatabase[1].Databases, []string{"inventory"}) {
+		t.Errorf("perDatabase = %+v", perDatabase)
+	}
+}
+
+func TestRenderDashboard_Template(t *testing.T) {
+	templateText, err := readTemplate(filepath.Join("..", "..", "nerdgraph", "otel_dashboard.json.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := renderDashboard(templateText, templateVars{}.dashboardVars(1))
+	if err != nil {
+		t.Fatalf("renderDashboard: %v", err)
+	}
+	if all["name"] != defaultDashboardName {
+		t.Errorf("name = %v", all["name"])
+	}
+
+	vars := templateVars{"name": `Bob's "db"`, "databases": "orders,o'brien"}.dashboardVars(1)
+	filtered, err := renderDashboard(templateText, vars)
+	if err != nil {
+		t.Fatalf("renderDashboard: %v", err)
+	}
+	if filtered["name"] != `Bob's "db"` {
+		t.Errorf("name = %v", filtered["name"])
+	}
+	query := firstQuery(t, filtered)
+	if !strings.Contains(query, `attributes.db.name IN ('orders', 'o\'brien')`) {
+		t.Errorf("query %q is not limited to the databases", query)
+	}
+	if strings.Contains(firstQuery(t, all), " IN (") {
+		t.Errorf("unfiltered query %q has a database filter", firstQuery(t, all))
+	}
+}
+
+// firstQuery returns the NRQL of the first widget
+func firstQuery(t *testing.T, dashboard map[string]interface{}) string {
+	t.Helper()
+	page := dashboard["pages"].([]interface{})[0].(map[string]interface{})
+	widget := page["widgets"].([]interface{})[0].(map[string]interface{})
+	config := widget["rawConfiguration"].(map[string]interface{})
+	return config["nrqlQueries"].([]interface{})[0].(map[string]interface{})["query"].(string)
+}
+
+func TestRenderDashboard_Errors(t *testing.T) {
+	vars := templateVars{}.dashboardVars(1)
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"unresolved extra", `{"name": {{json .Name}}, "owner": "{{.Extra.owner}}", "pages": [{}]}`, `map has no entry for key "owner"`},
+		{"unknown field", `{"name": {{json .Owner}}, "pages": [{}]}`, "can't evaluate field Owner"},
+		{"parse error", `{"name": {{json .Name}`, "failed to parse dashboard template"},
+		{"invalid JSON", `{"name": {{.Name}}, "pages": [{}]}`, "did not render valid JSON"},
+		{"no name", `{"name": "", "pages": [{}]}`, "has no name"},
+		{"no pages", `{"name": {{json .Name}}, "pages": []}`, "has no pages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderDashboard(tt.template, vars)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
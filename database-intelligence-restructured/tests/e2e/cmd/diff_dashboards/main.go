@@ -0,0 +1,66 @@
+// Command diff_dashboards compares two dashboard JSON files, typically an OHI
+// dashboard and the OTEL dashboard migrated from it, and reports the widgets
+// whose queries, facets, events or metrics drifted apart. Like diff(1), it
+// exits 1 when the dashboards differ and 2 when they cannot be compared.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
+)
+
+func main() {
+	format := flag.String("format", "json", "Output format, json or text")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: diff_dashboards [-format json|text] <left.json> <right.json>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log.SetFlags(0)
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != "json" && *format != "text" {
+		fatalf("Unknown format %q", *format)
+	}
+
+	left, err := validation.LoadDashboard(flag.Arg(0))
+	if err != nil {
+		fatalf("Failed to load dashboard: %v", err)
+	}
+	right, err := validation.LoadDashboard(flag.Arg(1))
+	if err != nil {
+		fatalf("Failed to load dashboard: %v", err)
+	}
+
+	diff := validation.DiffDashboards(left, right)
+	if *format == "text" {
+		err = diff.Print(os.Stdout)
+	} else {
+		var out []byte
+		out, err = json.MarshalIndent(diff, "", "  ")
+		if err == nil {
+			_, err = fmt.Printf("%s\n", out)
+		}
+	}
+	if err != nil {
+		fatalf("Failed to write diff: %v", err)
+	}
+
+	if !diff.Equal() {
+		os.Exit(1)
+	}
+}
+
+// fatalf logs and exits with 2, keeping 1 for dashboards that differ
+func fatalf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	os.Exit(2)
+}
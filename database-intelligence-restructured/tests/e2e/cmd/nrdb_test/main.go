@@ -3,12 +3,15 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 type GraphQLRequest struct {
@@ -78,6 +81,14 @@ func queryNRDB(accountID, apiKey, nrql string) (*NRQLResponse, error) {
 }
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
 	// Load credentials
 	accountID := os.Getenv("NEW_RELIC_ACCOUNT_ID")
 	apiKey := os.Getenv("NEW_RELIC_API_KEY")
@@ -90,7 +101,8 @@ func main() {
 	}
 	
 	fmt.Println("=== NRDB Query Test ===")
-	fmt.Printf("Account: %s\n\n", accountID)
+	fmt.Printf("Account: %s\n", accountID)
+	fmt.Printf("Time window: %s\n\n", window)
 	
 	// Test queries to understand what data we have
 	queries := []struct {
@@ -121,9 +133,10 @@ func main() {
 	
 	for _, q := range queries {
 		fmt.Printf("\n=== %s ===\n", q.desc)
-		fmt.Printf("Query: %s\n\n", q.nrql)
+		nrql := window.Apply(q.nrql)
+		fmt.Printf("Query: %s\n\n", nrql)
 		
-		result, err := queryNRDB(accountID, apiKey, q.nrql)
+		result, err := queryNRDB(accountID, apiKey, nrql)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
@@ -3,23 +3,44 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
-	"time"
 
-	"github.com/database-intelligence/tests/e2e/framework"
-	"github.com/database-intelligence/tests/e2e/pkg/validation"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
+)
+
+const (
+	dashboardFile = "./testdata/postgresql_ohi_dashboard.json"
+	mappingsFile  = "./configs/validation/metric_mappings.yaml"
 )
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	accountsFile := flag.String("accounts", "", "YAML file of accounts to validate in one pass instead of NEW_RELIC_ACCOUNT_ID")
+	concurrency := flag.Int("concurrency", 0, "Accounts validated at once with -accounts (default: the file's concurrency, or 4)")
+	flag.Parse()
+
 	fmt.Println("=== Running OHI Parity Validation Platform ===")
 	fmt.Println()
 
 	// Load environment
 	loadEnvFile(".env")
 
+	if *accountsFile != "" {
+		window, err := windowFlags.Window()
+		if err != nil {
+			log.Fatalf("Invalid time window: %v", err)
+		}
+		if !runAccounts(*accountsFile, *concurrency, window) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Verify environment
 	accountID := os.Getenv("NEW_RELIC_ACCOUNT_ID")
 	apiKey := os.Getenv("NEW_RELIC_USER_KEY")
@@ -31,26 +52,28 @@ func main() {
 		log.Fatal("Missing required environment variables: NEW_RELIC_ACCOUNT_ID and NEW_RELIC_API_KEY/NEW_RELIC_USER_KEY")
 	}
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
 	fmt.Printf("Using New Relic Account: %s\n", accountID)
+	fmt.Printf("Time window: %s\n", window)
 	fmt.Println()
 
 	// Create NRDB client
 	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
 
 	// 1. Parse Dashboard
 	fmt.Println("Step 1: Parsing PostgreSQL OHI Dashboard...")
-	parser := validation.NewDashboardParser()
-	if err := parser.ParseFile("./testdata/postgresql_ohi_dashboard.json"); err != nil {
-		log.Fatalf("Failed to parse dashboard: %v", err)
-	}
-
-	widgets := parser.GetWidgets()
+	widgets := loadWidgets()
 	fmt.Printf("✅ Parsed %d widgets\n", len(widgets))
 	fmt.Println()
 
 	// 2. Load Metric Mappings
 	fmt.Println("Step 2: Loading OHI to OTEL Metric Mappings...")
-	mappings, err := validation.LoadMetricMappings("./configs/validation/metric_mappings.yaml")
+	mappings, err := validation.LoadMetricMappings(mappingsFile)
 	if err != nil {
 		log.Fatalf("Failed to load metric mappings: %v", err)
 	}
@@ -60,37 +83,20 @@ func main() {
 	// 3. Validate each widget
 	fmt.Println("Step 3: Validating Dashboard Widgets...")
 	ctx := context.Background()
-	validator := validation.NewParityValidator(nrdb, mappings)
+	client := nrdbDataClient{nrdb: nrdb}
+	validator, err := validation.NewParityValidator(client, client, mappingsFile)
+	if err != nil {
+		log.Fatalf("Failed to create parity validator: %v", err)
+	}
 
 	successCount := 0
 	failureCount := 0
 
 	for i, widget := range widgets {
-		fmt.Printf("\nWidget %d/%d: %s\n", i+1, len(widgets), widget.Name)
+		fmt.Printf("\nWidget %d/%d: %s\n", i+1, len(widgets), widget.Title)
 		fmt.Printf("  Type: %s\n", widget.VisualizationType)
-		fmt.Printf("  Query: %.100s...\n", widget.Query)
-
-		// Extract OHI event from query
-		ohiEvent := parser.ExtractEventFromQuery(widget.Query)
-		if ohiEvent == "" {
-			fmt.Println("  ⚠️  Could not extract OHI event from query")
-			failureCount++
-			continue
-		}
+		fmt.Printf("  Query: %.100s...\n", widget.NRQLQuery)
 
-		fmt.Printf("  OHI Event: %s\n", ohiEvent)
-
-		// Get OTEL equivalent
-		mapping, exists := mappings.Events[ohiEvent]
-		if !exists {
-			fmt.Printf("  ❌ No OTEL mapping found for %s\n", ohiEvent)
-			failureCount++
-			continue
-		}
-
-		fmt.Printf("  OTEL Mapping: %s\n", mapping.OTELMetricType)
-
-		// Run validation
 		result, err := validator.ValidateWidget(ctx, widget)
 		if err != nil {
 			fmt.Printf("  ❌ Validation error: %v\n", err)
@@ -98,11 +104,11 @@ func main() {
 			continue
 		}
 
-		if result.Valid {
-			fmt.Printf("  ✅ Validation passed (score: %.2f)\n", result.Score)
+		if result.Status == validation.ValidationStatusPassed {
+			fmt.Printf("  ✅ Validation passed (accuracy: %.2f)\n", result.Accuracy)
 			successCount++
 		} else {
-			fmt.Printf("  ❌ Validation failed (score: %.2f)\n", result.Score)
+			fmt.Printf("  ❌ Validation %s (accuracy: %.2f)\n", strings.ToLower(string(result.Status)), result.Accuracy)
 			for _, issue := range result.Issues {
 				fmt.Printf("     - %s: %s\n", issue.Severity, issue.Message)
 			}
@@ -125,6 +131,97 @@ func main() {
 	}
 }
 
+// runAccounts validates the dashboard widgets in every account of the
+// accounts file and prints a widget by account matrix. It reports whether
+// every account passed.
+func runAccounts(path string, concurrency int, window framework.TimeWindow) bool {
+	cfg, err := validation.LoadAccountsConfig(path)
+	if err != nil {
+		log.Fatalf("Failed to load accounts: %v", err)
+	}
+	if concurrency > 0 {
+		cfg.Concurrency = concurrency
+	}
+
+	widgets := loadWidgets()
+
+	fmt.Printf("Validating %d widgets in %d accounts, %d at a time\n", len(widgets), len(cfg.Accounts), cfg.Concurrency)
+	fmt.Printf("Time window: %s\n\n", window)
+
+	results := validation.ValidateAccounts(context.Background(), cfg.Accounts, cfg.Concurrency,
+		func(ctx context.Context, account validation.Account) ([]*validation.ValidationResult, error) {
+			nrdb := framework.NewNRDBClient(account.AccountID, account.APIKey)
+			nrdb.SetTimeWindow(window)
+
+			// Fail the account as a whole when it cannot be queried,
+			// rather than every widget separately
+			if _, err := nrdb.Query(ctx, "SELECT count(*) FROM Metric SINCE 5 minutes ago"); err != nil {
+				return nil, err
+			}
+
+			client := nrdbDataClient{nrdb: nrdb}
+			validator, err := validation.NewParityValidator(client, client, mappingsFile)
+			if err != nil {
+				return nil, err
+			}
+			return validator.ValidateAllWidgets(ctx, widgets)
+		})
+
+	matrix := validation.BuildParityMatrix(results)
+	if err := matrix.WriteText(os.Stdout); err != nil {
+		log.Fatalf("Failed to write results: %v", err)
+	}
+	return matrix.Passed()
+}
+
+// loadWidgets parses the OHI dashboard into the widgets to validate
+func loadWidgets() []validation.DashboardWidget {
+	data, err := os.ReadFile(dashboardFile)
+	if err != nil {
+		log.Fatalf("Failed to read dashboard: %v", err)
+	}
+	parser := validation.NewDashboardParser()
+	if err := parser.ParseDashboard(data); err != nil {
+		log.Fatalf("Failed to parse dashboard: %v", err)
+	}
+	return parser.GetWidgetValidationTests()
+}
+
+// nrdbDataClient lets the parity validator query NRDB
+type nrdbDataClient struct {
+	nrdb *framework.NRDBClient
+}
+
+func (c nrdbDataClient) Query(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	result, err := c.nrdb.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+// GetMetricValue returns the latest value of metric. The query carries no
+// SINCE of its own so that it runs over the client's time window, or NRQL's
+// default last hour when none was given.
+func (c nrdbDataClient) GetMetricValue(ctx context.Context, metric string, filters map[string]string) (float64, error) {
+	nrql := fmt.Sprintf("SELECT latest(%s) AS value FROM Metric WHERE metricName = '%s'", metric, metric)
+	for key, value := range filters {
+		nrql += fmt.Sprintf(" AND `%s` = '%s'", key, value)
+	}
+	result, err := c.nrdb.Query(ctx, nrql)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Results) == 0 {
+		return 0, fmt.Errorf("no value for metric %s", metric)
+	}
+	value, ok := result.Results[0]["value"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("no value for metric %s", metric)
+	}
+	return value, nil
+}
+
 func checkDataAvailability(ctx context.Context, nrdb *framework.NRDBClient) {
 	queries := []struct {
 		name  string
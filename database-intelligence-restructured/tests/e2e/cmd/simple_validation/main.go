@@ -3,17 +3,21 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"strings"
 
-	"github.com/database-intelligence/tests/e2e/framework"
-	"github.com/database-intelligence/tests/e2e/pkg/validation"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
 )
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
 	fmt.Println("=== OHI Parity Validation - Simple Test ===")
 	fmt.Println()
 
@@ -57,7 +61,14 @@ func main() {
 		log.Fatal("Missing required environment variables")
 	}
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+	fmt.Printf("Time window: %s\n", window)
+
 	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
 	ctx := context.Background()
 
 	// Test query
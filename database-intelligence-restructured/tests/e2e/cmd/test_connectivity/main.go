@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/lib/pq"
-	"github.com/database-intelligence/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 func main() {
@@ -24,6 +25,7 @@ func main() {
 	fmt.Printf("NEW_RELIC_API_KEY: %s\n", maskValue(os.Getenv("NEW_RELIC_API_KEY")))
 	fmt.Printf("POSTGRES_HOST: %s\n", getEnvOrDefault("POSTGRES_HOST", "localhost"))
 	fmt.Printf("POSTGRES_PORT: %s\n", getEnvOrDefault("POSTGRES_PORT", "5432"))
+	fmt.Printf("POSTGRES_SSLMODE: %s\n", getEnvOrDefault("POSTGRES_SSLMODE", "disable"))
 	fmt.Println()
 
 	// Test PostgreSQL connection
@@ -37,13 +39,17 @@ func main() {
 
 func testPostgreSQL() {
 	host := getEnvOrDefault("POSTGRES_HOST", "localhost")
-	port := getEnvOrDefault("POSTGRES_PORT", "5432")
+	port := getEnvOrDefaultInt("POSTGRES_PORT", 5432)
 	user := getEnvOrDefault("POSTGRES_USER", "postgres")
 	password := getEnvOrDefault("POSTGRES_PASSWORD", "postgres")
 	dbname := getEnvOrDefault("POSTGRES_DB", "testdb")
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+	tls := framework.PostgresTLSFromEnv()
+	if err := tls.Validate(); err != nil {
+		log.Printf("❌ Invalid PostgreSQL TLS settings: %v", err)
+		return
+	}
+	dsn := framework.PostgresDSN(host, port, user, password, dbname, tls)
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -57,6 +63,7 @@ func testPostgreSQL() {
 
 	if err := db.PingContext(ctx); err != nil {
 		log.Printf("❌ Failed to ping PostgreSQL: %v", err)
+		log.Printf("   DSN: %s", framework.RedactDSN(dsn))
 		return
 	}
 
@@ -163,4 +170,11 @@ func getEnvOrDefault(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
+}
+
+func getEnvOrDefaultInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
 }
\ No newline at end of file
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+)
+
+// driverName is the database/sql driver the checks connect with; tests
+// replace it with a fake
+var driverName = "postgres"
+
+// databaseCheck is the result of checking one discovered database
+type databaseCheck struct {
+	Name              string
+	Connected         bool
+	StatStatements    bool
+	StatementCount    int
+	StatStatementsErr error
+	Err               error
+}
+
+// discoverDatabases lists the databases on the server dsn points at, runs
+// the connectivity checks against each one and prints a summary table
+func discoverDatabases(dsn string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	names, err := listDatabases(ctx, dsn)
+	if err != nil {
+		fmt.Printf("❌ Failed to list databases: %v\n", err)
+		fmt.Printf("   DSN: %s\n", framework.RedactDSN(dsn))
+		return
+	}
+	fmt.Printf("✅ Found %d databases\n\n", len(names))
+
+	checks := make([]databaseCheck, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, checkDatabase(dsnForDatabase(dsn, name), name))
+	}
+
+	printDatabaseChecks(os.Stdout, checks)
+}
+
+// listDatabases returns the non-template databases that accept connections
+func listDatabases(ctx context.Context, dsn string) ([]string, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT datname FROM pg_database
+		WHERE NOT datistemplate AND datallowconn
+		ORDER BY datname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// checkDatabase connects to one database and checks pg_stat_statements
+func checkDatabase(dsn, name string) databaseCheck {
+	check := databaseCheck{Name: name}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		check.Err = err
+		return check
+	}
+	check.Connected = true
+
+	check.StatStatements, check.StatementCount, check.StatStatementsErr = checkStatStatements(ctx, db)
+	return check
+}
+
+// printDatabaseChecks prints one row per database and the databases list to
+// compare with the collector configuration
+func printDatabaseChecks(out io.Writer, checks []databaseCheck) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATABASE\tCONNECTED\tPG_STAT_STATEMENTS\tSTATEMENTS\tERROR")
+
+	var reachable []string
+	for _, check := range checks {
+		connected, statStatements, statements, errText := "❌", "-", "-", ""
+		if check.Connected {
+			connected = "✅"
+			reachable = append(reachable, check.Name)
+			switch {
+			case !check.StatStatements:
+				statStatements = "not installed"
+			case check.StatStatementsErr != nil:
+				statStatements = "not accessible"
+				errText = check.StatStatementsErr.Error()
+			default:
+				statStatements = "✅"
+				statements = fmt.Sprintf("%d", check.StatementCount)
+			}
+		} else {
+			errText = check.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", check.Name, connected, statStatements, statements, errText)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\n%d of %d databases reachable\n", len(reachable), len(checks))
+	fmt.Fprintf(out, "databases: [%s]\n", strings.Join(reachable, ", "))
+}
+
+// dsnForDatabase returns dsn pointed at another database. Both URL and
+// key=value DSNs are supported.
+func dsnForDatabase(dsn, name string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		u.Path = "/" + name
+		u.RawPath = ""
+		return u.String()
+	}
+
+	value := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name) + "'"
+
+	var parts []string
+	for _, part := range strings.Fields(dsn) {
+		if !strings.HasPrefix(part, "dbname=") {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(append(parts, "dbname="+value), " ")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDatabase is how one database answers the checks. The fake driver's
+// DSN is the database name.
+type fakeDatabase struct {
+	unreachable    bool
+	statStatements bool
+	statements     int
+	statementsErr  error
+}
+
+var fakeDatabases = map[string]fakeDatabase{
+	"app":       {statStatements: true, statements: 42},
+	"analytics": {},
+	"locked":    {statStatements: true, statementsErr: errors.New("permission denied for view pg_stat_statements")},
+	"offline":   {unreachable: true},
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{db: fakeDatabases[name]}, nil
+}
+
+type fakeConn struct {
+	db fakeDatabase
+}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) Ping(context.Context) error {
+	if c.db.unreachable {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "FROM pg_extension"):
+		return &fakeRows{value: c.db.statStatements}, nil
+	case strings.Contains(query, "FROM pg_stat_statements"):
+		if c.db.statementsErr != nil {
+			return nil, c.db.statementsErr
+		}
+		return &fakeRows{value: int64(c.db.statements)}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+// fakeRows is a single row with a single column
+type fakeRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func init() {
+	sql.Register("connectivity-fake", fakeDriver{})
+}
+
+func TestCheckDatabase(t *testing.T) {
+	defer func(name string) { driverName = name }(driverName)
+	driverName = "connectivity-fake"
+
+	app := checkDatabase("app", "app")
+	assert.Equal(t, databaseCheck{Name: "app", Connected: true, StatStatements: true, StatementCount: 42}, app)
+
+	analytics := checkDatabase("analytics", "analytics")
+	assert.True(t, analytics.Connected)
+	assert.False(t, analytics.StatStatements)
+
+	locked := checkDatabase("locked", "locked")
+	assert.True(t, locked.Connected)
+	assert.True(t, locked.StatStatements)
+	assert.EqualError(t, locked.StatStatementsErr, "permission denied for view pg_stat_statements")
+
+	offline := checkDatabase("offline", "offline")
+	assert.False(t, offline.Connected)
+	assert.EqualError(t, offline.Err, "connection refused")
+}
+
+func TestPrintDatabaseChecks(t *testing.T) {
+	checks := []databaseCheck{
+		{Name: "analytics", Connected: true},
+		{Name: "app", Connected: true, StatStatements: true, StatementCount: 42},
+		{Name: "locked", Connected: true, StatStatements: true, StatStatementsErr: errors.New("permission denied")},
+		{Name: "offline", Err: errors.New("connection refused")},
+	}
+
+	var out bytes.Buffer
+	printDatabaseChecks(&out, checks)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, []string{"DATABASE", "CONNECTED", "PG_STAT_STATEMENTS", "STATEMENTS", "ERROR"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"analytics", "✅", "not", "installed", "-"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"app", "✅", "✅", "42"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"locked", "✅", "not", "accessible", "-", "permission", "denied"}, strings.Fields(lines[3]))
+	assert.Equal(t, []string{"offline", "❌", "-", "-", "connection", "refused"}, strings.Fields(lines[4]))
+	assert.Equal(t, "3 of 4 databases reachable", lines[6])
+	assert.Equal(t, "databases: [analytics, app, locked]", lines[7], "unreachable databases are left out of the list")
+}
+
+func TestDSNForDatabase(t *testing.T) {
+	assert.Equal(t, "postgres://user:pw@db:5432/orders?sslmode=disable",
+		dsnForDatabase("postgres://user:pw@db:5432/postgres?sslmode=disable", "orders"))
+	assert.Equal(t, `host=db user=app dbname='order\'s'`,
+		dsnForDatabase("host=db dbname=postgres user=app", "order's"))
+}
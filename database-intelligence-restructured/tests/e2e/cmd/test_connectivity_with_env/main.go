@@ -4,17 +4,22 @@ import (
 	"bufio"
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
-	"github.com/database-intelligence/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 func main() {
+	discover := flag.Bool("discover", false, "List every database on the server and check each one")
+	flag.Parse()
+
 	// Load .env file manually
 	loadEnvFile(".env")
 	
@@ -30,11 +35,21 @@ func main() {
 	fmt.Printf("NEW_RELIC_USER_KEY: %s\n", maskValue(os.Getenv("NEW_RELIC_USER_KEY")))
 	fmt.Printf("POSTGRES_HOST: %s\n", getEnvOrDefault("POSTGRES_HOST", "localhost"))
 	fmt.Printf("POSTGRES_PORT: %s\n", getEnvOrDefault("POSTGRES_PORT", "5432"))
+	fmt.Printf("POSTGRES_SSLMODE: %s\n", getEnvOrDefault("POSTGRES_SSLMODE", "disable"))
 	fmt.Println()
 
+	if err := framework.PostgresTLSFromEnv().Validate(); err != nil {
+		log.Fatalf("❌ Invalid PostgreSQL TLS settings: %v", err)
+	}
+
 	// Test PostgreSQL connection
-	fmt.Println("Testing PostgreSQL Connection...")
-	testPostgreSQL()
+	if *discover {
+		fmt.Println("Discovering PostgreSQL Databases...")
+		discoverDatabases(postgresDSN())
+	} else {
+		fmt.Println("Testing PostgreSQL Connection...")
+		testPostgreSQL()
+	}
 	
 	// Test New Relic API connection
 	fmt.Println("\nTesting New Relic API Connection...")
@@ -79,20 +94,26 @@ func loadEnvFile(filename string) {
 	}
 }
 
-func testPostgreSQL() {
+// postgresDSN returns PG_REPLICA_DSN, or a DSN built from the POSTGRES_*
+// variables, including the POSTGRES_SSL* TLS settings
+func postgresDSN() string {
 	// Try DSN first
 	dsn := os.Getenv("PG_REPLICA_DSN")
 	if dsn == "" {
 		// Fall back to individual params
 		host := getEnvOrDefault("POSTGRES_HOST", "localhost")
-		port := getEnvOrDefault("POSTGRES_PORT", "5432")
+		port := getEnvOrDefaultInt("POSTGRES_PORT", 5432)
 		user := getEnvOrDefault("POSTGRES_USER", "postgres")
 		password := getEnvOrDefault("POSTGRES_PASSWORD", "postgres")
 		dbname := getEnvOrDefault("POSTGRES_DB", "postgres")
 
-		dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			host, port, user, password, dbname)
+		dsn = framework.PostgresDSN(host, port, user, password, dbname, framework.PostgresTLSFromEnv())
 	}
+	return dsn
+}
+
+func testPostgreSQL() {
+	dsn := postgresDSN()
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -106,7 +127,7 @@ func testPostgreSQL() {
 
 	if err := db.PingContext(ctx); err != nil {
 		log.Printf("❌ Failed to ping PostgreSQL: %v", err)
-		log.Printf("   DSN: %s", maskDSN(dsn))
+		log.Printf("   DSN: %s", framework.RedactDSN(dsn))
 		return
 	}
 
@@ -122,19 +143,9 @@ func testPostgreSQL() {
 	fmt.Printf("   Version: %.80s...\n", version)
 
 	// Check if pg_stat_statements is available
-	var hasStatStatements bool
-	err = db.QueryRowContext(ctx, `
-		SELECT EXISTS (
-			SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements'
-		)
-	`).Scan(&hasStatStatements)
-
-	if err == nil && hasStatStatements {
+	hasStatStatements, count, err := checkStatStatements(ctx, db)
+	if hasStatStatements {
 		fmt.Println("✅ pg_stat_statements extension is installed")
-		
-		// Check if we can query it
-		var count int
-		err = db.QueryRowContext(ctx, "SELECT count(*) FROM pg_stat_statements").Scan(&count)
 		if err != nil {
 			fmt.Printf("⚠️  pg_stat_statements installed but not accessible: %v\n", err)
 		} else {
@@ -145,6 +156,24 @@ func testPostgreSQL() {
 	}
 }
 
+// checkStatStatements reports whether pg_stat_statements is installed in the
+// connected database and, if so, how many statements it holds. err is set
+// when the extension is installed but cannot be queried.
+func checkStatStatements(ctx context.Context, db *sql.DB) (installed bool, count int, err error) {
+	err = db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements'
+		)
+	`).Scan(&installed)
+	if err != nil || !installed {
+		return false, 0, nil
+	}
+
+	// Check if we can query it
+	err = db.QueryRowContext(ctx, "SELECT count(*) FROM pg_stat_statements").Scan(&count)
+	return true, count, err
+}
+
 func testNewRelicAPI() {
 	accountID := os.Getenv("NEW_RELIC_ACCOUNT_ID")
 	apiKey := os.Getenv("NEW_RELIC_API_KEY")
@@ -216,19 +245,15 @@ func maskValue(value string) string {
 	return value[:4] + "..." + value[len(value)-4:]
 }
 
-func maskDSN(dsn string) string {
-	// Mask password in DSN
-	parts := strings.Split(dsn, " ")
-	for i, part := range parts {
-		if strings.HasPrefix(part, "password=") {
-			parts[i] = "password=***"
-		}
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
-	return strings.Join(parts, " ")
+	return defaultValue
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func getEnvOrDefaultInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
 		return value
 	}
 	return defaultValue
@@ -6,7 +6,7 @@ import (
 	"log"
 	"os"
 
-	"github.com/database-intelligence/tests/e2e/pkg/validation"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
 )
 
 func main() {
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
+)
+
+const defaultMappingsFile = "./configs/validation/metric_mappings.yaml"
+
+// validate_mappings checks metric mapping files against the schema used by
+// the parity validation, printing every problem with its line. It exits 1
+// when any file is invalid, so it can run as a CI gate.
+func main() {
+	files := os.Args[1:]
+	if len(files) == 0 {
+		files = []string{defaultMappingsFile}
+	}
+
+	failed := false
+	for _, file := range files {
+		mappings, err := validation.LoadMetricMappings(file)
+		if err != nil {
+			failed = true
+			var errs validation.MappingErrors
+			if errors.As(err, &errs) {
+				fmt.Printf("❌ %s: %d problems\n", file, len(errs))
+				for _, e := range errs {
+					fmt.Printf("   %s\n", e)
+				}
+			} else {
+				fmt.Printf("❌ %v\n", err)
+			}
+			continue
+		}
+
+		fields := 0
+		for _, eventFields := range mappings.Fields {
+			fields += len(eventFields)
+		}
+		fmt.Printf("✅ %s: %d events, %d fields\n", file, len(mappings.Events), fields)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -3,16 +3,20 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
-	"github.com/database-intelligence/tests/e2e/framework"
-	"github.com/database-intelligence/tests/e2e/pkg/validation"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/pkg/validation"
 )
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
 	fmt.Println("=== OHI to OTEL Mapping Validation ===")
 	fmt.Println()
 
@@ -29,7 +33,14 @@ func main() {
 		log.Fatal("Missing required environment variables")
 	}
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+	fmt.Printf("Time window: %s\n\n", window)
+
 	nrdb := framework.NewNRDBClient(accountID, apiKey)
+	nrdb.SetTimeWindow(window)
 	ctx := context.Background()
 
 	// Parse dashboard to get OHI event requirements
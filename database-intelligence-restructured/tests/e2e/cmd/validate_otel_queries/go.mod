@@ -1,5 +0,0 @@
-module validate_otel_queries
-
-go 1.22
-
-require github.com/joho/godotenv v1.5.1
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 	"github.com/joho/godotenv"
 )
 
@@ -211,10 +213,18 @@ func executeNRQL(apiKey, accountID, nrqlQuery string) (json.RawMessage, error) {
 }
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
 	if err := loadEnv(); err != nil {
 		log.Printf("Warning: %v", err)
 	}
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
 	apiKey := os.Getenv("NEW_RELIC_API_KEY")
 	if apiKey == "" {
 		log.Fatal("NEW_RELIC_API_KEY environment variable is required")
@@ -227,6 +237,7 @@ func main() {
 
 	fmt.Println("🔍 Validating OpenTelemetry PostgreSQL Queries")
 	fmt.Printf("Account ID: %s\n", accountID)
+	fmt.Printf("Time window: %s\n", window)
 	fmt.Println(strings.Repeat("=", 80))
 
 	successCount := 0
@@ -235,9 +246,10 @@ func main() {
 	for i, test := range queries {
 		fmt.Printf("\n[%d/%d] %s\n", i+1, len(queries), test.Name)
 		fmt.Printf("Description: %s\n", test.Description)
-		fmt.Printf("Query: %s\n", test.Query)
+		query := window.Apply(test.Query)
+		fmt.Printf("Query: %s\n", query)
 		
-		results, err := executeNRQL(apiKey, accountID, test.Query)
+		results, err := executeNRQL(apiKey, accountID, query)
 		if err != nil {
 			fmt.Printf("❌ FAILED: %v\n", err)
 			failureCount++
@@ -3,12 +3,15 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 type GraphQLRequest struct {
@@ -31,6 +34,14 @@ type NRQLResponse struct {
 }
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+
 	fmt.Println("=== New Relic Connection Verification ===")
 	fmt.Println()
 
@@ -47,6 +58,7 @@ func main() {
 
 	fmt.Printf("Account ID: %s\n", accountID)
 	fmt.Printf("API Key: %s...%s (hidden)\n", apiKey[:4], apiKey[len(apiKey)-4:])
+	fmt.Printf("Time window: %s\n", window)
 	fmt.Println()
 
 	// Test NRDB connection
@@ -130,7 +142,7 @@ func main() {
 					}
 				}
 			}
-		}`, accountID, q.nrql)
+		}`, accountID, window.Apply(q.nrql))
 		
 		requestBody, _ := json.Marshal(GraphQLRequest{Query: query})
 		req, _ := http.NewRequest("POST", "https://api.newrelic.com/graphql", bytes.NewBuffer(requestBody))
@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
-	"github.com/database-intelligence/tests/e2e/framework"
+	"github.com/database-intelligence/db-intel/tests/e2e/framework"
 )
 
 func main() {
+	windowFlags := framework.AddTimeWindowFlags(flag.CommandLine)
+	flag.Parse()
+
 	fmt.Println("=== New Relic Connection Verification ===")
 	fmt.Println()
 
@@ -33,8 +37,15 @@ func main() {
 	}
 	fmt.Println()
 
+	window, err := windowFlags.Window()
+	if err != nil {
+		log.Fatalf("Invalid time window: %v", err)
+	}
+	fmt.Printf("Time window: %s\n\n", window)
+
 	// Create NRDB client
 	client := framework.NewNRDBClient(accountID, apiKey)
+	client.SetTimeWindow(window)
 
 	// Test query
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
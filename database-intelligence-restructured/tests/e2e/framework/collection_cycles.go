@@ -0,0 +1,113 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CollectionCycles is the schedule a receiver collects on: once every
+// Interval, at Anchor and every whole number of intervals before and after
+// it. A delta metric's data point at the end of a cycle carries the counter
+// change since the previous collection, so summing the data points of whole
+// cycles gives exactly the change between two collections. Taking the
+// database snapshots just after those two collections makes the snapshot
+// delta and the NRDB sum cover the same activity, without the off-by-one
+// cycle errors a fixed wait and a rolling SINCE window run into.
+type CollectionCycles struct {
+	Interval time.Duration
+	// Anchor is the time of any one collection, e.g. the timestamp of a
+	// data point the receiver reported
+	Anchor time.Time
+	// Margin is how long after a collection the snapshots are taken, to
+	// allow for the collection itself and clock skew. Zero means a tenth
+	// of the interval.
+	Margin time.Duration
+}
+
+// Validate checks the schedule
+func (c CollectionCycles) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("collection interval must be positive, got %v", c.Interval)
+	}
+	if c.Anchor.IsZero() {
+		return fmt.Errorf("collection anchor must be set")
+	}
+	if c.Margin < 0 || c.Margin >= c.Interval/2 {
+		return fmt.Errorf("collection margin must be at least 0 and less than half the interval, got %v", c.Margin)
+	}
+	return nil
+}
+
+func (c CollectionCycles) margin() time.Duration {
+	if c.Margin == 0 {
+		return c.Interval / 10
+	}
+	return c.Margin
+}
+
+// Checkpoint returns the last collection at or before t
+func (c CollectionCycles) Checkpoint(t time.Time) time.Time {
+	offset := t.Sub(c.Anchor) % c.Interval
+	if offset < 0 {
+		offset += c.Interval
+	}
+	return t.Add(-offset)
+}
+
+// Next returns the first collection at or after t
+func (c CollectionCycles) Next(t time.Time) time.Time {
+	checkpoint := c.Checkpoint(t)
+	if checkpoint.Equal(t) {
+		return t
+	}
+	return checkpoint.Add(c.Interval)
+}
+
+// SnapshotTime returns when to take the snapshot matching the collection
+// at checkpoint
+func (c CollectionCycles) SnapshotTime(checkpoint time.Time) time.Time {
+	return checkpoint.Add(c.margin())
+}
+
+// NextSnapshot returns the first collection whose snapshot time is at or
+// after t
+func (c CollectionCycles) NextSnapshot(t time.Time) time.Time {
+	return c.Next(t.Add(-c.margin()))
+}
+
+// Window returns the time window holding the data points of the cycles
+// after the collection at from, up to and including the collection at to.
+// The window runs from the middle of one cycle to the middle of another,
+// so data points timestamped a little after their collection still fall
+// into the right cycle.
+func (c CollectionCycles) Window(from, to time.Time) TimeWindow {
+	half := c.Interval / 2
+	return TimeWindow{
+		Since: strconv.FormatInt(from.Add(half).UnixMilli(), 10),
+		Until: strconv.FormatInt(to.Add(half).UnixMilli(), 10),
+	}
+}
+
+// Cycles returns the number of collections in (from, to]
+func (c CollectionCycles) Cycles(from, to time.Time) int {
+	return int(c.Checkpoint(to).Sub(c.Checkpoint(from)) / c.Interval)
+}
+
+// DiscoverCollectionCycles anchors the schedule of a receiver collecting
+// every interval to the latest data point of metric in NRDB
+func DiscoverCollectionCycles(ctx context.Context, nrdb NRQLQuerier, metric string, interval time.Duration) (CollectionCycles, error) {
+	nrql := fmt.Sprintf("SELECT latest(timestamp) AS last FROM Metric WHERE metricName = '%s' SINCE 30 minutes ago", metric)
+	result, err := nrdb.Query(ctx, nrql)
+	if err != nil {
+		return CollectionCycles{}, fmt.Errorf("failed to query NRDB: %w", err)
+	}
+	last, err := numericResult(result, "last")
+	if err != nil {
+		return CollectionCycles{}, fmt.Errorf("no recent %s data point to anchor the collection cycles: %w", metric, err)
+	}
+
+	cycles := CollectionCycles{Interval: interval, Anchor: time.UnixMilli(int64(last)).UTC()}
+	return cycles, cycles.Validate()
+}
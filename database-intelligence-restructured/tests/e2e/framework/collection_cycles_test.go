@@ -0,0 +1,127 @@
+package framework
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionCyclesCheckpoint(t *testing.T) {
+	// Collections at :07 past every minute
+	anchor := time.Date(2024, 5, 10, 12, 0, 7, 0, time.UTC)
+	cycles := CollectionCycles{Interval: time.Minute, Anchor: anchor}
+	at := func(m, s int) time.Time { return time.Date(2024, 5, 10, 12, m, s, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		t          time.Time
+		checkpoint time.Time
+		next       time.Time
+	}{
+		{"at the anchor", anchor, anchor, anchor},
+		{"within a cycle", at(3, 30), at(3, 7), at(4, 7)},
+		{"at a later collection", at(5, 7), at(5, 7), at(5, 7)},
+		{"just before a collection", at(5, 6), at(4, 7), at(5, 7)},
+		{"before the anchor", time.Date(2024, 5, 10, 11, 58, 0, 0, time.UTC), time.Date(2024, 5, 10, 11, 57, 7, 0, time.UTC), time.Date(2024, 5, 10, 11, 58, 7, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.checkpoint, cycles.Checkpoint(tt.t))
+			assert.Equal(t, tt.next, cycles.Next(tt.t))
+		})
+	}
+}
+
+func TestCollectionCyclesSnapshots(t *testing.T) {
+	anchor := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cycles := CollectionCycles{Interval: time.Minute, Anchor: anchor}
+
+	// The default margin is a tenth of the interval
+	assert.Equal(t, anchor.Add(6*time.Second), cycles.SnapshotTime(anchor))
+
+	// Until its snapshot time has passed, the last collection is still the
+	// one to snapshot after
+	assert.Equal(t, anchor, cycles.NextSnapshot(anchor.Add(5*time.Second)))
+	assert.Equal(t, anchor, cycles.NextSnapshot(anchor.Add(6*time.Second)))
+	assert.Equal(t, anchor.Add(time.Minute), cycles.NextSnapshot(anchor.Add(7*time.Second)))
+
+	cycles.Margin = 2 * time.Second
+	assert.Equal(t, anchor.Add(time.Minute), cycles.NextSnapshot(anchor.Add(5*time.Second)))
+}
+
+func TestCollectionCyclesWindow(t *testing.T) {
+	anchor := time.Date(2024, 5, 10, 12, 0, 7, 0, time.UTC)
+	cycles := CollectionCycles{Interval: time.Minute, Anchor: anchor}
+	from, to := anchor.Add(2*time.Minute), anchor.Add(5*time.Minute)
+
+	// The window holds the data points of the collections at 3, 4 and 5
+	// minutes past the anchor, even when they are stamped a little late
+	window := cycles.Window(from, to)
+	since, err := strconv.ParseInt(window.Since, 10, 64)
+	require.NoError(t, err)
+	until, err := strconv.ParseInt(window.Until, 10, 64)
+	require.NoError(t, err)
+
+	contains := func(ts time.Time) bool {
+		return ts.UnixMilli() >= since && ts.UnixMilli() < until
+	}
+	assert.False(t, contains(from), "the collection at from closes the previous cycle")
+	assert.False(t, contains(from.Add(2*time.Second)))
+	for i := 1; i <= 3; i++ {
+		collection := from.Add(time.Duration(i) * time.Minute)
+		assert.True(t, contains(collection), "collection %d", i)
+		assert.True(t, contains(collection.Add(2*time.Second)), "late collection %d", i)
+	}
+	assert.False(t, contains(to.Add(time.Minute)))
+	assert.Equal(t, 3, cycles.Cycles(from, to))
+	assert.Equal(t, 3, cycles.Cycles(from.Add(time.Second), to.Add(time.Second)))
+
+	assert.Equal(t, "SELECT sum(postgresql.commits) FROM Metric SINCE "+window.Since+" UNTIL "+window.Until,
+		window.Apply("SELECT sum(postgresql.commits) FROM Metric SINCE 5 minutes ago"))
+}
+
+func TestCollectionCyclesValidate(t *testing.T) {
+	anchor := time.Now()
+	tests := []struct {
+		name    string
+		cycles  CollectionCycles
+		wantErr string
+	}{
+		{"valid", CollectionCycles{Interval: time.Minute, Anchor: anchor}, ""},
+		{"explicit margin", CollectionCycles{Interval: time.Minute, Anchor: anchor, Margin: 29 * time.Second}, ""},
+		{"no interval", CollectionCycles{Anchor: anchor}, "collection interval must be positive"},
+		{"no anchor", CollectionCycles{Interval: time.Minute}, "collection anchor must be set"},
+		{"margin too large", CollectionCycles{Interval: time.Minute, Anchor: anchor, Margin: 30 * time.Second}, "less than half the interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cycles.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDiscoverCollectionCycles(t *testing.T) {
+	const nrql = "SELECT latest(timestamp) AS last FROM Metric WHERE metricName = 'postgresql.commits' SINCE 30 minutes ago"
+	last := time.Date(2024, 5, 10, 12, 0, 7, 250e6, time.UTC)
+	nrdb := &fakeNRDB{results: map[string]*NRQLResult{nrql: nrqlValue("last", float64(last.UnixMilli()))}}
+
+	cycles, err := DiscoverCollectionCycles(context.Background(), nrdb, "postgresql.commits", 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, CollectionCycles{Interval: 30 * time.Second, Anchor: last}, cycles)
+
+	nrdb.results[nrql] = nrqlValue("last", nil)
+	_, err = DiscoverCollectionCycles(context.Background(), nrdb, "postgresql.commits", 30*time.Second)
+	assert.ErrorContains(t, err, "no recent postgresql.commits data point")
+
+	_, err = DiscoverCollectionCycles(context.Background(), nrdb, "postgresql.rollbacks", 30*time.Second)
+	assert.ErrorContains(t, err, "failed to query NRDB")
+}
@@ -43,7 +43,7 @@ func NewDatabaseFactory(ctx context.Context) *DatabaseFactory {
 }
 
 // CreateDatabase creates a database container of the specified type
-func (f *DatabaseFactory) CreateDatabase(dbType DatabaseType, config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) CreateDatabase(dbType DatabaseType, config ContainerConfig) (*DatabaseContainer, error) {
 	switch dbType {
 	case DatabaseTypePostgreSQL:
 		return f.createPostgreSQL(config)
@@ -62,8 +62,8 @@ func (f *DatabaseFactory) CreateDatabase(dbType DatabaseType, config DatabaseCon
 	}
 }
 
-// DatabaseConfig configures database creation
-type DatabaseConfig struct {
+// ContainerConfig configures database container creation
+type ContainerConfig struct {
 	Version    string
 	InitScript string
 	Username   string
@@ -73,7 +73,7 @@ type DatabaseConfig struct {
 	Options map[string]interface{}
 }
 
-func (f *DatabaseFactory) createPostgreSQL(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createPostgreSQL(config ContainerConfig) (*DatabaseContainer, error) {
 	if config.Version == "" {
 		config.Version = "15-alpine"
 	}
@@ -139,7 +139,7 @@ func (f *DatabaseFactory) createPostgreSQL(config DatabaseConfig) (*DatabaseCont
 	}, nil
 }
 
-func (f *DatabaseFactory) createMySQL(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createMySQL(config ContainerConfig) (*DatabaseContainer, error) {
 	if config.Version == "" {
 		config.Version = "8.0"
 	}
@@ -204,7 +204,7 @@ func (f *DatabaseFactory) createMySQL(config DatabaseConfig) (*DatabaseContainer
 	}, nil
 }
 
-func (f *DatabaseFactory) createMongoDB(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createMongoDB(config ContainerConfig) (*DatabaseContainer, error) {
 	if config.Version == "" {
 		config.Version = "7.0"
 	}
@@ -284,7 +284,7 @@ func (f *DatabaseFactory) createMongoDB(config DatabaseConfig) (*DatabaseContain
 	}, nil
 }
 
-func (f *DatabaseFactory) createRedis(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createRedis(config ContainerConfig) (*DatabaseContainer, error) {
 	if config.Version == "" {
 		config.Version = "7-alpine"
 	}
@@ -335,12 +335,12 @@ func (f *DatabaseFactory) createRedis(config DatabaseConfig) (*DatabaseContainer
 	}, nil
 }
 
-func (f *DatabaseFactory) createOracle(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createOracle(config ContainerConfig) (*DatabaseContainer, error) {
 	// Oracle XE implementation
 	return nil, fmt.Errorf("Oracle support not yet implemented")
 }
 
-func (f *DatabaseFactory) createSQLServer(config DatabaseConfig) (*DatabaseContainer, error) {
+func (f *DatabaseFactory) createSQLServer(config ContainerConfig) (*DatabaseContainer, error) {
 	// SQL Server implementation
 	return nil, fmt.Errorf("SQL Server support not yet implemented")
 }
@@ -0,0 +1,271 @@
+package framework
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Environment variables holding the TLS settings of the test databases
+const (
+	PostgresSSLModeEnv  = "POSTGRES_SSLMODE"
+	PostgresRootCertEnv = "POSTGRES_SSLROOTCERT"
+	PostgresCertEnv     = "POSTGRES_SSLCERT"
+	PostgresKeyEnv      = "POSTGRES_SSLKEY"
+
+	MySQLSSLModeEnv = "MYSQL_SSL_MODE"
+	MySQLCAEnv      = "MYSQL_SSL_CA"
+	MySQLCertEnv    = "MYSQL_SSL_CERT"
+	MySQLKeyEnv     = "MYSQL_SSL_KEY"
+)
+
+// PostgresSSLModes are the libpq sslmode values
+var PostgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// MySQL TLS modes, named after the mysql client's --ssl-mode values
+const (
+	MySQLSSLDisabled       = "DISABLED"
+	MySQLSSLPreferred      = "PREFERRED"
+	MySQLSSLRequired       = "REQUIRED"
+	MySQLSSLVerifyCA       = "VERIFY_CA"
+	MySQLSSLVerifyIdentity = "VERIFY_IDENTITY"
+)
+
+// PostgresTLS holds the TLS settings of a PostgreSQL connection. The local
+// test databases have no TLS, so SSLMode defaults to disable; managed
+// services such as RDS and Cloud SQL need require or verify-full.
+type PostgresTLS struct {
+	SSLMode  string
+	RootCert string
+	Cert     string
+	Key      string
+}
+
+// PostgresTLSFromEnv reads the POSTGRES_SSL* variables
+func PostgresTLSFromEnv() PostgresTLS {
+	return PostgresTLS{
+		SSLMode:  getEnvOrDefault(PostgresSSLModeEnv, "disable"),
+		RootCert: os.Getenv(PostgresRootCertEnv),
+		Cert:     os.Getenv(PostgresCertEnv),
+		Key:      os.Getenv(PostgresKeyEnv),
+	}
+}
+
+// Validate checks the sslmode and that the certificate paths fit it
+func (t PostgresTLS) Validate() error {
+	valid := false
+	for _, mode := range PostgresSSLModes {
+		valid = valid || t.SSLMode == mode
+	}
+	if !valid {
+		return fmt.Errorf("invalid %s %q: expected one of %s", PostgresSSLModeEnv, t.SSLMode, strings.Join(PostgresSSLModes, ", "))
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return fmt.Errorf("%s and %s must be set together", PostgresCertEnv, PostgresKeyEnv)
+	}
+	if t.SSLMode == "disable" && (t.RootCert != "" || t.Cert != "") {
+		return fmt.Errorf("PostgreSQL certificates are set but %s is disable", PostgresSSLModeEnv)
+	}
+	return nil
+}
+
+// PostgresDSN builds a key/value connection string. Values are quoted as
+// libpq requires, so passwords and paths may contain spaces and quotes.
+func PostgresDSN(host string, port int, user, password, dbname string, t PostgresTLS) string {
+	params := []string{
+		"host", host,
+		"port", strconv.Itoa(port),
+		"user", user,
+		"password", password,
+		"dbname", dbname,
+		"sslmode", t.SSLMode,
+	}
+	if t.RootCert != "" {
+		params = append(params, "sslrootcert", t.RootCert)
+	}
+	if t.Cert != "" {
+		params = append(params, "sslcert", t.Cert, "sslkey", t.Key)
+	}
+
+	pairs := make([]string, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		pairs = append(pairs, params[i]+"="+quoteDSNValue(params[i+1]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// quoteDSNValue single-quotes a value that is empty or contains spaces,
+// quotes or backslashes
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " '\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// dsnPassword matches a quoted or unquoted password in a key/value DSN
+var dsnPassword = regexp.MustCompile(`\bpassword=('(?:[^'\\]|\\.)*'|\S*)`)
+
+// RedactDSN masks the password of a PostgreSQL URL or key/value DSN, or of
+// a MySQL DSN, for logging
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		return u.Redacted()
+	}
+	if dsnPassword.MatchString(dsn) {
+		return dsnPassword.ReplaceAllString(dsn, "password=***")
+	}
+	if cfg, err := mysql.ParseDSN(dsn); err == nil && cfg.Passwd != "" {
+		cfg.Passwd = "***"
+		return cfg.FormatDSN()
+	}
+	return dsn
+}
+
+// MySQLTLS holds the TLS settings of a MySQL connection, named after the
+// mysql client's --ssl-mode, --ssl-ca, --ssl-cert and --ssl-key options
+type MySQLTLS struct {
+	Mode string
+	CA   string
+	Cert string
+	Key  string
+}
+
+// MySQLTLSFromEnv reads the MYSQL_SSL_* variables
+func MySQLTLSFromEnv() MySQLTLS {
+	return MySQLTLS{
+		Mode: getEnvOrDefault(MySQLSSLModeEnv, MySQLSSLDisabled),
+		CA:   os.Getenv(MySQLCAEnv),
+		Cert: os.Getenv(MySQLCertEnv),
+		Key:  os.Getenv(MySQLKeyEnv),
+	}
+}
+
+// mode returns the upper-cased mode, DISABLED when empty
+func (t MySQLTLS) mode() string {
+	if t.Mode == "" {
+		return MySQLSSLDisabled
+	}
+	return strings.ToUpper(t.Mode)
+}
+
+// Validate checks the mode and that the certificate paths fit it
+func (t MySQLTLS) Validate() error {
+	switch t.mode() {
+	case MySQLSSLDisabled, MySQLSSLPreferred, MySQLSSLRequired, MySQLSSLVerifyCA, MySQLSSLVerifyIdentity:
+	default:
+		return fmt.Errorf("invalid %s %q: expected DISABLED, PREFERRED, REQUIRED, VERIFY_CA or VERIFY_IDENTITY", MySQLSSLModeEnv, t.Mode)
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return fmt.Errorf("%s and %s must be set together", MySQLCertEnv, MySQLKeyEnv)
+	}
+	if t.mode() == MySQLSSLDisabled && (t.CA != "" || t.Cert != "") {
+		return fmt.Errorf("MySQL certificates are set but %s is DISABLED", MySQLSSLModeEnv)
+	}
+	return nil
+}
+
+// MySQLConfig returns the driver configuration of a MySQL connection; open
+// it with mysql.NewConnector and sql.OpenDB. As with the mysql client,
+// REQUIRED with a CA verifies the server certificate like VERIFY_CA.
+func MySQLConfig(host string, port int, user, password, dbname string, t MySQLTLS) (*mysql.Config, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	cfg.DBName = dbname
+	cfg.ParseTime = true
+
+	mode := t.mode()
+	if mode == MySQLSSLRequired && t.CA != "" {
+		mode = MySQLSSLVerifyCA
+	}
+
+	// Without certificate files the driver's named configurations suffice
+	if t.CA == "" && t.Cert == "" {
+		switch mode {
+		case MySQLSSLDisabled:
+			cfg.TLSConfig = "false"
+			return cfg, nil
+		case MySQLSSLPreferred:
+			cfg.TLSConfig = "preferred"
+			return cfg, nil
+		case MySQLSSLRequired:
+			cfg.TLSConfig = "skip-verify"
+			return cfg, nil
+		}
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.CA != "" {
+		pem, err := os.ReadFile(t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MySQL CA: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in MySQL CA %s", t.CA)
+		}
+	}
+	if t.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MySQL client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch mode {
+	case MySQLSSLPreferred, MySQLSSLRequired:
+		tlsConfig.InsecureSkipVerify = true
+		cfg.AllowFallbackToPlaintext = mode == MySQLSSLPreferred
+	case MySQLSSLVerifyCA:
+		// Verify the chain but not the host name
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyChain(rawCerts, roots)
+		}
+	case MySQLSSLVerifyIdentity:
+		tlsConfig.ServerName = host
+	}
+	cfg.TLS = tlsConfig
+	return cfg, nil
+}
+
+// verifyChain verifies the server certificate chain against roots, or the
+// system roots when nil
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server sent no certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
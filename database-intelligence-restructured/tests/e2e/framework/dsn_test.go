@@ -0,0 +1,202 @@
+package framework
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		tls      PostgresTLS
+		want     string
+	}{
+		{
+			name:     "local",
+			password: "postgres",
+			tls:      PostgresTLS{SSLMode: "disable"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=disable",
+		},
+		{
+			name:     "require",
+			password: "postgres",
+			tls:      PostgresTLS{SSLMode: "require"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=require",
+		},
+		{
+			name:     "verify-full with client certificate",
+			password: "postgres",
+			tls:      PostgresTLS{SSLMode: "verify-full", RootCert: "/certs/ca.pem", Cert: "/certs/client.pem", Key: "/certs/client.key"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=verify-full sslrootcert=/certs/ca.pem sslcert=/certs/client.pem sslkey=/certs/client.key",
+		},
+		{
+			name:     "quoted password",
+			password: `it's a \secret`,
+			tls:      PostgresTLS{SSLMode: "prefer"},
+			want:     `host=db.example.com port=5432 user=app password='it\'s a \\secret' dbname=testdb sslmode=prefer`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.tls.Validate())
+			dsn := PostgresDSN("db.example.com", 5432, "app", tt.password, "testdb", tt.tls)
+			assert.Equal(t, tt.want, dsn)
+			assert.NotContains(t, RedactDSN(dsn), tt.password)
+		})
+	}
+}
+
+func TestPostgresTLSFromEnv(t *testing.T) {
+	t.Setenv(PostgresSSLModeEnv, "")
+	assert.Equal(t, PostgresTLS{SSLMode: "disable"}, PostgresTLSFromEnv())
+
+	t.Setenv(PostgresSSLModeEnv, "verify-full")
+	t.Setenv(PostgresRootCertEnv, "/etc/ssl/rds-ca.pem")
+	tls := PostgresTLSFromEnv()
+	require.NoError(t, tls.Validate())
+	assert.Equal(t, PostgresTLS{SSLMode: "verify-full", RootCert: "/etc/ssl/rds-ca.pem"}, tls)
+}
+
+func TestPostgresTLSValidate(t *testing.T) {
+	assert.ErrorContains(t, PostgresTLS{SSLMode: "on"}.Validate(), "invalid POSTGRES_SSLMODE")
+	assert.ErrorContains(t, PostgresTLS{SSLMode: "require", Key: "/certs/client.key"}.Validate(), "must be set together")
+	assert.ErrorContains(t, PostgresTLS{SSLMode: "disable", RootCert: "/certs/ca.pem"}.Validate(), "is disable")
+}
+
+func TestRedactDSN(t *testing.T) {
+	tests := map[string]string{
+		"host=db password=secret dbname=testdb":                "host=db password=*** dbname=testdb",
+		`host=db password='with \'quotes\' and spaces' x=1`:    "host=db password=*** x=1",
+		"postgres://app:secret@db:5432/testdb?sslmode=require": "postgres://app:xxxxx@db:5432/testdb?sslmode=require",
+		"app:secret@tcp(db:3306)/testdb?tls=skip-verify":       "app:***@tcp(db:3306)/testdb?tls=skip-verify",
+		"host=db dbname=testdb":                                "host=db dbname=testdb",
+	}
+	for dsn, want := range tests {
+		assert.Equal(t, want, RedactDSN(dsn), dsn)
+	}
+}
+
+func TestMySQLConfig(t *testing.T) {
+	t.Run("without certificates", func(t *testing.T) {
+		tests := map[string]string{
+			"":                "false",
+			"disabled":        "false",
+			MySQLSSLPreferred: "preferred",
+			MySQLSSLRequired:  "skip-verify",
+		}
+		for mode, want := range tests {
+			cfg, err := MySQLConfig("db.example.com", 3306, "app", "secret", "testdb", MySQLTLS{Mode: mode})
+			require.NoError(t, err, mode)
+			assert.Equal(t, want, cfg.TLSConfig, mode)
+			assert.Nil(t, cfg.TLS, mode)
+			assert.Equal(t, "db.example.com:3306", cfg.Addr)
+			assert.True(t, cfg.ParseTime)
+			assert.NotContains(t, RedactDSN(cfg.FormatDSN()), "secret")
+		}
+	})
+
+	ca, caFile := writeTestCA(t)
+	server := ca.issue(t, "other-host.example.com")
+
+	t.Run("verify identity", func(t *testing.T) {
+		cfg, err := MySQLConfig("db.example.com", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLVerifyIdentity, CA: caFile})
+		require.NoError(t, err)
+		require.NotNil(t, cfg.TLS)
+		assert.Equal(t, "db.example.com", cfg.TLS.ServerName)
+		assert.False(t, cfg.TLS.InsecureSkipVerify)
+		assert.NotNil(t, cfg.TLS.RootCAs)
+	})
+
+	t.Run("verify CA ignores the host name", func(t *testing.T) {
+		cfg, err := MySQLConfig("db.example.com", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLVerifyCA, CA: caFile})
+		require.NoError(t, err)
+		require.NotNil(t, cfg.TLS.VerifyPeerCertificate)
+		assert.NoError(t, cfg.TLS.VerifyPeerCertificate([][]byte{server}, nil))
+
+		other, _ := writeTestCA(t)
+		assert.Error(t, cfg.TLS.VerifyPeerCertificate([][]byte{other.issue(t, "db.example.com")}, nil))
+	})
+
+	t.Run("required with a CA verifies it", func(t *testing.T) {
+		cfg, err := MySQLConfig("db.example.com", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLRequired, CA: caFile})
+		require.NoError(t, err)
+		require.NotNil(t, cfg.TLS.VerifyPeerCertificate)
+		assert.NoError(t, cfg.TLS.VerifyPeerCertificate([][]byte{server}, nil))
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := MySQLConfig("db", 3306, "app", "secret", "testdb", MySQLTLS{Mode: "ON"})
+		assert.ErrorContains(t, err, "invalid MYSQL_SSL_MODE")
+		_, err = MySQLConfig("db", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLDisabled, CA: caFile})
+		assert.ErrorContains(t, err, "is DISABLED")
+		_, err = MySQLConfig("db", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLRequired, Cert: "/certs/client.pem"})
+		assert.ErrorContains(t, err, "must be set together")
+		_, err = MySQLConfig("db", 3306, "app", "secret", "testdb", MySQLTLS{Mode: MySQLSSLVerifyCA, CA: filepath.Join(t.TempDir(), "missing.pem")})
+		assert.ErrorContains(t, err, "failed to read MySQL CA")
+	})
+}
+
+// testCA is a self-signed CA for certificate verification tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// writeTestCA creates a CA and writes its certificate to a PEM file
+func writeTestCA(t *testing.T) (*testCA, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return &testCA{cert: cert, key: key}, path
+}
+
+// issue returns a DER server certificate for host signed by the CA
+func (ca *testCA) issue(t *testing.T, host string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return der
+}
@@ -0,0 +1,288 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/debugexporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/exporter/otlphttpexporter"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/database-intelligence/db-intel/components/processors"
+)
+
+// telemetryDefaults turns off the collector's own metrics server, so
+// collectors in one test process do not compete for its port. A config
+// that sets service.telemetry.metrics.level turns it back on.
+const telemetryDefaults = "yaml:service::telemetry::metrics::level: none"
+
+// InProcessCollector runs the collector inside the test process instead of
+// building and executing a binary. It has the Start, Stop, Restart and
+// GetLogs methods of TestCollector, and Start returns once every pipeline
+// is running rather than after polling the health endpoint.
+type InProcessCollector struct {
+	env       *TestEnvironment
+	factories func() (otelcol.Factories, error)
+
+	config    string
+	collector *otelcol.Collector
+	// done receives the result of Run once the collector has stopped
+	done    chan error
+	logFile *os.File
+}
+
+// NewInProcessCollector creates an in-process collector with the given
+// components; nil means DefaultFactories
+func NewInProcessCollector(env *TestEnvironment, factories func() (otelcol.Factories, error)) *InProcessCollector {
+	if factories == nil {
+		factories = DefaultFactories
+	}
+	return &InProcessCollector{
+		env:       env,
+		factories: factories,
+	}
+}
+
+// DefaultFactories returns the OTLP receiver, the debug and OTLP exporters,
+// the batch and memory limiter processors and this repository's processors
+func DefaultFactories() (otelcol.Factories, error) {
+	var factories otelcol.Factories
+	var err error
+
+	if factories.Extensions, err = extension.MakeFactoryMap(); err != nil {
+		return factories, err
+	}
+	if factories.Receivers, err = receiver.MakeFactoryMap(otlpreceiver.NewFactory()); err != nil {
+		return factories, err
+	}
+	if factories.Exporters, err = exporter.MakeFactoryMap(
+		debugexporter.NewFactory(),
+		otlpexporter.NewFactory(),
+		otlphttpexporter.NewFactory(),
+	); err != nil {
+		return factories, err
+	}
+	if factories.Processors, err = processor.MakeFactoryMap(
+		batchprocessor.NewFactory(),
+		memorylimiterprocessor.NewFactory(),
+	); err != nil {
+		return factories, err
+	}
+	for typ, factory := range processors.All() {
+		factories.Processors[typ] = factory
+	}
+	if factories.Connectors, err = connector.MakeFactoryMap(); err != nil {
+		return factories, err
+	}
+	return factories, nil
+}
+
+// Start runs the collector with the given YAML configuration and waits
+// until its pipelines are running. ${env:NAME} in the configuration reads
+// the database and New Relic settings of the test environment, or the
+// process environment for other names.
+func (c *InProcessCollector) Start(config string) error {
+	if c.collector != nil {
+		return errors.New("collector is already running")
+	}
+
+	logFile, err := os.Create(filepath.Join(c.env.TempDir, "collector.log"))
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	collector, err := otelcol.NewCollector(otelcol.CollectorSettings{
+		BuildInfo: component.BuildInfo{
+			Command:     "e2e-in-process-collector",
+			Description: "Database Intelligence Collector - in-process e2e instance",
+			Version:     "e2e",
+		},
+		Factories: c.factories,
+		ConfigProviderSettings: otelcol.ConfigProviderSettings{
+			ResolverSettings: confmap.ResolverSettings{
+				URIs: []string{telemetryDefaults, "yaml:" + config},
+				ProviderFactories: []confmap.ProviderFactory{
+					yamlprovider.NewFactory(),
+					fileprovider.NewFactory(),
+					newEnvProviderFactory(c.env.variables()),
+				},
+			},
+		},
+		// The test process keeps its own signal handling
+		DisableGracefulShutdown: true,
+		LoggingOptions: []zap.Option{
+			zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return zapcore.NewTee(core, zapcore.NewCore(
+					zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
+					zapcore.AddSync(logFile),
+					zapcore.DebugLevel,
+				))
+			}),
+		},
+		SkipSettingGRPCLogger: true,
+	})
+	if err != nil {
+		logFile.Close()
+		return fmt.Errorf("failed to create collector: %w", err)
+	}
+
+	c.config = config
+	c.collector = collector
+	c.logFile = logFile
+	c.done = make(chan error, 1)
+	go func() {
+		c.done <- collector.Run(context.Background())
+	}()
+
+	if err := c.waitRunning(30 * time.Second); err != nil {
+		c.Stop()
+		return fmt.Errorf("collector failed to start: %w", err)
+	}
+	return nil
+}
+
+// waitRunning waits until the collector is running or Run has returned
+func (c *InProcessCollector) waitRunning(timeout time.Duration) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-c.done:
+			// Leave the result for Stop
+			c.done <- err
+			if err == nil {
+				err = errors.New("collector stopped while starting")
+			}
+			return err
+		case <-deadline:
+			return fmt.Errorf("timeout after %v, collector is %v", timeout, c.collector.GetState())
+		case <-ticker.C:
+			if c.collector.GetState() == otelcol.StateRunning {
+				return nil
+			}
+		}
+	}
+}
+
+// Running reports whether the collector's pipelines are running
+func (c *InProcessCollector) Running() bool {
+	return c.collector != nil && c.collector.GetState() == otelcol.StateRunning
+}
+
+// Stop shuts the collector down, which flushes its pipelines, and returns
+// the error Run returned, if any
+func (c *InProcessCollector) Stop() error {
+	if c.collector == nil {
+		return nil
+	}
+
+	c.collector.Shutdown()
+	var err error
+	select {
+	case err = <-c.done:
+	case <-time.After(30 * time.Second):
+		err = errors.New("timeout waiting for collector shutdown")
+	}
+
+	c.collector = nil
+	if c.logFile != nil {
+		c.logFile.Close()
+	}
+	return err
+}
+
+// Restart stops the collector and starts it again with the same
+// configuration
+func (c *InProcessCollector) Restart() error {
+	if err := c.Stop(); err != nil {
+		return fmt.Errorf("failed to stop collector: %w", err)
+	}
+	return c.Start(c.config)
+}
+
+// GetLogs returns the collector logs
+func (c *InProcessCollector) GetLogs() (string, error) {
+	if c.logFile == nil {
+		return "", fmt.Errorf("no log file available")
+	}
+
+	content, err := os.ReadFile(c.logFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+	return string(content), nil
+}
+
+// variables returns the environment a collector started by TestCollector
+// gets, so configurations work with either
+func (env *TestEnvironment) variables() map[string]string {
+	return map[string]string{
+		"POSTGRES_HOST":           env.PostgresHost,
+		"POSTGRES_PORT":           fmt.Sprint(env.PostgresPort),
+		"POSTGRES_USER":           env.PostgresUser,
+		"POSTGRES_PASSWORD":       env.PostgresPassword,
+		"POSTGRES_DB":             env.PostgresDatabase,
+		"MYSQL_HOST":              env.MySQLHost,
+		"MYSQL_PORT":              fmt.Sprint(env.MySQLPort),
+		"MYSQL_USER":              env.MySQLUser,
+		"MYSQL_PASSWORD":          env.MySQLPassword,
+		"MYSQL_DB":                env.MySQLDatabase,
+		"NEW_RELIC_LICENSE_KEY":   env.NewRelicLicenseKey,
+		"NEW_RELIC_OTLP_ENDPOINT": env.NewRelicEndpoint,
+	}
+}
+
+// envProvider resolves ${env:NAME} from fixed variables before the process
+// environment, without changing the environment of the test process
+type envProvider struct {
+	variables map[string]string
+}
+
+func newEnvProviderFactory(variables map[string]string) confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+		return &envProvider{variables: variables}
+	})
+}
+
+func (p *envProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	name, ok := strings.CutPrefix(uri, p.Scheme()+":")
+	if !ok {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, p.Scheme())
+	}
+
+	value, ok := p.variables[name]
+	if !ok {
+		value = os.Getenv(name)
+	}
+	return confmap.NewRetrievedFromYAML([]byte(value))
+}
+
+func (*envProvider) Scheme() string {
+	return "env"
+}
+
+func (*envProvider) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+// sinkExporter keeps what the collector exports for the test to inspect
+type sinkExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*consumertest.MetricsSink
+}
+
+// withSink returns DefaultFactories plus a "sink" exporter writing to sink
+func withSink(sink *consumertest.MetricsSink) func() (otelcol.Factories, error) {
+	return func() (otelcol.Factories, error) {
+		factories, err := DefaultFactories()
+		if err != nil {
+			return factories, err
+		}
+		factories.Exporters[component.MustNewType("sink")] = exporter.NewFactory(
+			component.MustNewType("sink"),
+			func() component.Config { return &struct{}{} },
+			exporter.WithMetrics(func(context.Context, exporter.Settings, component.Config) (exporter.Metrics, error) {
+				return sinkExporter{MetricsSink: sink}, nil
+			}, component.StabilityLevelDevelopment),
+		)
+		return factories, nil
+	}
+}
+
+// freePort returns a local port nothing is listening on
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestInProcessCollector_StartPushStop(t *testing.T) {
+	env := &TestEnvironment{TempDir: t.TempDir(), PostgresHost: "127.0.0.1"}
+	sink := &consumertest.MetricsSink{}
+	collector := NewInProcessCollector(env, withSink(sink))
+
+	port := freePort(t)
+	// The endpoint host comes from the test environment, as in the
+	// collector configs under configs/
+	require.NoError(t, collector.Start(fmt.Sprintf(`
+receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: ${env:POSTGRES_HOST}:%d
+exporters:
+  sink:
+service:
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      exporters: [sink]
+`, port)))
+	require.True(t, collector.Running())
+	assert.Error(t, collector.Start(""), "a running collector cannot be started again")
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("postgresql.backends")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(7)
+	body, err := pmetricotlp.NewExportRequestFromMetrics(md).MarshalProto()
+	require.NoError(t, err)
+
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/v1/metrics", port), "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool { return sink.DataPointCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	got := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "postgresql.backends", got.Name())
+	assert.Equal(t, int64(7), got.Gauge().DataPoints().At(0).IntValue())
+
+	require.NoError(t, collector.Stop())
+	assert.False(t, collector.Running())
+	assert.NoError(t, collector.Stop(), "stopping twice is a no-op")
+
+	// The receiver has released its port
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	l.Close()
+
+	logs, err := collector.GetLogs()
+	require.NoError(t, err)
+	assert.Contains(t, logs, "Everything is ready")
+}
+
+func TestInProcessCollector_Restart(t *testing.T) {
+	env := &TestEnvironment{TempDir: t.TempDir()}
+	collector := NewInProcessCollector(env, withSink(&consumertest.MetricsSink{}))
+
+	require.NoError(t, collector.Start(fmt.Sprintf(`
+receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: 127.0.0.1:%d
+processors:
+  batch:
+exporters:
+  sink:
+service:
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [sink]
+`, freePort(t))))
+	require.NoError(t, collector.Restart())
+	assert.True(t, collector.Running())
+	require.NoError(t, collector.Stop())
+}
+
+func TestInProcessCollector_InvalidConfig(t *testing.T) {
+	env := &TestEnvironment{TempDir: t.TempDir()}
+	collector := NewInProcessCollector(env, nil)
+
+	err := collector.Start(`
+receivers:
+  otlp:
+    protocols:
+      http:
+exporters:
+  nosuchexporter:
+service:
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      exporters: [nosuchexporter]
+`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nosuchexporter")
+	assert.False(t, collector.Running())
+	assert.NoError(t, collector.Stop())
+}
@@ -0,0 +1,284 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// NRQLQuerier runs NRQL queries; NRDBClient implements it
+type NRQLQuerier interface {
+	Query(ctx context.Context, nrql string) (*NRQLResult, error)
+}
+
+// Snapshot holds database counter values, e.g. from pg_stat_database, keyed
+// by a name chosen by the test
+type Snapshot map[string]float64
+
+// MetricCheck compares one value queried from NRDB with the value expected
+// from the database snapshots
+type MetricCheck struct {
+	Name string
+	NRQL string
+
+	// Field is the result column holding the value. It can be left empty
+	// when the query returns a single column.
+	Field string
+
+	// Expected computes the expected NRDB value from the snapshots taken
+	// before the operation and after the collection wait
+	Expected func(before, after Snapshot) float64
+
+	// Tolerance is the allowed relative deviation, e.g. 0.01 for 1%.
+	// Zero requires an exact match.
+	Tolerance float64
+}
+
+// Delta expects the change of a snapshot counter
+func Delta(key string) func(before, after Snapshot) float64 {
+	return func(before, after Snapshot) float64 {
+		return after[key] - before[key]
+	}
+}
+
+// Latest expects the value of a snapshot counter after the collection wait
+func Latest(key string) func(before, after Snapshot) float64 {
+	return func(before, after Snapshot) float64 {
+		return after[key]
+	}
+}
+
+// MetricCheckResult is the outcome of one MetricCheck
+type MetricCheckResult struct {
+	Name      string
+	Expected  float64
+	Actual    float64
+	Deviation float64
+	Tolerance float64
+	Passed    bool
+	// Err is set when NRDB could not be queried or returned no value
+	Err error
+}
+
+func (r MetricCheckResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %v", r.Name, r.Err)
+	}
+	status := "ok"
+	if !r.Passed {
+		status = "FAILED"
+	}
+	if r.Tolerance == 0 {
+		return fmt.Sprintf("%s: %s (expected exactly %g, got %g)", r.Name, status, r.Expected, r.Actual)
+	}
+	return fmt.Sprintf("%s: %s (expected %g, got %g, deviation %.2f%%, tolerance %.2f%%)",
+		r.Name, status, r.Expected, r.Actual, r.Deviation*100, r.Tolerance*100)
+}
+
+// MetricVerifier checks that the collector reports database counter changes
+// accurately: it snapshots the counters, runs an operation, waits for the
+// metrics to reach NRDB and compares the NRDB values with the snapshots
+type MetricVerifier struct {
+	NRDB NRQLQuerier
+
+	// Snapshot reads the database counters the checks refer to
+	Snapshot func(ctx context.Context) (Snapshot, error)
+
+	// Operation generates the activity to verify; it may be nil
+	Operation func(ctx context.Context) error
+
+	// CollectionWait covers the collection interval plus export and
+	// ingestion delay. With Cycles set it only covers the export and
+	// ingestion delay.
+	CollectionWait time.Duration
+
+	// Cycles, when set, compares the database and NRDB since the last
+	// checkpoint: the snapshots are taken just after a collection, and
+	// the SINCE and UNTIL clauses of every check are replaced by the
+	// cycles between the two collections
+	Cycles *CollectionCycles
+
+	Checks []MetricCheck
+}
+
+// Run performs the verification and returns one result per check. An error
+// is only returned when the snapshots or the operation fail; check failures
+// are reported in the results.
+func (v *MetricVerifier) Run(ctx context.Context) ([]MetricCheckResult, error) {
+	if v.Cycles != nil {
+		if err := v.Cycles.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var from time.Time
+	if v.Cycles != nil {
+		from = v.Cycles.NextSnapshot(time.Now())
+		if err := v.waitForSnapshot(ctx, from); err != nil {
+			return nil, err
+		}
+	}
+	before, err := v.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take baseline snapshot: %w", err)
+	}
+
+	if v.Operation != nil {
+		if err := v.Operation(ctx); err != nil {
+			return nil, fmt.Errorf("operation failed: %w", err)
+		}
+	}
+
+	if v.Cycles != nil {
+		// The collection closing the window must come after the operation
+		to := v.Cycles.Next(time.Now())
+		if err := v.waitForSnapshot(ctx, to); err != nil {
+			return nil, err
+		}
+		after, err := v.Snapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take snapshot after collection: %w", err)
+		}
+
+		// NRDB has to have the data points up to the end of the window
+		wait := max(v.CollectionWait, time.Until(to.Add(v.Cycles.Interval/2)))
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		return v.runChecks(ctx, v.Cycles.Window(from, to), before, after), nil
+	}
+
+	if err := sleep(ctx, v.CollectionWait); err != nil {
+		return nil, err
+	}
+	after, err := v.Snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take snapshot after collection: %w", err)
+	}
+	return v.runChecks(ctx, TimeWindow{}, before, after), nil
+}
+
+// waitForSnapshot waits until the snapshot time of the collection at
+// checkpoint
+func (v *MetricVerifier) waitForSnapshot(ctx context.Context, checkpoint time.Time) error {
+	return sleep(ctx, time.Until(v.Cycles.SnapshotTime(checkpoint)))
+}
+
+// runChecks runs every check over window, or over its own time range when
+// window is zero
+func (v *MetricVerifier) runChecks(ctx context.Context, window TimeWindow, before, after Snapshot) []MetricCheckResult {
+	results := make([]MetricCheckResult, 0, len(v.Checks))
+	for _, check := range v.Checks {
+		check.NRQL = window.Apply(check.NRQL)
+		results = append(results, v.runCheck(ctx, check, before, after))
+	}
+	return results
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (v *MetricVerifier) runCheck(ctx context.Context, check MetricCheck, before, after Snapshot) MetricCheckResult {
+	result := MetricCheckResult{
+		Name:      check.Name,
+		Expected:  check.Expected(before, after),
+		Tolerance: check.Tolerance,
+	}
+
+	nrql, err := v.NRDB.Query(ctx, check.NRQL)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to query NRDB: %w", err)
+		return result
+	}
+
+	result.Actual, err = numericResult(nrql, check.Field)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Deviation, result.Passed = withinTolerance(result.Expected, result.Actual, check.Tolerance)
+	return result
+}
+
+// AllPassed reports whether every check passed
+func AllPassed(results []MetricCheckResult) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// withinTolerance returns the relative deviation of actual from expected
+// and whether it is within tolerance. With a zero tolerance the values must
+// be equal. An expected value of zero only matches zero, since there is no
+// relative deviation from it.
+func withinTolerance(expected, actual, tolerance float64) (float64, bool) {
+	if actual == expected {
+		return 0, true
+	}
+	if expected == 0 {
+		return math.Inf(1), false
+	}
+
+	deviation := math.Abs(actual-expected) / math.Abs(expected)
+	if tolerance == 0 {
+		return deviation, false
+	}
+	return deviation, deviation <= tolerance
+}
+
+// numericResult reads a number from the first row of an NRQL result
+func numericResult(result *NRQLResult, field string) (float64, error) {
+	if result == nil || len(result.Results) == 0 {
+		return 0, fmt.Errorf("NRDB returned no results")
+	}
+	row := result.Results[0]
+
+	if field == "" {
+		if len(row) != 1 {
+			return 0, fmt.Errorf("NRDB returned %d columns, the check must name a field", len(row))
+		}
+		for name := range row {
+			field = name
+		}
+	}
+
+	value, ok := row[field]
+	if !ok || value == nil {
+		return 0, fmt.Errorf("NRDB result has no value for %q", field)
+	}
+	return numberValue(field, value)
+}
+
+// numberValue converts a decoded NRDB value to a number
+func numberValue(field string, value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	default:
+		return 0, fmt.Errorf("NRDB value for %q is %T, not a number", field, value)
+	}
+}
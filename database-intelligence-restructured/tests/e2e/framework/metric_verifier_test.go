@@ -0,0 +1,233 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNRDB answers NRQL queries with fixed results
+type fakeNRDB struct {
+	results map[string]*NRQLResult
+	queries []string
+}
+
+func (f *fakeNRDB) Query(ctx context.Context, nrql string) (*NRQLResult, error) {
+	f.queries = append(f.queries, nrql)
+	result, ok := f.results[nrql]
+	if !ok {
+		return nil, errors.New("NRDB query errors: unknown query")
+	}
+	return result, nil
+}
+
+func nrqlValue(field string, value interface{}) *NRQLResult {
+	return &NRQLResult{Results: []map[string]interface{}{{field: value}}}
+}
+
+func TestWithinTolerance(t *testing.T) {
+	tests := []struct {
+		name          string
+		expected      float64
+		actual        float64
+		tolerance     float64
+		wantDeviation float64
+		wantPassed    bool
+	}{
+		{"exact match", 100, 100, 0, 0, true},
+		{"exact mismatch", 100, 101, 0, 0.01, false},
+		{"within tolerance", 100, 105, 0.1, 0.05, true},
+		{"below expected within tolerance", 100, 92, 0.1, 0.08, true},
+		{"at tolerance", 100, 110, 0.1, 0.1, true},
+		{"outside tolerance", 100, 111, 0.1, 0.11, false},
+		{"negative expected", -50, -55, 0.1, 0.1, true},
+		{"zero expected and actual", 0, 0, 0.1, 0, true},
+		{"zero expected", 0, 1, 0.1, math.Inf(1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviation, passed := withinTolerance(tt.expected, tt.actual, tt.tolerance)
+			assert.Equal(t, tt.wantPassed, passed)
+			if math.IsInf(tt.wantDeviation, 1) {
+				assert.True(t, math.IsInf(deviation, 1))
+				return
+			}
+			assert.InDelta(t, tt.wantDeviation, deviation, 1e-9)
+		})
+	}
+}
+
+func TestNumericResult(t *testing.T) {
+	value, err := numericResult(nrqlValue("sum.postgresql.commits", 42.0), "")
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, value)
+
+	value, err = numericResult(&NRQLResult{Results: []map[string]interface{}{{"avg": 1.5, "max": 3.0}}}, "max")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, value)
+
+	_, err = numericResult(&NRQLResult{Results: []map[string]interface{}{{"avg": 1.5, "max": 3.0}}}, "")
+	assert.EqualError(t, err, "NRDB returned 2 columns, the check must name a field")
+
+	_, err = numericResult(&NRQLResult{}, "value")
+	assert.EqualError(t, err, "NRDB returned no results")
+
+	_, err = numericResult(nrqlValue("value", nil), "value")
+	assert.EqualError(t, err, `NRDB result has no value for "value"`)
+
+	_, err = numericResult(nrqlValue("value", "12"), "value")
+	assert.EqualError(t, err, `NRDB value for "value" is string, not a number`)
+}
+
+func TestMetricVerifierRun(t *testing.T) {
+	const (
+		commitsNRQL     = "SELECT sum(postgresql.commits) AS commits FROM Metric SINCE 5 minutes ago"
+		rowsNRQL        = "SELECT sum(postgresql.rows_fetched) FROM Metric SINCE 5 minutes ago"
+		connectionsNRQL = "SELECT latest(postgresql.backends) AS backends FROM Metric SINCE 5 minutes ago"
+		missingNRQL     = "SELECT sum(postgresql.deadlocks) FROM Metric SINCE 5 minutes ago"
+	)
+	nrdb := &fakeNRDB{results: map[string]*NRQLResult{
+		commitsNRQL:     nrqlValue("commits", 250.0),
+		rowsNRQL:        nrqlValue("sum.postgresql.rows_fetched", 1040.0),
+		connectionsNRQL: nrqlValue("backends", 7.0),
+	}}
+
+	snapshots := []Snapshot{
+		{"commits": 1000, "rows_fetched": 5000, "backends": 3},
+		{"commits": 1250, "rows_fetched": 6000, "backends": 6},
+	}
+	var steps []string
+	verifier := &MetricVerifier{
+		NRDB: nrdb,
+		Snapshot: func(ctx context.Context) (Snapshot, error) {
+			steps = append(steps, "snapshot")
+			snapshot := snapshots[0]
+			snapshots = snapshots[1:]
+			return snapshot, nil
+		},
+		Operation: func(ctx context.Context) error {
+			steps = append(steps, "operation")
+			return nil
+		},
+		CollectionWait: time.Millisecond,
+		Checks: []MetricCheck{
+			{Name: "commits", NRQL: commitsNRQL, Field: "commits", Expected: Delta("commits")},
+			{Name: "rows fetched", NRQL: rowsNRQL, Expected: Delta("rows_fetched"), Tolerance: 0.05},
+			{Name: "backends", NRQL: connectionsNRQL, Field: "backends", Expected: Latest("backends"), Tolerance: 0.1},
+			{Name: "deadlocks", NRQL: missingNRQL, Expected: Delta("deadlocks")},
+		},
+	}
+
+	results, err := verifier.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snapshot", "operation", "snapshot"}, steps)
+	assert.Equal(t, []string{commitsNRQL, rowsNRQL, connectionsNRQL, missingNRQL}, nrdb.queries)
+	require.Len(t, results, 4)
+
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, 250.0, results[0].Expected)
+	assert.Equal(t, "commits: ok (expected exactly 250, got 250)", results[0].String())
+
+	assert.True(t, results[1].Passed)
+	assert.InDelta(t, 0.04, results[1].Deviation, 1e-9)
+
+	assert.False(t, results[2].Passed)
+	assert.Equal(t, "backends: FAILED (expected 6, got 7, deviation 16.67%, tolerance 10.00%)", results[2].String())
+
+	assert.False(t, results[3].Passed)
+	assert.ErrorContains(t, results[3].Err, "failed to query NRDB")
+
+	assert.False(t, AllPassed(results))
+	assert.True(t, AllPassed(results[:2]))
+}
+
+func TestMetricVerifierRunErrors(t *testing.T) {
+	snapshotErr := errors.New("connection refused")
+	verifier := &MetricVerifier{
+		NRDB: &fakeNRDB{},
+		Snapshot: func(ctx context.Context) (Snapshot, error) {
+			return nil, snapshotErr
+		},
+	}
+	_, err := verifier.Run(context.Background())
+	assert.ErrorIs(t, err, snapshotErr)
+
+	verifier.Snapshot = func(ctx context.Context) (Snapshot, error) { return Snapshot{}, nil }
+	verifier.Operation = func(ctx context.Context) error { return errors.New("insert failed") }
+	_, err = verifier.Run(context.Background())
+	assert.EqualError(t, err, "operation failed: insert failed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	verifier.Operation = nil
+	verifier.CollectionWait = time.Hour
+	_, err = verifier.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// querierFunc answers NRQL queries with a function
+type querierFunc func(ctx context.Context, nrql string) (*NRQLResult, error)
+
+func (f querierFunc) Query(ctx context.Context, nrql string) (*NRQLResult, error) {
+	return f(ctx, nrql)
+}
+
+func TestMetricVerifierRunSinceCheckpoint(t *testing.T) {
+	cycles := &CollectionCycles{Interval: 40 * time.Millisecond, Anchor: time.Now().Add(-time.Hour), Margin: 5 * time.Millisecond}
+
+	var snapshots []time.Time
+	var queries []string
+	verifier := &MetricVerifier{
+		NRDB: querierFunc(func(ctx context.Context, nrql string) (*NRQLResult, error) {
+			queries = append(queries, nrql)
+			return nrqlValue("commits", 10.0), nil
+		}),
+		Snapshot: func(ctx context.Context) (Snapshot, error) {
+			snapshots = append(snapshots, time.Now())
+			return Snapshot{"commits": float64(10 * len(snapshots))}, nil
+		},
+		Operation: func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+		Cycles: cycles,
+		Checks: []MetricCheck{{
+			Name:     "commits",
+			NRQL:     "SELECT sum(postgresql.commits) AS commits FROM Metric SINCE 5 minutes ago",
+			Field:    "commits",
+			Expected: Delta("commits"),
+		}},
+	}
+
+	results, err := verifier.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed, results[0].String())
+
+	// Each snapshot follows a collection by the margin
+	require.Len(t, snapshots, 2)
+	from, to := cycles.Checkpoint(snapshots[0]), cycles.Checkpoint(snapshots[1])
+	for i, snapshot := range snapshots {
+		assert.GreaterOrEqual(t, snapshot.Sub(cycles.Checkpoint(snapshot)), cycles.Margin, "snapshot %d", i)
+	}
+	assert.GreaterOrEqual(t, cycles.Cycles(from, to), 2, "the operation spans more than a cycle")
+
+	// The check ran over exactly the cycles between the snapshots
+	window := cycles.Window(from, to)
+	assert.Equal(t, []string{"SELECT sum(postgresql.commits) AS commits FROM Metric SINCE " + window.Since + " UNTIL " + window.Until}, queries)
+}
+
+func TestMetricVerifierRunInvalidCycles(t *testing.T) {
+	verifier := &MetricVerifier{
+		NRDB:   &fakeNRDB{},
+		Cycles: &CollectionCycles{Anchor: time.Now()},
+	}
+	_, err := verifier.Run(context.Background())
+	assert.EqualError(t, err, "collection interval must be positive, got 0s")
+}
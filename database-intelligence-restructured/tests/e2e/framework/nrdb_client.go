@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,7 @@ type NRDBClient struct {
 	apiKey     string
 	endpoint   string
 	httpClient *http.Client
+	window     TimeWindow
 }
 
 // NewNRDBClient creates a new NRDB client
@@ -32,24 +34,98 @@ func NewNRDBClient(accountID, apiKey string) *NRDBClient {
 
 // NRQLResult represents the result of an NRQL query
 type NRQLResult struct {
-	Results []map[string]interface{} `json:"results"`
-	Facets  []string                 `json:"facets"`
-	Total   int                      `json:"total"`
+	Results  []map[string]interface{} `json:"results"`
+	Facets   []string                 `json:"facets"`
+	Total    int                      `json:"total"`
+	Metadata *NRQLMetadata            `json:"metadata"`
+}
+
+// NRQLMetadata describes how NRDB ran a query
+type NRQLMetadata struct {
+	// Facets are the attributes the results are faceted by
+	Facets     []string        `json:"facets"`
+	TimeWindow *NRQLTimeWindow `json:"timeWindow"`
+}
+
+// NRQLTimeWindow is the time range a query covered, in epoch milliseconds
+type NRQLTimeWindow struct {
+	Begin int64 `json:"begin"`
+	End   int64 `json:"end"`
+}
+
+// SetTimeWindow makes every query run over w instead of its own SINCE and
+// UNTIL clauses
+func (c *NRDBClient) SetTimeWindow(w TimeWindow) {
+	c.window = w
 }
 
 // Query executes an NRQL query against NRDB
 func (c *NRDBClient) Query(ctx context.Context, nrql string) (*NRQLResult, error) {
+	var account struct {
+		NRQL NRQLResult `json:"nrql"`
+	}
+	if err := c.post(ctx, nrqlField("nrql", c.window.Apply(nrql)), &account); err != nil {
+		return nil, err
+	}
+	return &account.NRQL, nil
+}
+
+// QueryBatch executes several NRQL queries in one NerdGraph request and
+// returns their results in the same order. A report that needs one query per
+// metric or attribute pays for a single round trip instead of one each.
+func (c *NRDBClient) QueryBatch(ctx context.Context, nrqls []string) ([]*NRQLResult, error) {
+	if len(nrqls) == 0 {
+		return nil, nil
+	}
+
+	fields := make([]string, len(nrqls))
+	for i, nrql := range nrqls {
+		fields[i] = nrqlField(fmt.Sprintf("q%d: nrql", i), c.window.Apply(nrql))
+	}
+
+	var account map[string]*NRQLResult
+	if err := c.post(ctx, strings.Join(fields, ""), &account); err != nil {
+		return nil, err
+	}
+
+	results := make([]*NRQLResult, len(nrqls))
+	for i := range nrqls {
+		result, ok := account[fmt.Sprintf("q%d", i)]
+		if !ok || result == nil {
+			return nil, fmt.Errorf("NRDB returned no result for query %d: %s", i, nrqls[i])
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// nrqlField returns the NerdGraph selection running nrql, under field, which
+// may carry an alias
+func nrqlField(field, nrql string) string {
+	return fmt.Sprintf(`
+					%s(query: "%s") {
+						results
+						metadata {
+							facets
+							timeWindow {
+								begin
+								end
+							}
+						}
+					}`, field, nrql)
+}
+
+// post runs the NRQL fields against the account and decodes the account
+// object of the response into account
+func (c *NRDBClient) post(ctx context.Context, fields string, account interface{}) error {
 	query := fmt.Sprintf(`
 		{
 			actor {
-				account(id: %s) {
-					nrql(query: "%s") {
-						results
-					}
+				account(id: %s) {%s
 				}
 			}
 		}
-	`, c.accountID, nrql)
+	`, c.accountID, fields)
 	
 	requestBody := map[string]string{
 		"query": query,
@@ -57,12 +133,12 @@ func (c *NRDBClient) Query(ctx context.Context, nrql string) (*NRQLResult, error
 	
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 	
 	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
@@ -70,21 +146,19 @@ func (c *NRDBClient) Query(ctx context.Context, nrql string) (*NRQLResult, error
 	
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("NRDB query failed with status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("NRDB query failed with status %d: %s", resp.StatusCode, string(body))
 	}
 	
 	var response struct {
 		Data struct {
 			Actor struct {
-				Account struct {
-					NRQL NRQLResult `json:"nrql"`
-				} `json:"account"`
+				Account json.RawMessage `json:"account"`
 			} `json:"actor"`
 		} `json:"data"`
 		Errors []struct {
@@ -93,14 +167,20 @@ func (c *NRDBClient) Query(ctx context.Context, nrql string) (*NRQLResult, error
 	}
 	
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 	
 	if len(response.Errors) > 0 {
-		return nil, fmt.Errorf("NRDB query errors: %v", response.Errors)
+		return fmt.Errorf("NRDB query errors: %v", response.Errors)
 	}
 	
-	return &response.Data.Actor.Account.NRQL, nil
+	if len(response.Data.Actor.Account) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Data.Actor.Account, account); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
 
 // WaitForData waits for data to appear in NRDB
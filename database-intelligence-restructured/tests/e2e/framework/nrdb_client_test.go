@@ -0,0 +1,62 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNRDBClient_QueryBatch(t *testing.T) {
+	var graphQL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		graphQL = body["query"]
+		io.WriteString(w, `{"data": {"actor": {"account": {
+			"q1": {"results": [{"count": 7}], "metadata": {"facets": null}},
+			"q0": {"results": [{"facet": "postgresql.commits", "count": 42}], "metadata": {"facets": ["metricName"]}}
+		}}}}`)
+	}))
+	defer server.Close()
+
+	client := NewNRDBClient("12345", "test-key")
+	client.endpoint = server.URL
+	window, err := ParseTimeWindow("2 hours ago", "")
+	require.NoError(t, err)
+	client.SetTimeWindow(window)
+
+	results, err := client.QueryBatch(context.Background(), []string{
+		"SELECT count(*) FROM Metric FACET metricName",
+		"SELECT count(*) FROM Metric SINCE 1 hour ago",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, graphQL, `q0: nrql(query: "SELECT count(*) FROM Metric FACET metricName SINCE 2 hours ago")`)
+	assert.Contains(t, graphQL, `q1: nrql(query: "SELECT count(*) FROM Metric SINCE 2 hours ago")`)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"metricName"}, results[0].Metadata.Facets)
+	assert.Equal(t, float64(42), results[0].Results[0]["count"])
+	assert.Equal(t, float64(7), results[1].Results[0]["count"])
+}
+
+func TestNRDBClient_QueryBatchMissingResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data": {"actor": {"account": {"q0": {"results": []}}}}}`)
+	}))
+	defer server.Close()
+
+	client := NewNRDBClient("12345", "test-key")
+	client.endpoint = server.URL
+	_, err := client.QueryBatch(context.Background(), []string{
+		"SELECT count(*) FROM Metric",
+		"SELECT uniqueCount(db.name) FROM Metric",
+	})
+	assert.EqualError(t, err, "NRDB returned no result for query 1: SELECT uniqueCount(db.name) FROM Metric")
+}
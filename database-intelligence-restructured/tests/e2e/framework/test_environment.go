@@ -9,7 +9,10 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/database-intelligence/db-intel/internal/newrelic"
+	"github.com/database-intelligence/db-intel/internal/secrets"
 )
 
 // TestEnvironment represents the complete test environment
@@ -24,6 +27,7 @@ type TestEnvironment struct {
 	PostgresUser     string
 	PostgresPassword string
 	PostgresDatabase string
+	PostgresTLS      PostgresTLS
 	
 	MySQLHost     string
 	MySQLPort     int
@@ -31,11 +35,14 @@ type TestEnvironment struct {
 	MySQLPassword string
 	MySQLDatabase string
 	MySQLEnabled  bool
+	MySQLTLS      MySQLTLS
 	
 	// New Relic configuration
 	NewRelicAccountID string
 	NewRelicAPIKey    string
 	NewRelicLicenseKey string
+	// NewRelicEndpoint is resolved by Initialize from NEW_RELIC_OTLP_ENDPOINT
+	// or NEW_RELIC_REGION
 	NewRelicEndpoint  string
 	
 	// Collector configuration
@@ -46,6 +53,10 @@ type TestEnvironment struct {
 	// Test data
 	TestDataPath string
 	TempDir      string
+	
+	// TestRunID tags the data of one run (test_run_id in the collector
+	// configs) so NRDB queries only see that run
+	TestRunID string
 }
 
 // NewTestEnvironment creates a new test environment from environment variables
@@ -57,6 +68,7 @@ func NewTestEnvironment() *TestEnvironment {
 		PostgresUser:     getEnvOrDefault("POSTGRES_USER", "postgres"),
 		PostgresPassword: getEnvOrDefault("POSTGRES_PASSWORD", "postgres"),
 		PostgresDatabase: getEnvOrDefault("POSTGRES_DB", "testdb"),
+		PostgresTLS:      PostgresTLSFromEnv(),
 		
 		// MySQL defaults
 		MySQLHost:     getEnvOrDefault("MYSQL_HOST", "localhost"),
@@ -65,12 +77,12 @@ func NewTestEnvironment() *TestEnvironment {
 		MySQLPassword: getEnvOrDefault("MYSQL_PASSWORD", "root"),
 		MySQLDatabase: getEnvOrDefault("MYSQL_DB", "testdb"),
 		MySQLEnabled:  getEnvOrDefaultBool("MYSQL_ENABLED", true),
+		MySQLTLS:      MySQLTLSFromEnv(),
 		
 		// New Relic configuration
 		NewRelicAccountID:  os.Getenv("NEW_RELIC_ACCOUNT_ID"),
 		NewRelicAPIKey:     os.Getenv("NEW_RELIC_API_KEY"),
-		NewRelicLicenseKey: os.Getenv("NEW_RELIC_LICENSE_KEY"),
-		NewRelicEndpoint:   getEnvOrDefault("NEW_RELIC_OTLP_ENDPOINT", "otlp.nr-data.net:4317"),
+		NewRelicLicenseKey: os.Getenv(newrelic.LicenseKeyEnv),
 		
 		// Collector endpoints
 		CollectorEndpoint: getEnvOrDefault("COLLECTOR_ENDPOINT", "localhost:4317"),
@@ -80,6 +92,8 @@ func NewTestEnvironment() *TestEnvironment {
 		// Test data
 		TestDataPath: getEnvOrDefault("TEST_DATA_PATH", "./testdata"),
 		TempDir:      getEnvOrDefault("TEST_TEMP_DIR", "/tmp/db-intelligence-e2e"),
+		
+		TestRunID: getEnvOrDefault("TEST_RUN_ID", fmt.Sprintf("e2e_test_%d", time.Now().Unix())),
 	}
 	
 	return env
@@ -87,14 +101,31 @@ func NewTestEnvironment() *TestEnvironment {
 
 // Initialize sets up the test environment
 func (env *TestEnvironment) Initialize() error {
+	// Resolve the New Relic endpoint for the account's region
+	if env.NewRelicEndpoint == "" {
+		endpoint, err := newrelic.EndpointFromEnv(newrelic.ProtocolGRPC)
+		if err != nil {
+			return fmt.Errorf("failed to resolve New Relic endpoint: %w", err)
+		}
+		env.NewRelicEndpoint = endpoint
+	}
+	
+	// Credentials may be secretref:// references to a secret store
+	if err := env.resolveSecrets(context.Background()); err != nil {
+		return err
+	}
+	
 	// Create temp directory
 	if err := os.MkdirAll(env.TempDir, 0755); err != nil {
 		return fmt.Errorf("failed to create temp dir: %w", err)
 	}
 	
 	// Connect to PostgreSQL
-	pgDSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		env.PostgresHost, env.PostgresPort, env.PostgresUser, env.PostgresPassword, env.PostgresDatabase)
+	if err := env.PostgresTLS.Validate(); err != nil {
+		return err
+	}
+	pgDSN := PostgresDSN(env.PostgresHost, env.PostgresPort, env.PostgresUser, env.PostgresPassword,
+		env.PostgresDatabase, env.PostgresTLS)
 	
 	var err error
 	env.PostgresDB, err = sql.Open("postgres", pgDSN)
@@ -108,13 +139,17 @@ func (env *TestEnvironment) Initialize() error {
 	
 	// Connect to MySQL if enabled
 	if env.MySQLEnabled {
-		mysqlDSN := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
-			env.MySQLUser, env.MySQLPassword, env.MySQLHost, env.MySQLPort, env.MySQLDatabase)
+		mysqlConfig, err := MySQLConfig(env.MySQLHost, env.MySQLPort, env.MySQLUser, env.MySQLPassword,
+			env.MySQLDatabase, env.MySQLTLS)
+		if err != nil {
+			return err
+		}
 		
-		env.MySQLDB, err = sql.Open("mysql", mysqlDSN)
+		connector, err := mysql.NewConnector(mysqlConfig)
 		if err != nil {
 			return fmt.Errorf("failed to connect to MySQL: %w", err)
 		}
+		env.MySQLDB = sql.OpenDB(connector)
 		
 		if err := env.MySQLDB.Ping(); err != nil {
 			return fmt.Errorf("failed to ping MySQL: %w", err)
@@ -180,6 +215,28 @@ func (env *TestEnvironment) Cleanup() error {
 	return nil
 }
 
+// resolveSecrets replaces the credentials that are secret references with
+// the secrets they point to
+func (env *TestEnvironment) resolveSecrets(ctx context.Context) error {
+	credentials := []struct {
+		name  string
+		value *string
+	}{
+		{"POSTGRES_PASSWORD", &env.PostgresPassword},
+		{"MYSQL_PASSWORD", &env.MySQLPassword},
+		{"NEW_RELIC_API_KEY", &env.NewRelicAPIKey},
+		{newrelic.LicenseKeyEnv, &env.NewRelicLicenseKey},
+	}
+	for _, credential := range credentials {
+		value, err := secrets.ResolveValue(ctx, *credential.value)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", credential.name, err)
+		}
+		*credential.value = value
+	}
+	return nil
+}
+
 // Helper functions
 
 func getEnvOrDefault(key, defaultValue string) string {
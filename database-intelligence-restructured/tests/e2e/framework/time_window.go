@@ -0,0 +1,305 @@
+package framework
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables the -since and -until flags default to
+const (
+	SinceEnv = "NRDB_SINCE"
+	UntilEnv = "NRDB_UNTIL"
+)
+
+// TimeWindow overrides the time range of NRQL queries, so a validation can
+// look at an incident window instead of the last hour. Since and Until hold
+// NRQL time expressions; the zero value leaves queries unchanged.
+type TimeWindow struct {
+	Since string
+	Until string
+}
+
+// IsZero reports whether the window leaves queries unchanged
+func (w TimeWindow) IsZero() bool {
+	return w.Since == "" && w.Until == ""
+}
+
+// String returns the SINCE and UNTIL clauses the window applies
+func (w TimeWindow) String() string {
+	if w.IsZero() {
+		return "query default"
+	}
+	clause := "SINCE " + w.Since
+	if w.Until != "" {
+		clause += " UNTIL " + w.Until
+	}
+	return clause
+}
+
+var relativeTime = regexp.MustCompile(`^(\d+)\s*(second|minute|hour|day|week|month)s?\s+ago$`)
+
+// ParseTimeWindow validates since and until and converts them to NRQL. Each
+// accepts a relative time ("3 hours ago"), a Go duration meaning that long
+// ago ("90m"), today or yesterday, epoch milliseconds, or an RFC 3339 or
+// "2006-01-02 15:04[:05]" timestamp in UTC. Until also accepts now, which
+// is the same as leaving it empty. Timestamps become epoch milliseconds,
+// which NRQL takes unquoted. Until requires since, and must come after it.
+func ParseTimeWindow(since, until string) (TimeWindow, error) {
+	var w TimeWindow
+	now := time.Now()
+
+	if strings.EqualFold(strings.TrimSpace(until), "now") {
+		until = ""
+	}
+	if strings.TrimSpace(since) == "" {
+		if strings.TrimSpace(until) != "" {
+			return w, fmt.Errorf("until %q requires since", until)
+		}
+		return w, nil
+	}
+
+	sinceNRQL, sinceTime, err := parseTimeExpr(since, now)
+	if err != nil {
+		return w, fmt.Errorf("invalid since: %w", err)
+	}
+	if !sinceTime.Before(now) {
+		return w, fmt.Errorf("since %q is not in the past", since)
+	}
+	w.Since = sinceNRQL
+
+	if strings.TrimSpace(until) != "" {
+		untilNRQL, untilTime, err := parseTimeExpr(until, now)
+		if err != nil {
+			return w, fmt.Errorf("invalid until: %w", err)
+		}
+		if !untilTime.After(sinceTime) {
+			return w, fmt.Errorf("until %q is not after since %q", until, since)
+		}
+		w.Until = untilNRQL
+	}
+	return w, nil
+}
+
+// parseTimeExpr converts one time expression to NRQL and resolves it
+// against now, approximating a month as 30 days
+func parseTimeExpr(expr string, now time.Time) (string, time.Time, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(expr), " "))
+
+	switch s {
+	case "today":
+		y, m, d := now.Date()
+		return s, time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	case "yesterday":
+		y, m, d := now.Date()
+		return s, time.Date(y, m, d-1, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	if m := relativeTime.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 {
+			return "", time.Time{}, fmt.Errorf("%q: amount must be positive", expr)
+		}
+		unit := map[string]time.Duration{
+			"second": time.Second,
+			"minute": time.Minute,
+			"hour":   time.Hour,
+			"day":    24 * time.Hour,
+			"week":   7 * 24 * time.Hour,
+			"month":  30 * 24 * time.Hour,
+		}[m[2]]
+		name := m[2]
+		if n != 1 {
+			name += "s"
+		}
+		return fmt.Sprintf("%d %s ago", n, name), now.Add(-time.Duration(n) * unit), nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return "", time.Time{}, fmt.Errorf("%q: duration must be positive", expr)
+		}
+		return durationAgo(d), now.Add(-d), nil
+	}
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		// Anything earlier is more likely epoch seconds or a year
+		if ms < 1e12 {
+			return "", time.Time{}, fmt.Errorf("%q: expected epoch milliseconds", expr)
+		}
+		return s, time.UnixMilli(ms), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(expr)); err == nil {
+			return strconv.FormatInt(t.UnixMilli(), 10), t, nil
+		}
+	}
+
+	return "", time.Time{}, fmt.Errorf("%q is not a relative time (3 hours ago), duration (90m), today, yesterday, epoch milliseconds or timestamp", expr)
+}
+
+// durationAgo writes d in the largest NRQL unit that divides it exactly
+func durationAgo(d time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if d%u.size == 0 {
+			n := int64(d / u.size)
+			if n == 1 {
+				return "1 " + u.name + " ago"
+			}
+			return fmt.Sprintf("%d %ss ago", n, u.name)
+		}
+	}
+	return fmt.Sprintf("%d seconds ago", int64((d+time.Second-1)/time.Second))
+}
+
+// nrqlClauseKeywords end a SINCE or UNTIL clause
+var nrqlClauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "FACET": true, "LIMIT": true,
+	"OFFSET": true, "TIMESERIES": true, "COMPARE": true, "WITH": true,
+	"ORDER": true, "EXTRAPOLATE": true, "SINCE": true, "UNTIL": true,
+	"RAW": true, "AS": true,
+}
+
+// nrqlClause is the byte range of a top-level SINCE or UNTIL clause,
+// including the whitespace before it
+type nrqlClause struct {
+	keyword    string
+	start, end int
+}
+
+// Apply returns nrql with its time range replaced by the window. With
+// since set, the first SINCE clause is replaced and any UNTIL clause is
+// dropped; a query without SINCE gets the window appended. Keywords inside
+// quoted strings, backtick names and subqueries are left alone.
+func (w TimeWindow) Apply(nrql string) string {
+	if w.IsZero() {
+		return nrql
+	}
+
+	clauses := findTimeClauses(nrql)
+	var b strings.Builder
+	replaced := false
+	last := 0
+	for _, c := range clauses {
+		b.WriteString(nrql[last:c.start])
+		last = c.end
+		if c.keyword == "SINCE" && !replaced {
+			b.WriteString(" " + w.String())
+			replaced = true
+		}
+	}
+	b.WriteString(nrql[last:])
+
+	out := b.String()
+	if !replaced {
+		out = strings.TrimRight(out, " \t\r\n;") + " " + w.String()
+	}
+	return strings.TrimSpace(out)
+}
+
+// findTimeClauses returns the top-level SINCE and UNTIL clauses of nrql in
+// order
+func findTimeClauses(nrql string) []nrqlClause {
+	type word struct {
+		text       string
+		start, end int
+	}
+
+	// Split into top-level words, skipping quoted text and parentheses
+	var words []word
+	depth := 0
+	for i := 0; i < len(nrql); {
+		ch := nrql[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			j := i + 1
+			for j < len(nrql) && nrql[j] != ch {
+				if nrql[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if depth == 0 {
+				words = append(words, word{start: i, end: min(j+1, len(nrql))})
+			}
+			i = j + 1
+		case ch == '(':
+			depth++
+			i++
+		case ch == ')':
+			if depth > 0 {
+				depth--
+			}
+			i++
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			i++
+		default:
+			j := i
+			for j < len(nrql) && !strings.ContainsRune(" \t\n\r,()'\"`", rune(nrql[j])) {
+				j++
+			}
+			if depth == 0 {
+				words = append(words, word{text: strings.ToUpper(nrql[i:j]), start: i, end: j})
+			}
+			i = j
+		}
+	}
+
+	var clauses []nrqlClause
+	for i := 0; i < len(words); i++ {
+		if words[i].text != "SINCE" && words[i].text != "UNTIL" {
+			continue
+		}
+		c := nrqlClause{keyword: words[i].text, start: words[i].start, end: words[i].end}
+		for c.start > 0 && strings.ContainsRune(" \t\n\r", rune(nrql[c.start-1])) {
+			c.start--
+		}
+		for i+1 < len(words) && !nrqlClauseKeywords[words[i+1].text] {
+			i++
+			c.end = words[i].end
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses
+}
+
+// TimeWindowFlags holds the -since and -until flags
+type TimeWindowFlags struct {
+	since string
+	until string
+}
+
+// AddTimeWindowFlags registers -since and -until on fs. Unset flags fall
+// back to NRDB_SINCE and NRDB_UNTIL, read when Window is called so a .env
+// file loaded after flag parsing still applies.
+func AddTimeWindowFlags(fs *flag.FlagSet) *TimeWindowFlags {
+	f := &TimeWindowFlags{}
+	fs.StringVar(&f.since, "since", "", "Start of the NRQL time window, e.g. \"3 hours ago\", 90m or \"2024-05-01 13:00\" (env "+SinceEnv+"; default: each query's own)")
+	fs.StringVar(&f.until, "until", "", "End of the NRQL time window, in the same forms as -since or now (env "+UntilEnv+")")
+	return f
+}
+
+// Window validates the flags and returns the window they select
+func (f *TimeWindowFlags) Window() (TimeWindow, error) {
+	since, until := f.since, f.until
+	if since == "" {
+		since = os.Getenv(SinceEnv)
+	}
+	if until == "" {
+		until = os.Getenv(UntilEnv)
+	}
+	return ParseTimeWindow(since, until)
+}
@@ -0,0 +1,162 @@
+package framework
+
+import (
+	"flag"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	may1 := strconv.FormatInt(time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC).UnixMilli(), 10)
+	may2 := strconv.FormatInt(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC).UnixMilli(), 10)
+
+	tests := []struct {
+		name    string
+		since   string
+		until   string
+		want    TimeWindow
+		wantErr string
+	}{
+		{name: "unset keeps the query default"},
+		{name: "relative", since: "3 Hours  ago", want: TimeWindow{Since: "3 hours ago"}},
+		{name: "singular", since: "1 days ago", want: TimeWindow{Since: "1 day ago"}},
+		{name: "duration", since: "90m", until: "30m", want: TimeWindow{Since: "90 minutes ago", Until: "30 minutes ago"}},
+		{name: "whole days", since: "48h", want: TimeWindow{Since: "2 days ago"}},
+		{name: "keywords", since: "yesterday", until: "today", want: TimeWindow{Since: "yesterday", Until: "today"}},
+		{name: "until now", since: "1 week ago", until: "now", want: TimeWindow{Since: "1 week ago"}},
+		{name: "timestamps", since: "2024-05-01 13:00", until: "2024-05-02", want: TimeWindow{Since: may1, Until: may2}},
+		{name: "rfc3339", since: "2024-05-01T15:00:00+02:00", want: TimeWindow{Since: may1}},
+		{name: "epoch milliseconds", since: may1, want: TimeWindow{Since: may1}},
+		{name: "until without since", until: "1 hour ago", wantErr: "requires since"},
+		{name: "garbage", since: "last tuesday", wantErr: "invalid since"},
+		{name: "zero amount", since: "0 hours ago", wantErr: "amount must be positive"},
+		{name: "negative duration", since: "-1h", wantErr: "duration must be positive"},
+		{name: "epoch seconds", since: "1714568400", wantErr: "expected epoch milliseconds"},
+		{name: "future", since: "2999-01-01", wantErr: "not in the past"},
+		{name: "until before since", since: "1 hour ago", until: "2 hours ago", wantErr: "is not after since"},
+		{name: "bad until", since: "1 hour ago", until: "soon", wantErr: "invalid until"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimeWindow(tt.since, tt.until)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTimeWindowApply(t *testing.T) {
+	window := TimeWindow{Since: "1714568400000", Until: "1714572000000"}
+	const clause = "SINCE 1714568400000 UNTIL 1714572000000"
+
+	tests := []struct {
+		name string
+		nrql string
+		want string
+	}{
+		{
+			name: "trailing since",
+			nrql: "SELECT count(*) FROM Metric WHERE db.system = 'postgresql' SINCE 1 hour ago",
+			want: "SELECT count(*) FROM Metric WHERE db.system = 'postgresql' " + clause,
+		},
+		{
+			name: "since before limit",
+			nrql: "SELECT uniques(metricName) FROM Metric SINCE 1 day ago LIMIT 50",
+			want: "SELECT uniques(metricName) FROM Metric " + clause + " LIMIT 50",
+		},
+		{
+			name: "existing until is dropped",
+			nrql: "SELECT count(*) FROM Log SINCE 2 days ago UNTIL 1 day ago FACET level",
+			want: "SELECT count(*) FROM Log " + clause + " FACET level",
+		},
+		{
+			name: "until before since",
+			nrql: "SELECT count(*) FROM Log UNTIL 10 minutes ago SINCE 1 hour ago",
+			want: "SELECT count(*) FROM Log " + clause,
+		},
+		{
+			name: "timeseries and compare",
+			nrql: "SELECT sum(postgres.slow_queries.count) FROM Metric since 1 hour ago TIMESERIES AUTO COMPARE WITH 1 week ago",
+			want: "SELECT sum(postgres.slow_queries.count) FROM Metric " + clause + " TIMESERIES AUTO COMPARE WITH 1 week ago",
+		},
+		{
+			name: "quoted timestamp",
+			nrql: "SELECT * FROM Metric SINCE '2024-01-01 00:00:00' LIMIT 5",
+			want: "SELECT * FROM Metric " + clause + " LIMIT 5",
+		},
+		{
+			name: "no time clause",
+			nrql: "SELECT count(*) FROM Metric WHERE metricName LIKE 'postgresql%';",
+			want: "SELECT count(*) FROM Metric WHERE metricName LIKE 'postgresql%' " + clause,
+		},
+		{
+			name: "keywords in strings and names",
+			nrql: "SELECT count(*) FROM Log WHERE message = 'up since 1 hour ago' AND `until` IS NOT NULL SINCE 5 minutes ago",
+			want: "SELECT count(*) FROM Log WHERE message = 'up since 1 hour ago' AND `until` IS NOT NULL " + clause,
+		},
+		{
+			name: "subquery keeps its own range",
+			nrql: "SELECT count(*) FROM Metric WHERE entity.guid IN (SELECT uniques(entity.guid) FROM Log SINCE 1 day ago) SINCE 1 hour ago",
+			want: "SELECT count(*) FROM Metric WHERE entity.guid IN (SELECT uniques(entity.guid) FROM Log SINCE 1 day ago) " + clause,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := window.Apply(tt.nrql)
+			assert.Equal(t, tt.want, got)
+
+			// The result has exactly one top-level SINCE and UNTIL, holding
+			// the window
+			clauses := findTimeClauses(got)
+			require.Len(t, clauses, 2)
+			assert.Equal(t, " SINCE "+window.Since, got[clauses[0].start:clauses[0].end])
+			assert.Equal(t, " UNTIL "+window.Until, got[clauses[1].start:clauses[1].end])
+
+			// Applying again changes nothing
+			assert.Equal(t, got, window.Apply(got))
+		})
+	}
+
+	t.Run("since only keeps no until", func(t *testing.T) {
+		got := TimeWindow{Since: "3 hours ago"}.Apply("SELECT count(*) FROM Metric SINCE 1 hour ago UNTIL 5 minutes ago LIMIT 1")
+		assert.Equal(t, "SELECT count(*) FROM Metric SINCE 3 hours ago LIMIT 1", got)
+	})
+
+	t.Run("zero window", func(t *testing.T) {
+		const nrql = "SELECT count(*) FROM Metric SINCE 1 hour ago"
+		assert.Equal(t, nrql, TimeWindow{}.Apply(nrql))
+	})
+}
+
+func TestTimeWindowFlags(t *testing.T) {
+	t.Setenv(SinceEnv, "6 hours ago")
+	t.Setenv(UntilEnv, "")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := AddTimeWindowFlags(fs)
+	require.NoError(t, fs.Parse(nil))
+
+	w, err := flags.Window()
+	require.NoError(t, err)
+	assert.Equal(t, TimeWindow{Since: "6 hours ago"}, w, "falls back to the environment")
+
+	require.NoError(t, fs.Parse([]string{"-since", "2h", "-until", "1h"}))
+	w, err = flags.Window()
+	require.NoError(t, err)
+	assert.Equal(t, TimeWindow{Since: "2 hours ago", Until: "1 hour ago"}, w)
+
+	require.NoError(t, fs.Parse([]string{"-since", "1 hour ago", "-until", "2 hours ago"}))
+	_, err = flags.Window()
+	assert.Error(t, err)
+}
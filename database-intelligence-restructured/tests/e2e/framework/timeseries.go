@@ -0,0 +1,205 @@
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Columns NRDB adds to the rows of a TIMESERIES result
+const (
+	beginTimeField = "beginTimeSeconds"
+	endTimeField   = "endTimeSeconds"
+	facetField     = "facet"
+)
+
+// TimeseriesBucket is one time bucket of a TIMESERIES result
+type TimeseriesBucket struct {
+	Begin time.Time
+	End   time.Time
+}
+
+// TimeseriesPoint is the value of one series in one bucket
+type TimeseriesPoint struct {
+	TimeseriesBucket
+	Value float64
+	// Missing is set when the series has no value in the bucket: NRDB
+	// returned null, or no row for the series' facet. Functions such as
+	// count() report an empty bucket as 0 instead.
+	Missing bool
+}
+
+// Timeseries is one series of a TIMESERIES result
+type Timeseries struct {
+	// Facet is the facet value, several facets joined with ", "; empty when
+	// the query has no FACET
+	Facet  string
+	Points []TimeseriesPoint
+}
+
+// IsTimeseries reports whether the result came from a TIMESERIES query
+func (r *NRQLResult) IsTimeseries() bool {
+	if r == nil || len(r.Results) == 0 {
+		return false
+	}
+	_, ok := r.Results[0][beginTimeField]
+	return ok
+}
+
+// Buckets returns the time buckets of a TIMESERIES result in time order
+func (r *NRQLResult) Buckets() ([]TimeseriesBucket, error) {
+	if !r.IsTimeseries() {
+		return nil, fmt.Errorf("NRDB result is not a TIMESERIES result")
+	}
+
+	seen := make(map[TimeseriesBucket]bool)
+	var buckets []TimeseriesBucket
+	for i, row := range r.Results {
+		bucket, err := rowBucket(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		if !seen[bucket] {
+			seen[bucket] = true
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Begin.Before(buckets[j].Begin) })
+	return buckets, nil
+}
+
+// Timeseries converts a TIMESERIES result into one series per facet. Every
+// series has a point for every bucket of the result, in time order, so series
+// can be compared point by point and gaps show up as missing points. field
+// names the value column; it may be empty when the rows have a single one.
+func (r *NRQLResult) Timeseries(field string) ([]Timeseries, error) {
+	buckets, err := r.Buckets()
+	if err != nil {
+		return nil, err
+	}
+	if field == "" {
+		if field, err = r.valueField(); err != nil {
+			return nil, err
+		}
+	}
+
+	index := make(map[TimeseriesBucket]int, len(buckets))
+	for i, bucket := range buckets {
+		index[bucket] = i
+	}
+
+	series := make(map[string]*Timeseries)
+	var facets []string
+	for i, row := range r.Results {
+		facet := facetValue(row[facetField])
+		s, ok := series[facet]
+		if !ok {
+			s = &Timeseries{Facet: facet, Points: make([]TimeseriesPoint, len(buckets))}
+			for j, bucket := range buckets {
+				s.Points[j] = TimeseriesPoint{TimeseriesBucket: bucket, Missing: true}
+			}
+			series[facet] = s
+			facets = append(facets, facet)
+		}
+
+		bucket, _ := rowBucket(row)
+		value, ok := row[field]
+		if !ok || value == nil {
+			continue
+		}
+		number, err := numberValue(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		point := &s.Points[index[bucket]]
+		point.Value = number
+		point.Missing = false
+	}
+
+	sort.Strings(facets)
+	result := make([]Timeseries, len(facets))
+	for i, facet := range facets {
+		result[i] = *series[facet]
+	}
+	return result, nil
+}
+
+// Gaps returns the buckets in which the series has no value
+func (s Timeseries) Gaps() []TimeseriesBucket {
+	var gaps []TimeseriesBucket
+	for _, point := range s.Points {
+		if point.Missing {
+			gaps = append(gaps, point.TimeseriesBucket)
+		}
+	}
+	return gaps
+}
+
+// valueField returns the only column of the rows that is not a bucket time,
+// the facet or a faceted attribute
+func (r *NRQLResult) valueField() (string, error) {
+	skip := map[string]bool{beginTimeField: true, endTimeField: true, facetField: true}
+	if r.Metadata != nil {
+		for _, facet := range r.Metadata.Facets {
+			skip[facet] = true
+		}
+	}
+
+	fields := make(map[string]bool)
+	for _, row := range r.Results {
+		for name := range row {
+			if !skip[name] {
+				fields[name] = true
+			}
+		}
+	}
+	if len(fields) != 1 {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("NRDB returned %d value columns (%s), name the field", len(names), strings.Join(names, ", "))
+	}
+	for name := range fields {
+		return name, nil
+	}
+	return "", nil
+}
+
+// rowBucket reads the bucket times of a TIMESERIES row
+func rowBucket(row map[string]interface{}) (TimeseriesBucket, error) {
+	begin, err := numberValue(beginTimeField, row[beginTimeField])
+	if err != nil {
+		return TimeseriesBucket{}, err
+	}
+	end, err := numberValue(endTimeField, row[endTimeField])
+	if err != nil {
+		return TimeseriesBucket{}, err
+	}
+	return TimeseriesBucket{
+		Begin: time.Unix(int64(begin), 0).UTC(),
+		End:   time.Unix(int64(end), 0).UTC(),
+	}, nil
+}
+
+// facetValue formats the facet of a row; NRDB returns a list when the query
+// facets by several attributes
+func facetValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, part := range v {
+			parts[i] = facetValue(part)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
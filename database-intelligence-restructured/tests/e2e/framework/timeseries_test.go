@@ -0,0 +1,107 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryTimeseriesFixture runs a query against a server answering with the
+// sample NerdGraph TIMESERIES response
+func queryTimeseriesFixture(t *testing.T) (*NRQLResult, string) {
+	response, err := os.ReadFile("testdata/nrdb_timeseries_response.json")
+	require.NoError(t, err)
+
+	var graphQL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		graphQL = body["query"]
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	client := NewNRDBClient("12345", "test-key")
+	client.endpoint = server.URL
+	result, err := client.Query(context.Background(),
+		"SELECT sum(postgresql.commits) FROM Metric FACET db.name TIMESERIES 1 minute SINCE 3 minutes ago")
+	require.NoError(t, err)
+	return result, graphQL
+}
+
+func TestNRDBClient_QueryTimeseriesMetadata(t *testing.T) {
+	result, graphQL := queryTimeseriesFixture(t)
+
+	assert.Contains(t, graphQL, "metadata")
+	require.NotNil(t, result.Metadata)
+	assert.Equal(t, []string{"db.name"}, result.Metadata.Facets)
+	assert.Equal(t, &NRQLTimeWindow{Begin: 1714568400000, End: 1714568580000}, result.Metadata.TimeWindow)
+	assert.Len(t, result.Results, 5)
+	assert.True(t, result.IsTimeseries())
+}
+
+func TestNRQLResult_Timeseries(t *testing.T) {
+	result, _ := queryTimeseriesFixture(t)
+	start := time.Unix(1714568400, 0).UTC()
+	bucket := func(i int) TimeseriesBucket {
+		return TimeseriesBucket{Begin: start.Add(time.Duration(i) * time.Minute), End: start.Add(time.Duration(i+1) * time.Minute)}
+	}
+
+	buckets, err := result.Buckets()
+	require.NoError(t, err)
+	assert.Equal(t, []TimeseriesBucket{bucket(0), bucket(1), bucket(2)}, buckets)
+
+	// The value column is found without naming it, skipping the facet attribute
+	series, err := result.Timeseries("")
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+
+	assert.Equal(t, Timeseries{Facet: "inventory", Points: []TimeseriesPoint{
+		{TimeseriesBucket: bucket(0), Value: 40},
+		{TimeseriesBucket: bucket(1), Missing: true},
+		{TimeseriesBucket: bucket(2), Value: 42},
+	}}, series[0])
+	assert.Equal(t, Timeseries{Facet: "orders", Points: []TimeseriesPoint{
+		{TimeseriesBucket: bucket(0), Value: 120},
+		{TimeseriesBucket: bucket(1), Value: 135.5},
+		{TimeseriesBucket: bucket(2), Missing: true},
+	}}, series[1])
+
+	assert.Equal(t, []TimeseriesBucket{bucket(1)}, series[0].Gaps())
+	assert.Equal(t, []TimeseriesBucket{bucket(2)}, series[1].Gaps())
+}
+
+func TestNRQLResult_TimeseriesErrors(t *testing.T) {
+	_, err := (&NRQLResult{Results: []map[string]interface{}{{"count": 3.0}}}).Timeseries("count")
+	assert.EqualError(t, err, "NRDB result is not a TIMESERIES result")
+
+	result := &NRQLResult{Results: []map[string]interface{}{
+		{"beginTimeSeconds": 60.0, "endTimeSeconds": 120.0, "commits": 1.0, "rollbacks": 0.0},
+	}}
+	_, err = result.Timeseries("")
+	assert.EqualError(t, err, "NRDB returned 2 value columns (commits, rollbacks), name the field")
+
+	series, err := result.Timeseries("rollbacks")
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, "", series[0].Facet)
+	assert.Empty(t, series[0].Gaps())
+
+	result.Results[0]["facet"] = []interface{}{"orders", "SELECT"}
+	result.Results[0]["commits"] = map[string]interface{}{"95": 1.5}
+	_, err = result.Timeseries("commits")
+	assert.EqualError(t, err, `row 0: NRDB value for "commits" is map[string]interface {}, not a number`)
+
+	series, err = result.Timeseries("rollbacks")
+	require.NoError(t, err)
+	assert.Equal(t, "orders, SELECT", series[0].Facet)
+}
@@ -3,10 +3,31 @@ module github.com/database-intelligence/db-intel/tests/e2e
 go 1.23.0
 
 require (
+	github.com/database-intelligence/db-intel/components/processors v0.0.0-00010101000000-000000000000
+	github.com/database-intelligence/db-intel/internal v0.0.0-00010101000000-000000000000
 	github.com/go-sql-driver/mysql v1.9.3
+	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.10.0
+	github.com/testcontainers/testcontainers-go v0.31.0
+	go.opentelemetry.io/collector/component v0.105.0
+	go.opentelemetry.io/collector/confmap v0.105.0
+	go.opentelemetry.io/collector/confmap/provider/fileprovider v0.105.0
+	go.opentelemetry.io/collector/confmap/provider/yamlprovider v0.105.0
+	go.opentelemetry.io/collector/connector v0.105.0
+	go.opentelemetry.io/collector/exporter v0.105.0
+	go.opentelemetry.io/collector/exporter/debugexporter v0.105.0
+	go.opentelemetry.io/collector/exporter/otlpexporter v0.105.0
+	go.opentelemetry.io/collector/exporter/otlphttpexporter v0.105.0
+	go.opentelemetry.io/collector/extension v0.105.0
+	go.opentelemetry.io/collector/otelcol v0.105.0
+	go.opentelemetry.io/collector/processor v0.105.0
+	go.opentelemetry.io/collector/processor/batchprocessor v0.105.0
+	go.opentelemetry.io/collector/processor/memorylimiterprocessor v0.105.0
+	go.opentelemetry.io/collector/receiver v0.105.0
+	go.opentelemetry.io/collector/receiver/otlpreceiver v0.105.0
+	go.uber.org/zap v1.27.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -20,6 +41,8 @@ require (
 )
 
 replace (
+	github.com/database-intelligence/db-intel/internal => ../../internal
+	github.com/database-intelligence/db-intel/components/processors => ../../components/processors
 	github.com/database-intelligence/db-intel/components/common => ../../common
 	github.com/database-intelligence/db-intel/components/processors/adaptivesampler => ../../processors/adaptivesampler
 	github.com/database-intelligence/db-intel/components/processors/circuitbreaker => ../../processors/circuitbreaker
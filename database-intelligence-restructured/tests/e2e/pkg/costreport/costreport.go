@@ -0,0 +1,345 @@
+// Package costreport ranks the metrics and attribute keys that drive New
+// Relic ingest cost, from NRDB query results. It estimates cost the way the
+// costcontrol processor does, from a data point count and a fixed size per
+// data point, so the report can be compared with the processor's budget.
+package costreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultSince is the window the queries cover unless a time window
+// overrides it
+const DefaultSince = "SINCE 1 day ago"
+
+// bytesPerGB matches the costcontrol processor's conversion
+const bytesPerGB = 1024 * 1024 * 1024
+
+// month is the period the monthly projection covers
+const month = 30 * 24 * time.Hour
+
+// Pricing converts data points to an estimated cost
+type Pricing struct {
+	// PricePerGB is the ingest price, as costcontrol's price_per_gb
+	PricePerGB float64
+
+	// BytesPerDataPoint is the estimated size of one data point
+	BytesPerDataPoint int
+}
+
+// DefaultPricing returns the costcontrol processor's defaults
+func DefaultPricing() Pricing {
+	return Pricing{PricePerGB: 0.35, BytesPerDataPoint: 100}
+}
+
+// Cost returns the estimated cost of ingesting dataPoints
+func (p Pricing) Cost(dataPoints int64) float64 {
+	return float64(dataPoints) * float64(p.BytesPerDataPoint) / bytesPerGB * p.PricePerGB
+}
+
+// MetricUsage is what one metric sent over the window
+type MetricUsage struct {
+	Name       string
+	DataPoints int64
+	Series     int64
+}
+
+// AttributeUsage is how many values one attribute key had over the window,
+// and how many data points carried it
+type AttributeUsage struct {
+	Key         string
+	Cardinality int64
+	DataPoints  int64
+}
+
+// MetricVolumeQuery counts the data points of every metric
+func MetricVolumeQuery(where string) string {
+	return "SELECT count(*) AS datapoints FROM Metric" + whereClause(where) + " FACET metricName LIMIT MAX " + DefaultSince
+}
+
+// MetricSeriesQuery counts the time series of every metric
+func MetricSeriesQuery(where string) string {
+	return "SELECT cardinality() AS series FROM Metric" + whereClause(where) + " FACET metricName LIMIT MAX " + DefaultSince
+}
+
+// KeysetQuery lists the attribute keys on metrics
+func KeysetQuery(where string) string {
+	return "SELECT keyset() FROM Metric" + whereClause(where) + " " + DefaultSince
+}
+
+// AttributeQuery counts the values of key and the data points carrying it
+func AttributeQuery(key, where string) string {
+	return fmt.Sprintf("SELECT uniqueCount(`%s`) AS cardinality, filter(count(*), WHERE `%s` IS NOT NULL) AS datapoints FROM Metric%s %s",
+		key, key, whereClause(where), DefaultSince)
+}
+
+func whereClause(where string) string {
+	if where = strings.TrimSpace(where); where == "" {
+		return ""
+	}
+	return " WHERE " + where
+}
+
+// ParseMetricUsage joins the rows of MetricVolumeQuery and
+// MetricSeriesQuery by metric name. A metric missing from the series rows
+// has no series count.
+func ParseMetricUsage(volume, series []map[string]interface{}) ([]MetricUsage, error) {
+	seriesByName := make(map[string]int64, len(series))
+	for _, row := range series {
+		name, err := facetName(row)
+		if err != nil {
+			return nil, err
+		}
+		count, err := countValue(row, "series")
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %w", name, err)
+		}
+		seriesByName[name] = count
+	}
+
+	usage := make([]MetricUsage, 0, len(volume))
+	for _, row := range volume {
+		name, err := facetName(row)
+		if err != nil {
+			return nil, err
+		}
+		dataPoints, err := countValue(row, "datapoints")
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %w", name, err)
+		}
+		usage = append(usage, MetricUsage{Name: name, DataPoints: dataPoints, Series: seriesByName[name]})
+	}
+	return usage, nil
+}
+
+// ParseAttributeUsage reads the row of AttributeQuery for key
+func ParseAttributeUsage(key string, rows []map[string]interface{}) (AttributeUsage, error) {
+	usage := AttributeUsage{Key: key}
+	if len(rows) == 0 {
+		return usage, nil
+	}
+
+	var err error
+	if usage.Cardinality, err = countValue(rows[0], "cardinality"); err != nil {
+		return usage, fmt.Errorf("attribute %s: %w", key, err)
+	}
+	if usage.DataPoints, err = countValue(rows[0], "datapoints"); err != nil {
+		return usage, fmt.Errorf("attribute %s: %w", key, err)
+	}
+	return usage, nil
+}
+
+// ParseKeyset returns the string attribute keys from the row of
+// KeysetQuery, sorted. Numeric keys are mostly metric values rather than
+// dimensions, and keys NRDB adds itself cannot be normalized by the
+// collector, so both are left out.
+func ParseKeyset(rows []map[string]interface{}) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	raw, ok := rows[0]["stringKeys"].([]interface{})
+	if !ok {
+		raw, _ = rows[0]["allKeys"].([]interface{})
+	}
+
+	keys := make([]string, 0, len(raw))
+	for _, v := range raw {
+		key, ok := v.(string)
+		if !ok || key == "" || ignoredKey(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ignoredKey reports whether key is set by NRDB rather than the collector,
+// or cannot be quoted in NRQL
+func ignoredKey(key string) bool {
+	switch key {
+	case "metricName", "timestamp", "endTimestamp":
+		return true
+	}
+	return strings.HasPrefix(key, "newrelic.") || strings.ContainsAny(key, "`\"")
+}
+
+// MetricCost is one metric's estimated share of the cost
+type MetricCost struct {
+	MetricUsage
+	// Share is the fraction of all data points
+	Share          float64
+	CostUSD        float64
+	MonthlyCostUSD float64
+}
+
+// AttributeCost is one attribute key's cardinality and the share of data
+// points that carry it
+type AttributeCost struct {
+	AttributeUsage
+	// Share is the fraction of all data points that carry the key
+	Share float64
+	// CostUSD is the estimated cost of the data points carrying the key
+	CostUSD float64
+}
+
+// Report ranks metrics by estimated cost and attribute keys by cardinality
+type Report struct {
+	Window          time.Duration
+	Pricing         Pricing
+	TotalDataPoints int64
+	TotalCostUSD    float64
+	// MonthlyCostUSD projects the window's cost to 30 days, zero when the
+	// window is unknown
+	MonthlyCostUSD float64
+	Metrics        []MetricCost
+	Attributes     []AttributeCost
+}
+
+// Build ranks the usage, keeping the top entries of each list; top of zero
+// or less keeps them all. Totals cover every metric, not only the top ones.
+func Build(window time.Duration, pricing Pricing, metrics []MetricUsage, attributes []AttributeUsage, top int) *Report {
+	report := &Report{Window: window, Pricing: pricing}
+	for _, m := range metrics {
+		report.TotalDataPoints += m.DataPoints
+	}
+	report.TotalCostUSD = pricing.Cost(report.TotalDataPoints)
+	report.MonthlyCostUSD = report.monthly(report.TotalCostUSD)
+
+	for _, m := range metrics {
+		cost := pricing.Cost(m.DataPoints)
+		report.Metrics = append(report.Metrics, MetricCost{
+			MetricUsage:    m,
+			Share:          report.share(m.DataPoints),
+			CostUSD:        cost,
+			MonthlyCostUSD: report.monthly(cost),
+		})
+	}
+	sort.SliceStable(report.Metrics, func(i, j int) bool {
+		a, b := report.Metrics[i], report.Metrics[j]
+		if a.DataPoints != b.DataPoints {
+			return a.DataPoints > b.DataPoints
+		}
+		if a.Series != b.Series {
+			return a.Series > b.Series
+		}
+		return a.Name < b.Name
+	})
+
+	for _, a := range attributes {
+		if a.Cardinality == 0 {
+			continue
+		}
+		report.Attributes = append(report.Attributes, AttributeCost{
+			AttributeUsage: a,
+			Share:          report.share(a.DataPoints),
+			CostUSD:        pricing.Cost(a.DataPoints),
+		})
+	}
+	sort.SliceStable(report.Attributes, func(i, j int) bool {
+		a, b := report.Attributes[i], report.Attributes[j]
+		if a.Cardinality != b.Cardinality {
+			return a.Cardinality > b.Cardinality
+		}
+		if a.DataPoints != b.DataPoints {
+			return a.DataPoints > b.DataPoints
+		}
+		return a.Key < b.Key
+	})
+
+	if top > 0 {
+		if len(report.Metrics) > top {
+			report.Metrics = report.Metrics[:top]
+		}
+		if len(report.Attributes) > top {
+			report.Attributes = report.Attributes[:top]
+		}
+	}
+	return report
+}
+
+func (r *Report) share(dataPoints int64) float64 {
+	if r.TotalDataPoints == 0 {
+		return 0
+	}
+	return float64(dataPoints) / float64(r.TotalDataPoints)
+}
+
+func (r *Report) monthly(cost float64) float64 {
+	if r.Window <= 0 {
+		return 0
+	}
+	return cost * float64(month) / float64(r.Window)
+}
+
+// Print writes the report as two ranked tables
+func (r *Report) Print(w io.Writer) error {
+	fmt.Fprintf(w, "Estimated cost over %s at $%.2f/GB and %d bytes per data point\n",
+		r.windowString(), r.Pricing.PricePerGB, r.Pricing.BytesPerDataPoint)
+	fmt.Fprintf(w, "Total: %d data points, $%.4f", r.TotalDataPoints, r.TotalCostUSD)
+	if r.MonthlyCostUSD > 0 {
+		fmt.Fprintf(w, " ($%.2f per 30 days)", r.MonthlyCostUSD)
+	}
+	fmt.Fprint(w, "\n\nMetrics by estimated cost\n")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tMETRIC\tDATA POINTS\tSERIES\tSHARE\tCOST\tPER 30 DAYS")
+	for i, m := range r.Metrics {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.1f%%\t$%.4f\t$%.2f\n",
+			i+1, m.Name, m.DataPoints, m.Series, m.Share*100, m.CostUSD, m.MonthlyCostUSD)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\nAttribute keys by cardinality\n")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tATTRIBUTE\tCARDINALITY\tDATA POINTS\tSHARE\tCOST")
+	for i, a := range r.Attributes {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.1f%%\t$%.4f\n",
+			i+1, a.Key, a.Cardinality, a.DataPoints, a.Share*100, a.CostUSD)
+	}
+	return tw.Flush()
+}
+
+func (r *Report) windowString() string {
+	if r.Window <= 0 {
+		return "the queried window"
+	}
+	return r.Window.String()
+}
+
+// facetName returns the metric name a faceted row is for
+func facetName(row map[string]interface{}) (string, error) {
+	for _, field := range []string{"metricName", "facet"} {
+		if name, ok := row[field].(string); ok && name != "" {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("NRDB row has no metricName facet: %v", row)
+}
+
+// countValue reads a count NRDB returned as a JSON number
+func countValue(row map[string]interface{}, field string) (int64, error) {
+	switch v := row[field].(type) {
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	case nil:
+		return 0, fmt.Errorf("NRDB row has no %q", field)
+	default:
+		return 0, fmt.Errorf("NRDB value for %q is %T, not a number", field, v)
+	}
+}
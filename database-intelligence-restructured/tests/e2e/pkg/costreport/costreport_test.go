@@ -0,0 +1,149 @@
+package costreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadBatchResponse reads a NerdGraph response to a batch of aliased NRQL
+// queries and returns the result rows of each alias in order
+func loadBatchResponse(t *testing.T, path string) [][]map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var response struct {
+		Data struct {
+			Actor struct {
+				Account map[string]struct {
+					Results []map[string]interface{} `json:"results"`
+				} `json:"account"`
+			} `json:"actor"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	account := response.Data.Actor.Account
+	results := make([][]map[string]interface{}, len(account))
+	for i := range results {
+		result, ok := account[fmt.Sprintf("q%d", i)]
+		require.True(t, ok, "missing q%d", i)
+		results[i] = result.Results
+	}
+	return results
+}
+
+func TestReportFromNRDBResponse(t *testing.T) {
+	usage := loadBatchResponse(t, "testdata/nrdb_usage_response.json")
+	metrics, err := ParseMetricUsage(usage[0], usage[1])
+	require.NoError(t, err)
+
+	keys := ParseKeyset(usage[2])
+	assert.Equal(t, []string{"db.name", "db.system", "db.wait_event.name", "query.text"}, keys,
+		"NRDB's own keys are left out")
+
+	attributeRows := loadBatchResponse(t, "testdata/nrdb_attribute_response.json")
+	require.Len(t, attributeRows, len(keys))
+	attributes := make([]AttributeUsage, len(keys))
+	for i, key := range keys {
+		attributes[i], err = ParseAttributeUsage(key, attributeRows[i])
+		require.NoError(t, err)
+	}
+
+	report := Build(24*time.Hour, DefaultPricing(), metrics, attributes, 3)
+
+	assert.Equal(t, int64(7516193), report.TotalDataPoints)
+	assert.InDelta(t, 0.245, report.TotalCostUSD, 0.0001)
+	assert.InDelta(t, 7.35, report.MonthlyCostUSD, 0.001)
+
+	require.Len(t, report.Metrics, 3, "only the top 3 metrics are kept")
+	assert.Equal(t, MetricUsage{Name: "postgres.wait_events", DataPoints: 5368709, Series: 912}, report.Metrics[0].MetricUsage)
+	assert.InDelta(t, 0.7143, report.Metrics[0].Share, 0.0001)
+	assert.InDelta(t, 0.175, report.Metrics[0].CostUSD, 0.0001)
+	assert.InDelta(t, 5.25, report.Metrics[0].MonthlyCostUSD, 0.001)
+	// Equal volume is broken by series count
+	assert.Equal(t, "postgresql.backends", report.Metrics[1].Name)
+	assert.Equal(t, "postgresql.commits", report.Metrics[2].Name)
+
+	require.Len(t, report.Attributes, 3)
+	assert.Equal(t, []string{"query.text", "db.wait_event.name", "db.name"}, []string{
+		report.Attributes[0].Key, report.Attributes[1].Key, report.Attributes[2].Key,
+	})
+	assert.Equal(t, int64(4811), report.Attributes[0].Cardinality)
+	assert.InDelta(t, 0.1429, report.Attributes[0].Share, 0.0001)
+	assert.InDelta(t, 1.0, report.Attributes[2].Share, 0.0001)
+}
+
+func TestBuildWithoutWindow(t *testing.T) {
+	report := Build(0, DefaultPricing(), []MetricUsage{{Name: "a", DataPoints: 10}}, nil, 0)
+	assert.Zero(t, report.MonthlyCostUSD)
+	assert.Zero(t, report.Metrics[0].MonthlyCostUSD)
+
+	var out strings.Builder
+	require.NoError(t, report.Print(&out))
+	assert.Contains(t, out.String(), "Estimated cost over the queried window")
+	assert.NotContains(t, out.String(), "per 30 days)")
+}
+
+func TestBuildSkipsUnusedAttributes(t *testing.T) {
+	report := Build(time.Hour, DefaultPricing(), nil, []AttributeUsage{
+		{Key: "db.name", Cardinality: 2, DataPoints: 10},
+		{Key: "db.operation"},
+	}, 0)
+	require.Len(t, report.Attributes, 1)
+	assert.Equal(t, "db.name", report.Attributes[0].Key)
+	assert.Zero(t, report.Attributes[0].Share, "no share without metric volume")
+}
+
+func TestPrint(t *testing.T) {
+	report := Build(24*time.Hour, DefaultPricing(), []MetricUsage{
+		{Name: "postgres.wait_events", DataPoints: 5368709, Series: 912},
+		{Name: "postgresql.commits", DataPoints: 5368709, Series: 4},
+	}, []AttributeUsage{{Key: "query.text", Cardinality: 4811, DataPoints: 5368709}}, 0)
+
+	var out strings.Builder
+	require.NoError(t, report.Print(&out))
+	assert.Equal(t, `Estimated cost over 24h0m0s at $0.35/GB and 100 bytes per data point
+Total: 10737418 data points, $0.3500 ($10.50 per 30 days)
+
+Metrics by estimated cost
+RANK  METRIC                DATA POINTS  SERIES  SHARE  COST     PER 30 DAYS
+1     postgres.wait_events  5368709      912     50.0%  $0.1750  $5.25
+2     postgresql.commits    5368709      4       50.0%  $0.1750  $5.25
+
+Attribute keys by cardinality
+RANK  ATTRIBUTE   CARDINALITY  DATA POINTS  SHARE  COST
+1     query.text  4811         5368709      50.0%  $0.1750
+`, out.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := ParseMetricUsage([]map[string]interface{}{{"datapoints": float64(1)}}, nil)
+	assert.ErrorContains(t, err, "NRDB row has no metricName facet")
+
+	_, err = ParseMetricUsage([]map[string]interface{}{{"facet": "a", "datapoints": "many"}}, nil)
+	assert.EqualError(t, err, `metric a: NRDB value for "datapoints" is string, not a number`)
+
+	_, err = ParseAttributeUsage("db.name", []map[string]interface{}{{"cardinality": float64(3)}})
+	assert.EqualError(t, err, `attribute db.name: NRDB row has no "datapoints"`)
+
+	usage, err := ParseAttributeUsage("db.name", nil)
+	require.NoError(t, err)
+	assert.Equal(t, AttributeUsage{Key: "db.name"}, usage)
+}
+
+func TestQueries(t *testing.T) {
+	assert.Equal(t, "SELECT count(*) AS datapoints FROM Metric WHERE db.system = 'postgresql' FACET metricName LIMIT MAX SINCE 1 day ago",
+		MetricVolumeQuery(" db.system = 'postgresql' "))
+	assert.Equal(t, "SELECT cardinality() AS series FROM Metric FACET metricName LIMIT MAX SINCE 1 day ago",
+		MetricSeriesQuery(""))
+	assert.Equal(t, "SELECT uniqueCount(`db.name`) AS cardinality, filter(count(*), WHERE `db.name` IS NOT NULL) AS datapoints FROM Metric SINCE 1 day ago",
+		AttributeQuery("db.name", ""))
+}
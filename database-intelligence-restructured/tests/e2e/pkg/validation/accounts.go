@@ -0,0 +1,273 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultAccountConcurrency is how many accounts are validated at once when
+// the accounts file does not say
+const DefaultAccountConcurrency = 4
+
+// Account is a New Relic account the parity validation runs against
+type Account struct {
+	// Name labels the account in the matrix; the account ID when empty
+	Name      string `yaml:"name" json:"name"`
+	AccountID string `yaml:"account_id" json:"account_id"`
+	// APIKey is a user key for the account. ${VAR} references are expanded
+	// from the environment, so keys need not be stored in the file.
+	APIKey string `yaml:"api_key" json:"-"`
+}
+
+// AccountsConfig lists the accounts of a multi-account validation run
+type AccountsConfig struct {
+	// Concurrency caps how many accounts are validated at once
+	Concurrency int       `yaml:"concurrency"`
+	Accounts    []Account `yaml:"accounts"`
+}
+
+// LoadAccountsConfig reads and validates an accounts file
+func LoadAccountsConfig(path string) (*AccountsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts file: %w", err)
+	}
+
+	var cfg AccountsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts file %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid accounts file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (cfg *AccountsConfig) validate() error {
+	if len(cfg.Accounts) == 0 {
+		return errors.New("no accounts listed")
+	}
+	if cfg.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", cfg.Concurrency)
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = DefaultAccountConcurrency
+	}
+
+	names := make(map[string]bool, len(cfg.Accounts))
+	for i := range cfg.Accounts {
+		account := &cfg.Accounts[i]
+		if account.AccountID == "" {
+			return fmt.Errorf("account %d has no account_id", i+1)
+		}
+		if account.Name == "" {
+			account.Name = account.AccountID
+		}
+		if names[account.Name] {
+			return fmt.Errorf("duplicate account name %q", account.Name)
+		}
+		names[account.Name] = true
+
+		account.APIKey = os.ExpandEnv(account.APIKey)
+		if account.APIKey == "" {
+			return fmt.Errorf("account %s has no api_key", account.Name)
+		}
+	}
+	return nil
+}
+
+// AccountValidator runs the widget validation for one account
+type AccountValidator func(ctx context.Context, account Account) ([]*ValidationResult, error)
+
+// AccountResult is the outcome of validating one account
+type AccountResult struct {
+	Account Account
+	Results []*ValidationResult
+	// Err is set when the account could not be validated at all, for
+	// example because its API key was rejected
+	Err error
+}
+
+// ValidateAccounts runs validate for every account, at most concurrency at a
+// time. An account that fails or panics is reported in its result and does
+// not stop the others. Results are in the order of accounts.
+func ValidateAccounts(ctx context.Context, accounts []Account, concurrency int, validate AccountValidator) []AccountResult {
+	if concurrency <= 0 {
+		concurrency = DefaultAccountConcurrency
+	}
+
+	results := make([]AccountResult, len(accounts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, account := range accounts {
+		results[i].Account = account
+
+		wg.Add(1)
+		go func(result *AccountResult) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+
+			defer func() {
+				if r := recover(); r != nil {
+					result.Err = fmt.Errorf("validation panicked: %v", r)
+				}
+			}()
+			result.Results, result.Err = validate(ctx, result.Account)
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
+
+// ParityMatrix is the status of every widget in every account
+type ParityMatrix struct {
+	// Accounts and Widgets are the columns and rows, in the order first seen
+	Accounts []string `json:"accounts"`
+	Widgets  []string `json:"widgets"`
+	// Cells holds the status by widget, then account. A widget an account
+	// did not report has no cell.
+	Cells map[string]map[string]ValidationStatus `json:"cells"`
+	// Errors holds the accounts that could not be validated
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// BuildParityMatrix aggregates per-account results into a matrix
+func BuildParityMatrix(results []AccountResult) *ParityMatrix {
+	m := &ParityMatrix{
+		Cells:  make(map[string]map[string]ValidationStatus),
+		Errors: make(map[string]string),
+	}
+
+	for _, result := range results {
+		account := result.Account.Name
+		m.Accounts = append(m.Accounts, account)
+		if result.Err != nil {
+			m.Errors[account] = result.Err.Error()
+			continue
+		}
+
+		for _, r := range result.Results {
+			if r == nil {
+				continue
+			}
+			row, ok := m.Cells[r.MetricName]
+			if !ok {
+				row = make(map[string]ValidationStatus)
+				m.Cells[r.MetricName] = row
+				m.Widgets = append(m.Widgets, r.MetricName)
+			}
+			row[account] = worseStatus(row[account], r.Status)
+		}
+	}
+	return m
+}
+
+// statusRank orders statuses from best to worst
+var statusRank = map[ValidationStatus]int{
+	"":                      0,
+	ValidationStatusSkipped: 1,
+	ValidationStatusPassed:  2,
+	ValidationStatusWarning: 3,
+	ValidationStatusFailed:  4,
+}
+
+// worseStatus keeps the worst status when a widget is reported twice
+func worseStatus(a, b ValidationStatus) ValidationStatus {
+	if statusRank[b] > statusRank[a] {
+		return b
+	}
+	return a
+}
+
+// Passed reports whether every account was validated and no widget failed
+func (m *ParityMatrix) Passed() bool {
+	if len(m.Errors) > 0 {
+		return false
+	}
+	for _, row := range m.Cells {
+		for _, status := range row {
+			if status == ValidationStatusFailed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AccountPassed reports whether an account was validated without failures
+func (m *ParityMatrix) AccountPassed(account string) bool {
+	if _, failed := m.Errors[account]; failed {
+		return false
+	}
+	for _, row := range m.Cells {
+		if row[account] == ValidationStatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText writes the matrix as a table with a widget per row and an
+// account per column, followed by the accounts that could not be validated
+func (m *ParityMatrix) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Widget\t%s\n", strings.Join(m.Accounts, "\t"))
+	for _, widget := range m.Widgets {
+		cells := make([]string, len(m.Accounts))
+		for i, account := range m.Accounts {
+			cells[i] = m.cellText(widget, account)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", widget, strings.Join(cells, "\t"))
+	}
+
+	verdicts := make([]string, len(m.Accounts))
+	for i, account := range m.Accounts {
+		verdicts[i] = "PASS"
+		if !m.AccountPassed(account) {
+			verdicts[i] = "FAIL"
+		}
+	}
+	fmt.Fprintf(tw, "Overall\t%s\n", strings.Join(verdicts, "\t"))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, account := range m.Accounts {
+		if msg, ok := m.Errors[account]; ok {
+			fmt.Fprintf(w, "\n%s: %s", account, msg)
+		}
+	}
+	if len(m.Errors) > 0 {
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func (m *ParityMatrix) cellText(widget, account string) string {
+	if _, failed := m.Errors[account]; failed {
+		return "ERROR"
+	}
+	status, ok := m.Cells[widget][account]
+	if !ok {
+		return "-"
+	}
+	return string(status)
+}
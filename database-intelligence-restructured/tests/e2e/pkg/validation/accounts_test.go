@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func widgetResult(widget string, status ValidationStatus) *ValidationResult {
+	return &ValidationResult{MetricName: widget, Status: status}
+}
+
+func TestLoadAccountsConfig(t *testing.T) {
+	t.Setenv("TEST_NR_PRODUCTION_KEY", "NRAK-PRODUCTION")
+
+	cfg, err := LoadAccountsConfig("testdata/accounts.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Concurrency)
+	assert.Equal(t, []Account{
+		{Name: "production", AccountID: "1000001", APIKey: "NRAK-PRODUCTION"},
+		{Name: "1000002", AccountID: "1000002", APIKey: "NRAK-STAGING"},
+	}, cfg.Accounts)
+
+	t.Setenv("TEST_NR_PRODUCTION_KEY", "")
+	_, err = LoadAccountsConfig("testdata/accounts.yaml")
+	assert.ErrorContains(t, err, "account production has no api_key")
+}
+
+func TestAccountsConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg  AccountsConfig
+		want string
+	}{
+		"no accounts":  {AccountsConfig{}, "no accounts listed"},
+		"no id":        {AccountsConfig{Accounts: []Account{{Name: "a", APIKey: "k"}}}, "account 1 has no account_id"},
+		"duplicate":    {AccountsConfig{Accounts: []Account{{AccountID: "1", APIKey: "k"}, {AccountID: "1", APIKey: "k"}}}, `duplicate account name "1"`},
+		"negative cap": {AccountsConfig{Concurrency: -1, Accounts: []Account{{AccountID: "1", APIKey: "k"}}}, "concurrency must not be negative, got -1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.EqualError(t, tt.cfg.validate(), tt.want)
+		})
+	}
+
+	cfg := AccountsConfig{Accounts: []Account{{AccountID: "1", APIKey: "k"}}}
+	require.NoError(t, cfg.validate())
+	assert.Equal(t, DefaultAccountConcurrency, cfg.Concurrency)
+}
+
+func TestValidateAccounts_IsolatesFailuresAndCapsConcurrency(t *testing.T) {
+	accounts := []Account{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+
+	var running, peak int32
+	results := ValidateAccounts(context.Background(), accounts, 2, func(ctx context.Context, account Account) ([]*ValidationResult, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		switch account.Name {
+		case "b":
+			return nil, errors.New("NRDB query failed with status 401")
+		case "c":
+			panic("nil client")
+		}
+		return []*ValidationResult{widgetResult("Database", ValidationStatusPassed)}, nil
+	})
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	require.Len(t, results, 4)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, name, results[i].Account.Name)
+	}
+	assert.NoError(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, "NRDB query failed with status 401")
+	assert.EqualError(t, results[2].Err, "validation panicked: nil client")
+	assert.NoError(t, results[3].Err)
+	assert.Len(t, results[3].Results, 1)
+}
+
+func TestBuildParityMatrix(t *testing.T) {
+	matrix := BuildParityMatrix([]AccountResult{
+		{Account: Account{Name: "production"}, Results: []*ValidationResult{
+			widgetResult("Database", ValidationStatusPassed),
+			widgetResult("Wait Events", ValidationStatusWarning),
+		}},
+		{Account: Account{Name: "staging"}, Results: []*ValidationResult{
+			widgetResult("Database", ValidationStatusPassed),
+			widgetResult("Wait Events", ValidationStatusPassed),
+			widgetResult("Wait Events", ValidationStatusFailed),
+			widgetResult("Blocking", ValidationStatusSkipped),
+		}},
+		{Account: Account{Name: "sandbox"}, Err: fmt.Errorf("NRDB query failed with status 401")},
+	})
+
+	assert.Equal(t, []string{"production", "staging", "sandbox"}, matrix.Accounts)
+	assert.Equal(t, []string{"Database", "Wait Events", "Blocking"}, matrix.Widgets)
+	assert.Equal(t, map[string]map[string]ValidationStatus{
+		"Database":    {"production": ValidationStatusPassed, "staging": ValidationStatusPassed},
+		"Wait Events": {"production": ValidationStatusWarning, "staging": ValidationStatusFailed},
+		"Blocking":    {"staging": ValidationStatusSkipped},
+	}, matrix.Cells)
+	assert.Equal(t, map[string]string{"sandbox": "NRDB query failed with status 401"}, matrix.Errors)
+
+	assert.True(t, matrix.AccountPassed("production"), "a warning is not a failure")
+	assert.False(t, matrix.AccountPassed("staging"))
+	assert.False(t, matrix.AccountPassed("sandbox"))
+	assert.False(t, matrix.Passed())
+
+	var out strings.Builder
+	require.NoError(t, matrix.WriteText(&out))
+	assert.Equal(t, strings.Join([]string{
+		"Widget       production  staging  sandbox",
+		"Database     PASSED      PASSED   ERROR",
+		"Wait Events  WARNING     FAILED   ERROR",
+		"Blocking     -           SKIPPED  ERROR",
+		"Overall      PASS        FAIL     FAIL",
+		"",
+		"sandbox: NRDB query failed with status 401",
+		"",
+	}, "\n"), out.String())
+
+	passing := BuildParityMatrix([]AccountResult{
+		{Account: Account{Name: "production"}, Results: []*ValidationResult{widgetResult("Database", ValidationStatusPassed)}},
+	})
+	assert.True(t, passing.Passed())
+}
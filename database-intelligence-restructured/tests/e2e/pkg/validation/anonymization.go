@@ -0,0 +1,24 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/database-intelligence/db-intel/components/processors/querynormalizer"
+)
+
+// VerifyAnonymized checks that a statement reported by the pipeline, such
+// as a db.statement attribute, has no string or numeric literals left, so
+// tests need not look for the specific values their workload used. The
+// statement is tokenized the way the querynormalizer processor normalizes
+// statements; ? and positional parameters such as $1 count as placeholders,
+// so statements normalized by the processor and by pg_stat_statements both
+// pass. The error lists the literals found.
+func VerifyAnonymized(statement string) error {
+	literals := querynormalizer.Literals(statement)
+	if len(literals) == 0 {
+		return nil
+	}
+	return fmt.Errorf("statement is not anonymized, found %d literal(s) %s: %s",
+		len(literals), strings.Join(literals, ", "), statement)
+}
@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyAnonymized(t *testing.T) {
+	anonymized := []string{
+		"SELECT * FROM orders WHERE status = ? AND amount > ?",
+		"SELECT * FROM orders WHERE status = $1 AND amount > $2",
+		"SELECT id FROM users WHERE id IN (?) AND created_at > now() - interval ?",
+		`SELECT "2024_totals", col1 FROM t2`,
+	}
+	for _, statement := range anonymized {
+		assert.NoError(t, VerifyAnonymized(statement), statement)
+	}
+
+	tests := []struct {
+		statement string
+		err       string
+	}{
+		{
+			statement: "SELECT * FROM orders WHERE status = 'A' AND amount > 5000",
+			err:       "found 2 literal(s) 'A', 5000",
+		},
+		{
+			// One literal left behind among placeholders
+			statement: "UPDATE accounts SET balance = ? WHERE email = 'jane@example.com'",
+			err:       "found 1 literal(s) 'jane@example.com'",
+		},
+		{
+			statement: "SELECT * FROM t WHERE ratio > -0.5",
+			err:       "found 1 literal(s) -0.5",
+		},
+		{
+			statement: "SELECT $$secret$$",
+			err:       "found 1 literal(s) $$secret$$",
+		},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, VerifyAnonymized(tt.statement), tt.err, tt.statement)
+	}
+}
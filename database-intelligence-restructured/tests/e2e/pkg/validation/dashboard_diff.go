@@ -0,0 +1,262 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Ways two widgets are aligned
+const (
+	MatchedByTitle    = "title"
+	MatchedByPosition = "position"
+)
+
+// WidgetRef identifies a widget in a dashboard
+type WidgetRef struct {
+	Page   string `json:"page"`
+	Title  string `json:"title"`
+	Row    int    `json:"row"`
+	Column int    `json:"column"`
+}
+
+func (r WidgetRef) String() string {
+	return fmt.Sprintf("%q (page %q, row %d, column %d)", r.Title, r.Page, r.Row, r.Column)
+}
+
+// FieldDiff is a widget property that differs between the dashboards
+type FieldDiff struct {
+	// Field is title, visualization, queries, events, metrics or facets
+	Field string   `json:"field"`
+	Left  []string `json:"left"`
+	Right []string `json:"right"`
+}
+
+// WidgetDiff pairs a widget of the left dashboard with its counterpart in
+// the right one
+type WidgetDiff struct {
+	Left        WidgetRef   `json:"left"`
+	Right       WidgetRef   `json:"right"`
+	MatchedBy   string      `json:"matched_by"`
+	Differences []FieldDiff `json:"differences,omitempty"`
+}
+
+// DashboardDiff is the structured difference between two dashboards, such
+// as an OHI dashboard and the OTEL dashboard migrated from it
+type DashboardDiff struct {
+	Matched     []WidgetDiff `json:"matched"`
+	OnlyInLeft  []WidgetRef  `json:"only_in_left"`
+	OnlyInRight []WidgetRef  `json:"only_in_right"`
+}
+
+// Equal reports whether every widget has a counterpart with the same
+// properties
+func (d *DashboardDiff) Equal() bool {
+	if len(d.OnlyInLeft) > 0 || len(d.OnlyInRight) > 0 {
+		return false
+	}
+	for _, w := range d.Matched {
+		if len(w.Differences) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Changed returns the matched widgets that differ
+func (d *DashboardDiff) Changed() []WidgetDiff {
+	var changed []WidgetDiff
+	for _, w := range d.Matched {
+		if len(w.Differences) > 0 {
+			changed = append(changed, w)
+		}
+	}
+	return changed
+}
+
+// Print writes the diff for reading: "~" marks a changed widget, "-" one
+// only in the left dashboard and "+" one only in the right
+func (d *DashboardDiff) Print(w io.Writer) error {
+	var b strings.Builder
+	for _, widget := range d.Changed() {
+		fmt.Fprintf(&b, "~ %s, matched by %s", widget.Left, widget.MatchedBy)
+		if widget.Right != widget.Left {
+			fmt.Fprintf(&b, " to %s", widget.Right)
+		}
+		b.WriteString("\n")
+		for _, field := range widget.Differences {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", field.Field, formatValues(field.Left), formatValues(field.Right))
+		}
+	}
+	for _, widget := range d.OnlyInLeft {
+		fmt.Fprintf(&b, "- %s only in left\n", widget)
+	}
+	for _, widget := range d.OnlyInRight {
+		fmt.Fprintf(&b, "+ %s only in right\n", widget)
+	}
+	fmt.Fprintf(&b, "%d widgets matched, %d differ, %d only in left, %d only in right\n",
+		len(d.Matched), len(d.Changed()), len(d.OnlyInLeft), len(d.OnlyInRight))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatValues(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// diffWidget is a widget with all its queries
+type diffWidget struct {
+	ref       WidgetRef
+	pageIndex int
+	viz       string
+	queries   []NRQLQuery
+}
+
+// key is the widget's normalized title
+func (w *diffWidget) key() string {
+	return strings.ToLower(strings.Join(strings.Fields(w.ref.Title), " "))
+}
+
+// widgets groups the parsed queries by widget, in dashboard order
+func (p *DashboardParser) widgets() []*diffWidget {
+	var widgets []*diffWidget
+	byIndex := make(map[int]*diffWidget)
+	for _, q := range p.nrqlQueries {
+		w, ok := byIndex[q.WidgetIndex]
+		if !ok {
+			w = &diffWidget{
+				ref:       WidgetRef{Page: q.Page, Title: q.WidgetTitle, Row: q.Layout.Row, Column: q.Layout.Column},
+				pageIndex: q.PageIndex,
+				viz:       q.Visualization,
+			}
+			byIndex[q.WidgetIndex] = w
+			widgets = append(widgets, w)
+		}
+		w.queries = append(w.queries, q)
+	}
+	return widgets
+}
+
+// LoadDashboard reads and parses a dashboard JSON file
+func LoadDashboard(path string) (*DashboardParser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	parser := NewDashboardParser()
+	if err := parser.ParseDashboard(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return parser, nil
+}
+
+// DiffDashboards aligns the widgets of two parsed dashboards and compares
+// them. Widgets are paired by title first, ignoring case and spacing, and
+// in dashboard order when a title repeats; the rest are paired when they
+// sit at the same row and column of the same page. Widgets without a
+// query are not compared.
+func DiffDashboards(left, right *DashboardParser) *DashboardDiff {
+	leftWidgets, rightWidgets := left.widgets(), right.widgets()
+	pairs := make(map[*diffWidget]*diffWidget)
+	matchedBy := make(map[*diffWidget]string)
+	taken := make(map[*diffWidget]bool)
+
+	align := func(by string, same func(l, r *diffWidget) bool) {
+		for _, l := range leftWidgets {
+			if pairs[l] != nil {
+				continue
+			}
+			for _, r := range rightWidgets {
+				if !taken[r] && same(l, r) {
+					pairs[l], matchedBy[l], taken[r] = r, by, true
+					break
+				}
+			}
+		}
+	}
+	align(MatchedByTitle, func(l, r *diffWidget) bool { return l.key() == r.key() })
+	align(MatchedByPosition, func(l, r *diffWidget) bool {
+		return l.pageIndex == r.pageIndex && l.ref.Row == r.ref.Row && l.ref.Column == r.ref.Column
+	})
+
+	diff := &DashboardDiff{Matched: []WidgetDiff{}, OnlyInLeft: []WidgetRef{}, OnlyInRight: []WidgetRef{}}
+	for _, l := range leftWidgets {
+		r := pairs[l]
+		if r == nil {
+			diff.OnlyInLeft = append(diff.OnlyInLeft, l.ref)
+			continue
+		}
+		diff.Matched = append(diff.Matched, WidgetDiff{
+			Left:        l.ref,
+			Right:       r.ref,
+			MatchedBy:   matchedBy[l],
+			Differences: compareWidgets(l, r),
+		})
+	}
+	for _, r := range rightWidgets {
+		if !taken[r] {
+			diff.OnlyInRight = append(diff.OnlyInRight, r.ref)
+		}
+	}
+	return diff
+}
+
+// compareWidgets returns the properties that differ between two widgets
+func compareWidgets(l, r *diffWidget) []FieldDiff {
+	var diffs []FieldDiff
+	compare := func(field string, left, right []string) {
+		if !slices.Equal(left, right) {
+			diffs = append(diffs, FieldDiff{Field: field, Left: left, Right: right})
+		}
+	}
+
+	compare("title", nonEmpty(l.ref.Title), nonEmpty(r.ref.Title))
+	compare("visualization", nonEmpty(l.viz), nonEmpty(r.viz))
+	compare("queries", queryTexts(l.queries), queryTexts(r.queries))
+	compare("events", querySet(l.queries, func(q NRQLQuery) []string { return nonEmpty(q.EventType) }),
+		querySet(r.queries, func(q NRQLQuery) []string { return nonEmpty(q.EventType) }))
+	compare("metrics", querySet(l.queries, func(q NRQLQuery) []string { return q.Attributes }),
+		querySet(r.queries, func(q NRQLQuery) []string { return q.Attributes }))
+	compare("facets", querySet(l.queries, func(q NRQLQuery) []string { return q.Facets }),
+		querySet(r.queries, func(q NRQLQuery) []string { return q.Facets }))
+	return diffs
+}
+
+// queryTexts returns the queries in order with their whitespace collapsed
+func queryTexts(queries []NRQLQuery) []string {
+	texts := make([]string, len(queries))
+	for i, q := range queries {
+		texts[i] = strings.Join(strings.Fields(q.Query), " ")
+	}
+	return texts
+}
+
+// querySet returns the sorted, distinct values of values across queries
+func querySet(queries []NRQLQuery, values func(NRQLQuery) []string) []string {
+	seen := make(map[string]bool)
+	var set []string
+	for _, q := range queries {
+		for _, v := range values(q) {
+			if !seen[v] {
+				seen[v] = true
+				set = append(set, v)
+			}
+		}
+	}
+	sort.Strings(set)
+	return set
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
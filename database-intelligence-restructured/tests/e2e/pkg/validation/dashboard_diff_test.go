@@ -0,0 +1,131 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadDiffDashboards(t *testing.T) (*DashboardParser, *DashboardParser) {
+	t.Helper()
+	left, err := LoadDashboard("testdata/dashboards/ohi.json")
+	require.NoError(t, err)
+	right, err := LoadDashboard("testdata/dashboards/otel.json")
+	require.NoError(t, err)
+	return left, right
+}
+
+func TestDiffDashboards(t *testing.T) {
+	left, right := loadDiffDashboards(t)
+	diff := DiffDashboards(left, right)
+
+	require.Len(t, diff.Matched, 4)
+	assert.False(t, diff.Equal())
+
+	// Only whitespace differs in the query
+	assert.Equal(t, WidgetDiff{
+		Left:      WidgetRef{Page: "Queries", Title: "Database", Row: 1, Column: 1},
+		Right:     WidgetRef{Page: "Queries", Title: "Database", Row: 1, Column: 1},
+		MatchedBy: MatchedByTitle,
+	}, diff.Matched[0])
+
+	// Titles are aligned ignoring case and spacing, but still reported
+	elapsed := diff.Matched[1]
+	assert.Equal(t, MatchedByTitle, elapsed.MatchedBy)
+	assert.Equal(t, []FieldDiff{
+		{Field: "title", Left: []string{"Average elapsed time"}, Right: []string{"Average  Elapsed Time"}},
+		{
+			Field: "queries",
+			Left:  []string{"SELECT average(avg_elapsed_time_ms) FROM PostgresSlowQueries FACET query_id TIMESERIES"},
+			Right: []string{"SELECT average(avg_elapsed_time_ms) FROM PostgresSlowQueries FACET db.query.id TIMESERIES"},
+		},
+		{Field: "facets", Left: []string{"query_id"}, Right: []string{"db.query.id"}},
+	}, elapsed.Differences)
+
+	// A renamed widget is found at its position
+	waits := diff.Matched[2]
+	assert.Equal(t, MatchedByPosition, waits.MatchedBy)
+	assert.Equal(t, "Wait time by event", waits.Right.Title)
+	fields := make(map[string]FieldDiff)
+	for _, d := range waits.Differences {
+		fields[d.Field] = d
+	}
+	assert.ElementsMatch(t, []string{"title", "queries", "events", "metrics", "facets"}, keys(fields))
+	assert.Equal(t, FieldDiff{Field: "events", Left: []string{"PostgresWaitEvents"}, Right: []string{"Metric"}}, fields["events"])
+	assert.Equal(t, FieldDiff{Field: "metrics", Left: []string{"total_wait_time_ms"}, Right: []string{"postgres.wait_events"}}, fields["metrics"])
+
+	// Both queries of a widget are compared
+	assert.Equal(t, "Executions", diff.Matched[3].Left.Title)
+	assert.Empty(t, diff.Matched[3].Differences)
+
+	assert.Equal(t, []WidgetRef{{Page: "Queries", Title: "Blocking sessions", Row: 4, Column: 7}}, diff.OnlyInLeft)
+	assert.Equal(t, []WidgetRef{{Page: "Queries", Title: "Connections", Row: 10, Column: 1}}, diff.OnlyInRight)
+	assert.Len(t, diff.Changed(), 2)
+}
+
+func TestDiffDashboardsIdentical(t *testing.T) {
+	left, _ := loadDiffDashboards(t)
+	diff := DiffDashboards(left, left)
+	assert.True(t, diff.Equal())
+	assert.Len(t, diff.Matched, 5)
+	assert.Empty(t, diff.OnlyInLeft)
+	assert.Empty(t, diff.OnlyInRight)
+}
+
+func TestDiffDashboardsRepeatedTitles(t *testing.T) {
+	dashboard := func(widgets ...string) *DashboardParser {
+		p := NewDashboardParser()
+		require.NoError(t, p.ParseDashboard([]byte(`{"pages": [{"name": "Overview", "widgets": [`+strings.Join(widgets, ",")+`]}]}`)))
+		return p
+	}
+	widget := func(title string, row int, query string) string {
+		return `{"title": "` + title + `", "layout": {"row": ` + string(rune('0'+row)) + `, "column": 1},
+			"rawConfiguration": {"nrqlQueries": [{"query": "` + query + `"}]}}`
+	}
+
+	left := dashboard(
+		widget("Throughput", 1, "SELECT count(*) FROM PostgresSlowQueries"),
+		widget("Throughput", 2, "SELECT count(*) FROM PostgresWaitEvents"),
+	)
+	right := dashboard(
+		widget("Throughput", 1, "SELECT count(*) FROM PostgresSlowQueries"),
+		widget("Throughput", 2, "SELECT count(*) FROM PostgresWaitEvents"),
+	)
+
+	// Repeated titles pair up in dashboard order
+	diff := DiffDashboards(left, right)
+	require.Len(t, diff.Matched, 2)
+	assert.True(t, diff.Equal())
+	assert.Equal(t, 2, diff.Matched[1].Right.Row)
+}
+
+func TestDashboardDiffPrint(t *testing.T) {
+	left, right := loadDiffDashboards(t)
+
+	var out strings.Builder
+	require.NoError(t, DiffDashboards(left, right).Print(&out))
+	assert.Equal(t, `~ "Average elapsed time" (page "Queries", row 1, column 4), matched by title to "Average  Elapsed Time" (page "Queries", row 1, column 4)
+    title: [Average elapsed time] -> [Average  Elapsed Time]
+    queries: [SELECT average(avg_elapsed_time_ms) FROM PostgresSlowQueries FACET query_id TIMESERIES] -> [SELECT average(avg_elapsed_time_ms) FROM PostgresSlowQueries FACET db.query.id TIMESERIES]
+    facets: [query_id] -> [db.query.id]
+~ "Wait events" (page "Queries", row 4, column 1), matched by position to "Wait time by event" (page "Queries", row 4, column 1)
+    title: [Wait events] -> [Wait time by event]
+    queries: [SELECT latest(total_wait_time_ms) FROM PostgresWaitEvents FACET wait_event_name] -> [SELECT sum(postgres.wait_events) FROM Metric FACET db.wait_event.name]
+    events: [PostgresWaitEvents] -> [Metric]
+    metrics: [total_wait_time_ms] -> [postgres.wait_events]
+    facets: [wait_event_name] -> [db.wait_event.name]
+- "Blocking sessions" (page "Queries", row 4, column 7) only in left
++ "Connections" (page "Queries", row 10, column 1) only in right
+4 widgets matched, 2 differ, 1 only in left, 1 only in right
+`, out.String())
+}
+
+func keys(m map[string]FieldDiff) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
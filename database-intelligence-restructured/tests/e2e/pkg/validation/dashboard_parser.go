@@ -13,19 +13,29 @@ type DashboardParser struct {
 	nrqlQueries   []NRQLQuery
 	ohiEvents     map[string]*OHIEvent
 	attributes    map[string][]string
+	widgetCount   int
 }
 
 // NRQLQuery represents a parsed NRQL query from the dashboard
 type NRQLQuery struct {
-	WidgetTitle    string
-	Query          string
-	EventType      string
-	Metrics        []string
-	Attributes     []string
-	Aggregations   []string
-	TimeWindow     string
-	Facets         []string
-	Visualization  string
+	// Page is the name of the page holding the widget, and PageIndex its
+	// position among the pages
+	Page      string
+	PageIndex int
+	// WidgetIndex numbers the widgets of the dashboard in order; the
+	// queries of one widget share it
+	WidgetIndex int
+	Layout      WidgetLayout
+
+	WidgetTitle   string
+	Query         string
+	EventType     string
+	Metrics       []string
+	Attributes    []string
+	Aggregations  []string
+	TimeWindow    string
+	Facets        []string
+	Visualization string
 }
 
 // OHIEvent represents an OHI event type with its fields
@@ -39,6 +49,7 @@ type OHIEvent struct {
 
 // DashboardWidget represents a widget from the dashboard
 type DashboardWidget struct {
+	Page               string
 	Title              string
 	NRQLQuery          string
 	VisualizationType  string
@@ -80,8 +91,8 @@ func (p *DashboardParser) ParseDashboard(dashboardData []byte) error {
 	}
 
 	// Parse each page
-	for _, page := range pages {
-		if err := p.parsePage(page.(map[string]interface{})); err != nil {
+	for i, page := range pages {
+		if err := p.parsePage(i, page.(map[string]interface{})); err != nil {
 			return fmt.Errorf("failed to parse page: %w", err)
 		}
 	}
@@ -171,7 +182,7 @@ func (p *DashboardParser) initializeOHIEvents() {
 }
 
 // parsePage parses a dashboard page
-func (p *DashboardParser) parsePage(page map[string]interface{}) error {
+func (p *DashboardParser) parsePage(pageIndex int, page map[string]interface{}) error {
 	pageName := page["name"].(string)
 	
 	widgets, ok := page["widgets"].([]interface{})
@@ -181,7 +192,8 @@ func (p *DashboardParser) parsePage(page map[string]interface{}) error {
 
 	for _, widget := range widgets {
 		widgetMap := widget.(map[string]interface{})
-		if err := p.parseWidget(pageName, widgetMap); err != nil {
+		p.widgetCount++
+		if err := p.parseWidget(pageIndex, pageName, widgetMap); err != nil {
 			return fmt.Errorf("failed to parse widget: %w", err)
 		}
 	}
@@ -190,8 +202,9 @@ func (p *DashboardParser) parsePage(page map[string]interface{}) error {
 }
 
 // parseWidget parses a dashboard widget
-func (p *DashboardParser) parseWidget(pageName string, widget map[string]interface{}) error {
+func (p *DashboardParser) parseWidget(pageIndex int, pageName string, widget map[string]interface{}) error {
 	title := widget["title"].(string)
+	layout := parseLayout(widget["layout"])
 	
 	// Extract visualization type
 	vizType := ""
@@ -209,6 +222,10 @@ func (p *DashboardParser) parseWidget(pageName string, widget map[string]interfa
 				parsedQuery := p.parseNRQL(query)
 				parsedQuery.WidgetTitle = title
 				parsedQuery.Visualization = vizType
+				parsedQuery.Page = pageName
+				parsedQuery.PageIndex = pageIndex
+				parsedQuery.WidgetIndex = p.widgetCount - 1
+				parsedQuery.Layout = layout
 				
 				p.nrqlQueries = append(p.nrqlQueries, parsedQuery)
 				
@@ -226,6 +243,21 @@ func (p *DashboardParser) parseWidget(pageName string, widget map[string]interfa
 	return nil
 }
 
+// parseLayout reads a widget's layout; missing values are zero
+func parseLayout(value interface{}) WidgetLayout {
+	layout, _ := value.(map[string]interface{})
+	number := func(key string) int {
+		n, _ := layout[key].(float64)
+		return int(n)
+	}
+	return WidgetLayout{
+		Column: number("column"),
+		Row:    number("row"),
+		Width:  number("width"),
+		Height: number("height"),
+	}
+}
+
 // parseNRQL parses a NRQL query to extract its components
 func (p *DashboardParser) parseNRQL(query string) NRQLQuery {
 	parsed := NRQLQuery{
@@ -240,7 +272,7 @@ func (p *DashboardParser) parseNRQL(query string) NRQLQuery {
 	normalizedQuery := strings.ToUpper(query)
 
 	// Extract event type from FROM clause
-	fromRegex := regexp.MustCompile(`FROM\s+(\w+)`)
+	fromRegex := regexp.MustCompile(`(?i)\bFROM\s+(\w+)`)
 	if matches := fromRegex.FindStringSubmatch(query); len(matches) > 1 {
 		parsed.EventType = matches[1]
 	}
@@ -252,8 +284,8 @@ func (p *DashboardParser) parseNRQL(query string) NRQLQuery {
 	}
 
 	// Extract FACET clause
-	facetRegex := regexp.MustCompile(`FACET\s+(.+?)(?:\s+LIMIT|\s+SINCE|\s+TIMESERIES|$)`)
-	if matches := facetRegex.FindStringSubmatch(normalizedQuery); len(matches) > 1 {
+	facetRegex := regexp.MustCompile(`(?i)FACET\s+(.+?)(?:\s+LIMIT|\s+SINCE|\s+TIMESERIES|$)`)
+	if matches := facetRegex.FindStringSubmatch(query); len(matches) > 1 {
 		facets := strings.Split(matches[1], ",")
 		for _, facet := range facets {
 			parsed.Facets = append(parsed.Facets, strings.TrimSpace(facet))
@@ -310,11 +342,13 @@ func (p *DashboardParser) GetWidgetValidationTests() []DashboardWidget {
 	
 	for _, query := range p.nrqlQueries {
 		widget := DashboardWidget{
+			Page:               query.Page,
 			Title:              query.WidgetTitle,
 			NRQLQuery:          query.Query,
 			VisualizationType:  query.Visualization,
 			RequiredMetrics:    query.Metrics,
 			RequiredAttributes: query.Attributes,
+			Layout:             query.Layout,
 		}
 		
 		widgets = append(widgets, widget)
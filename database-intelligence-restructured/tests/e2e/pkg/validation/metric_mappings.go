@@ -0,0 +1,379 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MetricMappings is a metric_mappings.yaml that passed schema validation
+type MetricMappings struct {
+	// Events are the OHI event types, keyed by name
+	Events map[string]*EventMapping
+	// Fields are the metric and attribute mappings of each event, keyed by
+	// event then OHI field name
+	Fields map[string]map[string]*MetricMapping
+	// Transformations are the defined transformations and their descriptions
+	Transformations map[string]string
+}
+
+// MappingError is a schema violation in a metric mappings file
+type MappingError struct {
+	File    string
+	Line    int
+	Event   string
+	Message string
+}
+
+func (e MappingError) Error() string {
+	if e.Event == "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: event %s: %s", e.File, e.Line, e.Event, e.Message)
+}
+
+// MappingErrors are all the schema violations found in a file, in file order
+type MappingErrors []MappingError
+
+func (e MappingErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// schemaField describes one key of a mapping in the file
+type schemaField struct {
+	required bool
+	kind     yaml.Kind
+	enum     []string
+}
+
+var (
+	topLevelSchema = map[string]schemaField{
+		"ohi_to_otel_mappings": {required: true, kind: yaml.MappingNode},
+		"transformations":      {kind: yaml.MappingNode},
+		"special_values":       {kind: yaml.MappingNode},
+		"validation_rules":     {kind: yaml.MappingNode},
+	}
+
+	eventSchema = map[string]schemaField{
+		"otel_metric_type": {required: true, kind: yaml.ScalarNode, enum: []string{"Metric", "Log", "Span"}},
+		"otel_filter":      {kind: yaml.ScalarNode},
+		"description":      {kind: yaml.ScalarNode},
+		"metrics":          {kind: yaml.MappingNode},
+		"attributes":       {kind: yaml.MappingNode},
+		"required_fields":  {kind: yaml.SequenceNode},
+		"optional_fields":  {kind: yaml.SequenceNode},
+	}
+
+	fieldSchema = map[string]schemaField{
+		"otel_name":           {required: true, kind: yaml.ScalarNode},
+		"type":                {required: true, kind: yaml.ScalarNode, enum: []string{"gauge", "counter", "sum", "histogram", "summary", "attribute"}},
+		"transformation":      {kind: yaml.ScalarNode},
+		"formula":             {kind: yaml.ScalarNode},
+		"unit":                {kind: yaml.ScalarNode},
+		"namespace_transform": {kind: yaml.ScalarNode},
+		"pii_safe":            {kind: yaml.ScalarNode},
+		"default_value":       {kind: yaml.ScalarNode},
+		"special_values":      {kind: yaml.MappingNode},
+		"description":         {kind: yaml.ScalarNode},
+	}
+
+	transformationSchema = map[string]schemaField{
+		"description": {required: true, kind: yaml.ScalarNode},
+		"formula":     {kind: yaml.ScalarNode},
+		"steps":       {kind: yaml.SequenceNode},
+		"example":     {kind: yaml.ScalarNode},
+	}
+)
+
+// calculatedName is the otel_name of a field computed by its formula
+const calculatedName = "calculated"
+
+var kindNames = map[yaml.Kind]string{
+	yaml.ScalarNode:   "a value",
+	yaml.MappingNode:  "a mapping",
+	yaml.SequenceNode: "a list",
+}
+
+// LoadMetricMappings reads and validates a metric_mappings.yaml. When the
+// file breaks the schema the error is a MappingErrors listing every problem
+// with its line.
+func LoadMetricMappings(path string) (*MetricMappings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric mappings: %w", err)
+	}
+	return ParseMetricMappings(path, data)
+}
+
+// ParseMetricMappings validates the contents of a metric mappings file and
+// returns its mappings; file is only used in error messages
+func ParseMetricMappings(file string, data []byte) (*MetricMappings, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, MappingErrors{{File: file, Line: 1, Message: "file is empty"}}
+	}
+
+	v := &mappingValidator{
+		file: file,
+		mappings: &MetricMappings{
+			Events:          make(map[string]*EventMapping),
+			Fields:          make(map[string]map[string]*MetricMapping),
+			Transformations: make(map[string]string),
+		},
+	}
+	v.validateFile(doc.Content[0])
+
+	if len(v.errs) > 0 {
+		sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Line < v.errs[j].Line })
+		return nil, v.errs
+	}
+	return v.mappings, nil
+}
+
+// mappingValidator walks the YAML nodes, so errors carry line numbers, and
+// fills in the mappings as it goes
+type mappingValidator struct {
+	file     string
+	errs     MappingErrors
+	mappings *MetricMappings
+
+	// transformations is nil when the file does not define any, in which case
+	// transformation names are not checked
+	transformations map[string]bool
+}
+
+func (v *mappingValidator) errorf(line int, event, format string, args ...any) {
+	v.errs = append(v.errs, MappingError{
+		File:    v.file,
+		Line:    line,
+		Event:   event,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkKeys validates the keys of node, owned by the key at line, against
+// schema and returns the key and value nodes by key. what prefixes messages,
+// e.g. "metric db.commitsPerSecond: ".
+func (v *mappingValidator) checkKeys(node *yaml.Node, line int, schema map[string]schemaField, event, what string) map[string][2]*yaml.Node {
+	values := make(map[string][2]*yaml.Node)
+	if node.Kind != yaml.MappingNode {
+		v.errorf(node.Line, event, "%smust be a mapping", what)
+		return values
+	}
+
+	for _, pair := range mappingPairs(node) {
+		key, value := pair[0], pair[1]
+		if _, dup := values[key.Value]; dup {
+			v.errorf(key.Line, event, "%sduplicate key %q", what, key.Value)
+			continue
+		}
+		values[key.Value] = pair
+
+		field, known := schema[key.Value]
+		switch {
+		case !known:
+			v.errorf(key.Line, event, "%sunknown key %q (expected one of %s)", what, key.Value, strings.Join(schemaKeys(schema), ", "))
+		case value.Kind != field.kind && !(field.kind != yaml.ScalarNode && isNull(value)):
+			v.errorf(value.Line, event, "%s%s must be %s", what, key.Value, kindNames[field.kind])
+		case len(field.enum) > 0 && !contains(field.enum, value.Value):
+			v.errorf(value.Line, event, "%s%s %q is not one of %s", what, key.Value, value.Value, strings.Join(field.enum, ", "))
+		}
+	}
+
+	for _, key := range schemaKeys(schema) {
+		if _, ok := values[key]; !ok && schema[key].required {
+			v.errorf(line, event, "%s%s is required", what, key)
+		}
+	}
+	return values
+}
+
+func (v *mappingValidator) validateFile(root *yaml.Node) {
+	sections := v.checkKeys(root, root.Line, topLevelSchema, "", "")
+
+	// Transformations first, so fields can be checked against them
+	if pair, ok := sections["transformations"]; ok && pair[1].Kind == yaml.MappingNode {
+		v.transformations = make(map[string]bool)
+		for _, t := range mappingPairs(pair[1]) {
+			name := t[0].Value
+			if v.transformations[name] {
+				v.errorf(t[0].Line, "", "duplicate transformation %q", name)
+				continue
+			}
+			v.transformations[name] = true
+			keys := v.checkKeys(t[1], t[0].Line, transformationSchema, "", fmt.Sprintf("transformation %s: ", name))
+			if d, ok := keys["description"]; ok {
+				v.mappings.Transformations[name] = d[1].Value
+			}
+		}
+	}
+
+	pair, ok := sections["ohi_to_otel_mappings"]
+	if !ok || pair[1].Kind != yaml.MappingNode {
+		return
+	}
+	events := mappingPairs(pair[1])
+	if len(events) == 0 {
+		v.errorf(pair[0].Line, "", "ohi_to_otel_mappings has no events")
+	}
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		if seen[e[0].Value] {
+			v.errorf(e[0].Line, "", "duplicate event %q", e[0].Value)
+			continue
+		}
+		seen[e[0].Value] = true
+		v.validateEvent(e[0], e[1])
+	}
+}
+
+func (v *mappingValidator) validateEvent(key, node *yaml.Node) {
+	event := key.Value
+	values := v.checkKeys(node, key.Line, eventSchema, event, "")
+
+	mapping := &EventMapping{OHIEvent: event}
+	if pair, ok := values["otel_metric_type"]; ok {
+		mapping.OTELMetricType = pair[1].Value
+	}
+	if pair, ok := values["otel_filter"]; ok {
+		mapping.OTELFilter = pair[1].Value
+	}
+
+	fields := make(map[string]*MetricMapping)
+	fieldLines := make(map[string]int)
+	for _, section := range []string{"metrics", "attributes"} {
+		pair, ok := values[section]
+		if !ok || pair[1].Kind != yaml.MappingNode {
+			continue
+		}
+		kind := strings.TrimSuffix(section, "s")
+		for _, f := range mappingPairs(pair[1]) {
+			name := f[0].Value
+			if line, dup := fieldLines[name]; dup {
+				v.errorf(f[0].Line, event, "%s %s is already mapped on line %d", kind, name, line)
+				continue
+			}
+			fieldLines[name] = f[0].Line
+			fields[name] = v.validateField(event, kind, f[0], f[1])
+		}
+	}
+	if len(fieldLines) == 0 && node.Kind == yaml.MappingNode {
+		v.errorf(key.Line, event, "has no metrics or attributes")
+	}
+
+	mapping.RequiredFields = v.fieldList(event, values, "required_fields", fieldLines)
+	mapping.OptionalFields = v.fieldList(event, values, "optional_fields", fieldLines)
+
+	v.mappings.Events[event] = mapping
+	v.mappings.Fields[event] = fields
+}
+
+func (v *mappingValidator) validateField(event, kind string, key, node *yaml.Node) *MetricMapping {
+	what := fmt.Sprintf("%s %s: ", kind, key.Value)
+	values := v.checkKeys(node, key.Line, fieldSchema, event, what)
+
+	field := &MetricMapping{OHIName: key.Value}
+	get := func(name string) (string, int, bool) {
+		pair, ok := values[name]
+		if !ok || pair[1].Kind != yaml.ScalarNode {
+			return "", 0, false
+		}
+		return pair[1].Value, pair[1].Line, true
+	}
+
+	field.OTELName, _, _ = get("otel_name")
+	typ, _, _ := get("type")
+	field.Type = MetricType(typ)
+	field.Formula, _, _ = get("formula")
+	field.Unit, _, _ = get("unit")
+
+	transformation, line, hasTransformation := get("transformation")
+	field.Transformation = transformation
+	switch {
+	case field.OTELName == calculatedName && field.Formula == "":
+		v.errorf(key.Line, event, "%sformula is required when otel_name is %q", what, calculatedName)
+	case !hasTransformation && field.Formula == "" && node.Kind == yaml.MappingNode:
+		v.errorf(key.Line, event, "%stransformation is required unless a formula is given", what)
+	}
+	if kind == "metric" && field.Type == "attribute" {
+		v.errorf(key.Line, event, "%stype attribute belongs under attributes", what)
+	}
+
+	if hasTransformation {
+		v.checkTransformation(event, what, "transformation", transformation, line)
+	}
+	if namespace, line, ok := get("namespace_transform"); ok {
+		v.checkTransformation(event, what, "namespace_transform", namespace, line)
+	}
+	return field
+}
+
+func (v *mappingValidator) checkTransformation(event, what, key, name string, line int) {
+	if v.transformations != nil && !v.transformations[name] {
+		v.errorf(line, event, "%s%s %q is not defined under transformations", what, key, name)
+	}
+}
+
+// fieldList returns the names in a required_fields or optional_fields list,
+// each of which must be a field of the event
+func (v *mappingValidator) fieldList(event string, values map[string][2]*yaml.Node, key string, fields map[string]int) []string {
+	pair, ok := values[key]
+	if !ok || pair[1].Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var names []string
+	for _, item := range pair[1].Content {
+		if item.Kind != yaml.ScalarNode {
+			v.errorf(item.Line, event, "%s entries must be field names", key)
+			continue
+		}
+		if _, ok := fields[item.Value]; !ok {
+			v.errorf(item.Line, event, "%s names %q, which is not a metric or attribute of the event", key, item.Value)
+			continue
+		}
+		names = append(names, item.Value)
+	}
+	return names
+}
+
+// mappingPairs returns the key and value nodes of a mapping node
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	return pairs
+}
+
+func schemaKeys(schema map[string]schemaField) []string {
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package validation
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mappingFixture(name string) string {
+	return filepath.Join("testdata", "metric_mappings", name)
+}
+
+// mappingErrorLines loads a fixture that must fail and returns its errors
+func mappingErrorLines(t *testing.T, name string) []string {
+	_, err := LoadMetricMappings(mappingFixture(name))
+	require.Error(t, err)
+
+	var errs MappingErrors
+	require.True(t, errors.As(err, &errs), "expected MappingErrors, got %v", err)
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+	}
+	return lines
+}
+
+func TestLoadMetricMappings_Valid(t *testing.T) {
+	mappings, err := LoadMetricMappings(mappingFixture("valid.yaml"))
+	require.NoError(t, err)
+
+	require.Contains(t, mappings.Events, "PostgreSQLSample")
+	event := mappings.Events["PostgreSQLSample"]
+	assert.Equal(t, "Metric", event.OTELMetricType)
+	assert.Equal(t, "db.system = 'postgresql'", event.OTELFilter)
+	assert.Equal(t, []string{"db.commitsPerSecond"}, event.RequiredFields)
+
+	commits := mappings.Fields["PostgreSQLSample"]["db.commitsPerSecond"]
+	require.NotNil(t, commits)
+	assert.Equal(t, "postgresql.commits", commits.OTELName)
+	assert.Equal(t, MetricTypeCounter, commits.Type)
+	assert.Equal(t, "rate_per_second", commits.Transformation)
+
+	assert.Len(t, mappings.Fields["PostgresSlowQueries"], 2)
+	assert.Equal(t, "No transformation needed", mappings.Transformations["direct"])
+}
+
+func TestLoadMetricMappings_ShippedFile(t *testing.T) {
+	mappings, err := LoadMetricMappings("../../configs/validation/metric_mappings.yaml")
+	require.NoError(t, err)
+	assert.Len(t, mappings.Events, 6)
+}
+
+func TestLoadMetricMappings_MissingFields(t *testing.T) {
+	path := mappingFixture("missing_fields.yaml")
+	assert.Equal(t, []string{
+		path + ":2: event PostgreSQLSample: otel_metric_type is required",
+		path + ":5: event PostgreSQLSample: metric db.commitsPerSecond: otel_name is required",
+		path + ":9: event PostgresWaitEvents: has no metrics or attributes",
+	}, mappingErrorLines(t, "missing_fields.yaml"))
+}
+
+func TestLoadMetricMappings_BadValues(t *testing.T) {
+	path := mappingFixture("bad_values.yaml")
+	assert.Equal(t, []string{
+		path + `:3: event PostgresSlowQueries: otel_metric_type "Metrics" is not one of Metric, Log, Span`,
+		path + `:11: event PostgresSlowQueries: attribute execution_count: type "guage" is not one of gauge, counter, sum, histogram, summary, attribute`,
+		path + `:16: event PostgresSlowQueries: attribute query_text: transformation "anonymise" is not defined under transformations`,
+	}, mappingErrorLines(t, "bad_values.yaml"))
+}
+
+func TestLoadMetricMappings_BadStructure(t *testing.T) {
+	path := mappingFixture("bad_structure.yaml")
+	assert.Equal(t, []string{
+		path + `:4: event PostgresBlockingSessions: required_fields names "blocking_pids", which is not a metric or attribute of the event`,
+		path + `:5: event PostgresBlockingSessions: unknown key "attribute" (expected one of attributes, description, metrics, optional_fields, otel_filter, otel_metric_type, required_fields)`,
+		path + `:11: event PostgresBlockingSessions: metric blocked_pid: type attribute belongs under attributes`,
+		path + `:15: event PostgresBlockingSessions: metric db.blockedRatio: formula is required when otel_name is "calculated"`,
+		path + `:19: event PostgresBlockingSessions: attribute blocked_pid is already mapped on line 11`,
+	}, mappingErrorLines(t, "bad_structure.yaml"))
+}
+
+func TestParseMetricMappings_Syntax(t *testing.T) {
+	_, err := ParseMetricMappings("inline.yaml", []byte("ohi_to_otel_mappings: [unclosed"))
+	assert.ErrorContains(t, err, "failed to parse inline.yaml")
+
+	_, err = ParseMetricMappings("empty.yaml", nil)
+	assert.EqualError(t, err, "empty.yaml:1: file is empty")
+
+	_, err = ParseMetricMappings("top.yaml", []byte("mappings: {}\n"))
+	assert.EqualError(t, err, "top.yaml:1: unknown key \"mappings\" (expected one of ohi_to_otel_mappings, special_values, transformations, validation_rules)\n"+
+		"top.yaml:1: ohi_to_otel_mappings is required")
+}
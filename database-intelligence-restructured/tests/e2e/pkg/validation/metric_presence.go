@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DefaultPresenceSince is the window presence checks look at unless a time
+// window overrides it
+const DefaultPresenceSince = "SINCE 1 hour ago"
+
+// PresenceCheck checks that one mapped OTEL metric has data in NRDB
+type PresenceCheck struct {
+	// Metric is the OTEL metric name
+	Metric string
+	// Sources are the OHI fields mapped to the metric, as event.field
+	Sources []string
+	// Query counts the metric's data points
+	Query string
+}
+
+// PresenceChecks generates a check for every OTEL metric named in the
+// mappings, so the checks follow the mappings file as it changes. Only
+// events mapped to OTEL metrics are checked; attributes, and fields
+// calculated by a formula, are not metrics of their own. where, if set,
+// limits every query, e.g. to one collector's hosts. The checks are sorted
+// by metric name.
+func PresenceChecks(mappings *MetricMappings, where string) []PresenceCheck {
+	sources := make(map[string][]string)
+	for event, fields := range mappings.Fields {
+		if mappings.Events[event].OTELMetricType != "Metric" {
+			continue
+		}
+		for name, field := range fields {
+			if field.Type == "attribute" || field.OTELName == calculatedName || field.OTELName == "" {
+				continue
+			}
+			sources[field.OTELName] = append(sources[field.OTELName], event+"."+name)
+		}
+	}
+
+	checks := make([]PresenceCheck, 0, len(sources))
+	for metric, fields := range sources {
+		sort.Strings(fields)
+		checks = append(checks, PresenceCheck{
+			Metric:  metric,
+			Sources: fields,
+			Query:   PresenceQuery(metric, where),
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Metric < checks[j].Metric })
+	return checks
+}
+
+// PresenceQuery counts the data points of metric over DefaultPresenceSince
+func PresenceQuery(metric, where string) string {
+	condition := "metricName = '" + strings.ReplaceAll(metric, "'", `\'`) + "'"
+	if where != "" {
+		condition += " AND (" + where + ")"
+	}
+	return "SELECT count(*) AS datapoints FROM Metric WHERE " + condition + " " + DefaultPresenceSince
+}
+
+// BatchQueryFunc runs NRQL queries together and returns the result rows of
+// each, in order, as the NRDB client's QueryBatch does
+type BatchQueryFunc func(ctx context.Context, nrqls []string) ([][]map[string]interface{}, error)
+
+// PresenceResult is the outcome of one presence check
+type PresenceResult struct {
+	PresenceCheck
+	DataPoints int64
+}
+
+// Present reports whether the metric had any data points
+func (r PresenceResult) Present() bool {
+	return r.DataPoints > 0
+}
+
+// PresenceReport holds the results of every presence check
+type PresenceReport struct {
+	Results []PresenceResult
+}
+
+// Missing returns the results of the metrics without data points
+func (r PresenceReport) Missing() []PresenceResult {
+	var missing []PresenceResult
+	for _, result := range r.Results {
+		if !result.Present() {
+			missing = append(missing, result)
+		}
+	}
+	return missing
+}
+
+// OK reports whether every mapped metric is present
+func (r PresenceReport) OK() bool {
+	return len(r.Missing()) == 0
+}
+
+// Print writes the missing metrics, with the OHI fields mapped to each, and
+// a summary line
+func (r PresenceReport) Print(w io.Writer) error {
+	missing := r.Missing()
+	for _, result := range missing {
+		if _, err := fmt.Fprintf(w, "❌ %s: no data (mapped from %s)\n", result.Metric, strings.Join(result.Sources, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d mapped metrics present, %d missing\n",
+		len(r.Results)-len(missing), len(r.Results), len(missing))
+	return err
+}
+
+// CheckPresence runs the checks through query, batchSize queries per
+// request, and returns the data point count of every metric
+func CheckPresence(ctx context.Context, checks []PresenceCheck, batchSize int, query BatchQueryFunc) (PresenceReport, error) {
+	if batchSize <= 0 {
+		batchSize = len(checks)
+	}
+
+	report := PresenceReport{Results: make([]PresenceResult, 0, len(checks))}
+	for start := 0; start < len(checks); start += batchSize {
+		batch := checks[start:min(start+batchSize, len(checks))]
+
+		queries := make([]string, len(batch))
+		for i, check := range batch {
+			queries[i] = check.Query
+		}
+		results, err := query(ctx, queries)
+		if err != nil {
+			return PresenceReport{}, err
+		}
+		if len(results) != len(batch) {
+			return PresenceReport{}, fmt.Errorf("expected %d query results, got %d", len(batch), len(results))
+		}
+
+		for i, check := range batch {
+			dataPoints, err := parseDataPoints(results[i])
+			if err != nil {
+				return PresenceReport{}, fmt.Errorf("%s: %w", check.Metric, err)
+			}
+			report.Results = append(report.Results, PresenceResult{PresenceCheck: check, DataPoints: dataPoints})
+		}
+	}
+	return report, nil
+}
+
+// parseDataPoints reads the datapoints count of a presence query result.
+// NRDB returns numbers as JSON numbers, which decode to float64.
+func parseDataPoints(rows []map[string]interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	switch count := rows[0]["datapoints"].(type) {
+	case float64:
+		return int64(count), nil
+	case int64:
+		return count, nil
+	case int:
+		return int64(count), nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unexpected datapoints value %v", count)
+	}
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceChecks(t *testing.T) {
+	mappings, err := LoadMetricMappings(mappingFixture("presence.yaml"))
+	require.NoError(t, err)
+
+	checks := PresenceChecks(mappings, "")
+	// One check per metric; calculated fields, attributes and log events
+	// are skipped
+	require.Len(t, checks, 2)
+	assert.Equal(t, PresenceCheck{
+		Metric:  "postgresql.blocks_read",
+		Sources: []string{"PostgreSQLSample.db.reads.blocksPerSecond"},
+		Query:   "SELECT count(*) AS datapoints FROM Metric WHERE metricName = 'postgresql.blocks_read' SINCE 1 hour ago",
+	}, checks[0])
+	assert.Equal(t, "postgresql.commits", checks[1].Metric)
+	assert.Equal(t, []string{"PostgreSQLSample.db.commitsPerSecond", "PostgresqlDatabaseSample.db.commitsPerSecond"}, checks[1].Sources)
+
+	checks = PresenceChecks(mappings, "host.name = 'db-1'")
+	assert.Equal(t,
+		"SELECT count(*) AS datapoints FROM Metric WHERE metricName = 'postgresql.commits' AND (host.name = 'db-1') SINCE 1 hour ago",
+		checks[1].Query)
+}
+
+func TestPresenceChecks_ShippedFile(t *testing.T) {
+	mappings, err := LoadMetricMappings("../../configs/validation/metric_mappings.yaml")
+	require.NoError(t, err)
+	assert.NotEmpty(t, PresenceChecks(mappings, ""))
+}
+
+// fakeNRDB answers presence queries from fixed data point counts and
+// records the batches it was sent
+type fakeNRDB struct {
+	counts  map[string]float64
+	batches [][]string
+}
+
+func (f *fakeNRDB) query(ctx context.Context, nrqls []string) ([][]map[string]interface{}, error) {
+	f.batches = append(f.batches, nrqls)
+	results := make([][]map[string]interface{}, len(nrqls))
+	for i, nrql := range nrqls {
+		for metric, count := range f.counts {
+			if nrql == PresenceQuery(metric, "") {
+				results[i] = []map[string]interface{}{{"datapoints": count}}
+			}
+		}
+	}
+	return results, nil
+}
+
+func TestCheckPresence(t *testing.T) {
+	checks := []PresenceCheck{
+		{Metric: "postgresql.backends", Sources: []string{"PostgreSQLSample.db.connections.active"}, Query: PresenceQuery("postgresql.backends", "")},
+		{Metric: "postgresql.commits", Sources: []string{"PostgreSQLSample.db.commitsPerSecond"}, Query: PresenceQuery("postgresql.commits", "")},
+		{Metric: "postgresql.rollbacks", Sources: []string{"PostgreSQLSample.db.rollbacksPerSecond"}, Query: PresenceQuery("postgresql.rollbacks", "")},
+	}
+	nrdb := &fakeNRDB{counts: map[string]float64{"postgresql.backends": 120, "postgresql.rollbacks": 0}}
+
+	report, err := CheckPresence(context.Background(), checks, 2, nrdb.query)
+	require.NoError(t, err)
+	assert.Len(t, nrdb.batches, 2, "queries are sent in batches")
+	assert.Len(t, nrdb.batches[0], 2)
+
+	require.Len(t, report.Results, 3)
+	assert.EqualValues(t, 120, report.Results[0].DataPoints)
+	assert.False(t, report.OK())
+	missing := report.Missing()
+	require.Len(t, missing, 2)
+	assert.Equal(t, "postgresql.commits", missing[0].Metric)
+	assert.Equal(t, "postgresql.rollbacks", missing[1].Metric)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Print(&out))
+	assert.Contains(t, out.String(), "postgresql.commits: no data (mapped from PostgreSQLSample.db.commitsPerSecond)")
+	assert.Contains(t, out.String(), "1 of 3 mapped metrics present, 2 missing")
+}
+
+func TestCheckPresence_QueryError(t *testing.T) {
+	checks := []PresenceCheck{{Metric: "postgresql.commits", Query: PresenceQuery("postgresql.commits", "")}}
+	failing := func(ctx context.Context, nrqls []string) ([][]map[string]interface{}, error) {
+		return nil, errors.New("401 Unauthorized")
+	}
+
+	_, err := CheckPresence(context.Background(), checks, 25, failing)
+	assert.ErrorContains(t, err, "401 Unauthorized")
+}
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strings"
 	"gopkg.in/yaml.v3"
+
+	"github.com/database-intelligence/db-intel/internal/newrelic"
 )
 
 type FullConfig struct {
@@ -226,7 +228,7 @@ func testNewRelicIntegration() {
 	if exporterConfig, ok := config.Exporters["otlp/newrelic"].(map[string]interface{}); ok {
 		// Check endpoint
 		if endpoint, ok := exporterConfig["endpoint"].(string); ok {
-			if strings.Contains(endpoint, "otlp.nr-data.net") || strings.Contains(endpoint, "OTLP_ENDPOINT") {
+			if newrelic.IsOTLPEndpoint(endpoint) || strings.Contains(endpoint, "OTLP_ENDPOINT") {
 				fmt.Println("   ✓ New Relic OTLP endpoint configured")
 			} else {
 				fmt.Println("   ✗ Invalid New Relic endpoint")
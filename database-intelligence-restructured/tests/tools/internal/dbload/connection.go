@@ -0,0 +1,207 @@
+// Package dbload has the database plumbing the load generators share:
+// connection monitoring, query timeouts, transaction retries, pool metrics,
+// DSN building and logging.
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// Connection monitoring limits; the maximum backoff is configurable
+const (
+	MinReconnectBackoff = 500 * time.Millisecond
+	pingTimeout         = 5 * time.Second
+)
+
+// isConnectionError reports whether err means the database could not be
+// reached, as during a restart, rather than that the query itself failed.
+// Queries failing this way are worth running again once the database is
+// back; anything else (deadlocks, lock timeouts, bad SQL) is not.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now, the server is starting up
+			return true
+		}
+		// Class 08 is connection exception
+		return pqErr.Code.Class() == "08"
+	}
+
+	var netErr *net.OpError
+	return errors.As(err, &netErr)
+}
+
+// ConnMonitor tracks whether the database is reachable. It pings every
+// interval; after a failed ping, or when a worker reports a connection
+// error, it pings with exponential backoff until the database answers again.
+// Workers check Connected and skip their turn during an outage instead of
+// piling up failing queries.
+type ConnMonitor struct {
+	ping       func(ctx context.Context) error
+	logger     *zap.Logger
+	interval   time.Duration
+	maxBackoff time.Duration
+
+	connected atomic.Bool
+	outages   atomic.Int64
+	// wake asks run to check the connection now
+	wake chan struct{}
+
+	mu        sync.Mutex
+	downSince time.Time
+}
+
+func NewConnMonitor(ping func(ctx context.Context) error, logger *zap.Logger, interval, maxBackoff time.Duration) *ConnMonitor {
+	m := &ConnMonitor{
+		ping:       ping,
+		logger:     logger,
+		interval:   interval,
+		maxBackoff: maxBackoff,
+		wake:       make(chan struct{}, 1),
+	}
+	m.connected.Store(true)
+	return m
+}
+
+// Connected reports whether the last check reached the database
+func (m *ConnMonitor) Connected() bool {
+	return m.connected.Load()
+}
+
+// Outages returns how many times the database became unreachable
+func (m *ConnMonitor) Outages() int64 {
+	return m.outages.Load()
+}
+
+// ReportError marks the database as unreachable when err is a connection
+// error and reports whether it was one
+func (m *ConnMonitor) ReportError(err error) bool {
+	if !isConnectionError(err) {
+		return false
+	}
+	m.markDown(err)
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Run checks the connection until ctx is done
+func (m *ConnMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+
+		err := m.check(ctx)
+		if err == nil {
+			// A worker may have reported an outage the ping did not see
+			m.markUp()
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		m.markDown(err)
+		m.waitForRecovery(ctx)
+	}
+}
+
+// waitForRecovery pings with exponential backoff until the database
+// answers or ctx is done
+func (m *ConnMonitor) waitForRecovery(ctx context.Context) {
+	backoff := MinReconnectBackoff
+	for {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		err := m.check(ctx)
+		if err == nil {
+			m.markUp()
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		m.logger.Debug("Database still unreachable", zap.Duration("retry_in", backoff), zap.Error(err))
+		backoff = nextBackoff(backoff, m.maxBackoff)
+	}
+}
+
+// check pings the database once
+func (m *ConnMonitor) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return m.ping(ctx)
+}
+
+// markDown records the start of an outage, once per outage
+func (m *ConnMonitor) markDown(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.connected.Load() {
+		return
+	}
+	m.connected.Store(false)
+	m.outages.Add(1)
+	m.downSince = time.Now()
+	m.logger.Warn("Database unreachable, pausing queries until it recovers", zap.Error(err))
+}
+
+// markUp records the end of an outage
+func (m *ConnMonitor) markUp() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.connected.Load() {
+		return
+	}
+	m.connected.Store(true)
+	m.logger.Info("Database reachable again, resuming queries",
+		zap.Duration("outage", time.Since(m.downSince).Round(time.Millisecond)))
+}
+
+// nextBackoff doubles backoff up to max
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+	if max > 0 && backoff > max {
+		return max
+	}
+	return backoff
+}
@@ -0,0 +1,179 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "bad conn", err: driver.ErrBadConn, want: true},
+		{name: "conn done", err: sql.ErrConnDone, want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, want: true},
+		{name: "reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "admin shutdown", err: &pq.Error{Code: "57P01"}, want: true},
+		{name: "starting up", err: &pq.Error{Code: "57P03"}, want: true},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, want: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, want: false},
+		{name: "lock timeout", err: &pq.Error{Code: "55P03"}, want: false},
+		{name: "syntax error", err: &pq.Error{Code: "42601"}, want: false},
+		{name: "query canceled", err: &pq.Error{Code: "57014"}, want: false},
+		{name: "no rows", err: sql.ErrNoRows, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: false},
+	}
+	for _, tt := range tests {
+		if got := isConnectionError(tt.err); got != tt.want {
+			t.Errorf("%s: isConnectionError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestQueryStats_RecordDisconnect(t *testing.T) {
+	running := context.Background()
+	var stats QueryStats
+
+	if got := stats.Record(running, running, &pq.Error{Code: "57P01"}); got != QueryDisconnected {
+		t.Errorf("Record(admin shutdown) = %v, want QueryDisconnected", got)
+	}
+	if got := stats.Record(running, running, &pq.Error{Code: "40P01"}); got != QueryFailed {
+		t.Errorf("Record(deadlock) = %v, want QueryFailed", got)
+	}
+	if stats.disconnects.Load() != 1 || stats.errors.Load() != 1 {
+		t.Errorf("disconnects, errors = %d, %d, want 1, 1", stats.disconnects.Load(), stats.errors.Load())
+	}
+}
+
+// fakeDB answers pings with the error it is set to
+type fakeDB struct {
+	err   atomic.Value
+	pings atomic.Int64
+}
+
+func (f *fakeDB) set(err error) { f.err.Store(&err) }
+
+func (f *fakeDB) ping(ctx context.Context) error {
+	f.pings.Add(1)
+	if err, ok := f.err.Load().(*error); ok {
+		return *err
+	}
+	return nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConnMonitor_RecoversAfterRestart(t *testing.T) {
+	db := &fakeDB{}
+	m := NewConnMonitor(db.ping, zap.NewNop(), 10*time.Millisecond, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	waitFor(t, "first ping", func() bool { return db.pings.Load() > 0 })
+	if !m.Connected() {
+		t.Fatal("should be connected while pings succeed")
+	}
+
+	db.set(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+	waitFor(t, "outage", func() bool { return !m.Connected() })
+	pings := db.pings.Load()
+
+	db.set(nil)
+	waitFor(t, "recovery", m.Connected)
+	if db.pings.Load() <= pings {
+		t.Error("recovery must be verified with a ping")
+	}
+	if got := m.Outages(); got != 1 {
+		t.Errorf("Outages = %d, want 1", got)
+	}
+}
+
+func TestConnMonitor_WorkerReportsOutage(t *testing.T) {
+	db := &fakeDB{}
+	db.set(driver.ErrBadConn)
+	// The interval is long, so only the worker's report triggers a check
+	m := NewConnMonitor(db.ping, zap.NewNop(), time.Hour, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	if m.ReportError(&pq.Error{Code: "40P01"}) {
+		t.Error("a deadlock is not a connection error")
+	}
+	if !m.Connected() {
+		t.Fatal("a query failure must not mark the database unreachable")
+	}
+
+	if !m.ReportError(&pq.Error{Code: "57P01"}) {
+		t.Error("admin shutdown is a connection error")
+	}
+	if m.Connected() {
+		t.Fatal("should be disconnected after a reported connection error")
+	}
+	waitFor(t, "reconnect attempt", func() bool { return db.pings.Load() > 0 })
+
+	db.set(nil)
+	waitFor(t, "recovery", m.Connected)
+	if got := m.Outages(); got != 1 {
+		t.Errorf("Outages = %d, want 1", got)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	backoff := MinReconnectBackoff
+	var got []time.Duration
+	for i := 0; i < 5; i++ {
+		backoff = nextBackoff(backoff, 5*time.Second)
+		got = append(got, backoff)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("backoffs = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestConnMonitor_StopsOnShutdown(t *testing.T) {
+	m := NewConnMonitor(func(context.Context) error { return errors.New("unreachable") }, zap.NewNop(), time.Millisecond, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	waitFor(t, "outage", func() bool { return !m.Connected() })
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitor did not stop on shutdown")
+	}
+}
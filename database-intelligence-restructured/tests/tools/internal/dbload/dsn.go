@@ -0,0 +1,79 @@
+package dbload
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sslModes are the libpq sslmode values accepted by -sslmode
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// TLSConfig holds the TLS settings of the PostgreSQL connection. The local
+// test databases have no TLS, so sslmode defaults to disable; managed
+// services such as RDS and Cloud SQL need require or verify-full.
+type TLSConfig struct {
+	SSLMode  string
+	RootCert string
+	Cert     string
+	Key      string
+}
+
+func (c TLSConfig) Validate() error {
+	valid := false
+	for _, mode := range sslModes {
+		valid = valid || c.SSLMode == mode
+	}
+	if !valid {
+		return fmt.Errorf("invalid -sslmode %q: expected one of %s", c.SSLMode, strings.Join(sslModes, ", "))
+	}
+	if (c.Cert == "") != (c.Key == "") {
+		return fmt.Errorf("-sslcert and -sslkey must be set together")
+	}
+	if c.SSLMode == "disable" && (c.RootCert != "" || c.Cert != "") {
+		return fmt.Errorf("-sslrootcert, -sslcert and -sslkey need an -sslmode other than disable")
+	}
+	return nil
+}
+
+// PostgresDSN builds a key/value connection string. Values are quoted as
+// libpq requires, so passwords and paths may contain spaces and quotes.
+func PostgresDSN(host, port, user, password, dbname string, tls TLSConfig) string {
+	params := []string{
+		"host", host,
+		"port", port,
+		"user", user,
+		"password", password,
+		"dbname", dbname,
+		"sslmode", tls.SSLMode,
+	}
+	if tls.RootCert != "" {
+		params = append(params, "sslrootcert", tls.RootCert)
+	}
+	if tls.Cert != "" {
+		params = append(params, "sslcert", tls.Cert, "sslkey", tls.Key)
+	}
+
+	pairs := make([]string, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		pairs = append(pairs, params[i]+"="+quoteDSNValue(params[i+1]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// quoteDSNValue single-quotes a value that is empty or contains spaces,
+// quotes or backslashes
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " '\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// dsnPassword matches a quoted or unquoted password in a key/value DSN
+var dsnPassword = regexp.MustCompile(`\bpassword=('(?:[^'\\]|\\.)*'|\S*)`)
+
+// RedactDSN masks the password of a key/value DSN for logging
+func RedactDSN(dsn string) string {
+	return dsnPassword.ReplaceAllString(dsn, "password=***")
+}
@@ -0,0 +1,99 @@
+package dbload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		tls      TLSConfig
+		want     string
+	}{
+		{
+			name:     "local",
+			password: "postgres",
+			tls:      TLSConfig{SSLMode: "disable"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=disable",
+		},
+		{
+			name:     "require",
+			password: "postgres",
+			tls:      TLSConfig{SSLMode: "require"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=require",
+		},
+		{
+			name:     "verify-full with a CA",
+			password: "postgres",
+			tls:      TLSConfig{SSLMode: "verify-full", RootCert: "/etc/ssl/rds-ca.pem"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=verify-full sslrootcert=/etc/ssl/rds-ca.pem",
+		},
+		{
+			name:     "client certificate",
+			password: "postgres",
+			tls:      TLSConfig{SSLMode: "verify-ca", RootCert: "/certs/ca.pem", Cert: "/certs/client.pem", Key: "/certs/client key.pem"},
+			want:     "host=db.example.com port=5432 user=app password=postgres dbname=testdb sslmode=verify-ca sslrootcert=/certs/ca.pem sslcert=/certs/client.pem sslkey='/certs/client key.pem'",
+		},
+		{
+			name:     "quoted password",
+			password: `it's a \secret`,
+			tls:      TLSConfig{SSLMode: "prefer"},
+			want:     `host=db.example.com port=5432 user=app password='it\'s a \\secret' dbname=testdb sslmode=prefer`,
+		},
+		{
+			name:     "empty password",
+			password: "",
+			tls:      TLSConfig{SSLMode: "disable"},
+			want:     "host=db.example.com port=5432 user=app password='' dbname=testdb sslmode=disable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tls.Validate(); err != nil {
+				t.Fatalf("validate() = %v", err)
+			}
+			got := PostgresDSN("db.example.com", "5432", "app", tt.password, "testdb", tt.tls)
+			if got != tt.want {
+				t.Errorf("PostgresDSN() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if tt.password != "" && strings.Contains(RedactDSN(got), tt.password) {
+				t.Errorf("RedactDSN(%q) leaks the password", got)
+			}
+		})
+	}
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr string
+	}{
+		{name: "unknown mode", tls: TLSConfig{SSLMode: "required"}, wantErr: "invalid -sslmode"},
+		{name: "cert without key", tls: TLSConfig{SSLMode: "require", Cert: "/certs/client.pem"}, wantErr: "set together"},
+		{name: "files with disable", tls: TLSConfig{SSLMode: "disable", RootCert: "/certs/ca.pem"}, wantErr: "other than disable"},
+	}
+	for _, tt := range tests {
+		err := tt.tls.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: validate() = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	tests := map[string]string{
+		"host=db password=secret dbname=testdb":             "host=db password=*** dbname=testdb",
+		`host=db password='with \'quotes\' and spaces' x=1`: "host=db password=*** x=1",
+		"host=db password= dbname=testdb":                   "host=db password=*** dbname=testdb",
+		"host=db dbname=testdb":                             "host=db dbname=testdb",
+	}
+	for dsn, want := range tests {
+		if got := RedactDSN(dsn); got != want {
+			t.Errorf("RedactDSN(%q) = %q, want %q", dsn, got, want)
+		}
+	}
+}
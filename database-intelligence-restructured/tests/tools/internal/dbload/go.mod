@@ -0,0 +1,10 @@
+module github.com/newrelic/database-intelligence/tools/internal/dbload
+
+go 1.21
+
+require (
+	github.com/lib/pq v1.10.9
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
@@ -0,0 +1,39 @@
+package dbload
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log formats accepted by -log-format
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// NewLogger builds the logger for the given -log-format and -log-level.
+// console is human readable; json writes one object per line for CI.
+func NewLogger(format, level string, out zapcore.WriteSyncer) (*zap.Logger, error) {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	var encoder zapcore.Encoder
+	switch format {
+	case LogFormatConsole:
+		cfg := zap.NewDevelopmentEncoderConfig()
+		cfg.EncodeTime = zapcore.TimeEncoderOfLayout("2006/01/02 15:04:05")
+		encoder = zapcore.NewConsoleEncoder(cfg)
+	case LogFormatJSON:
+		cfg := zap.NewProductionEncoderConfig()
+		cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(cfg)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: expected json or console", format)
+	}
+
+	return zap.New(zapcore.NewCore(encoder, zapcore.Lock(out), lvl)), nil
+}
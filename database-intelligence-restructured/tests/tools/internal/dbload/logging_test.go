@@ -0,0 +1,53 @@
+package dbload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(LogFormatJSON, "info", zapcore.AddSync(&buf))
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	logger.Info("Starting worker", zap.String("pattern", "Query Load"), zap.Int("worker", 3))
+	logger.Warn("Temp files: failed to read temp_bytes", zap.Error(errors.New("connection reset")))
+	logger.Info("Replication lag: holding a replica transaction", zap.Duration("hold", 30*time.Second))
+	logger.Debug("not logged at info")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("invalid JSON %q", line)
+		}
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if entry["level"] != "info" || entry["msg"] != "Starting worker" || entry["pattern"] != "Query Load" || entry["worker"] != float64(3) {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
+
+func TestNewLogger_Invalid(t *testing.T) {
+	if _, err := NewLogger("xml", "info", zapcore.AddSync(&bytes.Buffer{})); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if _, err := NewLogger(LogFormatJSON, "loud", zapcore.AddSync(&bytes.Buffer{})); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,155 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// poolStatsMetric is one database/sql pool statistic exposed on /metrics
+type poolStatsMetric struct {
+	name  string
+	kind  string
+	help  string
+	value func(sql.DBStats) float64
+}
+
+// poolStatsMetrics are the pool statistics, in the order they are written.
+// in_use at max_open with a rising wait_count means the workers are starved
+// for connections, not the database slow.
+var poolStatsMetrics = []poolStatsMetric{
+	{"generator_db_pool_max_open_connections", "gauge", "Maximum number of open connections allowed (0 is unlimited).",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+	{"generator_db_pool_open_connections", "gauge", "Open connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+	{"generator_db_pool_in_use_connections", "gauge", "Connections currently running a query or transaction.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) }},
+	{"generator_db_pool_idle_connections", "gauge", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) }},
+	{"generator_db_pool_wait_count_total", "counter", "Times a worker waited for a free connection.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+	{"generator_db_pool_wait_duration_seconds_total", "counter", "Total time workers waited for a free connection.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+	{"generator_db_pool_max_idle_closed_total", "counter", "Connections closed because the idle pool was full.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }},
+	{"generator_db_pool_max_lifetime_closed_total", "counter", "Connections closed for reaching their maximum lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }},
+}
+
+// PoolMetrics samples the statistics of connection pools every interval and
+// serves the latest sample on /metrics in the Prometheus text format, so a
+// stress test can tell a generator starved for connections from a slow
+// database. Each pool is labelled with its name.
+type PoolMetrics struct {
+	mu      sync.Mutex
+	pools   map[string]func() sql.DBStats
+	latest  map[string]sql.DBStats
+	samples int64
+}
+
+func NewPoolMetrics() *PoolMetrics {
+	return &PoolMetrics{
+		pools:  make(map[string]func() sql.DBStats),
+		latest: make(map[string]sql.DBStats),
+	}
+}
+
+// Add registers a pool; stats is usually (*sql.DB).Stats
+func (m *PoolMetrics) Add(name string, stats func() sql.DBStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pools[name] = stats
+}
+
+// sample records the current statistics of every pool
+func (m *PoolMetrics) sample() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, stats := range m.pools {
+		m.latest[name] = stats()
+	}
+	m.samples++
+}
+
+// Stats returns the latest sample of a pool
+func (m *PoolMetrics) Stats(name string) (sql.DBStats, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.latest[name]
+	return stats, ok
+}
+
+// Run samples every interval until ctx is done
+func (m *PoolMetrics) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.sample()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sample()
+		}
+	}
+}
+
+// write writes the latest sample in the Prometheus text format
+func (m *PoolMetrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.latest))
+	for name := range m.latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, metric := range poolStatsMetrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s{pool=%q} %g\n", metric.name, name, metric.value(m.latest[name])); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "# HELP generator_db_pool_samples_total Times the pool statistics were sampled.\n"+
+		"# TYPE generator_db_pool_samples_total counter\ngenerator_db_pool_samples_total %d\n", m.samples)
+	return err
+}
+
+// ServeHTTP serves the latest sample
+func (m *PoolMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+// Serve listens on addr and serves /metrics until ctx is done
+func (m *PoolMetrics) Serve(ctx context.Context, addr string, logger *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving connection pool metrics", zap.String("addr", addr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Connection pool metrics server failed", zap.Error(err))
+	}
+}
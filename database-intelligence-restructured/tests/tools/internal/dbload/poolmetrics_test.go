@@ -0,0 +1,179 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver hands out connections that cannot run queries; the pool
+// statistics only need connections to be checked out and returned
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("poolmetrics-fake", fakeDriver{})
+}
+
+// metricValues parses the samples of a Prometheus text exposition
+func metricValues(t *testing.T, body string) map[string]float64 {
+	t.Helper()
+	values := make(map[string]float64)
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("malformed sample %q", line)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("malformed value in %q: %v", line, err)
+		}
+		values[name] = v
+	}
+	return values
+}
+
+func TestPoolMetricsAfterLoad(t *testing.T) {
+	db, err := sql.Open("poolmetrics-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	metrics := NewPoolMetrics()
+	metrics.Add("primary", db.Stats)
+
+	// Hold every connection, then make a worker wait for one
+	held := make([]*sql.Conn, 2)
+	for i := range held {
+		if held[i], err = db.Conn(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}()
+	for db.Stats().WaitCount == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	metrics.sample()
+	stats, ok := metrics.Stats("primary")
+	if !ok || stats.InUse != 2 || stats.Idle != 0 || stats.WaitCount != 1 {
+		t.Fatalf("saturated sample = %+v (ok %v), want 2 in use, 0 idle, 1 wait", stats, ok)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	held[0].Close()
+	wg.Wait()
+	held[1].Close()
+
+	// The sampler takes a sample as soon as it starts
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		metrics.Run(ctx, time.Hour)
+	}()
+	for {
+		if stats, _ := metrics.Stats("primary"); stats.InUse == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	server := httptest.NewServer(metrics)
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "# TYPE generator_db_pool_wait_count_total counter\n") {
+		t.Errorf("missing TYPE line in:\n%s", body)
+	}
+
+	values := metricValues(t, string(body))
+	want := map[string]float64{
+		`generator_db_pool_max_open_connections{pool="primary"}`: 2,
+		`generator_db_pool_open_connections{pool="primary"}`:     2,
+		`generator_db_pool_in_use_connections{pool="primary"}`:   0,
+		`generator_db_pool_idle_connections{pool="primary"}`:     2,
+		`generator_db_pool_wait_count_total{pool="primary"}`:     1,
+		`generator_db_pool_samples_total`:                        2,
+	}
+	for name, v := range want {
+		if got, ok := values[name]; !ok || got != v {
+			t.Errorf("%s = %v (present %v), want %v", name, got, ok, v)
+		}
+	}
+	if wait := values[`generator_db_pool_wait_duration_seconds_total{pool="primary"}`]; wait < 0.01 {
+		t.Errorf("wait duration = %vs, want at least 10ms", wait)
+	}
+}
+
+func TestPoolMetricsLabelsEachPool(t *testing.T) {
+	metrics := NewPoolMetrics()
+	metrics.Add("replica", func() sql.DBStats { return sql.DBStats{MaxOpenConnections: 2, InUse: 1} })
+	metrics.Add("primary", func() sql.DBStats { return sql.DBStats{MaxOpenConnections: 50, InUse: 50, WaitCount: 7} })
+
+	var out strings.Builder
+	if err := metrics.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if values := metricValues(t, out.String()); len(values) != 1 {
+		t.Errorf("before the first sample got %v, want only the sample count", values)
+	}
+
+	metrics.sample()
+	out.Reset()
+	if err := metrics.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "# HELP generator_db_pool_in_use_connections Connections currently running a query or transaction.\n" +
+		"# TYPE generator_db_pool_in_use_connections gauge\n" +
+		"generator_db_pool_in_use_connections{pool=\"primary\"} 50\n" +
+		"generator_db_pool_in_use_connections{pool=\"replica\"} 1\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("output missing\n%s\ngot\n%s", want, out.String())
+	}
+}
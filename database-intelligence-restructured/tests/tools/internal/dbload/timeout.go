@@ -0,0 +1,78 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// QueryOutcome classifies how a query ended
+type QueryOutcome int
+
+const (
+	QueryOK QueryOutcome = iota
+	QueryFailed
+	QueryTimedOut
+	// QueryCanceled means the generator was shutting down
+	QueryCanceled
+	// QueryDisconnected means the database could not be reached
+	QueryDisconnected
+)
+
+// QueryStats counts failed queries, keeping timeouts and lost connections
+// apart from errors
+type QueryStats struct {
+	errors      atomic.Int64
+	timeouts    atomic.Int64
+	disconnects atomic.Int64
+}
+
+// Errors returns how many queries failed
+func (s *QueryStats) Errors() int64 {
+	return s.errors.Load()
+}
+
+// Timeouts returns how many queries ran into the query timeout
+func (s *QueryStats) Timeouts() int64 {
+	return s.timeouts.Load()
+}
+
+// Disconnects returns how many queries failed because the database could not
+// be reached
+func (s *QueryStats) Disconnects() int64 {
+	return s.disconnects.Load()
+}
+
+// WithQueryTimeout derives a query's context from the shutdown context, so
+// the query ends at the timeout or at shutdown, whichever comes first. A
+// timeout of 0 only follows shutdown.
+func WithQueryTimeout(shutdown context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(shutdown)
+	}
+	return context.WithTimeout(shutdown, timeout)
+}
+
+// Record classifies the result of a query run with ctx and counts it.
+// Queries cut short by shutdown are not counted.
+func (s *QueryStats) Record(shutdown, ctx context.Context, err error) QueryOutcome {
+	switch {
+	case err == nil || errors.Is(err, sql.ErrNoRows):
+		return QueryOK
+	case shutdown.Err() != nil:
+		return QueryCanceled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		// lib/pq reports the server's cancellation rather than the
+		// context error, so the context decides
+		s.timeouts.Add(1)
+		return QueryTimedOut
+	case isConnectionError(err):
+		s.disconnects.Add(1)
+		return QueryDisconnected
+	default:
+		s.errors.Add(1)
+		return QueryFailed
+	}
+}
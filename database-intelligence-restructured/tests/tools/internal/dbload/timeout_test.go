@@ -0,0 +1,81 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithQueryTimeout_Deadline(t *testing.T) {
+	ctx, cancel := WithQueryTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("query context did not time out")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("ctx.Err() = %v, want DeadlineExceeded", ctx.Err())
+	}
+}
+
+func TestWithQueryTimeout_Shutdown(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Hour} {
+		shutdown, stop := context.WithCancel(context.Background())
+		ctx, cancel := WithQueryTimeout(shutdown, timeout)
+
+		if _, hasDeadline := ctx.Deadline(); hasDeadline != (timeout > 0) {
+			t.Errorf("timeout %v: hasDeadline = %v", timeout, hasDeadline)
+		}
+
+		stop()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("timeout %v: query context not cancelled on shutdown", timeout)
+		}
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("timeout %v: ctx.Err() = %v, want Canceled", timeout, ctx.Err())
+		}
+		cancel()
+	}
+}
+
+func TestQueryStats_Record(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	shutDown, stop := context.WithCancel(context.Background())
+	stop()
+	running := context.Background()
+	queryErr := errors.New("pq: canceling statement due to user request")
+
+	tests := []struct {
+		name     string
+		shutdown context.Context
+		ctx      context.Context
+		err      error
+		want     QueryOutcome
+	}{
+		{name: "success", shutdown: running, ctx: running, err: nil, want: QueryOK},
+		{name: "no rows", shutdown: running, ctx: running, err: sql.ErrNoRows, want: QueryOK},
+		{name: "error", shutdown: running, ctx: running, err: errors.New("relation does not exist"), want: QueryFailed},
+		{name: "timeout", shutdown: running, ctx: expired, err: queryErr, want: QueryTimedOut},
+		{name: "shutdown", shutdown: shutDown, ctx: shutDown, err: queryErr, want: QueryCanceled},
+	}
+
+	var stats QueryStats
+	for _, tt := range tests {
+		if got := stats.Record(tt.shutdown, tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: Record() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := stats.errors.Load(); got != 1 {
+		t.Errorf("errors = %d, want 1", got)
+	}
+	if got := stats.timeouts.Load(); got != 1 {
+		t.Errorf("timeouts = %d, want 1", got)
+	}
+}
@@ -0,0 +1,148 @@
+package dbload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Backoff between attempts of a transaction aborted by contention
+const (
+	minTxRetryBackoff = 10 * time.Millisecond
+	maxTxRetryBackoff = time.Second
+)
+
+// ErrRollback asks RunTx to roll the transaction back without an error, for
+// patterns that exercise rollbacks on purpose
+var ErrRollback = errors.New("rollback requested")
+
+// TxStepError is a failed BEGIN or COMMIT. RunTx returns it apart from the
+// errors of fn, so callers that record every statement can record it too.
+type TxStepError struct {
+	// Step is begin or commit
+	Step string
+	Err  error
+}
+
+func (e *TxStepError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e *TxStepError) Unwrap() error {
+	return e.Err
+}
+
+// isRetryableTxError reports whether err aborted a transaction that can run
+// again as is: PostgreSQL picked it as a deadlock victim (40P01) or could not
+// serialize it (40001). Both abort the whole transaction, so only running it
+// from the start helps.
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case "40P01", // deadlock_detected
+		"40001": // serialization_failure
+		return true
+	}
+	return false
+}
+
+// TxRetrier runs transactions, retrying those aborted by contention so the
+// offered write load stays on target when transactions deadlock
+type TxRetrier struct {
+	// maxRetries is how often a transaction is retried; 0 disables retries
+	maxRetries int
+
+	// retries counts attempts after the first
+	retries atomic.Int64
+	// exhausted counts transactions still aborted after maxRetries
+	exhausted atomic.Int64
+
+	// sleep waits between attempts; tests replace it
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func NewTxRetrier(maxRetries int) *TxRetrier {
+	return &TxRetrier{maxRetries: maxRetries, sleep: sleepContext}
+}
+
+// Retries returns how many transaction attempts were retries
+func (r *TxRetrier) Retries() int64 {
+	return r.retries.Load()
+}
+
+// Exhausted returns how many transactions were given up after maxRetries
+func (r *TxRetrier) Exhausted() int64 {
+	return r.exhausted.Load()
+}
+
+// RunTx runs fn in a transaction on db and commits it when fn returns nil.
+// When fn returns ErrRollback the transaction is rolled back and RunTx
+// returns nil. A deadlock or serialization failure, from fn or the commit,
+// rolls back and runs fn again in a new transaction; any other error rolls
+// back and is returned. Begin and commit errors are *TxStepError.
+func (r *TxRetrier) RunTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	return r.retry(ctx, func() error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return &TxStepError{Step: "begin", Err: err}
+		}
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			if errors.Is(err, ErrRollback) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return &TxStepError{Step: "commit", Err: err}
+		}
+		return nil
+	})
+}
+
+// retry runs attempt until it succeeds, fails with an error that is not
+// retryable, runs out of retries or ctx is done, backing off exponentially
+// with jitter between attempts. It returns the error of the last attempt.
+func (r *TxRetrier) retry(ctx context.Context, attempt func() error) error {
+	backoff := minTxRetryBackoff
+	for i := 0; ; i++ {
+		err := attempt()
+		if !isRetryableTxError(err) {
+			return err
+		}
+		if i >= r.maxRetries {
+			if r.maxRetries > 0 {
+				r.exhausted.Add(1)
+			}
+			return err
+		}
+
+		// Jitter keeps the two sides of a deadlock from meeting again
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		if r.sleep(ctx, wait) != nil {
+			return err
+		}
+		r.retries.Add(1)
+		backoff = nextBackoff(backoff, maxTxRetryBackoff)
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package dbload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"wrapped deadlock", fmt.Errorf("update: %w", &pq.Error{Code: "40P01"}), true},
+		{"serialization failure at commit", &TxStepError{Step: "commit", Err: &pq.Error{Code: "40001"}}, true},
+		{"lock timeout", &pq.Error{Code: "55P03"}, false},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, false},
+		{"not a pq error", errors.New("40P01"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableTxError(tt.err); got != tt.want {
+				t.Errorf("isRetryableTxError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestRetrier records the waits instead of sleeping
+func newTestRetrier(maxRetries int, waits *[]time.Duration) *TxRetrier {
+	r := NewTxRetrier(maxRetries)
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return r
+}
+
+// attempts returns an attempt that fails with errs in turn, then succeeds
+func attempts(calls *int, errs ...error) func() error {
+	return func() error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+func TestTxRetrier(t *testing.T) {
+	deadlock := &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	serialization := &pq.Error{Code: "40001", Message: "could not serialize access"}
+	uniqueViolation := &pq.Error{Code: "23505", Message: "duplicate key"}
+
+	tests := []struct {
+		name          string
+		maxRetries    int
+		errs          []error
+		wantErr       error
+		wantCalls     int
+		wantRetries   int64
+		wantExhausted int64
+	}{
+		{name: "succeeds first time", maxRetries: 3, wantCalls: 1},
+		{name: "deadlock victim retried", maxRetries: 3, errs: []error{deadlock}, wantCalls: 2, wantRetries: 1},
+		{name: "serialization failure retried", maxRetries: 3, errs: []error{serialization, deadlock}, wantCalls: 3, wantRetries: 2},
+		{name: "other errors are not retried", maxRetries: 3, errs: []error{uniqueViolation}, wantErr: uniqueViolation, wantCalls: 1},
+		{name: "retries run out", maxRetries: 2, errs: []error{deadlock, deadlock, deadlock, deadlock}, wantErr: deadlock, wantCalls: 3, wantRetries: 2, wantExhausted: 1},
+		{name: "retries disabled", maxRetries: 0, errs: []error{deadlock}, wantErr: deadlock, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var waits []time.Duration
+			r := newTestRetrier(tt.maxRetries, &waits)
+			calls := 0
+
+			err := r.retry(context.Background(), attempts(&calls, tt.errs...))
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", calls, tt.wantCalls)
+			}
+			if r.Retries() != tt.wantRetries || r.Exhausted() != tt.wantExhausted {
+				t.Errorf("retries, exhausted = %d, %d, want %d, %d", r.Retries(), r.Exhausted(), tt.wantRetries, tt.wantExhausted)
+			}
+			if int64(len(waits)) != tt.wantRetries {
+				t.Errorf("waited %d times, want %d", len(waits), tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestTxRetrierBackoff(t *testing.T) {
+	var waits []time.Duration
+	r := newTestRetrier(10, &waits)
+	deadlock := &pq.Error{Code: "40P01"}
+	calls := 0
+	if err := r.retry(context.Background(), attempts(&calls, deadlock, deadlock, deadlock, deadlock, deadlock, deadlock, deadlock, deadlock)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each wait is the backoff, doubling up to the maximum, with +-50% jitter
+	backoff := minTxRetryBackoff
+	for i, wait := range waits {
+		if wait < backoff/2 || wait >= backoff*3/2 {
+			t.Errorf("wait %d = %v, want within [%v, %v)", i, wait, backoff/2, backoff*3/2)
+		}
+		backoff = nextBackoff(backoff, maxTxRetryBackoff)
+	}
+	if backoff != maxTxRetryBackoff {
+		t.Errorf("backoff after %d retries = %v, want capped at %v", len(waits), backoff, maxTxRetryBackoff)
+	}
+}
+
+func TestTxRetrierStopsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var waits []time.Duration
+	r := newTestRetrier(3, &waits)
+	deadlock := &pq.Error{Code: "40P01"}
+	calls := 0
+	if err := r.retry(ctx, attempts(&calls, deadlock)); err != deadlock {
+		t.Errorf("err = %v, want the deadlock", err)
+	}
+	if calls != 1 || r.Retries() != 0 || r.Exhausted() != 0 {
+		t.Errorf("attempts, retries, exhausted = %d, %d, %d, want 1, 0, 0", calls, r.Retries(), r.Exhausted())
+	}
+}
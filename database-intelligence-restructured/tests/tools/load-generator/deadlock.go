@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// deadlockDetected is the SQLSTATE PostgreSQL returns to the transaction it
+// aborts to break a deadlock
+const deadlockDetected pq.ErrorCode = "40P01"
+
+const (
+	// deadlockTargetPairs is how many row pairs deadlock_targets holds;
+	// concurrent pairs use different rows so they cannot block each other
+	deadlockTargetPairs = 50
+	// deadlockMaxBurst caps how many pairs start in one tick, e.g. after an
+	// outage, so catching up cannot flood the database
+	deadlockMaxBurst = 5
+)
+
+// deadlockOutcome classifies how a deadlock-inducing transaction pair ended
+type deadlockOutcome int
+
+const (
+	// pairDeadlocked means PostgreSQL aborted one side with 40P01
+	pairDeadlocked deadlockOutcome = iota
+	// pairResolved means both sides committed because one won the race
+	pairResolved
+	// pairFailed means a side failed for another reason
+	pairFailed
+)
+
+// classifyDeadlockPair returns the outcome of a pair whose sides ended
+// with errA and errB
+func classifyDeadlockPair(errA, errB error) deadlockOutcome {
+	if isDeadlock(errA) || isDeadlock(errB) {
+		return pairDeadlocked
+	}
+	if errA == nil && errB == nil {
+		return pairResolved
+	}
+	return pairFailed
+}
+
+func isDeadlock(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == deadlockDetected
+}
+
+// deadlockController schedules deadlock-inducing transaction pairs to hit a
+// target rate of deadlocks per minute. It schedules against deadlocks
+// actually detected rather than pairs started, so pairs that resolve
+// without a deadlock are made up for and postgresql.deadlocks should grow
+// by the target rate.
+type deadlockController struct {
+	mu       sync.Mutex
+	rate     float64 // deadlocks per minute
+	start    time.Time
+	inFlight int64
+	pairs    int64
+	counts   [3]int64 // indexed by deadlockOutcome
+}
+
+func newDeadlockController(rate float64, start time.Time) *deadlockController {
+	return &deadlockController{rate: rate, start: start}
+}
+
+// tick returns how often the controller should be asked for due pairs
+func (c *deadlockController) tick() time.Duration {
+	interval := time.Duration(float64(time.Minute) / c.rate)
+	if interval > time.Second {
+		return time.Second
+	}
+	return interval
+}
+
+// due returns how many pairs to start at now so that detected deadlocks,
+// plus those still in flight, keep up with the target. The started pairs
+// count as in flight until finish is called.
+func (c *deadlockController) due(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expected := int64(math.Floor(c.rate * now.Sub(c.start).Minutes()))
+	n := expected - c.counts[pairDeadlocked] - c.inFlight
+	if n <= 0 {
+		return 0
+	}
+	if n > deadlockMaxBurst {
+		n = deadlockMaxBurst
+	}
+	c.inFlight += n
+	return int(n)
+}
+
+// next returns the row pair for the next started pair
+func (c *deadlockController) next() (first, second int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slot := int(c.pairs % deadlockTargetPairs)
+	c.pairs++
+	return 2*slot + 1, 2*slot + 2
+}
+
+// finish records the outcome of a started pair
+func (c *deadlockController) finish(outcome deadlockOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+	c.counts[outcome]++
+}
+
+// deadlockReport compares the observed deadlock rate with the target
+type deadlockReport struct {
+	TargetPerMinute   float64
+	ObservedPerMinute float64
+	Pairs             int64
+	Deadlocks         int64
+	Resolved          int64
+	Failed            int64
+}
+
+func (c *deadlockController) report(now time.Time) deadlockReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := deadlockReport{
+		TargetPerMinute: c.rate,
+		Pairs:           c.pairs,
+		Deadlocks:       c.counts[pairDeadlocked],
+		Resolved:        c.counts[pairResolved],
+		Failed:          c.counts[pairFailed],
+	}
+	if elapsed := now.Sub(c.start).Minutes(); elapsed > 0 {
+		r.ObservedPerMinute = float64(r.Deadlocks) / elapsed
+	}
+	return r
+}
+
+func (r deadlockReport) fields() []zap.Field {
+	return []zap.Field{
+		zap.Float64("target_per_min", r.TargetPerMinute),
+		zap.Float64("observed_per_min", r.ObservedPerMinute),
+		zap.Int64("pairs", r.Pairs),
+		zap.Int64("deadlocks", r.Deadlocks),
+		zap.Int64("resolved", r.Resolved),
+		zap.Int64("failed", r.Failed),
+	}
+}
+
+// deadlockWorker starts deadlock pairs at the rate set by -deadlock-rate and
+// logs observed against target rate every minute
+func (lg *LoadGenerator) deadlockWorker() {
+	defer lg.wg.Done()
+
+	ticker := time.NewTicker(lg.deadlocks.tick())
+	defer ticker.Stop()
+	reportTicker := time.NewTicker(time.Minute)
+	defer reportTicker.Stop()
+
+	for {
+		select {
+		case <-lg.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if lg.offline() {
+				continue
+			}
+			for i := lg.deadlocks.due(now); i > 0; i-- {
+				first, second := lg.deadlocks.next()
+				lg.wg.Add(1)
+				go func() {
+					defer lg.wg.Done()
+					lg.deadlocks.finish(lg.runDeadlockPair(first, second))
+				}()
+			}
+		case now := <-reportTicker.C:
+			lg.logger.Info("Deadlock rate", lg.deadlocks.report(now).fields()...)
+		}
+	}
+}
+
+// runDeadlockPair runs two transactions that lock the rows first and second
+// in opposite orders. Each side takes its first lock and waits for the other
+// to do the same before asking for the second, so unless a side fails early
+// the pair deadlocks. The victim is not retried: its abort is the deadlock
+// being counted.
+func (lg *LoadGenerator) runDeadlockPair(first, second int) deadlockOutcome {
+	var holding, done sync.WaitGroup
+	holding.Add(2)
+	done.Add(2)
+	errs := make([]error, 2)
+
+	side := func(i, lockFirst, lockSecond int) {
+		defer done.Done()
+		ctx, cancel := lg.queryContext()
+		defer cancel()
+
+		start := time.Now()
+		tx, err := lg.db.BeginTx(ctx, nil)
+		if err != nil {
+			holding.Done()
+			lg.finishQuery(ctx, "begin_transaction", start, 0, err)
+			errs[i] = err
+			return
+		}
+		defer tx.Rollback()
+
+		start = time.Now()
+		n, err := rowsAffected(tx.ExecContext(ctx, "UPDATE deadlock_targets SET touched_at = NOW() WHERE id = $1", lockFirst))
+		holding.Done()
+		lg.finishQuery(ctx, "deadlock_update", start, n, err)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		holding.Wait()
+
+		// PostgreSQL aborts one side of the deadlock, which counts as an error
+		start = time.Now()
+		n, err = rowsAffected(tx.ExecContext(ctx, "UPDATE deadlock_targets SET touched_at = NOW() WHERE id = $1", lockSecond))
+		lg.finishQuery(ctx, "deadlock_update", start, n, err)
+		if err == nil {
+			err = tx.Commit()
+		}
+		errs[i] = err
+	}
+
+	go side(0, first, second)
+	go side(1, second, first)
+	done.Wait()
+	return classifyDeadlockPair(errs[0], errs[1])
+}
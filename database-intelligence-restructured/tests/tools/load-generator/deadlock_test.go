@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestDeadlockControllerDue(t *testing.T) {
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	c := newDeadlockController(6, start)
+
+	if got := c.tick(); got != time.Second {
+		t.Errorf("tick() = %v, want 1s", got)
+	}
+	if n := c.due(start.Add(9 * time.Second)); n != 0 {
+		t.Errorf("due before the first slot = %d, want 0", n)
+	}
+
+	// One deadlock is due every 10s; an in-flight pair is not started again
+	if n := c.due(start.Add(10 * time.Second)); n != 1 {
+		t.Fatalf("due at 10s = %d, want 1", n)
+	}
+	if n := c.due(start.Add(15 * time.Second)); n != 0 {
+		t.Errorf("due with the pair in flight = %d, want 0", n)
+	}
+
+	// A pair that resolves without a deadlock is made up for
+	c.finish(pairResolved)
+	if n := c.due(start.Add(15 * time.Second)); n != 1 {
+		t.Fatalf("due after a resolved pair = %d, want 1", n)
+	}
+	c.finish(pairDeadlocked)
+	if n := c.due(start.Add(19 * time.Second)); n != 0 {
+		t.Errorf("due once on target = %d, want 0", n)
+	}
+	if n := c.due(start.Add(20 * time.Second)); n != 1 {
+		t.Errorf("due at 20s = %d, want 1", n)
+	}
+	c.finish(pairDeadlocked)
+
+	// Catching up after a pause starts at most deadlockMaxBurst pairs a tick
+	if n := c.due(start.Add(10 * time.Minute)); n != deadlockMaxBurst {
+		t.Errorf("due after a pause = %d, want %d", n, deadlockMaxBurst)
+	}
+}
+
+func TestDeadlockControllerTick(t *testing.T) {
+	tests := map[float64]time.Duration{
+		0.5: time.Second,
+		60:  time.Second,
+		600: 100 * time.Millisecond,
+	}
+	for rate, want := range tests {
+		if got := newDeadlockController(rate, time.Now()).tick(); got != want {
+			t.Errorf("tick() at %g/min = %v, want %v", rate, got, want)
+		}
+	}
+}
+
+func TestDeadlockControllerRate(t *testing.T) {
+	// Simulate an hour at 12/min where every third pair resolves without a
+	// deadlock; the controller should still reach the target
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	c := newDeadlockController(12, start)
+
+	for now := start; now.Before(start.Add(time.Hour)); now = now.Add(c.tick()) {
+		for i := c.due(now); i > 0; i-- {
+			if c.next(); c.pairs%3 == 0 {
+				c.finish(pairResolved)
+			} else {
+				c.finish(pairDeadlocked)
+			}
+		}
+	}
+
+	r := c.report(start.Add(time.Hour))
+	if math.Abs(r.ObservedPerMinute-r.TargetPerMinute) > 0.1 {
+		t.Errorf("observed %g/min, want %g/min", r.ObservedPerMinute, r.TargetPerMinute)
+	}
+	if r.Pairs != r.Deadlocks+r.Resolved+r.Failed {
+		t.Errorf("pairs %d != deadlocks %d + resolved %d + failed %d", r.Pairs, r.Deadlocks, r.Resolved, r.Failed)
+	}
+	if r.Resolved == 0 {
+		t.Error("expected resolved pairs to be counted")
+	}
+}
+
+func TestDeadlockControllerNext(t *testing.T) {
+	c := newDeadlockController(1, time.Now())
+	seen := map[int]bool{}
+	for i := 0; i < deadlockTargetPairs; i++ {
+		first, second := c.next()
+		if second != first+1 || seen[first] || seen[second] {
+			t.Fatalf("pair %d = (%d, %d) overlaps an earlier pair", i, first, second)
+		}
+		seen[first], seen[second] = true, true
+	}
+	if first, second := c.next(); first != 1 || second != 2 {
+		t.Errorf("pairs should wrap around to (1, 2), got (%d, %d)", first, second)
+	}
+}
+
+func TestClassifyDeadlockPair(t *testing.T) {
+	deadlock := fmt.Errorf("update: %w", &pq.Error{Code: "40P01", Message: "deadlock detected"})
+	lockTimeout := &pq.Error{Code: "55P03", Message: "canceling statement due to lock timeout"}
+
+	tests := []struct {
+		name       string
+		errA, errB error
+		want       deadlockOutcome
+	}{
+		{"deadlock", nil, deadlock, pairDeadlocked},
+		{"deadlock on either side", deadlock, lockTimeout, pairDeadlocked},
+		{"one side won", nil, nil, pairResolved},
+		{"other error", lockTimeout, nil, pairFailed},
+		{"connection error", errors.New("connection refused"), nil, pairFailed},
+	}
+	for _, tt := range tests {
+		if got := classifyDeadlockPair(tt.errA, tt.errB); got != tt.want {
+			t.Errorf("%s: classifyDeadlockPair() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event log formats accepted by -event-log-format
+const (
+	eventFormatCSV     = "csv"
+	eventFormatParquet = "parquet"
+)
+
+// eventColumns are the event log columns, in order
+var eventColumns = []string{"timestamp", "query_type", "duration_ms", "rows", "error"}
+
+// queryEvent is one query as executed by the generator, the ground truth to
+// join against what the collector reported
+type queryEvent struct {
+	Time      time.Time
+	QueryType string
+	Duration  time.Duration
+	Rows      int64
+	Error     string
+}
+
+// eventEncoder writes events to one event log file
+type eventEncoder interface {
+	writeEvents(events []queryEvent) error
+	// close finishes the file, without closing the underlying writer
+	close() error
+}
+
+// eventLog buffers query events and writes them to a file in batches,
+// starting a new file once the current one reaches maxBytes. Files after the
+// first get a sequence number before the extension: events.csv,
+// events.1.csv, events.2.csv and so on.
+type eventLog struct {
+	path     string
+	format   string
+	maxBytes int64
+
+	mu      sync.Mutex
+	pending []queryEvent
+	file    *os.File
+	counter *countingWriter
+	encoder eventEncoder
+	files   int
+}
+
+// newEventLog creates the first event log file. maxBytes of 0 disables
+// rotation.
+func newEventLog(path, format string, maxBytes int64) (*eventLog, error) {
+	if format != eventFormatCSV && format != eventFormatParquet {
+		return nil, fmt.Errorf("invalid event log format %q: expected csv or parquet", format)
+	}
+	if format == eventFormatParquet && !parquetSupported {
+		return nil, fmt.Errorf("event log format parquet requires a load generator built with -tags parquet")
+	}
+	l := &eventLog{path: path, format: format, maxBytes: maxBytes}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// record adds an event to the next flush
+func (l *eventLog) record(e queryEvent) {
+	l.mu.Lock()
+	l.pending = append(l.pending, e)
+	l.mu.Unlock()
+}
+
+// run flushes every interval until ctx is done. Errors are passed to
+// onError and do not stop the loop.
+func (l *eventLog) run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.flush(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// flush writes the pending events and rotates the file when it is full
+func (l *eventLog) flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.writePending(); err != nil {
+		return err
+	}
+	if l.maxBytes > 0 && l.counter != nil && l.counter.n >= l.maxBytes {
+		if err := l.closeFile(); err != nil {
+			return err
+		}
+		return l.openFile()
+	}
+	return nil
+}
+
+// Close writes the pending events and finishes the current file
+func (l *eventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	writeErr := l.writePending()
+	if err := l.closeFile(); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// writePending writes the buffered events to the current file
+func (l *eventLog) writePending() error {
+	if len(l.pending) == 0 || l.encoder == nil {
+		return nil
+	}
+	events := l.pending
+	l.pending = nil
+	if err := l.encoder.writeEvents(events); err != nil {
+		return fmt.Errorf("failed to write %d query events to %s: %w", len(events), l.file.Name(), err)
+	}
+	return nil
+}
+
+// openFile starts the next file in the sequence
+func (l *eventLog) openFile() error {
+	name := l.path
+	if l.files > 0 {
+		ext := filepath.Ext(l.path)
+		name = strings.TrimSuffix(l.path, ext) + "." + strconv.Itoa(l.files) + ext
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create event log: %w", err)
+	}
+	counter := &countingWriter{w: f}
+
+	var encoder eventEncoder
+	switch l.format {
+	case eventFormatParquet:
+		encoder, err = newParquetEncoder(counter)
+	default:
+		encoder, err = newCSVEncoder(counter)
+	}
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to start event log %s: %w", name, err)
+	}
+
+	l.file, l.counter, l.encoder = f, counter, encoder
+	l.files++
+	return nil
+}
+
+// closeFile finishes and closes the current file
+func (l *eventLog) closeFile() error {
+	if l.encoder == nil {
+		return nil
+	}
+	err := l.encoder.close()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file, l.counter, l.encoder = nil, nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to close event log: %w", err)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to a file, which for Parquet is
+// only known once a row group has been written out
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// csvEncoder writes a header row followed by one row per event
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w)}
+	if err := e.w.Write(eventColumns); err != nil {
+		return nil, err
+	}
+	e.w.Flush()
+	return e, e.w.Error()
+}
+
+func (e *csvEncoder) writeEvents(events []queryEvent) error {
+	for _, ev := range events {
+		err := e.w.Write([]string{
+			ev.Time.UTC().Format(time.RFC3339Nano),
+			ev.QueryType,
+			strconv.FormatFloat(float64(ev.Duration)/float64(time.Millisecond), 'f', 3, 64),
+			strconv.FormatInt(ev.Rows, 10),
+			ev.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
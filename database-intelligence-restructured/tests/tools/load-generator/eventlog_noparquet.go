@@ -0,0 +1,17 @@
+//go:build !parquet
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// parquetSupported reports whether -event-log-format=parquet can be used.
+// The Parquet writer pulls in Apache Arrow, so it is only built with
+// -tags parquet.
+const parquetSupported = false
+
+func newParquetEncoder(io.Writer) (eventEncoder, error) {
+	return nil, errors.New("parquet event log is not supported by this build")
+}
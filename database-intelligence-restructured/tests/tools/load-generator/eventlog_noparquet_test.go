@@ -0,0 +1,19 @@
+//go:build !parquet
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewEventLog_ParquetNotBuilt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.parquet")
+	if _, err := newEventLog(path, eventFormatParquet, 0); err == nil {
+		t.Fatal("expected an error for format parquet without -tags parquet")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("event log file was created: %v", err)
+	}
+}
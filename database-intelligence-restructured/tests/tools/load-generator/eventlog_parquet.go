@@ -0,0 +1,76 @@
+//go:build parquet
+
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/compress"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+)
+
+// parquetSupported reports whether -event-log-format=parquet can be used.
+// The Parquet writer pulls in Apache Arrow, so it is only built with
+// -tags parquet.
+const parquetSupported = true
+
+// eventSchema has the columns of eventColumns; error is null for queries
+// that succeeded
+var eventSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "timestamp", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}},
+	{Name: "query_type", Type: arrow.BinaryTypes.String},
+	{Name: "duration_ms", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "rows", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "error", Type: arrow.BinaryTypes.String, Nullable: true},
+}, nil)
+
+// parquetEncoder writes each batch of events as one row group
+type parquetEncoder struct {
+	w *pqarrow.FileWriter
+}
+
+func newParquetEncoder(w io.Writer) (*parquetEncoder, error) {
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(eventSchema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, err
+	}
+	return &parquetEncoder{w: fw}, nil
+}
+
+func (e *parquetEncoder) writeEvents(events []queryEvent) error {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, eventSchema)
+	defer b.Release()
+
+	timestamps := b.Field(0).(*array.TimestampBuilder)
+	queryTypes := b.Field(1).(*array.StringBuilder)
+	durations := b.Field(2).(*array.Float64Builder)
+	rows := b.Field(3).(*array.Int64Builder)
+	errs := b.Field(4).(*array.StringBuilder)
+	for _, ev := range events {
+		timestamps.Append(arrow.Timestamp(ev.Time.UnixMicro()))
+		queryTypes.Append(ev.QueryType)
+		durations.Append(float64(ev.Duration) / float64(time.Millisecond))
+		rows.Append(ev.Rows)
+		if ev.Error == "" {
+			errs.AppendNull()
+		} else {
+			errs.Append(ev.Error)
+		}
+	}
+
+	rec := b.NewRecord()
+	defer rec.Release()
+	return e.w.Write(rec)
+}
+
+// close writes the footer. The FileWriter would also close w, so it is
+// given the countingWriter rather than the file.
+func (e *parquetEncoder) close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,49 @@
+//go:build parquet
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/parquet/file"
+)
+
+func TestEventLog_Parquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.parquet")
+	l, err := newEventLog(path, eventFormatParquet, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range testEvents(5) {
+		l.record(e)
+	}
+	if err := l.flush(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range testEvents(2) {
+		l.record(e)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := file.OpenParquetFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if got := r.NumRows(); got != 7 {
+		t.Errorf("NumRows = %d, want 7", got)
+	}
+	if got := r.NumRowGroups(); got != 2 {
+		t.Errorf("NumRowGroups = %d, want one per flush", got)
+	}
+	schema := r.MetaData().Schema
+	for i, name := range eventColumns {
+		if got := schema.Column(i).Name(); got != name {
+			t.Errorf("column %d = %q, want %q", i, got, name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testEvents(n int) []queryEvent {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]queryEvent, n)
+	for i := range events {
+		events[i] = queryEvent{
+			Time:      start.Add(time.Duration(i) * time.Millisecond),
+			QueryType: "select_by_index",
+			Duration:  1500 * time.Microsecond,
+			Rows:      10,
+		}
+	}
+	return events
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return records
+}
+
+func TestEventLog_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.csv")
+	l, err := newEventLog(path, eventFormatCSV, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := testEvents(3)
+	events[2].QueryType = "update_locked"
+	events[2].Rows = 0
+	events[2].Error = `pq: deadlock detected, "orders"`
+	for _, e := range events[:2] {
+		l.record(e)
+	}
+	if err := l.flush(); err != nil {
+		t.Fatal(err)
+	}
+	l.record(events[2])
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readCSV(t, path)
+	if len(records) != 4 {
+		t.Fatalf("got %d rows, want a header and 3 events", len(records))
+	}
+	if !reflect.DeepEqual(records[0], eventColumns) {
+		t.Errorf("header = %v, want %v", records[0], eventColumns)
+	}
+	want := []string{"2024-05-01T12:00:00Z", "select_by_index", "1.500", "10", ""}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("first event = %v, want %v", records[1], want)
+	}
+	want = []string{"2024-05-01T12:00:00.002Z", "update_locked", "1.500", "0", `pq: deadlock detected, "orders"`}
+	if !reflect.DeepEqual(records[3], want) {
+		t.Errorf("failed event = %v, want %v", records[3], want)
+	}
+}
+
+func TestEventLog_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := newEventLog(filepath.Join(dir, "events.csv"), eventFormatCSV, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each flush of 20 events is well over 1KB, so every flush starts a file
+	for i := 0; i < 3; i++ {
+		for _, e := range testEvents(20) {
+			l.record(e)
+		}
+		if err := l.flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	events := 0
+	for _, name := range []string{"events.csv", "events.1.csv", "events.2.csv", "events.3.csv"} {
+		records := readCSV(t, filepath.Join(dir, name))
+		if !reflect.DeepEqual(records[0], eventColumns) {
+			t.Errorf("%s: header = %v", name, records[0])
+		}
+		events += len(records) - 1
+	}
+	if events != 60 {
+		t.Errorf("got %d events across files, want 60", events)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "events.4.csv")); !os.IsNotExist(err) {
+		t.Errorf("unexpected fifth file: %v", err)
+	}
+}
+
+func TestNewEventLog_InvalidFormat(t *testing.T) {
+	if _, err := newEventLog(filepath.Join(t.TempDir(), "events.json"), "json", 0); err == nil {
+		t.Error("expected an error for format json")
+	}
+}
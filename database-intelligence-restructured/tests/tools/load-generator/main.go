@@ -3,8 +3,9 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"math/rand"
 	"os"
 	"os/signal"
@@ -14,36 +15,148 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/newrelic/database-intelligence/tools/internal/dbload"
+	"go.uber.org/zap"
 )
 
 type LoadGenerator struct {
 	db      *sql.DB
+	logger  *zap.Logger
 	pattern string
 	qps     int
 	ctx     context.Context
 	cancel  context.CancelFunc
 	wg      sync.WaitGroup
+
+	// queryTimeout bounds each query; 0 disables it
+	queryTimeout time.Duration
+	stats        dbload.QueryStats
+
+	// events is the per-query event log, nil unless -event-log is set
+	events *eventLog
+	// conn tracks whether the database is reachable
+	conn *dbload.ConnMonitor
+	// txRetries retries transactions aborted by deadlocks or serialization
+	// failures
+	txRetries *dbload.TxRetrier
+	// pools samples the connection pool statistics for /metrics
+	pools *dbload.PoolMetrics
+
+	// deadlockRate is the -deadlock-rate target in deadlocks per minute;
+	// 0 leaves deadlocks to chance in the blocking pattern
+	deadlockRate float64
+	deadlocks    *deadlockController
+	// slowPlan is the statement latency distribution of the slowqueries
+	// pattern, nil for the other patterns
+	slowPlan *slowQueryPlan
 }
 
 func main() {
+	pattern := flag.String("pattern", getEnv("LOAD_PATTERN", "mixed"), "Load pattern: simple, complex, analytical, blocking, mixed, stress or slowqueries")
+	qps := flag.Int("qps", getEnvInt("QUERIES_PER_SECOND", 10), "Queries per second")
+	queryTimeout := flag.Duration("query-timeout", getEnvDuration("QUERY_TIMEOUT", 30*time.Second), "Cancel queries running longer than this (0 disables)")
+	logFormat := flag.String("log-format", getEnv("LOG_FORMAT", dbload.LogFormatConsole), "Log format: console or json")
+	logLevel := flag.String("log-level", getEnv("LOG_LEVEL", "info"), "Log level: debug, info, warn or error (warn shows query errors)")
+	eventLogPath := flag.String("event-log", getEnv("EVENT_LOG", ""), "Write one row per executed query to this file (empty disables)")
+	eventLogFormat := flag.String("event-log-format", getEnv("EVENT_LOG_FORMAT", eventFormatCSV), "Event log format: csv, or parquet in builds with -tags parquet")
+	eventLogMaxMB := flag.Int("event-log-max-mb", getEnvInt("EVENT_LOG_MAX_MB", 100), "Start a new event log file once the current one reaches this size in MB (0 disables)")
+	healthInterval := flag.Duration("health-interval", getEnvDuration("HEALTH_CHECK_INTERVAL", 5*time.Second), "How often the database connection is checked")
+	maxBackoff := flag.Duration("reconnect-max-backoff", getEnvDuration("RECONNECT_MAX_BACKOFF", 30*time.Second), "Longest wait between reconnect attempts while the database is unreachable")
+	var tls dbload.TLSConfig
+	flag.StringVar(&tls.SSLMode, "sslmode", getEnv("POSTGRES_SSLMODE", "disable"), "SSL mode: disable, allow, prefer, require, verify-ca or verify-full")
+	flag.StringVar(&tls.RootCert, "sslrootcert", getEnv("POSTGRES_SSLROOTCERT", ""), "CA certificate file used to verify the server (verify-ca, verify-full)")
+	flag.StringVar(&tls.Cert, "sslcert", getEnv("POSTGRES_SSLCERT", ""), "Client certificate file")
+	flag.StringVar(&tls.Key, "sslkey", getEnv("POSTGRES_SSLKEY", ""), "Client private key file")
+	deadlockRate := flag.Float64("deadlock-rate", getEnvFloat("DEADLOCK_RATE", 0), "Induce this many deadlocks per minute, counting those PostgreSQL detects (0 leaves deadlocks to chance in the blocking pattern)")
+	txMaxRetries := flag.Int("tx-max-retries", getEnvInt("TX_MAX_RETRIES", 3), "Retry a transaction aborted by a deadlock or serialization failure up to this many times (0 disables)")
+	eventLogFlush := flag.Duration("event-log-flush", getEnvDuration("EVENT_LOG_FLUSH_INTERVAL", 5*time.Second), "How often buffered query events are written to the event log")
+	metricsAddr := flag.String("metrics-addr", getEnv("METRICS_ADDR", ":9101"), "Serve connection pool metrics on /metrics at this address (empty disables)")
+	poolStatsInterval := flag.Duration("pool-stats-interval", getEnvDuration("POOL_STATS_INTERVAL", 5*time.Second), "How often the connection pool statistics are sampled for /metrics")
+	slowStatements := flag.Int("slow-statements", getEnvInt("SLOW_STATEMENTS", 100), "Number of distinct statements the slowqueries pattern runs")
+	slowMinLatency := flag.Duration("slow-min-latency", getEnvDuration("SLOW_MIN_LATENCY", 100*time.Millisecond), "Latency of the fastest slowqueries statement")
+	slowMaxLatency := flag.Duration("slow-max-latency", getEnvDuration("SLOW_MAX_LATENCY", 1500*time.Millisecond), "Latency of the slowest slowqueries statement; the others are spread evenly in between")
+	slowThreshold := flag.Duration("slow-threshold", getEnvDuration("SLOW_THRESHOLD", 500*time.Millisecond), "Mean execution time from which a slowqueries statement is expected to be reported, as pgslowqueries' min_mean_exec_time")
+	slowRound := flag.Duration("slow-round", getEnvDuration("SLOW_ROUND", time.Minute), "The slowqueries pattern runs every statement once per round")
+	slowDigestFile := flag.String("slow-digest-file", getEnv("SLOW_DIGEST_FILE", ""), "Write the slowqueries statements and which are expected to be reported to this JSON file (empty disables)")
+	flag.Parse()
+
+	logger, err := dbload.NewLogger(*logFormat, *logLevel, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *healthInterval <= 0 || *maxBackoff < dbload.MinReconnectBackoff {
+		fmt.Fprintf(os.Stderr, "-health-interval must be positive and -reconnect-max-backoff at least %v\n", dbload.MinReconnectBackoff)
+		os.Exit(2)
+	}
+	if err := tls.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *txMaxRetries < 0 {
+		fmt.Fprintf(os.Stderr, "-tx-max-retries must not be negative, got %d\n", *txMaxRetries)
+		os.Exit(2)
+	}
+	if *poolStatsInterval <= 0 {
+		fmt.Fprintf(os.Stderr, "-pool-stats-interval must be positive, got %v\n", *poolStatsInterval)
+		os.Exit(2)
+	}
+	if *deadlockRate < 0 {
+		fmt.Fprintf(os.Stderr, "-deadlock-rate must not be negative, got %g\n", *deadlockRate)
+		os.Exit(2)
+	}
+	var slowPlan *slowQueryPlan
+	if *pattern == "slowqueries" {
+		slowPlan, err = newSlowQueryPlan(*slowStatements, *slowMinLatency, *slowMaxLatency, *slowThreshold, *slowRound)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if *queryTimeout > 0 && *slowMaxLatency >= *queryTimeout {
+			fmt.Fprintf(os.Stderr, "-slow-max-latency must be below -query-timeout, got %v and %v\n", *slowMaxLatency, *queryTimeout)
+			os.Exit(2)
+		}
+		if *slowDigestFile != "" {
+			if err := slowPlan.writeDigests(*slowDigestFile); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write -slow-digest-file: %v\n", err)
+				os.Exit(2)
+			}
+		}
+	}
+	defer logger.Sync()
+
 	lg := &LoadGenerator{
-		pattern: getEnv("LOAD_PATTERN", "mixed"),
-		qps:     getEnvInt("QUERIES_PER_SECOND", 10),
+		logger:  logger,
+		pattern:      *pattern,
+		qps:          *qps,
+		queryTimeout: *queryTimeout,
+		deadlockRate: *deadlockRate,
+		txRetries:    dbload.NewTxRetrier(*txMaxRetries),
+		slowPlan:     slowPlan,
+	}
+
+	if *eventLogPath != "" {
+		lg.events, err = newEventLog(*eventLogPath, *eventLogFormat, int64(*eventLogMaxMB)<<20)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
 	}
 
 	// Connect to PostgreSQL
-	pgDSN := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	pgDSN := dbload.PostgresDSN(
 		getEnv("POSTGRES_HOST", "localhost"),
 		getEnv("POSTGRES_PORT", "5432"),
 		getEnv("POSTGRES_USER", "postgres"),
 		getEnv("POSTGRES_PASSWORD", "postgres"),
 		getEnv("POSTGRES_DB", "testdb"),
+		tls,
 	)
+	logger.Info("Connecting to PostgreSQL", zap.String("dsn", dbload.RedactDSN(pgDSN)))
 	
-	var err error
 	lg.db, err = sql.Open("postgres", pgDSN)
 	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		logger.Fatal("Failed to connect to PostgreSQL", zap.Error(err))
 	}
 	defer lg.db.Close()
 
@@ -51,6 +164,9 @@ func main() {
 	lg.db.SetMaxOpenConns(20)
 	lg.db.SetMaxIdleConns(10)
 	lg.db.SetConnMaxLifetime(5 * time.Minute)
+	lg.conn = dbload.NewConnMonitor(lg.db.PingContext, logger, *healthInterval, *maxBackoff)
+	lg.pools = dbload.NewPoolMetrics()
+	lg.pools.Add("primary", lg.db.Stats)
 
 	// Create context for graceful shutdown
 	lg.ctx, lg.cancel = context.WithCancel(context.Background())
@@ -59,22 +175,73 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	log.Printf("PostgreSQL load generator started: pattern=%s, qps=%d", lg.pattern, lg.qps)
+	logger.Info("PostgreSQL load generator started",
+		zap.String("pattern", lg.pattern),
+		zap.Int("qps", lg.qps),
+		zap.Duration("query_timeout", lg.queryTimeout))
 	
 	// Create test tables
 	if err := lg.createTables(); err != nil {
-		log.Fatalf("Failed to create tables: %v", err)
+		logger.Fatal("Failed to create tables", zap.Error(err))
 	}
 	
 	// Start load generation
 	lg.generateLoad()
 
+	lg.wg.Add(1)
+	go func() {
+		defer lg.wg.Done()
+		lg.conn.Run(lg.ctx)
+	}()
+
+	if *metricsAddr != "" {
+		lg.wg.Add(2)
+		go func() {
+			defer lg.wg.Done()
+			lg.pools.Run(lg.ctx, *poolStatsInterval)
+		}()
+		go func() {
+			defer lg.wg.Done()
+			lg.pools.Serve(lg.ctx, *metricsAddr, logger)
+		}()
+	}
+
+	if lg.events != nil {
+		lg.wg.Add(1)
+		go func() {
+			defer lg.wg.Done()
+			lg.events.run(lg.ctx, *eventLogFlush, func(err error) {
+				logger.Error("Failed to write query events", zap.Error(err))
+			})
+		}()
+		logger.Info("Writing query event log",
+			zap.String("path", *eventLogPath),
+			zap.String("format", *eventLogFormat))
+	}
+
 	// Wait for interrupt
 	<-sigChan
-	log.Println("Shutting down...")
+	logger.Info("Shutting down...")
 	lg.cancel()
 	lg.wg.Wait()
-	log.Println("Load generator stopped")
+	if lg.events != nil {
+		if err := lg.events.Close(); err != nil {
+			logger.Error("Failed to close query event log", zap.Error(err))
+		}
+	}
+	pool := lg.db.Stats()
+	logger.Info("Load generator stopped",
+		zap.Int64("query_errors", lg.stats.Errors()),
+		zap.Int64("query_timeouts", lg.stats.Timeouts()),
+		zap.Int64("query_disconnects", lg.stats.Disconnects()),
+		zap.Int64("database_outages", lg.conn.Outages()),
+		zap.Int64("tx_retries", lg.txRetries.Retries()),
+		zap.Int64("tx_retries_exhausted", lg.txRetries.Exhausted()),
+		zap.Int64("pool_waits", pool.WaitCount),
+		zap.Duration("pool_wait_time", pool.WaitDuration))
+	if lg.deadlocks != nil {
+		logger.Info("Deadlock rate", lg.deadlocks.report(time.Now()).fields()...)
+	}
 }
 
 func (lg *LoadGenerator) createTables() error {
@@ -120,6 +287,11 @@ func (lg *LoadGenerator) createTables() error {
 			data TEXT
 		)`,
 		// Indexes to exercise postgresql.index.scans
+		// Rows the -deadlock-rate pairs lock, two per pair
+		`CREATE TABLE IF NOT EXISTS deadlock_targets (
+			id INTEGER PRIMARY KEY,
+			touched_at TIMESTAMP
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_username ON users(username)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_email ON users(email)`,
 		`CREATE INDEX IF NOT EXISTS idx_products_category ON products(category)`,
@@ -133,6 +305,10 @@ func (lg *LoadGenerator) createTables() error {
 		`CREATE INDEX IF NOT EXISTS idx_products_lower_name ON products(LOWER(name))`,
 	}
 
+	if lg.slowPlan != nil {
+		tables = append(tables, lg.slowPlan.createTableStatements()...)
+	}
+
 	for _, query := range tables {
 		if _, err := lg.db.ExecContext(lg.ctx, query); err != nil {
 			return fmt.Errorf("failed to execute: %s - %v", query, err)
@@ -140,7 +316,7 @@ func (lg *LoadGenerator) createTables() error {
 	}
 
 	// Insert initial data
-	log.Println("Inserting initial test data...")
+	lg.logger.Info("Inserting initial test data...")
 	
 	// Insert users
 	for i := 0; i < 100; i++ {
@@ -151,10 +327,19 @@ func (lg *LoadGenerator) createTables() error {
 			fmt.Sprintf(`{"role": "user", "level": %d}`, rand.Intn(10)),
 		)
 		if err != nil {
-			log.Printf("Failed to insert user: %v", err)
+			lg.logger.Warn("Failed to insert user", zap.Int("user", i), zap.Error(err))
 		}
 	}
 
+	// Seed the deadlock rows; an UPDATE of a missing row takes no lock
+	_, err := lg.db.ExecContext(lg.ctx,
+		"INSERT INTO deadlock_targets (id) SELECT generate_series(1, $1) ON CONFLICT (id) DO NOTHING",
+		2*deadlockTargetPairs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to seed deadlock_targets: %w", err)
+	}
+
 	// Insert products
 	categories := []string{"electronics", "books", "clothing", "food", "toys"}
 	for i := 0; i < 500; i++ {
@@ -167,7 +352,7 @@ func (lg *LoadGenerator) createTables() error {
 			"Lorem ipsum dolor sit amet, consectetur adipiscing elit.",
 		)
 		if err != nil {
-			log.Printf("Failed to insert product: %v", err)
+			lg.logger.Warn("Failed to insert product", zap.Int("product", i), zap.Error(err))
 		}
 	}
 
@@ -183,10 +368,17 @@ func (lg *LoadGenerator) generateLoad() {
 		"mixed":      lg.mixedQueries,
 		"stress":     lg.stressTest,
 	}
+	if lg.slowPlan != nil {
+		patterns["slowqueries"] = lg.slowQueries
+		lg.logger.Info("Running statements with a controlled latency distribution", lg.slowPlan.fields()...)
+		for _, s := range lg.slowPlan.expected() {
+			lg.logger.Debug("Expected slow query", zap.String("statement", s.Statement), zap.Duration("latency", s.Latency))
+		}
+	}
 
 	pattern, exists := patterns[lg.pattern]
 	if !exists {
-		log.Printf("Unknown pattern %s, using mixed", lg.pattern)
+		lg.logger.Warn("Unknown pattern, using mixed", zap.String("pattern", lg.pattern))
 		pattern = patterns["mixed"]
 	}
 
@@ -202,6 +394,13 @@ func (lg *LoadGenerator) generateLoad() {
 	go lg.vacuumWorker()
 	go lg.checkpointWorker()
 	go lg.connectionChurnWorker()
+
+	if lg.deadlockRate > 0 {
+		lg.deadlocks = newDeadlockController(lg.deadlockRate, time.Now())
+		lg.wg.Add(1)
+		go lg.deadlockWorker()
+		lg.logger.Info("Inducing deadlocks at a target rate", zap.Float64("per_min", lg.deadlockRate))
+	}
 }
 
 func (lg *LoadGenerator) simpleQueries() {
@@ -221,6 +420,9 @@ func (lg *LoadGenerator) simpleQueries() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			go queries[rand.Intn(len(queries))]()
 		}
 	}
@@ -235,6 +437,9 @@ func (lg *LoadGenerator) complexQueries() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			go lg.complexJoin()
 			go lg.aggregateQuery()
 		}
@@ -250,6 +455,9 @@ func (lg *LoadGenerator) analyticalQueries() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			go lg.analyticalQuery()
 			go lg.windowFunction()
 		}
@@ -265,8 +473,12 @@ func (lg *LoadGenerator) blockingQueries() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			go lg.lockingTransaction()
-			if rand.Float32() < 0.1 { // 10% chance
+			// With -deadlock-rate the deadlock worker owns deadlocks
+			if lg.deadlockRate == 0 && rand.Float32() < 0.1 { // 10% chance
 				go lg.createDeadlock()
 			}
 		}
@@ -308,6 +520,12 @@ func (lg *LoadGenerator) stressTest() {
 				case <-lg.ctx.Done():
 					return
 				case <-ticker.C:
+					if lg.offline() {
+						continue
+					}
+			if lg.offline() {
+				continue
+			}
 					lg.selectByPrimaryKey()
 					lg.insertData()
 				}
@@ -319,71 +537,90 @@ func (lg *LoadGenerator) stressTest() {
 // Query implementations
 
 func (lg *LoadGenerator) selectByPrimaryKey() {
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
 	var id int
 	var username string
-	err := lg.db.QueryRowContext(lg.ctx, 
+	err := lg.db.QueryRowContext(ctx, 
 		"SELECT id, username FROM users WHERE id = $1", 
 		rand.Intn(100)+1,
 	).Scan(&id, &username)
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Select by PK error: %v", err)
-	}
+	lg.finishQuery(ctx, "select_by_pk", start, rowScanned(err), err)
 }
 
 func (lg *LoadGenerator) selectByIndex() {
-	rows, err := lg.db.QueryContext(lg.ctx,
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	rows, err := lg.db.QueryContext(ctx,
 		"SELECT id, name, price FROM products WHERE category = $1 LIMIT 10",
 		[]string{"electronics", "books", "clothing", "food", "toys"}[rand.Intn(5)],
 	)
 	if err != nil {
-		log.Printf("Select by index error: %v", err)
+		lg.finishQuery(ctx, "select_by_index", start, 0, err)
 		return
 	}
 	defer rows.Close()
 	
 	// Consume results to exercise postgresql.rows metric
+	var n int64
 	for rows.Next() {
 		var id int
 		var name string
 		var price float64
 		rows.Scan(&id, &name, &price)
+		n++
 	}
+	lg.finishQuery(ctx, "select_by_index", start, n, rows.Err())
 }
 
 func (lg *LoadGenerator) insertData() {
-	_, err := lg.db.ExecContext(lg.ctx,
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	n, err := rowsAffected(lg.db.ExecContext(ctx,
 		"INSERT INTO analytics (event_type, user_id, data) VALUES ($1, $2, $3)",
 		[]string{"page_view", "click", "purchase", "search"}[rand.Intn(4)],
 		rand.Intn(100)+1,
 		fmt.Sprintf(`{"timestamp": "%s", "value": %d}`, time.Now().Format(time.RFC3339), rand.Intn(100)),
-	)
-	if err != nil {
-		log.Printf("Insert error: %v", err)
-	}
+	))
+	lg.finishQuery(ctx, "insert", start, n, err)
 }
 
 func (lg *LoadGenerator) updateData() {
-	_, err := lg.db.ExecContext(lg.ctx,
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	n, err := rowsAffected(lg.db.ExecContext(ctx,
 		"UPDATE products SET stock = stock - 1 WHERE id = $1 AND stock > 0",
 		rand.Intn(500)+1,
-	)
-	if err != nil {
-		log.Printf("Update error: %v", err)
-	}
+	))
+	lg.finishQuery(ctx, "update", start, n, err)
 }
 
 func (lg *LoadGenerator) deleteData() {
-	_, err := lg.db.ExecContext(lg.ctx,
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	n, err := rowsAffected(lg.db.ExecContext(ctx,
 		"DELETE FROM analytics WHERE created_at < NOW() - INTERVAL '7 days' AND event_type = $1",
 		[]string{"page_view", "click"}[rand.Intn(2)],
-	)
-	if err != nil {
-		log.Printf("Delete error: %v", err)
-	}
+	))
+	lg.finishQuery(ctx, "delete", start, n, err)
 }
 
 func (lg *LoadGenerator) complexJoin() {
-	rows, err := lg.db.QueryContext(lg.ctx, `
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	rows, err := lg.db.QueryContext(ctx, `
 		SELECT u.username, COUNT(o.id) as order_count, SUM(o.total) as total_spent
 		FROM users u
 		LEFT JOIN orders o ON u.id = o.user_id
@@ -394,35 +631,44 @@ func (lg *LoadGenerator) complexJoin() {
 		LIMIT 10
 	`)
 	if err != nil {
-		log.Printf("Complex join error: %v", err)
+		lg.finishQuery(ctx, "complex_join", start, 0, err)
 		return
 	}
 	defer rows.Close()
 	
+	var n int64
 	for rows.Next() {
 		var username string
 		var orderCount int
 		var totalSpent float64
 		rows.Scan(&username, &orderCount, &totalSpent)
+		n++
 	}
+	lg.finishQuery(ctx, "complex_join", start, n, rows.Err())
 }
 
 func (lg *LoadGenerator) aggregateQuery() {
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
 	var count int
-	err := lg.db.QueryRowContext(lg.ctx, `
+	err := lg.db.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT user_id) 
 		FROM analytics 
 		WHERE event_type = $1 
 		AND created_at > NOW() - INTERVAL '1 hour'
 	`, "page_view").Scan(&count)
-	if err != nil {
-		log.Printf("Aggregate query error: %v", err)
-	}
+	lg.finishQuery(ctx, "aggregate", start, rowScanned(err), err)
 }
 
 func (lg *LoadGenerator) analyticalQuery() {
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
 	// Force sequential scan on purpose to exercise postgresql.sequential_scans
-	rows, err := lg.db.QueryContext(lg.ctx, `
+	start := time.Now()
+	rows, err := lg.db.QueryContext(ctx, `
 		WITH monthly_sales AS (
 			SELECT 
 				DATE_TRUNC('month', created_at) as month,
@@ -438,22 +684,29 @@ func (lg *LoadGenerator) analyticalQuery() {
 		LIMIT 12
 	`)
 	if err != nil {
-		log.Printf("Analytical query error: %v", err)
+		lg.finishQuery(ctx, "analytical", start, 0, err)
 		return
 	}
 	defer rows.Close()
 	
+	var n int64
 	for rows.Next() {
 		var month time.Time
 		var orderCount int
 		var revenue, avgValue float64
 		rows.Scan(&month, &orderCount, &revenue, &avgValue)
+		n++
 	}
+	lg.finishQuery(ctx, "analytical", start, n, rows.Err())
 }
 
 func (lg *LoadGenerator) windowFunction() {
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
 	// Query with temp file generation
-	rows, err := lg.db.QueryContext(lg.ctx, `
+	start := time.Now()
+	rows, err := lg.db.QueryContext(ctx, `
 		SELECT 
 			user_id,
 			event_type,
@@ -465,57 +718,65 @@ func (lg *LoadGenerator) windowFunction() {
 		ORDER BY user_id, created_at DESC
 	`)
 	if err != nil {
-		log.Printf("Window function error: %v", err)
+		lg.finishQuery(ctx, "window_function", start, 0, err)
 		return
 	}
 	defer rows.Close()
 	
 	// Consume all rows
+	var n int64
 	for rows.Next() {
 		var userID int
 		var eventType string
 		var createdAt, prevEventTime sql.NullTime
 		var rn int
 		rows.Scan(&userID, &eventType, &createdAt, &rn, &prevEventTime)
+		n++
 	}
+	lg.finishQuery(ctx, "window_function", start, n, rows.Err())
 }
 
 func (lg *LoadGenerator) lockingTransaction() {
-	tx, err := lg.db.BeginTx(lg.ctx, nil)
-	if err != nil {
-		log.Printf("Begin transaction error: %v", err)
-		return
-	}
-	defer tx.Rollback()
-
-	// Lock a row
-	var total float64
-	err = tx.QueryRow(
-		"SELECT total FROM orders WHERE id = $1 FOR UPDATE",
-		rand.Intn(100)+1,
-	).Scan(&total)
-	
-	if err != nil && err != sql.ErrNoRows {
-		return
-	}
-
-	// Simulate some work
-	time.Sleep(time.Duration(rand.Intn(200)) * time.Millisecond)
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	err := lg.txRetries.RunTx(ctx, lg.db, func(tx *sql.Tx) error {
+		// Lock a row; waiting for it is what the query timeout bounds
+		start := time.Now()
+		var total float64
+		err := tx.QueryRowContext(ctx,
+			"SELECT total FROM orders WHERE id = $1 FOR UPDATE",
+			rand.Intn(100)+1,
+		).Scan(&total)
+		lg.finishQuery(ctx, "select_for_update", start, rowScanned(err), err)
+		if err != nil {
+			return err
+		}
 
-	// Update the locked row
-	_, err = tx.Exec(
-		"UPDATE orders SET status = $1, total = $2 WHERE id = $3",
-		[]string{"pending", "processing", "completed"}[rand.Intn(3)],
-		total * 1.1,
-		rand.Intn(100)+1,
-	)
+		// Simulate some work
+		time.Sleep(time.Duration(rand.Intn(200)) * time.Millisecond)
+
+		// Update the locked row
+		start = time.Now()
+		n, err := rowsAffected(tx.ExecContext(ctx,
+			"UPDATE orders SET status = $1, total = $2 WHERE id = $3",
+			[]string{"pending", "processing", "completed"}[rand.Intn(3)],
+			total * 1.1,
+			rand.Intn(100)+1,
+		))
+		lg.finishQuery(ctx, "update_locked", start, n, err)
+		if err != nil {
+			return err
+		}
 
-	// Randomly commit or rollback to exercise both metrics
-	if rand.Float32() < 0.9 {
-		tx.Commit()
-	} else {
-		tx.Rollback()
-	}
+		// Randomly commit or rollback to exercise both metrics
+		if rand.Float32() >= 0.9 {
+			return dbload.ErrRollback
+		}
+		return nil
+	})
+	lg.finishTx(ctx, start, err)
 }
 
 func (lg *LoadGenerator) createDeadlock() {
@@ -523,25 +784,110 @@ func (lg *LoadGenerator) createDeadlock() {
 	orderID1 := rand.Intn(50) + 1
 	orderID2 := rand.Intn(50) + 51
 
-	// First transaction
-	go func() {
-		tx, _ := lg.db.BeginTx(lg.ctx, nil)
-		defer tx.Rollback()
-		
-		tx.Exec("UPDATE orders SET status = 'lock1' WHERE id = $1", orderID1)
-		time.Sleep(100 * time.Millisecond)
-		tx.Exec("UPDATE orders SET status = 'lock1' WHERE id = $1", orderID2)
-	}()
+	// Each side locks one order and then the other. PostgreSQL aborts one
+	// side of the deadlock, which counts as an error, and the victim is
+	// retried once the other side has committed.
+	side := func(status string, first, second int) {
+		ctx, cancel := lg.queryContext()
+		defer cancel()
+
+		start := time.Now()
+		err := lg.txRetries.RunTx(ctx, lg.db, func(tx *sql.Tx) error {
+			start := time.Now()
+			n, err := rowsAffected(tx.ExecContext(ctx, "UPDATE orders SET status = $1 WHERE id = $2", status, first))
+			lg.finishQuery(ctx, "deadlock_update", start, n, err)
+			if err != nil {
+				return err
+			}
+			time.Sleep(100 * time.Millisecond)
 
+			start = time.Now()
+			n, err = rowsAffected(tx.ExecContext(ctx, "UPDATE orders SET status = $1 WHERE id = $2", status, second))
+			lg.finishQuery(ctx, "deadlock_update", start, n, err)
+			return err
+		})
+		lg.finishTx(ctx, start, err)
+	}
+
+	go side("lock1", orderID1, orderID2)
 	// Second transaction (reverse order)
-	go func() {
-		tx, _ := lg.db.BeginTx(lg.ctx, nil)
-		defer tx.Rollback()
-		
-		tx.Exec("UPDATE orders SET status = 'lock2' WHERE id = $1", orderID2)
-		time.Sleep(100 * time.Millisecond)
-		tx.Exec("UPDATE orders SET status = 'lock2' WHERE id = $1", orderID1)
-	}()
+	go side("lock2", orderID2, orderID1)
+}
+
+// finishTx records the begin or commit that failed a transaction started at
+// start; runTx callers record their statements as they run
+func (lg *LoadGenerator) finishTx(ctx context.Context, start time.Time, err error) {
+	var step *dbload.TxStepError
+	if errors.As(err, &step) {
+		lg.finishQuery(ctx, step.Step+"_transaction", start, 0, step.Err)
+	}
+}
+
+// queryContext returns the context for one query or transaction, bounded by
+// -query-timeout and cancelled on shutdown
+func (lg *LoadGenerator) queryContext() (context.Context, context.CancelFunc) {
+	return dbload.WithQueryTimeout(lg.ctx, lg.queryTimeout)
+}
+
+// finishQuery handles the end of a query run with ctx that started at start
+// and returned rows rows: a failure is counted and logged, and the query is
+// added to the event log unless it was cut short by shutdown
+func (lg *LoadGenerator) finishQuery(ctx context.Context, queryType string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	outcome := dbload.QueryOK
+	if err != nil {
+		outcome = lg.queryError(ctx, queryType, err)
+	}
+	if lg.events == nil || outcome == dbload.QueryCanceled {
+		return
+	}
+
+	event := queryEvent{Time: start, QueryType: queryType, Duration: duration, Rows: rows}
+	if outcome != dbload.QueryOK {
+		event.Error = err.Error()
+	}
+	lg.events.record(event)
+}
+
+// queryError counts a failed query run with ctx as a timeout or an error and
+// logs it at warn level, so -log-level=error silences them
+func (lg *LoadGenerator) queryError(ctx context.Context, queryType string, err error) dbload.QueryOutcome {
+	outcome := lg.stats.Record(lg.ctx, ctx, err)
+	switch outcome {
+	case dbload.QueryTimedOut:
+		lg.logger.Warn("Query timed out",
+			zap.String("query_type", queryType),
+			zap.Duration("timeout", lg.queryTimeout))
+	case dbload.QueryFailed:
+		lg.logger.Warn("Query failed", zap.String("query_type", queryType), zap.Error(err))
+	case dbload.QueryDisconnected:
+		// Not the query's fault: pause the workers until the database is back
+		lg.conn.ReportError(err)
+	}
+	return outcome
+}
+
+// offline reports whether the database is unreachable. Workers skip their
+// turn until the connection monitor sees it recover.
+func (lg *LoadGenerator) offline() bool {
+	return !lg.conn.Connected()
+}
+
+// rowScanned is the row count of a single-row query that returned err
+func rowScanned(err error) int64 {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+// rowsAffected returns the rows changed by an Exec that returned res and err
+func rowsAffected(res sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
 }
 
 // Background workers
@@ -556,10 +902,17 @@ func (lg *LoadGenerator) vacuumWorker() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			// Run VACUUM to exercise postgresql.table.vacuum.count
 			tables := []string{"analytics", "sessions", "orders"}
 			for _, table := range tables {
-				lg.db.ExecContext(lg.ctx, fmt.Sprintf("VACUUM %s", table))
+				ctx, cancel := lg.queryContext()
+				start := time.Now()
+				_, err := lg.db.ExecContext(ctx, fmt.Sprintf("VACUUM %s", table))
+				lg.finishQuery(ctx, "vacuum", start, 0, err)
+				cancel()
 			}
 		}
 	}
@@ -575,8 +928,15 @@ func (lg *LoadGenerator) checkpointWorker() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			// Force checkpoint to exercise postgresql.bgwriter metrics
-			lg.db.ExecContext(lg.ctx, "CHECKPOINT")
+			ctx, cancel := lg.queryContext()
+			start := time.Now()
+			_, err := lg.db.ExecContext(ctx, "CHECKPOINT")
+			lg.finishQuery(ctx, "checkpoint", start, 0, err)
+			cancel()
 		}
 	}
 }
@@ -591,11 +951,15 @@ func (lg *LoadGenerator) connectionChurnWorker() {
 		case <-lg.ctx.Done():
 			return
 		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
 			// Create new connections to exercise postgresql.backends
 			for i := 0; i < 5; i++ {
 				go func() {
 					conn, err := lg.db.Conn(lg.ctx)
 					if err != nil {
+						lg.conn.ReportError(err)
 						return
 					}
 					
@@ -615,6 +979,24 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if i, err := strconv.Atoi(value); err == nil {
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/database-intelligence/tools/internal/dbload"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestQueryError_LogLevelSuppressesQueryErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := dbload.NewLogger(dbload.LogFormatConsole, "error", zapcore.AddSync(&buf))
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	lg := &LoadGenerator{logger: logger, ctx: context.Background()}
+	lg.queryError(context.Background(), "select_by_pk", errors.New("connection reset"))
+	if buf.Len() != 0 {
+		t.Errorf("query error logged at -log-level=error: %s", buf.String())
+	}
+
+	logger.Error("Failed to create tables")
+	if !strings.Contains(buf.String(), "Failed to create tables") {
+		t.Errorf("console output %q is missing the message", buf.String())
+	}
+}
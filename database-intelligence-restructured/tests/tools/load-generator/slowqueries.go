@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// slowQueryTable has a column per statement; selecting a different
+	// column gives each statement its own pg_stat_statements query ID
+	slowQueryTable = "slow_query_targets"
+	// slowQueryMaxStatements stays well below PostgreSQL's 1600 column limit
+	slowQueryMaxStatements = 1000
+	// slowQueryMaxConcurrency is how many of the pool's connections the
+	// statements may hold at once, leaving the rest to the background workers
+	slowQueryMaxConcurrency = 10
+)
+
+// slowStatement is one distinct statement of the slowqueries pattern
+type slowStatement struct {
+	// Statement is the text pg_stat_statements records, and the
+	// db.statement pgslowqueries reports
+	Statement string `json:"statement"`
+	// Latency is how long every execution sleeps
+	Latency time.Duration `json:"-"`
+	// LatencyMs is Latency for the digest file
+	LatencyMs float64 `json:"latency_ms"`
+	// Expected means the mean execution time reaches the threshold, so the
+	// statement is reported as a slow query
+	Expected bool `json:"expected"`
+
+	column string
+}
+
+// slowQueryPlan spreads the latencies of count statements evenly from the
+// minimum to the maximum latency and runs each statement once per round. pg_sleep makes every
+// execution take its statement's latency, so the distribution over
+// statements is known in advance, and so is the set of statements whose
+// mean execution time reaches the receiver's threshold.
+type slowQueryPlan struct {
+	statements []slowStatement
+	threshold  time.Duration
+	round      time.Duration
+}
+
+func newSlowQueryPlan(count int, minLatency, maxLatency, threshold, round time.Duration) (*slowQueryPlan, error) {
+	switch {
+	case count <= 0 || count > slowQueryMaxStatements:
+		return nil, fmt.Errorf("-slow-statements must be between 1 and %d, got %d", slowQueryMaxStatements, count)
+	case minLatency <= 0 || maxLatency < minLatency:
+		return nil, fmt.Errorf("-slow-min-latency must be positive and at most -slow-max-latency, got %v and %v", minLatency, maxLatency)
+	case threshold <= 0:
+		return nil, fmt.Errorf("-slow-threshold must be positive, got %v", threshold)
+	case round <= 0:
+		return nil, fmt.Errorf("-slow-round must be positive, got %v", round)
+	}
+
+	p := &slowQueryPlan{threshold: threshold, round: round}
+	for i := 0; i < count; i++ {
+		latency := minLatency
+		if count > 1 {
+			latency += time.Duration(float64(maxLatency-minLatency) * float64(i) / float64(count-1))
+		}
+		latency = latency.Round(time.Millisecond)
+		column := fmt.Sprintf("c%03d", i+1)
+		p.statements = append(p.statements, slowStatement{
+			Statement: fmt.Sprintf("SELECT pg_sleep($1), %s FROM %s", column, slowQueryTable),
+			Latency:   latency,
+			LatencyMs: float64(latency) / float64(time.Millisecond),
+			Expected:  latency >= threshold,
+			column:    column,
+		})
+	}
+
+	if c := p.concurrency(); c > slowQueryMaxConcurrency {
+		return nil, fmt.Errorf("running every statement once per %v needs %d connections, more than %d; raise -slow-round or lower the latencies",
+			round, c, slowQueryMaxConcurrency)
+	}
+	return p, nil
+}
+
+// interval returns the time between statement starts, which spreads one
+// execution of every statement over a round
+func (p *slowQueryPlan) interval() time.Duration {
+	return p.round / time.Duration(len(p.statements))
+}
+
+// concurrency returns how many statements run at once at most, once the
+// rounds have settled into their steady state
+func (p *slowQueryPlan) concurrency() int {
+	// The count only rises when a statement starts, so check every start
+	interval := p.interval()
+	peak := 0
+	for i := range p.statements {
+		now := time.Duration(i) * interval
+		running := 0
+		for j, s := range p.statements {
+			// Statement j last started age ago, and before that a round
+			// earlier each time
+			age := (now - time.Duration(j)*interval + p.round) % p.round
+			for ; age < s.Latency; age += p.round {
+				running++
+			}
+		}
+		peak = max(peak, running)
+	}
+	return peak
+}
+
+// percentile returns the latency that the fraction q of statements stay
+// at or below
+func (p *slowQueryPlan) percentile(q float64) time.Duration {
+	// Latencies rise with the statement index
+	i := int(math.Ceil(q*float64(len(p.statements)))) - 1
+	i = min(max(i, 0), len(p.statements)-1)
+	return p.statements[i].Latency
+}
+
+// expected returns the statements the receiver reports as slow queries
+func (p *slowQueryPlan) expected() []slowStatement {
+	var expected []slowStatement
+	for _, s := range p.statements {
+		if s.Expected {
+			expected = append(expected, s)
+		}
+	}
+	return expected
+}
+
+// createTableStatements create the table the statements select from, with
+// a column per statement and the single row each statement reads, so
+// pg_sleep runs once per execution
+func (p *slowQueryPlan) createTableStatements() []string {
+	columns := make([]string, len(p.statements))
+	for i, s := range p.statements {
+		columns[i] = "ADD COLUMN IF NOT EXISTS " + s.column + " INTEGER DEFAULT 0"
+	}
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + slowQueryTable + " (id INTEGER PRIMARY KEY)",
+		"ALTER TABLE " + slowQueryTable + " " + strings.Join(columns, ", "),
+		"INSERT INTO " + slowQueryTable + " (id) VALUES (1) ON CONFLICT (id) DO NOTHING",
+	}
+}
+
+// slowQueryDigests is the digest file: the statements tests can expect
+// pgslowqueries to report with a min_mean_exec_time of ThresholdMs
+type slowQueryDigests struct {
+	ThresholdMs float64         `json:"threshold_ms"`
+	RoundMs     float64         `json:"round_ms"`
+	P50Ms       float64         `json:"p50_ms"`
+	P95Ms       float64         `json:"p95_ms"`
+	Statements  []slowStatement `json:"statements"`
+}
+
+func (p *slowQueryPlan) digests() slowQueryDigests {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return slowQueryDigests{
+		ThresholdMs: ms(p.threshold),
+		RoundMs:     ms(p.round),
+		P50Ms:       ms(p.percentile(0.5)),
+		P95Ms:       ms(p.percentile(0.95)),
+		Statements:  p.statements,
+	}
+}
+
+// writeDigests writes the digest file as JSON
+func (p *slowQueryPlan) writeDigests(path string) error {
+	data, err := json.MarshalIndent(p.digests(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func (p *slowQueryPlan) fields() []zap.Field {
+	return []zap.Field{
+		zap.Int("statements", len(p.statements)),
+		zap.Int("expected_slow", len(p.expected())),
+		zap.Duration("threshold", p.threshold),
+		zap.Duration("p50", p.percentile(0.5)),
+		zap.Duration("p95", p.percentile(0.95)),
+		zap.Duration("round", p.round),
+		zap.Int("concurrency", p.concurrency()),
+	}
+}
+
+// slowQueries runs the statements of the plan in order, one every
+// interval, so each runs once per round
+func (lg *LoadGenerator) slowQueries() {
+	ticker := time.NewTicker(lg.slowPlan.interval())
+	defer ticker.Stop()
+
+	next := 0
+	for {
+		select {
+		case <-lg.ctx.Done():
+			return
+		case <-ticker.C:
+			if lg.offline() {
+				continue
+			}
+			s := lg.slowPlan.statements[next]
+			next = (next + 1) % len(lg.slowPlan.statements)
+			lg.wg.Add(1)
+			go func() {
+				defer lg.wg.Done()
+				lg.slowStatement(s)
+			}()
+		}
+	}
+}
+
+func (lg *LoadGenerator) slowStatement(s slowStatement) {
+	ctx, cancel := lg.queryContext()
+	defer cancel()
+
+	start := time.Now()
+	n, err := rowsAffected(lg.db.ExecContext(ctx, s.Statement, s.Latency.Seconds()))
+	lg.finishQuery(ctx, "slow_statement", start, n, err)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowQueryPlanDistribution(t *testing.T) {
+	p, err := newSlowQueryPlan(100, 100*time.Millisecond, 1500*time.Millisecond, 500*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.statements) != 100 {
+		t.Fatalf("len(statements) = %d, want 100", len(p.statements))
+	}
+	first, last := p.statements[0], p.statements[99]
+	if first.Latency != 100*time.Millisecond || last.Latency != 1500*time.Millisecond {
+		t.Errorf("latencies range from %v to %v, want 100ms to 1.5s", first.Latency, last.Latency)
+	}
+	if first.Statement != "SELECT pg_sleep($1), c001 FROM slow_query_targets" {
+		t.Errorf("statement = %q", first.Statement)
+	}
+
+	// Every statement needs its own query ID
+	seen := make(map[string]bool)
+	for _, s := range p.statements {
+		if seen[s.Statement] {
+			t.Errorf("statement %q is not distinct", s.Statement)
+		}
+		seen[s.Statement] = true
+	}
+
+	if got := p.percentile(0.95); got != 1429*time.Millisecond {
+		t.Errorf("p95 = %v, want 1.429s", got)
+	}
+	if got := p.percentile(0.5); got != 793*time.Millisecond {
+		t.Errorf("p50 = %v, want 793ms", got)
+	}
+	if got := p.percentile(0); got != 100*time.Millisecond {
+		t.Errorf("p0 = %v, want 100ms", got)
+	}
+
+	// Statement 30 sleeps 100ms + 1.4s*29/99 = 510ms, the first at or above
+	// the threshold
+	expected := p.expected()
+	if len(expected) != 71 {
+		t.Fatalf("len(expected) = %d, want 71", len(expected))
+	}
+	if expected[0].column != "c030" || expected[0].Latency != 510*time.Millisecond {
+		t.Errorf("first expected statement = %s at %v, want c030 at 510ms", expected[0].column, expected[0].Latency)
+	}
+	if p.statements[28].Expected {
+		t.Errorf("statement c029 at %v must not be expected", p.statements[28].Latency)
+	}
+}
+
+func TestSlowQueryPlanSingleStatement(t *testing.T) {
+	p, err := newSlowQueryPlan(1, time.Second, 2*time.Second, 500*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.statements[0].Latency != time.Second {
+		t.Errorf("latency = %v, want the minimum latency", p.statements[0].Latency)
+	}
+	if got := p.interval(); got != time.Minute {
+		t.Errorf("interval() = %v, want 1m", got)
+	}
+}
+
+func TestSlowQueryPlanScheduling(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		min, max    time.Duration
+		round       time.Duration
+		interval    time.Duration
+		concurrency int
+	}{
+		{"defaults", 100, 100 * time.Millisecond, 1500 * time.Millisecond, time.Minute, 600 * time.Millisecond, 3},
+		{"one at a time", 10, 100 * time.Millisecond, 900 * time.Millisecond, 10 * time.Second, time.Second, 1},
+		{"overlapping", 4, 2 * time.Second, 2 * time.Second, 4 * time.Second, time.Second, 2},
+		// The last statement still runs when the next round starts
+		{"across rounds", 2, time.Second, 3 * time.Second, 4 * time.Second, 2 * time.Second, 2},
+		// A statement longer than a round overlaps its own next run
+		{"longer than a round", 1, 5 * time.Second, 5 * time.Second, 2 * time.Second, 2 * time.Second, 3},
+	}
+	for _, tt := range tests {
+		p, err := newSlowQueryPlan(tt.count, tt.min, tt.max, time.Millisecond, tt.round)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := p.interval(); got != tt.interval {
+			t.Errorf("%s: interval() = %v, want %v", tt.name, got, tt.interval)
+		}
+		if got := p.concurrency(); got != tt.concurrency {
+			t.Errorf("%s: concurrency() = %d, want %d", tt.name, got, tt.concurrency)
+		}
+	}
+}
+
+func TestNewSlowQueryPlanErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		min, max time.Duration
+		round    time.Duration
+		want     string
+	}{
+		{"no statements", 0, time.Second, time.Second, time.Minute, "-slow-statements must be between 1 and 1000"},
+		{"too many statements", 1001, time.Second, time.Second, time.Hour, "-slow-statements must be between 1 and 1000"},
+		{"reversed latencies", 10, 2 * time.Second, time.Second, time.Minute, "-slow-min-latency must be positive"},
+		{"no round", 10, time.Second, time.Second, 0, "-slow-round must be positive"},
+		{"too concurrent", 100, 5 * time.Second, 5 * time.Second, 10 * time.Second, "needs 50 connections, more than 10"},
+	}
+	for _, tt := range tests {
+		_, err := newSlowQueryPlan(tt.count, tt.min, tt.max, 500*time.Millisecond, tt.round)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := newSlowQueryPlan(10, time.Second, time.Second, 0, time.Minute); err == nil {
+		t.Error("a zero threshold must be rejected")
+	}
+}
+
+func TestSlowQueryPlanWriteDigests(t *testing.T) {
+	p, err := newSlowQueryPlan(3, 400*time.Millisecond, 600*time.Millisecond, 500*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "digests.json")
+	if err := p.writeDigests(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got slowQueryDigests
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ThresholdMs != 500 || got.RoundMs != 60000 || got.P50Ms != 500 || got.P95Ms != 600 {
+		t.Errorf("summary = %+v", got)
+	}
+	if len(got.Statements) != 3 {
+		t.Fatalf("len(statements) = %d, want 3", len(got.Statements))
+	}
+	want := []struct {
+		latencyMs float64
+		expected  bool
+	}{{400, false}, {500, true}, {600, true}}
+	for i, s := range got.Statements {
+		if s.LatencyMs != want[i].latencyMs || s.Expected != want[i].expected {
+			t.Errorf("statement %d = %v ms, expected %t; want %v ms, expected %t",
+				i, s.LatencyMs, s.Expected, want[i].latencyMs, want[i].expected)
+		}
+	}
+}
@@ -2,4 +2,13 @@ module github.com/newrelic/database-intelligence/tools/postgres-test-generator
 
 go 1.21
 
-require github.com/lib/pq v1.10.9
+require (
+	github.com/lib/pq v1.10.9
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+require github.com/newrelic/database-intelligence/tools/internal/dbload v0.0.0
+
+replace github.com/newrelic/database-intelligence/tools/internal/dbload => ../internal/dbload
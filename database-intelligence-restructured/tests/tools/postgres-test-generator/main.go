@@ -3,17 +3,23 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"math/rand"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/newrelic/database-intelligence/tools/internal/dbload"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
@@ -22,28 +28,63 @@ type Config struct {
 	User               string
 	Password           string
 	Database           string
+	TLS                dbload.TLSConfig
 	MaxConnections     int
 	WorkersPerPattern  int
 	QueryInterval      time.Duration
 	EnableDeadlocks    bool
 	EnableTempFiles    bool
+	TempWorkMem        string
+	TempSortRows       int
 	EnableReplication  bool
+	ReplicaDSN         string
+	ReplicationHold    time.Duration
+	QueryTimeout       time.Duration
+	HealthInterval     time.Duration
+	MaxBackoff         time.Duration
+	LogFormat          string
+	LogLevel           string
+	MetricsAddr        string
+	PoolStatsInterval  time.Duration
+	TxMaxRetries       int
 }
 
 type TestGenerator struct {
 	config *Config
+	logger *zap.Logger
 	db     *sql.DB
+	// replica is only set when replication testing has a replica DSN
+	replica *sql.DB
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+	stats  dbload.QueryStats
+	// conn tracks whether the primary is reachable
+	conn *dbload.ConnMonitor
+	// pools samples the connection pool statistics for /metrics
+	pools *dbload.PoolMetrics
+	// txRetries retries transactions aborted by deadlocks or serialization
+	// failures
+	txRetries *dbload.TxRetrier
 }
 
 func main() {
-	config := parseFlags()
+	config, err := parseFlags(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Invalid flags: %v\n", err)
+		os.Exit(2)
+	}
+	
+	// parseFlags has validated the format and level
+	logger, _ := dbload.NewLogger(config.LogFormat, config.LogLevel, os.Stderr)
+	defer logger.Sync()
 	
-	generator, err := NewTestGenerator(config)
+	generator, err := NewTestGenerator(config, logger)
 	if err != nil {
-		log.Fatalf("Failed to create test generator: %v", err)
+		logger.Fatal("Failed to create test generator", zap.Error(err))
 	}
 	defer generator.Close()
 
@@ -51,40 +92,93 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	log.Println("Starting PostgreSQL test data generator...")
-	log.Printf("Generating load to exercise all %d+ metrics", 35)
+	logger.Info("Starting PostgreSQL test data generator...")
+	logger.Info("Generating load to exercise all metrics", zap.Int("metrics", 35))
 
 	// Start all test patterns
 	generator.Start()
 
 	// Wait for interrupt
 	<-sigChan
-	log.Println("Shutting down...")
+	logger.Info("Shutting down...")
 	generator.Stop()
 }
 
-func parseFlags() *Config {
+// workMemPattern matches the work_mem values accepted by -temp-work-mem.
+// SET cannot take a bind parameter, so the value is checked before it is
+// put into the statement.
+var workMemPattern = regexp.MustCompile(`^[0-9]+(kB|MB|GB)?$`)
+
+func parseFlags(args []string) (*Config, error) {
 	config := &Config{}
+	flags := flag.NewFlagSet("postgres-test-generator", flag.ContinueOnError)
+	
+	flags.StringVar(&config.Host, "host", getEnv("POSTGRES_HOST", "localhost"), "PostgreSQL host")
+	flags.IntVar(&config.Port, "port", getEnvInt("POSTGRES_PORT", 5432), "PostgreSQL port")
+	flags.StringVar(&config.User, "user", getEnv("POSTGRES_USER", "postgres"), "PostgreSQL user")
+	flags.StringVar(&config.Password, "password", getEnv("POSTGRES_PASSWORD", "postgres"), "PostgreSQL password")
+	flags.StringVar(&config.Database, "database", getEnv("POSTGRES_DB", "testdb"), "PostgreSQL database")
+	flags.StringVar(&config.TLS.SSLMode, "sslmode", getEnv("POSTGRES_SSLMODE", "disable"), "SSL mode: disable, allow, prefer, require, verify-ca or verify-full")
+	flags.StringVar(&config.TLS.RootCert, "sslrootcert", getEnv("POSTGRES_SSLROOTCERT", ""), "CA certificate file used to verify the server (verify-ca, verify-full)")
+	flags.StringVar(&config.TLS.Cert, "sslcert", getEnv("POSTGRES_SSLCERT", ""), "Client certificate file")
+	flags.StringVar(&config.TLS.Key, "sslkey", getEnv("POSTGRES_SSLKEY", ""), "Client private key file")
+	flags.IntVar(&config.MaxConnections, "max-connections", 50, "Maximum number of connections")
+	flags.IntVar(&config.WorkersPerPattern, "workers", 5, "Workers per test pattern")
+	flags.DurationVar(&config.QueryInterval, "interval", 100*time.Millisecond, "Query interval")
+	flags.BoolVar(&config.EnableDeadlocks, "deadlocks", true, "Enable deadlock generation")
+	flags.BoolVar(&config.EnableTempFiles, "temp-files", true, "Enable temp file generation")
+	flags.StringVar(&config.TempWorkMem, "temp-work-mem", "", "work_mem for the temp file sorts, e.g. 64kB (empty uses the server setting)")
+	flags.IntVar(&config.TempSortRows, "temp-sort-rows", 0, "Rows to sort per temp file run (0 uses the test_large self-join)")
+	flags.BoolVar(&config.EnableReplication, "replication", false, "Enable replication testing")
+	flags.StringVar(&config.ReplicaDSN, "replica-dsn", getEnv("POSTGRES_REPLICA_DSN", ""), "Replica connection string for replication testing")
+	flags.DurationVar(&config.ReplicationHold, "replication-hold", 0, "Hold transactions on the replica this long to induce replay lag (0 disables)")
+	flags.DurationVar(&config.QueryTimeout, "query-timeout", getEnvDuration("QUERY_TIMEOUT", 30*time.Second), "Cancel queries running longer than this (0 disables)")
+	flags.DurationVar(&config.HealthInterval, "health-interval", getEnvDuration("HEALTH_CHECK_INTERVAL", 5*time.Second), "How often the database connection is checked")
+	flags.DurationVar(&config.MaxBackoff, "reconnect-max-backoff", getEnvDuration("RECONNECT_MAX_BACKOFF", 30*time.Second), "Longest wait between reconnect attempts while the database is unreachable")
+	flags.StringVar(&config.LogFormat, "log-format", getEnv("LOG_FORMAT", dbload.LogFormatConsole), "Log format: console or json")
+	flags.StringVar(&config.LogLevel, "log-level", getEnv("LOG_LEVEL", "info"), "Log level: debug, info, warn or error")
+	flags.StringVar(&config.MetricsAddr, "metrics-addr", getEnv("METRICS_ADDR", ":9101"), "Serve connection pool metrics on /metrics at this address (empty disables)")
+	flags.IntVar(&config.TxMaxRetries, "tx-max-retries", getEnvInt("TX_MAX_RETRIES", 3), "Retry a transaction aborted by a deadlock or serialization failure up to this many times (0 disables)")
+	flags.DurationVar(&config.PoolStatsInterval, "pool-stats-interval", getEnvDuration("POOL_STATS_INTERVAL", 5*time.Second), "How often the connection pool statistics are sampled for /metrics")
+	
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
 	
-	flag.StringVar(&config.Host, "host", getEnv("POSTGRES_HOST", "localhost"), "PostgreSQL host")
-	flag.IntVar(&config.Port, "port", getEnvInt("POSTGRES_PORT", 5432), "PostgreSQL port")
-	flag.StringVar(&config.User, "user", getEnv("POSTGRES_USER", "postgres"), "PostgreSQL user")
-	flag.StringVar(&config.Password, "password", getEnv("POSTGRES_PASSWORD", "postgres"), "PostgreSQL password")
-	flag.StringVar(&config.Database, "database", getEnv("POSTGRES_DB", "testdb"), "PostgreSQL database")
-	flag.IntVar(&config.MaxConnections, "max-connections", 50, "Maximum number of connections")
-	flag.IntVar(&config.WorkersPerPattern, "workers", 5, "Workers per test pattern")
-	flag.DurationVar(&config.QueryInterval, "interval", 100*time.Millisecond, "Query interval")
-	flag.BoolVar(&config.EnableDeadlocks, "deadlocks", true, "Enable deadlock generation")
-	flag.BoolVar(&config.EnableTempFiles, "temp-files", true, "Enable temp file generation")
-	flag.BoolVar(&config.EnableReplication, "replication", false, "Enable replication testing")
+	if err := config.TLS.Validate(); err != nil {
+		return nil, err
+	}
+	if config.TempWorkMem != "" && !workMemPattern.MatchString(config.TempWorkMem) {
+		return nil, fmt.Errorf("invalid -temp-work-mem %q: expected a size such as 4096, 64kB or 4MB", config.TempWorkMem)
+	}
+	if config.TempSortRows < 0 {
+		return nil, fmt.Errorf("-temp-sort-rows must not be negative, got %d", config.TempSortRows)
+	}
+	if config.QueryTimeout < 0 {
+		return nil, fmt.Errorf("-query-timeout must not be negative, got %v", config.QueryTimeout)
+	}
+	if config.HealthInterval <= 0 {
+		return nil, fmt.Errorf("-health-interval must be positive, got %v", config.HealthInterval)
+	}
+	if config.MaxBackoff < dbload.MinReconnectBackoff {
+		return nil, fmt.Errorf("-reconnect-max-backoff must be at least %v, got %v", dbload.MinReconnectBackoff, config.MaxBackoff)
+	}
+	if config.TxMaxRetries < 0 {
+		return nil, fmt.Errorf("-tx-max-retries must not be negative, got %d", config.TxMaxRetries)
+	}
+	if config.PoolStatsInterval <= 0 {
+		return nil, fmt.Errorf("-pool-stats-interval must be positive, got %v", config.PoolStatsInterval)
+	}
+	if _, err := dbload.NewLogger(config.LogFormat, config.LogLevel, zapcore.AddSync(io.Discard)); err != nil {
+		return nil, err
+	}
 	
-	flag.Parse()
-	return config
+	return config, nil
 }
 
-func NewTestGenerator(config *Config) (*TestGenerator, error) {
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		config.Host, config.Port, config.User, config.Password, config.Database)
+func NewTestGenerator(config *Config, logger *zap.Logger) (*TestGenerator, error) {
+	connStr := dbload.PostgresDSN(config.Host, strconv.Itoa(config.Port), config.User, config.Password, config.Database, config.TLS)
+	logger.Info("Connecting to PostgreSQL", zap.String("dsn", dbload.RedactDSN(connStr)))
 	
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -99,10 +193,15 @@ func NewTestGenerator(config *Config) (*TestGenerator, error) {
 	
 	generator := &TestGenerator{
 		config: config,
+		logger: logger,
 		db:     db,
 		ctx:    ctx,
 		cancel: cancel,
+		pools:  dbload.NewPoolMetrics(),
 	}
+	generator.txRetries = dbload.NewTxRetrier(config.TxMaxRetries)
+	generator.conn = dbload.NewConnMonitor(db.PingContext, logger, config.HealthInterval, config.MaxBackoff)
+	generator.pools.Add("primary", db.Stats)
 	
 	// Initialize test schema
 	if err := generator.initSchema(); err != nil {
@@ -110,6 +209,23 @@ func NewTestGenerator(config *Config) (*TestGenerator, error) {
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 	
+	if config.EnableReplication && config.ReplicaDSN != "" {
+		replica, err := sql.Open("postgres", config.ReplicaDSN)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to replica: %w", err)
+		}
+		if err := replica.PingContext(ctx); err != nil {
+			replica.Close()
+			db.Close()
+			return nil, fmt.Errorf("failed to ping replica: %w", err)
+		}
+		// One connection measures lag, one holds transactions
+		replica.SetMaxOpenConns(2)
+		generator.replica = replica
+		generator.pools.Add("replica", replica.Stats)
+	}
+	
 	return generator, nil
 }
 
@@ -160,7 +276,7 @@ func (g *TestGenerator) initSchema() error {
 	}
 	
 	// Insert initial data for large table
-	log.Println("Inserting initial test data...")
+	g.logger.Info("Inserting initial test data...")
 	tx, err := g.db.BeginTx(g.ctx, nil)
 	if err != nil {
 		return err
@@ -184,6 +300,24 @@ func (g *TestGenerator) initSchema() error {
 }
 
 func (g *TestGenerator) Start() {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.conn.Run(g.ctx)
+	}()
+
+	if g.config.MetricsAddr != "" {
+		g.wg.Add(2)
+		go func() {
+			defer g.wg.Done()
+			g.pools.Run(g.ctx, g.config.PoolStatsInterval)
+		}()
+		go func() {
+			defer g.wg.Done()
+			g.pools.Serve(g.ctx, g.config.MetricsAddr, g.logger)
+		}()
+	}
+	
 	patterns := []struct {
 		name    string
 		workers int
@@ -208,12 +342,20 @@ func (g *TestGenerator) Start() {
 		}{"Deadlock Generation", 2, g.deadlockPattern})
 	}
 	
+	if g.config.EnableReplication {
+		patterns = append(patterns, struct {
+			name    string
+			workers int
+			fn      func()
+		}{"Replication Lag", 1, g.replicationLagPattern})
+	}
+	
 	for _, pattern := range patterns {
 		for i := 0; i < pattern.workers; i++ {
 			g.wg.Add(1)
 			go func(name string, id int, fn func()) {
 				defer g.wg.Done()
-				log.Printf("Starting %s worker %d", name, id)
+				g.logger.Info("Starting worker", zap.String("pattern", name), zap.Int("worker", id))
 				fn()
 			}(pattern.name, i, pattern.fn)
 		}
@@ -223,10 +365,73 @@ func (g *TestGenerator) Start() {
 func (g *TestGenerator) Stop() {
 	g.cancel()
 	g.wg.Wait()
+	pool := g.db.Stats()
+	g.logger.Info("Test generator stopped",
+		zap.Int64("query_errors", g.stats.Errors()),
+		zap.Int64("query_timeouts", g.stats.Timeouts()),
+		zap.Int64("query_disconnects", g.stats.Disconnects()),
+		zap.Int64("database_outages", g.conn.Outages()),
+		zap.Int64("tx_retries", g.txRetries.Retries()),
+		zap.Int64("tx_retries_exhausted", g.txRetries.Exhausted()),
+		zap.Int64("pool_waits", pool.WaitCount),
+		zap.Duration("pool_wait_time", pool.WaitDuration))
 }
 
 func (g *TestGenerator) Close() {
 	g.db.Close()
+	if g.replica != nil {
+		g.replica.Close()
+	}
+}
+
+// queryContext returns the context for one query or transaction, bounded by
+// -query-timeout and cancelled on shutdown
+func (g *TestGenerator) queryContext() (context.Context, context.CancelFunc) {
+	return dbload.WithQueryTimeout(g.ctx, g.config.QueryTimeout)
+}
+
+// queryResult counts a query run with ctx as a timeout or an error. Several
+// patterns fail on purpose (deadlocks, lock waits), so failures are only
+// logged at debug level.
+func (g *TestGenerator) queryResult(ctx context.Context, pattern string, err error) {
+	switch g.stats.Record(g.ctx, ctx, err) {
+	case dbload.QueryTimedOut:
+		g.logger.Debug("Query timed out",
+			zap.String("pattern", pattern),
+			zap.Duration("timeout", g.config.QueryTimeout))
+	case dbload.QueryFailed:
+		g.logger.Debug("Query failed", zap.String("pattern", pattern), zap.Error(err))
+	case dbload.QueryDisconnected:
+		// Not the query's fault: pause the workers until the database is back
+		g.conn.ReportError(err)
+	}
+}
+
+// txResult counts the begin or commit that failed a transaction run with
+// runTx; the statements are counted as they run
+func (g *TestGenerator) txResult(ctx context.Context, pattern string, err error) {
+	var step *dbload.TxStepError
+	if errors.As(err, &step) {
+		g.queryResult(ctx, pattern, step.Err)
+	}
+}
+
+// offline reports whether the database is unreachable. Workers skip their
+// turn until the connection monitor sees it recover.
+func (g *TestGenerator) offline() bool {
+	return !g.conn.Connected()
+}
+
+// scan runs a read query and discards the rows
+func (g *TestGenerator) scan(pattern, query string, args ...interface{}) {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	if err == nil {
+		rows.Close()
+	}
+	g.queryResult(ctx, pattern, err)
 }
 
 // Pattern implementations to exercise different metrics
@@ -240,10 +445,15 @@ func (g *TestGenerator) connectionChurnPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			// Create and close connections to exercise postgresql.backends metric
 			conn, err := g.db.Conn(g.ctx)
 			if err != nil {
-				log.Printf("Connection churn error: %v", err)
+				if !g.conn.ReportError(err) {
+					g.logger.Warn("Connection churn failed", zap.Error(err))
+				}
 				continue
 			}
 			
@@ -263,32 +473,42 @@ func (g *TestGenerator) transactionPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
-			// Randomly choose commit or rollback to exercise both metrics
-			shouldCommit := rand.Float32() > 0.1 // 90% commit, 10% rollback
-			
-			tx, err := g.db.BeginTx(g.ctx, nil)
-			if err != nil {
+			if g.offline() {
 				continue
 			}
-			
-			// Perform some operations
-			accountID := rand.Intn(1000)
-			amount := rand.Float64() * 1000
-			
-			_, err = tx.Exec(
-				"INSERT INTO test_transactions (account_id, amount, type) VALUES ($1, $2, $3)",
-				accountID, amount, "TEST",
-			)
-			
-			if err != nil || !shouldCommit {
-				tx.Rollback() // Exercise postgresql.rollbacks
-			} else {
-				tx.Commit() // Exercise postgresql.commits
-			}
+			g.transaction()
 		}
 	}
 }
 
+func (g *TestGenerator) transaction() {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	// Randomly choose commit or rollback to exercise both metrics
+	shouldCommit := rand.Float32() > 0.1 // 90% commit, 10% rollback
+	
+	err := g.txRetries.RunTx(ctx, g.db, func(tx *sql.Tx) error {
+		// Perform some operations
+		accountID := rand.Intn(1000)
+		amount := rand.Float64() * 1000
+		
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO test_transactions (account_id, amount, type) VALUES ($1, $2, $3)",
+			accountID, amount, "TEST",
+		)
+		g.queryResult(ctx, "transaction", err)
+		if err != nil {
+			return err
+		}
+		if !shouldCommit {
+			return dbload.ErrRollback // Exercise postgresql.rollbacks
+		}
+		return nil // Exercise postgresql.commits
+	})
+	g.txResult(ctx, "transaction", err)
+}
+
 func (g *TestGenerator) queryLoadPattern() {
 	ticker := time.NewTicker(g.config.QueryInterval)
 	defer ticker.Stop()
@@ -310,20 +530,27 @@ func (g *TestGenerator) queryLoadPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			query := queries[rand.Intn(len(queries))]
+			ctx, cancel := g.queryContext()
 			
+			var err error
 			switch query {
 			case queries[0], queries[2], queries[4], queries[5]:
-				g.db.ExecContext(g.ctx, query, fmt.Sprintf("cat_%d", rand.Intn(10)))
+				_, err = g.db.ExecContext(ctx, query, fmt.Sprintf("cat_%d", rand.Intn(10)))
 			case queries[1]:
-				g.db.QueryRowContext(g.ctx, query).Scan(new(int))
+				err = g.db.QueryRowContext(ctx, query).Scan(new(int))
 			case queries[3]:
-				g.db.ExecContext(g.ctx, query, 
+				_, err = g.db.ExecContext(ctx, query, 
 					generateRandomString(50), 
 					fmt.Sprintf("cat_%d", rand.Intn(10)),
 					rand.Float64()*100,
 				)
 			}
+			g.queryResult(ctx, "query_load", err)
+			cancel()
 		}
 	}
 }
@@ -337,21 +564,15 @@ func (g *TestGenerator) indexOperationsPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			// Queries that use indexes to exercise postgresql.index.scans
 			category := fmt.Sprintf("cat_%d", rand.Intn(10))
-			
-			rows, err := g.db.QueryContext(g.ctx,
-				"SELECT * FROM test_metrics WHERE category = $1", category)
-			if err == nil {
-				rows.Close()
-			}
+			g.scan("index_operations", "SELECT * FROM test_metrics WHERE category = $1", category)
 			
 			// Query by date range (uses index)
-			rows, err = g.db.QueryContext(g.ctx,
-				"SELECT * FROM test_metrics WHERE created_at > NOW() - INTERVAL '1 hour'")
-			if err == nil {
-				rows.Close()
-			}
+			g.scan("index_operations", "SELECT * FROM test_metrics WHERE created_at > NOW() - INTERVAL '1 hour'")
 		}
 	}
 }
@@ -365,13 +586,12 @@ func (g *TestGenerator) sequentialScanPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			// Force sequential scan on large table (no index on random_value)
 			// This exercises postgresql.sequential_scans
-			rows, err := g.db.QueryContext(g.ctx,
-				"SELECT * FROM test_large WHERE random_value = $1", rand.Intn(1000))
-			if err == nil {
-				rows.Close()
-			}
+			g.scan("sequential_scan", "SELECT * FROM test_large WHERE random_value = $1", rand.Intn(1000))
 		}
 	}
 }
@@ -389,21 +609,102 @@ func (g *TestGenerator) tempFilePattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
-			// Large sort operation to generate temp files
-			// This exercises postgresql.temp_files
-			rows, err := g.db.QueryContext(g.ctx, `
-				SELECT t1.*, t2.data 
-				FROM test_large t1 
-				JOIN test_large t2 ON t1.random_value = t2.random_value 
-				ORDER BY t1.data, t2.data
-			`)
-			if err == nil {
-				rows.Close()
+			if g.offline() {
+				continue
 			}
+			// Large sort operation to generate temp files
+			// This exercises postgresql.temp_files and postgresql.temp_bytes
+			g.spillTempFiles()
 		}
 	}
 }
 
+// tempStatsFlushDelay is how long to wait before reading the temp file
+// statistics back; backends report them at most once a second
+const tempStatsFlushDelay = 2 * time.Second
+
+// spillTempFiles runs one sort that spills to disk and logs how many temp
+// bytes pg_stat_database recorded meanwhile. The delta covers the whole
+// database, so it also includes the other temp file worker.
+func (g *TestGenerator) spillTempFiles() {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	// work_mem is a session setting, so the sort needs its own connection
+	conn, err := g.db.Conn(ctx)
+	if err != nil {
+		g.queryResult(ctx, "temp_files", err)
+		return
+	}
+	defer conn.Close()
+	
+	if g.config.TempWorkMem != "" {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET work_mem = '%s'", g.config.TempWorkMem)); err != nil {
+			g.logger.Warn("Temp files: failed to set work_mem", zap.String("work_mem", g.config.TempWorkMem), zap.Error(err))
+			return
+		}
+		// The connection goes back to the pool afterwards
+		defer conn.ExecContext(context.Background(), "RESET work_mem")
+	}
+	
+	before, err := g.tempBytes()
+	if err != nil {
+		g.logger.Warn("Temp files: failed to read temp_bytes", zap.Error(err))
+		return
+	}
+	
+	var rows *sql.Rows
+	if g.config.TempSortRows > 0 {
+		// The OFFSET skips every row, so the whole input is sorted but
+		// nothing is sent back
+		rows, err = conn.QueryContext(ctx, `
+			SELECT i, md5(i::text) AS h
+			FROM generate_series(1, $1::int) i
+			ORDER BY h
+			OFFSET $1
+		`, g.config.TempSortRows)
+	} else {
+		rows, err = conn.QueryContext(ctx, `
+			SELECT t1.*, t2.data 
+			FROM test_large t1 
+			JOIN test_large t2 ON t1.random_value = t2.random_value 
+			ORDER BY t1.data, t2.data
+		`)
+	}
+	if err != nil {
+		g.queryResult(ctx, "temp_files", err)
+		return
+	}
+	rows.Close()
+	
+	select {
+	case <-g.ctx.Done():
+		return
+	case <-time.After(tempStatsFlushDelay):
+	}
+	
+	after, err := g.tempBytes()
+	if err != nil {
+		g.logger.Warn("Temp files: failed to read temp_bytes", zap.Error(err))
+		return
+	}
+	g.logger.Info("Temp files: sort generated temp files",
+		zap.Float64("mb", float64(after-before)/(1024*1024)),
+		zap.Int64("temp_bytes_before", before),
+		zap.Int64("temp_bytes_after", after))
+}
+
+// tempBytes returns pg_stat_database.temp_bytes for the test database
+func (g *TestGenerator) tempBytes() (int64, error) {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	var bytes int64
+	err := g.db.QueryRowContext(ctx,
+		"SELECT temp_bytes FROM pg_stat_database WHERE datname = current_database()").Scan(&bytes)
+	return bytes, err
+}
+
 func (g *TestGenerator) walActivityPattern() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -413,30 +714,43 @@ func (g *TestGenerator) walActivityPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
-			// Bulk insert to generate WAL activity
-			// This exercises postgresql.wal.* metrics
-			tx, err := g.db.BeginTx(g.ctx, nil)
-			if err != nil {
+			if g.offline() {
 				continue
 			}
-			
-			stmt, err := tx.Prepare("INSERT INTO test_metrics (data, category, value) VALUES ($1, $2, $3)")
+			// Bulk insert to generate WAL activity
+			// This exercises postgresql.wal.* metrics
+			g.walBatch()
+		}
+	}
+}
+
+// walBatch inserts 100 rows in one transaction
+func (g *TestGenerator) walBatch() {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	err := g.txRetries.RunTx(ctx, g.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, "INSERT INTO test_metrics (data, category, value) VALUES ($1, $2, $3)")
+		if err != nil {
+			g.queryResult(ctx, "wal_activity", err)
+			return err
+		}
+		defer stmt.Close()
+		
+		for i := 0; i < 100; i++ {
+			_, err := stmt.ExecContext(ctx,
+				generateRandomString(100),
+				fmt.Sprintf("bulk_%d", rand.Intn(5)),
+				rand.Float64()*1000,
+			)
 			if err != nil {
-				tx.Rollback()
-				continue
-			}
-			
-			for i := 0; i < 100; i++ {
-				stmt.Exec(
-					generateRandomString(100),
-					fmt.Sprintf("bulk_%d", rand.Intn(5)),
-					rand.Float64()*1000,
-				)
+				g.queryResult(ctx, "wal_activity", err)
+				return err
 			}
-			stmt.Close()
-			tx.Commit()
 		}
-	}
+		return nil
+	})
+	g.txResult(ctx, "wal_activity", err)
 }
 
 func (g *TestGenerator) vacuumPattern() {
@@ -448,11 +762,17 @@ func (g *TestGenerator) vacuumPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			// Run VACUUM to exercise postgresql.table.vacuum.count
 			tables := []string{"test_metrics", "test_transactions", "test_locks"}
 			table := tables[rand.Intn(len(tables))]
 			
-			g.db.ExecContext(g.ctx, fmt.Sprintf("VACUUM %s", table))
+			ctx, cancel := g.queryContext()
+			_, err := g.db.ExecContext(ctx, fmt.Sprintf("VACUUM %s", table))
+			g.queryResult(ctx, "vacuum", err)
+			cancel()
 		}
 	}
 }
@@ -466,32 +786,42 @@ func (g *TestGenerator) lockContentionPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
-			resourceID := rand.Intn(10) // Limited resources to increase contention
-			
-			tx, err := g.db.BeginTx(g.ctx, nil)
-			if err != nil {
+			if g.offline() {
 				continue
 			}
-			
-			// Try to acquire lock on resource
-			// This exercises postgresql.locks and potentially db.ash.blocked_sessions
-			_, err = tx.Exec(`
-				INSERT INTO test_locks (resource_id, lock_type) 
-				VALUES ($1, 'exclusive')
-				ON CONFLICT (resource_id) DO UPDATE 
-				SET acquired_at = CURRENT_TIMESTAMP
-			`, resourceID)
-			
-			if err == nil {
-				// Hold lock briefly to create contention
-				time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond)
-			}
-			
-			tx.Rollback()
+			g.lockContention()
 		}
 	}
 }
 
+func (g *TestGenerator) lockContention() {
+	resourceID := rand.Intn(10) // Limited resources to increase contention
+	
+	// Waiting for the lock is what the query timeout bounds
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	err := g.txRetries.RunTx(ctx, g.db, func(tx *sql.Tx) error {
+		// Try to acquire lock on resource
+		// This exercises postgresql.locks and potentially db.ash.blocked_sessions
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO test_locks (resource_id, lock_type) 
+			VALUES ($1, 'exclusive')
+			ON CONFLICT (resource_id) DO UPDATE 
+			SET acquired_at = CURRENT_TIMESTAMP
+		`, resourceID)
+		g.queryResult(ctx, "lock_contention", err)
+		if err != nil {
+			return err
+		}
+		
+		// Hold lock briefly to create contention, then release it
+		time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond)
+		return dbload.ErrRollback
+	})
+	g.txResult(ctx, "lock_contention", err)
+}
+
 func (g *TestGenerator) deadlockPattern() {
 	if !g.config.EnableDeadlocks {
 		return
@@ -505,6 +835,9 @@ func (g *TestGenerator) deadlockPattern() {
 		case <-g.ctx.Done():
 			return
 		case <-ticker.C:
+			if g.offline() {
+				continue
+			}
 			// Create potential deadlock situation
 			// This exercises postgresql.deadlocks
 			go g.deadlockWorker(1, 2)
@@ -514,20 +847,185 @@ func (g *TestGenerator) deadlockPattern() {
 }
 
 func (g *TestGenerator) deadlockWorker(first, second int) {
-	tx, err := g.db.BeginTx(g.ctx, nil)
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	err := g.txRetries.RunTx(ctx, g.db, func(tx *sql.Tx) error {
+		// Lock first resource
+		_, err := tx.ExecContext(ctx, "UPDATE test_locks SET lock_type = 'deadlock_test' WHERE resource_id = $1", first)
+		if err != nil {
+			g.queryResult(ctx, "deadlock", err)
+			return err
+		}
+		
+		// Small delay
+		time.Sleep(100 * time.Millisecond)
+		
+		// Try to lock second resource (potential deadlock). PostgreSQL aborts
+		// one side of a deadlock, which counts as an error, and the victim is
+		// retried once the other side has committed.
+		_, err = tx.ExecContext(ctx, "UPDATE test_locks SET lock_type = 'deadlock_test' WHERE resource_id = $1", second)
+		g.queryResult(ctx, "deadlock", err)
+		return err
+	})
+	g.txResult(ctx, "deadlock", err)
+}
+
+func (g *TestGenerator) replicationLagPattern() {
+	if g.replica == nil {
+		g.logger.Warn("Replication testing enabled but no replica DSN configured (-replica-dsn); skipping replication lag pattern")
+		return
+	}
+	
+	ctx, cancel := g.queryContext()
+	var inRecovery bool
+	err := g.replica.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery)
+	cancel()
+	if err != nil {
+		g.logger.Error("Replication lag pattern: failed to check replica", zap.Error(err))
+		return
+	}
+	if !inRecovery {
+		g.logger.Warn("Replica DSN does not point at a standby; skipping replication lag pattern")
+		return
+	}
+	
+	writeTicker := time.NewTicker(time.Second)
+	defer writeTicker.Stop()
+	
+	measureTicker := time.NewTicker(5 * time.Second)
+	defer measureTicker.Stop()
+	
+	// Holding transactions is optional; a nil channel never fires
+	var holdTicks <-chan time.Time
+	if g.config.ReplicationHold > 0 {
+		holdTicker := time.NewTicker(2 * g.config.ReplicationHold)
+		defer holdTicker.Stop()
+		holdTicks = holdTicker.C
+	}
+	
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-writeTicker.C:
+			if g.offline() {
+				continue
+			}
+			// Bulk writes on the primary give the replica WAL to replay
+			// This exercises postgresql.replication.data_delay and wal lag metrics
+			g.replicationWrite()
+		case <-measureTicker.C:
+			g.measureReplicationLag()
+		case <-holdTicks:
+			g.wg.Add(1)
+			go func() {
+				defer g.wg.Done()
+				g.holdReplicaSnapshot()
+			}()
+		}
+	}
+}
+
+// replicationWrite inserts a batch of rows on the primary and removes the
+// previous batch, leaving dead tuples for VACUUM to clean up
+func (g *TestGenerator) replicationWrite() {
+	ctx, cancel := g.queryContext()
+	defer cancel()
+	
+	tx, err := g.db.BeginTx(ctx, nil)
 	if err != nil {
+		g.queryResult(ctx, "replication_lag", err)
 		return
 	}
 	defer tx.Rollback()
 	
-	// Lock first resource
-	tx.Exec("UPDATE test_locks SET lock_type = 'deadlock_test' WHERE resource_id = $1", first)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM test_metrics WHERE category = 'replication'"); err != nil {
+		g.queryResult(ctx, "replication_lag", err)
+		return
+	}
 	
-	// Small delay
-	time.Sleep(100 * time.Millisecond)
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO test_metrics (data, category, value) VALUES ($1, 'replication', $2)")
+	if err != nil {
+		g.queryResult(ctx, "replication_lag", err)
+		return
+	}
+	defer stmt.Close()
+	for i := 0; i < 500; i++ {
+		if _, err := stmt.ExecContext(ctx, generateRandomString(200), rand.Float64()*1000); err != nil {
+			g.queryResult(ctx, "replication_lag", err)
+			return
+		}
+	}
+	g.queryResult(ctx, "replication_lag", tx.Commit())
+}
+
+// measureReplicationLag logs how far the replica's replay position is behind
+// the primary's current WAL position, in bytes and in time
+func (g *TestGenerator) measureReplicationLag() {
+	ctx, cancel := g.queryContext()
+	defer cancel()
 	
-	// Try to lock second resource (potential deadlock)
-	tx.Exec("UPDATE test_locks SET lock_type = 'deadlock_test' WHERE resource_id = $1", second)
+	var primaryLSN string
+	if err := g.db.QueryRowContext(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&primaryLSN); err != nil {
+		g.logger.Warn("Replication lag: failed to read primary WAL position", zap.Error(err))
+		return
+	}
+	
+	var replayLSN sql.NullString
+	var lagBytes, lagSeconds sql.NullFloat64
+	err := g.replica.QueryRowContext(ctx, `
+		SELECT
+			pg_last_wal_replay_lsn()::text,
+			pg_wal_lsn_diff($1::pg_lsn, pg_last_wal_replay_lsn()),
+			EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	`, primaryLSN).Scan(&replayLSN, &lagBytes, &lagSeconds)
+	if err != nil {
+		g.logger.Warn("Replication lag: failed to read replica replay position", zap.Error(err))
+		return
+	}
+	
+	// Nothing has been replayed yet right after the standby starts
+	if !replayLSN.Valid {
+		g.logger.Info("Replication lag: replica has not replayed any WAL yet")
+		return
+	}
+	
+	g.logger.Info("Replication lag",
+		zap.String("primary_lsn", primaryLSN),
+		zap.String("replay_lsn", replayLSN.String),
+		zap.Float64("lag_bytes", lagBytes.Float64),
+		zap.Float64("last_replay_age_seconds", lagSeconds.Float64))
+}
+
+// holdReplicaSnapshot keeps a repeatable-read transaction open on the
+// replica while the primary vacuums the rows it can see. Replay of the
+// cleanup conflicts with the snapshot, so the standby delays it for up to
+// max_standby_streaming_delay and lag builds up.
+func (g *TestGenerator) holdReplicaSnapshot() {
+	// The transaction is held open on purpose, so it only ends at shutdown
+	// or after -replication-hold, not at the query timeout
+	tx, err := g.replica.BeginTx(g.ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+	
+	var count int
+	if err := tx.QueryRow("SELECT count(*) FROM test_metrics WHERE category = 'replication'").Scan(&count); err != nil {
+		return
+	}
+	
+	g.logger.Info("Replication lag: holding a replica transaction", zap.Duration("hold", g.config.ReplicationHold))
+	ctx, cancel := g.queryContext()
+	_, err = g.db.ExecContext(ctx, "VACUUM test_metrics")
+	g.queryResult(ctx, "replication_lag", err)
+	cancel()
+	
+	select {
+	case <-g.ctx.Done():
+	case <-time.After(g.config.ReplicationHold):
+	}
 }
 
 // Utility functions
@@ -548,6 +1046,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		var intValue int
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			args: nil,
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.EnableTempFiles {
+					t.Error("temp files should be enabled by default")
+				}
+				if cfg.TempWorkMem != "" {
+					t.Errorf("TempWorkMem = %q, want server setting", cfg.TempWorkMem)
+				}
+				if cfg.TempSortRows != 0 {
+					t.Errorf("TempSortRows = %d, want 0", cfg.TempSortRows)
+				}
+				if cfg.QueryInterval != 100*time.Millisecond {
+					t.Errorf("QueryInterval = %v, want 100ms", cfg.QueryInterval)
+				}
+				if cfg.LogFormat != "console" || cfg.LogLevel != "info" {
+					t.Errorf("LogFormat, LogLevel = %q, %q, want console, info", cfg.LogFormat, cfg.LogLevel)
+				}
+				if cfg.QueryTimeout != 30*time.Second {
+					t.Errorf("QueryTimeout = %v, want 30s", cfg.QueryTimeout)
+				}
+				if cfg.HealthInterval != 5*time.Second || cfg.MaxBackoff != 30*time.Second {
+					t.Errorf("HealthInterval, MaxBackoff = %v, %v, want 5s, 30s", cfg.HealthInterval, cfg.MaxBackoff)
+				}
+				if cfg.TLS.SSLMode != "disable" {
+					t.Errorf("TLS.SSLMode = %q, want disable", cfg.TLS.SSLMode)
+				}
+				if cfg.TxMaxRetries != 3 {
+					t.Errorf("TxMaxRetries = %d, want 3", cfg.TxMaxRetries)
+				}
+				if cfg.MetricsAddr != ":9101" || cfg.PoolStatsInterval != 5*time.Second {
+					t.Errorf("MetricsAddr, PoolStatsInterval = %q, %v, want :9101, 5s", cfg.MetricsAddr, cfg.PoolStatsInterval)
+				}
+			},
+		},
+		{
+			name: "query timeout disabled",
+			args: []string{"-query-timeout=0"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.QueryTimeout != 0 {
+					t.Errorf("QueryTimeout = %v, want 0", cfg.QueryTimeout)
+				}
+			},
+		},
+		{
+			name:    "negative query timeout",
+			args:    []string{"-query-timeout=-1s"},
+			wantErr: "-query-timeout must not be negative",
+		},
+		{
+			name:    "zero health interval",
+			args:    []string{"-health-interval=0"},
+			wantErr: "-health-interval must be positive",
+		},
+		{
+			name:    "negative transaction retries",
+			args:    []string{"-tx-max-retries=-1"},
+			wantErr: "-tx-max-retries must not be negative",
+		},
+		{
+			name:    "zero pool stats interval",
+			args:    []string{"-pool-stats-interval=0"},
+			wantErr: "-pool-stats-interval must be positive",
+		},
+		{
+			name:    "backoff below minimum",
+			args:    []string{"-reconnect-max-backoff=10ms"},
+			wantErr: "-reconnect-max-backoff must be at least",
+		},
+		{
+			name: "temp file sizing",
+			args: []string{"-temp-work-mem=64kB", "-temp-sort-rows=1000000"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.TempWorkMem != "64kB" {
+					t.Errorf("TempWorkMem = %q, want 64kB", cfg.TempWorkMem)
+				}
+				if cfg.TempSortRows != 1000000 {
+					t.Errorf("TempSortRows = %d, want 1000000", cfg.TempSortRows)
+				}
+			},
+		},
+		{
+			name: "work_mem without unit",
+			args: []string{"-temp-work-mem=4096"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.TempWorkMem != "4096" {
+					t.Errorf("TempWorkMem = %q, want 4096", cfg.TempWorkMem)
+				}
+			},
+		},
+		{
+			name:    "invalid work_mem",
+			args:    []string{"-temp-work-mem=64kB'; DROP TABLE test_large; --"},
+			wantErr: "invalid -temp-work-mem",
+		},
+		{
+			name:    "work_mem with unknown unit",
+			args:    []string{"-temp-work-mem=64mb"},
+			wantErr: "invalid -temp-work-mem",
+		},
+		{
+			name:    "negative sort rows",
+			args:    []string{"-temp-sort-rows=-1"},
+			wantErr: "-temp-sort-rows must not be negative",
+		},
+		{
+			name: "managed database TLS",
+			args: []string{"-sslmode=verify-full", "-sslrootcert=/etc/ssl/rds-ca.pem"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.TLS.SSLMode != "verify-full" || cfg.TLS.RootCert != "/etc/ssl/rds-ca.pem" {
+					t.Errorf("TLS = %+v, want verify-full with the RDS CA", cfg.TLS)
+				}
+			},
+		},
+		{
+			name:    "invalid sslmode",
+			args:    []string{"-sslmode=on"},
+			wantErr: "invalid -sslmode",
+		},
+		{
+			name: "json logging",
+			args: []string{"-log-format=json", "-log-level=warn"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.LogFormat != "json" || cfg.LogLevel != "warn" {
+					t.Errorf("LogFormat, LogLevel = %q, %q, want json, warn", cfg.LogFormat, cfg.LogLevel)
+				}
+			},
+		},
+		{
+			name:    "invalid log format",
+			args:    []string{"-log-format=xml"},
+			wantErr: "invalid log format",
+		},
+		{
+			name:    "invalid log level",
+			args:    []string{"-log-level=loud"},
+			wantErr: "invalid log level",
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-no-such-flag"},
+			wantErr: "flag provided but not defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}